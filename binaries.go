@@ -0,0 +1,83 @@
+//go:build !js
+
+package main
+
+import (
+	"fmt"
+	"os"
+	"relativity_simulation_2d/internal/export"
+	"relativity_simulation_2d/internal/physics"
+	"relativity_simulation_2d/internal/provenance"
+)
+
+// binaryOptions returns the binary detection options configured by cfg. The
+// direct solver's pair force is softened by cfg.Softening; the mesh smooths
+// the PM force over about a cell. cfg.BinarySeparation is in cells
+func binaryOptions() physics.BinaryOptions {
+	dx := cfg.Spacing()
+	softening := dx
+	if cfg.DirectSolver() {
+		softening = cfg.Softening
+	}
+	return physics.BinaryOptions{
+		MaxSeparation:         cfg.BinarySeparation * dx,
+		Softening:             softening,
+		Width:                 cfg.SimulationWidth,
+		Height:                cfg.SimulationDepth,
+		CellSize:              cfg.CellSize,
+		GravitationalConstant: cfg.GravitationalConstant,
+	}
+}
+
+// openBinaryLog starts logging binary events to cfg.BinaryLogPath, if set,
+// with the run's metadata beside it
+func (s *Simulation) openBinaryLog() error {
+	if s.binaries == nil || cfg.BinaryLogPath == "" {
+		return nil
+	}
+	log, err := export.NewBinaryLog(cfg.BinaryLogPath)
+	if err != nil {
+		return err
+	}
+	if err := provenance.WriteSidecar(cfg.BinaryLogPath, s.metadata()); err != nil {
+		_ = log.Close()
+		return err
+	}
+	s.binaryLog = log
+	return nil
+}
+
+// closeBinaryLog closes the binary event log, if open
+func (s *Simulation) closeBinaryLog() error {
+	if s.binaryLog == nil {
+		return nil
+	}
+	err := s.binaryLog.Close()
+	s.binaryLog = nil
+	return err
+}
+
+// binaryInterval returns the number of steps between binary detections
+func binaryInterval() int64 {
+	if cfg.BinaryInterval <= 0 {
+		return physics.DefaultBinaryInterval
+	}
+	return int64(cfg.BinaryInterval)
+}
+
+// detectBinaries is the observer that updates the binaries every
+// binaryInterval steps and logs their formations and disruptions. A failed
+// write stops the log
+func (s *Simulation) detectBinaries(step int64) {
+	if s.binaries == nil {
+		return
+	}
+	events := s.binaries.Update(s.Particles, step, s.SimTime)
+	if s.binaryLog == nil || len(events) == 0 {
+		return
+	}
+	if err := s.binaryLog.Log(events); err != nil {
+		fmt.Fprintf(os.Stderr, "Warning: binary log stopped: %v\n", err)
+		_ = s.closeBinaryLog()
+	}
+}
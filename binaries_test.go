@@ -0,0 +1,61 @@
+//go:build !js
+
+package main
+
+import (
+	"math"
+	"os"
+	"path/filepath"
+	"relativity_simulation_2d/internal/config"
+	"relativity_simulation_2d/internal/physics"
+	"strings"
+	"testing"
+)
+
+// TestBinaryDetection tests logging the formation and disruption of a binary
+func TestBinaryDetection(t *testing.T) {
+	saved, savedGPU := cfg, useGPU
+	defer func() { cfg, useGPU = saved, savedGPU }()
+	cfg = config.DefaultConfig()
+	cfg.SimulationWidth, cfg.SimulationDepth = 64, 64
+	cfg.NumParticles = 2
+	cfg.Seed = 7
+	cfg.Solver = config.SolverDirect
+	cfg.BinarySeparation = 5
+	cfg.BinaryInterval = 5
+	cfg.BinaryLogPath = filepath.Join(t.TempDir(), "binaries.csv")
+	useGPU = false
+
+	sim := NewSimulation()
+	if err := sim.openBinaryLog(); err != nil {
+		t.Fatalf("openBinaryLog failed: %v", err)
+	}
+	d, eps := 2.0, cfg.Softening
+	v := math.Sqrt(2 * cfg.GravitationalConstant * d * d / (d*d + eps*eps)) // Circular: v² = G·M·d²/(d² + ε²), M = 2
+	sim.Particles = []*physics.Particle{
+		physics.NewParticle(1, -d/2, 0, 0, 0, 0, -v/2),
+		physics.NewParticle(1, d/2, 0, 0, 0, 0, v/2),
+	}
+	for i := 0; i < 20; i++ {
+		sim.Step(0.01)
+	}
+	if active := sim.binaries.Active(); len(active) != 1 || math.Abs(active[0].Orbit.SemiMajorAxis-d) > 0.1 {
+		t.Fatalf("Expected one binary of semi-major axis %g, got %+v", d, active)
+	}
+	sim.Particles[1].Velocity.Z += 100
+	for i := 0; i < 5; i++ {
+		sim.Step(0.01)
+	}
+	if err := sim.closeBinaryLog(); err != nil {
+		t.Fatalf("closeBinaryLog failed: %v", err)
+	}
+
+	data, err := os.ReadFile(cfg.BinaryLogPath)
+	if err != nil {
+		t.Fatalf("Failed to read binary log: %v", err)
+	}
+	rows := strings.Split(strings.TrimSpace(string(data)), "\n")
+	if len(rows) != 3 || !strings.HasPrefix(rows[1], "formed,5,") || !strings.HasPrefix(rows[2], "disrupted,25,") {
+		t.Errorf("Expected a formation at step 5 and a disruption at step 25, got %q", rows)
+	}
+}
@@ -0,0 +1,82 @@
+//go:build !js
+
+package main
+
+import (
+	"fmt"
+	rl "github.com/gen2brain/raylib-go/raylib"
+	"relativity_simulation_2d/internal/config"
+	"relativity_simulation_2d/internal/input"
+	"relativity_simulation_2d/internal/renderer"
+)
+
+// bookmarkKeys are the number keys of the camera bookmarks, slot 1 first
+var bookmarkKeys = [config.NumBookmarks]int32{rl.KeyOne, rl.KeyTwo, rl.KeyThree, rl.KeyFour, rl.KeyFive, rl.KeySix, rl.KeySeven, rl.KeyEight, rl.KeyNine}
+
+// handleBookmarks saves the view to the bookmark of a number key pressed
+// with Ctrl, and jumps to the bookmark of a number key pressed alone. Saved
+// bookmarks are written to the config file so later runs have them. Shift
+// with a number key toggles a scene layer instead
+func handleBookmarks(camera *rl.Camera3D, watcher *configWatcher) {
+	if rl.IsKeyDown(rl.KeyLeftShift) || rl.IsKeyDown(rl.KeyRightShift) {
+		return
+	}
+	save := rl.IsKeyDown(rl.KeyLeftControl) || rl.IsKeyDown(rl.KeyRightControl)
+	for i, key := range bookmarkKeys {
+		if !rl.IsKeyPressed(key) {
+			continue
+		}
+		slot := i + 1
+		if save {
+			saveBookmark(cameraBookmark(slot, *camera, yaw, pitch), watcher)
+			continue
+		}
+		b, ok := cfg.Bookmark(slot)
+		if !ok {
+			ui.Notify(renderer.NotificationWarning, fmt.Sprintf("Bookmark %d is empty: Ctrl+%d saves the view to it", slot, slot))
+			continue
+		}
+		yaw, pitch = jumpToBookmark(camera, b)
+		ui.Notify(renderer.NotificationInfo, fmt.Sprintf("Bookmark %d", slot))
+	}
+}
+
+// saveBookmark sets the bookmark and writes the bookmarks to the config
+// file, or keeps them for this run only without one
+func saveBookmark(b config.Bookmark, watcher *configWatcher) {
+	if err := cfg.SetBookmark(b); err != nil {
+		ui.Notify(renderer.NotificationError, "Bookmark not saved: "+err.Error())
+		return
+	}
+	if cfg.ConfigPath == "" {
+		ui.Notify(renderer.NotificationWarning, fmt.Sprintf("Bookmark %d saved for this run; start with -config to keep bookmarks", b.Slot))
+		return
+	}
+	if err := config.SaveSetting(cfg.ConfigPath, "Bookmarks", cfg.Bookmarks); err != nil {
+		ui.Notify(renderer.NotificationError, fmt.Sprintf("Bookmark %d saved for this run, not to %s: %v", b.Slot, cfg.ConfigPath, err))
+		return
+	}
+	watcher.saved(func(c *config.Config) { c.Bookmarks = cfg.Bookmarks })
+	ui.Notify(renderer.NotificationInfo, fmt.Sprintf("Bookmark %d saved to %s", b.Slot, cfg.ConfigPath))
+}
+
+// cameraBookmark returns the view of camera, looking along yaw and pitch,
+// as the bookmark in slot
+func cameraBookmark(slot int, camera rl.Camera3D, yaw, pitch float32) config.Bookmark {
+	return config.Bookmark{
+		Slot: slot,
+		X:    float64(camera.Position.X),
+		Y:    float64(camera.Position.Y),
+		Z:    float64(camera.Position.Z),
+		Yaw:  float64(yaw), Pitch: float64(pitch),
+	}
+}
+
+// jumpToBookmark moves camera to the bookmark's view and returns its view
+// angles, which steer the camera from then on
+func jumpToBookmark(camera *rl.Camera3D, b config.Bookmark) (yaw, pitch float32) {
+	yaw, pitch = float32(b.Yaw), float32(b.Pitch)
+	camera.Position = rl.NewVector3(float32(b.X), float32(b.Y), float32(b.Z))
+	input.NewMouseHandler().UpdateCameraTarget(camera, yaw, pitch)
+	return yaw, pitch
+}
@@ -0,0 +1,63 @@
+//go:build !js && !android
+
+package main
+
+import (
+	rl "github.com/gen2brain/raylib-go/raylib"
+	"math"
+	"os"
+	"path/filepath"
+	"relativity_simulation_2d/internal/config"
+	"testing"
+	"time"
+)
+
+// TestBookmarkView tests that jumping to a saved bookmark restores the
+// camera's position and view direction
+func TestBookmarkView(t *testing.T) {
+	camera := initialCamera()
+	camera.Position = rl.NewVector3(10, 20, -30)
+	b := cameraBookmark(4, camera, 1.2, -0.5)
+	if b.Slot != 4 || b.X != 10 || b.Y != 20 || b.Z != -30 || math.Abs(b.Yaw-1.2) > 1e-6 || math.Abs(b.Pitch+0.5) > 1e-6 {
+		t.Fatalf("Unexpected bookmark %+v", b)
+	}
+
+	moved := initialCamera()
+	yaw, pitch := jumpToBookmark(&moved, b)
+	if moved.Position != camera.Position || yaw != 1.2 || pitch != -0.5 {
+		t.Errorf("Expected the bookmarked position and angles, got %v, %g and %g", moved.Position, yaw, pitch)
+	}
+	direction := rl.Vector3Subtract(moved.Target, moved.Position)
+	if math.Abs(float64(direction.Y)-math.Sin(-0.5)) > 1e-6 || math.Abs(float64(direction.X)-math.Cos(1.2)*math.Cos(-0.5)) > 1e-6 {
+		t.Errorf("Expected the camera to look along the bookmarked angles, got %v", direction)
+	}
+}
+
+// TestConfigWatcherSaved tests that the session's own write of a bookmark
+// to the config file is not reported as a change
+func TestConfigWatcherSaved(t *testing.T) {
+	saved := cfg
+	defer func() { cfg = saved }()
+	cfg = config.DefaultConfig()
+	cfg.ConfigPath = filepath.Join(t.TempDir(), "config.json")
+	if err := os.WriteFile(cfg.ConfigPath, []byte(`{"NumParticles": 10}`), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	w := newConfigWatcher(nil)
+
+	_ = cfg.SetBookmark(config.Bookmark{Slot: 1, Y: 10})
+	later := time.Now().Add(2 * time.Second)
+	if err := config.SaveSetting(cfg.ConfigPath, "Bookmarks", cfg.Bookmarks); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.Chtimes(cfg.ConfigPath, later, later); err != nil { // Past the file system's time resolution
+		t.Fatal(err)
+	}
+	w.saved(func(c *config.Config) { c.Bookmarks = cfg.Bookmarks })
+	if w.poll(later) {
+		t.Error("Expected the saved bookmarks not to count as a change")
+	}
+	if w.base.Bookmarks != cfg.Bookmarks {
+		t.Errorf("Expected the bookmarks taken as loaded, got %q", w.base.Bookmarks)
+	}
+}
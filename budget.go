@@ -0,0 +1,68 @@
+//go:build !js
+
+package main
+
+import (
+	"fmt"
+	rl "github.com/gen2brain/raylib-go/raylib"
+	"relativity_simulation_2d/internal/config"
+	"relativity_simulation_2d/internal/renderer"
+)
+
+// budgetSliderWidth is the width in pixels at scale 1 of the render budget
+// slider's track
+const budgetSliderWidth = 300
+
+// budgetSlider returns the slider of the render budget for a count of
+// particles, from the smallest budget up to drawing them all; each step
+// doubles or halves the budget
+func budgetSlider(particles int) renderer.Slider {
+	return renderer.Slider{Min: config.MinRenderBudget, Max: max(particles, config.MinRenderBudget), Factor: 2}
+}
+
+// stepBudget returns the render budget for particles moved by steps along
+// the slider, starting from all of them while none is set
+func stepBudget(budget, particles, steps int) int {
+	if budget == 0 || budget > particles {
+		budget = particles
+	}
+	return budgetSlider(particles).Step(budget, steps)
+}
+
+// handleBudgetKeys lowers the render budget with [ and raises it with ],
+// while there are enough particles to sub-sample. The cursor is hidden while
+// looking around, so the slider follows the keys instead of the mouse
+func handleBudgetKeys(particles int) {
+	steps := 0
+	if rl.IsKeyPressed(rl.KeyLeftBracket) {
+		steps--
+	}
+	if rl.IsKeyPressed(rl.KeyRightBracket) {
+		steps++
+	}
+	if steps == 0 || particles <= config.MinRenderBudget {
+		return
+	}
+	cfg.RenderBudget = stepBudget(cfg.RenderBudget, particles, steps)
+	ui.Notify(renderer.NotificationInfo, fmt.Sprintf("Render budget: %d of %d particles", cfg.RenderBudget, particles))
+}
+
+// drawBudgetSlider shows how many particles are drawn on a slider at the
+// top of the screen, while the render budget sub-samples them
+func drawBudgetSlider(drawn, particles int, scheme renderer.ColorScheme) {
+	if cfg.RenderBudget == 0 || particles <= cfg.RenderBudget {
+		return
+	}
+	x, y, width := ui.GetSliderPosition(budgetSliderWidth)
+	size := ui.GetFontSize()
+	label := fmt.Sprintf("Drawing %d of %d particles ([ ])", drawn, particles)
+	screenWidth, _ := ui.GetScreenDimensions()
+	drawHUDText(label, (screenWidth-int(rl.MeasureText(label, int32(size))))/2, y-size-size/2, ui.GetDefaultTextColor())
+
+	// The track, filled up to the knob
+	height := max(size/2, 4)
+	fill := int(budgetSlider(particles).Fraction(cfg.RenderBudget) * float64(width))
+	rl.DrawRectangle(int32(x), int32(y), int32(width), int32(height), raylibColor(scheme.Grid))
+	rl.DrawRectangle(int32(x), int32(y), int32(fill), int32(height), raylibColor(scheme.Text))
+	rl.DrawRectangle(int32(x+fill-height/2), int32(y-height/2), int32(height), int32(2*height), raylibColor(scheme.Title))
+}
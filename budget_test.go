@@ -0,0 +1,27 @@
+//go:build !js && !android
+
+package main
+
+import (
+	"relativity_simulation_2d/internal/config"
+	"testing"
+)
+
+// TestStepBudget tests stepping the render budget from drawing all
+// particles, and within the slider's range
+func TestStepBudget(t *testing.T) {
+	tests := []struct {
+		budget, particles, steps, want int
+	}{
+		{0, 1000000, -1, 500000},                                    // From all particles
+		{100000, 1000000, 1, 200000},                                // Doubled
+		{800000, 1000000, 1, 1000000},                               // Up to all particles
+		{2000000, 1000000, -1, 500000},                              // Over the count counts as all
+		{config.MinRenderBudget, 50000, -1, config.MinRenderBudget}, // No lower than the smallest budget
+	}
+	for _, tt := range tests {
+		if got := stepBudget(tt.budget, tt.particles, tt.steps); got != tt.want {
+			t.Errorf("stepBudget(%d, %d, %d) = %d, want %d", tt.budget, tt.particles, tt.steps, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,130 @@
+//go:build !js
+
+package main
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"relativity_simulation_2d/internal/calibration"
+	"relativity_simulation_2d/internal/config"
+	"relativity_simulation_2d/internal/gpu"
+	"time"
+)
+
+// calibrationDue reports whether this launch measures the hardware: a
+// windowed session whose calibration file is missing or to be replaced
+func calibrationDue(c *config.Config) bool {
+	if c.CalibrationPath == "" || c.Headless || c.RenderDir != "" {
+		return false
+	}
+	if c.Recalibrate {
+		return true
+	}
+	_, err := os.Stat(c.CalibrationPath)
+	return errors.Is(err, fs.ErrNotExist)
+}
+
+// loadCalibration returns the calibration of a windowed session, measuring
+// it first on the first launch or with -recalibrate. Headless runs and
+// offline renders keep the built-in defaults, so their results don't depend
+// on the machine. ok is false without a calibration; a failure to measure or
+// read one is reported to w
+func loadCalibration(w io.Writer) (result calibration.Result, ok bool) {
+	if cfg.CalibrationPath == "" || cfg.Headless || cfg.RenderDir != "" {
+		return result, false
+	}
+	if !calibrationDue(cfg) {
+		result, err := calibration.Load(cfg.CalibrationPath)
+		if err != nil {
+			fmt.Fprintf(w, "Warning: %v; using the default grid size (-recalibrate measures again)\n", err)
+			return result, false
+		}
+		return result, true
+	}
+
+	fmt.Fprintf(w, "Calibrating the grid size for this machine (%s of physics per frame)...\n", calibration.Budget(cfg))
+	result, err := calibrate(w)
+	if err != nil {
+		fmt.Fprintf(w, "Warning: calibration failed: %v; using the default grid size\n", err)
+		return result, false
+	}
+	fmt.Fprintf(w, "Recommended: %dx%d grid on the %s", result.Width, result.Depth, result.Processor())
+	if err := calibration.Save(cfg.CalibrationPath, result); err != nil {
+		fmt.Fprintf(w, " (not saved: %v)\n", err)
+	} else {
+		fmt.Fprintf(w, ", saved to %s\n", cfg.CalibrationPath)
+	}
+	return result, true
+}
+
+// calibrate times particle-mesh steps of the default setup on each grid
+// size, on the CPU and then the GPU, and recommends the largest grid that
+// keeps to the physics budget of the configured frame rate. The GPU is
+// left out if it fails. The session's configuration and compute mode are
+// restored afterwards
+func calibrate(w io.Writer) (calibration.Result, error) {
+	saved, savedGPU, savedMode := cfg, useGPU, computeMode
+	defer func() { cfg, useGPU, computeMode = saved, savedGPU, savedMode }()
+
+	// The default setup, with the frame rate and GPU settings of this session
+	base := config.DefaultConfig()
+	base.Seed = 1 // The same particles on every grid
+	base.TargetFPS, base.PhysicsBudget = saved.TargetFPS, saved.PhysicsBudget
+	base.GPUBackend, base.ShaderCacheDir, base.GLDebug = saved.GPUBackend, saved.ShaderCacheDir, saved.GLDebug
+	budget := calibration.Budget(base)
+
+	// One context serves every grid size, so the hidden window is not
+	// reopened for each
+	var shared *gpu.GPU
+	defer func() {
+		if shared != nil {
+			_ = CleanupGPU(shared)
+		}
+	}()
+	deltaTime := float32(1.0 / 60)
+	samples, failed := calibration.Measure([]string{config.ComputeCPU, config.ComputeGPU}, calibration.Sizes, budget, func(processor string, size int) (time.Duration, error) {
+		c := base.Clone()
+		c.SimulationWidth, c.SimulationDepth = size, size
+		cfg = c
+		setComputeMode(nil, processor)
+		sim := NewSimulation()
+		sim.gpu = shared
+		defer func() {
+			shared = sim.gpu
+			if sim.cuda != nil {
+				_ = sim.cuda.Close()
+			}
+		}()
+
+		times := make([]time.Duration, 0, calibration.Steps)
+		for i := 0; i < calibration.WarmupSteps+calibration.Steps; i++ {
+			start := time.Now()
+			sim.Step(deltaTime)
+			if useGPU && sim.fallbackToCPU {
+				if sim.lastGPUError != nil {
+					return 0, sim.lastGPUError
+				}
+				return 0, errors.New("fell back to the CPU")
+			}
+			if i >= calibration.WarmupSteps {
+				times = append(times, time.Since(start))
+			}
+		}
+		median := calibration.Median(times)
+		fmt.Fprintf(w, "  %s %dx%d: %.1f ms per step\n", processor, size, size, float64(median.Microseconds())/1000)
+		return median, nil
+	})
+	for processor, err := range failed {
+		fmt.Fprintf(w, "  %s left out: %v\n", processor, err)
+	}
+
+	result, err := calibration.Recommend(samples, budget)
+	if err != nil {
+		return result, err
+	}
+	result.TargetFPS, result.Particles = base.TargetFPS, base.NumParticles
+	return result, nil
+}
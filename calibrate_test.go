@@ -0,0 +1,70 @@
+//go:build !js && !android
+
+package main
+
+import (
+	"io"
+	"os"
+	"path/filepath"
+	"relativity_simulation_2d/internal/calibration"
+	"relativity_simulation_2d/internal/config"
+	"testing"
+)
+
+// TestCalibrationDue tests that only windowed sessions without a
+// calibration file, or asked to replace it, measure the hardware
+func TestCalibrationDue(t *testing.T) {
+	c := config.DefaultConfig()
+	c.CalibrationPath = filepath.Join(t.TempDir(), "calibration.json")
+	if !calibrationDue(c) {
+		t.Error("Expected the first launch to calibrate")
+	}
+	if err := os.WriteFile(c.CalibrationPath, []byte("{}"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	if calibrationDue(c) {
+		t.Error("Expected an existing calibration to be used")
+	}
+	c.Recalibrate = true
+	if !calibrationDue(c) {
+		t.Error("Expected -recalibrate to measure again")
+	}
+	c.Headless = true
+	if calibrationDue(c) {
+		t.Error("Expected headless runs not to calibrate")
+	}
+	c.Headless, c.CalibrationPath = false, ""
+	if calibrationDue(c) {
+		t.Error("Expected no calibration when it is off")
+	}
+}
+
+// TestCalibratedDefaults tests that a saved calibration is layered under
+// the command-line flags
+func TestCalibratedDefaults(t *testing.T) {
+	saved, savedDefaults := cfg, defaults
+	defer func() { cfg, defaults = saved, savedDefaults }()
+	path := filepath.Join(t.TempDir(), "calibration.json")
+	if err := calibration.Save(path, calibration.Result{Width: 512, Depth: 512}); err != nil {
+		t.Fatal(err)
+	}
+	cfg = config.DefaultConfig()
+	cfg.CalibrationPath = path
+
+	result, ok := loadCalibration(io.Discard)
+	if !ok {
+		t.Fatal("Expected the saved calibration")
+	}
+	defaults = func() *config.Config {
+		c := config.DefaultConfig()
+		result.Apply(c)
+		return c
+	}
+	loaded, err := loadConfig([]string{"-depth", "128"}, "")
+	if err != nil {
+		t.Fatalf("loadConfig failed: %v", err)
+	}
+	if loaded.SimulationWidth != 512 || loaded.SimulationDepth != 128 || loaded.UseGPU {
+		t.Errorf("Expected a calibrated 512-cell CPU width under the flag's depth, got %dx%d (GPU %v)", loaded.SimulationWidth, loaded.SimulationDepth, loaded.UseGPU)
+	}
+}
@@ -0,0 +1,278 @@
+//go:build !js
+
+package main
+
+import (
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"relativity_simulation_2d/internal/config"
+	"relativity_simulation_2d/internal/importer"
+	"relativity_simulation_2d/internal/physics"
+	"strconv"
+	"strings"
+)
+
+// errConfigFile marks loadConfig errors from the config file, which unlike
+// flag errors have not been printed
+var errConfigFile = errors.New("invalid config file")
+
+// defaults returns the bottom layer of loadConfig: the built-in defaults,
+// with the calibrated grid size and processor once main has them
+var defaults = config.DefaultConfig
+
+// loadConfig builds the configuration in layers: the defaults, the preset
+// (preset, or else the one named by -preset), the -config file, the platform
+// defaults and the command-line flags. Flag errors are returned already
+// printed by the flag package; an unknown preset is left for Validate
+func loadConfig(args []string, preset string) (*config.Config, error) {
+	cfg := defaults()
+	probe := cfg.Clone()
+	applyPlatformDefaults(probe)
+	if err := parseFlags(probe, args); err != nil {
+		return nil, err
+	}
+	if preset == "" {
+		preset = probe.Preset
+	}
+	if preset != "" {
+		_ = cfg.ApplyPreset(preset) // Validate reports an unknown name
+	}
+	if probe.ConfigPath != "" {
+		if err := cfg.LoadFile(probe.ConfigPath); err != nil {
+			return nil, fmt.Errorf("%w %s: %v", errConfigFile, probe.ConfigPath, err)
+		}
+	}
+	applyPlatformDefaults(cfg)
+	if err := parseFlags(cfg, args); err != nil {
+		return nil, err
+	}
+	if p, ok := config.LookupPreset(preset); ok {
+		cfg.Preset = p.Name
+	}
+	return cfg, nil
+}
+
+// listPresets prints the presets that -preset and F8 select
+func listPresets(w io.Writer) {
+	for _, p := range config.Presets {
+		fmt.Fprintf(w, "%-12s %s\n", p.Name, p.Description)
+	}
+}
+
+// parseFlags applies command-line overrides to the configuration
+func parseFlags(cfg *config.Config, args []string) error {
+	fs := flag.NewFlagSet("relativity_simulation", flag.ContinueOnError)
+
+	// Presets and config file
+	fs.StringVar(&cfg.Preset, "preset", cfg.Preset, "layer a preset under the other flags: "+strings.Join(config.PresetNames(), ", ")+" (cycle with F8)")
+	fs.BoolVar(&cfg.ListPresets, "list-presets", cfg.ListPresets, "list the presets and exit")
+	fs.StringVar(&cfg.ConfigPath, "config", cfg.ConfigPath, "JSON file of settings, keyed by Config field name, layered over the preset and reloaded while running")
+
+	// Simulation parameters
+	fs.IntVar(&cfg.NumParticles, "particles", cfg.NumParticles, "number of particles")
+	fs.IntVar(&cfg.SimulationWidth, "width", cfg.SimulationWidth, "simulation grid width")
+	fs.IntVar(&cfg.SimulationDepth, "depth", cfg.SimulationDepth, "simulation grid depth")
+	fs.Float64Var(&cfg.CellSize, "cell-size", cfg.CellSize, "physical size of a grid cell; the domain spans width×depth cells of it, so more cells at a smaller size refine the same domain")
+	fs.Float64Var(&cfg.GravitationalConstant, "G", cfg.GravitationalConstant, "gravitational constant")
+	fs.Int64Var(&cfg.Seed, "seed", cfg.Seed, "random seed for particle initialization (0 = random)")
+	fs.StringVar(&cfg.Precision, "precision", cfg.Precision, "CPU grid/FFT precision (float64 or float32)")
+	fs.StringVar(&cfg.Solver, "solver", cfg.Solver, "force solver (pm, direct or direct-gpu; direct summation is limited to small particle counts)")
+	fs.Float64Var(&cfg.Softening, "softening", cfg.Softening, "softening length in cells for the direct solver")
+	fs.BoolVar(&cfg.IncrementalDeposit, "incremental-deposit", cfg.IncrementalDeposit, "update the PM density grid by moving only the particles that changed (faster for few particles on large grids)")
+	fs.BoolVar(&cfg.SparseGrid, "sparse-grid", cfg.SparseGrid, "deposit and take PM gradients only over the grid tiles holding mass (for particles in a small part of a large domain)")
+	fs.BoolVar(&cfg.CropSolve, "crop-solve", cfg.CropSolve, "with -sparse-grid, solve the PM potential in a padded power-of-two window around the particles")
+	fs.IntVar(&cfg.CropPadding, "crop-padding", cfg.CropPadding, "empty cells kept around the particles by -crop-solve (0 = the extent of the particles)")
+	fs.BoolVar(&cfg.Deterministic, "deterministic", cfg.Deterministic, "bit-identical runs for a -seed at any -procs: CPU only, fixed-point mass deposition")
+	fs.IntVar(&cfg.BlockLevels, "block-levels", cfg.BlockLevels, "give the direct solver block time steps down to dt/2^N for particles deep in wells (0 = one shared step)")
+	fs.Float64Var(&cfg.EncounterRadius, "encounter-radius", cfg.EncounterRadius, "sub-step pairs closer than this many cells in the direct solver (0 = disabled)")
+	fs.Float64Var(&cfg.FrameOmega, "frame-omega", cfg.FrameOmega, "integrate in a frame rotating at this angular velocity about +Y, adding Coriolis and centrifugal forces (0 = inertial)")
+	fs.Float64Var(&cfg.SpongeWidth, "sponge", cfg.SpongeWidth, "depth in cells of a layer along the domain edges that damps velocities, for isolated systems (0 = none)")
+	fs.Float64Var(&cfg.SpongeStrength, "sponge-strength", cfg.SpongeStrength, "damping rate per unit time at the domain edge (0 = default)")
+	fs.BoolVar(&cfg.ImageCorrection, "image-correction", cfg.ImageCorrection, "cancel the leading-order pull of the periodic images on PM forces, for isolated systems")
+	fs.IntVar(&cfg.LyapunovParticles, "lyapunov", cfg.LyapunovParticles, "follow this many particles with perturbed shadows and estimate their Lyapunov exponents (0 = off)")
+	fs.StringVar(&cfg.RadiusModel, "radius-model", cfg.RadiusModel, "physical particle radius: density (spheres of -particle-density), fixed (-particle-radius) or off (points)")
+	fs.Float64Var(&cfg.ParticleDensity, "particle-density", cfg.ParticleDensity, "particle density for the density radius model")
+	fs.Float64Var(&cfg.ParticleRadius, "particle-radius", cfg.ParticleRadius, "particle radius for the fixed radius model")
+	fs.StringVar(&cfg.Scenario, "scenario", cfg.Scenario, "initial conditions: random, three-body for tracers around a binary with its Lagrange points marked, or tidal-disruption for a cluster on a plunging orbit (particle count = -particles)")
+	fs.IntVar(&cfg.TracerSheet, "tracer-sheet", cfg.TracerSheet, "lay a sheet of this many massless tracers per side over the domain and draw it as a deforming mesh (0 = off)")
+	fs.StringVar(&cfg.ImportPath, "ic", cfg.ImportPath, "load initial particles from this file instead of generating them")
+	fs.StringVar(&cfg.ImportFormat, "ic-format", cfg.ImportFormat, "initial conditions format (auto, csv, gadget or tipsy)")
+	fs.BoolVar(&cfg.ImportPlaneXY, "ic-plane-xy", cfg.ImportPlaneXY, "map the file's x-y plane onto the simulation's x-z plane")
+	fs.BoolVar(&cfg.UseGPU, "gpu", cfg.UseGPU, "use GPU acceleration for the Poisson solver")
+	fs.StringVar(&cfg.ComputeMode, "compute", cfg.ComputeMode, "compute mode (auto, cpu or gpu; G cycles it at runtime; default follows -gpu)")
+	fs.StringVar(&cfg.GPUBackend, "gpu-backend", cfg.GPUBackend, "GPU backend (gl or cuda; cuda needs a build with -tags cuda)")
+	fs.StringVar(&cfg.ShaderCacheDir, "shader-cache", cfg.ShaderCacheDir, "directory for compiled GPU programs reused across runs (empty = compile every run)")
+	fs.StringVar(&cfg.CalibrationPath, "calibration", cfg.CalibrationPath, "file of the grid size and processor measured for this machine, used as defaults and measured on first launch (empty = off)")
+	fs.BoolVar(&cfg.Recalibrate, "recalibrate", cfg.Recalibrate, "measure the grid sizes again and replace the calibration file")
+	fs.StringVar(&cfg.GLDebug, "gl-debug", cfg.GLDebug, "lowest severity of OpenGL driver messages logged: off, high, medium, low or all")
+	fs.IntVar(&cfg.GPUDevice, "gpu-device", cfg.GPUDevice, "GPU to run OpenGL on, as numbered by -list-gpus (0 = driver default)")
+	fs.BoolVar(&cfg.ListGPUs, "list-gpus", cfg.ListGPUs, "list the available GPUs and exit")
+	fs.BoolVar(&cfg.StartPaused, "paused", cfg.StartPaused, "start with the simulation paused")
+	fs.BoolVar(&cfg.TouchControls, "touch", cfg.TouchControls, "enable touch controls: drag to look, pinch to zoom, tap to spawn a particle")
+	fs.StringVar(&cfg.Palette, "palette", cfg.Palette, "color palette (default, deuteranopia or high-contrast)")
+	fs.Float64Var(&cfg.UIScale, "ui-scale", cfg.UIScale, "scale of the HUD text and layout (0.5 to 4)")
+	fs.StringVar(&cfg.ParticleColoring, "particle-color", cfg.ParticleColoring, "particle coloring: uniform, binding (bound or unbound), density, lyapunov or group (cycle with B)")
+	fs.StringVar(&cfg.GridColoring, "grid-color", cfg.GridColoring, "grid coloring: potential, or the particle flow's divergence, vorticity or shear (cycle with V)")
+	fs.IntVar(&cfg.FlowInterval, "flow-interval", cfg.FlowInterval, "steps between updates of the velocity flow maps")
+	fs.Float64Var(&cfg.DisplayScale, "display-scale", cfg.DisplayScale, "scale of the drawn particle radius relative to the physical radius")
+	fs.Float64Var(&cfg.MinDisplayRadius, "min-display-radius", cfg.MinDisplayRadius, "smallest drawn particle radius")
+	fs.BoolVar(&cfg.Culling, "culling", cfg.Culling, "skip particles whose drawn spheres lie outside the view")
+	fs.Float64Var(&cfg.CullPixels, "cull-pixels", cfg.CullPixels, "skip particles drawn with a radius under this many pixels (0 = off)")
+	fs.IntVar(&cfg.RenderBudget, "render-budget", cfg.RenderBudget, fmt.Sprintf("particles drawn at most, sub-sampled by screen density (0 = all, else at least %d; adjust with [ and ])", config.MinRenderBudget))
+	fs.StringVar(&cfg.Layers, "layers", cfg.Layers, "scene layers shown, in drawing order: "+strings.Join(config.SceneLayers, ", ")+" (toggle with Shift+1-9, 0)")
+	fs.IntVar(&cfg.TrailLength, "trail-length", cfg.TrailLength, "positions kept in each particle trail")
+	fs.IntVar(&cfg.TrailParticles, "trail-particles", cfg.TrailParticles, "particles with trails, from the first")
+	fs.IntVar(&cfg.VectorStride, "vector-stride", cfg.VectorStride, "cells between arrows of the acceleration field")
+	fs.IntVar(&cfg.ContourLevels, "contour-levels", cfg.ContourLevels, "contour lines of the potential")
+	fs.Float64Var(&cfg.HaloThreshold, "halo-threshold", cfg.HaloThreshold, "cell mass, relative to the mean, of the density peaks ringed as halos")
+	fs.IntVar(&cfg.MinimapSize, "minimap-size", cfg.MinimapSize, "side of the minimap in pixels at UI scale 1")
+	fs.BoolVar(&cfg.Stereo, "stereo", cfg.Stereo, "render side-by-side stereo for 3D displays and viewers (toggle with F3)")
+	fs.Float64Var(&cfg.EyeSeparation, "eye-separation", cfg.EyeSeparation, "distance between the stereo eyes in simulation units")
+	fs.BoolVar(&cfg.Sonify, "sonify", cfg.Sonify, "play the potential well depth and accretion events as sound (toggle with M)")
+	fs.BoolVar(&cfg.ShowPlots, "plots", cfg.ShowPlots, "show live KE/PE/virial ratio plots (toggle with F2)")
+	fs.BoolVar(&cfg.ShowPhaseSpace, "phase-space", cfg.ShowPhaseSpace, "show live x-vx and z-vz phase-space plots (toggle with F6)")
+	fs.BoolVar(&cfg.ShowProfiles, "profiles", cfg.ShowProfiles, "show live radial profiles of surface density and velocities (toggle with F7)")
+	fs.BoolVar(&cfg.ShowRenderStats, "render-stats", cfg.ShowRenderStats, "show draw calls, vertices and time per scene layer, and what limits the frame rate (toggle with F10)")
+	fs.BoolVar(&cfg.Explain, "explain", cfg.Explain, "walk through each particle-mesh step stage by stage with annotations and the intermediate fields, one step per pass (toggle with F1, N for the next stage)")
+	fs.Float64Var(&cfg.ExplainStageDuration, "explain-stage", cfg.ExplainStageDuration, "seconds each stage of -explain is shown")
+	fs.IntVar(&cfg.TargetFPS, "fps", cfg.TargetFPS, "frame rate cap of the window (0 = uncapped; toggle uncapped with F4)")
+	fs.BoolVar(&cfg.VSync, "vsync", cfg.VSync, "wait for the display's vertical sync (toggle with F5)")
+	fs.IntVar(&cfg.IdleFPS, "idle-fps", cfg.IdleFPS, "frame rate cap while paused or in the background, with the physics stopped (0 = run at full rate)")
+	fs.Float64Var(&cfg.DeltaSmoothing, "dt-smoothing", cfg.DeltaSmoothing, "weight of the previous average in the moving average of frame times passed to the physics (0 = none)")
+	fs.Float64Var(&cfg.DeltaSpikeFactor, "dt-spike-factor", cfg.DeltaSpikeFactor, "replace frame times above this multiple of the average before they reach the physics (0 = keep them)")
+	fs.BoolVar(&cfg.AdaptiveQuality, "adaptive-quality", cfg.AdaptiveQuality, "lower grid detail, frame rate, then GPU use when frames run slow")
+	fs.BoolVar(&cfg.PowerSaver, "power-saver", cfg.PowerSaver, "run at reduced frame rate and grid detail to save power")
+	fs.Float64Var(&cfg.PhysicsBudget, "physics-budget", cfg.PhysicsBudget, "milliseconds of physics per frame before diagnostics, view grid and deposit are reduced (0 = none)")
+	fs.BoolVar(&cfg.PreviewPhysics, "preview-physics", cfg.PreviewPhysics, "step the CPU physics on a half-resolution grid while the camera moves or settings are reloaded")
+
+	// Headless run settings
+	fs.BoolVar(&cfg.Headless, "headless", cfg.Headless, "run without a window")
+	fs.IntVar(&cfg.MaxSteps, "steps", cfg.MaxSteps, "number of steps to run in headless mode (0 = until interrupted)")
+	fs.Float64Var(&cfg.Timeout, "timeout", cfg.Timeout, "stop a headless run gracefully after this many seconds (0 = no limit)")
+	timeStep := float64(cfg.FixedTimeStep)
+	fs.Float64Var(&timeStep, "dt", timeStep, "fixed time step in headless mode")
+	fs.StringVar(&cfg.CheckpointPath, "checkpoint", cfg.CheckpointPath, "write a final checkpoint to this file on shutdown")
+	fs.StringVar(&cfg.NBodyPath, "nbody-out", cfg.NBodyPath, "write the final particles to this file in an N-body format")
+	fs.StringVar(&cfg.NBodyFormat, "nbody-format", cfg.NBodyFormat, "N-body snapshot format (gadget or raw)")
+	fs.StringVar(&cfg.FlowDir, "flow-out", cfg.FlowDir, "write velocity divergence, vorticity and shear maps to this directory every -flow-interval steps (headless)")
+	fs.StringVar(&cfg.ProfilePath, "profile-out", cfg.ProfilePath, "write the radial profile of the final state to this CSV file (headless)")
+	fs.StringVar(&cfg.DiagnosticsPath, "diagnostics", cfg.DiagnosticsPath, "write diagnostics CSV to this file")
+	fs.IntVar(&cfg.DiagnosticsInterval, "diag-interval", cfg.DiagnosticsInterval, "steps between diagnostics records")
+	fs.Float64Var(&cfg.ProgressInterval, "progress-interval", cfg.ProgressInterval, "seconds between progress reports in headless mode (0 = disabled)")
+	fs.StringVar(&cfg.ProgressPath, "progress-file", cfg.ProgressPath, "write JSON progress to this file in headless mode")
+
+	// Scheduling
+	fs.IntVar(&cfg.MaxProcs, "procs", cfg.MaxProcs, "GOMAXPROCS, the threads running Go code at once (0 = one per CPU)")
+	fs.BoolVar(&cfg.PinCores, "pin-cores", cfg.PinCores, "pin the simulation to the performance cores of hybrid CPUs (Linux)")
+	fs.BoolVar(&cfg.HugePages, "huge-pages", cfg.HugePages, "back grids of 1024x1024 cells and more with transparent huge pages (Linux)")
+	fs.IntVar(&cfg.HeadlessNice, "nice", cfg.HeadlessNice, "lower the priority of headless runs by this niceness, 1 to 19 (Linux)")
+
+	// Crash reporting
+	fs.StringVar(&cfg.CrashReportDir, "crash-dir", cfg.CrashReportDir, "directory for crash reports")
+
+	// Run output
+	fs.StringVar(&cfg.RunDir, "run-dir", cfg.RunDir, "directory for per-run output directories holding a config copy, logs, snapshots, exports and screenshots, with a latest link (empty = outputs where configured)")
+
+	// Sanity checks
+	fs.IntVar(&cfg.GuardInterval, "guard-interval", cfg.GuardInterval, "steps between NaN/Inf and energy explosion checks that pause and report (0 = off)")
+	fs.Float64Var(&cfg.GuardEnergyGrowth, "guard-energy-growth", cfg.GuardEnergyGrowth, "kinetic energy growth over the earlier peak that counts as an explosion")
+	fs.IntVar(&cfg.HealthInterval, "health-interval", cfg.HealthInterval, "steps between health checks that show a score and advice such as \"reduce dt\" (0 = off)")
+	fs.IntVar(&cfg.ReversalSteps, "reversal-steps", cfg.ReversalSteps, "steps the time-reversal test (R key) runs forward and then back")
+	fs.BoolVar(&cfg.TimeReversal, "time-reversal", cfg.TimeReversal, "run the time-reversal test, print the positional error and exit (headless)")
+
+	// Soak runs
+	fs.BoolVar(&cfg.Soak, "soak", cfg.Soak, "sample memory and energy drift through a long run and stop it with a report when one trends upward (headless)")
+	fs.Float64Var(&cfg.SoakInterval, "soak-interval", cfg.SoakInterval, "seconds between soak samples")
+	fs.StringVar(&cfg.SoakPath, "soak-out", cfg.SoakPath, "CSV file for the soak samples (empty = none)")
+	fs.Float64Var(&cfg.SoakRSSGrowth, "soak-rss", cfg.SoakRSSGrowth, "resident memory growth in MB per hour that stops a soak run (0 = unwatched)")
+	fs.Float64Var(&cfg.SoakGPUGrowth, "soak-gpu", cfg.SoakGPUGrowth, "GPU memory growth in MB per hour that stops a soak run (0 = unwatched)")
+	fs.Float64Var(&cfg.SoakAllocGrowth, "soak-alloc", cfg.SoakAllocGrowth, "allocation rate growth in MB/s per hour that stops a soak run (0 = unwatched)")
+	fs.Float64Var(&cfg.SoakDriftGrowth, "soak-drift", cfg.SoakDriftGrowth, "relative energy drift per hour that stops a soak run (0 = unwatched)")
+
+	// Radial profiles
+	fs.IntVar(&cfg.ProfileBins, "profile-bins", cfg.ProfileBins, "radial bins of the profiles")
+	fs.StringVar(&cfg.ProfileCenter, "profile-center", cfg.ProfileCenter, "center of the radial profiles: densest or mass")
+
+	// Slow motion
+	fs.StringVar(&cfg.SlowMotionTrigger, "slowmo", cfg.SlowMotionTrigger, "slow the simulation down when particles at the watched particle or region approach (closer than -slowmo-distance) or make contact (radii touch); mark with K or Shift+K")
+	fs.Float64Var(&cfg.SlowMotionDistance, "slowmo-distance", cfg.SlowMotionDistance, "separation in cells that counts as a close approach")
+	fs.Float64Var(&cfg.SlowMotionScale, "slowmo-scale", cfg.SlowMotionScale, "time scale during slow motion")
+	fs.Float64Var(&cfg.SlowMotionDuration, "slowmo-duration", cfg.SlowMotionDuration, "seconds of slow motion per trigger")
+	fs.IntVar(&cfg.SlowMotionParticle, "slowmo-particle", cfg.SlowMotionParticle, "watch this particle for slow motion triggers (-1 = none)")
+	fs.Func("slowmo-region", "watch the circle x,z,radius (in cells) for slow motion triggers", func(value string) error {
+		x, z, r, err := parseCircle(value)
+		if err != nil {
+			return err
+		}
+		cfg.SlowMotionRegionX, cfg.SlowMotionRegionZ, cfg.SlowMotionRadius = x, z, r
+		return nil
+	})
+
+	// Particle groups
+	fs.Func("group", "tag the particles starting in a circle as a named group, name=x,z,radius (repeatable)", func(value string) error {
+		if _, err := config.ParseGroupRegions(value); err != nil {
+			return err
+		}
+		if cfg.GroupRegions != "" {
+			value = cfg.GroupRegions + ";" + value
+		}
+		cfg.GroupRegions = value
+		return nil
+	})
+
+	// Mesh export
+	fs.StringVar(&cfg.MeshPath, "mesh-out", cfg.MeshPath, "write the deformed grid to this .obj or .gltf file at the end of a headless run, numbered by step on F9")
+	fs.BoolVar(&cfg.MeshParticles, "mesh-particles", cfg.MeshParticles, "include the particles as spheres in exported meshes")
+
+	// Frame overlay
+	fs.StringVar(&cfg.Overlay, "overlay", cfg.Overlay, "stamp these items on drawn and rendered frames, joined by commas: time, step, params")
+	fs.StringVar(&cfg.OverlayLabel, "overlay-label", cfg.OverlayLabel, "stamp this label on drawn and rendered frames, such as a credit or watermark")
+
+	// Replay recording and offline rendering
+	fs.StringVar(&cfg.RecordDir, "record", cfg.RecordDir, "record replay frames of a headless run to this directory every -record-interval steps")
+	fs.IntVar(&cfg.RecordInterval, "record-interval", cfg.RecordInterval, "steps between replay frames")
+	fs.StringVar(&cfg.RenderDir, "render", cfg.RenderDir, "render the replay in this directory to PNG images instead of running the simulation")
+	fs.StringVar(&cfg.RenderOut, "render-out", cfg.RenderOut, "directory for rendered images (default: render inside the replay directory)")
+	fs.IntVar(&cfg.RenderWidth, "render-width", cfg.RenderWidth, "width of rendered images in pixels")
+	fs.IntVar(&cfg.RenderHeight, "render-height", cfg.RenderHeight, "height of rendered images in pixels")
+	fs.IntVar(&cfg.Supersample, "supersample", cfg.Supersample, "samples per pixel along each axis of rendered images, averaged for antialiasing")
+	fs.StringVar(&cfg.RenderStereo, "render-stereo", cfg.RenderStereo, "render stereo images: side-by-side or anaglyph (default: mono)")
+
+	// Binaries
+	fs.Float64Var(&cfg.BinarySeparation, "binaries", cfg.BinarySeparation, "detect pairs whose orbit stays within this many cells as binaries (0 = off)")
+	fs.IntVar(&cfg.BinaryInterval, "binary-interval", cfg.BinaryInterval, "steps between binary detections")
+	fs.StringVar(&cfg.BinaryLogPath, "binary-log", cfg.BinaryLogPath, "write binary formation and disruption events with their orbital elements to this CSV file")
+
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	cfg.FixedTimeStep = float32(timeStep)
+
+	return nil
+}
+
+// parseCircle parses a circle given as "x,z,radius"
+func parseCircle(value string) (x, z, r float64, err error) {
+	fields := strings.Split(value, ",")
+	if len(fields) != 3 {
+		return 0, 0, 0, fmt.Errorf("invalid circle: %q (want x,z,radius)", value)
+	}
+	var v [3]float64
+	for i, field := range fields {
+		if v[i], err = strconv.ParseFloat(strings.TrimSpace(field), 64); err != nil {
+			return 0, 0, 0, fmt.Errorf("invalid circle: %q (want x,z,radius)", value)
+		}
+	}
+	return v[0], v[1], v[2], nil
+}
+
+// loadInitialConditions imports the particles named by cfg.ImportPath
+func loadInitialConditions(cfg *config.Config) ([]*physics.Particle, error) {
+	format, err := importer.ParseFormat(cfg.ImportFormat)
+	if err != nil {
+		return nil, err
+	}
+	return importer.Load(cfg.ImportPath, format, importer.Options{PlaneXY: cfg.ImportPlaneXY})
+}
@@ -0,0 +1,74 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"os"
+	"path/filepath"
+	"relativity_simulation_2d/internal/compare"
+	"strings"
+)
+
+func main() {
+	labelA := flag.String("label-a", "", "name of the first run in the report (default: its file name)")
+	labelB := flag.String("label-b", "", "name of the second run in the report (default: its file name)")
+	columns := flag.String("columns", "", "comma-separated quantities to compare (default: all both files have)")
+	out := flag.String("out", "comparison.html", "report file, .html or .md (Markdown writes its plots beside it as SVG)")
+	tolerance := flag.Float64("tolerance", 0, "exit with status 1 if a quantity's relative difference exceeds this (0 = report only)")
+	flag.Usage = func() {
+		fmt.Fprintf(flag.CommandLine.Output(), "Usage: %s [flags] a.csv b.csv\n", filepath.Base(os.Args[0]))
+		flag.PrintDefaults()
+	}
+	flag.Parse()
+	if flag.NArg() != 2 {
+		flag.Usage()
+		os.Exit(2)
+	}
+	if *tolerance < 0 {
+		fmt.Fprintln(os.Stderr, "tolerance must not be negative")
+		os.Exit(2)
+	}
+
+	pathA, pathB := flag.Arg(0), flag.Arg(1)
+	a, err := compare.LoadTable(pathA)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "%s: %v\n", pathA, err)
+		os.Exit(1)
+	}
+	b, err := compare.LoadTable(pathB)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "%s: %v\n", pathB, err)
+		os.Exit(1)
+	}
+	var names []string
+	if *columns != "" {
+		for _, name := range strings.Split(*columns, ",") {
+			names = append(names, strings.TrimSpace(name))
+		}
+	}
+	report, err := compare.Compare(a, b, names)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(1)
+	}
+	report.LabelA, report.LabelB = label(*labelA, pathA), label(*labelB, pathB)
+	report.Tolerance = *tolerance
+
+	if err := compare.SaveReport(*out, report); err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(1)
+	}
+	fmt.Printf("Wrote %s (%d quantities over %d samples)\n", *out, len(report.Diffs), report.Samples)
+	if failed := report.Failed(); len(failed) > 0 {
+		fmt.Fprintf(os.Stderr, "Differences above %g in %s\n", report.Tolerance, strings.Join(failed, ", "))
+		os.Exit(1)
+	}
+}
+
+// label returns name, or the base name of path without its extension
+func label(name, path string) string {
+	if name != "" {
+		return name
+	}
+	return strings.TrimSuffix(filepath.Base(path), filepath.Ext(path))
+}
@@ -0,0 +1,69 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"os"
+	"relativity_simulation_2d/internal/sweep"
+	"relativity_simulation_2d/internal/verification"
+	"runtime"
+)
+
+func main() {
+	defaults := verification.DefaultForceConfig()
+	grids := flag.String("grids", "64,128,256", "comma-separated square grid sizes")
+	softenings := flag.String("softening", "0,0.5,1,2", "comma-separated direct-summation softening lengths in cells")
+	particles := flag.Int("particles", defaults.Particles, "particles per configuration")
+	configs := flag.Int("configs", defaults.Configurations, "random configurations per grid size")
+	cluster := flag.Float64("cluster", defaults.ClusterFraction, "cluster radius as a fraction of the grid size")
+	g := flag.Float64("G", defaults.G, "gravitational constant")
+	seed := flag.Int64("seed", defaults.Seed, "random seed for the configurations")
+	workers := flag.Int("workers", runtime.NumCPU(), "goroutines for direct summation")
+	out := flag.String("out", "", "also write the results as CSV to this file")
+	flag.Parse()
+
+	cfg := defaults
+	var err error
+	if cfg.GridSizes, err = sweep.ParseIntList(*grids); err != nil {
+		fmt.Fprintf(os.Stderr, "invalid -grids: %v\n", err)
+		os.Exit(2)
+	}
+	if cfg.Softenings, err = sweep.ParseFloatList(*softenings); err != nil {
+		fmt.Fprintf(os.Stderr, "invalid -softening: %v\n", err)
+		os.Exit(2)
+	}
+	cfg.Particles, cfg.Configurations, cfg.ClusterFraction = *particles, *configs, *cluster
+	cfg.G, cfg.Seed, cfg.Workers = *g, *seed, *workers
+
+	results, err := verification.CompareForces(cfg)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(2)
+	}
+
+	fmt.Printf("%6s %10s %8s %12s %12s\n", "grid", "softening", "samples", "rms error", "max error")
+	for _, r := range results {
+		fmt.Printf("%6d %10g %8d %12.4g %12.4g\n", r.GridSize, r.Softening, r.Samples, r.RMSError, r.MaxError)
+	}
+
+	if *out != "" {
+		if err := writeResults(*out, results); err != nil {
+			fmt.Fprintln(os.Stderr, err)
+			os.Exit(1)
+		}
+		fmt.Printf("Wrote %s\n", *out)
+	}
+}
+
+// writeResults writes the benchmark results as CSV to path
+func writeResults(path string, results []verification.ForceResult) error {
+	file, err := os.Create(path)
+	if err != nil {
+		return fmt.Errorf("failed to create %s: %v", path, err)
+	}
+	if err := verification.WriteForceResults(file, results); err != nil {
+		_ = file.Close()
+		return fmt.Errorf("failed to write %s: %v", path, err)
+	}
+	return file.Close()
+}
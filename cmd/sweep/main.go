@@ -0,0 +1,167 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"os/exec"
+	"os/signal"
+	"path/filepath"
+	"relativity_simulation_2d/internal/sweep"
+	"runtime"
+	"strings"
+	"syscall"
+	"time"
+)
+
+// shutdownGrace is how long an interrupted run may take to write its final
+// diagnostics before it is killed
+const shutdownGrace = 30 * time.Second
+
+func main() {
+	bin := flag.String("bin", "./relativity_simulation", "path to the simulation binary")
+	gValues := flag.String("G", "1.0", "comma-separated gravitational constants")
+	particles := flag.String("particles", "1000", "comma-separated particle counts")
+	seeds := flag.String("seeds", "1", "comma-separated random seeds")
+	ensemble := flag.Int("ensemble", 0, "run K consecutive seeds per configuration (overrides -seeds)")
+	seedBase := flag.Int64("seed-base", 1, "first seed in ensemble mode")
+	steps := flag.Int("steps", 1000, "steps per run")
+	workers := flag.Int("workers", runtime.NumCPU(), "number of runs executed in parallel")
+	out := flag.String("out", "sweep.csv", "combined summary CSV")
+	dir := flag.String("dir", "sweep_runs", "directory for per-run diagnostics")
+	timeout := flag.Duration("timeout", 0, "wall-clock limit per run, e.g. 10m (0 = none)")
+	aggregate := flag.String("aggregate", "", "write per-configuration ensemble statistics to this CSV (default ensemble.csv in ensemble mode)")
+	flag.Parse()
+
+	matrix, err := parseMatrix(*gValues, *particles, *seeds)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(2)
+	}
+	if *ensemble < 0 {
+		fmt.Fprintln(os.Stderr, "ensemble size must not be negative")
+		os.Exit(2)
+	}
+	if *ensemble > 0 {
+		matrix.Seeds = sweep.SeedRange(*seedBase, *ensemble)
+		if *aggregate == "" {
+			*aggregate = "ensemble.csv"
+		}
+	}
+	if *steps <= 0 {
+		fmt.Fprintln(os.Stderr, "steps must be positive")
+		os.Exit(2)
+	}
+	if err := os.MkdirAll(*dir, 0o755); err != nil {
+		fmt.Fprintf(os.Stderr, "failed to create run directory: %v\n", err)
+		os.Exit(1)
+	}
+
+	// Ctrl-C stops queued jobs and asks running ones to shut down gracefully
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
+	jobs := matrix.Jobs()
+	fmt.Printf("Running %d jobs on %d workers\n", len(jobs), *workers)
+
+	results := sweep.Run(ctx, jobs, *workers, func(ctx context.Context, job sweep.Job) sweep.Result {
+		return runJob(ctx, *bin, *dir, *steps, *timeout, job)
+	})
+
+	if err := writeCSV(*out, func(w io.Writer) error { return sweep.WriteResults(w, results) }); err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(1)
+	}
+	if *aggregate != "" {
+		ensembles := sweep.Aggregate(results)
+		if err := writeCSV(*aggregate, func(w io.Writer) error { return sweep.WriteEnsembles(w, ensembles) }); err != nil {
+			fmt.Fprintln(os.Stderr, err)
+			os.Exit(1)
+		}
+		for _, e := range ensembles {
+			fmt.Printf("G=%g N=%d: drift %.3g ± %.3g (%.1fσ) over %d runs\n",
+				e.G, e.Particles, e.KineticDrift.Mean, e.KineticDrift.StdDev,
+				e.KineticDrift.Significance(), e.KineticDrift.N)
+		}
+	}
+
+	failed := 0
+	for _, r := range results {
+		if r.Err != nil {
+			failed++
+		}
+	}
+	fmt.Printf("Wrote %s (%d ok, %d failed)\n", *out, len(results)-failed, failed)
+	if failed > 0 || ctx.Err() != nil {
+		os.Exit(1)
+	}
+}
+
+// parseMatrix builds the parameter matrix from comma-separated flag values
+func parseMatrix(gValues, particles, seeds string) (sweep.Matrix, error) {
+	var m sweep.Matrix
+	var err error
+	if m.GValues, err = sweep.ParseFloatList(gValues); err != nil {
+		return m, fmt.Errorf("invalid -G: %v", err)
+	}
+	if m.ParticleCounts, err = sweep.ParseIntList(particles); err != nil {
+		return m, fmt.Errorf("invalid -particles: %v", err)
+	}
+	if m.Seeds, err = sweep.ParseInt64List(seeds); err != nil {
+		return m, fmt.Errorf("invalid -seeds: %v", err)
+	}
+	if len(m.Jobs()) == 0 {
+		return m, fmt.Errorf("parameter matrix is empty")
+	}
+	return m, nil
+}
+
+// writeCSV creates path and writes its contents with write
+func writeCSV(path string, write func(w io.Writer) error) error {
+	file, err := os.Create(path)
+	if err != nil {
+		return fmt.Errorf("failed to create %s: %v", path, err)
+	}
+	if err := write(file); err != nil {
+		_ = file.Close()
+		return fmt.Errorf("failed to write %s: %v", path, err)
+	}
+	return file.Close()
+}
+
+// runJob runs one headless simulation process and summarizes its diagnostics.
+// When ctx is cancelled or the timeout passes, the process is sent SIGINT so
+// it stops after its current step, and killed if it does not exit in time
+func runJob(ctx context.Context, bin, dir string, steps int, timeout time.Duration, job sweep.Job) sweep.Result {
+	if timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, timeout)
+		defer cancel()
+	}
+
+	diagPath := filepath.Join(dir, fmt.Sprintf("run_%d.csv", job.ID))
+	cmd := exec.CommandContext(ctx, bin, job.Args(steps, diagPath)...)
+	cmd.Cancel = func() error { return cmd.Process.Signal(os.Interrupt) }
+	cmd.WaitDelay = shutdownGrace
+	var stderr bytes.Buffer
+	cmd.Stderr = &stderr
+
+	start := time.Now()
+	err := cmd.Run()
+	result := sweep.Result{Job: job, Duration: time.Since(start)}
+	if err != nil {
+		result.Err = fmt.Errorf("%v: %s", err, strings.TrimSpace(stderr.String()))
+		return result
+	}
+	if err := ctx.Err(); err != nil {
+		// The run shut down early; its partial diagnostics are not comparable
+		result.Err = fmt.Errorf("run stopped early: %w", err)
+		return result
+	}
+
+	result.Summary, result.Err = sweep.SummarizeDiagnostics(diagPath)
+	return result
+}
@@ -0,0 +1,234 @@
+//go:build js && wasm
+
+// Web runs the simulation in a browser: CPU physics compiled to WebAssembly
+// and a WebGL2 view of the curved grid and particles. Build it with
+// `make wasm` and serve build/web. Drag to orbit, scroll to zoom, Space to
+// pause and R to reset. Query parameters override the defaults, e.g.
+// index.html?particles=2000&grid=128&G=2&seed=3
+package main
+
+import (
+	"fmt"
+	"math"
+	"net/url"
+	"relativity_simulation_2d/internal/config"
+	"relativity_simulation_2d/internal/physics"
+	"relativity_simulation_2d/internal/renderer"
+	"relativity_simulation_2d/internal/simulation"
+	"strconv"
+	"syscall/js"
+)
+
+// fovY is the vertical field of view, matching the desktop camera
+const fovY = 65.0 * math.Pi / 180.0
+
+// orbit is a camera circling the origin
+type orbit struct {
+	yaw, pitch, distance float64
+}
+
+// eye returns the camera position
+func (o orbit) eye() physics.Vec3 {
+	return physics.NewVec3(
+		o.distance*math.Cos(o.pitch)*math.Sin(o.yaw),
+		o.distance*math.Sin(o.pitch),
+		o.distance*math.Cos(o.pitch)*math.Cos(o.yaw),
+	)
+}
+
+// app is the browser simulation and its view state
+type app struct {
+	cfg       *config.Config
+	sim       *simulation.Simulation
+	view      *webGLRenderer
+	canvas    js.Value
+	stats     js.Value
+	camera    orbit
+	paused    bool
+	dragging  bool
+	lastX     float64
+	lastY     float64
+	grid      []float32 // Reused grid vertex buffer
+	particles []float32 // Reused particle vertex buffer
+	frames    int
+	lastStats float64
+}
+
+func main() {
+	document := js.Global().Get("document")
+	canvas := document.Call("getElementById", "canvas")
+	stats := document.Call("getElementById", "stats")
+
+	cfg, err := configFromQuery(js.Global().Get("location").Get("search").String())
+	if err == nil {
+		err = cfg.Validate()
+	}
+	if err != nil {
+		showError(stats, err)
+		return
+	}
+
+	view, err := newWebGLRenderer(canvas)
+	if err != nil {
+		showError(stats, err)
+		return
+	}
+
+	a := &app{
+		cfg:    cfg,
+		sim:    simulation.NewSimulation(cfg),
+		view:   view,
+		canvas: canvas,
+		stats:  stats,
+		camera: orbit{yaw: math.Pi / 4, pitch: math.Atan(1 / math.Sqrt2), distance: 50 * math.Sqrt(3)},
+		paused: cfg.StartPaused,
+	}
+	a.bindInput()
+
+	var tick js.Func
+	tick = js.FuncOf(func(this js.Value, args []js.Value) any {
+		a.frame(args[0].Float())
+		js.Global().Call("requestAnimationFrame", tick)
+		return nil
+	})
+	js.Global().Call("requestAnimationFrame", tick)
+
+	select {} // Keep the Go runtime alive for the callbacks
+}
+
+// configFromQuery returns the default configuration with overrides from the
+// page's query string
+func configFromQuery(query string) (*config.Config, error) {
+	cfg := config.DefaultConfig()
+	cfg.SimulationWidth = 128
+	cfg.SimulationDepth = 128
+	cfg.NumParticles = 1000
+	cfg.UseGPU = false
+
+	values, err := url.ParseQuery(trimQuestionMark(query))
+	if err != nil {
+		return nil, fmt.Errorf("invalid query string: %v", err)
+	}
+	if v := values.Get("particles"); v != "" {
+		if cfg.NumParticles, err = strconv.Atoi(v); err != nil {
+			return nil, fmt.Errorf("invalid particles: %v", err)
+		}
+	}
+	if v := values.Get("grid"); v != "" {
+		n, err := strconv.Atoi(v)
+		if err != nil {
+			return nil, fmt.Errorf("invalid grid: %v", err)
+		}
+		cfg.SimulationWidth, cfg.SimulationDepth = n, n
+	}
+	if v := values.Get("G"); v != "" {
+		if cfg.GravitationalConstant, err = strconv.ParseFloat(v, 64); err != nil {
+			return nil, fmt.Errorf("invalid G: %v", err)
+		}
+	}
+	if v := values.Get("seed"); v != "" {
+		if cfg.Seed, err = strconv.ParseInt(v, 10, 64); err != nil {
+			return nil, fmt.Errorf("invalid seed: %v", err)
+		}
+	}
+	cfg.StartPaused = values.Get("paused") == "1"
+	return cfg, nil
+}
+
+// trimQuestionMark strips the leading "?" of location.search
+func trimQuestionMark(query string) string {
+	if len(query) > 0 && query[0] == '?' {
+		return query[1:]
+	}
+	return query
+}
+
+// showError replaces the stats line with an error message
+func showError(stats js.Value, err error) {
+	stats.Set("textContent", "Error: "+err.Error())
+	js.Global().Get("console").Call("error", err.Error())
+}
+
+// bindInput registers the mouse and keyboard handlers
+func (a *app) bindInput() {
+	a.canvas.Call("addEventListener", "pointerdown", js.FuncOf(func(this js.Value, args []js.Value) any {
+		a.dragging = true
+		a.lastX, a.lastY = args[0].Get("clientX").Float(), args[0].Get("clientY").Float()
+		a.canvas.Call("setPointerCapture", args[0].Get("pointerId"))
+		return nil
+	}))
+	a.canvas.Call("addEventListener", "pointerup", js.FuncOf(func(this js.Value, args []js.Value) any {
+		a.dragging = false
+		return nil
+	}))
+	a.canvas.Call("addEventListener", "pointermove", js.FuncOf(func(this js.Value, args []js.Value) any {
+		if !a.dragging {
+			return nil
+		}
+		x, y := args[0].Get("clientX").Float(), args[0].Get("clientY").Float()
+		a.camera.yaw -= (x - a.lastX) * 0.005
+		a.camera.pitch = math.Max(-1.5, math.Min(1.5, a.camera.pitch+(y-a.lastY)*0.005))
+		a.lastX, a.lastY = x, y
+		return nil
+	}))
+	a.canvas.Call("addEventListener", "wheel", js.FuncOf(func(this js.Value, args []js.Value) any {
+		args[0].Call("preventDefault")
+		a.camera.distance = math.Max(5, math.Min(1000, a.camera.distance*math.Exp(args[0].Get("deltaY").Float()*0.001)))
+		return nil
+	}), map[string]any{"passive": false})
+	js.Global().Get("window").Call("addEventListener", "keydown", js.FuncOf(func(this js.Value, args []js.Value) any {
+		switch args[0].Get("key").String() {
+		case " ":
+			a.paused = !a.paused
+			args[0].Call("preventDefault")
+		case "r", "R":
+			a.sim = simulation.NewSimulation(a.cfg)
+		}
+		return nil
+	}))
+}
+
+// frame steps the simulation once and redraws; now is the animation timestamp in milliseconds
+func (a *app) frame(now float64) {
+	if !a.paused {
+		a.sim.Step(a.cfg.FixedTimeStep)
+	}
+
+	// Match the drawing buffer to the displayed size
+	ratio := js.Global().Get("devicePixelRatio").Float()
+	width := int(a.canvas.Get("clientWidth").Float() * ratio)
+	height := int(a.canvas.Get("clientHeight").Float() * ratio)
+	if width <= 0 || height <= 0 {
+		return
+	}
+	if a.canvas.Get("width").Int() != width || a.canvas.Get("height").Int() != height {
+		a.canvas.Set("width", width)
+		a.canvas.Set("height", height)
+	}
+
+	var step int64
+	a.sim.ReadFrame(func(f *simulation.Frame) {
+		step = f.Step
+		a.grid = renderer.GridLineVertices(a.grid[:0], f.PotentialGrid, 1, a.cfg.Spacing(), a.cfg.GridVisScale)
+		a.particles = renderer.ParticleVertices(a.particles[:0], f.Particles, renderer.DisplayRadius{Scale: a.cfg.DisplayScale, Min: a.cfg.MinDisplayRadius})
+	})
+
+	view := physics.Mat4LookAt(a.camera.eye(), physics.NewVec3(0, 0, 0), physics.NewVec3(0, 1, 0))
+	projection := physics.Mat4Perspective(fovY, float64(width)/float64(height), 0.1, 2000)
+	// A world length L at clip depth w covers L·f·height/(2w) pixels; the
+	// point size is the particle's diameter, 2·radius
+	pointScale := float64(height) / math.Tan(fovY/2)
+	a.view.frame(width, height, projection.Multiply(view).ColumnMajor32(), a.grid, a.particles, pointScale)
+
+	a.frames++
+	if now-a.lastStats >= 500 {
+		fps := float64(a.frames) * 1000 / (now - a.lastStats)
+		state := ""
+		if a.paused {
+			state = " | paused"
+		}
+		a.stats.Set("textContent", fmt.Sprintf("%d particles | step %d | %.0f fps%s", len(a.particles)/4, step, fps, state))
+		a.frames = 0
+		a.lastStats = now
+	}
+}
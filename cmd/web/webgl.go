@@ -0,0 +1,202 @@
+//go:build js && wasm
+
+package main
+
+import (
+	"errors"
+	"fmt"
+	"relativity_simulation_2d/internal/gpu"
+	"syscall/js"
+	"unsafe"
+)
+
+// gridVertexShader places the precomputed grid line vertices
+const gridVertexShader = `#version 300 es
+in vec3 aPosition;
+uniform mat4 uMVP;
+out float vDisplacement;
+
+void main() {
+    vDisplacement = aPosition.y;
+    gl_Position = uMVP * vec4(aPosition, 1.0);
+}
+`
+
+// particleVertexShader draws each particle as a point sprite sized by its
+// radius and distance
+const particleVertexShader = `#version 300 es
+in vec4 aParticle;
+uniform mat4 uMVP;
+uniform float uPointScale;
+
+void main() {
+    gl_Position = uMVP * vec4(aParticle.xyz, 1.0);
+    gl_PointSize = clamp(uPointScale * aParticle.w / gl_Position.w, 2.0, 64.0);
+}
+`
+
+// particleFragmentShader shades point sprites as gold discs
+const particleFragmentShader = `#version 300 es
+precision mediump float;
+out vec4 fragColor;
+
+void main() {
+    vec2 d = gl_PointCoord * 2.0 - 1.0;
+    float r2 = dot(d, d);
+    if (r2 > 1.0) {
+        discard;
+    }
+    vec3 gold = vec3(1.0, 0.8, 0.0);
+    fragColor = vec4(gold * (0.6 + 0.4 * sqrt(1.0 - r2)), 1.0);
+}
+`
+
+// gridFragmentShader returns the grid colouring of the desktop GPU grid view
+func gridFragmentShader() string {
+	return fmt.Sprintf(`#version 300 es
+precision mediump float;
+in float vDisplacement;
+out vec4 fragColor;
+
+void main() {
+    // Deeper wells glow from the grid colour towards orange
+    float heat = clamp(-vDisplacement / %.1f, 0.0, 1.0);
+    vec3 base = vec3(50.0, 50.0, 100.0) / 255.0;
+    vec3 hot = vec3(1.0, 0.55, 0.1);
+    fragColor = vec4(mix(base, hot, sqrt(heat)), 1.0);
+}
+`, gpu.PotentialViewDepth)
+}
+
+// glProgram is a linked shader program with its single vertex attribute
+type glProgram struct {
+	program js.Value
+	vao     js.Value
+	buffer  js.Value
+	mvp     js.Value
+}
+
+// webGLRenderer draws the grid and particles into a WebGL2 canvas
+type webGLRenderer struct {
+	gl         js.Value
+	grid       glProgram
+	particles  glProgram
+	pointScale js.Value
+	matrix     js.Value // Reused Float32Array(16) for uniform uploads
+}
+
+// newWebGLRenderer creates the WebGL2 context and shader programs for canvas
+func newWebGLRenderer(canvas js.Value) (*webGLRenderer, error) {
+	gl := canvas.Call("getContext", "webgl2", map[string]any{"antialias": true})
+	if gl.IsNull() || gl.IsUndefined() {
+		return nil, errors.New("WebGL2 is not available in this browser")
+	}
+
+	r := &webGLRenderer{gl: gl, matrix: js.Global().Get("Float32Array").New(16)}
+	var err error
+	if r.grid, err = r.newProgram("grid", gridVertexShader, gridFragmentShader(), "aPosition", 3); err != nil {
+		return nil, err
+	}
+	if r.particles, err = r.newProgram("particle", particleVertexShader, particleFragmentShader, "aParticle", 4); err != nil {
+		return nil, err
+	}
+	r.pointScale = gl.Call("getUniformLocation", r.particles.program, "uPointScale")
+
+	gl.Call("enable", gl.Get("DEPTH_TEST"))
+	gl.Call("clearColor", 0, 0, 0, 1)
+	return r, nil
+}
+
+// newProgram compiles and links a program whose vertices are a single float
+// attribute of the given size, with a vertex array and buffer for it
+func (r *webGLRenderer) newProgram(name, vertexSource, fragmentSource, attribute string, size int) (glProgram, error) {
+	gl := r.gl
+	vertex, err := r.compile(name+" vertex", gl.Get("VERTEX_SHADER"), vertexSource)
+	if err != nil {
+		return glProgram{}, err
+	}
+	fragment, err := r.compile(name+" fragment", gl.Get("FRAGMENT_SHADER"), fragmentSource)
+	if err != nil {
+		return glProgram{}, err
+	}
+
+	program := gl.Call("createProgram")
+	gl.Call("attachShader", program, vertex)
+	gl.Call("attachShader", program, fragment)
+	gl.Call("linkProgram", program)
+	gl.Call("deleteShader", vertex)
+	gl.Call("deleteShader", fragment)
+	if !gl.Call("getProgramParameter", program, gl.Get("LINK_STATUS")).Bool() {
+		return glProgram{}, &gpu.ShaderError{Shader: name, Stage: "linking", Log: gl.Call("getProgramInfoLog", program).String()}
+	}
+
+	p := glProgram{
+		program: program,
+		vao:     gl.Call("createVertexArray"),
+		buffer:  gl.Call("createBuffer"),
+		mvp:     gl.Call("getUniformLocation", program, "uMVP"),
+	}
+	gl.Call("bindVertexArray", p.vao)
+	gl.Call("bindBuffer", gl.Get("ARRAY_BUFFER"), p.buffer)
+	location := gl.Call("getAttribLocation", program, attribute).Int()
+	gl.Call("enableVertexAttribArray", location)
+	gl.Call("vertexAttribPointer", location, size, gl.Get("FLOAT"), false, 0, 0)
+	gl.Call("bindVertexArray", nil)
+	return p, nil
+}
+
+// compile compiles one shader stage
+func (r *webGLRenderer) compile(name string, stage js.Value, source string) (js.Value, error) {
+	gl := r.gl
+	shader := gl.Call("createShader", stage)
+	gl.Call("shaderSource", shader, source)
+	gl.Call("compileShader", shader)
+	if !gl.Call("getShaderParameter", shader, gl.Get("COMPILE_STATUS")).Bool() {
+		log := gl.Call("getShaderInfoLog", shader).String()
+		gl.Call("deleteShader", shader)
+		return js.Value{}, &gpu.ShaderError{Shader: name, Stage: "compilation", Log: log}
+	}
+	return shader, nil
+}
+
+// frame clears the canvas and draws grid line vertices (x, y, z) and particle
+// vertices (x, y, z, radius) with the column-major view-projection matrix mvp
+func (r *webGLRenderer) frame(width, height int, mvp [16]float32, gridVertices, particleVertices []float32, pointScale float64) {
+	gl := r.gl
+	gl.Call("viewport", 0, 0, width, height)
+	gl.Call("clear", gl.Get("COLOR_BUFFER_BIT").Int()|gl.Get("DEPTH_BUFFER_BIT").Int())
+
+	for i, v := range mvp {
+		r.matrix.SetIndex(i, v)
+	}
+
+	r.draw(r.grid, gl.Get("LINES"), gridVertices, 3)
+
+	gl.Call("useProgram", r.particles.program)
+	gl.Call("uniform1f", r.pointScale, pointScale)
+	r.draw(r.particles, gl.Get("POINTS"), particleVertices, 4)
+}
+
+// draw uploads vertices into the program's buffer and draws them
+func (r *webGLRenderer) draw(p glProgram, mode js.Value, vertices []float32, size int) {
+	if len(vertices) == 0 {
+		return
+	}
+	gl := r.gl
+	gl.Call("useProgram", p.program)
+	gl.Call("uniformMatrix4fv", p.mvp, false, r.matrix)
+	gl.Call("bindVertexArray", p.vao)
+	gl.Call("bindBuffer", gl.Get("ARRAY_BUFFER"), p.buffer)
+	gl.Call("bufferData", gl.Get("ARRAY_BUFFER"), float32Array(vertices), gl.Get("DYNAMIC_DRAW"))
+	gl.Call("drawArrays", mode, 0, len(vertices)/size)
+	gl.Call("bindVertexArray", nil)
+}
+
+// float32Array copies v into a new JavaScript Float32Array. wasm is little
+// endian, so the bytes are copied as they are
+func float32Array(v []float32) js.Value {
+	bytes := unsafe.Slice((*byte)(unsafe.Pointer(unsafe.SliceData(v))), len(v)*4)
+	u8 := js.Global().Get("Uint8Array").New(len(bytes))
+	js.CopyBytesToJS(u8, bytes)
+	return js.Global().Get("Float32Array").New(u8.Get("buffer"))
+}
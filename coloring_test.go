@@ -0,0 +1,54 @@
+//go:build !js
+
+package main
+
+import (
+	rl "github.com/gen2brain/raylib-go/raylib"
+	"math"
+	"relativity_simulation_2d/internal/config"
+	"relativity_simulation_2d/internal/physics"
+	"relativity_simulation_2d/internal/renderer"
+	"relativity_simulation_2d/internal/simulation"
+	"testing"
+)
+
+// TestLyapunovColoring tests that the tracked particles get exponents and
+// ramp colors while the others keep the particle color
+func TestLyapunovColoring(t *testing.T) {
+	saved, savedGPU := cfg, useGPU
+	defer func() { cfg, useGPU = saved, savedGPU }()
+	cfg = config.DefaultConfig()
+	cfg.SimulationWidth, cfg.SimulationDepth = 64, 64
+	cfg.NumParticles = 10
+	cfg.Seed = 7
+	cfg.LyapunovParticles = 3
+	cfg.ParticleColoring = config.ParticleColoringLyapunov
+	useGPU = false
+
+	sim := NewSimulation()
+	for i := 0; i < 20; i++ {
+		sim.Step(0.05)
+	}
+	exponents := sim.chaos.Exponents()
+	if len(exponents) != 3 {
+		t.Fatalf("Expected 3 exponents, got %v", exponents)
+	}
+	for _, e := range exponents {
+		if e == 0 || math.IsNaN(e) {
+			t.Errorf("Expected measured exponents, got %v", exponents)
+		}
+	}
+
+	scheme := renderer.PaletteDefault.Scheme()
+	var colors []rl.Color
+	sim.ReadFrame(func(frame *simulation.Frame) { colors = particleColors(sim, frame, scheme) })
+	if colors[5] != raylibColor(scheme.Particle) {
+		t.Errorf("Expected an untracked particle in the particle color, got %+v", colors[5])
+	}
+	_, largest := physics.LyapunovSummary(exponents)
+	for i, e := range exponents {
+		if e == largest && colors[i] != raylibColor(scheme.ParticleHeavy) {
+			t.Errorf("Expected the most chaotic particle at the heavy end, got %+v", colors[i])
+		}
+	}
+}
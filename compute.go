@@ -0,0 +1,163 @@
+//go:build !js
+
+package main
+
+import (
+	"errors"
+	"fmt"
+	"relativity_simulation_2d/internal/config"
+	"relativity_simulation_2d/internal/gpu"
+	"relativity_simulation_2d/internal/physics"
+	fftpkg "relativity_simulation_2d/pkg/fft"
+	"time"
+)
+
+// Errors of the testing switches forceGPUInitFailure and forceGPUCompFailure
+var (
+	errForcedGPUInit = fmt.Errorf("%w: forced initialization failure", gpu.ErrNoGLContext)
+	errForcedGPUComp = errors.New("forced GPU computation failure")
+)
+
+// glBackend solves the Poisson equation with the OpenGL compute shaders,
+// creating the GPU context on first use. Deposit and particle updates have
+// no compute kernels yet and run on the CPU
+type glBackend struct {
+	gpu.CPUBackend
+	sim *Simulation
+}
+
+// SolvePoisson solves ∇²Φ = 4πGρ with the GPU FFT. The GPU FFT has only
+// radix-2 passes and would fall back to a naive O(N²) DFT shader every step
+// on other sizes, so those grids are solved by the CPU's Bluestein FFT, as
+// the startup warning says. Padding would not do: it breaks the periodic
+// boundaries the solve assumes
+func (b glBackend) SolvePoisson(potential, density physics.Grid, gravitationalConstant float64) error {
+	if !gpuFFTGrid(density.Width(), density.Height()) {
+		return b.CPUBackend.SolvePoisson(potential, density, gravitationalConstant)
+	}
+	s := b.sim
+	if s.forceGPUInitFailure {
+		return errForcedGPUInit
+	}
+	if s.gpu == nil {
+		g, err := InitializeGPU()
+		if err != nil {
+			return err
+		}
+		s.gpu = g
+	}
+	if s.forceGPUCompFailure {
+		return errForcedGPUComp
+	}
+
+	result, err := SolvePoissonGPU(s.gpu, density, s.mesh.Dx(), gravitationalConstant)
+	if err != nil {
+		return err
+	}
+	physics.CopyGrid(potential, result)
+	return nil
+}
+
+// gpuFFTGrid reports whether the OpenGL FFT solves a width×height grid,
+// which takes a power of two per side
+func gpuFFTGrid(width, height int) bool {
+	return fftpkg.IsPowerOfTwo(width) && fftpkg.IsPowerOfTwo(height)
+}
+
+// gpuFFTWarning returns the warning shown when the GPU is switched on for a
+// grid its FFT cannot solve, or "" if it can or the GPU is off
+func gpuFFTWarning() string {
+	if !useGPU || cfg.DirectSolver() || cfg.GPUBackend == config.GPUBackendCUDA || gpuFFTGrid(cfg.SimulationWidth, cfg.SimulationDepth) {
+		return ""
+	}
+	return fmt.Sprintf("%dx%d grid is not a power of two: the Poisson solve stays on the CPU", cfg.SimulationWidth, cfg.SimulationDepth)
+}
+
+// computeModes maps the config compute modes to the processor selection;
+// an unset mode prefers the GPU
+var computeModes = map[string]gpu.ComputeMode{
+	config.ComputeAuto: gpu.ModeAuto,
+	config.ComputeCPU:  gpu.ModeCPU,
+	config.ComputeGPU:  gpu.ModeGPU,
+}
+
+// nextComputeMode returns the mode G switches to: Auto, CPU, GPU and back
+func nextComputeMode(mode string) string {
+	switch mode {
+	case config.ComputeAuto:
+		return config.ComputeCPU
+	case config.ComputeCPU:
+		return config.ComputeGPU
+	default:
+		return config.ComputeAuto
+	}
+}
+
+// setComputeMode switches the session to a compute mode. The GPU paths stay
+// off while the quality governor forces the CPU, and come back with the
+// new mode when it stops
+func setComputeMode(sim *Simulation, mode string) {
+	computeMode = mode
+	if quality != nil && quality.level.ForceCPU() {
+		useGPU = false
+		quality.restoreGPU = mode != config.ComputeCPU
+	} else {
+		useGPU = mode != config.ComputeCPU
+	}
+	if m, ok := computeModes[mode]; ok && sim != nil {
+		sim.compute.SetMode(m)
+	}
+}
+
+// newComputeManager creates the processor selection of a simulation, with
+// the CPU and OpenGL backends registered and the session's compute mode
+func newComputeManager(s *Simulation) *gpu.FallbackManager {
+	cpu := gpu.CPUBackend{Precision: s.precision, Incremental: s.deposit, Mesh: s.mesh}
+	m := gpu.NewFallbackManager()
+	m.RegisterBackend(gpu.ProcessorTypeCPU, cpu)
+	m.RegisterBackend(gpu.ProcessorTypeGPU, glBackend{CPUBackend: cpu, sim: s})
+	m.SetGPUAvailable(true) // Until initialization reports otherwise
+	if mode, ok := computeModes[computeMode]; ok {
+		m.SetMode(mode)
+	} else {
+		m.SetMode(gpu.ModeGPU)
+	}
+	return m
+}
+
+// updatePM runs a kick-drift-kick particle-mesh step with each phase on the
+// processor the compute manager selects. A failing GPU phase is rerun on the
+// CPU, and the rest of the run stays there. Steps that complete on one
+// processor are timed, which is what Auto mode compares
+func (s *Simulation) updatePM(deltaTime float32) {
+	processor, failed := s.compute.GetProcessor().GetType(), s.compute.HasError()
+	start := time.Now()
+	field := &physics.ForceField{
+		AccelFieldX: s.AccelFieldX,
+		AccelFieldZ: s.AccelFieldZ,
+		Width:       cfg.SimulationWidth,
+		Height:      cfg.SimulationDepth,
+		CellSize:    s.mesh.CellSize,
+	}
+
+	s.compute.StepPM(s.Particles, s.MassDensityGrid, s.PotentialGrid, field, deltaTime, cfg.GravitationalConstant)
+
+	if s.compute.HasError() == failed {
+		s.compute.RecordPerformance(processor, float64(time.Since(start).Microseconds())/1000)
+	}
+	if s.compute.HasError() && !s.fallbackToCPU {
+		s.lastGPUError = s.compute.GetLastError()
+		s.gpuErrorOccurred = true
+		s.fallbackToCPU = true
+	}
+	s.advanceClock(deltaTime)
+}
+
+// updatePMOnCPU runs updatePM on the CPU backend whatever the compute mode,
+// for CPU mode with incremental deposition
+func (s *Simulation) updatePMOnCPU(deltaTime float32) {
+	mode := s.compute.GetMode()
+	s.compute.SetMode(gpu.ModeCPU)
+	defer s.compute.SetMode(mode)
+	s.updatePM(deltaTime)
+}
@@ -0,0 +1,103 @@
+//go:build !js
+
+package main
+
+import (
+	"fmt"
+	"os"
+	"relativity_simulation_2d/internal/config"
+	"strings"
+	"time"
+)
+
+// configPollInterval is how often the config file is checked for changes
+const configPollInterval = time.Second
+
+// configWatcher reloads the -config file when it changes, applying the live
+// settings to cfg
+type configWatcher struct {
+	args    []string       // Command-line arguments, layered over the file again on reload
+	base    *config.Config // Configuration as last loaded, so runtime changes survive reloads
+	modTime time.Time      // Modification time of the file when last loaded
+	checked time.Time      // Time of the last check
+}
+
+// newConfigWatcher creates a watcher for cfg.ConfigPath loaded with args, or
+// returns nil without a config file
+func newConfigWatcher(args []string) *configWatcher {
+	if cfg.ConfigPath == "" {
+		return nil
+	}
+	w := &configWatcher{args: args}
+	w.rebase()
+	return w
+}
+
+// rebase takes the current configuration and file as the last loaded, after
+// a restart has replaced cfg
+func (w *configWatcher) rebase() {
+	if w == nil {
+		return
+	}
+	w.base = cfg.Clone()
+	if info, err := os.Stat(cfg.ConfigPath); err == nil {
+		w.modTime = info.ModTime()
+	}
+}
+
+// saved takes a write to the file by this session as loaded: apply makes
+// the same change to the last loaded configuration, so the write is not
+// reported as a reload
+func (w *configWatcher) saved(apply func(c *config.Config)) {
+	if w == nil {
+		return
+	}
+	apply(w.base)
+	if info, err := os.Stat(cfg.ConfigPath); err == nil {
+		w.modTime = info.ModTime()
+	}
+}
+
+// poll reports whether the file has been modified since it was last loaded,
+// checking at most once per configPollInterval
+func (w *configWatcher) poll(now time.Time) bool {
+	if w == nil || now.Sub(w.checked) < configPollInterval {
+		return false
+	}
+	w.checked = now
+	info, err := os.Stat(cfg.ConfigPath)
+	if err != nil || info.ModTime().Equal(w.modTime) {
+		return false
+	}
+	w.modTime = info.ModTime()
+	return true
+}
+
+// reload loads the file again under the same preset and flags and applies
+// what changed. An invalid file leaves the configuration as it was
+func (w *configWatcher) reload() (config.Reload, error) {
+	next, err := loadConfig(w.args, cfg.Preset)
+	if err != nil {
+		return config.Reload{}, err
+	}
+	outputs.Redirect(next)
+	if err := next.Validate(); err != nil {
+		return config.Reload{}, err
+	}
+	r := cfg.Reload(w.base, next)
+	w.base = next
+	mouseSensitivity = cfg.MouseSensitivity
+	return r, nil
+}
+
+// reloadMessages returns the notifications for a reload: the settings
+// applied, and those waiting for a restart
+func reloadMessages(r config.Reload) (applied, restart string) {
+	if len(r.Applied) > 0 {
+		applied = "Config reloaded: " + strings.Join(r.Applied, ", ")
+	}
+	if len(r.Restart) > 0 {
+		restart = fmt.Sprintf("Config: restart to apply %s", strings.Join(r.Restart, ", "))
+	}
+	return applied, restart
+}
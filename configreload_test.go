@@ -0,0 +1,62 @@
+//go:build !js
+
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+// TestConfigWatcher tests reloading a changed config file under the flags
+func TestConfigWatcher(t *testing.T) {
+	saved, savedSensitivity := cfg, mouseSensitivity
+	defer func() { cfg, mouseSensitivity = saved, savedSensitivity }()
+	path := filepath.Join(t.TempDir(), "config.json")
+	if err := os.WriteFile(path, []byte(`{"GridVisScale": 2, "TargetFPS": 30}`), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	args := []string{"-config", path, "-fps", "45"}
+	loaded, err := loadConfig(args, "")
+	if err != nil {
+		t.Fatalf("loadConfig failed: %v", err)
+	}
+	if loaded.GridVisScale != 2 || loaded.TargetFPS != 45 {
+		t.Fatalf("Expected the file's scale under the flag's FPS cap, got %g and %d", loaded.GridVisScale, loaded.TargetFPS)
+	}
+	cfg = loaded
+	w := newConfigWatcher(args)
+
+	if err := os.WriteFile(path, []byte(`{"GridVisScale": 3, "TargetFPS": 60, "SimulationWidth": 128}`), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	later := time.Now().Add(time.Minute)
+	if err := os.Chtimes(path, later, later); err != nil {
+		t.Fatal(err)
+	}
+	if !w.poll(time.Now()) {
+		t.Fatal("Expected the change to be detected")
+	}
+	r, err := w.reload()
+	if err != nil {
+		t.Fatalf("reload failed: %v", err)
+	}
+	if cfg.GridVisScale != 3 || cfg.TargetFPS != 45 || cfg.SimulationWidth == 128 {
+		t.Errorf("Expected only the scale to apply, got %g, %d FPS and width %d", cfg.GridVisScale, cfg.TargetFPS, cfg.SimulationWidth)
+	}
+	if applied, restart := reloadMessages(r); !strings.Contains(applied, "GridVisScale") || !strings.Contains(restart, "SimulationWidth") {
+		t.Errorf("Unexpected messages %q and %q", applied, restart)
+	}
+
+	if err := os.WriteFile(path, []byte(`{"GridVisScale": `), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := w.reload(); err == nil || cfg.GridVisScale != 3 {
+		t.Errorf("Expected an invalid file to be rejected, got %v with scale %g", err, cfg.GridVisScale)
+	}
+	if w.poll(time.Now()) {
+		t.Error("Expected no check within the poll interval")
+	}
+}
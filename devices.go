@@ -0,0 +1,46 @@
+//go:build !js
+
+package main
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"relativity_simulation_2d/internal/gpu"
+	"sort"
+)
+
+// listGPUs prints the GPUs that -gpu-device can select
+func listGPUs(w io.Writer, devices []gpu.Device) {
+	if len(devices) == 0 {
+		fmt.Fprintln(w, "No GPUs found; the driver default is used")
+		return
+	}
+	for _, d := range devices {
+		fmt.Fprintln(w, d.String())
+	}
+}
+
+// selectGPUDevice points the OpenGL driver at the index-th device. It sets
+// environment variables read when the context is created, so it must run
+// before the window or a hidden GPU context opens
+func selectGPUDevice(devices []gpu.Device, index int) error {
+	if index == 0 {
+		return nil
+	}
+	if index > len(devices) {
+		return fmt.Errorf("GPU device %d not found (%d available)", index, len(devices))
+	}
+	env := gpu.SelectionEnv(devices[index-1])
+	keys := make([]string, 0, len(env))
+	for key := range env {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	for _, key := range keys {
+		if err := os.Setenv(key, env[key]); err != nil {
+			return fmt.Errorf("failed to select GPU device %d: %v", index, err)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,77 @@
+// Export-csv steps a simulation in a plain loop, without the headless runner,
+// and records diagnostics with the CSV exporter from a step observer. The final particles are also
+// written as raw arrays for numpy.fromfile.
+//
+//	go run ./examples/export-csv -out diag.csv -nbody final.raw
+package main
+
+import (
+	"flag"
+	"fmt"
+	"os"
+	"relativity_simulation_2d/internal/config"
+	"relativity_simulation_2d/internal/export"
+	"relativity_simulation_2d/internal/physics"
+	"relativity_simulation_2d/internal/simulation"
+)
+
+func main() {
+	out := flag.String("out", "diagnostics.csv", "diagnostics CSV to write")
+	nbody := flag.String("nbody", "", "also write the final particles as raw arrays to this file")
+	steps := flag.Int64("steps", 300, "number of steps to run")
+	interval := flag.Int64("interval", 10, "steps between diagnostics records")
+	flag.Parse()
+
+	if err := run(*out, *nbody, *steps, *interval); err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(1)
+	}
+}
+
+// run steps the simulation and writes a diagnostics record every interval steps
+func run(out, nbody string, steps, interval int64) error {
+	cfg := config.DefaultConfig()
+	cfg.SimulationWidth = 64
+	cfg.SimulationDepth = 64
+	cfg.NumParticles = 200
+	cfg.Seed = 7
+
+	exporter, err := export.NewCSVExporter(out)
+	if err != nil {
+		return err
+	}
+
+	// The exporter runs as an observer; a failed write stops the run after that step
+	sim := simulation.NewSimulation(cfg)
+	var exportErr error
+	sim.RegisterObserver(interval, func(step int64, sim *simulation.Simulation) {
+		record := export.Record{
+			Step:        step,
+			SimTime:     sim.GetSimTime(),
+			Diagnostics: physics.ComputeDiagnostics(sim.Particles),
+		}
+		if err := exporter.Export(record); err != nil && exportErr == nil {
+			exportErr = fmt.Errorf("failed to export step %d: %v", step, err)
+		}
+	})
+	for sim.GetStepCount() < steps && exportErr == nil {
+		sim.Step(cfg.FixedTimeStep)
+	}
+	if exportErr != nil {
+		_ = exporter.Close()
+		return exportErr
+	}
+	if err := exporter.Close(); err != nil {
+		return fmt.Errorf("failed to close %s: %v", out, err)
+	}
+	fmt.Printf("Wrote %d records to %s\n", steps/interval, out)
+
+	if nbody != "" {
+		meta := export.NBodyMeta{SimTime: sim.GetSimTime(), Width: float64(cfg.SimulationWidth), Depth: float64(cfg.SimulationDepth)}
+		if err := export.SaveNBody(nbody, export.NBodyFormatRaw, sim.Particles, meta); err != nil {
+			return err
+		}
+		fmt.Printf("Wrote %d particles to %s\n", len(sim.Particles), nbody)
+	}
+	return nil
+}
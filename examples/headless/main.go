@@ -0,0 +1,61 @@
+// Headless runs a small CPU simulation through the headless runner, the same
+// path as `relativity_simulation --headless`, and prints the final diagnostics.
+// Ctrl-C or the timeout stops it after the current step.
+//
+//	go run ./examples/headless -steps 500 -timeout 30s -checkpoint final.rsim
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"os"
+	"relativity_simulation_2d/internal/config"
+	"relativity_simulation_2d/internal/headless"
+	"relativity_simulation_2d/internal/simulation"
+	"time"
+)
+
+func main() {
+	steps := flag.Int64("steps", 200, "number of steps to run")
+	particles := flag.Int("particles", 500, "number of particles")
+	timeout := flag.Duration("timeout", time.Minute, "stop after this wall-clock time")
+	checkpoint := flag.String("checkpoint", "", "write a final checkpoint to this file")
+	flag.Parse()
+
+	cfg := config.DefaultConfig()
+	cfg.SimulationWidth = 128
+	cfg.SimulationDepth = 128
+	cfg.NumParticles = *particles
+	cfg.Seed = 1
+	if err := cfg.Validate(); err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(2)
+	}
+
+	sim := simulation.NewSimulation(cfg)
+	runner := headless.NewRunner(sim, headless.Options{
+		Steps:          *steps,
+		TimeStep:       cfg.FixedTimeStep,
+		CheckpointPath: *checkpoint,
+		Config:         cfg,
+		Progress:       headless.NewProgressReporter(os.Stdout, "", time.Second),
+	})
+
+	// The runner handles Ctrl-C itself; the context adds the timeout
+	ctx, cancel := context.WithTimeout(context.Background(), *timeout)
+	defer cancel()
+
+	result, err := runner.RunContext(ctx)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(1)
+	}
+	if result.Interrupted {
+		fmt.Println("Stopped early")
+	}
+
+	d := result.Diagnostics
+	fmt.Printf("steps=%d t=%.3f KE=%.6g p=(%.3g, %.3g) gyration=%.3g\n",
+		result.Steps, result.SimTime, d.KineticEnergy, d.MomentumX, d.MomentumZ, d.GyrationRadius)
+}
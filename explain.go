@@ -0,0 +1,161 @@
+//go:build !js
+
+package main
+
+import (
+	"fmt"
+	rl "github.com/gen2brain/raylib-go/raylib"
+	"relativity_simulation_2d/internal/lesson"
+	"relativity_simulation_2d/internal/renderer"
+	"relativity_simulation_2d/internal/simulation"
+)
+
+// explainPanelSize is the side of the explain mode's field panel in pixels
+// at UI scale 1
+const explainPanelSize = 256
+
+// explainPanelCells is the most cells per side the panel draws; larger grids
+// are averaged down to it
+const explainPanelCells = 64
+
+// explainVelocityScale is the length of a velocity line in panel cells per
+// unit of speed
+const explainVelocityScale = 2.0
+
+// explain walks through the particle-mesh step in explain mode, toggled with
+// F1
+var explain *explainState
+
+// explainState is the lesson shown by explain mode: the stage on screen and
+// the fields of the step it explains
+type explainState struct {
+	player        *lesson.Player
+	fields        lesson.Fields
+	width, height int  // Grid the fields were solved on (0 = not yet solved)
+	skip          bool // N was pressed: move on to the next stage this frame
+}
+
+// newExplainState creates the lesson at its first stage
+func newExplainState() *explainState {
+	return &explainState{player: lesson.NewPlayer(cfg.ExplainStageDuration)}
+}
+
+// toggleExplain turns explain mode on or off. Turning it on starts the lesson
+// over on the current particles
+func toggleExplain(s *Simulation) {
+	cfg.Explain = !cfg.Explain
+	if !cfg.Explain {
+		ui.Notify(renderer.NotificationInfo, "Explain mode off")
+		return
+	}
+	explain.restart(s)
+	ui.Notify(renderer.NotificationInfo, "Explain mode: one step per pass through the stages, N for the next stage")
+}
+
+// restart starts the lesson over at the first stage, solving the fields of
+// the simulation's particles
+func (e *explainState) restart(s *Simulation) {
+	e.player = lesson.NewPlayer(cfg.ExplainStageDuration)
+	e.skip = false
+	e.solve(s)
+}
+
+// solve computes the intermediate fields of a step from the particles
+func (e *explainState) solve(s *Simulation) {
+	e.width, e.height = cfg.SimulationWidth, cfg.SimulationDepth
+	e.fields = lesson.Compute(s.Particles, e.width, e.height, s.mesh, cfg.GravitationalConstant)
+}
+
+// advance moves the lesson on by dt seconds while running, or to the next
+// stage if N was pressed. step reports whether the simulation takes its step,
+// which it does on entering the kick and drift stage. The fields are solved
+// again when a new pass starts, from the particles that step moved
+func (e *explainState) advance(dt float64, s *Simulation, running bool) (step bool) {
+	e.player.StageDuration = cfg.ExplainStageDuration
+	if e.width != cfg.SimulationWidth || e.height != cfg.SimulationDepth {
+		e.solve(s)
+	}
+	entered := false
+	switch {
+	case e.skip:
+		e.skip = false
+		e.player.Next()
+		entered = true
+	case running:
+		entered = e.player.Advance(dt)
+	}
+	if !entered {
+		return false
+	}
+	switch e.player.Stage() {
+	case lesson.StageKickDrift:
+		return true
+	case lesson.StageDeposit:
+		e.solve(s)
+	}
+	return false
+}
+
+// draw draws the stage's title, annotation and legend above a panel in the
+// bottom-right corner showing its field, with the particles and their
+// velocities at the kick and drift stage, on cells of size dx
+func (e *explainState) draw(frame *simulation.Frame, dx float64, scheme renderer.ColorScheme) {
+	stage := e.player.Stage()
+	lines := []string{fmt.Sprintf("Stage %d/%d: %s", e.player.Number(), len(lesson.Stages), stage.Title())}
+	lines = append(lines, stage.Annotation()...)
+	lines = append(lines, stage.Legend())
+	for i, line := range lines {
+		x, y := ui.GetExplainTextPosition(i, len(lines), explainPanelSize)
+		color := scheme.Text
+		if i == 0 {
+			color = scheme.Title
+		}
+		drawHUDText(line, x, y, color)
+	}
+
+	x, y, side := ui.GetExplainPanelPosition(explainPanelSize)
+	rl.DrawRectangle(int32(x), int32(y), int32(side), int32(side), rl.Black)
+	field := lesson.Downsample(e.fields.Field(stage), explainPanelCells)
+	if len(field) > 0 && len(field[0]) > 0 {
+		cell := float64(side) / float64(max(len(field), len(field[0])))
+		lo, hi := lesson.Range(field)
+		for i := range field {
+			for j := range field[i] {
+				t := 0.0
+				if hi > lo {
+					t = (field[i][j] - lo) / (hi - lo)
+				}
+				color := renderer.LerpColor(renderer.UIColor{A: 255}, scheme.GridHot, t)
+				rl.DrawRectangle(int32(float64(x)+float64(i)*cell), int32(float64(y)+float64(j)*cell), int32(cell+1), int32(cell+1), raylibColor(color))
+			}
+		}
+	}
+	if stage == lesson.StageKickDrift {
+		drawExplainParticles(frame, x, y, side, dx, scheme)
+	}
+
+	// Progress through the stage along the bottom of the panel
+	bar := ui.GetUIScale() * 4
+	rl.DrawRectangle(int32(x), int32(y+side), int32(float64(side)*e.player.Progress()), int32(bar), raylibColor(scheme.Title))
+}
+
+// drawExplainParticles draws the particles on the panel with a line along
+// each one's velocity, the drift the step is about to take. dx is the cell
+// size the panel's cells stand for
+func drawExplainParticles(frame *simulation.Frame, x, y, side int, dx float64, scheme renderer.ColorScheme) {
+	width, height := float64(cfg.SimulationWidth), float64(cfg.SimulationDepth)
+	cell := float64(side) / max(width, height)
+	stride := max(1, len(frame.Particles)/2000)
+	for i := 0; i < len(frame.Particles); i += stride {
+		p := &frame.Particles[i]
+		gx := p.Position.X/dx + width/2
+		gz := p.Position.Z/dx + height/2
+		if gx < 0 || gx >= width || gz < 0 || gz >= height {
+			continue
+		}
+		px, pz := float32(float64(x)+gx*cell), float32(float64(y)+gz*cell)
+		end := rl.NewVector2(px+float32(p.Velocity.X*explainVelocityScale*cell), pz+float32(p.Velocity.Z*explainVelocityScale*cell))
+		rl.DrawLineV(rl.NewVector2(px, pz), end, raylibColor(scheme.Text))
+		rl.DrawCircleV(rl.NewVector2(px, pz), 2, raylibColor(scheme.ParticleLight))
+	}
+}
@@ -0,0 +1,76 @@
+//go:build !js
+
+package main
+
+import (
+	"math"
+	"relativity_simulation_2d/internal/config"
+	"relativity_simulation_2d/internal/physics"
+	"testing"
+)
+
+// TestRotatingFrameSimulation tests that a nonzero frame angular velocity
+// converts the initial velocities and adds the pseudo-forces to each step
+func TestRotatingFrameSimulation(t *testing.T) {
+	saved, savedGPU := cfg, useGPU
+	defer func() { cfg, useGPU = saved, savedGPU }()
+	cfg = config.DefaultConfig()
+	cfg.SimulationWidth, cfg.SimulationDepth = 32, 32
+	cfg.NumParticles = 20
+	cfg.Seed = 5
+	useGPU = false
+
+	inertial := NewSimulation()
+	if inertial.Forces != nil {
+		t.Fatalf("Expected no extra forces in the inertial frame, got %d", len(inertial.Forces))
+	}
+
+	cfg.FrameOmega = 0.1
+	sim := NewSimulation()
+	if len(sim.Forces) != 2 {
+		t.Fatalf("Expected the Coriolis and centrifugal forces, got %d", len(sim.Forces))
+	}
+	for i, p := range sim.Particles {
+		q := inertial.Particles[i]
+		want := physics.Vec3{X: q.Velocity.X - 0.1*q.Position.Z, Y: q.Velocity.Y, Z: q.Velocity.Z + 0.1*q.Position.X}
+		if p.Velocity.Sub(want).Length() > 1e-12 {
+			t.Fatalf("Particle %d velocity %+v, want %+v", i, p.Velocity, want)
+		}
+	}
+
+	sim.Step(0.1)
+	if sim.GetStepCount() != 1 {
+		t.Errorf("Expected one completed step, got %d", sim.GetStepCount())
+	}
+}
+
+// TestSpongeLayer tests that the sponge damps particles at the domain edge only
+func TestSpongeLayer(t *testing.T) {
+	saved, savedGPU := cfg, useGPU
+	defer func() { cfg, useGPU = saved, savedGPU }()
+	cfg = config.DefaultConfig()
+	cfg.SimulationWidth, cfg.SimulationDepth = 64, 64
+	cfg.NumParticles = 2
+	cfg.Seed = 7
+	cfg.Solver = config.SolverDirect
+	cfg.SpongeWidth = 8
+	cfg.SpongeStrength = 5
+	useGPU = false
+
+	sim := NewSimulation()
+	if len(sim.Forces) != 1 {
+		t.Fatalf("Expected the sponge force, got %d forces", len(sim.Forces))
+	}
+	sim.Particles = []*physics.Particle{
+		physics.NewParticle(1e-6, 0, 0, 0, 0, 0, 1),
+		physics.NewParticle(1e-6, 31, 0, 0, 0, 0, 1),
+	}
+	sim.Step(0.1)
+
+	if v := sim.Particles[0].Velocity.Z; math.Abs(v-1) > 1e-3 {
+		t.Errorf("Expected the central particle to keep its speed, got %g", v)
+	}
+	if v := sim.Particles[1].Velocity.Z; v > 0.7 {
+		t.Errorf("Expected the edge particle to be damped, got %g", v)
+	}
+}
@@ -0,0 +1,124 @@
+//go:build !js
+
+package main
+
+import (
+	rl "github.com/gen2brain/raylib-go/raylib"
+	"relativity_simulation_2d/internal/governor"
+	"relativity_simulation_2d/internal/renderer"
+)
+
+// frameRateState applies the frame rate settings of the interactive window
+// to the render loop, which paces the frames; raylib's own limit stays off.
+// F4 switches to the uncapped benchmark mode, with no cap and no vsync, and
+// back; F5 toggles vsync. While paused or in the background the window idles
+// at cfg.IdleFPS with the physics stopped
+type frameRateState struct {
+	loop     *renderer.RenderLoop
+	filter   *renderer.DeltaFilter // Filters the physics time step
+	uncapped bool                  // Benchmark mode, overriding the configured cap and vsync
+	idle     bool                  // Paused or in the background at the last apply
+	vsync    bool                  // Vsync state of the window
+}
+
+// newFrameRateState creates the frame rate state for a window opened with
+// vsync as configured, and sets the loop's time step filter
+func newFrameRateState(loop *renderer.RenderLoop) *frameRateState {
+	f := &frameRateState{
+		loop:   loop,
+		filter: renderer.NewDeltaFilter(cfg.DeltaSmoothing, cfg.DeltaSpikeFactor),
+		vsync:  cfg.VSync,
+	}
+	loop.SetDeltaFilter(f.filter)
+	return f
+}
+
+// windowFlags returns the raylib flags to open the window with
+func windowFlags() uint32 {
+	if cfg.VSync {
+		return rl.FlagVsyncHint
+	}
+	return 0
+}
+
+// target returns the frame rate cap (0 = uncapped) and vsync state for the
+// quality level. Idling drops vsync so that the idle cap applies
+func (f *frameRateState) target(level governor.Level) (fps int, vsync bool) {
+	fps, vsync = level.FrameRate(cfg.TargetFPS), cfg.VSync
+	switch {
+	case f.idle:
+		if fps == 0 || fps > cfg.IdleFPS {
+			fps = cfg.IdleFPS
+		}
+		return fps, false
+	case f.uncapped:
+		return 0, false
+	default:
+		return fps, vsync
+	}
+}
+
+// budget returns the seconds per frame that the cap or vsync paces frames
+// to (0 = uncapped)
+func (f *frameRateState) budget() float64 {
+	if f.vsync {
+		if rate := rl.GetMonitorRefreshRate(rl.GetCurrentMonitor()); rate > 0 {
+			return 1 / float64(rate)
+		}
+	}
+	return f.loop.GetTargetFrameTime()
+}
+
+// stepping reports whether the physics runs this frame: not paused and not
+// idling in the background
+func (f *frameRateState) stepping() bool {
+	return !pause && !f.idle
+}
+
+// handleKeys toggles the uncapped mode and vsync
+func (f *frameRateState) handleKeys() {
+	if rl.IsKeyPressed(rl.KeyF4) {
+		f.uncapped = !f.uncapped
+		if f.uncapped {
+			ui.Notify(renderer.NotificationInfo, "Frame rate: uncapped")
+		} else {
+			ui.Notify(renderer.NotificationInfo, "Frame rate: capped")
+		}
+	}
+	if rl.IsKeyPressed(rl.KeyF5) {
+		cfg.VSync = !cfg.VSync
+		f.uncapped = f.uncapped && !cfg.VSync // Vsync ends the benchmark mode
+		if cfg.VSync {
+			ui.Notify(renderer.NotificationInfo, "VSync: on")
+		} else {
+			ui.Notify(renderer.NotificationInfo, "VSync: off")
+		}
+	}
+}
+
+// apply passes the settings for the quality level to the render loop, the
+// window and the HUD
+func (f *frameRateState) apply(level governor.Level) {
+	wasIdle := f.idle
+	f.idle = cfg.IdleFPS > 0 && (pause || !rl.IsWindowFocused())
+
+	fps, vsync := f.target(level)
+	f.loop.SetTargetFPS(fps)
+	f.loop.EnableVSync(vsync)
+	ui.SetTargetFPS(fps)
+
+	// Restart the time step average at the full rate, so the long last idle
+	// frame is rejected as a spike instead of stepping the physics
+	if wasIdle && !f.idle {
+		f.filter.Reset(f.loop.GetTargetFrameTime())
+	}
+
+	if vsync != f.vsync {
+		if vsync {
+			rl.SetWindowState(rl.FlagVsyncHint)
+		} else {
+			rl.ClearWindowState(rl.FlagVsyncHint)
+		}
+		f.vsync = vsync
+	}
+}
@@ -0,0 +1,1599 @@
+//go:build !js && !android
+
+package main
+
+import (
+	"fmt"
+	rl "github.com/gen2brain/raylib-go/raylib"
+	"github.com/go-gl/gl/v4.3-core/gl"
+	"math"
+	"os"
+	"relativity_simulation_2d/internal/gpu"
+	"relativity_simulation_2d/internal/physics"
+	"strings"
+	"time"
+	"unsafe"
+)
+
+// Real GPU Types and Functions for OpenGL 4.3+ Compute Shaders
+// These replace fake CPU implementations with actual GPU acceleration
+// Uses raylib's OpenGL context instead of separate GLFW window
+
+// shaderSources generates the GLSL of every compute program built here
+var shaderSources = gpu.NewShaderManager()
+
+// LoadFunctions resolves the OpenGL 4.3 entry points used by the compute path
+func (raylibPlatform) LoadFunctions() error { return gl.Init() }
+
+// InitializeGPU initializes GPU with proper context handling
+func InitializeGPU() (*gpu.GPU, error) {
+	return InitializeGPUWithMode(false)
+}
+
+// InitializeGPUWithMode initializes GPU on the shared GL context. An open
+// window is used even when forceHeadless is set, since raylib cannot create a
+// second context next to it; otherwise a hidden window is shared by all users
+func InitializeGPUWithMode(forceHeadless bool) (*gpu.GPU, error) {
+	owner, err := glContext.Acquire()
+	if err != nil {
+		return nil, fmt.Errorf("%w: failed to initialize OpenGL: %w", gpu.ErrNoGLContext, err)
+	}
+
+	// Test if OpenGL context is working
+	var testBuffer uint32
+	gl.GenBuffers(1, &testBuffer)
+	if testBuffer == 0 {
+		code := gl.GetError()
+		glContext.Release()
+		return nil, fmt.Errorf("%w: GenBuffers failed (GL error: %s)", gpu.ErrNoGLContext, glErrorName(code))
+	}
+	gl.DeleteBuffers(1, &testBuffer)
+
+	// Old drivers fail later with cryptic GL errors, so check the version up
+	// front and leave the context to the renderer
+	caps := queryCapabilities()
+	if !caps.Compute() {
+		glContext.Release()
+		return nil, gpu.NewUnsupportedGLError(caps)
+	}
+
+	enableDebugOutput(caps)
+
+	return &gpu.GPU{
+		Initialized:   true,
+		Headless:      owner == gpu.ContextHidden,
+		NeedsCleanup:  true,
+		BufferStorage: caps.BufferStorage(),
+		Caps:          caps,
+		FftPlanCache:  gpu.NewFFTPlanCache(gpu.DefaultFFTPlanCapacity),
+		ShaderCache:   make(map[string]*gpu.ComputeShader),
+		ProgramCache:  newProgramCache(caps),
+		Tuner:         newWorkgroupTuner(),
+	}, nil
+}
+
+// newProgramCache returns the program binary cache for the current driver,
+// or nil if caching is off or the driver offers no binary formats
+func newProgramCache(caps gpu.Capabilities) *gpu.ProgramCache {
+	if cfg == nil || cfg.ShaderCacheDir == "" || !caps.ProgramBinary() {
+		return nil
+	}
+	var formats int32
+	gl.GetIntegerv(gl.NUM_PROGRAM_BINARY_FORMATS, &formats)
+	if formats == 0 {
+		return nil
+	}
+	return gpu.NewProgramCache(cfg.ShaderCacheDir, driverString())
+}
+
+// newWorkgroupTuner returns the work group tuner for the current driver,
+// storing its results in the shader cache directory, or nil if caching is
+// off and the default sizes are used
+func newWorkgroupTuner() *gpu.WorkgroupTuner {
+	if cfg == nil || cfg.ShaderCacheDir == "" {
+		return nil
+	}
+	return gpu.NewWorkgroupTuner(cfg.ShaderCacheDir, driverString())
+}
+
+// driverString identifies the GL driver by its vendor, renderer and version
+func driverString() string {
+	return strings.Join([]string{
+		gl.GoStr(gl.GetString(gl.VENDOR)),
+		gl.GoStr(gl.GetString(gl.RENDERER)),
+		gl.GoStr(gl.GetString(gl.VERSION)),
+	}, "\n")
+}
+
+// queryCapabilities reads the version, renderer and extensions of the
+// current context
+func queryCapabilities() gpu.Capabilities {
+	var major, minor, count int32
+	gl.GetIntegerv(gl.MAJOR_VERSION, &major)
+	gl.GetIntegerv(gl.MINOR_VERSION, &minor)
+	gl.GetIntegerv(gl.NUM_EXTENSIONS, &count)
+	extensions := make([]string, count)
+	for i := range extensions {
+		extensions[i] = gl.GoStr(gl.GetStringi(gl.EXTENSIONS, uint32(i)))
+	}
+	return gpu.Capabilities{
+		Major:      int(major),
+		Minor:      int(minor),
+		Renderer:   gl.GoStr(gl.GetString(gl.RENDERER)),
+		Extensions: extensions,
+	}
+}
+
+// GL_NVX_gpu_memory_info queries, in KB
+const (
+	gpuMemoryTotalNVX     = 0x9048 // GPU_MEMORY_INFO_TOTAL_AVAILABLE_MEMORY_NVX
+	gpuMemoryAvailableNVX = 0x9049 // GPU_MEMORY_INFO_CURRENT_AVAILABLE_VIDMEM_NVX
+)
+
+// GPUMemoryUsed returns the video memory in use on the context's device, by
+// every process, where the driver reports it. ok is false otherwise
+func GPUMemoryUsed(g *gpu.GPU) (used uint64, ok bool) {
+	if g == nil || !g.Initialized || !g.Caps.MemoryInfo() {
+		return 0, false
+	}
+	var total, available int32
+	gl.GetIntegerv(gpuMemoryTotalNVX, &total)
+	gl.GetIntegerv(gpuMemoryAvailableNVX, &available)
+	if total <= 0 || available > total {
+		return 0, false
+	}
+	return uint64(total-available) * 1024, true
+}
+
+// glDebug receives the driver's debug messages once a context enabled them
+// (nil = unsupported or turned off)
+var glDebug *gpu.DebugLog
+
+// enableDebugOutput routes the driver's debug messages into glDebug, which
+// logs those of the configured severity to stderr and keeps errors for
+// glError. Contexts without the debug callback keep glGetError alone
+func enableDebugOutput(caps gpu.Capabilities) {
+	severity := gpu.DebugSeverityMedium
+	if cfg != nil {
+		severity, _ = gpu.ParseDebugSeverity(cfg.GLDebug) // Validated at startup
+	}
+	if severity == gpu.DebugSeverityOff || !caps.Debug() {
+		return
+	}
+	if glDebug == nil || glDebug.MinSeverity() != severity {
+		glDebug = gpu.NewDebugLog(os.Stderr, severity)
+	}
+	log := glDebug // Messages may arrive on a driver thread
+	gl.Enable(gl.DEBUG_OUTPUT)
+	gl.DebugMessageCallback(func(source, kind, id, level uint32, _ int32, message string, _ unsafe.Pointer) {
+		log.Handle(gpu.DebugMessage{
+			Source:   debugSourceName(source),
+			Type:     debugTypeName(kind),
+			ID:       id,
+			Severity: debugSeverity(level),
+			Text:     strings.TrimSpace(message),
+		})
+	}, nil)
+
+	// Have the driver drop the messages that would not be logged, but keep
+	// every error for glError
+	for _, level := range []uint32{gl.DEBUG_SEVERITY_NOTIFICATION, gl.DEBUG_SEVERITY_LOW, gl.DEBUG_SEVERITY_MEDIUM, gl.DEBUG_SEVERITY_HIGH} {
+		gl.DebugMessageControl(gl.DONT_CARE, gl.DONT_CARE, level, 0, nil, debugSeverity(level) >= severity)
+	}
+	gl.DebugMessageControl(gl.DONT_CARE, gl.DEBUG_TYPE_ERROR, gl.DONT_CARE, 0, nil, true)
+}
+
+// debugSourceName names the part of the GL stack that raised a debug message
+func debugSourceName(source uint32) string {
+	switch source {
+	case gl.DEBUG_SOURCE_API:
+		return "api"
+	case gl.DEBUG_SOURCE_WINDOW_SYSTEM:
+		return "window system"
+	case gl.DEBUG_SOURCE_SHADER_COMPILER:
+		return "shader compiler"
+	case gl.DEBUG_SOURCE_THIRD_PARTY:
+		return "third party"
+	case gl.DEBUG_SOURCE_APPLICATION:
+		return "application"
+	default:
+		return "other"
+	}
+}
+
+// debugTypeName names the type of a debug message
+func debugTypeName(kind uint32) string {
+	switch kind {
+	case gl.DEBUG_TYPE_ERROR:
+		return "error"
+	case gl.DEBUG_TYPE_DEPRECATED_BEHAVIOR:
+		return "deprecated"
+	case gl.DEBUG_TYPE_UNDEFINED_BEHAVIOR:
+		return "undefined behavior"
+	case gl.DEBUG_TYPE_PORTABILITY:
+		return "portability"
+	case gl.DEBUG_TYPE_PERFORMANCE:
+		return "performance"
+	default:
+		return "other"
+	}
+}
+
+// debugSeverity converts a GL debug severity
+func debugSeverity(level uint32) gpu.DebugSeverity {
+	switch level {
+	case gl.DEBUG_SEVERITY_HIGH:
+		return gpu.DebugSeverityHigh
+	case gl.DEBUG_SEVERITY_MEDIUM:
+		return gpu.DebugSeverityMedium
+	case gl.DEBUG_SEVERITY_LOW:
+		return gpu.DebugSeverityLow
+	default:
+		return gpu.DebugSeverityNotification
+	}
+}
+
+// lastGLError is the last error glError returned, for the GPU state overlay
+// (nil = none yet)
+var lastGLError error
+
+// glError returns the first OpenGL error raised since the last check, naming
+// what was being done (nil = none), and keeps it in lastGLError. The debug
+// callback's report is preferred: it describes the failing call and also
+// holds errors raised asynchronously, which glGetError misses once another
+// check has cleared them
+func glError(what string) error {
+	err := checkGLError(what)
+	if err != nil {
+		lastGLError = err
+	}
+	return err
+}
+
+// checkGLError returns the first OpenGL error raised since the last check
+func checkGLError(what string) error {
+	code := gl.GetError()
+	for i := 0; i < 8 && gl.GetError() != gl.NO_ERROR; i++ {
+		// Clear the remaining error flags, so the next check starts afresh
+	}
+	if glDebug != nil {
+		if err := glDebug.TakeError(); err != nil {
+			return fmt.Errorf("OpenGL error during %s: %v", what, err)
+		}
+	}
+	if code != gl.NO_ERROR {
+		return fmt.Errorf("OpenGL error during %s: %s", what, glErrorName(code))
+	}
+	return nil
+}
+
+// glErrorName returns the name of a glGetError code
+func glErrorName(code uint32) string {
+	switch code {
+	case gl.INVALID_ENUM:
+		return "GL_INVALID_ENUM"
+	case gl.INVALID_VALUE:
+		return "GL_INVALID_VALUE"
+	case gl.INVALID_OPERATION:
+		return "GL_INVALID_OPERATION"
+	case gl.INVALID_FRAMEBUFFER_OPERATION:
+		return "GL_INVALID_FRAMEBUFFER_OPERATION"
+	case gl.OUT_OF_MEMORY:
+		return "GL_OUT_OF_MEMORY"
+	case gl.STACK_OVERFLOW:
+		return "GL_STACK_OVERFLOW"
+	case gl.STACK_UNDERFLOW:
+		return "GL_STACK_UNDERFLOW"
+	default:
+		return fmt.Sprintf("0x%x", code)
+	}
+}
+
+func AllocateGPUMemory(g *gpu.GPU, sizeBytes int) (*gpu.GPUMemoryBuffer, error) {
+	if !g.Initialized {
+		return nil, fmt.Errorf("%w: GPU context not initialized", gpu.ErrNoGLContext)
+	}
+
+	var bufferID uint32
+	gl.GenBuffers(1, &bufferID)
+
+	// Debug: Check if GenBuffers is working
+	if bufferID == 0 {
+		// Try to get more info about OpenGL state
+		return nil, fmt.Errorf("gl.GenBuffers returned 0, GL error: %s", glErrorName(gl.GetError()))
+	}
+
+	gl.BindBuffer(gl.SHADER_STORAGE_BUFFER, bufferID)
+	gl.BufferData(gl.SHADER_STORAGE_BUFFER, sizeBytes, gl.Ptr(nil), gl.DYNAMIC_DRAW)
+
+	if err := glError("buffer allocation"); err != nil {
+		return nil, err
+	}
+
+	return &gpu.GPUMemoryBuffer{BufferID: bufferID, Size: sizeBytes}, nil
+}
+
+func CompileComputeShader(g *gpu.GPU, source string) (*gpu.ComputeShader, error) {
+	if !g.Initialized {
+		return nil, fmt.Errorf("%w: GPU context not initialized", gpu.ErrNoGLContext)
+	}
+
+	programID, err := buildComputeProgram(g, "compute", source)
+	if err != nil {
+		return nil, err
+	}
+	return &gpu.ComputeShader{ProgramID: programID}, nil
+}
+
+// buildComputeProgram compiles and links a compute program, or loads it from
+// the GPU's program cache when one is set. Freshly linked programs are added
+// to the cache
+func buildComputeProgram(g *gpu.GPU, name, source string) (uint32, error) {
+	var key string
+	if g != nil && g.ProgramCache != nil {
+		key = g.ProgramCache.Key(source)
+		if programID, ok := loadCachedProgram(g.ProgramCache, key); ok {
+			return programID, nil
+		}
+	}
+
+	// Compile the compute shader
+	shaderID := gl.CreateShader(gl.COMPUTE_SHADER)
+	cSources, free := gl.Strs(source + "\x00")
+	gl.ShaderSource(shaderID, 1, cSources, nil)
+	free()
+	gl.CompileShader(shaderID)
+
+	// Check compilation status
+	var status int32
+	gl.GetShaderiv(shaderID, gl.COMPILE_STATUS, &status)
+	if status == gl.FALSE {
+		var logLength int32
+		gl.GetShaderiv(shaderID, gl.INFO_LOG_LENGTH, &logLength)
+
+		log := make([]byte, logLength+1)
+		gl.GetShaderInfoLog(shaderID, logLength, nil, &log[0])
+
+		gl.DeleteShader(shaderID)
+		return 0, &gpu.ShaderError{Shader: name, Stage: "compilation", Log: string(log)}
+	}
+
+	// Create program and link
+	programID := gl.CreateProgram()
+	gl.AttachShader(programID, shaderID)
+	if key != "" {
+		gl.ProgramParameteri(programID, gl.PROGRAM_BINARY_RETRIEVABLE_HINT, gl.TRUE)
+	}
+	gl.LinkProgram(programID)
+
+	// Check linking status
+	gl.GetProgramiv(programID, gl.LINK_STATUS, &status)
+	if status == gl.FALSE {
+		var logLength int32
+		gl.GetProgramiv(programID, gl.INFO_LOG_LENGTH, &logLength)
+
+		log := make([]byte, logLength+1)
+		gl.GetProgramInfoLog(programID, logLength, nil, &log[0])
+
+		gl.DeleteProgram(programID)
+		gl.DeleteShader(shaderID)
+		return 0, &gpu.ShaderError{Shader: name, Stage: "linking", Log: string(log)}
+	}
+
+	// Clean up shader (program retains copy)
+	gl.DeleteShader(shaderID)
+
+	if key != "" {
+		storeCachedProgram(g.ProgramCache, key, programID)
+	}
+	return programID, nil
+}
+
+// loadCachedProgram creates a program from a cached binary. Binaries the
+// driver rejects, e.g. after an update it does not announce in its version
+// string, are removed from the cache
+func loadCachedProgram(cache *gpu.ProgramCache, key string) (uint32, bool) {
+	format, data, ok := cache.Load(key)
+	if !ok {
+		return 0, false
+	}
+	programID := gl.CreateProgram()
+	gl.ProgramBinary(programID, format, gl.Ptr(data), int32(len(data)))
+
+	var status int32
+	gl.GetProgramiv(programID, gl.LINK_STATUS, &status)
+	if status == gl.FALSE {
+		gl.DeleteProgram(programID)
+		cache.Remove(key)
+		return 0, false
+	}
+	return programID, true
+}
+
+// storeCachedProgram saves the binary of a linked program. A failure only
+// costs a compilation on the next run, so it is ignored
+func storeCachedProgram(cache *gpu.ProgramCache, key string, programID uint32) {
+	var length int32
+	gl.GetProgramiv(programID, gl.PROGRAM_BINARY_LENGTH, &length)
+	if length <= 0 {
+		return
+	}
+	data := make([]byte, length)
+	var format uint32
+	gl.GetProgramBinary(programID, length, &length, &format, gl.Ptr(data))
+	_ = cache.Store(key, format, data[:length])
+}
+
+// AcquireUploadSlot returns the next buffer of the GPU's upload ring, mapped
+// persistently with room for sizeBytes. It waits until the GPU has finished
+// with the slot's previous contents, which with three slots only blocks when
+// the CPU runs more than two uploads ahead. Call FenceUploadSlot after the
+// commands that read the slot have been issued
+func AcquireUploadSlot(g *gpu.GPU, sizeBytes int) (*gpu.UploadSlot, error) {
+	if !g.Initialized {
+		return nil, fmt.Errorf("%w: GPU context not initialized", gpu.ErrNoGLContext)
+	}
+	if !g.BufferStorage {
+		return nil, fmt.Errorf("persistent buffers not supported (need OpenGL 4.4 or %s)", gpu.BufferStorageExtension)
+	}
+
+	slot := g.UploadRing.Next()
+	if slot.Fence != 0 {
+		status := gl.ClientWaitSync(slot.Fence, gl.SYNC_FLUSH_COMMANDS_BIT, uint64(time.Second))
+		gl.DeleteSync(slot.Fence)
+		slot.Fence = 0
+		if status == gl.WAIT_FAILED || status == gl.TIMEOUT_EXPIRED {
+			return nil, fmt.Errorf("timed out waiting for upload buffer (status 0x%x)", status)
+		}
+	}
+
+	if slot.Size < sizeBytes {
+		releaseUploadSlot(slot)
+
+		// Immutable storage may stay mapped while the GPU reads it; coherent
+		// mapping makes CPU writes visible without explicit flushes
+		flags := uint32(gl.MAP_WRITE_BIT | gl.MAP_PERSISTENT_BIT | gl.MAP_COHERENT_BIT)
+		size := gpu.RingCapacity(sizeBytes)
+		gl.GenBuffers(1, &slot.BufferID)
+		gl.BindBuffer(gl.SHADER_STORAGE_BUFFER, slot.BufferID)
+		gl.BufferStorage(gl.SHADER_STORAGE_BUFFER, size, nil, flags)
+		ptr := gl.MapBufferRange(gl.SHADER_STORAGE_BUFFER, 0, size, flags)
+		if ptr == nil {
+			code := gl.GetError()
+			releaseUploadSlot(slot)
+			return nil, fmt.Errorf("failed to map upload buffer (GL error: %s)", glErrorName(code))
+		}
+		slot.Size = size
+		slot.Data = unsafe.Slice((*float32)(ptr), size/4)
+	}
+
+	return slot, nil
+}
+
+// FenceUploadSlot marks the slot as in use by the commands issued so far
+func FenceUploadSlot(slot *gpu.UploadSlot) {
+	slot.Fence = gl.FenceSync(gl.SYNC_GPU_COMMANDS_COMPLETE, 0)
+}
+
+// releaseUploadSlot unmaps and deletes a slot's buffer and fence
+func releaseUploadSlot(slot *gpu.UploadSlot) {
+	if slot.Fence != 0 {
+		gl.DeleteSync(slot.Fence)
+		slot.Fence = 0
+	}
+	if slot.BufferID != 0 {
+		gl.BindBuffer(gl.SHADER_STORAGE_BUFFER, slot.BufferID)
+		gl.UnmapBuffer(gl.SHADER_STORAGE_BUFFER)
+		gl.DeleteBuffers(1, &slot.BufferID)
+		slot.BufferID = 0
+	}
+	slot.Size = 0
+	slot.Data = nil
+}
+
+func DeleteComputeShader(shader *gpu.ComputeShader) error {
+	if shader.ProgramID != 0 {
+		gl.DeleteProgram(shader.ProgramID)
+		shader.ProgramID = 0
+	}
+	return nil
+}
+
+func CreateGPUFFTPlan2D(g *gpu.GPU, width, height int, isForward bool) (*gpu.GPUFFTPlan, error) {
+	if !g.Initialized {
+		return nil, fmt.Errorf("%w: GPU context not initialized", gpu.ErrNoGLContext)
+	}
+
+	// For now, implement as a Cooley-Tukey FFT using compute shaders
+	// This is a placeholder - real implementation would use optimized GPU FFT library
+	plan := &gpu.GPUFFTPlan{
+		Gpu:       g,
+		Width:     width,
+		Height:    height,
+		IsForward: isForward,
+	}
+
+	// Create compute shaders for FFT operations (simplified for TDD)
+	// Real implementation would have bit-reversal, butterfly operations, and transpose shaders
+	return plan, nil
+}
+
+func CreateComplexGPUBuffer(g *gpu.GPU, elementCount int) (*gpu.ComplexGPUBuffer, error) {
+	if !g.Initialized {
+		return nil, fmt.Errorf("%w: GPU context not initialized", gpu.ErrNoGLContext)
+	}
+
+	// Complex data requires 2x space (real + imaginary as float32 pairs)
+	sizeBytes := elementCount * 8 // 2 * sizeof(float32)
+
+	// Use the same method that works for regular buffers
+	memBuffer, err := AllocateGPUMemory(g, sizeBytes)
+	if err != nil {
+		return nil, fmt.Errorf("failed to allocate GPU memory for complex buffer: %w", err)
+	}
+
+	// Wrap the GPUMemoryBuffer in a ComplexGPUBuffer
+	return &gpu.ComplexGPUBuffer{BufferID: memBuffer.BufferID, Size: elementCount}, nil
+}
+
+func UploadComplexData(buffer *gpu.ComplexGPUBuffer, data []complex128) error {
+	if buffer.BufferID == 0 {
+		return fmt.Errorf("invalid complex GPU buffer")
+	}
+
+	if len(data) > buffer.Size {
+		return gpu.NewBufferTooSmallError(len(data), buffer.Size)
+	}
+
+	// Convert complex128 to interleaved float32 (real, imag, real, imag, ...)
+	float32Data := make([]float32, len(data)*2)
+	for i, c := range data {
+		float32Data[i*2] = float32(real(c))
+		float32Data[i*2+1] = float32(imag(c))
+	}
+
+	expectedSize := len(float32Data) * 4 // float32 = 4 bytes
+	gl.BindBuffer(gl.SHADER_STORAGE_BUFFER, buffer.BufferID)
+	gl.BufferSubData(gl.SHADER_STORAGE_BUFFER, 0, expectedSize, gl.Ptr(float32Data))
+	gl.MemoryBarrier(gl.SHADER_STORAGE_BARRIER_BIT)
+
+	if err := glError("complex data upload"); err != nil {
+		return err
+	}
+
+	return nil
+}
+
+func DownloadComplexData(buffer *gpu.ComplexGPUBuffer, elementCount int) ([]complex128, error) {
+	if buffer.BufferID == 0 {
+		return nil, fmt.Errorf("invalid complex GPU buffer")
+	}
+
+	if elementCount > buffer.Size {
+		return nil, gpu.NewBufferTooSmallError(elementCount, buffer.Size)
+	}
+
+	// Download as interleaved float32, then convert to complex128
+	float32Data := make([]float32, elementCount*2)
+	expectedSize := len(float32Data) * 4 // float32 = 4 bytes
+
+	gl.BindBuffer(gl.SHADER_STORAGE_BUFFER, buffer.BufferID)
+	gl.GetBufferSubData(gl.SHADER_STORAGE_BUFFER, 0, expectedSize, gl.Ptr(float32Data))
+
+	if err := glError("complex data download"); err != nil {
+		return nil, err
+	}
+
+	// Convert float32 pairs back to complex128
+	data := make([]complex128, elementCount)
+	for i := 0; i < elementCount; i++ {
+		realData := float64(float32Data[i*2])
+		imagData := float64(float32Data[i*2+1])
+		data[i] = complex(realData, imagData)
+	}
+
+	return data, nil
+}
+
+func FreeComplexGPUBuffer(buffer *gpu.ComplexGPUBuffer) error {
+	if buffer.BufferID != 0 {
+		gl.DeleteBuffers(1, &buffer.BufferID)
+		buffer.BufferID = 0
+	}
+	return nil
+}
+
+func ExecuteFFT(plan *gpu.GPUFFTPlan, inputBuffer, outputBuffer *gpu.ComplexGPUBuffer) error {
+	if inputBuffer == nil || outputBuffer == nil {
+		return fmt.Errorf("input and output buffers must not be nil")
+	}
+
+	totalSize := plan.Width * plan.Height
+
+	// The plan keeps its shader across executions
+	fftShader, err := planShader(plan)
+	if err != nil {
+		return fmt.Errorf("failed to compile FFT shader: %w", err)
+	}
+
+	// Check if we're using Cooley-Tukey (power of 2) or fallback naive DFT
+	if !isPowerOfTwo(plan.Width) || !isPowerOfTwo(plan.Height) {
+		// Naive DFT - single pass
+		return executeNaiveFFT(plan, fftShader, inputBuffer, outputBuffer, totalSize)
+	}
+
+	// Try Cooley-Tukey FFT - multi-stage execution
+	err = executeCooleyTukeyFFT(plan, fftShader, inputBuffer, outputBuffer)
+	if err != nil {
+		// Fallback to naive DFT if Cooley-Tukey implementation is incomplete
+		// This allows progressive implementation while maintaining functionality
+		// Create naive DFT shader for fallback
+		naiveFftShader, naiveErr := compileNaiveDFTShader(plan.Gpu, plan.Width, plan.Height, plan.IsForward)
+		if naiveErr != nil {
+			return fmt.Errorf("Cooley-Tukey failed (%w) and naive fallback failed (%w)", err, naiveErr)
+		}
+		defer func() {
+			_ = DeleteComputeShader(naiveFftShader)
+		}()
+
+		return executeNaiveFFT(plan, naiveFftShader, inputBuffer, outputBuffer, totalSize)
+	}
+
+	return nil
+}
+
+func executeNaiveFFT(plan *gpu.GPUFFTPlan, shader *gpu.ComputeShader, inputBuffer, outputBuffer *gpu.ComplexGPUBuffer, totalSize int) error {
+	// Single-pass naive DFT
+	gl.BindBufferBase(gl.SHADER_STORAGE_BUFFER, 0, inputBuffer.BufferID)
+	gl.BindBufferBase(gl.SHADER_STORAGE_BUFFER, 1, outputBuffer.BufferID)
+
+	gl.UseProgram(shader.ProgramID)
+
+	groupSize := plan.Gpu.Workgroups.DFTSize()
+	workGroupsX := uint32((totalSize + groupSize - 1) / groupSize)
+	gl.DispatchCompute(workGroupsX, 1, 1)
+	gl.MemoryBarrier(gl.SHADER_STORAGE_BARRIER_BIT)
+
+	if err := glError("naive FFT execution"); err != nil {
+		return err
+	}
+
+	return nil
+}
+
+func executeCooleyTukeyFFT(plan *gpu.GPUFFTPlan, shader *gpu.ComputeShader, inputBuffer, outputBuffer *gpu.ComplexGPUBuffer) error {
+	gl.UseProgram(shader.ProgramID)
+
+	// Get uniform locations
+	stageLocation := gl.GetUniformLocation(shader.ProgramID, gl.Str("stage\x00"))
+	directionLocation := gl.GetUniformLocation(shader.ProgramID, gl.Str("direction_flag\x00"))
+	columnPassLocation := gl.GetUniformLocation(shader.ProgramID, gl.Str("is_column_pass\x00"))
+
+	direction := int32(1)
+	if !plan.IsForward {
+		direction = -1
+	}
+	gl.Uniform1i(directionLocation, direction)
+
+	// The plan keeps its buffer for ping-pong operations across executions
+	if plan.Temp == nil {
+		temp, err := createTempComplexBuffer(plan, plan.Width*plan.Height)
+		if err != nil {
+			return fmt.Errorf("failed to create temp buffer: %w", err)
+		}
+		plan.Temp = temp
+	}
+	tempBuffer := plan.Temp
+
+	currentInput := inputBuffer
+	currentOutput := tempBuffer
+
+	// Phase 1: Row-wise FFT
+	gl.Uniform1i(columnPassLocation, 0) // Row pass
+	totalSize := uint32(plan.Width * plan.Height)
+	groupSize := uint32(plan.Gpu.Workgroups.FFTSize())
+	workGroups := (totalSize + groupSize - 1) / groupSize
+
+	// Row bit-reversal pass
+	gl.Uniform1i(stageLocation, -1) // Special stage for a bit of reversal
+	gl.BindBufferBase(gl.SHADER_STORAGE_BUFFER, 0, currentInput.BufferID)
+	gl.BindBufferBase(gl.SHADER_STORAGE_BUFFER, 1, currentOutput.BufferID)
+	gl.DispatchCompute(workGroups, 1, 1)
+	gl.MemoryBarrier(gl.SHADER_STORAGE_BARRIER_BIT)
+
+	// Swap buffers
+	currentInput, currentOutput = currentOutput, currentInput
+
+	// Row butterfly stages, along the fastest index j
+	numStages := int(math.Log2(float64(plan.Height)))
+	for stage := 0; stage < numStages; stage++ {
+		gl.Uniform1i(stageLocation, int32(stage))
+		gl.BindBufferBase(gl.SHADER_STORAGE_BUFFER, 0, currentInput.BufferID)
+		gl.BindBufferBase(gl.SHADER_STORAGE_BUFFER, 1, currentOutput.BufferID)
+		gl.DispatchCompute(workGroups, 1, 1)
+		gl.MemoryBarrier(gl.SHADER_STORAGE_BARRIER_BIT)
+
+		// Swap buffers
+		currentInput, currentOutput = currentOutput, currentInput
+	}
+
+	// Phase 2: Column-wise FFT
+	gl.Uniform1i(columnPassLocation, 1) // Column pass
+
+	// Column bit-reversal pass
+	gl.Uniform1i(stageLocation, -1)
+	gl.BindBufferBase(gl.SHADER_STORAGE_BUFFER, 0, currentInput.BufferID)
+	gl.BindBufferBase(gl.SHADER_STORAGE_BUFFER, 1, currentOutput.BufferID)
+	gl.DispatchCompute(workGroups, 1, 1)
+	gl.MemoryBarrier(gl.SHADER_STORAGE_BARRIER_BIT)
+
+	// Swap buffers
+	currentInput, currentOutput = currentOutput, currentInput
+
+	// Column butterfly stages, along i
+	numStages = int(math.Log2(float64(plan.Width)))
+	for stage := 0; stage < numStages; stage++ {
+		gl.Uniform1i(stageLocation, int32(stage))
+		gl.BindBufferBase(gl.SHADER_STORAGE_BUFFER, 0, currentInput.BufferID)
+		gl.BindBufferBase(gl.SHADER_STORAGE_BUFFER, 1, currentOutput.BufferID)
+		gl.DispatchCompute(workGroups, 1, 1)
+		gl.MemoryBarrier(gl.SHADER_STORAGE_BARRIER_BIT)
+
+		// Swap buffers
+		currentInput, currentOutput = currentOutput, currentInput
+	}
+
+	// Copy final result to output buffer (if needed)
+	if currentInput != outputBuffer {
+		if err := copyComplexBuffer(plan, currentInput, outputBuffer); err != nil {
+			return fmt.Errorf("failed to copy final result: %w", err)
+		}
+	}
+
+	if err := glError("Cooley-Tukey FFT"); err != nil {
+		return err
+	}
+
+	return nil
+}
+
+func createTempComplexBuffer(plan *gpu.GPUFFTPlan, elementCount int) (*gpu.ComplexGPUBuffer, error) {
+	// Create a temporary complex buffer for intermediate FFT operations
+	tempBuffer, err := CreateComplexGPUBuffer(plan.Gpu, elementCount)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create temporary complex buffer: %w", err)
+	}
+	return tempBuffer, nil
+}
+
+func copyComplexBuffer(plan *gpu.GPUFFTPlan, src, dst *gpu.ComplexGPUBuffer) error {
+	// Copy data between complex GPU buffers using OpenGL buffer copy
+	if src.BufferID == 0 || dst.BufferID == 0 {
+		return fmt.Errorf("invalid buffer IDs for copy operation")
+	}
+
+	// Calculate byte size (complex elements * 8 bytes per element)
+	byteSize := src.Size * 8
+	if dst.Size < src.Size {
+		return gpu.NewBufferTooSmallError(src.Size, dst.Size)
+	}
+
+	// Use OpenGL's efficient buffer-to-buffer copy
+	gl.BindBuffer(gl.COPY_READ_BUFFER, src.BufferID)
+	gl.BindBuffer(gl.COPY_WRITE_BUFFER, dst.BufferID)
+	gl.CopyBufferSubData(gl.COPY_READ_BUFFER, gl.COPY_WRITE_BUFFER, 0, 0, byteSize)
+
+	// Unbind buffers
+	gl.BindBuffer(gl.COPY_READ_BUFFER, 0)
+	gl.BindBuffer(gl.COPY_WRITE_BUFFER, 0)
+
+	if err := glError("buffer copy"); err != nil {
+		return err
+	}
+
+	return nil
+}
+
+func DestroyFFTPlan(plan *gpu.GPUFFTPlan) error {
+	// Clean up any allocated resources
+	if plan.Shader != nil {
+		_ = DeleteComputeShader(plan.Shader)
+		plan.Shader = nil
+	}
+	if plan.Temp != nil {
+		err := FreeComplexGPUBuffer(plan.Temp)
+		plan.Temp = nil
+		return err
+	}
+	return nil
+}
+
+// cachedFFTPlan returns the plan for a width×height transform from the
+// GPU's plan cache, creating it on first use
+func cachedFFTPlan(g *gpu.GPU, width, height int, forward bool) (*gpu.GPUFFTPlan, error) {
+	if g.FftPlanCache == nil {
+		g.FftPlanCache = gpu.NewFFTPlanCache(gpu.DefaultFFTPlanCapacity)
+	}
+	key := gpu.FFTPlanKey{Width: width, Height: height, Forward: forward, Precision: physics.PrecisionFloat32}
+	return g.FftPlanCache.Get(key, func() (*gpu.GPUFFTPlan, error) {
+		return CreateGPUFFTPlan2D(g, width, height, forward)
+	}, func(plan *gpu.GPUFFTPlan) {
+		_ = DestroyFFTPlan(plan)
+	})
+}
+
+// planShader returns the FFT kernel of plan, compiling it on first use and
+// again if the work group size was retuned since
+func planShader(plan *gpu.GPUFFTPlan) (*gpu.ComputeShader, error) {
+	groupSize := plan.Gpu.Workgroups.FFTSize()
+	if !isPowerOfTwo(plan.Width) || !isPowerOfTwo(plan.Height) {
+		groupSize = plan.Gpu.Workgroups.DFTSize()
+	}
+	if plan.Shader != nil && plan.GroupSize == groupSize {
+		return plan.Shader, nil
+	}
+	shader, err := compileFFTComputeShader(plan.Gpu, plan.Width, plan.Height, plan.IsForward)
+	if err != nil {
+		return nil, err
+	}
+	if plan.Shader != nil {
+		_ = DeleteComputeShader(plan.Shader)
+	}
+	plan.Shader, plan.GroupSize = shader, groupSize
+	return shader, nil
+}
+
+func isPowerOfTwo(n int) bool {
+	return n > 0 && (n&(n-1)) == 0
+}
+
+func compileNaiveDFTShader(g *gpu.GPU, width, height int, isForward bool) (*gpu.ComputeShader, error) {
+	// Fallback to O(N²) DFT implementation for non-power-of-2 sizes
+	shaderSource := shaderSources.GenerateNaiveDFTShader(width, height, isForward, g.Workgroups.DFTSize())
+
+	programID, err := buildComputeProgram(g, "naive DFT compute", shaderSource)
+	if err != nil {
+		return nil, err
+	}
+	return &gpu.ComputeShader{ProgramID: programID}, nil
+}
+
+func compileFFTComputeShader(g *gpu.GPU, width, height int, isForward bool) (*gpu.ComputeShader, error) {
+	// O(N log N) Cooley-Tukey FFT implementation for GPU
+	// Uses separable 2D FFT: row FFTs then column FFTs
+
+	// Check if dimensions are power of 2 (required for Cooley-Tukey)
+	if !isPowerOfTwo(width) || !isPowerOfTwo(height) {
+		return compileNaiveDFTShader(g, width, height, isForward)
+	}
+
+	shaderSource := shaderSources.GenerateCooleyTukeyShader(width, height, g.Workgroups.FFTSize())
+
+	programID, err := buildComputeProgram(g, "FFT compute", shaderSource)
+	if err != nil {
+		return nil, err
+	}
+	return &gpu.ComputeShader{ProgramID: programID}, nil
+}
+
+func SolvePoissonGPU(g *gpu.GPU, densityGrid physics.Grid, dx, gravitationalConstant float64) (physics.Grid, error) {
+	if !g.Initialized {
+		return nil, fmt.Errorf("%w: GPU context not initialized", gpu.ErrNoGLContext)
+	}
+
+	// Match CPU coordinate system: densityGrid[i][j] where i=cfg.SimulationWidth, j=cfg.SimulationDepth
+	width := densityGrid.Width()   // cfg.SimulationWidth (first dimension)
+	height := densityGrid.Height() // cfg.SimulationDepth (second dimension)
+	totalSize := width * height
+	if totalSize == 0 {
+		return nil, fmt.Errorf("%w: empty density grid", physics.ErrInvalidGrid)
+	}
+	tuneWorkgroups(g, width, height)
+	defer g.Timings.Since("Poisson solve", time.Now())
+
+	// Step 1: Upload density grid to GPU as complex data (real part = density, imag = 0)
+	inputBuffer, err := CreateComplexGPUBuffer(g, totalSize)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create input buffer: %w", err)
+	}
+
+	// Convert density to complex128 and upload (match CPU coordinate system)
+	complexData := make([]complex128, totalSize)
+	for idx, v := range densityGrid.Flatten(nil) { // Row-major i*height+j matches CPU reference
+		complexData[idx] = complex(v, 0)
+	}
+
+	start := time.Now()
+	err = UploadComplexData(inputBuffer, complexData)
+	g.Timings.Since("density upload", start)
+	if err != nil {
+		return nil, fmt.Errorf("failed to upload density data: %w", err)
+	}
+
+	// Step 2: Forward FFT
+	fftOutputBuffer, err := CreateComplexGPUBuffer(g, totalSize)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create FFT output buffer: %w", err)
+	}
+
+	// Step 2: Forward FFT (use cached plan if available)
+	fftPlan, err := cachedFFTPlan(g, width, height, true)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create FFT plan: %w", err)
+	}
+
+	start = time.Now()
+	err = ExecuteFFT(fftPlan, inputBuffer, fftOutputBuffer)
+	g.Timings.Since("forward FFT", start)
+	if err != nil {
+		return nil, fmt.Errorf("failed to execute forward FFT: %w", err)
+	}
+
+	// Step 3: Apply Green's function in Fourier space
+	start = time.Now()
+	err = applyGreensFunction(g, fftOutputBuffer, width, height, dx, gravitationalConstant)
+	g.Timings.Since("Green's function", start)
+	if err != nil {
+		return nil, fmt.Errorf("failed to apply Green's function: %w", err)
+	}
+
+	// Step 4: Inverse FFT (use cached plan if available)
+	ifftPlan, err := cachedFFTPlan(g, width, height, false)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create IFFT plan: %w", err)
+	}
+
+	finalBuffer, err := CreateComplexGPUBuffer(g, totalSize)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create final buffer: %w", err)
+	}
+
+	start = time.Now()
+	err = ExecuteFFT(ifftPlan, fftOutputBuffer, finalBuffer)
+	g.Timings.Since("inverse FFT", start)
+	if err != nil {
+		return nil, fmt.Errorf("failed to execute inverse FFT: %w", err)
+	}
+
+	// Keep the potential on the GPU for diagnostics reductions
+	if g.PotentialBuffer != nil {
+		_ = FreeComplexGPUBuffer(g.PotentialBuffer)
+	}
+	g.PotentialBuffer = finalBuffer
+
+	// Step 5: Download result and extract real part
+	start = time.Now()
+	resultData, err := DownloadComplexData(finalBuffer, totalSize)
+	g.Timings.Since("potential download", start)
+	if err != nil {
+		return nil, fmt.Errorf("failed to download result: %w", err)
+	}
+
+	// Apply inverse FFT normalization to match CPU go-dsp library behavior
+	// The CPU library auto-normalizes, but GPU IFFT may not
+	normalizationFactor := 1.0 / float64(totalSize)
+	for i := range resultData {
+		resultData[i] *= complex(normalizationFactor, 0)
+	}
+
+	// Convert back to 2D real grid (match CPU coordinate system)
+	potentialGrid := physics.NewGrid(width, height)
+	idx := 0
+	for i := 0; i < width; i++ {
+		for j := 0; j < height; j++ {
+			potentialGrid.SetUnchecked(i, j, real(resultData[idx])) // Use i,j to match CPU coordinate system
+			idx++
+		}
+	}
+
+	return potentialGrid, nil
+}
+
+// tuneWorkgroups sets the GPU's work group sizes for a grid size. Sizes
+// stored for this driver and grid are loaded; otherwise each candidate of the
+// FFT (or DFT) and Green's function kernels is benchmarked once and the
+// fastest are stored. Without a tuner the defaults stay in place
+func tuneWorkgroups(g *gpu.GPU, width, height int) {
+	t := g.Tuner
+	if t == nil || t.Grid == t.Key(width, height) {
+		return
+	}
+	t.Grid = t.Key(width, height)
+	if w, ok := t.Load(width, height); ok {
+		g.Workgroups = w
+		return
+	}
+
+	totalSize := width * height
+	input, err := CreateComplexGPUBuffer(g, totalSize)
+	if err != nil {
+		return
+	}
+	defer func() { _ = FreeComplexGPUBuffer(input) }()
+	output, err := CreateComplexGPUBuffer(g, totalSize)
+	if err != nil {
+		return
+	}
+	defer func() { _ = FreeComplexGPUBuffer(output) }()
+
+	const runs = 3
+	maxSize := maxWorkgroupSize()
+	tuned := g.Workgroups
+	plan := &gpu.GPUFFTPlan{Gpu: g, Width: width, Height: height, IsForward: true}
+	radix2 := isPowerOfTwo(width) && isPowerOfTwo(height)
+
+	// Compile each FFT variant during its warm-up run, outside the timing
+	shaders := make(map[int]*gpu.ComputeShader)
+	defer func() {
+		for _, shader := range shaders {
+			_ = DeleteComputeShader(shader)
+		}
+	}()
+	fft, err := gpu.FastestSize(gpu.WorkgroupCandidates, maxSize, runs, func(size int) error {
+		g.Workgroups.FFT, g.Workgroups.DFT = size, size
+		shader, ok := shaders[size]
+		if !ok {
+			var err error
+			if shader, err = compileFFTComputeShader(g, width, height, true); err != nil {
+				return err
+			}
+			shaders[size] = shader
+		}
+		if radix2 {
+			err = executeCooleyTukeyFFT(plan, shader, input, output)
+		} else {
+			err = executeNaiveFFT(plan, shader, input, output, totalSize)
+		}
+		gl.Finish()
+		return err
+	})
+	if err == nil && radix2 {
+		tuned.FFT = fft
+	} else if err == nil {
+		tuned.DFT = fft
+	}
+
+	greens, err := gpu.FastestSize(gpu.WorkgroupCandidates, maxSize, runs, func(size int) error {
+		g.Workgroups.Greens = size
+		err := applyGreensFunction(g, output, width, height, 1, 1)
+		gl.Finish()
+		return err
+	})
+	if err == nil {
+		tuned.Greens = greens
+	}
+
+	g.Workgroups = tuned
+	_ = t.Store(width, height, tuned) // A failure only repeats the tuning next run
+}
+
+// maxWorkgroupSize returns the largest one-dimensional work group the GPU runs
+func maxWorkgroupSize() int {
+	var size, invocations int32
+	gl.GetIntegeri_v(gl.MAX_COMPUTE_WORK_GROUP_SIZE, 0, &size)
+	gl.GetIntegerv(gl.MAX_COMPUTE_WORK_GROUP_INVOCATIONS, &invocations)
+	return int(min(size, invocations))
+}
+
+// applyGreensFunction applies Green's function kernel in Fourier space for
+// cells of size dx
+func applyGreensFunction(g *gpu.GPU, buffer *gpu.ComplexGPUBuffer, width, height int, dx, gravitationalConstant float64) error {
+	// Create compute shader for Green's function
+	params := gpu.KernelParams{Gravity: gpu.KernelNewtonian, LocalSize: g.Workgroups.GreensSize()}
+	shaderSource := shaderSources.GenerateGreensFunctionShader(params)
+
+	// Use cached shader if available
+	shaderKey := "greens_function_" + params.Key()
+	shader, exists := g.ShaderCache[shaderKey]
+	if !exists {
+		var err error
+		shader, err = CompileComputeShader(g, shaderSource)
+		if err != nil {
+			return fmt.Errorf("failed to compile Green's function shader: %w", err)
+		}
+		g.ShaderCache[shaderKey] = shader
+	}
+
+	// Bind buffer and set uniforms
+	gl.UseProgram(shader.ProgramID)
+
+	// Bind buffer to shader storage buffer object
+	gl.BindBufferBase(gl.SHADER_STORAGE_BUFFER, 0, buffer.BufferID)
+
+	// Set uniforms. The density holds mass per cell, so G is divided by the
+	// cell area to make it a density, as in physics.SolvePoissonFFTInto
+	kxFactor := 2.0 * math.Pi / (float64(width) * dx)
+	kzFactor := 2.0 * math.Pi / (float64(height) * dx)
+
+	widthLoc := gl.GetUniformLocation(shader.ProgramID, gl.Str("uWidth\x00"))
+	heightLoc := gl.GetUniformLocation(shader.ProgramID, gl.Str("uHeight\x00"))
+	gravitationalConstantLoc := gl.GetUniformLocation(shader.ProgramID, gl.Str("uGConstant\x00"))
+	kxFactorLoc := gl.GetUniformLocation(shader.ProgramID, gl.Str("uKxFactor\x00"))
+	kzFactorLoc := gl.GetUniformLocation(shader.ProgramID, gl.Str("uKzFactor\x00"))
+
+	gl.Uniform1i(widthLoc, int32(width))
+	gl.Uniform1i(heightLoc, int32(height))
+	gl.Uniform1f(gravitationalConstantLoc, float32(gravitationalConstant/(dx*dx)))
+	gl.Uniform1f(kxFactorLoc, float32(kxFactor))
+	gl.Uniform1f(kzFactorLoc, float32(kzFactor))
+
+	// Dispatch compute shader
+	totalSize := width * height
+	groupSize := params.GroupSize()
+	workGroups := (totalSize + groupSize - 1) / groupSize // Round up to handle all elements
+	gl.DispatchCompute(uint32(workGroups), 1, 1)
+
+	// Memory barrier to ensure shader writes are visible
+	gl.MemoryBarrier(gl.SHADER_STORAGE_BARRIER_BIT)
+
+	// Check for OpenGL errors
+	if err := glError("Green's function shader"); err != nil {
+		return err
+	}
+
+	return nil
+}
+
+// GradientGPU computes the acceleration field a = -∇Φ of the potential in
+// the real part of a complex GPU buffer, scaled by scale (e.g. the inverse
+// FFT normalization), with periodic central differences over 2·dx like
+// physics.CalculateGradientInto
+func GradientGPU(g *gpu.GPU, potential *gpu.ComplexGPUBuffer, width, height int, dx, scale float64) (*physics.ForceField, error) {
+	if !g.Initialized {
+		return nil, fmt.Errorf("%w: GPU context not initialized", gpu.ErrNoGLContext)
+	}
+	defer g.Timings.Since("gradient", time.Now())
+	totalSize := width * height
+	if potential == nil || potential.Size < totalSize {
+		return nil, fmt.Errorf("%w: potential buffer does not cover %dx%d", physics.ErrInvalidGrid, width, height)
+	}
+
+	params := gpu.KernelParams{Boundary: gpu.BoundaryPeriodic}
+	shaderKey := "gradient_" + params.Key()
+	shader, exists := g.ShaderCache[shaderKey]
+	if !exists {
+		var err error
+		shader, err = CompileComputeShader(g, shaderSources.GenerateGradientShader(params))
+		if err != nil {
+			return nil, fmt.Errorf("failed to compile gradient shader: %w", err)
+		}
+		g.ShaderCache[shaderKey] = shader
+	}
+
+	// The (ax, az) pairs have the layout of a complex buffer
+	accelBuffer, err := CreateComplexGPUBuffer(g, totalSize)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create acceleration buffer: %w", err)
+	}
+	defer FreeComplexGPUBuffer(accelBuffer)
+
+	gl.UseProgram(shader.ProgramID)
+	gl.BindBufferBase(gl.SHADER_STORAGE_BUFFER, 0, potential.BufferID)
+	gl.BindBufferBase(gl.SHADER_STORAGE_BUFFER, 1, accelBuffer.BufferID)
+	gl.Uniform1i(gl.GetUniformLocation(shader.ProgramID, gl.Str("uWidth\x00")), int32(width))
+	gl.Uniform1i(gl.GetUniformLocation(shader.ProgramID, gl.Str("uHeight\x00")), int32(height))
+	gl.Uniform1f(gl.GetUniformLocation(shader.ProgramID, gl.Str("uScale\x00")), float32(scale/dx))
+
+	groupSize := params.GroupSize()
+	gl.DispatchCompute(uint32((totalSize+groupSize-1)/groupSize), 1, 1)
+	gl.MemoryBarrier(gl.SHADER_STORAGE_BARRIER_BIT)
+	if err := glError("gradient shader"); err != nil {
+		return nil, err
+	}
+
+	accel, err := DownloadComplexData(accelBuffer, totalSize)
+	if err != nil {
+		return nil, fmt.Errorf("failed to download accelerations: %w", err)
+	}
+	forceField := physics.NewForceField(width, height, dx)
+	for idx, a := range accel {
+		i, j := idx/height, idx%height
+		forceField.AccelFieldX.SetUnchecked(i, j, real(a))
+		forceField.AccelFieldZ.SetUnchecked(i, j, imag(a))
+	}
+	return forceField, nil
+}
+
+// DirectAccelerationsGPU computes direct-summation accelerations with the
+// tiled all-pairs compute shader, matching physics.DirectAccelerationsPeriodic
+// up to float32 rounding. Periods <= 0 leave that axis unbounded
+func DirectAccelerationsGPU(g *gpu.GPU, particles []*physics.Particle, gravitationalConstant, softening, periodX, periodZ float64) (ax, az []float64, err error) {
+	if !g.Initialized {
+		return nil, nil, fmt.Errorf("%w: GPU context not initialized", gpu.ErrNoGLContext)
+	}
+	defer g.Timings.Since("direct forces", time.Now())
+	n := len(particles)
+	ax = make([]float64, n)
+	az = make([]float64, n)
+	if n == 0 {
+		return ax, az, nil
+	}
+
+	shaderKey := fmt.Sprintf("direct_force_%d", gpu.DirectTileSize)
+	shader, exists := g.ShaderCache[shaderKey]
+	if !exists {
+		shader, err = CompileComputeShader(g, gpu.GenerateDirectForceShader(gpu.DirectTileSize))
+		if err != nil {
+			return nil, nil, fmt.Errorf("failed to compile direct force shader: %w", err)
+		}
+		g.ShaderCache[shaderKey] = shader
+	}
+
+	// Step 1: Upload positions and masses as vec4 bodies
+	xs := make([]float64, n)
+	zs := make([]float64, n)
+	ms := make([]float64, n)
+	for i, p := range particles {
+		xs[i], zs[i], ms[i] = p.Position.X, p.Position.Z, float64(p.Mass)
+	}
+	bodyBytes := n * 4 * 4
+	var bodyBufferID uint32
+	var slot *gpu.UploadSlot
+	if g.BufferStorage {
+		// Write straight into a persistently mapped ring buffer
+		slot, err = AcquireUploadSlot(g, bodyBytes)
+		if err != nil {
+			return nil, nil, fmt.Errorf("failed to acquire body upload buffer: %w", err)
+		}
+		gpu.PackDirectBodies(slot.Data, xs, zs, ms)
+		bodyBufferID = slot.BufferID
+	} else {
+		bodies := gpu.PackDirectBodies(nil, xs, zs, ms)
+		bodyBuffer, err := AllocateGPUMemory(g, bodyBytes)
+		if err != nil {
+			return nil, nil, fmt.Errorf("failed to create body buffer: %w", err)
+		}
+		defer gl.DeleteBuffers(1, &bodyBuffer.BufferID)
+		gl.BufferSubData(gl.SHADER_STORAGE_BUFFER, 0, bodyBytes, gl.Ptr(bodies))
+		bodyBufferID = bodyBuffer.BufferID
+	}
+
+	accelBuffer, err := AllocateGPUMemory(g, n*2*4)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to create acceleration buffer: %w", err)
+	}
+	defer gl.DeleteBuffers(1, &accelBuffer.BufferID)
+
+	// Step 2: Dispatch one invocation per body
+	gl.UseProgram(shader.ProgramID)
+	gl.BindBufferBase(gl.SHADER_STORAGE_BUFFER, gpu.DirectBodiesBinding, bodyBufferID)
+	gl.BindBufferBase(gl.SHADER_STORAGE_BUFFER, gpu.DirectAccelerationsBinding, accelBuffer.BufferID)
+
+	gl.Uniform1i(gl.GetUniformLocation(shader.ProgramID, gl.Str("uCount\x00")), int32(n))
+	gl.Uniform1f(gl.GetUniformLocation(shader.ProgramID, gl.Str("uCoupling\x00")), float32(-2*gravitationalConstant))
+	gl.Uniform1f(gl.GetUniformLocation(shader.ProgramID, gl.Str("uSoftening2\x00")), float32(softening*softening))
+	gl.Uniform2f(gl.GetUniformLocation(shader.ProgramID, gl.Str("uPeriod\x00")), float32(periodX), float32(periodZ))
+
+	gl.DispatchCompute(uint32(gpu.DirectWorkGroups(n, gpu.DirectTileSize)), 1, 1)
+	gl.MemoryBarrier(gl.SHADER_STORAGE_BARRIER_BIT)
+	if slot != nil {
+		FenceUploadSlot(slot)
+	}
+
+	// Step 3: Download the accelerations
+	result := make([]float32, n*2)
+	gl.BindBuffer(gl.SHADER_STORAGE_BUFFER, accelBuffer.BufferID)
+	gl.GetBufferSubData(gl.SHADER_STORAGE_BUFFER, 0, len(result)*4, gl.Ptr(result))
+
+	if err := glError("direct force shader"); err != nil {
+		return nil, nil, err
+	}
+
+	for i := range ax {
+		ax[i], az[i] = float64(result[2*i]), float64(result[2*i+1])
+	}
+	return ax, az, nil
+}
+
+// ReduceGPU runs a multi-pass parallel reduction of kind over count elements
+// of the input buffer and downloads only the final vec4
+func ReduceGPU(g *gpu.GPU, kind gpu.ReductionKind, input uint32, count int, scale float32) ([4]float32, error) {
+	var result [4]float32
+	if !g.Initialized {
+		return result, fmt.Errorf("%w: GPU context not initialized", gpu.ErrNoGLContext)
+	}
+	defer g.Timings.Since("reduction", time.Now())
+	passes := gpu.ReductionPasses(count, gpu.ReductionLocalSize)
+	if len(passes) == 0 {
+		return result, fmt.Errorf("empty reduction input")
+	}
+
+	// Ping-pong between two partial buffers sized for the first pass output
+	var partials [2]*gpu.GPUMemoryBuffer
+	for i := range partials {
+		buffer, err := AllocateGPUMemory(g, gpu.ReductionGroups(count, gpu.ReductionLocalSize)*16)
+		if err != nil {
+			return result, fmt.Errorf("failed to create reduction buffer: %w", err)
+		}
+		defer gl.DeleteBuffers(1, &buffer.BufferID)
+		partials[i] = buffer
+	}
+
+	source := input
+	for pass, n := range passes {
+		firstPass := pass == 0
+		shaderKey := fmt.Sprintf("reduction_%d_%t", kind, firstPass)
+		shader, exists := g.ShaderCache[shaderKey]
+		if !exists {
+			var err error
+			shader, err = CompileComputeShader(g, gpu.GenerateReductionShader(kind, gpu.ReductionLocalSize, firstPass))
+			if err != nil {
+				return result, fmt.Errorf("failed to compile reduction shader: %w", err)
+			}
+			g.ShaderCache[shaderKey] = shader
+		}
+
+		output := partials[pass%2]
+		gl.UseProgram(shader.ProgramID)
+		gl.BindBufferBase(gl.SHADER_STORAGE_BUFFER, gpu.ReductionInputBinding, source)
+		gl.BindBufferBase(gl.SHADER_STORAGE_BUFFER, gpu.ReductionOutputBinding, output.BufferID)
+		gl.Uniform1ui(gl.GetUniformLocation(shader.ProgramID, gl.Str("uCount\x00")), uint32(n))
+		gl.Uniform1f(gl.GetUniformLocation(shader.ProgramID, gl.Str("uScale\x00")), scale)
+		gl.DispatchCompute(uint32(gpu.ReductionGroups(n, gpu.ReductionLocalSize)), 1, 1)
+		gl.MemoryBarrier(gl.SHADER_STORAGE_BARRIER_BIT)
+		source = output.BufferID
+	}
+
+	gl.BindBuffer(gl.SHADER_STORAGE_BUFFER, source)
+	gl.GetBufferSubData(gl.SHADER_STORAGE_BUFFER, 0, 16, gl.Ptr(&result[0]))
+	if err := glError("reduction"); err != nil {
+		return result, err
+	}
+	return result, nil
+}
+
+// ParticleMomentsGPU uploads particle velocities and masses and reduces them
+// to the total kinetic energy, momentum and mass on the GPU
+func ParticleMomentsGPU(g *gpu.GPU, particles []*physics.Particle) (gpu.ParticleMoments, error) {
+	n := len(particles)
+	if n == 0 {
+		return gpu.ParticleMoments{}, nil
+	}
+	xs := make([]float64, n)
+	zs := make([]float64, n)
+	ms := make([]float64, n)
+	for i, p := range particles {
+		xs[i], zs[i], ms[i] = p.Velocity.X, p.Velocity.Z, float64(p.Mass)
+	}
+
+	// The moments reduction reads the same vec4 layout as the direct kernel's bodies
+	sizeBytes := n * 4 * 4
+	var bufferID uint32
+	var slot *gpu.UploadSlot
+	if g.BufferStorage {
+		var err error
+		slot, err = AcquireUploadSlot(g, sizeBytes)
+		if err != nil {
+			return gpu.ParticleMoments{}, fmt.Errorf("failed to acquire upload buffer: %w", err)
+		}
+		gpu.PackDirectBodies(slot.Data, xs, zs, ms)
+		bufferID = slot.BufferID
+	} else {
+		buffer, err := AllocateGPUMemory(g, sizeBytes)
+		if err != nil {
+			return gpu.ParticleMoments{}, fmt.Errorf("failed to create particle buffer: %w", err)
+		}
+		defer gl.DeleteBuffers(1, &buffer.BufferID)
+		gl.BufferSubData(gl.SHADER_STORAGE_BUFFER, 0, sizeBytes, gl.Ptr(gpu.PackDirectBodies(nil, xs, zs, ms)))
+		bufferID = buffer.BufferID
+	}
+
+	result, err := ReduceGPU(g, gpu.ReduceMoments, bufferID, n, 1)
+	if slot != nil {
+		FenceUploadSlot(slot)
+	}
+	if err != nil {
+		return gpu.ParticleMoments{}, err
+	}
+	return gpu.NewParticleMoments(result), nil
+}
+
+// PotentialRangeGPU returns the minimum and maximum of the potential from the
+// last GPU Poisson solve without downloading the grid
+func PotentialRangeGPU(g *gpu.GPU) (gpu.PotentialRange, error) {
+	if g.PotentialBuffer == nil {
+		return gpu.PotentialRange{}, fmt.Errorf("no GPU potential available")
+	}
+	// Apply the inverse FFT normalization that SolvePoissonGPU applies on download
+	count := g.PotentialBuffer.Size
+	result, err := ReduceGPU(g, gpu.ReduceRange, g.PotentialBuffer.BufferID, count, float32(1/float64(count)))
+	if err != nil {
+		return gpu.PotentialRange{}, err
+	}
+	return gpu.NewPotentialRange(result), nil
+}
+
+// compileShaderStage compiles one stage of a render program
+func compileShaderStage(stage uint32, source string) (uint32, error) {
+	shaderID := gl.CreateShader(stage)
+	cSources, free := gl.Strs(source + "\x00")
+	gl.ShaderSource(shaderID, 1, cSources, nil)
+	free()
+	gl.CompileShader(shaderID)
+
+	var status int32
+	gl.GetShaderiv(shaderID, gl.COMPILE_STATUS, &status)
+	if status == gl.FALSE {
+		var logLength int32
+		gl.GetShaderiv(shaderID, gl.INFO_LOG_LENGTH, &logLength)
+
+		log := make([]byte, logLength+1)
+		gl.GetShaderInfoLog(shaderID, logLength, nil, &log[0])
+
+		gl.DeleteShader(shaderID)
+		name := "fragment"
+		if stage == gl.VERTEX_SHADER {
+			name = "vertex"
+		}
+		return 0, &gpu.ShaderError{Shader: name, Stage: "compilation", Log: string(log)}
+	}
+	return shaderID, nil
+}
+
+// CompileRenderProgram compiles and links a vertex/fragment shader program
+func CompileRenderProgram(vertex, fragment string) (uint32, error) {
+	vertexID, err := compileShaderStage(gl.VERTEX_SHADER, vertex)
+	if err != nil {
+		return 0, err
+	}
+	defer gl.DeleteShader(vertexID)
+	fragmentID, err := compileShaderStage(gl.FRAGMENT_SHADER, fragment)
+	if err != nil {
+		return 0, err
+	}
+	defer gl.DeleteShader(fragmentID)
+
+	programID := gl.CreateProgram()
+	gl.AttachShader(programID, vertexID)
+	gl.AttachShader(programID, fragmentID)
+	gl.LinkProgram(programID)
+
+	var status int32
+	gl.GetProgramiv(programID, gl.LINK_STATUS, &status)
+	if status == gl.FALSE {
+		var logLength int32
+		gl.GetProgramiv(programID, gl.INFO_LOG_LENGTH, &logLength)
+
+		log := make([]byte, logLength+1)
+		gl.GetProgramInfoLog(programID, logLength, nil, &log[0])
+
+		gl.DeleteProgram(programID)
+		return 0, &gpu.ShaderError{Shader: "render program", Stage: "linking", Log: string(log)}
+	}
+	return programID, nil
+}
+
+// preparePotentialView creates the program, texture and vertex array used to
+// draw a width x height potential grid, recreating the texture on resize
+func preparePotentialView(g *gpu.GPU, width, height int) error {
+	view := &g.PotentialView
+	if view.ProgramID == 0 {
+		vertex, fragment := gpu.GeneratePotentialViewShaders()
+		programID, err := CompileRenderProgram(vertex, fragment)
+		if err != nil {
+			return fmt.Errorf("failed to compile potential view shader: %w", err)
+		}
+		view.ProgramID = programID
+		gl.GenVertexArrays(1, &view.VertexArrayID)
+	}
+	if view.TextureID != 0 && (view.Width != width || view.Height != height) {
+		gl.DeleteTextures(1, &view.TextureID)
+		view.TextureID = 0
+	}
+	if view.TextureID == 0 {
+		gl.GenTextures(1, &view.TextureID)
+		gl.BindTexture(gl.TEXTURE_2D, view.TextureID)
+		// The texture row is the fastest-varying grid index j, so it is height texels wide
+		gl.TexStorage2D(gl.TEXTURE_2D, 1, gl.RG32F, int32(height), int32(width))
+		gl.TexParameteri(gl.TEXTURE_2D, gl.TEXTURE_MIN_FILTER, gl.NEAREST)
+		gl.TexParameteri(gl.TEXTURE_2D, gl.TEXTURE_MAG_FILTER, gl.NEAREST)
+		gl.BindTexture(gl.TEXTURE_2D, 0)
+		view.Width, view.Height = width, height
+	}
+	return nil
+}
+
+// DrawPotentialGPU draws every stride-th line of the deformed grid from the
+// potential of the last GPU Poisson solve. The potential buffer is copied into
+// a texture on the GPU, so no grid data is read back to the CPU. Lines are
+// coloured from colors.Base to colors.Hot with well depth, and spaced dx
+// apart. Must be called inside BeginMode3D
+func DrawPotentialGPU(g *gpu.GPU, width, height, stride int, dx, visScale float64, colors gpu.PotentialColors) error {
+	if g.PotentialBuffer == nil {
+		return fmt.Errorf("no GPU potential available")
+	}
+	if g.PotentialBuffer.Size != width*height {
+		return fmt.Errorf("%w: GPU potential has %d elements, expected %dx%d", physics.ErrInvalidGrid, g.PotentialBuffer.Size, width, height)
+	}
+	if err := preparePotentialView(g, width, height); err != nil {
+		return err
+	}
+	view := &g.PotentialView
+
+	// Flush raylib's batched draws so they stay ordered with the raw GL draw
+	rl.DrawRenderBatchActive()
+	mvp := rl.MatrixToFloatV(rl.MatrixMultiply(rl.GetMatrixModelview(), rl.GetMatrixProjection()))
+
+	// Make the compute shader writes visible, then copy the buffer into the texture
+	gl.MemoryBarrier(gl.PIXEL_BUFFER_BARRIER_BIT)
+	gl.BindBuffer(gl.PIXEL_UNPACK_BUFFER, g.PotentialBuffer.BufferID)
+	gl.BindTexture(gl.TEXTURE_2D, view.TextureID)
+	gl.TexSubImage2D(gl.TEXTURE_2D, 0, 0, 0, int32(height), int32(width), gl.RG, gl.FLOAT, nil)
+	gl.BindBuffer(gl.PIXEL_UNPACK_BUFFER, 0)
+
+	gl.UseProgram(view.ProgramID)
+	gl.UniformMatrix4fv(gl.GetUniformLocation(view.ProgramID, gl.Str("uMVP\x00")), 1, false, &mvp[0])
+	gl.Uniform2i(gl.GetUniformLocation(view.ProgramID, gl.Str("uGrid\x00")), int32(width), int32(height))
+	gl.Uniform1i(gl.GetUniformLocation(view.ProgramID, gl.Str("uStride\x00")), int32(stride))
+	gl.Uniform1f(gl.GetUniformLocation(view.ProgramID, gl.Str("uNormalization\x00")), float32(1/float64(width*height)))
+	gl.Uniform1f(gl.GetUniformLocation(view.ProgramID, gl.Str("uVisScale\x00")), float32(visScale))
+	gl.Uniform1f(gl.GetUniformLocation(view.ProgramID, gl.Str("uCellSize\x00")), float32(dx))
+	gl.Uniform3fv(gl.GetUniformLocation(view.ProgramID, gl.Str("uBaseColor\x00")), 1, &colors.Base[0])
+	gl.Uniform3fv(gl.GetUniformLocation(view.ProgramID, gl.Str("uHotColor\x00")), 1, &colors.Hot[0])
+	gl.ActiveTexture(gl.TEXTURE0)
+	gl.Uniform1i(gl.GetUniformLocation(view.ProgramID, gl.Str("uPotential\x00")), 0)
+
+	gl.BindVertexArray(view.VertexArrayID)
+	gl.DrawArrays(gl.LINES, 0, int32(gpu.PotentialViewVertexCount(width, height, stride)))
+
+	// Leave the state raylib expects
+	gl.BindVertexArray(0)
+	gl.BindTexture(gl.TEXTURE_2D, 0)
+	gl.UseProgram(0)
+
+	if err := glError("potential drawing"); err != nil {
+		return err
+	}
+	return nil
+}
+
+// releasePotentialView deletes the GL objects of the potential view
+func releasePotentialView(view *gpu.PotentialView) {
+	if view.TextureID != 0 {
+		gl.DeleteTextures(1, &view.TextureID)
+	}
+	if view.VertexArrayID != 0 {
+		gl.DeleteVertexArrays(1, &view.VertexArrayID)
+	}
+	if view.ProgramID != 0 {
+		gl.DeleteProgram(view.ProgramID)
+	}
+	*view = gpu.PotentialView{}
+}
+
+func CleanupGPU(g *gpu.GPU) error {
+	if g.Initialized {
+		// Clean up cached FFT plans
+		if g.FftPlanCache != nil {
+			g.FftPlanCache.Clear(func(plan *gpu.GPUFFTPlan) { _ = DestroyFFTPlan(plan) })
+			g.FftPlanCache = nil
+		}
+
+		// Clean up cached shaders
+		for _, shader := range g.ShaderCache {
+			_ = DeleteComputeShader(shader)
+		}
+		g.ShaderCache = nil
+
+		if g.PotentialBuffer != nil {
+			_ = FreeComplexGPUBuffer(g.PotentialBuffer)
+			g.PotentialBuffer = nil
+		}
+
+		releasePotentialView(&g.PotentialView)
+
+		// Clean up the upload ring
+		for i := range g.UploadRing.Slots {
+			releaseUploadSlot(&g.UploadRing.Slots[i])
+		}
+
+		// Release the shared context; a hidden window closes with its last user
+		if g.NeedsCleanup {
+			glContext.Release()
+			g.NeedsCleanup = false
+		}
+
+		g.Initialized = false
+	}
+	return nil
+}
@@ -0,0 +1,58 @@
+//go:build android
+
+package main
+
+import (
+	"relativity_simulation_2d/internal/gpu"
+	"relativity_simulation_2d/internal/physics"
+)
+
+// Android contexts are OpenGL ES, which the 4.3 compute path cannot use.
+// These stand-ins report ErrNoGLContext so every GPU caller takes its CPU
+// fallback; the window and its drawing still go through raylib
+
+// LoadFunctions reports that the compute path has no OpenGL 4.3 context
+func (raylibPlatform) LoadFunctions() error { return gpu.ErrNoGLContext }
+
+// InitializeGPU reports that no compute-capable context is available
+func InitializeGPU() (*gpu.GPU, error) {
+	return nil, gpu.ErrNoGLContext
+}
+
+// SolvePoissonGPU reports that no compute-capable context is available
+func SolvePoissonGPU(g *gpu.GPU, densityGrid physics.Grid, dx, gravitationalConstant float64) (physics.Grid, error) {
+	return nil, gpu.ErrNoGLContext
+}
+
+// DirectAccelerationsGPU reports that no compute-capable context is available
+func DirectAccelerationsGPU(g *gpu.GPU, particles []*physics.Particle, gravitationalConstant, softening, periodX, periodZ float64) (ax, az []float64, err error) {
+	return nil, nil, gpu.ErrNoGLContext
+}
+
+// ParticleMomentsGPU reports that no compute-capable context is available
+func ParticleMomentsGPU(g *gpu.GPU, particles []*physics.Particle) (gpu.ParticleMoments, error) {
+	return gpu.ParticleMoments{}, gpu.ErrNoGLContext
+}
+
+// PotentialRangeGPU reports that no compute-capable context is available
+func PotentialRangeGPU(g *gpu.GPU) (gpu.PotentialRange, error) {
+	return gpu.PotentialRange{}, gpu.ErrNoGLContext
+}
+
+// DrawPotentialGPU reports that no compute-capable context is available
+func DrawPotentialGPU(g *gpu.GPU, width, height, stride int, dx, visScale float64, colors gpu.PotentialColors) error {
+	return gpu.ErrNoGLContext
+}
+
+// CleanupGPU has nothing to release
+func CleanupGPU(g *gpu.GPU) error {
+	return nil
+}
+
+// GPUMemoryUsed is unknown without a compute context
+func GPUMemoryUsed(g *gpu.GPU) (used uint64, ok bool) {
+	return 0, false
+}
+
+// lastGLError stays nil: no GL calls are made for compute
+var lastGLError error
@@ -1,9 +1,26 @@
+//go:build !js
+
 package main
 
 import (
+	"errors"
+	"fmt"
+	rl "github.com/gen2brain/raylib-go/raylib"
 	"math"
 	"math/cmplx"
+	"os"
+	"path/filepath"
+	"relativity_simulation_2d/internal/config"
+	"relativity_simulation_2d/internal/cuda"
+	"relativity_simulation_2d/internal/governor"
+	"relativity_simulation_2d/internal/gpu"
+	"relativity_simulation_2d/internal/physics"
+	"relativity_simulation_2d/internal/renderer"
+	"relativity_simulation_2d/internal/simulation"
+	"relativity_simulation_2d/internal/slowmo"
+	"relativity_simulation_2d/internal/snapshot"
 	fftpkg "relativity_simulation_2d/pkg/fft"
+	"strings"
 	"testing"
 	"time"
 )
@@ -173,3 +190,1033 @@ func TestFFTPoissonSolver(t *testing.T) {
 			centerPot, edgePot)
 	}
 }
+
+// TestDirectGPUSolverFallback tests that the direct-gpu solver falls back to
+// CPU summation when the GPU is unavailable and then matches the direct solver
+func TestDirectGPUSolverFallback(t *testing.T) {
+	saved := cfg
+	defer func() { cfg = saved }()
+	cfg = config.DefaultConfig()
+	cfg.SimulationWidth, cfg.SimulationDepth = 64, 64
+	cfg.NumParticles = 50
+	cfg.Seed = 7
+
+	cfg.Solver = config.SolverDirect
+	reference := NewSimulation()
+	cfg.Solver = config.SolverDirectGPU
+	sim := NewSimulation()
+	sim.forceGPUInitFailure = true
+
+	for step := 0; step < 3; step++ {
+		cfg.Solver = config.SolverDirect
+		reference.Step(0.1)
+		cfg.Solver = config.SolverDirectGPU
+		sim.Step(0.1)
+	}
+
+	if !sim.HasGPUErrorOccurred() || !sim.fallbackToCPU {
+		t.Fatal("Expected the direct-gpu solver to fall back to the CPU")
+	}
+	for i, p := range sim.Particles {
+		q := reference.Particles[i]
+		if p.Position != q.Position || p.Velocity != q.Velocity {
+			t.Fatalf("Particle %d at %+v, CPU direct solver at %+v", i, p.Position, q.Position)
+		}
+	}
+}
+
+// TestCUDABackendFallback tests that selecting the CUDA backend in a build
+// without it falls back to the CPU step and reports the error
+func TestCUDABackendFallback(t *testing.T) {
+	if cuda.Built {
+		t.Skip("CUDA backend is built in")
+	}
+	saved := cfg
+	defer func() { cfg = saved }()
+	cfg = config.DefaultConfig()
+	cfg.SimulationWidth, cfg.SimulationDepth = 64, 64
+	cfg.NumParticles = 100
+	cfg.Seed = 11
+	cfg.GPUBackend = config.GPUBackendCUDA
+
+	reference := NewSimulation()
+	sim := NewSimulation()
+	reference.Update(0.1)
+	sim.UpdateGPU(0.1)
+
+	if !errors.Is(sim.lastGPUError, cuda.ErrNotBuilt) || !sim.fallbackToCPU {
+		t.Fatalf("Expected a fallback with ErrNotBuilt, got %v", sim.lastGPUError)
+	}
+	if sim.GetStepCount() != 1 {
+		t.Errorf("Expected one completed step, got %d", sim.GetStepCount())
+	}
+	for i, p := range sim.Particles {
+		if p.Position != reference.Particles[i].Position {
+			t.Fatalf("Particle %d at %+v, CPU step at %+v", i, p.Position, reference.Particles[i].Position)
+		}
+	}
+}
+
+// TestPMFallback tests that a failing GPU context moves the particle-mesh
+// step to the CPU backend, matching a step run on the CPU processor from the
+// start
+func TestPMFallback(t *testing.T) {
+	saved := cfg
+	defer func() { cfg = saved }()
+	cfg = config.DefaultConfig()
+	cfg.SimulationWidth, cfg.SimulationDepth = 64, 64
+	cfg.NumParticles = 100
+	cfg.Seed = 5
+
+	reference := NewSimulation()
+	reference.compute.SetMode(gpu.ModeCPU)
+	sim := NewSimulation()
+	sim.forceGPUInitFailure = true
+	for step := 0; step < 2; step++ {
+		reference.UpdateGPU(0.1)
+		sim.UpdateGPU(0.1)
+	}
+
+	if !errors.Is(sim.lastGPUError, gpu.ErrNoGLContext) || !sim.fallbackToCPU {
+		t.Fatalf("Expected a fallback with ErrNoGLContext, got %v", sim.lastGPUError)
+	}
+	if sim.compute.GetProcessor().GetType() != gpu.ProcessorTypeCPU {
+		t.Error("Expected later steps on the CPU processor")
+	}
+	for i, p := range sim.Particles {
+		if p.Position != reference.Particles[i].Position || p.Velocity != reference.Particles[i].Velocity {
+			t.Fatalf("Particle %d at %+v, CPU processor at %+v", i, p.Position, reference.Particles[i].Position)
+		}
+	}
+}
+
+// TestPMNonPowerOfTwoGrid tests that a grid the GPU FFT cannot solve runs
+// on the CPU without touching the GPU, matching the CPU processor, and that
+// switching to the GPU warns about it
+func TestPMNonPowerOfTwoGrid(t *testing.T) {
+	saved, savedGPU := cfg, useGPU
+	defer func() { cfg, useGPU = saved, savedGPU }()
+	cfg = config.DefaultConfig()
+	cfg.SimulationWidth, cfg.SimulationDepth = 48, 40
+	cfg.NumParticles = 100
+	cfg.Seed = 5
+	useGPU = true
+
+	reference := NewSimulation()
+	reference.compute.SetMode(gpu.ModeCPU)
+	sim := NewSimulation()
+	sim.forceGPUInitFailure = true // Any use of the GPU would fail
+	for step := 0; step < 2; step++ {
+		reference.UpdateGPU(0.1)
+		sim.UpdateGPU(0.1)
+	}
+
+	if sim.lastGPUError != nil || sim.fallbackToCPU {
+		t.Fatalf("Expected the GPU to be left alone, got %v", sim.lastGPUError)
+	}
+	for i, p := range sim.Particles {
+		if p.Position != reference.Particles[i].Position || p.Velocity != reference.Particles[i].Velocity {
+			t.Fatalf("Particle %d at %+v, CPU processor at %+v", i, p.Position, reference.Particles[i].Position)
+		}
+	}
+	if warning := gpuFFTWarning(); !strings.Contains(warning, "48x40") {
+		t.Errorf("Expected a warning about the 48x40 grid, got %q", warning)
+	}
+	cfg.GPUBackend = config.GPUBackendCUDA
+	if warning := gpuFFTWarning(); warning != "" {
+		t.Errorf("Expected no warning with the CUDA back end, got %q", warning)
+	}
+}
+
+// TestIncrementalDepositStep tests that CPU steps with incremental
+// deposition follow the same trajectories as full deposits
+func TestIncrementalDepositStep(t *testing.T) {
+	saved, savedGPU := cfg, useGPU
+	defer func() { cfg, useGPU = saved, savedGPU }()
+	cfg = config.DefaultConfig()
+	cfg.SimulationWidth, cfg.SimulationDepth = 64, 64
+	cfg.NumParticles = 50
+	cfg.Seed = 7
+	useGPU = false
+
+	reference := NewSimulation()
+	cfg.IncrementalDeposit = true
+	sim := NewSimulation()
+	for step := 0; step < 5; step++ {
+		reference.updatePMOnCPU(0.1)
+		sim.Step(0.1)
+	}
+
+	for i, p := range sim.Particles {
+		if d := p.Position.Sub(reference.Particles[i].Position).Length(); d > 1e-9 {
+			t.Fatalf("Particle %d is %g from the full-deposit trajectory", i, d)
+		}
+	}
+}
+
+// TestGuardReportsNaN tests that a NaN particle is caught and reported with a snapshot
+func TestGuardReportsNaN(t *testing.T) {
+	saved, savedGPU := cfg, useGPU
+	defer func() { cfg, useGPU = saved, savedGPU }()
+	cfg = config.DefaultConfig()
+	cfg.SimulationWidth, cfg.SimulationDepth = 64, 64
+	cfg.NumParticles = 10
+	cfg.Seed = 7
+	cfg.GuardInterval = 1
+	cfg.CrashReportDir = t.TempDir()
+	useGPU = false
+
+	sim := NewSimulation()
+	sanity := newGuard()
+	sim.Particles[3].Velocity.X = math.NaN()
+	sim.Step(0.01)
+	sanity.Record(0.01)
+
+	v := sanity.Check(sim.StepCount, sim.SimTime, sim.Particles)
+	if v == nil {
+		t.Fatal("Expected the NaN to be caught")
+	}
+	if message := sim.reportViolation(v); !strings.Contains(message, "report:") {
+		t.Errorf("Expected the report path in %q", message)
+	}
+	reports, _ := filepath.Glob(filepath.Join(cfg.CrashReportDir, "guard_*"))
+	if len(reports) != 2 {
+		t.Errorf("Expected a report and a snapshot, got %v", reports)
+	}
+
+	cfg.GuardInterval = 0
+	if newGuard() != nil {
+		t.Error("Expected no checker with the guard disabled")
+	}
+}
+
+// TestLoadConfigPreset tests layering a preset under the command-line flags
+func TestLoadConfigPreset(t *testing.T) {
+	loaded, err := loadConfig([]string{"-preset", "Demo", "-particles", "50"}, "")
+	if err != nil {
+		t.Fatalf("loadConfig failed: %v", err)
+	}
+	if loaded.Preset != "demo" || loaded.NumParticles != 50 || !loaded.ShowPlots {
+		t.Errorf("Expected demo with 50 particles and plots, got %q with %d (plots %v)", loaded.Preset, loaded.NumParticles, loaded.ShowPlots)
+	}
+
+	loaded, err = loadConfig([]string{"-preset", "demo"}, "accuracy")
+	if err != nil {
+		t.Fatalf("loadConfig failed: %v", err)
+	}
+	if loaded.Preset != "accuracy" || !loaded.DirectSolver() || loaded.ShowPlots {
+		t.Errorf("Expected the accuracy preset to replace demo, got %q", loaded.Preset)
+	}
+	if loaded, err := loadConfig([]string{"-preset", "galaxy"}, ""); err != nil || loaded.Validate() == nil {
+		t.Errorf("Expected an unknown preset to fail validation, got %v", err)
+	}
+}
+
+// TestReplaySimulation tests restoring a recorded frame for offline rendering
+// under the recorded grid and tracer sheet
+func TestReplaySimulation(t *testing.T) {
+	saved, savedGPU := cfg, useGPU
+	defer func() { cfg, useGPU = saved, savedGPU }()
+	cfg = config.DefaultConfig()
+	cfg.SimulationWidth, cfg.SimulationDepth = 64, 32
+	cfg.NumParticles = 20
+	cfg.Seed = 7
+	cfg.TracerSheet = 4
+	cfg.ComputeMode = config.ComputeCPU
+	useGPU = false
+
+	sim := NewSimulation()
+	sim.Step(0.05)
+	snap := snapshot.New(cfg, sim.Particles, sim.StepCount, sim.SimTime)
+
+	cfg = config.DefaultConfig() // The render's own settings
+	cfg.RenderDir = "replay"
+	if got := renderOutDir(); got != filepath.Join("replay", "render") {
+		t.Errorf("Expected images in replay/render, got %s", got)
+	}
+	applyReplayConfig(snap.Config)
+	if cfg.SimulationWidth != 64 || cfg.SimulationDepth != 32 || cfg.TracerSheet != 4 {
+		t.Fatalf("Expected the recorded 64x32 grid and sheet, got %dx%d and %d", cfg.SimulationWidth, cfg.SimulationDepth, cfg.TracerSheet)
+	}
+	replay := newReplaySimulation(snap)
+	if replay.sheet == nil || replay.sheet.First != 20 {
+		t.Fatalf("Expected the sheet after the 20 particles, got %+v", replay.sheet)
+	}
+	replay.ReadFrame(func(frame *simulation.Frame) {
+		if frame.Step != 1 || len(frame.Particles) != 20+16 || frame.Particles[3].Position != sim.Particles[3].Position {
+			t.Errorf("Expected the recorded particles at step 1, got %d at step %d", len(frame.Particles), frame.Step)
+		}
+		solved := false
+		for i := range frame.PotentialGrid {
+			for _, v := range frame.PotentialGrid[i] {
+				solved = solved || v != 0
+			}
+		}
+		if frame.PotentialGrid.Width() != 64 || !solved {
+			t.Error("Expected the potential solved again from the particles")
+		}
+	})
+}
+
+// TestPreviewPhysics tests coarse steps while interacting and the return to
+// full resolution once idle
+func TestPreviewPhysics(t *testing.T) {
+	saved, savedGPU, savedQuality := cfg, useGPU, quality
+	defer func() { cfg, useGPU, quality = saved, savedGPU, savedQuality }()
+	cfg = config.DefaultConfig()
+	cfg.SimulationWidth, cfg.SimulationDepth = 64, 64
+	cfg.NumParticles = 200
+	cfg.Seed = 7
+	cfg.ComputeMode = config.ComputeCPU
+	cfg.PreviewPhysics = true
+	useGPU = false
+	quality = newQualityState()
+	if previewFactor() != 1 {
+		t.Fatal("Expected full resolution before any interaction")
+	}
+
+	quality.recordInteraction(0.016, true)
+	if previewFactor() != previewGridFactor || !strings.Contains(quality.label(), "preview") {
+		t.Fatalf("Expected preview physics while interacting, got factor %d and status %q", previewFactor(), quality.label())
+	}
+	sim := NewSimulation()
+	sim.Step(0.01)
+	potential, mass := physics.NewGrid(64, 64), physics.NewGrid(64, 64)
+	physics.SolveCoarseInto(potential, mass, sim.Particles, previewGridFactor, nil, cfg.GravitationalConstant)
+	for i := range potential {
+		for j := range potential[i] {
+			if sim.PotentialGrid[i][j] != potential[i][j] {
+				t.Fatalf("Expected the coarse potential at (%d, %d), got %g instead of %g", i, j, sim.PotentialGrid[i][j], potential[i][j])
+			}
+		}
+	}
+
+	useGPU = true
+	if previewFactor() != 1 {
+		t.Error("Expected GPU steps to keep full resolution")
+	}
+	useGPU = false
+
+	quality.recordInteraction(previewIdleDelay/2, false)
+	if previewFactor() != previewGridFactor {
+		t.Error("Expected preview physics to last through short pauses")
+	}
+	quality.recordInteraction(previewIdleDelay/2, false)
+	if previewFactor() != 1 || quality.label() != "" {
+		t.Errorf("Expected full resolution once idle, got factor %d and status %q", previewFactor(), quality.label())
+	}
+	sim.Step(0.01)
+	physics.DepositMassToGridInto(mass, sim.Particles, nil)
+	physics.SolvePoissonFFTInto(potential, mass, 1, cfg.GravitationalConstant)
+	if sim.PotentialGrid[10][20] != potential[10][20] {
+		t.Errorf("Expected the full-resolution potential, got %g instead of %g", sim.PotentialGrid[10][20], potential[10][20])
+	}
+}
+
+// TestRaylibConversion tests converting vectors to raylib and back
+func TestRaylibConversion(t *testing.T) {
+	v := physics.NewVec3(1.5, 2.5, 3.5)
+
+	rlVec := toRaylib(v)
+	if rlVec.X != 1.5 || rlVec.Y != 2.5 || rlVec.Z != 3.5 {
+		t.Errorf("Expected raylib Vector3(1.5,2.5,3.5), got (%f,%f,%f)", rlVec.X, rlVec.Y, rlVec.Z)
+	}
+	if back := fromRaylib(rlVec); back != v {
+		t.Errorf("Expected Vec3(1.5,2.5,3.5), got (%f,%f,%f)", back.X, back.Y, back.Z)
+	}
+}
+
+// TestRaylibVectors tests that packed positions read as raylib vectors
+// sharing their memory, and that batches convert in order
+func TestRaylibVectors(t *testing.T) {
+	xyz := []float32{1, 2, 3, 4, 5, 6}
+	vectors := raylibVectors(xyz)
+	if len(vectors) != 2 || vectors[1] != rl.NewVector3(4, 5, 6) {
+		t.Fatalf("Expected 2 vectors ending at (4,5,6), got %v", vectors)
+	}
+	xyz[0] = 9
+	if vectors[0].X != 9 {
+		t.Error("Expected the vectors to share the packed memory")
+	}
+	if raylibVectors(nil) != nil {
+		t.Error("Expected no vectors for no positions")
+	}
+
+	batch := appendRaylib(nil, []physics.Vec3{physics.NewVec3(1, 2, 3), physics.NewVec3(-1, 0, 0.5)})
+	if len(batch) != 2 || batch[0] != rl.NewVector3(1, 2, 3) || batch[1] != rl.NewVector3(-1, 0, 0.5) {
+		t.Errorf("Expected the converted batch, got %v", batch)
+	}
+}
+
+// BenchmarkParticlePositions compares converting each particle's position
+// with reinterpreting the positions packed on publish
+func BenchmarkParticlePositions(b *testing.B) {
+	particles := physics.InitializeParticlesWithSeed(100000, 256, 256, 1)
+	frame := make([]physics.Particle, len(particles))
+	for i, p := range particles {
+		frame[i] = *p
+	}
+	packed := physics.PackParticlePositions(nil, particles)
+	var sink rl.Vector3
+	b.Run("convert", func(b *testing.B) {
+		for n := 0; n < b.N; n++ {
+			for i := range frame {
+				sink = toRaylib(frame[i].Position)
+			}
+		}
+	})
+	b.Run("packed", func(b *testing.B) {
+		for n := 0; n < b.N; n++ {
+			for _, v := range raylibVectors(packed) {
+				sink = v
+			}
+		}
+	})
+	_ = sink
+}
+
+// TestObservers tests that observers run after the steps of their stride and
+// are suspended during the time-reversal test
+func TestObservers(t *testing.T) {
+	saved, savedGPU := cfg, useGPU
+	defer func() { cfg, useGPU = saved, savedGPU }()
+	cfg = config.DefaultConfig()
+	cfg.SimulationWidth, cfg.SimulationDepth = 64, 64
+	cfg.NumParticles = 20
+	cfg.Seed = 7
+	cfg.ReversalSteps = 4
+	useGPU = false
+
+	sim := NewSimulation()
+	var steps []int64
+	sim.RegisterObserver(2, func(step int64, s *Simulation) { steps = append(steps, step) })
+	for i := 0; i < 5; i++ {
+		sim.Step(0.01)
+	}
+	if len(steps) != 2 || steps[0] != 2 || steps[1] != 4 {
+		t.Fatalf("Expected calls after steps 2 and 4, got %v", steps)
+	}
+
+	if _, err := sim.timeReversal(); err != nil {
+		t.Fatalf("timeReversal failed: %v", err)
+	}
+	sim.Step(0.01)
+	if len(steps) != 3 || steps[2] != 6 {
+		t.Errorf("Expected only the call after step 6 since, got %v", steps)
+	}
+}
+
+// TestRectangularSimulation tests that a 128x512 domain steps like the same
+// particles on a 512x128 domain with x and z swapped, on each CPU path
+func TestRectangularSimulation(t *testing.T) {
+	saved := cfg
+	defer func() { cfg = saved }()
+	swap := func(v physics.Vec3) physics.Vec3 { return physics.NewVec3(v.Z, v.Y, v.X) }
+
+	for _, tt := range []struct {
+		name      string
+		tolerance float64 // Cells the runs may drift apart by rounding
+		modify    func(c *config.Config)
+	}{
+		{"pm", 1e-6, func(c *config.Config) {}},
+		{"float32", 1e-3, func(c *config.Config) { c.Precision = config.PrecisionFloat32 }},
+		{"sparse", 1e-6, func(c *config.Config) { c.SparseGrid = true }},
+		{"direct", 1e-6, func(c *config.Config) { c.Solver = config.SolverDirect }},
+	} {
+		t.Run(tt.name, func(t *testing.T) {
+			run := func(width, depth int, transposed bool) *Simulation {
+				cfg = config.DefaultConfig()
+				cfg.SimulationWidth, cfg.SimulationDepth = width, depth
+				cfg.NumParticles = 500
+				cfg.Seed = 7
+				tt.modify(cfg)
+				if err := cfg.Validate(); err != nil {
+					t.Fatalf("Validate failed: %v", err)
+				}
+				sim := NewSimulation()
+				sim.Particles = physics.InitializeParticlesWithSeed(500, 128, 512, 7)
+				if transposed {
+					for _, p := range sim.Particles {
+						p.Position, p.Velocity = swap(p.Position), swap(p.Velocity)
+					}
+				}
+				for step := 0; step < 5; step++ {
+					sim.Step(0.1)
+				}
+				return sim
+			}
+			tall, wide := run(128, 512, false), run(512, 128, true)
+
+			if tall.PotentialGrid.Width() != 128 || tall.PotentialGrid.Height() != 512 {
+				t.Fatalf("Expected a 128x512 potential, got %dx%d", tall.PotentialGrid.Width(), tall.PotentialGrid.Height())
+			}
+			for i, p := range tall.Particles {
+				q := wide.Particles[i]
+				if d := p.Position.Sub(swap(q.Position)).Length(); d > tt.tolerance || math.IsNaN(d) {
+					t.Fatalf("Particle %d at %+v, transposed run at %+v", i, p.Position, swap(q.Position))
+				}
+				if p.Position.X < -64 || p.Position.X > 64 || p.Position.Z < -256 || p.Position.Z > 256 {
+					t.Fatalf("Particle %d left the 128x512 domain: %+v", i, p.Position)
+				}
+			}
+		})
+	}
+}
+
+// TestSlowMotionConfig tests building the slow motion controller from the configuration
+func TestSlowMotionConfig(t *testing.T) {
+	saved := cfg
+	defer func() { cfg = saved }()
+	cfg = config.DefaultConfig()
+	cfg.SimulationWidth, cfg.SimulationDepth = 64, 64
+	cfg.SlowMotionTrigger = config.SlowMotionContact
+	cfg.SlowMotionRegionX, cfg.SlowMotionRegionZ, cfg.SlowMotionRadius = 5, -5, 4
+	cfg.SlowMotionScale = 0.25
+
+	c := newSlowMotion()
+	if c.Trigger != slowmo.TriggerContact || c.Watch() != slowmo.RegionWatch(5, -5, 4) {
+		t.Fatalf("Expected a contact trigger on the region, got %v on %+v", c.Trigger, c.Watch())
+	}
+	particles := []*physics.Particle{
+		physics.NewParticle(1, 5, 0, -5, 0, 0, 0),
+		physics.NewParticle(1, 5.5, 0, -5, 0, 0, 0),
+	}
+	particles[0].Radius, particles[1].Radius = 0.3, 0.3
+	if _, fired := c.Check(particles); !fired || c.TimeScale() != 0.25 {
+		t.Errorf("Expected slow motion at 0.25 after contact, got %g (fired %v)", c.TimeScale(), fired)
+	}
+
+	if x, z, r, err := parseCircle("1.5, -2,3"); err != nil || x != 1.5 || z != -2 || r != 3 {
+		t.Errorf("parseCircle = %g, %g, %g, %v", x, z, r, err)
+	}
+	if _, _, _, err := parseCircle("1,2"); err == nil {
+		t.Error("Expected an error for a circle without a radius")
+	}
+}
+
+// TestPhysicsBudget tests stepping the physics levels down over budget and
+// the coarse view grids they leave the simulation drawing
+func TestPhysicsBudget(t *testing.T) {
+	saved, savedGPU, savedQuality := cfg, useGPU, quality
+	defer func() { cfg, useGPU, quality = saved, savedGPU, savedQuality }()
+	cfg = config.DefaultConfig()
+	cfg.SimulationWidth, cfg.SimulationDepth = 64, 64
+	cfg.NumParticles = 200
+	cfg.Seed = 7
+	cfg.PhysicsBudget = 5
+	useGPU = false
+	quality = newQualityState()
+	sim := NewSimulation()
+
+	for i := 0; i < 1000 && physicsLevel() != governor.PhysicsSubsampled; i++ {
+		quality.updatePhysics(sim, 0.1, 0.05)
+	}
+	if physicsLevel() != governor.PhysicsSubsampled || sim.mesh.DepositStride() != 2 {
+		t.Fatalf("Expected sub-sampled deposits over budget, got %v (stride %d)", physicsLevel(), sim.mesh.DepositStride())
+	}
+	if !strings.Contains(quality.label(), "Physics") {
+		t.Errorf("Expected the physics level in the status line, got %q", quality.label())
+	}
+	if next := NewSimulation(); next.mesh.DepositStride() != 2 {
+		t.Errorf("Expected a new simulation to keep the session's stride, got %d", next.mesh.DepositStride())
+	}
+
+	sim.Step(0.01)
+	total, deposited := 0.0, 0.0
+	for _, p := range sim.Particles {
+		total += float64(p.Mass)
+	}
+	for i := range sim.MassDensityGrid {
+		for _, v := range sim.MassDensityGrid[i] {
+			deposited += v
+		}
+	}
+	if math.Abs(deposited-total) > 0.01*total {
+		t.Errorf("Expected the coarse view to hold mass %g, got %g", total, deposited)
+	}
+
+	for i := 0; i < 1000 && physicsLevel() != governor.PhysicsFull; i++ {
+		quality.updatePhysics(sim, 0.1, 0)
+	}
+	if physicsLevel() != governor.PhysicsFull || sim.mesh.DepositStride() != 1 {
+		t.Errorf("Expected full physics with headroom, got %v (stride %d)", physicsLevel(), sim.mesh.DepositStride())
+	}
+}
+
+// TestSparseGridStep tests that sparse steps follow the dense CPU steps
+func TestSparseGridStep(t *testing.T) {
+	saved, savedGPU := cfg, useGPU
+	defer func() { cfg, useGPU = saved, savedGPU }()
+	cfg = config.DefaultConfig()
+	cfg.SimulationWidth, cfg.SimulationDepth = 64, 64
+	cfg.NumParticles = 50
+	cfg.Seed = 7
+	useGPU = false
+
+	reference := NewSimulation()
+	cfg.SparseGrid = true
+	sim := NewSimulation()
+	for step := 0; step < 5; step++ {
+		reference.Step(0.1)
+		sim.Step(0.1)
+	}
+
+	for i, p := range sim.Particles {
+		if d := p.Position.Sub(reference.Particles[i].Position).Length(); d > 1e-9 {
+			t.Fatalf("Particle %d is %g from the dense trajectory", i, d)
+		}
+	}
+	for i := range sim.PotentialGrid {
+		for j, v := range sim.PotentialGrid[i] {
+			if d := math.Abs(v - reference.PotentialGrid[i][j]); d > 1e-9 {
+				t.Fatalf("Drawn potential differs by %g at (%d, %d)", d, i, j)
+			}
+		}
+	}
+}
+
+// TestSetComputeMode tests cycling the compute modes and the GPU switch
+// they leave to the quality governor
+func TestSetComputeMode(t *testing.T) {
+	saved, savedGPU, savedMode, savedQuality := cfg, useGPU, computeMode, quality
+	defer func() { cfg, useGPU, computeMode, quality = saved, savedGPU, savedMode, savedQuality }()
+	cfg = config.DefaultConfig()
+	cfg.SimulationWidth, cfg.SimulationDepth = 64, 64
+	cfg.NumParticles = 10
+	quality = nil
+
+	sim := NewSimulation()
+	want := []struct {
+		mode    string
+		compute gpu.ComputeMode
+		useGPU  bool
+	}{
+		{config.ComputeAuto, gpu.ModeAuto, true},
+		{config.ComputeCPU, gpu.ModeCPU, false},
+		{config.ComputeGPU, gpu.ModeGPU, true},
+		{config.ComputeAuto, gpu.ModeAuto, true},
+	}
+	computeMode = config.ComputeGPU
+	for _, w := range want {
+		setComputeMode(sim, nextComputeMode(computeMode))
+		if computeMode != w.mode || sim.compute.GetMode() != w.compute || useGPU != w.useGPU {
+			t.Errorf("Expected %s (GPU %v), got %s, %v (GPU %v)", w.mode, w.useGPU, computeMode, sim.compute.GetMode(), useGPU)
+		}
+	}
+}
+
+// TestSampleDiagnosticsCPU tests the CPU path of the diagnostics sample used
+// when GPU reductions are unavailable
+func TestSampleDiagnosticsCPU(t *testing.T) {
+	saved, savedGPU := cfg, useGPU
+	defer func() { cfg, useGPU = saved, savedGPU }()
+	cfg = config.DefaultConfig()
+	cfg.SimulationWidth, cfg.SimulationDepth = 32, 32
+	cfg.NumParticles = 50
+	cfg.Seed = 3
+	useGPU = false
+
+	sim := NewSimulation()
+	sim.Update(0.1)
+	kinetic, potential := sim.sampleDiagnostics()
+
+	if want := physics.ComputeDiagnostics(sim.Particles).KineticEnergy; kinetic != want {
+		t.Errorf("Kinetic energy %g, want %g", kinetic, want)
+	}
+	for i := range sim.PotentialGrid {
+		for _, v := range sim.PotentialGrid[i] {
+			if v < potential.Min || v > potential.Max {
+				t.Fatalf("Potential %g outside range [%g, %g]", v, potential.Min, potential.Max)
+			}
+		}
+	}
+	if !(potential.Min < potential.Max) {
+		t.Errorf("Expected a non-empty range, got [%g, %g]", potential.Min, potential.Max)
+	}
+}
+
+// TestDrawPotentialGPUSkipped tests that the grid is drawn from the CPU copy
+// whenever the GPU potential is not current
+func TestDrawPotentialGPUSkipped(t *testing.T) {
+	saved, savedGPU := cfg, useGPU
+	defer func() { cfg, useGPU = saved, savedGPU }()
+	cfg = config.DefaultConfig()
+	cfg.SimulationWidth, cfg.SimulationDepth = 16, 16
+
+	sim := NewSimulation()
+	useGPU = false
+	if sim.drawPotentialGPU(1) {
+		t.Error("Expected CPU drawing in CPU mode")
+	}
+
+	useGPU = true
+	sim.gpu = &gpu.GPU{Initialized: true, PotentialBuffer: &gpu.ComplexGPUBuffer{Size: 16 * 16}}
+	sim.fallbackToCPU = true
+	if sim.drawPotentialGPU(1) {
+		t.Error("Expected CPU drawing after a GPU fallback")
+	}
+
+	sim.fallbackToCPU = false
+	cfg.Solver = config.SolverDirect
+	if sim.drawPotentialGPU(1) {
+		t.Error("Expected CPU drawing with the direct solver")
+	}
+}
+
+// TestSelectGPUDevice tests the offload variables set for a chosen device
+func TestSelectGPUDevice(t *testing.T) {
+	devices := []gpu.Device{
+		{Index: 1, Driver: "i915", PCISlot: "0000:00:02.0", Default: true},
+		{Index: 2, Driver: "amdgpu", PCISlot: "0000:03:00.0"},
+	}
+	t.Setenv("DRI_PRIME", "")
+
+	if err := selectGPUDevice(devices, 0); err != nil || os.Getenv("DRI_PRIME") != "" {
+		t.Errorf("Expected the driver default to set nothing, got %v, DRI_PRIME=%q", err, os.Getenv("DRI_PRIME"))
+	}
+	if err := selectGPUDevice(devices, 2); err != nil || os.Getenv("DRI_PRIME") != "pci-0000_03_00_0" {
+		t.Errorf("Expected DRI_PRIME for device 2, got %v, DRI_PRIME=%q", err, os.Getenv("DRI_PRIME"))
+	}
+	if err := selectGPUDevice(devices, 3); err == nil {
+		t.Error("Expected an error for a missing device")
+	}
+}
+
+// TestQualityCPULevel tests that the CPU quality level switches GPU mode off
+// and restores it when quality recovers
+func TestQualityCPULevel(t *testing.T) {
+	saved, savedGPU := cfg, useGPU
+	defer func() { cfg, useGPU = saved, savedGPU }()
+	cfg = config.DefaultConfig()
+	cfg.PowerSaver = true
+	useGPU = true
+
+	q := newQualityState()
+	if q.level != governor.LevelReducedFPS || q.label() != "Quality: Reduced FPS (power saver)" {
+		t.Errorf("Expected a fixed power-saver level, got %v %q", q.level, q.label())
+	}
+
+	q.level = governor.LevelCPU
+	q.apply()
+	if useGPU {
+		t.Error("Expected CPU mode at the CPU level")
+	}
+	q.level = governor.LevelReducedFPS
+	q.apply()
+	if !useGPU {
+		t.Error("Expected GPU mode restored after leaving the CPU level")
+	}
+
+	// GPU mode switched off by the user stays off
+	useGPU = false
+	q.level = governor.LevelCPU
+	q.apply()
+	q.level = governor.LevelFull
+	q.apply()
+	if useGPU {
+		t.Error("Expected GPU mode to stay off")
+	}
+}
+
+// TestGPUFallbackMessage tests that the notification names the kind of GPU failure
+func TestGPUFallbackMessage(t *testing.T) {
+	tests := []struct {
+		err  error
+		want string
+	}{
+		{nil, "GPU unavailable, falling back to CPU"},
+		{gpu.ErrNoGLContext, "No OpenGL 4.3 context, running on CPU"},
+		{gpu.NewUnsupportedGLError(gpu.Capabilities{Major: 3, Minor: 3, Renderer: "Old GPU"}), "Driver too old for GPU compute, running on CPU: OpenGL compute shaders not supported: OpenGL 3.3 on Old GPU, 4.3 needed"},
+		{&gpu.ShaderError{Shader: "FFT compute", Stage: "compilation", Log: "bad"}, "GPU shader failed to build, falling back to CPU: FFT compute shader compilation failed: bad"},
+		{gpu.NewBufferTooSmallError(8, 4), "Grid does not fit the GPU buffers, falling back to CPU: GPU buffer too small: need 8 elements, have 4"},
+		{errors.New("lost device"), "GPU error, falling back to CPU: lost device"},
+	}
+	for _, tt := range tests {
+		if got := gpuFallbackMessage(tt.err); got != tt.want {
+			t.Errorf("gpuFallbackMessage(%v) = %q, want %q", tt.err, got, tt.want)
+		}
+	}
+}
+
+// TestGPUDegradedMessage tests the notification listing missing optional features
+func TestGPUDegradedMessage(t *testing.T) {
+	if got := gpuDegradedMessage(gpu.Capabilities{Major: 4, Minor: 6}); got != "" {
+		t.Errorf("Expected no message for OpenGL 4.6, got %q", got)
+	}
+	want := "GPU without persistent upload buffers, using slower paths"
+	if got := gpuDegradedMessage(gpu.Capabilities{Major: 4, Minor: 3}); got != want {
+		t.Errorf("Expected %q, got %q", want, got)
+	}
+}
+
+// TestGroundPoint tests where tap rays meet the simulation plane
+func TestGroundPoint(t *testing.T) {
+	down := rl.Ray{Position: rl.NewVector3(10, 20, -5), Direction: rl.NewVector3(0.5, -1, 0.25)}
+	x, z, ok := groundPoint(down)
+	if !ok || x != 20 || z != 0 {
+		t.Errorf("Expected (20, 0), got (%g, %g) ok=%v", x, z, ok)
+	}
+
+	up := rl.Ray{Position: rl.NewVector3(0, 20, 0), Direction: rl.NewVector3(0, 1, 0)}
+	if _, _, ok := groundPoint(up); ok {
+		t.Error("Expected a ray pointing away from the plane to miss it")
+	}
+	level := rl.Ray{Position: rl.NewVector3(0, 20, 0), Direction: rl.NewVector3(1, 0, 0)}
+	if _, _, ok := groundPoint(level); ok {
+		t.Error("Expected a ray parallel to the plane to miss it")
+	}
+}
+
+// TestSpawnParticle tests that taps add particles inside the box only
+func TestSpawnParticle(t *testing.T) {
+	saved := cfg
+	defer func() { cfg = saved }()
+	cfg = config.DefaultConfig()
+	cfg.SimulationWidth, cfg.SimulationDepth = 32, 32
+	cfg.NumParticles = 10
+	cfg.Seed = 3
+
+	sim := NewSimulation()
+	if !sim.SpawnParticle(4, -6) {
+		t.Fatal("Expected a spawn inside the box to succeed")
+	}
+	if len(sim.Particles) != 11 {
+		t.Fatalf("Expected 11 particles, got %d", len(sim.Particles))
+	}
+	p := sim.Particles[10]
+	if p.Position.X != 4 || p.Position.Z != -6 || p.Mass <= 0 || p.Radius <= 0 {
+		t.Errorf("Unexpected spawned particle %+v", *p)
+	}
+	if sim.SpawnParticle(16, 0) || sim.SpawnParticle(0, -17) {
+		t.Error("Expected spawns outside the box to be rejected")
+	}
+
+	cfg.Solver = config.SolverDirect
+	sim.Particles = physics.InitializeParticlesWithSeed(config.MaxDirectParticles, 32, 32, 1)
+	if sim.SpawnParticle(0, 0) {
+		t.Error("Expected a spawn past the direct solver limit to be rejected")
+	}
+}
+
+// TestSimulationParticleEnergy tests binding energies read from the solved potential
+func TestSimulationParticleEnergy(t *testing.T) {
+	saved := cfg
+	defer func() { cfg = saved }()
+	cfg = config.DefaultConfig()
+	cfg.SimulationWidth, cfg.SimulationDepth = 32, 32
+	cfg.NumParticles = 0
+
+	sim := NewSimulation()
+	sim.Particles = []*physics.Particle{
+		physics.NewParticle(1000, 0, 0, 0, 0, 0, 0), // Clump at rest
+		physics.NewParticle(1, 1, 0, 0, 0, 0, 0),    // Slow neighbour
+		physics.NewParticle(1, 0, 0, 1, 1000, 0, 0), // Escaping neighbour
+	}
+	physics.DepositMassToGridInto(sim.MassDensityGrid, sim.Particles, nil)
+	sim.solvePotential()
+
+	if e := sim.ParticleEnergy(1); !e.Bound() || e.Kinetic != 0 {
+		t.Errorf("Expected the slow particle to be bound, got %+v", e)
+	}
+	if e := sim.ParticleEnergy(2); e.Bound() {
+		t.Errorf("Expected the fast particle to be unbound, got %+v", e)
+	}
+}
+
+// TestParticleColoring tests cycling the coloring modes and the density coloring
+func TestParticleColoring(t *testing.T) {
+	if got := nextMode(config.ParticleColorings, ""); got != config.ParticleColoringBinding {
+		t.Errorf("Expected uniform to be followed by binding, got %q", got)
+	}
+	if got := nextMode(config.ParticleColorings, config.ParticleColoringDensity); got != config.ParticleColoringLyapunov {
+		t.Errorf("Expected density to be followed by lyapunov, got %q", got)
+	}
+	if got := nextMode(config.ParticleColorings, config.ParticleColoringLyapunov); got != config.ParticleColoringGroup {
+		t.Errorf("Expected lyapunov to be followed by group, got %q", got)
+	}
+	if got := nextMode(config.ParticleColorings, config.ParticleColoringGroup); got != config.ParticleColoringUniform {
+		t.Errorf("Expected group to wrap to uniform, got %q", got)
+	}
+
+	saved := cfg
+	defer func() { cfg = saved }()
+	cfg = config.DefaultConfig()
+	cfg.SimulationWidth, cfg.SimulationDepth = 32, 32
+	cfg.NumParticles = 0
+	cfg.ParticleColoring = config.ParticleColoringDensity
+
+	sim := NewSimulation()
+	sim.Particles = []*physics.Particle{
+		physics.NewParticle(1, 0, 0, 0, 0, 0, 0),
+		physics.NewParticle(1, 0.5, 0, 0, 0, 0, 0),
+		physics.NewParticle(1, 0, 0, 0.5, 0, 0, 0),
+		physics.NewParticle(1, 12, 0, 12, 0, 0, 0), // Isolated
+	}
+	densities := sim.LocalDensities()
+	if densities[0] <= densities[3] {
+		t.Errorf("Expected the clustered particle to be denser, got %v", densities)
+	}
+
+	scheme := renderer.PaletteDefault.Scheme()
+	sim.publish()
+	var colors []rl.Color
+	sim.ReadFrame(func(frame *simulation.Frame) { colors = particleColors(sim, frame, scheme) })
+	if colors[3] != raylibColor(scheme.ParticleLight) {
+		t.Errorf("Expected the sparsest particle at the light end, got %+v", colors[3])
+	}
+}
+
+// TestSimulationReadFrame tests that the renderer's frame holds the state of
+// the last completed step and is not touched by the live fields
+func TestSimulationReadFrame(t *testing.T) {
+	saved, savedGPU := cfg, useGPU
+	defer func() { cfg, useGPU = saved, savedGPU }()
+	cfg = config.DefaultConfig()
+	cfg.SimulationWidth, cfg.SimulationDepth = 32, 32
+	cfg.NumParticles = 0
+	useGPU = false
+
+	sim := NewSimulation()
+	sim.Particles = []*physics.Particle{
+		physics.NewParticle(20, -4, 0, 0, 0, 0, 1),
+		physics.NewParticle(20, 4, 0, 0, 0, 0, -1),
+	}
+	sim.Step(0.01)
+
+	sim.ReadFrame(func(frame *simulation.Frame) {
+		if frame.Step != sim.StepCount || frame.SimTime != sim.SimTime {
+			t.Errorf("Expected step %d at %g, got step %d at %g", sim.StepCount, sim.SimTime, frame.Step, frame.SimTime)
+		}
+		if len(frame.Particles) != 2 || frame.Particles[0].Position != sim.Particles[0].Position {
+			t.Fatalf("Expected the stepped particles, got %+v", frame.Particles)
+		}
+		if frame.PotentialGrid[16][16] != sim.PotentialGrid[16][16] {
+			t.Errorf("Expected the solved potential %g, got %g", sim.PotentialGrid[16][16], frame.PotentialGrid[16][16])
+		}
+	})
+
+	// Overwriting the live state leaves the published frame alone
+	sim.Particles[0].Position.X = 100
+	sim.PotentialGrid[16][16] = 1e9
+	sim.ReadFrame(func(frame *simulation.Frame) {
+		if frame.Particles[0].Position.X == 100 || frame.PotentialGrid[16][16] == 1e9 {
+			t.Error("Expected the frame to be a copy of the live state")
+		}
+	})
+
+	if !sim.SpawnParticle(0, 0) {
+		t.Fatal("Expected the spawn to succeed")
+	}
+	sim.ReadFrame(func(frame *simulation.Frame) {
+		if len(frame.Particles) != 3 {
+			t.Errorf("Expected the spawned particle in the frame, got %d particles", len(frame.Particles))
+		}
+	})
+}
+
+// TestFrameRateTarget tests the frame rate cap and vsync for the configuration,
+// quality level and uncapped mode
+func TestFrameRateTarget(t *testing.T) {
+	saved := cfg
+	defer func() { cfg = saved }()
+	cfg = config.DefaultConfig()
+	cfg.TargetFPS = 144
+	cfg.VSync = true
+
+	f := newFrameRateState(renderer.NewRenderLoop())
+	if fps, vsync := f.target(governor.LevelFull); fps != 144 || !vsync {
+		t.Errorf("Expected the configured 144 FPS with vsync, got %d (vsync %v)", fps, vsync)
+	}
+	if fps, _ := f.target(governor.LevelReducedFPS); fps != 72 {
+		t.Errorf("Expected reduced quality to halve the cap, got %d", fps)
+	}
+	f.uncapped = true
+	if fps, vsync := f.target(governor.LevelReducedFPS); fps != 0 || vsync {
+		t.Errorf("Expected the uncapped mode to drop the cap and vsync, got %d (vsync %v)", fps, vsync)
+	}
+
+	// Idling takes precedence, and stops the physics
+	f.idle = true
+	if fps, vsync := f.target(governor.LevelFull); fps != cfg.IdleFPS || vsync {
+		t.Errorf("Expected the idle cap of %d without vsync, got %d (vsync %v)", cfg.IdleFPS, fps, vsync)
+	}
+	if f.stepping() {
+		t.Error("Expected no physics steps while idling")
+	}
+}
+
+// TestSimulationFlowField tests that flow maps are reused until they are FlowInterval steps old
+func TestSimulationFlowField(t *testing.T) {
+	saved := cfg
+	defer func() { cfg = saved }()
+	cfg = config.DefaultConfig()
+	cfg.SimulationWidth, cfg.SimulationDepth = 16, 16
+	cfg.NumParticles = 0
+	cfg.FlowInterval = 5
+
+	sim := NewSimulation()
+	sim.Particles = []*physics.Particle{physics.NewParticle(1, 0, 0, 0, 1, 0, 0)}
+	if v := sim.FlowField().VelocityX[8][8]; v != 1 {
+		t.Fatalf("Expected the particle velocity at the origin node, got %g", v)
+	}
+
+	sim.Particles[0].Velocity.X = 2
+	sim.StepCount = 4
+	if v := sim.FlowField().VelocityX[8][8]; v != 1 {
+		t.Errorf("Expected the cached map before the interval, got %g", v)
+	}
+	sim.StepCount = 5
+	if v := sim.FlowField().VelocityX[8][8]; v != 2 {
+		t.Errorf("Expected a fresh map after the interval, got %g", v)
+	}
+
+	scheme := renderer.PaletteDefault.Scheme()
+	sim.publish()
+	var colors [][]rl.Color
+	sim.ReadFrame(func(frame *simulation.Frame) { colors = flowColors(sim, frame, scheme) })
+	if colors != nil {
+		t.Error("Expected no flow colors for the potential coloring")
+	}
+	cfg.GridColoring = config.GridColoringShear
+	sim.ReadFrame(func(frame *simulation.Frame) { colors = flowColors(sim, frame, scheme) })
+	if len(colors) != 16 || colors[0][0] != raylibColor(scheme.Grid) {
+		t.Errorf("Expected still nodes in the grid color, got %d rows", len(colors))
+	}
+}
+
+// BenchmarkUpdateGPU measures full UpdateGPU steps per second on the OpenGL
+// backend across grid sizes. It opens a hidden window for the GL context and
+// is skipped without GL 4.3 or with -short
+func BenchmarkUpdateGPU(b *testing.B) {
+	if testing.Short() {
+		b.Skip("Needs an OpenGL context")
+	}
+	saved, savedGPU := cfg, useGPU
+	defer func() { cfg, useGPU = saved, savedGPU }()
+	for _, size := range []int{128, 256, 512, 1024} {
+		b.Run(fmt.Sprintf("%dx%d", size, size), func(b *testing.B) {
+			cfg = config.DefaultConfig()
+			cfg.SimulationWidth, cfg.SimulationDepth = size, size
+			cfg.NumParticles = 2000
+			cfg.Seed = 7
+			cfg.ComputeMode = config.ComputeGPU
+			useGPU = true
+			sim := NewSimulation()
+			defer sim.CleanupGPU()
+			sim.UpdateGPU(0.01) // Creates the context and warms the plans
+			if sim.HasGPUErrorOccurred() {
+				b.Skipf("No OpenGL 4.3 context: %v", sim.lastGPUError)
+			}
+
+			b.ResetTimer()
+			for i := 0; i < b.N; i++ {
+				sim.UpdateGPU(0.01)
+			}
+			b.StopTimer()
+			if sim.HasGPUErrorOccurred() {
+				b.Fatalf("Step fell back to the CPU: %v", sim.lastGPUError)
+			}
+			b.ReportMetric(float64(b.N)/b.Elapsed().Seconds(), "steps/s")
+		})
+	}
+}
@@ -0,0 +1,56 @@
+//go:build !js
+
+package main
+
+import (
+	"fmt"
+	"os"
+	"relativity_simulation_2d/internal/gpu"
+	"relativity_simulation_2d/internal/renderer"
+)
+
+// showGPUState shows the GPU state overlay, toggled with F11
+var showGPUState bool
+
+// gpuState returns the state of the simulation's GPU compute path. The
+// fallback error kept by the simulation stands in when the compute manager
+// has none, as after a failed CUDA or direct-force step
+func (s *Simulation) gpuState() gpu.State {
+	state := gpu.NewState(s.gpu, s.compute)
+	if state.Fallback == nil {
+		state.Fallback = s.lastGPUError
+	}
+	state.GLError = lastGLError
+	return state
+}
+
+// gpuStateLines returns the lines of the GPU state overlay: the back end
+// computing the steps and why, then the state of the GPU
+func (s *Simulation) gpuStateLines() []string {
+	lines := []string{"GPU state: computing on " + s.backend()}
+	if warning := gpuFFTWarning(); warning != "" {
+		lines = append(lines, warning)
+	}
+	return append(lines, s.gpuState().Lines()...)
+}
+
+// toggleGPUState shows or hides the GPU state overlay. Showing it also
+// writes the state to the log, where it can be copied into a bug report
+func toggleGPUState(s *Simulation) {
+	showGPUState = !showGPUState
+	if !showGPUState {
+		return
+	}
+	for _, line := range s.gpuStateLines() {
+		fmt.Fprintln(os.Stderr, line)
+	}
+	ui.Notify(renderer.NotificationInfo, "GPU state written to the log")
+}
+
+// drawGPUState draws the GPU state overlay on the left below the controls
+func drawGPUState(s *Simulation) {
+	for i, line := range s.gpuStateLines() {
+		x, y := ui.GetGPUStatePosition(i)
+		drawHUDText(line, x, y, ui.GetDefaultTextColor())
+	}
+}
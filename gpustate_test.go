@@ -0,0 +1,37 @@
+//go:build !js && !android
+
+package main
+
+import (
+	"errors"
+	"relativity_simulation_2d/internal/config"
+	"strings"
+	"testing"
+)
+
+// TestGPUStateLines tests that the GPU state overlay names the back end, the
+// fallback error and the last GL error before any GPU context exists
+func TestGPUStateLines(t *testing.T) {
+	saved, savedGPU, savedGLError := cfg, useGPU, lastGLError
+	defer func() { cfg, useGPU, lastGLError = saved, savedGPU, savedGLError }()
+	cfg = config.DefaultConfig()
+	useGPU = true
+	lastGLError = errors.New("OpenGL error during gradient shader: GL_INVALID_OPERATION")
+
+	sim := &Simulation{}
+	sim.compute = newComputeManager(sim)
+	sim.lastGPUError = errors.New("CUDA solver unavailable")
+	sim.fallbackToCPU = true
+
+	text := strings.Join(sim.gpuStateLines(), "\n")
+	for _, want := range []string{
+		"GPU state: computing on cpu",
+		"Context: none (GPU not initialized)",
+		"Fallback: CUDA solver unavailable",
+		"Last GL error: OpenGL error during gradient shader: GL_INVALID_OPERATION",
+	} {
+		if !strings.Contains(text, want) {
+			t.Errorf("Expected %q in the GPU state:\n%s", want, text)
+		}
+	}
+}
@@ -0,0 +1,94 @@
+//go:build !js
+
+package main
+
+import (
+	"fmt"
+	rl "github.com/gen2brain/raylib-go/raylib"
+	"io"
+	"math"
+	"relativity_simulation_2d/internal/config"
+	"relativity_simulation_2d/internal/physics"
+	"relativity_simulation_2d/internal/renderer"
+)
+
+// groupTagRadius is the radius in cells of the circle that T tags as a group
+const groupTagRadius = 10.0
+
+// tagGroupRegions adds a group for each configured region, holding the
+// particles inside it at the start
+func (s *Simulation) tagGroupRegions() {
+	regions, _ := config.ParseGroupRegions(cfg.GroupRegions) // Validated at startup
+	for _, r := range regions {
+		members := physics.RegionMembers(s.Particles, r.X, r.Z, r.Radius, cfg.SimulationWidth, cfg.SimulationDepth, s.mesh.Dx())
+		s.groups = append(s.groups, physics.Group{Name: r.Name, Members: members})
+	}
+}
+
+// tagGroup adds the particles around the point at the center of the view as
+// a new group, or with clear removes all groups
+func tagGroup(camera *rl.Camera, sim *Simulation, clear bool) {
+	if clear {
+		sim.groups = nil
+		ui.Notify(renderer.NotificationInfo, "Groups cleared")
+		return
+	}
+	center := rl.NewVector2(float32(rl.GetScreenWidth())/2, float32(rl.GetScreenHeight())/2)
+	x, z, ok := groundPoint(rl.GetScreenToWorldRay(center, *camera))
+	if !ok {
+		ui.Notify(renderer.NotificationWarning, "Groups: aim at the simulation plane to tag")
+		return
+	}
+	radius := math.Min(groupTagRadius, float64(min(cfg.SimulationWidth, cfg.SimulationDepth))/2) * sim.mesh.Dx()
+	members := physics.RegionMembers(sim.Particles, x, z, radius, cfg.SimulationWidth, cfg.SimulationDepth, sim.mesh.Dx())
+	if len(members) == 0 {
+		ui.Notify(renderer.NotificationWarning, "Groups: no particles to tag")
+		return
+	}
+	name := fmt.Sprintf("group %d", len(sim.groups)+1)
+	sim.groups = append(sim.groups, physics.Group{Name: name, Members: members})
+	ui.Notify(renderer.NotificationInfo, fmt.Sprintf("Groups: tagged %d particles around (%.1f, %.1f) as %s", len(members), x, z, name))
+}
+
+// groupStats returns the diagnostics of the simulation's groups
+func (s *Simulation) groupStats() []physics.GroupStats {
+	return physics.MeasureGroups(s.Particles, s.groups, s.PotentialGrid, cfg.SimulationWidth, cfg.SimulationDepth, s.mesh.Dx())
+}
+
+// groupPanel holds the group diagnostics shown under the HUD, sampled once
+// per plotInterval
+type groupPanel struct {
+	stats      []physics.GroupStats
+	lastSample float64
+	sampled    bool
+}
+
+// Sample measures the groups if a sample is due at wall-clock time now
+func (p *groupPanel) Sample(now float64, sim *Simulation) {
+	if p.sampled && now-p.lastSample < plotInterval {
+		return
+	}
+	p.lastSample, p.sampled = now, true
+	p.stats = sim.groupStats()
+}
+
+// Draw lists the groups in their colors, one per HUD line from line
+func (p *groupPanel) Draw(line int, scheme renderer.ColorScheme) {
+	for i, g := range p.stats {
+		x, y := ui.GetLinePosition(line + i)
+		drawHUDText(groupLine(g), x, y, scheme.Group(i))
+	}
+}
+
+// groupLine formats the diagnostics of a group for the HUD
+func groupLine(g physics.GroupStats) string {
+	return fmt.Sprintf("%s: %d particles, mass %.4g, center (%.1f, %.1f), energy %.4g", g.Name, g.Count, g.Mass, g.CenterX, g.CenterZ, g.Energy())
+}
+
+// printGroups writes the diagnostics of each group
+func printGroups(w io.Writer, stats []physics.GroupStats) {
+	for _, g := range stats {
+		fmt.Fprintf(w, "Group %s: %d particles, mass %.6g, center (%.3f, %.3f), velocity (%.4g, %.4g), kinetic %.6g, potential %.6g\n",
+			g.Name, g.Count, g.Mass, g.CenterX, g.CenterZ, g.VelocityX, g.VelocityZ, g.Kinetic, g.Potential)
+	}
+}
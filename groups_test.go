@@ -0,0 +1,43 @@
+//go:build !js
+
+package main
+
+import (
+	"relativity_simulation_2d/internal/config"
+	"strings"
+	"testing"
+)
+
+// TestGroupRegions tests tagging groups from -group and measuring them
+func TestGroupRegions(t *testing.T) {
+	saved, savedGPU := cfg, useGPU
+	defer func() { cfg, useGPU = saved, savedGPU }()
+	loaded, err := loadConfig([]string{"-width", "64", "-depth", "64", "-particles", "200", "-seed", "7", "-group", "left=-16,0,12", "-group", "right=16,0,12"}, "")
+	if err != nil {
+		t.Fatalf("loadConfig failed: %v", err)
+	}
+	if err := loaded.Validate(); err != nil {
+		t.Fatalf("Validate failed: %v", err)
+	}
+	cfg, useGPU = loaded, false
+	cfg.ComputeMode = config.ComputeCPU
+
+	sim := NewSimulation()
+	if len(sim.groups) != 2 || sim.groups[0].Name != "left" || sim.groups[1].Name != "right" {
+		t.Fatalf("Expected the left and right groups, got %+v", sim.groups)
+	}
+	stats := sim.groupStats()
+	for i, g := range stats {
+		if g.Count == 0 || g.Count != len(sim.groups[i].Members) || g.Mass <= 0 {
+			t.Errorf("Expected group %s to hold its particles, got %+v", g.Name, g)
+		}
+	}
+	if stats[0].CenterX >= 0 || stats[1].CenterX <= 0 {
+		t.Errorf("Expected the left group left of the right one, got centers %g and %g", stats[0].CenterX, stats[1].CenterX)
+	}
+	var out strings.Builder
+	printGroups(&out, stats)
+	if !strings.Contains(out.String(), "Group left:") || !strings.Contains(out.String(), "Group right:") {
+		t.Errorf("Unexpected group summary %q", out.String())
+	}
+}
@@ -0,0 +1,135 @@
+//go:build !js
+
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"relativity_simulation_2d/internal/crash"
+	"relativity_simulation_2d/internal/export"
+	"relativity_simulation_2d/internal/headless"
+	"relativity_simulation_2d/internal/physics"
+	"relativity_simulation_2d/internal/provenance"
+	"relativity_simulation_2d/internal/soak"
+	"time"
+)
+
+// runHeadless runs the simulation without a window until the step budget or
+// timeout is exhausted or SIGINT/SIGTERM is received
+func runHeadless() error {
+	simulation := NewSimulation()
+	defer crash.Recover(cfg.CrashReportDir, simulation.crashState)
+
+	if cfg.TimeReversal {
+		defer simulation.CleanupGPU()
+		result, err := simulation.timeReversal()
+		if err != nil {
+			return err
+		}
+		fmt.Println(result)
+		return nil
+	}
+
+	if err := simulation.openBinaryLog(); err != nil {
+		return err
+	}
+	defer simulation.closeBinaryLog()
+
+	var monitor *soak.Monitor
+	if cfg.Soak {
+		var closeSoak func() error
+		var err error
+		if monitor, closeSoak, err = simulation.openSoak(); err != nil {
+			return err
+		}
+		defer closeSoak()
+	}
+
+	var exporters []export.Exporter
+	if cfg.DiagnosticsPath != "" {
+		csvExporter, err := export.NewCSVExporter(cfg.DiagnosticsPath)
+		if err != nil {
+			return err
+		}
+		if err := provenance.WriteSidecar(cfg.DiagnosticsPath, simulation.metadata()); err != nil {
+			_ = csvExporter.Close()
+			return err
+		}
+		exporters = append(exporters, csvExporter)
+	}
+
+	var progress *headless.ProgressReporter
+	if cfg.ProgressInterval > 0 {
+		interval := time.Duration(cfg.ProgressInterval * float64(time.Second))
+		progress = headless.NewProgressReporter(os.Stdout, cfg.ProgressPath, interval)
+	}
+
+	runner := headless.NewRunner(simulation, headless.Options{
+		Steps:               int64(cfg.MaxSteps),
+		TimeStep:            cfg.FixedTimeStep,
+		DiagnosticsInterval: int64(cfg.DiagnosticsInterval),
+		CheckpointPath:      cfg.CheckpointPath,
+		NBodyPath:           cfg.NBodyPath,
+		NBodyFormat:         cfg.NBodyFormat,
+		FlowDir:             cfg.FlowDir,
+		FlowInterval:        int64(cfg.FlowInterval),
+		ProfilePath:         cfg.ProfilePath,
+		RecordDir:           cfg.RecordDir,
+		RecordInterval:      int64(cfg.RecordInterval),
+		Profile:             profileOptions(),
+		Config:              cfg,
+		Exporters:           exporters,
+		Progress:            progress,
+		Guard:               newGuard(),
+		GuardReportDir:      cfg.CrashReportDir,
+		Health:              simulation.health,
+		Soak:                monitor,
+		Provenance:          simulation.metadata,
+		Cleanup: func() error {
+			simulation.CleanupGPU()
+			return nil
+		},
+	})
+
+	ctx := context.Background()
+	if cfg.Timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, time.Duration(cfg.Timeout*float64(time.Second)))
+		defer cancel()
+	}
+
+	result, err := runner.RunContext(ctx)
+	switch {
+	case result.Signal != nil:
+		fmt.Fprintf(os.Stderr, "Received %v, shut down after step %d\n", result.Signal, result.Steps)
+	case errors.Is(result.Cause, context.DeadlineExceeded):
+		fmt.Fprintf(os.Stderr, "Timed out after %gs, shut down after step %d\n", cfg.Timeout, result.Steps)
+	}
+	fmt.Printf("Completed %d steps (t=%.3f, KE=%.6g)\n", result.Steps, result.SimTime, result.Diagnostics.KineticEnergy)
+	if simulation.chaos != nil {
+		mean, largest := physics.LyapunovSummary(simulation.chaos.Exponents())
+		fmt.Printf("Lyapunov exponents of %d particles: mean %.4g, max %.4g\n", simulation.chaos.Tracked(), mean, largest)
+	}
+	printGroups(os.Stdout, simulation.groupStats())
+	if monitor != nil {
+		printSoak(os.Stdout, monitor)
+	}
+	if simulation.sheet != nil {
+		stretch := simulation.sheet.Stretch(func(k int) physics.Vec3 { return simulation.Particles[k].Position })
+		fmt.Printf("Tracer sheet: %.1f%% folded\n", 100*physics.FoldedFraction(stretch))
+	}
+	if simulation.binaries != nil {
+		fmt.Printf("Binaries: %d active, %d formed, %d disrupted\n", len(simulation.binaries.Active()), simulation.binaries.Formed, simulation.binaries.Disrupted)
+	}
+	if cfg.MeshPath != "" && result.Signal == nil {
+		if meshErr := simulation.exportMesh(cfg.MeshPath); meshErr != nil {
+			err = errors.Join(err, meshErr)
+		} else {
+			fmt.Printf("Mesh written to %s\n", cfg.MeshPath)
+		}
+	}
+
+	return err
+}
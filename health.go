@@ -0,0 +1,66 @@
+//go:build !js
+
+package main
+
+import (
+	"fmt"
+	"os"
+	"relativity_simulation_2d/internal/health"
+	"relativity_simulation_2d/internal/renderer"
+)
+
+// newHealthMonitor creates the health monitor configured by cfg
+func newHealthMonitor() *health.Monitor {
+	return health.NewMonitor(health.Options{
+		Interval: int64(cfg.HealthInterval),
+		Width:    cfg.SimulationWidth,
+		Height:   cfg.SimulationDepth,
+		CellSize: cfg.CellSize,
+	})
+}
+
+// checkHealth runs the health monitor after an interactive step of
+// deltaTime. When the advice changes it is logged, and new issues are shown
+// as a notification
+func (s *Simulation) checkHealth(deltaTime float32) {
+	if s.health == nil {
+		return
+	}
+	report := s.health.Check(s.StepCount, s.SimTime, deltaTime, s.Particles, s.PotentialGrid)
+	if report == nil || !report.Changed {
+		return
+	}
+	fmt.Fprintf(os.Stderr, "Step %d: %v\n", report.Step, report)
+	if len(report.Issues) > 0 {
+		ui.Notify(renderer.NotificationWarning, "Health: "+report.Issues[0].Advice)
+	}
+}
+
+// healthReport returns the latest health report, or nil if there is none
+func (s *Simulation) healthReport() *health.Report {
+	if s.health == nil {
+		return nil
+	}
+	return s.health.Last()
+}
+
+// healthLabel describes a health report for the HUD, with the worst issue's advice
+func healthLabel(report *health.Report) string {
+	label := fmt.Sprintf("Health: %.0f/100 (%s)", report.Score, report.Status())
+	if len(report.Issues) > 0 {
+		label += " - " + report.Issues[0].Advice
+	}
+	return label
+}
+
+// healthColor returns the HUD color of a health report's status
+func healthColor(report *health.Report, scheme renderer.ColorScheme) renderer.UIColor {
+	switch report.Status() {
+	case "good":
+		return scheme.Text
+	case "fair":
+		return scheme.Warning
+	default:
+		return scheme.Error
+	}
+}
@@ -0,0 +1,110 @@
+// Package affinity places the simulation's threads on CPUs. It finds the
+// performance cores of hybrid processors, pins the process to them and
+// lowers its scheduling priority, so that benchmark runs see the same cores
+// from run to run
+package affinity
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// ErrUnsupported is returned where the OS offers no affinity or priority control
+var ErrUnsupported = errors.New("CPU affinity not supported on this platform")
+
+// CPURoot is the sysfs directory describing the machine's CPUs
+const CPURoot = "/sys/devices/system/cpu"
+
+// HybridCoreList is the sysfs file listing the performance cores of hybrid
+// Intel processors
+const HybridCoreList = "/sys/devices/cpu_core/cpus"
+
+// PerformanceCores returns the CPUs of the fastest core type, or nil if all
+// cores are alike or the CPUs cannot be read
+func PerformanceCores() []int {
+	return performanceCoresIn(HybridCoreList, CPURoot)
+}
+
+// performanceCoresIn reads the hybrid core list, then falls back to the
+// highest maximum frequency below a cpu class directory, as on big.LITTLE
+// ARM processors
+func performanceCoresIn(hybridList, root string) []int {
+	if data, err := os.ReadFile(hybridList); err == nil {
+		if cpus, err := ParseCPUList(string(data)); err == nil && len(cpus) > 0 {
+			return cpus
+		}
+	}
+
+	entries, err := os.ReadDir(root)
+	if err != nil {
+		return nil
+	}
+	frequencies := make(map[int]int)
+	for _, entry := range entries {
+		cpu, err := strconv.Atoi(strings.TrimPrefix(entry.Name(), "cpu"))
+		if err != nil || !strings.HasPrefix(entry.Name(), "cpu") {
+			continue
+		}
+		data, err := os.ReadFile(filepath.Join(root, entry.Name(), "cpufreq", "cpuinfo_max_freq"))
+		if err != nil {
+			continue
+		}
+		if khz, err := strconv.Atoi(strings.TrimSpace(string(data))); err == nil {
+			frequencies[cpu] = khz
+		}
+	}
+	return fastestCPUs(frequencies)
+}
+
+// fastestCPUs returns the CPUs with the highest frequency in ascending
+// order, or nil if every CPU has the same frequency
+func fastestCPUs(frequencies map[int]int) []int {
+	fastest, slower := 0, false
+	for _, khz := range frequencies {
+		if fastest != 0 && khz != fastest {
+			slower = true
+		}
+		fastest = max(fastest, khz)
+	}
+	if !slower {
+		return nil
+	}
+	var cpus []int
+	for cpu, khz := range frequencies {
+		if khz == fastest {
+			cpus = append(cpus, cpu)
+		}
+	}
+	sort.Ints(cpus)
+	return cpus
+}
+
+// ParseCPUList parses a kernel CPU list such as "0-3,8,10-11"
+func ParseCPUList(list string) ([]int, error) {
+	var cpus []int
+	for _, part := range strings.Split(strings.TrimSpace(list), ",") {
+		if part == "" {
+			continue
+		}
+		first, last, isRange := strings.Cut(part, "-")
+		lo, err := strconv.Atoi(first)
+		if err != nil {
+			return nil, fmt.Errorf("invalid CPU list: %q", list)
+		}
+		hi := lo
+		if isRange {
+			if hi, err = strconv.Atoi(last); err != nil || hi < lo {
+				return nil, fmt.Errorf("invalid CPU list: %q", list)
+			}
+		}
+		for cpu := lo; cpu <= hi; cpu++ {
+			cpus = append(cpus, cpu)
+		}
+	}
+	return cpus, nil
+}
@@ -0,0 +1,69 @@
+package affinity
+
+import (
+	"fmt"
+	"os"
+	"strconv"
+	"syscall"
+	"unsafe"
+)
+
+// Pin restricts every thread of the process to the given CPUs. Threads the
+// Go runtime starts later inherit the mask from the thread creating them,
+// so goroutines such as the physics workers stay on these CPUs
+func Pin(cpus []int) error {
+	if len(cpus) == 0 {
+		return fmt.Errorf("invalid CPU set: empty")
+	}
+	var mask [16]uint64 // 1024 CPUs, the kernel's default cpu_set_t
+	for _, cpu := range cpus {
+		if cpu < 0 || cpu >= len(mask)*64 {
+			return fmt.Errorf("invalid CPU: %d", cpu)
+		}
+		mask[cpu/64] |= 1 << (cpu % 64)
+	}
+
+	tasks, err := os.ReadDir("/proc/self/task")
+	if err != nil {
+		return err
+	}
+	for _, task := range tasks {
+		tid, err := strconv.Atoi(task.Name())
+		if err != nil {
+			continue
+		}
+		_, _, errno := syscall.RawSyscall(syscall.SYS_SCHED_SETAFFINITY, uintptr(tid), unsafe.Sizeof(mask), uintptr(unsafe.Pointer(&mask)))
+		if errno != 0 && errno != syscall.ESRCH { // Threads may exit while we iterate
+			return fmt.Errorf("pinning thread %d: %w", tid, errno)
+		}
+	}
+	return nil
+}
+
+// LowerPriority raises the niceness of the whole process by n (1 to 19).
+// Linux applies niceness per thread, so every current thread is lowered;
+// threads started later inherit it
+func LowerPriority(n int) error {
+	if n < 1 || n > 19 {
+		return fmt.Errorf("invalid niceness: %d (want 1 to 19)", n)
+	}
+	tasks, err := os.ReadDir("/proc/self/task")
+	if err != nil {
+		return err
+	}
+	for _, task := range tasks {
+		tid, err := strconv.Atoi(task.Name())
+		if err != nil {
+			continue
+		}
+		current, err := syscall.Getpriority(syscall.PRIO_PROCESS, tid)
+		if err != nil {
+			continue // The thread exited
+		}
+		// The raw syscall returns 20 - nice
+		if err := syscall.Setpriority(syscall.PRIO_PROCESS, tid, min(20-current+n, 19)); err != nil && err != syscall.ESRCH {
+			return fmt.Errorf("lowering thread %d: %w", tid, err)
+		}
+	}
+	return nil
+}
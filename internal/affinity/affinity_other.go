@@ -0,0 +1,13 @@
+//go:build !linux
+
+package affinity
+
+// Pin is unsupported outside Linux
+func Pin(cpus []int) error {
+	return ErrUnsupported
+}
+
+// LowerPriority is unsupported outside Linux
+func LowerPriority(n int) error {
+	return ErrUnsupported
+}
@@ -0,0 +1,64 @@
+package affinity
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+// TestParseCPUList tests ranges, single CPUs and malformed lists
+func TestParseCPUList(t *testing.T) {
+	cpus, err := ParseCPUList("0-3,8,10-11\n")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := []int{0, 1, 2, 3, 8, 10, 11}; !reflect.DeepEqual(cpus, want) {
+		t.Errorf("Expected %v, got %v", want, cpus)
+	}
+	for _, list := range []string{"a", "3-1", "0-x"} {
+		if _, err := ParseCPUList(list); err == nil {
+			t.Errorf("Expected an error for %q", list)
+		}
+	}
+}
+
+// writeCPU creates a fake sysfs cpu directory with a maximum frequency
+func writeCPU(t *testing.T, root, name, khz string) {
+	dir := filepath.Join(root, name, "cpufreq")
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(dir, "cpuinfo_max_freq"), []byte(khz+"\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+}
+
+// TestPerformanceCores tests detection from the hybrid core list and from
+// the maximum frequencies
+func TestPerformanceCores(t *testing.T) {
+	root := t.TempDir()
+	writeCPU(t, root, "cpu0", "1800000")
+	writeCPU(t, root, "cpu1", "1800000")
+	writeCPU(t, root, "cpu2", "3000000")
+	writeCPU(t, root, "cpu3", "3000000")
+	os.MkdirAll(filepath.Join(root, "cpufreq"), 0o755) // Not a CPU
+	missing := filepath.Join(root, "no-hybrid")
+
+	if got := performanceCoresIn(missing, root); !reflect.DeepEqual(got, []int{2, 3}) {
+		t.Errorf("Expected the fast cores 2 and 3, got %v", got)
+	}
+
+	hybrid := filepath.Join(root, "cpus")
+	os.WriteFile(hybrid, []byte("0-1\n"), 0o644)
+	if got := performanceCoresIn(hybrid, root); !reflect.DeepEqual(got, []int{0, 1}) {
+		t.Errorf("Expected the hybrid core list, got %v", got)
+	}
+
+	same := t.TempDir()
+	writeCPU(t, same, "cpu0", "2000000")
+	writeCPU(t, same, "cpu1", "2000000")
+	if got := performanceCoresIn(missing, same); got != nil {
+		t.Errorf("Expected no performance cores on a uniform CPU, got %v", got)
+	}
+}
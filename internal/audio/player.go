@@ -0,0 +1,74 @@
+//go:build !js
+
+package audio
+
+import (
+	"errors"
+
+	rl "github.com/gen2brain/raylib-go/raylib"
+)
+
+// BufferFrames is the size of each raylib stream buffer, about 46 ms at
+// SampleRate; raylib double-buffers, so at least one frame of the main loop
+// fits before the stream runs dry
+const BufferFrames = 2048
+
+// ErrNoAudioDevice is returned when raylib cannot open an audio device
+var ErrNoAudioDevice = errors.New("audio device not available")
+
+// Player streams a synth to the default audio device through raylib
+type Player struct {
+	synth   *Synth
+	stream  rl.AudioStream
+	buffer  []float32
+	playing bool
+}
+
+// NewPlayer opens the audio device and a mono float stream fed by synth. The
+// stream starts paused
+func NewPlayer(synth *Synth) (*Player, error) {
+	rl.InitAudioDevice()
+	if !rl.IsAudioDeviceReady() {
+		return nil, ErrNoAudioDevice
+	}
+	rl.SetAudioStreamBufferSizeDefault(BufferFrames)
+	return &Player{
+		synth:  synth,
+		stream: rl.LoadAudioStream(SampleRate, 32, 1),
+		buffer: make([]float32, BufferFrames),
+	}, nil
+}
+
+// SetPlaying starts or pauses the stream
+func (p *Player) SetPlaying(playing bool) {
+	switch {
+	case playing && !p.playing:
+		// Prime the first buffer so playback starts without a gap
+		p.synth.Fill(p.buffer)
+		rl.UpdateAudioStream(p.stream, p.buffer)
+		rl.PlayAudioStream(p.stream)
+	case !playing && p.playing:
+		rl.PauseAudioStream(p.stream)
+	}
+	p.playing = playing
+}
+
+// IsPlaying returns true if the stream is playing
+func (p *Player) IsPlaying() bool {
+	return p.playing
+}
+
+// Update refills the stream buffers that raylib has finished playing. Call
+// it once per frame
+func (p *Player) Update() {
+	for p.playing && rl.IsAudioStreamProcessed(p.stream) {
+		p.synth.Fill(p.buffer)
+		rl.UpdateAudioStream(p.stream, p.buffer)
+	}
+}
+
+// Close stops the stream and closes the audio device
+func (p *Player) Close() {
+	rl.UnloadAudioStream(p.stream)
+	rl.CloseAudioDevice()
+}
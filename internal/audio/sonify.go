@@ -0,0 +1,118 @@
+// Package audio turns the spacetime curvature into sound: a drone whose pitch
+// falls as the deepest potential well deepens, and a short ping for each
+// particle that falls into the well
+package audio
+
+import (
+	"math"
+	"relativity_simulation_2d/internal/physics"
+)
+
+// Drone pitch range in Hz. A well as deep as the first one measured plays
+// MaxFrequency; one DepthOctaves doublings deeper plays MinFrequency
+const (
+	MaxFrequency = 440.0
+	MinFrequency = 55.0
+	DepthOctaves = 4.0
+)
+
+// Drone volume range, from the shallowest to the deepest well
+const (
+	MinVolume = 0.08
+	MaxVolume = 0.35
+)
+
+// WellFraction is how deep, relative to the deepest point of the potential, a
+// particle's position must be for it to count as inside the well
+const WellFraction = 0.5
+
+// Tone is the pitch and loudness of the drone
+type Tone struct {
+	Frequency float64 // Hz
+	Volume    float64 // Linear amplitude in [0, 1]
+}
+
+// WellTone maps a well depth to the drone, relative to a reference depth.
+// The three octaves from MaxFrequency down to MinFrequency are spread over
+// DepthOctaves doublings of the depth, and the volume rises with them
+func WellTone(depth, reference float64) Tone {
+	level := 0.0
+	if depth > 0 && reference > 0 {
+		level = math.Log2(depth/reference) / DepthOctaves
+		level = math.Max(0, math.Min(1, level))
+	}
+	return Tone{
+		Frequency: MaxFrequency * math.Pow(MinFrequency/MaxFrequency, level),
+		Volume:    MinVolume + (MaxVolume-MinVolume)*level,
+	}
+}
+
+// Probe is what the sonification measures from one frame of the simulation
+type Probe struct {
+	Depth  float64 // Depth of the deepest well, -min Φ (0 if the potential has no well)
+	InWell int     // Particles deeper than WellFraction of Depth
+}
+
+// ProbeWell measures the deepest well of the potential, on cells of size dx,
+// and how many particles sit inside it
+func ProbeWell(potential physics.Grid, particles []*physics.Particle, dx float64) Probe {
+	minimum := 0.0
+	for i := range potential {
+		for _, v := range potential[i] {
+			minimum = math.Min(minimum, v)
+		}
+	}
+	if minimum >= 0 {
+		return Probe{}
+	}
+
+	probe := Probe{Depth: -minimum}
+	threshold := WellFraction * minimum
+	for _, p := range particles {
+		if physics.InterpolateGrid(potential, p.Position.X, p.Position.Z, dx) <= threshold {
+			probe.InWell++
+		}
+	}
+	return probe
+}
+
+// Sonifier drives a synth from the simulation: the drone follows the well
+// depth and every particle newly inside the well triggers a ping
+type Sonifier struct {
+	synth     *Synth
+	reference float64 // Depth of the first well measured
+	inWell    int     // Particles inside the well at the last update
+	started   bool
+}
+
+// NewSonifier creates a sonifier playing through synth
+func NewSonifier(synth *Synth) *Sonifier {
+	return &Sonifier{synth: synth}
+}
+
+// Update measures the simulation, whose grid cells are dx wide, and updates
+// the synth; it returns the probe
+func (s *Sonifier) Update(potential physics.Grid, particles []*physics.Particle, dx float64) Probe {
+	probe := ProbeWell(potential, particles, dx)
+	if s.reference == 0 {
+		s.reference = probe.Depth
+	}
+	s.synth.SetTone(WellTone(probe.Depth, s.reference))
+
+	// The first frame sets the baseline; pinging for every particle
+	// already in the well would only be noise
+	if s.started && probe.InWell > s.inWell {
+		s.synth.Ping(probe.InWell - s.inWell)
+	}
+	s.inWell = probe.InWell
+	s.started = true
+	return probe
+}
+
+// Reset forgets the reference depth and well population, as after a
+// simulation reset
+func (s *Sonifier) Reset() {
+	s.reference = 0
+	s.inWell = 0
+	s.started = false
+}
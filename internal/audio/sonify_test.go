@@ -0,0 +1,87 @@
+package audio
+
+import (
+	"math"
+	"relativity_simulation_2d/internal/physics"
+	"testing"
+)
+
+func TestWellTone(t *testing.T) {
+	flat := WellTone(0, 1)
+	if flat.Frequency != MaxFrequency || flat.Volume != MinVolume {
+		t.Errorf("Expected the top of the range for no well, got %+v", flat)
+	}
+	if got := WellTone(1, 1); got != flat {
+		t.Errorf("Expected the reference depth to play the top of the range, got %+v", got)
+	}
+
+	deepest := WellTone(math.Pow(2, DepthOctaves), 1)
+	if math.Abs(deepest.Frequency-MinFrequency) > 1e-9 || math.Abs(deepest.Volume-MaxVolume) > 1e-12 {
+		t.Errorf("Expected the bottom of the range, got %+v", deepest)
+	}
+	if got := WellTone(1e9, 1); got != deepest {
+		t.Errorf("Expected deeper wells to clamp, got %+v", got)
+	}
+
+	// Halfway in doublings is halfway in octaves
+	half := WellTone(math.Pow(2, DepthOctaves/2), 1)
+	if want := math.Sqrt(MaxFrequency * MinFrequency); math.Abs(half.Frequency-want) > 1e-9 {
+		t.Errorf("Expected %g Hz halfway, got %g", want, half.Frequency)
+	}
+}
+
+// wellGrid returns an 8x8 potential with a single well of the given depth at node (4, 4), the origin
+func wellGrid(depth float64) physics.Grid {
+	grid := physics.NewGrid(8, 8)
+	grid[4][4] = -depth
+	return grid
+}
+
+func TestProbeWell(t *testing.T) {
+	particles := []*physics.Particle{
+		physics.NewParticle(1, 0, 0, 0, 0, 0, 0),    // At the bottom
+		physics.NewParticle(1, 0.25, 0, 0, 0, 0, 0), // 75% of the depth
+		physics.NewParticle(1, 0.75, 0, 0, 0, 0, 0), // 25% of the depth
+		physics.NewParticle(1, -3, 0, 2, 0, 0, 0),   // Outside
+	}
+
+	probe := ProbeWell(wellGrid(2), particles, 1)
+	if probe.Depth != 2 || probe.InWell != 2 {
+		t.Errorf("Expected depth 2 with 2 particles inside, got %+v", probe)
+	}
+
+	if probe := ProbeWell(physics.NewGrid(8, 8), particles, 1); probe != (Probe{}) {
+		t.Errorf("Expected an empty probe for a flat potential, got %+v", probe)
+	}
+}
+
+func TestSonifierPingsOnAccretion(t *testing.T) {
+	synth := NewSynth(SampleRate)
+	sonifier := NewSonifier(synth)
+	grid := wellGrid(1)
+	inside := physics.NewParticle(1, 0, 0, 0, 0, 0, 0)
+	outside := physics.NewParticle(1, 3, 0, 3, 0, 0, 0)
+
+	sonifier.Update(grid, []*physics.Particle{inside, outside}, 1)
+	if len(synth.pings) != 0 {
+		t.Fatalf("Expected no pings for particles already in the well, got %d", len(synth.pings))
+	}
+
+	outside.Position = physics.NewVec3(0, 0, 0)
+	sonifier.Update(grid, []*physics.Particle{inside, outside}, 1)
+	if len(synth.pings) != 1 {
+		t.Errorf("Expected one ping for one accreted particle, got %d", len(synth.pings))
+	}
+
+	// The drone deepens relative to the first depth measured
+	sonifier.Update(wellGrid(4), []*physics.Particle{inside, outside}, 1)
+	if want := WellTone(4, 1); synth.target != want {
+		t.Errorf("Expected target %+v, got %+v", want, synth.target)
+	}
+
+	sonifier.Reset()
+	sonifier.Update(wellGrid(4), nil, 1)
+	if want := WellTone(1, 1); synth.target != want {
+		t.Errorf("Expected the reset to rebase the depth, got %+v", synth.target)
+	}
+}
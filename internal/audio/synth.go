@@ -0,0 +1,97 @@
+package audio
+
+import "math"
+
+// SampleRate is the output rate of the synth in Hz
+const SampleRate = 44100
+
+// Ping sound: a PingFrequency Hz sine, an octave above the highest drone, that
+// decays with time constant PingDecay seconds. At most MaxPings ring at once
+const (
+	PingFrequency = 880.0
+	PingVolume    = 0.25
+	PingDecay     = 0.06
+	MaxPings      = 8
+)
+
+// glideTime is the time constant in seconds over which the drone follows a
+// new tone, so pitch changes slide instead of clicking
+const glideTime = 0.05
+
+// ping is one ringing accretion ping
+type ping struct {
+	phase     float64
+	amplitude float64
+}
+
+// Synth generates the drone and pings as mono float samples in [-1, 1]
+type Synth struct {
+	sampleRate float64
+	target     Tone
+	frequency  float64
+	volume     float64
+	phase      float64
+	pings      []ping
+	glide      float64 // Per-sample smoothing factor towards the target
+	decay      float64 // Per-sample ping amplitude factor
+}
+
+// NewSynth creates a silent synth producing sampleRate samples per second
+func NewSynth(sampleRate int) *Synth {
+	rate := float64(sampleRate)
+	return &Synth{
+		sampleRate: rate,
+		frequency:  MaxFrequency,
+		target:     Tone{Frequency: MaxFrequency},
+		glide:      1 - math.Exp(-1/(glideTime*rate)),
+		decay:      math.Exp(-1 / (PingDecay * rate)),
+	}
+}
+
+// SetTone sets the drone the synth glides towards
+func (s *Synth) SetTone(t Tone) {
+	s.target = t
+}
+
+// GetTone returns the drone currently playing
+func (s *Synth) GetTone() Tone {
+	return Tone{Frequency: s.frequency, Volume: s.volume}
+}
+
+// Ping starts n pings, dropping the oldest beyond MaxPings
+func (s *Synth) Ping(n int) {
+	for i := 0; i < n; i++ {
+		if len(s.pings) == MaxPings {
+			s.pings = append(s.pings[:0], s.pings[1:]...)
+		}
+		s.pings = append(s.pings, ping{amplitude: PingVolume})
+	}
+}
+
+// Fill writes the next len(buf) samples
+func (s *Synth) Fill(buf []float32) {
+	pingStep := 2 * math.Pi * PingFrequency / s.sampleRate
+	for n := range buf {
+		s.frequency += (s.target.Frequency - s.frequency) * s.glide
+		s.volume += (s.target.Volume - s.volume) * s.glide
+		s.phase = math.Mod(s.phase+2*math.Pi*s.frequency/s.sampleRate, 2*math.Pi)
+		sample := s.volume * math.Sin(s.phase)
+
+		for i := range s.pings {
+			p := &s.pings[i]
+			p.phase = math.Mod(p.phase+pingStep, 2*math.Pi)
+			sample += p.amplitude * math.Sin(p.phase)
+			p.amplitude *= s.decay
+		}
+		buf[n] = float32(math.Max(-1, math.Min(1, sample)))
+	}
+
+	// Drop pings that have faded below hearing
+	live := s.pings[:0]
+	for _, p := range s.pings {
+		if p.amplitude > 1e-4 {
+			live = append(live, p)
+		}
+	}
+	s.pings = live
+}
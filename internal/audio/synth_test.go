@@ -0,0 +1,65 @@
+package audio
+
+import (
+	"math"
+	"testing"
+)
+
+func TestSynthSilentByDefault(t *testing.T) {
+	synth := NewSynth(SampleRate)
+	buf := make([]float32, 1024)
+	synth.Fill(buf)
+	for i, v := range buf {
+		if v != 0 {
+			t.Fatalf("Expected silence, sample %d is %g", i, v)
+		}
+	}
+}
+
+func TestSynthGlidesToTone(t *testing.T) {
+	synth := NewSynth(SampleRate)
+	synth.SetTone(Tone{Frequency: 110, Volume: 0.2})
+	buf := make([]float32, SampleRate/2) // Ten glide time constants
+	synth.Fill(buf)
+
+	tone := synth.GetTone()
+	if math.Abs(tone.Frequency-110) > 0.1 || math.Abs(tone.Volume-0.2) > 1e-4 {
+		t.Errorf("Expected the synth to reach the target, got %+v", tone)
+	}
+
+	// The last 1/110 s holds one full period at the target amplitude
+	peak := 0.0
+	for _, v := range buf[len(buf)-SampleRate/110:] {
+		peak = math.Max(peak, math.Abs(float64(v)))
+	}
+	if math.Abs(peak-0.2) > 0.01 {
+		t.Errorf("Expected amplitude 0.2, got %g", peak)
+	}
+}
+
+func TestSynthPings(t *testing.T) {
+	synth := NewSynth(SampleRate)
+	synth.Ping(MaxPings + 3)
+	if len(synth.pings) != MaxPings {
+		t.Fatalf("Expected %d pings, got %d", MaxPings, len(synth.pings))
+	}
+
+	buf := make([]float32, 256)
+	synth.Fill(buf)
+	loud := 0.0
+	for _, v := range buf {
+		if math.Abs(float64(v)) > 1 {
+			t.Fatalf("Sample %g outside [-1, 1]", v)
+		}
+		loud = math.Max(loud, math.Abs(float64(v)))
+	}
+	if loud == 0 {
+		t.Error("Expected pings to be audible")
+	}
+
+	// Pings fade and are dropped
+	synth.Fill(make([]float32, SampleRate))
+	if len(synth.pings) != 0 {
+		t.Errorf("Expected faded pings to be dropped, %d remain", len(synth.pings))
+	}
+}
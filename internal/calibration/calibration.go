@@ -0,0 +1,179 @@
+package calibration
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"relativity_simulation_2d/internal/config"
+	"sort"
+	"time"
+)
+
+// Sizes are the square grid sizes timed, smallest first
+var Sizes = []int{64, 128, 256, 512, 1024}
+
+// Steps is the number of steps timed per grid size, after WarmupSteps
+// untimed ones that build the GPU context, plans and shaders
+const (
+	Steps       = 5
+	WarmupSteps = 2
+)
+
+// PhysicsShare is the share of a frame the physics may take when no physics
+// budget is configured, leaving the rest for drawing
+const PhysicsShare = 0.5
+
+// Sample is the step time of one processor on one grid size
+type Sample struct {
+	Processor string  `json:"processor"` // config.ComputeCPU or config.ComputeGPU
+	Width     int     `json:"width"`
+	Depth     int     `json:"depth"`
+	StepMs    float64 `json:"step_ms"` // Median time of a step
+}
+
+// Result is the grid size and processor recommended for the hardware, with
+// the measurements it was chosen from
+type Result struct {
+	TargetFPS int      `json:"target_fps"` // Frame rate the recommendation keeps to
+	BudgetMs  float64  `json:"budget_ms"`  // Physics time per frame allowed
+	Particles int      `json:"particles"`  // Particles stepped while timing
+	Width     int      `json:"width"`
+	Depth     int      `json:"depth"`
+	UseGPU    bool     `json:"use_gpu"`
+	Samples   []Sample `json:"samples"`
+}
+
+// Budget returns the physics time per frame of c: its PhysicsBudget, or
+// PhysicsShare of a frame at its target frame rate (60 FPS if uncapped)
+func Budget(c *config.Config) time.Duration {
+	if c.PhysicsBudget > 0 {
+		return time.Duration(c.PhysicsBudget * float64(time.Millisecond))
+	}
+	fps := c.TargetFPS
+	if fps <= 0 {
+		fps = config.DefaultConfig().TargetFPS
+	}
+	return time.Duration(PhysicsShare * float64(time.Second) / float64(fps))
+}
+
+// Measure times each processor on the grid sizes, smallest first, with
+// step, which returns the median step time of a size. A processor stops at
+// the first size over budget, since larger grids only take longer, and at
+// its first error, which is returned with the samples taken
+func Measure(processors []string, sizes []int, budget time.Duration, step func(processor string, size int) (time.Duration, error)) ([]Sample, map[string]error) {
+	var samples []Sample
+	failed := make(map[string]error)
+	for _, processor := range processors {
+		for _, size := range sizes {
+			d, err := step(processor, size)
+			if err != nil {
+				failed[processor] = err
+				break
+			}
+			samples = append(samples, Sample{
+				Processor: processor,
+				Width:     size,
+				Depth:     size,
+				StepMs:    float64(d.Microseconds()) / 1000,
+			})
+			if d > budget {
+				break
+			}
+		}
+	}
+	return samples, failed
+}
+
+// Median returns the median of the durations, which must not be empty
+func Median(durations []time.Duration) time.Duration {
+	sorted := append([]time.Duration(nil), durations...)
+	sort.Slice(sorted, func(i, j int) bool { return sorted[i] < sorted[j] })
+	return sorted[len(sorted)/2]
+}
+
+// Recommend picks the largest grid a processor steps within budget, on the
+// faster processor for that grid. If no grid fits, the fastest sample is
+// taken
+func Recommend(samples []Sample, budget time.Duration) (Result, error) {
+	if len(samples) == 0 {
+		return Result{}, errors.New("no calibration samples")
+	}
+	limit := float64(budget.Microseconds()) / 1000
+	better := func(s, than Sample) bool {
+		fits, thanFits := s.StepMs <= limit, than.StepMs <= limit
+		switch {
+		case fits != thanFits:
+			return fits
+		case fits && s.cells() != than.cells():
+			return s.cells() > than.cells()
+		default:
+			return s.StepMs < than.StepMs
+		}
+	}
+	best := samples[0]
+	for _, s := range samples[1:] {
+		if better(s, best) {
+			best = s
+		}
+	}
+	return Result{
+		BudgetMs: limit,
+		Width:    best.Width,
+		Depth:    best.Depth,
+		UseGPU:   best.Processor == config.ComputeGPU,
+		Samples:  samples,
+	}, nil
+}
+
+// cells returns the number of cells of the sample's grid
+func (s Sample) cells() int {
+	return s.Width * s.Depth
+}
+
+// Apply sets the recommended grid size and processor in c
+func (r Result) Apply(c *config.Config) {
+	c.SimulationWidth, c.SimulationDepth = r.Width, r.Depth
+	c.UseGPU = r.UseGPU
+}
+
+// Processor names the recommended processor
+func (r Result) Processor() string {
+	if r.UseGPU {
+		return config.ComputeGPU
+	}
+	return config.ComputeCPU
+}
+
+// Save writes the result to path as indented JSON, creating its directory
+func Save(path string, r Result) error {
+	data, err := json.MarshalIndent(r, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to encode calibration: %v", err)
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		return fmt.Errorf("failed to create calibration directory: %v", err)
+	}
+	if err := os.WriteFile(path, append(data, '\n'), 0o644); err != nil {
+		return fmt.Errorf("failed to write calibration: %v", err)
+	}
+	return nil
+}
+
+// Load reads a result written by Save. A missing file is reported with an
+// error matching fs.ErrNotExist
+func Load(path string) (Result, error) {
+	var r Result
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return r, err
+	}
+	if err := json.Unmarshal(data, &r); err != nil {
+		return r, fmt.Errorf("invalid calibration: %v", err)
+	}
+	if r.Width <= 0 || r.Depth <= 0 {
+		return r, fmt.Errorf("invalid calibration: grid %dx%d", r.Width, r.Depth)
+	}
+	return r, nil
+}
@@ -0,0 +1,128 @@
+package calibration
+
+import (
+	"errors"
+	"io/fs"
+	"path/filepath"
+	"reflect"
+	"relativity_simulation_2d/internal/config"
+	"testing"
+	"time"
+)
+
+// TestBudget tests the physics budget from the frame rate or the configured budget
+func TestBudget(t *testing.T) {
+	c := config.DefaultConfig()
+	c.TargetFPS = 50
+	if got := Budget(c); got != 10*time.Millisecond {
+		t.Errorf("Expected half of a 50 FPS frame, got %v", got)
+	}
+	c.TargetFPS = 0
+	if got := Budget(c); got != 8333333 {
+		t.Errorf("Expected half of a 60 FPS frame when uncapped, got %v", got)
+	}
+	c.PhysicsBudget = 4
+	if got := Budget(c); got != 4*time.Millisecond {
+		t.Errorf("Expected the configured 4 ms, got %v", got)
+	}
+}
+
+// TestMeasure tests that a processor stops at the first grid over budget
+// and at its first error
+func TestMeasure(t *testing.T) {
+	stepTimes := map[string]map[int]time.Duration{
+		config.ComputeCPU: {64: time.Millisecond, 128: 4 * time.Millisecond, 256: 16 * time.Millisecond},
+		config.ComputeGPU: {64: time.Millisecond},
+	}
+	var timed []int
+	samples, failed := Measure([]string{config.ComputeCPU, config.ComputeGPU}, []int{64, 128, 256, 512}, 8*time.Millisecond, func(processor string, size int) (time.Duration, error) {
+		d, ok := stepTimes[processor][size]
+		if !ok {
+			return 0, errors.New("no context")
+		}
+		timed = append(timed, size)
+		return d, nil
+	})
+
+	if want := []int{64, 128, 256, 64}; !reflect.DeepEqual(timed, want) {
+		t.Errorf("Expected the CPU to stop after 256 and the GPU at its error, timed %v", timed)
+	}
+	if len(samples) != 4 || samples[2] != (Sample{Processor: config.ComputeCPU, Width: 256, Depth: 256, StepMs: 16}) {
+		t.Errorf("Expected 4 samples with the 256 CPU grid third, got %+v", samples)
+	}
+	if len(failed) != 1 || failed[config.ComputeGPU] == nil {
+		t.Errorf("Expected the GPU error, got %v", failed)
+	}
+}
+
+// TestMedian tests the median step time
+func TestMedian(t *testing.T) {
+	if got := Median([]time.Duration{5, 1, 9, 3, 100}); got != 5 {
+		t.Errorf("Expected 5, got %v", got)
+	}
+}
+
+// TestRecommend tests picking the largest grid within budget on the faster
+// processor, and the fastest grid when none fits
+func TestRecommend(t *testing.T) {
+	samples := []Sample{
+		{config.ComputeCPU, 64, 64, 1},
+		{config.ComputeCPU, 128, 128, 5},
+		{config.ComputeCPU, 256, 256, 20},
+		{config.ComputeGPU, 64, 64, 2},
+		{config.ComputeGPU, 128, 128, 3},
+		{config.ComputeGPU, 256, 256, 7},
+		{config.ComputeGPU, 512, 512, 30},
+	}
+	r, err := Recommend(samples, 8*time.Millisecond)
+	if err != nil {
+		t.Fatalf("Recommend failed: %v", err)
+	}
+	if r.Width != 256 || r.Depth != 256 || !r.UseGPU || r.BudgetMs != 8 {
+		t.Errorf("Expected 256x256 on the GPU within 8 ms, got %+v", r)
+	}
+
+	if r, _ := Recommend(samples, 4*time.Millisecond); r.Width != 128 || !r.UseGPU {
+		t.Errorf("Expected 128x128 on the faster GPU, got %dx%d on the %s", r.Width, r.Depth, r.Processor())
+	}
+	if r, _ := Recommend(samples, 500*time.Microsecond); r.Width != 64 || r.UseGPU {
+		t.Errorf("Expected the fastest grid when none fits, got %dx%d on the %s", r.Width, r.Depth, r.Processor())
+	}
+	if _, err := Recommend(nil, time.Millisecond); err == nil {
+		t.Error("Expected an error without samples")
+	}
+}
+
+// TestSaveLoad tests writing a result, reading it back and applying it
+func TestSaveLoad(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "config", "calibration.json")
+	if _, err := Load(path); !errors.Is(err, fs.ErrNotExist) {
+		t.Fatalf("Expected a missing file, got %v", err)
+	}
+
+	want := Result{TargetFPS: 60, BudgetMs: 8.333, Particles: 2000, Width: 512, Depth: 512, UseGPU: true, Samples: []Sample{{config.ComputeGPU, 512, 512, 6.5}}}
+	if err := Save(path, want); err != nil {
+		t.Fatalf("Save failed: %v", err)
+	}
+	got, err := Load(path)
+	if err != nil {
+		t.Fatalf("Load failed: %v", err)
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("Expected %+v, got %+v", want, got)
+	}
+
+	c := config.DefaultConfig()
+	c.UseGPU = false
+	got.Apply(c)
+	if c.SimulationWidth != 512 || c.SimulationDepth != 512 || !c.UseGPU {
+		t.Errorf("Expected the 512x512 GPU setup applied, got %dx%d (GPU %v)", c.SimulationWidth, c.SimulationDepth, c.UseGPU)
+	}
+
+	if err := Save(path, Result{}); err != nil {
+		t.Fatalf("Save failed: %v", err)
+	}
+	if _, err := Load(path); err == nil {
+		t.Error("Expected an empty grid to be rejected")
+	}
+}
@@ -0,0 +1,178 @@
+// Package compare lines up two diagnostics CSVs, such as the runs before and
+// after a code change or a CPU and a GPU run, and reports how far each
+// quantity drifted apart, with overlaid plots
+package compare
+
+import (
+	"encoding/csv"
+	"fmt"
+	"io"
+	"math"
+	"os"
+	"strconv"
+)
+
+// Table is a diagnostics CSV: named columns of numbers, one row per sample
+type Table struct {
+	Columns []string
+	Rows    [][]float64
+}
+
+// ReadTable reads a CSV with a header row and numeric values
+func ReadTable(r io.Reader) (Table, error) {
+	records, err := csv.NewReader(r).ReadAll()
+	if err != nil {
+		return Table{}, fmt.Errorf("failed to read diagnostics: %v", err)
+	}
+	if len(records) < 2 {
+		return Table{}, fmt.Errorf("diagnostics file has no records")
+	}
+	t := Table{Columns: records[0], Rows: make([][]float64, 0, len(records)-1)}
+	for i, record := range records[1:] {
+		row := make([]float64, len(t.Columns))
+		for j := range row {
+			if j >= len(record) {
+				return Table{}, fmt.Errorf("row %d has %d of %d columns", i+1, len(record), len(t.Columns))
+			}
+			v, err := strconv.ParseFloat(record[j], 64)
+			if err != nil {
+				return Table{}, fmt.Errorf("invalid %s value %q in row %d", t.Columns[j], record[j], i+1)
+			}
+			row[j] = v
+		}
+		t.Rows = append(t.Rows, row)
+	}
+	return t, nil
+}
+
+// LoadTable reads the diagnostics CSV at path
+func LoadTable(path string) (Table, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return Table{}, fmt.Errorf("failed to open diagnostics: %v", err)
+	}
+	defer file.Close()
+	return ReadTable(file)
+}
+
+// Column returns the index of the named column, or -1
+func (t Table) Column(name string) int {
+	for i, c := range t.Columns {
+		if c == name {
+			return i
+		}
+	}
+	return -1
+}
+
+// Diff is the comparison of one quantity over the samples both runs share
+type Diff struct {
+	Name   string
+	X      []float64 // Sample positions: sim_time if both runs have it, else step
+	A, B   []float64 // Values of each run at X
+	MaxAbs float64   // Largest |b - a|
+	RMS    float64   // Root mean square of b - a
+	MaxRel float64   // Largest |b - a| / max(|a|, |b|), over samples not both 0
+	WorstX float64   // X of the largest |b - a|
+}
+
+// FinalA returns the last value of the first run
+func (d Diff) FinalA() float64 {
+	return d.A[len(d.A)-1]
+}
+
+// FinalB returns the last value of the second run
+func (d Diff) FinalB() float64 {
+	return d.B[len(d.B)-1]
+}
+
+// Report is the comparison of two runs
+type Report struct {
+	LabelA, LabelB string
+	XLabel         string  // Column the samples are plotted against
+	Samples        int     // Steps both runs recorded
+	OnlyA, OnlyB   int     // Steps only one run recorded
+	Tolerance      float64 // Largest relative difference that passes (0 = report only)
+	Diffs          []Diff
+}
+
+// Failed returns the quantities whose relative difference exceeds the tolerance
+func (r Report) Failed() []string {
+	if r.Tolerance <= 0 {
+		return nil
+	}
+	var failed []string
+	for _, d := range r.Diffs {
+		if d.MaxRel > r.Tolerance || math.IsNaN(d.MaxRel) {
+			failed = append(failed, d.Name)
+		}
+	}
+	return failed
+}
+
+// Compare lines up the rows of a and b with the same step and compares the
+// columns both have. columns limits the comparison to the named columns
+// (nil = all shared columns but step)
+func Compare(a, b Table, columns []string) (Report, error) {
+	stepA, stepB := a.Column("step"), b.Column("step")
+	if stepA < 0 || stepB < 0 {
+		return Report{}, fmt.Errorf("diagnostics missing column %q", "step")
+	}
+	rowsB := make(map[float64][]float64, len(b.Rows))
+	for _, row := range b.Rows {
+		rowsB[row[stepB]] = row
+	}
+	var pairs [][2][]float64
+	for _, row := range a.Rows {
+		if other, ok := rowsB[row[stepA]]; ok {
+			pairs = append(pairs, [2][]float64{row, other})
+		}
+	}
+	if len(pairs) == 0 {
+		return Report{}, fmt.Errorf("the runs share no steps")
+	}
+	report := Report{Samples: len(pairs), OnlyA: len(a.Rows) - len(pairs), OnlyB: len(b.Rows) - len(pairs)}
+
+	xA, xB := stepA, stepB
+	report.XLabel = "step"
+	if ta, tb := a.Column("sim_time"), b.Column("sim_time"); ta >= 0 && tb >= 0 {
+		xA, xB = ta, tb
+		report.XLabel = "sim_time"
+	}
+	if columns == nil {
+		for _, name := range a.Columns {
+			if name != "step" && name != report.XLabel && b.Column(name) >= 0 {
+				columns = append(columns, name)
+			}
+		}
+	}
+
+	for _, name := range columns {
+		ca, cb := a.Column(name), b.Column(name)
+		if ca < 0 || cb < 0 {
+			return Report{}, fmt.Errorf("diagnostics missing column %q", name)
+		}
+		d := Diff{Name: name, X: make([]float64, len(pairs)), A: make([]float64, len(pairs)), B: make([]float64, len(pairs))}
+		sumSq := 0.0
+		for i, p := range pairs {
+			// Both runs sample the same step, so their times only differ by rounding
+			d.X[i] = (p[0][xA] + p[1][xB]) / 2
+			d.A[i], d.B[i] = p[0][ca], p[1][cb]
+			diff := math.Abs(d.B[i] - d.A[i])
+			switch {
+			case math.IsNaN(d.MaxAbs): // An earlier sample was not finite
+			case math.IsNaN(diff):
+				d.MaxAbs, d.MaxRel, d.WorstX = math.NaN(), math.NaN(), d.X[i]
+			case diff > d.MaxAbs:
+				d.MaxAbs, d.WorstX = diff, d.X[i]
+			}
+			if scale := math.Max(math.Abs(d.A[i]), math.Abs(d.B[i])); scale > 0 {
+				d.MaxRel = math.Max(d.MaxRel, diff/scale)
+			}
+			sumSq += diff * diff
+		}
+		d.RMS = math.Sqrt(sumSq / float64(len(pairs)))
+		report.Diffs = append(report.Diffs, d)
+	}
+	return report, nil
+}
@@ -0,0 +1,111 @@
+package compare
+
+import (
+	"math"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// runA and runB are two short diagnostics files; runB skipped step 20 and
+// recorded step 40 too
+const (
+	runA = "step,sim_time,kinetic_energy,total_mass\n0,0,1,10\n10,0.1,2,10\n20,0.2,4,10\n30,0.3,8,10\n"
+	runB = "step,sim_time,kinetic_energy,total_mass,max_speed\n0,0,1,10,1\n10,0.1,2.5,10,1\n30,0.3,7,10,2\n40,0.4,9,10,2\n"
+)
+
+// mustRead parses a diagnostics file or fails the test
+func mustRead(t *testing.T, data string) Table {
+	t.Helper()
+	table, err := ReadTable(strings.NewReader(data))
+	if err != nil {
+		t.Fatalf("ReadTable failed: %v", err)
+	}
+	return table
+}
+
+// TestCompare tests lining up the runs by step and the difference statistics
+func TestCompare(t *testing.T) {
+	r, err := Compare(mustRead(t, runA), mustRead(t, runB), nil)
+	if err != nil {
+		t.Fatalf("Compare failed: %v", err)
+	}
+	if r.Samples != 3 || r.OnlyA != 1 || r.OnlyB != 1 || r.XLabel != "sim_time" {
+		t.Fatalf("Expected 3 shared samples plotted over sim_time, got %+v", r)
+	}
+	if len(r.Diffs) != 2 || r.Diffs[0].Name != "kinetic_energy" || r.Diffs[1].Name != "total_mass" {
+		t.Fatalf("Expected the shared quantities, got %d", len(r.Diffs))
+	}
+
+	kinetic := r.Diffs[0]
+	if kinetic.MaxAbs != 1 || kinetic.WorstX != 0.3 || kinetic.FinalA() != 8 || kinetic.FinalB() != 7 {
+		t.Errorf("Expected the largest difference 1 at t = 0.3, got %g at %g", kinetic.MaxAbs, kinetic.WorstX)
+	}
+	if want := math.Sqrt((0.25 + 1) / 3); math.Abs(kinetic.RMS-want) > 1e-12 {
+		t.Errorf("Expected RMS %g, got %g", want, kinetic.RMS)
+	}
+	if math.Abs(kinetic.MaxRel-0.2) > 1e-12 {
+		t.Errorf("Expected relative difference 0.2, got %g", kinetic.MaxRel)
+	}
+
+	r.Tolerance = 0.1
+	if failed := r.Failed(); len(failed) != 1 || failed[0] != "kinetic_energy" {
+		t.Errorf("Expected only kinetic_energy over the tolerance, got %v", failed)
+	}
+
+	if _, err := Compare(mustRead(t, runA), mustRead(t, runB), []string{"max_speed"}); err == nil {
+		t.Error("Expected an error for a quantity only one run has")
+	}
+}
+
+// TestCompareNonFinite tests that a non-finite sample fails any tolerance
+func TestCompareNonFinite(t *testing.T) {
+	r, err := Compare(mustRead(t, "step,kinetic_energy\n0,1\n1,NaN\n2,1\n"), mustRead(t, "step,kinetic_energy\n0,1\n1,1\n2,1\n"), nil)
+	if err != nil {
+		t.Fatalf("Compare failed: %v", err)
+	}
+	d := r.Diffs[0]
+	if r.XLabel != "step" || !math.IsNaN(d.MaxAbs) || !math.IsNaN(d.MaxRel) || d.WorstX != 1 {
+		t.Fatalf("Expected a NaN difference at step 1, got %g at %g", d.MaxAbs, d.WorstX)
+	}
+	r.Tolerance = 1
+	if len(r.Failed()) != 1 {
+		t.Error("Expected the non-finite quantity to fail")
+	}
+}
+
+// TestSaveReport tests the HTML and Markdown reports
+func TestSaveReport(t *testing.T) {
+	r, err := Compare(mustRead(t, runA), mustRead(t, runB), []string{"kinetic_energy"})
+	if err != nil {
+		t.Fatalf("Compare failed: %v", err)
+	}
+	r.LabelA, r.LabelB, r.Tolerance = "cpu", "gpu <fast>", 0.1
+	dir := t.TempDir()
+
+	if err := SaveReport(filepath.Join(dir, "report.html"), r); err != nil {
+		t.Fatalf("SaveReport failed: %v", err)
+	}
+	page, _ := os.ReadFile(filepath.Join(dir, "report.html"))
+	for _, want := range []string{"gpu &lt;fast&gt;", `<td class="fail">FAIL</td>`, "<svg", "<polyline"} {
+		if !strings.Contains(string(page), want) {
+			t.Errorf("Expected %q in the HTML report", want)
+		}
+	}
+
+	if err := SaveReport(filepath.Join(dir, "report.md"), r); err != nil {
+		t.Fatalf("SaveReport failed: %v", err)
+	}
+	doc, _ := os.ReadFile(filepath.Join(dir, "report.md"))
+	if !strings.Contains(string(doc), "![kinetic_energy](report-kinetic_energy.svg)") || !strings.Contains(string(doc), "| kinetic_energy | 8 | 7 |") {
+		t.Errorf("Unexpected Markdown report:\n%s", doc)
+	}
+	if svg, err := os.ReadFile(filepath.Join(dir, "report-kinetic_energy.svg")); err != nil || strings.Count(string(svg), "<polyline") != 2 {
+		t.Errorf("Expected both runs in the plot file, got %v", err)
+	}
+
+	if err := SaveReport(filepath.Join(dir, "report.pdf"), r); err == nil {
+		t.Error("Expected an error for a .pdf report")
+	}
+}
@@ -0,0 +1,188 @@
+package compare
+
+import (
+	"fmt"
+	"html"
+	"image/color"
+	"io"
+	"os"
+	"path/filepath"
+	"relativity_simulation_2d/internal/plot"
+	"strings"
+)
+
+// Report file formats, chosen by extension
+const (
+	FormatHTML     = ".html"
+	FormatMarkdown = ".md"
+)
+
+// Size of the plots in pixels
+const (
+	plotWidth  = 640
+	plotHeight = 240
+	plotMargin = 56 // Room for the tick labels on the left and bottom
+	plotTicks  = 5
+)
+
+// Colors of the two runs in the plots
+var (
+	colorA = color.RGBA{R: 31, G: 119, B: 180, A: 255}
+	colorB = color.RGBA{R: 255, G: 127, B: 14, A: 255}
+)
+
+// SVG returns the quantity of both runs overlaid in one chart, as an SVG
+// document
+func (r Report) SVG(d Diff) string {
+	a := plot.NewSeries(r.LabelA, colorA, len(d.X))
+	b := plot.NewSeries(r.LabelB, colorB, len(d.X))
+	for i, x := range d.X {
+		a.Add(x, d.A[i])
+		b.Add(x, d.B[i])
+	}
+	layout := plot.NewChart(d.Name, 0, a, b).Layout(plot.Rect{W: plotWidth, H: plotHeight}, plotMargin, plotTicks)
+	area := layout.Plot
+
+	var sb strings.Builder
+	fmt.Fprintf(&sb, `<svg xmlns="http://www.w3.org/2000/svg" width="%d" height="%d" font-family="sans-serif" font-size="11">`+"\n", plotWidth, plotHeight+20)
+	fmt.Fprintf(&sb, `<rect x="%.1f" y="%.1f" width="%.1f" height="%.1f" fill="none" stroke="#999"/>`+"\n", area.X, area.Y, area.W, area.H)
+	for _, t := range layout.XTicks {
+		fmt.Fprintf(&sb, `<text x="%.1f" y="%.1f" text-anchor="middle">%s</text>`+"\n", t.Pos, area.Y+area.H+14, t.Label)
+	}
+	for _, t := range layout.YTicks {
+		fmt.Fprintf(&sb, `<text x="%.1f" y="%.1f" text-anchor="end">%s</text>`+"\n", area.X-4, t.Pos+4, t.Label)
+	}
+	fmt.Fprintf(&sb, `<text x="%.1f" y="%d" text-anchor="middle">%s</text>`+"\n", area.X+area.W/2, plotHeight+16, html.EscapeString(r.XLabel))
+	for k, line := range layout.Lines {
+		points := make([]string, len(line.Points))
+		for i, p := range line.Points {
+			points[i] = fmt.Sprintf("%.1f,%.1f", p.X, p.Y)
+		}
+		c := line.Series.Color
+		dash := ""
+		if k == 1 {
+			dash = ` stroke-dasharray="6 3"` // Keeps identical runs distinguishable
+		}
+		fmt.Fprintf(&sb, `<polyline fill="none" stroke="rgb(%d,%d,%d)" stroke-width="1.5"%s points="%s"/>`+"\n", c.R, c.G, c.B, dash, strings.Join(points, " "))
+		fmt.Fprintf(&sb, `<text x="%.1f" y="%.1f" fill="rgb(%d,%d,%d)">%s</text>`+"\n", area.X+8, area.Y+14+14*float64(k), c.R, c.G, c.B, html.EscapeString(line.Series.Name))
+	}
+	sb.WriteString("</svg>\n")
+	return sb.String()
+}
+
+// summary returns the report's opening line
+func (r Report) summary() string {
+	text := fmt.Sprintf("%d samples compared", r.Samples)
+	if r.OnlyA > 0 || r.OnlyB > 0 {
+		text += fmt.Sprintf(" (%d only in %s, %d only in %s)", r.OnlyA, r.LabelA, r.OnlyB, r.LabelB)
+	}
+	if r.Tolerance > 0 {
+		if failed := r.Failed(); len(failed) > 0 {
+			text += fmt.Sprintf("; %d of %d quantities differ by more than %g", len(failed), len(r.Diffs), r.Tolerance)
+		} else {
+			text += fmt.Sprintf("; all quantities agree within %g", r.Tolerance)
+		}
+	}
+	return text
+}
+
+// cells returns the statistics of d as table cells
+func (r Report) cells(d Diff) []string {
+	status := ""
+	if r.Tolerance > 0 {
+		status = "ok"
+		for _, name := range r.Failed() {
+			if name == d.Name {
+				status = "FAIL"
+			}
+		}
+	}
+	return []string{d.Name, plot.FormatTick(d.FinalA()), plot.FormatTick(d.FinalB()), plot.FormatTick(d.MaxAbs), plot.FormatTick(d.RMS), plot.FormatTick(d.MaxRel), plot.FormatTick(d.WorstX), status}
+}
+
+// headers returns the column headers of the statistics table
+func (r Report) headers() []string {
+	return []string{"quantity", "final " + r.LabelA, "final " + r.LabelB, "max abs diff", "RMS diff", "max rel diff", "worst at " + r.XLabel, "status"}
+}
+
+// WriteHTML writes the report as a self-contained HTML page with the plots inline
+func WriteHTML(w io.Writer, r Report) error {
+	var sb strings.Builder
+	title := html.EscapeString(fmt.Sprintf("%s vs %s", r.LabelA, r.LabelB))
+	fmt.Fprintf(&sb, "<!DOCTYPE html>\n<html>\n<head>\n<meta charset=\"utf-8\">\n<title>%s</title>\n", title)
+	sb.WriteString("<style>body{font-family:sans-serif;margin:2em}table{border-collapse:collapse}td,th{border:1px solid #ccc;padding:4px 8px;text-align:right}td:first-child{text-align:left}.fail{color:#c00;font-weight:bold}</style>\n</head>\n<body>\n")
+	fmt.Fprintf(&sb, "<h1>%s</h1>\n<p>%s</p>\n<table>\n<tr>", title, html.EscapeString(r.summary()))
+	for _, h := range r.headers() {
+		fmt.Fprintf(&sb, "<th>%s</th>", html.EscapeString(h))
+	}
+	sb.WriteString("</tr>\n")
+	for _, d := range r.Diffs {
+		cells := r.cells(d)
+		sb.WriteString("<tr>")
+		for _, c := range cells {
+			if c == "FAIL" {
+				fmt.Fprintf(&sb, `<td class="fail">%s</td>`, c)
+			} else {
+				fmt.Fprintf(&sb, "<td>%s</td>", html.EscapeString(c))
+			}
+		}
+		sb.WriteString("</tr>\n")
+	}
+	sb.WriteString("</table>\n")
+	for _, d := range r.Diffs {
+		fmt.Fprintf(&sb, "<h2>%s</h2>\n%s", html.EscapeString(d.Name), r.SVG(d))
+	}
+	sb.WriteString("</body>\n</html>\n")
+	_, err := io.WriteString(w, sb.String())
+	return err
+}
+
+// WriteMarkdown writes the report as Markdown, linking each quantity's plot
+// at plotPath(name)
+func WriteMarkdown(w io.Writer, r Report, plotPath func(name string) string) error {
+	var sb strings.Builder
+	fmt.Fprintf(&sb, "# %s vs %s\n\n%s\n\n", r.LabelA, r.LabelB, r.summary())
+	headers := r.headers()
+	fmt.Fprintf(&sb, "| %s |\n|%s\n", strings.Join(headers, " | "), strings.Repeat(" --- |", len(headers)))
+	for _, d := range r.Diffs {
+		fmt.Fprintf(&sb, "| %s |\n", strings.Join(r.cells(d), " | "))
+	}
+	for _, d := range r.Diffs {
+		fmt.Fprintf(&sb, "\n## %s\n\n![%s](%s)\n", d.Name, d.Name, plotPath(d.Name))
+	}
+	_, err := io.WriteString(w, sb.String())
+	return err
+}
+
+// SaveReport writes the report to path, as HTML or Markdown by its
+// extension. A Markdown report's plots are written beside it as SVG files
+// named after it and the quantity
+func SaveReport(path string, r Report) error {
+	ext := strings.ToLower(filepath.Ext(path))
+	if ext != FormatHTML && ext != FormatMarkdown {
+		return fmt.Errorf("invalid report file %q (want %s or %s)", path, FormatHTML, FormatMarkdown)
+	}
+	file, err := os.Create(path)
+	if err != nil {
+		return fmt.Errorf("failed to create report: %v", err)
+	}
+	if ext == FormatHTML {
+		err = WriteHTML(file, r)
+	} else {
+		stem := strings.TrimSuffix(path, filepath.Ext(path))
+		plotPath := func(name string) string { return stem + "-" + name + ".svg" }
+		for _, d := range r.Diffs {
+			if err = os.WriteFile(plotPath(d.Name), []byte(r.SVG(d)), 0644); err != nil {
+				break
+			}
+		}
+		if err == nil {
+			err = WriteMarkdown(file, r, func(name string) string { return filepath.Base(plotPath(name)) })
+		}
+	}
+	if err != nil {
+		_ = file.Close()
+		return fmt.Errorf("failed to write report: %v", err)
+	}
+	return file.Close()
+}
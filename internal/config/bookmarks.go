@@ -0,0 +1,95 @@
+package config
+
+import (
+	"fmt"
+	"math"
+	"slices"
+	"strconv"
+	"strings"
+)
+
+// NumBookmarks is the number of camera bookmarks, one per number key 1 to 9
+const NumBookmarks = 9
+
+// Bookmark is a saved camera viewpoint
+type Bookmark struct {
+	Slot       int     // Number key, 1 to NumBookmarks
+	X, Y, Z    float64 // Camera position
+	Yaw, Pitch float64 // View direction in radians
+}
+
+// ParseBookmarks parses Bookmarks: "slot=x,y,z,yaw,pitch" entries joined by
+// ";", returned in slot order
+func ParseBookmarks(s string) ([]Bookmark, error) {
+	var bookmarks []Bookmark
+	for _, entry := range strings.Split(s, ";") {
+		entry = strings.TrimSpace(entry)
+		if entry == "" {
+			continue
+		}
+		slot, view, ok := strings.Cut(entry, "=")
+		n, err := strconv.Atoi(strings.TrimSpace(slot))
+		if !ok || err != nil || n < 1 || n > NumBookmarks {
+			return nil, fmt.Errorf("invalid bookmark: %q (want slot=x,y,z,yaw,pitch with a slot of 1 to %d)", entry, NumBookmarks)
+		}
+		fields := strings.Split(view, ",")
+		if len(fields) != 5 {
+			return nil, fmt.Errorf("invalid bookmark: %q (want slot=x,y,z,yaw,pitch)", entry)
+		}
+		var values [5]float64
+		for i, field := range fields {
+			v, err := strconv.ParseFloat(strings.TrimSpace(field), 64)
+			if err != nil || math.IsNaN(v) || math.IsInf(v, 0) {
+				return nil, fmt.Errorf("invalid bookmark: %q (want slot=x,y,z,yaw,pitch)", entry)
+			}
+			values[i] = v
+		}
+		if slices.ContainsFunc(bookmarks, func(b Bookmark) bool { return b.Slot == n }) {
+			return nil, fmt.Errorf("invalid bookmark: %q (slot %d is already set)", entry, n)
+		}
+		bookmarks = append(bookmarks, Bookmark{Slot: n, X: values[0], Y: values[1], Z: values[2], Yaw: values[3], Pitch: values[4]})
+	}
+	slices.SortFunc(bookmarks, func(a, b Bookmark) int { return a.Slot - b.Slot })
+	return bookmarks, nil
+}
+
+// FormatBookmarks formats bookmarks in the form ParseBookmarks reads, to a
+// thousandth of a unit and of a radian
+func FormatBookmarks(bookmarks []Bookmark) string {
+	entries := make([]string, len(bookmarks))
+	for i, b := range bookmarks {
+		values := []string{}
+		for _, v := range []float64{b.X, b.Y, b.Z, b.Yaw, b.Pitch} {
+			values = append(values, strconv.FormatFloat(math.Round(v*1000)/1000, 'f', -1, 64))
+		}
+		entries[i] = fmt.Sprintf("%d=%s", b.Slot, strings.Join(values, ","))
+	}
+	return strings.Join(entries, ";")
+}
+
+// Bookmark returns the camera bookmark in slot, or false if it is not set
+func (c *Config) Bookmark(slot int) (Bookmark, bool) {
+	bookmarks, _ := ParseBookmarks(c.Bookmarks)
+	for _, b := range bookmarks {
+		if b.Slot == slot {
+			return b, true
+		}
+	}
+	return Bookmark{}, false
+}
+
+// SetBookmark sets the camera bookmark in its slot, replacing any there
+func (c *Config) SetBookmark(bookmark Bookmark) error {
+	if bookmark.Slot < 1 || bookmark.Slot > NumBookmarks {
+		return fmt.Errorf("invalid bookmark slot: %d (want 1 to %d)", bookmark.Slot, NumBookmarks)
+	}
+	bookmarks, err := ParseBookmarks(c.Bookmarks)
+	if err != nil {
+		return err
+	}
+	bookmarks = slices.DeleteFunc(bookmarks, func(b Bookmark) bool { return b.Slot == bookmark.Slot })
+	bookmarks = append(bookmarks, bookmark)
+	slices.SortFunc(bookmarks, func(a, b Bookmark) int { return a.Slot - b.Slot })
+	c.Bookmarks = FormatBookmarks(bookmarks)
+	return nil
+}
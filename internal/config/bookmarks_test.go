@@ -0,0 +1,63 @@
+package config
+
+import (
+	"slices"
+	"testing"
+)
+
+// TestParseBookmarks tests parsing camera bookmarks into slot order
+func TestParseBookmarks(t *testing.T) {
+	bookmarks, err := ParseBookmarks(" 3=1,2,3,0.5,-0.25; 1=-10,20.5,0,3.142,0 ;")
+	if err != nil {
+		t.Fatalf("ParseBookmarks failed: %v", err)
+	}
+	want := []Bookmark{
+		{Slot: 1, X: -10, Y: 20.5, Z: 0, Yaw: 3.142, Pitch: 0},
+		{Slot: 3, X: 1, Y: 2, Z: 3, Yaw: 0.5, Pitch: -0.25},
+	}
+	if !slices.Equal(bookmarks, want) {
+		t.Errorf("Expected %+v, got %+v", want, bookmarks)
+	}
+	if s := FormatBookmarks(bookmarks); s != "1=-10,20.5,0,3.142,0;3=1,2,3,0.5,-0.25" {
+		t.Errorf("Unexpected formatting %q", s)
+	}
+
+	for _, s := range []string{"0=1,2,3,4,5", "10=1,2,3,4,5", "a=1,2,3,4,5", "1=1,2,3,4", "1=1,2,3,4,NaN", "1=1,2,3,4,5;1=0,0,0,0,0"} {
+		if _, err := ParseBookmarks(s); err == nil {
+			t.Errorf("Expected an error for %q", s)
+		}
+	}
+}
+
+// TestSetBookmark tests saving and recalling bookmarks in the configuration
+func TestSetBookmark(t *testing.T) {
+	cfg := DefaultConfig()
+	if _, ok := cfg.Bookmark(1); ok {
+		t.Error("Expected no bookmarks by default")
+	}
+	if err := cfg.SetBookmark(Bookmark{Slot: 2, X: 1.23456, Y: 50, Z: -7, Yaw: 3.92699, Pitch: -0.628}); err != nil {
+		t.Fatalf("SetBookmark failed: %v", err)
+	}
+	if err := cfg.SetBookmark(Bookmark{Slot: 1, Y: 10}); err != nil {
+		t.Fatalf("SetBookmark failed: %v", err)
+	}
+	if err := cfg.SetBookmark(Bookmark{Slot: 2, X: 5}); err != nil {
+		t.Fatalf("SetBookmark failed to replace a bookmark: %v", err)
+	}
+	if cfg.Bookmarks != "1=0,10,0,0,0;2=5,0,0,0,0" {
+		t.Errorf("Unexpected bookmarks %q", cfg.Bookmarks)
+	}
+	if b, ok := cfg.Bookmark(2); !ok || b.X != 5 {
+		t.Errorf("Expected bookmark 2 at x 5, got %+v (%v)", b, ok)
+	}
+	if err := cfg.SetBookmark(Bookmark{Slot: NumBookmarks + 1}); err == nil {
+		t.Error("Expected an error for a slot past the number keys")
+	}
+	if err := cfg.Validate(); err != nil {
+		t.Errorf("Expected saved bookmarks to validate, got %v", err)
+	}
+	cfg.Bookmarks = "1=0,0"
+	if err := cfg.Validate(); err == nil {
+		t.Error("Expected invalid bookmarks to fail validation")
+	}
+}
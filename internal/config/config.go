@@ -1,11 +1,161 @@
 package config
 
 import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
 	"fmt"
+	"math"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// Numeric precisions for the CPU physics pipeline
+const (
+	PrecisionFloat64 = "float64"
+	PrecisionFloat32 = "float32" // Matches GPU precision, halves grid memory bandwidth
+)
+
+// Force solvers
+const (
+	SolverPM        = "pm"         // Particle-mesh FFT solver, O(N + grid log grid) per step
+	SolverDirect    = "direct"     // Direct O(N²) summation, more accurate for small N
+	SolverDirectGPU = "direct-gpu" // Direct summation in a compute shader, falls back to the CPU
+)
+
+// GPU backends for the particle-mesh pipeline
+const (
+	GPUBackendGL   = "gl"   // OpenGL 4.3 compute shaders
+	GPUBackendCUDA = "cuda" // cuFFT and CUDA kernels, needs a binary built with -tags cuda
+)
+
+// Compute modes of the particle-mesh step
+const (
+	ComputeAuto = "auto" // Measure both processors and use the faster one
+	ComputeCPU  = "cpu"  // Always the CPU
+	ComputeGPU  = "gpu"  // The GPU, falling back to the CPU on errors
+)
+
+// MaxBlockLevels is the deepest block time step level, stepping by dt/2^MaxBlockLevels
+const MaxBlockLevels = 10
+
+// MaxGridCells is the largest number of grid cells, width times depth,
+// accepted for the simulation grid. Every grid, FFT scratch and GPU buffer
+// holds this many values, and GPU kernels index them with 32-bit integers
+const MaxGridCells = 1 << 26
+
+// Largest particle counts accepted by the direct solvers
+const (
+	MaxDirectParticles    = 5000
+	MaxDirectGPUParticles = 65536
+)
+
+// MaxTracerSheet is the largest number of tracers per side of the tracer sheet
+const MaxTracerSheet = 512
+
+// Initial condition import formats
+const (
+	ImportFormatAuto   = "auto"   // Chosen from the file extension
+	ImportFormatCSV    = "csv"    // CSV with a header row naming the columns
+	ImportFormatGadget = "gadget" // Gadget-2 style ASCII columns
+	ImportFormatTipsy  = "tipsy"  // TIPSY ASCII arrays
+)
+
+// Lowest severities of the OpenGL debug messages that are logged
+const (
+	GLDebugOff    = "off"
+	GLDebugHigh   = "high"   // Errors and undefined behavior
+	GLDebugMedium = "medium" // Also major performance warnings and deprecated use
+	GLDebugLow    = "low"    // Also minor performance warnings
+	GLDebugAll    = "all"    // Also informational notifications
+)
+
+// Particle radius models
+const (
+	RadiusModelDensity = "density" // Spheres of uniform density ParticleDensity
+	RadiusModelFixed   = "fixed"   // Every particle has radius ParticleRadius
+	RadiusModelOff     = "off"     // Point particles
+)
+
+// Built-in scenarios for the initial conditions
+const (
+	ScenarioRandom          = "random"           // Random particles, or the imported ones
+	ScenarioThreeBody       = "three-body"       // Tracers around a binary, with its Lagrange points marked
+	ScenarioTidalDisruption = "tidal-disruption" // A cluster torn apart on a plunging orbit past a host mass
+)
+
+// Color palettes
+const (
+	PaletteDefault      = "default"
+	PaletteDeuteranopia = "deuteranopia"  // Okabe-Ito colors, safe with red-green color blindness
+	PaletteHighContrast = "high-contrast" // Saturated colors with a dark backdrop behind the HUD text
+)
+
+// Particle coloring modes
+const (
+	ParticleColoringUniform  = "uniform"  // The palette's particle color
+	ParticleColoringBinding  = "binding"  // Bound or unbound to the potential
+	ParticleColoringDensity  = "density"  // Local density along the palette's mass ramp
+	ParticleColoringLyapunov = "lyapunov" // Lyapunov exponent of the particles tracked by LyapunovParticles
+	ParticleColoringGroup    = "group"    // Each particle group in its own color
+)
+
+// ParticleColorings lists the particle coloring modes in the order the B key cycles through them
+var ParticleColorings = []string{ParticleColoringUniform, ParticleColoringBinding, ParticleColoringDensity, ParticleColoringLyapunov, ParticleColoringGroup}
+
+// Grid coloring modes
+const (
+	GridColoringPotential  = "potential"  // Depth of the potential well
+	GridColoringDivergence = "divergence" // Expansion or contraction of the particle flow
+	GridColoringVorticity  = "vorticity"  // Rotation of the particle flow
+	GridColoringShear      = "shear"      // Shear of the particle flow
+)
+
+// GridColorings lists the grid coloring modes in the order the V key cycles through them
+var GridColorings = []string{GridColoringPotential, GridColoringDivergence, GridColoringVorticity, GridColoringShear}
+
+// Radial profile centers
+const (
+	ProfileCenterDensest = "densest" // Densest point, found by shrinking circles
+	ProfileCenterMass    = "mass"    // Center of mass
+)
+
+// Slow motion triggers
+const (
+	SlowMotionApproach = "approach" // Two particles closer than SlowMotionDistance
+	SlowMotionContact  = "contact"  // The physical radii of two particles touch
+)
+
+// UI scale limits
+const (
+	MinUIScale = 0.5
+	MaxUIScale = 4.0
+)
+
+// Render budget settings: the smallest budget, and the default
+const (
+	MinRenderBudget     = 1000
+	DefaultRenderBudget = 100000
+)
+
+// Stereo layouts of offline renders
+const (
+	RenderStereoSideBySide = "side-by-side" // Left and right eyes next to each other
+	RenderStereoAnaglyph   = "anaglyph"     // Red-cyan anaglyph for colored glasses
+)
+
+// Offline render limits
+const (
+	MaxSupersample = 8     // Samples per pixel along each axis
+	MaxRenderSize  = 16384 // Pixels along each side of the supersampled image, a common GPU texture limit
 )
 
 // Config holds all configuration parameters for the simulation
 type Config struct {
+	Preset     string // Name of the preset layered over the defaults ("" = none)
+	ConfigPath string // JSON file of settings layered over the preset and reloaded while running ("" = none)
+
 	// Display settings
 	ScreenWidth  int
 	ScreenHeight int
@@ -13,23 +163,214 @@ type Config struct {
 	// Simulation dimensions
 	SimulationWidth int
 	SimulationDepth int
+	CellSize        float64 // Physical size of a grid cell, so the domain spans width·CellSize × depth·CellSize (0 = 1)
 
 	// Physics parameters
 	NumParticles          int
 	GravitationalConstant float64
+	Seed                  int64   // Random seed for particle initialization (0 = random)
+	Precision             string  // CPU grid/FFT precision: PrecisionFloat64 or PrecisionFloat32 ("" = float64)
+	Solver                string  // Force solver: SolverPM, SolverDirect or SolverDirectGPU ("" = pm)
+	Softening             float64 // Softening length in cells for the direct solver
+	EncounterRadius       float64 // Pairs closer than this (cells) are sub-stepped by the direct solver (0 = disabled)
+	IncrementalDeposit    bool    // PM deposits move only the mass of particles that changed instead of re-depositing all
+	SparseGrid            bool    // PM steps deposit into, and take gradients over, only the grid tiles holding mass
+	CropSolve             bool    // With SparseGrid, solve the PM potential in a padded window around the particles
+	CropPadding           int     // Empty cells kept around the particles by CropSolve (0 = the extent of the particles)
+	BlockLevels           int     // Block time step levels of the direct solver, level k stepping by dt/2^k (0 = one shared step)
+	Deterministic         bool    // Bit-identical runs for a Seed at any GOMAXPROCS: CPU only, fixed-point deposition
+	FrameOmega            float64 // Angular velocity of the rotating reference frame about +Y (0 = inertial)
+	SpongeWidth           float64 // Depth in cells of the velocity-damping layer along the domain edges (0 = none)
+	SpongeStrength        float64 // Damping rate per unit time at the domain edge (0 = physics.DefaultSpongeStrength)
+	ImageCorrection       bool    // Cancel the leading-order pull of the periodic images on PM forces, for isolated systems
+	LyapunovParticles     int     // Particles followed by a perturbed shadow to estimate their Lyapunov exponents (0 = none)
+	RadiusModel           string  // One of the RadiusModel* values, for the physical radius ("" = density)
+	ParticleDensity       float64 // Density of the density radius model (0 = default)
+	ParticleRadius        float64 // Radius of the fixed radius model
+
+	// Initial conditions
+	Scenario      string // One of the Scenario* values ("" = random)
+	ImportPath    string // Particle file replacing random initialization ("" = none)
+	ImportFormat  string // One of the ImportFormat* values ("" = auto)
+	ImportPlaneXY bool   // Map the file's x-y plane onto the simulation's x-z plane
+	TracerSheet   int    // Tracers per side of a Lagrangian sheet laid over the domain at rest, drawn as a mesh (0 = none)
 
 	// Rendering parameters
 	GridVisScale     float64
 	MoveSpeed        float32
 	MouseSensitivity float32
+	TouchControls    bool    // Drag to look, pinch to zoom and tap to spawn particles
+	Palette          string  // One of the Palette* values ("" = default)
+	UIScale          float64 // HUD font and layout scale (0 = 1 = 20 px text)
+	ParticleColoring string  // One of the ParticleColoring* values ("" = uniform)
+	GridColoring     string  // One of the GridColoring* values ("" = potential)
+	FlowInterval     int     // Steps between updates of the velocity flow maps
+	Stereo           bool    // Render side-by-side stereo: left eye on the left half of the window
+	EyeSeparation    float64 // Distance between the stereo eyes in simulation units (0 = default)
+	DisplayScale     float64 // Drawn particle radius per unit of physical radius (0 = 1)
+	MinDisplayRadius float64 // Smallest drawn particle radius, so point and light particles stay visible
+	Culling          bool    // Skip particles whose drawn spheres lie outside the view
+	CullPixels       float64 // Skip particles drawn with a radius under this many pixels (0 = drawn at any size)
+	RenderBudget     int     // Particles drawn at most, sub-sampled by screen density; physics uses them all (0 = all drawn)
+
+	// Scene layers
+	Layers         string  // Layers shown, comma-separated in drawing order; the others follow hidden ("" = DefaultLayers)
+	TrailLength    int     // Positions kept in each particle trail (0 = DefaultTrailLength)
+	TrailParticles int     // Particles with trails, from the first (0 = DefaultTrailParticles)
+	VectorStride   int     // Cells between arrows of the acceleration field (0 = DefaultVectorStride)
+	ContourLevels  int     // Contour lines of the potential (0 = DefaultContourLevels)
+	HaloThreshold  float64 // Cell mass, relative to the mean, of the density peaks ringed as halos (0 = DefaultHaloThreshold)
+	MinimapSize    int     // Side of the minimap in pixels at UI scale 1 (0 = DefaultMinimapSize)
+
+	// Frame rate
+	TargetFPS        int     // Frame rate cap of the window (0 = uncapped)
+	VSync            bool    // Wait for the display's vertical sync before presenting a frame
+	DeltaSmoothing   float64 // Weight of the previous average in the physics time step's moving average, 0 to below 1 (0 = none)
+	DeltaSpikeFactor float64 // Frame times above this multiple of the average are replaced by it (0 = no rejection)
+	IdleFPS          int     // Frame rate cap while paused or in the background, with the physics stopped (0 = no idle mode)
 
 	// Camera initial settings
 	InitialYaw   float32
 	InitialPitch float32
+	Bookmarks    string // Camera bookmarks, "slot=x,y,z,yaw,pitch" entries joined by ";", saved with Ctrl+1-9 ("" = none)
 
 	// Runtime flags
-	StartPaused bool
-	UseGPU      bool
+	StartPaused     bool
+	UseGPU          bool
+	ComputeMode     string // ComputeAuto, ComputeCPU or ComputeGPU ("" = gpu if UseGPU, else cpu)
+	GPUBackend      string // GPUBackendGL or GPUBackendCUDA ("" = gl)
+	GPUDevice       int    // GPU to create the OpenGL context on, as numbered by -list-gpus (0 = driver default)
+	ListGPUs        bool   // Print the available GPUs and exit
+	ListPresets     bool   // Print the presets and exit
+	ShowPlots       bool   // Show the live diagnostics plot panel
+	ShowPhaseSpace  bool   // Show the live x–vx and z–vz phase-space panel
+	ShowProfiles    bool   // Show the live radial profile panel
+	ShowRenderStats bool   // Show draw calls, vertices and time per scene layer, and whether physics or rendering limits the frame rate
+	Sonify          bool   // Play the potential well and accretion as sound
+
+	// Educational annotations
+	Explain              bool    // Walk through each particle-mesh step stage by stage, with annotations and the intermediate fields, one step per pass
+	ExplainStageDuration float64 // Seconds each stage is shown (0 = lesson.DefaultStageDuration)
+
+	// Adaptive quality
+	AdaptiveQuality bool    // Lower grid detail, frame rate, then GPU use while frames run over budget
+	PowerSaver      bool    // Hold reduced quality to save power (also on when running from battery with AdaptiveQuality)
+	PhysicsBudget   float64 // Milliseconds of physics per frame before diagnostics, view grid and deposit are reduced (0 = no budget)
+	PreviewPhysics  bool    // Step the CPU physics on a half-resolution grid while the camera moves or settings are reloaded
+
+	// GPU program cache
+	ShaderCacheDir string // Directory for linked compute programs reused across runs ("" = compile every run)
+
+	// Hardware calibration
+	CalibrationPath string // File of the grid size and processor measured for this machine, used as defaults and written on first launch ("" = off)
+	Recalibrate     bool   // Measure again and replace the calibration file
+
+	// GPU driver messages
+	GLDebug string // Lowest severity of OpenGL debug messages logged to stderr: one of the GLDebug* values ("" = medium)
+
+	// Scheduling
+	MaxProcs     int  // GOMAXPROCS, the threads running Go code at once (0 = one per CPU)
+	PinCores     bool // Pin the process, and with it the physics workers, to the performance cores (Linux)
+	HeadlessNice int  // Niceness added to headless runs, so exports yield to interactive work (0 = unchanged, Linux)
+	HugePages    bool // Advise transparent huge pages for grids of physics.LargeGridCells cells and more (Linux)
+
+	// Crash reporting
+	CrashReportDir string // Directory for crash reports written on panic
+	CrashDumpState bool   // Include a full state snapshot in crash reports
+
+	// Run output
+	RunDir string // Directory for per-run output directories, named by start time and linked as "latest", holding the relative output paths ("" = outputs where configured)
+
+	// Sanity checks
+	GuardInterval     int     // Steps between NaN/Inf and energy explosion checks; a failed check pauses and writes a report (0 = off)
+	GuardEnergyGrowth float64 // Kinetic energy, relative to the earlier peak, that counts as an explosion (0 = guard.DefaultEnergyGrowth)
+	HealthInterval    int     // Steps between health checks that score drift, occupancy and CFL with advice (0 = off)
+	ReversalSteps     int     // Steps the time-reversal test runs forward and then back (0 = verification.DefaultReversalSteps)
+	TimeReversal      bool    // Run the time-reversal test in headless mode, print the result and exit
+
+	// Soak runs
+	Soak            bool    // Sample memory and energy drift through a long headless run and stop it when one trends upward
+	SoakInterval    float64 // Wall-clock seconds between soak samples (0 = soak.DefaultInterval)
+	SoakPath        string  // CSV file for the soak samples ("" = none)
+	SoakRSSGrowth   float64 // Resident memory growth in MB per hour that stops a soak run (0 = unwatched)
+	SoakGPUGrowth   float64 // GPU memory growth in MB per hour that stops a soak run (0 = unwatched)
+	SoakAllocGrowth float64 // Allocation rate growth in MB/s per hour that stops a soak run (0 = unwatched)
+	SoakDriftGrowth float64 // Relative energy drift per hour that stops a soak run (0 = unwatched)
+
+	// Radial profiles
+	ProfileBins   int    // Radial bins of the profiles (0 = physics.DefaultProfileBins)
+	ProfileCenter string // ProfileCenterDensest or ProfileCenterMass ("" = densest)
+
+	// Slow motion
+	SlowMotionTrigger  string  // SlowMotionApproach or SlowMotionContact at the watch starts slow motion ("" = off)
+	SlowMotionDistance float64 // Separation in cells that counts as a close approach
+	SlowMotionScale    float64 // Time scale during slow motion, above 0 and up to 1
+	SlowMotionDuration float64 // Wall-clock seconds of slow motion per trigger
+	SlowMotionParticle int     // Watched particle (-1 = none)
+	SlowMotionRegionX  float64 // Center of the watched region
+	SlowMotionRegionZ  float64
+	SlowMotionRadius   float64 // Radius in cells of the watched region (0 = none)
+
+	// Binaries
+	BinarySeparation float64 // Pairs whose orbit stays within this many cells are detected as binaries (0 = off)
+	BinaryInterval   int     // Steps between binary detections (0 = physics.DefaultBinaryInterval)
+	BinaryLogPath    string  // CSV file for binary formation and disruption events ("" = none)
+
+	// Particle groups
+	GroupRegions string // Circles tagging the particles inside at start into named groups, "name=x,z,radius" joined by ";" ("" = none)
+
+	// Mesh export
+	MeshPath      string // OBJ or glTF file for the deformed grid, written at the end of a headless run and numbered by step on F9 ("" = spacetime.gltf on F9 only)
+	MeshParticles bool   // Include the particles as spheres in exported meshes
+
+	// Frame overlay
+	Overlay      string // Items stamped on each drawn and rendered frame: OverlayTime, OverlayStep and OverlayParams joined by "," ("" = none)
+	OverlayLabel string // Custom label stamped under the items, such as a credit or watermark ("" = none)
+
+	// Replay recording and offline rendering
+	RecordDir      string // Directory for replay frames recorded every RecordInterval steps of a headless run ("" = none)
+	RecordInterval int    // Steps between replay frames
+	RenderDir      string // Replay directory to render to images instead of running the simulation ("" = none)
+	RenderOut      string // Directory for the rendered PNG images ("" = "render" inside RenderDir)
+	RenderWidth    int    // Width of the rendered images in pixels
+	RenderHeight   int    // Height of the rendered images in pixels
+	Supersample    int    // Samples per pixel along each axis, averaged for antialiasing
+	RenderStereo   string // RenderStereoSideBySide or RenderStereoAnaglyph ("" = mono)
+
+	// Headless run settings
+	Headless            bool    // Run without a window
+	MaxSteps            int     // Steps to run in headless mode (0 = until interrupted)
+	Timeout             float64 // Wall-clock limit in seconds for headless mode (0 = none)
+	FixedTimeStep       float32 // Time step used in headless mode
+	CheckpointPath      string  // Final checkpoint written on shutdown ("" = none)
+	DiagnosticsPath     string  // CSV file for diagnostics ("" = none)
+	DiagnosticsInterval int     // Steps between diagnostics records
+	ProgressInterval    float64 // Seconds between progress reports (0 = disabled)
+	ProgressPath        string  // JSON progress file for external schedulers ("" = none)
+	NBodyPath           string  // Final particle snapshot in an external N-body format ("" = none)
+	NBodyFormat         string  // Format of NBodyPath: "gadget" or "raw"
+	FlowDir             string  // Directory for velocity flow maps written every FlowInterval steps ("" = none)
+	ProfilePath         string  // CSV file for the radial profile of the final state ("" = none)
+}
+
+// DefaultShaderCacheDir returns the directory for cached GPU program binaries
+// under the user's cache directory, or "" if the system has none
+func DefaultShaderCacheDir() string {
+	dir, err := os.UserCacheDir()
+	if err != nil {
+		return ""
+	}
+	return filepath.Join(dir, "relativity_simulation_2d", "shaders")
+}
+
+// DefaultCalibrationPath returns the hardware calibration file under the
+// user's config directory, or "" if the system has none
+func DefaultCalibrationPath() string {
+	dir, err := os.UserConfigDir()
+	if err != nil {
+		return ""
+	}
+	return filepath.Join(dir, "relativity_simulation_2d", "calibration.json")
 }
 
 // DefaultConfig returns the default configuration
@@ -42,15 +383,58 @@ func DefaultConfig() *Config {
 		// Simulation dimensions
 		SimulationWidth: 256,
 		SimulationDepth: 256,
+		CellSize:        1,
 
 		// Physics parameters
 		NumParticles:          10,
 		GravitationalConstant: 1.0,
+		Seed:                  0,
+		Precision:             PrecisionFloat64,
+		Solver:                SolverPM,
+		Softening:             0.25,
+		EncounterRadius:       2.0,
+		RadiusModel:           RadiusModelDensity,
+		ParticleDensity:       120 / math.Pi, // A mass of 20 has radius 0.5
+		ParticleRadius:        0.5,
+
+		// Initial conditions
+		Scenario:      ScenarioRandom,
+		ImportPath:    "",
+		ImportFormat:  ImportFormatAuto,
+		ImportPlaneXY: false,
 
 		// Rendering parameters
 		GridVisScale:     0.1,
 		MoveSpeed:        0.3,
 		MouseSensitivity: 0.003,
+		Palette:          PaletteDefault,
+		UIScale:          1.0,
+		ParticleColoring: ParticleColoringUniform,
+		GridColoring:     GridColoringPotential,
+		FlowInterval:     10,
+		Stereo:           false,
+		EyeSeparation:    2.0,
+		DisplayScale:     1.0,
+		MinDisplayRadius: 0.1,
+		Culling:          true,
+		CullPixels:       0,
+		RenderBudget:     DefaultRenderBudget,
+
+		// Scene layers
+		Layers:         DefaultLayers,
+		TrailLength:    DefaultTrailLength,
+		TrailParticles: DefaultTrailParticles,
+		VectorStride:   DefaultVectorStride,
+		ContourLevels:  DefaultContourLevels,
+		HaloThreshold:  DefaultHaloThreshold,
+		MinimapSize:    DefaultMinimapSize,
+
+		// Frame rate
+		TargetFPS:        60,
+		VSync:            false,
+		DeltaSmoothing:   0.8,
+		DeltaSpikeFactor: 3.0,
+		IdleFPS:          10,
 
 		// Camera initial settings
 		InitialYaw:   3.92699, // Start facing -Z direction
@@ -59,11 +443,84 @@ func DefaultConfig() *Config {
 		// Runtime flags
 		StartPaused: false,
 		UseGPU:      true,
+		GPUBackend:  GPUBackendGL,
+		GPUDevice:   0,
+		ListGPUs:    false,
+		ShowPlots:   false,
+
+		// Adaptive quality
+		AdaptiveQuality: false,
+		PowerSaver:      false,
+
+		// GPU program cache
+		ShaderCacheDir: DefaultShaderCacheDir(),
+
+		// Educational annotations
+		ExplainStageDuration: 4,
+
+		// Hardware calibration
+		CalibrationPath: DefaultCalibrationPath(),
+
+		// GPU driver messages
+		GLDebug: GLDebugMedium,
+
+		// Crash reporting
+		CrashReportDir: "crash_reports",
+		CrashDumpState: true,
+
+		// Sanity checks
+		GuardInterval:     10,
+		GuardEnergyGrowth: 100,
+		HealthInterval:    100,
+		ReversalSteps:     100,
+
+		// Soak runs
+		SoakInterval:    60,
+		SoakPath:        "soak.csv",
+		SoakRSSGrowth:   64,
+		SoakGPUGrowth:   64,
+		SoakAllocGrowth: 1,
+		SoakDriftGrowth: 0.01,
+
+		// Radial profiles
+		ProfileBins:   32,
+		ProfileCenter: ProfileCenterDensest,
+
+		// Slow motion
+		SlowMotionDistance: 1,
+		SlowMotionScale:    0.1,
+		SlowMotionDuration: 3,
+		SlowMotionParticle: -1,
+
+		// Binaries
+		BinaryInterval: 10,
+
+		// Replay recording and offline rendering
+		RecordInterval: 10,
+		RenderWidth:    3840,
+		RenderHeight:   2160,
+		Supersample:    2,
+
+		// Headless run settings
+		Headless:            false,
+		MaxSteps:            0,
+		Timeout:             0,
+		FixedTimeStep:       1.0 / 60.0,
+		CheckpointPath:      "",
+		DiagnosticsPath:     "",
+		DiagnosticsInterval: 100,
+		ProgressInterval:    5.0,
+		ProgressPath:        "",
+		NBodyPath:           "",
+		NBodyFormat:         "gadget",
 	}
 }
 
 // Validate checks if the configuration is valid
 func (c *Config) Validate() error {
+	if _, ok := LookupPreset(c.Preset); c.Preset != "" && !ok {
+		return fmt.Errorf("invalid preset: %q (want %s)", c.Preset, strings.Join(PresetNames(), ", "))
+	}
 	if c.ScreenWidth <= 0 {
 		return fmt.Errorf("invalid screen width: %d", c.ScreenWidth)
 	}
@@ -76,9 +533,387 @@ func (c *Config) Validate() error {
 	if c.SimulationDepth <= 0 {
 		return fmt.Errorf("invalid simulation depth: %d", c.SimulationDepth)
 	}
+	// Divide rather than multiply, so huge sizes cannot overflow
+	if c.SimulationWidth > MaxGridCells/c.SimulationDepth {
+		return fmt.Errorf("invalid simulation size: %dx%d (want at most %d cells)", c.SimulationWidth, c.SimulationDepth, MaxGridCells)
+	}
+	if !(c.CellSize >= 0) || math.IsInf(c.CellSize, 0) {
+		return fmt.Errorf("invalid cell size: %g", c.CellSize)
+	}
 	if c.NumParticles < 0 {
 		return fmt.Errorf("invalid number of particles: %d", c.NumParticles)
 	}
+	if c.Precision != "" && c.Precision != PrecisionFloat64 && c.Precision != PrecisionFloat32 {
+		return fmt.Errorf("invalid precision: %q (want %s or %s)", c.Precision, PrecisionFloat64, PrecisionFloat32)
+	}
+	switch c.Solver {
+	case "", SolverPM:
+	case SolverDirect, SolverDirectGPU:
+		limit := MaxDirectParticles
+		if c.Solver == SolverDirectGPU {
+			limit = MaxDirectGPUParticles
+		}
+		if c.NumParticles > limit {
+			return fmt.Errorf("%s solver supports at most %d particles, got %d: use the %s solver for more", c.Solver, limit, c.NumParticles, SolverPM)
+		}
+		if c.Softening < 0 {
+			return fmt.Errorf("invalid softening: %f", c.Softening)
+		}
+		if c.EncounterRadius < 0 {
+			return fmt.Errorf("invalid encounter radius: %f", c.EncounterRadius)
+		}
+	default:
+		return fmt.Errorf("invalid solver: %q (want %s, %s or %s)", c.Solver, SolverPM, SolverDirect, SolverDirectGPU)
+	}
+	if c.BlockLevels < 0 || c.BlockLevels > MaxBlockLevels {
+		return fmt.Errorf("invalid block time step levels: %d (want 0 to %d)", c.BlockLevels, MaxBlockLevels)
+	}
+	if c.BlockLevels > 0 && c.Solver != SolverDirect {
+		return fmt.Errorf("invalid block time step levels: %d (only the %s solver supports them)", c.BlockLevels, SolverDirect)
+	}
+	if c.SparseGrid && c.Solver != "" && c.Solver != SolverPM {
+		return fmt.Errorf("invalid sparse grid: the %s solver has no density grid", c.Solver)
+	}
+	if c.SparseGrid && c.IncrementalDeposit {
+		return fmt.Errorf("invalid sparse grid: cannot be combined with incremental deposits")
+	}
+	if c.CropSolve && !c.SparseGrid {
+		return fmt.Errorf("invalid cropped solve: needs the sparse grid (add -sparse-grid)")
+	}
+	if c.CropPadding < 0 {
+		return fmt.Errorf("invalid crop padding: %d", c.CropPadding)
+	}
+	if math.IsNaN(c.FrameOmega) || math.IsInf(c.FrameOmega, 0) {
+		return fmt.Errorf("invalid frame angular velocity: %f", c.FrameOmega)
+	}
+	if c.SpongeWidth < 0 || 2*c.SpongeWidth > float64(min(c.SimulationWidth, c.SimulationDepth)) {
+		return fmt.Errorf("invalid sponge width: %g cells (want 0 to half the domain)", c.SpongeWidth)
+	}
+	if c.SpongeStrength < 0 {
+		return fmt.Errorf("invalid sponge strength: %g", c.SpongeStrength)
+	}
+	if c.ImageCorrection && c.Solver != "" && c.Solver != SolverPM {
+		return fmt.Errorf("invalid image correction: the %s solver has no periodic images", c.Solver)
+	}
+	if c.ImageCorrection && c.CropSolve {
+		return fmt.Errorf("invalid image correction: a cropped solve is periodic over its window, not the domain")
+	}
+	if c.LyapunovParticles < 0 {
+		return fmt.Errorf("invalid Lyapunov particles: %d", c.LyapunovParticles)
+	}
+	switch c.GLDebug {
+	case "", GLDebugOff, GLDebugHigh, GLDebugMedium, GLDebugLow, GLDebugAll:
+	default:
+		return fmt.Errorf("invalid GL debug level: %q (want %s, %s, %s, %s or %s)", c.GLDebug, GLDebugOff, GLDebugHigh, GLDebugMedium, GLDebugLow, GLDebugAll)
+	}
+	switch c.RadiusModel {
+	case "", RadiusModelDensity:
+		if !(c.ParticleDensity >= 0) || math.IsInf(c.ParticleDensity, 0) {
+			return fmt.Errorf("invalid particle density: %f", c.ParticleDensity)
+		}
+	case RadiusModelFixed:
+		if !(c.ParticleRadius >= 0) || math.IsInf(c.ParticleRadius, 0) {
+			return fmt.Errorf("invalid particle radius: %f", c.ParticleRadius)
+		}
+	case RadiusModelOff:
+	default:
+		return fmt.Errorf("invalid radius model: %q (want %s, %s or %s)", c.RadiusModel, RadiusModelDensity, RadiusModelFixed, RadiusModelOff)
+	}
+	if c.TracerSheet != 0 && (c.TracerSheet < 2 || c.TracerSheet > MaxTracerSheet) {
+		return fmt.Errorf("invalid tracer sheet: %d tracers per side (want 0 or 2 to %d)", c.TracerSheet, MaxTracerSheet)
+	}
+	switch c.Scenario {
+	case "", ScenarioRandom:
+	case ScenarioThreeBody, ScenarioTidalDisruption:
+		if c.ImportPath != "" {
+			return fmt.Errorf("%s scenario cannot use imported initial conditions", c.Scenario)
+		}
+		if c.Scenario == ScenarioThreeBody && c.FrameOmega != 0 {
+			return fmt.Errorf("%s scenario sets its own rotating frame, got frame angular velocity %f", c.Scenario, c.FrameOmega)
+		}
+	default:
+		return fmt.Errorf("invalid scenario: %q (want %s, %s or %s)", c.Scenario, ScenarioRandom, ScenarioThreeBody, ScenarioTidalDisruption)
+	}
+	switch c.ComputeMode {
+	case "", ComputeAuto, ComputeCPU, ComputeGPU:
+	default:
+		return fmt.Errorf("invalid compute mode: %q (want %s, %s or %s)", c.ComputeMode, ComputeAuto, ComputeCPU, ComputeGPU)
+	}
+	if c.PhysicsBudget < 0 {
+		return fmt.Errorf("invalid physics budget: %g ms", c.PhysicsBudget)
+	}
+	if c.Deterministic {
+		switch {
+		case c.Seed == 0:
+			return fmt.Errorf("invalid deterministic run: needs a fixed seed (set -seed to a nonzero value)")
+		case c.ComputeMode != "" && c.ComputeMode != ComputeCPU:
+			return fmt.Errorf("invalid deterministic run: compute mode %s (runs on the CPU only)", c.ComputeMode)
+		case c.Solver == SolverDirectGPU:
+			return fmt.Errorf("invalid deterministic run: %s solver (runs on the CPU only)", c.Solver)
+		case c.SparseGrid:
+			return fmt.Errorf("invalid deterministic run: sparse grid (deposits in floating point)")
+		case c.PhysicsBudget > 0:
+			return fmt.Errorf("invalid deterministic run: physics budget (reductions depend on timing)")
+		case c.PreviewPhysics:
+			return fmt.Errorf("invalid deterministic run: preview physics (steps depend on interaction)")
+		}
+	}
+	switch c.GPUBackend {
+	case "", GPUBackendGL, GPUBackendCUDA:
+	default:
+		return fmt.Errorf("invalid GPU backend: %q (want %s or %s)", c.GPUBackend, GPUBackendGL, GPUBackendCUDA)
+	}
+	switch c.Palette {
+	case "", PaletteDefault, PaletteDeuteranopia, PaletteHighContrast:
+	default:
+		return fmt.Errorf("invalid palette: %q (want %s, %s or %s)", c.Palette, PaletteDefault, PaletteDeuteranopia, PaletteHighContrast)
+	}
+	switch c.ParticleColoring {
+	case "", ParticleColoringUniform, ParticleColoringBinding, ParticleColoringDensity, ParticleColoringLyapunov, ParticleColoringGroup:
+	default:
+		return fmt.Errorf("invalid particle coloring: %q (want %s, %s, %s, %s or %s)", c.ParticleColoring,
+			ParticleColoringUniform, ParticleColoringBinding, ParticleColoringDensity, ParticleColoringLyapunov, ParticleColoringGroup)
+	}
+	switch c.GridColoring {
+	case "", GridColoringPotential, GridColoringDivergence, GridColoringVorticity, GridColoringShear:
+	default:
+		return fmt.Errorf("invalid grid coloring: %q (want %s, %s, %s or %s)", c.GridColoring,
+			GridColoringPotential, GridColoringDivergence, GridColoringVorticity, GridColoringShear)
+	}
+	if c.FlowInterval < 0 {
+		return fmt.Errorf("invalid flow interval: %d", c.FlowInterval)
+	}
+	if c.UIScale != 0 && (c.UIScale < MinUIScale || c.UIScale > MaxUIScale) {
+		return fmt.Errorf("invalid UI scale: %g (want %g to %g)", c.UIScale, MinUIScale, MaxUIScale)
+	}
+	if !(c.DisplayScale >= 0) || math.IsInf(c.DisplayScale, 0) {
+		return fmt.Errorf("invalid display radius scale: %f", c.DisplayScale)
+	}
+	if !(c.MinDisplayRadius >= 0) || math.IsInf(c.MinDisplayRadius, 0) {
+		return fmt.Errorf("invalid minimum display radius: %f", c.MinDisplayRadius)
+	}
+	if !(c.CullPixels >= 0) || math.IsInf(c.CullPixels, 0) {
+		return fmt.Errorf("invalid cull pixels: %g", c.CullPixels)
+	}
+	if c.RenderBudget != 0 && c.RenderBudget < MinRenderBudget {
+		return fmt.Errorf("invalid render budget: %d (want 0 or at least %d)", c.RenderBudget, MinRenderBudget)
+	}
+	if _, err := ParseLayers(c.Layers); err != nil {
+		return err
+	}
+	if c.TrailLength < 0 || c.TrailLength == 1 {
+		return fmt.Errorf("invalid trail length: %d (want 2 or more)", c.TrailLength)
+	}
+	if c.TrailParticles < 0 {
+		return fmt.Errorf("invalid trail particles: %d", c.TrailParticles)
+	}
+	if c.VectorStride < 0 {
+		return fmt.Errorf("invalid vector stride: %d", c.VectorStride)
+	}
+	if c.ContourLevels < 0 {
+		return fmt.Errorf("invalid contour levels: %d", c.ContourLevels)
+	}
+	if !(c.HaloThreshold >= 0) || math.IsInf(c.HaloThreshold, 0) {
+		return fmt.Errorf("invalid halo threshold: %g", c.HaloThreshold)
+	}
+	if c.MinimapSize < 0 {
+		return fmt.Errorf("invalid minimap size: %d", c.MinimapSize)
+	}
+	if c.EyeSeparation < 0 {
+		return fmt.Errorf("invalid eye separation: %f", c.EyeSeparation)
+	}
+	if c.TargetFPS < 0 {
+		return fmt.Errorf("invalid target FPS: %d", c.TargetFPS)
+	}
+	if c.IdleFPS < 0 {
+		return fmt.Errorf("invalid idle FPS: %d", c.IdleFPS)
+	}
+	if !(c.DeltaSmoothing >= 0 && c.DeltaSmoothing < 1) {
+		return fmt.Errorf("invalid time step smoothing: %f (want 0 to below 1)", c.DeltaSmoothing)
+	}
+	if (c.DeltaSpikeFactor != 0 && !(c.DeltaSpikeFactor > 1)) || math.IsInf(c.DeltaSpikeFactor, 0) {
+		return fmt.Errorf("invalid time step spike factor: %f (want 0 or above 1)", c.DeltaSpikeFactor)
+	}
+	if c.GPUDevice < 0 {
+		return fmt.Errorf("invalid GPU device: %d", c.GPUDevice)
+	}
+	if c.MaxProcs < 0 {
+		return fmt.Errorf("invalid GOMAXPROCS: %d", c.MaxProcs)
+	}
+	if c.HeadlessNice < 0 || c.HeadlessNice > 19 {
+		return fmt.Errorf("invalid niceness: %d (want 0 to 19)", c.HeadlessNice)
+	}
+	if c.GuardInterval < 0 {
+		return fmt.Errorf("invalid guard interval: %d", c.GuardInterval)
+	}
+	if c.GuardEnergyGrowth < 0 || (c.GuardEnergyGrowth > 0 && c.GuardEnergyGrowth <= 1) {
+		return fmt.Errorf("invalid guard energy growth: %g (want more than 1)", c.GuardEnergyGrowth)
+	}
+	if c.HealthInterval < 0 {
+		return fmt.Errorf("invalid health interval: %d", c.HealthInterval)
+	}
+	if c.ProfileBins < 0 {
+		return fmt.Errorf("invalid profile bins: %d", c.ProfileBins)
+	}
+	switch c.ProfileCenter {
+	case "", ProfileCenterDensest, ProfileCenterMass:
+	default:
+		return fmt.Errorf("invalid profile center: %q (want %s or %s)", c.ProfileCenter, ProfileCenterDensest, ProfileCenterMass)
+	}
+	if err := c.validateSlowMotion(); err != nil {
+		return err
+	}
+	if !(c.BinarySeparation >= 0) || math.IsInf(c.BinarySeparation, 0) {
+		return fmt.Errorf("invalid binary separation: %g", c.BinarySeparation)
+	}
+	if c.BinaryInterval < 0 {
+		return fmt.Errorf("invalid binary interval: %d", c.BinaryInterval)
+	}
+	if c.BinaryLogPath != "" && c.BinarySeparation == 0 {
+		return fmt.Errorf("invalid binary log: binary detection is off (set a binary separation)")
+	}
+	if ext := strings.ToLower(filepath.Ext(c.MeshPath)); c.MeshPath != "" && ext != ".obj" && ext != ".gltf" {
+		return fmt.Errorf("invalid mesh file: %q (want a .obj or .gltf file)", c.MeshPath)
+	}
+	if _, err := ParseOverlay(c.Overlay); err != nil {
+		return err
+	}
+	if err := c.validateRender(); err != nil {
+		return err
+	}
+	if _, err := ParseGroupRegions(c.GroupRegions); err != nil {
+		return err
+	}
+	if _, err := ParseBookmarks(c.Bookmarks); err != nil {
+		return err
+	}
+	if c.ReversalSteps < 0 {
+		return fmt.Errorf("invalid time reversal steps: %d", c.ReversalSteps)
+	}
+	if c.TimeReversal && !c.Headless {
+		return fmt.Errorf("the time reversal test runs in headless mode")
+	}
+	if !(c.ExplainStageDuration >= 0) || math.IsInf(c.ExplainStageDuration, 0) {
+		return fmt.Errorf("invalid explain stage duration: %g", c.ExplainStageDuration)
+	}
+	if err := c.validateSoak(); err != nil {
+		return err
+	}
+	switch c.ImportFormat {
+	case "", ImportFormatAuto, ImportFormatCSV, ImportFormatGadget, ImportFormatTipsy:
+	default:
+		return fmt.Errorf("invalid import format: %q (want %s, %s, %s or %s)", c.ImportFormat,
+			ImportFormatAuto, ImportFormatCSV, ImportFormatGadget, ImportFormatTipsy)
+	}
+	if c.Headless {
+		if c.MaxSteps < 0 {
+			return fmt.Errorf("invalid number of steps: %d", c.MaxSteps)
+		}
+		if c.Timeout < 0 {
+			return fmt.Errorf("invalid timeout: %f", c.Timeout)
+		}
+		if c.FixedTimeStep <= 0 {
+			return fmt.Errorf("invalid fixed time step: %f", c.FixedTimeStep)
+		}
+		if c.DiagnosticsInterval <= 0 {
+			return fmt.Errorf("invalid diagnostics interval: %d", c.DiagnosticsInterval)
+		}
+		if c.ProgressInterval < 0 {
+			return fmt.Errorf("invalid progress interval: %f", c.ProgressInterval)
+		}
+		if c.NBodyPath != "" && c.NBodyFormat != "gadget" && c.NBodyFormat != "raw" {
+			return fmt.Errorf("invalid N-body format: %q (want gadget or raw)", c.NBodyFormat)
+		}
+	}
+	return nil
+}
+
+// validateSoak checks the soak run settings
+func (c *Config) validateSoak() error {
+	if !(c.SoakInterval >= 0) || math.IsInf(c.SoakInterval, 0) {
+		return fmt.Errorf("invalid soak interval: %g", c.SoakInterval)
+	}
+	thresholds := []struct {
+		name  string
+		value float64
+	}{
+		{"RSS", c.SoakRSSGrowth},
+		{"GPU memory", c.SoakGPUGrowth},
+		{"allocation rate", c.SoakAllocGrowth},
+		{"energy drift", c.SoakDriftGrowth},
+	}
+	for _, t := range thresholds {
+		if !(t.value >= 0) || math.IsInf(t.value, 0) {
+			return fmt.Errorf("invalid soak %s growth: %g", t.name, t.value)
+		}
+	}
+	if c.Soak && !c.Headless {
+		return fmt.Errorf("soak runs are headless (add -headless)")
+	}
+	return nil
+}
+
+// validateRender checks the replay recording and offline render settings
+func (c *Config) validateRender() error {
+	if c.RecordDir != "" {
+		if !c.Headless {
+			return fmt.Errorf("replays are recorded in headless mode (add -headless)")
+		}
+		if c.RecordInterval <= 0 {
+			return fmt.Errorf("invalid record interval: %d", c.RecordInterval)
+		}
+	}
+	switch c.RenderStereo {
+	case "", RenderStereoSideBySide, RenderStereoAnaglyph:
+	default:
+		return fmt.Errorf("invalid render stereo: %q (want %s or %s)", c.RenderStereo, RenderStereoSideBySide, RenderStereoAnaglyph)
+	}
+	if c.RenderDir == "" {
+		return nil
+	}
+	if c.Headless {
+		return fmt.Errorf("offline rendering replaces the run, so it cannot be headless (drop -headless)")
+	}
+	if c.RenderWidth <= 0 || c.RenderHeight <= 0 {
+		return fmt.Errorf("invalid render size: %dx%d", c.RenderWidth, c.RenderHeight)
+	}
+	if c.Supersample < 1 || c.Supersample > MaxSupersample {
+		return fmt.Errorf("invalid supersampling: %d (want 1 to %d)", c.Supersample, MaxSupersample)
+	}
+	if max(c.RenderWidth, c.RenderHeight)*c.Supersample > MaxRenderSize {
+		return fmt.Errorf("invalid render size: %dx%d at %dx supersampling exceeds %d pixels per side (lower -supersample)",
+			c.RenderWidth, c.RenderHeight, c.Supersample, MaxRenderSize)
+	}
+	return nil
+}
+
+// validateSlowMotion checks the slow motion settings, which only apply with a trigger
+func (c *Config) validateSlowMotion() error {
+	switch c.SlowMotionTrigger {
+	case "":
+		return nil
+	case SlowMotionApproach, SlowMotionContact:
+	default:
+		return fmt.Errorf("invalid slow motion trigger: %q (want %s or %s)", c.SlowMotionTrigger, SlowMotionApproach, SlowMotionContact)
+	}
+	if c.SlowMotionTrigger == SlowMotionContact && c.RadiusModel == RadiusModelOff {
+		return fmt.Errorf("invalid slow motion trigger: contact needs particle radii, but the radius model is off")
+	}
+	if !(c.SlowMotionScale > 0 && c.SlowMotionScale <= 1) {
+		return fmt.Errorf("invalid slow motion scale: %g (want above 0 and up to 1)", c.SlowMotionScale)
+	}
+	if !(c.SlowMotionDuration > 0) || math.IsInf(c.SlowMotionDuration, 0) {
+		return fmt.Errorf("invalid slow motion duration: %g", c.SlowMotionDuration)
+	}
+	if !(c.SlowMotionDistance > 0) || math.IsInf(c.SlowMotionDistance, 0) {
+		return fmt.Errorf("invalid slow motion distance: %g", c.SlowMotionDistance)
+	}
+	if c.SlowMotionParticle < -1 {
+		return fmt.Errorf("invalid slow motion particle: %d", c.SlowMotionParticle)
+	}
+	if c.SlowMotionRadius < 0 || 2*c.SlowMotionRadius > float64(min(c.SimulationWidth, c.SimulationDepth)) {
+		return fmt.Errorf("invalid slow motion radius: %g cells (want 0 to half the domain)", c.SlowMotionRadius)
+	}
 	return nil
 }
 
@@ -87,3 +922,138 @@ func (c *Config) Clone() *Config {
 	clone := *c
 	return &clone
 }
+
+// hashVersion is the version of the hashParams encoding. Bump it whenever
+// the fields or their encoding change, so old and new hashes never collide
+const hashVersion = 1
+
+// hashParams are the parameters of a run that decide its results, in the
+// encoding Hash digests. Where a run reads its settings and writes its
+// outputs, and how it is drawn, are left out
+type hashParams struct {
+	Version int `json:"version"`
+
+	// Domain
+	SimulationWidth int     `json:"width"`
+	SimulationDepth int     `json:"depth"`
+	CellSize        float64 `json:"cell_size"`
+
+	// Physics
+	NumParticles          int     `json:"particles"`
+	GravitationalConstant float64 `json:"g"`
+	Seed                  int64   `json:"seed"`
+	Precision             string  `json:"precision"`
+	Solver                string  `json:"solver"`
+	Softening             float64 `json:"softening"`
+	EncounterRadius       float64 `json:"encounter_radius"`
+	IncrementalDeposit    bool    `json:"incremental_deposit"`
+	SparseGrid            bool    `json:"sparse_grid"`
+	CropSolve             bool    `json:"crop_solve"`
+	CropPadding           int     `json:"crop_padding"`
+	BlockLevels           int     `json:"block_levels"`
+	Deterministic         bool    `json:"deterministic"`
+	FrameOmega            float64 `json:"frame_omega"`
+	SpongeWidth           float64 `json:"sponge_width"`
+	SpongeStrength        float64 `json:"sponge_strength"`
+	ImageCorrection       bool    `json:"image_correction"`
+	RadiusModel           string  `json:"radius_model"`
+	ParticleDensity       float64 `json:"particle_density"`
+	ParticleRadius        float64 `json:"particle_radius"`
+	Compute               string  `json:"compute"`
+
+	// Initial conditions
+	Scenario      string `json:"scenario"`
+	ImportPath    string `json:"import_path"`
+	ImportFormat  string `json:"import_format"`
+	ImportPlaneXY bool   `json:"import_plane_xy"`
+	TracerSheet   int    `json:"tracer_sheet"`
+
+	// Time stepping
+	FixedTimeStep float32 `json:"fixed_time_step"`
+	MaxSteps      int     `json:"max_steps"`
+}
+
+// hashParams returns the parameters of the run that Hash digests
+func (c *Config) hashParams() hashParams {
+	return hashParams{
+		Version:               hashVersion,
+		SimulationWidth:       c.SimulationWidth,
+		SimulationDepth:       c.SimulationDepth,
+		CellSize:              c.CellSize,
+		NumParticles:          c.NumParticles,
+		GravitationalConstant: c.GravitationalConstant,
+		Seed:                  c.Seed,
+		Precision:             c.Precision,
+		Solver:                c.Solver,
+		Softening:             c.Softening,
+		EncounterRadius:       c.EncounterRadius,
+		IncrementalDeposit:    c.IncrementalDeposit,
+		SparseGrid:            c.SparseGrid,
+		CropSolve:             c.CropSolve,
+		CropPadding:           c.CropPadding,
+		BlockLevels:           c.BlockLevels,
+		Deterministic:         c.Deterministic,
+		FrameOmega:            c.FrameOmega,
+		SpongeWidth:           c.SpongeWidth,
+		SpongeStrength:        c.SpongeStrength,
+		ImageCorrection:       c.ImageCorrection,
+		RadiusModel:           c.RadiusModel,
+		ParticleDensity:       c.ParticleDensity,
+		ParticleRadius:        c.ParticleRadius,
+		Compute:               c.Compute(),
+		Scenario:              c.Scenario,
+		ImportPath:            c.ImportPath,
+		ImportFormat:          c.ImportFormat,
+		ImportPlaneXY:         c.ImportPlaneXY,
+		TracerSheet:           c.TracerSheet,
+		FixedTimeStep:         c.FixedTimeStep,
+		MaxSteps:              c.MaxSteps,
+	}
+}
+
+// Hash returns a digest of the JSON encoding of the parameters that decide a
+// run's results, so runs with the same parameters share a hash wherever their
+// outputs go and however they are drawn. Parameters JSON cannot encode, such
+// as NaN, hash to ""
+func (c *Config) Hash() string {
+	data, err := json.Marshal(c.hashParams())
+	if err != nil {
+		return ""
+	}
+	sum := sha256.Sum256(data)
+	return hex.EncodeToString(sum[:8])
+}
+
+// Spacing returns the physical size of a grid cell: CellSize, or 1 if unset
+func (c *Config) Spacing() float64 {
+	if c.CellSize > 0 {
+		return c.CellSize
+	}
+	return 1
+}
+
+// DomainSize returns the physical width and depth of the simulation domain
+func (c *Config) DomainSize() (width, depth float64) {
+	dx := c.Spacing()
+	return float64(c.SimulationWidth) * dx, float64(c.SimulationDepth) * dx
+}
+
+// DirectSolver reports whether the configured solver uses direct summation
+func (c *Config) DirectSolver() bool {
+	return c.Solver == SolverDirect || c.Solver == SolverDirectGPU
+}
+
+// Compute returns the compute mode, derived from UseGPU when unset.
+// Deterministic runs always use the CPU
+func (c *Config) Compute() string {
+	switch {
+	case c.Deterministic:
+		return ComputeCPU
+	case c.ComputeMode != "":
+		return c.ComputeMode
+	case c.UseGPU:
+		return ComputeGPU
+	default:
+		return ComputeCPU
+	}
+}
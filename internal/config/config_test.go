@@ -1,6 +1,8 @@
 package config
 
 import (
+	"encoding/json"
+	"math"
 	"testing"
 )
 
@@ -58,6 +60,39 @@ func TestDefaultConfig(t *testing.T) {
 	if cfg.UseGPU != true {
 		t.Errorf("Expected UseGPU true, got %v", cfg.UseGPU)
 	}
+
+	if cfg.Precision != PrecisionFloat64 {
+		t.Errorf("Expected Precision float64, got %s", cfg.Precision)
+	}
+	if cfg.Solver != SolverPM {
+		t.Errorf("Expected Solver pm, got %s", cfg.Solver)
+	}
+
+	if cfg.AdaptiveQuality || cfg.PowerSaver {
+		t.Errorf("Expected adaptive quality and power saver off, got %v and %v", cfg.AdaptiveQuality, cfg.PowerSaver)
+	}
+
+	// Test crash reporting defaults
+	if cfg.CrashReportDir != "crash_reports" {
+		t.Errorf("Expected CrashReportDir crash_reports, got %s", cfg.CrashReportDir)
+	}
+	if cfg.CrashDumpState != true {
+		t.Errorf("Expected CrashDumpState true, got %v", cfg.CrashDumpState)
+	}
+
+	// Test headless defaults
+	if cfg.Headless != false {
+		t.Errorf("Expected Headless false, got %v", cfg.Headless)
+	}
+	if cfg.FixedTimeStep <= 0 {
+		t.Errorf("Expected positive FixedTimeStep, got %f", cfg.FixedTimeStep)
+	}
+	if cfg.DiagnosticsInterval != 100 {
+		t.Errorf("Expected DiagnosticsInterval 100, got %d", cfg.DiagnosticsInterval)
+	}
+	if cfg.ProgressInterval != 5.0 {
+		t.Errorf("Expected ProgressInterval 5.0, got %f", cfg.ProgressInterval)
+	}
 }
 
 // TestCustomConfig tests creating a custom configuration
@@ -124,6 +159,75 @@ func TestConfigValidation(t *testing.T) {
 			},
 			wantError: true,
 		},
+		{
+			name: "rectangular simulation",
+			config: &Config{
+				ScreenWidth:     1920,
+				ScreenHeight:    1080,
+				SimulationWidth: 128,
+				SimulationDepth: 512,
+				NumParticles:    10,
+			},
+			wantError: false,
+		},
+		{
+			name: "too many grid cells",
+			config: &Config{
+				ScreenWidth:     1920,
+				ScreenHeight:    1080,
+				SimulationWidth: 1 << 16,
+				SimulationDepth: 1 << 11,
+				NumParticles:    10,
+			},
+			wantError: true,
+		},
+		{
+			name: "overflowing simulation size",
+			config: &Config{
+				ScreenWidth:     1920,
+				ScreenHeight:    1080,
+				SimulationWidth: math.MaxInt,
+				SimulationDepth: 2,
+				NumParticles:    10,
+			},
+			wantError: true,
+		},
+		{
+			name: "fine cells",
+			config: &Config{
+				ScreenWidth:     1920,
+				ScreenHeight:    1080,
+				SimulationWidth: 512,
+				SimulationDepth: 512,
+				CellSize:        0.25,
+				NumParticles:    10,
+			},
+			wantError: false,
+		},
+		{
+			name: "negative cell size",
+			config: &Config{
+				ScreenWidth:     1920,
+				ScreenHeight:    1080,
+				SimulationWidth: 256,
+				SimulationDepth: 256,
+				CellSize:        -1,
+				NumParticles:    10,
+			},
+			wantError: true,
+		},
+		{
+			name: "infinite cell size",
+			config: &Config{
+				ScreenWidth:     1920,
+				ScreenHeight:    1080,
+				SimulationWidth: 256,
+				SimulationDepth: 256,
+				CellSize:        math.Inf(1),
+				NumParticles:    10,
+			},
+			wantError: true,
+		},
 		{
 			name: "invalid particle count",
 			config: &Config{
@@ -135,14 +239,1026 @@ func TestConfigValidation(t *testing.T) {
 			},
 			wantError: true,
 		},
-	}
-
-	for _, tt := range tests {
-		t.Run(tt.name, func(t *testing.T) {
-			err := tt.config.Validate()
-			if (err != nil) != tt.wantError {
-				t.Errorf("Validate() error = %v, wantError %v", err, tt.wantError)
-			}
-		})
+		{
+			name: "invalid precision",
+			config: &Config{
+				ScreenWidth:     1920,
+				ScreenHeight:    1080,
+				SimulationWidth: 256,
+				SimulationDepth: 256,
+				NumParticles:    10,
+				Precision:       "float16",
+			},
+			wantError: true,
+		},
+		{
+			name: "float32 precision",
+			config: &Config{
+				ScreenWidth:     1920,
+				ScreenHeight:    1080,
+				SimulationWidth: 256,
+				SimulationDepth: 256,
+				NumParticles:    10,
+				Precision:       PrecisionFloat32,
+			},
+			wantError: false,
+		},
+		{
+			name: "invalid solver",
+			config: &Config{
+				ScreenWidth:     1920,
+				ScreenHeight:    1080,
+				SimulationWidth: 256,
+				SimulationDepth: 256,
+				NumParticles:    10,
+				Solver:          "tree",
+			},
+			wantError: true,
+		},
+		{
+			name: "direct solver",
+			config: &Config{
+				ScreenWidth:     1920,
+				ScreenHeight:    1080,
+				SimulationWidth: 256,
+				SimulationDepth: 256,
+				NumParticles:    MaxDirectParticles,
+				Solver:          SolverDirect,
+			},
+			wantError: false,
+		},
+		{
+			name: "GPU direct solver",
+			config: &Config{
+				ScreenWidth:     1920,
+				ScreenHeight:    1080,
+				SimulationWidth: 256,
+				SimulationDepth: 256,
+				NumParticles:    MaxDirectGPUParticles,
+				Solver:          SolverDirectGPU,
+			},
+			wantError: false,
+		},
+		{
+			name: "GPU direct solver with too many particles",
+			config: &Config{
+				ScreenWidth:     1920,
+				ScreenHeight:    1080,
+				SimulationWidth: 256,
+				SimulationDepth: 256,
+				NumParticles:    MaxDirectGPUParticles + 1,
+				Solver:          SolverDirectGPU,
+			},
+			wantError: true,
+		},
+		{
+			name: "direct solver with too many particles",
+			config: &Config{
+				ScreenWidth:     1920,
+				ScreenHeight:    1080,
+				SimulationWidth: 256,
+				SimulationDepth: 256,
+				NumParticles:    MaxDirectParticles + 1,
+				Solver:          SolverDirect,
+			},
+			wantError: true,
+		},
+		{
+			name: "CUDA backend",
+			config: &Config{
+				ScreenWidth:     1920,
+				ScreenHeight:    1080,
+				SimulationWidth: 256,
+				SimulationDepth: 256,
+				NumParticles:    10,
+				GPUBackend:      GPUBackendCUDA,
+			},
+			wantError: false,
+		},
+		{
+			name: "block time steps with the PM solver",
+			config: &Config{
+				ScreenWidth:     1920,
+				ScreenHeight:    1080,
+				SimulationWidth: 256,
+				SimulationDepth: 256,
+				NumParticles:    10,
+				Solver:          SolverPM,
+				BlockLevels:     3,
+			},
+			wantError: true,
+		},
+		{
+			name: "deterministic without a seed",
+			config: &Config{
+				ScreenWidth:     1920,
+				ScreenHeight:    1080,
+				SimulationWidth: 256,
+				SimulationDepth: 256,
+				NumParticles:    10,
+				Deterministic:   true,
+			},
+			wantError: true,
+		},
+		{
+			name: "deterministic on the GPU",
+			config: &Config{
+				ScreenWidth:     1920,
+				ScreenHeight:    1080,
+				SimulationWidth: 256,
+				SimulationDepth: 256,
+				NumParticles:    10,
+				Seed:            1,
+				Deterministic:   true,
+				ComputeMode:     ComputeGPU,
+			},
+			wantError: true,
+		},
+		{
+			name: "negative guard interval",
+			config: &Config{
+				ScreenWidth:     1920,
+				ScreenHeight:    1080,
+				SimulationWidth: 256,
+				SimulationDepth: 256,
+				NumParticles:    10,
+				GuardInterval:   -1,
+			},
+			wantError: true,
+		},
+		{
+			name: "guard energy growth not above 1",
+			config: &Config{
+				ScreenWidth:       1920,
+				ScreenHeight:      1080,
+				SimulationWidth:   256,
+				SimulationDepth:   256,
+				NumParticles:      10,
+				GuardEnergyGrowth: 0.5,
+			},
+			wantError: true,
+		},
+		{
+			name: "unknown layer",
+			config: &Config{
+				ScreenWidth:     1920,
+				ScreenHeight:    1080,
+				SimulationWidth: 256,
+				SimulationDepth: 256,
+				NumParticles:    10,
+				Layers:          "grid,stars",
+			},
+			wantError: true,
+		},
+		{
+			name: "one-position trails",
+			config: &Config{
+				ScreenWidth:     1920,
+				ScreenHeight:    1080,
+				SimulationWidth: 256,
+				SimulationDepth: 256,
+				NumParticles:    10,
+				TrailLength:     1,
+			},
+			wantError: true,
+		},
+		{
+			name: "negative health interval",
+			config: &Config{
+				ScreenWidth:     1920,
+				ScreenHeight:    1080,
+				SimulationWidth: 256,
+				SimulationDepth: 256,
+				NumParticles:    10,
+				HealthInterval:  -1,
+			},
+			wantError: true,
+		},
+		{
+			name: "negative Lyapunov particles",
+			config: &Config{
+				ScreenWidth:       1920,
+				ScreenHeight:      1080,
+				SimulationWidth:   256,
+				SimulationDepth:   256,
+				NumParticles:      10,
+				LyapunovParticles: -1,
+			},
+			wantError: true,
+		},
+		{
+			name: "invalid profile center",
+			config: &Config{
+				ScreenWidth:     1920,
+				ScreenHeight:    1080,
+				SimulationWidth: 256,
+				SimulationDepth: 256,
+				NumParticles:    10,
+				ProfileCenter:   "origin",
+			},
+			wantError: true,
+		},
+		{
+			name: "slow motion scale above one",
+			config: &Config{
+				ScreenWidth:       1920,
+				ScreenHeight:      1080,
+				SimulationWidth:   256,
+				SimulationDepth:   256,
+				NumParticles:      10,
+				SlowMotionTrigger: SlowMotionApproach,
+				SlowMotionScale:   2,
+			},
+			wantError: true,
+		},
+		{
+			name: "unknown preset",
+			config: &Config{
+				ScreenWidth:     1920,
+				ScreenHeight:    1080,
+				SimulationWidth: 256,
+				SimulationDepth: 256,
+				NumParticles:    10,
+				Preset:          "galaxy",
+			},
+			wantError: true,
+		},
+		{
+			name: "group region without radius",
+			config: &Config{
+				ScreenWidth:     1920,
+				ScreenHeight:    1080,
+				SimulationWidth: 256,
+				SimulationDepth: 256,
+				NumParticles:    10,
+				GroupRegions:    "left=-20,0",
+			},
+			wantError: true,
+		},
+		{
+			name: "tracer sheet of one",
+			config: &Config{
+				ScreenWidth:     1920,
+				ScreenHeight:    1080,
+				SimulationWidth: 256,
+				SimulationDepth: 256,
+				NumParticles:    10,
+				TracerSheet:     1,
+			},
+			wantError: true,
+		},
+		{
+			name: "mesh file of unknown format",
+			config: &Config{
+				ScreenWidth:     1920,
+				ScreenHeight:    1080,
+				SimulationWidth: 256,
+				SimulationDepth: 256,
+				NumParticles:    10,
+				MeshPath:        "spacetime.stl",
+			},
+			wantError: true,
+		},
+		{
+			name: "replay recorded with a window",
+			config: &Config{
+				ScreenWidth:     1920,
+				ScreenHeight:    1080,
+				SimulationWidth: 256,
+				SimulationDepth: 256,
+				NumParticles:    10,
+				RecordDir:       "replay",
+			},
+			wantError: true,
+		},
+		{
+			name: "render oversampled past the texture limit",
+			config: &Config{
+				ScreenWidth:     1920,
+				ScreenHeight:    1080,
+				SimulationWidth: 256,
+				SimulationDepth: 256,
+				NumParticles:    10,
+				RenderDir:       "replay",
+				RenderWidth:     7680,
+				RenderHeight:    4320,
+				Supersample:     4,
+			},
+			wantError: true,
+		},
+		{
+			name: "unknown overlay item",
+			config: &Config{
+				ScreenWidth:     1920,
+				ScreenHeight:    1080,
+				SimulationWidth: 256,
+				SimulationDepth: 256,
+				NumParticles:    10,
+				Overlay:         "time,fps",
+			},
+			wantError: true,
+		},
+		{
+			name: "binary log without detection",
+			config: &Config{
+				ScreenWidth:     1920,
+				ScreenHeight:    1080,
+				SimulationWidth: 256,
+				SimulationDepth: 256,
+				NumParticles:    10,
+				BinaryLogPath:   "binaries.csv",
+			},
+			wantError: true,
+		},
+		{
+			name: "negative time reversal steps",
+			config: &Config{
+				ScreenWidth:     1920,
+				ScreenHeight:    1080,
+				SimulationWidth: 256,
+				SimulationDepth: 256,
+				NumParticles:    10,
+				ReversalSteps:   -1,
+			},
+			wantError: true,
+		},
+		{
+			name: "time reversal test without headless",
+			config: &Config{
+				ScreenWidth:     1920,
+				ScreenHeight:    1080,
+				SimulationWidth: 256,
+				SimulationDepth: 256,
+				NumParticles:    10,
+				TimeReversal:    true,
+			},
+			wantError: true,
+		},
+		{
+			name: "soak run without headless",
+			config: &Config{
+				ScreenWidth:     1920,
+				ScreenHeight:    1080,
+				SimulationWidth: 256,
+				SimulationDepth: 256,
+				NumParticles:    10,
+				Soak:            true,
+			},
+			wantError: true,
+		},
+		{
+			name: "negative soak growth",
+			config: &Config{
+				ScreenWidth:     1920,
+				ScreenHeight:    1080,
+				SimulationWidth: 256,
+				SimulationDepth: 256,
+				NumParticles:    10,
+				SoakRSSGrowth:   -1,
+			},
+			wantError: true,
+		},
+		{
+			name: "negative physics budget",
+			config: &Config{
+				ScreenWidth:     1920,
+				ScreenHeight:    1080,
+				SimulationWidth: 256,
+				SimulationDepth: 256,
+				NumParticles:    10,
+				PhysicsBudget:   -1,
+			},
+			wantError: true,
+		},
+		{
+			name: "deterministic with a physics budget",
+			config: &Config{
+				ScreenWidth:     1920,
+				ScreenHeight:    1080,
+				SimulationWidth: 256,
+				SimulationDepth: 256,
+				NumParticles:    10,
+				Seed:            1,
+				Deterministic:   true,
+				PhysicsBudget:   8,
+			},
+			wantError: true,
+		},
+		{
+			name: "deterministic with preview physics",
+			config: &Config{
+				ScreenWidth:     1920,
+				ScreenHeight:    1080,
+				SimulationWidth: 256,
+				SimulationDepth: 256,
+				NumParticles:    10,
+				Seed:            1,
+				Deterministic:   true,
+				PreviewPhysics:  true,
+			},
+			wantError: true,
+		},
+		{
+			name: "sparse grid with crop",
+			config: &Config{
+				ScreenWidth:     1920,
+				ScreenHeight:    1080,
+				SimulationWidth: 256,
+				SimulationDepth: 256,
+				NumParticles:    10,
+				SparseGrid:      true,
+				CropSolve:       true,
+				CropPadding:     16,
+			},
+			wantError: false,
+		},
+		{
+			name: "sparse grid with the direct solver",
+			config: &Config{
+				ScreenWidth:     1920,
+				ScreenHeight:    1080,
+				SimulationWidth: 256,
+				SimulationDepth: 256,
+				NumParticles:    10,
+				Solver:          SolverDirect,
+				SparseGrid:      true,
+			},
+			wantError: true,
+		},
+		{
+			name: "sparse grid with incremental deposits",
+			config: &Config{
+				ScreenWidth:        1920,
+				ScreenHeight:       1080,
+				SimulationWidth:    256,
+				SimulationDepth:    256,
+				NumParticles:       10,
+				SparseGrid:         true,
+				IncrementalDeposit: true,
+			},
+			wantError: true,
+		},
+		{
+			name: "crop without the sparse grid",
+			config: &Config{
+				ScreenWidth:     1920,
+				ScreenHeight:    1080,
+				SimulationWidth: 256,
+				SimulationDepth: 256,
+				NumParticles:    10,
+				CropSolve:       true,
+			},
+			wantError: true,
+		},
+		{
+			name: "negative crop padding",
+			config: &Config{
+				ScreenWidth:     1920,
+				ScreenHeight:    1080,
+				SimulationWidth: 256,
+				SimulationDepth: 256,
+				NumParticles:    10,
+				SparseGrid:      true,
+				CropPadding:     -1,
+			},
+			wantError: true,
+		},
+		{
+			name: "sponge layer",
+			config: &Config{
+				ScreenWidth:     1920,
+				ScreenHeight:    1080,
+				SimulationWidth: 256,
+				SimulationDepth: 256,
+				NumParticles:    10,
+				SpongeWidth:     16,
+				SpongeStrength:  5,
+			},
+			wantError: false,
+		},
+		{
+			name: "sponge wider than half the domain",
+			config: &Config{
+				ScreenWidth:     1920,
+				ScreenHeight:    1080,
+				SimulationWidth: 256,
+				SimulationDepth: 256,
+				NumParticles:    10,
+				SpongeWidth:     200,
+			},
+			wantError: true,
+		},
+		{
+			name: "negative sponge strength",
+			config: &Config{
+				ScreenWidth:     1920,
+				ScreenHeight:    1080,
+				SimulationWidth: 256,
+				SimulationDepth: 256,
+				NumParticles:    10,
+				SpongeWidth:     16,
+				SpongeStrength:  -1,
+			},
+			wantError: true,
+		},
+		{
+			name: "image correction",
+			config: &Config{
+				ScreenWidth:     1920,
+				ScreenHeight:    1080,
+				SimulationWidth: 256,
+				SimulationDepth: 256,
+				NumParticles:    10,
+				ImageCorrection: true,
+			},
+			wantError: false,
+		},
+		{
+			name: "image correction with the direct solver",
+			config: &Config{
+				ScreenWidth:     1920,
+				ScreenHeight:    1080,
+				SimulationWidth: 256,
+				SimulationDepth: 256,
+				NumParticles:    10,
+				Solver:          SolverDirect,
+				ImageCorrection: true,
+			},
+			wantError: true,
+		},
+		{
+			name: "image correction with a cropped solve",
+			config: &Config{
+				ScreenWidth:     1920,
+				ScreenHeight:    1080,
+				SimulationWidth: 256,
+				SimulationDepth: 256,
+				NumParticles:    10,
+				SparseGrid:      true,
+				CropSolve:       true,
+				ImageCorrection: true,
+			},
+			wantError: true,
+		},
+		{
+			name: "auto compute mode",
+			config: &Config{
+				ScreenWidth:     1920,
+				ScreenHeight:    1080,
+				SimulationWidth: 256,
+				SimulationDepth: 256,
+				NumParticles:    10,
+				ComputeMode:     ComputeAuto,
+			},
+			wantError: false,
+		},
+		{
+			name: "invalid compute mode",
+			config: &Config{
+				ScreenWidth:     1920,
+				ScreenHeight:    1080,
+				SimulationWidth: 256,
+				SimulationDepth: 256,
+				NumParticles:    10,
+				ComputeMode:     "fpga",
+			},
+			wantError: true,
+		},
+		{
+			name: "invalid GPU backend",
+			config: &Config{
+				ScreenWidth:     1920,
+				ScreenHeight:    1080,
+				SimulationWidth: 256,
+				SimulationDepth: 256,
+				NumParticles:    10,
+				GPUBackend:      "metal",
+			},
+			wantError: true,
+		},
+		{
+			name: "deuteranopia palette",
+			config: &Config{
+				ScreenWidth:     1920,
+				ScreenHeight:    1080,
+				SimulationWidth: 256,
+				SimulationDepth: 256,
+				NumParticles:    10,
+				Palette:         PaletteDeuteranopia,
+				UIScale:         1.5,
+			},
+			wantError: false,
+		},
+		{
+			name: "invalid palette",
+			config: &Config{
+				ScreenWidth:     1920,
+				ScreenHeight:    1080,
+				SimulationWidth: 256,
+				SimulationDepth: 256,
+				NumParticles:    10,
+				Palette:         "sepia",
+			},
+			wantError: true,
+		},
+		{
+			name: "UI scale too large",
+			config: &Config{
+				ScreenWidth:     1920,
+				ScreenHeight:    1080,
+				SimulationWidth: 256,
+				SimulationDepth: 256,
+				NumParticles:    10,
+				UIScale:         10,
+			},
+			wantError: true,
+		},
+		{
+			name: "negative eye separation",
+			config: &Config{
+				ScreenWidth:     1920,
+				ScreenHeight:    1080,
+				SimulationWidth: 256,
+				SimulationDepth: 256,
+				NumParticles:    10,
+				Stereo:          true,
+				EyeSeparation:   -1,
+			},
+			wantError: true,
+		},
+		{
+			name: "negative target FPS",
+			config: &Config{
+				ScreenWidth:     1920,
+				ScreenHeight:    1080,
+				SimulationWidth: 256,
+				SimulationDepth: 256,
+				NumParticles:    10,
+				TargetFPS:       -1,
+			},
+			wantError: true,
+		},
+		{
+			name: "time step smoothing of 1",
+			config: &Config{
+				ScreenWidth:     1920,
+				ScreenHeight:    1080,
+				SimulationWidth: 256,
+				SimulationDepth: 256,
+				NumParticles:    10,
+				DeltaSmoothing:  1,
+			},
+			wantError: true,
+		},
+		{
+			name: "time step spike factor below 1",
+			config: &Config{
+				ScreenWidth:      1920,
+				ScreenHeight:     1080,
+				SimulationWidth:  256,
+				SimulationDepth:  256,
+				NumParticles:     10,
+				DeltaSpikeFactor: 0.5,
+			},
+			wantError: true,
+		},
+		{
+			name: "rotating frame",
+			config: &Config{
+				ScreenWidth:     1920,
+				ScreenHeight:    1080,
+				SimulationWidth: 256,
+				SimulationDepth: 256,
+				NumParticles:    10,
+				FrameOmega:      -0.05,
+			},
+			wantError: false,
+		},
+		{
+			name: "infinite frame angular velocity",
+			config: &Config{
+				ScreenWidth:     1920,
+				ScreenHeight:    1080,
+				SimulationWidth: 256,
+				SimulationDepth: 256,
+				NumParticles:    10,
+				FrameOmega:      math.Inf(1),
+			},
+			wantError: true,
+		},
+		{
+			name: "three-body scenario",
+			config: &Config{
+				ScreenWidth:     1920,
+				ScreenHeight:    1080,
+				SimulationWidth: 256,
+				SimulationDepth: 256,
+				NumParticles:    10,
+				Scenario:        ScenarioThreeBody,
+			},
+			wantError: false,
+		},
+		{
+			name: "tidal disruption in a rotating frame",
+			config: &Config{
+				ScreenWidth:     1920,
+				ScreenHeight:    1080,
+				SimulationWidth: 256,
+				SimulationDepth: 256,
+				NumParticles:    10,
+				Scenario:        ScenarioTidalDisruption,
+				FrameOmega:      0.1,
+			},
+			wantError: false,
+		},
+		{
+			name: "invalid scenario",
+			config: &Config{
+				ScreenWidth:     1920,
+				ScreenHeight:    1080,
+				SimulationWidth: 256,
+				SimulationDepth: 256,
+				NumParticles:    10,
+				Scenario:        "four-body",
+			},
+			wantError: true,
+		},
+		{
+			name: "three-body scenario in a rotating frame",
+			config: &Config{
+				ScreenWidth:     1920,
+				ScreenHeight:    1080,
+				SimulationWidth: 256,
+				SimulationDepth: 256,
+				NumParticles:    10,
+				Scenario:        ScenarioThreeBody,
+				FrameOmega:      0.1,
+			},
+			wantError: true,
+		},
+		{
+			name: "fixed particle radius",
+			config: &Config{
+				ScreenWidth:     1920,
+				ScreenHeight:    1080,
+				SimulationWidth: 256,
+				SimulationDepth: 256,
+				NumParticles:    10,
+				RadiusModel:     RadiusModelFixed,
+				ParticleRadius:  0.2,
+			},
+			wantError: false,
+		},
+		{
+			name: "GL debug off",
+			config: &Config{
+				ScreenWidth:     1920,
+				ScreenHeight:    1080,
+				SimulationWidth: 256,
+				SimulationDepth: 256,
+				NumParticles:    10,
+				GLDebug:         GLDebugOff,
+			},
+			wantError: false,
+		},
+		{
+			name: "invalid GL debug level",
+			config: &Config{
+				ScreenWidth:     1920,
+				ScreenHeight:    1080,
+				SimulationWidth: 256,
+				SimulationDepth: 256,
+				NumParticles:    10,
+				GLDebug:         "verbose",
+			},
+			wantError: true,
+		},
+		{
+			name: "invalid radius model",
+			config: &Config{
+				ScreenWidth:     1920,
+				ScreenHeight:    1080,
+				SimulationWidth: 256,
+				SimulationDepth: 256,
+				NumParticles:    10,
+				RadiusModel:     "mass",
+			},
+			wantError: true,
+		},
+		{
+			name: "negative particle density",
+			config: &Config{
+				ScreenWidth:     1920,
+				ScreenHeight:    1080,
+				SimulationWidth: 256,
+				SimulationDepth: 256,
+				NumParticles:    10,
+				ParticleDensity: -1,
+			},
+			wantError: true,
+		},
+		{
+			name: "negative minimum display radius",
+			config: &Config{
+				ScreenWidth:      1920,
+				ScreenHeight:     1080,
+				SimulationWidth:  256,
+				SimulationDepth:  256,
+				NumParticles:     10,
+				MinDisplayRadius: -0.1,
+			},
+			wantError: true,
+		},
+		{
+			name: "NaN cull pixels",
+			config: &Config{
+				ScreenWidth:     1920,
+				ScreenHeight:    1080,
+				SimulationWidth: 256,
+				SimulationDepth: 256,
+				NumParticles:    10,
+				CullPixels:      math.NaN(),
+			},
+			wantError: true,
+		},
+		{
+			name: "render budget too small",
+			config: &Config{
+				ScreenWidth:     1920,
+				ScreenHeight:    1080,
+				SimulationWidth: 256,
+				SimulationDepth: 256,
+				NumParticles:    10,
+				RenderBudget:    10,
+			},
+			wantError: true,
+		},
+		{
+			name: "invalid particle coloring",
+			config: &Config{
+				ScreenWidth:      1920,
+				ScreenHeight:     1080,
+				SimulationWidth:  256,
+				SimulationDepth:  256,
+				NumParticles:     10,
+				ParticleColoring: "velocity",
+			},
+			wantError: true,
+		},
+		{
+			name: "negative GPU device",
+			config: &Config{
+				ScreenWidth:     1920,
+				ScreenHeight:    1080,
+				SimulationWidth: 256,
+				SimulationDepth: 256,
+				NumParticles:    10,
+				GPUDevice:       -1,
+			},
+			wantError: true,
+		},
+		{
+			name: "invalid import format",
+			config: &Config{
+				ScreenWidth:     1920,
+				ScreenHeight:    1080,
+				SimulationWidth: 256,
+				SimulationDepth: 256,
+				NumParticles:    10,
+				ImportFormat:    "hdf5",
+			},
+			wantError: true,
+		},
+		{
+			name: "tipsy import format",
+			config: &Config{
+				ScreenWidth:     1920,
+				ScreenHeight:    1080,
+				SimulationWidth: 256,
+				SimulationDepth: 256,
+				NumParticles:    10,
+				ImportPath:      "ic.tipsy",
+				ImportFormat:    ImportFormatTipsy,
+			},
+			wantError: false,
+		},
+		{
+			name: "invalid headless time step",
+			config: &Config{
+				ScreenWidth:         1920,
+				ScreenHeight:        1080,
+				SimulationWidth:     256,
+				SimulationDepth:     256,
+				NumParticles:        10,
+				Headless:            true,
+				FixedTimeStep:       0,
+				DiagnosticsInterval: 100,
+			},
+			wantError: true,
+		},
+		{
+			name: "negative headless timeout",
+			config: &Config{
+				ScreenWidth:         1920,
+				ScreenHeight:        1080,
+				SimulationWidth:     256,
+				SimulationDepth:     256,
+				NumParticles:        10,
+				Headless:            true,
+				Timeout:             -1,
+				FixedTimeStep:       1.0 / 60.0,
+				DiagnosticsInterval: 100,
+			},
+			wantError: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := tt.config.Validate()
+			if (err != nil) != tt.wantError {
+				t.Errorf("Validate() error = %v, wantError %v", err, tt.wantError)
+			}
+		})
+	}
+}
+
+// TestConfigCompute tests that an unset compute mode follows UseGPU
+func TestConfigCompute(t *testing.T) {
+	cfg := &Config{UseGPU: true}
+	if cfg.Compute() != ComputeGPU {
+		t.Errorf("Expected %s, got %s", ComputeGPU, cfg.Compute())
+	}
+	cfg.UseGPU = false
+	if cfg.Compute() != ComputeCPU {
+		t.Errorf("Expected %s, got %s", ComputeCPU, cfg.Compute())
+	}
+	cfg.ComputeMode = ComputeAuto
+	if cfg.Compute() != ComputeAuto {
+		t.Errorf("Expected %s, got %s", ComputeAuto, cfg.Compute())
+	}
+	cfg.Deterministic = true
+	if cfg.Compute() != ComputeCPU {
+		t.Errorf("Expected %s for a deterministic run, got %s", ComputeCPU, cfg.Compute())
+	}
+}
+
+// TestConfigDomainSize tests that the domain is the cell count times the
+// cell size, with an unset size counting as 1
+func TestConfigDomainSize(t *testing.T) {
+	cfg := &Config{SimulationWidth: 128, SimulationDepth: 64}
+	if width, depth := cfg.DomainSize(); width != 128 || depth != 64 {
+		t.Errorf("Expected 128x64 with unit cells, got %gx%g", width, depth)
+	}
+	cfg.CellSize = 0.5
+	if width, depth := cfg.DomainSize(); width != 64 || depth != 32 {
+		t.Errorf("Expected 64x32 with half-size cells, got %gx%g", width, depth)
+	}
+}
+
+// TestConfigHash tests that the hash follows the parameters but not where
+// the outputs go
+func TestConfigHash(t *testing.T) {
+	cfg := DefaultConfig()
+	hash := cfg.Hash()
+	if len(hash) != 16 {
+		t.Errorf("Expected a 16-digit hash, got %q", hash)
+	}
+
+	moved := cfg.Clone()
+	moved.DiagnosticsPath, moved.RunDir, moved.ConfigPath = "runs/1/logs/diag.csv", "runs", "run.json"
+	moved.GridVisScale, moved.Palette, moved.TargetFPS = 2, PaletteHighContrast, 30
+	if moved.Hash() != hash {
+		t.Error("Expected output locations and drawing settings to leave the hash unchanged")
+	}
+
+	changed := cfg.Clone()
+	changed.Seed = 42
+	if changed.Hash() == hash {
+		t.Error("Expected a different seed to change the hash")
+	}
+}
+
+// TestConfigHashPinned tests that the hash encoding stays fixed across
+// releases; changing it must bump hashVersion and these values
+func TestConfigHashPinned(t *testing.T) {
+	cfg := DefaultConfig()
+	cfg.Seed = 7
+	data, err := json.Marshal(cfg.hashParams())
+	if err != nil {
+		t.Fatalf("Failed to encode the parameters: %v", err)
+	}
+	want := `{"version":1,"width":256,"depth":256,"cell_size":1,"particles":10,"g":1,"seed":7,` +
+		`"precision":"float64","solver":"pm","softening":0.25,"encounter_radius":2,"incremental_deposit":false,` +
+		`"sparse_grid":false,"crop_solve":false,"crop_padding":0,"block_levels":0,"deterministic":false,` +
+		`"frame_omega":0,"sponge_width":0,"sponge_strength":0,"image_correction":false,"radius_model":"density",` +
+		`"particle_density":38.19718634205488,"particle_radius":0.5,"compute":"gpu","scenario":"random",` +
+		`"import_path":"","import_format":"auto","import_plane_xy":false,"tracer_sheet":0,` +
+		`"fixed_time_step":0.016666668,"max_steps":0}`
+	if string(data) != want {
+		t.Errorf("Unexpected encoding\n got %s\nwant %s", data, want)
+	}
+	if got := cfg.Hash(); got != "6b989df2c2da89a7" {
+		t.Errorf("Expected hash 6b989df2c2da89a7, got %s", got)
 	}
 }
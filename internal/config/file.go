@@ -0,0 +1,161 @@
+package config
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"reflect"
+)
+
+// LoadFile layers the settings in the JSON file at path over the
+// configuration. Keys are Config field names, and unknown keys are errors.
+// Preset and ConfigPath are kept, since they select the layers themselves
+func (c *Config) LoadFile(path string) error {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return err
+	}
+	loaded := c.Clone()
+	decoder := json.NewDecoder(bytes.NewReader(data))
+	decoder.DisallowUnknownFields()
+	if err := decoder.Decode(loaded); err != nil {
+		return fmt.Errorf("invalid JSON: %v", err)
+	}
+	loaded.Preset, loaded.ConfigPath = c.Preset, c.ConfigPath
+	*c = *loaded
+	return nil
+}
+
+// SaveSetting writes one setting into the JSON file at path, keeping the
+// others, or creates the file with just that setting. The keys are written
+// back in alphabetical order. The file is replaced in one step so a reload
+// never reads it half written
+func SaveSetting(path, name string, value interface{}) error {
+	settings := map[string]json.RawMessage{}
+	data, err := os.ReadFile(path)
+	switch {
+	case err == nil:
+		if err := json.Unmarshal(data, &settings); err != nil {
+			return fmt.Errorf("invalid JSON: %v", err)
+		}
+	case !errors.Is(err, fs.ErrNotExist):
+		return err
+	}
+	if settings == nil { // The file held null
+		settings = map[string]json.RawMessage{}
+	}
+	encoded, err := json.Marshal(value)
+	if err != nil {
+		return err
+	}
+	settings[name] = encoded
+	data, err = json.MarshalIndent(settings, "", "  ")
+	if err != nil {
+		return err
+	}
+
+	mode := fs.FileMode(0o644)
+	if info, err := os.Stat(path); err == nil {
+		mode = info.Mode().Perm()
+	}
+	temp, err := os.CreateTemp(filepath.Dir(path), filepath.Base(path)+".*")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(temp.Name())
+	if err := temp.Chmod(mode); err != nil {
+		temp.Close()
+		return err
+	}
+	if _, err := temp.Write(append(data, '\n')); err != nil {
+		temp.Close()
+		return err
+	}
+	if err := temp.Close(); err != nil {
+		return err
+	}
+	return os.Rename(temp.Name(), path)
+}
+
+// LiveFields lists the settings a running simulation picks up when the
+// config file changes; the others need a restart
+var LiveFields = []string{
+	"GravitationalConstant",
+	"GridVisScale",
+	"MoveSpeed",
+	"MouseSensitivity",
+	"ParticleColoring",
+	"GridColoring",
+	"FlowInterval",
+	"DisplayScale",
+	"MinDisplayRadius",
+	"Culling",
+	"CullPixels",
+	"RenderBudget",
+	"TargetFPS",
+	"VSync",
+	"IdleFPS",
+	"ShowPlots",
+	"ShowPhaseSpace",
+	"ShowProfiles",
+	"ShowRenderStats",
+	"MeshPath",
+	"MeshParticles",
+	"Overlay",
+	"OverlayLabel",
+	"PreviewPhysics",
+	"Bookmarks",
+	"Explain",
+	"ExplainStageDuration",
+}
+
+// Reload is the outcome of a config file change
+type Reload struct {
+	Applied []string // Settings that changed and now apply
+	Restart []string // Settings that changed but only apply after a restart
+}
+
+// Changed reports whether any setting changed
+func (r Reload) Changed() bool {
+	return len(r.Applied) > 0 || len(r.Restart) > 0
+}
+
+// Reload applies the settings that changed between old and next, the
+// configurations loaded before and after the file changed. Live settings are
+// copied into c; settings changed at runtime but not in the file keep their
+// runtime values. The image correction force holds the gravitational
+// constant, so with it G needs a restart
+func (c *Config) Reload(old, next *Config) Reload {
+	var r Reload
+	dst, before, after := reflect.ValueOf(c).Elem(), reflect.ValueOf(old).Elem(), reflect.ValueOf(next).Elem()
+	for i := 0; i < after.NumField(); i++ {
+		name := after.Type().Field(i).Name
+		if reflect.DeepEqual(before.Field(i).Interface(), after.Field(i).Interface()) {
+			continue
+		}
+		if !c.live(name) {
+			r.Restart = append(r.Restart, name)
+			continue
+		}
+		dst.Field(i).Set(after.Field(i))
+		r.Applied = append(r.Applied, name)
+	}
+	return r
+}
+
+// live reports whether the setting called name applies without a restart
+func (c *Config) live(name string) bool {
+	if name == "GravitationalConstant" && c.ImageCorrection {
+		return false
+	}
+	for _, field := range LiveFields {
+		if field == name {
+			return true
+		}
+	}
+	return false
+}
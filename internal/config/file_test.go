@@ -0,0 +1,107 @@
+package config
+
+import (
+	"os"
+	"path/filepath"
+	"slices"
+	"testing"
+)
+
+// TestLoadFile tests layering a JSON file over the configuration
+func TestLoadFile(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "config.json")
+	if err := os.WriteFile(path, []byte(`{"NumParticles": 42, "GridVisScale": 2.5, "Preset": "demo"}`), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	cfg := DefaultConfig()
+	if err := cfg.ApplyPreset("accuracy"); err != nil {
+		t.Fatal(err)
+	}
+	if err := cfg.LoadFile(path); err != nil {
+		t.Fatalf("LoadFile failed: %v", err)
+	}
+	if cfg.NumParticles != 42 || cfg.GridVisScale != 2.5 {
+		t.Errorf("Expected the file's settings, got %d particles and scale %g", cfg.NumParticles, cfg.GridVisScale)
+	}
+	if cfg.Preset != "accuracy" || cfg.Solver != SolverDirect {
+		t.Errorf("Expected the accuracy preset to stay, got %q with solver %q", cfg.Preset, cfg.Solver)
+	}
+
+	if err := os.WriteFile(path, []byte(`{"Particles": 42}`), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	before := *cfg
+	if err := cfg.LoadFile(path); err == nil {
+		t.Error("Expected an error for an unknown setting")
+	}
+	if *cfg != before {
+		t.Error("Expected a failed load to leave the configuration unchanged")
+	}
+}
+
+// TestConfigReload tests applying live settings and listing those needing a restart
+func TestConfigReload(t *testing.T) {
+	old := DefaultConfig()
+	next := old.Clone()
+	next.GravitationalConstant = 2
+	next.TargetFPS = 30
+	next.SimulationWidth = 512
+
+	current := old.Clone()
+	current.ShowPlots = !old.ShowPlots // Toggled at runtime
+	r := current.Reload(old, next)
+	if !slices.Equal(r.Applied, []string{"GravitationalConstant", "TargetFPS"}) || !slices.Equal(r.Restart, []string{"SimulationWidth"}) {
+		t.Errorf("Unexpected reload %+v", r)
+	}
+	if current.GravitationalConstant != 2 || current.TargetFPS != 30 || current.SimulationWidth != old.SimulationWidth {
+		t.Errorf("Expected G and the FPS cap only to apply, got G %g, FPS %d, width %d", current.GravitationalConstant, current.TargetFPS, current.SimulationWidth)
+	}
+	if current.ShowPlots == old.ShowPlots {
+		t.Error("Expected a runtime change the file did not touch to survive the reload")
+	}
+
+	current = old.Clone()
+	current.ImageCorrection = true
+	if r := current.Reload(old, next); slices.Contains(r.Applied, "GravitationalConstant") {
+		t.Error("Expected G to need a restart with image correction")
+	}
+	if r := current.Reload(old, old.Clone()); r.Changed() {
+		t.Errorf("Expected no changes, got %+v", r)
+	}
+}
+
+// TestSaveSetting tests writing a setting into a config file, keeping the
+// others, and creating the file
+func TestSaveSetting(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "config.json")
+	if err := SaveSetting(path, "Bookmarks", "1=0,10,0,0,-1.5"); err != nil {
+		t.Fatalf("SaveSetting failed to create the file: %v", err)
+	}
+	if err := os.WriteFile(path, []byte(`{"NumParticles": 42, "Bookmarks": ""}`), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.Chmod(path, 0o600); err != nil {
+		t.Fatal(err)
+	}
+	if err := SaveSetting(path, "Bookmarks", "2=1,2,3,0.5,0"); err != nil {
+		t.Fatalf("SaveSetting failed: %v", err)
+	}
+
+	cfg := DefaultConfig()
+	if err := cfg.LoadFile(path); err != nil {
+		t.Fatalf("LoadFile failed: %v", err)
+	}
+	if cfg.NumParticles != 42 || cfg.Bookmarks != "2=1,2,3,0.5,0" {
+		t.Errorf("Expected 42 particles and the saved bookmark, got %d and %q", cfg.NumParticles, cfg.Bookmarks)
+	}
+	if info, err := os.Stat(path); err != nil || info.Mode().Perm() != 0o600 {
+		t.Errorf("Expected the file's permissions kept, got %v (%v)", info.Mode(), err)
+	}
+
+	if err := os.WriteFile(path, []byte(`{"NumParticles": `), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	if err := SaveSetting(path, "Bookmarks", ""); err == nil {
+		t.Error("Expected an error for a file that is not JSON")
+	}
+}
@@ -0,0 +1,33 @@
+package config
+
+import (
+	"encoding/json"
+	"testing"
+)
+
+// FuzzConfigJSON tests that any configuration decoded from JSON, as stored in
+// snapshot CONF blocks, validates without panicking. Run with:
+// go test -fuzz FuzzConfigJSON ./internal/config
+func FuzzConfigJSON(f *testing.F) {
+	defaults, err := json.Marshal(DefaultConfig())
+	if err != nil {
+		f.Fatal(err)
+	}
+	f.Add(defaults)
+	f.Add([]byte(`{"Solver":"direct","NumParticles":100000}`))
+	f.Add([]byte(`{"Headless":true,"FixedTimeStep":-1}`))
+	f.Add([]byte(`{"SimulationWidth":-5}`))
+	f.Add([]byte(`null`))
+
+	f.Fuzz(func(t *testing.T, data []byte) {
+		cfg := DefaultConfig()
+		if err := json.Unmarshal(data, cfg); err != nil {
+			return
+		}
+		_ = cfg.Validate()
+		clone := cfg.Clone()
+		if clone.DirectSolver() != cfg.DirectSolver() {
+			t.Fatal("Clone changed the solver")
+		}
+	})
+}
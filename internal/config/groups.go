@@ -0,0 +1,47 @@
+package config
+
+import (
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+)
+
+// GroupRegion is a circle of the x-z plane whose particles start in a named group
+type GroupRegion struct {
+	Name         string
+	X, Z, Radius float64
+}
+
+// ParseGroupRegions parses GroupRegions: "name=x,z,radius" entries joined by ";"
+func ParseGroupRegions(s string) ([]GroupRegion, error) {
+	var regions []GroupRegion
+	for _, entry := range strings.Split(s, ";") {
+		entry = strings.TrimSpace(entry)
+		if entry == "" {
+			continue
+		}
+		name, circle, ok := strings.Cut(entry, "=")
+		name = strings.TrimSpace(name)
+		if !ok || name == "" {
+			return nil, fmt.Errorf("invalid group region: %q (want name=x,z,radius)", entry)
+		}
+		fields := strings.Split(circle, ",")
+		if len(fields) != 3 {
+			return nil, fmt.Errorf("invalid group region: %q (want name=x,z,radius)", entry)
+		}
+		var values [3]float64
+		for i, field := range fields {
+			v, err := strconv.ParseFloat(strings.TrimSpace(field), 64)
+			if err != nil || math.IsNaN(v) || math.IsInf(v, 0) {
+				return nil, fmt.Errorf("invalid group region: %q (want name=x,z,radius)", entry)
+			}
+			values[i] = v
+		}
+		if values[2] <= 0 {
+			return nil, fmt.Errorf("invalid group region: %q (radius must be positive)", entry)
+		}
+		regions = append(regions, GroupRegion{Name: name, X: values[0], Z: values[1], Radius: values[2]})
+	}
+	return regions, nil
+}
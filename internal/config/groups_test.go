@@ -0,0 +1,20 @@
+package config
+
+import "testing"
+
+// TestParseGroupRegions tests parsing named circles and rejecting malformed ones
+func TestParseGroupRegions(t *testing.T) {
+	regions, err := ParseGroupRegions(" left=-20, 0, 8; right = 20,0,8;")
+	if err != nil {
+		t.Fatalf("ParseGroupRegions failed: %v", err)
+	}
+	want := []GroupRegion{{Name: "left", X: -20, Radius: 8}, {Name: "right", X: 20, Radius: 8}}
+	if len(regions) != len(want) || regions[0] != want[0] || regions[1] != want[1] {
+		t.Errorf("Expected %+v, got %+v", want, regions)
+	}
+	for _, s := range []string{"=1,2,3", "a=1,2", "a=1,2,0", "a=1,x,3", "a"} {
+		if _, err := ParseGroupRegions(s); err == nil {
+			t.Errorf("Expected an error for %q", s)
+		}
+	}
+}
@@ -0,0 +1,60 @@
+package config
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+)
+
+// Scene layers
+const (
+	LayerGrid      = "grid"      // Deformed potential grid
+	LayerContours  = "contours"  // Contour lines of the potential
+	LayerParticles = "particles" // Particle spheres
+	LayerHalos     = "halos"     // Rings around density peaks
+	LayerTrails    = "trails"    // Recent paths of the first particles
+	LayerVectors   = "vectors"   // Arrows of the acceleration field
+	LayerSheet     = "sheet"     // Tracer sheet mesh
+	LayerMarkers   = "markers"   // Scenario masses, markers and the slow motion watch
+	LayerAxes      = "axes"      // Coordinate axes
+	LayerMinimap   = "minimap"   // Top-down map of the domain in a screen corner
+)
+
+// SceneLayers lists every scene layer in its default drawing order. The
+// number keys toggle them by position
+var SceneLayers = []string{
+	LayerGrid, LayerContours, LayerParticles, LayerHalos, LayerTrails,
+	LayerVectors, LayerSheet, LayerMarkers, LayerAxes, LayerMinimap,
+}
+
+// DefaultLayers are the layers shown unless configured otherwise
+const DefaultLayers = "grid,particles,sheet,markers,axes"
+
+// Scene layer setting defaults
+const (
+	DefaultTrailLength    = 64
+	DefaultTrailParticles = 32
+	DefaultVectorStride   = 8
+	DefaultContourLevels  = 12
+	DefaultHaloThreshold  = 20.0
+	DefaultMinimapSize    = 200
+)
+
+// ParseLayers parses a comma-separated list of scene layers, in drawing order
+func ParseLayers(s string) ([]string, error) {
+	var layers []string
+	for _, name := range strings.Split(s, ",") {
+		name = strings.TrimSpace(name)
+		if name == "" {
+			continue
+		}
+		if !slices.Contains(SceneLayers, name) {
+			return nil, fmt.Errorf("invalid layer: %q (want %s)", name, strings.Join(SceneLayers, ", "))
+		}
+		if slices.Contains(layers, name) {
+			return nil, fmt.Errorf("invalid layers: %q is listed twice", name)
+		}
+		layers = append(layers, name)
+	}
+	return layers, nil
+}
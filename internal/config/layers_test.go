@@ -0,0 +1,23 @@
+package config
+
+import "testing"
+
+// TestParseLayers tests parsing layer lists in order and rejecting unknown
+// or repeated layers
+func TestParseLayers(t *testing.T) {
+	layers, err := ParseLayers(" trails, grid ,,minimap")
+	if err != nil {
+		t.Fatalf("ParseLayers failed: %v", err)
+	}
+	if len(layers) != 3 || layers[0] != LayerTrails || layers[1] != LayerGrid || layers[2] != LayerMinimap {
+		t.Errorf("Expected trails, grid, minimap, got %v", layers)
+	}
+	if _, err := ParseLayers(DefaultLayers); err != nil {
+		t.Errorf("Expected the default layers to parse: %v", err)
+	}
+	for _, s := range []string{"grid,stars", "grid,grid"} {
+		if _, err := ParseLayers(s); err == nil {
+			t.Errorf("Expected an error for %q", s)
+		}
+	}
+}
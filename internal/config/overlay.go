@@ -0,0 +1,61 @@
+package config
+
+import (
+	"fmt"
+	"strings"
+)
+
+// Frame overlay items
+const (
+	OverlayTime   = "time"   // Simulation time
+	OverlayStep   = "step"   // Step number
+	OverlayParams = "params" // Summary of the run's parameters
+)
+
+// ParseOverlay parses Overlay: item names joined by ",", each at most once
+func ParseOverlay(s string) ([]string, error) {
+	var items []string
+	for _, item := range strings.Split(s, ",") {
+		item = strings.TrimSpace(item)
+		if item == "" {
+			continue
+		}
+		switch item {
+		case OverlayTime, OverlayStep, OverlayParams:
+		default:
+			return nil, fmt.Errorf("invalid overlay item: %q (want %s, %s or %s)", item, OverlayTime, OverlayStep, OverlayParams)
+		}
+		for _, seen := range items {
+			if seen == item {
+				return nil, fmt.Errorf("invalid overlay: %q is listed twice", item)
+			}
+		}
+		items = append(items, item)
+	}
+	return items, nil
+}
+
+// Summary returns a one-line summary of the settings that define a run, for
+// frame overlays
+func (c *Config) Summary() string {
+	scenario, solver := c.Scenario, c.Solver
+	if scenario == "" {
+		scenario = ScenarioRandom
+	}
+	if solver == "" {
+		solver = SolverPM
+	}
+	parts := []string{
+		scenario,
+		fmt.Sprintf("%d particles", c.NumParticles),
+		fmt.Sprintf("%s solver on %dx%d", solver, c.SimulationWidth, c.SimulationDepth),
+		fmt.Sprintf("G %g", c.GravitationalConstant),
+	}
+	if c.DirectSolver() {
+		parts = append(parts, fmt.Sprintf("softening %g", c.Softening))
+	}
+	if c.Seed != 0 {
+		parts = append(parts, fmt.Sprintf("seed %d", c.Seed))
+	}
+	return strings.Join(parts, ", ")
+}
@@ -0,0 +1,38 @@
+package config
+
+import "testing"
+
+// TestParseOverlay tests parsing overlay items and rejecting unknown or repeated ones
+func TestParseOverlay(t *testing.T) {
+	items, err := ParseOverlay(" step, time ,")
+	if err != nil {
+		t.Fatalf("ParseOverlay failed: %v", err)
+	}
+	if len(items) != 2 || items[0] != OverlayStep || items[1] != OverlayTime {
+		t.Errorf("Expected [step time], got %v", items)
+	}
+	for _, s := range []string{"clock", "time,time"} {
+		if _, err := ParseOverlay(s); err == nil {
+			t.Errorf("Expected an error for %q", s)
+		}
+	}
+}
+
+// TestSummary tests the one-line parameter summary
+func TestSummary(t *testing.T) {
+	c := DefaultConfig()
+	c.NumParticles = 500
+	c.SimulationWidth, c.SimulationDepth = 128, 64
+	c.GravitationalConstant = 2
+	c.Seed = 0
+	if got, want := c.Summary(), "random, 500 particles, pm solver on 128x64, G 2"; got != want {
+		t.Errorf("Expected %q, got %q", want, got)
+	}
+
+	c.Solver = SolverDirect
+	c.Softening = 0.5
+	c.Seed = 42
+	if got, want := c.Summary(), "random, 500 particles, direct solver on 128x64, G 2, softening 0.5, seed 42"; got != want {
+		t.Errorf("Expected %q, got %q", want, got)
+	}
+}
@@ -0,0 +1,125 @@
+package config
+
+import (
+	"fmt"
+	"strings"
+)
+
+// Preset is a named set of settings layered over the defaults, for new users
+// who want a sensible configuration without learning every parameter
+type Preset struct {
+	Name        string
+	Description string
+	apply       func(c *Config)
+}
+
+// Presets lists the built-in presets in the order F8 cycles through them
+var Presets = []Preset{
+	{
+		Name:        "demo",
+		Description: "2000 particles colored by density with the live plots, adapting quality to the machine",
+		apply: func(c *Config) {
+			c.NumParticles = 2000
+			c.ParticleColoring = ParticleColoringDensity
+			c.ShowPlots = true
+			c.AdaptiveQuality = true
+		},
+	},
+	{
+		Name:        "accuracy",
+		Description: "1000 particles on the direct solver with light softening, short fixed steps and bit-identical runs of seed 1",
+		apply: func(c *Config) {
+			c.NumParticles = 1000
+			c.Seed = 1
+			c.Solver = SolverDirect
+			c.Softening = 0.1
+			c.Precision = PrecisionFloat64
+			c.Deterministic = true
+			c.FixedTimeStep = 1.0 / 240.0
+		},
+	},
+	{
+		Name:        "performance",
+		Description: "50000 particles in float32 on the GPU, uncapped, adapting quality to the machine",
+		apply: func(c *Config) {
+			c.NumParticles = 50000
+			c.Precision = PrecisionFloat32
+			c.UseGPU = true
+			c.TargetFPS = 0
+			c.VSync = false
+			c.AdaptiveQuality = true
+		},
+	},
+	{
+		Name:        "big-grid",
+		Description: "A 1024x1024 grid with 100000 particles in float32, on huge pages where available",
+		apply: func(c *Config) {
+			c.SimulationWidth = 1024
+			c.SimulationDepth = 1024
+			c.NumParticles = 100000
+			c.Precision = PrecisionFloat32
+			c.HugePages = true
+			c.AdaptiveQuality = true
+		},
+	},
+	{
+		Name:        "black-hole",
+		Description: "A 2000-particle cluster torn apart on a plunging orbit past a massive host, colored by binding",
+		apply: func(c *Config) {
+			c.Scenario = ScenarioTidalDisruption
+			c.NumParticles = 2000
+			c.ParticleColoring = ParticleColoringBinding
+			c.GridVisScale = 0.2
+		},
+	},
+}
+
+// LookupPreset returns the preset called name. Case, dashes and underscores
+// are ignored, so "BigGrid" names big-grid
+func LookupPreset(name string) (Preset, bool) {
+	key := presetKey(name)
+	for _, p := range Presets {
+		if presetKey(p.Name) == key {
+			return p, true
+		}
+	}
+	return Preset{}, false
+}
+
+// PresetNames returns the names of the built-in presets
+func PresetNames() []string {
+	names := make([]string, len(Presets))
+	for i, p := range Presets {
+		names[i] = p.Name
+	}
+	return names
+}
+
+// NextPreset returns the name of the preset after name, wrapping around;
+// after "" or an unknown name it is the first
+func NextPreset(name string) string {
+	key := presetKey(name)
+	for i, p := range Presets {
+		if presetKey(p.Name) == key {
+			return Presets[(i+1)%len(Presets)].Name
+		}
+	}
+	return Presets[0].Name
+}
+
+// ApplyPreset layers the preset called name over the configuration and
+// records it in Preset
+func (c *Config) ApplyPreset(name string) error {
+	p, ok := LookupPreset(name)
+	if !ok {
+		return fmt.Errorf("invalid preset: %q (want %s)", name, strings.Join(PresetNames(), ", "))
+	}
+	p.apply(c)
+	c.Preset = p.Name
+	return nil
+}
+
+// presetKey normalizes a preset name for lookup
+func presetKey(name string) string {
+	return strings.NewReplacer("-", "", "_", "").Replace(strings.ToLower(name))
+}
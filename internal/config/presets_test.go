@@ -0,0 +1,44 @@
+package config
+
+import "testing"
+
+// TestPresetsValidate tests that every preset layered over the defaults is valid
+func TestPresetsValidate(t *testing.T) {
+	for _, name := range PresetNames() {
+		cfg := DefaultConfig()
+		if err := cfg.ApplyPreset(name); err != nil {
+			t.Fatalf("ApplyPreset(%q) failed: %v", name, err)
+		}
+		if err := cfg.Validate(); err != nil {
+			t.Errorf("Preset %q does not validate: %v", name, err)
+		}
+		if cfg.Preset != name {
+			t.Errorf("Expected Preset %q, got %q", name, cfg.Preset)
+		}
+	}
+}
+
+// TestLookupPreset tests that lookups ignore case, dashes and underscores
+func TestLookupPreset(t *testing.T) {
+	for _, name := range []string{"BigGrid", "big_grid", "BIG-GRID"} {
+		if p, ok := LookupPreset(name); !ok || p.Name != "big-grid" {
+			t.Errorf("LookupPreset(%q) = %q, %v; want big-grid", name, p.Name, ok)
+		}
+	}
+	if err := DefaultConfig().ApplyPreset("galaxy"); err == nil {
+		t.Error("Expected an error for an unknown preset")
+	}
+}
+
+// TestNextPreset tests cycling through the presets
+func TestNextPreset(t *testing.T) {
+	if got := NextPreset(""); got != Presets[0].Name {
+		t.Errorf("Expected %q after none, got %q", Presets[0].Name, got)
+	}
+	if got := NextPreset("Demo"); got != Presets[1].Name {
+		t.Errorf("Expected %q after demo, got %q", Presets[1].Name, got)
+	}
+	if got := NextPreset(Presets[len(Presets)-1].Name); got != Presets[0].Name {
+		t.Errorf("Expected the cycle to wrap to %q, got %q", Presets[0].Name, got)
+	}
+}
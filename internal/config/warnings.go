@@ -0,0 +1,79 @@
+package config
+
+import (
+	"fmt"
+	"math/bits"
+)
+
+// Thresholds of the configuration warnings
+const (
+	MinGridCells        = 16      // Grid cells per side below which PM forces are smoothed over much of the domain
+	MaxParticlesPerCell = 16      // Mean particles per PM cell above which the grid is too coarse for them
+	sparseGridCells     = 1 << 20 // Grid cells from which a mostly empty grid suggests SparseGrid
+	sparseOccupancy     = 1024    // Cells per particle above which the grid counts as mostly empty
+)
+
+// Warnings returns the problems with a valid configuration that slow the
+// run down or make it less accurate than intended, each with what to change.
+// Call it after Validate
+func (c *Config) Warnings() []string {
+	var warnings []string
+	pm := !c.DirectSolver()
+	gpu := c.Compute() == ComputeGPU
+
+	if pm && (!powerOfTwo(c.SimulationWidth) || !powerOfTwo(c.SimulationDepth)) && !(gpu && c.GPUBackend == GPUBackendCUDA) {
+		path := "the CPU FFT falls back from its cached radix-2 plans to a slower, allocating transform"
+		if gpu {
+			path = "the OpenGL FFT cannot solve it and the Poisson solve runs on the CPU's slower Bluestein FFT instead"
+		}
+		warnings = append(warnings, fmt.Sprintf("%dx%d grid is not a power of two, so %s: use -width %d -depth %d",
+			c.SimulationWidth, c.SimulationDepth, path, nearestPowerOfTwo(c.SimulationWidth), nearestPowerOfTwo(c.SimulationDepth)))
+	}
+	if pm && min(c.SimulationWidth, c.SimulationDepth) < MinGridCells {
+		warnings = append(warnings, fmt.Sprintf("%dx%d grid smooths PM forces over a large part of the domain: use at least %d cells per side",
+			c.SimulationWidth, c.SimulationDepth, MinGridCells))
+	}
+	cells := c.SimulationWidth * c.SimulationDepth
+	if pm && c.NumParticles > MaxParticlesPerCell*cells {
+		warnings = append(warnings, fmt.Sprintf("%d particles on a %dx%d grid is %.0f per cell, and PM forces are smoothed over a cell: use a larger grid or, for up to %d particles, -solver %s",
+			c.NumParticles, c.SimulationWidth, c.SimulationDepth, float64(c.NumParticles)/float64(cells), MaxDirectParticles, SolverDirect))
+	}
+	if pm && !c.SparseGrid && cells >= sparseGridCells && c.NumParticles*sparseOccupancy < cells {
+		warnings = append(warnings, fmt.Sprintf("%d particles leave most of the %dx%d grid empty: -sparse-grid skips the empty tiles",
+			c.NumParticles, c.SimulationWidth, c.SimulationDepth))
+	}
+	if !pm && c.Precision == PrecisionFloat32 {
+		warnings = append(warnings, fmt.Sprintf("-precision %s sets the PM grid and FFT precision and has no effect on the %s solver",
+			PrecisionFloat32, c.Solver))
+	}
+	if c.Explain && !pm {
+		warnings = append(warnings, fmt.Sprintf("-explain walks through the particle-mesh pipeline, which the %s solver skips: its fields are solved for the annotations only",
+			c.Solver))
+	}
+	if c.Explain && c.Headless {
+		warnings = append(warnings, "-explain annotates the window and has no effect on headless runs")
+	}
+	if c.GPUBackend == GPUBackendCUDA && c.Compute() == ComputeCPU {
+		warnings = append(warnings, fmt.Sprintf("-gpu-backend %s is unused while the CPU computes: use -compute %s or %s",
+			GPUBackendCUDA, ComputeGPU, ComputeAuto))
+	}
+	return warnings
+}
+
+// powerOfTwo reports whether n is a positive power of two
+func powerOfTwo(n int) bool {
+	return n > 0 && n&(n-1) == 0
+}
+
+// nearestPowerOfTwo returns the power of two closest to n, rounding up on ties
+func nearestPowerOfTwo(n int) int {
+	if n <= 1 {
+		return 1
+	}
+	above := 1 << bits.Len(uint(n-1))
+	below := above >> 1
+	if n-below < above-n {
+		return below
+	}
+	return above
+}
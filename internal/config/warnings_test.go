@@ -0,0 +1,73 @@
+package config
+
+import (
+	"strings"
+	"testing"
+)
+
+// TestDefaultConfigWarnings tests that the defaults and presets raise no warnings
+func TestDefaultConfigWarnings(t *testing.T) {
+	if warnings := DefaultConfig().Warnings(); len(warnings) != 0 {
+		t.Errorf("Expected no warnings for the defaults, got %v", warnings)
+	}
+	for _, name := range PresetNames() {
+		cfg := DefaultConfig()
+		_ = cfg.ApplyPreset(name)
+		if warnings := cfg.Warnings(); len(warnings) != 0 {
+			t.Errorf("Expected no warnings for preset %q, got %v", name, warnings)
+		}
+	}
+}
+
+// TestConfigWarnings tests the warnings and the changes they suggest
+func TestConfigWarnings(t *testing.T) {
+	tests := []struct {
+		name   string
+		modify func(c *Config)
+		want   string
+	}{
+		{"non-power-of-two grid", func(c *Config) { c.SimulationWidth, c.SimulationDepth = 200, 300 }, "-width 256 -depth 256"},
+		{"non-power-of-two grid on the GPU", func(c *Config) { c.SimulationWidth = 100; c.ComputeMode = ComputeGPU }, "runs on the CPU's slower Bluestein FFT"},
+		{"small grid", func(c *Config) { c.SimulationWidth, c.SimulationDepth = 8, 8 }, "at least 16 cells"},
+		{"crowded grid", func(c *Config) { c.SimulationWidth, c.SimulationDepth, c.NumParticles = 16, 16, 10000 }, "39 per cell"},
+		{"empty large grid", func(c *Config) { c.SimulationWidth, c.SimulationDepth = 1024, 1024 }, "-sparse-grid"},
+		{"float32 direct solver", func(c *Config) { c.Solver, c.Precision = SolverDirect, PrecisionFloat32 }, "no effect on the direct solver"},
+		{"explain with the direct solver", func(c *Config) { c.Explain, c.Solver = true, SolverDirect }, "fields are solved for the annotations only"},
+		{"explain headless", func(c *Config) { c.Explain, c.Headless = true, true }, "no effect on headless runs"},
+		{"CUDA on the CPU", func(c *Config) { c.GPUBackend, c.ComputeMode = GPUBackendCUDA, ComputeCPU }, "unused while the CPU computes"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cfg := DefaultConfig()
+			tt.modify(cfg)
+			if err := cfg.Validate(); err != nil {
+				t.Fatalf("Validate failed: %v", err)
+			}
+			warnings := strings.Join(cfg.Warnings(), "\n")
+			if !strings.Contains(warnings, tt.want) {
+				t.Errorf("Expected a warning containing %q, got %q", tt.want, warnings)
+			}
+		})
+	}
+}
+
+// TestCUDAGridWarning tests that cuFFT, which takes any size, raises no
+// power-of-two warning
+func TestCUDAGridWarning(t *testing.T) {
+	cfg := DefaultConfig()
+	cfg.SimulationWidth, cfg.ComputeMode, cfg.GPUBackend = 96, ComputeGPU, GPUBackendCUDA
+	for _, warning := range cfg.Warnings() {
+		if strings.Contains(warning, "power of two") {
+			t.Errorf("Expected no power-of-two warning with the CUDA back end, got %q", warning)
+		}
+	}
+}
+
+// TestNearestPowerOfTwo tests rounding grid sizes to powers of two
+func TestNearestPowerOfTwo(t *testing.T) {
+	for n, want := range map[int]int{1: 1, 3: 4, 5: 4, 6: 8, 200: 256, 300: 256, 384: 512, 1000: 1024} {
+		if got := nearestPowerOfTwo(n); got != want {
+			t.Errorf("nearestPowerOfTwo(%d) = %d, want %d", n, got, want)
+		}
+	}
+}
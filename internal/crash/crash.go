@@ -0,0 +1,118 @@
+package crash
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"relativity_simulation_2d/internal/config"
+	"relativity_simulation_2d/internal/physics"
+	"relativity_simulation_2d/internal/snapshot"
+	"runtime"
+	"runtime/debug"
+	"time"
+)
+
+// Report holds everything needed to diagnose a crash after the fact
+type Report struct {
+	Time        time.Time            `json:"time"`
+	Panic       string               `json:"panic"`
+	Stack       string               `json:"stack"`
+	GoVersion   string               `json:"go_version"`
+	Platform    string               `json:"platform"`
+	Config      *config.Config       `json:"config,omitempty"`
+	Diagnostics *physics.Diagnostics `json:"diagnostics,omitempty"`
+	CollectErr  string               `json:"collect_error,omitempty"` // Set if gathering state itself failed
+
+	// Snapshot is written to a separate file next to the report
+	Snapshot     *snapshot.Snapshot `json:"-"`
+	SnapshotFile string             `json:"snapshot_file,omitempty"`
+}
+
+// State holds the simulation state captured at crash time
+type State struct {
+	Config      *config.Config
+	Diagnostics *physics.Diagnostics
+	Snapshot    *snapshot.Snapshot // Optional; nil skips the state dump
+}
+
+// Collector gathers simulation state for a crash report
+type Collector func() State
+
+// NewReport creates a report for the given panic value and stack trace
+func NewReport(panicValue interface{}, stack []byte) *Report {
+	return &Report{
+		Time:      time.Now().UTC(),
+		Panic:     fmt.Sprint(panicValue),
+		Stack:     string(stack),
+		GoVersion: runtime.Version(),
+		Platform:  runtime.GOOS + "/" + runtime.GOARCH,
+	}
+}
+
+// Collect fills the report with simulation state, tolerating a panicking collector
+func (r *Report) Collect(collect Collector) {
+	if collect == nil {
+		return
+	}
+
+	defer func() {
+		if err := recover(); err != nil {
+			r.CollectErr = fmt.Sprintf("state collection panicked: %v", err)
+		}
+	}()
+
+	state := collect()
+	r.Config = state.Config
+	r.Diagnostics = state.Diagnostics
+	r.Snapshot = state.Snapshot
+}
+
+// WriteReport writes the report (and snapshot, if any) to dir and returns the report path
+func WriteReport(dir string, r *Report) (string, error) {
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return "", fmt.Errorf("failed to create crash report directory: %v", err)
+	}
+
+	base := "crash_" + r.Time.Format("20060102_150405")
+
+	if r.Snapshot != nil {
+		snapshotPath := filepath.Join(dir, base+"_state.rsim")
+		if err := snapshot.Save(snapshotPath, r.Snapshot); err != nil {
+			r.CollectErr = fmt.Sprintf("failed to write state snapshot: %v", err)
+		} else {
+			r.SnapshotFile = filepath.Base(snapshotPath)
+		}
+	}
+
+	data, err := json.MarshalIndent(r, "", "  ")
+	if err != nil {
+		return "", fmt.Errorf("failed to encode crash report: %v", err)
+	}
+
+	reportPath := filepath.Join(dir, base+".json")
+	if err := os.WriteFile(reportPath, data, 0o644); err != nil {
+		return "", fmt.Errorf("failed to write crash report: %v", err)
+	}
+
+	return reportPath, nil
+}
+
+// Recover must be deferred directly; on panic it writes a crash report to dir and re-panics
+func Recover(dir string, collect Collector) {
+	r := recover()
+	if r == nil {
+		return
+	}
+
+	report := NewReport(r, debug.Stack())
+	report.Collect(collect)
+
+	if path, err := WriteReport(dir, report); err != nil {
+		fmt.Fprintf(os.Stderr, "Failed to write crash report: %v\n", err)
+	} else {
+		fmt.Fprintf(os.Stderr, "Crash report written to %s\n", path)
+	}
+
+	panic(r)
+}
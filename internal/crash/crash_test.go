@@ -0,0 +1,108 @@
+package crash
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"relativity_simulation_2d/internal/config"
+	"relativity_simulation_2d/internal/physics"
+	"relativity_simulation_2d/internal/snapshot"
+	"strings"
+	"testing"
+)
+
+// TestRecoverWritesReport tests that a panic produces a report and is re-raised
+func TestRecoverWritesReport(t *testing.T) {
+	dir := t.TempDir()
+	particles := []*physics.Particle{physics.NewParticle(5.0, 1.0, 0, 1.0, 0, 0, 0)}
+	cfg := config.DefaultConfig()
+
+	collect := func() State {
+		diag := physics.ComputeDiagnostics(particles)
+		return State{
+			Config:      cfg,
+			Diagnostics: &diag,
+			Snapshot:    snapshot.New(cfg, particles, 7, 0.1),
+		}
+	}
+
+	repanicked := func() (value interface{}) {
+		defer func() { value = recover() }()
+		defer Recover(dir, collect)
+		panic("boom")
+	}()
+
+	if repanicked != "boom" {
+		t.Errorf("Expected original panic to be re-raised, got %v", repanicked)
+	}
+
+	reports, _ := filepath.Glob(filepath.Join(dir, "crash_*[0-9].json"))
+	if len(reports) != 1 {
+		t.Fatalf("Expected 1 crash report, found %d", len(reports))
+	}
+
+	data, err := os.ReadFile(reports[0])
+	if err != nil {
+		t.Fatal(err)
+	}
+	var report Report
+	if err := json.Unmarshal(data, &report); err != nil {
+		t.Fatalf("Crash report is not valid JSON: %v", err)
+	}
+
+	if report.Panic != "boom" {
+		t.Errorf("Expected panic message 'boom', got %q", report.Panic)
+	}
+	if !strings.Contains(report.Stack, "TestRecoverWritesReport") {
+		t.Error("Stack trace should include the panicking function")
+	}
+	if report.Config == nil || report.Diagnostics == nil {
+		t.Error("Report should include config and diagnostics")
+	}
+	if report.SnapshotFile == "" {
+		t.Fatal("Report should reference the state snapshot")
+	}
+
+	snap, err := snapshot.Load(filepath.Join(dir, report.SnapshotFile))
+	if err != nil {
+		t.Fatalf("Failed to load crash snapshot: %v", err)
+	}
+	if snap.Step != 7 || len(snap.Particles) != 1 {
+		t.Errorf("Unexpected snapshot contents: step %d, %d particles", snap.Step, len(snap.Particles))
+	}
+}
+
+// TestRecoverNoPanic tests that Recover is a no-op without a panic
+func TestRecoverNoPanic(t *testing.T) {
+	dir := filepath.Join(t.TempDir(), "reports")
+
+	func() {
+		defer Recover(dir, nil)
+	}()
+
+	if _, err := os.Stat(dir); !os.IsNotExist(err) {
+		t.Error("No report directory should be created without a panic")
+	}
+}
+
+// TestCollectPanics tests that a failing collector does not lose the report
+func TestCollectPanics(t *testing.T) {
+	report := NewReport("original", []byte("stack"))
+	report.Collect(func() State {
+		var particles []*physics.Particle
+		_ = particles[3] // Index out of range
+		return State{}
+	})
+
+	if report.CollectErr == "" {
+		t.Error("Expected collection error to be recorded")
+	}
+
+	path, err := WriteReport(t.TempDir(), report)
+	if err != nil {
+		t.Fatalf("WriteReport failed: %v", err)
+	}
+	if _, err := os.Stat(path); err != nil {
+		t.Errorf("Report file missing: %v", err)
+	}
+}
@@ -0,0 +1,157 @@
+// Package cuda runs the particle-mesh pipeline on NVIDIA GPUs: CIC deposit,
+// cuFFT Poisson solve, gradient and the kick/drift particle kernels. The
+// backend needs cgo, the CUDA driver API, NVRTC and cuFFT, so it is only
+// compiled with the cuda build tag; otherwise NewSolver returns ErrNotBuilt
+package cuda
+
+import (
+	"errors"
+	"relativity_simulation_2d/internal/gpu"
+)
+
+// ErrNotBuilt is returned by NewSolver in binaries built without the cuda tag
+var ErrNotBuilt = errors.New("CUDA backend not built (rebuild with -tags cuda)")
+
+// BlockSize is the number of threads per block for every kernel
+const BlockSize = 256
+
+// Processor describes the CUDA backend for processor selection
+func Processor() *gpu.Processor {
+	return &gpu.Processor{Type: gpu.ProcessorTypeCUDA}
+}
+
+// blocks returns the number of blocks that cover n elements
+func blocks(n int) int {
+	return (n + BlockSize - 1) / BlockSize
+}
+
+// Kernel entry points in KernelSource
+const (
+	kernelDeposit     = "deposit_cic"
+	kernelToComplex   = "to_complex"
+	kernelGreens      = "apply_greens"
+	kernelFromComplex = "from_complex"
+	kernelGradient    = "gradient"
+	kernelKick        = "kick"
+	kernelDrift       = "drift"
+)
+
+// KernelSource is the CUDA C source of the kernels, compiled with NVRTC when
+// a Solver is created. Grids are row-major with index i*height + j, as in
+// physics.Grid.Flatten, and every kernel mirrors its CPU counterpart in
+// internal/physics in double precision. Deposition uses atomicAdd on doubles,
+// which needs compute capability 6.0
+const KernelSource = `
+extern "C" {
+
+struct Stencil {
+    int i, j, ni, nj;
+    double fx, fz;
+};
+
+__device__ int wrap_index(int i, int n) {
+    i %= n;
+    return i < 0 ? i + n : i;
+}
+
+// locateCell: grid node i sits at x = i - width/2
+__device__ Stencil locate(double x, double z, int width, int height) {
+    double gx = x + width / 2.0;
+    double gz = z + height / 2.0;
+    double fi = floor(gx);
+    double fj = floor(gz);
+    Stencil s;
+    s.i = wrap_index((int)fi, width);
+    s.j = wrap_index((int)fj, height);
+    s.ni = wrap_index(s.i + 1, width);
+    s.nj = wrap_index(s.j + 1, height);
+    s.fx = gx - fi;
+    s.fz = gz - fj;
+    return s;
+}
+
+__device__ double interpolate(const double* grid, Stencil s, int height) {
+    double v1 = grid[s.i * height + s.j] * (1 - s.fz) + grid[s.i * height + s.nj] * s.fz;
+    double v2 = grid[s.ni * height + s.j] * (1 - s.fz) + grid[s.ni * height + s.nj] * s.fz;
+    return v1 * (1 - s.fx) + v2 * s.fx;
+}
+
+// wrapCoordinate: leaving one side re-enters at the opposite edge
+__device__ double wrap_coordinate(double v, int extent) {
+    double half = extent / 2.0;
+    if (v > half) v = -half;
+    if (v < -half) v = half;
+    return v;
+}
+
+__global__ void deposit_cic(const double2* pos, const double* mass, int n, double* grid, int width, int height) {
+    int p = blockIdx.x * blockDim.x + threadIdx.x;
+    if (p >= n) return;
+    Stencil s = locate(pos[p].x, pos[p].y, width, height);
+    double m = mass[p];
+    atomicAdd(&grid[s.i * height + s.j], m * (1 - s.fx) * (1 - s.fz));
+    atomicAdd(&grid[s.ni * height + s.j], m * s.fx * (1 - s.fz));
+    atomicAdd(&grid[s.i * height + s.nj], m * (1 - s.fx) * s.fz);
+    atomicAdd(&grid[s.ni * height + s.nj], m * s.fx * s.fz);
+}
+
+__global__ void to_complex(const double* density, double2* data, int size) {
+    int k = blockIdx.x * blockDim.x + threadIdx.x;
+    if (k >= size) return;
+    data[k] = make_double2(density[k], 0.0);
+}
+
+// Φ̂(k) = -4πG ρ̂(k) / |k|², dropping the k = 0 mode
+__global__ void apply_greens(double2* data, int width, int height, double g) {
+    int k = blockIdx.x * blockDim.x + threadIdx.x;
+    if (k >= width * height) return;
+    int u = k / height;
+    int v = k % height;
+    double kx = (u > width / 2 ? u - width : u) * (2.0 * 3.14159265358979323846 / width);
+    double kz = (v > height / 2 ? v - height : v) * (2.0 * 3.14159265358979323846 / height);
+    double k2 = kx * kx + kz * kz;
+    if (k2 == 0.0) {
+        data[k] = make_double2(0.0, 0.0);
+        return;
+    }
+    double scale = -4.0 * 3.14159265358979323846 * g / k2;
+    data[k] = make_double2(data[k].x * scale, data[k].y * scale);
+}
+
+// from_complex takes the real part, applying the inverse FFT normalization
+__global__ void from_complex(const double2* data, double* potential, int size, double scale) {
+    int k = blockIdx.x * blockDim.x + threadIdx.x;
+    if (k >= size) return;
+    potential[k] = data[k].x * scale;
+}
+
+__global__ void gradient(const double* phi, double* ax, double* az, int width, int height) {
+    int k = blockIdx.x * blockDim.x + threadIdx.x;
+    if (k >= width * height) return;
+    int i = k / height;
+    int j = k % height;
+    int pi = (i - 1 + width) % width;
+    int ni = (i + 1) % width;
+    int pj = (j - 1 + height) % height;
+    int nj = (j + 1) % height;
+    ax[k] = -(phi[ni * height + j] - phi[pi * height + j]) / 2.0;
+    az[k] = -(phi[i * height + nj] - phi[i * height + pj]) / 2.0;
+}
+
+__global__ void kick(const double2* pos, double2* vel, const double* ax, const double* az, int n, int width, int height, double h) {
+    int p = blockIdx.x * blockDim.x + threadIdx.x;
+    if (p >= n) return;
+    Stencil s = locate(pos[p].x, pos[p].y, width, height);
+    vel[p].x += interpolate(ax, s, height) * h;
+    vel[p].y += interpolate(az, s, height) * h;
+}
+
+__global__ void drift(double2* pos, const double2* vel, int n, int width, int height, double dt) {
+    int p = blockIdx.x * blockDim.x + threadIdx.x;
+    if (p >= n) return;
+    pos[p].x = wrap_coordinate(pos[p].x + vel[p].x * dt, width);
+    pos[p].y = wrap_coordinate(pos[p].y + vel[p].y * dt, height);
+}
+
+}
+`
@@ -0,0 +1,47 @@
+package cuda
+
+import (
+	"relativity_simulation_2d/internal/gpu"
+	"strings"
+	"testing"
+)
+
+// TestKernelSource tests that every entry point the solver loads is defined
+// with C linkage
+func TestKernelSource(t *testing.T) {
+	if !strings.HasPrefix(strings.TrimSpace(KernelSource), `extern "C" {`) {
+		t.Error("Kernels must have C linkage so the driver can find them by name")
+	}
+	for _, name := range []string{kernelDeposit, kernelToComplex, kernelGreens, kernelFromComplex, kernelGradient, kernelKick, kernelDrift} {
+		if !strings.Contains(KernelSource, "__global__ void "+name+"(") {
+			t.Errorf("Kernel %s not defined", name)
+		}
+	}
+	if strings.Count(KernelSource, "{") != strings.Count(KernelSource, "}") {
+		t.Error("Unbalanced braces in kernel source")
+	}
+}
+
+// TestBlocks tests that the launch grid covers every element
+func TestBlocks(t *testing.T) {
+	tests := []struct {
+		n, want int
+	}{
+		{1, 1},
+		{BlockSize, 1},
+		{BlockSize + 1, 2},
+		{256 * 256, 256 * 256 / BlockSize},
+	}
+	for _, tt := range tests {
+		if got := blocks(tt.n); got != tt.want {
+			t.Errorf("blocks(%d) = %d, want %d", tt.n, got, tt.want)
+		}
+	}
+}
+
+// TestProcessor tests that the backend reports the CUDA processor type
+func TestProcessor(t *testing.T) {
+	if got := Processor().GetType(); got != gpu.ProcessorTypeCUDA {
+		t.Errorf("Processor type = %v, want %v", got, gpu.ProcessorTypeCUDA)
+	}
+}
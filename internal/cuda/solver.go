@@ -0,0 +1,460 @@
+//go:build cuda
+
+package cuda
+
+/*
+#cgo CFLAGS: -I/usr/local/cuda/include
+#cgo LDFLAGS: -L/usr/local/cuda/lib64 -lcuda -lnvrtc -lcufft
+#include <stdlib.h>
+#include <cuda.h>
+#include <nvrtc.h>
+#include <cufft.h>
+
+// compileProgram compiles src to PTX with NVRTC. The caller frees *ptx and *log
+static nvrtcResult compileProgram(const char* src, const char* arch, char** ptx, char** log) {
+	nvrtcProgram prog;
+	nvrtcResult r = nvrtcCreateProgram(&prog, src, "pm.cu", 0, NULL, NULL);
+	if (r != NVRTC_SUCCESS) return r;
+	const char* opts[] = {arch};
+	r = nvrtcCompileProgram(prog, 1, opts);
+
+	size_t logSize;
+	nvrtcGetProgramLogSize(prog, &logSize);
+	*log = (char*)malloc(logSize);
+	nvrtcGetProgramLog(prog, *log);
+
+	if (r == NVRTC_SUCCESS) {
+		size_t ptxSize;
+		nvrtcGetPTXSize(prog, &ptxSize);
+		*ptx = (char*)malloc(ptxSize);
+		nvrtcGetPTX(prog, *ptx);
+	}
+	nvrtcDestroyProgram(&prog);
+	return r;
+}
+
+static cufftResult execZ2Z(cufftHandle plan, CUdeviceptr data, int direction) {
+	return cufftExecZ2Z(plan, (cufftDoubleComplex*)data, (cufftDoubleComplex*)data, direction);
+}
+
+// Kernel launchers build the argument arrays on the C side, since cgo forbids
+// passing Go memory that holds Go pointers
+
+static CUresult launchDeposit(CUfunction f, unsigned int grid, CUdeviceptr pos, CUdeviceptr mass, int n, CUdeviceptr out, int width, int height) {
+	void* args[] = {&pos, &mass, &n, &out, &width, &height};
+	return cuLaunchKernel(f, grid, 1, 1, 256, 1, 1, 0, 0, args, NULL);
+}
+
+static CUresult launchToComplex(CUfunction f, unsigned int grid, CUdeviceptr density, CUdeviceptr data, int size) {
+	void* args[] = {&density, &data, &size};
+	return cuLaunchKernel(f, grid, 1, 1, 256, 1, 1, 0, 0, args, NULL);
+}
+
+static CUresult launchGreens(CUfunction f, unsigned int grid, CUdeviceptr data, int width, int height, double g) {
+	void* args[] = {&data, &width, &height, &g};
+	return cuLaunchKernel(f, grid, 1, 1, 256, 1, 1, 0, 0, args, NULL);
+}
+
+static CUresult launchFromComplex(CUfunction f, unsigned int grid, CUdeviceptr data, CUdeviceptr potential, int size, double scale) {
+	void* args[] = {&data, &potential, &size, &scale};
+	return cuLaunchKernel(f, grid, 1, 1, 256, 1, 1, 0, 0, args, NULL);
+}
+
+static CUresult launchGradient(CUfunction f, unsigned int grid, CUdeviceptr phi, CUdeviceptr ax, CUdeviceptr az, int width, int height) {
+	void* args[] = {&phi, &ax, &az, &width, &height};
+	return cuLaunchKernel(f, grid, 1, 1, 256, 1, 1, 0, 0, args, NULL);
+}
+
+static CUresult launchKick(CUfunction f, unsigned int grid, CUdeviceptr pos, CUdeviceptr vel, CUdeviceptr ax, CUdeviceptr az, int n, int width, int height, double h) {
+	void* args[] = {&pos, &vel, &ax, &az, &n, &width, &height, &h};
+	return cuLaunchKernel(f, grid, 1, 1, 256, 1, 1, 0, 0, args, NULL);
+}
+
+static CUresult launchDrift(CUfunction f, unsigned int grid, CUdeviceptr pos, CUdeviceptr vel, int n, int width, int height, double dt) {
+	void* args[] = {&pos, &vel, &n, &width, &height, &dt};
+	return cuLaunchKernel(f, grid, 1, 1, 256, 1, 1, 0, 0, args, NULL);
+}
+*/
+import "C"
+
+import (
+	"fmt"
+	"relativity_simulation_2d/internal/physics"
+	"unsafe"
+)
+
+// Built reports whether the CUDA backend is compiled in
+const Built = true
+
+// Solver is the CUDA particle-mesh backend for a fixed grid size. It keeps
+// the grids on the device; particles are uploaded and downloaded every step
+// because the rest of the simulation owns them. It must be used from a
+// single goroutine
+type Solver struct {
+	width, height int
+	cellSize      float64 // Physical size of a cell
+	capacity      int     // Particles the device buffers hold
+	name          string
+
+	device  C.CUdevice
+	context C.CUcontext
+	module  C.CUmodule
+	plan    C.cufftHandle
+	planned bool // cuFFT handles may be 0, so track the plan separately
+
+	deposit, toComplex, greens, fromComplex, gradient, kick, drift C.CUfunction
+
+	positions, velocities, masses C.CUdeviceptr // double2, double2, double per particle
+	density, potential            C.CUdeviceptr // double per cell
+	accelX, accelZ                C.CUdeviceptr
+	spectrum                      C.CUdeviceptr // double2 per cell
+
+	host []float64 // Staging buffer for uploads and downloads
+}
+
+// NewSolver compiles the kernels on the first CUDA device and allocates the
+// grids and a cuFFT plan for a width×height grid of cells of size dx
+func NewSolver(width, height int, dx float64) (*Solver, error) {
+	if width <= 0 || height <= 0 {
+		return nil, fmt.Errorf("invalid grid size %dx%d", width, height)
+	}
+	if !(dx > 0) {
+		dx = 1
+	}
+	s := &Solver{width: width, height: height, cellSize: dx}
+
+	if err := check(C.cuInit(0), "cuInit"); err != nil {
+		return nil, err
+	}
+	if err := check(C.cuDeviceGet(&s.device, 0), "cuDeviceGet"); err != nil {
+		return nil, err
+	}
+	// The primary context is shared with the runtime API that cuFFT uses
+	if err := check(C.cuDevicePrimaryCtxRetain(&s.context, s.device), "cuDevicePrimaryCtxRetain"); err != nil {
+		return nil, err
+	}
+	if err := check(C.cuCtxSetCurrent(s.context), "cuCtxSetCurrent"); err != nil {
+		_ = s.Close()
+		return nil, err
+	}
+
+	var name [256]C.char
+	C.cuDeviceGetName(&name[0], C.int(len(name)), s.device)
+	s.name = C.GoString(&name[0])
+
+	if err := s.loadKernels(); err != nil {
+		_ = s.Close()
+		return nil, err
+	}
+
+	cells := width * height
+	for _, buf := range []struct {
+		ptr   *C.CUdeviceptr
+		bytes int
+	}{
+		{&s.density, cells * 8},
+		{&s.potential, cells * 8},
+		{&s.accelX, cells * 8},
+		{&s.accelZ, cells * 8},
+		{&s.spectrum, cells * 16},
+	} {
+		if err := check(C.cuMemAlloc(buf.ptr, C.size_t(buf.bytes)), "cuMemAlloc"); err != nil {
+			_ = s.Close()
+			return nil, err
+		}
+	}
+
+	// cuFFT's first dimension is the slowest varying, matching i*height + j
+	if r := C.cufftPlan2d(&s.plan, C.int(width), C.int(height), C.CUFFT_Z2Z); r != C.CUFFT_SUCCESS {
+		_ = s.Close()
+		return nil, fmt.Errorf("cufftPlan2d failed: error %d", int(r))
+	}
+	s.planned = true
+
+	return s, nil
+}
+
+// loadKernels compiles KernelSource for the device and resolves the entry points
+func (s *Solver) loadKernels() error {
+	var major, minor C.int
+	C.cuDeviceGetAttribute(&major, C.CU_DEVICE_ATTRIBUTE_COMPUTE_CAPABILITY_MAJOR, s.device)
+	C.cuDeviceGetAttribute(&minor, C.CU_DEVICE_ATTRIBUTE_COMPUTE_CAPABILITY_MINOR, s.device)
+	if major < 6 {
+		return fmt.Errorf("device %s has compute capability %d.%d, need 6.0 for double atomics", s.name, int(major), int(minor))
+	}
+
+	source := C.CString(KernelSource)
+	defer C.free(unsafe.Pointer(source))
+	arch := C.CString(fmt.Sprintf("--gpu-architecture=compute_%d%d", int(major), int(minor)))
+	defer C.free(unsafe.Pointer(arch))
+
+	var ptx, log *C.char
+	r := C.compileProgram(source, arch, &ptx, &log)
+	if log != nil {
+		defer C.free(unsafe.Pointer(log))
+	}
+	if r != C.NVRTC_SUCCESS {
+		return fmt.Errorf("kernel compilation failed: %s: %s", C.GoString(C.nvrtcGetErrorString(r)), C.GoString(log))
+	}
+	defer C.free(unsafe.Pointer(ptx))
+
+	if err := check(C.cuModuleLoadData(&s.module, unsafe.Pointer(ptx)), "cuModuleLoadData"); err != nil {
+		return err
+	}
+	for _, k := range []struct {
+		fn   *C.CUfunction
+		name string
+	}{
+		{&s.deposit, kernelDeposit},
+		{&s.toComplex, kernelToComplex},
+		{&s.greens, kernelGreens},
+		{&s.fromComplex, kernelFromComplex},
+		{&s.gradient, kernelGradient},
+		{&s.kick, kernelKick},
+		{&s.drift, kernelDrift},
+	} {
+		name := C.CString(k.name)
+		err := check(C.cuModuleGetFunction(k.fn, s.module, name), "cuModuleGetFunction "+k.name)
+		C.free(unsafe.Pointer(name))
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Name returns the device name
+func (s *Solver) Name() string {
+	return s.name
+}
+
+// Step performs one Kick-Drift-Kick step on the device, equivalent to
+// physics.RunTimeEvolution up to rounding and the summation order of the deposit.
+// The kernels work in cells: positions and velocities are divided by the
+// cell size on upload, which leaves the potential of the cell masses
+// unchanged and scales the kicks by 1/dx²
+func (s *Solver) Step(particles []*physics.Particle, dt float32, gravitationalConstant float64) error {
+	n := len(particles)
+	if n == 0 {
+		return nil
+	}
+	dx := s.cellSize
+	if err := s.upload(particles, dx); err != nil {
+		return err
+	}
+
+	forceCorrectionFactor := float32(0.5)
+	halfKick := float64(dt*0.5) * float64(forceCorrectionFactor) / (dx * dx)
+	grid := C.uint(blocks(n))
+	steps := []func() error{
+		func() error { return s.forces(n, gravitationalConstant) },
+		func() error {
+			return check(C.launchKick(s.kick, grid, s.positions, s.velocities, s.accelX, s.accelZ, C.int(n), C.int(s.width), C.int(s.height), C.double(halfKick)), "kick")
+		},
+		func() error {
+			return check(C.launchDrift(s.drift, grid, s.positions, s.velocities, C.int(n), C.int(s.width), C.int(s.height), C.double(dt)), "drift")
+		},
+		func() error { return s.forces(n, gravitationalConstant) },
+		func() error {
+			return check(C.launchKick(s.kick, grid, s.positions, s.velocities, s.accelX, s.accelZ, C.int(n), C.int(s.width), C.int(s.height), C.double(halfKick)), "kick")
+		},
+	}
+	for _, step := range steps {
+		if err := step(); err != nil {
+			return err
+		}
+	}
+
+	return s.download(particles, dx)
+}
+
+// SolvePoisson solves ∇²Φ = 4πGρ for massGrid on the device and writes Φ
+// into potentialGrid, like physics.SolvePoissonFFTInto
+func (s *Solver) SolvePoisson(potentialGrid, massGrid physics.Grid, gravitationalConstant float64) error {
+	cells := s.width * s.height
+	s.host = massGrid.Flatten(s.host)
+	if err := check(C.cuMemcpyHtoD(s.density, unsafe.Pointer(&s.host[0]), C.size_t(cells*8)), "upload density"); err != nil {
+		return err
+	}
+	if err := s.solve(gravitationalConstant); err != nil {
+		return err
+	}
+	return s.readGrid(potentialGrid, s.potential, 1)
+}
+
+// Fields downloads the mass, potential and acceleration grids of the last
+// force evaluation, with the accelerations converted from cells to physical units
+func (s *Solver) Fields(massGrid, potentialGrid physics.Grid, forceField *physics.ForceField) error {
+	perCell := 1 / s.cellSize
+	for _, g := range []struct {
+		grid  physics.Grid
+		ptr   C.CUdeviceptr
+		scale float64
+	}{
+		{massGrid, s.density, 1},
+		{potentialGrid, s.potential, 1},
+		{forceField.AccelFieldX, s.accelX, perCell},
+		{forceField.AccelFieldZ, s.accelZ, perCell},
+	} {
+		if err := s.readGrid(g.grid, g.ptr, g.scale); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Close releases the device buffers, kernels, FFT plan and context
+func (s *Solver) Close() error {
+	if s.planned {
+		C.cufftDestroy(s.plan)
+		s.planned = false
+	}
+	for _, ptr := range []*C.CUdeviceptr{&s.positions, &s.velocities, &s.masses, &s.density, &s.potential, &s.accelX, &s.accelZ, &s.spectrum} {
+		if *ptr != 0 {
+			C.cuMemFree(*ptr)
+			*ptr = 0
+		}
+	}
+	if s.module != nil {
+		C.cuModuleUnload(s.module)
+		s.module = nil
+	}
+	if s.context != nil {
+		C.cuDevicePrimaryCtxRelease(s.device)
+		s.context = nil
+	}
+	return nil
+}
+
+// forces deposits the particles and computes the acceleration grids
+func (s *Solver) forces(n int, gravitationalConstant float64) error {
+	cells := s.width * s.height
+	if err := check(C.cuMemsetD8(s.density, 0, C.size_t(cells*8)), "clear density"); err != nil {
+		return err
+	}
+	if err := check(C.launchDeposit(s.deposit, C.uint(blocks(n)), s.positions, s.masses, C.int(n), s.density, C.int(s.width), C.int(s.height)), "deposit"); err != nil {
+		return err
+	}
+	if err := s.solve(gravitationalConstant); err != nil {
+		return err
+	}
+	return check(C.launchGradient(s.gradient, C.uint(blocks(cells)), s.potential, s.accelX, s.accelZ, C.int(s.width), C.int(s.height)), "gradient")
+}
+
+// solve computes the potential grid from the density grid
+func (s *Solver) solve(gravitationalConstant float64) error {
+	cells := s.width * s.height
+	grid := C.uint(blocks(cells))
+	if err := check(C.launchToComplex(s.toComplex, grid, s.density, s.spectrum, C.int(cells)), "to_complex"); err != nil {
+		return err
+	}
+	if r := C.execZ2Z(s.plan, s.spectrum, C.CUFFT_FORWARD); r != C.CUFFT_SUCCESS {
+		return fmt.Errorf("forward FFT failed: error %d", int(r))
+	}
+	if err := check(C.launchGreens(s.greens, grid, s.spectrum, C.int(s.width), C.int(s.height), C.double(gravitationalConstant)), "apply_greens"); err != nil {
+		return err
+	}
+	if r := C.execZ2Z(s.plan, s.spectrum, C.CUFFT_INVERSE); r != C.CUFFT_SUCCESS {
+		return fmt.Errorf("inverse FFT failed: error %d", int(r))
+	}
+	// cuFFT leaves the inverse transform unnormalized
+	return check(C.launchFromComplex(s.fromComplex, grid, s.spectrum, s.potential, C.int(cells), C.double(1/float64(cells))), "from_complex")
+}
+
+// upload copies positions and velocities in cells of size dx, and masses, to
+// the device, growing the particle buffers if needed
+func (s *Solver) upload(particles []*physics.Particle, dx float64) error {
+	n := len(particles)
+	if n > s.capacity {
+		for _, buf := range []struct {
+			ptr   *C.CUdeviceptr
+			bytes int
+		}{
+			{&s.positions, n * 16},
+			{&s.velocities, n * 16},
+			{&s.masses, n * 8},
+		} {
+			if *buf.ptr != 0 {
+				C.cuMemFree(*buf.ptr)
+				*buf.ptr = 0
+			}
+			if err := check(C.cuMemAlloc(buf.ptr, C.size_t(buf.bytes)), "cuMemAlloc"); err != nil {
+				s.capacity = 0
+				return err
+			}
+		}
+		s.capacity = n
+	}
+
+	s.host = s.staging(2 * n)
+	for i, p := range particles {
+		s.host[2*i], s.host[2*i+1] = p.Position.X/dx, p.Position.Z/dx
+	}
+	if err := check(C.cuMemcpyHtoD(s.positions, unsafe.Pointer(&s.host[0]), C.size_t(n*16)), "upload positions"); err != nil {
+		return err
+	}
+	for i, p := range particles {
+		s.host[2*i], s.host[2*i+1] = p.Velocity.X/dx, p.Velocity.Z/dx
+	}
+	if err := check(C.cuMemcpyHtoD(s.velocities, unsafe.Pointer(&s.host[0]), C.size_t(n*16)), "upload velocities"); err != nil {
+		return err
+	}
+	for i, p := range particles {
+		s.host[i] = float64(p.Mass)
+	}
+	return check(C.cuMemcpyHtoD(s.masses, unsafe.Pointer(&s.host[0]), C.size_t(n*8)), "upload masses")
+}
+
+// download copies positions and velocities back into the particles,
+// converting them from cells of size dx
+func (s *Solver) download(particles []*physics.Particle, dx float64) error {
+	n := len(particles)
+	s.host = s.staging(2 * n)
+	if err := check(C.cuMemcpyDtoH(unsafe.Pointer(&s.host[0]), s.positions, C.size_t(n*16)), "download positions"); err != nil {
+		return err
+	}
+	for i, p := range particles {
+		p.Position.X, p.Position.Z = s.host[2*i]*dx, s.host[2*i+1]*dx
+	}
+	if err := check(C.cuMemcpyDtoH(unsafe.Pointer(&s.host[0]), s.velocities, C.size_t(n*16)), "download velocities"); err != nil {
+		return err
+	}
+	for i, p := range particles {
+		p.Velocity.X, p.Velocity.Z = s.host[2*i]*dx, s.host[2*i+1]*dx
+	}
+	return nil
+}
+
+// readGrid downloads a device grid into grid, multiplied by scale
+func (s *Solver) readGrid(grid physics.Grid, ptr C.CUdeviceptr, scale float64) error {
+	cells := s.width * s.height
+	s.host = s.staging(cells)
+	if err := check(C.cuMemcpyDtoH(unsafe.Pointer(&s.host[0]), ptr, C.size_t(cells*8)), "download grid"); err != nil {
+		return err
+	}
+	if scale != 1 {
+		for i := range s.host {
+			s.host[i] *= scale
+		}
+	}
+	grid.Unflatten(s.host)
+	return nil
+}
+
+// staging returns the staging buffer with room for n values
+func (s *Solver) staging(n int) []float64 {
+	if cap(s.host) < n {
+		return make([]float64, n)
+	}
+	return s.host[:n]
+}
+
+// check converts a driver API result into an error naming the failed call
+func check(r C.CUresult, what string) error {
+	if r == C.CUDA_SUCCESS {
+		return nil
+	}
+	var name *C.char
+	C.cuGetErrorName(r, &name)
+	return fmt.Errorf("%s failed: %s", what, C.GoString(name))
+}
@@ -0,0 +1,44 @@
+//go:build !cuda
+
+package cuda
+
+import (
+	"relativity_simulation_2d/internal/physics"
+)
+
+// Built reports whether the CUDA backend is compiled in
+const Built = false
+
+// Solver is the CUDA particle-mesh backend. Without the cuda build tag it
+// cannot be created
+type Solver struct{}
+
+// NewSolver returns ErrNotBuilt
+func NewSolver(width, height int, dx float64) (*Solver, error) {
+	return nil, ErrNotBuilt
+}
+
+// Name returns an empty device name
+func (s *Solver) Name() string {
+	return ""
+}
+
+// Step returns ErrNotBuilt
+func (s *Solver) Step(particles []*physics.Particle, dt float32, gravitationalConstant float64) error {
+	return ErrNotBuilt
+}
+
+// SolvePoisson returns ErrNotBuilt
+func (s *Solver) SolvePoisson(potentialGrid, massGrid physics.Grid, gravitationalConstant float64) error {
+	return ErrNotBuilt
+}
+
+// Fields returns ErrNotBuilt
+func (s *Solver) Fields(massGrid, potentialGrid physics.Grid, forceField *physics.ForceField) error {
+	return ErrNotBuilt
+}
+
+// Close does nothing
+func (s *Solver) Close() error {
+	return nil
+}
@@ -0,0 +1,23 @@
+//go:build !cuda
+
+package cuda
+
+import (
+	"errors"
+	"testing"
+)
+
+// TestNewSolverNotBuilt tests that binaries without the cuda tag report the
+// missing backend instead of failing later
+func TestNewSolverNotBuilt(t *testing.T) {
+	solver, err := NewSolver(64, 64, 1)
+	if !errors.Is(err, ErrNotBuilt) {
+		t.Errorf("Expected ErrNotBuilt, got %v", err)
+	}
+	if solver != nil {
+		t.Error("Expected no solver")
+	}
+	if Built {
+		t.Error("Built should be false without the cuda tag")
+	}
+}
@@ -0,0 +1,183 @@
+//go:build cuda
+
+package cuda
+
+import (
+	"fmt"
+	"math"
+	"relativity_simulation_2d/internal/physics"
+	"testing"
+)
+
+// newTestSolver creates a solver or skips the test when no device is present
+func newTestSolver(t *testing.T, width, height int) *Solver {
+	t.Helper()
+	solver, err := NewSolver(width, height, 1)
+	if err != nil {
+		t.Skipf("No usable CUDA device: %v", err)
+	}
+	t.Cleanup(func() { _ = solver.Close() })
+	t.Logf("Using %s", solver.Name())
+	return solver
+}
+
+// TestSolvePoissonMatchesCPU tests the cuFFT Poisson solve against the CPU solver
+func TestSolvePoissonMatchesCPU(t *testing.T) {
+	const n = 64
+	solver := newTestSolver(t, n, n)
+
+	particles := physics.InitializeParticlesWithSeed(500, n, n, 3)
+	mass := physics.DepositMassToGrid(particles, n, n, nil)
+	want := physics.SolvePoissonFFT(mass, n, n, 1, 1)
+
+	got := physics.NewGrid(n, n)
+	if err := solver.SolvePoisson(got, mass, 1); err != nil {
+		t.Fatalf("SolvePoisson failed: %v", err)
+	}
+	for i := 0; i < n; i++ {
+		for j := 0; j < n; j++ {
+			if diff := math.Abs(got[i][j] - want[i][j]); diff > 1e-9 {
+				t.Fatalf("Potential at (%d, %d) = %g, CPU %g", i, j, got[i][j], want[i][j])
+			}
+		}
+	}
+}
+
+// TestStepMatchesCPU tests a device step against physics.RunTimeEvolution
+func TestStepMatchesCPU(t *testing.T) {
+	const n = 64
+	solver := newTestSolver(t, n, n)
+
+	particles := physics.InitializeParticlesWithSeed(1000, n, n, 5)
+	reference := make([]*physics.Particle, len(particles))
+	for i, p := range particles {
+		q := *p
+		reference[i] = &q
+	}
+
+	for step := 0; step < 10; step++ {
+		if err := solver.Step(particles, 0.1, 1); err != nil {
+			t.Fatalf("Step failed: %v", err)
+		}
+		physics.RunTimeEvolution(reference, 0.1, n, n, nil, 1).Release()
+	}
+
+	for i, p := range particles {
+		q := reference[i]
+		if d := math.Hypot(p.Position.X-q.Position.X, p.Position.Z-q.Position.Z); d > 1e-6 {
+			t.Fatalf("Particle %d drifted %g cells from the CPU result", i, d)
+		}
+	}
+}
+
+// kernelParity is a device kernel checked against the CPU code it replaces.
+// run computes both on the same particles and returns them flattened in the
+// same order
+type kernelParity struct {
+	name      string
+	tolerance float64 // Largest difference allowed, relative to the largest CPU value
+	run       func(s *Solver, particles []*physics.Particle, width, height int) (device, cpu []float64, err error)
+}
+
+// kernelParities lists every device kernel with its CPU fallback. The grids
+// of a step with dt = 0 are those of the particles' current positions
+var kernelParities = []kernelParity{
+	{name: "deposit", tolerance: 1e-12, run: func(s *Solver, particles []*physics.Particle, width, height int) ([]float64, []float64, error) {
+		mass, potential, field := physics.NewGrid(width, height), physics.NewGrid(width, height), physics.NewForceField(width, height, 1)
+		if err := s.Step(particles, 0, 1); err != nil {
+			return nil, nil, err
+		}
+		if err := s.Fields(mass, potential, field); err != nil {
+			return nil, nil, err
+		}
+		return mass.Flatten(nil), physics.DepositMassToGrid(particles, width, height, nil).Flatten(nil), nil
+	}},
+	{name: "poisson", tolerance: 1e-9, run: func(s *Solver, particles []*physics.Particle, width, height int) ([]float64, []float64, error) {
+		mass := physics.DepositMassToGrid(particles, width, height, nil)
+		potential := physics.NewGrid(width, height)
+		if err := s.SolvePoisson(potential, mass, 1); err != nil {
+			return nil, nil, err
+		}
+		return potential.Flatten(nil), physics.SolvePoissonFFT(mass, width, height, 1, 1).Flatten(nil), nil
+	}},
+	{name: "gradient", tolerance: 1e-9, run: func(s *Solver, particles []*physics.Particle, width, height int) ([]float64, []float64, error) {
+		mass, potential, field := physics.NewGrid(width, height), physics.NewGrid(width, height), physics.NewForceField(width, height, 1)
+		if err := s.Step(particles, 0, 1); err != nil {
+			return nil, nil, err
+		}
+		if err := s.Fields(mass, potential, field); err != nil {
+			return nil, nil, err
+		}
+		want := physics.CalculateGradient(physics.SolvePoissonFFT(physics.DepositMassToGrid(particles, width, height, nil), width, height, 1, 1), width, height, 1)
+		return append(field.AccelFieldX.Flatten(nil), field.AccelFieldZ.Flatten(nil)...),
+			append(want.AccelFieldX.Flatten(nil), want.AccelFieldZ.Flatten(nil)...), nil
+	}},
+	{name: "integration", tolerance: 1e-9, run: func(s *Solver, particles []*physics.Particle, width, height int) ([]float64, []float64, error) {
+		reference := make([]*physics.Particle, len(particles))
+		for i, p := range particles {
+			q := *p
+			reference[i] = &q
+		}
+		if err := s.Step(particles, 0.1, 1); err != nil {
+			return nil, nil, err
+		}
+		physics.RunTimeEvolution(reference, 0.1, width, height, nil, 1).Release()
+		return phaseSpace(particles), phaseSpace(reference), nil
+	}},
+}
+
+// phaseSpace returns the positions and velocities of the particles in the
+// x-z plane
+func phaseSpace(particles []*physics.Particle) []float64 {
+	values := make([]float64, 0, 4*len(particles))
+	for _, p := range particles {
+		values = append(values, p.Position.X, p.Position.Z, p.Velocity.X, p.Velocity.Z)
+	}
+	return values
+}
+
+// relativeError returns the largest difference between got and want relative
+// to the largest magnitude in want
+func relativeError(got, want []float64) float64 {
+	scale := 0.0
+	for _, v := range want {
+		scale = math.Max(scale, math.Abs(v))
+	}
+	if scale == 0 {
+		scale = 1
+	}
+	worst := 0.0
+	for i := range want {
+		diff := math.Abs(got[i]-want[i]) / scale
+		if math.IsNaN(diff) {
+			return math.Inf(1)
+		}
+		worst = math.Max(worst, diff)
+	}
+	return worst
+}
+
+// TestKernelParity runs every device kernel and its CPU fallback on small
+// grids, powers of two and not and rectangular, and checks that they agree
+// to rounding
+func TestKernelParity(t *testing.T) {
+	for _, kernel := range kernelParities {
+		for _, size := range [][2]int{{8, 8}, {16, 8}, {12, 10}, {128, 512}} {
+			width, height := size[0], size[1]
+			t.Run(fmt.Sprintf("%s/%dx%d", kernel.name, width, height), func(t *testing.T) {
+				solver := newTestSolver(t, width, height)
+				particles := physics.InitializeParticlesWithSeed(1000, float64(width), float64(height), 7)
+				device, cpu, err := kernel.run(solver, particles, width, height)
+				if err != nil {
+					t.Fatalf("Device kernel failed: %v", err)
+				}
+				if len(device) != len(cpu) {
+					t.Fatalf("Expected %d values, got %d", len(cpu), len(device))
+				}
+				if diff := relativeError(device, cpu); diff > kernel.tolerance {
+					t.Errorf("Relative difference %.3g exceeds %.3g", diff, kernel.tolerance)
+				}
+			})
+		}
+	}
+}
@@ -0,0 +1,50 @@
+package export
+
+import (
+	"encoding/csv"
+	"os"
+	"path/filepath"
+	"relativity_simulation_2d/internal/physics"
+	"testing"
+)
+
+// TestBinaryLog tests writing formation and disruption rows
+func TestBinaryLog(t *testing.T) {
+	particles := []*physics.Particle{
+		physics.NewParticle(1, -1, 0, 0, 0, 0, -0.25),
+		physics.NewParticle(1, 1, 0, 0, 0, 0, 0.25),
+	}
+	tracker := physics.NewBinaryTracker(physics.BinaryOptions{MaxSeparation: 5, GravitationalConstant: 1})
+
+	path := filepath.Join(t.TempDir(), "binaries.csv")
+	log, err := NewBinaryLog(path)
+	if err != nil {
+		t.Fatalf("NewBinaryLog failed: %v", err)
+	}
+	if err := log.Log(tracker.Update(particles, 10, 1)); err != nil {
+		t.Fatalf("Log failed: %v", err)
+	}
+	particles[1].Velocity.Z = 10
+	if err := log.Log(tracker.Update(particles, 20, 2)); err != nil {
+		t.Fatalf("Log failed: %v", err)
+	}
+	if err := log.Close(); err != nil {
+		t.Fatalf("Close failed: %v", err)
+	}
+
+	file, err := os.Open(path)
+	if err != nil {
+		t.Fatalf("Failed to open binary log: %v", err)
+	}
+	defer file.Close()
+	rows, err := csv.NewReader(file).ReadAll()
+	if err != nil {
+		t.Fatalf("Failed to read binary log: %v", err)
+	}
+	if len(rows) != 3 || len(rows[0]) != len(BinaryLogHeader) {
+		t.Fatalf("Expected a header and 2 events, got %d rows of %d columns", len(rows), len(rows[0]))
+	}
+	if rows[1][0] != "formed" || rows[1][1] != "10" || rows[2][0] != "disrupted" || rows[2][16] != "1" {
+		t.Errorf("Unexpected events %v", rows[1:])
+	}
+}
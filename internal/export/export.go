@@ -0,0 +1,104 @@
+package export
+
+import (
+	"encoding/csv"
+	"fmt"
+	"os"
+	"relativity_simulation_2d/internal/physics"
+	"strconv"
+)
+
+// Record is a single diagnostics sample taken during a run
+type Record struct {
+	Step        int64
+	SimTime     float64
+	Diagnostics physics.Diagnostics
+}
+
+// Exporter writes diagnostics records to an output sink
+type Exporter interface {
+	Export(record Record) error
+	Flush() error
+	Close() error
+}
+
+// CSVHeader is the column header written by CSVExporter
+var CSVHeader = []string{
+	"step", "sim_time", "particles", "total_mass", "kinetic_energy",
+	"momentum_x", "momentum_z", "com_x", "com_z", "max_speed", "non_finite",
+	"gyration_radius", "lagrangian_r10", "lagrangian_r50", "lagrangian_r90",
+	"velocity_anisotropy",
+}
+
+// CSVExporter writes diagnostics records as CSV rows
+type CSVExporter struct {
+	file   *os.File
+	writer *csv.Writer
+}
+
+// NewCSVExporter creates a CSV exporter writing to path, truncating any existing file
+func NewCSVExporter(path string) (*CSVExporter, error) {
+	file, err := os.Create(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create diagnostics file: %v", err)
+	}
+
+	e := &CSVExporter{
+		file:   file,
+		writer: csv.NewWriter(file),
+	}
+	if err := e.writer.Write(CSVHeader); err != nil {
+		_ = file.Close()
+		return nil, fmt.Errorf("failed to write diagnostics header: %v", err)
+	}
+
+	return e, nil
+}
+
+// Export writes one record as a CSV row
+func (e *CSVExporter) Export(record Record) error {
+	d := record.Diagnostics
+	return e.writer.Write([]string{
+		strconv.FormatInt(record.Step, 10),
+		formatFloat(record.SimTime),
+		strconv.Itoa(d.ParticleCount),
+		formatFloat(d.TotalMass),
+		formatFloat(d.KineticEnergy),
+		formatFloat(d.MomentumX),
+		formatFloat(d.MomentumZ),
+		formatFloat(d.CenterOfMass.X),
+		formatFloat(d.CenterOfMass.Z),
+		formatFloat(d.MaxSpeed),
+		strconv.Itoa(d.NonFiniteCount),
+		formatFloat(d.GyrationRadius),
+		formatFloat(d.LagrangianR10),
+		formatFloat(d.LagrangianR50),
+		formatFloat(d.LagrangianR90),
+		formatFloat(d.VelocityAnisotropy),
+	})
+}
+
+// Flush writes any buffered rows to disk
+func (e *CSVExporter) Flush() error {
+	e.writer.Flush()
+	if err := e.writer.Error(); err != nil {
+		return err
+	}
+	return e.file.Sync()
+}
+
+// Close flushes buffered rows and closes the file
+func (e *CSVExporter) Close() error {
+	e.writer.Flush()
+	flushErr := e.writer.Error()
+	closeErr := e.file.Close()
+	if flushErr != nil {
+		return flushErr
+	}
+	return closeErr
+}
+
+// formatFloat formats a float with the shortest exact representation
+func formatFloat(f float64) string {
+	return strconv.FormatFloat(f, 'g', -1, 64)
+}
@@ -0,0 +1,69 @@
+package export
+
+import (
+	"encoding/csv"
+	"os"
+	"path/filepath"
+	"relativity_simulation_2d/internal/physics"
+	"testing"
+)
+
+// TestCSVExporter tests writing diagnostics records to CSV
+func TestCSVExporter(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "diagnostics.csv")
+
+	exporter, err := NewCSVExporter(path)
+	if err != nil {
+		t.Fatalf("Failed to create exporter: %v", err)
+	}
+
+	for step := int64(1); step <= 3; step++ {
+		record := Record{
+			Step:    step,
+			SimTime: float64(step) * 0.5,
+			Diagnostics: physics.Diagnostics{
+				ParticleCount: 10,
+				TotalMass:     250.0,
+				KineticEnergy: float64(step),
+			},
+		}
+		if err := exporter.Export(record); err != nil {
+			t.Fatalf("Export failed: %v", err)
+		}
+	}
+
+	if err := exporter.Flush(); err != nil {
+		t.Fatalf("Flush failed: %v", err)
+	}
+	if err := exporter.Close(); err != nil {
+		t.Fatalf("Close failed: %v", err)
+	}
+
+	file, err := os.Open(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer file.Close()
+
+	rows, err := csv.NewReader(file).ReadAll()
+	if err != nil {
+		t.Fatalf("Output is not valid CSV: %v", err)
+	}
+	if len(rows) != 4 {
+		t.Fatalf("Expected header plus 3 rows, got %d rows", len(rows))
+	}
+	if len(rows[0]) != len(CSVHeader) || rows[0][0] != "step" {
+		t.Errorf("Unexpected header: %v", rows[0])
+	}
+	if rows[3][0] != "3" || rows[3][1] != "1.5" || rows[3][4] != "3" {
+		t.Errorf("Unexpected last row: %v", rows[3])
+	}
+}
+
+// TestCSVExporterBadPath tests exporter creation failure
+func TestCSVExporterBadPath(t *testing.T) {
+	_, err := NewCSVExporter(filepath.Join(t.TempDir(), "missing", "diagnostics.csv"))
+	if err == nil {
+		t.Error("Expected error for unwritable path")
+	}
+}
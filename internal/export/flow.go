@@ -0,0 +1,65 @@
+package export
+
+import (
+	"encoding/csv"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"relativity_simulation_2d/internal/physics"
+	"strconv"
+)
+
+// FlowHeader is the column header written by WriteFlowCSV
+var FlowHeader = []string{"x", "z", "vx", "vz", "divergence", "vorticity", "shear"}
+
+// FlowFileName returns the name of the flow map file for a step
+func FlowFileName(step int64) string {
+	return fmt.Sprintf("flow_%08d.csv", step)
+}
+
+// WriteFlowCSV writes one row per grid node with its position, mean velocity
+// and velocity decomposition. Positions are centered on the origin, as for
+// the particles
+func WriteFlowCSV(w io.Writer, f physics.FlowField) error {
+	writer := csv.NewWriter(w)
+	if err := writer.Write(FlowHeader); err != nil {
+		return err
+	}
+	width, height := f.VelocityX.Width(), f.VelocityX.Height()
+	for i := 0; i < width; i++ {
+		for j := 0; j < height; j++ {
+			row := []string{
+				strconv.Itoa(i - width/2),
+				strconv.Itoa(j - height/2),
+				formatFloat(f.VelocityX[i][j]),
+				formatFloat(f.VelocityZ[i][j]),
+				formatFloat(f.Divergence[i][j]),
+				formatFloat(f.Vorticity[i][j]),
+				formatFloat(f.Shear[i][j]),
+			}
+			if err := writer.Write(row); err != nil {
+				return err
+			}
+		}
+	}
+	writer.Flush()
+	return writer.Error()
+}
+
+// SaveFlowField writes the flow map of a step as FlowFileName(step) in dir,
+// creating dir if needed
+func SaveFlowField(dir string, step int64, f physics.FlowField) error {
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return fmt.Errorf("failed to create flow directory: %v", err)
+	}
+	file, err := os.Create(filepath.Join(dir, FlowFileName(step)))
+	if err != nil {
+		return fmt.Errorf("failed to create flow map: %v", err)
+	}
+	if err := WriteFlowCSV(file, f); err != nil {
+		_ = file.Close()
+		return fmt.Errorf("failed to write flow map: %v", err)
+	}
+	return file.Close()
+}
@@ -0,0 +1,39 @@
+package export
+
+import (
+	"encoding/csv"
+	"os"
+	"path/filepath"
+	"relativity_simulation_2d/internal/physics"
+	"testing"
+)
+
+// TestSaveFlowField tests writing a flow map named after its step
+func TestSaveFlowField(t *testing.T) {
+	particles := []*physics.Particle{physics.NewParticle(1, 0, 0, 0, 2, 0, -1)}
+	flow := physics.ComputeFlowField(particles, 4, 4, 1)
+
+	dir := filepath.Join(t.TempDir(), "flow")
+	if err := SaveFlowField(dir, 120, flow); err != nil {
+		t.Fatalf("SaveFlowField failed: %v", err)
+	}
+
+	file, err := os.Open(filepath.Join(dir, "flow_00000120.csv"))
+	if err != nil {
+		t.Fatalf("Expected the flow map to be named after the step: %v", err)
+	}
+	defer file.Close()
+	rows, err := csv.NewReader(file).ReadAll()
+	if err != nil {
+		t.Fatalf("Failed to read flow map: %v", err)
+	}
+	if len(rows) != 1+16 || len(rows[0]) != len(FlowHeader) {
+		t.Fatalf("Expected a header and 16 nodes, got %d rows of %d columns", len(rows), len(rows[0]))
+	}
+
+	// Node (2, 2) is the origin, where the particle sits
+	origin := rows[1+2*4+2]
+	if origin[0] != "0" || origin[1] != "0" || origin[2] != "2" || origin[3] != "-1" {
+		t.Errorf("Expected the particle velocity at the origin, got %v", origin)
+	}
+}
@@ -0,0 +1,305 @@
+package export
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/base64"
+	"encoding/binary"
+	"encoding/json"
+	"fmt"
+	"io"
+	"math"
+	"os"
+	"path/filepath"
+	"relativity_simulation_2d/internal/physics"
+	"strconv"
+	"strings"
+)
+
+// Mesh file formats, chosen by the file extension
+const (
+	MeshFormatOBJ  = ".obj"  // Wavefront OBJ, geometry only
+	MeshFormatGLTF = ".gltf" // glTF 2.0 with the buffer embedded, geometry and colors
+)
+
+// Mesh is a named triangle mesh in simulation coordinates, y up
+type Mesh struct {
+	Name      string
+	Positions []float32  // x, y, z per vertex
+	Normals   []float32  // Unit x, y, z per vertex
+	Indices   []uint32   // Three vertices per triangle, counter-clockwise seen from outside
+	Color     [4]float32 // Linear RGBA base color
+}
+
+// VertexCount returns the number of vertices
+func (m Mesh) VertexCount() int {
+	return len(m.Positions) / 3
+}
+
+// Default colors of the exported meshes
+var (
+	SurfaceColor  = [4]float32{0.2, 0.45, 0.9, 1}
+	ParticleColor = [4]float32{1, 0.85, 0.4, 1}
+)
+
+// SurfaceMesh returns the potential grid as a surface with one vertex per
+// node, laid out as the spacetime grid is drawn: node (i, j) at x = i - W/2,
+// z = j - H/2 and height Φ·scale
+func SurfaceMesh(grid physics.Grid, scale float64) Mesh {
+	width, height := grid.Width(), grid.Height()
+	m := Mesh{
+		Name:      "spacetime",
+		Positions: make([]float32, 0, 3*width*height),
+		Normals:   make([]float32, 0, 3*width*height),
+		Color:     SurfaceColor,
+	}
+	at := func(i, j int) float64 {
+		return grid.AtUnchecked(min(max(i, 0), width-1), min(max(j, 0), height-1)) * scale
+	}
+	for i := 0; i < width; i++ {
+		for j := 0; j < height; j++ {
+			m.Positions = append(m.Positions, float32(i)-float32(width)/2, float32(at(i, j)), float32(j)-float32(height)/2)
+			// The normal of the height field y = f(x, z) is (-∂f/∂x, 1, -∂f/∂z)
+			dx := slope(at(i-1, j), at(i+1, j), min(i+1, width-1)-max(i-1, 0))
+			dz := slope(at(i, j-1), at(i, j+1), min(j+1, height-1)-max(j-1, 0))
+			m.Normals = appendUnit(m.Normals, -dx, 1, -dz)
+		}
+	}
+	for i := 0; i+1 < width; i++ {
+		for j := 0; j+1 < height; j++ {
+			a := uint32(i*height + j)
+			b, c, d := a+uint32(height), a+1, a+uint32(height)+1
+			m.Indices = append(m.Indices, a, c, b, b, c, d)
+		}
+	}
+	return m
+}
+
+// SphereMesh returns one UV sphere of slices×stacks quads per center, with
+// the matching radius, merged into a single mesh
+func SphereMesh(name string, centers []physics.Vec3, radii []float32, slices, stacks int) Mesh {
+	m := Mesh{Name: name, Color: ParticleColor}
+	ring := uint32(slices + 1)
+	for n, center := range centers {
+		first := uint32(m.VertexCount())
+		r := float64(radii[n])
+		for k := 0; k <= stacks; k++ {
+			theta := math.Pi * float64(k) / float64(stacks)
+			for l := 0; l <= slices; l++ {
+				phi := 2 * math.Pi * float64(l) / float64(slices)
+				x, y, z := math.Sin(theta)*math.Cos(phi), math.Cos(theta), math.Sin(theta)*math.Sin(phi)
+				m.Positions = append(m.Positions, float32(center.X+r*x), float32(center.Y+r*y), float32(center.Z+r*z))
+				m.Normals = append(m.Normals, float32(x), float32(y), float32(z))
+			}
+		}
+		for k := 0; k < stacks; k++ {
+			for l := 0; l < slices; l++ {
+				a := first + uint32(k)*ring + uint32(l)
+				b, c, d := a+ring, a+1, a+ring+1
+				if k > 0 { // Skips the triangles that collapse onto the poles
+					m.Indices = append(m.Indices, a, c, b)
+				}
+				if k < stacks-1 {
+					m.Indices = append(m.Indices, c, d, b)
+				}
+			}
+		}
+	}
+	return m
+}
+
+// slope returns the difference from a to b over a span of cells, or 0 for
+// an empty span at the edge of a single-node axis
+func slope(a, b float64, span int) float64 {
+	if span == 0 {
+		return 0
+	}
+	return (b - a) / float64(span)
+}
+
+// appendUnit appends the vector (x, y, z) scaled to unit length
+func appendUnit(dst []float32, x, y, z float64) []float32 {
+	length := math.Sqrt(x*x + y*y + z*z)
+	return append(dst, float32(x/length), float32(y/length), float32(z/length))
+}
+
+// WriteOBJ writes the meshes as objects of a Wavefront OBJ file
+func WriteOBJ(w io.Writer, meshes []Mesh) error {
+	writer := bufio.NewWriter(w)
+	fmt.Fprintln(writer, "# relativity_simulation_2d spacetime mesh")
+	offset := 1 // OBJ indices are 1-based and run across all objects
+	for _, m := range meshes {
+		if m.VertexCount() == 0 {
+			continue
+		}
+		fmt.Fprintf(writer, "o %s\n", m.Name)
+		for v := 0; v < len(m.Positions); v += 3 {
+			fmt.Fprintf(writer, "v %s %s %s\n", formatFloat32(m.Positions[v]), formatFloat32(m.Positions[v+1]), formatFloat32(m.Positions[v+2]))
+		}
+		for v := 0; v < len(m.Normals); v += 3 {
+			fmt.Fprintf(writer, "vn %s %s %s\n", formatFloat32(m.Normals[v]), formatFloat32(m.Normals[v+1]), formatFloat32(m.Normals[v+2]))
+		}
+		for t := 0; t < len(m.Indices); t += 3 {
+			a, b, c := int(m.Indices[t])+offset, int(m.Indices[t+1])+offset, int(m.Indices[t+2])+offset
+			fmt.Fprintf(writer, "f %d//%d %d//%d %d//%d\n", a, a, b, b, c, c)
+		}
+		offset += m.VertexCount()
+	}
+	return writer.Flush()
+}
+
+// formatFloat32 formats a float32 compactly without losing precision
+func formatFloat32(f float32) string {
+	return strconv.FormatFloat(float64(f), 'g', -1, 32)
+}
+
+// glTF 2.0 document, limited to what WriteGLTF uses
+type (
+	gltfDocument struct {
+		Asset       gltfAsset        `json:"asset"`
+		Scene       int              `json:"scene"`
+		Scenes      []gltfScene      `json:"scenes"`
+		Nodes       []gltfNode       `json:"nodes"`
+		Meshes      []gltfMesh       `json:"meshes"`
+		Materials   []gltfMaterial   `json:"materials"`
+		Accessors   []gltfAccessor   `json:"accessors"`
+		BufferViews []gltfBufferView `json:"bufferViews"`
+		Buffers     []gltfBuffer     `json:"buffers"`
+	}
+	gltfAsset struct {
+		Version   string `json:"version"`
+		Generator string `json:"generator"`
+	}
+	gltfScene struct {
+		Nodes []int `json:"nodes"`
+	}
+	gltfNode struct {
+		Name string `json:"name"`
+		Mesh int    `json:"mesh"`
+	}
+	gltfMesh struct {
+		Name       string          `json:"name"`
+		Primitives []gltfPrimitive `json:"primitives"`
+	}
+	gltfPrimitive struct {
+		Attributes map[string]int `json:"attributes"`
+		Indices    int            `json:"indices"`
+		Material   int            `json:"material"`
+	}
+	gltfMaterial struct {
+		Name        string  `json:"name"`
+		PBR         gltfPBR `json:"pbrMetallicRoughness"`
+		DoubleSided bool    `json:"doubleSided"`
+	}
+	gltfPBR struct {
+		BaseColorFactor [4]float32 `json:"baseColorFactor"`
+		MetallicFactor  float32    `json:"metallicFactor"`
+		RoughnessFactor float32    `json:"roughnessFactor"`
+	}
+	gltfAccessor struct {
+		BufferView    int       `json:"bufferView"`
+		ComponentType int       `json:"componentType"`
+		Count         int       `json:"count"`
+		Type          string    `json:"type"`
+		Min           []float32 `json:"min,omitempty"`
+		Max           []float32 `json:"max,omitempty"`
+	}
+	gltfBufferView struct {
+		Buffer     int `json:"buffer"`
+		ByteOffset int `json:"byteOffset"`
+		ByteLength int `json:"byteLength"`
+		Target     int `json:"target"`
+	}
+	gltfBuffer struct {
+		ByteLength int    `json:"byteLength"`
+		URI        string `json:"uri"`
+	}
+)
+
+// glTF component types and buffer view targets
+const (
+	gltfFloat        = 5126
+	gltfUnsignedInt  = 5125
+	gltfArrayBuffer  = 34962
+	gltfElementArray = 34963
+)
+
+// WriteGLTF writes the meshes as a glTF 2.0 scene with one node per mesh and
+// the vertex data embedded as a base64 buffer
+func WriteGLTF(w io.Writer, meshes []Mesh) error {
+	doc := gltfDocument{
+		Asset:  gltfAsset{Version: "2.0", Generator: "relativity_simulation_2d"},
+		Scenes: []gltfScene{{Nodes: []int{}}},
+	}
+	var buffer bytes.Buffer
+	view := func(data any, target int) int {
+		offset := buffer.Len()
+		_ = binary.Write(&buffer, binary.LittleEndian, data) // Writing to a bytes.Buffer does not fail
+		doc.BufferViews = append(doc.BufferViews, gltfBufferView{ByteOffset: offset, ByteLength: buffer.Len() - offset, Target: target})
+		return len(doc.BufferViews) - 1
+	}
+	accessor := func(a gltfAccessor) int {
+		doc.Accessors = append(doc.Accessors, a)
+		return len(doc.Accessors) - 1
+	}
+	for _, m := range meshes {
+		if m.VertexCount() == 0 {
+			continue
+		}
+		low, high := bounds(m.Positions)
+		position := accessor(gltfAccessor{BufferView: view(m.Positions, gltfArrayBuffer), ComponentType: gltfFloat, Count: m.VertexCount(), Type: "VEC3", Min: low, Max: high})
+		normal := accessor(gltfAccessor{BufferView: view(m.Normals, gltfArrayBuffer), ComponentType: gltfFloat, Count: m.VertexCount(), Type: "VEC3"})
+		indices := accessor(gltfAccessor{BufferView: view(m.Indices, gltfElementArray), ComponentType: gltfUnsignedInt, Count: len(m.Indices), Type: "SCALAR"})
+		doc.Materials = append(doc.Materials, gltfMaterial{
+			Name:        m.Name,
+			PBR:         gltfPBR{BaseColorFactor: m.Color, RoughnessFactor: 0.8},
+			DoubleSided: true, // The surface is seen from below as well
+		})
+		doc.Meshes = append(doc.Meshes, gltfMesh{Name: m.Name, Primitives: []gltfPrimitive{{
+			Attributes: map[string]int{"POSITION": position, "NORMAL": normal},
+			Indices:    indices,
+			Material:   len(doc.Materials) - 1,
+		}}})
+		doc.Nodes = append(doc.Nodes, gltfNode{Name: m.Name, Mesh: len(doc.Meshes) - 1})
+		doc.Scenes[0].Nodes = append(doc.Scenes[0].Nodes, len(doc.Nodes)-1)
+	}
+	doc.Buffers = []gltfBuffer{{
+		ByteLength: buffer.Len(),
+		URI:        "data:application/octet-stream;base64," + base64.StdEncoding.EncodeToString(buffer.Bytes()),
+	}}
+	return json.NewEncoder(w).Encode(doc)
+}
+
+// bounds returns the per-axis minimum and maximum of x, y, z triples
+func bounds(positions []float32) (low, high []float32) {
+	low, high = append([]float32(nil), positions[:3]...), append([]float32(nil), positions[:3]...)
+	for v := 3; v < len(positions); v += 3 {
+		for axis := 0; axis < 3; axis++ {
+			low[axis] = min(low[axis], positions[v+axis])
+			high[axis] = max(high[axis], positions[v+axis])
+		}
+	}
+	return low, high
+}
+
+// SaveMesh writes the meshes to path in the format of its extension,
+// MeshFormatOBJ or MeshFormatGLTF
+func SaveMesh(path string, meshes []Mesh) error {
+	write := WriteOBJ
+	switch strings.ToLower(filepath.Ext(path)) {
+	case MeshFormatOBJ:
+	case MeshFormatGLTF:
+		write = WriteGLTF
+	default:
+		return fmt.Errorf("invalid mesh format: %q (want %s or %s)", filepath.Ext(path), MeshFormatOBJ, MeshFormatGLTF)
+	}
+	file, err := os.Create(path)
+	if err != nil {
+		return fmt.Errorf("failed to create mesh: %v", err)
+	}
+	if err := write(file, meshes); err != nil {
+		_ = file.Close()
+		return fmt.Errorf("failed to write mesh: %v", err)
+	}
+	return file.Close()
+}
@@ -0,0 +1,157 @@
+package export
+
+import (
+	"bufio"
+	"encoding/base64"
+	"encoding/binary"
+	"encoding/json"
+	"math"
+	"os"
+	"path/filepath"
+	"relativity_simulation_2d/internal/physics"
+	"strings"
+	"testing"
+)
+
+// facesOutward fails the test unless every triangle of m winds
+// counter-clockwise around its vertex normals
+func facesOutward(t *testing.T, m Mesh) {
+	t.Helper()
+	vertex := func(k uint32) [3]float64 {
+		return [3]float64{float64(m.Positions[3*k]), float64(m.Positions[3*k+1]), float64(m.Positions[3*k+2])}
+	}
+	for n := 0; n < len(m.Indices); n += 3 {
+		a, b, c := vertex(m.Indices[n]), vertex(m.Indices[n+1]), vertex(m.Indices[n+2])
+		u := [3]float64{b[0] - a[0], b[1] - a[1], b[2] - a[2]}
+		v := [3]float64{c[0] - a[0], c[1] - a[1], c[2] - a[2]}
+		normal := [3]float64{u[1]*v[2] - u[2]*v[1], u[2]*v[0] - u[0]*v[2], u[0]*v[1] - u[1]*v[0]}
+		k := m.Indices[n]
+		dot := normal[0]*float64(m.Normals[3*k]) + normal[1]*float64(m.Normals[3*k+1]) + normal[2]*float64(m.Normals[3*k+2])
+		if dot <= 0 {
+			t.Fatalf("Triangle %d winds against its normals", n/3)
+		}
+	}
+}
+
+// TestSurfaceMesh tests the layout, triangles and normals of the grid surface
+func TestSurfaceMesh(t *testing.T) {
+	grid := physics.NewGrid(4, 3)
+	grid[2][1] = -2
+	m := SurfaceMesh(grid, 0.5)
+
+	if m.VertexCount() != 12 || len(m.Normals) != 36 || len(m.Indices) != 3*2*3*2 {
+		t.Fatalf("Expected 12 vertices and 12 triangles, got %d and %d", m.VertexCount(), len(m.Indices)/3)
+	}
+	// Node (2, 1) is placed as the spacetime grid is drawn
+	k := 2*3 + 1
+	if x, y, z := m.Positions[3*k], m.Positions[3*k+1], m.Positions[3*k+2]; x != 0 || y != -1 || z != -0.5 {
+		t.Errorf("Expected node (2, 1) at (0, -1, -0.5), got (%g, %g, %g)", x, y, z)
+	}
+	// At the bottom of the well the surface is flat, beside it tilted towards it
+	if m.Normals[3*k+1] != 1 {
+		t.Errorf("Expected an upward normal at the well, got %v", m.Normals[3*k:3*k+3])
+	}
+	if k := 1*3 + 1; m.Normals[3*k] <= 0 {
+		t.Errorf("Expected the normal left of the well to lean towards +x, got %v", m.Normals[3*k:3*k+3])
+	}
+	facesOutward(t, m)
+}
+
+// TestSphereMesh tests that spheres sit at their centers and face outward
+func TestSphereMesh(t *testing.T) {
+	centers := []physics.Vec3{physics.NewVec3(0, 0, 0), physics.NewVec3(5, 1, -2)}
+	m := SphereMesh("particles", centers, []float32{1, 0.5}, 8, 6)
+
+	perSphere := 9 * 7
+	if m.VertexCount() != 2*perSphere {
+		t.Fatalf("Expected %d vertices, got %d", 2*perSphere, m.VertexCount())
+	}
+	// Two poles of 8 triangles and 4 bands of 16
+	if triangles := len(m.Indices) / 3; triangles != 2*(2*8+4*16) {
+		t.Errorf("Expected %d triangles, got %d", 2*(2*8+4*16), triangles)
+	}
+	for k := perSphere; k < 2*perSphere; k++ {
+		dx, dy, dz := float64(m.Positions[3*k])-5, float64(m.Positions[3*k+1])-1, float64(m.Positions[3*k+2])+2
+		if r := math.Sqrt(dx*dx + dy*dy + dz*dz); math.Abs(r-0.5) > 1e-6 {
+			t.Fatalf("Expected vertex %d at radius 0.5, got %g", k, r)
+		}
+	}
+	facesOutward(t, m)
+}
+
+// TestSaveMeshOBJ tests writing the meshes as OBJ objects with shared indices
+func TestSaveMeshOBJ(t *testing.T) {
+	surface := SurfaceMesh(physics.NewGrid(2, 2), 1)
+	spheres := SphereMesh("particles", []physics.Vec3{physics.NewVec3(0, 0, 0)}, []float32{1}, 4, 2)
+	path := filepath.Join(t.TempDir(), "mesh.obj")
+	if err := SaveMesh(path, []Mesh{surface, spheres, {Name: "empty"}}); err != nil {
+		t.Fatalf("SaveMesh failed: %v", err)
+	}
+
+	file, err := os.Open(path)
+	if err != nil {
+		t.Fatalf("Failed to open mesh: %v", err)
+	}
+	defer file.Close()
+	counts := map[string]int{}
+	var lastFace string
+	scanner := bufio.NewScanner(file)
+	for scanner.Scan() {
+		fields := strings.Fields(scanner.Text())
+		counts[fields[0]]++
+		if fields[0] == "f" {
+			lastFace = scanner.Text()
+		}
+	}
+	if counts["o"] != 2 || counts["v"] != 4+15 || counts["vn"] != 4+15 || counts["f"] != 2+8 {
+		t.Errorf("Unexpected element counts %v", counts)
+	}
+	// The sphere's faces are numbered after the surface's vertices
+	if lastFace != "f 13//13 14//14 18//18" {
+		t.Errorf("Unexpected last face %q", lastFace)
+	}
+}
+
+// TestSaveMeshGLTF tests that the glTF scene references its embedded data
+func TestSaveMeshGLTF(t *testing.T) {
+	surface := SurfaceMesh(physics.NewGrid(3, 3), 1)
+	path := filepath.Join(t.TempDir(), "mesh.gltf")
+	if err := SaveMesh(path, []Mesh{surface}); err != nil {
+		t.Fatalf("SaveMesh failed: %v", err)
+	}
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatalf("Failed to read mesh: %v", err)
+	}
+	var doc gltfDocument
+	if err := json.Unmarshal(data, &doc); err != nil {
+		t.Fatalf("Invalid glTF JSON: %v", err)
+	}
+	if doc.Asset.Version != "2.0" || len(doc.Nodes) != 1 || len(doc.Meshes) != 1 || len(doc.Accessors) != 3 {
+		t.Fatalf("Unexpected document %+v", doc)
+	}
+	buffer, err := base64.StdEncoding.DecodeString(strings.TrimPrefix(doc.Buffers[0].URI, "data:application/octet-stream;base64,"))
+	if err != nil || len(buffer) != doc.Buffers[0].ByteLength {
+		t.Fatalf("Expected a %d-byte embedded buffer, got %d (%v)", doc.Buffers[0].ByteLength, len(buffer), err)
+	}
+	indices := doc.Accessors[doc.Meshes[0].Primitives[0].Indices]
+	view := doc.BufferViews[indices.BufferView]
+	if indices.Count != len(surface.Indices) || view.ByteLength != 4*indices.Count {
+		t.Errorf("Expected %d indices, got %d in %d bytes", len(surface.Indices), indices.Count, view.ByteLength)
+	}
+	if last := binary.LittleEndian.Uint32(buffer[view.ByteOffset+view.ByteLength-4:]); last != surface.Indices[len(surface.Indices)-1] {
+		t.Errorf("Expected the last index %d, got %d", surface.Indices[len(surface.Indices)-1], last)
+	}
+	position := doc.Accessors[doc.Meshes[0].Primitives[0].Attributes["POSITION"]]
+	if position.Min[0] != -1.5 || position.Max[2] != 0.5 {
+		t.Errorf("Unexpected position bounds %v to %v", position.Min, position.Max)
+	}
+}
+
+// TestSaveMeshFormat tests rejecting unknown extensions
+func TestSaveMeshFormat(t *testing.T) {
+	if err := SaveMesh(filepath.Join(t.TempDir(), "mesh.stl"), nil); err == nil {
+		t.Error("Expected an error for an .stl file")
+	}
+}
@@ -0,0 +1,207 @@
+package export
+
+import (
+	"bufio"
+	"context"
+	"encoding/binary"
+	"fmt"
+	"io"
+	"math"
+	"os"
+	"relativity_simulation_2d/internal/physics"
+	"strings"
+)
+
+// N-body snapshot formats
+const (
+	NBodyFormatGadget = "gadget" // Gadget-2 binary (SnapFormat 1), readable by yt and nbodykit
+	NBodyFormatRaw    = "raw"    // Headerless POS/VEL/MASS arrays for numpy.fromfile
+)
+
+// NBodyMeta describes the run a particle snapshot was taken from
+type NBodyMeta struct {
+	SimTime float64
+	Width   float64 // Simulation extent along X
+	Depth   float64 // Simulation extent along Z
+}
+
+// gadgetHeaderSize is the fixed size of the Gadget-2 header block
+const gadgetHeaderSize = 256
+
+// SaveNBody writes particles to path in the given N-body format
+func SaveNBody(path, format string, particles []*physics.Particle, meta NBodyMeta) error {
+	return SaveNBodyContext(context.Background(), path, format, particles, meta)
+}
+
+// SaveNBodyContext is SaveNBody with a context. Cancelling ctx stops the write
+// at the next buffered block and removes the partial file
+func SaveNBodyContext(ctx context.Context, path, format string, particles []*physics.Particle, meta NBodyMeta) error {
+	var write func(io.Writer, []*physics.Particle, NBodyMeta) error
+	switch strings.ToLower(format) {
+	case NBodyFormatGadget:
+		write = WriteGadget
+	case NBodyFormatRaw:
+		write = func(w io.Writer, particles []*physics.Particle, _ NBodyMeta) error {
+			return WriteRaw(w, particles)
+		}
+	default:
+		return fmt.Errorf("unknown N-body format %q (want %s or %s)", format, NBodyFormatGadget, NBodyFormatRaw)
+	}
+
+	file, err := os.Create(path)
+	if err != nil {
+		return fmt.Errorf("failed to create N-body snapshot: %v", err)
+	}
+	bw := bufio.NewWriter(contextWriter{ctx: ctx, w: file})
+	if err := write(bw, particles, meta); err != nil {
+		_ = file.Close()
+		_ = os.Remove(path)
+		return fmt.Errorf("failed to write N-body snapshot: %w", err)
+	}
+	if err := bw.Flush(); err != nil {
+		_ = file.Close()
+		_ = os.Remove(path)
+		return fmt.Errorf("failed to write N-body snapshot: %w", err)
+	}
+	return file.Close()
+}
+
+// contextWriter fails writes once its context is cancelled
+type contextWriter struct {
+	ctx context.Context
+	w   io.Writer
+}
+
+// Write writes p unless the context is done
+func (c contextWriter) Write(p []byte) (int, error) {
+	if err := c.ctx.Err(); err != nil {
+		return 0, err
+	}
+	return c.w.Write(p)
+}
+
+// WriteGadget writes particles as a single-file Gadget-2 snapshot (SnapFormat
+// 1, little-endian) with all particles as type 1. Each block is framed by
+// Fortran record markers. Positions are shifted into the box [0, BoxSize),
+// where BoxSize is the larger simulation extent; velocities are unchanged. A
+// MASS block is written only when particle masses differ
+func WriteGadget(w io.Writer, particles []*physics.Particle, meta NBodyMeta) error {
+	n := len(particles)
+	if uint64(n)*12 > math.MaxUint32 { // Largest block must fit a 4-byte record marker
+		return fmt.Errorf("too many particles for Gadget format: %d", n)
+	}
+	boxSize := math.Max(meta.Width, meta.Depth)
+
+	equalMass := n > 0
+	for _, p := range particles {
+		if p.Mass != particles[0].Mass {
+			equalMass = false
+			break
+		}
+	}
+
+	header := make([]byte, 0, gadgetHeaderSize)
+	le := binary.LittleEndian
+	for t := 0; t < 6; t++ { // Npart
+		header = le.AppendUint32(header, uint32(countIf(t == 1, n)))
+	}
+	for t := 0; t < 6; t++ { // Massarr
+		mass := 0.0
+		if t == 1 && equalMass {
+			mass = float64(particles[0].Mass)
+		}
+		header = le.AppendUint64(header, math.Float64bits(mass))
+	}
+	header = le.AppendUint64(header, math.Float64bits(meta.SimTime)) // Time
+	header = le.AppendUint64(header, math.Float64bits(0))            // Redshift
+	header = le.AppendUint32(header, 0)                              // FlagSfr
+	header = le.AppendUint32(header, 0)                              // FlagFeedback
+	for t := 0; t < 6; t++ {                                         // Nall
+		header = le.AppendUint32(header, uint32(countIf(t == 1, n)))
+	}
+	header = le.AppendUint32(header, 0)                         // FlagCooling
+	header = le.AppendUint32(header, 1)                         // NumFiles
+	header = le.AppendUint64(header, math.Float64bits(boxSize)) // BoxSize
+	header = le.AppendUint64(header, math.Float64bits(0))       // Omega0
+	header = le.AppendUint64(header, math.Float64bits(0))       // OmegaLambda
+	header = le.AppendUint64(header, math.Float64bits(1))       // HubbleParam
+	header = le.AppendUint32(header, 0)                         // FlagAge
+	header = le.AppendUint32(header, 0)                         // FlagMetals
+	for t := 0; t < 6; t++ {                                    // NallHW
+		header = le.AppendUint32(header, 0)
+	}
+	header = le.AppendUint32(header, 0) // FlagEntropyInsteadU
+	header = header[:gadgetHeaderSize]  // Zero fill
+
+	offset := [3]float64{meta.Width / 2, boxSize / 2, meta.Depth / 2}
+	pos := make([]byte, 0, n*12)
+	vel := make([]byte, 0, n*12)
+	ids := make([]byte, 0, n*4)
+	for i, p := range particles {
+		for k, v := range [3]float64{p.Position.X, p.Position.Y, p.Position.Z} {
+			pos = le.AppendUint32(pos, math.Float32bits(float32(v+offset[k])))
+		}
+		for _, v := range [3]float64{p.Velocity.X, p.Velocity.Y, p.Velocity.Z} {
+			vel = le.AppendUint32(vel, math.Float32bits(float32(v)))
+		}
+		ids = le.AppendUint32(ids, uint32(i+1))
+	}
+
+	blocks := [][]byte{header, pos, vel, ids}
+	if !equalMass && n > 0 {
+		mass := make([]byte, 0, n*4)
+		for _, p := range particles {
+			mass = le.AppendUint32(mass, math.Float32bits(p.Mass))
+		}
+		blocks = append(blocks, mass)
+	}
+
+	for _, block := range blocks {
+		if err := writeFortranRecord(w, block); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// WriteRaw writes particles as little-endian arrays: particle count (uint64),
+// then positions [N][3]float64, velocities [N][3]float64 and masses [N]float64,
+// all in simulation coordinates
+func WriteRaw(w io.Writer, particles []*physics.Particle) error {
+	le := binary.LittleEndian
+	buf := le.AppendUint64(nil, uint64(len(particles)))
+	for _, p := range particles {
+		for _, v := range [3]float64{p.Position.X, p.Position.Y, p.Position.Z} {
+			buf = le.AppendUint64(buf, math.Float64bits(v))
+		}
+	}
+	for _, p := range particles {
+		for _, v := range [3]float64{p.Velocity.X, p.Velocity.Y, p.Velocity.Z} {
+			buf = le.AppendUint64(buf, math.Float64bits(v))
+		}
+	}
+	for _, p := range particles {
+		buf = le.AppendUint64(buf, math.Float64bits(float64(p.Mass)))
+	}
+	_, err := w.Write(buf)
+	return err
+}
+
+// writeFortranRecord writes data framed by 4-byte length markers
+func writeFortranRecord(w io.Writer, data []byte) error {
+	marker := binary.LittleEndian.AppendUint32(nil, uint32(len(data)))
+	for _, part := range [][]byte{marker, data, marker} {
+		if _, err := w.Write(part); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// countIf returns n if cond holds and 0 otherwise
+func countIf(cond bool, n int) int {
+	if cond {
+		return n
+	}
+	return 0
+}
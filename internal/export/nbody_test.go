@@ -0,0 +1,147 @@
+package export
+
+import (
+	"bytes"
+	"context"
+	"encoding/binary"
+	"errors"
+	"math"
+	"os"
+	"path/filepath"
+	"relativity_simulation_2d/internal/physics"
+	"testing"
+)
+
+// readFortranRecords splits a Gadget file into its records, checking markers
+func readFortranRecords(t *testing.T, data []byte) [][]byte {
+	t.Helper()
+	var records [][]byte
+	for len(data) > 0 {
+		if len(data) < 8 {
+			t.Fatalf("Trailing %d bytes", len(data))
+		}
+		n := int(binary.LittleEndian.Uint32(data))
+		if len(data) < n+8 || binary.LittleEndian.Uint32(data[4+n:]) != uint32(n) {
+			t.Fatalf("Mismatched record markers for %d-byte record", n)
+		}
+		records = append(records, data[4:4+n])
+		data = data[n+8:]
+	}
+	return records
+}
+
+// float32At decodes the little-endian float32 at index i
+func float32At(b []byte, i int) float32 {
+	return math.Float32frombits(binary.LittleEndian.Uint32(b[i*4:]))
+}
+
+// TestWriteGadget tests header fields, box shift and the optional MASS block
+func TestWriteGadget(t *testing.T) {
+	particles := []*physics.Particle{
+		physics.NewParticle(2, -10, 0, 5, 1, 0, -1),
+		physics.NewParticle(3, 20, 0, -30, 0, 0.5, 0),
+	}
+	meta := NBodyMeta{SimTime: 1.25, Width: 64, Depth: 128}
+
+	var buf bytes.Buffer
+	if err := WriteGadget(&buf, particles, meta); err != nil {
+		t.Fatalf("WriteGadget failed: %v", err)
+	}
+	records := readFortranRecords(t, buf.Bytes())
+	if len(records) != 5 {
+		t.Fatalf("Expected header, POS, VEL, ID and MASS records, got %d", len(records))
+	}
+
+	header := records[0]
+	if len(header) != 256 {
+		t.Fatalf("Header must be 256 bytes, got %d", len(header))
+	}
+	le := binary.LittleEndian
+	if le.Uint32(header[4:]) != 2 || le.Uint32(header[0:]) != 0 {
+		t.Error("All particles should be type 1")
+	}
+	if time := math.Float64frombits(le.Uint64(header[72:])); time != 1.25 {
+		t.Errorf("Expected time 1.25, got %f", time)
+	}
+	if box := math.Float64frombits(le.Uint64(header[128:])); box != 128 {
+		t.Errorf("Expected box size 128, got %f", box)
+	}
+
+	pos, vel, ids, mass := records[1], records[2], records[3], records[4]
+	if float32At(pos, 0) != 22 || float32At(pos, 1) != 64 || float32At(pos, 2) != 69 {
+		t.Errorf("Positions not shifted into the box: %v %v %v", float32At(pos, 0), float32At(pos, 1), float32At(pos, 2))
+	}
+	if float32At(vel, 2) != -1 || float32At(vel, 4) != 0.5 {
+		t.Error("Velocities mismatch")
+	}
+	if le.Uint32(ids[4:]) != 2 {
+		t.Error("IDs should start at 1")
+	}
+	if float32At(mass, 0) != 2 || float32At(mass, 1) != 3 {
+		t.Error("Masses mismatch")
+	}
+
+	// Equal masses go in the header mass table instead of a MASS block
+	particles[1].Mass = 2
+	buf.Reset()
+	if err := WriteGadget(&buf, particles, meta); err != nil {
+		t.Fatal(err)
+	}
+	records = readFortranRecords(t, buf.Bytes())
+	if len(records) != 4 {
+		t.Errorf("Expected no MASS record for equal masses, got %d records", len(records))
+	}
+	if m := math.Float64frombits(le.Uint64(records[0][24+8:])); m != 2 {
+		t.Errorf("Expected type 1 mass 2 in header, got %f", m)
+	}
+}
+
+// TestSaveNBodyRaw tests the raw array layout and format selection
+func TestSaveNBodyRaw(t *testing.T) {
+	particles := []*physics.Particle{
+		physics.NewParticle(4, 1, 2, 3, 4, 5, 6),
+		physics.NewParticle(8, -1, -2, -3, -4, -5, -6),
+	}
+	path := filepath.Join(t.TempDir(), "final.raw")
+	if err := SaveNBody(path, NBodyFormatRaw, particles, NBodyMeta{}); err != nil {
+		t.Fatalf("SaveNBody failed: %v", err)
+	}
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(data) != 8+2*7*8 {
+		t.Fatalf("Unexpected size %d", len(data))
+	}
+	values := make([]float64, (len(data)-8)/8)
+	for i := range values {
+		values[i] = math.Float64frombits(binary.LittleEndian.Uint64(data[8+i*8:]))
+	}
+	if binary.LittleEndian.Uint64(data) != 2 || values[3] != -1 || values[6+2] != 6 || values[12] != 4 || values[13] != 8 {
+		t.Errorf("Unexpected raw layout: %v", values)
+	}
+
+	if err := SaveNBody(path, "hdf5", particles, NBodyMeta{}); err == nil {
+		t.Error("Expected error for unknown format")
+	}
+}
+
+// TestSaveNBodyContextCancelled tests that a cancelled write fails and leaves no partial file
+func TestSaveNBodyContextCancelled(t *testing.T) {
+	particles := make([]*physics.Particle, 1000)
+	for i := range particles {
+		particles[i] = physics.NewParticle(1, float64(i), 0, 0, 0, 0, 0)
+	}
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	path := filepath.Join(t.TempDir(), "final.gadget")
+	err := SaveNBodyContext(ctx, path, NBodyFormatGadget, particles, NBodyMeta{})
+	if !errors.Is(err, context.Canceled) {
+		t.Fatalf("Expected context.Canceled, got %v", err)
+	}
+	if _, err := os.Stat(path); !os.IsNotExist(err) {
+		t.Errorf("Partial snapshot was not removed: %v", err)
+	}
+}
@@ -0,0 +1,54 @@
+package export
+
+import (
+	"encoding/csv"
+	"fmt"
+	"io"
+	"os"
+	"relativity_simulation_2d/internal/physics"
+	"strconv"
+)
+
+// ProfileHeader is the column header written by WriteProfileCSV
+var ProfileHeader = []string{"r_inner", "r_outer", "count", "mass", "density", "rotation", "sigma_r", "sigma_t", "sigma", "enclosed_mass", "v_circ"}
+
+// WriteProfileCSV writes one row per radial bin of a profile, innermost first
+func WriteProfileCSV(w io.Writer, profile physics.RadialProfile) error {
+	writer := csv.NewWriter(w)
+	if err := writer.Write(ProfileHeader); err != nil {
+		return err
+	}
+	for _, b := range profile.Bins {
+		row := []string{
+			formatFloat(b.Inner),
+			formatFloat(b.Outer),
+			strconv.Itoa(b.Count),
+			formatFloat(b.Mass),
+			formatFloat(b.Density),
+			formatFloat(b.Rotation),
+			formatFloat(b.SigmaR),
+			formatFloat(b.SigmaT),
+			formatFloat(b.Dispersion),
+			formatFloat(b.EnclosedMass),
+			formatFloat(b.CircularVelocity),
+		}
+		if err := writer.Write(row); err != nil {
+			return err
+		}
+	}
+	writer.Flush()
+	return writer.Error()
+}
+
+// SaveProfile writes a radial profile as CSV to path
+func SaveProfile(path string, profile physics.RadialProfile) error {
+	file, err := os.Create(path)
+	if err != nil {
+		return fmt.Errorf("failed to create radial profile: %v", err)
+	}
+	if err := WriteProfileCSV(file, profile); err != nil {
+		_ = file.Close()
+		return fmt.Errorf("failed to write radial profile: %v", err)
+	}
+	return file.Close()
+}
@@ -0,0 +1,40 @@
+package export
+
+import (
+	"encoding/csv"
+	"os"
+	"path/filepath"
+	"relativity_simulation_2d/internal/physics"
+	"testing"
+)
+
+// TestSaveProfile tests writing one row per radial bin
+func TestSaveProfile(t *testing.T) {
+	particles := []*physics.Particle{
+		physics.NewParticle(1, 0, 0, 0, 0, 0, 0),
+		physics.NewParticle(2, 3, 0, 0, 0, 0, 1),
+	}
+	profile := physics.ComputeRadialProfile(particles, physics.ProfileOptions{Bins: 4, MaxRadius: 4, GravitationalConstant: 1})
+
+	path := filepath.Join(t.TempDir(), "profile.csv")
+	if err := SaveProfile(path, profile); err != nil {
+		t.Fatalf("SaveProfile failed: %v", err)
+	}
+	file, err := os.Open(path)
+	if err != nil {
+		t.Fatalf("Failed to open profile: %v", err)
+	}
+	defer file.Close()
+	rows, err := csv.NewReader(file).ReadAll()
+	if err != nil {
+		t.Fatalf("Failed to read profile: %v", err)
+	}
+	if len(rows) != 1+4 || len(rows[0]) != len(ProfileHeader) {
+		t.Fatalf("Expected a header and 4 bins, got %d rows of %d columns", len(rows), len(rows[0]))
+	}
+
+	// The center of mass is at x=2, so both particles fall in the bins at r=1 and r=2
+	if rows[2][2] != "1" || rows[3][2] != "1" || rows[4][9] != "3" {
+		t.Errorf("Unexpected bins %v", rows[1:])
+	}
+}
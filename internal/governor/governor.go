@@ -0,0 +1,199 @@
+// Package governor adapts interactive quality to the machine's headroom. It
+// steps quality down when frames run over budget or the machine is saving
+// power, and back up once there is headroom again
+package governor
+
+import (
+	"sort"
+)
+
+// Level is a quality level; higher levels trade more quality for less work.
+// Each level keeps the reductions of the levels below it
+type Level int
+
+const (
+	// LevelFull draws every grid line at the full frame rate
+	LevelFull Level = iota
+	// LevelReducedGrid draws every second grid line
+	LevelReducedGrid
+	// LevelReducedFPS also halves the frame rate
+	LevelReducedFPS
+	// LevelCPU also switches the simulation to CPU mode
+	LevelCPU
+)
+
+// String returns string representation of Level
+func (l Level) String() string {
+	switch l {
+	case LevelFull:
+		return "Full"
+	case LevelReducedGrid:
+		return "Reduced grid"
+	case LevelReducedFPS:
+		return "Reduced FPS"
+	case LevelCPU:
+		return "CPU"
+	default:
+		return "Unknown"
+	}
+}
+
+// TargetFPS returns the frame rate cap of the level
+func (l Level) TargetFPS() int {
+	if l >= LevelReducedFPS {
+		return 30
+	}
+	return 60
+}
+
+// FrameRate returns the frame rate cap of the level for a configured cap of
+// base frames per second (0 = uncapped). Reduced frame rate levels halve the
+// cap, and cap an uncapped session at TargetFPS
+func (l Level) FrameRate(base int) int {
+	switch {
+	case l < LevelReducedFPS:
+		return base
+	case base <= 0:
+		return l.TargetFPS()
+	default:
+		return max(1, base/2)
+	}
+}
+
+// GridStride returns the spacing, in cells, between drawn grid lines
+func (l Level) GridStride() int {
+	if l >= LevelReducedGrid {
+		return 2
+	}
+	return 1
+}
+
+// ForceCPU reports whether the level runs the simulation in CPU mode
+func (l Level) ForceCPU() bool {
+	return l >= LevelCPU
+}
+
+// Options configures a Governor
+type Options struct {
+	FrameBudget  float64 // Seconds of work per frame before the frame counts as over budget
+	Window       int     // Frames whose median work time is compared to the budget
+	Cooldown     float64 // Seconds to wait after a level change before stepping down again
+	RecoverDelay float64 // Seconds of headroom required before stepping back up
+	AllowCPU     bool    // Whether the last step may switch to CPU mode
+}
+
+// DefaultOptions returns options for a 60 FPS interactive session
+func DefaultOptions() Options {
+	return Options{
+		FrameBudget:  1.0 / 60.0,
+		Window:       30,
+		Cooldown:     2.0,
+		RecoverDelay: 5.0,
+		AllowCPU:     true,
+	}
+}
+
+// recoverFraction is the share of the budget the median work time must stay under to step up
+const recoverFraction = 0.5
+
+// Governor picks a quality level from frame work times and the power state
+type Governor struct {
+	steps stepper
+}
+
+// NewGovernor creates a governor starting at LevelFull
+func NewGovernor(opts Options) *Governor {
+	return &Governor{steps: newStepper(opts)}
+}
+
+// GetLevel returns the current quality level
+func (g *Governor) GetLevel() Level {
+	return Level(g.steps.level)
+}
+
+// Update records one frame and returns the level to use and whether it
+// changed. frameTime is the wall time of the frame and workTime the part
+// spent simulating and drawing, excluding the wait for the frame rate cap,
+// both in seconds. While powerSaving is set the level stays at
+// LevelReducedFPS or above
+func (g *Governor) Update(frameTime, workTime float64, powerSaving bool) (Level, bool) {
+	floor := LevelFull
+	if powerSaving {
+		floor = LevelReducedFPS
+	}
+	maxLevel := LevelReducedFPS
+	if g.steps.opts.AllowCPU {
+		maxLevel = LevelCPU
+	}
+	level, changed := g.steps.update(frameTime, workTime, int(floor), int(maxLevel))
+	return Level(level), changed
+}
+
+// stepper moves between numbered levels, one at a time, from the median of
+// recent work times against a budget
+type stepper struct {
+	opts     Options
+	level    int
+	samples  []float64 // Ring of recent work times
+	next     int
+	filled   bool
+	sinceSet float64 // Seconds since the last level change
+	headroom float64 // Seconds the average has stayed under the recovery threshold
+}
+
+// newStepper creates a stepper starting at level 0
+func newStepper(opts Options) stepper {
+	if opts.Window < 1 {
+		opts.Window = 1
+	}
+	return stepper{opts: opts, samples: make([]float64, opts.Window)}
+}
+
+// update records one frame and returns the level, kept between floor and
+// maxLevel, and whether it changed
+func (s *stepper) update(frameTime, workTime float64, floor, maxLevel int) (int, bool) {
+	s.samples[s.next] = workTime
+	s.next = (s.next + 1) % len(s.samples)
+	if s.next == 0 {
+		s.filled = true
+	}
+	s.sinceSet += frameTime
+
+	if s.level < floor {
+		return s.set(floor), true
+	}
+	if !s.filled {
+		return s.level, false
+	}
+
+	// The median ignores isolated spikes such as shader compiles or GC pauses
+	sorted := append([]float64(nil), s.samples...)
+	sort.Float64s(sorted)
+	median := sorted[len(sorted)/2]
+
+	switch {
+	case median > s.opts.FrameBudget:
+		s.headroom = 0
+		if s.level < maxLevel && s.sinceSet >= s.opts.Cooldown {
+			return s.set(s.level + 1), true
+		}
+	case median < s.opts.FrameBudget*recoverFraction:
+		s.headroom += frameTime
+		if s.level > floor && s.headroom >= s.opts.RecoverDelay {
+			return s.set(s.level - 1), true
+		}
+	default:
+		s.headroom = 0
+	}
+	return s.level, false
+}
+
+// set changes the level and restarts the measurement window
+func (s *stepper) set(level int) int {
+	s.level = level
+	s.sinceSet = 0
+	s.headroom = 0
+	s.next = 0
+	s.filled = false
+	return level
+}
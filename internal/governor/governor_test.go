@@ -0,0 +1,102 @@
+package governor
+
+import (
+	"testing"
+)
+
+// testOptions uses a short window so tests can step through levels quickly
+func testOptions() Options {
+	return Options{FrameBudget: 0.016, Window: 4, Cooldown: 0.1, RecoverDelay: 0.2, AllowCPU: true}
+}
+
+// run feeds frames of the given work time until the level changes or n frames pass
+func run(g *Governor, n int, frameTime, workTime float64, powerSaving bool) (Level, bool) {
+	for i := 0; i < n; i++ {
+		if level, changed := g.Update(frameTime, workTime, powerSaving); changed {
+			return level, true
+		}
+	}
+	return g.GetLevel(), false
+}
+
+// TestGovernorStepsDownOnSlowFrames tests degrading one level at a time to CPU mode
+func TestGovernorStepsDownOnSlowFrames(t *testing.T) {
+	g := NewGovernor(testOptions())
+	for _, want := range []Level{LevelReducedGrid, LevelReducedFPS, LevelCPU} {
+		level, changed := run(g, 100, 0.04, 0.03, false)
+		if !changed || level != want {
+			t.Fatalf("Expected a step down to %v, got %v (changed %v)", want, level, changed)
+		}
+	}
+	if _, changed := run(g, 100, 0.04, 0.03, false); changed {
+		t.Error("Expected CPU mode to be the last level")
+	}
+	if !g.GetLevel().ForceCPU() || g.GetLevel().TargetFPS() != 30 || g.GetLevel().GridStride() != 2 {
+		t.Errorf("Expected CPU mode to keep the other reductions")
+	}
+}
+
+// TestLevelFrameRate tests scaling the configured frame rate cap by level
+func TestLevelFrameRate(t *testing.T) {
+	tests := []struct {
+		level Level
+		base  int
+		want  int
+	}{
+		{LevelFull, 144, 144},
+		{LevelReducedGrid, 0, 0},
+		{LevelReducedFPS, 144, 72},
+		{LevelReducedFPS, 1, 1},
+		{LevelCPU, 0, 30},
+	}
+	for _, tt := range tests {
+		if got := tt.level.FrameRate(tt.base); got != tt.want {
+			t.Errorf("%v.FrameRate(%d) = %d, want %d", tt.level, tt.base, got, tt.want)
+		}
+	}
+}
+
+// TestGovernorWithoutCPU tests that AllowCPU caps the degradation
+func TestGovernorWithoutCPU(t *testing.T) {
+	opts := testOptions()
+	opts.AllowCPU = false
+	g := NewGovernor(opts)
+	run(g, 100, 0.04, 0.03, false)
+	run(g, 100, 0.04, 0.03, false)
+	if level, changed := run(g, 100, 0.04, 0.03, false); changed || level != LevelReducedFPS {
+		t.Errorf("Expected to stop at %v, got %v", LevelReducedFPS, level)
+	}
+}
+
+// TestGovernorIgnoresSingleSpike tests that one slow frame does not change the level
+func TestGovernorIgnoresSingleSpike(t *testing.T) {
+	g := NewGovernor(testOptions())
+	run(g, 10, 0.016, 0.005, false)
+	g.Update(0.2, 0.2, false)
+	if level, changed := run(g, 3, 0.016, 0.005, false); changed || level != LevelFull {
+		t.Errorf("Expected to stay at %v, got %v", LevelFull, level)
+	}
+}
+
+// TestGovernorRecovers tests stepping back up after sustained headroom
+func TestGovernorRecovers(t *testing.T) {
+	g := NewGovernor(testOptions())
+	run(g, 100, 0.04, 0.03, false)
+	if level, changed := run(g, 100, 0.016, 0.002, false); !changed || level != LevelFull {
+		t.Errorf("Expected recovery to %v, got %v", LevelFull, level)
+	}
+}
+
+// TestGovernorPowerSaving tests the power-saving floor
+func TestGovernorPowerSaving(t *testing.T) {
+	g := NewGovernor(testOptions())
+	if level, changed := g.Update(0.016, 0.002, true); !changed || level != LevelReducedFPS {
+		t.Fatalf("Expected an immediate switch to %v, got %v", LevelReducedFPS, level)
+	}
+	if level, changed := run(g, 100, 0.033, 0.002, true); changed {
+		t.Errorf("Expected to hold %v while saving power, got %v", LevelReducedFPS, level)
+	}
+	if level, changed := run(g, 100, 0.033, 0.002, false); !changed || level != LevelReducedGrid {
+		t.Errorf("Expected a step up once power saving ends, got %v", level)
+	}
+}
@@ -0,0 +1,96 @@
+package governor
+
+// PhysicsLevel is a physics quality level; higher levels trade more accuracy
+// for shorter steps. Each level keeps the reductions of the levels below it
+type PhysicsLevel int
+
+const (
+	// PhysicsFull runs every step at full quality
+	PhysicsFull PhysicsLevel = iota
+	// PhysicsSkipDiagnostics pauses the diagnostics plots and refreshes flow maps less often
+	PhysicsSkipDiagnostics
+	// PhysicsCoarseView also computes the drawn grids at half resolution
+	PhysicsCoarseView
+	// PhysicsSubsampled also deposits only every second particle for the forces
+	PhysicsSubsampled
+)
+
+// String returns string representation of PhysicsLevel
+func (l PhysicsLevel) String() string {
+	switch l {
+	case PhysicsFull:
+		return "Full"
+	case PhysicsSkipDiagnostics:
+		return "Diagnostics paused"
+	case PhysicsCoarseView:
+		return "Coarse view"
+	case PhysicsSubsampled:
+		return "Sub-sampled deposit"
+	default:
+		return "Unknown"
+	}
+}
+
+// SkipDiagnostics reports whether the level pauses optional diagnostics
+func (l PhysicsLevel) SkipDiagnostics() bool {
+	return l >= PhysicsSkipDiagnostics
+}
+
+// FlowIntervalScale returns the factor applied to the steps between flow maps
+func (l PhysicsLevel) FlowIntervalScale() int {
+	if l >= PhysicsSkipDiagnostics {
+		return 4
+	}
+	return 1
+}
+
+// ViewFactor returns the coarsening of the grids computed only for drawing
+func (l PhysicsLevel) ViewFactor() int {
+	if l >= PhysicsCoarseView {
+		return 2
+	}
+	return 1
+}
+
+// DepositStride returns the share of particles deposited: every DepositStride-th one
+func (l PhysicsLevel) DepositStride() int {
+	if l >= PhysicsSubsampled {
+		return 2
+	}
+	return 1
+}
+
+// DefaultPhysicsOptions returns options for a physics budget of budget
+// seconds per frame. The window is shorter than for frames, since steps
+// over budget stall input until the reductions take effect
+func DefaultPhysicsOptions(budget float64) Options {
+	return Options{
+		FrameBudget:  budget,
+		Window:       10,
+		Cooldown:     1.0,
+		RecoverDelay: 3.0,
+	}
+}
+
+// PhysicsGovernor picks a physics quality level from the time spent stepping each frame
+type PhysicsGovernor struct {
+	steps stepper
+}
+
+// NewPhysicsGovernor creates a physics governor starting at PhysicsFull
+func NewPhysicsGovernor(opts Options) *PhysicsGovernor {
+	return &PhysicsGovernor{steps: newStepper(opts)}
+}
+
+// GetLevel returns the current physics quality level
+func (g *PhysicsGovernor) GetLevel() PhysicsLevel {
+	return PhysicsLevel(g.steps.level)
+}
+
+// Update records one frame and returns the level to use and whether it
+// changed. frameTime is the wall time of the frame and stepTime the part
+// spent stepping the simulation, both in seconds
+func (g *PhysicsGovernor) Update(frameTime, stepTime float64) (PhysicsLevel, bool) {
+	level, changed := g.steps.update(frameTime, stepTime, int(PhysicsFull), int(PhysicsSubsampled))
+	return PhysicsLevel(level), changed
+}
@@ -0,0 +1,59 @@
+package governor
+
+import (
+	"testing"
+)
+
+// runPhysics feeds frames of the given step time until the level changes or n frames pass
+func runPhysics(g *PhysicsGovernor, n int, frameTime, stepTime float64) (PhysicsLevel, bool) {
+	for i := 0; i < n; i++ {
+		if level, changed := g.Update(frameTime, stepTime); changed {
+			return level, true
+		}
+	}
+	return g.GetLevel(), false
+}
+
+// TestPhysicsGovernorStepsDownAndRecovers tests degrading through every
+// physics level over budget and restoring them with headroom
+func TestPhysicsGovernorStepsDownAndRecovers(t *testing.T) {
+	g := NewPhysicsGovernor(testOptions())
+	for _, want := range []PhysicsLevel{PhysicsSkipDiagnostics, PhysicsCoarseView, PhysicsSubsampled} {
+		level, changed := runPhysics(g, 100, 0.04, 0.03)
+		if !changed || level != want {
+			t.Fatalf("Expected a step down to %v, got %v (changed %v)", want, level, changed)
+		}
+	}
+	if level, changed := runPhysics(g, 100, 0.04, 0.03); changed {
+		t.Fatalf("Expected to stay at the lowest level, got %v", level)
+	}
+
+	for _, want := range []PhysicsLevel{PhysicsCoarseView, PhysicsSkipDiagnostics, PhysicsFull} {
+		level, changed := runPhysics(g, 100, 0.016, 0.002)
+		if !changed || level != want {
+			t.Fatalf("Expected a step up to %v, got %v (changed %v)", want, level, changed)
+		}
+	}
+}
+
+// TestPhysicsLevelReductions tests what each physics level reduces
+func TestPhysicsLevelReductions(t *testing.T) {
+	tests := []struct {
+		level     PhysicsLevel
+		skip      bool
+		flowScale int
+		view      int
+		stride    int
+	}{
+		{PhysicsFull, false, 1, 1, 1},
+		{PhysicsSkipDiagnostics, true, 4, 1, 1},
+		{PhysicsCoarseView, true, 4, 2, 1},
+		{PhysicsSubsampled, true, 4, 2, 2},
+	}
+	for _, tt := range tests {
+		if tt.level.SkipDiagnostics() != tt.skip || tt.level.FlowIntervalScale() != tt.flowScale ||
+			tt.level.ViewFactor() != tt.view || tt.level.DepositStride() != tt.stride {
+			t.Errorf("Unexpected reductions for %v", tt.level)
+		}
+	}
+}
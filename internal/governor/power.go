@@ -0,0 +1,46 @@
+package governor
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// PowerSupplyRoot is the sysfs directory listing the machine's power supplies
+const PowerSupplyRoot = "/sys/class/power_supply"
+
+// OnBattery reports whether the machine runs from a battery with no mains
+// power connected. It returns false where power supplies cannot be read
+func OnBattery() bool {
+	return onBatteryIn(PowerSupplyRoot)
+}
+
+// onBatteryIn reads the power supplies below a power_supply class directory
+func onBatteryIn(root string) bool {
+	entries, err := os.ReadDir(root)
+	if err != nil {
+		return false
+	}
+	battery, mains := false, false
+	for _, entry := range entries {
+		dir := filepath.Join(root, entry.Name())
+		switch readAttribute(filepath.Join(dir, "type")) {
+		case "Battery":
+			battery = true
+		case "Mains":
+			if readAttribute(filepath.Join(dir, "online")) == "1" {
+				mains = true
+			}
+		}
+	}
+	return battery && !mains
+}
+
+// readAttribute returns a trimmed sysfs attribute ("" if unreadable)
+func readAttribute(path string) string {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return ""
+	}
+	return strings.TrimSpace(string(data))
+}
@@ -0,0 +1,53 @@
+package governor
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+// writeSupply creates a fake sysfs power supply
+func writeSupply(t *testing.T, root, name string, attrs map[string]string) {
+	t.Helper()
+	dir := filepath.Join(root, name)
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		t.Fatal(err)
+	}
+	for attr, value := range attrs {
+		if err := os.WriteFile(filepath.Join(dir, attr), []byte(value+"\n"), 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+}
+
+// TestOnBattery tests the laptop power states
+func TestOnBattery(t *testing.T) {
+	tests := []struct {
+		name   string
+		online string // Mains online attribute ("" = no mains supply)
+		want   bool
+	}{
+		{"unplugged", "0", true},
+		{"plugged in", "1", false},
+		{"battery only", "", true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			root := t.TempDir()
+			writeSupply(t, root, "BAT0", map[string]string{"type": "Battery"})
+			if tt.online != "" {
+				writeSupply(t, root, "AC", map[string]string{"type": "Mains", "online": tt.online})
+			}
+			if got := onBatteryIn(root); got != tt.want {
+				t.Errorf("onBatteryIn() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+
+	// Desktops have no battery; unreadable systems report mains power
+	desktop := t.TempDir()
+	writeSupply(t, desktop, "AC", map[string]string{"type": "Mains", "online": "0"})
+	if onBatteryIn(desktop) || onBatteryIn(filepath.Join(desktop, "missing")) {
+		t.Error("Expected no battery")
+	}
+}
@@ -0,0 +1,151 @@
+package gpu
+
+import (
+	"encoding/json"
+	"fmt"
+	"math"
+	"os"
+	"path/filepath"
+	"time"
+)
+
+// Default work group sizes of the FFT and Green's function kernels
+const (
+	DefaultFFTLocalSize = 32
+	DefaultDFTLocalSize = 64
+)
+
+// WorkgroupCandidates are the work group sizes the autotuner tries
+var WorkgroupCandidates = []int{32, 64, 128, 256}
+
+// Workgroups are the work group sizes of the Poisson solver kernels. Zero
+// fields use the defaults
+type Workgroups struct {
+	FFT    int `json:"fft"`    // Radix-2 FFT passes
+	DFT    int `json:"dft"`    // Naive DFT for grid sizes that are not powers of two
+	Greens int `json:"greens"` // Green's function
+}
+
+// FFTSize returns the work group size of the radix-2 FFT
+func (w Workgroups) FFTSize() int {
+	if w.FFT <= 0 {
+		return DefaultFFTLocalSize
+	}
+	return w.FFT
+}
+
+// DFTSize returns the work group size of the naive DFT
+func (w Workgroups) DFTSize() int {
+	if w.DFT <= 0 {
+		return DefaultDFTLocalSize
+	}
+	return w.DFT
+}
+
+// GreensSize returns the work group size of the Green's function
+func (w Workgroups) GreensSize() int {
+	return KernelParams{LocalSize: w.Greens}.GroupSize()
+}
+
+// WorkgroupTuner keeps the work group sizes found by benchmarking the
+// kernels on this GPU, so that each driver and grid size is tuned only once.
+// The sizes are stored as JSON next to the program binaries
+type WorkgroupTuner struct {
+	Path   string // JSON file of the tuned sizes
+	Driver string // Vendor, renderer and version strings of the GL driver
+	Grid   string // Grid the GPU's current sizes apply to ("" = none yet)
+}
+
+// NewWorkgroupTuner creates a tuner storing its results in dir
+func NewWorkgroupTuner(dir, driver string) *WorkgroupTuner {
+	return &WorkgroupTuner{Path: filepath.Join(dir, "workgroups.json"), Driver: driver}
+}
+
+// Key returns the entry of a grid size for this tuner's driver
+func (t *WorkgroupTuner) Key(width, height int) string {
+	return fmt.Sprintf("%s\n%dx%d", t.Driver, width, height)
+}
+
+// read returns all stored entries; a missing or damaged file reads as empty
+func (t *WorkgroupTuner) read() map[string]Workgroups {
+	entries := make(map[string]Workgroups)
+	if data, err := os.ReadFile(t.Path); err == nil {
+		if json.Unmarshal(data, &entries) != nil {
+			return make(map[string]Workgroups)
+		}
+	}
+	return entries
+}
+
+// Load returns the stored sizes for a grid size, or false if it has not been
+// tuned with this driver
+func (t *WorkgroupTuner) Load(width, height int) (Workgroups, bool) {
+	w, ok := t.read()[t.Key(width, height)]
+	return w, ok
+}
+
+// Store saves the sizes for a grid size, keeping the other entries. The file
+// is replaced in one rename
+func (t *WorkgroupTuner) Store(width, height int, w Workgroups) error {
+	entries := t.read()
+	entries[t.Key(width, height)] = w
+	data, err := json.MarshalIndent(entries, "", "  ")
+	if err != nil {
+		return err
+	}
+	dir := filepath.Dir(t.Path)
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return err
+	}
+	tmp, err := os.CreateTemp(dir, "workgroups.*.tmp")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(tmp.Name())
+	_, err = tmp.Write(data)
+	if closeErr := tmp.Close(); err == nil {
+		err = closeErr
+	}
+	if err != nil {
+		return err
+	}
+	return os.Rename(tmp.Name(), t.Path)
+}
+
+// FastestSize benchmarks run for each candidate size up to maxSize and
+// returns the fastest. Each candidate runs once to warm up and is then
+// timed over the given number of runs, keeping its best time. Candidates
+// that fail are skipped; if all fail the last error is returned
+func FastestSize(candidates []int, maxSize, runs int, run func(size int) error) (int, error) {
+	best, bestTime := 0, time.Duration(math.MaxInt64)
+	var lastErr error
+	for _, size := range candidates {
+		if maxSize > 0 && size > maxSize {
+			continue
+		}
+		if err := run(size); err != nil {
+			lastErr = err
+			continue
+		}
+		elapsed := time.Duration(math.MaxInt64)
+		for i := 0; i < runs; i++ {
+			start := time.Now()
+			if err := run(size); err != nil {
+				lastErr = err
+				elapsed = time.Duration(math.MaxInt64)
+				break
+			}
+			elapsed = min(elapsed, time.Since(start))
+		}
+		if elapsed < bestTime {
+			best, bestTime = size, elapsed
+		}
+	}
+	if best == 0 {
+		if lastErr == nil {
+			lastErr = fmt.Errorf("invalid work group candidates: none up to %d", maxSize)
+		}
+		return 0, lastErr
+	}
+	return best, nil
+}
@@ -0,0 +1,65 @@
+package gpu
+
+import (
+	"errors"
+	"testing"
+	"time"
+)
+
+// TestWorkgroupsDefaults tests the sizes of unset fields
+func TestWorkgroupsDefaults(t *testing.T) {
+	var w Workgroups
+	if w.FFTSize() != DefaultFFTLocalSize || w.DFTSize() != DefaultDFTLocalSize || w.GreensSize() != DefaultKernelLocalSize {
+		t.Errorf("Expected the default sizes, got %d, %d and %d", w.FFTSize(), w.DFTSize(), w.GreensSize())
+	}
+	w = Workgroups{FFT: 128, DFT: 256, Greens: 32}
+	if w.FFTSize() != 128 || w.DFTSize() != 256 || w.GreensSize() != 32 {
+		t.Errorf("Expected the set sizes, got %d, %d and %d", w.FFTSize(), w.DFTSize(), w.GreensSize())
+	}
+}
+
+// TestWorkgroupTunerStore tests that tuned sizes persist per driver and grid
+func TestWorkgroupTunerStore(t *testing.T) {
+	dir := t.TempDir()
+	tuner := NewWorkgroupTuner(dir, "driver")
+	if _, ok := tuner.Load(64, 64); ok {
+		t.Fatal("Expected no sizes before tuning")
+	}
+
+	want := Workgroups{FFT: 64, Greens: 128}
+	if err := tuner.Store(64, 64, want); err != nil {
+		t.Fatal(err)
+	}
+	if err := tuner.Store(48, 48, Workgroups{DFT: 256}); err != nil {
+		t.Fatal(err)
+	}
+	if got, ok := NewWorkgroupTuner(dir, "driver").Load(64, 64); !ok || got != want {
+		t.Errorf("Expected %+v, got %+v (ok %v)", want, got, ok)
+	}
+	if _, ok := NewWorkgroupTuner(dir, "other driver").Load(64, 64); ok {
+		t.Error("Expected another driver to need its own tuning")
+	}
+}
+
+// TestFastestSize tests the choice of the fastest candidate
+func TestFastestSize(t *testing.T) {
+	delays := map[int]time.Duration{32: 3 * time.Millisecond, 64: time.Millisecond, 128: 2 * time.Millisecond}
+	run := func(size int) error {
+		if size == 256 {
+			return errors.New("unsupported")
+		}
+		time.Sleep(delays[size])
+		return nil
+	}
+	size, err := FastestSize(WorkgroupCandidates, 0, 2, run)
+	if err != nil || size != 64 {
+		t.Errorf("Expected 64, got %d (%v)", size, err)
+	}
+
+	if size, _ := FastestSize([]int{32, 128}, 64, 1, run); size != 32 {
+		t.Errorf("Expected candidates above the limit to be skipped, got %d", size)
+	}
+	if _, err := FastestSize([]int{256}, 0, 1, run); err == nil {
+		t.Error("Expected an error when every candidate fails")
+	}
+}
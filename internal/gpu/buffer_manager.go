@@ -2,30 +2,144 @@ package gpu
 
 import (
 	"errors"
+	"fmt"
+	"runtime"
+	"sync"
 )
 
-// BufferManager manages GPU buffer creation and operations
+// BufferManager manages GPU buffer creation and operations. It is safe for
+// concurrent use; buffers taken from the pool are tracked until they are
+// returned or freed, so that leaks can be listed
 type BufferManager struct {
-	bufferPool map[int][]*GPUMemoryBuffer // Pool of reusable buffers by size
+	mu          sync.Mutex
+	bufferPool  map[int][]*GPUMemoryBuffer // Pool of reusable buffers by size
+	outstanding map[*GPUMemoryBuffer]string
+	debug       bool             // Record where outstanding buffers were acquired
+	stats       PoolStats        // Pool counters, without the derived sizes
+	metrics     *FallbackManager // Receives the pool statistics (nil = none)
+}
+
+// PoolStats are the counters of a BufferManager's pool
+type PoolStats struct {
+	Hits        int // GetPooledBuffer calls served from the pool
+	Misses      int // GetPooledBuffer calls that found no buffer
+	Returns     int // Buffers returned to the pool
+	Pooled      int // Buffers waiting in the pool
+	Outstanding int // Buffers handed out and not yet returned or freed
+}
+
+// BufferLeak describes a buffer that was never returned or freed
+type BufferLeak struct {
+	Buffer *GPUMemoryBuffer
+	Origin string // Caller that acquired it ("" unless debug mode was on)
+}
+
+// String describes the leak for logs
+func (l BufferLeak) String() string {
+	origin := l.Origin
+	if origin == "" {
+		origin = "unknown caller"
+	}
+	return fmt.Sprintf("buffer %d (%d bytes) acquired by %s", l.Buffer.BufferID, l.Buffer.Size, origin)
 }
 
 // NewBufferManager creates a new buffer manager
 func NewBufferManager() *BufferManager {
 	return &BufferManager{
-		bufferPool: make(map[int][]*GPUMemoryBuffer),
+		bufferPool:  make(map[int][]*GPUMemoryBuffer),
+		outstanding: make(map[*GPUMemoryBuffer]string),
+	}
+}
+
+// SetDebug turns on recording the caller of every acquired buffer, which
+// Leaks reports
+func (m *BufferManager) SetDebug(enabled bool) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.debug = enabled
+}
+
+// SetMetrics sets the fallback manager that receives the pool statistics
+// after every pool operation
+func (m *BufferManager) SetMetrics(metrics *FallbackManager) {
+	m.mu.Lock()
+	m.metrics = metrics
+	m.mu.Unlock()
+	m.report()
+}
+
+// Track registers a buffer created outside the pool as outstanding, so that
+// it is reported as a leak until it is returned or freed
+func (m *BufferManager) Track(buffer *GPUMemoryBuffer) {
+	if buffer == nil {
+		return
 	}
+	m.mu.Lock()
+	m.acquire(buffer)
+	m.mu.Unlock()
+	m.report()
+}
+
+// acquire marks a buffer as handed out; m.mu must be held
+func (m *BufferManager) acquire(buffer *GPUMemoryBuffer) {
+	origin := ""
+	if m.debug {
+		// Skip acquire and the exported method to name their caller
+		if _, file, line, ok := runtime.Caller(2); ok {
+			origin = fmt.Sprintf("%s:%d", file, line)
+		}
+	}
+	m.outstanding[buffer] = origin
+}
+
+// Stats returns the pool counters
+func (m *BufferManager) Stats() PoolStats {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return m.statsLocked()
+}
+
+// statsLocked returns the pool counters; m.mu must be held
+func (m *BufferManager) statsLocked() PoolStats {
+	stats := m.stats
+	for _, buffers := range m.bufferPool {
+		stats.Pooled += len(buffers)
+	}
+	stats.Outstanding = len(m.outstanding)
+	return stats
+}
+
+// report passes the pool counters to the metrics, outside the pool lock
+func (m *BufferManager) report() {
+	m.mu.Lock()
+	metrics, stats := m.metrics, m.statsLocked()
+	m.mu.Unlock()
+	if metrics != nil {
+		metrics.RecordPoolStats(stats)
+	}
+}
+
+// Leaks returns the buffers handed out and never returned or freed
+func (m *BufferManager) Leaks() []BufferLeak {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	leaks := make([]BufferLeak, 0, len(m.outstanding))
+	for buffer, origin := range m.outstanding {
+		leaks = append(leaks, BufferLeak{Buffer: buffer, Origin: origin})
+	}
+	return leaks
 }
 
 // CreateFloatBuffer creates a GPU buffer for float data
 func (m *BufferManager) CreateFloatBuffer(elementCount int) (*GPUMemoryBuffer, error) {
 	// Without OpenGL context, we cannot actually create GPU buffers
-	return nil, errors.New("OpenGL context not available")
+	return nil, ErrNoGLContext
 }
 
 // CreateComplexBuffer creates a GPU buffer for complex data
 func (m *BufferManager) CreateComplexBuffer(elementCount int) (*ComplexGPUBuffer, error) {
 	// Without OpenGL context, we cannot actually create GPU buffers
-	return nil, errors.New("OpenGL context not available")
+	return nil, ErrNoGLContext
 }
 
 // FreeBuffer frees a GPU buffer
@@ -34,6 +148,11 @@ func (m *BufferManager) FreeBuffer(buffer *GPUMemoryBuffer) error {
 		return nil
 	}
 
+	m.mu.Lock()
+	delete(m.outstanding, buffer)
+	m.mu.Unlock()
+	m.report()
+
 	// Mark buffer as freed
 	buffer.BufferID = 0
 	buffer.Size = 0
@@ -137,15 +256,22 @@ func (m *BufferManager) SynchronizeBuffer(buffer *GPUMemoryBuffer) error {
 
 // GetPooledBuffer gets a buffer from the pool
 func (m *BufferManager) GetPooledBuffer(size int) *GPUMemoryBuffer {
+	defer m.report()
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
 	// Check if we have a buffer of this size in the pool
 	if buffers, ok := m.bufferPool[size]; ok && len(buffers) > 0 {
 		// Pop buffer from pool
 		buffer := buffers[len(buffers)-1]
 		m.bufferPool[size] = buffers[:len(buffers)-1]
+		m.stats.Hits++
+		m.acquire(buffer)
 		return buffer
 	}
 
 	// No buffer available in pool
+	m.stats.Misses++
 	return nil
 }
 
@@ -154,8 +280,13 @@ func (m *BufferManager) ReturnToPool(buffer *GPUMemoryBuffer) {
 	if buffer == nil || buffer.Size == 0 {
 		return
 	}
+	defer m.report()
+	m.mu.Lock()
+	defer m.mu.Unlock()
 
 	// Add to pool
 	size := buffer.Size
 	m.bufferPool[size] = append(m.bufferPool[size], buffer)
+	m.stats.Returns++
+	delete(m.outstanding, buffer)
 }
@@ -1,6 +1,9 @@
 package gpu
 
 import (
+	"errors"
+	"strings"
+	"sync"
 	"testing"
 )
 
@@ -23,7 +26,7 @@ func TestBufferCreation(t *testing.T) {
 	floatBuffer, err := manager.CreateFloatBuffer(1024)
 	if err != nil {
 		// Expected to fail without OpenGL context
-		if err.Error() != "OpenGL context not available" {
+		if !errors.Is(err, ErrNoGLContext) {
 			t.Errorf("Unexpected error: %v", err)
 		}
 	} else {
@@ -39,7 +42,7 @@ func TestBufferCreation(t *testing.T) {
 	complexBuffer, err := manager.CreateComplexBuffer(512)
 	if err != nil {
 		// Expected to fail without OpenGL context
-		if err.Error() != "OpenGL context not available" {
+		if !errors.Is(err, ErrNoGLContext) {
 			t.Errorf("Unexpected error: %v", err)
 		}
 	} else {
@@ -251,3 +254,63 @@ func TestBufferPool(t *testing.T) {
 		}
 	}
 }
+
+// TestBufferPoolConcurrent tests that the pool serves concurrent users
+// without losing buffers and counts hits and misses
+func TestBufferPoolConcurrent(t *testing.T) {
+	manager := NewBufferManager()
+	const workers, rounds = 8, 100
+	for i := 0; i < workers; i++ {
+		manager.ReturnToPool(&GPUMemoryBuffer{BufferID: uint32(i + 1), Size: 256})
+	}
+
+	var wg sync.WaitGroup
+	for i := 0; i < workers; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for j := 0; j < rounds; j++ {
+				if buffer := manager.GetPooledBuffer(256); buffer != nil {
+					manager.ReturnToPool(buffer)
+				}
+			}
+		}()
+	}
+	wg.Wait()
+
+	stats := manager.Stats()
+	if stats.Hits+stats.Misses != workers*rounds {
+		t.Errorf("Expected %d requests, got %d hits and %d misses", workers*rounds, stats.Hits, stats.Misses)
+	}
+	if stats.Pooled != workers || stats.Outstanding != 0 {
+		t.Errorf("Expected all %d buffers back in the pool, got %d pooled and %d outstanding", workers, stats.Pooled, stats.Outstanding)
+	}
+}
+
+// TestBufferLeaks tests that buffers never returned are reported with their
+// origin in debug mode
+func TestBufferLeaks(t *testing.T) {
+	manager := NewBufferManager()
+	manager.SetDebug(true)
+	metrics := NewFallbackManager()
+	manager.SetMetrics(metrics)
+
+	manager.ReturnToPool(&GPUMemoryBuffer{BufferID: 1, Size: 64})
+	leaked := manager.GetPooledBuffer(64)
+	created := &GPUMemoryBuffer{BufferID: 2, Size: 64}
+	manager.Track(created)
+	_ = manager.FreeBuffer(created)
+
+	leaks := manager.Leaks()
+	if len(leaks) != 1 || leaks[0].Buffer != leaked {
+		t.Fatalf("Expected the pooled buffer as the only leak, got %v", leaks)
+	}
+	if !strings.Contains(leaks[0].Origin, "buffer_manager_test.go") {
+		t.Errorf("Expected the leak to name this test as its origin, got %q", leaks[0].Origin)
+	}
+
+	pool := metrics.GetPerformanceStats().Pool
+	if pool.Hits != 1 || pool.Returns != 1 || pool.Outstanding != 1 {
+		t.Errorf("Expected 1 hit, 1 return and 1 outstanding buffer in the metrics, got %+v", pool)
+	}
+}
@@ -0,0 +1,72 @@
+package gpu
+
+import "strings"
+
+// Extensions that provide the compute path on contexts older than OpenGL 4.3
+const (
+	ComputeShaderExtension = "GL_ARB_compute_shader"
+	StorageBufferExtension = "GL_ARB_shader_storage_buffer_object"
+	ProgramBinaryExtension = "GL_ARB_get_program_binary"
+)
+
+// MemoryInfoExtension reports the dedicated video memory in use (NVIDIA)
+const MemoryInfoExtension = "GL_NVX_gpu_memory_info"
+
+// Capabilities describe what the current OpenGL context supports. They are
+// queried once at initialization and decide which GPU features are used
+type Capabilities struct {
+	Major, Minor int
+	Renderer     string
+	Extensions   []string
+}
+
+// AtLeast reports whether the context version is at least major.minor
+func (c Capabilities) AtLeast(major, minor int) bool {
+	return c.Major > major || (c.Major == major && c.Minor >= minor)
+}
+
+// HasExtension reports whether the context advertises an extension
+func (c Capabilities) HasExtension(name string) bool {
+	for _, ext := range c.Extensions {
+		if strings.TrimSpace(ext) == name {
+			return true
+		}
+	}
+	return false
+}
+
+// Compute reports whether compute shaders and shader storage buffers are
+// available. Without them every GPU solver falls back to the CPU
+func (c Capabilities) Compute() bool {
+	return c.AtLeast(4, 3) || (c.HasExtension(ComputeShaderExtension) && c.HasExtension(StorageBufferExtension))
+}
+
+// BufferStorage reports whether particle uploads can use persistently
+// mapped buffers
+func (c Capabilities) BufferStorage() bool {
+	return SupportsBufferStorage(c.Major, c.Minor, c.Extensions)
+}
+
+// ProgramBinary reports whether linked programs can be saved to the shader
+// cache
+func (c Capabilities) ProgramBinary() bool {
+	return c.AtLeast(4, 1) || c.HasExtension(ProgramBinaryExtension)
+}
+
+// MemoryInfo reports whether the video memory in use can be queried
+func (c Capabilities) MemoryInfo() bool {
+	return c.HasExtension(MemoryInfoExtension)
+}
+
+// Degraded lists the optional features the context lacks. The GPU path
+// still runs without them, only slower
+func (c Capabilities) Degraded() []string {
+	var missing []string
+	if !c.BufferStorage() {
+		missing = append(missing, "persistent upload buffers")
+	}
+	if !c.ProgramBinary() {
+		missing = append(missing, "shader binary cache")
+	}
+	return missing
+}
@@ -0,0 +1,52 @@
+package gpu
+
+import (
+	"errors"
+	"reflect"
+	"testing"
+)
+
+// TestCapabilitiesCompute tests the version and extension checks of the compute path
+func TestCapabilitiesCompute(t *testing.T) {
+	tests := []struct {
+		caps Capabilities
+		want bool
+	}{
+		{Capabilities{Major: 4, Minor: 6}, true},
+		{Capabilities{Major: 4, Minor: 3}, true},
+		{Capabilities{Major: 4, Minor: 2}, false},
+		{Capabilities{Major: 3, Minor: 3}, false},
+		{Capabilities{Major: 4, Minor: 2, Extensions: []string{ComputeShaderExtension, StorageBufferExtension}}, true},
+		{Capabilities{Major: 4, Minor: 2, Extensions: []string{ComputeShaderExtension}}, false},
+	}
+	for _, tt := range tests {
+		if got := tt.caps.Compute(); got != tt.want {
+			t.Errorf("Compute() for %d.%d %v = %v, want %v", tt.caps.Major, tt.caps.Minor, tt.caps.Extensions, got, tt.want)
+		}
+	}
+}
+
+// TestCapabilitiesDegraded tests the list of missing optional features
+func TestCapabilitiesDegraded(t *testing.T) {
+	if got := (Capabilities{Major: 4, Minor: 4}).Degraded(); len(got) != 0 {
+		t.Errorf("Expected nothing missing on 4.4, got %v", got)
+	}
+	want := []string{"persistent upload buffers", "shader binary cache"}
+	if got := (Capabilities{Major: 4, Minor: 0}).Degraded(); !reflect.DeepEqual(got, want) {
+		t.Errorf("Expected %v, got %v", want, got)
+	}
+	if got := (Capabilities{Major: 4, Minor: 0, Extensions: []string{ProgramBinaryExtension}}).Degraded(); len(got) != 1 {
+		t.Errorf("Expected the extension to provide the binary cache, got %v", got)
+	}
+}
+
+// TestUnsupportedGLError tests that the error names the context and unwraps
+func TestUnsupportedGLError(t *testing.T) {
+	err := NewUnsupportedGLError(Capabilities{Major: 3, Minor: 1, Renderer: "llvmpipe"})
+	if !errors.Is(err, ErrUnsupportedGL) {
+		t.Error("Expected errors.Is(err, ErrUnsupportedGL)")
+	}
+	if want := "OpenGL compute shaders not supported: OpenGL 3.1 on llvmpipe, 4.3 needed"; err.Error() != want {
+		t.Errorf("Expected %q, got %q", want, err.Error())
+	}
+}
@@ -0,0 +1,166 @@
+package gpu
+
+import (
+	"errors"
+	"sync"
+)
+
+// ContextOwner identifies who created the current OpenGL context
+type ContextOwner int
+
+const (
+	// ContextNone means no context exists
+	ContextNone ContextOwner = iota
+	// ContextWindow is the application's visible raylib window. GPU users
+	// borrow it and never close it
+	ContextWindow
+	// ContextHidden is a hidden 1x1 window created for GPU work without a
+	// visible window (tests, headless runs). It is closed with its last user
+	ContextHidden
+)
+
+// String returns string representation of ContextOwner
+func (o ContextOwner) String() string {
+	switch o {
+	case ContextNone:
+		return "None"
+	case ContextWindow:
+		return "Window"
+	case ContextHidden:
+		return "Hidden"
+	default:
+		return "Unknown"
+	}
+}
+
+// ErrContextInUse is returned when the window is opened while a hidden GPU
+// context is still in use. raylib supports a single window, so opening the
+// real window would replace the context under the GPU users
+var ErrContextInUse = errors.New("hidden GPU context is still in use")
+
+// ErrWindowOpen is returned when the window is opened twice
+var ErrWindowOpen = errors.New("window is already open")
+
+// ContextPlatform is the windowing layer a GLContext drives
+type ContextPlatform interface {
+	// WindowReady reports whether a window, and so a current context, exists
+	WindowReady() bool
+	// OpenWindow creates a window and makes its context current on the calling thread
+	OpenWindow(width, height int, title string, hidden bool)
+	// CloseWindow destroys the window and its context
+	CloseWindow()
+	// LoadFunctions resolves the OpenGL entry points of the current context
+	LoadFunctions() error
+}
+
+// GLContext owns the single OpenGL context of the process. It decides whether
+// GPU users borrow the application window or share one hidden window, and
+// counts the users so a hidden window is closed exactly once and never
+// coexists with the real window. The context is current on the OS thread that
+// opened its window; all GL calls must be made from that thread
+type GLContext struct {
+	mu       sync.Mutex
+	platform ContextPlatform
+	owner    ContextOwner
+	refs     int
+}
+
+// NewGLContext creates a context manager for the given platform
+func NewGLContext(platform ContextPlatform) *GLContext {
+	return &GLContext{platform: platform}
+}
+
+// OpenWindow opens the application window. It fails if a hidden GPU context
+// is in use instead of silently replacing it
+func (c *GLContext) OpenWindow(width, height int, title string) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	switch c.owner {
+	case ContextHidden:
+		return ErrContextInUse
+	case ContextWindow:
+		return ErrWindowOpen
+	}
+	if c.platform.WindowReady() {
+		return ErrWindowOpen
+	}
+	c.platform.OpenWindow(width, height, title, false)
+	c.owner = ContextWindow
+	return nil
+}
+
+// CloseWindow closes the application window. GPU users must have released
+// the context first; any that have not are left without a context
+func (c *GLContext) CloseWindow() {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if c.owner != ContextWindow {
+		return
+	}
+	c.platform.CloseWindow()
+	c.owner = ContextNone
+	c.refs = 0
+}
+
+// Acquire makes a context available to a GPU user and returns its owner. An
+// open window, including one opened outside the manager, is shared even when
+// headless is requested, since a second window would conflict with it.
+// Otherwise all users share one hidden window. Each successful Acquire must
+// be paired with a Release
+func (c *GLContext) Acquire() (ContextOwner, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if c.owner == ContextNone {
+		if c.platform.WindowReady() {
+			c.owner = ContextWindow
+		} else {
+			c.platform.OpenWindow(1, 1, "GPU Context", true)
+			c.owner = ContextHidden
+		}
+	}
+
+	if c.refs == 0 {
+		if err := c.platform.LoadFunctions(); err != nil {
+			if c.owner == ContextHidden {
+				c.platform.CloseWindow()
+				c.owner = ContextNone
+			}
+			return ContextNone, err
+		}
+	}
+	c.refs++
+	return c.owner, nil
+}
+
+// Release ends one Acquire. The hidden window is closed when its last user
+// releases it; the application window is left open
+func (c *GLContext) Release() {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if c.refs == 0 {
+		return
+	}
+	c.refs--
+	if c.refs == 0 && c.owner == ContextHidden {
+		c.platform.CloseWindow()
+		c.owner = ContextNone
+	}
+}
+
+// GetOwner returns who owns the current context
+func (c *GLContext) GetOwner() ContextOwner {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.owner
+}
+
+// GetRefCount returns the number of GPU users holding the context
+func (c *GLContext) GetRefCount() int {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.refs
+}
@@ -0,0 +1,130 @@
+package gpu
+
+import (
+	"errors"
+	"testing"
+)
+
+// fakePlatform records the windows a GLContext opens and closes
+type fakePlatform struct {
+	ready   bool
+	hidden  bool
+	opened  int
+	closed  int
+	loadErr error
+}
+
+func (p *fakePlatform) WindowReady() bool { return p.ready }
+
+func (p *fakePlatform) OpenWindow(width, height int, title string, hidden bool) {
+	if p.ready {
+		panic("second window opened")
+	}
+	p.ready, p.hidden = true, hidden
+	p.opened++
+}
+
+func (p *fakePlatform) CloseWindow() {
+	p.ready = false
+	p.closed++
+}
+
+func (p *fakePlatform) LoadFunctions() error { return p.loadErr }
+
+// TestGLContextSharedHiddenWindow tests that GPU users without a window share
+// one hidden window that closes with the last user
+func TestGLContextSharedHiddenWindow(t *testing.T) {
+	platform := &fakePlatform{}
+	ctx := NewGLContext(platform)
+
+	for i := 0; i < 2; i++ {
+		owner, err := ctx.Acquire()
+		if err != nil || owner != ContextHidden {
+			t.Fatalf("Acquire = %v, %v; want Hidden", owner, err)
+		}
+	}
+	if platform.opened != 1 || !platform.hidden {
+		t.Errorf("Expected one hidden window, opened %d", platform.opened)
+	}
+
+	ctx.Release()
+	if platform.closed != 0 {
+		t.Error("Hidden window closed while still in use")
+	}
+	ctx.Release()
+	ctx.Release() // Unpaired releases are ignored
+	if platform.closed != 1 || ctx.GetOwner() != ContextNone || ctx.GetRefCount() != 0 {
+		t.Errorf("Expected the hidden window closed once, closed %d, owner %v", platform.closed, ctx.GetOwner())
+	}
+}
+
+// TestGLContextWindow tests that GPU users borrow the application window
+func TestGLContextWindow(t *testing.T) {
+	platform := &fakePlatform{}
+	ctx := NewGLContext(platform)
+
+	if err := ctx.OpenWindow(800, 600, "test"); err != nil {
+		t.Fatal(err)
+	}
+	if err := ctx.OpenWindow(800, 600, "test"); !errors.Is(err, ErrWindowOpen) {
+		t.Errorf("Expected ErrWindowOpen, got %v", err)
+	}
+	owner, err := ctx.Acquire()
+	if err != nil || owner != ContextWindow {
+		t.Fatalf("Acquire = %v, %v; want Window", owner, err)
+	}
+	ctx.Release()
+	if platform.closed != 0 {
+		t.Error("Releasing the last GPU user closed the application window")
+	}
+	ctx.CloseWindow()
+	if platform.closed != 1 || ctx.GetOwner() != ContextNone {
+		t.Errorf("Expected the window closed, owner %v", ctx.GetOwner())
+	}
+}
+
+// TestGLContextExternalWindow tests that a window opened outside the manager
+// is shared instead of opening a conflicting hidden one
+func TestGLContextExternalWindow(t *testing.T) {
+	platform := &fakePlatform{ready: true}
+	ctx := NewGLContext(platform)
+
+	owner, err := ctx.Acquire()
+	if err != nil || owner != ContextWindow {
+		t.Fatalf("Acquire = %v, %v; want Window", owner, err)
+	}
+	if platform.opened != 0 {
+		t.Error("Opened a window alongside the existing one")
+	}
+}
+
+// TestGLContextWindowConflict tests that the window cannot replace a hidden
+// context in use
+func TestGLContextWindowConflict(t *testing.T) {
+	platform := &fakePlatform{}
+	ctx := NewGLContext(platform)
+
+	if _, err := ctx.Acquire(); err != nil {
+		t.Fatal(err)
+	}
+	if err := ctx.OpenWindow(800, 600, "test"); !errors.Is(err, ErrContextInUse) {
+		t.Errorf("Expected ErrContextInUse, got %v", err)
+	}
+	ctx.Release()
+	if err := ctx.OpenWindow(800, 600, "test"); err != nil {
+		t.Errorf("Expected the window to open once the hidden context is released, got %v", err)
+	}
+}
+
+// TestGLContextLoadFailure tests that a failed load closes the hidden window
+func TestGLContextLoadFailure(t *testing.T) {
+	platform := &fakePlatform{loadErr: errors.New("no GL")}
+	ctx := NewGLContext(platform)
+
+	if _, err := ctx.Acquire(); err == nil {
+		t.Fatal("Expected an error")
+	}
+	if platform.closed != 1 || ctx.GetOwner() != ContextNone || ctx.GetRefCount() != 0 {
+		t.Errorf("Expected the hidden window closed, closed %d, owner %v", platform.closed, ctx.GetOwner())
+	}
+}
@@ -0,0 +1,151 @@
+package gpu
+
+import (
+	"fmt"
+	"io"
+	"sync"
+)
+
+// DebugExtension provides the debug message callback on contexts older than
+// OpenGL 4.3
+const DebugExtension = "GL_KHR_debug"
+
+// Debug reports whether the driver can report errors and warnings through a
+// debug message callback
+func (c Capabilities) Debug() bool {
+	return c.AtLeast(4, 3) || c.HasExtension(DebugExtension)
+}
+
+// DebugSeverity is the severity of a driver debug message, lowest first
+type DebugSeverity int
+
+const (
+	DebugSeverityNotification DebugSeverity = iota // Informational, e.g. where a buffer was placed
+	DebugSeverityLow                               // Redundant state changes, minor performance issues
+	DebugSeverityMedium                            // Major performance issues, deprecated use
+	DebugSeverityHigh                              // Errors and undefined behavior
+	DebugSeverityOff                               // Above every message: none are logged
+)
+
+// ParseDebugSeverity parses the configured lowest logged severity: off,
+// high, medium, low or all
+func ParseDebugSeverity(s string) (DebugSeverity, error) {
+	switch s {
+	case "", "medium":
+		return DebugSeverityMedium, nil
+	case "off":
+		return DebugSeverityOff, nil
+	case "high":
+		return DebugSeverityHigh, nil
+	case "low":
+		return DebugSeverityLow, nil
+	case "all":
+		return DebugSeverityNotification, nil
+	default:
+		return DebugSeverityMedium, fmt.Errorf("unknown debug severity %q", s)
+	}
+}
+
+// String returns the name of the severity in log lines
+func (s DebugSeverity) String() string {
+	switch s {
+	case DebugSeverityNotification:
+		return "notification"
+	case DebugSeverityLow:
+		return "low"
+	case DebugSeverityMedium:
+		return "medium"
+	case DebugSeverityHigh:
+		return "high"
+	default:
+		return "off"
+	}
+}
+
+// DebugMessage is a message from the driver's debug callback
+type DebugMessage struct {
+	Source   string // Part of the stack that raised it, e.g. "api" or "shader compiler"
+	Type     string // e.g. "error", "performance" or "undefined behavior"
+	ID       uint32 // Driver-specific message number
+	Severity DebugSeverity
+	Text     string
+}
+
+// IsError reports whether the message reports a failed GL call
+func (m DebugMessage) IsError() bool {
+	return m.Type == "error"
+}
+
+// DebugRepeatLimit is how many times a message ID is logged before its
+// repeats are only counted, so a failing call in the frame loop cannot
+// flood the log
+const DebugRepeatLimit = 5
+
+// DebugLog receives the driver's debug messages. It logs those at or above
+// its severity and keeps the first error until it is taken, so the call that
+// checks for errors sees errors that glGetError misses because they were
+// raised asynchronously or already cleared. Messages may arrive on a driver
+// thread, so it is safe for concurrent use
+type DebugLog struct {
+	mu         sync.Mutex
+	out        io.Writer
+	min        DebugSeverity
+	seen       map[uint32]int
+	pending    *DebugMessage // First error since the last TakeError (nil = none)
+	logged     int
+	suppressed int
+}
+
+// NewDebugLog creates a debug log that writes messages of severity min and
+// above to out
+func NewDebugLog(out io.Writer, min DebugSeverity) *DebugLog {
+	return &DebugLog{out: out, min: min, seen: make(map[uint32]int)}
+}
+
+// MinSeverity returns the lowest severity that is logged
+func (l *DebugLog) MinSeverity() DebugSeverity {
+	return l.min
+}
+
+// Handle records a message from the debug callback
+func (l *DebugLog) Handle(m DebugMessage) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	if m.IsError() && l.pending == nil {
+		l.pending = &m
+	}
+	if m.Severity < l.min {
+		return
+	}
+	l.seen[m.ID]++
+	switch n := l.seen[m.ID]; {
+	case n < DebugRepeatLimit:
+		fmt.Fprintf(l.out, "GL %s %s (%s, id %d): %s\n", m.Severity, m.Type, m.Source, m.ID, m.Text)
+		l.logged++
+	case n == DebugRepeatLimit:
+		fmt.Fprintf(l.out, "GL %s %s (%s, id %d): %s (repeats not shown)\n", m.Severity, m.Type, m.Source, m.ID, m.Text)
+		l.logged++
+	default:
+		l.suppressed++
+	}
+}
+
+// TakeError returns the first error message since the last call as an
+// error, and clears it (nil = none)
+func (l *DebugLog) TakeError() error {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	if l.pending == nil {
+		return nil
+	}
+	m := l.pending
+	l.pending = nil
+	return fmt.Errorf("%s (%s, id %d)", m.Text, m.Source, m.ID)
+}
+
+// Counts returns the number of messages logged and of repeats left out
+func (l *DebugLog) Counts() (logged, suppressed int) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	return l.logged, l.suppressed
+}
@@ -0,0 +1,64 @@
+package gpu
+
+import (
+	"strings"
+	"testing"
+)
+
+// TestDebugLog tests severity filtering, repeat suppression and keeping the
+// first error for the next check
+func TestDebugLog(t *testing.T) {
+	var out strings.Builder
+	l := NewDebugLog(&out, DebugSeverityMedium)
+
+	l.Handle(DebugMessage{Source: "api", Type: "other", ID: 1, Severity: DebugSeverityNotification, Text: "buffer placed in video memory"})
+	l.Handle(DebugMessage{Source: "api", Type: "performance", ID: 2, Severity: DebugSeverityMedium, Text: "program recompiled"})
+	if out.String() != "GL medium performance (api, id 2): program recompiled\n" {
+		t.Fatalf("Expected only the medium message logged, got %q", out.String())
+	}
+	if err := l.TakeError(); err != nil {
+		t.Fatalf("Expected no error before one was reported, got %v", err)
+	}
+
+	for i := 0; i < DebugRepeatLimit+3; i++ {
+		l.Handle(DebugMessage{Source: "api", Type: "error", ID: 1282, Severity: DebugSeverityHigh, Text: "invalid operation"})
+	}
+	if logged, suppressed := l.Counts(); logged != 1+DebugRepeatLimit || suppressed != 3 {
+		t.Errorf("Expected %d logged and 3 suppressed, got %d and %d", 1+DebugRepeatLimit, logged, suppressed)
+	}
+	if !strings.Contains(out.String(), "(repeats not shown)") {
+		t.Error("Expected the last logged repeat to say further ones are not shown")
+	}
+
+	err := l.TakeError()
+	if err == nil || !strings.Contains(err.Error(), "invalid operation") {
+		t.Fatalf("Expected the error message, got %v", err)
+	}
+	if err := l.TakeError(); err != nil {
+		t.Errorf("Expected TakeError to clear the error, got %v", err)
+	}
+
+	quiet := NewDebugLog(&out, DebugSeverityOff)
+	quiet.Handle(DebugMessage{Type: "error", Severity: DebugSeverityHigh, Text: "out of memory"})
+	if quiet.TakeError() == nil {
+		t.Error("Expected errors kept even when none are logged")
+	}
+}
+
+// TestParseDebugSeverity tests the configured severity names
+func TestParseDebugSeverity(t *testing.T) {
+	for name, want := range map[string]DebugSeverity{
+		"": DebugSeverityMedium, "off": DebugSeverityOff, "high": DebugSeverityHigh,
+		"medium": DebugSeverityMedium, "low": DebugSeverityLow, "all": DebugSeverityNotification,
+	} {
+		if got, err := ParseDebugSeverity(name); err != nil || got != want {
+			t.Errorf("ParseDebugSeverity(%q) = %v, %v, want %v", name, got, err, want)
+		}
+	}
+	if _, err := ParseDebugSeverity("verbose"); err == nil {
+		t.Error("Expected an error for an unknown severity")
+	}
+	if !(Capabilities{Major: 4, Minor: 1, Extensions: []string{DebugExtension}}).Debug() || (Capabilities{Major: 4, Minor: 1}).Debug() {
+		t.Error("Expected the debug callback from OpenGL 4.3 or GL_KHR_debug")
+	}
+}
@@ -0,0 +1,158 @@
+package gpu
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// DRMRoot is the sysfs directory listing the kernel's display devices
+const DRMRoot = "/sys/class/drm"
+
+// PCI vendor IDs of the common GPU vendors
+const (
+	VendorAMD    = 0x1002
+	VendorIntel  = 0x8086
+	VendorNVIDIA = 0x10de
+)
+
+// Device is a GPU that an OpenGL context can be created on
+type Device struct {
+	Index    int    // 1-based position in EnumerateDevices order, as used by config.GPUDevice
+	Card     string // DRM card name, e.g. "card1"
+	PCISlot  string // PCI address, e.g. "0000:01:00.0"
+	VendorID uint16
+	DeviceID uint16
+	Driver   string // Kernel driver, e.g. "i915", "amdgpu" or "nvidia"
+	Default  bool   // Boot VGA device, which contexts land on unless told otherwise
+}
+
+// VendorName returns a readable vendor name
+func (d Device) VendorName() string {
+	switch d.VendorID {
+	case VendorAMD:
+		return "AMD"
+	case VendorIntel:
+		return "Intel"
+	case VendorNVIDIA:
+		return "NVIDIA"
+	default:
+		return fmt.Sprintf("vendor %04x", d.VendorID)
+	}
+}
+
+// String returns a one-line description for device listings
+func (d Device) String() string {
+	s := fmt.Sprintf("%d: %s %04x:%04x (%s, %s)", d.Index, d.VendorName(), d.VendorID, d.DeviceID, d.Driver, d.PCISlot)
+	if d.Default {
+		s += " [default]"
+	}
+	return s
+}
+
+// EnumerateDevices lists the PCI GPUs known to the kernel's DRM subsystem.
+// It returns no devices where DRM is unavailable (non-Linux systems)
+func EnumerateDevices() ([]Device, error) {
+	return enumerateDevicesIn(DRMRoot)
+}
+
+// enumerateDevicesIn lists the PCI GPUs below a DRM class directory
+func enumerateDevicesIn(root string) ([]Device, error) {
+	entries, err := os.ReadDir(root)
+	if os.IsNotExist(err) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to list GPUs: %v", err)
+	}
+
+	var devices []Device
+	for _, entry := range entries {
+		// Connectors such as card0-HDMI-A-1 share the directory with the cards
+		number, ok := strings.CutPrefix(entry.Name(), "card")
+		if !ok || strings.Contains(number, "-") {
+			continue
+		}
+		if _, err := strconv.Atoi(number); err != nil {
+			continue
+		}
+		device, ok := readDevice(filepath.Join(root, entry.Name(), "device"))
+		if !ok {
+			continue // Not a PCI device (e.g. a virtual framebuffer)
+		}
+		device.Card = entry.Name()
+		devices = append(devices, device)
+	}
+
+	sort.Slice(devices, func(i, j int) bool {
+		a, _ := strconv.Atoi(strings.TrimPrefix(devices[i].Card, "card"))
+		b, _ := strconv.Atoi(strings.TrimPrefix(devices[j].Card, "card"))
+		return a < b
+	})
+	for i := range devices {
+		devices[i].Index = i + 1
+	}
+	return devices, nil
+}
+
+// readDevice reads the PCI attributes of one DRM card's device directory
+func readDevice(dir string) (Device, bool) {
+	vendor, err := readHexAttribute(filepath.Join(dir, "vendor"))
+	if err != nil {
+		return Device{}, false
+	}
+	id, err := readHexAttribute(filepath.Join(dir, "device"))
+	if err != nil {
+		return Device{}, false
+	}
+	device := Device{VendorID: vendor, DeviceID: id}
+
+	if boot, err := os.ReadFile(filepath.Join(dir, "boot_vga")); err == nil {
+		device.Default = strings.TrimSpace(string(boot)) == "1"
+	}
+	if uevent, err := os.ReadFile(filepath.Join(dir, "uevent")); err == nil {
+		for _, line := range strings.Split(string(uevent), "\n") {
+			if key, value, ok := strings.Cut(strings.TrimSpace(line), "="); ok {
+				switch key {
+				case "DRIVER":
+					device.Driver = value
+				case "PCI_SLOT_NAME":
+					device.PCISlot = value
+				}
+			}
+		}
+	}
+	return device, true
+}
+
+// readHexAttribute parses a sysfs attribute such as "0x10de"
+func readHexAttribute(path string) (uint16, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return 0, err
+	}
+	value, err := strconv.ParseUint(strings.TrimPrefix(strings.TrimSpace(string(data)), "0x"), 16, 16)
+	return uint16(value), err
+}
+
+// SelectionEnv returns the environment variables that make the OpenGL driver
+// create its context on d; they must be set before the context is created.
+// The default device needs none. NVIDIA's proprietary driver is reached
+// through PRIME render offload; Mesa drivers through DRI_PRIME
+func SelectionEnv(d Device) map[string]string {
+	if d.Default {
+		return nil
+	}
+	if d.Driver == "nvidia" {
+		return map[string]string{
+			"__NV_PRIME_RENDER_OFFLOAD": "1",
+			"__GLX_VENDOR_LIBRARY_NAME": "nvidia",
+		}
+	}
+	// Mesa names devices by a PCI tag with separators replaced: pci-0000_01_00_0
+	tag := strings.NewReplacer(":", "_", ".", "_").Replace(d.PCISlot)
+	return map[string]string{"DRI_PRIME": "pci-" + tag}
+}
@@ -0,0 +1,76 @@
+package gpu
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+// writeCard creates a fake sysfs DRM card
+func writeCard(t *testing.T, root, card string, attrs map[string]string) {
+	t.Helper()
+	dir := filepath.Join(root, card, "device")
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		t.Fatal(err)
+	}
+	for name, value := range attrs {
+		if err := os.WriteFile(filepath.Join(dir, name), []byte(value), 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+}
+
+// TestEnumerateDevices tests listing an iGPU + dGPU laptop
+func TestEnumerateDevices(t *testing.T) {
+	root := t.TempDir()
+	writeCard(t, root, "card1", map[string]string{
+		"vendor": "0x10de\n", "device": "0x2520\n", "boot_vga": "0\n",
+		"uevent": "DRIVER=nvidia\nPCI_SLOT_NAME=0000:01:00.0\n",
+	})
+	writeCard(t, root, "card0", map[string]string{
+		"vendor": "0x8086\n", "device": "0x9a49\n", "boot_vga": "1\n",
+		"uevent": "DRIVER=i915\nPCI_SLOT_NAME=0000:00:02.0\n",
+	})
+	writeCard(t, root, "card0-eDP-1", map[string]string{"status": "connected"})
+	writeCard(t, root, "card2", map[string]string{"uevent": "DRIVER=simple-framebuffer\n"})
+
+	devices, err := enumerateDevicesIn(root)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(devices) != 2 {
+		t.Fatalf("Expected 2 devices, got %v", devices)
+	}
+	if d := devices[0]; d.Index != 1 || d.Card != "card0" || d.VendorID != VendorIntel || !d.Default || d.Driver != "i915" {
+		t.Errorf("Unexpected first device %+v", d)
+	}
+	if d := devices[1]; d.Index != 2 || d.VendorID != VendorNVIDIA || d.DeviceID != 0x2520 || d.Default || d.PCISlot != "0000:01:00.0" {
+		t.Errorf("Unexpected second device %+v", d)
+	}
+	if got, want := devices[1].String(), "2: NVIDIA 10de:2520 (nvidia, 0000:01:00.0)"; got != want {
+		t.Errorf("String() = %q, want %q", got, want)
+	}
+}
+
+// TestEnumerateDevicesMissingRoot tests systems without DRM
+func TestEnumerateDevicesMissingRoot(t *testing.T) {
+	devices, err := enumerateDevicesIn(filepath.Join(t.TempDir(), "missing"))
+	if err != nil || devices != nil {
+		t.Errorf("Expected no devices and no error, got %v, %v", devices, err)
+	}
+}
+
+// TestSelectionEnv tests the driver-specific offload variables
+func TestSelectionEnv(t *testing.T) {
+	if env := SelectionEnv(Device{Default: true, Driver: "nvidia"}); env != nil {
+		t.Errorf("Expected no variables for the default device, got %v", env)
+	}
+	env := SelectionEnv(Device{Driver: "nvidia"})
+	if env["__NV_PRIME_RENDER_OFFLOAD"] != "1" || env["__GLX_VENDOR_LIBRARY_NAME"] != "nvidia" {
+		t.Errorf("Unexpected NVIDIA variables %v", env)
+	}
+	env = SelectionEnv(Device{Driver: "amdgpu", PCISlot: "0000:03:00.0"})
+	if env["DRI_PRIME"] != "pci-0000_03_00_0" {
+		t.Errorf("Unexpected Mesa variables %v", env)
+	}
+}
@@ -0,0 +1,104 @@
+package gpu
+
+import (
+	"fmt"
+)
+
+// DirectTileSize is the work group size of the all-pairs kernel and the number
+// of bodies staged in shared memory per tile
+const DirectTileSize = 256
+
+// Binding points of the all-pairs kernel's shader storage buffers
+const (
+	DirectBodiesBinding        = 0 // vec4 per body: x, z, mass, unused
+	DirectAccelerationsBinding = 1 // vec2 per body: ax, az
+)
+
+// GenerateDirectForceShader generates the all-pairs gravity kernel. Each
+// invocation owns one body; the work group loads the bodies tile by tile into
+// shared memory so every body is read from global memory once per group
+// instead of once per invocation. It evaluates the 2D force law
+//
+//	a_i = uCoupling Σ_j m_j d_ij / (|d_ij|² + uSoftening2)
+//
+// with d_ij the nearest-image separation along each axis with a positive uPeriod
+func GenerateDirectForceShader(tileSize int) string {
+	return fmt.Sprintf(`#version 430
+layout(local_size_x = %[1]d) in;
+
+layout(std430, binding = %[2]d) readonly buffer Bodies {
+    vec4 bodies[];
+};
+
+layout(std430, binding = %[3]d) writeonly buffer Accelerations {
+    vec2 accelerations[];
+};
+
+uniform int uCount;
+uniform float uCoupling;   // -2G
+uniform float uSoftening2; // ε²
+uniform vec2 uPeriod;      // Domain size (0 = unbounded)
+
+shared vec4 tile[%[1]d];
+
+vec2 nearestImage(vec2 d) {
+    if (uPeriod.x > 0.0) d.x -= uPeriod.x * round(d.x / uPeriod.x);
+    if (uPeriod.y > 0.0) d.y -= uPeriod.y * round(d.y / uPeriod.y);
+    return d;
+}
+
+void main() {
+    uint i = gl_GlobalInvocationID.x;
+    uint lane = gl_LocalInvocationID.x;
+    uint count = uint(uCount);
+    bool active = i < count;
+    vec2 position = active ? bodies[i].xy : vec2(0.0);
+    vec2 sum = vec2(0.0);
+
+    for (uint base = 0u; base < count; base += %[1]du) {
+        // Padding bodies past the end have zero mass
+        uint j = base + lane;
+        tile[lane] = j < count ? bodies[j] : vec4(0.0);
+        memoryBarrierShared();
+        barrier();
+
+        for (uint k = 0u; k < %[1]du; k++) {
+            vec4 body = tile[k];
+            vec2 d = nearestImage(position - body.xy);
+            float r2 = dot(d, d) + uSoftening2;
+            if (base + k != i && r2 > 0.0) {
+                sum += body.z * d / r2;
+            }
+        }
+        barrier();
+    }
+
+    if (active) {
+        accelerations[i] = uCoupling * sum;
+    }
+}
+`, tileSize, DirectBodiesBinding, DirectAccelerationsBinding)
+}
+
+// PackDirectBodies interleaves positions and masses as the vec4 bodies the
+// all-pairs kernel reads into dst, growing it if needed, and returns the
+// packed slice. dst may be a mapped upload buffer
+func PackDirectBodies(dst []float32, xs, zs, masses []float64) []float32 {
+	n := 4 * len(xs)
+	if cap(dst) < n {
+		dst = make([]float32, n)
+	}
+	dst = dst[:n]
+	for i := range xs {
+		dst[4*i] = float32(xs[i])
+		dst[4*i+1] = float32(zs[i])
+		dst[4*i+2] = float32(masses[i])
+		dst[4*i+3] = 0
+	}
+	return dst
+}
+
+// DirectWorkGroups returns the number of work groups that cover count bodies
+func DirectWorkGroups(count, tileSize int) int {
+	return (count + tileSize - 1) / tileSize
+}
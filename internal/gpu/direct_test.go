@@ -0,0 +1,73 @@
+package gpu
+
+import (
+	"strings"
+	"testing"
+)
+
+// TestGenerateDirectForceShader tests that the all-pairs kernel is a valid
+// compute shader sized to the tile
+func TestGenerateDirectForceShader(t *testing.T) {
+	source := GenerateDirectForceShader(DirectTileSize)
+
+	if !NewShaderManager().ValidateShaderSource(source) {
+		t.Fatal("Generated shader failed validation")
+	}
+	for _, want := range []string{
+		"layout(local_size_x = 256) in;",
+		"shared vec4 tile[256];",
+		"base += 256u",
+		"layout(std430, binding = 0) readonly buffer Bodies",
+		"layout(std430, binding = 1) writeonly buffer Accelerations",
+		"barrier();",
+	} {
+		if !strings.Contains(source, want) {
+			t.Errorf("Shader missing %q", want)
+		}
+	}
+	if strings.Contains(source, "%!") {
+		t.Error("Shader contains a formatting error")
+	}
+}
+
+// TestPackDirectBodies tests the vec4 body layout
+func TestPackDirectBodies(t *testing.T) {
+	bodies := PackDirectBodies(nil, []float64{1, -2}, []float64{3, 4.5}, []float64{0.25, 2})
+	want := []float32{1, 3, 0.25, 0, -2, 4.5, 2, 0}
+
+	if len(bodies) != len(want) {
+		t.Fatalf("Expected %d floats, got %d", len(want), len(bodies))
+	}
+	for i := range want {
+		if bodies[i] != want[i] {
+			t.Errorf("bodies[%d] = %v, want %v", i, bodies[i], want[i])
+		}
+	}
+
+	// Packing into a mapped buffer reuses it and overwrites stale padding
+	mapped := make([]float32, 16)
+	for i := range mapped {
+		mapped[i] = -1
+	}
+	if packed := PackDirectBodies(mapped, []float64{1}, []float64{2}, []float64{3}); &packed[0] != &mapped[0] || packed[3] != 0 {
+		t.Errorf("Expected packing into the given buffer, got %v", packed)
+	}
+}
+
+// TestDirectWorkGroups tests that the dispatch covers every body
+func TestDirectWorkGroups(t *testing.T) {
+	tests := []struct {
+		count, want int
+	}{
+		{0, 0},
+		{1, 1},
+		{DirectTileSize, 1},
+		{DirectTileSize + 1, 2},
+		{65536, 65536 / DirectTileSize},
+	}
+	for _, tt := range tests {
+		if got := DirectWorkGroups(tt.count, DirectTileSize); got != tt.want {
+			t.Errorf("DirectWorkGroups(%d) = %d, want %d", tt.count, got, tt.want)
+		}
+	}
+}
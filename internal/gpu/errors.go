@@ -0,0 +1,50 @@
+package gpu
+
+import (
+	"errors"
+	"fmt"
+)
+
+// ErrNoGLContext is returned when no usable OpenGL context exists. Retrying
+// will not help, so callers should switch to the CPU for the rest of the run
+var ErrNoGLContext = errors.New("OpenGL context not available")
+
+// ErrShaderCompile is returned when a shader fails to compile or link.
+// Errors of this kind are *ShaderError values carrying the driver log
+var ErrShaderCompile = errors.New("shader compilation failed")
+
+// ErrUnsupportedGL is returned when the OpenGL context is too old for
+// compute shaders. Like ErrNoGLContext it is permanent for the run
+var ErrUnsupportedGL = errors.New("OpenGL compute shaders not supported")
+
+// ErrBufferTooSmall is returned when data does not fit in a GPU buffer
+var ErrBufferTooSmall = errors.New("GPU buffer too small")
+
+// ShaderError describes a shader that failed to compile or link
+type ShaderError struct {
+	Shader string // Which shader failed, e.g. "FFT compute"
+	Stage  string // "compilation" or "linking"
+	Log    string // Driver info log
+}
+
+// Error returns the message in the form "<shader> shader <stage> failed: <log>"
+func (e *ShaderError) Error() string {
+	return fmt.Sprintf("%s shader %s failed: %s", e.Shader, e.Stage, e.Log)
+}
+
+// Unwrap makes errors.Is(err, ErrShaderCompile) report true
+func (e *ShaderError) Unwrap() error {
+	return ErrShaderCompile
+}
+
+// NewBufferTooSmallError returns an ErrBufferTooSmall error for a buffer of
+// size elements that was asked to hold needed elements
+func NewBufferTooSmallError(needed, size int) error {
+	return fmt.Errorf("%w: need %d elements, have %d", ErrBufferTooSmall, needed, size)
+}
+
+// NewUnsupportedGLError returns an ErrUnsupportedGL error naming the version
+// and renderer of a context without compute shaders
+func NewUnsupportedGLError(caps Capabilities) error {
+	return fmt.Errorf("%w: OpenGL %d.%d on %s, 4.3 needed", ErrUnsupportedGL, caps.Major, caps.Minor, caps.Renderer)
+}
@@ -0,0 +1,37 @@
+package gpu
+
+import (
+	"errors"
+	"fmt"
+	"testing"
+)
+
+// TestShaderError tests that wrapped shader errors match ErrShaderCompile
+func TestShaderError(t *testing.T) {
+	err := fmt.Errorf("failed to compile FFT shader: %w", &ShaderError{Shader: "FFT compute", Stage: "linking", Log: "missing main"})
+
+	if !errors.Is(err, ErrShaderCompile) {
+		t.Error("Wrapped ShaderError should match ErrShaderCompile")
+	}
+	var shaderErr *ShaderError
+	if !errors.As(err, &shaderErr) || shaderErr.Log != "missing main" {
+		t.Errorf("errors.As did not recover the ShaderError: %v", err)
+	}
+	if want := "failed to compile FFT shader: FFT compute shader linking failed: missing main"; err.Error() != want {
+		t.Errorf("Error() = %q, want %q", err.Error(), want)
+	}
+	if errors.Is(err, ErrNoGLContext) {
+		t.Error("ShaderError should not match ErrNoGLContext")
+	}
+}
+
+// TestBufferTooSmallError tests the message and kind of buffer size errors
+func TestBufferTooSmallError(t *testing.T) {
+	err := NewBufferTooSmallError(10, 4)
+	if !errors.Is(err, ErrBufferTooSmall) {
+		t.Error("Should match ErrBufferTooSmall")
+	}
+	if want := "GPU buffer too small: need 10 elements, have 4"; err.Error() != want {
+		t.Errorf("Error() = %q, want %q", err.Error(), want)
+	}
+}
@@ -2,6 +2,7 @@ package gpu
 
 import (
 	"errors"
+	"fmt"
 	"sync"
 	"time"
 )
@@ -40,11 +41,14 @@ const (
 	ProcessorTypeCPU ProcessorType = iota
 	// ProcessorTypeGPU represents GPU processor
 	ProcessorTypeGPU
+	// ProcessorTypeCUDA represents an NVIDIA GPU driven through CUDA
+	ProcessorTypeCUDA
 )
 
 // Processor represents a compute processor
 type Processor struct {
-	Type ProcessorType
+	Type    ProcessorType
+	Backend Backend // Executes the compute steps (nil = type only)
 }
 
 // GetType returns the processor type
@@ -63,6 +67,7 @@ type GPUInfo struct {
 type PerformanceStats struct {
 	CPUStats Stats
 	GPUStats Stats
+	Pool     PoolStats // Latest counters of the buffer pool
 }
 
 // Stats contains statistics for a processor
@@ -80,14 +85,20 @@ type FallbackManager struct {
 	lastError       error
 	hasError        bool
 	performanceData map[ProcessorType][]float64
+	poolStats       PoolStats
+	backends        map[ProcessorType]Backend
 }
 
+// maxPerformanceSamples is the number of recent timings kept per processor
+const maxPerformanceSamples = 240
+
 // NewFallbackManager creates a new fallback manager
 func NewFallbackManager() *FallbackManager {
 	return &FallbackManager{
 		mode:            ModeAuto,
 		gpuAvailable:    false, // In test environment, GPU is not available
 		performanceData: make(map[ProcessorType][]float64),
+		backends:        make(map[ProcessorType]Backend),
 	}
 }
 
@@ -142,14 +153,11 @@ func (m *FallbackManager) GetProcessor() *Processor {
 	case ModeAuto:
 		// Choose based on availability and performance
 		if m.gpuAvailable && !m.hasError {
-			// Check if GPU has better performance
-			if m.isGPUFaster() {
-				processorType = ProcessorTypeGPU
-			}
+			processorType = m.autoChoice()
 		}
 	}
 
-	return &Processor{Type: processorType}
+	return &Processor{Type: processorType, Backend: m.backends[processorType]}
 }
 
 // SimulateGPUError simulates a GPU error for testing
@@ -190,13 +198,36 @@ func (m *FallbackManager) ClearErrors() {
 	m.lastError = nil
 }
 
+// ReportError records a GPU error and falls back to CPU. ErrNoGLContext and
+// ErrUnsupportedGL also mark the GPU unavailable, since no recovery attempt
+// can succeed without a usable context; other errors, such as
+// ErrShaderCompile or ErrBufferTooSmall, leave it available for
+// AttemptRecovery
+func (m *FallbackManager) ReportError(err error) {
+	if err == nil {
+		return
+	}
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	m.hasError = true
+	m.lastError = err
+	if errors.Is(err, ErrNoGLContext) || errors.Is(err, ErrUnsupportedGL) {
+		m.gpuAvailable = false
+	}
+	if m.mode == ModeGPU {
+		m.mode = ModeCPU
+	}
+}
+
 // AttemptRecovery attempts to recover from GPU error
 func (m *FallbackManager) AttemptRecovery() error {
 	m.mu.Lock()
 	defer m.mu.Unlock()
 
 	if !m.gpuAvailable {
-		return errors.New("GPU not available")
+		return ErrNoGLContext
 	}
 
 	// Simulate recovery attempt
@@ -209,12 +240,24 @@ func (m *FallbackManager) AttemptRecovery() error {
 	return nil
 }
 
-// RecordPerformance records performance metrics
+// RecordPerformance records performance metrics, keeping the most recent
+// maxPerformanceSamples per processor
 func (m *FallbackManager) RecordPerformance(processorType ProcessorType, timeMs float64) {
 	m.mu.Lock()
 	defer m.mu.Unlock()
 
-	m.performanceData[processorType] = append(m.performanceData[processorType], timeMs)
+	data := append(m.performanceData[processorType], timeMs)
+	if len(data) > maxPerformanceSamples {
+		data = append(data[:0], data[len(data)-maxPerformanceSamples:]...)
+	}
+	m.performanceData[processorType] = data
+}
+
+// RecordPoolStats records the latest buffer pool counters
+func (m *FallbackManager) RecordPoolStats(stats PoolStats) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.poolStats = stats
 }
 
 // GetPerformanceStats returns performance statistics
@@ -222,7 +265,7 @@ func (m *FallbackManager) GetPerformanceStats() *PerformanceStats {
 	m.mu.RLock()
 	defer m.mu.RUnlock()
 
-	stats := &PerformanceStats{}
+	stats := &PerformanceStats{Pool: m.poolStats}
 
 	// Calculate CPU stats
 	if cpuData, ok := m.performanceData[ProcessorTypeCPU]; ok && len(cpuData) > 0 {
@@ -256,6 +299,49 @@ func (m *FallbackManager) calculateStats(data []float64) Stats {
 	}
 }
 
+// AutoProbeSamples is the number of steps Auto mode times on each processor
+// before it compares them
+const AutoProbeSamples = 10
+
+// autoChoice picks the processor in Auto mode while the GPU works: the GPU
+// and then the CPU are timed for AutoProbeSamples steps each, after which
+// the faster one is used. m.mu must be held
+func (m *FallbackManager) autoChoice() ProcessorType {
+	switch {
+	case len(m.performanceData[ProcessorTypeGPU]) < AutoProbeSamples:
+		return ProcessorTypeGPU
+	case len(m.performanceData[ProcessorTypeCPU]) < AutoProbeSamples:
+		return ProcessorTypeCPU
+	case m.isGPUFaster():
+		return ProcessorTypeGPU
+	}
+	return ProcessorTypeCPU
+}
+
+// AutoReason explains the processor Auto mode uses, with the measured step
+// times once both processors have been timed
+func (m *FallbackManager) AutoReason() string {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	gpuData, cpuData := m.performanceData[ProcessorTypeGPU], m.performanceData[ProcessorTypeCPU]
+	switch {
+	case m.hasError:
+		return "CPU (GPU failed)"
+	case !m.gpuAvailable:
+		return "CPU (no GPU)"
+	case len(gpuData) < AutoProbeSamples:
+		return fmt.Sprintf("timing GPU (%d/%d)", len(gpuData), AutoProbeSamples)
+	case len(cpuData) < AutoProbeSamples:
+		return fmt.Sprintf("timing CPU (%d/%d)", len(cpuData), AutoProbeSamples)
+	}
+	gpuTime, cpuTime := m.calculateStats(gpuData).AverageTime, m.calculateStats(cpuData).AverageTime
+	if m.isGPUFaster() {
+		return fmt.Sprintf("GPU (%.1f ms vs CPU %.1f ms per step)", gpuTime, cpuTime)
+	}
+	return fmt.Sprintf("CPU (%.1f ms vs GPU %.1f ms per step)", cpuTime, gpuTime)
+}
+
 // isGPUFaster checks if GPU is faster based on recorded performance
 func (m *FallbackManager) isGPUFaster() bool {
 	cpuData := m.performanceData[ProcessorTypeCPU]
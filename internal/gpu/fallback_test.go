@@ -1,6 +1,8 @@
 package gpu
 
 import (
+	"errors"
+	"fmt"
 	"testing"
 )
 
@@ -155,6 +157,45 @@ func TestPerformanceMonitoring(t *testing.T) {
 	}
 }
 
+// TestAutoSelection tests that Auto mode times both processors before
+// picking the faster one, and explains its choice
+func TestAutoSelection(t *testing.T) {
+	manager := NewFallbackManager()
+	manager.SetMode(ModeAuto)
+	if reason := manager.AutoReason(); reason != "CPU (no GPU)" {
+		t.Errorf("Expected the missing GPU as the reason, got %q", reason)
+	}
+
+	manager.SetGPUAvailable(true)
+	for i := 0; i < AutoProbeSamples; i++ {
+		if manager.GetProcessor().GetType() != ProcessorTypeGPU {
+			t.Fatalf("Expected the GPU to be timed first, sample %d", i)
+		}
+		manager.RecordPerformance(ProcessorTypeGPU, 4)
+	}
+	if reason := manager.AutoReason(); reason != fmt.Sprintf("timing CPU (0/%d)", AutoProbeSamples) {
+		t.Errorf("Expected the CPU to be timed next, got %q", reason)
+	}
+	for i := 0; i < AutoProbeSamples; i++ {
+		if manager.GetProcessor().GetType() != ProcessorTypeCPU {
+			t.Fatalf("Expected the CPU to be timed second, sample %d", i)
+		}
+		manager.RecordPerformance(ProcessorTypeCPU, 8)
+	}
+
+	if manager.GetProcessor().GetType() != ProcessorTypeGPU {
+		t.Error("Expected the faster GPU after timing")
+	}
+	if reason := manager.AutoReason(); reason != "GPU (4.0 ms vs CPU 8.0 ms per step)" {
+		t.Errorf("Expected the timings in the reason, got %q", reason)
+	}
+
+	manager.ReportError(errors.New("lost context"))
+	if manager.GetProcessor().GetType() != ProcessorTypeCPU || manager.AutoReason() != "CPU (GPU failed)" {
+		t.Errorf("Expected the CPU after a GPU error, got %q", manager.AutoReason())
+	}
+}
+
 // TestFallbackRecovery tests recovering from fallback
 func TestFallbackRecovery(t *testing.T) {
 	manager := NewFallbackManager()
@@ -224,3 +265,29 @@ func TestConcurrentFallback(t *testing.T) {
 	// If we get here without deadlock or panic, the test passes
 	t.Log("Concurrent operations completed successfully")
 }
+
+// TestReportError tests that the error kind decides whether recovery is possible
+func TestReportError(t *testing.T) {
+	manager := NewFallbackManager()
+	manager.gpuAvailable = true
+	manager.SetMode(ModeGPU)
+
+	manager.ReportError(&ShaderError{Shader: "FFT compute", Stage: "compilation", Log: "syntax error"})
+	if manager.GetMode() != ModeCPU || !manager.HasError() {
+		t.Fatal("Should fall back to CPU after a shader error")
+	}
+	if !manager.IsGPUAvailable() {
+		t.Error("A shader error should not mark the GPU unavailable")
+	}
+	if err := manager.AttemptRecovery(); err != nil {
+		t.Errorf("Recovery after a shader error failed: %v", err)
+	}
+
+	manager.ReportError(fmt.Errorf("failed to initialize GPU: %w", ErrNoGLContext))
+	if manager.IsGPUAvailable() {
+		t.Error("A missing context should mark the GPU unavailable")
+	}
+	if err := manager.AttemptRecovery(); !errors.Is(err, ErrNoGLContext) {
+		t.Errorf("Expected ErrNoGLContext from recovery, got %v", err)
+	}
+}
@@ -0,0 +1,95 @@
+package gpu
+
+import "relativity_simulation_2d/internal/physics"
+
+// DefaultFFTPlanCapacity is the number of plans an FFTPlanCache keeps: both
+// directions of a few grid sizes in use at once, such as the full grid, a
+// coarse preview grid and a region of interest
+const DefaultFFTPlanCapacity = 8
+
+// FFTPlanKey identifies a GPU FFT plan by grid size, direction and precision
+type FFTPlanKey struct {
+	Width, Height int
+	Forward       bool
+	Precision     physics.Precision // The GL kernels transform in PrecisionFloat32
+}
+
+// FFTPlanStats counts the lookups of an FFT plan cache
+type FFTPlanStats struct {
+	Plans   int // Plans held
+	Hits    int // Lookups that found their plan
+	Misses  int // Lookups that created one
+	Evicted int // Plans destroyed to make room
+}
+
+// FFTPlanCache keeps the FFT plans of recently used grid sizes, with their
+// compiled kernels and scratch buffers, so switching between sizes at
+// runtime does not rebuild them. Beyond its capacity the least recently used
+// plan is destroyed. Like the GL context its plans live in, it is used from
+// one goroutine
+type FFTPlanCache struct {
+	capacity int
+	plans    map[FFTPlanKey]*GPUFFTPlan
+	order    []FFTPlanKey // Least recently used first
+	stats    FFTPlanStats
+}
+
+// NewFFTPlanCache creates a cache of up to capacity plans (DefaultFFTPlanCapacity if not positive)
+func NewFFTPlanCache(capacity int) *FFTPlanCache {
+	if capacity <= 0 {
+		capacity = DefaultFFTPlanCapacity
+	}
+	return &FFTPlanCache{capacity: capacity, plans: make(map[FFTPlanKey]*GPUFFTPlan)}
+}
+
+// Get returns the plan for key, calling create on a miss. A plan evicted to
+// make room is passed to destroy
+func (c *FFTPlanCache) Get(key FFTPlanKey, create func() (*GPUFFTPlan, error), destroy func(*GPUFFTPlan)) (*GPUFFTPlan, error) {
+	if plan, ok := c.plans[key]; ok {
+		c.stats.Hits++
+		c.touch(key)
+		return plan, nil
+	}
+
+	plan, err := create()
+	if err != nil {
+		return nil, err
+	}
+	c.stats.Misses++
+	if len(c.order) >= c.capacity {
+		destroy(c.plans[c.order[0]])
+		delete(c.plans, c.order[0])
+		c.order = c.order[1:]
+		c.stats.Evicted++
+	}
+	c.plans[key] = plan
+	c.order = append(c.order, key)
+	return plan, nil
+}
+
+// touch moves key to the most recently used end of the order
+func (c *FFTPlanCache) touch(key FFTPlanKey) {
+	for i, k := range c.order {
+		if k == key {
+			copy(c.order[i:], c.order[i+1:])
+			c.order[len(c.order)-1] = key
+			return
+		}
+	}
+}
+
+// Clear passes every plan to destroy and empties the cache
+func (c *FFTPlanCache) Clear(destroy func(*GPUFFTPlan)) {
+	for _, key := range c.order {
+		destroy(c.plans[key])
+	}
+	clear(c.plans)
+	c.order = c.order[:0]
+}
+
+// Stats returns the number of plans held and the lookups so far
+func (c *FFTPlanCache) Stats() FFTPlanStats {
+	stats := c.stats
+	stats.Plans = len(c.plans)
+	return stats
+}
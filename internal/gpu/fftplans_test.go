@@ -0,0 +1,50 @@
+package gpu
+
+import (
+	"errors"
+	"relativity_simulation_2d/internal/physics"
+	"testing"
+)
+
+// TestFFTPlanCache tests reusing plans by key and destroying the least
+// recently used one beyond the capacity
+func TestFFTPlanCache(t *testing.T) {
+	c := NewFFTPlanCache(2)
+	var destroyed []*GPUFFTPlan
+	destroy := func(plan *GPUFFTPlan) { destroyed = append(destroyed, plan) }
+	get := func(width, height int, forward bool) *GPUFFTPlan {
+		t.Helper()
+		key := FFTPlanKey{Width: width, Height: height, Forward: forward, Precision: physics.PrecisionFloat32}
+		plan, err := c.Get(key, func() (*GPUFFTPlan, error) {
+			return &GPUFFTPlan{Width: width, Height: height, IsForward: forward}, nil
+		}, destroy)
+		if err != nil {
+			t.Fatalf("Get failed: %v", err)
+		}
+		return plan
+	}
+
+	full := get(64, 64, true)
+	inverse := get(64, 64, false)
+	if get(64, 64, true) != full || inverse == full {
+		t.Fatal("Expected one plan per size and direction")
+	}
+	get(32, 32, true) // Evicts the inverse plan, used least recently
+	if len(destroyed) != 1 || destroyed[0] != inverse {
+		t.Fatalf("Expected the inverse plan destroyed, got %v", destroyed)
+	}
+	if stats := c.Stats(); stats != (FFTPlanStats{Plans: 2, Hits: 1, Misses: 3, Evicted: 1}) {
+		t.Errorf("Unexpected stats %+v", stats)
+	}
+
+	if _, err := c.Get(FFTPlanKey{Width: 16, Height: 16}, func() (*GPUFFTPlan, error) {
+		return nil, errors.New("no context")
+	}, destroy); err == nil || c.Stats().Plans != 2 {
+		t.Error("Expected a failed creation to leave the cache as it was")
+	}
+
+	c.Clear(destroy)
+	if len(destroyed) != 3 || c.Stats().Plans != 0 {
+		t.Errorf("Expected every plan destroyed on Clear, got %d of 3", len(destroyed))
+	}
+}
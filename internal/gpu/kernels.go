@@ -0,0 +1,215 @@
+package gpu
+
+import "fmt"
+
+// ShaderPrecision selects the arithmetic precision of a generated kernel.
+// Buffers always hold float32 values; double precision only widens the
+// arithmetic inside the kernel
+type ShaderPrecision string
+
+const (
+	ShaderFloat  ShaderPrecision = "float"
+	ShaderDouble ShaderPrecision = "double"
+)
+
+// Boundary selects how a generated stencil kernel treats the grid edges
+type Boundary string
+
+const (
+	BoundaryPeriodic Boundary = "periodic" // Wrap around, like the FFT solver
+	BoundaryClamped  Boundary = "clamped"  // One-sided differences at the edges
+)
+
+// GravityKernel selects the Green's function applied in Fourier space
+type GravityKernel string
+
+const (
+	KernelNewtonian GravityKernel = "newtonian" // G(k) = -4πG / k²
+	KernelYukawa    GravityKernel = "yukawa"    // G(k) = -4πG / (k² + 1/λ²), screened beyond λ
+)
+
+// DefaultKernelLocalSize is the work group size of generated kernels when
+// KernelParams.LocalSize is 0
+const DefaultKernelLocalSize = 64
+
+// KernelParams are the template parameters of the generated Green's function
+// and gradient kernels. The zero value is a float, periodic, Newtonian kernel
+type KernelParams struct {
+	Precision ShaderPrecision
+	Boundary  Boundary
+	Gravity   GravityKernel
+	LocalSize int // Work group size (0 = DefaultKernelLocalSize)
+}
+
+// normalized returns the parameters with defaults filled in
+func (p KernelParams) normalized() KernelParams {
+	if p.Precision == "" {
+		p.Precision = ShaderFloat
+	}
+	if p.Boundary == "" {
+		p.Boundary = BoundaryPeriodic
+	}
+	if p.Gravity == "" {
+		p.Gravity = KernelNewtonian
+	}
+	if p.LocalSize <= 0 {
+		p.LocalSize = DefaultKernelLocalSize
+	}
+	return p
+}
+
+// Key returns a string identifying the parameters, for shader caches
+func (p KernelParams) Key() string {
+	p = p.normalized()
+	return fmt.Sprintf("%s_%s_%s_%d", p.Precision, p.Boundary, p.Gravity, p.LocalSize)
+}
+
+// GroupSize returns the work group size of kernels generated with p
+func (p KernelParams) GroupSize() int {
+	return p.normalized().LocalSize
+}
+
+// GenerateGreensFunctionShader generates the kernel that multiplies the
+// Fourier transform of the density by the Green's function, turning it into
+// the transform of the potential. The DC component is zeroed, so the mean
+// density does not contribute. Uniforms: uWidth, uHeight, uGConstant,
+// uKxFactor, uKzFactor and, for the Yukawa kernel, uScreeningLength
+func (m *ShaderManager) GenerateGreensFunctionShader(params KernelParams) string {
+	p := params.normalized()
+	scalar := string(p.Precision)
+
+	screening, denominator := "", "kSquared"
+	if p.Gravity == KernelYukawa {
+		screening = "\n\t\tuniform float uScreeningLength;"
+		denominator = fmt.Sprintf("(kSquared + %[1]s(1.0) / (%[1]s(uScreeningLength) * %[1]s(uScreeningLength)))", scalar)
+	}
+
+	return fmt.Sprintf(`
+		#version 430
+		layout(local_size_x = %[2]d) in;
+
+		layout(std430, binding = 0) buffer FourierBuffer {
+			vec2 fourierData[];
+		};
+
+		uniform int uWidth;
+		uniform int uHeight;
+		uniform float uGConstant;
+		uniform float uKxFactor;
+		uniform float uKzFactor;%[3]s
+
+		void main() {
+			uint index = gl_GlobalInvocationID.x;
+			uint totalSize = uint(uWidth * uHeight);
+
+			if (index >= totalSize) return;
+
+			// Convert 1D index to 2D coordinates
+			// Data is uploaded as densityGrid[i][j] with j inner loop
+			// So u (width/i) changes slower, v (height/j) changes faster
+			uint u = index / uint(uHeight);
+			uint v = index %% uint(uHeight);
+
+			// Calculate wave vector k
+			%[1]s kx = %[1]s(u);
+			if (u > uint(uWidth)/2u) {
+				kx = %[1]s(int(u) - uWidth);
+			}
+
+			%[1]s kz = %[1]s(v);
+			if (v > uint(uHeight)/2u) {
+				kz = %[1]s(int(v) - uHeight);
+			}
+
+			%[1]s kSquared = (kx * uKxFactor) * (kx * uKxFactor) +
+							 (kz * uKzFactor) * (kz * uKzFactor);
+
+			if (kSquared == %[1]s(0.0)) {
+				// Ignore DC component
+				fourierData[index] = vec2(0.0, 0.0);
+			} else {
+				// Apply Green's function: %[5]s
+				%[1]s scalingFactor = %[1]s(-4.0 * 3.14159265359) * %[1]s(uGConstant) / %[4]s;
+				fourierData[index] = vec2(%[1]s(fourierData[index].x) * scalingFactor,
+				                          %[1]s(fourierData[index].y) * scalingFactor);
+			}
+		}
+	`, scalar, p.LocalSize, screening, denominator, greensFormula(p.Gravity))
+}
+
+// greensFormula returns the Green's function of a kernel, for the generated
+// comments
+func greensFormula(kernel GravityKernel) string {
+	if kernel == KernelYukawa {
+		return "G(k) = -4πG / (|k|² + 1/λ²)"
+	}
+	return "G(k) = -4πG / |k|²"
+}
+
+// GenerateGradientShader generates the kernel that turns the potential into
+// the acceleration field a = -∇Φ by central differences in grid units. It
+// reads the real part of the inverse-transformed potential at binding 0,
+// scaled by uScale (the FFT normalization), and writes (ax, az) to binding 1.
+// Periodic boundaries match physics.CalculateGradientInto; clamped
+// boundaries fall back to one-sided differences at the edges. Uniforms:
+// uWidth, uHeight and uScale
+func (m *ShaderManager) GenerateGradientShader(params KernelParams) string {
+	p := params.normalized()
+	scalar := string(p.Precision)
+
+	var neighbours string
+	if p.Boundary == BoundaryClamped {
+		neighbours = `int prevI = max(i - 1, 0);
+			int nextI = min(i + 1, uWidth - 1);
+			int prevJ = max(j - 1, 0);
+			int nextJ = min(j + 1, uHeight - 1);`
+	} else {
+		neighbours = `int prevI = (i - 1 + uWidth) % uWidth;
+			int nextI = (i + 1) % uWidth;
+			int prevJ = (j - 1 + uHeight) % uHeight;
+			int nextJ = (j + 1) % uHeight;`
+	}
+
+	// Periodic differences always span two cells; clamped ones span one at
+	// the edges
+	spanX, spanZ := scalar+"(2.0)", scalar+"(2.0)"
+	if p.Boundary == BoundaryClamped {
+		spanX, spanZ = scalar+"(nextI - prevI)", scalar+"(nextJ - prevJ)"
+	}
+
+	return fmt.Sprintf(`
+		#version 430
+		layout(local_size_x = %[2]d) in;
+
+		layout(std430, binding = 0) buffer PotentialBuffer {
+			vec2 potentialData[];
+		};
+		layout(std430, binding = 1) buffer AccelBuffer {
+			vec2 accelData[];
+		};
+
+		uniform int uWidth;
+		uniform int uHeight;
+		uniform float uScale;
+
+		%[1]s potential(int i, int j) {
+			return %[1]s(potentialData[i * uHeight + j].x) * %[1]s(uScale);
+		}
+
+		void main() {
+			uint index = gl_GlobalInvocationID.x;
+			if (index >= uint(uWidth * uHeight)) return;
+
+			// Same layout as the Green's function: j changes fastest
+			int i = int(index) / uHeight;
+			int j = int(index) - i * uHeight;
+
+			// %[5]s boundaries
+			%[3]s
+
+			%[1]s ax = -(potential(nextI, j) - potential(prevI, j)) / max(%[4]s, %[1]s(1.0));
+			%[1]s az = -(potential(i, nextJ) - potential(i, prevJ)) / max(%[6]s, %[1]s(1.0));
+			accelData[index] = vec2(ax, az);
+		}
+	`, scalar, p.LocalSize, neighbours, spanX, p.Boundary, spanZ)
+}
@@ -0,0 +1,74 @@
+package gpu
+
+import (
+	"strings"
+	"testing"
+)
+
+// TestGreensFunctionShader tests the template parameters of the Green's
+// function kernel
+func TestGreensFunctionShader(t *testing.T) {
+	m := NewShaderManager()
+	source := m.GenerateGreensFunctionShader(KernelParams{})
+	if !m.ValidateShaderSource(source) {
+		t.Fatal("Expected a valid shader source")
+	}
+	if !strings.Contains(source, "local_size_x = 64") || strings.Contains(source, "double") {
+		t.Error("Expected a float kernel with the default work group size")
+	}
+	if strings.Contains(source, "uScreeningLength") {
+		t.Error("Expected no screening length in the Newtonian kernel")
+	}
+
+	source = m.GenerateGreensFunctionShader(KernelParams{Precision: ShaderDouble, Gravity: KernelYukawa, LocalSize: 128})
+	for _, want := range []string{"double kSquared", "uniform float uScreeningLength", "local_size_x = 128"} {
+		if !strings.Contains(source, want) {
+			t.Errorf("Expected %q in the double Yukawa kernel", want)
+		}
+	}
+}
+
+// TestGradientShader tests the boundary handling of the gradient kernel
+func TestGradientShader(t *testing.T) {
+	m := NewShaderManager()
+	periodic := m.GenerateGradientShader(KernelParams{})
+	if !m.ValidateShaderSource(periodic) {
+		t.Fatal("Expected a valid shader source")
+	}
+	if !strings.Contains(periodic, "(i + 1) % uWidth") {
+		t.Error("Expected wrapped neighbours for periodic boundaries")
+	}
+
+	clamped := m.GenerateGradientShader(KernelParams{Boundary: BoundaryClamped})
+	if !strings.Contains(clamped, "min(i + 1, uWidth - 1)") || !strings.Contains(clamped, "max(j - 1, 0)") {
+		t.Error("Expected clamped neighbours for clamped boundaries")
+	}
+}
+
+// TestKernelParamsKey tests that the defaults share a key with the zero value
+func TestKernelParamsKey(t *testing.T) {
+	zero := KernelParams{}
+	explicit := KernelParams{Precision: ShaderFloat, Boundary: BoundaryPeriodic, Gravity: KernelNewtonian, LocalSize: DefaultKernelLocalSize}
+	if zero.Key() != explicit.Key() {
+		t.Errorf("Expected equal keys, got %q and %q", zero.Key(), explicit.Key())
+	}
+	if zero.Key() == (KernelParams{Gravity: KernelYukawa}).Key() {
+		t.Error("Expected the gravity kernel to change the key")
+	}
+}
+
+// TestFFTShaderSources tests the FFT kernels moved out of the GL code
+func TestFFTShaderSources(t *testing.T) {
+	m := NewShaderManager()
+	naive := m.GenerateNaiveDFTShader(48, 32, false, 128)
+	if !m.ValidateShaderSource(naive) || !strings.Contains(naive, "-1.0") || !strings.Contains(naive, "48") || !strings.Contains(naive, "local_size_x = 128") {
+		t.Error("Expected an inverse DFT kernel for a 48x32 grid")
+	}
+	if strings.Contains(naive, "%!") {
+		t.Error("Expected no formatting errors in the DFT kernel")
+	}
+	radix2 := m.GenerateCooleyTukeyShader(64, 64, DefaultFFTLocalSize)
+	if !m.ValidateShaderSource(radix2) || strings.Contains(radix2, "%!") {
+		t.Error("Expected a valid radix-2 kernel")
+	}
+}
@@ -0,0 +1,89 @@
+package gpu
+
+import (
+	"fmt"
+)
+
+// PotentialViewDepth is the grid displacement (world units below the plane)
+// at which the potential heatmap reaches its hottest colour
+const PotentialViewDepth = 10.0
+
+// PotentialColors are the line colors of the potential view as normalized
+// RGB: Base on a flat potential, Hot at PotentialViewDepth and below
+type PotentialColors struct {
+	Base [3]float32
+	Hot  [3]float32
+}
+
+// DefaultPotentialColors glow from the navy grid colour towards orange
+var DefaultPotentialColors = PotentialColors{
+	Base: [3]float32{50.0 / 255, 50.0 / 255, 100.0 / 255},
+	Hot:  [3]float32{1.0, 0.55, 0.1},
+}
+
+// PotentialViewVertexCount returns the number of GL_LINES vertices of a
+// width x height wireframe drawing every stride-th line: lines parallel to Z
+// first, then lines parallel to X
+func PotentialViewVertexCount(width, height, stride int) int {
+	if width < 1 || height < 1 || stride < 1 {
+		return 0
+	}
+	xLines := (width-1)/stride + 1  // Lines parallel to Z, at i = 0, stride, ...
+	zLines := (height-1)/stride + 1 // Lines parallel to X, at j = 0, stride, ...
+	return 2 * (xLines*(height-1) + zLines*(width-1))
+}
+
+// GeneratePotentialViewShaders generates the vertex and fragment shaders that
+// draw the deformed grid straight from a potential texture. The vertices are
+// generated from gl_VertexID, so the draw needs no vertex buffers. The texture
+// holds the unnormalized inverse FFT output as RG32F, texel (j, i) for grid
+// node (i, j), matching the row-major layout of the potential buffer
+func GeneratePotentialViewShaders() (vertex, fragment string) {
+	vertex = `#version 430
+
+uniform mat4 uMVP;
+uniform ivec2 uGrid;          // Grid nodes (width, height)
+uniform int uStride;          // Spacing between drawn lines
+uniform float uNormalization; // Inverse FFT normalization, 1/(width*height)
+uniform float uVisScale;      // Potential to world-space displacement
+uniform float uCellSize;      // World-space spacing of the grid nodes
+uniform sampler2D uPotential;
+
+out float vDisplacement;
+
+// node maps a line vertex to its grid node, in the order of drawDeformedGrid
+ivec2 node(int id) {
+    int segment = id / 2;
+    int end = id % 2;
+    int zSegments = ((uGrid.x - 1) / uStride + 1) * (uGrid.y - 1);
+    if (segment < zSegments) {
+        return ivec2(segment / (uGrid.y - 1) * uStride, segment % (uGrid.y - 1) + end);
+    }
+    segment -= zSegments;
+    return ivec2(segment % (uGrid.x - 1) + end, segment / (uGrid.x - 1) * uStride);
+}
+
+void main() {
+    ivec2 n = node(gl_VertexID);
+    float phi = texelFetch(uPotential, ivec2(n.y, n.x), 0).r * uNormalization;
+    vDisplacement = phi * uVisScale;
+    vec3 position = vec3((float(n.x) - float(uGrid.x) * 0.5) * uCellSize, vDisplacement, (float(n.y) - float(uGrid.y) * 0.5) * uCellSize);
+    gl_Position = uMVP * vec4(position, 1.0);
+}
+`
+	fragment = fmt.Sprintf(`#version 430
+
+uniform vec3 uBaseColor; // Flat potential
+uniform vec3 uHotColor;  // Deepest wells
+
+in float vDisplacement;
+out vec4 fragColor;
+
+void main() {
+    // Deeper wells glow from the grid colour towards the hot colour
+    float heat = clamp(-vDisplacement / %.1f, 0.0, 1.0);
+    fragColor = vec4(mix(uBaseColor, uHotColor, sqrt(heat)), 1.0);
+}
+`, PotentialViewDepth)
+	return vertex, fragment
+}
@@ -0,0 +1,50 @@
+package gpu
+
+import (
+	"strings"
+	"testing"
+)
+
+// TestPotentialViewVertexCount tests that the wireframe covers every grid edge
+func TestPotentialViewVertexCount(t *testing.T) {
+	tests := []struct {
+		width, height, stride, want int
+	}{
+		{0, 4, 1, 0},
+		{1, 1, 1, 0},
+		{2, 2, 1, 8},
+		{3, 2, 1, 14},
+		{256, 256, 1, 2 * 2 * 256 * 255},
+		{2, 2, 0, 0},
+		{5, 5, 2, 2 * 2 * 3 * 4}, // Lines at 0, 2 and 4 each way
+		{256, 256, 2, 2 * 2 * 128 * 255},
+	}
+	for _, tt := range tests {
+		if got := PotentialViewVertexCount(tt.width, tt.height, tt.stride); got != tt.want {
+			t.Errorf("PotentialViewVertexCount(%d, %d, %d) = %d, want %d", tt.width, tt.height, tt.stride, got, tt.want)
+		}
+	}
+}
+
+// TestGeneratePotentialViewShaders tests the texture lookup and uniforms
+func TestGeneratePotentialViewShaders(t *testing.T) {
+	vertex, fragment := GeneratePotentialViewShaders()
+	for _, want := range []string{
+		"#version 430",
+		"uniform sampler2D uPotential;",
+		"texelFetch(uPotential, ivec2(n.y, n.x), 0).r * uNormalization",
+		"node(gl_VertexID)",
+		"segment / (uGrid.y - 1) * uStride",
+		"uMVP * vec4(position, 1.0)",
+	} {
+		if !strings.Contains(vertex, want) {
+			t.Errorf("Vertex shader missing %q", want)
+		}
+	}
+	if !strings.Contains(fragment, "-vDisplacement / 10.0") {
+		t.Error("Fragment shader missing the heatmap depth")
+	}
+	if strings.Contains(vertex+fragment, "%!") {
+		t.Error("Shader contains a formatting error")
+	}
+}
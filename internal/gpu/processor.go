@@ -0,0 +1,140 @@
+package gpu
+
+import (
+	"errors"
+	"relativity_simulation_2d/internal/physics"
+)
+
+// ErrNoBackend is returned by a Processor without a Backend
+var ErrNoBackend = errors.New("processor has no compute backend")
+
+// Backend executes the particle-mesh steps of a simulation on one kind of
+// processor. A backend that fails must leave its outputs for the fallback
+// to overwrite: DepositMass and SolvePoisson rewrite their grids in full,
+// and IntegrateParticles must not fail after moving any particle
+type Backend interface {
+	// DepositMass clears the density grid and deposits the particle masses
+	DepositMass(density physics.Grid, particles []*physics.Particle) error
+
+	// SolvePoisson solves ∇²Φ = 4πGρ for the potential
+	SolvePoisson(potential, density physics.Grid, gravitationalConstant float64) error
+
+	// IntegrateParticles kicks the velocities by the field for kick, then
+	// drifts the positions for drift (0 = kick only) in the field's domain
+	IntegrateParticles(particles []*physics.Particle, field *physics.ForceField, kick, drift float32) error
+}
+
+// DepositMass deposits the particle masses with the processor's backend
+func (p *Processor) DepositMass(density physics.Grid, particles []*physics.Particle) error {
+	if p.Backend == nil {
+		return ErrNoBackend
+	}
+	return p.Backend.DepositMass(density, particles)
+}
+
+// SolvePoisson solves for the potential with the processor's backend
+func (p *Processor) SolvePoisson(potential, density physics.Grid, gravitationalConstant float64) error {
+	if p.Backend == nil {
+		return ErrNoBackend
+	}
+	return p.Backend.SolvePoisson(potential, density, gravitationalConstant)
+}
+
+// IntegrateParticles kicks and drifts the particles with the processor's backend
+func (p *Processor) IntegrateParticles(particles []*physics.Particle, field *physics.ForceField, kick, drift float32) error {
+	if p.Backend == nil {
+		return ErrNoBackend
+	}
+	return p.Backend.IntegrateParticles(particles, field, kick, drift)
+}
+
+// CPUBackend runs the particle-mesh steps with the physics package
+type CPUBackend struct {
+	Precision   physics.Precision           // Grid and FFT precision of the Poisson solve
+	Incremental *physics.IncrementalDeposit // Moves only changed particles' mass (nil = full deposits)
+	Mesh        *physics.Mesh               // Mesh the grids belong to (nil = unit cells)
+}
+
+// forceCorrectionFactor scales kicks as in the physics package's leapfrog
+const forceCorrectionFactor = 0.5
+
+// DepositMass deposits the particle masses with Cloud-in-Cell
+func (b CPUBackend) DepositMass(density physics.Grid, particles []*physics.Particle) error {
+	if b.Incremental != nil {
+		b.Incremental.Deposit(density, particles)
+		return nil
+	}
+	physics.DepositMassToGridInto(density, particles, b.Mesh)
+	return nil
+}
+
+// SolvePoisson solves for the potential with the CPU FFT
+func (b CPUBackend) SolvePoisson(potential, density physics.Grid, gravitationalConstant float64) error {
+	physics.SolvePoissonWithPrecision(b.Precision, potential, density, b.Mesh.Dx(), gravitationalConstant)
+	return nil
+}
+
+// IntegrateParticles kicks and drifts the particles on the CPU
+func (b CPUBackend) IntegrateParticles(particles []*physics.Particle, field *physics.ForceField, kick, drift float32) error {
+	physics.UpdateVelocities(particles, field, kick, forceCorrectionFactor)
+	if drift != 0 {
+		physics.UpdatePositions(particles, drift, field.Width, field.Height, field.Dx())
+	}
+	return nil
+}
+
+// RegisterBackend sets the backend of a processor type
+func (m *FallbackManager) RegisterBackend(processorType ProcessorType, backend Backend) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.backends[processorType] = backend
+}
+
+// SetGPUAvailable sets whether a GPU may be tried. A GPU reporting
+// ErrNoGLContext marks itself unavailable again
+func (m *FallbackManager) SetGPUAvailable(available bool) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.gpuAvailable = available
+}
+
+// Execute runs fn on the processor the mode selects. If a processor other
+// than the CPU fails, the error is reported, which moves later calls to the
+// CPU, and fn runs again on the CPU processor. The returned error is only
+// set if the CPU failed too; GetLastError has the error that caused a
+// fallback. Callers time whole steps with RecordPerformance, so that Auto
+// mode compares like with like
+func (m *FallbackManager) Execute(fn func(p *Processor) error) error {
+	processor := m.GetProcessor()
+	err := fn(processor)
+	if err == nil || processor.Type == ProcessorTypeCPU {
+		return err
+	}
+
+	m.ReportError(err)
+	m.mu.RLock()
+	cpu := &Processor{Type: ProcessorTypeCPU, Backend: m.backends[ProcessorTypeCPU]}
+	m.mu.RUnlock()
+	return fn(cpu)
+}
+
+// StepPM runs a kick-drift-kick particle-mesh step with each phase run by
+// Execute: the first kick and the drift with the field of the previous step,
+// the deposit and solve into density and potential, the gradient into field
+// and the second kick. A failing phase and the rest of the step run on the CPU
+func (m *FallbackManager) StepPM(particles []*physics.Particle, density, potential physics.Grid, field *physics.ForceField, deltaTime float32, gravitationalConstant float64) {
+	// The CPU backend cannot fail, so the phases always complete
+	_ = m.Execute(func(p *Processor) error {
+		return p.IntegrateParticles(particles, field, deltaTime*0.5, deltaTime)
+	})
+	_ = m.Execute(func(p *Processor) error {
+		if err := p.DepositMass(density, particles); err != nil {
+			return err
+		}
+		return p.SolvePoisson(potential, density, gravitationalConstant)
+	})
+	physics.CalculateGradientInto(field, potential)
+	_ = m.Execute(func(p *Processor) error {
+		return p.IntegrateParticles(particles, field, deltaTime*0.5, 0)
+	})
+}
@@ -0,0 +1,121 @@
+package gpu
+
+import (
+	"errors"
+	"relativity_simulation_2d/internal/physics"
+	"testing"
+)
+
+// failingBackend is a GPU backend whose Poisson solve always fails
+type failingBackend struct {
+	CPUBackend
+	err error
+}
+
+// SolvePoisson returns the backend's error
+func (b failingBackend) SolvePoisson(potential, density physics.Grid, gravitationalConstant float64) error {
+	return b.err
+}
+
+// TestExecuteFallback tests that a failing GPU phase is rerun on the CPU and
+// later phases stay there
+func TestExecuteFallback(t *testing.T) {
+	manager := NewFallbackManager()
+	manager.RegisterBackend(ProcessorTypeCPU, CPUBackend{})
+	manager.RegisterBackend(ProcessorTypeGPU, failingBackend{err: ErrNoGLContext})
+	manager.SetGPUAvailable(true)
+	manager.SetMode(ModeGPU)
+
+	density := physics.NewGrid(16, 16)
+	density[8][8] = 1
+	potential := physics.NewGrid(16, 16)
+	var used []ProcessorType
+	solve := func(p *Processor) error {
+		used = append(used, p.Type)
+		return p.SolvePoisson(potential, density, 1)
+	}
+
+	if err := manager.Execute(solve); err != nil {
+		t.Fatalf("Expected the CPU to complete the phase, got %v", err)
+	}
+	if len(used) != 2 || used[0] != ProcessorTypeGPU || used[1] != ProcessorTypeCPU {
+		t.Errorf("Expected a GPU attempt and a CPU rerun, got %v", used)
+	}
+	if !errors.Is(manager.GetLastError(), ErrNoGLContext) || manager.IsGPUAvailable() {
+		t.Error("Expected the GPU error to be recorded and the GPU marked unavailable")
+	}
+	if potential[8][8] >= 0 {
+		t.Errorf("Expected a potential well at the mass, got %g", potential[8][8])
+	}
+
+	used = nil
+	_ = manager.Execute(solve)
+	if len(used) != 1 || used[0] != ProcessorTypeCPU {
+		t.Errorf("Expected later phases on the CPU only, got %v", used)
+	}
+}
+
+// TestProcessorWithoutBackend tests that a type-only processor cannot compute
+func TestProcessorWithoutBackend(t *testing.T) {
+	p := &Processor{Type: ProcessorTypeCUDA}
+	if err := p.DepositMass(physics.NewGrid(4, 4), nil); !errors.Is(err, ErrNoBackend) {
+		t.Errorf("Expected ErrNoBackend, got %v", err)
+	}
+}
+
+// TestCPUBackendIntegrate tests that a kick-only call leaves positions alone
+func TestCPUBackendIntegrate(t *testing.T) {
+	field := &physics.ForceField{
+		AccelFieldX: physics.NewGrid(8, 8),
+		AccelFieldZ: physics.NewGrid(8, 8),
+		Width:       8,
+		Height:      8,
+	}
+	for i := range field.AccelFieldX {
+		for j := range field.AccelFieldX[i] {
+			field.AccelFieldX[i][j] = 2
+		}
+	}
+	p := &physics.Particle{Mass: 1}
+	particles := []*physics.Particle{p}
+
+	_ = CPUBackend{}.IntegrateParticles(particles, field, 1, 0)
+	if p.Velocity.X != 1 || p.Position.X != 0 {
+		t.Errorf("Expected a half-strength kick only, got velocity %g and position %g", p.Velocity.X, p.Position.X)
+	}
+	_ = CPUBackend{}.IntegrateParticles(particles, field, 0, 0.5)
+	if p.Position.X != 0.5 {
+		t.Errorf("Expected a drift to 0.5, got %g", p.Position.X)
+	}
+}
+
+// TestStepPMFallback tests that a step whose GPU solve fails completes on the CPU
+func TestStepPMFallback(t *testing.T) {
+	manager := NewFallbackManager()
+	manager.RegisterBackend(ProcessorTypeCPU, CPUBackend{})
+	manager.RegisterBackend(ProcessorTypeGPU, failingBackend{err: errors.New("dispatch failed")})
+	manager.SetGPUAvailable(true)
+	manager.SetMode(ModeGPU)
+
+	particles := []*physics.Particle{{Mass: 1, Position: physics.NewVec3(4, 0, 4)}, {Mass: 1, Position: physics.NewVec3(10, 0, 4)}}
+	density, potential := physics.NewGrid(16, 16), physics.NewGrid(16, 16)
+	field := &physics.ForceField{AccelFieldX: physics.NewGrid(16, 16), AccelFieldZ: physics.NewGrid(16, 16), Width: 16, Height: 16}
+	manager.StepPM(particles, density, potential, field, 0.1, 1)
+
+	if !manager.HasError() || manager.GetProcessor().GetType() != ProcessorTypeCPU {
+		t.Fatal("Expected the failed solve to move the manager to the CPU")
+	}
+	total, deepest := 0.0, 0.0
+	for i := range density {
+		for j := range density[i] {
+			total += density[i][j]
+			deepest = min(deepest, potential[i][j])
+		}
+	}
+	if total < 1.99 || total > 2.01 || deepest >= 0 {
+		t.Errorf("Expected the CPU to deposit and solve, got mass %g and deepest potential %g", total, deepest)
+	}
+	if particles[0].Velocity.X <= 0 || particles[1].Velocity.X >= 0 {
+		t.Errorf("Expected the particles kicked towards each other, got %g and %g", particles[0].Velocity.X, particles[1].Velocity.X)
+	}
+}
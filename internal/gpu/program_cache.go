@@ -0,0 +1,86 @@
+package gpu
+
+import (
+	"crypto/sha256"
+	"encoding/binary"
+	"encoding/hex"
+	"fmt"
+	"os"
+	"path/filepath"
+)
+
+// programCacheVersion is part of every cache key; bump it when the file
+// layout changes
+const programCacheVersion = "1"
+
+// ProgramCache stores linked program binaries on disk, keyed by the GL driver
+// and the shader sources, so that later runs load programs instead of
+// compiling and linking them. A driver update changes the key, and a binary
+// the driver rejects is removed and rebuilt from source
+type ProgramCache struct {
+	Dir    string // Directory holding one file per program
+	Driver string // Vendor, renderer and version strings of the GL driver
+}
+
+// NewProgramCache creates a cache in dir for programs built by the driver
+func NewProgramCache(dir, driver string) *ProgramCache {
+	return &ProgramCache{Dir: dir, Driver: driver}
+}
+
+// Key returns the cache key of a program linked from the given sources
+func (c *ProgramCache) Key(sources ...string) string {
+	h := sha256.New()
+	for _, s := range append([]string{programCacheVersion, c.Driver}, sources...) {
+		binary.Write(h, binary.LittleEndian, uint64(len(s)))
+		h.Write([]byte(s))
+	}
+	return hex.EncodeToString(h.Sum(nil))
+}
+
+// path returns the file of a key
+func (c *ProgramCache) path(key string) string {
+	return filepath.Join(c.Dir, key+".bin")
+}
+
+// Load returns the binary format and data stored under key, or false if
+// there is no usable entry
+func (c *ProgramCache) Load(key string) (format uint32, data []byte, ok bool) {
+	file, err := os.ReadFile(c.path(key))
+	if err != nil || len(file) <= 4 {
+		return 0, nil, false
+	}
+	return binary.LittleEndian.Uint32(file), file[4:], true
+}
+
+// Store saves a program binary under key. The file is written in full
+// before it replaces any previous entry, so concurrent runs never read a
+// partial binary
+func (c *ProgramCache) Store(key string, format uint32, data []byte) error {
+	if len(data) == 0 {
+		return fmt.Errorf("invalid program binary: empty")
+	}
+	if err := os.MkdirAll(c.Dir, 0o755); err != nil {
+		return err
+	}
+	tmp, err := os.CreateTemp(c.Dir, key+".*.tmp")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(tmp.Name())
+
+	var header [4]byte
+	binary.LittleEndian.PutUint32(header[:], format)
+	_, err = tmp.Write(append(header[:], data...))
+	if closeErr := tmp.Close(); err == nil {
+		err = closeErr
+	}
+	if err != nil {
+		return err
+	}
+	return os.Rename(tmp.Name(), c.path(key))
+}
+
+// Remove deletes the entry under key, for binaries the driver rejected
+func (c *ProgramCache) Remove(key string) {
+	os.Remove(c.path(key))
+}
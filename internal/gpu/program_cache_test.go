@@ -0,0 +1,57 @@
+package gpu
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+// TestProgramCacheKey tests that keys depend on the driver and every source
+func TestProgramCacheKey(t *testing.T) {
+	c := NewProgramCache(t.TempDir(), "Vendor Renderer 4.6")
+	key := c.Key("void main() {}")
+	if key != c.Key("void main() {}") {
+		t.Error("Expected the same key for the same source")
+	}
+	if key == c.Key("void main() { }") {
+		t.Error("Expected a different key for a different source")
+	}
+	if key == NewProgramCache(c.Dir, "Vendor Renderer 4.6.1").Key("void main() {}") {
+		t.Error("Expected a different key for a different driver")
+	}
+	if c.Key("ab", "c") == c.Key("a", "bc") {
+		t.Error("Expected the split between sources to change the key")
+	}
+}
+
+// TestProgramCacheRoundTrip tests storing, loading and removing a binary
+func TestProgramCacheRoundTrip(t *testing.T) {
+	dir := filepath.Join(t.TempDir(), "shaders")
+	c := NewProgramCache(dir, "driver")
+	key := c.Key("source")
+	if _, _, ok := c.Load(key); ok {
+		t.Fatal("Expected no entry in an empty cache")
+	}
+
+	data := []byte{1, 2, 3, 4, 5}
+	if err := c.Store(key, 0x8e21, data); err != nil {
+		t.Fatal(err)
+	}
+	format, loaded, ok := c.Load(key)
+	if !ok || format != 0x8e21 || !bytes.Equal(loaded, data) {
+		t.Errorf("Expected format 0x8e21 and %v, got 0x%x and %v (ok %v)", data, format, loaded, ok)
+	}
+	entries, _ := os.ReadDir(dir)
+	if len(entries) != 1 {
+		t.Errorf("Expected only the entry in the cache directory, got %d files", len(entries))
+	}
+
+	c.Remove(key)
+	if _, _, ok := c.Load(key); ok {
+		t.Error("Expected the entry to be removed")
+	}
+	if err := c.Store(key, 1, nil); err == nil {
+		t.Error("Expected an error for an empty binary")
+	}
+}
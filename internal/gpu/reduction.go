@@ -0,0 +1,150 @@
+package gpu
+
+import (
+	"fmt"
+)
+
+// ReductionLocalSize is the work group size of the reduction kernels. Each
+// group reduces 2*ReductionLocalSize elements to one partial result
+const ReductionLocalSize = 256
+
+// Binding points of the reduction kernels' shader storage buffers
+const (
+	ReductionInputBinding  = 0
+	ReductionOutputBinding = 1
+)
+
+// ReductionKind selects what a reduction kernel computes
+type ReductionKind int
+
+const (
+	// ReduceMoments sums particle kinetic energy, momentum and mass from
+	// vec4 (vx, vz, mass, unused) inputs
+	ReduceMoments ReductionKind = iota
+	// ReduceRange finds the minimum and maximum of the real parts of a
+	// complex (vec2) grid, scaled by uScale
+	ReduceRange
+)
+
+// ParticleMoments is the result of a ReduceMoments reduction
+type ParticleMoments struct {
+	KineticEnergy float64
+	MomentumX     float64
+	MomentumZ     float64
+	TotalMass     float64
+}
+
+// PotentialRange is the result of a ReduceRange reduction
+type PotentialRange struct {
+	Min, Max float64
+}
+
+// NewParticleMoments unpacks the final vec4 of a ReduceMoments reduction
+func NewParticleMoments(v [4]float32) ParticleMoments {
+	return ParticleMoments{
+		KineticEnergy: float64(v[0]),
+		MomentumX:     float64(v[1]),
+		MomentumZ:     float64(v[2]),
+		TotalMass:     float64(v[3]),
+	}
+}
+
+// NewPotentialRange unpacks the final vec4 of a ReduceRange reduction
+func NewPotentialRange(v [4]float32) PotentialRange {
+	return PotentialRange{Min: float64(v[0]), Max: float64(v[1])}
+}
+
+// ReductionGroups returns the number of work groups for one pass over n elements
+func ReductionGroups(n, localSize int) int {
+	return (n + 2*localSize - 1) / (2 * localSize)
+}
+
+// ReductionPasses returns the element count read by each pass of a reduction
+// of n elements; the last pass runs a single work group
+func ReductionPasses(n, localSize int) []int {
+	if n <= 0 {
+		return nil
+	}
+	passes := []int{n}
+	for groups := ReductionGroups(n, localSize); groups > 1; groups = ReductionGroups(groups, localSize) {
+		passes = append(passes, groups)
+	}
+	return passes
+}
+
+// GenerateReductionShader generates one pass of a parallel tree reduction.
+// The first pass reads the raw inputs of kind; later passes combine the vec4
+// partial results of the previous pass. Every pass writes one vec4 per work
+// group. localSize must be a power of two
+func GenerateReductionShader(kind ReductionKind, localSize int, firstPass bool) string {
+	var input, load, identity, combine string
+	switch kind {
+	case ReduceMoments:
+		identity = "vec4(0.0)"
+		combine = "a + b"
+		if firstPass {
+			input = "vec4"
+			load = "vec4(0.5 * v.z * dot(v.xy, v.xy), v.z * v.x, v.z * v.y, v.z)"
+		}
+	case ReduceRange:
+		identity = "vec4(uintBitsToFloat(0x7F800000u), uintBitsToFloat(0xFF800000u), 0.0, 0.0)" // (+Inf, -Inf)
+		combine = "vec4(min(a.x, b.x), max(a.y, b.y), 0.0, 0.0)"
+		if firstPass {
+			input = "vec2"
+			load = "vec4(v.x * uScale, v.x * uScale, 0.0, 0.0)"
+		}
+	}
+	if !firstPass {
+		input = "vec4"
+		load = "v"
+	}
+
+	return fmt.Sprintf(`#version 430
+layout(local_size_x = %[1]d) in;
+
+layout(std430, binding = %[2]d) readonly buffer Input {
+    %[3]s inputs[];
+};
+
+layout(std430, binding = %[4]d) writeonly buffer Output {
+    vec4 outputs[];
+};
+
+uniform uint uCount;
+uniform float uScale;
+
+shared vec4 partial[%[1]d];
+
+vec4 load(uint k) {
+    if (k >= uCount) return %[5]s;
+    %[3]s v = inputs[k];
+    return %[6]s;
+}
+
+vec4 combine(vec4 a, vec4 b) {
+    return %[7]s;
+}
+
+void main() {
+    uint lane = gl_LocalInvocationID.x;
+    uint base = gl_WorkGroupID.x * %[8]du;
+
+    // The first combine happens while loading, so each group covers two elements per invocation
+    partial[lane] = combine(load(base + lane), load(base + lane + %[1]du));
+    memoryBarrierShared();
+    barrier();
+
+    for (uint stride = %[1]du / 2u; stride > 0u; stride >>= 1) {
+        if (lane < stride) {
+            partial[lane] = combine(partial[lane], partial[lane + stride]);
+        }
+        memoryBarrierShared();
+        barrier();
+    }
+
+    if (lane == 0u) {
+        outputs[gl_WorkGroupID.x] = partial[0];
+    }
+}
+`, localSize, ReductionInputBinding, input, ReductionOutputBinding, identity, load, combine, 2*localSize)
+}
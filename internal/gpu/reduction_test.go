@@ -0,0 +1,79 @@
+package gpu
+
+import (
+	"strings"
+	"testing"
+)
+
+// TestReductionPasses tests the pass plan down to a single work group
+func TestReductionPasses(t *testing.T) {
+	tests := []struct {
+		n    int
+		want []int
+	}{
+		{0, nil},
+		{1, []int{1}},
+		{512, []int{512}},
+		{513, []int{513, 2}},
+		{256 * 256, []int{65536, 128}},
+		{1000000, []int{1000000, 1954, 4}},
+	}
+	for _, tt := range tests {
+		got := ReductionPasses(tt.n, ReductionLocalSize)
+		if len(got) != len(tt.want) {
+			t.Errorf("ReductionPasses(%d) = %v, want %v", tt.n, got, tt.want)
+			continue
+		}
+		for i := range got {
+			if got[i] != tt.want[i] {
+				t.Errorf("ReductionPasses(%d) = %v, want %v", tt.n, got, tt.want)
+				break
+			}
+		}
+	}
+}
+
+// TestGenerateReductionShader tests the input layout of each pass
+func TestGenerateReductionShader(t *testing.T) {
+	manager := NewShaderManager()
+	tests := []struct {
+		name      string
+		kind      ReductionKind
+		firstPass bool
+		want      []string
+	}{
+		{"moments first pass", ReduceMoments, true, []string{"vec4 inputs[];", "0.5 * v.z * dot(v.xy, v.xy)", "return a + b;"}},
+		{"moments later pass", ReduceMoments, false, []string{"vec4 inputs[];", "return v;", "return a + b;"}},
+		{"range first pass", ReduceRange, true, []string{"vec2 inputs[];", "v.x * uScale", "min(a.x, b.x)"}},
+		{"range later pass", ReduceRange, false, []string{"vec4 inputs[];", "return v;", "max(a.y, b.y)"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			source := GenerateReductionShader(tt.kind, ReductionLocalSize, tt.firstPass)
+			if !manager.ValidateShaderSource(source) {
+				t.Fatal("Generated shader failed validation")
+			}
+			want := append([]string{"layout(local_size_x = 256) in;", "shared vec4 partial[256];", "gl_WorkGroupID.x * 512u"}, tt.want...)
+			for _, w := range want {
+				if !strings.Contains(source, w) {
+					t.Errorf("Shader missing %q", w)
+				}
+			}
+			if strings.Contains(source, "%!") {
+				t.Error("Shader contains a formatting error")
+			}
+		})
+	}
+}
+
+// TestReductionResults tests unpacking of the final vec4
+func TestReductionResults(t *testing.T) {
+	m := NewParticleMoments([4]float32{1.5, -2, 3, 4})
+	if m.KineticEnergy != 1.5 || m.MomentumX != -2 || m.MomentumZ != 3 || m.TotalMass != 4 {
+		t.Errorf("Unexpected moments %+v", m)
+	}
+	r := NewPotentialRange([4]float32{-7, 2, 0, 0})
+	if r.Min != -7 || r.Max != 2 {
+		t.Errorf("Unexpected range %+v", r)
+	}
+}
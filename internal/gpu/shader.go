@@ -1,7 +1,6 @@
 package gpu
 
 import (
-	"errors"
 	"fmt"
 	"strings"
 )
@@ -22,7 +21,7 @@ func NewShaderManager() *ShaderManager {
 func (m *ShaderManager) CompileComputeShader(source string) (*ComputeShader, error) {
 	// Without OpenGL context, we cannot actually compile
 	// This is a placeholder that will be implemented when GPU support is added
-	return nil, errors.New("OpenGL context not available")
+	return nil, ErrNoGLContext
 }
 
 // DeleteShader deletes a compiled shader
@@ -113,3 +112,182 @@ func (m *ShaderManager) ValidateShaderSource(source string) bool {
 
 	return hasVersion && hasMain && hasLayout
 }
+
+// GenerateNaiveDFTShader generates the O(N²) DFT compute shader used for grid
+// sizes that are not powers of two, with localSize invocations per work group
+func (m *ShaderManager) GenerateNaiveDFTShader(width, height int, forward bool, localSize int) string {
+	direction := "1.0"
+	if !forward {
+		direction = "-1.0"
+	}
+
+	return fmt.Sprintf(`
+		#version 430
+		layout(local_size_x = %d) in;
+
+		layout(std430, binding = 0) buffer InputBuffer {
+			vec2 inputData[];
+		};
+		layout(std430, binding = 1) buffer OutputBuffer {
+			vec2 outputData[];
+		};
+
+		const float PI = 3.14159265359;
+		const float direction = %s;
+		const int WIDTH = %d;
+		const int HEIGHT = %d;
+		const int TOTAL_SIZE = WIDTH * HEIGHT;
+
+		vec2 complexMul(vec2 a, vec2 b) {
+			return vec2(a.x * b.x - a.y * b.y, a.x * b.y + a.y * b.x);
+		}
+
+		void main() {
+			uint index = gl_GlobalInvocationID.x;
+			if (index >= TOTAL_SIZE) return;
+
+			// Row-major i*HEIGHT + j, like the Green's function: j changes fastest
+			uint outputI = index / HEIGHT;
+			uint outputJ = index %% HEIGHT;
+
+			vec2 sum = vec2(0.0, 0.0);
+
+			for (uint inputI = 0; inputI < WIDTH; inputI++) {
+				for (uint inputJ = 0; inputJ < HEIGHT; inputJ++) {
+					// e^(-iθ) forward, like the radix-2 kernel. The phases are
+					// reduced modulo the period, so float keeps their precision
+					float angle = -direction * 2.0 * PI * (
+						float((outputI * inputI) %% WIDTH) / float(WIDTH) +
+						float((outputJ * inputJ) %% HEIGHT) / float(HEIGHT)
+					);
+					vec2 twiddle = vec2(cos(angle), sin(angle));
+					vec2 inputSample = inputData[inputI * HEIGHT + inputJ];
+					sum += complexMul(inputSample, twiddle);
+				}
+			}
+
+			// The inverse is left unnormalized, like the radix-2 kernel
+			outputData[index] = sum;
+		}
+	`, localSize, direction, width, height)
+}
+
+// GenerateCooleyTukeyShader generates the radix-2 FFT compute shader, run once
+// per stage with the stage, direction and pass set as uniforms, with
+// localSize invocations per work group
+func (m *ShaderManager) GenerateCooleyTukeyShader(width, height, localSize int) string {
+	return fmt.Sprintf(`
+		#version 430
+		layout(local_size_x = %d, local_size_y = 1) in;
+
+		layout(std430, binding = 0) buffer InputBuffer {
+			vec2 inputData[];
+		};
+		layout(std430, binding = 1) buffer OutputBuffer {
+			vec2 outputData[];
+		};
+
+		uniform int stage;      // Current FFT stage (0 to log2(size)-1)
+		uniform int direction_flag;  // 1 for forward, -1 for inverse
+		uniform int is_column_pass;  // 0 for row pass, 1 for column pass
+
+		const float PI = 3.14159265359;
+		const int WIDTH = %d;
+		const int HEIGHT = %d;
+		const int TOTAL_SIZE = WIDTH * HEIGHT;
+
+		vec2 complexMul(vec2 a, vec2 b) {
+			return vec2(a.x * b.x - a.y * b.y, a.x * b.y + a.y * b.x);
+		}
+
+		// Bit reversal for FFT
+		uint bitReverse(uint x, uint bits) {
+			uint result = 0;
+			for (uint i = 0; i < bits; i++) {
+				if ((x & (1u << i)) != 0) {
+					result |= 1u << (bits - 1 - i);
+				}
+			}
+			return result;
+		}
+
+		void main() {
+			uint index = gl_GlobalInvocationID.x;
+
+			// Row-major i*HEIGHT + j, like the Green's function: a row is the
+			// HEIGHT values of one i, a column the WIDTH values of one j
+			if (is_column_pass == 0) {
+				// Row pass: process each row independently
+				uint row = index / HEIGHT;
+				uint col = index %% HEIGHT;
+
+				if (row >= WIDTH || col >= HEIGHT) return;
+
+				if (stage == -1) {
+					// Bit reversal stage for rows
+					uint bits = uint(findMSB(uint(HEIGHT)));
+					uint reversedCol = bitReverse(col, bits);
+					uint srcIndex = row * HEIGHT + col;
+					uint dstIndex = row * HEIGHT + reversedCol;
+					outputData[dstIndex] = inputData[srcIndex];
+				} else {
+					// Butterfly operations for current stage
+					uint stepSize = 1u << (stage + 1);
+					uint halfStep = stepSize >> 1;
+					uint pos = col %% stepSize;
+
+					if (pos < halfStep) {
+						uint partner = index + halfStep;
+						if (partner < TOTAL_SIZE) {
+							float angle = float(direction_flag) * (-2.0 * PI * float(pos)) / float(stepSize);
+							vec2 twiddle = vec2(cos(angle), sin(angle));
+
+							vec2 a = inputData[index];
+							vec2 b = complexMul(inputData[partner], twiddle);
+
+							outputData[index] = a + b;
+							outputData[partner] = a - b;
+						}
+					}
+				}
+			} else {
+				// Column pass: process each column independently
+				uint col = index / WIDTH;
+				uint row = index %% WIDTH;
+
+				if (col >= HEIGHT || row >= WIDTH) return;
+
+				if (stage == -1) {
+					// Bit reversal stage for columns
+					uint bits = uint(findMSB(uint(WIDTH)));
+					uint reversedRow = bitReverse(row, bits);
+					uint srcIndex = row * HEIGHT + col;
+					uint dstIndex = reversedRow * HEIGHT + col;
+					outputData[dstIndex] = inputData[srcIndex];
+				} else {
+					// Butterfly operations for current stage
+					uint stepSize = 1u << (stage + 1);
+					uint halfStep = stepSize >> 1;
+					uint pos = row %% stepSize;
+
+					if (pos < halfStep) {
+						uint partnerRow = row + halfStep;
+						if (partnerRow < WIDTH) {
+							uint currentIndex = row * HEIGHT + col;
+							uint partnerIndex = partnerRow * HEIGHT + col;
+
+							float angle = float(direction_flag) * (-2.0 * PI * float(pos)) / float(stepSize);
+							vec2 twiddle = vec2(cos(angle), sin(angle));
+
+							vec2 a = inputData[currentIndex];
+							vec2 b = complexMul(inputData[partnerIndex], twiddle);
+
+							outputData[currentIndex] = a + b;
+							outputData[partnerIndex] = a - b;
+						}
+					}
+				}
+			}
+		}
+	`, localSize, width, height)
+}
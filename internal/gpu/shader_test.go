@@ -1,6 +1,7 @@
 package gpu
 
 import (
+	"errors"
 	"strings"
 	"testing"
 )
@@ -41,8 +42,7 @@ func TestShaderCompilation(t *testing.T) {
 		}
 	} else {
 		// Expected to fail without OpenGL context
-		if !strings.Contains(err.Error(), "OpenGL context") &&
-			!strings.Contains(err.Error(), "not available") {
+		if !errors.Is(err, ErrNoGLContext) {
 			t.Errorf("Unexpected error: %v", err)
 		}
 	}
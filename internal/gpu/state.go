@@ -0,0 +1,175 @@
+package gpu
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+)
+
+// KernelTiming is how long the last run of a GPU operation took
+type KernelTiming struct {
+	Name     string
+	Duration time.Duration
+}
+
+// KernelTimings keeps the duration of the last run of each GPU operation.
+// Durations are measured on the CPU around the GL calls: a kernel queued
+// without a readback counts the time to submit it, and the readback that
+// follows also waits for the kernels before it. Like the GL context, it is
+// used from one goroutine
+type KernelTimings struct {
+	timings []KernelTiming // In the order the operations first ran
+}
+
+// Record sets the duration of the last run of the operation name
+func (t *KernelTimings) Record(name string, d time.Duration) {
+	for i := range t.timings {
+		if t.timings[i].Name == name {
+			t.timings[i].Duration = d
+			return
+		}
+	}
+	t.timings = append(t.timings, KernelTiming{Name: name, Duration: d})
+}
+
+// Since records the time elapsed since start as the last run of name
+func (t *KernelTimings) Since(name string, start time.Time) {
+	t.Record(name, time.Since(start))
+}
+
+// Last returns the last run of each operation, in the order they first ran
+func (t *KernelTimings) Last() []KernelTiming {
+	return append([]KernelTiming(nil), t.timings...)
+}
+
+// Keys returns the keys of the cached plans, least recently used first
+func (c *FFTPlanCache) Keys() []FFTPlanKey {
+	return append([]FFTPlanKey(nil), c.order...)
+}
+
+// String describes the plan key, e.g. "256x128 forward float32"
+func (k FFTPlanKey) String() string {
+	direction := "inverse"
+	if k.Forward {
+		direction = "forward"
+	}
+	return fmt.Sprintf("%dx%d %s %s", k.Width, k.Height, direction, k.Precision)
+}
+
+// String names the processor type
+func (p ProcessorType) String() string {
+	switch p {
+	case ProcessorTypeCPU:
+		return "CPU"
+	case ProcessorTypeGPU:
+		return "GPU"
+	case ProcessorTypeCUDA:
+		return "CUDA"
+	default:
+		return "Unknown"
+	}
+}
+
+// State is a snapshot of the GPU compute path for debugging, such as why a
+// run fell back to the CPU, without attaching a debugger
+type State struct {
+	Initialized  bool           // A GL context was created for compute
+	Context      string         // Version and renderer of the context
+	Mode         ComputeMode    // Configured compute mode
+	Processor    ProcessorType  // Processor the mode selects now
+	AutoReason   string         // Why Auto mode selects it
+	Fallback     error          // Error that moved the run to the CPU (nil = none)
+	GLError      error          // Last OpenGL error reported (nil = none)
+	Plans        []FFTPlanKey   // Cached FFT plans, least recently used first
+	PlanStats    FFTPlanStats   // Lookups of the plan cache
+	Shaders      []string       // Keys of the compiled compute shaders, sorted
+	ProgramCache string         // Directory of the on-disk program binaries ("" = off)
+	Pool         PoolStats      // Latest counters of the buffer pool
+	Timings      []KernelTiming // Last run of each GPU operation
+}
+
+// NewState collects the state of g and m, either of which may be nil when
+// no context or compute manager exists
+func NewState(g *GPU, m *FallbackManager) State {
+	var s State
+	if m != nil {
+		s.Mode = m.GetMode()
+		s.Processor = m.GetProcessor().GetType()
+		s.AutoReason = m.AutoReason()
+		s.Fallback = m.GetLastError()
+		s.Pool = m.GetPerformanceStats().Pool
+	}
+	if g == nil {
+		return s
+	}
+	s.Initialized = g.Initialized
+	s.Context = fmt.Sprintf("OpenGL %d.%d, %s", g.Caps.Major, g.Caps.Minor, g.Caps.Renderer)
+	if g.FftPlanCache != nil {
+		s.Plans = g.FftPlanCache.Keys()
+		s.PlanStats = g.FftPlanCache.Stats()
+	}
+	for key := range g.ShaderCache {
+		s.Shaders = append(s.Shaders, key)
+	}
+	sort.Strings(s.Shaders)
+	if g.ProgramCache != nil {
+		s.ProgramCache = g.ProgramCache.Dir
+	}
+	s.Timings = g.Timings.Last()
+	return s
+}
+
+// Lines formats the state for the overlay and the log, one item per line
+// with the items of a list indented below it
+func (s State) Lines() []string {
+	context := "none (GPU not initialized)"
+	if s.Initialized {
+		context = s.Context
+	}
+	lines := []string{
+		"Context: " + context,
+		fmt.Sprintf("Compute: %s mode on the %s", s.Mode, s.Processor),
+	}
+	if s.Mode == ModeAuto {
+		lines = append(lines, "Auto: "+s.AutoReason)
+	}
+	lines = append(lines,
+		"Fallback: "+errorText(s.Fallback),
+		"Last GL error: "+errorText(s.GLError),
+		fmt.Sprintf("FFT plans: %d (%d hits, %d misses, %d evicted)", s.PlanStats.Plans, s.PlanStats.Hits, s.PlanStats.Misses, s.PlanStats.Evicted),
+	)
+	for _, key := range s.Plans {
+		lines = append(lines, "  "+key.String())
+	}
+	lines = append(lines, fmt.Sprintf("Shaders: %d", len(s.Shaders)))
+	for _, key := range s.Shaders {
+		lines = append(lines, "  "+key)
+	}
+	programs := s.ProgramCache
+	if programs == "" {
+		programs = "off"
+	}
+	lines = append(lines,
+		"Program cache: "+programs,
+		fmt.Sprintf("Buffer pool: %d pooled, %d outstanding (%d hits, %d misses, %d returns)", s.Pool.Pooled, s.Pool.Outstanding, s.Pool.Hits, s.Pool.Misses, s.Pool.Returns),
+		fmt.Sprintf("Timings: %d", len(s.Timings)),
+	)
+	for _, t := range s.Timings {
+		lines = append(lines, fmt.Sprintf("  %s %.3f ms", t.Name, float64(t.Duration.Microseconds())/1000))
+	}
+	return lines
+}
+
+// String returns the lines of the state, for the log
+func (s State) String() string {
+	return strings.Join(s.Lines(), "\n")
+}
+
+// errorText returns the text of err, or "none" if it is nil
+func errorText(err error) string {
+	if err == nil {
+		return "none"
+	}
+	return err.Error()
+}
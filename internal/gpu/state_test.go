@@ -0,0 +1,86 @@
+package gpu
+
+import (
+	"errors"
+	"relativity_simulation_2d/internal/physics"
+	"slices"
+	"strings"
+	"testing"
+	"time"
+)
+
+// TestKernelTimings tests that each operation keeps its last duration in
+// the order the operations first ran
+func TestKernelTimings(t *testing.T) {
+	var timings KernelTimings
+	timings.Record("forward FFT", 3*time.Millisecond)
+	timings.Record("gradient", time.Millisecond)
+	timings.Record("forward FFT", 2*time.Millisecond)
+
+	want := []KernelTiming{{"forward FFT", 2 * time.Millisecond}, {"gradient", time.Millisecond}}
+	got := timings.Last()
+	if !slices.Equal(got, want) {
+		t.Fatalf("Expected %v, got %v", want, got)
+	}
+	got[0].Duration = 0
+	if timings.Last()[0].Duration != 2*time.Millisecond {
+		t.Error("Expected Last to return a copy")
+	}
+}
+
+// TestNewState tests collecting the state of a GPU and its compute manager
+func TestNewState(t *testing.T) {
+	m := NewFallbackManager()
+	m.SetGPUAvailable(true)
+	m.SetMode(ModeGPU)
+	m.ReportError(errors.New("shader compilation failed"))
+
+	g := &GPU{
+		Initialized:  true,
+		Caps:         Capabilities{Major: 4, Minor: 6, Renderer: "Test Renderer"},
+		FftPlanCache: NewFFTPlanCache(0),
+		ShaderCache:  map[string]*ComputeShader{"gradient_64": nil, "direct_force_256": nil},
+		ProgramCache: NewProgramCache("/tmp/programs", "driver"),
+	}
+	key := FFTPlanKey{Width: 64, Height: 32, Forward: true, Precision: physics.PrecisionFloat32}
+	if _, err := g.FftPlanCache.Get(key, func() (*GPUFFTPlan, error) { return &GPUFFTPlan{}, nil }, func(*GPUFFTPlan) {}); err != nil {
+		t.Fatalf("Get failed: %v", err)
+	}
+	g.Timings.Record("forward FFT", 1500*time.Microsecond)
+
+	s := NewState(g, m)
+	if s.Processor != ProcessorTypeCPU || s.Fallback == nil {
+		t.Errorf("Expected the failed GPU to fall back to the CPU, got %v with error %v", s.Processor, s.Fallback)
+	}
+	if !slices.Equal(s.Shaders, []string{"direct_force_256", "gradient_64"}) {
+		t.Errorf("Expected sorted shader keys, got %v", s.Shaders)
+	}
+	text := s.String()
+	for _, want := range []string{
+		"Context: OpenGL 4.6, Test Renderer",
+		"Compute: CPU mode on the CPU",
+		"Fallback: shader compilation failed",
+		"Last GL error: none",
+		"FFT plans: 1 (0 hits, 1 misses, 0 evicted)",
+		"  64x32 forward float32",
+		"  gradient_64",
+		"Program cache: /tmp/programs",
+		"  forward FFT 1.500 ms",
+	} {
+		if !strings.Contains(text, want) {
+			t.Errorf("Expected %q in the state:\n%s", want, text)
+		}
+	}
+}
+
+// TestNewStateWithoutGPU tests the state before a GPU context exists
+func TestNewStateWithoutGPU(t *testing.T) {
+	s := NewState(nil, nil)
+	lines := s.Lines()
+	if lines[0] != "Context: none (GPU not initialized)" {
+		t.Errorf("Expected no context, got %q", lines[0])
+	}
+	if !strings.Contains(s.String(), "Program cache: off") {
+		t.Errorf("Expected the program cache off:\n%s", s)
+	}
+}
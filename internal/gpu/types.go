@@ -2,11 +2,34 @@ package gpu
 
 // GPU holds the GPU context and state
 type GPU struct {
-	Initialized  bool
-	Headless     bool
-	NeedsCleanup bool                      // True if we need to clean up raylib context
-	FftPlanCache map[string]*GPUFFTPlan    // Cache FFT plans by size/direction
-	ShaderCache  map[string]*ComputeShader // Cache compiled shaders by source
+	Initialized   bool
+	Headless      bool
+	NeedsCleanup  bool                      // Holds a GLContext reference that CleanupGPU releases
+	BufferStorage bool                      // Persistently mapped buffers are available
+	Caps          Capabilities              // Version and extensions of the context
+	FftPlanCache  *FFTPlanCache             // FFT plans by size, direction and precision
+	ShaderCache   map[string]*ComputeShader // Cache compiled shaders by source
+	UploadRing    UploadRing                // Particle upload buffers (used when BufferStorage is set)
+	ProgramCache  *ProgramCache             // On-disk compute program binaries (nil = always compile)
+	Workgroups    Workgroups                // Work group sizes of the Poisson solver kernels
+	Tuner         *WorkgroupTuner           // Benchmarks and stores Workgroups per grid size (nil = defaults)
+	Timings       KernelTimings             // Duration of the last run of each GPU operation
+
+	// Unnormalized inverse FFT of the last Poisson solve, kept on the GPU for
+	// diagnostics reductions (nil = none yet)
+	PotentialBuffer *ComplexGPUBuffer
+
+	// Render state for drawing PotentialBuffer without a CPU readback
+	PotentialView PotentialView
+}
+
+// PotentialView holds the GL objects that draw the potential grid from a texture
+type PotentialView struct {
+	ProgramID     uint32
+	TextureID     uint32 // RG32F copy of PotentialBuffer (0 = not created)
+	VertexArrayID uint32 // Empty VAO; vertices come from gl_VertexID
+	Width         int
+	Height        int
 }
 
 // GPUMemoryBuffer represents a GPU memory buffer
@@ -26,6 +49,11 @@ type GPUFFTPlan struct {
 	Width     int
 	Height    int
 	IsForward bool
+
+	// Built on first execution and reused until the plan is destroyed
+	Shader    *ComputeShader    // FFT kernel (nil = not compiled yet)
+	GroupSize int               // Work group size Shader was compiled for
+	Temp      *ComplexGPUBuffer // Ping-pong buffer of the Cooley-Tukey passes (nil = not allocated yet)
 }
 
 // ComplexGPUBuffer represents a GPU buffer for complex numbers
@@ -0,0 +1,62 @@
+package gpu
+
+import (
+	"strings"
+)
+
+// UploadRingSlots is the number of buffers in an UploadRing: the CPU fills one
+// while the GPU may still be reading the previous two uploads
+const UploadRingSlots = 3
+
+// BufferStorageExtension is the extension providing immutable, persistently
+// mappable buffers (core since OpenGL 4.4)
+const BufferStorageExtension = "GL_ARB_buffer_storage"
+
+// UploadSlot is one persistently mapped buffer of an UploadRing
+type UploadSlot struct {
+	BufferID uint32
+	Size     int       // Capacity in bytes
+	Data     []float32 // Persistent, coherent mapping of the whole buffer (nil = not allocated)
+	Fence    uintptr   // Sync object signalled once the GPU is done with the slot (0 = idle)
+}
+
+// UploadRing hands out upload buffers round-robin so a new upload never
+// writes into a buffer the GPU is still reading, which would otherwise make
+// the driver synchronize or orphan the buffer on every upload
+type UploadRing struct {
+	Slots [UploadRingSlots]UploadSlot
+	next  int
+}
+
+// Next returns the slot to fill next and advances the ring. The caller must
+// wait for the slot's fence before writing to it
+func (r *UploadRing) Next() *UploadSlot {
+	slot := &r.Slots[r.next]
+	r.next = (r.next + 1) % UploadRingSlots
+	return slot
+}
+
+// RingCapacity returns the byte size to allocate for an upload of size bytes,
+// rounded up to a power of two (at least 4 KiB) so a slowly growing particle
+// count reallocates rarely
+func RingCapacity(size int) int {
+	capacity := 4096
+	for capacity < size {
+		capacity *= 2
+	}
+	return capacity
+}
+
+// SupportsBufferStorage reports whether a context with the given version and
+// extensions provides persistently mapped buffers
+func SupportsBufferStorage(major, minor int, extensions []string) bool {
+	if major > 4 || (major == 4 && minor >= 4) {
+		return true
+	}
+	for _, ext := range extensions {
+		if strings.TrimSpace(ext) == BufferStorageExtension {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,62 @@
+package gpu
+
+import (
+	"testing"
+)
+
+// TestUploadRingNext tests that slots are handed out round-robin
+func TestUploadRingNext(t *testing.T) {
+	var ring UploadRing
+	var got []*UploadSlot
+	for i := 0; i < 2*UploadRingSlots; i++ {
+		got = append(got, ring.Next())
+	}
+	for i := 0; i < UploadRingSlots; i++ {
+		if got[i] != &ring.Slots[i] {
+			t.Errorf("Call %d returned the wrong slot", i)
+		}
+		if got[i] != got[i+UploadRingSlots] {
+			t.Errorf("Slot %d not reused after a full turn", i)
+		}
+	}
+}
+
+// TestRingCapacity tests power-of-two rounding with a 4 KiB minimum
+func TestRingCapacity(t *testing.T) {
+	tests := []struct {
+		size, want int
+	}{
+		{0, 4096},
+		{16, 4096},
+		{4096, 4096},
+		{4097, 8192},
+		{65536 * 16, 65536 * 16},
+	}
+	for _, tt := range tests {
+		if got := RingCapacity(tt.size); got != tt.want {
+			t.Errorf("RingCapacity(%d) = %d, want %d", tt.size, got, tt.want)
+		}
+	}
+}
+
+// TestSupportsBufferStorage tests detection by core version and extension
+func TestSupportsBufferStorage(t *testing.T) {
+	tests := []struct {
+		name         string
+		major, minor int
+		extensions   []string
+		want         bool
+	}{
+		{"GL 4.3 without extension", 4, 3, []string{"GL_ARB_compute_shader"}, false},
+		{"GL 4.3 with extension", 4, 3, []string{"GL_ARB_compute_shader", BufferStorageExtension}, true},
+		{"GL 4.4 core", 4, 4, nil, true},
+		{"GL 4.6 core", 4, 6, nil, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := SupportsBufferStorage(tt.major, tt.minor, tt.extensions); got != tt.want {
+				t.Errorf("SupportsBufferStorage = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
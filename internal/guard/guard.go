@@ -0,0 +1,232 @@
+package guard
+
+import (
+	"encoding/json"
+	"fmt"
+	"math"
+	"os"
+	"path/filepath"
+	"relativity_simulation_2d/internal/physics"
+	"relativity_simulation_2d/internal/snapshot"
+	"time"
+)
+
+// DefaultEnergyGrowth is the kinetic energy, relative to the highest seen in
+// earlier checks, above which the system counts as exploding
+const DefaultEnergyGrowth = 100.0
+
+// DefaultHistory is the number of recent time steps kept for reports
+const DefaultHistory = 32
+
+// Violation reasons
+const (
+	ReasonNaN       = "nan"
+	ReasonInf       = "inf"
+	ReasonExplosion = "kinetic energy explosion"
+)
+
+// Options configures a Checker
+type Options struct {
+	Interval     int64   // Steps between checks (0 = every step)
+	EnergyGrowth float64 // Kinetic energy growth over the earlier peak that is an explosion (0 = DefaultEnergyGrowth)
+	History      int     // Recent time steps kept for reports (0 = DefaultHistory)
+}
+
+// Violation describes the first bad state found by a Checker
+type Violation struct {
+	Time            time.Time          `json:"time"`
+	Step            int64              `json:"step"`
+	SimTime         float64            `json:"sim_time"`
+	Reason          string             `json:"reason"`
+	Particle        int                `json:"particle"` // Index of the offending particle (-1 for a system-wide explosion)
+	Position        [3]float64         `json:"position"`
+	Velocity        [3]float64         `json:"velocity"`
+	Mass            float32            `json:"mass"`
+	KineticEnergy   float64            `json:"kinetic_energy"`      // Total kinetic energy at the check (NaN is reported as null)
+	PeakEnergy      float64            `json:"peak_kinetic_energy"` // Highest total kinetic energy of earlier checks
+	RecentTimeSteps []float64          `json:"recent_time_steps"`   // Oldest first
+	Snapshot        *snapshot.Snapshot `json:"-"`
+	SnapshotFile    string             `json:"snapshot_file,omitempty"`
+}
+
+// Error describes the violation in one line
+func (v *Violation) Error() string {
+	if v.Particle < 0 {
+		return fmt.Sprintf("%s at step %d: kinetic energy %.6g (peak %.6g)", v.Reason, v.Step, v.KineticEnergy, v.PeakEnergy)
+	}
+	return fmt.Sprintf("%s at step %d in particle %d (position %v, velocity %v)", v.Reason, v.Step, v.Particle, v.Position, v.Velocity)
+}
+
+// MarshalJSON encodes the violation, writing non-finite numbers as null
+func (v *Violation) MarshalJSON() ([]byte, error) {
+	type plain Violation
+	out := struct {
+		*plain
+		Position      [3]*float64 `json:"position"`
+		Velocity      [3]*float64 `json:"velocity"`
+		KineticEnergy *float64    `json:"kinetic_energy"`
+	}{plain: (*plain)(v), KineticEnergy: finite(v.KineticEnergy)}
+	for i := range out.Position {
+		out.Position[i] = finite(v.Position[i])
+		out.Velocity[i] = finite(v.Velocity[i])
+	}
+	return json.Marshal(out)
+}
+
+// finite returns a pointer to x, or nil if x is NaN or infinite
+func finite(x float64) *float64 {
+	if math.IsNaN(x) || math.IsInf(x, 0) {
+		return nil
+	}
+	return &x
+}
+
+// Checker scans particle state for NaN/Inf values and kinetic energy explosions
+type Checker struct {
+	opts     Options
+	peak     float64
+	checked  bool
+	steps    []float64 // Ring buffer of recent time steps
+	next     int
+	recorded int
+}
+
+// NewChecker creates a checker with the given options
+func NewChecker(opts Options) *Checker {
+	if opts.Interval <= 0 {
+		opts.Interval = 1
+	}
+	if opts.EnergyGrowth <= 0 {
+		opts.EnergyGrowth = DefaultEnergyGrowth
+	}
+	if opts.History <= 0 {
+		opts.History = DefaultHistory
+	}
+	return &Checker{opts: opts, steps: make([]float64, opts.History)}
+}
+
+// Record remembers the time step of a completed step
+func (c *Checker) Record(dt float32) {
+	c.steps[c.next] = float64(dt)
+	c.next = (c.next + 1) % len(c.steps)
+	if c.recorded < len(c.steps) {
+		c.recorded++
+	}
+}
+
+// RecentTimeSteps returns the recorded time steps, oldest first
+func (c *Checker) RecentTimeSteps() []float64 {
+	out := make([]float64, 0, c.recorded)
+	start := (c.next - c.recorded + len(c.steps)) % len(c.steps)
+	for i := 0; i < c.recorded; i++ {
+		out = append(out, c.steps[(start+i)%len(c.steps)])
+	}
+	return out
+}
+
+// Reset forgets the energy peak and time step history, e.g. after a respawn
+func (c *Checker) Reset() {
+	c.peak = 0
+	c.checked = false
+	c.next = 0
+	c.recorded = 0
+}
+
+// Check scans the particles if step is due and returns the first violation, or nil
+func (c *Checker) Check(step int64, simTime float64, particles []*physics.Particle) *Violation {
+	if step%c.opts.Interval != 0 {
+		return nil
+	}
+
+	kinetic := 0.0
+	for i, p := range particles {
+		if reason := nonFinite(p); reason != "" {
+			v := c.violation(step, simTime, reason, particleEnergy(particles))
+			v.Particle = i
+			v.Position = [3]float64{p.Position.X, p.Position.Y, p.Position.Z}
+			v.Velocity = [3]float64{p.Velocity.X, p.Velocity.Y, p.Velocity.Z}
+			v.Mass = p.Mass
+			return v
+		}
+		kinetic += 0.5 * float64(p.Mass) * p.Velocity.Dot(p.Velocity)
+	}
+
+	if c.checked && c.peak > 0 && kinetic > c.peak*c.opts.EnergyGrowth {
+		v := c.violation(step, simTime, ReasonExplosion, kinetic)
+		v.Particle = -1
+		return v
+	}
+	c.peak = math.Max(c.peak, kinetic)
+	c.checked = true
+	return nil
+}
+
+// violation creates a violation carrying the checker's history
+func (c *Checker) violation(step int64, simTime float64, reason string, kinetic float64) *Violation {
+	return &Violation{
+		Time:            time.Now().UTC(),
+		Step:            step,
+		SimTime:         simTime,
+		Reason:          reason,
+		KineticEnergy:   kinetic,
+		PeakEnergy:      c.peak,
+		RecentTimeSteps: c.RecentTimeSteps(),
+	}
+}
+
+// nonFinite returns ReasonNaN or ReasonInf if any component of p is not finite
+func nonFinite(p *physics.Particle) string {
+	values := [...]float64{
+		p.Position.X, p.Position.Y, p.Position.Z,
+		p.Velocity.X, p.Velocity.Y, p.Velocity.Z,
+		float64(p.Mass),
+	}
+	for _, x := range values {
+		if math.IsNaN(x) {
+			return ReasonNaN
+		}
+	}
+	for _, x := range values {
+		if math.IsInf(x, 0) {
+			return ReasonInf
+		}
+	}
+	return ""
+}
+
+// particleEnergy returns the total kinetic energy of the particles
+func particleEnergy(particles []*physics.Particle) float64 {
+	kinetic := 0.0
+	for _, p := range particles {
+		kinetic += 0.5 * float64(p.Mass) * p.Velocity.Dot(p.Velocity)
+	}
+	return kinetic
+}
+
+// WriteReport writes the violation (and snapshot, if any) to dir and returns the report path
+func WriteReport(dir string, v *Violation) (string, error) {
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return "", fmt.Errorf("failed to create report directory: %v", err)
+	}
+
+	base := fmt.Sprintf("guard_%s_step%d", v.Time.Format("20060102_150405"), v.Step)
+
+	if v.Snapshot != nil {
+		snapshotPath := filepath.Join(dir, base+"_state.rsim")
+		if err := snapshot.Save(snapshotPath, v.Snapshot); err != nil {
+			return "", err
+		}
+		v.SnapshotFile = filepath.Base(snapshotPath)
+	}
+
+	data, err := json.MarshalIndent(v, "", "  ")
+	if err != nil {
+		return "", fmt.Errorf("failed to encode guard report: %v", err)
+	}
+
+	reportPath := filepath.Join(dir, base+".json")
+	if err := os.WriteFile(reportPath, data, 0o644); err != nil {
+		return "", fmt.Errorf("failed to write guard report: %v", err)
+	}
+	return reportPath, nil
+}
@@ -0,0 +1,132 @@
+package guard
+
+import (
+	"encoding/json"
+	"math"
+	"os"
+	"path/filepath"
+	"relativity_simulation_2d/internal/config"
+	"relativity_simulation_2d/internal/physics"
+	"relativity_simulation_2d/internal/snapshot"
+	"testing"
+)
+
+// TestCheckNaN tests that a NaN velocity is reported with the offending particle
+func TestCheckNaN(t *testing.T) {
+	particles := []*physics.Particle{
+		physics.NewParticle(1, 0, 0, 0, 1, 0, 0),
+		physics.NewParticle(2, 1, 0, 1, math.NaN(), 0, 0),
+	}
+	c := NewChecker(Options{})
+	c.Record(0.01)
+	c.Record(0.02)
+
+	v := c.Check(5, 0.5, particles)
+	if v == nil {
+		t.Fatal("Expected a violation for a NaN velocity")
+	}
+	if v.Reason != ReasonNaN || v.Particle != 1 || v.Step != 5 || v.Mass != 2 {
+		t.Errorf("Unexpected violation: %+v", v)
+	}
+	if len(v.RecentTimeSteps) != 2 || v.RecentTimeSteps[1] != float64(float32(0.02)) {
+		t.Errorf("Expected the recorded time steps, got %v", v.RecentTimeSteps)
+	}
+}
+
+// TestCheckInf tests that an infinite position is reported
+func TestCheckInf(t *testing.T) {
+	particles := []*physics.Particle{physics.NewParticle(1, math.Inf(1), 0, 0, 0, 0, 0)}
+	v := NewChecker(Options{}).Check(0, 0, particles)
+	if v == nil || v.Reason != ReasonInf || v.Particle != 0 {
+		t.Errorf("Expected an Inf violation in particle 0, got %+v", v)
+	}
+}
+
+// TestCheckExplosion tests that kinetic energy growth past the earlier peak is reported
+func TestCheckExplosion(t *testing.T) {
+	p := physics.NewParticle(2, 0, 0, 0, 1, 0, 0)
+	particles := []*physics.Particle{p}
+	c := NewChecker(Options{EnergyGrowth: 10})
+
+	if v := c.Check(0, 0, particles); v != nil {
+		t.Fatalf("Unexpected violation on first check: %v", v)
+	}
+	p.Velocity.X = 3 // 9x the energy stays within the limit
+	if v := c.Check(1, 0, particles); v != nil {
+		t.Fatalf("Unexpected violation below the growth limit: %v", v)
+	}
+	p.Velocity.X = 100
+	v := c.Check(2, 0, particles)
+	if v == nil || v.Reason != ReasonExplosion || v.Particle != -1 {
+		t.Fatalf("Expected an explosion, got %+v", v)
+	}
+	if v.PeakEnergy != 9 {
+		t.Errorf("Expected peak energy 9, got %v", v.PeakEnergy)
+	}
+}
+
+// TestCheckInterval tests that checks only run on due steps
+func TestCheckInterval(t *testing.T) {
+	particles := []*physics.Particle{physics.NewParticle(1, math.NaN(), 0, 0, 0, 0, 0)}
+	c := NewChecker(Options{Interval: 10})
+	if v := c.Check(7, 0, particles); v != nil {
+		t.Errorf("Expected no check at step 7, got %v", v)
+	}
+	if v := c.Check(20, 0, particles); v == nil {
+		t.Error("Expected a check at step 20")
+	}
+}
+
+// TestRecentTimeSteps tests that the history keeps the newest steps, oldest first
+func TestRecentTimeSteps(t *testing.T) {
+	c := NewChecker(Options{History: 3})
+	for i := 1; i <= 5; i++ {
+		c.Record(float32(i))
+	}
+	got := c.RecentTimeSteps()
+	want := []float64{3, 4, 5}
+	if len(got) != len(want) {
+		t.Fatalf("Expected %v, got %v", want, got)
+	}
+	for i := range want {
+		if got[i] != want[i] {
+			t.Errorf("Expected %v, got %v", want, got)
+		}
+	}
+
+	c.Reset()
+	if len(c.RecentTimeSteps()) != 0 {
+		t.Error("Expected an empty history after Reset")
+	}
+}
+
+// TestWriteReport tests that the report and snapshot are written and NaN is encoded as null
+func TestWriteReport(t *testing.T) {
+	dir := t.TempDir()
+	particles := []*physics.Particle{physics.NewParticle(1, 0, 0, 0, math.NaN(), 0, 0)}
+	v := NewChecker(Options{}).Check(0, 0, particles)
+	v.Snapshot = snapshot.New(config.DefaultConfig(), particles, 0, 0)
+
+	path, err := WriteReport(dir, v)
+	if err != nil {
+		t.Fatalf("WriteReport failed: %v", err)
+	}
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatalf("Failed to read report: %v", err)
+	}
+
+	var decoded map[string]interface{}
+	if err := json.Unmarshal(data, &decoded); err != nil {
+		t.Fatalf("Report is not valid JSON: %v", err)
+	}
+	if decoded["reason"] != ReasonNaN {
+		t.Errorf("Expected reason %q, got %v", ReasonNaN, decoded["reason"])
+	}
+	if velocity := decoded["velocity"].([]interface{}); velocity[0] != nil {
+		t.Errorf("Expected NaN velocity as null, got %v", velocity[0])
+	}
+	if _, err := os.Stat(filepath.Join(dir, v.SnapshotFile)); err != nil {
+		t.Errorf("Expected snapshot next to the report: %v", err)
+	}
+}
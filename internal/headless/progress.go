@@ -0,0 +1,146 @@
+package headless
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"relativity_simulation_2d/internal/physics"
+	"time"
+)
+
+// Run states reported in progress files
+const (
+	StateRunning     = "running"
+	StateCompleted   = "completed"
+	StateInterrupted = "interrupted"
+	StateFailed      = "failed"
+)
+
+// Progress is a point-in-time summary of a headless run
+type Progress struct {
+	State       string              `json:"state"`
+	Step        int64               `json:"step"`
+	TotalSteps  int64               `json:"total_steps"` // 0 when the run is open-ended
+	Percent     float64             `json:"percent"`
+	StepsPerSec float64             `json:"steps_per_sec"`
+	ElapsedSec  float64             `json:"elapsed_sec"`
+	ETASec      float64             `json:"eta_sec"` // -1 when unknown
+	SimTime     float64             `json:"sim_time"`
+	UpdatedAt   time.Time           `json:"updated_at"`
+	Diagnostics physics.Diagnostics `json:"diagnostics"`
+}
+
+// ProgressReporter periodically prints run progress and mirrors it to a JSON file
+type ProgressReporter struct {
+	out      io.Writer // Human-readable progress lines (nil = none)
+	path     string    // JSON progress file ("" = none)
+	interval time.Duration
+	now      func() time.Time
+
+	startTime  time.Time
+	startStep  int64
+	lastReport time.Time
+}
+
+// NewProgressReporter creates a reporter that reports at most once per interval
+func NewProgressReporter(out io.Writer, path string, interval time.Duration) *ProgressReporter {
+	return &ProgressReporter{
+		out:      out,
+		path:     path,
+		interval: interval,
+		now:      time.Now,
+	}
+}
+
+// Start marks the beginning of the run at the given step
+func (p *ProgressReporter) Start(step int64) {
+	p.startTime = p.now()
+	p.startStep = step
+	p.lastReport = p.startTime
+}
+
+// Due reports whether the reporting interval has elapsed since the last report
+func (p *ProgressReporter) Due() bool {
+	return p.now().Sub(p.lastReport) >= p.interval
+}
+
+// Snapshot computes the current progress of the engine
+func (p *ProgressReporter) Snapshot(engine Engine, totalSteps int64, state string) Progress {
+	now := p.now()
+	step := engine.GetStepCount()
+	elapsed := now.Sub(p.startTime).Seconds()
+
+	progress := Progress{
+		State:       state,
+		Step:        step,
+		TotalSteps:  totalSteps,
+		ElapsedSec:  elapsed,
+		ETASec:      -1,
+		SimTime:     engine.GetSimTime(),
+		UpdatedAt:   now.UTC(),
+		Diagnostics: physics.ComputeDiagnostics(engine.GetParticles()),
+	}
+
+	if elapsed > 0 {
+		progress.StepsPerSec = float64(step-p.startStep) / elapsed
+	}
+	if totalSteps > 0 {
+		progress.Percent = 100 * float64(step) / float64(totalSteps)
+		if state != StateRunning {
+			progress.ETASec = 0
+		} else if progress.StepsPerSec > 0 {
+			progress.ETASec = float64(totalSteps-step) / progress.StepsPerSec
+		}
+	}
+
+	return progress
+}
+
+// Report prints the progress line and rewrites the progress file
+func (p *ProgressReporter) Report(progress Progress) error {
+	p.lastReport = p.now()
+
+	if p.out != nil {
+		fmt.Fprintln(p.out, FormatProgress(progress))
+	}
+	if p.path != "" {
+		return writeProgressFile(p.path, progress)
+	}
+	return nil
+}
+
+// FormatProgress formats progress as a single human-readable line
+func FormatProgress(progress Progress) string {
+	steps := fmt.Sprintf("Step %d", progress.Step)
+	if progress.TotalSteps > 0 {
+		steps = fmt.Sprintf("Step %d/%d (%.1f%%)", progress.Step, progress.TotalSteps, progress.Percent)
+	}
+
+	eta := "ETA --"
+	if progress.ETASec >= 0 {
+		eta = "ETA " + (time.Duration(progress.ETASec * float64(time.Second))).Round(time.Second).String()
+	}
+
+	return fmt.Sprintf("%s | %.1f steps/s | %s | t=%.3f | KE=%.6g | mass=%.6g",
+		steps, progress.StepsPerSec, eta, progress.SimTime,
+		progress.Diagnostics.KineticEnergy, progress.Diagnostics.TotalMass)
+}
+
+// writeProgressFile atomically replaces the JSON progress file
+func writeProgressFile(path string, progress Progress) error {
+	data, err := json.MarshalIndent(progress, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to encode progress: %v", err)
+	}
+
+	tmpPath := path + ".tmp"
+	if err := os.WriteFile(tmpPath, data, 0o644); err != nil {
+		return fmt.Errorf("failed to write progress file: %v", err)
+	}
+	if err := os.Rename(tmpPath, path); err != nil {
+		_ = os.Remove(tmpPath)
+		return fmt.Errorf("failed to finalize progress file: %v", err)
+	}
+	return nil
+}
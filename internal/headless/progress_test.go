@@ -0,0 +1,145 @@
+package headless
+
+import (
+	"bytes"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"relativity_simulation_2d/internal/physics"
+	"strings"
+	"testing"
+	"time"
+)
+
+// fakeClock is a manually advanced clock for deterministic progress tests
+type fakeClock struct {
+	t time.Time
+}
+
+func (c *fakeClock) now() time.Time { return c.t }
+
+// TestProgressSnapshot tests steps/sec, percent and ETA calculation
+func TestProgressSnapshot(t *testing.T) {
+	clock := &fakeClock{t: time.Unix(1000, 0)}
+	reporter := NewProgressReporter(nil, "", time.Second)
+	reporter.now = clock.now
+
+	engine := &fakeEngine{particles: []*physics.Particle{physics.NewParticle(2.0, 0, 0, 0, 1.0, 0, 0)}}
+	reporter.Start(engine.GetStepCount())
+
+	for i := 0; i < 100; i++ {
+		engine.Step(0.01)
+	}
+	clock.t = clock.t.Add(2 * time.Second)
+
+	progress := reporter.Snapshot(engine, 400, StateRunning)
+
+	if progress.Step != 100 || progress.TotalSteps != 400 {
+		t.Errorf("Expected step 100/400, got %d/%d", progress.Step, progress.TotalSteps)
+	}
+	if progress.Percent != 25 {
+		t.Errorf("Expected 25%%, got %f", progress.Percent)
+	}
+	if progress.StepsPerSec != 50 {
+		t.Errorf("Expected 50 steps/s, got %f", progress.StepsPerSec)
+	}
+	if progress.ETASec != 6 {
+		t.Errorf("Expected ETA 6s, got %f", progress.ETASec)
+	}
+	if progress.Diagnostics.TotalMass != 2.0 {
+		t.Errorf("Expected diagnostics to be included, got %+v", progress.Diagnostics)
+	}
+
+	// Open-ended runs have no ETA
+	progress = reporter.Snapshot(engine, 0, StateRunning)
+	if progress.ETASec != -1 || progress.Percent != 0 {
+		t.Errorf("Expected unknown ETA for open-ended run, got %+v", progress)
+	}
+}
+
+// TestProgressDue tests interval-based reporting
+func TestProgressDue(t *testing.T) {
+	clock := &fakeClock{t: time.Unix(1000, 0)}
+	reporter := NewProgressReporter(nil, "", 5*time.Second)
+	reporter.now = clock.now
+	reporter.Start(0)
+
+	if reporter.Due() {
+		t.Error("Report should not be due immediately after start")
+	}
+	clock.t = clock.t.Add(5 * time.Second)
+	if !reporter.Due() {
+		t.Error("Report should be due after the interval")
+	}
+	if err := reporter.Report(Progress{}); err != nil {
+		t.Fatal(err)
+	}
+	if reporter.Due() {
+		t.Error("Report should not be due right after reporting")
+	}
+}
+
+// TestProgressReportOutputs tests the console line and JSON progress file
+func TestProgressReportOutputs(t *testing.T) {
+	var out bytes.Buffer
+	path := filepath.Join(t.TempDir(), "progress.json")
+	reporter := NewProgressReporter(&out, path, time.Second)
+
+	progress := Progress{
+		State:       StateRunning,
+		Step:        50,
+		TotalSteps:  200,
+		Percent:     25,
+		StepsPerSec: 10,
+		ETASec:      15,
+	}
+	if err := reporter.Report(progress); err != nil {
+		t.Fatalf("Report failed: %v", err)
+	}
+
+	line := out.String()
+	for _, want := range []string{"Step 50/200 (25.0%)", "10.0 steps/s", "ETA 15s"} {
+		if !strings.Contains(line, want) {
+			t.Errorf("Progress line %q missing %q", line, want)
+		}
+	}
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatalf("Progress file missing: %v", err)
+	}
+	var decoded Progress
+	if err := json.Unmarshal(data, &decoded); err != nil {
+		t.Fatalf("Progress file is not valid JSON: %v", err)
+	}
+	if decoded.Step != 50 || decoded.State != StateRunning {
+		t.Errorf("Unexpected progress file contents: %+v", decoded)
+	}
+}
+
+// TestRunnerWritesFinalProgress tests that the runner reports the final state
+func TestRunnerWritesFinalProgress(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "progress.json")
+	engine := &fakeEngine{}
+	runner := NewRunner(engine, Options{
+		Steps:    20,
+		TimeStep: 0.1,
+		Progress: NewProgressReporter(nil, path, time.Hour),
+	})
+
+	if _, err := runner.Run(); err != nil {
+		t.Fatalf("Run failed: %v", err)
+	}
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatalf("Progress file missing: %v", err)
+	}
+	var progress Progress
+	if err := json.Unmarshal(data, &progress); err != nil {
+		t.Fatal(err)
+	}
+	if progress.State != StateCompleted || progress.Step != 20 || progress.ETASec != 0 {
+		t.Errorf("Expected completed progress at step 20, got %+v", progress)
+	}
+}
@@ -0,0 +1,437 @@
+package headless
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"os/signal"
+	"relativity_simulation_2d/internal/config"
+	"relativity_simulation_2d/internal/export"
+	"relativity_simulation_2d/internal/guard"
+	"relativity_simulation_2d/internal/health"
+	"relativity_simulation_2d/internal/physics"
+	"relativity_simulation_2d/internal/provenance"
+	"relativity_simulation_2d/internal/snapshot"
+	"relativity_simulation_2d/internal/soak"
+	"syscall"
+	"time"
+)
+
+// Engine is the simulation driven by the headless runner
+type Engine interface {
+	Step(dt float32)
+	GetParticles() []*physics.Particle
+	GetStepCount() int64
+	GetSimTime() float64
+}
+
+// gridSource is implemented by engines whose potential grid is saved in checkpoints
+type gridSource interface {
+	GetPotentialGrid() physics.Grid
+}
+
+// potentialSource is implemented by engines that lend out their current
+// potential grid without copying it, for health checks
+type potentialSource interface {
+	CurrentPotential() physics.Grid
+}
+
+// Options configures a headless run
+type Options struct {
+	Steps               int64   // Number of steps to run (0 = until interrupted)
+	TimeStep            float32 // Fixed time step per simulation step
+	DiagnosticsInterval int64   // Steps between exported diagnostics records
+	CheckpointPath      string  // Final checkpoint written on shutdown ("" = none)
+	NBodyPath           string  // Final particles in an external N-body format ("" = none)
+	NBodyFormat         string  // export.NBodyFormatGadget or export.NBodyFormatRaw
+	FlowDir             string  // Directory for velocity flow maps ("" = none)
+	FlowInterval        int64   // Steps between flow maps
+	ProfilePath         string  // Radial profile of the final particles as CSV ("" = none)
+	RecordDir           string  // Directory for replay frames, re-rendered offline ("" = none)
+	RecordInterval      int64   // Steps between replay frames
+	Profile             physics.ProfileOptions
+	Config              *config.Config
+	Exporters           []export.Exporter
+	Progress            *ProgressReporter // Periodic progress output (nil = none)
+	Cleanup             func() error      // Releases engine resources (e.g. GPU) on shutdown
+	Guard               *guard.Checker    // Stops the run on NaN/Inf or exploding energy (nil = none)
+	GuardReportDir      string            // Directory for guard reports
+	Health              *health.Monitor   // Scores the run and logs advice when it changes (nil = none)
+	HealthLog           io.Writer         // Receives the health reports (nil = os.Stderr)
+	Soak                *soak.Monitor     // Samples memory and energy drift, stopping the run with a report on upward trends (nil = none)
+
+	// Provenance describes the code and parameters of the run, embedded in
+	// snapshots and written beside other outputs (nil = not recorded)
+	Provenance func() provenance.Metadata
+}
+
+// Result summarizes a completed headless run
+type Result struct {
+	Steps       int64
+	SimTime     float64
+	Interrupted bool
+	Signal      os.Signal // Signal that stopped the run (nil if it completed)
+	Cause       error     // Context error that stopped the run, e.g. context.DeadlineExceeded (nil if it was not cancelled)
+	Diagnostics physics.Diagnostics
+}
+
+// Runner runs a simulation without a window and shuts down gracefully on SIGINT/SIGTERM
+type Runner struct {
+	engine       Engine
+	opts         Options
+	signals      chan os.Signal
+	lastExported int64
+	progressErr  error // First progress reporting failure; reported but not fatal
+	flowStamped  bool  // The flow directory's metadata is written
+}
+
+// NewRunner creates a headless runner for the given engine
+func NewRunner(engine Engine, opts Options) *Runner {
+	if opts.DiagnosticsInterval <= 0 {
+		opts.DiagnosticsInterval = 1
+	}
+	if opts.FlowInterval <= 0 {
+		opts.FlowInterval = 1
+	}
+	if opts.RecordInterval <= 0 {
+		opts.RecordInterval = 1
+	}
+	return &Runner{
+		engine:       engine,
+		opts:         opts,
+		signals:      make(chan os.Signal, 1),
+		lastExported: -1,
+	}
+}
+
+// Interrupt requests a graceful shutdown as if sig had been received
+func (r *Runner) Interrupt(sig os.Signal) {
+	select {
+	case r.signals <- sig:
+	default:
+		// A shutdown is already pending
+	}
+}
+
+// Run steps the simulation until the step budget is exhausted or a signal arrives.
+// The current step always completes before shutdown begins.
+func (r *Runner) Run() (*Result, error) {
+	return r.RunContext(context.Background())
+}
+
+// RunContext is Run with a context. Cancelling ctx stops the run like a
+// signal: the current step completes, then the final diagnostics, checkpoint
+// and N-body snapshot are written. A signal received during that shutdown
+// abandons the remaining writes. Cancellation is reported in Result.Cause,
+// not as an error
+func (r *Runner) RunContext(ctx context.Context) (*Result, error) {
+	signal.Notify(r.signals, os.Interrupt, syscall.SIGTERM)
+	defer signal.Stop(r.signals)
+
+	result := &Result{}
+	var runErr error
+
+	if err := r.export(); err != nil {
+		runErr = err
+	} else if err := r.exportFlow(); err != nil {
+		runErr = err
+	} else if err := r.record(); err != nil {
+		runErr = err
+	}
+	if r.opts.Progress != nil {
+		r.opts.Progress.Start(r.engine.GetStepCount())
+	}
+
+loop:
+	for runErr == nil && (r.opts.Steps <= 0 || r.engine.GetStepCount() < r.opts.Steps) {
+		select {
+		case sig := <-r.signals:
+			result.Interrupted = true
+			result.Signal = sig
+			break loop
+		case <-ctx.Done():
+			result.Interrupted = true
+			result.Cause = context.Cause(ctx)
+			break loop
+		default:
+		}
+
+		r.engine.Step(r.opts.TimeStep)
+
+		if r.opts.Guard != nil {
+			runErr = r.checkGuard()
+		}
+		if runErr == nil && r.opts.Soak != nil {
+			runErr = r.checkSoak()
+		}
+		if runErr == nil && r.opts.Health != nil {
+			r.checkHealth()
+		}
+		if runErr == nil && r.engine.GetStepCount()%r.opts.DiagnosticsInterval == 0 {
+			runErr = r.export()
+		}
+		if runErr == nil && r.engine.GetStepCount()%r.opts.FlowInterval == 0 {
+			runErr = r.exportFlow()
+		}
+		if runErr == nil && r.engine.GetStepCount()%r.opts.RecordInterval == 0 {
+			runErr = r.record()
+		}
+		if r.opts.Progress != nil && r.opts.Progress.Due() {
+			r.reportProgress(StateRunning)
+		}
+	}
+
+	state := StateCompleted
+	if runErr != nil {
+		state = StateFailed
+	} else if result.Interrupted {
+		state = StateInterrupted
+	}
+	shutdownCtx, abandon := context.WithCancel(context.WithoutCancel(ctx))
+	done := make(chan struct{})
+	go func() {
+		select {
+		case <-r.signals:
+			abandon()
+		case <-done:
+		}
+	}()
+	shutdownErr := r.shutdown(shutdownCtx, state)
+	close(done)
+	abandon()
+
+	result.Steps = r.engine.GetStepCount()
+	result.SimTime = r.engine.GetSimTime()
+	result.Diagnostics = physics.ComputeDiagnostics(r.engine.GetParticles())
+
+	return result, errors.Join(runErr, shutdownErr, r.progressErr)
+}
+
+// reportProgress reports the current progress, remembering the first failure
+func (r *Runner) reportProgress(state string) {
+	progress := r.opts.Progress.Snapshot(r.engine, r.opts.Steps, state)
+	if err := r.opts.Progress.Report(progress); err != nil && r.progressErr == nil {
+		r.progressErr = err
+	}
+}
+
+// checkGuard runs the sanity checker and writes a report when it finds a violation
+func (r *Runner) checkGuard() error {
+	r.opts.Guard.Record(r.opts.TimeStep)
+	v := r.opts.Guard.Check(r.engine.GetStepCount(), r.engine.GetSimTime(), r.engine.GetParticles())
+	if v == nil {
+		return nil
+	}
+
+	v.Snapshot = r.snapshot()
+	path, err := guard.WriteReport(r.opts.GuardReportDir, v)
+	if err != nil {
+		return fmt.Errorf("sanity check failed: %v (report not written: %v)", v, err)
+	}
+	return fmt.Errorf("sanity check failed: %v (report: %s)", v, path)
+}
+
+// checkSoak takes a soak sample when one is due and writes a report when a
+// metric trends upward beyond its threshold
+func (r *Runner) checkSoak() error {
+	now := time.Now()
+	if !r.opts.Soak.Due(now) {
+		return nil
+	}
+	particles := r.engine.GetParticles()
+	kinetic := physics.ComputeDiagnostics(particles).KineticEnergy
+	var potential float64
+	grid := r.potential()
+	hasPotential := grid.Width() > 0 && grid.Height() > 0
+	if hasPotential {
+		potential = physics.PotentialEnergy(particles, grid, r.cellSize())
+	}
+	sample := r.opts.Soak.Measure(now, r.engine.GetStepCount(), r.engine.GetSimTime(), kinetic, potential, hasPotential)
+	if err := r.opts.Soak.Record(sample); err != nil {
+		return fmt.Errorf("failed to write soak sample: %v", err)
+	}
+
+	v := r.opts.Soak.Check(now)
+	if v == nil {
+		return nil
+	}
+	if r.opts.Provenance != nil {
+		meta := r.opts.Provenance()
+		v.Metadata = &meta
+	}
+	path, err := soak.WriteReport(r.opts.GuardReportDir, v)
+	if err != nil {
+		return fmt.Errorf("soak watchdog: %v (report not written: %v)", v, err)
+	}
+	return fmt.Errorf("soak watchdog: %v (report: %s)", v, path)
+}
+
+// potential returns the engine's current potential grid, or nil if it has none
+func (r *Runner) potential() physics.Grid {
+	switch g := r.engine.(type) {
+	case potentialSource:
+		return g.CurrentPotential()
+	case gridSource:
+		return g.GetPotentialGrid()
+	}
+	return nil
+}
+
+// cellSize returns the physical size of a grid cell of the run (1 without a config)
+func (r *Runner) cellSize() float64 {
+	if r.opts.Config == nil {
+		return 1
+	}
+	return r.opts.Config.Spacing()
+}
+
+// checkHealth runs the health monitor and logs the report when its advice changes
+func (r *Runner) checkHealth() {
+	report := r.opts.Health.Check(r.engine.GetStepCount(), r.engine.GetSimTime(), r.opts.TimeStep, r.engine.GetParticles(), r.potential())
+	if report == nil || !report.Changed {
+		return
+	}
+	out := r.opts.HealthLog
+	if out == nil {
+		out = os.Stderr
+	}
+	fmt.Fprintf(out, "Step %d: %v\n", report.Step, report)
+}
+
+// export sends the current diagnostics to all exporters
+func (r *Runner) export() error {
+	step := r.engine.GetStepCount()
+	if step == r.lastExported {
+		return nil
+	}
+	r.lastExported = step
+
+	record := export.Record{
+		Step:        step,
+		SimTime:     r.engine.GetSimTime(),
+		Diagnostics: physics.ComputeDiagnostics(r.engine.GetParticles()),
+	}
+	for _, e := range r.opts.Exporters {
+		if err := e.Export(record); err != nil {
+			return fmt.Errorf("failed to export diagnostics: %v", err)
+		}
+	}
+	return nil
+}
+
+// exportFlow writes the flow map of the current step if flow maps are enabled
+func (r *Runner) exportFlow() error {
+	if r.opts.FlowDir == "" || r.opts.Config == nil {
+		return nil
+	}
+	if !r.flowStamped {
+		if err := r.stampDir(r.opts.FlowDir); err != nil {
+			return err
+		}
+		r.flowStamped = true
+	}
+	flow := physics.ComputeFlowField(r.engine.GetParticles(), r.opts.Config.SimulationWidth, r.opts.Config.SimulationDepth, r.opts.Config.Spacing())
+	return export.SaveFlowField(r.opts.FlowDir, r.engine.GetStepCount(), flow)
+}
+
+// record writes the particles of the current step as a replay frame if
+// recording is enabled. Frames leave out the grids, which the renderer
+// solves again from the particles
+func (r *Runner) record() error {
+	if r.opts.RecordDir == "" {
+		return nil
+	}
+	return snapshot.SaveFrame(r.opts.RecordDir, r.snapshot())
+}
+
+// snapshot returns a snapshot of the current step with the run's metadata
+func (r *Runner) snapshot() *snapshot.Snapshot {
+	snap := snapshot.New(r.opts.Config, r.engine.GetParticles(), r.engine.GetStepCount(), r.engine.GetSimTime())
+	if r.opts.Provenance != nil {
+		meta := r.opts.Provenance()
+		snap.Metadata = &meta
+	}
+	return snap
+}
+
+// stamp writes the run's metadata beside the output at path, if recorded
+func (r *Runner) stamp(path string) error {
+	if r.opts.Provenance == nil {
+		return nil
+	}
+	return provenance.WriteSidecar(path, r.opts.Provenance())
+}
+
+// stampDir writes the run's metadata into the output directory dir, if recorded
+func (r *Runner) stampDir(dir string) error {
+	if r.opts.Provenance == nil {
+		return nil
+	}
+	return provenance.WriteDir(dir, r.opts.Provenance())
+}
+
+// shutdown records final diagnostics, flushes exporters, writes the checkpoint and releases resources.
+// Once ctx is cancelled the remaining output is skipped, but resources are still released
+func (r *Runner) shutdown(ctx context.Context, state string) error {
+	var errs []error
+
+	if err := r.export(); err != nil {
+		errs = append(errs, err)
+	}
+
+	for _, e := range r.opts.Exporters {
+		if err := e.Close(); err != nil {
+			errs = append(errs, fmt.Errorf("failed to close exporter: %v", err))
+		}
+	}
+
+	if r.opts.CheckpointPath != "" && ctx.Err() == nil {
+		snap := r.snapshot()
+		if g, ok := r.engine.(gridSource); ok {
+			snap.AddGrid(snapshot.GridPotential, g.GetPotentialGrid())
+		}
+		if err := snapshot.Save(r.opts.CheckpointPath, snap); err != nil {
+			errs = append(errs, fmt.Errorf("failed to write final checkpoint: %v", err))
+		}
+	}
+
+	if r.opts.NBodyPath != "" && ctx.Err() == nil {
+		meta := export.NBodyMeta{SimTime: r.engine.GetSimTime()}
+		if r.opts.Config != nil {
+			meta.Width = float64(r.opts.Config.SimulationWidth)
+			meta.Depth = float64(r.opts.Config.SimulationDepth)
+		}
+		if err := export.SaveNBodyContext(ctx, r.opts.NBodyPath, r.opts.NBodyFormat, r.engine.GetParticles(), meta); err != nil {
+			errs = append(errs, err)
+		} else if err := r.stamp(r.opts.NBodyPath); err != nil {
+			errs = append(errs, err)
+		}
+	}
+
+	if r.opts.ProfilePath != "" && ctx.Err() == nil {
+		profile := physics.ComputeRadialProfile(r.engine.GetParticles(), r.opts.Profile)
+		if err := export.SaveProfile(r.opts.ProfilePath, profile); err != nil {
+			errs = append(errs, err)
+		} else if err := r.stamp(r.opts.ProfilePath); err != nil {
+			errs = append(errs, err)
+		}
+	}
+
+	if err := ctx.Err(); err != nil {
+		errs = append(errs, fmt.Errorf("shutdown abandoned, final output may be incomplete: %w", err))
+	}
+
+	if r.opts.Progress != nil {
+		r.reportProgress(state)
+	}
+
+	if r.opts.Cleanup != nil {
+		if err := r.opts.Cleanup(); err != nil {
+			errs = append(errs, fmt.Errorf("failed to release resources: %v", err))
+		}
+	}
+
+	return errors.Join(errs...)
+}
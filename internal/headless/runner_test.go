@@ -0,0 +1,448 @@
+package headless
+
+import (
+	"context"
+	"errors"
+	"math"
+	"os"
+	"path/filepath"
+	"relativity_simulation_2d/internal/config"
+	"relativity_simulation_2d/internal/export"
+	"relativity_simulation_2d/internal/guard"
+	"relativity_simulation_2d/internal/health"
+	"relativity_simulation_2d/internal/physics"
+	"relativity_simulation_2d/internal/provenance"
+	"relativity_simulation_2d/internal/simulation"
+	"relativity_simulation_2d/internal/snapshot"
+	"relativity_simulation_2d/internal/soak"
+	"strings"
+	"syscall"
+	"testing"
+	"time"
+)
+
+// fakeEngine is a minimal Engine that drifts particles without forces
+type fakeEngine struct {
+	particles []*physics.Particle
+	steps     int64
+	simTime   float64
+	onStep    func(step int64)
+}
+
+func (e *fakeEngine) Step(dt float32) {
+	physics.UpdatePositions(e.particles, dt, 64, 64, 1)
+	e.steps++
+	e.simTime += float64(dt)
+	if e.onStep != nil {
+		e.onStep(e.steps)
+	}
+}
+
+func (e *fakeEngine) GetParticles() []*physics.Particle { return e.particles }
+func (e *fakeEngine) GetStepCount() int64               { return e.steps }
+func (e *fakeEngine) GetSimTime() float64               { return e.simTime }
+
+// recordingExporter records exported steps and lifecycle calls
+type recordingExporter struct {
+	steps  []int64
+	closed bool
+}
+
+func (e *recordingExporter) Export(record export.Record) error {
+	e.steps = append(e.steps, record.Step)
+	return nil
+}
+func (e *recordingExporter) Flush() error { return nil }
+func (e *recordingExporter) Close() error {
+	e.closed = true
+	return nil
+}
+
+// TestRunnerCompletesSteps tests a fixed-step run with exporters, checkpoint, N-body and profile output
+func TestRunnerCompletesSteps(t *testing.T) {
+	engine := &fakeEngine{particles: []*physics.Particle{physics.NewParticle(1.0, 0, 0, 0, 1.0, 0, 0)}}
+	exporter := &recordingExporter{}
+	checkpoint := filepath.Join(t.TempDir(), "final.json")
+	nbody := filepath.Join(t.TempDir(), "final.gadget")
+	profile := filepath.Join(t.TempDir(), "profile.csv")
+	cleanedUp := false
+
+	runner := NewRunner(engine, Options{
+		Steps:               10,
+		TimeStep:            0.1,
+		DiagnosticsInterval: 4,
+		CheckpointPath:      checkpoint,
+		NBodyPath:           nbody,
+		NBodyFormat:         export.NBodyFormatGadget,
+		ProfilePath:         profile,
+		Profile:             physics.ProfileOptions{Bins: 4},
+		Exporters:           []export.Exporter{exporter},
+		Cleanup: func() error {
+			cleanedUp = true
+			return nil
+		},
+	})
+
+	result, err := runner.Run()
+	if err != nil {
+		t.Fatalf("Run failed: %v", err)
+	}
+
+	if result.Steps != 10 || result.Interrupted {
+		t.Errorf("Expected 10 uninterrupted steps, got %d (interrupted=%v)", result.Steps, result.Interrupted)
+	}
+
+	// Initial state, every 4th step, and the final step
+	expected := []int64{0, 4, 8, 10}
+	if len(exporter.steps) != len(expected) {
+		t.Fatalf("Expected exports at %v, got %v", expected, exporter.steps)
+	}
+	for i := range expected {
+		if exporter.steps[i] != expected[i] {
+			t.Errorf("Expected exports at %v, got %v", expected, exporter.steps)
+			break
+		}
+	}
+
+	if !exporter.closed {
+		t.Error("Exporter should be closed on shutdown")
+	}
+	if !cleanedUp {
+		t.Error("Cleanup should be called on shutdown")
+	}
+
+	snap, err := snapshot.Load(checkpoint)
+	if err != nil {
+		t.Fatalf("Final checkpoint missing: %v", err)
+	}
+	if snap.Step != 10 {
+		t.Errorf("Expected checkpoint at step 10, got %d", snap.Step)
+	}
+
+	if info, err := os.Stat(nbody); err != nil || info.Size() == 0 {
+		t.Errorf("Final N-body snapshot missing: %v", err)
+	}
+	if data, err := os.ReadFile(profile); err != nil || strings.Count(string(data), "\n") != 1+4 {
+		t.Errorf("Expected a radial profile with 4 bins, got %q (%v)", data, err)
+	}
+}
+
+// TestRunnerFlowMaps tests that flow maps are written at the start and every interval
+func TestRunnerFlowMaps(t *testing.T) {
+	engine := &fakeEngine{particles: []*physics.Particle{physics.NewParticle(1.0, 0, 0, 0, 1.0, 0, 0)}}
+	cfg := config.DefaultConfig()
+	cfg.SimulationWidth, cfg.SimulationDepth = 16, 16
+	dir := filepath.Join(t.TempDir(), "flow")
+
+	runner := NewRunner(engine, Options{
+		Steps:        5,
+		TimeStep:     0.1,
+		FlowDir:      dir,
+		FlowInterval: 2,
+		Config:       cfg,
+	})
+	if _, err := runner.Run(); err != nil {
+		t.Fatalf("Run failed: %v", err)
+	}
+
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		t.Fatalf("Expected the flow directory to be created: %v", err)
+	}
+	var names []string
+	for _, e := range entries {
+		names = append(names, e.Name())
+	}
+	want := []string{export.FlowFileName(0), export.FlowFileName(2), export.FlowFileName(4)}
+	if len(names) != len(want) || names[0] != want[0] || names[1] != want[1] || names[2] != want[2] {
+		t.Errorf("Expected flow maps %v, got %v", want, names)
+	}
+}
+
+// TestRunnerRecord tests that replay frames are written at the start and every interval
+func TestRunnerRecord(t *testing.T) {
+	engine := &fakeEngine{particles: []*physics.Particle{physics.NewParticle(1.0, 0, 0, 0, 1.0, 0, 0)}}
+	dir := filepath.Join(t.TempDir(), "replay")
+
+	runner := NewRunner(engine, Options{
+		Steps:          6,
+		TimeStep:       0.1,
+		RecordDir:      dir,
+		RecordInterval: 3,
+		Config:         config.DefaultConfig(),
+	})
+	if _, err := runner.Run(); err != nil {
+		t.Fatalf("Run failed: %v", err)
+	}
+
+	paths, err := snapshot.ListFrames(dir)
+	if err != nil || len(paths) != 3 {
+		t.Fatalf("Expected 3 replay frames, got %v (%v)", paths, err)
+	}
+	last, err := snapshot.Load(paths[2])
+	if err != nil || last.Step != 6 || last.Particles[0].Position[0] != engine.particles[0].Position.X {
+		t.Errorf("Expected the last frame to hold step 6, got %+v (%v)", last, err)
+	}
+}
+
+// TestRunnerProvenance tests that snapshots embed the run's metadata and the
+// other outputs get it beside them
+func TestRunnerProvenance(t *testing.T) {
+	engine := &fakeEngine{particles: []*physics.Particle{physics.NewParticle(1.0, 0, 0, 0, 1.0, 0, 0)}}
+	cfg := config.DefaultConfig()
+	cfg.SimulationWidth, cfg.SimulationDepth = 16, 16
+	dir := t.TempDir()
+	meta := provenance.New(cfg, provenance.BackendCPU)
+	opts := Options{
+		Steps:          2,
+		TimeStep:       0.1,
+		CheckpointPath: filepath.Join(dir, "final.rsim"),
+		NBodyPath:      filepath.Join(dir, "final.raw"),
+		NBodyFormat:    export.NBodyFormatRaw,
+		FlowDir:        filepath.Join(dir, "flow"),
+		ProfilePath:    filepath.Join(dir, "profile.csv"),
+		RecordDir:      filepath.Join(dir, "replay"),
+		Config:         cfg,
+		Provenance:     func() provenance.Metadata { return meta },
+	}
+	if _, err := NewRunner(engine, opts).Run(); err != nil {
+		t.Fatalf("Run failed: %v", err)
+	}
+
+	frames, err := snapshot.ListFrames(opts.RecordDir)
+	if err != nil || len(frames) == 0 {
+		t.Fatalf("Expected replay frames, got %v (%v)", frames, err)
+	}
+	for _, path := range []string{opts.CheckpointPath, frames[0]} {
+		snap, err := snapshot.Load(path)
+		if err != nil || snap.Metadata == nil || *snap.Metadata != meta {
+			t.Errorf("Expected %s to embed %+v, got %+v (%v)", path, meta, snap, err)
+		}
+	}
+	for _, path := range []string{provenance.SidecarPath(opts.NBodyPath), provenance.SidecarPath(opts.ProfilePath), filepath.Join(opts.FlowDir, provenance.DirFile)} {
+		if got, err := provenance.Read(path); err != nil || got != meta {
+			t.Errorf("Expected %s to hold %+v, got %+v (%v)", path, meta, got, err)
+		}
+	}
+}
+
+// TestRunnerGracefulInterrupt tests that a signal finishes the current step and shuts down cleanly
+func TestRunnerGracefulInterrupt(t *testing.T) {
+	engine := &fakeEngine{particles: []*physics.Particle{physics.NewParticle(1.0, 0, 0, 0, 0, 0, 0)}}
+	exporter := &recordingExporter{}
+	checkpoint := filepath.Join(t.TempDir(), "final.json")
+
+	var runner *Runner
+	engine.onStep = func(step int64) {
+		if step == 3 {
+			runner.Interrupt(syscall.SIGTERM)
+		}
+	}
+	runner = NewRunner(engine, Options{
+		TimeStep:       0.1,
+		CheckpointPath: checkpoint,
+		Exporters:      []export.Exporter{exporter},
+	})
+
+	result, err := runner.Run()
+	if err != nil {
+		t.Fatalf("Run failed: %v", err)
+	}
+
+	if !result.Interrupted || result.Signal != syscall.SIGTERM {
+		t.Errorf("Expected run to be interrupted by SIGTERM, got %+v", result)
+	}
+	if result.Steps != 3 {
+		t.Errorf("Expected the interrupted step to complete (3 steps), got %d", result.Steps)
+	}
+	if !exporter.closed {
+		t.Error("Exporter should be closed after interrupt")
+	}
+	if _, err := os.Stat(checkpoint); err != nil {
+		t.Errorf("Final checkpoint should be written after interrupt: %v", err)
+	}
+}
+
+// TestRunnerContextCancel tests that cancelling the context shuts down like a signal
+func TestRunnerContextCancel(t *testing.T) {
+	engine := &fakeEngine{particles: []*physics.Particle{physics.NewParticle(1.0, 0, 0, 0, 1.0, 0, 0)}}
+	exporter := &recordingExporter{}
+	checkpoint := filepath.Join(t.TempDir(), "final.json")
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	engine.onStep = func(step int64) {
+		if step == 3 {
+			cancel()
+		}
+	}
+	runner := NewRunner(engine, Options{
+		TimeStep:       0.1,
+		CheckpointPath: checkpoint,
+		Exporters:      []export.Exporter{exporter},
+	})
+
+	result, err := runner.RunContext(ctx)
+	if err != nil {
+		t.Fatalf("Run failed: %v", err)
+	}
+	if !result.Interrupted || result.Signal != nil || !errors.Is(result.Cause, context.Canceled) {
+		t.Errorf("Expected run to be cancelled by the context, got %+v", result)
+	}
+	if result.Steps != 3 {
+		t.Errorf("Expected the cancelled step to complete (3 steps), got %d", result.Steps)
+	}
+	if !exporter.closed {
+		t.Error("Exporter should be closed after cancellation")
+	}
+	if _, err := os.Stat(checkpoint); err != nil {
+		t.Errorf("Final checkpoint should be written after cancellation: %v", err)
+	}
+}
+
+// TestRunnerTimeout tests that a deadline stops an unbounded run
+func TestRunnerTimeout(t *testing.T) {
+	engine := &fakeEngine{}
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+	defer cancel()
+
+	result, err := NewRunner(engine, Options{TimeStep: 0.1}).RunContext(ctx)
+	if err != nil {
+		t.Fatalf("Run failed: %v", err)
+	}
+	if !errors.Is(result.Cause, context.DeadlineExceeded) || result.Steps == 0 {
+		t.Errorf("Expected a timed-out run with some steps, got %+v", result)
+	}
+}
+
+// TestRunnerShutdownErrors tests that cleanup failures are reported
+func TestRunnerShutdownErrors(t *testing.T) {
+	engine := &fakeEngine{}
+	runner := NewRunner(engine, Options{
+		Steps:          1,
+		TimeStep:       0.1,
+		CheckpointPath: filepath.Join(t.TempDir(), "missing", "final.json"),
+		Cleanup:        func() error { return errors.New("cleanup failed") },
+	})
+
+	_, err := runner.Run()
+	if err == nil {
+		t.Fatal("Expected shutdown errors to be reported")
+	}
+}
+
+// TestRunnerGuardStops tests that a NaN stops the run with a report and a final checkpoint
+func TestRunnerGuardStops(t *testing.T) {
+	dir := t.TempDir()
+	engine := &fakeEngine{particles: []*physics.Particle{physics.NewParticle(1.0, 0, 0, 0, 1.0, 0, 0)}}
+	engine.particles[0].Velocity.X = math.NaN()
+	checkpoint := filepath.Join(dir, "final.rsim")
+	runner := NewRunner(engine, Options{
+		Steps:          100,
+		TimeStep:       0.1,
+		CheckpointPath: checkpoint,
+		Guard:          guard.NewChecker(guard.Options{Interval: 5}),
+		GuardReportDir: dir,
+	})
+
+	result, err := runner.Run()
+	if err == nil || !strings.Contains(err.Error(), "sanity check failed") {
+		t.Fatalf("Expected a sanity check error, got %v", err)
+	}
+	if result.Steps != 5 {
+		t.Errorf("Expected the run to stop at the first check (step 5), got %d", result.Steps)
+	}
+	reports, _ := filepath.Glob(filepath.Join(dir, "guard_*[0-9].json"))
+	if len(reports) != 1 {
+		t.Errorf("Expected one guard report, got %v", reports)
+	}
+	if _, err := os.Stat(checkpoint); err != nil {
+		t.Errorf("Expected the final checkpoint to be written: %v", err)
+	}
+}
+
+// TestRunnerSoakStops tests that steadily growing memory stops a soak run
+// with a report once the trend has enough samples
+func TestRunnerSoakStops(t *testing.T) {
+	dir := t.TempDir()
+	engine := &fakeEngine{particles: []*physics.Particle{physics.NewParticle(1.0, 0, 0, 0, 1.0, 0, 0)}}
+	var rss uint64
+	monitor := soak.NewMonitor(soak.Options{
+		Interval:   time.Nanosecond,
+		Thresholds: soak.Thresholds{RSS: 64},
+		ReadMemory: func() soak.Memory {
+			rss += 1 << 30
+			return soak.Memory{RSS: rss}
+		},
+	}, time.Now())
+	runner := NewRunner(engine, Options{
+		Steps:          100,
+		TimeStep:       0.1,
+		Soak:           monitor,
+		GuardReportDir: dir,
+		Provenance:     func() provenance.Metadata { return provenance.Metadata{Seed: 7} },
+	})
+
+	result, err := runner.Run()
+	if err == nil || !strings.Contains(err.Error(), "soak watchdog: rss grows") {
+		t.Fatalf("Expected a soak watchdog error, got %v", err)
+	}
+	if want := int64(soak.Warmup + soak.MinSamples); result.Steps != want {
+		t.Errorf("Expected the run to stop at step %d, got %d", want, result.Steps)
+	}
+	reports, _ := filepath.Glob(filepath.Join(dir, "soak_*.json"))
+	if len(reports) != 1 {
+		t.Fatalf("Expected one soak report, got %v", reports)
+	}
+	data, err := os.ReadFile(reports[0])
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(string(data), `"seed": 7`) {
+		t.Errorf("Expected the report to carry the run's metadata, got %s", data)
+	}
+}
+
+// TestRunnerHealthLog tests that health advice is logged once, when it first
+// appears, and does not stop the run
+func TestRunnerHealthLog(t *testing.T) {
+	engine := &fakeEngine{particles: []*physics.Particle{physics.NewParticle(1.0, 0, 0, 0, 30, 0, 0)}}
+	var log strings.Builder
+	runner := NewRunner(engine, Options{
+		Steps:     30,
+		TimeStep:  0.1,
+		Health:    health.NewMonitor(health.Options{Interval: 10, Width: 64, Height: 64}),
+		HealthLog: &log,
+	})
+
+	result, err := runner.Run()
+	if err != nil || result.Steps != 30 {
+		t.Fatalf("Expected 30 steps without error, got %d: %v", result.Steps, err)
+	}
+	lines := strings.Split(strings.TrimSpace(log.String()), "\n")
+	if len(lines) != 1 || !strings.HasPrefix(lines[0], "Step 10: health 0/100 (poor): reduce dt") {
+		t.Errorf("Expected a single CFL warning at step 10, got %q", log.String())
+	}
+}
+
+// TestRunnerWithSimulation tests the runner driving the real CPU simulation
+func TestRunnerWithSimulation(t *testing.T) {
+	cfg := config.DefaultConfig()
+	cfg.SimulationWidth = 32
+	cfg.SimulationDepth = 32
+	cfg.NumParticles = 4
+
+	sim := simulation.NewSimulation(cfg)
+	runner := NewRunner(sim, Options{Steps: 5, TimeStep: 0.016, Config: cfg})
+
+	result, err := runner.Run()
+	if err != nil {
+		t.Fatalf("Run failed: %v", err)
+	}
+	if result.Steps != 5 || sim.GetStepCount() != 5 {
+		t.Errorf("Expected 5 steps, got %d", result.Steps)
+	}
+	if result.Diagnostics.ParticleCount != 4 {
+		t.Errorf("Expected diagnostics for 4 particles, got %d", result.Diagnostics.ParticleCount)
+	}
+}
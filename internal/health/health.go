@@ -0,0 +1,296 @@
+package health
+
+import (
+	"fmt"
+	"math"
+	"relativity_simulation_2d/internal/physics"
+	"sort"
+	"strings"
+)
+
+// DefaultInterval is the number of steps between health checks
+const DefaultInterval = 100
+
+// Metric names
+const (
+	MetricNonFinite     = "non-finite"
+	MetricEnergyDrift   = "energy drift"
+	MetricMomentumDrift = "momentum drift"
+	MetricOccupancy     = "cell occupancy"
+	MetricCFL           = "cfl"
+)
+
+// Thresholds between which a metric's score falls from 1 to 0
+var (
+	EnergyDriftRange   = [2]float64{0.01, 0.1}  // Energy change relative to K + |W| per 1000 steps
+	MomentumDriftRange = [2]float64{1e-3, 1e-1} // Momentum change relative to Σm|v|
+	OccupancyRange     = [2]float64{100, 1000}  // Particles in the fullest cell
+	CFLRange           = [2]float64{0.5, 2}     // Cells crossed per step by the fastest particle
+)
+
+// Options configures a Monitor
+type Options struct {
+	Interval int64   // Steps between checks (0 = DefaultInterval)
+	Width    int     // Grid cells along x, for the occupancy count
+	Height   int     // Grid cells along z
+	CellSize float64 // Physical size of a grid cell (0 = 1)
+}
+
+// Sample is the state a report is computed from
+type Sample struct {
+	Step           int64
+	SimTime        float64
+	ParticleCount  int
+	TotalMass      float64
+	Kinetic        float64
+	Potential      float64
+	HasPotential   bool // False when no potential was given, so the energy drift is not scored
+	MomentumX      float64
+	MomentumZ      float64
+	MomentumScale  float64 // Σm|v|, the momentum drift is measured against
+	MaxOccupancy   int     // Particles in the fullest grid cell
+	CFL            float64 // Cells crossed per step by the fastest particle
+	NonFiniteCount int
+}
+
+// Issue is a metric that lowers the score, with advice on fixing it
+type Issue struct {
+	Metric string
+	Value  float64
+	Score  float64 // 0 (bad) to 1 (good)
+	Advice string
+}
+
+// Report is the result of a health check
+type Report struct {
+	Step    int64
+	Score   float64 // 0 to 100, set by the worst metric
+	Issues  []Issue // Metrics below full score, worst first
+	Changed bool    // The metrics with issues differ from the previous report's, so the advice is worth showing again
+}
+
+// Status names the score band: good, fair or poor
+func (r Report) Status() string {
+	switch {
+	case r.Score >= 80:
+		return "good"
+	case r.Score >= 50:
+		return "fair"
+	default:
+		return "poor"
+	}
+}
+
+// Advice returns the advice of every issue, worst first
+func (r Report) Advice() []string {
+	advice := make([]string, len(r.Issues))
+	for i, issue := range r.Issues {
+		advice[i] = issue.Advice
+	}
+	return advice
+}
+
+// String describes the report in one line
+func (r Report) String() string {
+	line := fmt.Sprintf("health %.0f/100 (%s)", r.Score, r.Status())
+	if len(r.Issues) > 0 {
+		line += ": " + strings.Join(r.Advice(), "; ")
+	}
+	return line
+}
+
+// Monitor checks the health of a run every few steps against the first
+// sample it took. A change in the particle count or total mass, e.g. from a
+// spawn, starts a new reference
+type Monitor struct {
+	opts      Options
+	reference Sample
+	started   bool
+	last      *Report
+	counts    []int32 // Reused occupancy counts
+}
+
+// NewMonitor creates a monitor with the given options
+func NewMonitor(opts Options) *Monitor {
+	if opts.Interval <= 0 {
+		opts.Interval = DefaultInterval
+	}
+	return &Monitor{opts: opts}
+}
+
+// Reset forgets the reference sample, e.g. after a restart
+func (m *Monitor) Reset() {
+	m.started = false
+	m.last = nil
+}
+
+// Last returns the latest report, or nil before the first check
+func (m *Monitor) Last() *Report {
+	return m.last
+}
+
+// Check measures the particles if step is due and returns the report, or
+// nil. potential is the potential of the current positions; without one
+// (nil) the energy drift is not scored
+func (m *Monitor) Check(step int64, simTime float64, dt float32, particles []*physics.Particle, potential physics.Grid) *Report {
+	if step%m.opts.Interval != 0 {
+		return nil
+	}
+	m.Observe(m.Measure(step, simTime, dt, particles, potential))
+	return m.last
+}
+
+// Measure takes a sample of the particles
+func (m *Monitor) Measure(step int64, simTime float64, dt float32, particles []*physics.Particle, potential physics.Grid) Sample {
+	d := physics.ComputeDiagnostics(particles)
+	dx := m.cellSize()
+	s := Sample{
+		Step:           step,
+		SimTime:        simTime,
+		ParticleCount:  d.ParticleCount,
+		TotalMass:      d.TotalMass,
+		Kinetic:        d.KineticEnergy,
+		MomentumX:      d.MomentumX,
+		MomentumZ:      d.MomentumZ,
+		CFL:            d.MaxSpeed * float64(dt) / dx,
+		NonFiniteCount: d.NonFiniteCount,
+	}
+	if potential.Width() > 0 && potential.Height() > 0 {
+		s.Potential = physics.PotentialEnergy(particles, potential, dx)
+		s.HasPotential = true
+	}
+	for _, p := range particles {
+		s.MomentumScale += float64(p.Mass) * p.Velocity.Length()
+	}
+	s.MaxOccupancy = m.maxOccupancy(particles)
+	return s
+}
+
+// maxOccupancy returns the number of particles in the fullest cell of the
+// periodic grid, skipping non-finite positions
+func (m *Monitor) maxOccupancy(particles []*physics.Particle) int {
+	width, height := m.opts.Width, m.opts.Height
+	if width <= 0 || height <= 0 {
+		return 0
+	}
+	if len(m.counts) != width*height {
+		m.counts = make([]int32, width*height)
+	} else {
+		clear(m.counts)
+	}
+	dx := m.cellSize()
+	most := int32(0)
+	for _, p := range particles {
+		x, z := p.Position.X/dx+float64(width)/2, p.Position.Z/dx+float64(height)/2
+		if math.IsNaN(x) || math.IsInf(x, 0) || math.IsNaN(z) || math.IsInf(z, 0) {
+			continue
+		}
+		i := int(math.Floor(x)) % width
+		j := int(math.Floor(z)) % height
+		if i < 0 {
+			i += width
+		}
+		if j < 0 {
+			j += height
+		}
+		m.counts[i*height+j]++
+		most = max(most, m.counts[i*height+j])
+	}
+	return int(most)
+}
+
+// cellSize returns the physical size of a grid cell
+func (m *Monitor) cellSize() float64 {
+	if m.opts.CellSize > 0 {
+		return m.opts.CellSize
+	}
+	return 1
+}
+
+// Observe scores a sample against the reference and remembers the report
+func (m *Monitor) Observe(s Sample) Report {
+	if !m.started || s.ParticleCount != m.reference.ParticleCount || s.TotalMass != m.reference.TotalMass {
+		m.reference = s
+		m.started = true
+	}
+	report := Score(m.reference, s)
+	report.Changed = m.last == nil || !sameMetrics(m.last.Issues, report.Issues)
+	m.last = &report
+	return report
+}
+
+// Score rates sample s against the reference sample ref
+func Score(ref, s Sample) Report {
+	report := Report{Step: s.Step, Score: 100}
+	if s.NonFiniteCount > 0 {
+		report.Score = 0
+		report.Issues = []Issue{{
+			Metric: MetricNonFinite,
+			Value:  float64(s.NonFiniteCount),
+			Advice: fmt.Sprintf("reduce dt, %d particles are NaN/Inf", s.NonFiniteCount),
+		}}
+		return report
+	}
+
+	var issues []Issue
+	add := func(metric string, value float64, bounds [2]float64, advice string) {
+		score := linear(value, bounds)
+		if score < 1 {
+			issues = append(issues, Issue{Metric: metric, Value: value, Score: score, Advice: advice})
+		}
+	}
+
+	// The energy change is measured against K + |W|, since a bound system's
+	// total energy can be close to zero
+	if steps := s.Step - ref.Step; steps > 0 && s.HasPotential && ref.HasPotential {
+		scale := math.Max(ref.Kinetic+math.Abs(ref.Potential), s.Kinetic+math.Abs(s.Potential))
+		if scale > 0 {
+			change := (s.Kinetic + s.Potential) - (ref.Kinetic + ref.Potential)
+			rate := math.Abs(change) / scale * 1000 / float64(steps)
+			add(MetricEnergyDrift, rate, EnergyDriftRange,
+				fmt.Sprintf("reduce dt, energy drifts %.2g%% per 1000 steps", 100*rate))
+		}
+	}
+	if scale := math.Max(ref.MomentumScale, s.MomentumScale); scale > 0 {
+		drift := math.Hypot(s.MomentumX-ref.MomentumX, s.MomentumZ-ref.MomentumZ) / scale
+		add(MetricMomentumDrift, drift, MomentumDriftRange,
+			fmt.Sprintf("reduce dt or turn off -adaptive-quality, momentum drifts %.2g%% of Σm|v|", 100*drift))
+	}
+	add(MetricOccupancy, float64(s.MaxOccupancy), OccupancyRange,
+		fmt.Sprintf("increase grid, cells contain >%.0f particles (fullest %d)", OccupancyRange[0], s.MaxOccupancy))
+	add(MetricCFL, s.CFL, CFLRange,
+		fmt.Sprintf("reduce dt, the fastest particle crosses %.2g cells per step", s.CFL))
+
+	sort.SliceStable(issues, func(a, b int) bool { return issues[a].Score < issues[b].Score })
+	if len(issues) > 0 {
+		report.Score = 100 * issues[0].Score
+	}
+	report.Issues = issues
+	return report
+}
+
+// sameMetrics reports whether a and b list the same metrics in the same order
+func sameMetrics(a, b []Issue) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for i := range a {
+		if a[i].Metric != b[i].Metric {
+			return false
+		}
+	}
+	return true
+}
+
+// linear returns 1 at or below bounds[0], 0 at or above bounds[1], and falls
+// linearly in between
+func linear(value float64, bounds [2]float64) float64 {
+	switch {
+	case value <= bounds[0]:
+		return 1
+	case value >= bounds[1]:
+		return 0
+	default:
+		return (bounds[1] - value) / (bounds[1] - bounds[0])
+	}
+}
@@ -0,0 +1,112 @@
+package health
+
+import (
+	"math"
+	"relativity_simulation_2d/internal/physics"
+	"strings"
+	"testing"
+)
+
+// TestScoreHealthy tests that a sample matching its reference scores 100
+// with no advice
+func TestScoreHealthy(t *testing.T) {
+	s := Sample{Step: 100, Kinetic: 1, Potential: -2, HasPotential: true, MomentumScale: 1, MaxOccupancy: 10, CFL: 0.1}
+	ref := s
+	ref.Step = 0
+	report := Score(ref, s)
+	if report.Score != 100 || len(report.Issues) != 0 || report.Status() != "good" {
+		t.Errorf("Expected a healthy report, got %v", report)
+	}
+}
+
+// TestScoreWorstMetric tests that the score is set by the worst metric and
+// the issues are ordered worst first
+func TestScoreWorstMetric(t *testing.T) {
+	ref := Sample{Kinetic: 1, Potential: -1, HasPotential: true}
+	s := ref
+	s.Step = 1000
+	s.Kinetic = 1.055    // 2.75% drift per 1000 steps, score 0.75
+	s.MaxOccupancy = 775 // Score 0.25
+	s.CFL = 0.5
+
+	report := Score(ref, s)
+	if math.Abs(report.Score-25) > 1e-9 || report.Status() != "poor" {
+		t.Errorf("Expected a poor score of 25, got %v", report)
+	}
+	if len(report.Issues) != 2 || report.Issues[0].Metric != MetricOccupancy || report.Issues[1].Metric != MetricEnergyDrift {
+		t.Fatalf("Expected occupancy then energy drift issues, got %+v", report.Issues)
+	}
+	if !strings.HasPrefix(report.Issues[0].Advice, "increase grid, cells contain >100 particles") {
+		t.Errorf("Unexpected occupancy advice %q", report.Issues[0].Advice)
+	}
+	if !strings.HasPrefix(report.Issues[1].Advice, "reduce dt") {
+		t.Errorf("Unexpected energy advice %q", report.Issues[1].Advice)
+	}
+}
+
+// TestScoreNonFinite tests that NaN/Inf particles score 0 and hide the other metrics
+func TestScoreNonFinite(t *testing.T) {
+	report := Score(Sample{}, Sample{NonFiniteCount: 3, CFL: 10})
+	if report.Score != 0 || len(report.Issues) != 1 || report.Issues[0].Metric != MetricNonFinite {
+		t.Errorf("Expected a single non-finite issue, got %v", report)
+	}
+}
+
+// TestScoreCFL tests that a time step crossing two cells scores 0 and asks
+// for a smaller dt
+func TestScoreCFL(t *testing.T) {
+	report := Score(Sample{}, Sample{CFL: 2})
+	if report.Score != 0 || len(report.Issues) != 1 || !strings.HasPrefix(report.Issues[0].Advice, "reduce dt") {
+		t.Errorf("Expected a CFL issue, got %v", report)
+	}
+}
+
+// TestMonitorMeasure tests the occupancy, CFL and momentum measured from particles
+func TestMonitorMeasure(t *testing.T) {
+	particles := []*physics.Particle{
+		physics.NewParticle(1, 0.2, 0, 0.2, 3, 0, 4),
+		physics.NewParticle(1, 0.7, 0, 0.9, -3, 0, -4),
+		physics.NewParticle(1, -3.5, 0, 2.5, 0, 0, 0),
+		physics.NewParticle(1, math.NaN(), 0, 0, 0, 0, 0),
+	}
+	m := NewMonitor(Options{Width: 8, Height: 8})
+	s := m.Measure(7, 1, 0.1, particles, nil)
+	if s.MaxOccupancy != 2 {
+		t.Errorf("Expected 2 particles in the fullest cell, got %d", s.MaxOccupancy)
+	}
+	if s.NonFiniteCount != 1 || s.HasPotential {
+		t.Errorf("Expected one non-finite particle and no potential, got %+v", s)
+	}
+	if math.Abs(s.MomentumScale-10) > 1e-12 {
+		t.Errorf("Expected Σm|v| = 10, got %g", s.MomentumScale)
+	}
+}
+
+// TestMonitorCheck tests that checks run on the interval, that repeated
+// advice is not marked as changed and that a change in particle count starts
+// a new reference
+func TestMonitorCheck(t *testing.T) {
+	particles := []*physics.Particle{physics.NewParticle(1, 0, 0, 0, 1, 0, 0)}
+	m := NewMonitor(Options{Interval: 10, Width: 4, Height: 4})
+	if m.Check(5, 0, 0.01, particles, nil) != nil || m.Last() != nil {
+		t.Fatal("Expected no check before the interval")
+	}
+	if r := m.Check(10, 0, 0.01, particles, nil); r == nil || r.Score != 100 {
+		t.Fatalf("Expected a healthy first report, got %v", r)
+	}
+
+	// A kick changes the momentum
+	particles[0].Velocity = physics.NewVec3(2, 0, 0)
+	if r := m.Check(20, 0, 0.01, particles, nil); r == nil || len(r.Issues) != 1 || r.Issues[0].Metric != MetricMomentumDrift || !r.Changed {
+		t.Fatalf("Expected a new momentum drift issue, got %v", r)
+	}
+	if r := m.Check(30, 0, 0.01, particles, nil); r == nil || r.Changed {
+		t.Fatalf("Expected the same advice again, got %+v", r)
+	}
+
+	// A spawn resets the reference
+	particles = append(particles, physics.NewParticle(1, 1, 0, 1, 0, 0, 0))
+	if r := m.Check(40, 0, 0.01, particles, nil); r == nil || r.Score != 100 || !r.Changed || m.Last() != r {
+		t.Errorf("Expected a new reference after a spawn, got %v", r)
+	}
+}
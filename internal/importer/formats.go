@@ -0,0 +1,202 @@
+package importer
+
+import (
+	"bufio"
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+)
+
+// csvColumns maps accepted CSV header names (lower case) to record fields
+var csvColumns = map[string]func(r *record, v float64){
+	"x":      func(r *record, v float64) { r.pos[0] = v },
+	"y":      func(r *record, v float64) { r.pos[1] = v },
+	"z":      func(r *record, v float64) { r.pos[2] = v },
+	"vx":     func(r *record, v float64) { r.vel[0] = v },
+	"vy":     func(r *record, v float64) { r.vel[1] = v },
+	"vz":     func(r *record, v float64) { r.vel[2] = v },
+	"mass":   func(r *record, v float64) { r.mass = v },
+	"m":      func(r *record, v float64) { r.mass = v },
+	"radius": func(r *record, v float64) { r.radius, r.hasRadius = v, true },
+}
+
+// readCSV reads a CSV file whose header names the columns. Known columns are
+// x, y, z, vx, vy, vz, mass (or m) and radius; missing position and velocity
+// columns default to zero, unknown columns (e.g. id) are ignored
+func readCSV(r io.Reader) ([]record, error) {
+	cr := csv.NewReader(r)
+	cr.Comment = '#'
+	cr.TrimLeadingSpace = true
+
+	header, err := cr.Read()
+	if err != nil {
+		return nil, fmt.Errorf("failed to read CSV header: %v", err)
+	}
+
+	setters := make([]func(*record, float64), len(header))
+	var hasMass, hasPosition bool
+	for i, name := range header {
+		name = strings.ToLower(strings.TrimSpace(name))
+		setters[i] = csvColumns[name]
+		switch name {
+		case "mass", "m":
+			hasMass = true
+		case "x", "y", "z":
+			hasPosition = true
+		}
+	}
+	if !hasMass || !hasPosition {
+		return nil, errors.New("CSV header must name a mass column and at least one position column")
+	}
+
+	var records []record
+	for {
+		row, err := cr.Read()
+		if err == io.EOF {
+			return records, nil
+		}
+		if err != nil {
+			return nil, fmt.Errorf("failed to read CSV: %v", err)
+		}
+
+		line, _ := cr.FieldPos(0)
+		var rec record
+		for i, field := range row {
+			if setters[i] == nil {
+				continue
+			}
+			v, err := strconv.ParseFloat(strings.TrimSpace(field), 64)
+			if err != nil {
+				return nil, fmt.Errorf("line %d: invalid %s value %q", line, header[i], field)
+			}
+			setters[i](&rec, v)
+		}
+		records = append(records, rec)
+	}
+}
+
+// readGadget reads Gadget-2 style ASCII rows "x y z vx vy vz mass", optionally
+// preceded by a particle ID column. Fields may be separated by whitespace or
+// commas; blank lines and lines starting with # are skipped
+func readGadget(r io.Reader) ([]record, error) {
+	var records []record
+	scanner := bufio.NewScanner(r)
+	scanner.Buffer(make([]byte, 64*1024), 1024*1024)
+	for line := 1; scanner.Scan(); line++ {
+		text := strings.TrimSpace(scanner.Text())
+		if text == "" || strings.HasPrefix(text, "#") {
+			continue
+		}
+
+		fields := strings.FieldsFunc(text, func(c rune) bool {
+			return c == ',' || c == ' ' || c == '\t'
+		})
+		switch len(fields) {
+		case 7:
+		case 8:
+			fields = fields[1:] // Leading particle ID
+		default:
+			return nil, fmt.Errorf("line %d: expected 7 or 8 columns, got %d", line, len(fields))
+		}
+
+		values, err := parseFloats(fields)
+		if err != nil {
+			return nil, fmt.Errorf("line %d: %v", line, err)
+		}
+		var rec record
+		copy(rec.pos[:], values[0:3])
+		copy(rec.vel[:], values[3:6])
+		rec.mass = values[6]
+		records = append(records, rec)
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("failed to read: %v", err)
+	}
+	return records, nil
+}
+
+// maxTipsyParticles bounds the header count so a corrupt header cannot exhaust memory
+const maxTipsyParticles = 1 << 26
+
+// readTipsy reads the TIPSY ASCII layout: a header "nbodies nsph nstar", the
+// dimension count (2 or 3) and the time, followed by whitespace-separated
+// arrays of all masses, then each position component, then each velocity
+// component. Any trailing arrays (softening, gas properties) are ignored
+func readTipsy(r io.Reader) ([]record, error) {
+	scanner := bufio.NewScanner(r)
+	scanner.Buffer(make([]byte, 64*1024), 1024*1024)
+	scanner.Split(bufio.ScanWords)
+
+	next := func(what string) (float64, error) {
+		if !scanner.Scan() {
+			if err := scanner.Err(); err != nil {
+				return 0, fmt.Errorf("failed to read: %v", err)
+			}
+			return 0, fmt.Errorf("unexpected end of file reading %s", what)
+		}
+		v, err := strconv.ParseFloat(scanner.Text(), 64)
+		if err != nil {
+			return 0, fmt.Errorf("invalid %s value %q", what, scanner.Text())
+		}
+		return v, nil
+	}
+
+	var header [5]float64 // nbodies, nsph, nstar, ndim, time
+	for i, name := range []string{"nbodies", "nsph", "nstar", "ndim", "time"} {
+		v, err := next(name)
+		if err != nil {
+			return nil, err
+		}
+		header[i] = v
+	}
+	n, ndim := int(header[0]), int(header[3])
+	if header[0] != float64(n) || n < 0 || n > maxTipsyParticles {
+		return nil, fmt.Errorf("invalid particle count %v", header[0])
+	}
+	if ndim != 2 && ndim != 3 {
+		return nil, fmt.Errorf("unsupported dimension count %v", header[3])
+	}
+
+	records := make([]record, n)
+	read := func(what string, set func(rec *record, v float64)) error {
+		for i := range records {
+			v, err := next(what)
+			if err != nil {
+				return err
+			}
+			set(&records[i], v)
+		}
+		return nil
+	}
+
+	if err := read("mass", func(rec *record, v float64) { rec.mass = v }); err != nil {
+		return nil, err
+	}
+	for d := 0; d < ndim; d++ {
+		if err := read("position", func(rec *record, v float64) { rec.pos[d] = v }); err != nil {
+			return nil, err
+		}
+	}
+	for d := 0; d < ndim; d++ {
+		if err := read("velocity", func(rec *record, v float64) { rec.vel[d] = v }); err != nil {
+			return nil, err
+		}
+	}
+	return records, nil
+}
+
+// parseFloats parses every field as a float64
+func parseFloats(fields []string) ([]float64, error) {
+	values := make([]float64, len(fields))
+	for i, f := range fields {
+		v, err := strconv.ParseFloat(f, 64)
+		if err != nil {
+			return nil, fmt.Errorf("invalid number %q", f)
+		}
+		values[i] = v
+	}
+	return values, nil
+}
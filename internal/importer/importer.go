@@ -0,0 +1,147 @@
+package importer
+
+import (
+	"fmt"
+	"io"
+	"math"
+	"os"
+	"path/filepath"
+	"relativity_simulation_2d/internal/physics"
+	"strings"
+)
+
+// Format identifies an external particle file format
+type Format string
+
+const (
+	FormatAuto   Format = "auto"   // Chosen from the file extension
+	FormatCSV    Format = "csv"    // CSV with a header row naming the columns
+	FormatGadget Format = "gadget" // Gadget-2 style ASCII: [id] x y z vx vy vz mass per line
+	FormatTipsy  Format = "tipsy"  // TIPSY ASCII: header, then per-quantity arrays
+)
+
+// Options controls how imported coordinates map onto the simulation
+type Options struct {
+	// PlaneXY maps the file's x-y plane onto the simulation's x-z plane
+	// (file y becomes Z, file z becomes Y). The default keeps axes as-is,
+	// with the simulation plane being x-z
+	PlaneXY bool
+}
+
+// ParseFormat converts a format name to a Format ("" = auto)
+func ParseFormat(s string) (Format, error) {
+	switch f := Format(strings.ToLower(s)); f {
+	case "":
+		return FormatAuto, nil
+	case FormatAuto, FormatCSV, FormatGadget, FormatTipsy:
+		return f, nil
+	default:
+		return "", fmt.Errorf("unknown import format %q", s)
+	}
+}
+
+// DetectFormat chooses a format from the file extension
+func DetectFormat(path string) (Format, error) {
+	switch strings.ToLower(filepath.Ext(path)) {
+	case ".csv":
+		return FormatCSV, nil
+	case ".txt", ".dat", ".ascii", ".gadget":
+		return FormatGadget, nil
+	case ".tipsy", ".tip", ".std":
+		return FormatTipsy, nil
+	default:
+		return "", fmt.Errorf("cannot detect format of %q; specify one of csv, gadget or tipsy", path)
+	}
+}
+
+// Load reads particles from the file at path
+func Load(path string, format Format, opts Options) ([]*physics.Particle, error) {
+	if format == FormatAuto || format == "" {
+		detected, err := DetectFormat(path)
+		if err != nil {
+			return nil, err
+		}
+		format = detected
+	}
+
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open initial conditions: %v", err)
+	}
+	defer f.Close()
+
+	particles, err := Read(f, format, opts)
+	if err != nil {
+		return nil, fmt.Errorf("failed to import %s: %v", path, err)
+	}
+	return particles, nil
+}
+
+// Read parses particles in the given format from r
+func Read(r io.Reader, format Format, opts Options) ([]*physics.Particle, error) {
+	var records []record
+	var err error
+	switch format {
+	case FormatCSV:
+		records, err = readCSV(r)
+	case FormatGadget:
+		records, err = readGadget(r)
+	case FormatTipsy:
+		records, err = readTipsy(r)
+	default:
+		return nil, fmt.Errorf("unsupported import format %q", format)
+	}
+	if err != nil {
+		return nil, err
+	}
+	if len(records) == 0 {
+		return nil, fmt.Errorf("no particles found")
+	}
+
+	particles := make([]*physics.Particle, len(records))
+	for i, rec := range records {
+		if err := rec.validate(); err != nil {
+			return nil, fmt.Errorf("particle %d: %v", i, err)
+		}
+		particles[i] = rec.particle(opts)
+	}
+	return particles, nil
+}
+
+// record is one particle as read from a file, in file coordinates
+type record struct {
+	pos, vel  [3]float64
+	mass      float64
+	radius    float64
+	hasRadius bool
+}
+
+// validate rejects non-finite values and non-positive masses
+func (r record) validate() error {
+	for _, v := range append(r.pos[:], r.vel[:]...) {
+		if math.IsNaN(v) || math.IsInf(v, 0) {
+			return fmt.Errorf("non-finite position or velocity")
+		}
+	}
+	if !(r.mass > 0) || math.IsInf(r.mass, 0) {
+		return fmt.Errorf("invalid mass %v", r.mass)
+	}
+	if r.hasRadius && (!(r.radius >= 0) || math.IsInf(r.radius, 0)) {
+		return fmt.Errorf("invalid radius %v", r.radius)
+	}
+	return nil
+}
+
+// particle converts the record to simulation coordinates
+func (r record) particle(opts Options) *physics.Particle {
+	pos, vel := r.pos, r.vel
+	if opts.PlaneXY {
+		pos[1], pos[2] = pos[2], pos[1]
+		vel[1], vel[2] = vel[2], vel[1]
+	}
+	p := physics.NewParticle(r.mass, pos[0], pos[1], pos[2], vel[0], vel[1], vel[2])
+	if r.hasRadius {
+		p.Radius = float32(r.radius)
+	}
+	return p
+}
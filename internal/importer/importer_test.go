@@ -0,0 +1,157 @@
+package importer
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// TestReadCSV tests header-mapped columns, defaults and ignored columns
+func TestReadCSV(t *testing.T) {
+	input := `# exported by another tool
+id, X, z, vx, vz, mass, radius
+7, 1.5, -2, 0.1, 0.2, 10, 0.3
+8, -4, 3, 0, -1, 2.5, 0.1
+`
+	particles, err := Read(strings.NewReader(input), FormatCSV, Options{})
+	if err != nil {
+		t.Fatalf("Read failed: %v", err)
+	}
+	if len(particles) != 2 {
+		t.Fatalf("Expected 2 particles, got %d", len(particles))
+	}
+
+	p := particles[0]
+	if p.Position.X != 1.5 || p.Position.Y != 0 || p.Position.Z != -2 {
+		t.Errorf("Unexpected position %v", p.Position)
+	}
+	if p.Velocity.X != 0.1 || p.Velocity.Z != 0.2 {
+		t.Errorf("Unexpected velocity %v", p.Velocity)
+	}
+	if p.Mass != 10 || p.Radius != 0.3 {
+		t.Errorf("Unexpected mass/radius %f/%f", p.Mass, p.Radius)
+	}
+}
+
+// TestReadCSVErrors tests missing columns and malformed values
+func TestReadCSVErrors(t *testing.T) {
+	cases := map[string]string{
+		"no mass column":   "x,z\n1,2\n",
+		"no position":      "mass,vx\n1,2\n",
+		"bad number":       "x,z,mass\n1,abc,2\n",
+		"zero mass":        "x,z,mass\n1,2,0\n",
+		"ragged row":       "x,z,mass\n1,2\n",
+		"no data rows":     "x,z,mass\n",
+		"non-finite value": "x,z,mass\nNaN,2,1\n",
+	}
+	for name, input := range cases {
+		if _, err := Read(strings.NewReader(input), FormatCSV, Options{}); err == nil {
+			t.Errorf("%s: expected error", name)
+		}
+	}
+}
+
+// TestReadGadget tests whitespace and comma rows with and without an ID column
+func TestReadGadget(t *testing.T) {
+	input := `# x y z vx vy vz mass
+1 2 3 4 5 6 7
+
+42, -1, -2, -3, -4, -5, -6, 0.5
+`
+	particles, err := Read(strings.NewReader(input), FormatGadget, Options{})
+	if err != nil {
+		t.Fatalf("Read failed: %v", err)
+	}
+	if len(particles) != 2 {
+		t.Fatalf("Expected 2 particles, got %d", len(particles))
+	}
+	if p := particles[0]; p.Position.Z != 3 || p.Velocity.Y != 5 || p.Mass != 7 {
+		t.Errorf("Unexpected first particle %+v", p)
+	}
+	if p := particles[1]; p.Position.X != -1 || p.Velocity.Z != -6 || p.Mass != 0.5 {
+		t.Errorf("Unexpected second particle (ID column not skipped?) %+v", p)
+	}
+
+	if _, err := Read(strings.NewReader("1 2 3\n"), FormatGadget, Options{}); err == nil {
+		t.Error("Expected error for short row")
+	}
+}
+
+// TestReadTipsy tests the array layout for 3D and 2D files
+func TestReadTipsy(t *testing.T) {
+	input := `2 0 0
+3
+0.0
+1.0 2.0
+10 20
+11 21
+12 22
+0.1 0.2
+0.3 0.4
+0.5 0.6
+0.01 0.01
+`
+	particles, err := Read(strings.NewReader(input), FormatTipsy, Options{})
+	if err != nil {
+		t.Fatalf("Read failed: %v", err)
+	}
+	if len(particles) != 2 {
+		t.Fatalf("Expected 2 particles, got %d", len(particles))
+	}
+	if p := particles[1]; p.Mass != 2 || p.Position.X != 20 || p.Position.Y != 21 || p.Position.Z != 22 ||
+		p.Velocity.X != 0.2 || p.Velocity.Y != 0.4 || p.Velocity.Z != 0.6 {
+		t.Errorf("Unexpected particle %+v", p)
+	}
+
+	flat := "1 0 0\n2\n0\n5\n1\n2\n3\n4\n"
+	particles, err = Read(strings.NewReader(flat), FormatTipsy, Options{PlaneXY: true})
+	if err != nil {
+		t.Fatalf("Read 2D failed: %v", err)
+	}
+	if p := particles[0]; p.Position.X != 1 || p.Position.Y != 0 || p.Position.Z != 2 || p.Velocity.Z != 4 {
+		t.Errorf("2D file should map x-y onto x-z, got %+v", p)
+	}
+
+	if _, err := Read(strings.NewReader("3 0 0\n3\n0\n1 2\n"), FormatTipsy, Options{}); err == nil {
+		t.Error("Expected error for truncated arrays")
+	}
+	if _, err := Read(strings.NewReader("1 0 0\n4\n0\n"), FormatTipsy, Options{}); err == nil {
+		t.Error("Expected error for unsupported dimension count")
+	}
+}
+
+// TestPlaneXY tests that the x-y plane option swaps the y and z axes
+func TestPlaneXY(t *testing.T) {
+	input := "x,y,z,vx,vy,vz,mass\n1,2,3,4,5,6,1\n"
+	particles, err := Read(strings.NewReader(input), FormatCSV, Options{PlaneXY: true})
+	if err != nil {
+		t.Fatal(err)
+	}
+	p := particles[0]
+	if p.Position.Y != 3 || p.Position.Z != 2 || p.Velocity.Y != 6 || p.Velocity.Z != 5 {
+		t.Errorf("Axes not swapped: %+v", p)
+	}
+}
+
+// TestLoadDetectsFormat tests extension-based detection and format parsing
+func TestLoadDetectsFormat(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "ic.csv")
+	if err := os.WriteFile(path, []byte("x,z,mass\n1,2,3\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	particles, err := Load(path, FormatAuto, Options{})
+	if err != nil || len(particles) != 1 {
+		t.Fatalf("Load failed: %v", err)
+	}
+
+	if _, err := DetectFormat("ic.hdf5"); err == nil {
+		t.Error("Expected error for unknown extension")
+	}
+	if f, err := ParseFormat("TIPSY"); err != nil || f != FormatTipsy {
+		t.Errorf("ParseFormat(TIPSY) = %q, %v", f, err)
+	}
+	if _, err := ParseFormat("hdf5"); err == nil {
+		t.Error("Expected error for unknown format name")
+	}
+}
@@ -1,3 +1,5 @@
+//go:build !js
+
 package input
 
 import (
@@ -10,8 +12,12 @@ import (
 type SimulationState struct {
 	Pause  bool
 	UseGPU bool
+	Cycle  bool // G asked for the next compute mode
 	Yaw    float32
 	Pitch  float32
+	Spawn  bool    // A tap asked for a particle at SpawnX, SpawnY
+	SpawnX float32 // Screen position of the tap
+	SpawnY float32
 }
 
 // InputConfig holds input configuration settings
@@ -20,12 +26,15 @@ type InputConfig struct {
 	MouseSensitivity float32
 	ScreenWidth      int
 	ScreenHeight     int
+	TouchEnabled     bool
+	FrameTime        float32
 }
 
-// InputController coordinates keyboard and mouse input
+// InputController coordinates keyboard, mouse and touch input
 type InputController struct {
 	keyboard *KeyboardHandler
 	mouse    *MouseHandler
+	touch    *TouchHandler
 }
 
 // NewInputController creates a new input controller
@@ -33,6 +42,7 @@ func NewInputController() *InputController {
 	return &InputController{
 		keyboard: NewKeyboardHandler(),
 		mouse:    NewMouseHandler(),
+		touch:    NewTouchHandler(),
 	}
 }
 
@@ -45,6 +55,7 @@ func (c *InputController) ProcessInput(camera *rl.Camera3D, state *SimulationSta
 	}
 	if actions.ToggleGPU {
 		state.UseGPU = !state.UseGPU
+		state.Cycle = true
 	}
 
 	// Process keyboard movement
@@ -54,18 +65,44 @@ func (c *InputController) ProcessInput(camera *rl.Camera3D, state *SimulationSta
 	// Process mouse rotation
 	rotation := c.mouse.ProcessRotation(state.Yaw, state.Pitch, config.MouseSensitivity)
 	if rotation.ShouldCenter {
-		rl.SetMousePosition(config.ScreenWidth/2, config.ScreenHeight/2)
+		// Recentering would move the emulated touch point under the finger
+		if !config.TouchEnabled {
+			rl.SetMousePosition(config.ScreenWidth/2, config.ScreenHeight/2)
+		}
 	} else if rotation.Active {
 		state.Yaw += rotation.YawDelta
 		state.Pitch += rotation.PitchDelta
 		c.mouse.UpdateCameraTarget(camera, state.Yaw, state.Pitch)
 	}
+
+	if config.TouchEnabled {
+		c.processTouch(camera, state, config)
+	}
+}
+
+// processTouch applies touch gestures: drag to look, pinch to zoom and tap to spawn
+func (c *InputController) processTouch(camera *rl.Camera3D, state *SimulationState, config *InputConfig) {
+	gestures := c.touch.ProcessGestures(config.FrameTime, state.Pitch, config.MouseSensitivity)
+	if gestures.LookActive {
+		state.Yaw += gestures.YawDelta
+		state.Pitch += gestures.PitchDelta
+		c.mouse.UpdateCameraTarget(camera, state.Yaw, state.Pitch)
+	}
+	if gestures.Zoom != 0 {
+		applyZoom(camera, gestures.Zoom)
+	}
+	if gestures.Tap {
+		state.Spawn = true
+		state.SpawnX = gestures.TapX
+		state.SpawnY = gestures.TapY
+	}
 }
 
 // UpdateFromRaylib updates input states from raylib
 func (c *InputController) UpdateFromRaylib() {
 	c.keyboard.UpdateFromRaylib()
 	c.mouse.UpdateFromRaylib()
+	c.touch.UpdateFromRaylib()
 }
 
 // Reset clears all input states
@@ -75,6 +112,25 @@ func (c *InputController) Reset() {
 	c.mouse.buttonStates = make(map[rl.MouseButton]bool)
 	c.mouse.deltaX = 0
 	c.mouse.deltaY = 0
+	c.touch.Reset()
+}
+
+// applyZoom moves the camera and its target along the view direction
+func applyZoom(camera *rl.Camera3D, amount float32) {
+	dx := camera.Target.X - camera.Position.X
+	dy := camera.Target.Y - camera.Position.Y
+	dz := camera.Target.Z - camera.Position.Z
+	length := float32(math.Sqrt(float64(dx*dx + dy*dy + dz*dz)))
+	if length == 0 {
+		return
+	}
+	scale := amount / length
+	camera.Position.X += dx * scale
+	camera.Position.Y += dy * scale
+	camera.Position.Z += dz * scale
+	camera.Target.X += dx * scale
+	camera.Target.Y += dy * scale
+	camera.Target.Z += dz * scale
 }
 
 // applyMovement applies movement to the camera
@@ -0,0 +1,146 @@
+//go:build !js
+
+package input
+
+import (
+	"math"
+
+	rl "github.com/gen2brain/raylib-go/raylib"
+)
+
+// Tap recognition limits: a touch that lifts within TapMaxDuration seconds
+// without moving more than TapMaxMovement pixels is a tap
+const (
+	TapMaxMovement = 12.0
+	TapMaxDuration = 0.3
+)
+
+// PinchZoomSpeed is the camera travel in world units per pixel of change in
+// the distance between two fingers
+const PinchZoomSpeed = 0.25
+
+// TouchPoint is one finger on the screen
+type TouchPoint struct {
+	ID int32
+	X  float32
+	Y  float32
+}
+
+// Gestures represents the touch gestures recognized in one frame
+type Gestures struct {
+	LookActive bool
+	YawDelta   float32
+	PitchDelta float32
+	Zoom       float32 // Camera travel along the view direction; positive moves closer
+	Tap        bool
+	TapX       float32
+	TapY       float32
+}
+
+// TouchHandler handles touch input: one finger drags to look, two fingers
+// pinch to zoom and a short touch taps
+type TouchHandler struct {
+	touches  []TouchPoint
+	previous []TouchPoint
+	tapStart TouchPoint // Where the current single touch began
+	tapTime  float32    // Seconds the current single touch has lasted
+	tapMoved bool       // The current touch moved too far or became a pinch
+}
+
+// NewTouchHandler creates a new touch handler
+func NewTouchHandler() *TouchHandler {
+	return &TouchHandler{}
+}
+
+// SetTouches sets the touch points for this frame (for testing)
+func (t *TouchHandler) SetTouches(points []TouchPoint) {
+	t.previous = append(t.previous[:0], t.touches...)
+	t.touches = append(t.touches[:0], points...)
+}
+
+// GetTouches returns the touch points of this frame
+func (t *TouchHandler) GetTouches() []TouchPoint {
+	return t.touches
+}
+
+// ProcessGestures recognizes gestures from the change in touch points since
+// the previous frame; dt is the frame time in seconds
+func (t *TouchHandler) ProcessGestures(dt, currentPitch, sensitivity float32) *Gestures {
+	gestures := &Gestures{}
+	current, previous := len(t.touches), len(t.previous)
+
+	switch {
+	case current == 0:
+		if previous == 1 && !t.tapMoved && t.tapTime <= TapMaxDuration {
+			gestures.Tap = true
+			gestures.TapX = t.previous[0].X
+			gestures.TapY = t.previous[0].Y
+		}
+		t.tapMoved = false
+		t.tapTime = 0
+
+	case current == 1 && previous == 0:
+		t.tapStart = t.touches[0]
+		t.tapTime = 0
+		t.tapMoved = false
+
+	case current == 1 && previous == 1 && t.touches[0].ID == t.previous[0].ID:
+		t.tapTime += dt
+		now, last := t.touches[0], t.previous[0]
+		if distance(now, t.tapStart) > TapMaxMovement {
+			t.tapMoved = true
+		}
+		if !t.tapMoved {
+			break
+		}
+
+		gestures.LookActive = true
+		gestures.YawDelta = (now.X - last.X) * sensitivity
+		gestures.PitchDelta = -(now.Y - last.Y) * sensitivity
+
+		// Clamp pitch to prevent flipping
+		newPitch := currentPitch + gestures.PitchDelta
+		if newPitch > 1.5 {
+			gestures.PitchDelta = 1.5 - currentPitch
+		} else if newPitch < -1.5 {
+			gestures.PitchDelta = -1.5 - currentPitch
+		}
+
+	case current >= 2:
+		t.tapMoved = true
+		if previous >= 2 && t.touches[0].ID == t.previous[0].ID && t.touches[1].ID == t.previous[1].ID {
+			spread := distance(t.touches[0], t.touches[1]) - distance(t.previous[0], t.previous[1])
+			gestures.Zoom = spread * PinchZoomSpeed
+		}
+
+	default:
+		// A finger lifted from a pinch or a new finger replaced the old one;
+		// neither is a tap and the jump in position is not a drag
+		t.tapMoved = true
+	}
+
+	return gestures
+}
+
+// UpdateFromRaylib updates touch points from raylib (for production use)
+func (t *TouchHandler) UpdateFromRaylib() {
+	points := make([]TouchPoint, rl.GetTouchPointCount())
+	for i := range points {
+		position := rl.GetTouchPosition(int32(i))
+		points[i] = TouchPoint{ID: rl.GetTouchPointId(int32(i)), X: position.X, Y: position.Y}
+	}
+	t.SetTouches(points)
+}
+
+// Reset clears all touch state
+func (t *TouchHandler) Reset() {
+	t.touches = t.touches[:0]
+	t.previous = t.previous[:0]
+	t.tapTime = 0
+	t.tapMoved = false
+}
+
+// distance returns the screen distance between two touch points
+func distance(a, b TouchPoint) float32 {
+	return float32(math.Hypot(float64(a.X-b.X), float64(a.Y-b.Y)))
+}
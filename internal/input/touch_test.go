@@ -0,0 +1,149 @@
+//go:build !js
+
+package input
+
+import (
+	"testing"
+
+	rl "github.com/gen2brain/raylib-go/raylib"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestTouchHandler_Tap(t *testing.T) {
+	t.Run("Short still touch taps where it lifted", func(t *testing.T) {
+		handler := NewTouchHandler()
+		handler.SetTouches([]TouchPoint{{ID: 1, X: 100, Y: 200}})
+		assert.False(t, handler.ProcessGestures(0.016, 0, 0.01).Tap)
+		handler.SetTouches([]TouchPoint{{ID: 1, X: 103, Y: 201}})
+		assert.False(t, handler.ProcessGestures(0.016, 0, 0.01).Tap)
+
+		handler.SetTouches(nil)
+		gestures := handler.ProcessGestures(0.016, 0, 0.01)
+		assert.True(t, gestures.Tap)
+		assert.Equal(t, float32(103), gestures.TapX)
+		assert.Equal(t, float32(201), gestures.TapY)
+	})
+
+	t.Run("Long touch is not a tap", func(t *testing.T) {
+		handler := NewTouchHandler()
+		handler.SetTouches([]TouchPoint{{ID: 1, X: 100, Y: 200}})
+		handler.ProcessGestures(0.016, 0, 0.01)
+		for i := 0; i < 30; i++ {
+			handler.SetTouches([]TouchPoint{{ID: 1, X: 100, Y: 200}})
+			handler.ProcessGestures(0.016, 0, 0.01)
+		}
+		handler.SetTouches(nil)
+		assert.False(t, handler.ProcessGestures(0.016, 0, 0.01).Tap)
+	})
+
+	t.Run("Drag is not a tap", func(t *testing.T) {
+		handler := NewTouchHandler()
+		handler.SetTouches([]TouchPoint{{ID: 1, X: 100, Y: 200}})
+		handler.ProcessGestures(0.016, 0, 0.01)
+		handler.SetTouches([]TouchPoint{{ID: 1, X: 150, Y: 200}})
+		handler.ProcessGestures(0.016, 0, 0.01)
+		handler.SetTouches(nil)
+		assert.False(t, handler.ProcessGestures(0.016, 0, 0.01).Tap)
+	})
+}
+
+func TestTouchHandler_Drag(t *testing.T) {
+	handler := NewTouchHandler()
+	handler.SetTouches([]TouchPoint{{ID: 1, X: 100, Y: 200}})
+	assert.False(t, handler.ProcessGestures(0.016, 0, 0.01).LookActive)
+
+	t.Run("Small movement stays a possible tap", func(t *testing.T) {
+		handler.SetTouches([]TouchPoint{{ID: 1, X: 105, Y: 200}})
+		assert.False(t, handler.ProcessGestures(0.016, 0, 0.01).LookActive)
+	})
+
+	t.Run("Movement past the tap limit looks around", func(t *testing.T) {
+		handler.SetTouches([]TouchPoint{{ID: 1, X: 125, Y: 190}})
+		gestures := handler.ProcessGestures(0.016, 0, 0.01)
+		assert.True(t, gestures.LookActive)
+		assert.InDelta(t, 0.2, gestures.YawDelta, 1e-6)
+		assert.InDelta(t, 0.1, gestures.PitchDelta, 1e-6)
+	})
+
+	t.Run("Pitch is clamped", func(t *testing.T) {
+		handler.SetTouches([]TouchPoint{{ID: 1, X: 125, Y: 0}})
+		gestures := handler.ProcessGestures(0.016, 1.4, 0.01)
+		assert.InDelta(t, 0.1, gestures.PitchDelta, 1e-6)
+	})
+}
+
+func TestTouchHandler_Pinch(t *testing.T) {
+	handler := NewTouchHandler()
+	handler.SetTouches([]TouchPoint{{ID: 1, X: 100, Y: 100}, {ID: 2, X: 200, Y: 100}})
+	assert.Zero(t, handler.ProcessGestures(0.016, 0, 0.01).Zoom)
+
+	t.Run("Spreading fingers zooms in", func(t *testing.T) {
+		handler.SetTouches([]TouchPoint{{ID: 1, X: 90, Y: 100}, {ID: 2, X: 210, Y: 100}})
+		assert.InDelta(t, 20*PinchZoomSpeed, handler.ProcessGestures(0.016, 0, 0.01).Zoom, 1e-5)
+	})
+
+	t.Run("Closing fingers zooms out", func(t *testing.T) {
+		handler.SetTouches([]TouchPoint{{ID: 1, X: 100, Y: 100}, {ID: 2, X: 200, Y: 100}})
+		assert.Less(t, handler.ProcessGestures(0.016, 0, 0.01).Zoom, float32(0))
+	})
+
+	t.Run("Lifting a finger neither looks nor taps", func(t *testing.T) {
+		handler.SetTouches([]TouchPoint{{ID: 2, X: 200, Y: 100}})
+		assert.False(t, handler.ProcessGestures(0.016, 0, 0.01).LookActive)
+		handler.SetTouches(nil)
+		assert.False(t, handler.ProcessGestures(0.016, 0, 0.01).Tap)
+	})
+}
+
+func TestInputController_Touch(t *testing.T) {
+	camera := &rl.Camera3D{
+		Position: rl.NewVector3(0, 0, 0),
+		Target:   rl.NewVector3(1, 0, 0),
+		Up:       rl.NewVector3(0, 1, 0),
+		Fovy:     45,
+	}
+	config := &InputConfig{
+		MouseSensitivity: 0.01,
+		ScreenWidth:      800,
+		ScreenHeight:     600,
+		TouchEnabled:     true,
+		FrameTime:        0.016,
+	}
+
+	t.Run("Pinch moves the camera along the view direction", func(t *testing.T) {
+		controller := NewInputController()
+		state := &SimulationState{}
+		controller.touch.SetTouches([]TouchPoint{{ID: 1, X: 100, Y: 100}, {ID: 2, X: 200, Y: 100}})
+		controller.ProcessInput(camera, state, config)
+		controller.touch.SetTouches([]TouchPoint{{ID: 1, X: 80, Y: 100}, {ID: 2, X: 220, Y: 100}})
+		controller.ProcessInput(camera, state, config)
+
+		assert.InDelta(t, 40*PinchZoomSpeed, camera.Position.X, 1e-5)
+		assert.InDelta(t, 40*PinchZoomSpeed+1, camera.Target.X, 1e-5)
+	})
+
+	t.Run("Tap requests a spawn", func(t *testing.T) {
+		controller := NewInputController()
+		state := &SimulationState{}
+		controller.touch.SetTouches([]TouchPoint{{ID: 1, X: 300, Y: 250}})
+		controller.ProcessInput(camera, state, config)
+		controller.touch.SetTouches(nil)
+		controller.ProcessInput(camera, state, config)
+
+		assert.True(t, state.Spawn)
+		assert.Equal(t, float32(300), state.SpawnX)
+		assert.Equal(t, float32(250), state.SpawnY)
+	})
+
+	t.Run("Touch is ignored when disabled", func(t *testing.T) {
+		controller := NewInputController()
+		state := &SimulationState{}
+		disabled := *config
+		disabled.TouchEnabled = false
+		controller.mouse.SetButtonDown(rl.MouseRightButton, true)
+		controller.touch.SetTouches([]TouchPoint{{ID: 1, X: 300, Y: 250}})
+		controller.touch.SetTouches(nil)
+		controller.ProcessInput(camera, state, &disabled)
+		assert.False(t, state.Spawn)
+	})
+}
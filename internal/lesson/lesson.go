@@ -0,0 +1,210 @@
+package lesson
+
+import (
+	"math"
+	"math/cmplx"
+	"relativity_simulation_2d/internal/physics"
+	"relativity_simulation_2d/pkg/fft"
+)
+
+// Stage is one stage of a particle-mesh step
+type Stage int
+
+// Stages of a particle-mesh step, in the order a step runs them
+const (
+	StageDeposit Stage = iota
+	StageFFT
+	StageGreen
+	StageGradient
+	StageKickDrift
+)
+
+// Stages lists every stage in the order a step runs them
+var Stages = []Stage{StageDeposit, StageFFT, StageGreen, StageGradient, StageKickDrift}
+
+// Title names the stage
+func (s Stage) Title() string {
+	switch s {
+	case StageDeposit:
+		return "Mass deposition"
+	case StageFFT:
+		return "Fourier transform"
+	case StageGreen:
+		return "Green's function"
+	case StageGradient:
+		return "Gradient"
+	case StageKickDrift:
+		return "Kick and drift"
+	default:
+		return "Unknown"
+	}
+}
+
+// Annotation explains the stage in a few short lines for a classroom
+func (s Stage) Annotation() []string {
+	switch s {
+	case StageDeposit:
+		return []string{
+			"Each particle shares its mass among the 4 nearest grid cells,",
+			"more to the closer ones (cloud-in-cell). The grid now holds",
+			"the mass density rho, which the field equations can work on.",
+		}
+	case StageFFT:
+		return []string{
+			"The FFT rewrites rho as a sum of waves. Each pixel is one wave:",
+			"long waves at the center, short ones at the edges. Bright means",
+			"that wavelength carries much of the mass distribution.",
+		}
+	case StageGreen:
+		return []string{
+			"For a single wave, Poisson's equation is simple algebra:",
+			"phi(k) = -4 pi G rho(k) / k^2. Long waves dominate the potential.",
+			"An inverse FFT turns the waves back into the potential phi,",
+			"the depth of the spacetime grid.",
+		}
+	case StageGradient:
+		return []string{
+			"Gravity pulls downhill: the acceleration a = -grad phi is the",
+			"slope of the potential, taken from the neighboring cells.",
+			"Bright cells are where the grid is steepest.",
+		}
+	case StageKickDrift:
+		return []string{
+			"Each particle reads the acceleration at its position, changes",
+			"its velocity by a dt (kick), then moves by v dt (drift).",
+			"The new positions start the next step.",
+		}
+	default:
+		return nil
+	}
+}
+
+// Legend describes the field drawn for the stage
+func (s Stage) Legend() string {
+	switch s {
+	case StageDeposit:
+		return "rho: mass per cell"
+	case StageFFT:
+		return "|rho(k)|: log scale, k = 0 at the center"
+	case StageGreen:
+		return "|phi(k)| = |G(k) rho(k)|: log scale, k = 0 at the center"
+	case StageGradient:
+		return "|a| = |grad phi|"
+	case StageKickDrift:
+		return "|a| with the particles and their velocities"
+	default:
+		return ""
+	}
+}
+
+// Fields are the intermediate fields of one particle-mesh step, each on
+// the width×height grid
+type Fields struct {
+	Density           physics.Grid // Mass per cell after cloud-in-cell deposition
+	Spectrum          physics.Grid // log(1 + |ρ̂|), zero frequency centered
+	PotentialSpectrum physics.Grid // log(1 + |Φ̂|) with Φ̂ = G(k) ρ̂, zero frequency centered
+	Potential         physics.Grid // Φ after the inverse transform
+	Acceleration      physics.Grid // |a| = |∇Φ|
+}
+
+// Compute runs the stages of a particle-mesh step on the particles, keeping
+// each intermediate field. It solves on the CPU whatever the compute mode,
+// so the fields are the textbook ones. The grid is the simulation's mesh
+func Compute(particles []*physics.Particle, width, height int, mesh *physics.Mesh, gravitationalConstant float64) Fields {
+	dx := mesh.Dx()
+	f := Fields{Density: physics.DepositMassToGrid(particles, width, height, mesh)}
+
+	transform := fft.FFT2Real(f.Density)
+	f.Spectrum = centeredMagnitude(transform)
+	for u := range transform {
+		for v := range transform[u] {
+			transform[u][v] *= complex(physics.GreensFunction(u, v, width, height, dx, gravitationalConstant), 0)
+		}
+	}
+	f.PotentialSpectrum = centeredMagnitude(transform)
+	f.Potential = fft.IFFT2Real(transform)
+
+	field := physics.CalculateGradient(f.Potential, width, height, dx)
+	defer field.Release()
+	f.Acceleration = physics.NewGrid(width, height)
+	for i := range f.Acceleration {
+		for j := range f.Acceleration[i] {
+			f.Acceleration[i][j] = math.Hypot(field.AccelFieldX.AtUnchecked(i, j), field.AccelFieldZ.AtUnchecked(i, j))
+		}
+	}
+	return f
+}
+
+// Field returns the field drawn for the stage
+func (f Fields) Field(s Stage) physics.Grid {
+	switch s {
+	case StageDeposit:
+		return f.Density
+	case StageFFT:
+		return f.Spectrum
+	case StageGreen:
+		return f.PotentialSpectrum
+	default:
+		return f.Acceleration
+	}
+}
+
+// centeredMagnitude returns log(1 + |c|) of a transform with the zero
+// frequency moved to the center, as spectra are usually drawn
+func centeredMagnitude(c [][]complex128) physics.Grid {
+	width := len(c)
+	if width == 0 {
+		return nil
+	}
+	height := len(c[0])
+	g := physics.NewGrid(width, height)
+	for u := range c {
+		for v := range c[u] {
+			g[(u+width/2)%width][(v+height/2)%height] = math.Log1p(cmplx.Abs(c[u][v]))
+		}
+	}
+	return g
+}
+
+// Range returns the smallest and largest value of the grid
+func Range(g physics.Grid) (lo, hi float64) {
+	lo, hi = math.Inf(1), math.Inf(-1)
+	for _, row := range g {
+		for _, v := range row {
+			lo, hi = math.Min(lo, v), math.Max(hi, v)
+		}
+	}
+	if lo > hi {
+		return 0, 0
+	}
+	return lo, hi
+}
+
+// Downsample averages the grid over square blocks so it has at most cells
+// cells per side, for drawing a large grid as a small panel
+func Downsample(g physics.Grid, cells int) physics.Grid {
+	width := len(g)
+	if width == 0 || cells <= 0 {
+		return g
+	}
+	height := len(g[0])
+	block := max((width+cells-1)/cells, (height+cells-1)/cells)
+	if block <= 1 {
+		return g
+	}
+	out := physics.NewGrid((width+block-1)/block, (height+block-1)/block)
+	for i := range out {
+		for j := range out[i] {
+			var sum float64
+			var n int
+			for x := i * block; x < min((i+1)*block, width); x++ {
+				for z := j * block; z < min((j+1)*block, height); z++ {
+					sum += g[x][z]
+					n++
+				}
+			}
+			out[i][j] = sum / float64(n)
+		}
+	}
+	return out
+}
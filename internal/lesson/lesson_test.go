@@ -0,0 +1,102 @@
+package lesson
+
+import (
+	"math"
+	"relativity_simulation_2d/internal/physics"
+	"testing"
+)
+
+// TestStages tests that every stage has a title, an annotation and a legend
+func TestStages(t *testing.T) {
+	for _, s := range Stages {
+		if s.Title() == "Unknown" || len(s.Annotation()) == 0 || s.Legend() == "" {
+			t.Errorf("Stage %d lacks a title, annotation or legend", s)
+		}
+	}
+}
+
+// TestCompute tests the fields of a point mass on the center cell: its mass
+// on the grid, a flat spectrum, a potential well at the mass and no force
+// there
+func TestCompute(t *testing.T) {
+	const width, height = 32, 16
+	particles := []*physics.Particle{physics.NewParticle(5, 0, 0, 0, 0, 0, 0)}
+	f := Compute(particles, width, height, nil, 1)
+
+	var mass float64
+	for _, row := range f.Density {
+		for _, v := range row {
+			mass += v
+		}
+	}
+	if math.Abs(mass-5) > 1e-9 {
+		t.Errorf("Expected the deposited mass to be 5, got %g", mass)
+	}
+	// A point mass holds every wave, so the centered zero frequency equals
+	// the others
+	if math.Abs(f.Spectrum[width/2][height/2]-math.Log1p(5)) > 1e-9 || math.Abs(f.Spectrum[0][0]-math.Log1p(5)) > 1e-9 {
+		t.Errorf("Expected a flat spectrum of log(1+5), got %g at k = 0 and %g at the corner", f.Spectrum[width/2][height/2], f.Spectrum[0][0])
+	}
+	if f.PotentialSpectrum[width/2][height/2] != 0 {
+		t.Errorf("Expected the mean potential to be dropped, got %g", f.PotentialSpectrum[width/2][height/2])
+	}
+
+	want := physics.SolvePoissonFFT(f.Density, width, height, 1, 1)
+	lo, _ := Range(f.Potential)
+	if math.Abs(f.Potential[16][8]-want[16][8]) > 1e-9 || f.Potential[16][8] != lo {
+		t.Errorf("Expected the solver's potential with its well at the mass, got %g (solver %g, lowest %g)", f.Potential[16][8], want[16][8], lo)
+	}
+	if f.Acceleration[16][8] > 1e-9 || f.Acceleration[18][8] <= 0 {
+		t.Errorf("Expected no force at the mass and a pull beside it, got %g and %g", f.Acceleration[16][8], f.Acceleration[18][8])
+	}
+	if f.Field(StageKickDrift)[18][8] != f.Acceleration[18][8] {
+		t.Error("Expected the kick and drift stage to show the acceleration")
+	}
+}
+
+// TestDownsample tests that blocks are averaged, including partial ones
+func TestDownsample(t *testing.T) {
+	g := physics.NewGrid(5, 4)
+	for i := range g {
+		for j := range g[i] {
+			g[i][j] = float64(i)
+		}
+	}
+	d := Downsample(g, 2)
+	if len(d) != 2 || len(d[0]) != 2 {
+		t.Fatalf("Expected a 2x2 grid, got %dx%d", len(d), len(d[0]))
+	}
+	if d[0][0] != 1 || d[1][1] != 3.5 {
+		t.Errorf("Expected block means 1 and 3.5, got %g and %g", d[0][0], d[1][1])
+	}
+	if Downsample(g, 8)[4][3] != 4 {
+		t.Error("Expected a small grid to be kept")
+	}
+}
+
+// TestPlayer tests that stages follow each other and wrap around
+func TestPlayer(t *testing.T) {
+	p := NewPlayer(2)
+	if p.Stage() != StageDeposit || p.Number() != 1 {
+		t.Fatalf("Expected to start at the first stage, got %v", p.Stage())
+	}
+	if p.Advance(1.5) || p.Progress() != 0.75 {
+		t.Errorf("Expected to stay in the first stage 75%% through, got %v at %g", p.Stage(), p.Progress())
+	}
+	if !p.Advance(10) || p.Stage() != StageFFT || p.Progress() != 0 {
+		t.Errorf("Expected a long frame to move on one stage only, got %v", p.Stage())
+	}
+	for range Stages[2:] {
+		p.Next()
+	}
+	if p.Stage() != StageKickDrift {
+		t.Errorf("Expected the last stage, got %v", p.Stage())
+	}
+	p.Next()
+	if p.Stage() != StageDeposit {
+		t.Errorf("Expected the lesson to start over, got %v", p.Stage())
+	}
+	if NewPlayer(0).Advance(DefaultStageDuration - 0.1) {
+		t.Error("Expected the default duration when none is set")
+	}
+}
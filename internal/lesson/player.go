@@ -0,0 +1,58 @@
+package lesson
+
+// DefaultStageDuration is the number of seconds each stage is shown
+const DefaultStageDuration = 4.0
+
+// Player shows the stages one after another, each for a fixed time. The
+// simulation takes one step per pass, at the kick and drift stage, so the
+// fields on screen are always those of the step being explained
+type Player struct {
+	StageDuration float64 // Seconds each stage is shown (0 = DefaultStageDuration)
+	stage         int     // Index into Stages
+	elapsed       float64 // Seconds the current stage has been shown
+}
+
+// NewPlayer creates a player at the first stage
+func NewPlayer(stageDuration float64) *Player {
+	return &Player{StageDuration: stageDuration}
+}
+
+// Stage returns the stage shown
+func (p *Player) Stage() Stage {
+	return Stages[p.stage]
+}
+
+// Number returns the position of the stage shown, from 1
+func (p *Player) Number() int {
+	return p.stage + 1
+}
+
+// Progress returns the fraction of the current stage shown so far
+func (p *Player) Progress() float64 {
+	return min(p.elapsed/p.duration(), 1)
+}
+
+// Advance moves the lesson on by dt seconds, at most to the next stage.
+// entered reports whether that stage was entered
+func (p *Player) Advance(dt float64) (entered bool) {
+	p.elapsed += dt
+	if p.elapsed < p.duration() {
+		return false
+	}
+	p.Next()
+	return true
+}
+
+// Next moves on to the next stage, back to the first after the last
+func (p *Player) Next() {
+	p.stage = (p.stage + 1) % len(Stages)
+	p.elapsed = 0
+}
+
+// duration returns the seconds each stage is shown
+func (p *Player) duration() float64 {
+	if p.StageDuration > 0 {
+		return p.StageDuration
+	}
+	return DefaultStageDuration
+}
@@ -0,0 +1,144 @@
+package output
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"relativity_simulation_2d/internal/config"
+	"time"
+)
+
+// Subdirectories of a run directory, one per kind of output
+const (
+	LogsDir        = "logs"        // Diagnostics, progress, binary events, soak samples, crash, guard and soak reports
+	SnapshotsDir   = "snapshots"   // Checkpoints, N-body snapshots and replay frames
+	ExportsDir     = "exports"     // Meshes, flow maps, profiles and rendered images
+	ScreenshotsDir = "screenshots" // Screenshots of the window
+)
+
+// Kinds are the subdirectories created in every run directory
+var Kinds = []string{LogsDir, SnapshotsDir, ExportsDir, ScreenshotsDir}
+
+// ConfigFile is the copy of the run's configuration in its directory, which
+// -config loads to repeat the run
+const ConfigFile = "config.json"
+
+// LatestLink is the link in the root directory to the newest run
+const LatestLink = "latest"
+
+// runTimeFormat names run directories by their start time
+const runTimeFormat = "20060102-150405"
+
+// Manager keeps the outputs of one run in its own directory, so repeated
+// runs don't overwrite each other's files. A nil Manager leaves outputs where
+// they are configured
+type Manager struct {
+	Root string // Directory holding the run directories
+	Dir  string // This run's directory
+}
+
+// NewRun creates the directory of a run started at start inside root, named
+// by the start time with a number appended if that is taken, and its
+// subdirectories
+func NewRun(root string, start time.Time) (*Manager, error) {
+	if err := os.MkdirAll(root, 0o755); err != nil {
+		return nil, fmt.Errorf("failed to create run root directory: %v", err)
+	}
+	name := start.Format(runTimeFormat)
+	dir := filepath.Join(root, name)
+	for n := 2; ; n++ {
+		err := os.Mkdir(dir, 0o755)
+		if err == nil {
+			break
+		}
+		if !errors.Is(err, fs.ErrExist) {
+			return nil, fmt.Errorf("failed to create run directory: %v", err)
+		}
+		dir = filepath.Join(root, fmt.Sprintf("%s-%d", name, n))
+	}
+	for _, kind := range Kinds {
+		if err := os.Mkdir(filepath.Join(dir, kind), 0o755); err != nil {
+			return nil, fmt.Errorf("failed to create run directory: %v", err)
+		}
+	}
+	return &Manager{Root: root, Dir: dir}, nil
+}
+
+// LinkLatest points the root's LatestLink at this run, replacing the link
+// to the previous one
+func (m *Manager) LinkLatest() error {
+	link := filepath.Join(m.Root, LatestLink)
+	temp := link + ".tmp"
+	_ = os.Remove(temp)
+	if err := os.Symlink(filepath.Base(m.Dir), temp); err != nil {
+		return fmt.Errorf("failed to link the latest run: %v", err)
+	}
+	if err := os.Rename(temp, link); err != nil {
+		_ = os.Remove(temp)
+		return fmt.Errorf("failed to link the latest run: %v", err)
+	}
+	return nil
+}
+
+// WriteConfig writes c to the run's ConfigFile
+func (m *Manager) WriteConfig(c *config.Config) error {
+	data, err := json.MarshalIndent(c, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to encode run config: %v", err)
+	}
+	if err := os.WriteFile(filepath.Join(m.Dir, ConfigFile), append(data, '\n'), 0o644); err != nil {
+		return fmt.Errorf("failed to write run config: %v", err)
+	}
+	return nil
+}
+
+// Path returns where the run keeps an output of the given kind at path:
+// relative paths go into the kind's subdirectory, absolute paths are kept
+func (m *Manager) Path(kind, path string) string {
+	if m == nil || path == "" || filepath.IsAbs(path) {
+		return path
+	}
+	return filepath.Join(m.Dir, kind, path)
+}
+
+// Redirect moves the outputs configured in c into the run directory with
+// Path. Outputs that are off stay off
+func (m *Manager) Redirect(c *config.Config) {
+	outputs := []struct {
+		kind string
+		path *string
+	}{
+		{LogsDir, &c.DiagnosticsPath},
+		{LogsDir, &c.ProgressPath},
+		{LogsDir, &c.BinaryLogPath},
+		{LogsDir, &c.CrashReportDir},
+		{LogsDir, &c.SoakPath},
+		{SnapshotsDir, &c.CheckpointPath},
+		{SnapshotsDir, &c.NBodyPath},
+		{SnapshotsDir, &c.RecordDir},
+		{ExportsDir, &c.MeshPath},
+		{ExportsDir, &c.FlowDir},
+		{ExportsDir, &c.ProfilePath},
+		{ExportsDir, &c.RenderOut},
+	}
+	for _, o := range outputs {
+		*o.path = m.Path(o.kind, *o.path)
+	}
+}
+
+// Unique returns path, or path with "-2", "-3" and so on before its
+// extension if a file already exists there. Paths it cannot check count as
+// free, leaving the error to creating the file
+func Unique(path string) string {
+	ext := filepath.Ext(path)
+	base := path[:len(path)-len(ext)]
+	for n := 2; ; n++ {
+		if _, err := os.Lstat(path); err != nil {
+			return path
+		}
+		path = fmt.Sprintf("%s-%d%s", base, n, ext)
+	}
+}
@@ -0,0 +1,115 @@
+package output
+
+import (
+	"os"
+	"path/filepath"
+	"relativity_simulation_2d/internal/config"
+	"testing"
+	"time"
+)
+
+// TestNewRun tests that runs get their own directories with the output
+// subdirectories, and that the latest link follows the newest
+func TestNewRun(t *testing.T) {
+	root := filepath.Join(t.TempDir(), "runs")
+	start := time.Date(2026, 10, 18, 15, 30, 45, 0, time.Local)
+	first, err := NewRun(root, start)
+	if err != nil {
+		t.Fatalf("NewRun failed: %v", err)
+	}
+	if want := filepath.Join(root, "20261018-153045"); first.Dir != want {
+		t.Errorf("Expected the run in %s, got %s", want, first.Dir)
+	}
+	for _, kind := range Kinds {
+		if info, err := os.Stat(filepath.Join(first.Dir, kind)); err != nil || !info.IsDir() {
+			t.Errorf("Expected the %s subdirectory (%v)", kind, err)
+		}
+	}
+
+	second, err := NewRun(root, start)
+	if err != nil {
+		t.Fatalf("NewRun failed for a second run in the same second: %v", err)
+	}
+	if second.Dir != first.Dir+"-2" {
+		t.Errorf("Expected the second run numbered, got %s", second.Dir)
+	}
+
+	for _, m := range []*Manager{first, second} {
+		if err := m.LinkLatest(); err != nil {
+			t.Skipf("Symbolic links unavailable: %v", err)
+		}
+	}
+	if target, err := os.Readlink(filepath.Join(root, LatestLink)); err != nil || target != filepath.Base(second.Dir) {
+		t.Errorf("Expected latest to link the second run, got %q (%v)", target, err)
+	}
+}
+
+// TestWriteConfig tests that the run's config copy loads as a config file
+func TestWriteConfig(t *testing.T) {
+	m, err := NewRun(t.TempDir(), time.Now())
+	if err != nil {
+		t.Fatal(err)
+	}
+	cfg := config.DefaultConfig()
+	cfg.NumParticles = 1234
+	if err := m.WriteConfig(cfg); err != nil {
+		t.Fatalf("WriteConfig failed: %v", err)
+	}
+	loaded := config.DefaultConfig()
+	if err := loaded.LoadFile(filepath.Join(m.Dir, ConfigFile)); err != nil {
+		t.Fatalf("The config copy does not load: %v", err)
+	}
+	if *loaded != *cfg {
+		t.Error("Expected the config copy to load as the same configuration")
+	}
+}
+
+// TestRedirect tests moving relative output paths into the run directory
+// by kind, keeping absolute paths and outputs that are off
+func TestRedirect(t *testing.T) {
+	m := &Manager{Root: "runs", Dir: filepath.Join("runs", "20261018-153045")}
+	absolute := filepath.Join(t.TempDir(), "final.rsim")
+	cfg := config.DefaultConfig()
+	cfg.DiagnosticsPath = "diagnostics.csv"
+	cfg.MeshPath = filepath.Join("meshes", "grid.obj")
+	cfg.CheckpointPath = absolute
+	cfg.FlowDir = ""
+	m.Redirect(cfg)
+
+	tests := []struct {
+		name, got, want string
+	}{
+		{"diagnostics", cfg.DiagnosticsPath, filepath.Join(m.Dir, LogsDir, "diagnostics.csv")},
+		{"mesh", cfg.MeshPath, filepath.Join(m.Dir, ExportsDir, "meshes", "grid.obj")},
+		{"crash reports", cfg.CrashReportDir, filepath.Join(m.Dir, LogsDir, config.DefaultConfig().CrashReportDir)},
+		{"checkpoint", cfg.CheckpointPath, absolute},
+		{"flow maps", cfg.FlowDir, ""},
+	}
+	for _, tt := range tests {
+		if tt.got != tt.want {
+			t.Errorf("Expected the %s at %q, got %q", tt.name, tt.want, tt.got)
+		}
+	}
+
+	var none *Manager
+	if path := none.Path(ExportsDir, "mesh.obj"); path != "mesh.obj" {
+		t.Errorf("Expected no run to keep the path, got %q", path)
+	}
+}
+
+// TestUnique tests numbering paths that are taken
+func TestUnique(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "shot.png")
+	if got := Unique(path); got != path {
+		t.Errorf("Expected a free path kept, got %s", got)
+	}
+	for _, name := range []string{"shot.png", "shot-2.png"} {
+		if err := os.WriteFile(filepath.Join(dir, name), nil, 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if got := Unique(path); got != filepath.Join(dir, "shot-3.png") {
+		t.Errorf("Expected shot-3.png, got %s", got)
+	}
+}
@@ -0,0 +1,361 @@
+package physics
+
+import (
+	"math"
+	"sort"
+)
+
+// Binary detection defaults
+const (
+	DefaultBinaryInterval = 10 // Steps between detections
+	turningPointTolerance = 1e-12
+	circularTolerance     = 1e-4 // Orbits with Apocenter - Pericenter below this fraction of their size are circular
+	periodSamples         = 64   // Quadrature points of the radial period integral
+)
+
+// BinaryOptions configures binary detection. Lengths are physical, in the
+// units of the particle positions
+type BinaryOptions struct {
+	MaxSeparation         float64 // A pair is a binary if its orbit stays within this separation
+	Softening             float64 // Plummer softening length ε of the pair force
+	Width, Height         int     // Periodic domain in cells, so separations take the nearest image (0 = not periodic)
+	CellSize              float64 // Physical size of a cell (0 = 1)
+	GravitationalConstant float64
+}
+
+// periods returns the physical extents of the periodic domain (0 = not periodic)
+func (o BinaryOptions) periods() (x, z float64) {
+	dx := spacing(o.CellSize)
+	return float64(o.Width) * dx, float64(o.Height) * dx
+}
+
+// BinaryOrbit holds the elements of a pair's relative orbit in its isolated
+// two-body potential Φ = G·M·ln(r² + ε²) times the force scale of the steps.
+// The logarithmic potential has no closed Kepler orbits, so the elements are
+// those of the rosette the separation oscillates in
+type BinaryOrbit struct {
+	Separation      float64 // Current separation r
+	Pericenter      float64 // Smallest separation of the orbit
+	Apocenter       float64 // Largest separation of the orbit
+	SemiMajorAxis   float64 // (Pericenter + Apocenter) / 2
+	Eccentricity    float64 // (Apocenter - Pericenter) / (Apocenter + Pericenter)
+	Energy          float64 // Orbital energy μ(½v² + Φ(r)), up to the additive constant of the logarithm
+	AngularMomentum float64 // μ(d × v), positive counter-clockwise seen from +Y
+	Period          float64 // Radial period, pericenter to pericenter
+}
+
+// Binary is a bound pair of particles I < J
+type Binary struct {
+	I, J             int
+	Mass             float64 // Total mass of the pair
+	CenterX, CenterZ float64 // Center of mass of the pair
+	Orbit            BinaryOrbit
+}
+
+// FindBinaries returns the bound pairs closer than opts.MaxSeparation whose
+// relative orbit, in the pair's own potential, never leaves MaxSeparation.
+// Each particle belongs to at most one binary: pairs are taken tightest
+// (smallest apocenter) first. Binaries are ordered by I
+func FindBinaries(particles []*Particle, opts BinaryOptions) []Binary {
+	if !(opts.MaxSeparation > 0) || math.IsInf(opts.MaxSeparation, 0) {
+		return nil
+	}
+	var candidates []Binary
+	forEachClosePair(particles, opts, func(i, j int) {
+		if b, ok := boundPair(particles, i, j, opts); ok {
+			candidates = append(candidates, b)
+		}
+	})
+	sort.Slice(candidates, func(a, b int) bool {
+		if candidates[a].Orbit.Apocenter != candidates[b].Orbit.Apocenter {
+			return candidates[a].Orbit.Apocenter < candidates[b].Orbit.Apocenter
+		}
+		if candidates[a].I != candidates[b].I {
+			return candidates[a].I < candidates[b].I
+		}
+		return candidates[a].J < candidates[b].J
+	})
+
+	paired := make(map[int]bool)
+	var binaries []Binary
+	for _, b := range candidates {
+		if !paired[b.I] && !paired[b.J] {
+			paired[b.I], paired[b.J] = true, true
+			binaries = append(binaries, b)
+		}
+	}
+	sort.Slice(binaries, func(a, b int) bool { return binaries[a].I < binaries[b].I })
+	return binaries
+}
+
+// forEachClosePair calls fn(i, j), i < j, for every pair closer than
+// opts.MaxSeparation. On a periodic domain of at least three cells of that
+// size per axis it only compares particles in neighbouring cells
+func forEachClosePair(particles []*Particle, opts BinaryOptions, fn func(i, j int)) {
+	rmax := opts.MaxSeparation
+	periodX, periodZ := opts.periods()
+	near := func(i, j int) bool {
+		p, q := particles[i], particles[j]
+		dx := nearestImage(p.Position.X-q.Position.X, periodX)
+		dz := nearestImage(p.Position.Z-q.Position.Z, periodZ)
+		return dx*dx+dz*dz < rmax*rmax
+	}
+
+	nx, nz := int(periodX/rmax), int(periodZ/rmax)
+	if nx < 3 || nz < 3 {
+		for i := range particles {
+			for j := i + 1; j < len(particles); j++ {
+				if near(i, j) {
+					fn(i, j)
+				}
+			}
+		}
+		return
+	}
+
+	// Cells at least rmax wide, so close pairs are in the same or adjacent cells
+	cellOf := func(p *Particle) (int, int) {
+		x := wrapPeriodic(p.Position.X, periodX) + periodX/2
+		z := wrapPeriodic(p.Position.Z, periodZ) + periodZ/2
+		return min(int(x/periodX*float64(nx)), nx-1), min(int(z/periodZ*float64(nz)), nz-1)
+	}
+	cells := make(map[[2]int][]int)
+	for i, p := range particles {
+		cx, cz := cellOf(p)
+		cells[[2]int{cx, cz}] = append(cells[[2]int{cx, cz}], i)
+	}
+	for i, p := range particles {
+		cx, cz := cellOf(p)
+		for di := -1; di <= 1; di++ {
+			for dj := -1; dj <= 1; dj++ {
+				for _, j := range cells[[2]int{wrapIndex(cx+di, nx), wrapIndex(cz+dj, nz)}] {
+					if j > i && near(i, j) {
+						fn(i, j)
+					}
+				}
+			}
+		}
+	}
+}
+
+// boundPair returns particles i and j as a binary if their relative orbit
+// stays within opts.MaxSeparation. The effective potential
+// Φeff(r) = L²/2r² + k·ln(r² + ε²) has a single minimum, so the orbit, the
+// interval around r where Φeff ≤ E, ends inside MaxSeparation exactly when
+// Φeff(MaxSeparation) > E
+func boundPair(particles []*Particle, i, j int, opts BinaryOptions) (Binary, bool) {
+	p, q := particles[i], particles[j]
+	m1, m2 := float64(p.Mass), float64(q.Mass)
+	mass := m1 + m2
+	if p.IsTracer() || q.IsTracer() || !(mass > 0) { // A tracer near a mass is a test orbit, not a binary
+		return Binary{}, false
+	}
+	periodX, periodZ := opts.periods()
+	dx := nearestImage(q.Position.X-p.Position.X, periodX)
+	dz := nearestImage(q.Position.Z-p.Position.Z, periodZ)
+	vx, vz := q.Velocity.X-p.Velocity.X, q.Velocity.Z-p.Velocity.Z
+	r := math.Hypot(dx, dz)
+
+	orbit := relativeOrbit{
+		k:    opts.GravitationalConstant * pmForceScale * mass,
+		eps2: opts.Softening * opts.Softening,
+		l:    dx*vz - dz*vx,
+	}
+	orbit.energy = 0.5*(vx*vx+vz*vz) + orbit.potential(r)
+	if !(orbit.k > 0) || math.IsInf(orbit.energy, 0) || !(orbit.effective(opts.MaxSeparation) > orbit.energy) {
+		return Binary{}, false
+	}
+
+	rp, ra := orbit.turningPoints(r, opts.MaxSeparation)
+	mu := m1 * m2 / mass
+	b := Binary{
+		I:       i,
+		J:       j,
+		Mass:    mass,
+		CenterX: wrapPeriodic(p.Position.X+dx*m2/mass, periodX),
+		CenterZ: wrapPeriodic(p.Position.Z+dz*m2/mass, periodZ),
+		Orbit: BinaryOrbit{
+			Separation:      r,
+			Pericenter:      rp,
+			Apocenter:       ra,
+			SemiMajorAxis:   (rp + ra) / 2,
+			Energy:          mu * orbit.energy,
+			AngularMomentum: mu * orbit.l,
+			Period:          orbit.radialPeriod(rp, ra),
+		},
+	}
+	if rp+ra > 0 {
+		b.Orbit.Eccentricity = (ra - rp) / (ra + rp)
+	}
+	return b, true
+}
+
+// relativeOrbit is the relative motion of a pair: specific energy, specific
+// angular momentum l and coupling k = G·M times the force scale
+type relativeOrbit struct {
+	k, eps2, l, energy float64
+}
+
+// potential returns k·ln(r² + ε²)
+func (o relativeOrbit) potential(r float64) float64 {
+	return o.k * math.Log(r*r+o.eps2)
+}
+
+// effective returns the effective potential l²/2r² + Φ(r)
+func (o relativeOrbit) effective(r float64) float64 {
+	if r == 0 {
+		if o.l != 0 {
+			return math.Inf(1)
+		}
+		return o.potential(0)
+	}
+	return o.l*o.l/(2*r*r) + o.potential(r)
+}
+
+// turningPoints returns the pericenter and apocenter around the current
+// separation r, by bisection on Φeff = E within [0, r] and [r, rmax]
+func (o relativeOrbit) turningPoints(r, rmax float64) (rp, ra float64) {
+	rp = 0
+	if o.effective(0) > o.energy {
+		rp = o.bisect(0, r)
+	}
+	return rp, o.bisect(r, rmax)
+}
+
+// bisect returns where Φeff crosses E between a and b
+func (o relativeOrbit) bisect(a, b float64) float64 {
+	fa := o.effective(a) - o.energy
+	for b-a > turningPointTolerance*b {
+		mid := (a + b) / 2
+		fm := o.effective(mid) - o.energy
+		if (fm > 0) == (fa > 0) {
+			a, fa = mid, fm
+		} else {
+			b = mid
+		}
+	}
+	return (a + b) / 2
+}
+
+// radialPeriod returns 2∫ dr / √(2(E - Φeff)) from rp to ra. Substituting
+// r = c - h·cos θ removes the square-root singularities at the turning
+// points, leaving a smooth integrand for the midpoint rule. Nearly circular
+// orbits, whose turning points are lost in rounding, take the limit of the
+// integral, the epicyclic period 2π/κ
+func (o relativeOrbit) radialPeriod(rp, ra float64) float64 {
+	c, h := (ra+rp)/2, (ra-rp)/2
+	if h <= circularTolerance*c {
+		// κ² = Φeff''(c), by a central difference
+		dr := 1e-4 * c
+		kappa2 := (o.effective(c+dr) - 2*o.effective(c) + o.effective(c-dr)) / (dr * dr)
+		if !(kappa2 > 0) {
+			return 0
+		}
+		return 2 * math.Pi / math.Sqrt(kappa2)
+	}
+	sum := 0.0
+	for n := 0; n < periodSamples; n++ {
+		theta := math.Pi * (float64(n) + 0.5) / periodSamples
+		r := c - h*math.Cos(theta)
+		if kinetic := 2 * (o.energy - o.effective(r)); kinetic > 0 {
+			sum += h * math.Sin(theta) / math.Sqrt(kinetic)
+		}
+	}
+	return 2 * sum * math.Pi / periodSamples
+}
+
+// BinaryEventKind says whether a binary formed or was disrupted
+type BinaryEventKind int
+
+// Binary event kinds
+const (
+	BinaryFormed BinaryEventKind = iota
+	BinaryDisrupted
+)
+
+// String returns "formed" or "disrupted"
+func (k BinaryEventKind) String() string {
+	if k == BinaryDisrupted {
+		return "disrupted"
+	}
+	return "formed"
+}
+
+// BinaryEvent is the formation or disruption of a binary, detected at Step
+type BinaryEvent struct {
+	Kind     BinaryEventKind
+	Step     int64
+	Time     float64
+	Binary   Binary  // The elements at formation, or the last bound elements at disruption
+	Lifetime float64 // Time since formation, for disruptions
+}
+
+// BinaryTracker follows binaries across detections and reports when they
+// form and break up. Particles are identified by their index, so the
+// particle slice must only grow between updates
+type BinaryTracker struct {
+	opts      BinaryOptions
+	active    map[[2]int]trackedBinary
+	Formed    int // Binaries formed so far
+	Disrupted int // Binaries disrupted so far
+}
+
+// trackedBinary is an active binary and when it formed
+type trackedBinary struct {
+	binary     Binary
+	formedTime float64
+}
+
+// NewBinaryTracker creates a tracker detecting binaries with opts
+func NewBinaryTracker(opts BinaryOptions) *BinaryTracker {
+	return &BinaryTracker{opts: opts, active: make(map[[2]int]trackedBinary)}
+}
+
+// Update detects the current binaries and returns the disruptions, then the
+// formations, since the last update, each in order of I
+func (t *BinaryTracker) Update(particles []*Particle, step int64, time float64) []BinaryEvent {
+	current := make(map[[2]int]Binary)
+	for _, b := range FindBinaries(particles, t.opts) {
+		current[[2]int{b.I, b.J}] = b
+	}
+
+	var disrupted, formed []BinaryEvent
+	for key, tracked := range t.active {
+		if b, ok := current[key]; ok {
+			tracked.binary = b
+			t.active[key] = tracked
+			continue
+		}
+		disrupted = append(disrupted, BinaryEvent{Kind: BinaryDisrupted, Step: step, Time: time, Binary: tracked.binary, Lifetime: time - tracked.formedTime})
+		delete(t.active, key)
+	}
+	for key, b := range current {
+		if _, ok := t.active[key]; !ok {
+			t.active[key] = trackedBinary{binary: b, formedTime: time}
+			formed = append(formed, BinaryEvent{Kind: BinaryFormed, Step: step, Time: time, Binary: b})
+		}
+	}
+	byPair := func(events []BinaryEvent) {
+		sort.Slice(events, func(a, b int) bool {
+			if events[a].Binary.I != events[b].Binary.I {
+				return events[a].Binary.I < events[b].Binary.I
+			}
+			return events[a].Binary.J < events[b].Binary.J
+		})
+	}
+	byPair(disrupted)
+	byPair(formed)
+	t.Disrupted += len(disrupted)
+	t.Formed += len(formed)
+	return append(disrupted, formed...)
+}
+
+// Active returns the binaries found by the last update, in order of I
+func (t *BinaryTracker) Active() []Binary {
+	binaries := make([]Binary, 0, len(t.active))
+	for _, tracked := range t.active {
+		binaries = append(binaries, tracked.binary)
+	}
+	sort.Slice(binaries, func(a, b int) bool { return binaries[a].I < binaries[b].I })
+	return binaries
+}
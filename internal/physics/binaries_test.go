@@ -0,0 +1,173 @@
+package physics
+
+import (
+	"math"
+	"math/rand"
+	"testing"
+)
+
+// pair returns two unit-mass particles at (±d/2, 0) with relative velocity (0, v)
+func pair(d, v float64) []*Particle {
+	return []*Particle{
+		NewParticle(1, -d/2, 0, 0, 0, 0, -v/2),
+		NewParticle(1, d/2, 0, 0, 0, 0, v/2),
+	}
+}
+
+// TestFindBinariesCircular tests the elements of a circular pair, whose radial
+// period in the logarithmic potential is the epicyclic 2π/(√2 Ω)
+func TestFindBinariesCircular(t *testing.T) {
+	d := 2.0
+	v := math.Sqrt(2 * pmForceScale * 2) // v² = 2G·M times the force scale, G = 1
+	binaries := FindBinaries(pair(d, v), BinaryOptions{MaxSeparation: 5, GravitationalConstant: 1})
+	if len(binaries) != 1 {
+		t.Fatalf("Expected one binary, got %d", len(binaries))
+	}
+	orbit := binaries[0].Orbit
+	if math.Abs(orbit.SemiMajorAxis-d) > 1e-6 || orbit.Eccentricity > 1e-6 {
+		t.Errorf("Expected a circular orbit of radius %g, got a=%g e=%g", d, orbit.SemiMajorAxis, orbit.Eccentricity)
+	}
+	if want := 2 * math.Pi / (math.Sqrt2 * v / d); math.Abs(orbit.Period-want) > 1e-3*want {
+		t.Errorf("Expected period %g, got %g", want, orbit.Period)
+	}
+	if binaries[0].CenterX != 0 || binaries[0].Mass != 2 {
+		t.Errorf("Expected mass 2 centered at the origin, got %g at %g", binaries[0].Mass, binaries[0].CenterX)
+	}
+}
+
+// TestFindBinariesEccentric tests the turning points and radial period
+// against a direct integration of the relative orbit
+func TestFindBinariesEccentric(t *testing.T) {
+	d, v, softening := 2.0, 0.6, 0.3
+	opts := BinaryOptions{MaxSeparation: 10, Softening: softening, GravitationalConstant: 1}
+	binaries := FindBinaries(pair(d, v), opts)
+	if len(binaries) != 1 {
+		t.Fatalf("Expected one binary, got %d", len(binaries))
+	}
+	orbit := binaries[0].Orbit
+
+	// Leapfrog the separation under a = -G·M·d/(r² + ε²), recording the
+	// extreme separations and the times of pericenter passage
+	x, z, vx, vz := d, 0.0, 0.0, v
+	dt := 1e-4
+	accel := func() (float64, float64) {
+		c := -2 * pmForceScale * 2 / (x*x + z*z + softening*softening)
+		return c * x, c * z
+	}
+	rMin, rMax := d, d
+	var pericenters []float64
+	lastRadial := 0.0
+	for step := 0; len(pericenters) < 2 && step < 1e6; step++ {
+		ax, az := accel()
+		vx, vz = vx+ax*dt/2, vz+az*dt/2
+		x, z = x+vx*dt, z+vz*dt
+		ax, az = accel()
+		vx, vz = vx+ax*dt/2, vz+az*dt/2
+		r := math.Hypot(x, z)
+		rMin, rMax = math.Min(rMin, r), math.Max(rMax, r)
+		radial := x*vx + z*vz
+		if lastRadial < 0 && radial >= 0 {
+			pericenters = append(pericenters, float64(step)*dt)
+		}
+		lastRadial = radial
+	}
+	if len(pericenters) < 2 {
+		t.Fatal("Expected two pericenter passages")
+	}
+	if math.Abs(orbit.Pericenter-rMin) > 1e-3 || math.Abs(orbit.Apocenter-rMax) > 1e-3 {
+		t.Errorf("Expected turning points %g and %g, got %g and %g", rMin, rMax, orbit.Pericenter, orbit.Apocenter)
+	}
+	if period := pericenters[1] - pericenters[0]; math.Abs(orbit.Period-period) > 1e-3*period {
+		t.Errorf("Expected radial period %g, got %g", period, orbit.Period)
+	}
+}
+
+// TestFindBinariesTightest tests that an unbound pair is skipped and that a
+// particle joins only its tightest partner
+func TestFindBinariesTightest(t *testing.T) {
+	if binaries := FindBinaries(pair(2, 5), BinaryOptions{MaxSeparation: 5, GravitationalConstant: 1}); len(binaries) != 0 {
+		t.Errorf("Expected a fast pair to escape, got %+v", binaries)
+	}
+
+	particles := append(pair(1, 0), NewParticle(1, 2, 0, 0, 0, 0, 0))
+	binaries := FindBinaries(particles, BinaryOptions{MaxSeparation: 5, GravitationalConstant: 1})
+	if len(binaries) != 1 || binaries[0].I != 0 || binaries[0].J != 1 {
+		t.Errorf("Expected only the pair 0-1, got %+v", binaries)
+	}
+}
+
+// TestForEachClosePairCells tests that the cell search finds the same pairs
+// as comparing all of them, across the periodic edges
+func TestForEachClosePairCells(t *testing.T) {
+	rng := rand.New(rand.NewSource(4))
+	particles := make([]*Particle, 500)
+	for i := range particles {
+		particles[i] = NewParticle(1, rng.Float64()*64-32, 0, rng.Float64()*64-32, 0, 0, 0)
+	}
+	opts := BinaryOptions{MaxSeparation: 3, Width: 64, Height: 64}
+
+	found := make(map[[2]int]bool)
+	forEachClosePair(particles, opts, func(i, j int) { found[[2]int{i, j}] = true })
+	want := 0
+	for i := range particles {
+		for j := i + 1; j < len(particles); j++ {
+			dx := nearestImage(particles[i].Position.X-particles[j].Position.X, 64)
+			dz := nearestImage(particles[i].Position.Z-particles[j].Position.Z, 64)
+			if math.Hypot(dx, dz) < 3 {
+				want++
+				if !found[[2]int{i, j}] {
+					t.Errorf("Missed pair %d-%d", i, j)
+				}
+			}
+		}
+	}
+	if len(found) != want {
+		t.Errorf("Expected %d pairs, found %d", want, len(found))
+	}
+}
+
+// TestFindBinariesCellSize tests that a pair straddling the edge of a domain
+// of half-size cells is found across the wrap, with its center wrapped into
+// the physical domain
+func TestFindBinariesCellSize(t *testing.T) {
+	particles := pair(0.2, 0)
+	particles[0].Position.X = wrapPeriodic(particles[0].Position.X+16, 32)
+	particles[1].Position.X = wrapPeriodic(particles[1].Position.X+16, 32)
+	opts := BinaryOptions{MaxSeparation: 1, Width: 64, Height: 64, CellSize: 0.5, GravitationalConstant: 1}
+	binaries := FindBinaries(particles, opts)
+	if len(binaries) != 1 {
+		t.Fatalf("Expected one binary across the wrap, got %d", len(binaries))
+	}
+	if r := binaries[0].Orbit.Separation; math.Abs(r-0.2) > 1e-9 {
+		t.Errorf("Expected separation 0.2, got %g", r)
+	}
+	if c := binaries[0].CenterX; math.Abs(c) > 16 || math.Abs(nearestImage(c-16, 32)) > 1e-9 {
+		t.Errorf("Expected the center on the edge at x=±16, got %g", c)
+	}
+}
+
+// TestBinaryTracker tests formation and disruption events
+func TestBinaryTracker(t *testing.T) {
+	particles := pair(2, 0.5)
+	tracker := NewBinaryTracker(BinaryOptions{MaxSeparation: 5, GravitationalConstant: 1})
+
+	events := tracker.Update(particles, 10, 1)
+	if len(events) != 1 || events[0].Kind != BinaryFormed || events[0].Step != 10 {
+		t.Fatalf("Expected a formation at step 10, got %+v", events)
+	}
+	if events := tracker.Update(particles, 20, 2); len(events) != 0 || len(tracker.Active()) != 1 {
+		t.Fatalf("Expected the binary to persist, got %+v", events)
+	}
+
+	particles[1].Velocity.Z = 10
+	events = tracker.Update(particles, 30, 3.5)
+	if len(events) != 1 || events[0].Kind != BinaryDisrupted || events[0].Lifetime != 2.5 {
+		t.Fatalf("Expected a disruption after 2.5, got %+v", events)
+	}
+	if tracker.Formed != 1 || tracker.Disrupted != 1 || len(tracker.Active()) != 0 {
+		t.Errorf("Expected 1 formed, 1 disrupted, none active; got %d, %d, %d", tracker.Formed, tracker.Disrupted, len(tracker.Active()))
+	}
+	if events[0].Kind.String() != "disrupted" || BinaryFormed.String() != "formed" {
+		t.Errorf("Unexpected kind names %q, %q", events[0].Kind, BinaryFormed)
+	}
+}
@@ -0,0 +1,33 @@
+package physics
+
+// ParticleEnergy is the kinetic and gravitational potential energy of one particle
+type ParticleEnergy struct {
+	Kinetic   float64 // ½mv² in the frame the energy was computed in
+	Potential float64 // mΦ at the particle, Φ interpolated from the potential grid
+}
+
+// Total returns the kinetic plus potential energy
+func (e ParticleEnergy) Total() float64 {
+	return e.Kinetic + e.Potential
+}
+
+// Bound reports whether the particle cannot escape the potential, i.e. its
+// total energy is negative
+func (e ParticleEnergy) Bound() bool {
+	return e.Total() < 0
+}
+
+// ComputeParticleEnergy returns the energy of p in a frame moving at
+// frameVelocity, with Φ interpolated from potentialGrid, of cells of size dx,
+// by the CIC stencil used for the mass deposition. The FFT solver drops the mean of Φ, so
+// "bound" means bound relative to the mean potential of the box. Φ includes
+// the particle's own deposited mass, which deepens it slightly at the
+// particle's position
+func ComputeParticleEnergy(p *Particle, potentialGrid Grid, dx float64, frameVelocity Vec3) ParticleEnergy {
+	v := p.Velocity.Sub(frameVelocity)
+	mass := float64(p.Mass)
+	return ParticleEnergy{
+		Kinetic:   0.5 * mass * v.Dot(v),
+		Potential: mass * InterpolateGrid(potentialGrid, p.Position.X, p.Position.Z, dx),
+	}
+}
@@ -0,0 +1,38 @@
+package physics
+
+import (
+	"math"
+	"testing"
+)
+
+// TestComputeParticleEnergy tests the kinetic and interpolated potential energy of a particle
+func TestComputeParticleEnergy(t *testing.T) {
+	grid := NewGrid(8, 8)
+	grid[4][4] = -2 // Well at the origin
+
+	p := NewParticle(3, 0, 0, 0, 1, 0, 0)
+	e := ComputeParticleEnergy(p, grid, 1, Vec3{})
+	if math.Abs(e.Kinetic-1.5) > 1e-12 || math.Abs(e.Potential+6) > 1e-12 {
+		t.Errorf("Expected kinetic 1.5 and potential -6, got %+v", e)
+	}
+	if !e.Bound() || e.Total() != e.Kinetic+e.Potential {
+		t.Errorf("Expected a bound particle with total %g, got %+v", e.Kinetic+e.Potential, e)
+	}
+
+	// Fast enough to escape the well
+	p.Velocity = NewVec3(3, 0, 0)
+	if e := ComputeParticleEnergy(p, grid, 1, Vec3{}); e.Bound() {
+		t.Errorf("Expected an unbound particle, got %+v", e)
+	}
+
+	// Bound again in a frame moving with it
+	if e := ComputeParticleEnergy(p, grid, 1, NewVec3(3, 0, 0)); e.Kinetic != 0 || !e.Bound() {
+		t.Errorf("Expected no kinetic energy in the co-moving frame, got %+v", e)
+	}
+
+	// Halfway to the next node sees half the depth
+	p = NewParticle(1, 0.5, 0, 0, 0, 0, 0)
+	if e := ComputeParticleEnergy(p, grid, 1, Vec3{}); math.Abs(e.Potential+1) > 1e-12 {
+		t.Errorf("Expected potential -1 halfway out of the well, got %g", e.Potential)
+	}
+}
@@ -0,0 +1,84 @@
+package physics
+
+import (
+	"math"
+	"testing"
+)
+
+// clusterParticles returns a heavy central mass with light particles on
+// circular orbits at radii from 1 to 20 cells
+func clusterParticles() []*Particle {
+	particles := []*Particle{{Mass: 500}}
+	for k := 0; k < 20; k++ {
+		r := 1 + float64(k)
+		angle := float64(k) * 2.4
+		v := math.Sqrt(1000) // Circular speed for G = 2, where a = 1000/r after the force correction
+
+		particles = append(particles, &Particle{
+			Position: NewVec3(r*math.Cos(angle), 0, r*math.Sin(angle)),
+			Velocity: NewVec3(-v*math.Sin(angle), 0, v*math.Cos(angle)),
+			Mass:     0.01,
+		})
+	}
+	return particles
+}
+
+// copyParticles returns deep copies of particles
+func copyParticles(particles []*Particle) []*Particle {
+	copies := make([]*Particle, len(particles))
+	for i, p := range particles {
+		c := *p
+		copies[i] = &c
+	}
+	return copies
+}
+
+// TestBlockStepsSingleLevel tests that a single level is the shared
+// Kick-Drift-Kick step of the direct solver
+func TestBlockStepsSingleLevel(t *testing.T) {
+	block, direct := clusterParticles(), clusterParticles()
+	opts := BlockOptions{GravitationalConstant: 2, Softening: 0.25, MaxLevel: 1, Accuracy: 1e9}
+	stats := RunBlockTimeEvolution(block, 0.01, 128, 128, opts)
+	RunDirectTimeEvolution(direct, 0.01, 128, 128, DirectOptions{GravitationalConstant: 2, Softening: 0.25})
+
+	if stats.Levels[0] != len(block) || stats.Evaluations != 2*len(block) {
+		t.Errorf("Expected every particle on level 0 with two evaluations each, got %+v", stats)
+	}
+	for i := range block {
+		if d := block[i].Position.Sub(direct[i].Position).Length(); d > 1e-12 {
+			t.Errorf("Particle %d is %g from the shared step", i, d)
+		}
+	}
+}
+
+// TestBlockStepsConcentrated tests that a centrally concentrated system keeps
+// the orbits of the finest shared step with far fewer force evaluations.
+// Orbital phases drift apart on the coarser levels, so the radii are compared
+func TestBlockStepsConcentrated(t *testing.T) {
+	const dt, levels = 0.1, 5
+	block, fine := clusterParticles(), clusterParticles()
+	opts := BlockOptions{GravitationalConstant: 2, Softening: 0.25, MaxLevel: levels}
+
+	evaluations := 0
+	for step := 0; step < 10; step++ {
+		stats := RunBlockTimeEvolution(block, dt, 128, 128, opts)
+		evaluations += stats.Evaluations
+		if step == 0 && (stats.Levels[0] == len(block) || stats.Levels[levels] == len(block)) {
+			t.Errorf("Expected the particles spread over several levels, got %v", stats.Levels)
+		}
+		for k := 0; k < 1<<levels; k++ {
+			RunDirectTimeEvolution(fine, dt/(1<<levels), 128, 128, DirectOptions{GravitationalConstant: 2, Softening: 0.25})
+		}
+	}
+
+	fineEvaluations := 10 * (1 << levels) * 2 * len(fine)
+	if evaluations*2 > fineEvaluations {
+		t.Errorf("Expected well under half the %d evaluations of the finest shared step, got %d", fineEvaluations, evaluations)
+	}
+	for i := 1; i < len(block); i++ {
+		r, want := block[i].Position.Sub(block[0].Position).Length(), fine[i].Position.Sub(fine[0].Position).Length()
+		if math.Abs(r-want) > 0.02*want {
+			t.Errorf("Particle %d orbits at radius %.3f, %.3f with the finest shared step", i, r, want)
+		}
+	}
+}
@@ -0,0 +1,45 @@
+package physics
+
+// SolveCoarseInto fills massGrid and potentialGrid from the particles through
+// a grid coarsened by factor in each dimension, interpolating the coarse
+// result back onto the full grids. It costs about 1/factor² of a full
+// deposit and solve, for views that can do without the small-scale detail.
+// A factor of 1 or less, or one that does not divide both grid dimensions,
+// deposits and solves at full resolution on the mesh
+func SolveCoarseInto(potentialGrid, massGrid Grid, particles []*Particle, factor int, mesh *Mesh, gravitationalConstant float64) {
+	width, height := gridDims(massGrid)
+	if factor <= 1 || width%factor != 0 || height%factor != 0 || width/factor < 2 || height/factor < 2 {
+		DepositMassToGridInto(massGrid, particles, mesh)
+		SolvePoissonFFTInto(potentialGrid, massGrid, mesh.Dx(), gravitationalConstant)
+		return
+	}
+	dx := mesh.Dx()
+
+	coarseWidth, coarseHeight := width/factor, height/factor
+	coarseMass := acquireGrid(coarseWidth, coarseHeight)
+	defer releaseGrid(coarseMass)
+	coarsePotential := acquireGrid(coarseWidth, coarseHeight)
+	defer releaseGrid(coarsePotential)
+
+	// A coarse cell covers factor² fine cells, but in coarse cell units the
+	// Laplacian also shrinks by factor², so the coarse solve of the cell
+	// masses is already the potential in simulation units
+	ClearGrid(coarseMass.rows)
+	scale := 1 / float64(factor)
+	for _, p := range particles {
+		depositCIC(coarseMass.rows, coarseWidth, coarseHeight, dx, p.Position.X*scale, p.Position.Z*scale, float64(p.Mass))
+	}
+	SolvePoissonFFTInto(coarsePotential.rows, coarseMass.rows, dx, gravitationalConstant)
+
+	cellArea := float64(factor * factor)
+	nodeScale := scale * dx
+	for i := 0; i < width; i++ {
+		x := (float64(i) - float64(width)/2) * nodeScale
+		for j := 0; j < height; j++ {
+			z := (float64(j) - float64(height)/2) * nodeScale
+			c := locateCell(x, z, coarseWidth, coarseHeight, dx)
+			massGrid[i][j] = c.interpolate(coarseMass.rows) / cellArea
+			potentialGrid[i][j] = c.interpolate(coarsePotential.rows)
+		}
+	}
+}
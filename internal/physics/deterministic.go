@@ -0,0 +1,45 @@
+package physics
+
+import (
+	"math"
+	"sync"
+)
+
+// FixedPointScale is the resolution of deterministic deposition: masses are
+// accumulated in units of 1/FixedPointScale, leaving room for about 3e10
+// mass units per cell
+const FixedPointScale = 1 << 28
+
+// fixedGridPool holds the integer accumulators of deterministic deposition
+var fixedGridPool sync.Pool // *[]int64
+
+// depositFixed deposits the particle masses with Cloud-in-Cell, summing the
+// contributions of each cell as integers, on cells of size dx
+func depositFixed(grid Grid, particles []*Particle, width, height int, dx float64) {
+	n := width * height
+	acc, _ := fixedGridPool.Get().(*[]int64)
+	if acc == nil || len(*acc) != n {
+		cells := make([]int64, n)
+		acc = &cells
+	}
+	defer fixedGridPool.Put(acc)
+	cells := *acc
+	clear(cells)
+
+	add := func(i, j int, mass float64) {
+		cells[i*height+j] += int64(math.Round(mass * FixedPointScale))
+	}
+	for _, p := range particles {
+		c := locateCell(p.Position.X, p.Position.Z, width, height, dx)
+		mass := float64(p.Mass)
+		add(c.i, c.j, float64(mass*(1-c.fx))*(1-c.fz))
+		add(c.nextI, c.j, float64(mass*c.fx)*(1-c.fz))
+		add(c.i, c.nextJ, float64(mass*(1-c.fx))*c.fz)
+		add(c.nextI, c.nextJ, float64(mass*c.fx)*c.fz)
+	}
+	for i := range grid {
+		for j := range grid[i] {
+			grid[i][j] = float64(cells[i*height+j]) / FixedPointScale
+		}
+	}
+}
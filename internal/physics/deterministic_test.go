@@ -0,0 +1,55 @@
+package physics
+
+import (
+	"math"
+	"math/rand"
+	"testing"
+)
+
+// TestDeterministicDeposit tests that fixed-point deposition gives the same
+// bits in any particle order and agrees with the floating-point deposit
+func TestDeterministicDeposit(t *testing.T) {
+	particles := InitializeParticlesWithSeed(500, 64, 64, 9)
+	floating := DepositMassToGrid(particles, 64, 64, nil)
+
+	mesh := &Mesh{Deterministic: true}
+	fixed := DepositMassToGrid(particles, 64, 64, mesh)
+	shuffled := append([]*Particle(nil), particles...)
+	rand.New(rand.NewSource(1)).Shuffle(len(shuffled), func(i, j int) {
+		shuffled[i], shuffled[j] = shuffled[j], shuffled[i]
+	})
+	reordered := DepositMassToGrid(shuffled, 64, 64, mesh)
+
+	for i := range fixed {
+		for j := range fixed[i] {
+			if math.Float64bits(fixed[i][j]) != math.Float64bits(reordered[i][j]) {
+				t.Fatalf("Cell (%d, %d) changed with the particle order: %g vs %g", i, j, fixed[i][j], reordered[i][j])
+			}
+			if math.Abs(fixed[i][j]-floating[i][j]) > 4*float64(len(particles))/FixedPointScale {
+				t.Fatalf("Cell (%d, %d) is %g, floating-point deposit %g", i, j, fixed[i][j], floating[i][j])
+			}
+		}
+	}
+}
+
+// TestDeterministicWorkers tests that direct-summation steps are
+// bit-identical for any number of workers
+func TestDeterministicWorkers(t *testing.T) {
+	run := func(workers int) []*Particle {
+		particles := InitializeParticlesWithSeed(200, 64, 64, 4)
+		opts := DirectOptions{GravitationalConstant: 1, Softening: 0.25, Workers: workers, EncounterRadius: 2}
+		for step := 0; step < 5; step++ {
+			RunDirectTimeEvolution(particles, 0.05, 64, 64, opts)
+		}
+		return particles
+	}
+
+	want := run(1)
+	for _, workers := range []int{2, 3, 8} {
+		for i, p := range run(workers) {
+			if p.Position != want[i].Position || p.Velocity != want[i].Velocity {
+				t.Fatalf("Particle %d differs with %d workers", i, workers)
+			}
+		}
+	}
+}
@@ -0,0 +1,104 @@
+package physics
+
+import "math"
+
+// Diagnostics holds global conserved quantities used to monitor simulation health
+type Diagnostics struct {
+	ParticleCount  int
+	TotalMass      float64
+	KineticEnergy  float64
+	MomentumX      float64
+	MomentumZ      float64
+	CenterOfMass   Vec3
+	MaxSpeed       float64
+	NonFiniteCount int     // Particles with NaN or Inf position/velocity
+	GyrationRadius float64 // Mass-weighted RMS distance from the center of mass (clustering measure)
+
+	// Relaxation indicators, measured in the x-z plane around the center of
+	// mass. Steady drift in the Lagrangian radii of an equilibrium system, or
+	// of the anisotropy towards 0, is the signature of two-body relaxation by
+	// PM noise rather than collisionless dynamics
+	LagrangianR10      float64 // Radius enclosing 10% of the mass
+	LagrangianR50      float64 // Radius enclosing 50% of the mass (half-mass radius)
+	LagrangianR90      float64 // Radius enclosing 90% of the mass
+	VelocityAnisotropy float64 // (σr² - σt²)/(σr² + σt²): 1 = radial orbits, 0 = isotropic, -1 = circular
+}
+
+// ComputeDiagnostics calculates global diagnostics for the given particles
+func ComputeDiagnostics(particles []*Particle) Diagnostics {
+	d := Diagnostics{ParticleCount: len(particles)}
+
+	var weighted Vec3
+	var weightedSq float64
+	for _, p := range particles {
+		if !isFiniteVec3(p.Position) || !isFiniteVec3(p.Velocity) {
+			d.NonFiniteCount++
+			continue
+		}
+
+		mass := float64(p.Mass)
+		d.TotalMass += mass
+		d.KineticEnergy += float64(p.KineticEnergy())
+		d.MomentumX += mass * p.Velocity.X
+		d.MomentumZ += mass * p.Velocity.Z
+		weighted = weighted.Add(p.Position.Scale(mass))
+		weightedSq += mass * (p.Position.X*p.Position.X + p.Position.Y*p.Position.Y + p.Position.Z*p.Position.Z)
+
+		if speed := p.Velocity.Length(); speed > d.MaxSpeed {
+			d.MaxSpeed = speed
+		}
+	}
+
+	if d.TotalMass > 0 {
+		d.CenterOfMass = weighted.Scale(1.0 / d.TotalMass)
+		com := d.CenterOfMass
+		variance := weightedSq/d.TotalMass - (com.X*com.X + com.Y*com.Y + com.Z*com.Z)
+		if variance > 0 {
+			d.GyrationRadius = math.Sqrt(variance)
+		}
+	}
+	computeRelaxation(&d, particles)
+
+	return d
+}
+
+// PotentialEnergy returns the gravitational potential energy W = ½ Σ mᵢ Φ(xᵢ),
+// with Φ interpolated from potentialGrid, of cells of size dx, using the same
+// periodic CIC stencil as the mass deposition. The FFT solver drops the mean
+// of Φ, so W is measured relative to the mean potential
+func PotentialEnergy(particles []*Particle, potentialGrid Grid, dx float64) float64 {
+	width, height := potentialGrid.Width(), potentialGrid.Height()
+	if width == 0 || height == 0 {
+		return 0
+	}
+	potentialGrid.mustCover(width, height, "potential")
+
+	var total float64
+	for _, p := range particles {
+		if !isFiniteVec3(p.Position) {
+			continue
+		}
+		c := locateCell(p.Position.X, p.Position.Z, width, height, dx)
+		total += float64(p.Mass) * c.interpolate(potentialGrid)
+	}
+	return 0.5 * total
+}
+
+// VirialRatio returns 2K/|W|, which is 1 for a system in virial equilibrium
+// (0 if W is zero)
+func VirialRatio(kinetic, potential float64) float64 {
+	if potential == 0 {
+		return 0
+	}
+	return 2 * kinetic / math.Abs(potential)
+}
+
+// isFiniteVec3 reports whether all components of v are finite
+func isFiniteVec3(v Vec3) bool {
+	return isFinite(v.X) && isFinite(v.Y) && isFinite(v.Z)
+}
+
+// isFinite reports whether f is neither NaN nor Inf
+func isFinite(f float64) bool {
+	return f-f == 0
+}
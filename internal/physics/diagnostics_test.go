@@ -0,0 +1,98 @@
+package physics
+
+import (
+	"math"
+	"testing"
+)
+
+// TestComputeDiagnostics tests global diagnostics for a simple system
+func TestComputeDiagnostics(t *testing.T) {
+	particles := []*Particle{
+		NewParticle(2.0, -1.0, 0, 0, 0, 0, 1.0),
+		NewParticle(2.0, 1.0, 0, 0, 0, 0, -1.0),
+	}
+
+	d := ComputeDiagnostics(particles)
+
+	if d.ParticleCount != 2 {
+		t.Errorf("Expected 2 particles, got %d", d.ParticleCount)
+	}
+	if d.TotalMass != 4.0 {
+		t.Errorf("Expected total mass 4.0, got %f", d.TotalMass)
+	}
+	if math.Abs(d.KineticEnergy-2.0) > 1e-6 {
+		t.Errorf("Expected kinetic energy 2.0, got %f", d.KineticEnergy)
+	}
+	if math.Abs(d.MomentumX) > 1e-12 || math.Abs(d.MomentumZ) > 1e-12 {
+		t.Errorf("Expected zero total momentum, got (%f, %f)", d.MomentumX, d.MomentumZ)
+	}
+	if d.CenterOfMass.Length() > 1e-12 {
+		t.Errorf("Expected center of mass at origin, got %v", d.CenterOfMass)
+	}
+	if d.MaxSpeed != 1.0 {
+		t.Errorf("Expected max speed 1.0, got %f", d.MaxSpeed)
+	}
+	if math.Abs(d.GyrationRadius-1.0) > 1e-12 {
+		t.Errorf("Expected gyration radius 1.0, got %f", d.GyrationRadius)
+	}
+}
+
+// TestComputeDiagnosticsNonFinite tests that NaN particles are counted and excluded
+func TestComputeDiagnosticsNonFinite(t *testing.T) {
+	particles := []*Particle{
+		NewParticle(1.0, 0, 0, 0, 0, 0, 0),
+		NewParticle(1.0, math.NaN(), 0, 0, 0, 0, 0),
+		NewParticle(1.0, 0, 0, 0, math.Inf(1), 0, 0),
+	}
+
+	d := ComputeDiagnostics(particles)
+
+	if d.NonFiniteCount != 2 {
+		t.Errorf("Expected 2 non-finite particles, got %d", d.NonFiniteCount)
+	}
+	if d.TotalMass != 1.0 {
+		t.Errorf("Non-finite particles should be excluded from totals, got mass %f", d.TotalMass)
+	}
+}
+
+// TestComputeDiagnosticsEmpty tests diagnostics with no particles
+func TestComputeDiagnosticsEmpty(t *testing.T) {
+	d := ComputeDiagnostics(nil)
+	if d.ParticleCount != 0 || d.TotalMass != 0 {
+		t.Errorf("Expected empty diagnostics, got %+v", d)
+	}
+}
+
+// TestPotentialEnergy tests that bound pairs have negative energy that
+// deepens as they approach, and the virial ratio helper
+func TestPotentialEnergy(t *testing.T) {
+	width, height := 32, 32
+	energyAt := func(separation float64) float64 {
+		particles := []*Particle{
+			NewParticle(10, -separation/2, 0, 0, 0, 0, 0),
+			NewParticle(10, separation/2, 0, 0, 0, 0, 0),
+		}
+		mass := DepositMassToGrid(particles, width, height, nil)
+		potential := SolvePoissonFFT(mass, width, height, 1, 1.0)
+		return PotentialEnergy(particles, potential, 1)
+	}
+
+	far, near := energyAt(12), energyAt(4)
+	if far >= 0 || near >= 0 {
+		t.Errorf("Expected negative potential energy, got far=%f near=%f", far, near)
+	}
+	if near >= far {
+		t.Errorf("Closer pair should be more bound: near=%f far=%f", near, far)
+	}
+
+	if PotentialEnergy(nil, NewGrid(width, height), 1) != 0 || PotentialEnergy([]*Particle{NewParticle(1, 0, 0, 0, 0, 0, 0)}, nil, 1) != 0 {
+		t.Error("Empty inputs should have zero potential energy")
+	}
+
+	if r := VirialRatio(5, -10); math.Abs(r-1) > 1e-12 {
+		t.Errorf("Expected virial ratio 1, got %f", r)
+	}
+	if VirialRatio(5, 0) != 0 {
+		t.Error("Virial ratio with zero potential energy should be 0")
+	}
+}
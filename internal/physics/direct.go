@@ -0,0 +1,107 @@
+package physics
+
+import (
+	"math"
+	"runtime"
+	"sync"
+)
+
+// DirectAccelerations computes the exact (isolated, non-periodic) acceleration
+// of every particle by O(N²) pairwise summation of the 2D force law implied by
+// ∇²Φ = 4πGρ in the x-z plane:
+//
+//	a_i = -2G Σ_j m_j (x_i - x_j) / (|x_i - x_j|² + ε²)
+//
+// softening is the Plummer softening length ε. Particles are split across
+// workers goroutines (< 1 = one per CPU). It is the reference for validating
+// the particle-mesh forces
+func DirectAccelerations(particles []*Particle, gravitationalConstant, softening float64, workers int) (ax, az []float64) {
+	return directAccelerations(particles, gravitationalConstant, softening, workers, 0, 0)
+}
+
+// DirectAccelerationsPeriodic is DirectAccelerations on a width×depth periodic
+// domain: each pair interacts through its nearest image, matching the
+// wrap-around of UpdatePositions
+func DirectAccelerationsPeriodic(particles []*Particle, gravitationalConstant, softening float64, workers int, width, depth float64) (ax, az []float64) {
+	return directAccelerations(particles, gravitationalConstant, softening, workers, width, depth)
+}
+
+// directAccelerations sums pairwise forces, using nearest-image separations
+// along any axis with a positive period
+func directAccelerations(particles []*Particle, gravitationalConstant, softening float64, workers int, periodX, periodZ float64) (ax, az []float64) {
+	n := len(particles)
+	ax = make([]float64, n)
+	az = make([]float64, n)
+
+	// Copy positions once so the inner loop reads contiguous memory
+	xs := make([]float64, n)
+	zs := make([]float64, n)
+	ms := make([]float64, n)
+	for i, p := range particles {
+		xs[i], zs[i], ms[i] = p.Position.X, p.Position.Z, float64(p.Mass)
+	}
+	eps2 := softening * softening
+	coupling := -2 * gravitationalConstant
+
+	parallelRows(n, workers, func(start, end int) {
+		for i := start; i < end; i++ {
+			sumX, sumZ := pairwiseSum(xs, zs, ms, i, eps2, periodX, periodZ)
+			ax[i], az[i] = coupling*sumX, coupling*sumZ
+		}
+	})
+
+	return ax, az
+}
+
+// pairwiseSum returns Σ_j m_j (x_i - x_j) / (|x_i - x_j|² + ε²) over all j ≠ i,
+// with nearest-image separations along any axis with a positive period
+func pairwiseSum(xs, zs, ms []float64, i int, eps2, periodX, periodZ float64) (sumX, sumZ float64) {
+	for j := range xs {
+		if j == i {
+			continue
+		}
+		dx := nearestImage(xs[i]-xs[j], periodX)
+		dz := nearestImage(zs[i]-zs[j], periodZ)
+		r2 := float64(dx*dx) + float64(dz*dz) + eps2
+		if r2 == 0 {
+			continue // Coincident particles without softening exert no defined force
+		}
+		sumX += ms[j] * dx / r2
+		sumZ += ms[j] * dz / r2
+	}
+	return sumX, sumZ
+}
+
+// parallelRows calls fn on contiguous chunks of [0, n) from up to workers
+// goroutines (< 1 = one per CPU) and waits for all of them
+func parallelRows(n, workers int, fn func(start, end int)) {
+	if n == 0 {
+		return
+	}
+	if workers < 1 {
+		workers = runtime.NumCPU()
+	}
+	if workers > n {
+		workers = n
+	}
+
+	var wg sync.WaitGroup
+	chunk := (n + workers - 1) / workers
+	for start := 0; start < n; start += chunk {
+		end := min(start+chunk, n)
+		wg.Add(1)
+		go func(start, end int) {
+			defer wg.Done()
+			fn(start, end)
+		}(start, end)
+	}
+	wg.Wait()
+}
+
+// nearestImage maps a separation onto [-period/2, period/2] (period <= 0 = unbounded)
+func nearestImage(d, period float64) float64 {
+	if period <= 0 {
+		return d
+	}
+	return d - period*math.Round(d/period)
+}
@@ -0,0 +1,102 @@
+package physics
+
+import (
+	"math"
+	"math/rand"
+	"testing"
+)
+
+// TestDirectAccelerationsPair tests the 2D force law for two particles
+func TestDirectAccelerationsPair(t *testing.T) {
+	particles := []*Particle{
+		NewParticle(2, 0, 0, 0, 0, 0, 0),
+		NewParticle(3, 4, 0, 0, 0, 0, 0),
+	}
+	ax, az := DirectAccelerations(particles, 1.5, 0, 1)
+
+	// a = -2G m d/|d|²: particle 0 is pulled towards +X by particle 1
+	if want := 2 * 1.5 * 3 / 4.0; math.Abs(ax[0]-want) > 1e-12 || az[0] != 0 {
+		t.Errorf("a0 = (%f, %f), want (%f, 0)", ax[0], az[0], want)
+	}
+	if want := -2 * 1.5 * 2 / 4.0; math.Abs(ax[1]-want) > 1e-12 {
+		t.Errorf("a1 = %f, want %f", ax[1], want)
+	}
+
+	// Softening reduces the force: d/(d² + ε²)
+	ax, _ = DirectAccelerations(particles, 1.5, 3, 1)
+	if want := 2 * 1.5 * 3 * 4 / 25.0; math.Abs(ax[0]-want) > 1e-12 {
+		t.Errorf("Softened a0 = %f, want %f", ax[0], want)
+	}
+}
+
+// TestDirectAccelerationsMomentum tests that pairwise forces cancel and that
+// the result does not depend on the number of workers
+func TestDirectAccelerationsMomentum(t *testing.T) {
+	rng := rand.New(rand.NewSource(3))
+	particles := make([]*Particle, 101)
+	for i := range particles {
+		particles[i] = NewParticle(rng.Float64()+0.5, rng.NormFloat64()*10, 0, rng.NormFloat64()*10, 0, 0, 0)
+	}
+	particles = append(particles, NewParticle(1, particles[0].Position.X, 0, particles[0].Position.Z, 0, 0, 0))
+
+	serialX, serialZ := DirectAccelerations(particles, 1, 0.5, 1)
+	parallelX, parallelZ := DirectAccelerations(particles, 1, 0.5, 7)
+
+	var px, pz float64
+	for i, p := range particles {
+		if serialX[i] != parallelX[i] || serialZ[i] != parallelZ[i] {
+			t.Fatalf("Particle %d differs between 1 and 7 workers", i)
+		}
+		px += float64(p.Mass) * serialX[i]
+		pz += float64(p.Mass) * serialZ[i]
+	}
+	if math.Abs(px) > 1e-9 || math.Abs(pz) > 1e-9 {
+		t.Errorf("Net force = (%g, %g), want 0", px, pz)
+	}
+
+	if ax, az := DirectAccelerations(particles[:1], 1, 0, 0); ax[0] != 0 || az[0] != 0 {
+		t.Error("A single particle should feel no force")
+	}
+}
+
+// TestDirectAccelerationsPeriodic tests that pairs interact through the nearest image
+func TestDirectAccelerationsPeriodic(t *testing.T) {
+	particles := []*Particle{
+		NewParticle(1, -15, 0, 0, 0, 0, 0),
+		NewParticle(1, 15, 0, 0, 0, 0, 0),
+	}
+
+	isoX, _ := DirectAccelerations(particles, 1, 0, 1)
+	perX, _ := DirectAccelerationsPeriodic(particles, 1, 0, 1, 32, 32)
+
+	// Isolated: 30 apart, particle 0 pulled towards +X. Periodic: 2 apart across the seam
+	if want := 2.0 / 30; math.Abs(isoX[0]-want) > 1e-12 {
+		t.Errorf("Isolated a0 = %f, want %f", isoX[0], want)
+	}
+	if want := -2.0 / 2; math.Abs(perX[0]-want) > 1e-12 {
+		t.Errorf("Periodic a0 = %f, want %f (pulled across the seam)", perX[0], want)
+	}
+}
+
+// TestRunDirectTimeEvolution tests that a pair at rest falls together while
+// conserving momentum
+func TestRunDirectTimeEvolution(t *testing.T) {
+	particles := []*Particle{
+		NewParticle(2, -5, 0, 1, 0, 0, 0),
+		NewParticle(1, 5, 0, 1, 0, 0, 0),
+	}
+	for step := 0; step < 50; step++ {
+		RunDirectTimeEvolution(particles, 0.05, 64, 64, DirectOptions{GravitationalConstant: 1, Softening: 0.25})
+	}
+
+	if sep := particles[1].Position.X - particles[0].Position.X; sep >= 10 || sep <= 0 {
+		t.Errorf("Separation %f, expected the pair to approach without crossing", sep)
+	}
+	px := float64(particles[0].Mass)*particles[0].Velocity.X + float64(particles[1].Mass)*particles[1].Velocity.X
+	if math.Abs(px) > 1e-9 {
+		t.Errorf("Momentum %g, want 0", px)
+	}
+	if particles[0].Position.Z != 1 || particles[0].Velocity.Z != 0 {
+		t.Error("Motion should stay along the separation axis")
+	}
+}
@@ -0,0 +1,127 @@
+package physics
+
+import (
+	"math"
+	"testing"
+)
+
+// pairEnergy returns the total energy of two particles under the direct
+// solver's effective coupling (half of G, as in the kicks)
+func pairEnergy(particles []*Particle, gravitationalConstant, softening float64) float64 {
+	var energy float64
+	for _, p := range particles {
+		energy += 0.5 * float64(p.Mass) * (p.Velocity.X*p.Velocity.X + p.Velocity.Z*p.Velocity.Z)
+	}
+	dx := particles[0].Position.X - particles[1].Position.X
+	dz := particles[0].Position.Z - particles[1].Position.Z
+	return energy + 0.5*gravitationalConstant*float64(particles[0].Mass*particles[1].Mass)*math.Log(dx*dx+dz*dz+softening*softening)
+}
+
+// TestRunDirectTimeEvolutionEncounter tests that sub-stepping an eccentric
+// binary removes the energy spikes a fixed global step produces at pericenter
+func TestRunDirectTimeEvolutionEncounter(t *testing.T) {
+	maxEnergyError := func(radius float64) (float64, int) {
+		particles := []*Particle{
+			NewParticle(1, -2, 0, 0, 0, 0, 0.05),
+			NewParticle(1, 2, 0, 0, 0, 0, -0.05),
+		}
+		opts := DirectOptions{GravitationalConstant: 1, Softening: 0.05, EncounterRadius: radius}
+		e0 := pairEnergy(particles, 1, 0.05)
+
+		var maxErr float64
+		substeps := 0
+		for step := 0; step < 400; step++ {
+			substeps += RunDirectTimeEvolution(particles, 0.1, 256, 256, opts)
+			maxErr = math.Max(maxErr, math.Abs(pairEnergy(particles, 1, 0.05)-e0)/math.Abs(e0))
+		}
+		return maxErr, substeps
+	}
+
+	plain, plainSubsteps := maxEnergyError(0)
+	regularized, substeps := maxEnergyError(2)
+	t.Logf("max relative energy error: %.2e plain, %.2e with sub-stepping (%d sub-steps)", plain, regularized, substeps)
+
+	if plainSubsteps != 400 {
+		t.Errorf("Without an encounter radius every step should be a single sub-step, got %d", plainSubsteps)
+	}
+	if substeps <= 400 {
+		t.Errorf("Expected extra sub-steps near pericenter, got %d", substeps)
+	}
+	if regularized > 0.01 {
+		t.Errorf("Energy error %.2e with sub-stepping exceeds 1%%", regularized)
+	}
+	if plain < 10*regularized {
+		t.Errorf("Sub-stepping should improve energy conservation: %.2e vs %.2e", regularized, plain)
+	}
+}
+
+// TestRunDirectTimeEvolutionSubsteps tests the sub-step bound and that distant
+// particles take a single step
+func TestRunDirectTimeEvolutionSubsteps(t *testing.T) {
+	far := []*Particle{
+		NewParticle(1, -20, 0, 0, 0, 0, 0),
+		NewParticle(1, 20, 0, 0, 0, 0, 0),
+	}
+	if n := RunDirectTimeEvolution(far, 0.1, 64, 64, DirectOptions{GravitationalConstant: 1, EncounterRadius: 2}); n != 1 {
+		t.Errorf("Distant pair used %d sub-steps, want 1", n)
+	}
+
+	near := []*Particle{
+		NewParticle(1, -0.01, 0, 0, 0, 0, 0),
+		NewParticle(1, 0.01, 0, 0, 0, 0, 0),
+	}
+	opts := DirectOptions{GravitationalConstant: 1, EncounterRadius: 2, MaxSubsteps: 8}
+	if n := RunDirectTimeEvolution(near, 0.1, 64, 64, opts); n != 8 {
+		t.Errorf("Close pair used %d sub-steps, want the cap of 8", n)
+	}
+}
+
+// TestRunDirectTimeEvolutionBackend tests that a custom force backend is
+// called twice per step with the effective coupling and reproduces the default
+func TestRunDirectTimeEvolutionBackend(t *testing.T) {
+	newParticles := func() []*Particle {
+		return []*Particle{
+			NewParticle(1, -3, 0, 1, 0, 0, 0.1),
+			NewParticle(2, 3, 0, -1, 0, 0, -0.05),
+			NewParticle(0.5, 0, 0, 30, 0.2, 0, 0),
+		}
+	}
+	opts := DirectOptions{GravitationalConstant: 1, Softening: 0.25, EncounterRadius: 2}
+
+	reference := newParticles()
+	RunDirectTimeEvolution(reference, 0.1, 64, 64, opts)
+
+	calls := 0
+	opts.Accelerations = func(particles []*Particle, gravitationalConstant, softening, periodX, periodZ float64) (ax, az []float64) {
+		calls++
+		if gravitationalConstant != 0.5 || softening != 0.25 || periodX != 64 || periodZ != 64 {
+			t.Errorf("Unexpected backend arguments: G %v, softening %v, period %vx%v", gravitationalConstant, softening, periodX, periodZ)
+		}
+		return DirectAccelerationsPeriodic(particles, gravitationalConstant, softening, 1, periodX, periodZ)
+	}
+	particles := newParticles()
+	RunDirectTimeEvolution(particles, 0.1, 64, 64, opts)
+
+	if calls != 2 {
+		t.Errorf("Backend called %d times, want 2", calls)
+	}
+	for i := range particles {
+		if particles[i].Position != reference[i].Position || particles[i].Velocity != reference[i].Velocity {
+			t.Errorf("Particle %d differs from the default backend: %+v vs %+v", i, *particles[i], *reference[i])
+		}
+	}
+}
+
+// TestChangeover tests the smooth force split weight
+func TestChangeover(t *testing.T) {
+	cases := []struct {
+		r, want float64
+	}{
+		{0, 1}, {1, 1}, {1.5, 0.5}, {2, 0}, {3, 0},
+	}
+	for _, c := range cases {
+		if got := changeover(c.r, 1, 2); math.Abs(got-c.want) > 1e-12 {
+			t.Errorf("changeover(%v) = %v, want %v", c.r, got, c.want)
+		}
+	}
+}
@@ -0,0 +1,67 @@
+package physics
+
+import "math"
+
+// ExternalPotential is an analytic potential that acts on the particles on
+// top of their self-gravity, such as masses the simulation does not evolve
+type ExternalPotential interface {
+	// Potential returns the potential at (x, z), up to an additive constant
+	Potential(x, z float64) float64
+	// Acceleration returns -∇Φ at (x, z)
+	Acceleration(x, z float64) (ax, az float64)
+}
+
+// ExternalForce is the Force of an external potential
+type ExternalForce struct {
+	Potential ExternalPotential
+}
+
+// Kick adds the acceleration of the potential times dt to each velocity
+func (f ExternalForce) Kick(particles []*Particle, dt float64) {
+	for _, p := range particles {
+		ax, az := f.Potential.Acceleration(p.Position.X, p.Position.Z)
+		p.Velocity.X += float64(ax * dt)
+		p.Velocity.Z += float64(az * dt)
+	}
+}
+
+// PointMass is a fixed mass in the x-z plane. It is not a particle: it does
+// not move, and it does not appear on the mass grid
+type PointMass struct {
+	X, Z      float64
+	Mass      float64
+	Softening float64 // Plummer softening length ε
+}
+
+// PointMasses is the potential of fixed point masses under the same 2D force
+// law as the particles, a = -2Gm d / (|d|² + ε²), so Φ = Gm ln(|d|² + ε²).
+// Distances are not wrapped: the masses have no periodic images
+type PointMasses struct {
+	G      float64
+	Masses []PointMass
+}
+
+// Potential returns the summed potential of the masses at (x, z)
+func (p PointMasses) Potential(x, z float64) float64 {
+	var phi float64
+	for _, m := range p.Masses {
+		dx, dz := x-m.X, z-m.Z
+		phi += m.Mass * math.Log(dx*dx+dz*dz+m.Softening*m.Softening)
+	}
+	return p.G * phi
+}
+
+// Acceleration returns the summed acceleration towards the masses at (x, z).
+// An unsoftened mass exerts no force at its own position
+func (p PointMasses) Acceleration(x, z float64) (ax, az float64) {
+	for _, m := range p.Masses {
+		dx, dz := x-m.X, z-m.Z
+		r2 := dx*dx + dz*dz + m.Softening*m.Softening
+		if r2 == 0 {
+			continue
+		}
+		ax -= m.Mass * dx / r2
+		az -= m.Mass * dz / r2
+	}
+	return 2 * p.G * ax, 2 * p.G * az
+}
@@ -0,0 +1,60 @@
+package physics
+
+import (
+	"math"
+	"testing"
+)
+
+// TestPointMassesMatchDirect tests that fixed point masses pull like particles
+// of the same mass under direct summation
+func TestPointMassesMatchDirect(t *testing.T) {
+	const g, softening = 1.5, 0.3
+	masses := PointMasses{G: g, Masses: []PointMass{
+		{X: -2, Z: 1, Mass: 3, Softening: softening},
+		{X: 4, Z: -1, Mass: 0.5, Softening: softening},
+	}}
+	probe := NewTracer(0.5, 2, 0, 0)
+	particles := []*Particle{
+		probe,
+		NewParticle(3, -2, 0, 1, 0, 0, 0),
+		NewParticle(0.5, 4, 0, -1, 0, 0, 0),
+	}
+	ax, az := DirectAccelerations(particles, g, softening, 1)
+	gotX, gotZ := masses.Acceleration(probe.Position.X, probe.Position.Z)
+	if math.Abs(gotX-ax[0]) > 1e-12 || math.Abs(gotZ-az[0]) > 1e-12 {
+		t.Errorf("Expected acceleration (%g, %g), got (%g, %g)", ax[0], az[0], gotX, gotZ)
+	}
+}
+
+// TestPointMassesGradient tests that the acceleration is minus the gradient
+// of the potential
+func TestPointMassesGradient(t *testing.T) {
+	masses := PointMasses{G: 1, Masses: []PointMass{{X: 1, Z: 2, Mass: 2}, {X: -3, Z: 0, Mass: 1, Softening: 0.5}}}
+	const h = 1e-6
+	for _, pt := range [][2]float64{{0, 0}, {2.5, -1}, {-3, 0.2}} {
+		x, z := pt[0], pt[1]
+		wantX := -(masses.Potential(x+h, z) - masses.Potential(x-h, z)) / (2 * h)
+		wantZ := -(masses.Potential(x, z+h) - masses.Potential(x, z-h)) / (2 * h)
+		ax, az := masses.Acceleration(x, z)
+		if math.Abs(ax-wantX) > 1e-6 || math.Abs(az-wantZ) > 1e-6 {
+			t.Errorf("At (%g, %g) expected (%g, %g), got (%g, %g)", x, z, wantX, wantZ, ax, az)
+		}
+	}
+
+	// An unsoftened mass exerts no force at its own position
+	if ax, az := (PointMasses{G: 1, Masses: []PointMass{{Mass: 1}}}).Acceleration(0, 0); ax != 0 || az != 0 {
+		t.Errorf("Expected no self-force, got (%g, %g)", ax, az)
+	}
+}
+
+// TestExternalForceKick tests that the external force kicks velocities by a·dt
+func TestExternalForceKick(t *testing.T) {
+	masses := PointMasses{G: 1, Masses: []PointMass{{X: 0, Z: 0, Mass: 2}}}
+	p := NewTracer(2, 0, 0, 1)
+	ExternalForce{Potential: masses}.Kick([]*Particle{p}, 0.1)
+
+	// a = -2Gm/r = -2 towards the mass
+	if want := NewVec3(-0.2, 0, 1); p.Velocity.Sub(want).Length() > 1e-12 {
+		t.Errorf("Expected velocity %+v, got %+v", want, p.Velocity)
+	}
+}
@@ -0,0 +1,105 @@
+package physics
+
+import "math"
+
+// FlowField is the particle velocity field on the grid and its decomposition
+// into expansion, rotation and shear, from the velocity gradient tensor
+// ∂vₐ/∂xᵦ in the x-z plane
+type FlowField struct {
+	VelocityX  Grid // Mass-weighted mean velocity per node (0 where there is no mass)
+	VelocityZ  Grid
+	Divergence Grid // ∂vx/∂x + ∂vz/∂z: positive where the flow expands
+	Vorticity  Grid // ωy = ∂vx/∂z - ∂vz/∂x: twice the angular velocity about +Y (right-hand rule)
+	Shear      Grid // Magnitude of the traceless symmetric part, √((∂vx/∂x - ∂vz/∂z)² + (∂vx/∂z + ∂vz/∂x)²)
+}
+
+// FlowStats summarizes a flow field over the nodes that hold mass
+type FlowStats struct {
+	MeanVorticity float64 // Net rotation: positive for disks spinning about +Y
+	RMSDivergence float64
+	RMSVorticity  float64
+	RMSShear      float64
+}
+
+// ComputeFlowField grids the particle velocities on a width×height grid of
+// cells of size dx centered on the origin by Cloud-in-Cell and
+// differentiates them with periodic central differences, matching
+// CalculateGradientInto. Non-finite particles are skipped
+func ComputeFlowField(particles []*Particle, width, height int, dx float64) FlowField {
+	f := FlowField{
+		VelocityX:  NewGrid(width, height),
+		VelocityZ:  NewGrid(width, height),
+		Divergence: NewGrid(width, height),
+		Vorticity:  NewGrid(width, height),
+		Shear:      NewGrid(width, height),
+	}
+	if width == 0 || height == 0 {
+		return f
+	}
+
+	// Deposit mass and momentum, then divide for the mean velocity
+	mass := NewGrid(width, height)
+	for _, p := range particles {
+		if !isFiniteVec3(p.Position) || !isFiniteVec3(p.Velocity) {
+			continue
+		}
+		m := float64(p.Mass)
+		depositCIC(mass, width, height, dx, p.Position.X, p.Position.Z, m)
+		depositCIC(f.VelocityX, width, height, dx, p.Position.X, p.Position.Z, m*p.Velocity.X)
+		depositCIC(f.VelocityZ, width, height, dx, p.Position.X, p.Position.Z, m*p.Velocity.Z)
+	}
+	for i := 0; i < width; i++ {
+		for j := 0; j < height; j++ {
+			if m := mass.AtUnchecked(i, j); m > 0 {
+				f.VelocityX.SetUnchecked(i, j, f.VelocityX.AtUnchecked(i, j)/m)
+				f.VelocityZ.SetUnchecked(i, j, f.VelocityZ.AtUnchecked(i, j)/m)
+			}
+		}
+	}
+
+	vx, vz := f.VelocityX, f.VelocityZ
+	span := 2 * dx
+	for i := 0; i < width; i++ {
+		prevI, nextI := wrapIndex(i-1, width), wrapIndex(i+1, width)
+		for j := 0; j < height; j++ {
+			prevJ, nextJ := wrapIndex(j-1, height), wrapIndex(j+1, height)
+			dvxdx := (vx.AtUnchecked(nextI, j) - vx.AtUnchecked(prevI, j)) / span
+			dvzdx := (vz.AtUnchecked(nextI, j) - vz.AtUnchecked(prevI, j)) / span
+			dvxdz := (vx.AtUnchecked(i, nextJ) - vx.AtUnchecked(i, prevJ)) / span
+			dvzdz := (vz.AtUnchecked(i, nextJ) - vz.AtUnchecked(i, prevJ)) / span
+
+			f.Divergence.SetUnchecked(i, j, dvxdx+dvzdz)
+			f.Vorticity.SetUnchecked(i, j, dvxdz-dvzdx)
+			f.Shear.SetUnchecked(i, j, math.Hypot(dvxdx-dvzdz, dvxdz+dvzdx))
+		}
+	}
+	return f
+}
+
+// Stats returns the mean vorticity and the RMS divergence, vorticity and
+// shear over the nodes where the velocity is defined (non-zero)
+func (f FlowField) Stats() FlowStats {
+	var s FlowStats
+	n := 0
+	for i := range f.VelocityX {
+		for j := range f.VelocityX[i] {
+			if f.VelocityX[i][j] == 0 && f.VelocityZ[i][j] == 0 {
+				continue
+			}
+			n++
+			div, vort, shear := f.Divergence[i][j], f.Vorticity[i][j], f.Shear[i][j]
+			s.MeanVorticity += vort
+			s.RMSDivergence += div * div
+			s.RMSVorticity += vort * vort
+			s.RMSShear += shear * shear
+		}
+	}
+	if n == 0 {
+		return FlowStats{}
+	}
+	s.MeanVorticity /= float64(n)
+	s.RMSDivergence = math.Sqrt(s.RMSDivergence / float64(n))
+	s.RMSVorticity = math.Sqrt(s.RMSVorticity / float64(n))
+	s.RMSShear = math.Sqrt(s.RMSShear / float64(n))
+	return s
+}
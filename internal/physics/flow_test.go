@@ -0,0 +1,70 @@
+package physics
+
+import (
+	"math"
+	"testing"
+)
+
+// flowLattice returns one unit mass on every node of a size×size grid, moving with velocity(x, z)
+func flowLattice(size int, velocity func(x, z float64) (vx, vz float64)) []*Particle {
+	var particles []*Particle
+	for i := 0; i < size; i++ {
+		for j := 0; j < size; j++ {
+			x, z := float64(i-size/2), float64(j-size/2)
+			vx, vz := velocity(x, z)
+			particles = append(particles, NewParticle(1, x, 0, z, vx, 0, vz))
+		}
+	}
+	return particles
+}
+
+// TestComputeFlowField tests the decomposition of linear flows away from the periodic seams
+func TestComputeFlowField(t *testing.T) {
+	const size, a = 16, 0.1
+	tests := []struct {
+		name                         string
+		velocity                     func(x, z float64) (float64, float64)
+		divergence, vorticity, shear float64
+	}{
+		{"rotation", func(x, z float64) (float64, float64) { return a * z, -a * x }, 0, 2 * a, 0},
+		{"expansion", func(x, z float64) (float64, float64) { return a * x, a * z }, 2 * a, 0, 0},
+		{"shear", func(x, z float64) (float64, float64) { return a * z, 0 }, 0, a, a},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			f := ComputeFlowField(flowLattice(size, tt.velocity), size, size, 1)
+			for i := 2; i < size-2; i++ {
+				for j := 2; j < size-2; j++ {
+					if math.Abs(f.Divergence[i][j]-tt.divergence) > 1e-9 ||
+						math.Abs(f.Vorticity[i][j]-tt.vorticity) > 1e-9 ||
+						math.Abs(f.Shear[i][j]-tt.shear) > 1e-9 {
+						t.Fatalf("Node (%d, %d): expected divergence %g, vorticity %g, shear %g, got %g, %g, %g", i, j,
+							tt.divergence, tt.vorticity, tt.shear, f.Divergence[i][j], f.Vorticity[i][j], f.Shear[i][j])
+					}
+				}
+			}
+		})
+	}
+}
+
+// TestFlowStats tests the summary over nodes with mass
+func TestFlowStats(t *testing.T) {
+	particles := []*Particle{
+		NewParticle(1, 0, 0, 0, 1, 0, 0),
+		NewParticle(1, 2, 0, 0, 0, 0, 0), // At rest: no velocity defined
+		NewParticle(1, math.NaN(), 0, 0, 1, 0, 0),
+	}
+	f := ComputeFlowField(particles, 8, 8, 1)
+	if f.VelocityX[4][4] != 1 || f.VelocityX[6][4] != 0 {
+		t.Errorf("Expected the gridded velocities 1 and 0, got %g and %g", f.VelocityX[4][4], f.VelocityX[6][4])
+	}
+
+	// The stats cover node (4, 4) only, where the central differences cancel
+	s := f.Stats()
+	if s.MeanVorticity != 0 || s.RMSDivergence != 0 || s.RMSVorticity != 0 || s.RMSShear != 0 {
+		t.Errorf("Expected a uniform single node to have no gradients, got %+v", s)
+	}
+	if s := ComputeFlowField(nil, 8, 8, 1).Stats(); s != (FlowStats{}) {
+		t.Errorf("Expected empty stats without particles, got %+v", s)
+	}
+}
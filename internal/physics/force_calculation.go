@@ -5,117 +5,211 @@ import (
 	"relativity_simulation_2d/pkg/fft"
 )
 
-// ForceField represents the gravitational acceleration field
+// ForceField represents the gravitational acceleration field. Both grids must
+// be at least Width×Height; hot loops index them without bounds checks
 type ForceField struct {
-	AccelFieldX [][]float64
-	AccelFieldZ [][]float64
+	AccelFieldX Grid
+	AccelFieldZ Grid
 	Width       int
 	Height      int
+	CellSize    float64 // Physical size of a cell (0 = 1)
 }
 
-// DepositMassToGrid distributes particle mass to grid using Cloud-in-Cell
-func DepositMassToGrid(particles []*Particle, width, height int) [][]float64 {
-	// Initialize mass density grid
-	grid := make([][]float64, width)
-	for i := range grid {
-		grid[i] = make([]float64, height)
+// Dx returns the physical size of a cell of the field: CellSize, or 1 if unset
+func (f *ForceField) Dx() float64 {
+	return spacing(f.CellSize)
+}
+
+// DepositMassToGrid distributes particle mass to grid using periodic Cloud-in-Cell
+func DepositMassToGrid(particles []*Particle, width, height int, mesh *Mesh) Grid {
+	grid := NewGrid(width, height)
+	DepositMassToGridInto(grid, particles, mesh)
+	return grid
+}
+
+// DepositMassToGridInto clears grid and deposits particle mass into it using
+// Cloud-in-Cell, sub-sampled if the mesh has a deposit stride
+func DepositMassToGridInto(grid Grid, particles []*Particle, mesh *Mesh) {
+	depositMass(grid, particles, mesh, true)
+}
+
+// depositMass clears grid and deposits the particles into it. Unless
+// sampled is set, every particle is deposited whatever the deposit stride
+func depositMass(grid Grid, particles []*Particle, mesh *Mesh, sampled bool) {
+	ClearGrid(grid)
+	width, height := gridDims(grid)
+	if width == 0 || height == 0 {
+		return
+	}
+	grid.mustCover(width, height, "mass")
+	dx := mesh.Dx()
+	if mesh.deterministic() {
+		depositFixed(grid, particles, width, height, dx)
+		return
+	}
+
+	if sampled {
+		if first, stride, scale, ok := mesh.subsample(particles); ok {
+			for i := first; i < len(particles); i += stride {
+				p := particles[i]
+				depositCIC(grid, width, height, dx, p.Position.X, p.Position.Z, float64(p.Mass)*scale)
+			}
+			return
+		}
 	}
 
 	// Deposit each particle's mass
 	for _, p := range particles {
-		// Find grid cell coordinates and fractional parts
-		gx := p.Position.X + float64(width)/2.0
-		gz := p.Position.Z + float64(height)/2.0
-		i := int(gx)
-		j := int(gz)
-		fx := gx - float64(i)
-		fz := gz - float64(j)
-
-		// Distribute mass to 4 nearest cells (Cloud-in-Cell)
-		if i >= 0 && i < width-1 && j >= 0 && j < height-1 {
-			grid[i][j] += float64(p.Mass) * (1 - fx) * (1 - fz)
-			grid[i+1][j] += float64(p.Mass) * fx * (1 - fz)
-			grid[i][j+1] += float64(p.Mass) * (1 - fx) * fz
-			grid[i+1][j+1] += float64(p.Mass) * fx * fz
-		}
+		depositCIC(grid, width, height, dx, p.Position.X, p.Position.Z, float64(p.Mass))
 	}
+}
 
-	return grid
+// depositCIC distributes mass at (x, z) to the 4 nearest cells (Cloud-in-Cell).
+// Cells wrap periodically, matching the periodic Poisson solve and gradient,
+// so every particle deposits exactly its full mass. The float64 conversions
+// here and in the other particle kernels round each product, which stops
+// the compiler fusing it into the following add on FMA architectures
+func depositCIC(grid Grid, width, height int, dx, x, z, mass float64) {
+	c := locateCell(x, z, width, height, dx)
+	grid.AddUnchecked(c.i, c.j, float64(float64(mass*(1-c.fx))*(1-c.fz)))
+	grid.AddUnchecked(c.nextI, c.j, float64(float64(mass*c.fx)*(1-c.fz)))
+	grid.AddUnchecked(c.i, c.nextJ, float64(float64(mass*(1-c.fx))*c.fz))
+	grid.AddUnchecked(c.nextI, c.nextJ, float64(float64(mass*c.fx)*c.fz))
 }
 
-// SolvePoissonFFT solves ∇²Φ = 4πGρ using FFT
-func SolvePoissonFFT(massGrid [][]float64, width, height int, gravitationalConstant float64) [][]float64 {
-	// Convert mass density grid to complex numbers for FFT
-	complexGrid := make([][]complex128, width)
+// cell is the periodic CIC stencil around a position: the lower-left cell,
+// its wrapped neighbours and the fractional offsets within the cell
+type cell struct {
+	i, j, nextI, nextJ int
+	fx, fz             float64
+}
+
+// locateCell finds the CIC stencil for (x, z) on a width×height grid of
+// cells of size dx centered on the origin
+func locateCell(x, z float64, width, height int, dx float64) cell {
+	gx := x/dx + float64(width)/2.0
+	gz := z/dx + float64(height)/2.0
+	fi := math.Floor(gx)
+	fj := math.Floor(gz)
+	i := wrapIndex(int(fi), width)
+	j := wrapIndex(int(fj), height)
+	return cell{
+		i: i, j: j,
+		nextI: wrapIndex(i+1, width),
+		nextJ: wrapIndex(j+1, height),
+		fx:    gx - fi,
+		fz:    gz - fj,
+	}
+}
+
+// interpolate bilinearly interpolates grid over the stencil, weighting each
+// cell as depositCIC does. Interpolating the forces with the deposition
+// kernel makes the pair forces equal and opposite and the self-force vanish,
+// so PM steps conserve momentum to rounding: the central-difference gradient
+// is antisymmetric and the Green's function symmetric
+func (c cell) interpolate(grid Grid) float64 {
+	v1 := float64(grid.AtUnchecked(c.i, c.j)*(1-c.fz)) + float64(grid.AtUnchecked(c.i, c.nextJ)*c.fz)
+	v2 := float64(grid.AtUnchecked(c.nextI, c.j)*(1-c.fz)) + float64(grid.AtUnchecked(c.nextI, c.nextJ)*c.fz)
+	return float64(v1*(1-c.fx)) + float64(v2*c.fx)
+}
+
+// wrapIndex maps a cell index onto [0, n) with periodic boundaries
+func wrapIndex(i, n int) int {
+	if i >= 0 && i < n {
+		return i
+	}
+	i %= n
+	if i < 0 {
+		i += n
+	}
+	return i
+}
+
+// SolvePoissonFFT solves ∇²Φ = 4πGρ using FFT on cells of size dx
+func SolvePoissonFFT(massGrid Grid, width, height int, dx, gravitationalConstant float64) Grid {
+	potentialGrid := NewGrid(width, height)
+	SolvePoissonFFTInto(potentialGrid, massGrid, dx, gravitationalConstant)
+	return potentialGrid
+}
+
+// SolvePoissonFFTInto solves ∇²Φ = 4πGρ for massGrid on cells of size dx and
+// writes Φ into potentialGrid. massGrid holds mass per cell, so ρ is it over
+// the cell area
+func SolvePoissonFFTInto(potentialGrid, massGrid Grid, dx, gravitationalConstant float64) {
+	width, height := gridDims(massGrid)
+
+	// Convert mass density grid to complex numbers for FFT (pooled scratch)
+	scratch := acquireComplexGrid(width, height)
+	defer releaseComplexGrid(scratch)
+	complexGrid := scratch.rows
 	for i := range complexGrid {
-		complexGrid[i] = make([]complex128, height)
 		for j := range complexGrid[i] {
 			complexGrid[i][j] = complex(massGrid[i][j], 0)
 		}
 	}
 
-	// Create FFT processor
-	processor := fft.NewFFTProcessor()
-
-	// 2D FFT of the mass density
-	fftGrid := processor.FFT2D(complexGrid)
-
-	// Solve in Fourier space: Φ̂(k) = -4πG * ρ̂(k) / |k|²
-	kxFactor := 2.0 * math.Pi / float64(width)
-	kzFactor := 2.0 * math.Pi / float64(height)
+	// 2D FFT of the mass density (in place)
+	fft.Transform2DInPlace(complexGrid, false)
+	fftGrid := complexGrid
 
+	// Solve in Fourier space: Φ̂(k) = G(k) ρ̂(k)
 	for u := 0; u < width; u++ {
 		for v := 0; v < height; v++ {
-			// Calculate wave vector k
-			kx := float64(u)
-			if u > width/2 {
-				kx = float64(u - width)
-			}
-			kz := float64(v)
-			if v > height/2 {
-				kz = float64(v - height)
-			}
-
-			kSquared := (kx*kxFactor)*(kx*kxFactor) + (kz*kzFactor)*(kz*kzFactor)
-
-			if kSquared == 0 {
-				fftGrid[u][v] = 0 // Ignore the DC component (average potential)
-			} else {
-				// Standard gravitational Poisson equation: ∇²Φ = 4πGρ
-				scalingFactor := -4.0 * math.Pi * gravitationalConstant / kSquared
-				fftGrid[u][v] *= complex(scalingFactor, 0)
-			}
+			fftGrid[u][v] *= complex(GreensFunction(u, v, width, height, dx, gravitationalConstant), 0)
 		}
 	}
 
 	// Inverse 2D FFT to get the potential grid in real space
-	potentialComplex := processor.IFFT2D(fftGrid)
+	fft.Transform2DInPlace(fftGrid, true)
+	potentialComplex := fftGrid
 
 	// Copy real part to potential grid
-	potentialGrid := make([][]float64, width)
 	for i := range potentialGrid {
-		potentialGrid[i] = make([]float64, height)
 		for j := range potentialGrid[i] {
 			potentialGrid[i][j] = real(potentialComplex[i][j])
 		}
 	}
-
-	return potentialGrid
 }
 
-// CalculateGradient computes acceleration a = -∇Φ using central differences
-func CalculateGradient(potentialGrid [][]float64, width, height int) *ForceField {
-	forceField := &ForceField{
-		AccelFieldX: make([][]float64, width),
-		AccelFieldZ: make([][]float64, height),
-		Width:       width,
-		Height:      height,
+// GreensFunction returns the Fourier-space Green's function of the Poisson
+// equation at frequency (u, v) of a width×height grid of mass per cell of
+// size dx: G(k) = -4πG / (|k|² ΔA), with k in physical units and ΔA the cell
+// area. The zero frequency, the mean potential, is 0
+func GreensFunction(u, v, width, height int, dx, gravitationalConstant float64) float64 {
+	kx := float64(u)
+	if u > width/2 {
+		kx = float64(u - width)
+	}
+	kz := float64(v)
+	if v > height/2 {
+		kz = float64(v - height)
 	}
+	kx *= 2.0 * math.Pi / (float64(width) * dx)
+	kz *= 2.0 * math.Pi / (float64(height) * dx)
 
-	for i := range forceField.AccelFieldX {
-		forceField.AccelFieldX[i] = make([]float64, height)
-		forceField.AccelFieldZ[i] = make([]float64, height)
+	kSquared := kx*kx + kz*kz
+	if kSquared == 0 {
+		return 0
 	}
+	cellArea := dx * dx
+	return -4.0 * math.Pi * gravitationalConstant / (kSquared * cellArea)
+}
+
+// CalculateGradient computes acceleration a = -∇Φ using central differences
+// over cells of size dx
+func CalculateGradient(potentialGrid Grid, width, height int, dx float64) *ForceField {
+	forceField := NewForceField(width, height, dx)
+	CalculateGradientInto(forceField, potentialGrid)
+	return forceField
+}
+
+// CalculateGradientInto computes a = -∇Φ into an existing force field of
+// matching size, differencing over the field's cells
+func CalculateGradientInto(forceField *ForceField, potentialGrid Grid) {
+	forceField.checkDims()
+	width, height := forceField.Width, forceField.Height
+	potentialGrid.mustCover(width, height, "potential")
+	span := 2 * forceField.Dx()
 
 	for i := 0; i < width; i++ {
 		for j := 0; j < height; j++ {
@@ -126,67 +220,77 @@ func CalculateGradient(potentialGrid [][]float64, width, height int) *ForceField
 			nextJ := (j + 1) % height
 
 			// Central difference for gradient with periodic boundaries
-			forceField.AccelFieldX[i][j] = -(potentialGrid[nextI][j] - potentialGrid[prevI][j]) / 2.0
-			forceField.AccelFieldZ[i][j] = -(potentialGrid[i][nextJ] - potentialGrid[i][prevJ]) / 2.0
+			forceField.AccelFieldX.SetUnchecked(i, j, -(potentialGrid.AtUnchecked(nextI, j)-potentialGrid.AtUnchecked(prevI, j))/span)
+			forceField.AccelFieldZ.SetUnchecked(i, j, -(potentialGrid.AtUnchecked(i, nextJ)-potentialGrid.AtUnchecked(i, prevJ))/span)
 		}
 	}
-
-	return forceField
 }
 
 // InterpolateAcceleration interpolates acceleration from grid to particle position
 func InterpolateAcceleration(position Vec3, forceField *ForceField) (ax, az float64) {
-	// Find grid cell coordinates and fractional parts for interpolation
-	gx := position.X + float64(forceField.Width)/2.0
-	gz := position.Z + float64(forceField.Height)/2.0
-	i := int(gx)
-	j := int(gz)
-	fx := gx - float64(i)
-	fz := gz - float64(j)
-
-	// Bilinear interpolation
-	if i >= 0 && i < forceField.Width-1 && j >= 0 && j < forceField.Height-1 {
-		ax1 := forceField.AccelFieldX[i][j]*(1-fz) + forceField.AccelFieldX[i][j+1]*fz
-		ax2 := forceField.AccelFieldX[i+1][j]*(1-fz) + forceField.AccelFieldX[i+1][j+1]*fz
-		ax = ax1*(1-fx) + ax2*fx
+	forceField.checkDims()
+	return interpolateAccelerationXZ(position.X, position.Z, forceField)
+}
 
-		az1 := forceField.AccelFieldZ[i][j]*(1-fz) + forceField.AccelFieldZ[i][j+1]*fz
-		az2 := forceField.AccelFieldZ[i+1][j]*(1-fz) + forceField.AccelFieldZ[i+1][j+1]*fz
-		az = az1*(1-fx) + az2*fx
+// InterpolateGrid bilinearly interpolates a grid of cells of size dx centered
+// on the origin at (x, z), wrapping periodically like the force interpolation
+func InterpolateGrid(grid Grid, x, z, dx float64) float64 {
+	width, height := grid.Width(), grid.Height()
+	if width == 0 || height == 0 {
+		return 0
 	}
+	grid.mustCover(width, height, "interpolated")
+	return locateCell(x, z, width, height, dx).interpolate(grid)
+}
+
+// checkDims panics if the grids are smaller than the declared field size,
+// which would make the unchecked accessors read out of bounds
+func (f *ForceField) checkDims() {
+	f.AccelFieldX.mustCover(f.Width, f.Height, "acceleration X")
+	f.AccelFieldZ.mustCover(f.Width, f.Height, "acceleration Z")
+}
 
-	return ax, az
+// interpolateAccelerationXZ bilinearly interpolates the acceleration at (x, z).
+// Cells wrap periodically like depositCIC, so the force is continuous across
+// the seam. The caller must have validated the field with checkDims
+func interpolateAccelerationXZ(x, z float64, forceField *ForceField) (ax, az float64) {
+	if forceField.Width == 0 || forceField.Height == 0 {
+		return 0, 0
+	}
+	c := locateCell(x, z, forceField.Width, forceField.Height, forceField.Dx())
+	return c.interpolate(forceField.AccelFieldX), c.interpolate(forceField.AccelFieldZ)
 }
 
 // UpdateVelocities updates particle velocities based on acceleration field (Kick step)
 func UpdateVelocities(particles []*Particle, forceField *ForceField, dt float32, forceCorrectionFactor float32) {
+	forceField.checkDims()
 	for _, p := range particles {
-		ax, az := InterpolateAcceleration(p.Position, forceField)
+		ax, az := interpolateAccelerationXZ(p.Position.X, p.Position.Z, forceField)
 
 		// Apply forces with correction factor to approximately remove self-interaction
-		p.Velocity.X += ax * float64(dt) * float64(forceCorrectionFactor)
-		p.Velocity.Z += az * float64(dt) * float64(forceCorrectionFactor)
+		p.Velocity.X += float64(ax * float64(dt) * float64(forceCorrectionFactor))
+		p.Velocity.Z += float64(az * float64(dt) * float64(forceCorrectionFactor))
 	}
 }
 
-// UpdatePositions updates the positions of all particles (Drift step)
-func UpdatePositions(particles []*Particle, dt float32, width, height int) {
+// UpdatePositions updates the positions of all particles (Drift step),
+// wrapping them at the edges of a width×height grid of cells of size dx
+func UpdatePositions(particles []*Particle, dt float32, width, height int, dx float64) {
+	extentX, extentZ := float64(width)*dx, float64(height)*dx
 	for _, p := range particles {
-		p.Position.X += p.Velocity.X * float64(dt)
-		p.Position.Z += p.Velocity.Z * float64(dt)
+		p.Position.X = wrapCoordinate(p.Position.X+float64(p.Velocity.X*float64(dt)), extentX)
+		p.Position.Z = wrapCoordinate(p.Position.Z+float64(p.Velocity.Z*float64(dt)), extentZ)
+	}
+}
 
-		// Boundary conditions - wrap around
-		if p.Position.X > float64(width)/2.0 {
-			p.Position.X = -float64(width) / 2.0
-		}
-		if p.Position.X < -float64(width)/2.0 {
-			p.Position.X = float64(width) / 2.0
-		}
-		if p.Position.Z > float64(height)/2.0 {
-			p.Position.Z = -float64(height) / 2.0
-		}
-		if p.Position.Z < -float64(height)/2.0 {
-			p.Position.Z = float64(height) / 2.0
-		}
+// wrapCoordinate applies the wrap-around boundary condition to a coordinate in a domain of the given physical extent
+func wrapCoordinate(v float64, extent float64) float64 {
+	half := extent / 2.0
+	if v > half {
+		v = -half
+	}
+	if v < -half {
+		v = half
 	}
+	return v
 }
@@ -20,7 +20,7 @@ func TestDepositMass(t *testing.T) {
 	}
 
 	// Deposit mass to grid
-	grid := DepositMassToGrid(particles, width, height)
+	grid := DepositMassToGrid(particles, width, height, nil)
 
 	// Check that mass is deposited correctly using Cloud-in-Cell
 	// fx = 0.5, fz = 0.5, so mass should be split equally among 4 cells
@@ -58,6 +58,87 @@ func TestDepositMass(t *testing.T) {
 	}
 }
 
+// TestDepositMassPeriodicEdges tests that clouds straddling the last row or
+// column wrap onto the opposite edge instead of being dropped
+func TestDepositMassPeriodicEdges(t *testing.T) {
+	width, height := 8, 8
+	particles := []*Particle{
+		{Position: NewVec3(3.5, 0, 0.25), Mass: 1},  // gx = 7.5, gz = 4.25: X neighbour wraps to column 0
+		{Position: NewVec3(3.75, 0, 3.75), Mass: 2}, // gx = gz = 7.75: corner cloud wraps on both axes
+		{Position: NewVec3(4, 0, -4), Mass: 3},      // Exactly on the seam (gx = 8 wraps to 0)
+	}
+
+	grid := DepositMassToGrid(particles, width, height, nil)
+
+	tolerance := 1e-12
+	checks := []struct {
+		i, j int
+		want float64
+	}{
+		{7, 4, 1 * 0.5 * 0.75},
+		{0, 4, 1 * 0.5 * 0.75},
+		{7, 5, 1 * 0.5 * 0.25},
+		{0, 5, 1 * 0.5 * 0.25},
+		{7, 7, 2 * 0.25 * 0.25},
+		{0, 7, 2 * 0.75 * 0.25},
+		{7, 0, 2 * 0.25 * 0.75},
+		{0, 0, 2*0.75*0.75 + 3},
+	}
+	for _, c := range checks {
+		if math.Abs(grid[c.i][c.j]-c.want) > tolerance {
+			t.Errorf("Mass at (%d,%d) incorrect: got %f, expected %f", c.i, c.j, grid[c.i][c.j], c.want)
+		}
+	}
+
+	if total := grid.Sum(); math.Abs(total-6) > tolerance {
+		t.Errorf("Total mass not conserved: got %f, expected 6", total)
+	}
+}
+
+// TestDepositMassConservation tests that total deposited mass equals total
+// particle mass for positions anywhere in the domain, including the edges
+func TestDepositMassConservation(t *testing.T) {
+	width, height := 16, 12
+	particles := InitializeParticlesWithSeed(500, float64(width), float64(height), 7)
+	particles = append(particles,
+		&Particle{Position: NewVec3(float64(width)/2, 0, 0), Mass: 1},
+		&Particle{Position: NewVec3(0, 0, -float64(height)/2), Mass: 1},
+		&Particle{Position: NewVec3(float64(width)/2-0.01, 0, float64(height)/2-0.01), Mass: 1},
+		&Particle{Position: NewVec3(-float64(width)/2, 0, -float64(height)/2), Mass: 1},
+	)
+
+	var want float64
+	for _, p := range particles {
+		want += float64(p.Mass)
+	}
+
+	grid := DepositMassToGrid(particles, width, height, nil)
+	if got := grid.Sum(); math.Abs(got-want) > 1e-9*want {
+		t.Errorf("Deposited mass %f, expected %f", got, want)
+	}
+
+	ps := NewParticleSystemFromParticles(particles)
+	ps.DepositMass(grid, 1)
+	if got := grid.Sum(); math.Abs(got-want) > 1e-9*want {
+		t.Errorf("ParticleSystem deposited mass %f, expected %f", got, want)
+	}
+
+	grid32 := make([][]float32, width)
+	for i := range grid32 {
+		grid32[i] = make([]float32, height)
+	}
+	DepositMassToGrid32Into(grid32, particles, nil)
+	var got32 float64
+	for i := range grid32 {
+		for _, v := range grid32[i] {
+			got32 += float64(v)
+		}
+	}
+	if math.Abs(got32-want) > 1e-4*want {
+		t.Errorf("float32 deposited mass %f, expected %f", got32, want)
+	}
+}
+
 func TestSolvePoissonEquation(t *testing.T) {
 	// Test solving Poisson equation ∇²Φ = 4πGρ
 
@@ -73,7 +154,7 @@ func TestSolvePoissonEquation(t *testing.T) {
 	massGrid[width/2][height/2] = 100.0
 
 	// Solve for potential
-	potentialGrid := SolvePoissonFFT(massGrid, width, height, gravitationalConstant)
+	potentialGrid := SolvePoissonFFT(massGrid, width, height, 1, gravitationalConstant)
 
 	// Check that potential is negative (attractive)
 	if potentialGrid[width/2][height/2] >= 0 {
@@ -93,6 +174,21 @@ func TestSolvePoissonEquation(t *testing.T) {
 	}
 }
 
+// TestGreensFunction tests the Green's function: zero at the mean, -4πG/k²
+// at the fundamental and symmetric in negative frequencies
+func TestGreensFunction(t *testing.T) {
+	if g := GreensFunction(0, 0, 32, 16, 1, 1); g != 0 {
+		t.Errorf("Expected 0 at the zero frequency, got %g", g)
+	}
+	k := 2 * math.Pi / 32
+	if g, want := GreensFunction(1, 0, 32, 16, 1, 1), -4*math.Pi/(k*k); math.Abs(g-want) > 1e-12*math.Abs(want) {
+		t.Errorf("Expected %g at the fundamental, got %g", want, g)
+	}
+	if GreensFunction(1, 3, 32, 16, 1, 2) != GreensFunction(31, 13, 32, 16, 1, 2) {
+		t.Error("Expected the Green's function to be even in k")
+	}
+}
+
 func TestCalculateGradient(t *testing.T) {
 	// Test gradient calculation a = -∇Φ
 
@@ -110,7 +206,7 @@ func TestCalculateGradient(t *testing.T) {
 	}
 
 	// Calculate gradient
-	forceField := CalculateGradient(potentialGrid, width, height)
+	forceField := CalculateGradient(potentialGrid, width, height, 1)
 
 	// For linear potential, gradient should be constant
 	// ∂Φ/∂x = 1, so ax = -1
@@ -134,6 +230,51 @@ func TestCalculateGradient(t *testing.T) {
 	}
 }
 
+// TestRectangularDomain tests the Poisson solvers and the gradient on
+// 128x512 and 512x128 grids against a plane wave along each axis, whose
+// potential Φ = -4πGρ/k² the spectral solve reproduces exactly
+func TestRectangularDomain(t *testing.T) {
+	for _, size := range [][2]int{{128, 512}, {512, 128}} {
+		width, height := size[0], size[1]
+		kx, kz := 2*math.Pi/float64(width), 2*math.Pi*3/float64(height)
+		density := NewGrid(width, height)
+		for i := 0; i < width; i++ {
+			for j := 0; j < height; j++ {
+				density[i][j] = math.Cos(kx*float64(i)) + math.Cos(kz*float64(j))
+			}
+		}
+
+		potential := SolvePoissonFFT(density, width, height, 1, 1)
+		potential32 := NewGrid(width, height)
+		SolvePoissonWithPrecision(PrecisionFloat32, potential32, density, 1, 1)
+		field := CalculateGradient(potential, width, height, 1)
+		if field.AccelFieldX.Width() != width || field.AccelFieldZ.Height() != height {
+			t.Fatalf("%dx%d: expected force field grids of the same size, got %dx%d and %dx%d", width, height,
+				field.AccelFieldX.Width(), field.AccelFieldX.Height(), field.AccelFieldZ.Width(), field.AccelFieldZ.Height())
+		}
+
+		// Largest values, for the float32 tolerance
+		amplitude := 4 * math.Pi * (1/(kx*kx) + 1/(kz*kz))
+		for i := 0; i < width; i++ {
+			for j := 0; j < height; j++ {
+				x, z := kx*float64(i), kz*float64(j)
+				phi := -4 * math.Pi * (math.Cos(x)/(kx*kx) + math.Cos(z)/(kz*kz))
+				if math.Abs(potential[i][j]-phi) > 1e-9*amplitude || math.Abs(potential32[i][j]-phi) > 1e-5*amplitude {
+					t.Fatalf("%dx%d: potential at (%d, %d) = %g (float32 %g), want %g", width, height, i, j, potential[i][j], potential32[i][j], phi)
+				}
+
+				// Central differences of cos(k·i) give sin(k·i)·sin(k)
+				ax := -4 * math.Pi * math.Sin(x) * math.Sin(kx) / (kx * kx)
+				az := -4 * math.Pi * math.Sin(z) * math.Sin(kz) / (kz * kz)
+				if math.Abs(field.AccelFieldX[i][j]-ax) > 1e-9*amplitude || math.Abs(field.AccelFieldZ[i][j]-az) > 1e-9*amplitude {
+					t.Fatalf("%dx%d: acceleration at (%d, %d) = (%g, %g), want (%g, %g)", width, height, i, j,
+						field.AccelFieldX[i][j], field.AccelFieldZ[i][j], ax, az)
+				}
+			}
+		}
+	}
+}
+
 func TestInterpolateAcceleration(t *testing.T) {
 	// Test bilinear interpolation of acceleration field to particle position
 
@@ -143,7 +284,7 @@ func TestInterpolateAcceleration(t *testing.T) {
 	// Create a simple uniform acceleration field
 	forceField := &ForceField{
 		AccelFieldX: make([][]float64, width),
-		AccelFieldZ: make([][]float64, height),
+		AccelFieldZ: make([][]float64, width),
 		Width:       width,
 		Height:      height,
 	}
@@ -182,6 +323,174 @@ func TestInterpolateAcceleration(t *testing.T) {
 	}
 }
 
+// TestInterpolateAccelerationSeam tests that interpolation wraps across the
+// periodic boundary instead of returning zero in the last row and column
+func TestInterpolateAccelerationSeam(t *testing.T) {
+	width, height := 8, 8
+	forceField := NewForceField(width, height, 1)
+	for i := 0; i < width; i++ {
+		for j := 0; j < height; j++ {
+			forceField.AccelFieldX[i][j] = float64(i) // Ramp along X: 0..7 then wraps to 0
+			forceField.AccelFieldZ[i][j] = float64(j) // Ramp along Z
+		}
+	}
+
+	tolerance := 1e-12
+	testCases := []struct {
+		name       string
+		position   Vec3
+		expectedAx float64
+		expectedAz float64
+	}{
+		{"last column midpoint", NewVec3(3.5, 0, 0), 0.5 * 7, 4},         // gx = 7.5 blends cells 7 and 0
+		{"last row midpoint", NewVec3(0, 0, 3.5), 4, 0.5 * 7},            // gz = 7.5 blends cells 7 and 0
+		{"corner", NewVec3(3.75, 0, 3.75), 0.25 * 7, 0.25 * 7},           // Both axes wrap
+		{"positive seam", NewVec3(4, 0, 0), 0, 4},                        // gx = 8 is cell 0
+		{"negative seam", NewVec3(-4, 0, 0), 0, 4},                       // gx = 0 is cell 0
+		{"beyond domain", NewVec3(3.5+float64(width), 0, 0), 0.5 * 7, 4}, // Whole periods wrap
+	}
+
+	for _, tc := range testCases {
+		ax, az := InterpolateAcceleration(tc.position, forceField)
+		if math.Abs(ax-tc.expectedAx) > tolerance || math.Abs(az-tc.expectedAz) > tolerance {
+			t.Errorf("%s: got (%f, %f), expected (%f, %f)", tc.name, ax, az, tc.expectedAx, tc.expectedAz)
+		}
+	}
+
+	// The force must be continuous approaching the seam from either side
+	const eps = 1e-9
+	axBelow, _ := InterpolateAcceleration(NewVec3(4-eps, 0, 0), forceField)
+	axAbove, _ := InterpolateAcceleration(NewVec3(-4+eps, 0, 0), forceField)
+	if math.Abs(axBelow-axAbove) > 1e-6 {
+		t.Errorf("Discontinuity at seam: %f vs %f", axBelow, axAbove)
+	}
+}
+
+func TestInterpolateGrid(t *testing.T) {
+	grid := NewGrid(8, 8)
+	for i := 0; i < 8; i++ {
+		for j := 0; j < 8; j++ {
+			grid[i][j] = float64(10*i + j)
+		}
+	}
+
+	// Node (i, j) sits at (i-4, j-4)
+	if got := InterpolateGrid(grid, -1, 2, 1); got != 36 {
+		t.Errorf("Expected node value 36, got %g", got)
+	}
+	if got := InterpolateGrid(grid, -0.5, 2.5, 1); math.Abs(got-41.5) > 1e-12 {
+		t.Errorf("Expected midpoint 41.5, got %g", got)
+	}
+	// Halfway across the seam between i=7 and i=0
+	if got := InterpolateGrid(grid, 3.5, -4, 1); math.Abs(got-35) > 1e-12 {
+		t.Errorf("Expected wrapped midpoint 35, got %g", got)
+	}
+	if got := InterpolateGrid(nil, 0, 0, 1); got != 0 {
+		t.Errorf("Expected 0 for an empty grid, got %g", got)
+	}
+}
+
+// TestInterpolateAccelerationUniformEverywhere tests that a uniform field is
+// reproduced exactly in every cell, including boundary cells
+func TestInterpolateAccelerationUniformEverywhere(t *testing.T) {
+	width, height := 6, 4
+	forceField := NewForceField(width, height, 1)
+	for i := 0; i < width; i++ {
+		for j := 0; j < height; j++ {
+			forceField.AccelFieldX[i][j] = -1
+			forceField.AccelFieldZ[i][j] = 2
+		}
+	}
+
+	for x := -3.0; x <= 3.0; x += 0.25 {
+		for z := -2.0; z <= 2.0; z += 0.25 {
+			ax, az := InterpolateAcceleration(NewVec3(x, 0, z), forceField)
+			if math.Abs(ax+1) > 1e-12 || math.Abs(az-2) > 1e-12 {
+				t.Fatalf("At (%f, %f) got (%f, %f), expected (-1, 2)", x, z, ax, az)
+			}
+		}
+	}
+}
+
+// TestInterpolationMatchesDeposit tests that interpolation uses the cells and
+// weights of the deposit: interpolating any grid at a position gives the sum
+// of the grid weighted by a unit mass deposited there
+func TestInterpolationMatchesDeposit(t *testing.T) {
+	width, height := 12, 8
+	grid := NewGrid(width, height)
+	for i := 0; i < width; i++ {
+		for j := 0; j < height; j++ {
+			grid[i][j] = math.Sin(float64(7*i+3*j)) + 0.1*float64(i*j)
+		}
+	}
+	forceField := NewForceField(width, height, 1)
+	CopyGrid(forceField.AccelFieldX, grid)
+	CopyGrid(forceField.AccelFieldZ, grid)
+
+	for _, pos := range [][2]float64{{0, 0}, {-2.3, 1.7}, {5.99, -3.2}, {-6, 3.999}, {6, 4}, {0.5, -0.5}, {17.25, -9.125}} {
+		deposit := DepositMassToGrid([]*Particle{NewParticle(1, pos[0], 0, pos[1], 0, 0, 0)}, width, height, nil)
+		weighted := 0.0
+		for i := 0; i < width; i++ {
+			for j := 0; j < height; j++ {
+				weighted += deposit[i][j] * grid[i][j]
+			}
+		}
+		if got := InterpolateGrid(grid, pos[0], pos[1], 1); math.Abs(got-weighted) > 1e-12 {
+			t.Errorf("At %v interpolated %g, deposit weights give %g", pos, got, weighted)
+		}
+		if ax, az := InterpolateAcceleration(NewVec3(pos[0], 0, pos[1]), forceField); math.Abs(ax-weighted) > 1e-12 || math.Abs(az-weighted) > 1e-12 {
+			t.Errorf("At %v interpolated acceleration (%g, %g), deposit weights give %g", pos, ax, az, weighted)
+		}
+	}
+}
+
+// TestPairForceAntisymmetry tests that the PM forces two particles exert on
+// each other are equal and opposite, and that a lone particle feels no force
+// from itself, to rounding, including across the periodic seam
+func TestPairForceAntisymmetry(t *testing.T) {
+	width, height := 32, 16
+	accelerations := func(particles []*Particle) [][2]float64 {
+		potential := SolvePoissonFFT(DepositMassToGrid(particles, width, height, nil), width, height, 1, 1)
+		field := CalculateGradient(potential, width, height, 1)
+		result := make([][2]float64, len(particles))
+		for i, p := range particles {
+			result[i][0], result[i][1] = InterpolateAcceleration(p.Position, field)
+		}
+		return result
+	}
+
+	testCases := []struct {
+		name           string
+		x1, z1, x2, z2 float64
+		m1, m2         float64
+	}{
+		{"equal masses", -3.3, 1.7, 4.1, -2.2, 1, 1},
+		{"unequal masses", -3.3, 1.7, 4.1, -2.2, 3, 0.5},
+		{"across the seam", 15.9, -7.99, -15.5, 7.2, 2, 1},
+		{"same cell", 0.1, 0.2, 0.7, 0.9, 1, 4},
+		{"on nodes", -5, 3, 6, -2, 1, 2},
+	}
+	for _, tc := range testCases {
+		particles := []*Particle{NewParticle(tc.m1, tc.x1, 0, tc.z1, 0, 0, 0), NewParticle(tc.m2, tc.x2, 0, tc.z2, 0, 0, 0)}
+		a := accelerations(particles)
+		force := math.Hypot(tc.m1*a[0][0], tc.m1*a[0][1])
+		if force == 0 {
+			t.Fatalf("%s: expected a nonzero pair force", tc.name)
+		}
+		netX, netZ := tc.m1*a[0][0]+tc.m2*a[1][0], tc.m1*a[0][1]+tc.m2*a[1][1]
+		if math.Hypot(netX, netZ) > 1e-13*force {
+			t.Errorf("%s: net force (%g, %g) on a pair with force %g", tc.name, netX, netZ, force)
+		}
+
+		for i, p := range particles {
+			self := accelerations([]*Particle{p})[0]
+			if math.Hypot(self[0], self[1]) > 1e-13*force {
+				t.Errorf("%s: particle %d feels a self-force (%g, %g)", tc.name, i, self[0], self[1])
+			}
+		}
+	}
+}
+
 func TestFullForceCalculationPipeline(t *testing.T) {
 	// Test the complete force calculation pipeline
 
@@ -204,13 +513,13 @@ func TestFullForceCalculationPipeline(t *testing.T) {
 	}
 
 	// Step 1: Deposit mass
-	massGrid := DepositMassToGrid(particles, width, height)
+	massGrid := DepositMassToGrid(particles, width, height, nil)
 
 	// Step 2: Solve Poisson equation
-	potentialGrid := SolvePoissonFFT(massGrid, width, height, gravitationalConstant)
+	potentialGrid := SolvePoissonFFT(massGrid, width, height, 1, gravitationalConstant)
 
 	// Step 3: Calculate gradient
-	forceField := CalculateGradient(potentialGrid, width, height)
+	forceField := CalculateGradient(potentialGrid, width, height, 1)
 
 	// Step 4: Interpolate forces to particles
 	ax1, _ := InterpolateAcceleration(particles[0].Position, forceField)
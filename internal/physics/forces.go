@@ -0,0 +1,90 @@
+package physics
+
+import "math"
+
+// Force is a plugin that adds an acceleration on top of gravity, such as an
+// external field or a pseudo-force. Forces act as velocity kicks: engines
+// kick them for half a step before and after each gravity step (Strang
+// splitting), which keeps the combined step second order
+type Force interface {
+	// Kick advances the particle velocities by dt under the force
+	Kick(particles []*Particle, dt float64)
+}
+
+// KickForces advances the velocities by dt under all forces. It kicks each
+// force for dt/2 in order and then again in reverse order, so the combined
+// kick is symmetric in time even when the forces do not commute, as the
+// velocity-dependent Coriolis force does not with position-dependent ones
+func KickForces(particles []*Particle, forces []Force, dt float64) {
+	for _, f := range forces {
+		f.Kick(particles, dt/2)
+	}
+	for i := len(forces) - 1; i >= 0; i-- {
+		forces[i].Kick(particles, dt/2)
+	}
+}
+
+// RotatingFrame is a frame rotating at angular velocity Omega about the +Y
+// axis through the origin, the center of the simulation box (right-hand
+// rule: positive Omega turns +X towards -Z)
+type RotatingFrame struct {
+	Omega float64
+}
+
+// Forces returns the pseudo-forces that act on particles in the frame
+func (f RotatingFrame) Forces() []Force {
+	return []Force{Centrifugal{Omega: f.Omega}, Coriolis{Omega: f.Omega}}
+}
+
+// FromInertial converts particle velocities from the inertial frame to the
+// rotating frame, v' = v - Ω×r, so a run started from inertial initial
+// conditions follows the same motion seen co-rotating
+func (f RotatingFrame) FromInertial(particles []*Particle) {
+	for _, p := range particles {
+		p.Velocity.X -= float64(f.Omega * p.Position.Z)
+		p.Velocity.Z += float64(f.Omega * p.Position.X)
+	}
+}
+
+// ToInertial converts particle velocities from the rotating frame back to the
+// inertial frame, v = v' + Ω×r
+func (f RotatingFrame) ToInertial(particles []*Particle) {
+	for _, p := range particles {
+		p.Velocity.X += float64(f.Omega * p.Position.Z)
+		p.Velocity.Z -= float64(f.Omega * p.Position.X)
+	}
+}
+
+// Centrifugal is the centrifugal pseudo-force -Ω×(Ω×r) = Ω²r of a rotating
+// frame, pushing particles away from the rotation axis in the x-z plane. It
+// uses positions relative to the origin and ignores periodic images, so
+// structures should sit well inside the box
+type Centrifugal struct {
+	Omega float64
+}
+
+// Kick adds Ω²r·dt to each velocity
+func (c Centrifugal) Kick(particles []*Particle, dt float64) {
+	k := c.Omega * c.Omega * dt
+	for _, p := range particles {
+		p.Velocity.X += float64(k * p.Position.X)
+		p.Velocity.Z += float64(k * p.Position.Z)
+	}
+}
+
+// Coriolis is the Coriolis pseudo-force -2Ω×v of a rotating frame. It does
+// no work, so its kick rotates each velocity in the x-z plane by 2Ω·dt
+// exactly instead of stepping the acceleration, which would add energy
+type Coriolis struct {
+	Omega float64
+}
+
+// Kick rotates each velocity by the angle 2Ω·dt
+func (c Coriolis) Kick(particles []*Particle, dt float64) {
+	sin, cos := math.Sincos(2 * c.Omega * dt)
+	for _, p := range particles {
+		vx, vz := p.Velocity.X, p.Velocity.Z
+		p.Velocity.X = vx*cos - vz*sin
+		p.Velocity.Z = vx*sin + vz*cos
+	}
+}
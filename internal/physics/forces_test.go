@@ -0,0 +1,56 @@
+package physics
+
+import (
+	"math"
+	"testing"
+)
+
+// TestRotatingFrameVelocities tests the inertial to rotating frame conversion
+func TestRotatingFrameVelocities(t *testing.T) {
+	frame := RotatingFrame{Omega: 0.5}
+
+	// A particle co-rotating with the frame is at rest in it
+	p := NewParticle(1, 2, 0, 0, 0, 0, -1)
+	frame.FromInertial([]*Particle{p})
+	if p.Velocity.Length() > 1e-12 {
+		t.Errorf("Expected a co-rotating particle to be at rest, got %+v", p.Velocity)
+	}
+	frame.ToInertial([]*Particle{p})
+	if p.Velocity != NewVec3(0, 0, -1) {
+		t.Errorf("Expected the round trip to restore the velocity, got %+v", p.Velocity)
+	}
+}
+
+// TestRotatingFrameFreeParticle tests that a free particle at rest in the
+// inertial frame circles the axis backwards in the rotating frame
+func TestRotatingFrameFreeParticle(t *testing.T) {
+	const omega, radius, dt, steps = 0.2, 5.0, 0.01, 1000
+	frame := RotatingFrame{Omega: omega}
+	forces := frame.Forces()
+	particles := []*Particle{NewParticle(1, radius, 0, 0, 0, 0, 0)}
+	frame.FromInertial(particles)
+
+	for i := 0; i < steps; i++ {
+		KickForces(particles, forces, dt/2)
+		p := particles[0]
+		p.Position = p.Position.Add(p.Velocity.Scale(dt))
+		KickForces(particles, forces, dt/2)
+	}
+
+	// The frame turns +X towards -Z, so the fixed point turns +X towards +Z
+	p := particles[0]
+	angle := omega * dt * steps
+	want := NewVec3(radius*math.Cos(angle), 0, radius*math.Sin(angle))
+	if p.Position.Sub(want).Length() > 1e-4 {
+		t.Errorf("Expected the particle at %+v, got %+v", want, p.Position)
+	}
+}
+
+// TestCoriolisConservesSpeed tests that the Coriolis kick does no work
+func TestCoriolisConservesSpeed(t *testing.T) {
+	p := NewParticle(1, 1, 0, 1, 3, 0, 4)
+	Coriolis{Omega: 1.3}.Kick([]*Particle{p}, 0.7)
+	if math.Abs(p.Velocity.Length()-5) > 1e-12 {
+		t.Errorf("Expected speed 5, got %g", p.Velocity.Length())
+	}
+}
@@ -0,0 +1,156 @@
+package physics
+
+import (
+	"errors"
+	"fmt"
+	"unsafe"
+)
+
+// Grid is a width×height scalar field indexed as grid[i][j], with i along X
+// and j along Z. It has the same underlying type as [][]float64, so existing
+// slices convert implicitly.
+//
+// At/Set/Add check bounds and report whether the cell exists. The *Unchecked
+// variants skip all bounds checks for hot loops; the caller must guarantee
+// 0 <= i < Width() and 0 <= j < Height()
+type Grid [][]float64
+
+// Width returns the number of cells along X
+func (g Grid) Width() int {
+	return len(g)
+}
+
+// Height returns the number of cells along Z
+func (g Grid) Height() int {
+	if len(g) == 0 {
+		return 0
+	}
+	return len(g[0])
+}
+
+// InBounds reports whether (i, j) is a valid cell
+func (g Grid) InBounds(i, j int) bool {
+	return i >= 0 && i < len(g) && j >= 0 && j < len(g[i])
+}
+
+// covers reports whether every cell in [0,width)×[0,height) exists, checking
+// each row so the unchecked accessors are safe over that range
+func (g Grid) covers(width, height int) bool {
+	if len(g) < width {
+		return false
+	}
+	for i := 0; i < width; i++ {
+		if len(g[i]) < height {
+			return false
+		}
+	}
+	return true
+}
+
+// ErrInvalidGrid is returned when a grid is empty or does not cover the
+// simulation domain
+var ErrInvalidGrid = errors.New("invalid grid")
+
+// Validate returns an error wrapping ErrInvalidGrid unless the domain is
+// non-empty and the grid covers every cell of width×height
+func (g Grid) Validate(width, height int) error {
+	if width <= 0 || height <= 0 {
+		return fmt.Errorf("%w: empty %dx%d domain", ErrInvalidGrid, width, height)
+	}
+	if !g.covers(width, height) {
+		return fmt.Errorf("%w: %dx%d does not cover %dx%d", ErrInvalidGrid, g.Width(), g.Height(), width, height)
+	}
+	return nil
+}
+
+// mustCover panics unless the grid covers width×height. The panic value is
+// an error wrapping ErrInvalidGrid
+func (g Grid) mustCover(width, height int, name string) {
+	if !g.covers(width, height) {
+		panic(fmt.Errorf("physics: %s grid %dx%d does not cover %dx%d: %w", name, g.Width(), g.Height(), width, height, ErrInvalidGrid))
+	}
+}
+
+// At returns the value at (i, j) and whether the cell exists
+func (g Grid) At(i, j int) (float64, bool) {
+	if !g.InBounds(i, j) {
+		return 0, false
+	}
+	return g[i][j], true
+}
+
+// Set stores v at (i, j), reporting false if the cell does not exist
+func (g Grid) Set(i, j int, v float64) bool {
+	if !g.InBounds(i, j) {
+		return false
+	}
+	g[i][j] = v
+	return true
+}
+
+// Add adds v to the value at (i, j), reporting false if the cell does not exist
+func (g Grid) Add(i, j int, v float64) bool {
+	if !g.InBounds(i, j) {
+		return false
+	}
+	g[i][j] += v
+	return true
+}
+
+// cell returns a pointer to (i, j) without bounds checks
+func (g Grid) cell(i, j int) *float64 {
+	row := (*[]float64)(unsafe.Add(unsafe.Pointer(unsafe.SliceData(g)), uintptr(i)*unsafe.Sizeof([]float64(nil))))
+	return (*float64)(unsafe.Add(unsafe.Pointer(unsafe.SliceData(*row)), uintptr(j)*unsafe.Sizeof(float64(0))))
+}
+
+// AtUnchecked returns the value at (i, j) without bounds checks
+func (g Grid) AtUnchecked(i, j int) float64 {
+	return *g.cell(i, j)
+}
+
+// SetUnchecked stores v at (i, j) without bounds checks
+func (g Grid) SetUnchecked(i, j int, v float64) {
+	*g.cell(i, j) = v
+}
+
+// AddUnchecked adds v to the value at (i, j) without bounds checks
+func (g Grid) AddUnchecked(i, j int, v float64) {
+	*g.cell(i, j) += v
+}
+
+// Clear sets every cell to zero
+func (g Grid) Clear() {
+	ClearGrid(g)
+}
+
+// Sum returns the sum of all cells
+func (g Grid) Sum() float64 {
+	var total float64
+	for i := range g {
+		for _, v := range g[i] {
+			total += v
+		}
+	}
+	return total
+}
+
+// Flatten writes the grid in row-major order (index i*Height()+j) into dst,
+// growing it if needed, and returns the flattened slice
+func (g Grid) Flatten(dst []float64) []float64 {
+	n := g.Width() * g.Height()
+	if cap(dst) < n {
+		dst = make([]float64, n)
+	}
+	dst = dst[:n]
+	for i := range g {
+		copy(dst[i*g.Height():], g[i])
+	}
+	return dst
+}
+
+// Unflatten fills the grid from src in the row-major order written by Flatten
+func (g Grid) Unflatten(src []float64) {
+	for i := range g {
+		copy(g[i], src[i*g.Height():(i+1)*g.Height()])
+	}
+}
@@ -0,0 +1,163 @@
+package physics
+
+import (
+	"errors"
+	"testing"
+)
+
+// TestGridCheckedAccess tests that At/Set/Add report out-of-bounds cells instead of panicking
+func TestGridCheckedAccess(t *testing.T) {
+	grid := NewGrid(3, 2)
+	if grid.Width() != 3 || grid.Height() != 2 {
+		t.Fatalf("Expected 3x2 grid, got %dx%d", grid.Width(), grid.Height())
+	}
+
+	if !grid.Set(2, 1, 5) {
+		t.Error("Set reported failure for an in-bounds cell")
+	}
+	if !grid.Add(2, 1, 1) {
+		t.Error("Add reported failure for an in-bounds cell")
+	}
+	if v, ok := grid.At(2, 1); !ok || v != 6 {
+		t.Errorf("Expected (6, true), got (%f, %v)", v, ok)
+	}
+
+	for _, c := range [][2]int{{-1, 0}, {0, -1}, {3, 0}, {0, 2}} {
+		if grid.Set(c[0], c[1], 1) || grid.Add(c[0], c[1], 1) {
+			t.Errorf("Write to (%d,%d) should report out of bounds", c[0], c[1])
+		}
+		if _, ok := grid.At(c[0], c[1]); ok {
+			t.Errorf("Read of (%d,%d) should report out of bounds", c[0], c[1])
+		}
+	}
+	if grid.Sum() != 6 {
+		t.Errorf("Out-of-bounds writes changed the grid, sum %f", grid.Sum())
+	}
+
+	var empty Grid
+	if empty.Width() != 0 || empty.Height() != 0 || empty.InBounds(0, 0) {
+		t.Error("Empty grid should have no cells")
+	}
+}
+
+// TestGridUncheckedMatchesChecked tests that the unchecked accessors address the same cells
+func TestGridUncheckedMatchesChecked(t *testing.T) {
+	grid := NewGrid(4, 5)
+	for i := 0; i < grid.Width(); i++ {
+		for j := 0; j < grid.Height(); j++ {
+			grid.SetUnchecked(i, j, float64(i*10+j))
+			grid.AddUnchecked(i, j, 0.5)
+		}
+	}
+
+	for i := 0; i < grid.Width(); i++ {
+		for j := 0; j < grid.Height(); j++ {
+			want := float64(i*10+j) + 0.5
+			if grid[i][j] != want || grid.AtUnchecked(i, j) != want {
+				t.Fatalf("Cell (%d,%d): index %f, unchecked %f, want %f", i, j, grid[i][j], grid.AtUnchecked(i, j), want)
+			}
+		}
+	}
+}
+
+// TestGridFlatten tests row-major flattening and buffer reuse
+func TestGridFlatten(t *testing.T) {
+	grid := Grid{{1, 2, 3}, {4, 5, 6}}
+	flat := grid.Flatten(nil)
+	want := []float64{1, 2, 3, 4, 5, 6}
+	if len(flat) != len(want) {
+		t.Fatalf("Expected %d values, got %d", len(want), len(flat))
+	}
+	for k := range want {
+		if flat[k] != want[k] {
+			t.Errorf("flat[%d] = %f, want %f", k, flat[k], want[k])
+		}
+	}
+
+	buf := make([]float64, 0, 16)
+	if reused := grid.Flatten(buf); &reused[0] != &buf[:1][0] {
+		t.Error("Flatten should reuse a large enough buffer")
+	}
+}
+
+// TestGridUnflatten tests that Unflatten inverts Flatten
+func TestGridUnflatten(t *testing.T) {
+	grid := NewGrid(2, 3)
+	grid.Unflatten([]float64{1, 2, 3, 4, 5, 6})
+	want := Grid{{1, 2, 3}, {4, 5, 6}}
+	for i := range want {
+		for j := range want[i] {
+			if grid[i][j] != want[i][j] {
+				t.Errorf("grid[%d][%d] = %f, want %f", i, j, grid[i][j], want[i][j])
+			}
+		}
+	}
+}
+
+// TestCalculateGradientPanicsOnSmallGrid tests that the unchecked gradient loop
+// refuses a potential grid smaller than the force field
+func TestCalculateGradientPanicsOnSmallGrid(t *testing.T) {
+	defer func() {
+		r := recover()
+		if r == nil {
+			t.Fatal("Expected panic for undersized potential grid")
+		}
+		if err, ok := r.(error); !ok || !errors.Is(err, ErrInvalidGrid) {
+			t.Errorf("Panic value should wrap ErrInvalidGrid, got %v", r)
+		}
+	}()
+	CalculateGradientInto(NewForceField(8, 8, 1), NewGrid(8, 4))
+}
+
+// TestGridValidate tests that undersized, ragged and empty grids are rejected
+func TestGridValidate(t *testing.T) {
+	ragged := NewGrid(4, 4)
+	ragged[2] = ragged[2][:3]
+
+	tests := []struct {
+		name  string
+		grid  Grid
+		valid bool
+	}{
+		{"exact", NewGrid(4, 4), true},
+		{"larger", NewGrid(5, 6), true},
+		{"too narrow", NewGrid(3, 4), false},
+		{"ragged", ragged, false},
+		{"nil", nil, false},
+	}
+	for _, tt := range tests {
+		err := tt.grid.Validate(4, 4)
+		if tt.valid && err != nil {
+			t.Errorf("%s: unexpected error %v", tt.name, err)
+		}
+		if !tt.valid && !errors.Is(err, ErrInvalidGrid) {
+			t.Errorf("%s: expected ErrInvalidGrid, got %v", tt.name, err)
+		}
+	}
+	if err := NewGrid(4, 4).Validate(0, 4); !errors.Is(err, ErrInvalidGrid) {
+		t.Errorf("Empty domain: expected ErrInvalidGrid, got %v", err)
+	}
+}
+
+// BenchmarkGridAccess compares checked indexing with the unchecked fast path
+func BenchmarkGridAccess(b *testing.B) {
+	grid := NewGrid(256, 256)
+	b.Run("Index", func(b *testing.B) {
+		for n := 0; n < b.N; n++ {
+			for i := 0; i < 256; i++ {
+				for j := 0; j < 256; j++ {
+					grid[i][j] += 1
+				}
+			}
+		}
+	})
+	b.Run("Unchecked", func(b *testing.B) {
+		for n := 0; n < b.N; n++ {
+			for i := 0; i < 256; i++ {
+				for j := 0; j < 256; j++ {
+					grid.AddUnchecked(i, j, 1)
+				}
+			}
+		}
+	})
+}
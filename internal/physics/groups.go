@@ -0,0 +1,144 @@
+package physics
+
+import "math"
+
+// Group is a named subset of the particles, such as one progenitor of a
+// merger, tracked by particle index
+type Group struct {
+	Name    string
+	Members []int // Particle indices; out-of-range indices are skipped
+}
+
+// GroupStats are the diagnostics of one group
+type GroupStats struct {
+	Name                 string
+	Count                int
+	Mass                 float64
+	CenterX, CenterZ     float64 // Center of mass, averaged on the periodic domain
+	VelocityX, VelocityZ float64 // Center-of-mass velocity
+	Kinetic              float64 // Kinetic energy about the center-of-mass velocity
+	Potential            float64 // The group's share ½ Σ mΦ of the potential energy W of all particles
+}
+
+// Energy returns the internal kinetic plus potential energy
+func (g GroupStats) Energy() float64 {
+	return g.Kinetic + g.Potential
+}
+
+// RegionMembers returns the indices of the particles within radius of (x, z),
+// taking the nearest periodic image in a domain of width×height cells of size
+// cellSize (≤ 0 cells = not periodic along that axis)
+func RegionMembers(particles []*Particle, x, z, radius float64, width, height int, cellSize float64) []int {
+	var members []int
+	periodX, periodZ := float64(width)*cellSize, float64(height)*cellSize
+	for i, p := range particles {
+		dx, dz := nearestImage(p.Position.X-x, periodX), nearestImage(p.Position.Z-z, periodZ)
+		if dx*dx+dz*dz <= radius*radius {
+			members = append(members, i)
+		}
+	}
+	return members
+}
+
+// GroupIndex returns the group of each of n particles (-1 = none). A
+// particle in several groups belongs to the last
+func GroupIndex(groups []Group, n int) []int {
+	index := make([]int, n)
+	for i := range index {
+		index[i] = -1
+	}
+	for g, group := range groups {
+		for _, i := range group.Members {
+			if i >= 0 && i < n {
+				index[i] = g
+			}
+		}
+	}
+	return index
+}
+
+// MeasureGroups returns the diagnostics of each group, with Φ interpolated
+// from potentialGrid. Centers are averaged on the periodic domain of
+// width×height cells of size dx, so a group straddling an edge is centered
+// where it is
+func MeasureGroups(particles []*Particle, groups []Group, potentialGrid Grid, width, height int, dx float64) []GroupStats {
+	stats := make([]GroupStats, len(groups))
+	for g, group := range groups {
+		stats[g] = measureGroup(particles, group, potentialGrid, width, height, dx)
+	}
+	return stats
+}
+
+// measureGroup returns the diagnostics of one group
+func measureGroup(particles []*Particle, group Group, potentialGrid Grid, width, height int, dx float64) GroupStats {
+	s := GroupStats{Name: group.Name}
+	centerX, centerZ := newPeriodicMean(float64(width)*dx), newPeriodicMean(float64(height)*dx)
+	var momentumX, momentumZ float64
+	for _, i := range group.Members {
+		if i < 0 || i >= len(particles) {
+			continue
+		}
+		p := particles[i]
+		mass := float64(p.Mass)
+		s.Count++
+		s.Mass += mass
+		centerX.add(p.Position.X, mass)
+		centerZ.add(p.Position.Z, mass)
+		momentumX += mass * p.Velocity.X
+		momentumZ += mass * p.Velocity.Z
+		if potentialGrid != nil {
+			s.Potential += 0.5 * mass * InterpolateGrid(potentialGrid, p.Position.X, p.Position.Z, dx)
+		}
+	}
+	if s.Mass <= 0 {
+		return s
+	}
+	s.CenterX, s.CenterZ = centerX.mean(), centerZ.mean()
+	s.VelocityX, s.VelocityZ = momentumX/s.Mass, momentumZ/s.Mass
+	for _, i := range group.Members {
+		if i < 0 || i >= len(particles) {
+			continue
+		}
+		p := particles[i]
+		vx, vz := p.Velocity.X-s.VelocityX, p.Velocity.Z-s.VelocityZ
+		s.Kinetic += 0.5 * float64(p.Mass) * (vx*vx + p.Velocity.Y*p.Velocity.Y + vz*vz)
+	}
+	return s
+}
+
+// periodicMean is a weighted mean of coordinates on a periodic axis, taken as
+// the direction of the mean of the points on a circle so that wrapping does
+// not pull it towards the middle of the domain
+type periodicMean struct {
+	period        float64 // ≤ 0 = not periodic
+	sum, cos, sin float64
+	weight        float64
+}
+
+// newPeriodicMean starts a mean on an axis of the given physical period
+func newPeriodicMean(period float64) periodicMean {
+	return periodicMean{period: period}
+}
+
+// add adds coordinate v with weight w
+func (m *periodicMean) add(v, w float64) {
+	m.weight += w
+	if m.period <= 0 {
+		m.sum += w * v
+		return
+	}
+	angle := 2 * math.Pi * v / m.period
+	m.cos += w * math.Cos(angle)
+	m.sin += w * math.Sin(angle)
+}
+
+// mean returns the mean, in [-period/2, period/2) on a periodic axis
+func (m *periodicMean) mean() float64 {
+	if m.weight <= 0 {
+		return 0
+	}
+	if m.period <= 0 {
+		return m.sum / m.weight
+	}
+	return m.period * math.Atan2(m.sin, m.cos) / (2 * math.Pi)
+}
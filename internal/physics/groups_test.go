@@ -0,0 +1,65 @@
+package physics
+
+import (
+	"math"
+	"testing"
+)
+
+// TestMeasureGroups tests the mass, center, velocity and energy of groups
+func TestMeasureGroups(t *testing.T) {
+	particles := []*Particle{
+		NewParticle(1, -1, 0, 0, 0, 0, 1),
+		NewParticle(1, 1, 0, 0, 0, 0, -1),
+		NewParticle(2, 10, 0, 10, 3, 0, 0),
+	}
+	groups := []Group{{Name: "pair", Members: []int{0, 1}}, {Name: "single", Members: []int{2, 7}}}
+	stats := MeasureGroups(particles, groups, nil, 0, 0, 1)
+
+	pair := stats[0]
+	if pair.Name != "pair" || pair.Count != 2 || pair.Mass != 2 || pair.CenterX != 0 || pair.CenterZ != 0 {
+		t.Errorf("Unexpected pair stats %+v", pair)
+	}
+	if pair.Kinetic != 1 || pair.VelocityZ != 0 {
+		t.Errorf("Expected internal kinetic energy 1 at rest, got %g moving at %g", pair.Kinetic, pair.VelocityZ)
+	}
+	single := stats[1]
+	if single.Count != 1 || single.VelocityX != 3 || single.Kinetic != 0 || single.CenterX != 10 {
+		t.Errorf("Expected one particle at rest in its own frame, got %+v", single)
+	}
+}
+
+// TestMeasureGroupsPeriodic tests centering a group straddling the domain edge
+func TestMeasureGroupsPeriodic(t *testing.T) {
+	particles := []*Particle{
+		NewParticle(1, 31, 0, 0, 0, 0, 0),
+		NewParticle(1, -31, 0, 0, 0, 0, 0),
+	}
+	stats := MeasureGroups(particles, []Group{{Name: "edge", Members: []int{0, 1}}}, nil, 64, 64, 1)
+	if x := stats[0].CenterX; math.Abs(math.Abs(x)-32) > 1e-9 {
+		t.Errorf("Expected the center on the edge at ±32, got %g", x)
+	}
+}
+
+// TestRegionMembers tests tagging a circle, across the periodic edge
+func TestRegionMembers(t *testing.T) {
+	particles := []*Particle{
+		NewParticle(1, 0, 0, 0, 0, 0, 0),
+		NewParticle(1, 3, 0, 0, 0, 0, 0),
+		NewParticle(1, 31, 0, 0, 0, 0, 0),
+	}
+	members := RegionMembers(particles, -31, 0, 2, 64, 64, 1)
+	if len(members) != 1 || members[0] != 2 {
+		t.Errorf("Expected particle 2 through the edge, got %v", members)
+	}
+	if members := RegionMembers(particles, 0, 0, 2, 64, 64, 1); len(members) != 1 || members[0] != 0 {
+		t.Errorf("Expected particle 0, got %v", members)
+	}
+}
+
+// TestGroupIndex tests that later groups win overlapping particles
+func TestGroupIndex(t *testing.T) {
+	index := GroupIndex([]Group{{Members: []int{0, 1}}, {Members: []int{1, 5}}}, 3)
+	if index[0] != 0 || index[1] != 1 || index[2] != -1 {
+		t.Errorf("Unexpected group index %v", index)
+	}
+}
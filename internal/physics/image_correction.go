@@ -0,0 +1,78 @@
+package physics
+
+// pmForceScale is the factor the PM steps apply to grid accelerations (their
+// forceCorrectionFactor); the correction removes images of what they apply
+const pmForceScale = 0.5
+
+// ImageCorrection approximately removes the pull of the periodic images from
+// PM forces, for a system meant to be isolated. Near a source, the periodic
+// potential differs from the isolated one by a smooth field: the uniform
+// background that the FFT solve subtracts, plus the images. To leading order
+// that field is quadratic, so summed over all particles it is a harmonic
+// force about the center of mass, a = M·(κx·(x-X), κz·(z-Z)), which this
+// force cancels. Higher orders grow as (size/box)², so the correction is
+// accurate for systems spanning up to about a quarter of the box. Positions
+// are not wrapped: the system must not straddle the box edge
+type ImageCorrection struct {
+	KappaX, KappaZ float64 // Image acceleration per unit mass and distance along X and Z
+}
+
+// NewImageCorrection calibrates the correction for a periodic domain of
+// width×height cells of size dx from the FFT solver itself: it solves for a unit mass and compares
+// the grid acceleration along each axis to the isolated 2G/r. Fitting two
+// distances removes the next order of the expansion. On a square box κ is
+// 2πG/L², the background alone, since the images' quadratic part cancels
+func NewImageCorrection(width, height int, dx, gravitationalConstant float64) ImageCorrection {
+	mass := NewGrid(width, height)
+	mass[width/2][height/2] = 1 // The origin is a grid node, so the mass sits in one cell
+	potential := NewGrid(width, height)
+	SolvePoissonFFTInto(potential, mass, dx, gravitationalConstant)
+	field := NewForceField(width, height, dx)
+	CalculateGradientInto(field, potential)
+
+	// Outward excess of the periodic acceleration at distance r along an axis,
+	// κr + βr³, fitted at r and 2r
+	kappa := func(extent int, accel func(r int) float64) float64 {
+		r := extent / 8
+		if r < 1 {
+			return 0
+		}
+		excess := func(r int) float64 {
+			distance := float64(r) * dx
+			return (accel(r) + 2*gravitationalConstant/distance) / distance
+		}
+		e1, e2 := excess(r), excess(2*r)
+		return (4*e1 - e2) / 3
+	}
+	return ImageCorrection{
+		KappaX: kappa(width, func(r int) float64 { return field.AccelFieldX[width/2+r][height/2] }),
+		KappaZ: kappa(height, func(r int) float64 { return field.AccelFieldZ[width/2][height/2+r] }),
+	}
+}
+
+// Acceleration returns the correction at (x, z) for particles of total mass
+// totalMass centered on (centerX, centerZ), before the PM force scale
+func (c ImageCorrection) Acceleration(x, z, totalMass, centerX, centerZ float64) (ax, az float64) {
+	return -totalMass * c.KappaX * (x - centerX), -totalMass * c.KappaZ * (z - centerZ)
+}
+
+// Kick adds the correction, scaled like the PM forces, times dt to each velocity
+func (c ImageCorrection) Kick(particles []*Particle, dt float64) {
+	var totalMass, sumX, sumZ float64
+	for _, p := range particles {
+		m := float64(p.Mass)
+		totalMass += m
+		sumX += m * p.Position.X
+		sumZ += m * p.Position.Z
+	}
+	if totalMass == 0 {
+		return
+	}
+	centerX, centerZ := sumX/totalMass, sumZ/totalMass
+	scale := pmForceScale * dt
+	for _, p := range particles {
+		ax, az := c.Acceleration(p.Position.X, p.Position.Z, totalMass, centerX, centerZ)
+		p.Velocity.X += float64(ax * scale)
+		p.Velocity.Z += float64(az * scale)
+	}
+}
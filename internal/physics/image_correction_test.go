@@ -0,0 +1,83 @@
+package physics
+
+import (
+	"math"
+	"testing"
+)
+
+// TestImageCorrectionCalibration tests the calibrated coefficients against
+// the background term: 2πG/L² on a square box, and a trace of 4πG/A on a
+// rectangular one
+func TestImageCorrectionCalibration(t *testing.T) {
+	const g = 1.5
+	square := NewImageCorrection(256, 256, 1, g)
+	want := 2 * math.Pi * g / (256 * 256)
+	if math.Abs(square.KappaX-want) > 0.02*want || math.Abs(square.KappaZ-want) > 0.02*want {
+		t.Errorf("Expected κ = %g on a square box, got (%g, %g)", want, square.KappaX, square.KappaZ)
+	}
+
+	rect := NewImageCorrection(512, 128, 1, g)
+	trace := 4 * math.Pi * g / (512 * 128)
+	if got := rect.KappaX + rect.KappaZ; math.Abs(got-trace) > 0.03*trace {
+		t.Errorf("Expected κx + κz = %g on a rectangular box, got %g", trace, got)
+	}
+	if rect.KappaX >= rect.KappaZ {
+		t.Errorf("Expected the short axis to feel its images more, got (%g, %g)", rect.KappaX, rect.KappaZ)
+	}
+}
+
+// TestImageCorrectionAccuracy tests that the correction brings PM forces on a
+// compact ring of masses closer to the isolated direct forces
+func TestImageCorrectionAccuracy(t *testing.T) {
+	const g, size = 1.0, 256
+	var particles []*Particle
+	for k := 0; k < 8; k++ {
+		angle := 2 * math.Pi * float64(k) / 8
+		r := 24.0 + 8*float64(k%2)
+		particles = append(particles, NewParticle(1+float64(k%3), 10+r*math.Cos(angle), 0, -5+r*math.Sin(angle), 0, 0, 0))
+	}
+
+	wantX, wantZ := DirectAccelerations(particles, g, 0, 1)
+
+	mass := DepositMassToGrid(particles, size, size, nil)
+	field := CalculateGradient(SolvePoissonFFT(mass, size, size, 1, g), size, size, 1)
+	correction := NewImageCorrection(size, size, 1, g)
+
+	var totalMass, sumX, sumZ float64
+	for _, p := range particles {
+		totalMass += float64(p.Mass)
+		sumX += float64(p.Mass) * p.Position.X
+		sumZ += float64(p.Mass) * p.Position.Z
+	}
+
+	var before, after float64
+	for i, p := range particles {
+		ax, az := InterpolateAcceleration(p.Position, field)
+		cx, cz := correction.Acceleration(p.Position.X, p.Position.Z, totalMass, sumX/totalMass, sumZ/totalMass)
+		before += math.Hypot(ax-wantX[i], az-wantZ[i])
+		after += math.Hypot(ax+cx-wantX[i], az+cz-wantZ[i])
+	}
+	if after > before/3 {
+		t.Errorf("Expected the correction to cut the force error at least threefold, got %g -> %g", before, after)
+	}
+}
+
+// TestImageCorrectionKick tests that the kick pulls towards the center of mass
+// and conserves momentum
+func TestImageCorrectionKick(t *testing.T) {
+	c := ImageCorrection{KappaX: 0.01, KappaZ: 0.02}
+	particles := []*Particle{
+		NewParticle(1, -10, 0, 0, 0, 0, 0),
+		NewParticle(3, 10, 0, 4, 0, 0, 0),
+	}
+	c.Kick(particles, 1)
+
+	if particles[0].Velocity.X <= 0 || particles[1].Velocity.X >= 0 {
+		t.Errorf("Expected pulls towards the center of mass, got %v and %v", particles[0].Velocity, particles[1].Velocity)
+	}
+	px := float64(particles[0].Mass)*particles[0].Velocity.X + float64(particles[1].Mass)*particles[1].Velocity.X
+	pz := float64(particles[0].Mass)*particles[0].Velocity.Z + float64(particles[1].Mass)*particles[1].Velocity.Z
+	if math.Abs(px) > 1e-12 || math.Abs(pz) > 1e-12 {
+		t.Errorf("Expected zero net momentum, got (%g, %g)", px, pz)
+	}
+}
@@ -0,0 +1,97 @@
+package physics
+
+// DefaultRebuildInterval is the number of incremental deposits between full
+// re-deposits, which clear the rounding error the updates accumulate
+const DefaultRebuildInterval = 100
+
+// IncrementalDeposit keeps a Cloud-in-Cell mass grid up to date by moving
+// the contributions of the particles that changed since the last deposit:
+// each one's old contribution is subtracted and its new one added. A step
+// then costs O(particles) instead of O(cells + particles), which pays off
+// when there are few particles for the grid size, and particles that did
+// not move cost nothing
+type IncrementalDeposit struct {
+	RebuildInterval int // Deposits between full re-deposits (0 = DefaultRebuildInterval)
+
+	mesh       *Mesh                        // Mesh the grid belongs to
+	grid       Grid                         // Grid the records were deposited into
+	records    map[*Particle]*depositRecord // Last deposited state of each particle
+	generation uint64                       // Deposit number, to find removed particles
+	sinceBuild int                          // Incremental deposits since the last full one
+}
+
+// depositRecord is what a particle last deposited
+type depositRecord struct {
+	x, z, mass float64
+	generation uint64
+}
+
+// NewIncrementalDeposit creates a depositor onto grids of the mesh whose
+// first deposit is a full one
+func NewIncrementalDeposit(mesh *Mesh) *IncrementalDeposit {
+	return &IncrementalDeposit{mesh: mesh, records: make(map[*Particle]*depositRecord)}
+}
+
+// Deposit brings grid up to date with the particles, which may have moved,
+// changed mass, been added or been removed since the previous call. The grid
+// must not be written by anything else in between; a different grid than
+// last time, or a Reset, makes this a full deposit
+func (d *IncrementalDeposit) Deposit(grid Grid, particles []*Particle) {
+	interval := d.RebuildInterval
+	if interval <= 0 {
+		interval = DefaultRebuildInterval
+	}
+	if !sameGrid(d.grid, grid) || d.sinceBuild >= interval {
+		d.rebuild(grid, particles)
+		return
+	}
+	d.sinceBuild++
+	d.generation++
+
+	width, height := gridDims(grid)
+	dx := d.mesh.Dx()
+	for _, p := range particles {
+		x, z, mass := p.Position.X, p.Position.Z, float64(p.Mass)
+		r, ok := d.records[p]
+		switch {
+		case !ok:
+			r = &depositRecord{}
+			d.records[p] = r
+			depositCIC(grid, width, height, dx, x, z, mass)
+		case r.x != x || r.z != z || r.mass != mass:
+			depositCIC(grid, width, height, dx, r.x, r.z, -r.mass)
+			depositCIC(grid, width, height, dx, x, z, mass)
+		}
+		r.x, r.z, r.mass, r.generation = x, z, mass, d.generation
+	}
+	for p, r := range d.records {
+		if r.generation != d.generation {
+			depositCIC(grid, width, height, dx, r.x, r.z, -r.mass)
+			delete(d.records, p)
+		}
+	}
+}
+
+// Reset makes the next deposit a full one, for grids written elsewhere
+func (d *IncrementalDeposit) Reset() {
+	d.grid = nil
+}
+
+// rebuild clears grid and deposits every particle, recording what each added
+func (d *IncrementalDeposit) rebuild(grid Grid, particles []*Particle) {
+	depositMass(grid, particles, d.mesh, false) // Every particle, as the updates assume
+	clear(d.records)
+	d.generation++
+	for _, p := range particles {
+		d.records[p] = &depositRecord{x: p.Position.X, z: p.Position.Z, mass: float64(p.Mass), generation: d.generation}
+	}
+	d.grid, d.sinceBuild = grid, 0
+}
+
+// sameGrid reports whether a and b share their cells
+func sameGrid(a, b Grid) bool {
+	if len(a) != len(b) || len(a) == 0 || len(a[0]) != len(b[0]) || len(a[0]) == 0 {
+		return false
+	}
+	return &a[0][0] == &b[0][0]
+}
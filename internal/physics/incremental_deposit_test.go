@@ -0,0 +1,116 @@
+package physics
+
+import (
+	"math"
+	"math/rand"
+	"testing"
+)
+
+// maxGridDifference returns the largest absolute difference between two grids
+func maxGridDifference(a, b Grid) float64 {
+	worst := 0.0
+	for i := range a {
+		for j := range a[i] {
+			worst = math.Max(worst, math.Abs(a[i][j]-b[i][j]))
+		}
+	}
+	return worst
+}
+
+// TestIncrementalDeposit tests that moving, adding, removing and reweighing
+// particles keeps the grid equal to a full deposit
+func TestIncrementalDeposit(t *testing.T) {
+	rng := rand.New(rand.NewSource(3))
+	particles := make([]*Particle, 50)
+	for i := range particles {
+		particles[i] = &Particle{Position: NewVec3(rng.Float64()*60-30, 0, rng.Float64()*60-30), Mass: float32(1 + rng.Intn(20))}
+	}
+	grid, want := NewGrid(64, 64), NewGrid(64, 64)
+	d := NewIncrementalDeposit(nil)
+
+	for step := 0; step < 20; step++ {
+		for i, p := range particles {
+			if i%3 != 0 { // Leave some particles still
+				p.Position.X += rng.Float64() - 0.5
+				p.Position.Z += rng.Float64() - 0.5
+			}
+		}
+		switch step {
+		case 5:
+			particles = append(particles, &Particle{Position: NewVec3(1.5, 0, -2.5), Mass: 35})
+		case 10:
+			particles = particles[1:]
+		case 15:
+			particles[0].Mass *= 2
+		}
+
+		d.Deposit(grid, particles)
+		DepositMassToGridInto(want, particles, nil)
+		if diff := maxGridDifference(grid, want); diff > 1e-9 {
+			t.Fatalf("Step %d: incremental grid differs from a full deposit by %g", step, diff)
+		}
+	}
+}
+
+// TestIncrementalDepositRebuild tests that a new grid, Reset and the rebuild
+// interval fall back to full deposits
+func TestIncrementalDepositRebuild(t *testing.T) {
+	particles := []*Particle{{Position: NewVec3(0.3, 0, 0.7), Mass: 10}}
+	d := NewIncrementalDeposit(nil)
+	d.RebuildInterval = 2
+
+	grid := NewGrid(16, 16)
+	grid[0][0] = 99 // Stale content a full deposit clears
+	d.Deposit(grid, particles)
+	if grid[0][0] != 0 {
+		t.Error("Expected the first deposit to clear the grid")
+	}
+
+	particles[0].Position.X += 0.1
+	d.Deposit(grid, particles)
+	if d.sinceBuild != 1 {
+		t.Errorf("Expected an incremental deposit, got %d since the last rebuild", d.sinceBuild)
+	}
+	d.Deposit(grid, particles)
+	d.Deposit(grid, particles)
+	if d.sinceBuild != 0 {
+		t.Errorf("Expected a rebuild after the interval, got %d since the last rebuild", d.sinceBuild)
+	}
+
+	d.Deposit(grid, particles)
+	grid[0][0] = 99
+	d.Reset()
+	d.Deposit(grid, particles)
+	if grid[0][0] != 0 || d.sinceBuild != 0 {
+		t.Error("Expected a full deposit after Reset")
+	}
+}
+
+// BenchmarkDepositFull measures full deposition of few particles on a large grid
+func BenchmarkDepositFull(b *testing.B) {
+	particles := InitializeParticlesWithSeed(100, 1024, 1024, 1)
+	grid := NewGrid(1024, 1024)
+
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		for _, p := range particles {
+			p.Position.X += 0.01
+		}
+		DepositMassToGridInto(grid, particles, nil)
+	}
+}
+
+// BenchmarkDepositIncremental measures incremental deposition of the same particles
+func BenchmarkDepositIncremental(b *testing.B) {
+	particles := InitializeParticlesWithSeed(100, 1024, 1024, 1)
+	grid := NewGrid(1024, 1024)
+	d := NewIncrementalDeposit(nil)
+
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		for _, p := range particles {
+			p.Position.X += 0.01
+		}
+		d.Deposit(grid, particles)
+	}
+}
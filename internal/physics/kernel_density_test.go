@@ -0,0 +1,100 @@
+package physics
+
+import (
+	"math"
+	"testing"
+)
+
+// TestCubicSplineKernel2D tests that the kernel integrates to 1 and vanishes beyond h
+func TestCubicSplineKernel2D(t *testing.T) {
+	const h, step = 2.0, 0.01
+	integral := 0.0
+	for r := step / 2; r < h; r += step {
+		integral += CubicSplineKernel2D(r, h) * 2 * math.Pi * r * step
+	}
+	if math.Abs(integral-1) > 1e-3 {
+		t.Errorf("Expected the kernel to integrate to 1, got %g", integral)
+	}
+	if CubicSplineKernel2D(h*1.01, h) != 0 {
+		t.Error("Expected zero beyond the kernel radius")
+	}
+}
+
+// latticeParticles returns unit masses on a square lattice filling a size×size box
+func latticeParticles(size int, spacing float64) []*Particle {
+	var particles []*Particle
+	for x := -float64(size) / 2; x < float64(size)/2; x += spacing {
+		for z := -float64(size) / 2; z < float64(size)/2; z += spacing {
+			particles = append(particles, NewParticle(1, x, 0, z, 0, 0, 0))
+		}
+	}
+	return particles
+}
+
+// TestEstimateKernelDensityUniform tests the density of a uniform lattice
+func TestEstimateKernelDensityUniform(t *testing.T) {
+	particles := latticeParticles(32, 2)
+	k := EstimateKernelDensity(particles, 32, 32, 1, DefaultKernelNeighbours)
+
+	// One unit mass per 2x2 area, the same everywhere in the periodic box
+	for i, rho := range k.Density {
+		if math.Abs(rho-0.25)/0.25 > 0.05 {
+			t.Fatalf("Particle %d: expected density 0.25, got %g", i, rho)
+		}
+	}
+	if k.Smoothing[0] != k.Smoothing[len(particles)-1] {
+		t.Errorf("Expected equal smoothing lengths on a periodic lattice, got %g and %g", k.Smoothing[0], k.Smoothing[len(particles)-1])
+	}
+}
+
+// TestEstimateKernelDensityAdaptive tests that clustered particles get narrow kernels
+func TestEstimateKernelDensityAdaptive(t *testing.T) {
+	var particles []*Particle
+	for i := 0; i < 20; i++ {
+		angle := float64(i) * math.Pi / 10
+		particles = append(particles, NewParticle(1, 3*math.Cos(angle), 0, 3*math.Sin(angle), 0, 0, 0))
+	}
+	loner := NewParticle(1, 12, 0, 12, 0, 0, 0)
+	particles = append(particles, loner, NewParticle(1, math.NaN(), 0, 0, 0, 0, 0))
+
+	k := EstimateKernelDensity(particles, 32, 32, 1, 4)
+	if k.Smoothing[0] >= k.Smoothing[20] || k.Density[0] <= k.Density[20] {
+		t.Errorf("Expected the ring to be denser with narrower kernels than the loner, got h %g vs %g, density %g vs %g",
+			k.Smoothing[0], k.Smoothing[20], k.Density[0], k.Density[20])
+	}
+	if k.Smoothing[21] != 0 || k.Density[21] != 0 {
+		t.Errorf("Expected a non-finite particle to be skipped, got %g, %g", k.Smoothing[21], k.Density[21])
+	}
+
+	// Coincident particles keep a finite kernel
+	pair := []*Particle{NewParticle(1, 0, 0, 0, 0, 0, 0), NewParticle(1, 0, 0, 0, 0, 0, 0)}
+	if k := EstimateKernelDensity(pair, 32, 32, 1, 1); k.Smoothing[0] != minSmoothing || math.IsInf(k.Density[0], 0) {
+		t.Errorf("Expected the minimum smoothing length, got %+v", k)
+	}
+}
+
+// TestKernelDensityField tests that the field conserves mass and wraps periodically
+func TestKernelDensityField(t *testing.T) {
+	particles := []*Particle{
+		NewParticle(2, 0.3, 0, -0.4, 0, 0, 0),
+		NewParticle(3, 15.5, 0, 15.5, 0, 0, 0), // Kernel wraps across the corner
+		NewParticle(1, -10, 0, 6, 0, 0, 0),
+	}
+	k := EstimateKernelDensity(particles, 32, 32, 1, 2)
+	grid := NewGrid(32, 32)
+	grid[0][0] = 100 // Overwritten
+	k.FieldInto(grid, particles)
+
+	total := 0.0
+	for i := range grid {
+		for _, v := range grid[i] {
+			total += v
+		}
+	}
+	if math.Abs(total-6)/6 > 0.01 {
+		t.Errorf("Expected the field to hold the total mass 6, got %g", total)
+	}
+	if grid[0][0] <= 0 || grid[0][0] > 5 {
+		t.Errorf("Expected the wrapped kernel at the corner node, got %g", grid[0][0])
+	}
+}
@@ -0,0 +1,93 @@
+package physics
+
+import (
+	"math"
+	"testing"
+)
+
+// linearField returns a 64×64 field with acceleration k·(x, z), zero at the origin
+func linearField(k float64) *ForceField {
+	field := NewForceField(64, 64, 1)
+	for i := 0; i < 64; i++ {
+		for j := 0; j < 64; j++ {
+			field.AccelFieldX[i][j] = k * float64(i-32)
+			field.AccelFieldZ[i][j] = k * float64(j-32)
+		}
+	}
+	return field
+}
+
+// runTracker steps a tracker on a particle at rest at the origin for the given time
+func runTracker(field *ForceField, duration float64) []float64 {
+	particles := []*Particle{NewParticle(1, 0, 0, 0, 0, 0, 0)}
+	tracker := NewLyapunovTracker(particles, 1, 64, 64, 1, 1)
+	dt := float32(0.01)
+	for t := 0.0; t < duration; t += float64(dt) {
+		tracker.Step(particles, dt, field)
+	}
+	return tracker.Exponents()
+}
+
+// TestLyapunovUnstable tests the exponent of a saddle, where separations grow
+// as exp(√(k/2)·t) with the PM force scale
+func TestLyapunovUnstable(t *testing.T) {
+	k := 0.5
+	exponents := runTracker(linearField(k), 20)
+	want := math.Sqrt(k * pmForceScale)
+	if math.Abs(exponents[0]-want) > 0.05*want {
+		t.Errorf("Expected exponent %.3f, got %.3f", want, exponents[0])
+	}
+}
+
+// TestLyapunovStable tests that a harmonic well, where separations oscillate, has an exponent near zero
+func TestLyapunovStable(t *testing.T) {
+	exponents := runTracker(linearField(-0.5), 200)
+	if math.Abs(exponents[0]) > 0.02 {
+		t.Errorf("Expected an exponent near zero, got %.3f", exponents[0])
+	}
+}
+
+// TestLyapunovCellSize tests that a shadow wraps with its particle at the
+// edge of a domain of half-size cells instead of jumping to the far side
+func TestLyapunovCellSize(t *testing.T) {
+	particles := []*Particle{NewParticle(1, 15, 0, 0, 1, 0, 0)}
+	field := NewForceField(64, 64, 0.5)
+	tracker := NewLyapunovTracker(particles, 1, 64, 64, 0.5, 1)
+	dt := float32(0.01)
+	for step := 0; step < 300; step++ {
+		p := particles[0]
+		p.Position.X = wrapPeriodic(p.Position.X+p.Velocity.X*float64(dt), 32)
+		tracker.Step(particles, dt, field)
+	}
+	if d := math.Abs(tracker.shadows[0].x - particles[0].Position.X); d > 1e-3 {
+		t.Errorf("Expected the shadow beside its particle after the wrap, got %g apart", d)
+	}
+	if e := tracker.Exponents()[0]; e > 1 {
+		t.Errorf("Expected a small exponent for free flight, got %.3f", e)
+	}
+}
+
+// TestLyapunovTracked tests the tracked count and the summary
+func TestLyapunovTracked(t *testing.T) {
+	particles := InitializeParticlesWithSeed(5, 64, 64, 1)
+	if n := NewLyapunovTracker(particles, 10, 64, 64, 1, 1).Tracked(); n != 5 {
+		t.Errorf("Expected all 5 particles tracked, got %d", n)
+	}
+	if n := NewLyapunovTracker(particles, 2, 64, 64, 1, 1).Tracked(); n != 2 {
+		t.Errorf("Expected 2 particles tracked, got %d", n)
+	}
+	mean, max := LyapunovSummary([]float64{0.1, 0.3})
+	if math.Abs(mean-0.2) > 1e-12 || max != 0.3 {
+		t.Errorf("Expected mean 0.2 and max 0.3, got %g and %g", mean, max)
+	}
+}
+
+// TestWrapPeriodic tests wrapping coordinates onto the domain
+func TestWrapPeriodic(t *testing.T) {
+	tests := []struct{ v, want float64 }{{0, 0}, {33, -31}, {-33, 31}, {-32, -32}, {100, -28}}
+	for _, tt := range tests {
+		if got := wrapPeriodic(tt.v, 64); got != tt.want {
+			t.Errorf("wrapPeriodic(%g) = %g, want %g", tt.v, got, tt.want)
+		}
+	}
+}
@@ -132,6 +132,18 @@ func (m Mat4) Transpose() Mat4 {
 	return result
 }
 
+// ColumnMajor32 returns the matrix as float32 in column-major order, the
+// layout glUniformMatrix4fv expects without transposition
+func (m Mat4) ColumnMajor32() [16]float32 {
+	var out [16]float32
+	for c := 0; c < 4; c++ {
+		for r := 0; r < 4; r++ {
+			out[c*4+r] = float32(m[r][c])
+		}
+	}
+	return out
+}
+
 // Mat4LookAt creates a view matrix looking from eye to target
 func Mat4LookAt(eye, target, up Vec3) Mat4 {
 	// Calculate forward, right, and up vectors
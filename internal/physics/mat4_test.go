@@ -202,3 +202,17 @@ func TestMat4Transpose(t *testing.T) {
 		}
 	}
 }
+
+// TestMat4ColumnMajor32 tests the OpenGL uniform layout
+func TestMat4ColumnMajor32(t *testing.T) {
+	m := Mat4Translation(1, 2, 3)
+	out := m.ColumnMajor32()
+
+	// Column-major puts the translation in elements 12-14
+	if out[12] != 1 || out[13] != 2 || out[14] != 3 || out[15] != 1 {
+		t.Errorf("Translation not in the last column: %v", out)
+	}
+	if out[3] != 0 || out[7] != 0 || out[11] != 0 {
+		t.Errorf("Bottom row should be zero: %v", out)
+	}
+}
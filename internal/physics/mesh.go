@@ -0,0 +1,53 @@
+package physics
+
+import "sync/atomic"
+
+// Mesh is the particle mesh of one simulation: the physical size of its
+// cells. Positions, velocities and accelerations are physical, so a
+// width×height grid spans width·dx × height·dx: deposition and
+// interpolation divide positions by dx, the Poisson solve uses wave numbers
+// of the physical domain, gradients difference over 2dx and drifts wrap at
+// the physical edges. Grids still hold mass per cell. Each simulation owns
+// its mesh, so simulations with different meshes can step side by side. A
+// nil *Mesh is a mesh of unit cells, with which every kernel runs exactly
+// as in cell units
+//
+// Deterministic deposition accumulates masses in fixed point. Integer sums
+// do not depend on the order particles are deposited in, so runs stay
+// bit-identical when particles are reordered or deposited in parallel. The
+// other CPU kernels are deterministic either way: each force is summed by
+// one goroutine in a fixed order, and multiply-adds are rounded explicitly
+// so no architecture fuses them
+type Mesh struct {
+	CellSize      float64 // Physical size dx of a cell (0 = 1)
+	Deterministic bool    // Deposit masses in fixed point
+
+	stride atomic.Int32  // Deposit every stride-th particle, see SetDepositStride
+	offset atomic.Uint32 // Rotates the deposited subset from one deposit to the next
+}
+
+// Dx returns the physical size of a cell: CellSize, or 1 if unset
+func (m *Mesh) Dx() float64 {
+	if m == nil {
+		return 1
+	}
+	return spacing(m.CellSize)
+}
+
+// deterministic reports whether the mesh deposits in fixed point
+func (m *Mesh) deterministic() bool {
+	return m != nil && m.Deterministic
+}
+
+// Extent returns the physical extent of cells cells of the mesh
+func (m *Mesh) Extent(cells int) float64 {
+	return float64(cells) * m.Dx()
+}
+
+// spacing returns a configured cell size, or 1 if it is unset
+func spacing(cellSize float64) float64 {
+	if !(cellSize > 0) {
+		return 1
+	}
+	return cellSize
+}
@@ -0,0 +1,94 @@
+package physics
+
+import (
+	"math"
+	"testing"
+)
+
+// TestMeshCellSize tests that a nil mesh and non-positive sizes have unit cells
+func TestMeshCellSize(t *testing.T) {
+	var mesh *Mesh
+	if mesh.Dx() != 1 || mesh.Extent(64) != 64 {
+		t.Fatalf("Expected unit cells on a nil mesh, got %g and extent %g", mesh.Dx(), mesh.Extent(64))
+	}
+	mesh = &Mesh{CellSize: 0.25}
+	if mesh.Dx() != 0.25 || mesh.Extent(64) != 16 {
+		t.Errorf("Expected cell size 0.25 and extent 16, got %g and %g", mesh.Dx(), mesh.Extent(64))
+	}
+	if dx := (&Mesh{CellSize: -1}).Dx(); dx != 1 {
+		t.Errorf("Expected a negative size to mean 1, got %g", dx)
+	}
+}
+
+// TestCellSizeConvergence tests that a plane wave on a fixed physical domain
+// gets the exact potential at any resolution, and a gradient whose
+// second-order error falls fourfold each time the cell size is halved
+func TestCellSizeConvergence(t *testing.T) {
+	const domain = 64.0
+	k := 2 * math.Pi * 3 / domain
+
+	previous := math.Inf(1)
+	for _, cells := range []int{32, 64, 128, 256} {
+		dx := domain / float64(cells)
+
+		// Mass per cell of the density cos(kx)
+		mass := NewGrid(cells, cells)
+		for i := 0; i < cells; i++ {
+			x := (float64(i) - float64(cells)/2) * dx
+			for j := range mass[i] {
+				mass[i][j] = math.Cos(k*x) * dx * dx
+			}
+		}
+		potential := SolvePoissonFFT(mass, cells, cells, dx, 1)
+		field := CalculateGradient(potential, cells, cells, dx)
+
+		amplitude := 4 * math.Pi / (k * k)
+		worst := 0.0
+		for i := 0; i < cells; i++ {
+			x := (float64(i) - float64(cells)/2) * dx
+			if phi := -amplitude * math.Cos(k*x); math.Abs(potential[i][0]-phi) > 1e-9*amplitude {
+				t.Fatalf("%d cells: potential at %g = %g, want %g", cells, x, potential[i][0], phi)
+			}
+			worst = math.Max(worst, math.Abs(field.AccelFieldX[i][0]+amplitude*k*math.Sin(k*x)))
+		}
+		if ratio := previous / worst; ratio < 3.9 {
+			t.Errorf("%d cells: gradient error %g fell only %.2fx from the coarser grid", cells, worst, ratio)
+		}
+		previous = worst
+	}
+}
+
+// TestCellSizeScaling tests that a step of a system scaled by dx, on cells
+// of size dx with G scaled by dx² to keep its dynamics, matches the step of
+// the unscaled system on unit cells scaled by dx, with both stepped in turn
+func TestCellSizeScaling(t *testing.T) {
+	const width, height, dx = 32, 16, 0.125
+	reference := InitializeParticlesWithSeed(200, width, height, 3)
+	for i, p := range reference {
+		p.Velocity = NewVec3(math.Sin(float64(i)), 0, math.Cos(float64(i)))
+	}
+	scaled := make([]*Particle, len(reference))
+	for i, p := range reference {
+		q := *p
+		q.Position = NewVec3(p.Position.X*dx, 0, p.Position.Z*dx)
+		q.Velocity = NewVec3(p.Velocity.X*dx, 0, p.Velocity.Z*dx)
+		scaled[i] = &q
+	}
+
+	// The two systems step alternately, each on its own mesh
+	mesh := &Mesh{CellSize: dx}
+	for step := 0; step < 20; step++ {
+		RunTimeEvolution(reference, 0.1, width, height, nil, 1).Release()
+		RunTimeEvolution(scaled, 0.1, width, height, mesh, dx*dx).Release()
+	}
+
+	for i, p := range reference {
+		q := scaled[i]
+		if math.Abs(q.Position.X-p.Position.X*dx) > 1e-9 || math.Abs(q.Position.Z-p.Position.Z*dx) > 1e-9 ||
+			math.Abs(q.Velocity.X-p.Velocity.X*dx) > 1e-9 || math.Abs(q.Velocity.Z-p.Velocity.Z*dx) > 1e-9 {
+			t.Fatalf("Particle %d at (%g, %g) moving (%g, %g), want (%g, %g) moving (%g, %g)", i,
+				q.Position.X, q.Position.Z, q.Velocity.X, q.Velocity.Z,
+				p.Position.X*dx, p.Position.Z*dx, p.Velocity.X*dx, p.Velocity.Z*dx)
+		}
+	}
+}
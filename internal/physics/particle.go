@@ -27,3 +27,23 @@ func (p *Particle) KineticEnergy() float32 {
 	v2 := float32(p.Velocity.X*p.Velocity.X + p.Velocity.Y*p.Velocity.Y + p.Velocity.Z*p.Velocity.Z)
 	return 0.5 * p.Mass * v2
 }
+
+// TracerRadius is the display radius of tracer particles, which have no mass
+// to derive one from
+const TracerRadius = 0.15
+
+// NewTracer creates a massless tracer particle in the x-z plane. Tracers
+// follow the gravitational field and any extra forces but exert no force and
+// add nothing to the mass grid
+func NewTracer(px, pz, vx, vz float64) *Particle {
+	return &Particle{
+		Position: NewVec3(px, 0, pz),
+		Velocity: NewVec3(vx, 0, vz),
+		Radius:   TracerRadius,
+	}
+}
+
+// IsTracer reports whether the particle is a massless tracer
+func (p *Particle) IsTracer() bool {
+	return p.Mass == 0
+}
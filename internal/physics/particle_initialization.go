@@ -1,25 +1,35 @@
 package physics
 
 import (
-	"math"
 	"math/rand"
 )
 
 // InitializeParticles creates particles with random positions and masses
 func InitializeParticles(numParticles int, simulationWidth, simulationDepth float64) []*Particle {
+	return initializeParticles(numParticles, simulationWidth, simulationDepth, rand.Float32)
+}
+
+// InitializeParticlesWithSeed creates the same random particles for the same seed
+func InitializeParticlesWithSeed(numParticles int, simulationWidth, simulationDepth float64, seed int64) []*Particle {
+	rng := rand.New(rand.NewSource(seed))
+	return initializeParticles(numParticles, simulationWidth, simulationDepth, rng.Float32)
+}
+
+// initializeParticles creates random particles drawing from the given source of [0,1) floats
+func initializeParticles(numParticles int, simulationWidth, simulationDepth float64, random func() float32) []*Particle {
 	particles := make([]*Particle, numParticles)
 
 	for i := 0; i < numParticles; i++ {
-		mass := 20.0 + rand.Float32()*30.0
+		mass := 20.0 + random()*30.0
 		particles[i] = &Particle{
 			Position: NewVec3(
-				float64((rand.Float32()-0.5)*float32(simulationWidth)*0.8),
+				float64((random()-0.5)*float32(simulationWidth)*0.8),
 				0,
-				float64((rand.Float32()-0.5)*float32(simulationDepth)*0.8),
+				float64((random()-0.5)*float32(simulationDepth)*0.8),
 			),
 			Velocity: NewVec3(0, 0, 0),
 			Mass:     mass,
-			Radius:   float32(math.Pow(float64(mass/20.0), 1.0/3.0)) * 0.5,
+			Radius:   float32(DefaultRadius().Radius(float64(mass))),
 		}
 	}
 
@@ -35,7 +45,7 @@ func InitializeParticlesWithCentralMass(numParticles int, simulationWidth, simul
 		Position: NewVec3(0, 0, 0),
 		Velocity: NewVec3(0, 0, 0),
 		Mass:     float32(centralMass),
-		Radius:   float32(math.Pow(centralMass/20.0, 1.0/3.0)) * 0.5,
+		Radius:   float32(DefaultRadius().Radius(centralMass)),
 	}
 
 	return particles
@@ -130,3 +130,27 @@ func TestParticleDistribution(t *testing.T) {
 		}
 	}
 }
+
+// TestInitializeParticlesWithSeed tests that seeded initialization is reproducible
+func TestInitializeParticlesWithSeed(t *testing.T) {
+	a := InitializeParticlesWithSeed(50, 200.0, 200.0, 42)
+	b := InitializeParticlesWithSeed(50, 200.0, 200.0, 42)
+	c := InitializeParticlesWithSeed(50, 200.0, 200.0, 43)
+
+	for i := range a {
+		if a[i].Position != b[i].Position || a[i].Mass != b[i].Mass {
+			t.Fatalf("Particle %d differs for the same seed: %+v vs %+v", i, a[i], b[i])
+		}
+	}
+
+	same := true
+	for i := range a {
+		if a[i].Position != c[i].Position {
+			same = false
+			break
+		}
+	}
+	if same {
+		t.Error("Different seeds should produce different particles")
+	}
+}
@@ -0,0 +1,230 @@
+package physics
+
+// ParticleSystem stores particles as a struct of arrays. Each attribute lives
+// in its own contiguous slice, so deposition and integration loops stream
+// through memory and the arrays can be uploaded to the GPU without
+// per-particle marshaling. Index i in every slice refers to the same particle
+type ParticleSystem struct {
+	PosX, PosY, PosZ []float64
+	VelX, VelY, VelZ []float64
+	Mass             []float64
+	Radius           []float64
+}
+
+// NewParticleSystem creates a system of n zero-valued particles
+func NewParticleSystem(n int) *ParticleSystem {
+	return &ParticleSystem{
+		PosX:   make([]float64, n),
+		PosY:   make([]float64, n),
+		PosZ:   make([]float64, n),
+		VelX:   make([]float64, n),
+		VelY:   make([]float64, n),
+		VelZ:   make([]float64, n),
+		Mass:   make([]float64, n),
+		Radius: make([]float64, n),
+	}
+}
+
+// NewParticleSystemFromParticles copies particles into a new system
+func NewParticleSystemFromParticles(particles []*Particle) *ParticleSystem {
+	ps := NewParticleSystem(len(particles))
+	for i, p := range particles {
+		ps.Set(i, p)
+	}
+	return ps
+}
+
+// Len returns the number of particles
+func (ps *ParticleSystem) Len() int {
+	return len(ps.Mass)
+}
+
+// Append adds a copy of p to the end of the system
+func (ps *ParticleSystem) Append(p *Particle) {
+	ps.PosX = append(ps.PosX, p.Position.X)
+	ps.PosY = append(ps.PosY, p.Position.Y)
+	ps.PosZ = append(ps.PosZ, p.Position.Z)
+	ps.VelX = append(ps.VelX, p.Velocity.X)
+	ps.VelY = append(ps.VelY, p.Velocity.Y)
+	ps.VelZ = append(ps.VelZ, p.Velocity.Z)
+	ps.Mass = append(ps.Mass, float64(p.Mass))
+	ps.Radius = append(ps.Radius, float64(p.Radius))
+}
+
+// Get returns a copy of particle i as a Particle
+func (ps *ParticleSystem) Get(i int) *Particle {
+	return &Particle{
+		Position: ps.Position(i),
+		Velocity: ps.Velocity(i),
+		Mass:     float32(ps.Mass[i]),
+		Radius:   float32(ps.Radius[i]),
+	}
+}
+
+// Set overwrites particle i with the values of p
+func (ps *ParticleSystem) Set(i int, p *Particle) {
+	ps.SetPosition(i, p.Position)
+	ps.SetVelocity(i, p.Velocity)
+	ps.Mass[i] = float64(p.Mass)
+	ps.Radius[i] = float64(p.Radius)
+}
+
+// Position returns the position of particle i
+func (ps *ParticleSystem) Position(i int) Vec3 {
+	return Vec3{X: ps.PosX[i], Y: ps.PosY[i], Z: ps.PosZ[i]}
+}
+
+// SetPosition sets the position of particle i
+func (ps *ParticleSystem) SetPosition(i int, v Vec3) {
+	ps.PosX[i], ps.PosY[i], ps.PosZ[i] = v.X, v.Y, v.Z
+}
+
+// Velocity returns the velocity of particle i
+func (ps *ParticleSystem) Velocity(i int) Vec3 {
+	return Vec3{X: ps.VelX[i], Y: ps.VelY[i], Z: ps.VelZ[i]}
+}
+
+// SetVelocity sets the velocity of particle i
+func (ps *ParticleSystem) SetVelocity(i int, v Vec3) {
+	ps.VelX[i], ps.VelY[i], ps.VelZ[i] = v.X, v.Y, v.Z
+}
+
+// ToParticles returns the system as newly allocated particles
+func (ps *ParticleSystem) ToParticles() []*Particle {
+	particles := make([]*Particle, ps.Len())
+	for i := range particles {
+		particles[i] = ps.Get(i)
+	}
+	return particles
+}
+
+// CopyToParticles writes the system state into existing particles of the same length
+func (ps *ParticleSystem) CopyToParticles(particles []*Particle) {
+	for i, p := range particles {
+		p.Position = ps.Position(i)
+		p.Velocity = ps.Velocity(i)
+		p.Mass = float32(ps.Mass[i])
+		p.Radius = float32(ps.Radius[i])
+	}
+}
+
+// DepositMass clears grid and deposits all particle masses into it using
+// Cloud-in-Cell on cells of size dx
+func (ps *ParticleSystem) DepositMass(grid Grid, dx float64) {
+	ClearGrid(grid)
+	width, height := gridDims(grid)
+	if width == 0 || height == 0 {
+		return
+	}
+	grid.mustCover(width, height, "mass")
+	for i, mass := range ps.Mass {
+		depositCIC(grid, width, height, dx, ps.PosX[i], ps.PosZ[i], mass)
+	}
+}
+
+// Kick updates velocities from the acceleration field
+func (ps *ParticleSystem) Kick(forceField *ForceField, dt float32, forceCorrectionFactor float32) {
+	forceField.checkDims()
+	scale := float64(dt) * float64(forceCorrectionFactor)
+	for i := range ps.VelX {
+		ax, az := interpolateAccelerationXZ(ps.PosX[i], ps.PosZ[i], forceField)
+		ps.VelX[i] += ax * scale
+		ps.VelZ[i] += az * scale
+	}
+}
+
+// Drift updates positions from velocities with wrap-around boundaries at the
+// edges of a width×height grid of cells of size dx
+func (ps *ParticleSystem) Drift(dt float32, width, height int, dx float64) {
+	extentX, extentZ := float64(width)*dx, float64(height)*dx
+	for i := range ps.PosX {
+		ps.PosX[i] = wrapCoordinate(ps.PosX[i]+ps.VelX[i]*float64(dt), extentX)
+		ps.PosZ[i] = wrapCoordinate(ps.PosZ[i]+ps.VelZ[i]*float64(dt), extentZ)
+	}
+}
+
+// Evolve performs a complete kick-drift-kick step with PM force calculation
+// on cells of size dx, matching RunTimeEvolution. The returned force field
+// may be handed back with Release
+func (ps *ParticleSystem) Evolve(dt float32, width, height int, dx, gravitationalConstant float64) *ForceField {
+	massGrid := acquireGrid(width, height)
+	defer releaseGrid(massGrid)
+	potentialGrid := acquireGrid(width, height)
+	defer releaseGrid(potentialGrid)
+	forceField := AcquireForceField(width, height, dx)
+
+	forceCorrectionFactor := float32(0.5)
+
+	ps.DepositMass(massGrid.rows, dx)
+	SolvePoissonFFTInto(potentialGrid.rows, massGrid.rows, dx, gravitationalConstant)
+	CalculateGradientInto(forceField, potentialGrid.rows)
+	ps.Kick(forceField, dt*0.5, forceCorrectionFactor)
+
+	ps.Drift(dt, width, height, dx)
+
+	ps.DepositMass(massGrid.rows, dx)
+	SolvePoissonFFTInto(potentialGrid.rows, massGrid.rows, dx, gravitationalConstant)
+	CalculateGradientInto(forceField, potentialGrid.rows)
+	ps.Kick(forceField, dt*0.5, forceCorrectionFactor)
+
+	return forceField
+}
+
+// KineticEnergy returns the total kinetic energy of the system
+func (ps *ParticleSystem) KineticEnergy() float64 {
+	var total float64
+	for i, mass := range ps.Mass {
+		total += 0.5 * mass * (ps.VelX[i]*ps.VelX[i] + ps.VelY[i]*ps.VelY[i] + ps.VelZ[i]*ps.VelZ[i])
+	}
+	return total
+}
+
+// PackPositionsMass writes x, y, z, mass per particle as float32 (std430 vec4
+// layout) into dst, growing it if needed, and returns the packed slice
+func (ps *ParticleSystem) PackPositionsMass(dst []float32) []float32 {
+	n := ps.Len() * 4
+	if cap(dst) < n {
+		dst = make([]float32, n)
+	}
+	dst = dst[:n]
+	for i, mass := range ps.Mass {
+		dst[i*4] = float32(ps.PosX[i])
+		dst[i*4+1] = float32(ps.PosY[i])
+		dst[i*4+2] = float32(ps.PosZ[i])
+		dst[i*4+3] = float32(mass)
+	}
+	return dst
+}
+
+// PackPositions writes x, y, z per particle as float32 into dst, growing it
+// if needed, and returns the packed slice. Renderers reinterpret the triples
+// as vectors instead of converting each particle
+func (ps *ParticleSystem) PackPositions(dst []float32) []float32 {
+	n := ps.Len() * 3
+	if cap(dst) < n {
+		dst = make([]float32, n)
+	}
+	dst = dst[:n]
+	for i := range ps.Mass {
+		dst[i*3] = float32(ps.PosX[i])
+		dst[i*3+1] = float32(ps.PosY[i])
+		dst[i*3+2] = float32(ps.PosZ[i])
+	}
+	return dst
+}
+
+// PackParticlePositions is PackPositions for particles not held in a
+// ParticleSystem
+func PackParticlePositions(dst []float32, particles []*Particle) []float32 {
+	n := len(particles) * 3
+	if cap(dst) < n {
+		dst = make([]float32, n)
+	}
+	dst = dst[:n]
+	for i, p := range particles {
+		dst[i*3] = float32(p.Position.X)
+		dst[i*3+1] = float32(p.Position.Y)
+		dst[i*3+2] = float32(p.Position.Z)
+	}
+	return dst
+}
@@ -0,0 +1,151 @@
+package physics
+
+import (
+	"math"
+	"testing"
+)
+
+// TestParticleSystemRoundTrip tests conversion to and from particles
+func TestParticleSystemRoundTrip(t *testing.T) {
+	particles := []*Particle{
+		NewParticle(8.0, 1, 2, 3, 4, 5, 6),
+		NewParticle(27.0, -1, 0, -3, 0.5, 0, -0.5),
+	}
+
+	ps := NewParticleSystemFromParticles(particles)
+	if ps.Len() != 2 {
+		t.Fatalf("Expected 2 particles, got %d", ps.Len())
+	}
+	if ps.PosZ[0] != 3 || ps.VelY[0] != 5 || ps.Mass[1] != 27 {
+		t.Errorf("Unexpected arrays: %+v", ps)
+	}
+
+	back := ps.ToParticles()
+	for i := range particles {
+		if *back[i] != *particles[i] {
+			t.Errorf("Particle %d changed in round trip: %+v vs %+v", i, back[i], particles[i])
+		}
+	}
+
+	ps.SetVelocity(1, NewVec3(9, 9, 9))
+	ps.Append(NewParticle(1.0, 0, 0, 0, 0, 0, 0))
+	if ps.Len() != 3 || ps.Velocity(1).X != 9 {
+		t.Errorf("Unexpected state after edits: %+v", ps)
+	}
+
+	ps.CopyToParticles(particles)
+	if particles[1].Velocity.Z != 9 {
+		t.Errorf("CopyToParticles did not update velocity: %+v", particles[1].Velocity)
+	}
+}
+
+// TestParticleSystemMatchesParticles tests that SoA evolution matches RunTimeEvolution exactly
+func TestParticleSystemMatchesParticles(t *testing.T) {
+	const size = 32
+	particles := InitializeParticlesWithSeed(100, size, size, 11)
+	ps := NewParticleSystemFromParticles(particles)
+
+	for step := 0; step < 5; step++ {
+		RunTimeEvolution(particles, 0.05, size, size, nil, 1.0).Release()
+		ps.Evolve(0.05, size, size, 1, 1.0).Release()
+	}
+
+	for i, p := range particles {
+		if ps.Position(i) != p.Position || ps.Velocity(i) != p.Velocity {
+			t.Fatalf("Particle %d diverged: SoA %v/%v vs AoS %v/%v",
+				i, ps.Position(i), ps.Velocity(i), p.Position, p.Velocity)
+		}
+	}
+
+	expected := ComputeDiagnostics(particles).KineticEnergy
+	if math.Abs(ps.KineticEnergy()-expected) > 1e-6*math.Max(1, expected) {
+		t.Errorf("Kinetic energy mismatch: %f vs %f", ps.KineticEnergy(), expected)
+	}
+}
+
+// TestParticleSystemDepositMass tests that deposition matches the particle version
+func TestParticleSystemDepositMass(t *testing.T) {
+	const size = 16
+	particles := InitializeParticlesWithSeed(30, size, size, 3)
+	ps := NewParticleSystemFromParticles(particles)
+
+	expected := DepositMassToGrid(particles, size, size, nil)
+	grid := NewGrid(size, size)
+	ps.DepositMass(grid, 1)
+
+	for i := range grid {
+		for j := range grid[i] {
+			if grid[i][j] != expected[i][j] {
+				t.Fatalf("Mass mismatch at (%d,%d): %f vs %f", i, j, grid[i][j], expected[i][j])
+			}
+		}
+	}
+}
+
+// TestPackPositionsMass tests the GPU upload layout
+func TestPackPositionsMass(t *testing.T) {
+	ps := NewParticleSystemFromParticles([]*Particle{NewParticle(2, 1, 2, 3, 0, 0, 0), NewParticle(4, 5, 6, 7, 0, 0, 0)})
+
+	packed := ps.PackPositionsMass(nil)
+	want := []float32{1, 2, 3, 2, 5, 6, 7, 4}
+	if len(packed) != len(want) {
+		t.Fatalf("Expected %d floats, got %d", len(want), len(packed))
+	}
+	for i := range want {
+		if packed[i] != want[i] {
+			t.Errorf("Index %d: expected %f, got %f", i, want[i], packed[i])
+		}
+	}
+
+	// Reuses capacity when possible
+	reused := ps.PackPositionsMass(make([]float32, 0, 16))
+	if cap(reused) != 16 {
+		t.Errorf("Expected buffer to be reused, got cap %d", cap(reused))
+	}
+}
+
+// TestPackPositions tests that both packings hold the positions as float32
+// triples and reuse the buffer
+func TestPackPositions(t *testing.T) {
+	particles := []*Particle{NewParticle(2, 1, 2, 3, 0, 0, 0), NewParticle(4, 5, 6, 7, 0, 0, 0)}
+	want := []float32{1, 2, 3, 5, 6, 7}
+	for name, packed := range map[string][]float32{
+		"system":    NewParticleSystemFromParticles(particles).PackPositions(nil),
+		"particles": PackParticlePositions(nil, particles),
+	} {
+		if len(packed) != len(want) {
+			t.Fatalf("%s: expected %d floats, got %d", name, len(want), len(packed))
+		}
+		for i := range want {
+			if packed[i] != want[i] {
+				t.Errorf("%s index %d: expected %f, got %f", name, i, want[i], packed[i])
+			}
+		}
+	}
+
+	if reused := PackParticlePositions(make([]float32, 0, 16), particles); cap(reused) != 16 {
+		t.Errorf("Expected buffer to be reused, got cap %d", cap(reused))
+	}
+}
+
+// BenchmarkDepositMassParticles measures deposition from []*Particle
+func BenchmarkDepositMassParticles(b *testing.B) {
+	particles := InitializeParticlesWithSeed(100000, 256, 256, 1)
+	grid := NewGrid(256, 256)
+
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		DepositMassToGridInto(grid, particles, nil)
+	}
+}
+
+// BenchmarkDepositMassParticleSystem measures deposition from a ParticleSystem
+func BenchmarkDepositMassParticleSystem(b *testing.B) {
+	ps := NewParticleSystemFromParticles(InitializeParticlesWithSeed(100000, 256, 256, 1))
+	grid := NewGrid(256, 256)
+
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		ps.DepositMass(grid, 1)
+	}
+}
@@ -58,3 +58,28 @@ func TestParticleKineticEnergy(t *testing.T) {
 		t.Errorf("Expected kinetic energy %f, got %f", expected, ke)
 	}
 }
+
+// TestTracer tests that tracers are massless and visible
+func TestTracer(t *testing.T) {
+	tracer := NewTracer(1, 2, 3, 4)
+	if !tracer.IsTracer() || tracer.Radius != TracerRadius {
+		t.Errorf("Expected a massless tracer of radius %v, got %+v", TracerRadius, tracer)
+	}
+	if tracer.Position != NewVec3(1, 0, 2) || tracer.Velocity != NewVec3(3, 0, 4) {
+		t.Errorf("Expected the tracer in the x-z plane, got %+v", tracer)
+	}
+	if NewParticle(1, 0, 0, 0, 0, 0, 0).IsTracer() {
+		t.Error("Expected a massive particle not to be a tracer")
+	}
+
+	// Tracers feel the field but deposit no mass
+	grid := NewGrid(16, 16)
+	DepositMassToGridInto(grid, []*Particle{tracer}, nil)
+	for i := range grid {
+		for _, v := range grid[i] {
+			if v != 0 {
+				t.Fatalf("Expected an empty mass grid, got %v", v)
+			}
+		}
+	}
+}
@@ -1,7 +1,9 @@
-package physics
+package physics_test
 
 import (
 	"math"
+	"relativity_simulation_2d/internal/physics"
+	"relativity_simulation_2d/pkg/testkit"
 	"testing"
 )
 
@@ -19,23 +21,23 @@ func TestPhysicsEngineIntegration(t *testing.T) {
 	numSteps := 50               // Shorter simulation to reduce numerical drift
 
 	// Initialize particles in a controlled way for testing
-	particles := InitializeParticles(numParticles, simulationWidth, simulationHeight)
+	particles := physics.InitializeParticles(numParticles, simulationWidth, simulationHeight)
 
 	// Record initial state for conservation law checks
-	initialMomentum := calculateTotalMomentum(particles)
+	initialMomentum := testkit.TotalMomentum(particles)
 
 	// Run the physics simulation for multiple steps using RunTimeEvolution
 	for step := 0; step < numSteps; step++ {
 		// Use the complete physics pipeline function
-		forceField := RunTimeEvolution(particles, dt, width, height, gravitationalConstant)
+		forceField := physics.RunTimeEvolution(particles, dt, width, height, nil, gravitationalConstant)
 		if forceField == nil {
 			t.Fatal("RunTimeEvolution returned nil force field")
 		}
 	}
 
 	// Verify conservation laws
-	finalKE := calculateKineticEnergy(particles)
-	finalMomentum := calculateTotalMomentum(particles)
+	finalKE := testkit.KineticEnergy(particles)
+	finalMomentum := testkit.TotalMomentum(particles)
 
 	// For Particle-Mesh (PM) methods with force correction factors,
 	// perfect energy conservation is not expected. We just check that
@@ -94,7 +96,7 @@ func TestPhysicsEngineWithCentralMass(t *testing.T) {
 	numSteps := 1000
 
 	// Initialize with central mass
-	particles := InitializeParticlesWithCentralMass(numParticles, simulationWidth, simulationHeight, centralMass)
+	particles := physics.InitializeParticlesWithCentralMass(numParticles, simulationWidth, simulationHeight, centralMass)
 
 	// Track orbital parameters
 	initialDistances := make([]float64, len(particles))
@@ -107,7 +109,7 @@ func TestPhysicsEngineWithCentralMass(t *testing.T) {
 
 	// Run simulation
 	for step := 0; step < numSteps; step++ {
-		forceField := RunTimeEvolution(particles, dt, width, height, gravitationalConstant)
+		forceField := physics.RunTimeEvolution(particles, dt, width, height, nil, gravitationalConstant)
 		if forceField == nil {
 			t.Fatal("RunTimeEvolution returned nil force field")
 		}
@@ -136,9 +138,9 @@ func TestPhysicsEngineWithCentralMass(t *testing.T) {
 // TestLeapfrogIntegration tests the leapfrog integration method
 func TestLeapfrogIntegration(t *testing.T) {
 	// Create a simple two-body system
-	particles := []*Particle{
-		NewParticle(1.0, 0, 0, 0, 0, 0, 1),
-		NewParticle(1.0, 10, 0, 0, 0, 0, -1),
+	particles := []*physics.Particle{
+		physics.NewParticle(1.0, 0, 0, 0, 0, 0, 1),
+		physics.NewParticle(1.0, 10, 0, 0, 0, 0, -1),
 	}
 
 	width := 256
@@ -148,47 +150,109 @@ func TestLeapfrogIntegration(t *testing.T) {
 	numSteps := 100
 
 	// Record initial center of mass
-	initialCOM := calculateCenterOfMass(particles)
+	initialCOM := testkit.CenterOfMass(particles)
 
 	// Run leapfrog integration
 	for step := 0; step < numSteps; step++ {
 		// Calculate forces
-		massGrid := DepositMassToGrid(particles, width, height)
-		potentialGrid := SolvePoissonFFT(massGrid, width, height, gravitationalConstant)
-		forceField := CalculateGradient(potentialGrid, width, height)
+		massGrid := physics.DepositMassToGrid(particles, width, height, nil)
+		potentialGrid := physics.SolvePoissonFFT(massGrid, width, height, 1, gravitationalConstant)
+		forceField := physics.CalculateGradient(potentialGrid, width, height, 1)
 
 		// Apply leapfrog step
-		LeapfrogStep(particles, forceField, dt, width, height)
+		physics.LeapfrogStep(particles, forceField, dt, width, height)
 	}
 
 	// Center of mass should not move (no external forces)
-	finalCOM := calculateCenterOfMass(particles)
-	comDrift := finalCOM.Sub(initialCOM).Length()
+	testkit.AssertCenterOfMassFixed(t, initialCOM, testkit.CenterOfMass(particles), 0.01)
+}
+
+func TestEnergyConservation(t *testing.T) {
+	// Test that energy doesn't explode in a closed system
+	// Note: Perfect conservation is not expected due to force correction factor and PM discretization
 
-	if comDrift > 0.01 {
-		t.Errorf("Center of mass drifted: initial=%v, final=%v, drift=%v",
-			initialCOM, finalCOM, comDrift)
+	// Create a simple bound system
+	particles := []*physics.Particle{
+		{
+			Position: physics.NewVec3(-2, 0, 0),
+			Velocity: physics.NewVec3(0, 0, 0.5), // Small perpendicular velocity
+			Mass:     100.0,
+		},
+		{
+			Position: physics.NewVec3(2, 0, 0),
+			Velocity: physics.NewVec3(0, 0, -0.5),
+			Mass:     100.0,
+		},
 	}
-}
 
-// Helper function to calculate center of mass
-func calculateCenterOfMass(particles []*Particle) Vec3 {
-	totalMass := float64(0)
-	com := Vec3{0, 0, 0}
+	width := 32
+	height := 32
+	gravitationalConstant := 0.1 // Weaker gravity for more stable system
+	dt := float32(0.01)
+
+	// Calculate initial energy
+	initialKE := testkit.KineticEnergy(particles)
+
+	// Run simulation for a short time
+	for i := 0; i < 50; i++ {
+		physics.RunTimeEvolution(particles, dt, width, height, nil, gravitationalConstant)
+	}
+
+	// Calculate final energy
+	finalKE := testkit.KineticEnergy(particles)
+
+	// Energy should not explode (kinetic energy should remain bounded)
+	// This is a weaker test but more realistic for the PM method with force corrections
+	if finalKE > initialKE*100 {
+		t.Errorf("Energy exploded: initial KE=%f, final KE=%f", initialKE, finalKE)
+	}
 
+	// Check that particles haven't escaped to infinity
 	for _, p := range particles {
-		mass := float64(p.Mass)
-		totalMass += mass
-		com.X += p.Position.X * mass
-		com.Y += p.Position.Y * mass
-		com.Z += p.Position.Z * mass
+		r := math.Sqrt(p.Position.X*p.Position.X + p.Position.Z*p.Position.Z)
+		if r > float64(width)/2 {
+			t.Errorf("Particle escaped: distance=%f", r)
+		}
+	}
+}
+
+func TestMomentumConservation(t *testing.T) {
+	// Test that momentum is conserved in a closed system
+
+	particles := []*physics.Particle{
+		{
+			Position: physics.NewVec3(-10, 0, 0),
+			Velocity: physics.NewVec3(2, 0, 0),
+			Mass:     50.0,
+		},
+		{
+			Position: physics.NewVec3(0, 0, 0),
+			Velocity: physics.NewVec3(0, 0, 1),
+			Mass:     100.0,
+		},
+		{
+			Position: physics.NewVec3(10, 0, 5),
+			Velocity: physics.NewVec3(-1, 0, -0.5),
+			Mass:     75.0,
+		},
 	}
 
-	if totalMass > 0 {
-		com.X /= totalMass
-		com.Y /= totalMass
-		com.Z /= totalMass
+	// Calculate initial momentum
+	initialMomentum := testkit.TotalMomentum(particles)
+
+	width := 32
+	height := 32
+	gravitationalConstant := 1.0
+	dt := float32(0.01)
+
+	// Run simulation for a short time
+	for i := 0; i < 100; i++ {
+		physics.RunTimeEvolution(particles, dt, width, height, nil, gravitationalConstant)
 	}
 
-	return com
+	// Calculate final momentum
+	finalMomentum := testkit.TotalMomentum(particles)
+
+	// Momentum should be approximately conserved (some error due to grid discretization)
+	testkit.AssertMomentumConserved(t, initialMomentum, finalMomentum, 1.0) // Large tolerance for PM discretization
 }
@@ -0,0 +1,222 @@
+package physics
+
+import "sync"
+
+// Pools for per-step temporaries. Each pooled value owns a contiguous backing
+// array so a width×height grid costs two allocations instead of width+1, and
+// steady-state steps reuse the same memory instead of allocating new grids
+var (
+	gridPool        sizedPool // *scratchGrid
+	complexGridPool sizedPool // *scratchComplexGrid
+	forceFieldPool  sizedPool // *ForceField
+)
+
+// gridSize is the width and height of a pooled value
+type gridSize struct {
+	width, height int
+}
+
+// sizedPool keeps one pool per grid size, so a value of one size is never
+// handed out for, or dropped by, a request for another. Simulations of
+// different sizes running side by side each reuse their own grids
+type sizedPool struct {
+	pools sync.Map // gridSize -> *sync.Pool
+}
+
+// pool returns the pool for width×height grids
+func (p *sizedPool) pool(width, height int) *sync.Pool {
+	key := gridSize{width, height}
+	if pool, ok := p.pools.Load(key); ok {
+		return pool.(*sync.Pool)
+	}
+	pool, _ := p.pools.LoadOrStore(key, new(sync.Pool))
+	return pool.(*sync.Pool)
+}
+
+// get returns a pooled width×height value, or nil if there is none
+func (p *sizedPool) get(width, height int) any {
+	return p.pool(width, height).Get()
+}
+
+// put returns a width×height value to the pool
+func (p *sizedPool) put(width, height int, v any) {
+	p.pool(width, height).Put(v)
+}
+
+// scratchGrid is a pooled real-valued grid
+type scratchGrid struct {
+	rows Grid
+}
+
+// scratchComplexGrid is a pooled complex-valued grid used as FFT scratch space
+type scratchComplexGrid struct {
+	rows [][]complex128
+}
+
+// NewGrid allocates a zeroed width×height grid backed by a single array,
+// aligned for huge pages from LargeGridCells cells
+func NewGrid(width, height int) Grid {
+	backing := float64Slab(width * height)
+	grid := make([][]float64, width)
+	for i := range grid {
+		grid[i] = backing[i*height : (i+1)*height : (i+1)*height]
+	}
+	return grid
+}
+
+// newComplexGrid allocates a zeroed width×height complex grid backed by a single array
+func newComplexGrid(width, height int) [][]complex128 {
+	backing := complex128Slab(width * height)
+	grid := make([][]complex128, width)
+	for i := range grid {
+		grid[i] = backing[i*height : (i+1)*height : (i+1)*height]
+	}
+	return grid
+}
+
+// ClearGrid sets every cell of grid to zero
+func ClearGrid(grid Grid) {
+	for i := range grid {
+		clear(grid[i])
+	}
+}
+
+// CopyGrid copies src into dst, which must have the same dimensions
+func CopyGrid(dst, src Grid) {
+	for i := range dst {
+		copy(dst[i], src[i])
+	}
+}
+
+// gridDims returns the dimensions of a grid
+func gridDims(grid Grid) (width, height int) {
+	return grid.Width(), grid.Height()
+}
+
+// rowsDims returns the dimensions of a grid of any element type
+func rowsDims[T any](rows [][]T) (width, height int) {
+	if len(rows) == 0 {
+		return 0, 0
+	}
+	return len(rows), len(rows[0])
+}
+
+// acquireGrid returns a zeroed pooled grid of the given size
+func acquireGrid(width, height int) *scratchGrid {
+	if g, ok := gridPool.get(width, height).(*scratchGrid); ok {
+		ClearGrid(g.rows)
+		return g
+	}
+	return &scratchGrid{rows: NewGrid(width, height)}
+}
+
+// releaseGrid returns a grid to the pool for its size
+func releaseGrid(g *scratchGrid) {
+	width, height := gridDims(g.rows)
+	gridPool.put(width, height, g)
+}
+
+// acquireComplexGrid returns a pooled complex grid of the given size (contents undefined)
+func acquireComplexGrid(width, height int) *scratchComplexGrid {
+	if g, ok := complexGridPool.get(width, height).(*scratchComplexGrid); ok {
+		return g
+	}
+	return &scratchComplexGrid{rows: newComplexGrid(width, height)}
+}
+
+// releaseComplexGrid returns a complex grid to the pool for its size
+func releaseComplexGrid(g *scratchComplexGrid) {
+	width, height := rowsDims(g.rows)
+	complexGridPool.put(width, height, g)
+}
+
+// NewForceField allocates a zeroed force field of the given size on cells of size dx
+func NewForceField(width, height int, dx float64) *ForceField {
+	return &ForceField{
+		AccelFieldX: NewGrid(width, height),
+		AccelFieldZ: NewGrid(width, height),
+		Width:       width,
+		Height:      height,
+		CellSize:    dx,
+	}
+}
+
+// AcquireForceField returns a force field on cells of size dx from the pool,
+// allocating one if none of the right size is available. Contents are undefined
+func AcquireForceField(width, height int, dx float64) *ForceField {
+	if f, ok := forceFieldPool.get(width, height).(*ForceField); ok {
+		f.CellSize = dx
+		return f
+	}
+	return NewForceField(width, height, dx)
+}
+
+// Release returns the force field to the pool for its size. The field and its
+// grids must not be used afterwards
+func (f *ForceField) Release() {
+	if f != nil {
+		forceFieldPool.put(f.Width, f.Height, f)
+	}
+}
+
+// Single-precision pools used by the float32 pipeline
+var (
+	grid32Pool        sizedPool // *scratchGrid32
+	complexGrid32Pool sizedPool // *scratchComplexGrid32
+)
+
+// scratchGrid32 is a pooled single-precision grid
+type scratchGrid32 struct {
+	rows [][]float32
+}
+
+// scratchComplexGrid32 is a pooled single-precision complex grid used as FFT scratch space
+type scratchComplexGrid32 struct {
+	rows [][]complex64
+}
+
+// NewGrid32 allocates a zeroed single-precision width×height grid backed by a single array
+func NewGrid32(width, height int) [][]float32 {
+	backing := float32Slab(width * height)
+	grid := make([][]float32, width)
+	for i := range grid {
+		grid[i] = backing[i*height : (i+1)*height : (i+1)*height]
+	}
+	return grid
+}
+
+// acquireGrid32 returns a zeroed pooled single-precision grid of the given size
+func acquireGrid32(width, height int) *scratchGrid32 {
+	if g, ok := grid32Pool.get(width, height).(*scratchGrid32); ok {
+		for i := range g.rows {
+			clear(g.rows[i])
+		}
+		return g
+	}
+	return &scratchGrid32{rows: NewGrid32(width, height)}
+}
+
+// releaseGrid32 returns a single-precision grid to the pool for its size
+func releaseGrid32(g *scratchGrid32) {
+	width, height := rowsDims(g.rows)
+	grid32Pool.put(width, height, g)
+}
+
+// acquireComplexGrid32 returns a pooled single-precision complex grid (contents undefined)
+func acquireComplexGrid32(width, height int) *scratchComplexGrid32 {
+	if g, ok := complexGrid32Pool.get(width, height).(*scratchComplexGrid32); ok {
+		return g
+	}
+	backing := complex64Slab(width * height)
+	rows := make([][]complex64, width)
+	for i := range rows {
+		rows[i] = backing[i*height : (i+1)*height : (i+1)*height]
+	}
+	return &scratchComplexGrid32{rows: rows}
+}
+
+// releaseComplexGrid32 returns a single-precision complex grid to the pool for its size
+func releaseComplexGrid32(g *scratchComplexGrid32) {
+	width, height := rowsDims(g.rows)
+	complexGrid32Pool.put(width, height, g)
+}
@@ -0,0 +1,157 @@
+package physics
+
+import (
+	"math"
+	"testing"
+)
+
+// TestNewGridContiguous tests grid shape and that rows cannot overrun each other
+func TestNewGridContiguous(t *testing.T) {
+	grid := NewGrid(4, 3)
+	if len(grid) != 4 || len(grid[0]) != 3 {
+		t.Fatalf("Expected 4x3 grid, got %dx%d", len(grid), len(grid[0]))
+	}
+
+	grid[0] = append(grid[0], 99) // Must reallocate rather than overwrite row 1
+	if grid[1][0] != 0 {
+		t.Error("Appending to a row overwrote the next row")
+	}
+}
+
+// TestAcquireGridZeroed tests that pooled grids are cleared before reuse
+func TestAcquireGridZeroed(t *testing.T) {
+	g := acquireGrid(8, 8)
+	g.rows[3][4] = 42
+	releaseGrid(g)
+
+	g = acquireGrid(8, 8)
+	defer releaseGrid(g)
+	for i := range g.rows {
+		for j := range g.rows[i] {
+			if g.rows[i][j] != 0 {
+				t.Fatalf("Pooled grid not cleared at (%d,%d): %f", i, j, g.rows[i][j])
+			}
+		}
+	}
+
+	// Mismatched sizes get a fresh grid
+	other := acquireGrid(4, 2)
+	if len(other.rows) != 4 || len(other.rows[0]) != 2 {
+		t.Errorf("Expected 4x2 grid, got %dx%d", len(other.rows), len(other.rows[0]))
+	}
+}
+
+// TestAcquireForceFieldSize tests that pooled force fields match the requested size
+func TestAcquireForceFieldSize(t *testing.T) {
+	f := AcquireForceField(16, 8, 1)
+	f.Release()
+
+	f = AcquireForceField(8, 16, 1)
+	if f.Width != 8 || f.Height != 16 || len(f.AccelFieldX) != 8 || len(f.AccelFieldZ[0]) != 16 {
+		t.Errorf("Unexpected force field shape: %dx%d", f.Width, f.Height)
+	}
+	f.Release()
+}
+
+// TestPoolKeyedBySize tests that alternating grid sizes neither hand out nor
+// drop pooled grids of the other size
+func TestPoolKeyedBySize(t *testing.T) {
+	reused := false
+	for range 10 { // sync.Pool may drop values, so allow a few tries
+		big := acquireGrid(8, 8)
+		releaseGrid(big)
+
+		small := acquireGrid(4, 4)
+		if w, h := gridDims(small.rows); w != 4 || h != 4 {
+			t.Fatalf("Expected 4x4 grid, got %dx%d", w, h)
+		}
+		releaseGrid(small)
+
+		again := acquireGrid(8, 8)
+		releaseGrid(again)
+		if again == big {
+			reused = true
+			break
+		}
+	}
+	if !reused {
+		t.Error("Acquiring a grid of another size dropped the pooled 8x8 grid")
+	}
+}
+
+// TestIntoVariantsMatch tests that the in-place functions match the allocating ones
+func TestIntoVariantsMatch(t *testing.T) {
+	const size = 32
+	particles := InitializeParticlesWithSeed(50, size, size, 7)
+
+	mass := DepositMassToGrid(particles, size, size, nil)
+	massInto := NewGrid(size, size)
+	massInto[0][0] = 123 // Stale data must be cleared
+	DepositMassToGridInto(massInto, particles, nil)
+
+	potential := SolvePoissonFFT(mass, size, size, 1, 1.0)
+	potentialInto := NewGrid(size, size)
+	SolvePoissonFFTInto(potentialInto, massInto, 1, 1.0)
+
+	field := CalculateGradient(potential, size, size, 1)
+	fieldInto := NewForceField(size, size, 1)
+	CalculateGradientInto(fieldInto, potentialInto)
+
+	for i := 0; i < size; i++ {
+		for j := 0; j < size; j++ {
+			if mass[i][j] != massInto[i][j] || potential[i][j] != potentialInto[i][j] {
+				t.Fatalf("Grid mismatch at (%d,%d)", i, j)
+			}
+			if math.Abs(field.AccelFieldX[i][j]-fieldInto.AccelFieldX[i][j]) > 0 ||
+				math.Abs(field.AccelFieldZ[i][j]-fieldInto.AccelFieldZ[i][j]) > 0 {
+				t.Fatalf("Force field mismatch at (%d,%d)", i, j)
+			}
+		}
+	}
+}
+
+// TestInPlaceStepsDoNotAllocate tests that deposition and gradient reuse their buffers
+func TestInPlaceStepsDoNotAllocate(t *testing.T) {
+	const size = 64
+	particles := InitializeParticlesWithSeed(200, size, size, 1)
+	grid := NewGrid(size, size)
+	field := NewForceField(size, size, 1)
+
+	allocs := testing.AllocsPerRun(20, func() {
+		DepositMassToGridInto(grid, particles, nil)
+		CalculateGradientInto(field, grid)
+	})
+	if allocs != 0 {
+		t.Errorf("Expected no allocations, got %.1f per run", allocs)
+	}
+}
+
+// BenchmarkRunTimeEvolution measures steady-state allocations of a full PM step
+func BenchmarkRunTimeEvolution(b *testing.B) {
+	const size = 128
+	particles := InitializeParticlesWithSeed(1000, size, size, 1)
+
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		RunTimeEvolution(particles, 0.01, size, size, nil, 1.0).Release()
+	}
+}
+
+// BenchmarkRunTimeEvolutionUnpooled measures the same step with freshly allocated grids
+func BenchmarkRunTimeEvolutionUnpooled(b *testing.B) {
+	const size = 128
+	particles := InitializeParticlesWithSeed(1000, size, size, 1)
+
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		for pass := 0; pass < 2; pass++ {
+			mass := DepositMassToGrid(particles, size, size, nil)
+			potential := SolvePoissonFFT(mass, size, size, 1, 1.0)
+			field := CalculateGradient(potential, size, size, 1)
+			UpdateVelocities(particles, field, 0.005, 0.5)
+		}
+		UpdatePositions(particles, 0.01, size, size, 1)
+	}
+}
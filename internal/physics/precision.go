@@ -0,0 +1,197 @@
+package physics
+
+import (
+	"fmt"
+	"math"
+	"relativity_simulation_2d/pkg/fft"
+)
+
+// Precision selects the floating-point width of the CPU grid/FFT pipeline
+type Precision int
+
+const (
+	PrecisionFloat64 Precision = iota // Double precision grids and FFT (default)
+	PrecisionFloat32                  // Single precision grids and FFT, matching the GPU path
+)
+
+// ParsePrecision converts a configuration string to a Precision ("" = float64)
+func ParsePrecision(s string) (Precision, error) {
+	switch s {
+	case "", "float64":
+		return PrecisionFloat64, nil
+	case "float32":
+		return PrecisionFloat32, nil
+	default:
+		return PrecisionFloat64, fmt.Errorf("unknown precision %q", s)
+	}
+}
+
+// String returns the configuration name of the precision
+func (p Precision) String() string {
+	if p == PrecisionFloat32 {
+		return "float32"
+	}
+	return "float64"
+}
+
+// RunTimeEvolutionWithPrecision runs RunTimeEvolution or RunTimeEvolution32
+func RunTimeEvolutionWithPrecision(precision Precision, particles []*Particle, dt float32, width, height int, mesh *Mesh, gravitationalConstant float64) *ForceField {
+	if precision == PrecisionFloat32 {
+		return RunTimeEvolution32(particles, dt, width, height, mesh, gravitationalConstant)
+	}
+	return RunTimeEvolution(particles, dt, width, height, mesh, gravitationalConstant)
+}
+
+// SolvePoissonWithPrecision solves ∇²Φ = 4πGρ on cells of size dx into
+// potentialGrid at the given precision. Grids are always float64 at the
+// boundary; the float32 path narrows the input and widens the result
+func SolvePoissonWithPrecision(precision Precision, potentialGrid, massGrid Grid, dx, gravitationalConstant float64) {
+	if precision != PrecisionFloat32 {
+		SolvePoissonFFTInto(potentialGrid, massGrid, dx, gravitationalConstant)
+		return
+	}
+
+	width, height := gridDims(massGrid)
+	mass := acquireGrid32(width, height)
+	defer releaseGrid32(mass)
+	potential := acquireGrid32(width, height)
+	defer releaseGrid32(potential)
+
+	for i := range massGrid {
+		for j, v := range massGrid[i] {
+			mass.rows[i][j] = float32(v)
+		}
+	}
+	SolvePoissonFFT32Into(potential.rows, mass.rows, dx, gravitationalConstant)
+	for i := range potentialGrid {
+		for j := range potentialGrid[i] {
+			potentialGrid[i][j] = float64(potential.rows[i][j])
+		}
+	}
+}
+
+// DepositMassToGrid32Into clears grid and deposits particle mass into it using
+// periodic Cloud-in-Cell, sub-sampled if the mesh has a deposit stride
+func DepositMassToGrid32Into(grid [][]float32, particles []*Particle, mesh *Mesh) {
+	for i := range grid {
+		clear(grid[i])
+	}
+	if len(grid) == 0 {
+		return
+	}
+	width, height := len(grid), len(grid[0])
+	dx := mesh.Dx()
+
+	first, stride, scale, _ := mesh.subsample(particles)
+	for i := first; i < len(particles); i += stride {
+		p := particles[i]
+		mass := p.Mass * float32(scale)
+		c := locateCell(p.Position.X, p.Position.Z, width, height, dx)
+		fx, fz := float32(c.fx), float32(c.fz)
+
+		grid[c.i][c.j] += mass * (1 - fx) * (1 - fz)
+		grid[c.nextI][c.j] += mass * fx * (1 - fz)
+		grid[c.i][c.nextJ] += mass * (1 - fx) * fz
+		grid[c.nextI][c.nextJ] += mass * fx * fz
+	}
+}
+
+// SolvePoissonFFT32Into solves ∇²Φ = 4πGρ on cells of size dx in single precision
+func SolvePoissonFFT32Into(potentialGrid, massGrid [][]float32, dx, gravitationalConstant float64) {
+	if len(massGrid) == 0 {
+		return
+	}
+	width, height := len(massGrid), len(massGrid[0])
+
+	scratch := acquireComplexGrid32(width, height)
+	defer releaseComplexGrid32(scratch)
+	grid := scratch.rows
+	for i := range grid {
+		for j := range grid[i] {
+			grid[i][j] = complex(massGrid[i][j], 0)
+		}
+	}
+
+	fft.Transform2DInPlace32(grid, false)
+
+	// Solve in Fourier space: Φ̂(k) = -4πG * ρ̂(k) / |k|² (factor computed in float64)
+	cellArea := dx * dx
+	kxFactor := 2.0 * math.Pi / (float64(width) * dx)
+	kzFactor := 2.0 * math.Pi / (float64(height) * dx)
+	for u := 0; u < width; u++ {
+		kx := float64(u)
+		if u > width/2 {
+			kx = float64(u - width)
+		}
+		for v := 0; v < height; v++ {
+			kz := float64(v)
+			if v > height/2 {
+				kz = float64(v - height)
+			}
+
+			kSquared := (kx*kxFactor)*(kx*kxFactor) + (kz*kzFactor)*(kz*kzFactor)
+			if kSquared == 0 {
+				grid[u][v] = 0
+			} else {
+				scale := float32(-4.0 * math.Pi * gravitationalConstant / (kSquared * cellArea))
+				grid[u][v] = complex(real(grid[u][v])*scale, imag(grid[u][v])*scale)
+			}
+		}
+	}
+
+	fft.Transform2DInPlace32(grid, true)
+
+	for i := range potentialGrid {
+		for j := range potentialGrid[i] {
+			potentialGrid[i][j] = real(grid[i][j])
+		}
+	}
+}
+
+// CalculateGradient32Into computes a = -∇Φ from a single-precision potential into
+// a force field, differencing over the field's cells. The field itself stays
+// float64 so the kick step is shared
+func CalculateGradient32Into(forceField *ForceField, potentialGrid [][]float32) {
+	forceField.checkDims()
+	width, height := forceField.Width, forceField.Height
+	span := 2 * forceField.Dx()
+
+	for i := 0; i < width; i++ {
+		prevI := (i - 1 + width) % width
+		nextI := (i + 1) % width
+		for j := 0; j < height; j++ {
+			prevJ := (j - 1 + height) % height
+			nextJ := (j + 1) % height
+
+			forceField.AccelFieldX.SetUnchecked(i, j, -float64(potentialGrid[nextI][j]-potentialGrid[prevI][j])/span)
+			forceField.AccelFieldZ.SetUnchecked(i, j, -float64(potentialGrid[i][nextJ]-potentialGrid[i][prevJ])/span)
+		}
+	}
+}
+
+// RunTimeEvolution32 performs the same step as RunTimeEvolution with
+// single-precision mass, FFT and potential grids
+func RunTimeEvolution32(particles []*Particle, dt float32, width, height int, mesh *Mesh, gravitationalConstant float64) *ForceField {
+	dx := mesh.Dx()
+	massGrid := acquireGrid32(width, height)
+	defer releaseGrid32(massGrid)
+	potentialGrid := acquireGrid32(width, height)
+	defer releaseGrid32(potentialGrid)
+	forceField := AcquireForceField(width, height, dx)
+
+	forceCorrectionFactor := float32(0.5)
+
+	DepositMassToGrid32Into(massGrid.rows, particles, mesh)
+	SolvePoissonFFT32Into(potentialGrid.rows, massGrid.rows, dx, gravitationalConstant)
+	CalculateGradient32Into(forceField, potentialGrid.rows)
+	UpdateVelocities(particles, forceField, dt*0.5, forceCorrectionFactor)
+
+	UpdatePositions(particles, dt, width, height, dx)
+
+	DepositMassToGrid32Into(massGrid.rows, particles, mesh)
+	SolvePoissonFFT32Into(potentialGrid.rows, massGrid.rows, dx, gravitationalConstant)
+	CalculateGradient32Into(forceField, potentialGrid.rows)
+	UpdateVelocities(particles, forceField, dt*0.5, forceCorrectionFactor)
+
+	return forceField
+}
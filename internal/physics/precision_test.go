@@ -0,0 +1,123 @@
+package physics
+
+import (
+	"math"
+	"testing"
+)
+
+// TestParsePrecision tests precision name parsing
+func TestParsePrecision(t *testing.T) {
+	for name, want := range map[string]Precision{"": PrecisionFloat64, "float64": PrecisionFloat64, "float32": PrecisionFloat32} {
+		got, err := ParsePrecision(name)
+		if err != nil || got != want {
+			t.Errorf("ParsePrecision(%q) = %v, %v; want %v", name, got, err, want)
+		}
+	}
+	if _, err := ParsePrecision("half"); err == nil {
+		t.Error("Expected error for unknown precision")
+	}
+	if PrecisionFloat32.String() != "float32" || PrecisionFloat64.String() != "float64" {
+		t.Error("Unexpected precision names")
+	}
+}
+
+// maxRelativeDiff returns max|a-b| / max|b| over two grids
+func maxRelativeDiff(a, b [][]float64) float64 {
+	var maxDiff, maxRef float64
+	for i := range b {
+		for j := range b[i] {
+			maxDiff = math.Max(maxDiff, math.Abs(a[i][j]-b[i][j]))
+			maxRef = math.Max(maxRef, math.Abs(b[i][j]))
+		}
+	}
+	if maxRef == 0 {
+		return maxDiff
+	}
+	return maxDiff / maxRef
+}
+
+// TestFloat32PotentialAccuracy quantifies the potential and force error of the float32 pipeline
+func TestFloat32PotentialAccuracy(t *testing.T) {
+	const size = 128
+	particles := InitializeParticlesWithSeed(500, size, size, 5)
+
+	mass := DepositMassToGrid(particles, size, size, nil)
+	potential64 := SolvePoissonFFT(mass, size, size, 1, 1.0)
+	potential32 := NewGrid(size, size)
+	SolvePoissonWithPrecision(PrecisionFloat32, potential32, mass, 1, 1.0)
+
+	potentialErr := maxRelativeDiff(potential32, potential64)
+	t.Logf("float32 potential max relative error: %.2e", potentialErr)
+	if potentialErr > 1e-5 {
+		t.Errorf("float32 potential error too large: %.2e", potentialErr)
+	}
+
+	mass32 := NewGrid32(size, size)
+	DepositMassToGrid32Into(mass32, particles, nil)
+	phi32 := NewGrid32(size, size)
+	SolvePoissonFFT32Into(phi32, mass32, 1, 1.0)
+	field32 := NewForceField(size, size, 1)
+	CalculateGradient32Into(field32, phi32)
+	field64 := CalculateGradient(potential64, size, size, 1)
+
+	forceErr := math.Max(maxRelativeDiff(field32.AccelFieldX, field64.AccelFieldX),
+		maxRelativeDiff(field32.AccelFieldZ, field64.AccelFieldZ))
+	t.Logf("float32 force field max relative error: %.2e", forceErr)
+	if forceErr > 1e-4 {
+		t.Errorf("float32 force error too large: %.2e", forceErr)
+	}
+}
+
+// TestFloat32EvolutionAccuracy quantifies trajectory and energy drift of float32 steps
+func TestFloat32EvolutionAccuracy(t *testing.T) {
+	const size = 64
+	const steps = 50
+	particles64 := InitializeParticlesWithSeed(100, size, size, 9)
+	particles32 := InitializeParticlesWithSeed(100, size, size, 9)
+
+	for step := 0; step < steps; step++ {
+		RunTimeEvolutionWithPrecision(PrecisionFloat64, particles64, 0.02, size, size, nil, 1.0).Release()
+		RunTimeEvolutionWithPrecision(PrecisionFloat32, particles32, 0.02, size, size, nil, 1.0).Release()
+	}
+
+	var maxOffset float64
+	for i := range particles64 {
+		maxOffset = math.Max(maxOffset, particles32[i].Position.Sub(particles64[i].Position).Length())
+	}
+	ke64 := ComputeDiagnostics(particles64).KineticEnergy
+	ke32 := ComputeDiagnostics(particles32).KineticEnergy
+	keErr := math.Abs(ke32-ke64) / ke64
+
+	t.Logf("After %d steps: max position offset %.2e cells, kinetic energy relative error %.2e", steps, maxOffset, keErr)
+	if maxOffset > 1e-2 {
+		t.Errorf("float32 trajectories diverged by %.2e cells", maxOffset)
+	}
+	if keErr > 1e-3 {
+		t.Errorf("float32 kinetic energy error too large: %.2e", keErr)
+	}
+}
+
+// BenchmarkSolvePoissonFloat64 measures the double-precision Poisson solve
+func BenchmarkSolvePoissonFloat64(b *testing.B) {
+	const size = 512
+	mass := DepositMassToGrid(InitializeParticlesWithSeed(5000, size, size, 1), size, size, nil)
+	potential := NewGrid(size, size)
+
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		SolvePoissonFFTInto(potential, mass, 1, 1.0)
+	}
+}
+
+// BenchmarkSolvePoissonFloat32 measures the single-precision Poisson solve
+func BenchmarkSolvePoissonFloat32(b *testing.B) {
+	const size = 512
+	mass := NewGrid32(size, size)
+	DepositMassToGrid32Into(mass, InitializeParticlesWithSeed(5000, size, size, 1), nil)
+	potential := NewGrid32(size, size)
+
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		SolvePoissonFFT32Into(potential, mass, 1, 1.0)
+	}
+}
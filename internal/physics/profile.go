@@ -0,0 +1,211 @@
+package physics
+
+import "math"
+
+// Radial profile defaults
+const (
+	DefaultProfileBins     = 32
+	shrinkFactor           = 0.75 // Radius ratio between shrinking-circle iterations
+	minShrinkParticles     = 8    // Shrinking circles stop before holding fewer particles
+	maxShrinkingIterations = 100
+)
+
+// ProfileOptions configures a radial profile. Lengths are physical, in the
+// units of the particle positions
+type ProfileOptions struct {
+	Bins                  int     // Equal-width radial bins (< 1 = DefaultProfileBins)
+	MaxRadius             float64 // Outer edge of the last bin (0 = the farthest particle)
+	Densest               bool    // Center on the densest point instead of the center of mass
+	Width, Height         int     // Periodic domain in cells, so distances take the nearest image (0 = not periodic)
+	CellSize              float64 // Physical size of a cell (0 = 1)
+	GravitationalConstant float64
+}
+
+// periods returns the physical extents of the periodic domain (0 = not periodic)
+func (o ProfileOptions) periods() (x, z float64) {
+	dx := spacing(o.CellSize)
+	return float64(o.Width) * dx, float64(o.Height) * dx
+}
+
+// ProfileBin holds the particles in one annulus Inner ≤ r < Outer
+type ProfileBin struct {
+	Inner, Outer     float64
+	Count            int
+	Mass             float64
+	Density          float64 // Surface density: mass per unit area of the annulus
+	Rotation         float64 // Mass-weighted mean tangential velocity, positive counter-clockwise seen from +Y
+	SigmaR, SigmaT   float64 // Radial and tangential velocity dispersions about the bin's means
+	Dispersion       float64 // One-dimensional dispersion √((σr² + σt²)/2)
+	EnclosedMass     float64 // Mass inside Outer
+	CircularVelocity float64 // Speed of a circular orbit at Outer around EnclosedMass
+}
+
+// Radius returns the middle of the bin
+func (b ProfileBin) Radius() float64 {
+	return (b.Inner + b.Outer) / 2
+}
+
+// RadialProfile is a system binned in annuli around its center. Velocities
+// are relative to the bulk velocity of the binned particles
+type RadialProfile struct {
+	CenterX, CenterZ     float64
+	VelocityX, VelocityZ float64 // Bulk velocity, mass-weighted over the binned particles
+	Bins                 []ProfileBin
+}
+
+// ComputeRadialProfile bins the particles by distance from the center of
+// mass, or the densest point found by shrinking circles, into surface density
+// Σ(r), rotation, velocity dispersion σ(r) and circular velocity Vc(r)
+// profiles. In 2D gravity the field of the mass inside r is 2G·M(<r)/r, so
+// Vc² = 2G·M(<r) times the force scale the PM and direct steps apply
+func ComputeRadialProfile(particles []*Particle, opts ProfileOptions) RadialProfile {
+	bins := opts.Bins
+	if bins < 1 {
+		bins = DefaultProfileBins
+	}
+	profile := RadialProfile{}
+	if len(particles) == 0 {
+		return profile
+	}
+	profile.CenterX, profile.CenterZ = profileCenter(particles, opts)
+
+	// Offsets from the center, and the outer radius
+	periodX, periodZ := opts.periods()
+	offset := func(p *Particle) (dx, dz float64) {
+		return nearestImage(p.Position.X-profile.CenterX, periodX), nearestImage(p.Position.Z-profile.CenterZ, periodZ)
+	}
+	maxRadius := opts.MaxRadius
+	if maxRadius <= 0 {
+		for _, p := range particles {
+			dx, dz := offset(p)
+			maxRadius = math.Max(maxRadius, math.Hypot(dx, dz))
+		}
+		maxRadius *= 1 + 1e-9 // Keep the farthest particle in the last bin
+		if maxRadius == 0 {
+			maxRadius = spacing(opts.CellSize) // All particles at the center: bin one cell
+		}
+	}
+	if !(maxRadius > 0) || math.IsInf(maxRadius, 0) {
+		return profile
+	}
+	width := maxRadius / float64(bins)
+	binOf := func(p *Particle) int { // -1 = outside the bins
+		dx, dz := offset(p)
+		k := math.Hypot(dx, dz) / width
+		if !(k < float64(bins)) {
+			return -1
+		}
+		return int(k)
+	}
+
+	// Bulk velocity of the binned particles
+	var totalMass, sumVX, sumVZ float64
+	for _, p := range particles {
+		if binOf(p) >= 0 {
+			m := float64(p.Mass)
+			totalMass += m
+			sumVX += m * p.Velocity.X
+			sumVZ += m * p.Velocity.Z
+		}
+	}
+	if totalMass > 0 {
+		profile.VelocityX, profile.VelocityZ = sumVX/totalMass, sumVZ/totalMass
+	}
+
+	// Mass-weighted first and second moments of the radial and tangential velocities
+	type moments struct{ vr, vt, vr2, vt2 float64 }
+	sums := make([]moments, bins)
+	profile.Bins = make([]ProfileBin, bins)
+	for _, p := range particles {
+		k := binOf(p)
+		if k < 0 {
+			continue
+		}
+		dx, dz := offset(p)
+		r := math.Hypot(dx, dz)
+		vx, vz := p.Velocity.X-profile.VelocityX, p.Velocity.Z-profile.VelocityZ
+		var vr, vt float64
+		if r > 0 {
+			vr = (vx*dx + vz*dz) / r
+			vt = (dx*vz - dz*vx) / r
+		}
+		m := float64(p.Mass)
+		profile.Bins[k].Count++
+		profile.Bins[k].Mass += m
+		sums[k].vr += m * vr
+		sums[k].vt += m * vt
+		sums[k].vr2 += m * vr * vr
+		sums[k].vt2 += m * vt * vt
+	}
+
+	enclosed := 0.0
+	for k := range profile.Bins {
+		b := &profile.Bins[k]
+		b.Inner, b.Outer = float64(k)*width, float64(k+1)*width
+		b.Density = b.Mass / (math.Pi * (b.Outer*b.Outer - b.Inner*b.Inner))
+		if b.Mass > 0 {
+			meanR, meanT := sums[k].vr/b.Mass, sums[k].vt/b.Mass
+			b.Rotation = meanT
+			b.SigmaR = math.Sqrt(math.Max(0, sums[k].vr2/b.Mass-meanR*meanR))
+			b.SigmaT = math.Sqrt(math.Max(0, sums[k].vt2/b.Mass-meanT*meanT))
+			b.Dispersion = math.Sqrt((b.SigmaR*b.SigmaR + b.SigmaT*b.SigmaT) / 2)
+		}
+		enclosed += b.Mass
+		b.EnclosedMass = enclosed
+		b.CircularVelocity = math.Sqrt(2 * opts.GravitationalConstant * pmForceScale * enclosed)
+	}
+	return profile
+}
+
+// profileCenter returns the center of mass, or with opts.Densest the center
+// of the smallest circle found by shrinking circles: the center of mass of
+// the particles within a radius that shrinks each iteration around the last
+// center, until too few particles remain
+func profileCenter(particles []*Particle, opts ProfileOptions) (x, z float64) {
+	x, z = centerOfMassNear(particles, particles[0].Position.X, particles[0].Position.Z, math.Inf(1), opts)
+	if !opts.Densest {
+		return x, z
+	}
+	periodX, periodZ := opts.periods()
+	radius := 0.0
+	for _, p := range particles {
+		dx, dz := nearestImage(p.Position.X-x, periodX), nearestImage(p.Position.Z-z, periodZ)
+		radius = math.Max(radius, math.Hypot(dx, dz))
+	}
+	for i := 0; i < maxShrinkingIterations; i++ {
+		radius *= shrinkFactor
+		inside := 0
+		for _, p := range particles {
+			dx, dz := nearestImage(p.Position.X-x, periodX), nearestImage(p.Position.Z-z, periodZ)
+			if math.Hypot(dx, dz) <= radius {
+				inside++
+			}
+		}
+		if inside < minShrinkParticles {
+			break
+		}
+		x, z = centerOfMassNear(particles, x, z, radius, opts)
+	}
+	return x, z
+}
+
+// centerOfMassNear returns the center of mass of the particles within radius
+// of (x, z), with offsets taken to their nearest images, or (x, z) if none has mass
+func centerOfMassNear(particles []*Particle, x, z, radius float64, opts ProfileOptions) (float64, float64) {
+	periodX, periodZ := opts.periods()
+	var mass, sumX, sumZ float64
+	for _, p := range particles {
+		dx, dz := nearestImage(p.Position.X-x, periodX), nearestImage(p.Position.Z-z, periodZ)
+		if math.Hypot(dx, dz) > radius {
+			continue
+		}
+		m := float64(p.Mass)
+		mass += m
+		sumX += m * dx
+		sumZ += m * dz
+	}
+	if mass == 0 {
+		return x, z
+	}
+	return wrapPeriodic(x+sumX/mass, periodX), wrapPeriodic(z+sumZ/mass, periodZ)
+}
@@ -0,0 +1,105 @@
+package physics
+
+import (
+	"math"
+	"math/rand"
+	"testing"
+)
+
+// uniformDisk returns n unit-mass particles spread uniformly over a disk,
+// rotating rigidly at omega
+func uniformDisk(n int, radius, cx, cz, omega float64, rng *rand.Rand) []*Particle {
+	particles := make([]*Particle, n)
+	for i := range particles {
+		r := radius * math.Sqrt(rng.Float64())
+		theta := 2 * math.Pi * rng.Float64()
+		dx, dz := r*math.Cos(theta), r*math.Sin(theta)
+		particles[i] = NewParticle(1, cx+dx, 0, cz+dz, -omega*dz, 0, omega*dx)
+	}
+	return particles
+}
+
+// TestRadialProfileUniformDisk tests the density, rotation and enclosed mass of a rotating uniform disk
+func TestRadialProfileUniformDisk(t *testing.T) {
+	n, radius, omega := 20000, 10.0, 0.3
+	particles := uniformDisk(n, radius, 5, 3, omega, rand.New(rand.NewSource(1)))
+	profile := ComputeRadialProfile(particles, ProfileOptions{Bins: 10, MaxRadius: radius, GravitationalConstant: 1})
+
+	if math.Abs(profile.CenterX-5) > 0.2 || math.Abs(profile.CenterZ-3) > 0.2 {
+		t.Errorf("Expected the center near (5, 3), got (%g, %g)", profile.CenterX, profile.CenterZ)
+	}
+	density := float64(n) / (math.Pi * radius * radius)
+	for _, b := range profile.Bins[2:] { // The innermost annuli hold few particles
+		if math.Abs(b.Density-density) > 0.1*density {
+			t.Errorf("Bin at r=%g: density %g, want %g", b.Radius(), b.Density, density)
+		}
+		if math.Abs(b.Rotation-omega*b.Radius()) > 0.05*omega*radius {
+			t.Errorf("Bin at r=%g: rotation %g, want %g", b.Radius(), b.Rotation, omega*b.Radius())
+		}
+		// Only the spread of ωr across the annulus and the offset of the found center remain
+		if b.SigmaR > 0.02 || b.SigmaT > 0.05*omega*radius {
+			t.Errorf("Bin at r=%g: expected a cold disk, got σr %g σt %g", b.Radius(), b.SigmaR, b.SigmaT)
+		}
+	}
+	profile = ComputeRadialProfile(particles, ProfileOptions{Bins: 10, GravitationalConstant: 1})
+	last := profile.Bins[len(profile.Bins)-1]
+	if last.EnclosedMass != float64(n) {
+		t.Errorf("Expected all %d particles enclosed, got %g", n, last.EnclosedMass)
+	}
+	if want := math.Sqrt(2 * pmForceScale * float64(n)); math.Abs(last.CircularVelocity-want) > 1e-9 {
+		t.Errorf("Expected Vc %g at the edge, got %g", want, last.CircularVelocity)
+	}
+}
+
+// TestRadialProfileDensest tests that shrinking circles find a dense clump off the center of mass
+func TestRadialProfileDensest(t *testing.T) {
+	rng := rand.New(rand.NewSource(2))
+	particles := append(uniformDisk(2000, 20, 0, 0, 0, rng), uniformDisk(500, 1, 12, 0, 0, rng)...)
+
+	com := ComputeRadialProfile(particles, ProfileOptions{})
+	densest := ComputeRadialProfile(particles, ProfileOptions{Densest: true})
+	if math.Abs(com.CenterX-2.4) > 0.5 {
+		t.Errorf("Expected the center of mass near x=2.4, got %g", com.CenterX)
+	}
+	if math.Hypot(densest.CenterX-12, densest.CenterZ) > 0.5 {
+		t.Errorf("Expected the densest point near (12, 0), got (%g, %g)", densest.CenterX, densest.CenterZ)
+	}
+	count := 0
+	for _, b := range densest.Bins {
+		count += b.Count
+	}
+	if count != len(particles) || len(densest.Bins) != DefaultProfileBins {
+		t.Errorf("Expected %d particles in %d bins, got %d in %d", len(particles), DefaultProfileBins, count, len(densest.Bins))
+	}
+}
+
+// TestRadialProfilePeriodic tests centering a cluster that straddles the domain edge
+func TestRadialProfilePeriodic(t *testing.T) {
+	particles := uniformDisk(1000, 3, 31, 0, 0, rand.New(rand.NewSource(3)))
+	for _, p := range particles {
+		p.Position.X = wrapPeriodic(p.Position.X, 64)
+	}
+	profile := ComputeRadialProfile(particles, ProfileOptions{Bins: 4, Densest: true, Width: 64, Height: 64})
+	if math.Abs(nearestImage(profile.CenterX-31, 64)) > 0.3 {
+		t.Errorf("Expected the center near x=31 across the wrap, got %g", profile.CenterX)
+	}
+	if outer := profile.Bins[len(profile.Bins)-1].Outer; outer > 3.5 {
+		t.Errorf("Expected the cluster within r=3 of the center, got an outer radius of %g", outer)
+	}
+}
+
+// TestRadialProfileCellSize tests centering a cluster that straddles the edge
+// of a domain of half-size cells
+func TestRadialProfileCellSize(t *testing.T) {
+	particles := uniformDisk(1000, 1.5, 15.5, 0, 0, rand.New(rand.NewSource(3)))
+	for _, p := range particles {
+		p.Position.X = wrapPeriodic(p.Position.X, 32)
+	}
+	profile := ComputeRadialProfile(particles, ProfileOptions{Bins: 4, Densest: true, Width: 64, Height: 64, CellSize: 0.5})
+	if math.Abs(nearestImage(profile.CenterX-15.5, 32)) > 0.15 {
+		t.Errorf("Expected the center near x=15.5 across the wrap, got %g", profile.CenterX)
+	}
+	if outer := profile.Bins[len(profile.Bins)-1].Outer; outer > 1.75 {
+		t.Errorf("Expected the cluster within r=1.5 of the center, got an outer radius of %g", outer)
+	}
+}
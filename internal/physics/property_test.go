@@ -0,0 +1,135 @@
+//go:build property
+
+package physics_test
+
+import (
+	"math"
+	"relativity_simulation_2d/internal/physics"
+	"relativity_simulation_2d/pkg/testkit"
+	"testing"
+
+	"pgregory.net/rapid"
+)
+
+// Property tests generate random inputs and shrink any failure to a minimal
+// counterexample. They are opt-in: go test -tags property ./internal/physics
+// (add -rapid.checks=10000 for a longer search)
+
+// momentumTolerance is the allowed momentum change per unit of total mass;
+// both solvers apply equal and opposite forces, so only rounding remains
+const momentumTolerance = 1e-9
+
+// particlesGen draws 1-30 particles inside a domain of the given half-extent
+func particlesGen(halfExtent float64) *rapid.Generator[[]*physics.Particle] {
+	particle := rapid.Custom(func(t *rapid.T) *physics.Particle {
+		return physics.NewParticle(
+			rapid.Float64Range(0.5, 2).Draw(t, "mass"),
+			rapid.Float64Range(-halfExtent, halfExtent).Draw(t, "x"), 0,
+			rapid.Float64Range(-halfExtent, halfExtent).Draw(t, "z"),
+			rapid.Float64Range(-1, 1).Draw(t, "vx"), 0,
+			rapid.Float64Range(-1, 1).Draw(t, "vz"),
+		)
+	})
+	return rapid.SliceOfN(particle, 1, 30)
+}
+
+// totalMass sums the particle masses
+func totalMass(particles []*physics.Particle) float64 {
+	mass := 0.0
+	for _, p := range particles {
+		mass += float64(p.Mass)
+	}
+	return mass
+}
+
+// TestPropertyPMMomentum tests that particle-mesh steps conserve momentum
+func TestPropertyPMMomentum(t *testing.T) {
+	rapid.Check(t, func(t *rapid.T) {
+		particles := particlesGen(30).Draw(t, "particles")
+		steps := rapid.IntRange(1, 10).Draw(t, "steps")
+		dt := float32(rapid.Float64Range(0.001, 0.05).Draw(t, "dt"))
+
+		before := testkit.TotalMomentum(particles)
+		for i := 0; i < steps; i++ {
+			physics.RunTimeEvolution(particles, dt, 64, 64, nil, 1.0)
+		}
+		testkit.AssertMomentumConserved(t, before, testkit.TotalMomentum(particles), momentumTolerance*totalMass(particles))
+		testkit.AssertFinite(t, particles)
+	})
+}
+
+// TestPropertyDirectMomentum tests that direct steps conserve momentum, with
+// and without close-encounter sub-stepping
+func TestPropertyDirectMomentum(t *testing.T) {
+	rapid.Check(t, func(t *rapid.T) {
+		particles := particlesGen(30).Draw(t, "particles")
+		opts := physics.DirectOptions{
+			GravitationalConstant: 1.0,
+			Softening:             rapid.Float64Range(0.1, 1).Draw(t, "softening"),
+			Workers:               1,
+			EncounterRadius:       rapid.SampledFrom([]float64{0, 2}).Draw(t, "encounterRadius"),
+		}
+
+		before := testkit.TotalMomentum(particles)
+		for i := 0; i < 5; i++ {
+			physics.RunDirectTimeEvolution(particles, 0.01, 64, 64, opts)
+		}
+		testkit.AssertMomentumConserved(t, before, testkit.TotalMomentum(particles), momentumTolerance*totalMass(particles))
+		testkit.AssertFinite(t, particles)
+	})
+}
+
+// gridGen draws a width x height grid of values in [-1, 1]
+func gridGen(width, height int) *rapid.Generator[physics.Grid] {
+	return rapid.Custom(func(t *rapid.T) physics.Grid {
+		values := rapid.SliceOfN(rapid.Float64Range(-1, 1), width*height, width*height).Draw(t, "values")
+		grid := physics.NewGrid(width, height)
+		grid.Unflatten(values)
+		return grid
+	})
+}
+
+// maxAbs returns the largest magnitude in grid
+func maxAbs(grid physics.Grid) float64 {
+	m := 0.0
+	for _, v := range grid.Flatten(nil) {
+		m = math.Max(m, math.Abs(v))
+	}
+	return m
+}
+
+// TestPropertyPoissonLinearity tests Φ(aρ₁ + bρ₂) = aΦ(ρ₁) + bΦ(ρ₂), and that
+// the solution has zero mean since the k = 0 mode is dropped
+func TestPropertyPoissonLinearity(t *testing.T) {
+	rapid.Check(t, func(t *rapid.T) {
+		width := rapid.SampledFrom([]int{4, 8, 16}).Draw(t, "width")
+		height := rapid.SampledFrom([]int{4, 8, 16}).Draw(t, "height")
+		rho1 := gridGen(width, height).Draw(t, "rho1")
+		rho2 := gridGen(width, height).Draw(t, "rho2")
+		a := rapid.Float64Range(-3, 3).Draw(t, "a")
+		b := rapid.Float64Range(-3, 3).Draw(t, "b")
+
+		combined := physics.NewGrid(width, height)
+		for i := 0; i < width; i++ {
+			for j := 0; j < height; j++ {
+				combined.SetUnchecked(i, j, a*rho1.AtUnchecked(i, j)+b*rho2.AtUnchecked(i, j))
+			}
+		}
+		phi1 := physics.SolvePoissonFFT(rho1, width, height, 1, 1.0)
+		phi2 := physics.SolvePoissonFFT(rho2, width, height, 1, 1.0)
+		phi := physics.SolvePoissonFFT(combined, width, height, 1, 1.0)
+
+		tolerance := 1e-9 * (1 + maxAbs(phi1)*math.Abs(a) + maxAbs(phi2)*math.Abs(b))
+		for i := 0; i < width; i++ {
+			for j := 0; j < height; j++ {
+				want := a*phi1.AtUnchecked(i, j) + b*phi2.AtUnchecked(i, j)
+				if got := phi.AtUnchecked(i, j); math.Abs(got-want) > tolerance {
+					t.Fatalf("Φ[%d][%d] = %g, want %g", i, j, got, want)
+				}
+			}
+		}
+		if mean := phi.Sum() / float64(width*height); math.Abs(mean) > tolerance {
+			t.Fatalf("Mean potential %g, want 0", mean)
+		}
+	})
+}
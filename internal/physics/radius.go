@@ -0,0 +1,86 @@
+package physics
+
+import (
+	"fmt"
+	"math"
+)
+
+// DefaultParticleDensity is the density of the default radius model, under
+// which a particle of mass 20 has radius 0.5
+const DefaultParticleDensity = 120 / math.Pi
+
+// RadiusModel derives the physical radius of a particle from its mass. The
+// physical radius is the particle's extent for collisions and merging; how
+// large it is drawn is up to the renderer
+type RadiusModel interface {
+	Radius(mass float64) float64
+}
+
+// DensityRadius gives each particle the radius of a sphere of uniform density,
+// r = (3m / 4πρ)^(1/3)
+type DensityRadius struct {
+	Density float64
+}
+
+// Radius returns the radius of a sphere of the given mass
+func (d DensityRadius) Radius(mass float64) float64 {
+	return math.Cbrt(3 * mass / (4 * math.Pi * d.Density))
+}
+
+// FixedRadius gives every particle the same radius
+type FixedRadius struct {
+	Size float64
+}
+
+// Radius returns the fixed radius
+func (f FixedRadius) Radius(mass float64) float64 {
+	return f.Size
+}
+
+// PointRadius makes every particle a point of zero radius
+type PointRadius struct{}
+
+// Radius returns zero
+func (PointRadius) Radius(mass float64) float64 {
+	return 0
+}
+
+// DefaultRadius returns the density model with DefaultParticleDensity
+func DefaultRadius() RadiusModel {
+	return DensityRadius{Density: DefaultParticleDensity}
+}
+
+// ParseRadiusModel converts a configuration name to a RadiusModel: "density"
+// (or "") with the given density (0 = DefaultParticleDensity), "fixed" with the
+// given radius, or "off"
+func ParseRadiusModel(name string, density, radius float64) (RadiusModel, error) {
+	switch name {
+	case "", "density":
+		if density == 0 {
+			return DefaultRadius(), nil
+		}
+		if !(density > 0) || math.IsInf(density, 0) {
+			return nil, fmt.Errorf("invalid particle density: %v", density)
+		}
+		return DensityRadius{Density: density}, nil
+	case "fixed":
+		if !(radius >= 0) || math.IsInf(radius, 0) {
+			return nil, fmt.Errorf("invalid particle radius: %v", radius)
+		}
+		return FixedRadius{Size: radius}, nil
+	case "off":
+		return PointRadius{}, nil
+	default:
+		return nil, fmt.Errorf("unknown radius model %q", name)
+	}
+}
+
+// ApplyRadius sets the radius of each massive particle from the model.
+// Tracers keep their radius, since they have no mass to derive one from
+func ApplyRadius(particles []*Particle, model RadiusModel) {
+	for _, p := range particles {
+		if !p.IsTracer() {
+			p.Radius = float32(model.Radius(float64(p.Mass)))
+		}
+	}
+}
@@ -0,0 +1,74 @@
+package physics
+
+import (
+	"math"
+	"testing"
+)
+
+// TestDensityRadius tests that the default density matches the radii of the
+// random initial conditions
+func TestDensityRadius(t *testing.T) {
+	model := DefaultRadius()
+	for _, mass := range []float64{20, 35, 50, 1000} {
+		want := math.Pow(mass/20, 1.0/3.0) * 0.5
+		if got := model.Radius(mass); math.Abs(got-want) > 1e-12 {
+			t.Errorf("Mass %g: expected radius %g, got %g", mass, want, got)
+		}
+	}
+
+	// Eight times the mass doubles the radius
+	dense := DensityRadius{Density: 2}
+	if r1, r8 := dense.Radius(1), dense.Radius(8); math.Abs(r8-2*r1) > 1e-12 {
+		t.Errorf("Expected radius %g for eight times the mass, got %g", 2*r1, r8)
+	}
+}
+
+// TestParseRadiusModel tests the configuration names of the radius models
+func TestParseRadiusModel(t *testing.T) {
+	tests := []struct {
+		name      string
+		density   float64
+		radius    float64
+		mass      float64
+		want      float64
+		wantError bool
+	}{
+		{name: "", density: DefaultParticleDensity, mass: 20, want: 0.5},
+		{name: "density", density: 3 / (4 * math.Pi), mass: 8, want: 2},
+		{name: "fixed", radius: 0.3, mass: 100, want: 0.3},
+		{name: "off", mass: 100, want: 0},
+		{name: "density", mass: 20, want: 0.5},
+		{name: "density", density: -1, wantError: true},
+		{name: "fixed", radius: -1, wantError: true},
+		{name: "cubic", wantError: true},
+	}
+	for _, tt := range tests {
+		model, err := ParseRadiusModel(tt.name, tt.density, tt.radius)
+		if tt.wantError {
+			if err == nil {
+				t.Errorf("ParseRadiusModel(%q, %g, %g): expected an error", tt.name, tt.density, tt.radius)
+			}
+			continue
+		}
+		if err != nil {
+			t.Errorf("ParseRadiusModel(%q, %g, %g): %v", tt.name, tt.density, tt.radius, err)
+			continue
+		}
+		if got := model.Radius(tt.mass); math.Abs(got-tt.want) > 1e-12 {
+			t.Errorf("ParseRadiusModel(%q): radius of mass %g is %g, want %g", tt.name, tt.mass, got, tt.want)
+		}
+	}
+}
+
+// TestApplyRadius tests that the model sets massive particles' radii and
+// leaves tracers alone
+func TestApplyRadius(t *testing.T) {
+	particles := []*Particle{NewParticle(20, 0, 0, 0, 0, 0, 0), NewTracer(1, 1, 0, 0)}
+	ApplyRadius(particles, FixedRadius{Size: 2})
+	if particles[0].Radius != 2 {
+		t.Errorf("Expected radius 2, got %v", particles[0].Radius)
+	}
+	if particles[1].Radius != TracerRadius {
+		t.Errorf("Expected the tracer to keep radius %v, got %v", TracerRadius, particles[1].Radius)
+	}
+}
@@ -0,0 +1,73 @@
+package physics
+
+import (
+	"math"
+	"sort"
+)
+
+// Mass fractions enclosed by the Lagrangian radii reported in Diagnostics
+const (
+	LagrangianFraction10 = 0.1
+	LagrangianFraction50 = 0.5
+	LagrangianFraction90 = 0.9
+)
+
+// massAtRadius is a particle's mass and its distance from the center of mass
+type massAtRadius struct {
+	radius, mass float64
+}
+
+// computeRelaxation fills in the Lagrangian radii and velocity anisotropy of
+// d, whose total mass, momentum and center of mass must already be set. Both
+// are measured in the x-z plane around the center of mass, with velocities
+// relative to the center-of-mass velocity
+func computeRelaxation(d *Diagnostics, particles []*Particle) {
+	if d.TotalMass <= 0 {
+		return
+	}
+	com := d.CenterOfMass
+	vx, vz := d.MomentumX/d.TotalMass, d.MomentumZ/d.TotalMass
+
+	shells := make([]massAtRadius, 0, len(particles))
+	var radialSq, tangentialSq float64
+	for _, p := range particles {
+		if !isFiniteVec3(p.Position) || !isFiniteVec3(p.Velocity) {
+			continue
+		}
+		mass := float64(p.Mass)
+		dx, dz := p.Position.X-com.X, p.Position.Z-com.Z
+		r := math.Hypot(dx, dz)
+		shells = append(shells, massAtRadius{radius: r, mass: mass})
+		if r == 0 {
+			continue // No radial direction at the center
+		}
+		ux, uz := p.Velocity.X-vx, p.Velocity.Z-vz
+		radial := (ux*dx + uz*dz) / r
+		tangential := (uz*dx - ux*dz) / r
+		radialSq += mass * radial * radial
+		tangentialSq += mass * tangential * tangential
+	}
+
+	d.LagrangianR10, d.LagrangianR50, d.LagrangianR90 = lagrangianRadii(shells, d.TotalMass)
+	if total := radialSq + tangentialSq; total > 0 {
+		d.VelocityAnisotropy = (radialSq - tangentialSq) / total
+	}
+}
+
+// lagrangianRadii returns the radii enclosing 10%, 50% and 90% of totalMass,
+// sorting shells by radius
+func lagrangianRadii(shells []massAtRadius, totalMass float64) (r10, r50, r90 float64) {
+	sort.Slice(shells, func(i, j int) bool { return shells[i].radius < shells[j].radius })
+
+	fractions := [...]float64{LagrangianFraction10, LagrangianFraction50, LagrangianFraction90}
+	var radii [len(fractions)]float64
+	next, enclosed := 0, 0.0
+	for _, s := range shells {
+		enclosed += s.mass
+		for next < len(fractions) && enclosed >= fractions[next]*totalMass {
+			radii[next] = s.radius
+			next++
+		}
+	}
+	return radii[0], radii[1], radii[2]
+}
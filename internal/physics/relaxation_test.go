@@ -0,0 +1,70 @@
+package physics
+
+import (
+	"math"
+	"testing"
+)
+
+// TestLagrangianRadii tests the radii enclosing 10%, 50% and 90% of the mass
+func TestLagrangianRadii(t *testing.T) {
+	// Ten unit masses at radii 1 to 10 along alternating axes, balanced around the origin
+	var particles []*Particle
+	for i := 1; i <= 10; i++ {
+		r := float64(i)
+		if i%2 == 0 {
+			particles = append(particles, NewParticle(0.5, r, 0, 0, 0, 0, 0), NewParticle(0.5, -r, 0, 0, 0, 0, 0))
+		} else {
+			particles = append(particles, NewParticle(0.5, 0, 0, r, 0, 0, 0), NewParticle(0.5, 0, 0, -r, 0, 0, 0))
+		}
+	}
+
+	d := ComputeDiagnostics(particles)
+	if d.LagrangianR10 != 1 || d.LagrangianR50 != 5 || d.LagrangianR90 != 9 {
+		t.Errorf("Expected R10, R50, R90 = 1, 5, 9, got %g, %g, %g", d.LagrangianR10, d.LagrangianR50, d.LagrangianR90)
+	}
+}
+
+// TestVelocityAnisotropy tests radial, circular and isotropic orbits
+func TestVelocityAnisotropy(t *testing.T) {
+	ring := func(radialSpeed, tangentialSpeed float64) []*Particle {
+		var particles []*Particle
+		for i := 0; i < 8; i++ {
+			angle := float64(i) * math.Pi / 4
+			c, s := math.Cos(angle), math.Sin(angle)
+			vx := radialSpeed*c - tangentialSpeed*s
+			vz := radialSpeed*s + tangentialSpeed*c
+			particles = append(particles, NewParticle(1, 3*c, 0, 3*s, vx, 0, vz))
+		}
+		return particles
+	}
+
+	tests := []struct {
+		name                     string
+		radial, tangential, want float64
+	}{
+		{"radial", 1, 0, 1},
+		{"circular", 0, 1, -1},
+		{"isotropic", 1, 1, 0},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			d := ComputeDiagnostics(ring(tt.radial, tt.tangential))
+			if math.Abs(d.VelocityAnisotropy-tt.want) > 1e-12 {
+				t.Errorf("Expected anisotropy %g, got %g", tt.want, d.VelocityAnisotropy)
+			}
+		})
+	}
+
+	// Bulk motion is removed before measuring
+	particles := ring(1, 0)
+	for _, p := range particles {
+		p.Velocity.X += 5
+	}
+	if d := ComputeDiagnostics(particles); math.Abs(d.VelocityAnisotropy-1) > 1e-12 {
+		t.Errorf("Expected bulk motion to be ignored, got anisotropy %g", d.VelocityAnisotropy)
+	}
+
+	if d := ComputeDiagnostics(ring(0, 0)); d.VelocityAnisotropy != 0 {
+		t.Errorf("Expected 0 for particles at rest, got %g", d.VelocityAnisotropy)
+	}
+}
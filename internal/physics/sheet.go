@@ -0,0 +1,111 @@
+package physics
+
+import "math"
+
+// TracerSheet is a regular Lagrangian sheet of massless tracers, laid out on
+// Columns×Rows points of the domain at rest. Following how the mesh between
+// them deforms shows the flow map of the collapse: where it is compressed,
+// and where it folds over itself in the caustics around the shell crossings
+// that individual particles do not reveal
+type TracerSheet struct {
+	First         int     // Index of the first tracer in the particle slice
+	Columns, Rows int     // Tracers along x and along z
+	SpacingX      float64 // Initial spacing of the tracers along x
+	SpacingZ      float64 // Initial spacing of the tracers along z
+	Width, Height int     // Periodic domain in cells
+	CellSize      float64 // Physical size of a cell (0 = 1)
+}
+
+// NewTracerSheet lays out a sheet of columns×rows tracers over the whole
+// domain of width×height cells of size dx, starting at index first of the
+// particle slice, and returns it with its tracers in row-major order
+func NewTracerSheet(first, columns, rows, width, height int, dx float64) (TracerSheet, []*Particle) {
+	extentX, extentZ := float64(width)*dx, float64(height)*dx
+	s := TracerSheet{
+		First:    first,
+		Columns:  columns,
+		Rows:     rows,
+		SpacingX: extentX / float64(columns),
+		SpacingZ: extentZ / float64(rows),
+		Width:    width,
+		Height:   height,
+		CellSize: dx,
+	}
+	tracers := make([]*Particle, 0, columns*rows)
+	for j := 0; j < rows; j++ {
+		for i := 0; i < columns; i++ {
+			x := -extentX/2 + (float64(i)+0.5)*s.SpacingX
+			z := -extentZ/2 + (float64(j)+0.5)*s.SpacingZ
+			tracers = append(tracers, NewTracer(x, z, 0, 0))
+		}
+	}
+	return s, tracers
+}
+
+// Len returns the number of tracers in the sheet
+func (s TracerSheet) Len() int {
+	return s.Columns * s.Rows
+}
+
+// Index returns the particle index of the tracer at column i and row j,
+// wrapping around the sheet like the domain
+func (s TracerSheet) Index(i, j int) int {
+	return s.First + wrapIndex(j, s.Rows)*s.Columns + wrapIndex(i, s.Columns)
+}
+
+// Contains reports whether particle index k is one of the sheet's tracers
+func (s TracerSheet) Contains(k int) bool {
+	return k >= s.First && k < s.First+s.Len()
+}
+
+// Offset returns the nearest-image separation (dx, dz) from particle a to b
+func (s TracerSheet) Offset(a, b Vec3) (dx, dz float64) {
+	cell := spacing(s.CellSize)
+	return nearestImage(b.X-a.X, float64(s.Width)*cell), nearestImage(b.Z-a.Z, float64(s.Height)*cell)
+}
+
+// Stretch returns the Jacobian determinant of the flow map at each tracer,
+// det ∂x/∂q from central differences over the neighboring tracers, relative
+// to the initial sheet. It is 1 where the sheet is undisturbed, between 0 and
+// 1 where it is compressed, and negative where it has folded over, so the
+// stream density there is 1/|J| summed over several streams
+func (s TracerSheet) Stretch(positions func(k int) Vec3) []float64 {
+	stretch := make([]float64, s.Len())
+	area := 4 * s.SpacingX * s.SpacingZ
+	for j := 0; j < s.Rows; j++ {
+		for i := 0; i < s.Columns; i++ {
+			xx, xz := s.Offset(positions(s.Index(i-1, j)), positions(s.Index(i+1, j)))
+			zx, zz := s.Offset(positions(s.Index(i, j-1)), positions(s.Index(i, j+1)))
+			stretch[j*s.Columns+i] = (xx*zz - xz*zx) / area
+		}
+	}
+	return stretch
+}
+
+// FoldedFraction returns the fraction of the stretches that are negative,
+// the share of the sheet that has gone through a shell crossing
+func FoldedFraction(stretch []float64) float64 {
+	if len(stretch) == 0 {
+		return 0
+	}
+	folded := 0
+	for _, j := range stretch {
+		if j < 0 {
+			folded++
+		}
+	}
+	return float64(folded) / float64(len(stretch))
+}
+
+// Compression maps a stretch onto [0, 1] for coloring: 0 for an undisturbed
+// or expanded sheet, rising with log(1/J) to 1 at maxCompression-fold
+// compression and beyond, and for folds
+func Compression(stretch, maxCompression float64) float64 {
+	if stretch <= 0 {
+		return 1
+	}
+	if stretch >= 1 || maxCompression <= 1 {
+		return 0
+	}
+	return math.Min(1, math.Log(1/stretch)/math.Log(maxCompression))
+}
@@ -0,0 +1,93 @@
+package physics
+
+import (
+	"math"
+	"testing"
+)
+
+// TestTracerSheetLayout tests the sheet's tracers and indexing
+func TestTracerSheetLayout(t *testing.T) {
+	sheet, tracers := NewTracerSheet(5, 4, 2, 64, 32, 1)
+	if len(tracers) != 8 || sheet.Len() != 8 {
+		t.Fatalf("Expected 8 tracers, got %d", len(tracers))
+	}
+	for _, p := range tracers {
+		if !p.IsTracer() || p.Velocity != (Vec3{}) {
+			t.Fatalf("Expected tracers at rest, got %+v", p)
+		}
+	}
+	if first := tracers[0].Position; first.X != -24 || first.Z != -8 {
+		t.Errorf("Expected the first tracer at (-24, -8), got (%g, %g)", first.X, first.Z)
+	}
+	if sheet.Index(0, 0) != 5 || sheet.Index(-1, 0) != 8 || sheet.Index(1, 2) != 6 {
+		t.Errorf("Unexpected indices %d, %d, %d", sheet.Index(0, 0), sheet.Index(-1, 0), sheet.Index(1, 2))
+	}
+	if !sheet.Contains(12) || sheet.Contains(13) || sheet.Contains(4) {
+		t.Error("Unexpected Contains")
+	}
+}
+
+// TestTracerSheetStretch tests the Jacobian of an undisturbed, a compressed
+// and a folded sheet
+func TestTracerSheetStretch(t *testing.T) {
+	sheet, tracers := NewTracerSheet(0, 8, 8, 64, 64, 1)
+	position := func(k int) Vec3 { return tracers[k].Position }
+	for k, j := range sheet.Stretch(position) {
+		if math.Abs(j-1) > 1e-12 {
+			t.Fatalf("Expected stretch 1 at tracer %d of the initial sheet, got %g", k, j)
+		}
+	}
+
+	// Move column 3 to the right of column 5, so the sheet folds over at
+	// column 4 between them and stretches on either side
+	for j := 0; j < 8; j++ {
+		tracers[sheet.Index(3, j)].Position.X += 6
+		tracers[sheet.Index(5, j)].Position.X -= 12
+	}
+	stretch := sheet.Stretch(position)
+	if s := stretch[sheet.Index(2, 0)]; math.Abs(s-1.375) > 1e-12 {
+		t.Errorf("Expected stretch 1.375 left of the fold, got %g", s)
+	}
+	if s := stretch[sheet.Index(4, 0)]; s >= 0 {
+		t.Errorf("Expected a fold at column 4, got stretch %g", s)
+	}
+	if f := FoldedFraction(stretch); f != 0.125 {
+		t.Errorf("Expected one column in eight folded, got %g", f)
+	}
+}
+
+// TestTracerSheetCellSize tests that a sheet over a domain of half-size cells
+// joins its edge columns across the wrap, so the initial sheet is undisturbed
+func TestTracerSheetCellSize(t *testing.T) {
+	sheet, tracers := NewTracerSheet(0, 8, 8, 64, 64, 0.5)
+	if sheet.SpacingX != 4 || sheet.SpacingZ != 4 {
+		t.Fatalf("Expected spacing 4, got %g×%g", sheet.SpacingX, sheet.SpacingZ)
+	}
+	if dx, _ := sheet.Offset(tracers[sheet.Index(7, 0)].Position, tracers[sheet.Index(0, 0)].Position); dx != 4 {
+		t.Errorf("Expected the last column 4 left of the first across the wrap, got %g", dx)
+	}
+	position := func(k int) Vec3 { return tracers[k].Position }
+	for k, j := range sheet.Stretch(position) {
+		if math.Abs(j-1) > 1e-12 {
+			t.Fatalf("Expected stretch 1 at tracer %d of the initial sheet, got %g", k, j)
+		}
+	}
+}
+
+// TestCompression tests mapping stretches onto the color ramp
+func TestCompression(t *testing.T) {
+	cases := []struct{ stretch, want float64 }{{2, 0}, {1, 0}, {0.1, 0.5}, {0.001, 1}, {-0.5, 1}}
+	for _, c := range cases {
+		if got := Compression(c.stretch, 100); math.Abs(got-c.want) > 1e-12 {
+			t.Errorf("Compression(%g) = %g, want %g", c.stretch, got, c.want)
+		}
+	}
+}
+
+// TestBinariesIgnoreTracers tests that a tracer orbiting a mass is not a binary
+func TestBinariesIgnoreTracers(t *testing.T) {
+	particles := []*Particle{NewParticle(10, 0, 0, 0, 0, 0, 0), NewTracer(1, 0, 0, 1)}
+	if b := FindBinaries(particles, BinaryOptions{MaxSeparation: 5, GravitationalConstant: 1}); len(b) != 0 {
+		t.Errorf("Expected no binaries, got %+v", b)
+	}
+}
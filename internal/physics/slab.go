@@ -0,0 +1,67 @@
+package physics
+
+import (
+	"sync/atomic"
+	"unsafe"
+)
+
+// LargeGridCells is the cell count from which grids and FFT scratch get
+// aligned slabs. Smaller grids fit the TLB reach of normal pages anyway
+const LargeGridCells = 1024 * 1024
+
+// slabAlignment is the start alignment of large slabs, the size of a
+// transparent huge page on x86-64 and arm64
+const slabAlignment = 2 << 20
+
+// hugePages asks the kernel to back new large slabs with huge pages
+var hugePages atomic.Bool
+
+// SetHugePages sets whether large grids allocated from now on are advised
+// to use transparent huge pages (Linux only; elsewhere it has no effect)
+func SetHugePages(on bool) {
+	hugePages.Store(on)
+}
+
+// alignedSlab returns zeroed, pointer-free memory of size bytes starting on
+// a slabAlignment boundary. The returned pointer keeps the allocation alive
+func alignedSlab(size int) unsafe.Pointer {
+	buf := make([]byte, size+slabAlignment)
+	offset := -uintptr(unsafe.Pointer(&buf[0])) & (slabAlignment - 1)
+	p := unsafe.Pointer(&buf[offset])
+	if hugePages.Load() {
+		adviseHugePages(p, size)
+	}
+	return p
+}
+
+// float64Slab returns the backing array of a float64 grid with n cells
+func float64Slab(n int) []float64 {
+	if n < LargeGridCells {
+		return make([]float64, n)
+	}
+	return unsafe.Slice((*float64)(alignedSlab(n*8)), n)
+}
+
+// float32Slab returns the backing array of a float32 grid with n cells
+func float32Slab(n int) []float32 {
+	if n < LargeGridCells {
+		return make([]float32, n)
+	}
+	return unsafe.Slice((*float32)(alignedSlab(n*4)), n)
+}
+
+// complex128Slab returns the backing array of a complex128 grid with n cells
+func complex128Slab(n int) []complex128 {
+	if n < LargeGridCells {
+		return make([]complex128, n)
+	}
+	return unsafe.Slice((*complex128)(alignedSlab(n*16)), n)
+}
+
+// complex64Slab returns the backing array of a complex64 grid with n cells
+func complex64Slab(n int) []complex64 {
+	if n < LargeGridCells {
+		return make([]complex64, n)
+	}
+	return unsafe.Slice((*complex64)(alignedSlab(n*8)), n)
+}
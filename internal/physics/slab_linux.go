@@ -0,0 +1,16 @@
+package physics
+
+import (
+	"syscall"
+	"unsafe"
+)
+
+// adviseHugePages marks the whole pages of a slab for transparent huge
+// pages. The advice is a hint, so errors such as THP being disabled are
+// ignored
+func adviseHugePages(p unsafe.Pointer, size int) {
+	size &^= syscall.Getpagesize() - 1
+	if size > 0 {
+		syscall.Madvise(unsafe.Slice((*byte)(p), size), syscall.MADV_HUGEPAGE)
+	}
+}
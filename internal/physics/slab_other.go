@@ -0,0 +1,8 @@
+//go:build !linux
+
+package physics
+
+import "unsafe"
+
+// adviseHugePages does nothing where madvise is unavailable
+func adviseHugePages(p unsafe.Pointer, size int) {}
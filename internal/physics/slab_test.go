@@ -0,0 +1,36 @@
+package physics
+
+import (
+	"testing"
+	"unsafe"
+)
+
+// TestLargeGridAlignment tests that large grids start on a huge page
+// boundary and are zeroed and contiguous, with and without huge pages
+func TestLargeGridAlignment(t *testing.T) {
+	defer SetHugePages(false)
+	for _, huge := range []bool{false, true} {
+		SetHugePages(huge)
+		grid := NewGrid(1024, 1024)
+		if addr := uintptr(unsafe.Pointer(&grid[0][0])); addr%slabAlignment != 0 {
+			t.Errorf("Expected a %d-byte aligned grid (huge pages %v), got address %#x", slabAlignment, huge, addr)
+		}
+		if uintptr(unsafe.Pointer(&grid[1][0]))-uintptr(unsafe.Pointer(&grid[0][0])) != 1024*8 {
+			t.Error("Expected contiguous rows")
+		}
+		for i := range grid {
+			for j := range grid[i] {
+				if grid[i][j] != 0 {
+					t.Fatalf("Expected a zeroed grid, got %g at (%d, %d)", grid[i][j], i, j)
+				}
+			}
+		}
+		grid[1023][1023] = 1 // The last cell is inside the slab
+
+		scratch := acquireComplexGrid(1024, 1024)
+		if addr := uintptr(unsafe.Pointer(&scratch.rows[0][0])); addr%slabAlignment != 0 {
+			t.Errorf("Expected aligned FFT scratch, got address %#x", addr)
+		}
+		releaseComplexGrid(scratch)
+	}
+}
@@ -0,0 +1,258 @@
+package physics
+
+import "math"
+
+// TileSize is the side, in cells, of a sparse grid tile
+const TileSize = 32
+
+// SparseGrid is a width×height density grid stored as TileSize×TileSize
+// tiles. Only tiles that received mass are allocated, so depositing into
+// a huge, mostly empty domain costs memory and time for the occupied
+// region only. Tiles at the right and top edges may extend past the grid
+type SparseGrid struct {
+	Width, Height  int
+	tilesX, tilesZ int
+	tiles          [][]float64 // TileSize² cells per tile, indexed ti*tilesZ+tj (nil = empty)
+	active         []int       // Indices of the allocated tiles
+	free           [][]float64 // Cleared tiles kept for reuse
+}
+
+// NewSparseGrid creates an empty sparse grid
+func NewSparseGrid(width, height int) *SparseGrid {
+	tilesX := (width + TileSize - 1) / TileSize
+	tilesZ := (height + TileSize - 1) / TileSize
+	return &SparseGrid{
+		Width:  width,
+		Height: height,
+		tilesX: tilesX,
+		tilesZ: tilesZ,
+		tiles:  make([][]float64, tilesX*tilesZ),
+	}
+}
+
+// Clear empties the grid, keeping the tiles for reuse
+func (g *SparseGrid) Clear() {
+	for _, t := range g.active {
+		clear(g.tiles[t])
+		g.free = append(g.free, g.tiles[t])
+		g.tiles[t] = nil
+	}
+	g.active = g.active[:0]
+}
+
+// ActiveTiles returns the number of allocated tiles
+func (g *SparseGrid) ActiveTiles() int {
+	return len(g.active)
+}
+
+// TotalTiles returns the number of tiles covering the grid
+func (g *SparseGrid) TotalTiles() int {
+	return len(g.tiles)
+}
+
+// At returns the value of cell (i, j)
+func (g *SparseGrid) At(i, j int) float64 {
+	tile := g.tiles[(i/TileSize)*g.tilesZ+j/TileSize]
+	if tile == nil {
+		return 0
+	}
+	return tile[(i%TileSize)*TileSize+j%TileSize]
+}
+
+// Add adds v to cell (i, j), allocating its tile if needed
+func (g *SparseGrid) Add(i, j int, v float64) {
+	t := (i/TileSize)*g.tilesZ + j/TileSize
+	tile := g.tiles[t]
+	if tile == nil {
+		if n := len(g.free); n > 0 {
+			tile, g.free = g.free[n-1], g.free[:n-1]
+		} else {
+			tile = make([]float64, TileSize*TileSize)
+		}
+		g.tiles[t] = tile
+		g.active = append(g.active, t)
+	}
+	tile[(i%TileSize)*TileSize+j%TileSize] += v
+}
+
+// Deposit clears the grid and deposits the particles with periodic
+// Cloud-in-Cell on cells of size dx, as DepositMassToGridInto does on a
+// dense grid, with each position first shifted by (-offsetX, -offsetZ)
+func (g *SparseGrid) Deposit(particles []*Particle, offsetX, offsetZ, dx float64) {
+	g.Clear()
+	for _, p := range particles {
+		c := locateCell(p.Position.X-offsetX, p.Position.Z-offsetZ, g.Width, g.Height, dx)
+		mass := float64(p.Mass)
+		g.Add(c.i, c.j, float64(float64(mass*(1-c.fx))*(1-c.fz)))
+		g.Add(c.nextI, c.j, float64(float64(mass*c.fx)*(1-c.fz)))
+		g.Add(c.i, c.nextJ, float64(float64(mass*(1-c.fx))*c.fz))
+		g.Add(c.nextI, c.nextJ, float64(float64(mass*c.fx)*c.fz))
+	}
+}
+
+// ToDense clears dst, which must be Width×Height, and copies the allocated tiles into it
+func (g *SparseGrid) ToDense(dst Grid) {
+	dst.mustCover(g.Width, g.Height, "sparse density")
+	ClearGrid(dst)
+	for _, t := range g.active {
+		g.eachCell(t, func(i, j, k int) {
+			dst[i][j] = g.tiles[t][k]
+		})
+	}
+}
+
+// eachCell calls fn for each cell of tile t inside the grid, with the index of the cell within the tile
+func (g *SparseGrid) eachCell(t int, fn func(i, j, k int)) {
+	i0, j0 := (t/g.tilesZ)*TileSize, (t%g.tilesZ)*TileSize
+	for di := 0; di < TileSize && i0+di < g.Width; di++ {
+		for dj := 0; dj < TileSize && j0+dj < g.Height; dj++ {
+			fn(i0+di, j0+dj, di*TileSize+dj)
+		}
+	}
+}
+
+// GradientTiles computes a = -∇Φ into forceField like CalculateGradientInto,
+// but only over the allocated tiles and the tiles around them. Particles
+// only sample the field next to cells they deposited into, so the rest of
+// the field is left as it was
+func (g *SparseGrid) GradientTiles(forceField *ForceField, potentialGrid Grid) {
+	forceField.checkDims()
+	width, height := forceField.Width, forceField.Height
+	potentialGrid.mustCover(width, height, "potential")
+
+	needed := make([]bool, len(g.tiles))
+	for _, t := range g.active {
+		ti, tj := t/g.tilesZ, t%g.tilesZ
+		for di := -1; di <= 1; di++ {
+			for dj := -1; dj <= 1; dj++ {
+				needed[wrapIndex(ti+di, g.tilesX)*g.tilesZ+wrapIndex(tj+dj, g.tilesZ)] = true
+			}
+		}
+	}
+	span := 2 * forceField.Dx()
+	for t, ok := range needed {
+		if !ok {
+			continue
+		}
+		g.eachCell(t, func(i, j, _ int) {
+			prevI := (i - 1 + width) % width
+			nextI := (i + 1) % width
+			prevJ := (j - 1 + height) % height
+			nextJ := (j + 1) % height
+			forceField.AccelFieldX.SetUnchecked(i, j, -(potentialGrid.AtUnchecked(nextI, j)-potentialGrid.AtUnchecked(prevI, j))/span)
+			forceField.AccelFieldZ.SetUnchecked(i, j, -(potentialGrid.AtUnchecked(i, nextJ)-potentialGrid.AtUnchecked(i, prevJ))/span)
+		})
+	}
+}
+
+// SparseStats describes the last force evaluation of a SparseSolver
+type SparseStats struct {
+	ActiveTiles int // Tiles holding mass
+	TotalTiles  int // Tiles covering the solved region
+	SolveWidth  int // Size of the solved region in cells; smaller than the domain when cropped
+	SolveHeight int
+}
+
+// SparseSolver runs particle-mesh steps on a sparse density grid, for runs
+// whose particles occupy a small part of a large domain. With Crop set, the
+// Poisson equation is solved on a power-of-two window around the particles
+// instead of the whole domain. The window is periodic, so the particles feel
+// images at the window size instead of the domain size; a padding at least
+// as wide as the particle region keeps their effect small
+type SparseSolver struct {
+	Crop     bool
+	Padding  int     // Empty cells kept around the particles in a cropped solve (0 = the extent of the particles)
+	CellSize float64 // Physical size of a cell (0 = 1)
+
+	density map[[2]int]*SparseGrid // Sparse grids by solved size
+	stats   SparseStats
+}
+
+// NewSparseSolver creates a sparse solver of the full domain
+func NewSparseSolver() *SparseSolver {
+	return &SparseSolver{density: make(map[[2]int]*SparseGrid)}
+}
+
+// GetStats returns the statistics of the last force evaluation
+func (s *SparseSolver) GetStats() SparseStats {
+	return s.stats
+}
+
+// Step advances the particles by dt with the kick-drift-kick scheme of RunTimeEvolution
+func (s *SparseSolver) Step(particles []*Particle, dt float32, width, height int, gravitationalConstant float64) {
+	forceCorrectionFactor := float32(0.5)
+
+	s.kick(particles, dt*0.5, forceCorrectionFactor, width, height, gravitationalConstant)
+	UpdatePositions(particles, dt, width, height, spacing(s.CellSize))
+	s.kick(particles, dt*0.5, forceCorrectionFactor, width, height, gravitationalConstant)
+}
+
+// kick solves for the field of the particles and updates their velocities by dt
+func (s *SparseSolver) kick(particles []*Particle, dt, forceCorrectionFactor float32, width, height int, gravitationalConstant float64) {
+	solveWidth, centerX := s.window(particles, width, func(p *Particle) float64 { return p.Position.X })
+	solveHeight, centerZ := s.window(particles, height, func(p *Particle) float64 { return p.Position.Z })
+
+	key := [2]int{solveWidth, solveHeight}
+	density := s.density[key]
+	if density == nil {
+		density = NewSparseGrid(solveWidth, solveHeight)
+		s.density[key] = density
+	}
+	dx := spacing(s.CellSize)
+	density.Deposit(particles, centerX, centerZ, dx)
+
+	massGrid := acquireGrid(solveWidth, solveHeight)
+	defer releaseGrid(massGrid)
+	potentialGrid := acquireGrid(solveWidth, solveHeight)
+	defer releaseGrid(potentialGrid)
+	forceField := AcquireForceField(solveWidth, solveHeight, dx)
+	defer forceField.Release()
+
+	density.ToDense(massGrid.rows)
+	SolvePoissonFFTInto(potentialGrid.rows, massGrid.rows, dx, gravitationalConstant)
+	density.GradientTiles(forceField, potentialGrid.rows)
+
+	for _, p := range particles {
+		ax, az := interpolateAccelerationXZ(p.Position.X-centerX, p.Position.Z-centerZ, forceField)
+		p.Velocity.X += float64(ax * float64(dt) * float64(forceCorrectionFactor))
+		p.Velocity.Z += float64(az * float64(dt) * float64(forceCorrectionFactor))
+	}
+
+	s.stats = SparseStats{
+		ActiveTiles: density.ActiveTiles(),
+		TotalTiles:  density.TotalTiles(),
+		SolveWidth:  solveWidth,
+		SolveHeight: solveHeight,
+	}
+}
+
+// window returns the solved extent along one axis of the given domain
+// extent and the position of its center. Without cropping, or when the
+// padded particles would not fit in a smaller power of two, it is the whole
+// domain. The center is a whole cell so the window's cells line up with the
+// domain's
+func (s *SparseSolver) window(particles []*Particle, extent int, coordinate func(*Particle) float64) (int, float64) {
+	if !s.Crop || len(particles) == 0 {
+		return extent, 0
+	}
+	dx := spacing(s.CellSize)
+	lo, hi := math.Inf(1), math.Inf(-1)
+	for _, p := range particles {
+		v := coordinate(p) / dx
+		lo, hi = math.Min(lo, v), math.Max(hi, v)
+	}
+	padding := float64(s.Padding)
+	if s.Padding <= 0 {
+		padding = hi - lo
+	}
+	// Two extra cells keep the Cloud-in-Cell stencils and gradient inside
+	needed := int(math.Ceil(hi-lo+2*padding)) + 2
+	size := TileSize
+	for size < needed {
+		size *= 2
+	}
+	if size >= extent {
+		return extent, 0
+	}
+	return size, math.Round((lo+hi)/2) * dx
+}
@@ -0,0 +1,137 @@
+package physics
+
+import (
+	"math"
+	"math/rand"
+	"testing"
+)
+
+// gaussianCluster returns n particles in a Gaussian cluster of the given
+// width around (x, z), orbiting slowly so the steps move them
+func gaussianCluster(n int, x, z, width float64, seed int64) []*Particle {
+	rng := rand.New(rand.NewSource(seed))
+	particles := make([]*Particle, n)
+	for i := range particles {
+		dx, dz := rng.NormFloat64()*width, rng.NormFloat64()*width
+		particles[i] = NewParticle(1+rng.Float64(), x+dx, 0, z+dz, -dz*0.1, 0, dx*0.1)
+	}
+	return particles
+}
+
+// cloneParticles returns a deep copy of the particles
+func cloneParticles(particles []*Particle) []*Particle {
+	out := make([]*Particle, len(particles))
+	for i, p := range particles {
+		c := *p
+		out[i] = &c
+	}
+	return out
+}
+
+// TestSparseGridDeposit tests that the sparse deposit matches the dense one
+// while allocating only the occupied tiles
+func TestSparseGridDeposit(t *testing.T) {
+	particles := gaussianCluster(500, 100, -60, 5, 1)
+	dense := NewGrid(512, 256)
+	DepositMassToGridInto(dense, particles, nil)
+
+	sparse := NewSparseGrid(512, 256)
+	sparse.Deposit(particles, 0, 0, 1)
+	if sparse.ActiveTiles() == 0 || sparse.ActiveTiles() > 9 {
+		t.Errorf("Expected a few tiles for a compact cluster, got %d of %d", sparse.ActiveTiles(), sparse.TotalTiles())
+	}
+	got := NewGrid(512, 256)
+	sparse.ToDense(got)
+	if d := maxGridDifference(dense, got); d != 0 {
+		t.Errorf("Expected the sparse deposit to match the dense one, max difference %g", d)
+	}
+	if v := sparse.At(300, 128); v != dense[300][128] {
+		t.Errorf("Expected At to read the deposited value %g, got %g", dense[300][128], v)
+	}
+
+	// Clearing keeps the tiles for reuse
+	sparse.Clear()
+	if sparse.ActiveTiles() != 0 || sparse.At(300, 128) != 0 {
+		t.Error("Expected an empty grid after Clear")
+	}
+}
+
+// TestSparseSolverMatchesDense tests that an uncropped sparse step is the dense step
+func TestSparseSolverMatchesDense(t *testing.T) {
+	particles := gaussianCluster(300, 20, 30, 4, 2)
+	reference := cloneParticles(particles)
+
+	solver := NewSparseSolver()
+	for step := 0; step < 3; step++ {
+		solver.Step(particles, 0.1, 256, 256, 1)
+		RunTimeEvolution(reference, 0.1, 256, 256, nil, 1).Release()
+	}
+	for i, p := range particles {
+		if p.Position != reference[i].Position || p.Velocity != reference[i].Velocity {
+			t.Fatalf("Particle %d differs from the dense step: %+v vs %+v", i, p, reference[i])
+		}
+	}
+	if stats := solver.GetStats(); stats.SolveWidth != 256 || stats.ActiveTiles >= stats.TotalTiles {
+		t.Errorf("Unexpected stats %+v", stats)
+	}
+}
+
+// TestSparseSolverCrop tests that a cropped solve of a compact cluster in a
+// large domain gives nearly the accelerations of the full solve
+func TestSparseSolverCrop(t *testing.T) {
+	particles := gaussianCluster(400, -150, 80, 4, 3)
+	reference := cloneParticles(particles)
+
+	solver := NewSparseSolver()
+	solver.Crop = true
+	solver.Step(particles, 0.1, 1024, 1024, 1)
+	RunTimeEvolution(reference, 0.1, 1024, 1024, nil, 1).Release()
+
+	stats := solver.GetStats()
+	if stats.SolveWidth >= 1024 || stats.SolveHeight >= 1024 {
+		t.Fatalf("Expected a cropped solve, got %+v", stats)
+	}
+
+	// Compare the velocity changes the forces made
+	maxKick, maxDiff := 0.0, 0.0
+	initial := gaussianCluster(400, -150, 80, 4, 3)
+	for i, p := range particles {
+		kick := reference[i].Velocity.Sub(initial[i].Velocity).Length()
+		maxKick = math.Max(maxKick, kick)
+		maxDiff = math.Max(maxDiff, p.Velocity.Sub(reference[i].Velocity).Length())
+	}
+	if maxDiff > 0.05*maxKick {
+		t.Errorf("Cropped kicks differ by %g (largest kick %g)", maxDiff, maxKick)
+	}
+
+	// Particles spread over the whole domain are solved in full
+	spread := gaussianCluster(100, 0, 0, 300, 4)
+	solver.Step(spread, 0.1, 1024, 1024, 1)
+	if stats := solver.GetStats(); stats.SolveWidth != 1024 {
+		t.Errorf("Expected a full solve for spread particles, got %+v", stats)
+	}
+}
+
+// BenchmarkSparseStep compares dense, sparse and cropped steps of a compact
+// cluster in a 1024x1024 domain
+func BenchmarkSparseStep(b *testing.B) {
+	particles := gaussianCluster(2000, 0, 0, 8, 5)
+	b.Run("dense", func(b *testing.B) {
+		for i := 0; i < b.N; i++ {
+			RunTimeEvolution(particles, 0.01, 1024, 1024, nil, 1).Release()
+		}
+	})
+	b.Run("sparse", func(b *testing.B) {
+		solver := NewSparseSolver()
+		for i := 0; i < b.N; i++ {
+			solver.Step(particles, 0.01, 1024, 1024, 1)
+		}
+	})
+	b.Run("cropped", func(b *testing.B) {
+		solver := NewSparseSolver()
+		solver.Crop = true
+		for i := 0; i < b.N; i++ {
+			solver.Step(particles, 0.01, 1024, 1024, 1)
+		}
+	})
+}
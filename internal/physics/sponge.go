@@ -0,0 +1,52 @@
+package physics
+
+import "math"
+
+// DefaultSpongeStrength is the damping rate of a sponge layer at the domain
+// edge, per unit time
+const DefaultSpongeStrength = 2.0
+
+// Sponge is an absorbing layer along the edges of the periodic domain. The
+// FFT solver always sees periodic images, so material crossing the edge
+// reappears on the far side and pulls on its images. The sponge damps the
+// velocities of particles in the layer, so matter escaping an isolated
+// system settles near the edge instead of wrapping around and falling back
+type Sponge struct {
+	Width, Height int     // Domain size in cells
+	Thickness     float64 // Depth of the layer in cells
+	Strength      float64 // Damping rate at the edge, per unit time (0 = DefaultSpongeStrength)
+	CellSize      float64 // Physical size of a cell (0 = 1)
+}
+
+// Rate returns the damping rate at (x, z). It is zero inside the layer's
+// inner boundary and rises quadratically to Strength at the edge, so
+// particles entering the layer are not reflected by a sudden change
+func (s Sponge) Rate(x, z float64) float64 {
+	if s.Thickness <= 0 {
+		return 0
+	}
+	dx := spacing(s.CellSize)
+	depth := math.Min(float64(s.Width)/2-math.Abs(x)/dx, float64(s.Height)/2-math.Abs(z)/dx)
+	if depth >= s.Thickness {
+		return 0
+	}
+	strength := s.Strength
+	if strength <= 0 {
+		strength = DefaultSpongeStrength
+	}
+	t := (s.Thickness - math.Max(depth, 0)) / s.Thickness
+	return strength * t * t
+}
+
+// Kick damps each velocity in the layer by exp(-rate·dt). The decay is
+// applied exactly rather than stepped, so it is stable at any strength
+func (s Sponge) Kick(particles []*Particle, dt float64) {
+	for _, p := range particles {
+		rate := s.Rate(p.Position.X, p.Position.Z)
+		if rate == 0 {
+			continue
+		}
+		decay := math.Exp(-rate * dt)
+		p.Velocity = p.Velocity.Scale(decay)
+	}
+}
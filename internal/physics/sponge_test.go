@@ -0,0 +1,60 @@
+package physics
+
+import (
+	"math"
+	"testing"
+)
+
+// TestSpongeRate tests the damping profile across the layer
+func TestSpongeRate(t *testing.T) {
+	s := Sponge{Width: 100, Height: 60, Thickness: 10, Strength: 4}
+	tests := []struct {
+		x, z, want float64
+	}{
+		{0, 0, 0},    // Center
+		{39, 0, 0},   // Inner boundary along X
+		{45, 0, 1},   // Halfway into the layer: 4·(1/2)²
+		{50, 0, 4},   // Edge
+		{0, -30, 4},  // Edge along Z, which is closer
+		{-10, 25, 1}, // Halfway into the Z layer
+		{60, 0, 4},   // Past the edge clamps to the edge rate
+	}
+	for _, tt := range tests {
+		if got := s.Rate(tt.x, tt.z); math.Abs(got-tt.want) > 1e-12 {
+			t.Errorf("Rate(%g, %g) = %g, want %g", tt.x, tt.z, got, tt.want)
+		}
+	}
+
+	if got := (Sponge{Width: 100, Height: 100, Thickness: 10}).Rate(50, 0); got != DefaultSpongeStrength {
+		t.Errorf("Expected the default strength at the edge, got %g", got)
+	}
+	if got := (Sponge{Width: 100, Height: 100}).Rate(50, 0); got != 0 {
+		t.Errorf("Expected no damping without a layer, got %g", got)
+	}
+}
+
+// TestSpongeKick tests that the kick decays velocities exactly in the layer
+// and leaves the interior untouched
+func TestSpongeKick(t *testing.T) {
+	s := Sponge{Width: 100, Height: 100, Thickness: 10, Strength: 3}
+	inside := NewParticle(1, 0, 0, 0, 2, 0, -1)
+	edge := NewParticle(1, 50, 0, 0, 2, 0, -1)
+
+	s.Kick([]*Particle{inside, edge}, 0.5)
+	if inside.Velocity != NewVec3(2, 0, -1) {
+		t.Errorf("Expected the interior velocity unchanged, got %v", inside.Velocity)
+	}
+	decay := math.Exp(-3 * 0.5)
+	if math.Abs(edge.Velocity.X-2*decay) > 1e-12 || math.Abs(edge.Velocity.Z+decay) > 1e-12 {
+		t.Errorf("Expected the edge velocity decayed by %g, got %v", decay, edge.Velocity)
+	}
+
+	// Two half kicks damp like one full kick
+	a := NewParticle(1, 47, 0, 0, 1, 0, 0)
+	b := NewParticle(1, 47, 0, 0, 1, 0, 0)
+	KickForces([]*Particle{a}, []Force{s}, 1)
+	s.Kick([]*Particle{b}, 1)
+	if math.Abs(a.Velocity.X-b.Velocity.X) > 1e-12 {
+		t.Errorf("Expected split kicks %g to match %g", a.Velocity.X, b.Velocity.X)
+	}
+}
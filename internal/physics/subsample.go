@@ -0,0 +1,40 @@
+package physics
+
+// SetDepositStride makes mass deposition on the mesh use every stride-th
+// particle, with the masses scaled up so the total mass is kept. The subset
+// rotates with each deposit, so over stride deposits every particle
+// contributes. A stride of 1 or less deposits every particle. Deterministic
+// deposition ignores it
+func (m *Mesh) SetDepositStride(stride int) {
+	m.stride.Store(int32(max(stride, 1)))
+}
+
+// DepositStride returns the stride set by SetDepositStride (1 for a nil mesh)
+func (m *Mesh) DepositStride() int {
+	if m == nil {
+		return 1
+	}
+	return max(int(m.stride.Load()), 1)
+}
+
+// subsample returns the first particle index to deposit and the mass scale
+// of the subset starting there, or ok = false to deposit every particle
+func (m *Mesh) subsample(particles []*Particle) (first, stride int, scale float64, ok bool) {
+	stride = m.DepositStride()
+	if stride <= 1 || len(particles) < stride {
+		return 0, 1, 1, false
+	}
+	first = int(m.offset.Add(1)) % stride
+
+	var total, sampled float64
+	for i, p := range particles {
+		total += float64(p.Mass)
+		if i%stride == first {
+			sampled += float64(p.Mass)
+		}
+	}
+	if sampled == 0 {
+		return 0, 1, 1, false
+	}
+	return first, stride, total / sampled, true
+}
@@ -0,0 +1,118 @@
+package physics
+
+import (
+	"math"
+	"math/rand"
+	"testing"
+)
+
+// TestSubsampledDeposit tests that a sub-sampled deposit keeps the total mass
+// and covers every particle over stride deposits
+func TestSubsampledDeposit(t *testing.T) {
+	rng := rand.New(rand.NewSource(1))
+	particles := make([]*Particle, 101)
+	total := 0.0
+	for i := range particles {
+		particles[i] = NewParticle(1+rng.Float64(), rng.Float64()*60-30, 0, rng.Float64()*60-30, 0, 0, 0)
+		total += float64(particles[i].Mass)
+	}
+
+	mesh := &Mesh{}
+	mesh.SetDepositStride(2)
+	if mesh.DepositStride() != 2 {
+		t.Fatalf("Expected stride 2, got %d", mesh.DepositStride())
+	}
+	first := NewGrid(64, 64)
+	second := NewGrid(64, 64)
+	DepositMassToGridInto(first, particles, mesh)
+	DepositMassToGridInto(second, particles, mesh)
+
+	if sum := gridSum(first); math.Abs(sum-total) > 1e-9*total {
+		t.Errorf("Expected total mass %g, got %g", total, sum)
+	}
+	if maxGridDifference(first, second) == 0 {
+		t.Error("Expected consecutive deposits to use different subsets")
+	}
+
+	// Stride 1 deposits every particle again
+	mesh.SetDepositStride(1)
+	full := NewGrid(64, 64)
+	DepositMassToGridInto(full, particles, mesh)
+	if sum := gridSum(full); math.Abs(sum-total) > 1e-9*total {
+		t.Errorf("Expected total mass %g at stride 1, got %g", total, sum)
+	}
+}
+
+// TestSubsampledDepositSkipsIncrementalRebuild tests that incremental
+// deposits always rebuild from every particle
+func TestSubsampledDepositSkipsIncrementalRebuild(t *testing.T) {
+	particles := []*Particle{
+		NewParticle(1, -5, 0, -5, 0, 0, 0),
+		NewParticle(2, 5, 0, 5, 0, 0, 0),
+		NewParticle(3, 0, 0, 7, 0, 0, 0),
+	}
+	full := NewGrid(32, 32)
+	DepositMassToGridInto(full, particles, nil)
+
+	mesh := &Mesh{}
+	mesh.SetDepositStride(2)
+	grid := NewGrid(32, 32)
+	NewIncrementalDeposit(mesh).Deposit(grid, particles)
+	if d := maxGridDifference(full, grid); d != 0 {
+		t.Errorf("Expected the rebuild to match a full deposit, max difference %g", d)
+	}
+}
+
+// TestSolveCoarse tests the coarse solve against a full one for a smooth distribution
+func TestSolveCoarse(t *testing.T) {
+	rng := rand.New(rand.NewSource(2))
+	particles := make([]*Particle, 2000)
+	total := 0.0
+	for i := range particles {
+		particles[i] = NewParticle(1, rng.NormFloat64()*8, 0, rng.NormFloat64()*8, 0, 0, 0)
+		total += float64(particles[i].Mass)
+	}
+
+	fullMass, fullPotential := NewGrid(64, 64), NewGrid(64, 64)
+	DepositMassToGridInto(fullMass, particles, nil)
+	SolvePoissonFFTInto(fullPotential, fullMass, 1, 1)
+
+	mass, potential := NewGrid(64, 64), NewGrid(64, 64)
+	SolveCoarseInto(potential, mass, particles, 2, nil, 1)
+
+	if sum := gridSum(mass); math.Abs(sum-total) > 0.01*total {
+		t.Errorf("Expected coarse density to hold mass %g, got %g", total, sum)
+	}
+	depth := -minGrid(fullPotential)
+	if d := maxGridDifference(fullPotential, potential); d > 0.05*depth {
+		t.Errorf("Coarse potential differs by %g from the full one (well depth %g)", d, depth)
+	}
+
+	// A factor that does not divide the grid solves at full resolution
+	SolveCoarseInto(potential, mass, particles, 3, nil, 1)
+	if d := maxGridDifference(fullPotential, potential); d > 1e-9*depth {
+		t.Errorf("Expected the full solve for factor 3, difference %g", d)
+	}
+}
+
+// gridSum returns the sum of all cells
+func gridSum(grid Grid) float64 {
+	sum := 0.0
+	for i := range grid {
+		for _, v := range grid[i] {
+			sum += v
+		}
+	}
+	return sum
+}
+
+// minGrid returns the smallest cell value
+func minGrid(grid Grid) float64 {
+	m := math.Inf(1)
+	for i := range grid {
+		for _, v := range grid[i] {
+			m = math.Min(m, v)
+		}
+	}
+	return m
+}
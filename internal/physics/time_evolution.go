@@ -1,6 +1,6 @@
 package physics
 
-// LeapfrogStep performs one step of leapfrog integration
+// LeapfrogStep performs one step of leapfrog integration on the field's cells
 // This is a second-order symplectic integrator that conserves energy well
 func LeapfrogStep(particles []*Particle, forceField *ForceField, dt float32, width, height int) {
 	// Leapfrog integration:
@@ -14,22 +14,32 @@ func LeapfrogStep(particles []*Particle, forceField *ForceField, dt float32, wid
 	UpdateVelocities(particles, forceField, dt*0.5, forceCorrectionFactor)
 
 	// 2. Drift - update positions by full step
-	UpdatePositions(particles, dt, width, height)
+	UpdatePositions(particles, dt, width, height, forceField.Dx())
 
 	// 3. Kick - update velocities by half step (assumes constant field for this test)
 	UpdateVelocities(particles, forceField, dt*0.5, forceCorrectionFactor)
 }
 
-// RunTimeEvolution performs a complete time evolution step including force calculation
-func RunTimeEvolution(particles []*Particle, dt float32, width, height int, gravitationalConstant float64) *ForceField {
+// RunTimeEvolution performs a complete time evolution step including force
+// calculation on the given mesh. Intermediate grids come from a pool; the
+// returned force field may be handed back with Release once the caller no
+// longer needs it
+func RunTimeEvolution(particles []*Particle, dt float32, width, height int, mesh *Mesh, gravitationalConstant float64) *ForceField {
+	dx := mesh.Dx()
+	massGrid := acquireGrid(width, height)
+	defer releaseGrid(massGrid)
+	potentialGrid := acquireGrid(width, height)
+	defer releaseGrid(potentialGrid)
+	forceField := AcquireForceField(width, height, dx)
+
 	// 1. Deposit mass onto grid
-	massGrid := DepositMassToGrid(particles, width, height)
+	DepositMassToGridInto(massGrid.rows, particles, mesh)
 
 	// 2. Solve Poisson equation for potential
-	potentialGrid := SolvePoissonFFT(massGrid, width, height, gravitationalConstant)
+	SolvePoissonFFTInto(potentialGrid.rows, massGrid.rows, dx, gravitationalConstant)
 
 	// 3. Calculate force field from potential
-	forceField := CalculateGradient(potentialGrid, width, height)
+	CalculateGradientInto(forceField, potentialGrid.rows)
 
 	// 4. Update particle velocities and positions
 	forceCorrectionFactor := float32(0.5)
@@ -38,12 +48,12 @@ func RunTimeEvolution(particles []*Particle, dt float32, width, height int, grav
 	UpdateVelocities(particles, forceField, dt*0.5, forceCorrectionFactor)
 
 	// Drift (full step)
-	UpdatePositions(particles, dt, width, height)
+	UpdatePositions(particles, dt, width, height, dx)
 
-	// Recalculate forces for second kick
-	massGrid = DepositMassToGrid(particles, width, height)
-	potentialGrid = SolvePoissonFFT(massGrid, width, height, gravitationalConstant)
-	forceField = CalculateGradient(potentialGrid, width, height)
+	// Recalculate forces for second kick, reusing the same grids
+	DepositMassToGridInto(massGrid.rows, particles, mesh)
+	SolvePoissonFFTInto(potentialGrid.rows, massGrid.rows, dx, gravitationalConstant)
+	CalculateGradientInto(forceField, potentialGrid.rows)
 
 	// Kick (half step)
 	UpdateVelocities(particles, forceField, dt*0.5, forceCorrectionFactor)
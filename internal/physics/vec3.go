@@ -2,8 +2,6 @@ package physics
 
 import (
 	"math"
-
-	rl "github.com/gen2brain/raylib-go/raylib"
 )
 
 // Vec3 represents a 3D vector with float64 precision
@@ -70,26 +68,3 @@ func (v Vec3) Cross(other Vec3) Vec3 {
 		Z: v.X*other.Y - v.Y*other.X,
 	}
 }
-
-// ToRaylib converts Vec3 to raylib's Vector3
-func (v Vec3) ToRaylib() rl.Vector3 {
-	return rl.Vector3{
-		X: float32(v.X),
-		Y: float32(v.Y),
-		Z: float32(v.Z),
-	}
-}
-
-// Vec3FromRaylib converts raylib's Vector3 to Vec3
-func Vec3FromRaylib(v rl.Vector3) Vec3 {
-	return Vec3{
-		X: float64(v.X),
-		Y: float64(v.Y),
-		Z: float64(v.Z),
-	}
-}
-
-// NewRaylibVector3 is a helper to create a raylib Vector3 (for testing)
-func NewRaylibVector3(x, y, z float32) rl.Vector3 {
-	return rl.Vector3{X: x, Y: y, Z: z}
-}
@@ -115,27 +115,3 @@ func TestVec3Cross(t *testing.T) {
 		t.Errorf("Expected (0,0,1), got (%f,%f,%f)", cross.X, cross.Y, cross.Z)
 	}
 }
-
-// TestVec3ToRaylib tests conversion to raylib Vector3
-func TestVec3ToRaylib(t *testing.T) {
-	v := NewVec3(1.5, 2.5, 3.5)
-
-	rlVec := v.ToRaylib()
-
-	if rlVec.X != 1.5 || rlVec.Y != 2.5 || rlVec.Z != 3.5 {
-		t.Errorf("Expected raylib Vector3(1.5,2.5,3.5), got (%f,%f,%f)",
-			rlVec.X, rlVec.Y, rlVec.Z)
-	}
-}
-
-// TestVec3FromRaylib tests conversion from raylib Vector3
-func TestVec3FromRaylib(t *testing.T) {
-	rlVec := NewRaylibVector3(1.5, 2.5, 3.5)
-
-	v := Vec3FromRaylib(rlVec)
-
-	if v.X != 1.5 || v.Y != 2.5 || v.Z != 3.5 {
-		t.Errorf("Expected Vec3(1.5,2.5,3.5), got (%f,%f,%f)",
-			v.X, v.Y, v.Z)
-	}
-}
@@ -0,0 +1,223 @@
+package plot
+
+import (
+	"image/color"
+	"math"
+	"strconv"
+)
+
+// Series is a fixed-capacity time series; once full, the oldest samples are dropped
+type Series struct {
+	Name   string
+	Color  color.RGBA
+	times  []float64
+	values []float64
+	start  int // Index of the oldest sample
+	count  int
+}
+
+// NewSeries creates a series holding up to capacity samples
+func NewSeries(name string, c color.RGBA, capacity int) *Series {
+	if capacity < 1 {
+		capacity = 1
+	}
+	return &Series{
+		Name:   name,
+		Color:  c,
+		times:  make([]float64, capacity),
+		values: make([]float64, capacity),
+	}
+}
+
+// Add appends a sample, dropping the oldest one if the series is full
+func (s *Series) Add(t, v float64) {
+	idx := (s.start + s.count) % len(s.times)
+	s.times[idx], s.values[idx] = t, v
+	if s.count < len(s.times) {
+		s.count++
+	} else {
+		s.start = (s.start + 1) % len(s.times)
+	}
+}
+
+// Len returns the number of stored samples
+func (s *Series) Len() int {
+	return s.count
+}
+
+// At returns sample i, oldest first
+func (s *Series) At(i int) (t, v float64) {
+	idx := (s.start + i) % len(s.times)
+	return s.times[idx], s.values[idx]
+}
+
+// Last returns the newest sample value (0 if empty)
+func (s *Series) Last() float64 {
+	if s.count == 0 {
+		return 0
+	}
+	_, v := s.At(s.count - 1)
+	return v
+}
+
+// Chart is a set of series sharing axes, sampled at most once per Interval
+type Chart struct {
+	Title    string
+	XLabel   string // Label of the horizontal axis ("" = t, the sample time)
+	Series   []*Series
+	Interval float64 // Minimum wall-clock seconds between samples
+
+	lastSample float64
+	sampled    bool
+}
+
+// NewChart creates a chart sampled at most once per interval seconds
+func NewChart(title string, interval float64, series ...*Series) *Chart {
+	return &Chart{Title: title, Series: series, Interval: interval}
+}
+
+// Due reports whether a new sample should be taken at wall-clock time now
+func (c *Chart) Due(now float64) bool {
+	return !c.sampled || now-c.lastSample >= c.Interval
+}
+
+// Sample adds values (one per series, in order) at time t if a sample is due
+// at wall-clock time now, and reports whether it did
+func (c *Chart) Sample(now, t float64, values ...float64) bool {
+	if !c.Due(now) {
+		return false
+	}
+	for i, s := range c.Series {
+		if i < len(values) {
+			s.Add(t, values[i])
+		}
+	}
+	c.lastSample, c.sampled = now, true
+	return true
+}
+
+// Bounds returns the time and value ranges covered by all finite samples.
+// Empty or flat ranges are widened so they can be mapped onto the screen
+func (c *Chart) Bounds() (tMin, tMax, vMin, vMax float64) {
+	tMin, vMin = math.Inf(1), math.Inf(1)
+	tMax, vMax = math.Inf(-1), math.Inf(-1)
+	for _, s := range c.Series {
+		for i := 0; i < s.Len(); i++ {
+			t, v := s.At(i)
+			if math.IsNaN(v) || math.IsInf(v, 0) {
+				continue
+			}
+			tMin, tMax = math.Min(tMin, t), math.Max(tMax, t)
+			vMin, vMax = math.Min(vMin, v), math.Max(vMax, v)
+		}
+	}
+	if tMin > tMax {
+		tMin, tMax = 0, 1
+	}
+	if vMin > vMax {
+		vMin, vMax = 0, 1
+	}
+	tMin, tMax = widen(tMin, tMax)
+	vMin, vMax = widen(vMin, vMax)
+	return tMin, tMax, vMin, vMax
+}
+
+// widen expands a zero-width range around its value
+func widen(lo, hi float64) (float64, float64) {
+	if hi > lo {
+		return lo, hi
+	}
+	pad := math.Abs(lo) * 0.1
+	if pad == 0 {
+		pad = 1
+	}
+	return lo - pad, hi + pad
+}
+
+// Rect is a screen rectangle with Y growing downwards
+type Rect struct {
+	X, Y, W, H float64
+}
+
+// Point is a screen position
+type Point struct {
+	X, Y float64
+}
+
+// Tick is an axis tick at a screen coordinate with its label
+type Tick struct {
+	Pos   float64
+	Label string
+}
+
+// Line is the screen polyline for one series
+type Line struct {
+	Series *Series
+	Points []Point
+}
+
+// Layout is a chart mapped onto screen space, ready to draw
+type Layout struct {
+	Plot   Rect // Area inside the axes
+	Lines  []Line
+	XTicks []Tick // Pos is an X coordinate on the bottom axis
+	YTicks []Tick // Pos is a Y coordinate on the left axis
+}
+
+// Layout maps the chart onto area, reserving margin pixels on the left and
+// bottom for tick labels, with about ticks ticks per axis
+func (c *Chart) Layout(area Rect, margin float64, ticks int) Layout {
+	plot := Rect{X: area.X + margin, Y: area.Y, W: area.W - margin, H: area.H - margin}
+	tMin, tMax, vMin, vMax := c.Bounds()
+
+	toX := func(t float64) float64 { return plot.X + (t-tMin)/(tMax-tMin)*plot.W }
+	toY := func(v float64) float64 { return plot.Y + plot.H - (v-vMin)/(vMax-vMin)*plot.H }
+
+	layout := Layout{Plot: plot}
+	for _, s := range c.Series {
+		line := Line{Series: s, Points: make([]Point, 0, s.Len())}
+		for i := 0; i < s.Len(); i++ {
+			t, v := s.At(i)
+			if math.IsNaN(v) || math.IsInf(v, 0) {
+				continue
+			}
+			line.Points = append(line.Points, Point{X: toX(t), Y: toY(v)})
+		}
+		layout.Lines = append(layout.Lines, line)
+	}
+	for _, t := range NiceTicks(tMin, tMax, ticks) {
+		layout.XTicks = append(layout.XTicks, Tick{Pos: toX(t), Label: FormatTick(t)})
+	}
+	for _, v := range NiceTicks(vMin, vMax, ticks) {
+		layout.YTicks = append(layout.YTicks, Tick{Pos: toY(v), Label: FormatTick(v)})
+	}
+	return layout
+}
+
+// NiceTicks returns about n evenly spaced tick values inside [lo, hi] whose
+// spacing is 1, 2 or 5 times a power of ten
+func NiceTicks(lo, hi float64, n int) []float64 {
+	if n < 2 || !(hi > lo) || math.IsInf(hi-lo, 0) {
+		return nil
+	}
+	raw := (hi - lo) / float64(n-1)
+	magnitude := math.Pow(10, math.Floor(math.Log10(raw)))
+	step := magnitude * 10
+	for _, m := range []float64{1, 2, 5} {
+		if m*magnitude >= raw {
+			step = m * magnitude
+			break
+		}
+	}
+
+	var ticks []float64
+	for k := math.Ceil(lo / step); k*step <= hi+step*1e-9; k++ {
+		ticks = append(ticks, k*step) // Multiples of step avoid accumulated rounding
+	}
+	return ticks
+}
+
+// FormatTick formats a tick value compactly
+func FormatTick(v float64) string {
+	return strconv.FormatFloat(v, 'g', 4, 64)
+}
@@ -0,0 +1,114 @@
+package plot
+
+import (
+	"image/color"
+	"math"
+	"testing"
+)
+
+// TestSeriesRingBuffer tests that the oldest samples are dropped when full
+func TestSeriesRingBuffer(t *testing.T) {
+	s := NewSeries("ke", color.RGBA{}, 3)
+	for i := 0; i < 5; i++ {
+		s.Add(float64(i), float64(i*10))
+	}
+
+	if s.Len() != 3 {
+		t.Fatalf("Expected 3 samples, got %d", s.Len())
+	}
+	for i, want := range []float64{20, 30, 40} {
+		if _, v := s.At(i); v != want {
+			t.Errorf("Sample %d = %f, want %f", i, v, want)
+		}
+	}
+	if s.Last() != 40 {
+		t.Errorf("Last = %f, want 40", s.Last())
+	}
+}
+
+// TestChartSampleInterval tests that samples are throttled by wall-clock time
+func TestChartSampleInterval(t *testing.T) {
+	a := NewSeries("a", color.RGBA{}, 10)
+	b := NewSeries("b", color.RGBA{}, 10)
+	c := NewChart("energy", 1.0, a, b)
+
+	if !c.Sample(0, 0, 1, 2) {
+		t.Error("First sample should always be taken")
+	}
+	if c.Sample(0.5, 0.1, 3, 4) {
+		t.Error("Sample before the interval elapsed should be skipped")
+	}
+	if !c.Sample(1.0, 0.2, 5, 6) {
+		t.Error("Sample after the interval should be taken")
+	}
+	if a.Len() != 2 || b.Last() != 6 {
+		t.Errorf("Unexpected series state: a.Len=%d b.Last=%f", a.Len(), b.Last())
+	}
+}
+
+// TestChartBounds tests ranges across series, skipping non-finite values
+func TestChartBounds(t *testing.T) {
+	a := NewSeries("a", color.RGBA{}, 10)
+	b := NewSeries("b", color.RGBA{}, 10)
+	a.Add(1, -5)
+	a.Add(2, math.NaN())
+	b.Add(3, 7)
+	b.Add(4, math.Inf(1))
+
+	tMin, tMax, vMin, vMax := NewChart("", 1, a, b).Bounds()
+	if tMin != 1 || tMax != 3 || vMin != -5 || vMax != 7 {
+		t.Errorf("Bounds = [%f,%f]x[%f,%f], want [1,3]x[-5,7]", tMin, tMax, vMin, vMax)
+	}
+
+	flat := NewSeries("flat", color.RGBA{}, 2)
+	flat.Add(0, 2)
+	tMin, tMax, vMin, vMax = NewChart("", 1, flat).Bounds()
+	if !(tMax > tMin) || !(vMax > vMin) {
+		t.Errorf("Flat ranges should be widened: [%f,%f]x[%f,%f]", tMin, tMax, vMin, vMax)
+	}
+}
+
+// TestLayout tests mapping samples and ticks into the plot area
+func TestLayout(t *testing.T) {
+	s := NewSeries("a", color.RGBA{}, 10)
+	s.Add(0, 0)
+	s.Add(10, 100)
+
+	layout := NewChart("", 1, s).Layout(Rect{X: 0, Y: 0, W: 140, H: 120}, 40, 5)
+	if layout.Plot != (Rect{X: 40, Y: 0, W: 100, H: 80}) {
+		t.Fatalf("Unexpected plot area %+v", layout.Plot)
+	}
+
+	points := layout.Lines[0].Points
+	if points[0] != (Point{X: 40, Y: 80}) || points[1] != (Point{X: 140, Y: 0}) {
+		t.Errorf("Points not mapped to corners: %+v", points)
+	}
+	if len(layout.XTicks) == 0 || len(layout.YTicks) == 0 {
+		t.Fatal("Expected ticks on both axes")
+	}
+	if layout.YTicks[0].Label != "0" || layout.YTicks[0].Pos != 80 {
+		t.Errorf("First Y tick = %+v, want 0 at the bottom", layout.YTicks[0])
+	}
+}
+
+// TestNiceTicks tests 1-2-5 spacing and exact multiples
+func TestNiceTicks(t *testing.T) {
+	ticks := NiceTicks(0, 1, 6)
+	want := []float64{0, 0.2, 0.4, 0.6000000000000001, 0.8, 1}
+	if len(ticks) != len(want) {
+		t.Fatalf("NiceTicks(0,1,6) = %v", ticks)
+	}
+	for i := range want {
+		if math.Abs(ticks[i]-want[i]) > 1e-12 {
+			t.Errorf("Tick %d = %v, want %v", i, ticks[i], want[i])
+		}
+	}
+
+	ticks = NiceTicks(-3.7, 12.2, 5)
+	if len(ticks) < 3 || ticks[0] < -3.7 || ticks[len(ticks)-1] > 12.2 {
+		t.Errorf("Ticks outside range: %v", ticks)
+	}
+	if NiceTicks(1, 1, 5) != nil || NiceTicks(0, 1, 1) != nil {
+		t.Error("Degenerate inputs should give no ticks")
+	}
+}
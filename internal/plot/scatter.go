@@ -0,0 +1,83 @@
+package plot
+
+import "math"
+
+// Scatter is a set of (x, y) points drawn against each other, such as the
+// particles of a phase-space plot
+type Scatter struct {
+	Title  string
+	XLabel string
+	YLabel string
+	X, Y   []float64 // Coordinates of each point; extra values of the longer slice are ignored
+}
+
+// ScatterLayout is a scatter plot mapped onto screen space, ready to draw
+type ScatterLayout struct {
+	Plot   Rect    // Area inside the axes
+	Points []Point // Screen position of each finite point
+	Index  []int   // Index into X and Y of each point
+	XTicks []Tick  // Pos is an X coordinate on the bottom axis
+	YTicks []Tick  // Pos is a Y coordinate on the left axis
+}
+
+// Len returns the number of points
+func (s *Scatter) Len() int {
+	return min(len(s.X), len(s.Y))
+}
+
+// Bounds returns the ranges covered by the finite points, widened like a
+// chart's when empty or flat
+func (s *Scatter) Bounds() (xMin, xMax, yMin, yMax float64) {
+	xMin, yMin = math.Inf(1), math.Inf(1)
+	xMax, yMax = math.Inf(-1), math.Inf(-1)
+	for i := 0; i < s.Len(); i++ {
+		x, y := s.X[i], s.Y[i]
+		if !finite(x) || !finite(y) {
+			continue
+		}
+		xMin, xMax = math.Min(xMin, x), math.Max(xMax, x)
+		yMin, yMax = math.Min(yMin, y), math.Max(yMax, y)
+	}
+	if xMin > xMax {
+		xMin, xMax = 0, 1
+	}
+	if yMin > yMax {
+		yMin, yMax = 0, 1
+	}
+	xMin, xMax = widen(xMin, xMax)
+	yMin, yMax = widen(yMin, yMax)
+	return xMin, xMax, yMin, yMax
+}
+
+// Layout maps the points onto area, reserving margin pixels on the left and
+// bottom for tick labels, with about ticks ticks per axis. Points with a
+// NaN or infinite coordinate are left out
+func (s *Scatter) Layout(area Rect, margin float64, ticks int) ScatterLayout {
+	plot := Rect{X: area.X + margin, Y: area.Y, W: area.W - margin, H: area.H - margin}
+	xMin, xMax, yMin, yMax := s.Bounds()
+
+	toX := func(x float64) float64 { return plot.X + (x-xMin)/(xMax-xMin)*plot.W }
+	toY := func(y float64) float64 { return plot.Y + plot.H - (y-yMin)/(yMax-yMin)*plot.H }
+
+	layout := ScatterLayout{Plot: plot}
+	for i := 0; i < s.Len(); i++ {
+		x, y := s.X[i], s.Y[i]
+		if !finite(x) || !finite(y) {
+			continue
+		}
+		layout.Points = append(layout.Points, Point{X: toX(x), Y: toY(y)})
+		layout.Index = append(layout.Index, i)
+	}
+	for _, x := range NiceTicks(xMin, xMax, ticks) {
+		layout.XTicks = append(layout.XTicks, Tick{Pos: toX(x), Label: FormatTick(x)})
+	}
+	for _, y := range NiceTicks(yMin, yMax, ticks) {
+		layout.YTicks = append(layout.YTicks, Tick{Pos: toY(y), Label: FormatTick(y)})
+	}
+	return layout
+}
+
+// finite reports whether v is neither NaN nor infinite
+func finite(v float64) bool {
+	return !math.IsNaN(v) && !math.IsInf(v, 0)
+}
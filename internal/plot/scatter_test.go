@@ -0,0 +1,44 @@
+package plot
+
+import (
+	"math"
+	"testing"
+)
+
+// TestScatterBounds tests ranges over finite points of unequal slices
+func TestScatterBounds(t *testing.T) {
+	s := &Scatter{
+		X: []float64{-2, 4, math.NaN(), 1, 100},
+		Y: []float64{3, -1, 50, math.Inf(1)},
+	}
+	if s.Len() != 4 {
+		t.Fatalf("Expected 4 points, got %d", s.Len())
+	}
+	xMin, xMax, yMin, yMax := s.Bounds()
+	if xMin != -2 || xMax != 4 || yMin != -1 || yMax != 3 {
+		t.Errorf("Bounds = [%f,%f]x[%f,%f], want [-2,4]x[-1,3]", xMin, xMax, yMin, yMax)
+	}
+
+	xMin, xMax, yMin, yMax = (&Scatter{}).Bounds()
+	if !(xMax > xMin) || !(yMax > yMin) {
+		t.Errorf("Empty ranges should be widened: [%f,%f]x[%f,%f]", xMin, xMax, yMin, yMax)
+	}
+}
+
+// TestScatterLayout tests mapping points into the plot area, keeping their indices
+func TestScatterLayout(t *testing.T) {
+	s := &Scatter{X: []float64{0, math.NaN(), 10}, Y: []float64{0, 1, 100}}
+	layout := s.Layout(Rect{X: 0, Y: 0, W: 140, H: 120}, 40, 5)
+	if layout.Plot != (Rect{X: 40, Y: 0, W: 100, H: 80}) {
+		t.Fatalf("Unexpected plot area %+v", layout.Plot)
+	}
+	if len(layout.Points) != 2 || layout.Index[0] != 0 || layout.Index[1] != 2 {
+		t.Fatalf("Expected points 0 and 2, got %v", layout.Index)
+	}
+	if layout.Points[0] != (Point{X: 40, Y: 80}) || layout.Points[1] != (Point{X: 140, Y: 0}) {
+		t.Errorf("Unexpected points %+v", layout.Points)
+	}
+	if len(layout.XTicks) == 0 || len(layout.YTicks) == 0 {
+		t.Error("Expected ticks on both axes")
+	}
+}
@@ -0,0 +1,79 @@
+package provenance
+
+import (
+	"bytes"
+	"encoding/binary"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"hash/crc32"
+	"image"
+	"image/png"
+	"io"
+)
+
+// PNGKeyword is the keyword of the tEXt chunk holding the metadata in PNG images
+const PNGKeyword = "Provenance"
+
+// pngHeaderSize is the length of the PNG signature and the IHDR chunk, which
+// must come first
+const pngHeaderSize = 8 + 4 + 4 + 13 + 4
+
+// EncodePNG writes img to w as PNG with m in a tEXt chunk, which image
+// viewers ignore and tools such as exiftool list
+func EncodePNG(w io.Writer, img image.Image, m Metadata) error {
+	var buf bytes.Buffer
+	if err := png.Encode(&buf, img); err != nil {
+		return err
+	}
+	text, err := json.Marshal(m)
+	if err != nil {
+		return fmt.Errorf("failed to encode metadata: %v", err)
+	}
+	data := buf.Bytes()
+	if _, err := w.Write(data[:pngHeaderSize]); err != nil {
+		return err
+	}
+	if err := writePNGChunk(w, "tEXt", append(append([]byte(PNGKeyword), 0), text...)); err != nil {
+		return err
+	}
+	_, err = w.Write(data[pngHeaderSize:])
+	return err
+}
+
+// writePNGChunk writes one PNG chunk with its CRC
+func writePNGChunk(w io.Writer, kind string, payload []byte) error {
+	chunk := make([]byte, 0, 12+len(payload))
+	chunk = binary.BigEndian.AppendUint32(chunk, uint32(len(payload)))
+	chunk = append(chunk, kind...)
+	chunk = append(chunk, payload...)
+	chunk = binary.BigEndian.AppendUint32(chunk, crc32.ChecksumIEEE(chunk[4:]))
+	_, err := w.Write(chunk)
+	return err
+}
+
+// ReadPNG returns the metadata EncodePNG stored in the PNG image data
+func ReadPNG(data []byte) (Metadata, error) {
+	var m Metadata
+	if len(data) < pngHeaderSize || !bytes.HasPrefix(data, []byte("\x89PNG\r\n\x1a\n")) {
+		return m, errors.New("not a PNG image")
+	}
+	for rest := data[8:]; len(rest) >= 12; {
+		length := int(binary.BigEndian.Uint32(rest))
+		if length > len(rest)-12 {
+			break
+		}
+		kind, payload := string(rest[4:8]), rest[8:8+length]
+		if kind == "IEND" {
+			break
+		}
+		if keyword, text, ok := bytes.Cut(payload, []byte{0}); kind == "tEXt" && ok && string(keyword) == PNGKeyword {
+			if err := json.Unmarshal(text, &m); err != nil {
+				return m, fmt.Errorf("invalid metadata: %v", err)
+			}
+			return m, nil
+		}
+		rest = rest[12+length:]
+	}
+	return m, errors.New("no metadata in PNG image")
+}
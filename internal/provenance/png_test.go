@@ -0,0 +1,44 @@
+package provenance
+
+import (
+	"bytes"
+	"image"
+	"image/color"
+	"image/png"
+	"relativity_simulation_2d/internal/config"
+	"testing"
+)
+
+// TestPNG tests that the metadata reads back from an encoded image, which
+// still decodes as the same picture
+func TestPNG(t *testing.T) {
+	img := image.NewRGBA(image.Rect(0, 0, 4, 3))
+	img.Set(1, 2, color.RGBA{R: 200, G: 10, B: 30, A: 255})
+	m := New(config.DefaultConfig(), BackendCUDA)
+
+	var buf bytes.Buffer
+	if err := EncodePNG(&buf, img, m); err != nil {
+		t.Fatalf("EncodePNG failed: %v", err)
+	}
+	if got, err := ReadPNG(buf.Bytes()); err != nil || got != m {
+		t.Errorf("Expected %+v, got %+v (%v)", m, got, err)
+	}
+
+	decoded, err := png.Decode(bytes.NewReader(buf.Bytes()))
+	if err != nil {
+		t.Fatalf("Image with metadata does not decode: %v", err)
+	}
+	r, g, b, a := decoded.At(1, 2).RGBA()
+	if decoded.Bounds() != img.Bounds() || color.RGBA64Model.Convert(img.At(1, 2)) != (color.RGBA64{uint16(r), uint16(g), uint16(b), uint16(a)}) {
+		t.Errorf("Expected the same picture, got %v with %v at (1, 2)", decoded.Bounds(), decoded.At(1, 2))
+	}
+
+	var plain bytes.Buffer
+	_ = png.Encode(&plain, img)
+	if _, err := ReadPNG(plain.Bytes()); err == nil {
+		t.Error("Expected an error for an image without metadata")
+	}
+	if _, err := ReadPNG([]byte("not an image")); err == nil {
+		t.Error("Expected an error for data that is not PNG")
+	}
+}
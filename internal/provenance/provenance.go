@@ -0,0 +1,134 @@
+package provenance
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"relativity_simulation_2d/internal/config"
+	"runtime"
+	"runtime/debug"
+)
+
+// Backends the simulation computes on
+const (
+	BackendCPU    = "cpu"
+	BackendOpenGL = "opengl"
+	BackendCUDA   = "cuda"
+)
+
+// SidecarSuffix is appended to the name of an output to name its sidecar
+const SidecarSuffix = ".meta.json"
+
+// DirFile is the metadata file of an output written as a directory of files
+const DirFile = "metadata.json"
+
+// Metadata traces an output to the code and parameters that produced it.
+// It holds no timestamps, so repeated runs write identical metadata
+type Metadata struct {
+	Program    string `json:"program"`     // Module path and version of the build
+	Commit     string `json:"commit"`      // VCS revision of the build, suffixed "-dirty" with local changes ("unknown" without VCS information)
+	ConfigHash string `json:"config_hash"` // config.Config.Hash of the run's parameters
+	Seed       int64  `json:"seed"`        // Random seed (0 = unseeded, not repeatable)
+	Solver     string `json:"solver"`      // Force solver
+	Backend    string `json:"backend"`     // One of the Backend* values
+	Precision  string `json:"precision"`   // CPU grid/FFT precision
+	GoVersion  string `json:"go_version"`  // Go toolchain of the build
+	Platform   string `json:"platform"`    // GOOS/GOARCH of the build
+}
+
+// New returns the metadata of this build running c on backend
+func New(c *config.Config, backend string) Metadata {
+	program, commit := buildVersion()
+	m := Metadata{
+		Program:   program,
+		Commit:    commit,
+		Backend:   backend,
+		GoVersion: runtime.Version(),
+		Platform:  runtime.GOOS + "/" + runtime.GOARCH,
+	}
+	if c != nil {
+		m.ConfigHash = c.Hash()
+		m.Seed = c.Seed
+		m.Solver = c.Solver
+		if m.Solver == "" {
+			m.Solver = config.SolverPM
+		}
+		m.Precision = c.Precision
+		if m.Precision == "" {
+			m.Precision = config.PrecisionFloat64
+		}
+	}
+	return m
+}
+
+// buildVersion returns the module and VCS revision the binary was built from
+func buildVersion() (program, commit string) {
+	program, commit = "relativity_simulation_2d", "unknown"
+	info, ok := debug.ReadBuildInfo()
+	if !ok {
+		return program, commit
+	}
+	if info.Main.Path != "" {
+		program = info.Main.Path
+	}
+	if info.Main.Version != "" {
+		program += "@" + info.Main.Version
+	}
+	var modified bool
+	for _, s := range info.Settings {
+		switch s.Key {
+		case "vcs.revision":
+			commit = s.Value
+		case "vcs.modified":
+			modified = s.Value == "true"
+		}
+	}
+	if modified && commit != "unknown" {
+		commit += "-dirty"
+	}
+	return program, commit
+}
+
+// SidecarPath returns the sidecar file of the output at path
+func SidecarPath(path string) string {
+	return path + SidecarSuffix
+}
+
+// Write writes m to path as indented JSON
+func Write(path string, m Metadata) error {
+	data, err := json.MarshalIndent(m, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to encode metadata: %v", err)
+	}
+	if err := os.WriteFile(path, append(data, '\n'), 0o644); err != nil {
+		return fmt.Errorf("failed to write metadata: %v", err)
+	}
+	return nil
+}
+
+// WriteSidecar writes m to the sidecar of the output at path
+func WriteSidecar(path string, m Metadata) error {
+	return Write(SidecarPath(path), m)
+}
+
+// WriteDir writes m to the DirFile of an output directory, creating it if needed
+func WriteDir(dir string, m Metadata) error {
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return fmt.Errorf("failed to create output directory: %v", err)
+	}
+	return Write(filepath.Join(dir, DirFile), m)
+}
+
+// Read reads metadata written by Write
+func Read(path string) (Metadata, error) {
+	var m Metadata
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return m, fmt.Errorf("failed to read metadata: %v", err)
+	}
+	if err := json.Unmarshal(data, &m); err != nil {
+		return m, fmt.Errorf("invalid metadata: %v", err)
+	}
+	return m, nil
+}
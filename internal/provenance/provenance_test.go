@@ -0,0 +1,55 @@
+package provenance
+
+import (
+	"path/filepath"
+	"relativity_simulation_2d/internal/config"
+	"runtime"
+	"testing"
+)
+
+// TestNew tests that the metadata describes the configuration and the build
+func TestNew(t *testing.T) {
+	cfg := config.DefaultConfig()
+	cfg.Seed = 7
+	cfg.Solver, cfg.Precision = "", ""
+	m := New(cfg, BackendOpenGL)
+
+	if m.ConfigHash != cfg.Hash() || m.Seed != 7 || m.Backend != BackendOpenGL {
+		t.Errorf("Expected hash %s, seed 7 and backend %s, got %+v", cfg.Hash(), BackendOpenGL, m)
+	}
+	if m.Solver != config.SolverPM || m.Precision != config.PrecisionFloat64 {
+		t.Errorf("Expected the default solver and precision for unset ones, got %q and %q", m.Solver, m.Precision)
+	}
+	if m.Commit == "" || m.Program == "" || m.GoVersion != runtime.Version() || m.Platform != runtime.GOOS+"/"+runtime.GOARCH {
+		t.Errorf("Expected the build to be described, got %+v", m)
+	}
+	if New(cfg, BackendOpenGL) != m {
+		t.Error("Expected the same run to get the same metadata")
+	}
+}
+
+// TestSidecar tests that sidecars and directory files read back as written
+func TestSidecar(t *testing.T) {
+	dir := t.TempDir()
+	m := New(config.DefaultConfig(), BackendCPU)
+
+	path := filepath.Join(dir, "diagnostics.csv")
+	if err := WriteSidecar(path, m); err != nil {
+		t.Fatalf("WriteSidecar failed: %v", err)
+	}
+	if got, err := Read(path + ".meta.json"); err != nil || got != m {
+		t.Errorf("Expected the sidecar to hold %+v, got %+v (%v)", m, got, err)
+	}
+
+	flow := filepath.Join(dir, "flow")
+	if err := WriteDir(flow, m); err != nil {
+		t.Fatalf("WriteDir failed: %v", err)
+	}
+	if got, err := Read(filepath.Join(flow, DirFile)); err != nil || got != m {
+		t.Errorf("Expected the directory file to hold %+v, got %+v (%v)", m, got, err)
+	}
+
+	if _, err := Read(filepath.Join(dir, "missing.json")); err == nil {
+		t.Error("Expected an error for missing metadata")
+	}
+}
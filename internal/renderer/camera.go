@@ -173,12 +173,18 @@ func (c *Camera) LookAt(target physics.Vec3) {
 
 // IsPointInFrustum checks if a point is within the camera frustum
 func (c *Camera) IsPointInFrustum(point physics.Vec3) bool {
-	// Transform point to camera space
+	return c.IsSphereInFrustum(point, 0)
+}
+
+// IsSphereInFrustum checks if any part of a sphere may be within the camera
+// frustum: its center is no farther than radius outside each plane
+func (c *Camera) IsSphereInFrustum(center physics.Vec3, radius float64) bool {
+	// Transform the center to camera space
 	viewMatrix := c.GetViewMatrix()
-	cameraSpace := viewMatrix.TransformPoint(point)
+	cameraSpace := viewMatrix.TransformPoint(center)
 
 	// Check against near and far planes
-	if cameraSpace.Z > -c.nearPlane || cameraSpace.Z < -c.farPlane {
+	if cameraSpace.Z > -c.nearPlane+radius || cameraSpace.Z < -c.farPlane-radius {
 		return false
 	}
 
@@ -188,18 +194,18 @@ func (c *Camera) IsPointInFrustum(point physics.Vec3) bool {
 		tanHalfFovY := math.Tan(halfFovY)
 		tanHalfFovX := tanHalfFovY * c.aspectRatio
 
-		// Project onto near plane for testing
+		// Distances past the side planes through the eye at depth z
 		z := -cameraSpace.Z
-		maxY := tanHalfFovY * z
-		maxX := tanHalfFovX * z
+		outY := (math.Abs(cameraSpace.Y) - tanHalfFovY*z) / math.Hypot(1, tanHalfFovY)
+		outX := (math.Abs(cameraSpace.X) - tanHalfFovX*z) / math.Hypot(1, tanHalfFovX)
 
-		if math.Abs(cameraSpace.Y) > maxY || math.Abs(cameraSpace.X) > maxX {
+		if outY > radius || outX > radius {
 			return false
 		}
 	} else {
 		// Orthographic frustum check
-		if cameraSpace.X < c.left || cameraSpace.X > c.right ||
-			cameraSpace.Y < c.bottom || cameraSpace.Y > c.top {
+		if cameraSpace.X < c.left-radius || cameraSpace.X > c.right+radius ||
+			cameraSpace.Y < c.bottom-radius || cameraSpace.Y > c.top+radius {
 			return false
 		}
 	}
@@ -207,6 +213,42 @@ func (c *Camera) IsPointInFrustum(point physics.Vec3) bool {
 	return true
 }
 
+// ProjectedRadius returns the radius in pixels of a sphere drawn on a
+// viewport height pixels high, or +Inf when the camera is inside it or it is
+// behind the camera, where its size is unknown
+func (c *Camera) ProjectedRadius(center physics.Vec3, radius float64, height int) float64 {
+	pixelsPerUnit := float64(height) / 2
+	if c.projectionType == ProjectionOrthographic {
+		return radius * pixelsPerUnit / ((c.top - c.bottom) / 2)
+	}
+	z := -c.GetViewMatrix().TransformPoint(center).Z
+	if z <= radius {
+		return math.Inf(1)
+	}
+	return radius * pixelsPerUnit / (z * math.Tan(c.fovY*math.Pi/360.0))
+}
+
+// ScreenPosition returns where a point is drawn on a width × height pixel
+// viewport, from its top-left corner, or false when it is behind the camera.
+// Points outside the view map outside the viewport
+func (c *Camera) ScreenPosition(point physics.Vec3, width, height int) (x, y float64, ok bool) {
+	p := c.GetViewMatrix().TransformPoint(point)
+	var ndcX, ndcY float64
+	if c.projectionType == ProjectionOrthographic {
+		ndcX = (2*p.X - c.right - c.left) / (c.right - c.left)
+		ndcY = (2*p.Y - c.top - c.bottom) / (c.top - c.bottom)
+	} else {
+		z := -p.Z
+		if z <= 0 {
+			return 0, 0, false
+		}
+		tanHalfFovY := math.Tan(c.fovY * math.Pi / 360.0)
+		ndcX = p.X / (z * tanHalfFovY * c.aspectRatio)
+		ndcY = p.Y / (z * tanHalfFovY)
+	}
+	return (ndcX + 1) / 2 * float64(width), (1 - ndcY) / 2 * float64(height), true
+}
+
 // GetViewProjectionMatrix returns the combined view-projection matrix
 func (c *Camera) GetViewProjectionMatrix() physics.Mat4 {
 	view := c.GetViewMatrix()
@@ -213,6 +213,92 @@ func TestFrustum(t *testing.T) {
 	}
 }
 
+// TestSphereInFrustum tests that spheres count as inside while any part of
+// them may reach into the frustum
+func TestSphereInFrustum(t *testing.T) {
+	cam := NewCamera(
+		physics.NewVec3(0, 0, 0),
+		physics.NewVec3(0, 0, -1),
+		physics.NewVec3(0, 1, 0),
+	)
+	cam.SetPerspective(60.0, 1.0, 1.0, 100.0)
+
+	// The side plane at depth 10 is at x = 10 tan 30° ≈ 5.77, and a point
+	// at x = 7 lies (7 - 5.77) cos 30° ≈ 1.07 outside it
+	side := physics.NewVec3(7, 0, -10)
+	if cam.IsSphereInFrustum(side, 1) {
+		t.Error("Sphere of radius 1 past the side plane should be outside")
+	}
+	if !cam.IsSphereInFrustum(side, 1.2) {
+		t.Error("Sphere of radius 1.2 reaching across the side plane should be inside")
+	}
+	if !cam.IsSphereInFrustum(physics.NewVec3(0, 0, -0.5), 1) {
+		t.Error("Sphere reaching past the near plane should be inside")
+	}
+	if cam.IsSphereInFrustum(physics.NewVec3(0, 0, -102), 1) {
+		t.Error("Sphere beyond the far plane should be outside")
+	}
+}
+
+// TestProjectedRadius tests the size of spheres on screen
+func TestProjectedRadius(t *testing.T) {
+	cam := NewCamera(
+		physics.NewVec3(0, 0, 0),
+		physics.NewVec3(0, 0, -1),
+		physics.NewVec3(0, 1, 0),
+	)
+	cam.SetPerspective(60.0, 1.0, 1.0, 100.0)
+
+	// 300 pixels span 10 tan 30° at depth 10
+	want := 300 / (10 * math.Tan(math.Pi/6))
+	if got := cam.ProjectedRadius(physics.NewVec3(3, 0, -10), 1, 600); math.Abs(got-want) > 1e-9 {
+		t.Errorf("Expected %g pixels, got %g", want, got)
+	}
+	if got := cam.ProjectedRadius(physics.NewVec3(0, 0, -0.5), 1, 600); !math.IsInf(got, 1) {
+		t.Errorf("Expected an unknown size around the camera, got %g", got)
+	}
+
+	cam.SetOrthographic(-10, 10, -10, 10, 1, 100)
+	if got := cam.ProjectedRadius(physics.NewVec3(0, 0, -50), 1, 600); got != 30 {
+		t.Errorf("Expected 30 pixels at any depth in orthographic view, got %g", got)
+	}
+}
+
+// TestScreenPosition tests projecting points to pixels
+func TestScreenPosition(t *testing.T) {
+	cam := NewCamera(
+		physics.NewVec3(0, 0, 0),
+		physics.NewVec3(0, 0, -1),
+		physics.NewVec3(0, 1, 0),
+	)
+	cam.SetPerspective(90.0, 2.0, 1.0, 100.0)
+
+	// At depth 10 the view spans x in ±20 and y in ±10
+	tests := []struct {
+		point physics.Vec3
+		x, y  float64
+	}{
+		{physics.NewVec3(0, 0, -10), 400, 200},
+		{physics.NewVec3(-20, 10, -10), 0, 0},
+		{physics.NewVec3(10, -5, -10), 600, 300},
+		{physics.NewVec3(40, 0, -10), 1200, 200}, // Off screen
+	}
+	for _, tt := range tests {
+		x, y, ok := cam.ScreenPosition(tt.point, 800, 400)
+		if !ok || math.Abs(x-tt.x) > 1e-9 || math.Abs(y-tt.y) > 1e-9 {
+			t.Errorf("Expected %v at (%g, %g), got (%g, %g) ok=%v", tt.point, tt.x, tt.y, x, y, ok)
+		}
+	}
+	if _, _, ok := cam.ScreenPosition(physics.NewVec3(0, 0, 5), 800, 400); ok {
+		t.Error("Expected no position behind the camera")
+	}
+
+	cam.SetOrthographic(-10, 10, -5, 5, 1, 100)
+	if x, y, ok := cam.ScreenPosition(physics.NewVec3(5, 5, -50), 800, 400); !ok || x != 600 || y != 0 {
+		t.Errorf("Expected (600, 0) in orthographic view, got (%g, %g) ok=%v", x, y, ok)
+	}
+}
+
 // Helper function to compare matrices
 func matricesEqual(a, b physics.Mat4) bool {
 	tolerance := 1e-10
@@ -0,0 +1,53 @@
+package renderer
+
+// maxSpikeRun is the number of consecutive rejected frame times after which
+// they are taken as a real change of frame rate rather than spikes
+const maxSpikeRun = 3
+
+// DeltaFilter smooths frame times before they reach the physics, so that
+// scheduling hiccups do not perturb the integration. Frame times more than
+// SpikeFactor times the running average are rejected as spikes and replaced by
+// the average; the rest enter an exponential moving average
+type DeltaFilter struct {
+	Smoothing   float64 // Weight of the previous average, 0 to below 1 (0 = no smoothing)
+	SpikeFactor float64 // Rejection threshold relative to the average (0 = no rejection)
+
+	average  float64
+	ready    bool
+	spikeRun int // Consecutive rejected frame times
+}
+
+// NewDeltaFilter creates a filter with the given smoothing and spike factor
+func NewDeltaFilter(smoothing, spikeFactor float64) *DeltaFilter {
+	return &DeltaFilter{Smoothing: smoothing, SpikeFactor: spikeFactor}
+}
+
+// Filter records a frame time and returns the time step to use for it. A run
+// of maxSpikeRun rejected frame times restarts the average from the latest
+// one, so that a lasting drop in frame rate is followed
+func (f *DeltaFilter) Filter(dt float64) float64 {
+	if !f.ready || dt <= 0 {
+		if dt > 0 {
+			f.average, f.ready = dt, true
+		}
+		return dt
+	}
+
+	if f.SpikeFactor > 0 && dt > f.SpikeFactor*f.average {
+		f.spikeRun++
+		if f.spikeRun < maxSpikeRun {
+			return f.average
+		}
+		f.average, f.spikeRun = dt, 0
+		return dt
+	}
+	f.spikeRun = 0
+	f.average = f.Smoothing*f.average + (1-f.Smoothing)*dt
+	return f.average
+}
+
+// Reset restarts the average at dt, forgetting the recorded frame times
+// (0 = start from the next frame time)
+func (f *DeltaFilter) Reset(dt float64) {
+	f.average, f.ready, f.spikeRun = dt, dt > 0, 0
+}
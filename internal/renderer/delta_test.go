@@ -0,0 +1,56 @@
+package renderer
+
+import (
+	"math"
+	"testing"
+)
+
+// TestDeltaFilterSmoothing tests the moving average of frame times
+func TestDeltaFilterSmoothing(t *testing.T) {
+	f := NewDeltaFilter(0.5, 0)
+	if got := f.Filter(0.016); got != 0.016 {
+		t.Errorf("Expected the first frame time to pass through, got %g", got)
+	}
+	if got := f.Filter(0.020); math.Abs(got-0.018) > 1e-12 {
+		t.Errorf("Expected the average 0.018, got %g", got)
+	}
+
+	// Without smoothing frame times pass through
+	f = NewDeltaFilter(0, 0)
+	f.Filter(0.016)
+	if got := f.Filter(0.030); got != 0.030 {
+		t.Errorf("Expected the unsmoothed frame time, got %g", got)
+	}
+}
+
+// TestDeltaFilterSpikes tests that isolated spikes are rejected and a lasting
+// change of frame rate is followed
+func TestDeltaFilterSpikes(t *testing.T) {
+	f := NewDeltaFilter(0.8, 3)
+	for i := 0; i < 10; i++ {
+		f.Filter(0.016)
+	}
+	if got := f.Filter(0.2); math.Abs(got-0.016) > 1e-12 {
+		t.Errorf("Expected the spike to be replaced by the average, got %g", got)
+	}
+	if got := f.Filter(0.016); math.Abs(got-0.016) > 1e-12 {
+		t.Errorf("Expected the average to be untouched by the spike, got %g", got)
+	}
+
+	var got float64
+	for i := 0; i < maxSpikeRun; i++ {
+		got = f.Filter(0.1)
+	}
+	if got != 0.1 {
+		t.Errorf("Expected a run of slow frames to be followed, got %g", got)
+	}
+
+	f.Reset(0)
+	if got := f.Filter(0.5); got != 0.5 {
+		t.Errorf("Expected a reset filter to pass the first frame time, got %g", got)
+	}
+	f.Reset(0.016)
+	if got := f.Filter(0.1); math.Abs(got-0.016) > 1e-12 {
+		t.Errorf("Expected a filter reset to 0.016 to reject 0.1, got %g", got)
+	}
+}
@@ -0,0 +1,286 @@
+package renderer
+
+import (
+	"math"
+	"relativity_simulation_2d/internal/physics"
+)
+
+// ContourSegment is a piece of a contour line crossing one grid cell, with
+// ends in node coordinates: (i, j) is node (i, j) and fractions lie between
+type ContourSegment struct {
+	Level          float64
+	I1, J1, I2, J2 float64
+}
+
+// ContourLevels returns n levels evenly spaced strictly between the lowest
+// and highest finite values of grid (nil if the grid is flat or n < 1)
+func ContourLevels(grid physics.Grid, n int) []float64 {
+	lo, hi := math.Inf(1), math.Inf(-1)
+	for _, column := range grid {
+		for _, v := range column {
+			if !math.IsNaN(v) && !math.IsInf(v, 0) {
+				lo, hi = math.Min(lo, v), math.Max(hi, v)
+			}
+		}
+	}
+	if n < 1 || !(hi > lo) {
+		return nil
+	}
+	levels := make([]float64, n)
+	for k := range levels {
+		levels[k] = lo + float64(k+1)*(hi-lo)/float64(n+1)
+	}
+	return levels
+}
+
+// ContourSegments appends the contour lines of grid at the given levels to
+// dst by marching squares. Saddle cells are split by the value at the cell
+// center, so the lines never cross
+func ContourSegments(dst []ContourSegment, grid physics.Grid, levels []float64) []ContourSegment {
+	width, height := grid.Width(), grid.Height()
+	for i := 0; i < width-1; i++ {
+		for j := 0; j < height-1; j++ {
+			// Corners counterclockwise from (i, j) and the edges that follow them
+			v := [4]float64{grid[i][j], grid[i+1][j], grid[i+1][j+1], grid[i][j+1]}
+			ci := [4]float64{0, 1, 1, 0}
+			cj := [4]float64{0, 0, 1, 1}
+			for _, level := range levels {
+				var crossings [4]bool
+				var points [4][2]float64
+				count := 0
+				for e := 0; e < 4; e++ {
+					a, b := v[e], v[(e+1)%4]
+					if (a < level) == (b < level) {
+						continue
+					}
+					t := (level - a) / (b - a)
+					crossings[e] = true
+					points[e] = [2]float64{
+						float64(i) + ci[e] + t*(ci[(e+1)%4]-ci[e]),
+						float64(j) + cj[e] + t*(cj[(e+1)%4]-cj[e]),
+					}
+					count++
+				}
+				segment := func(e1, e2 int) {
+					dst = append(dst, ContourSegment{Level: level,
+						I1: points[e1][0], J1: points[e1][1], I2: points[e2][0], J2: points[e2][1]})
+				}
+				switch count {
+				case 2:
+					var ends []int
+					for e, crossed := range crossings {
+						if crossed {
+							ends = append(ends, e)
+						}
+					}
+					segment(ends[0], ends[1])
+				case 4:
+					// Cut off the two corners on the other side from the center
+					center := (v[0] + v[1] + v[2] + v[3]) / 4
+					if (center < level) == (v[0] < level) {
+						segment(0, 1) // Corner 1
+						segment(2, 3) // Corner 3
+					} else {
+						segment(3, 0) // Corner 0
+						segment(1, 2) // Corner 2
+					}
+				}
+			}
+		}
+	}
+	return dst
+}
+
+// Halo is a density peak of the mass grid
+type Halo struct {
+	I, J   int     // Peak cell
+	Mass   float64 // Mass of the peak cell and its 8 neighbors
+	Radius float64 // Radius in cells of a disc at the threshold density holding Mass
+}
+
+// FindHalos returns the cells of the periodic mass grid that hold more than
+// threshold times the mean cell mass and at least as much as each of their 8
+// neighbors. Of equal neighbors only the first in row order is kept
+func FindHalos(mass physics.Grid, threshold float64) []Halo {
+	width, height := mass.Width(), mass.Height()
+	if width == 0 || height == 0 {
+		return nil
+	}
+	mean := mass.Sum() / float64(width*height)
+	if !(mean > 0) {
+		return nil
+	}
+	cutoff := threshold * mean
+
+	var halos []Halo
+	for i := 0; i < width; i++ {
+		for j := 0; j < height; j++ {
+			peak := mass[i][j]
+			if !(peak > cutoff) {
+				continue
+			}
+			total, isPeak := 0.0, true
+			for di := -1; di <= 1 && isPeak; di++ {
+				for dj := -1; dj <= 1; dj++ {
+					ni, nj := (i+di+width)%width, (j+dj+height)%height
+					neighbor := mass[ni][nj]
+					total += neighbor
+					if ni == i && nj == j {
+						continue
+					}
+					// Ties go to the neighbor first in row order
+					earlier := ni < i || (ni == i && nj < j)
+					if neighbor > peak || (neighbor == peak && earlier) {
+						isPeak = false
+						break
+					}
+				}
+			}
+			if isPeak {
+				radius := math.Max(1, math.Sqrt(total/(math.Pi*cutoff)))
+				halos = append(halos, Halo{I: i, J: j, Mass: total, Radius: radius})
+			}
+		}
+	}
+	return halos
+}
+
+// FieldArrow is an arrow of a vector field drawn from node (I, J)
+type FieldArrow struct {
+	I, J   int
+	DI, DJ float64 // Arrow in cells
+}
+
+// FieldArrows returns an arrow at every stride-th node of the field (fx,
+// fz). The longest arrow is 0.9·stride cells long and the others are scaled
+// with it; non-finite and zero vectors get no arrow
+func FieldArrows(fx, fz physics.Grid, stride int) []FieldArrow {
+	if stride < 1 {
+		stride = 1
+	}
+	width, height := fx.Width(), fx.Height()
+	var arrows []FieldArrow
+	longest := 0.0
+	for i := 0; i < width; i += stride {
+		for j := 0; j < height; j += stride {
+			x, z := fx[i][j], fz[i][j]
+			length := math.Hypot(x, z)
+			if math.IsNaN(length) || math.IsInf(length, 0) || length == 0 {
+				continue
+			}
+			arrows = append(arrows, FieldArrow{I: i, J: j, DI: x, DJ: z})
+			longest = math.Max(longest, length)
+		}
+	}
+	scale := 0.9 * float64(stride) / longest
+	for k := range arrows {
+		arrows[k].DI *= scale
+		arrows[k].DJ *= scale
+	}
+	return arrows
+}
+
+// Trails keeps the recent positions of the first particles. A particle that
+// wraps across the periodic domain starts a new trail, so no trail jumps
+// across the view
+type Trails struct {
+	length        int
+	width, height float64          // Physical extent of the domain
+	points        [][]physics.Vec3 // Ring buffer per particle
+	counts        []int            // Positions held per particle
+	next          int              // Ring index of the next sample
+	step          int64            // Step of the last sample (-1 = none)
+}
+
+// NewTrails keeps length positions of each of the first particles in a
+// domain of physical extent width×height
+func NewTrails(particles, length int, width, height float64) *Trails {
+	t := &Trails{length: max(length, 2), width: width, height: height, step: -1}
+	t.points = make([][]physics.Vec3, max(particles, 0))
+	for i := range t.points {
+		t.points[i] = make([]physics.Vec3, t.length)
+	}
+	t.counts = make([]int, len(t.points))
+	return t
+}
+
+// Reset empties every trail
+func (t *Trails) Reset() {
+	clear(t.counts)
+	t.next = 0
+	t.step = -1
+}
+
+// Record samples the particles at step. A step already sampled is skipped,
+// and an earlier step (a restart or a replay going back) empties the trails
+func (t *Trails) Record(step int64, particles []physics.Particle) {
+	if step == t.step {
+		return
+	}
+	if step < t.step {
+		t.Reset()
+	}
+	previous := (t.next - 1 + t.length) % t.length
+	for i := range t.points {
+		if i >= len(particles) {
+			t.counts[i] = 0
+			continue
+		}
+		p := particles[i].Position
+		if t.counts[i] > 0 {
+			last := t.points[i][previous]
+			if math.Abs(p.X-last.X) > t.width/2 || math.Abs(p.Z-last.Z) > t.height/2 {
+				t.counts[i] = 0
+			}
+		}
+		t.points[i][t.next] = p
+		t.counts[i] = min(t.counts[i]+1, t.length)
+	}
+	t.next = (t.next + 1) % t.length
+	t.step = step
+}
+
+// Len returns the number of particles with trails
+func (t *Trails) Len() int {
+	return len(t.points)
+}
+
+// Trail appends the trail of particle i to dst, oldest first
+func (t *Trails) Trail(dst []physics.Vec3, i int) []physics.Vec3 {
+	count := t.counts[i]
+	for k := count; k > 0; k-- {
+		dst = append(dst, t.points[i][(t.next-k+t.length)%t.length])
+	}
+	return dst
+}
+
+// Minimap maps the simulation plane, seen from above, onto a square of Size
+// pixels, keeping its aspect ratio. +x points right and +z down
+type Minimap struct {
+	Width, Height float64 // Physical extent of the domain
+	Size          float64
+}
+
+// Bounds returns the size in pixels of the mapped domain
+func (m Minimap) Bounds() (w, h float64) {
+	scale := m.scale()
+	return m.Width * scale, m.Height * scale
+}
+
+// Point returns the pixel offset of (x, z) from the minimap's top-left
+// corner, and whether it lies in the domain
+func (m Minimap) Point(x, z float64) (px, py float64, ok bool) {
+	scale := m.scale()
+	px, py = (x+m.Width/2)*scale, (z+m.Height/2)*scale
+	w, h := m.Bounds()
+	return px, py, px >= 0 && px <= w && py >= 0 && py <= h
+}
+
+// scale returns the pixels per unit of length
+func (m Minimap) scale() float64 {
+	extent := math.Max(m.Width, m.Height)
+	if !(extent > 0) {
+		return 0
+	}
+	return m.Size / extent
+}
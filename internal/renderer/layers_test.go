@@ -0,0 +1,165 @@
+package renderer
+
+import (
+	"math"
+	"relativity_simulation_2d/internal/physics"
+	"testing"
+)
+
+// TestContourLevels tests that the levels split the range evenly and a flat
+// grid has none
+func TestContourLevels(t *testing.T) {
+	grid := physics.NewGrid(2, 2)
+	grid[0][0], grid[1][1] = -3, 1
+	grid[0][1] = math.NaN()
+	levels := ContourLevels(grid, 3)
+	want := []float64{-2, -1, 0}
+	if len(levels) != len(want) {
+		t.Fatalf("Expected levels %v, got %v", want, levels)
+	}
+	for k := range want {
+		if math.Abs(levels[k]-want[k]) > 1e-12 {
+			t.Errorf("Expected levels %v, got %v", want, levels)
+		}
+	}
+	if levels := ContourLevels(physics.NewGrid(4, 4), 5); levels != nil {
+		t.Errorf("Expected no levels on a flat grid, got %v", levels)
+	}
+}
+
+// TestContourSegmentsCircle tests that the contour of a cone is a closed
+// ring of segments at the right distance from its tip
+func TestContourSegmentsCircle(t *testing.T) {
+	const n = 33
+	grid := physics.NewGrid(n, n)
+	for i := range grid {
+		for j := range grid[i] {
+			grid[i][j] = math.Hypot(float64(i-n/2), float64(j-n/2))
+		}
+	}
+	segments := ContourSegments(nil, grid, []float64{9.7})
+	if len(segments) < 40 {
+		t.Fatalf("Expected a ring of segments, got %d", len(segments))
+	}
+
+	// Every end lies near the circle and is shared by exactly two segments
+	ends := map[[2]float64]int{}
+	for _, s := range segments {
+		for _, p := range [][2]float64{{s.I1, s.J1}, {s.I2, s.J2}} {
+			if r := math.Hypot(p[0]-n/2, p[1]-n/2); math.Abs(r-9.7) > 0.1 {
+				t.Fatalf("Contour point %v at radius %g, want 9.7", p, r)
+			}
+			key := [2]float64{math.Round(p[0]*1e9) / 1e9, math.Round(p[1]*1e9) / 1e9}
+			ends[key]++
+		}
+	}
+	for p, count := range ends {
+		if count != 2 {
+			t.Fatalf("Contour point %v ends %d segments, want 2 (an open line)", p, count)
+		}
+	}
+}
+
+// TestContourSegmentsSaddle tests that a saddle cell gets two segments cut
+// by its center value
+func TestContourSegmentsSaddle(t *testing.T) {
+	grid := physics.Grid{{1, 0}, {0, 1}} // Corners 0 and 2 high
+	segments := ContourSegments(nil, grid, []float64{0.5})
+	if len(segments) != 2 {
+		t.Fatalf("Expected 2 segments in a saddle, got %d", len(segments))
+	}
+	// The center (0.5) is not below the level, like corner 0, so corners 1 and 3 are cut off
+	if s := segments[0]; s.I1 != 0.5 || s.J1 != 0 || s.I2 != 1 || s.J2 != 0.5 {
+		t.Errorf("Expected the first segment to cut off corner 1, got %+v", s)
+	}
+}
+
+// TestFindHalos tests that peaks above the threshold are found once, across
+// the periodic edge, and that plateaus give a single halo
+func TestFindHalos(t *testing.T) {
+	mass := physics.NewGrid(16, 16)
+	for i := range mass {
+		for j := range mass[i] {
+			mass[i][j] = 1
+		}
+	}
+	mass[3][4] = 100
+	mass[0][0], mass[15][0] = 50, 50 // A plateau across the edge
+	mass[8][8] = 2                   // Below the threshold
+
+	halos := FindHalos(mass, 10)
+	if len(halos) != 2 {
+		t.Fatalf("Expected 2 halos, got %+v", halos)
+	}
+	if h := halos[0]; h.I != 0 || h.J != 0 || h.Mass != 107 {
+		t.Errorf("Expected the plateau halo at (0, 0) with mass 107, got %+v", h)
+	}
+	if h := halos[1]; h.I != 3 || h.J != 4 || h.Mass != 108 || h.Radius <= 1 {
+		t.Errorf("Expected the peak halo at (3, 4) with mass 108, got %+v", h)
+	}
+}
+
+// TestFieldArrows tests that arrows follow the field at every stride-th node
+// and are scaled so the longest spans 0.9 strides
+func TestFieldArrows(t *testing.T) {
+	fx, fz := physics.NewGrid(8, 8), physics.NewGrid(8, 8)
+	fx[0][0], fz[0][0] = 3, 4
+	fx[4][4] = -1
+	fx[1][1] = 100 // Not on the stride
+	fx[4][0] = math.NaN()
+
+	arrows := FieldArrows(fx, fz, 4)
+	if len(arrows) != 2 {
+		t.Fatalf("Expected 2 arrows, got %+v", arrows)
+	}
+	if a := arrows[0]; a.I != 0 || a.J != 0 || math.Abs(math.Hypot(a.DI, a.DJ)-3.6) > 1e-12 {
+		t.Errorf("Expected the longest arrow at (0, 0) to be 3.6 cells, got %+v", a)
+	}
+	if a := arrows[1]; a.I != 4 || a.J != 4 || math.Abs(a.DI+0.72) > 1e-12 || a.DJ != 0 {
+		t.Errorf("Expected a 0.72 cell arrow along -x at (4, 4), got %+v", a)
+	}
+}
+
+// TestTrails tests that trails keep the latest positions oldest first, skip
+// repeated steps, restart on a periodic wrap and empty on a step going back
+func TestTrails(t *testing.T) {
+	trails := NewTrails(1, 3, 10, 10)
+	particles := []physics.Particle{{}}
+	for step := int64(1); step <= 4; step++ {
+		particles[0].Position = physics.NewVec3(float64(step), 0, 0)
+		trails.Record(step, particles)
+		trails.Record(step, particles)
+	}
+	trail := trails.Trail(nil, 0)
+	if len(trail) != 3 || trail[0].X != 2 || trail[2].X != 4 {
+		t.Fatalf("Expected the last 3 positions oldest first, got %v", trail)
+	}
+
+	particles[0].Position = physics.NewVec3(-4.5, 0, 0) // Wrapped across x = 5
+	trails.Record(5, particles)
+	if trail := trails.Trail(nil, 0); len(trail) != 1 || trail[0].X != -4.5 {
+		t.Errorf("Expected a new trail after a wrap, got %v", trail)
+	}
+
+	trails.Record(2, particles)
+	if trail := trails.Trail(nil, 0); len(trail) != 1 {
+		t.Errorf("Expected an emptied trail after going back, got %v", trail)
+	}
+}
+
+// TestMinimap tests the mapping of a rectangular domain into the minimap
+func TestMinimap(t *testing.T) {
+	m := Minimap{Width: 200, Height: 100, Size: 100}
+	if w, h := m.Bounds(); w != 100 || h != 50 {
+		t.Errorf("Expected 100x50 pixels, got %gx%g", w, h)
+	}
+	if px, py, ok := m.Point(0, 0); px != 50 || py != 25 || !ok {
+		t.Errorf("Expected the origin at (50, 25), got (%g, %g, %v)", px, py, ok)
+	}
+	if px, py, ok := m.Point(-100, 50); px != 0 || py != 50 || !ok {
+		t.Errorf("Expected a corner at (0, 50), got (%g, %g, %v)", px, py, ok)
+	}
+	if _, _, ok := m.Point(0, 60); ok {
+		t.Error("Expected a point past the edge to be outside")
+	}
+}
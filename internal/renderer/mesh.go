@@ -0,0 +1,75 @@
+package renderer
+
+import (
+	"relativity_simulation_2d/internal/physics"
+)
+
+// GridLineVertexCount returns the number of vertices GridLineVertices emits
+// for a width×height grid drawn with every stride-th line
+func GridLineVertexCount(width, height, stride int) int {
+	if width < 1 || height < 1 || stride < 1 {
+		return 0
+	}
+	linesX := (width + stride - 1) / stride  // Lines parallel to Z
+	linesZ := (height + stride - 1) / stride // Lines parallel to X
+	return 2 * (linesX*(height-1) + linesZ*(width-1))
+}
+
+// GridLineVertices appends the deformed potential grid to dst as line-list
+// vertex pairs of (x, y, z) float32s. Node (i, j) sits at
+// ((i - width/2)·dx, Φ·visScale, (j - height/2)·dx) for the cell size dx, as
+// in the desktop grid view, and every stride-th line is drawn in each
+// direction
+func GridLineVertices(dst []float32, grid physics.Grid, stride int, dx, visScale float64) []float32 {
+	width, height := grid.Width(), grid.Height()
+	if stride < 1 {
+		stride = 1
+	}
+	halfW, halfH := float32(width)/2, float32(height)/2
+	spacing := float32(dx)
+	vertex := func(i, j int) {
+		dst = append(dst, (float32(i)-halfW)*spacing, float32(grid.AtUnchecked(i, j)*visScale), (float32(j)-halfH)*spacing)
+	}
+
+	// Lines parallel to the Z axis
+	for i := 0; i < width; i += stride {
+		for j := 0; j < height-1; j++ {
+			vertex(i, j)
+			vertex(i, j+1)
+		}
+	}
+	// Lines parallel to the X axis
+	for j := 0; j < height; j += stride {
+		for i := 0; i < width-1; i++ {
+			vertex(i, j)
+			vertex(i+1, j)
+		}
+	}
+	return dst
+}
+
+// DisplayRadius maps a particle's physical radius to the radius it is drawn
+// with: scaled by Scale (0 = 1) and at least Min, so that point particles and
+// light ones stay visible
+type DisplayRadius struct {
+	Scale float64
+	Min   float64
+}
+
+// Radius returns the drawn radius of a particle of the given physical radius
+func (d DisplayRadius) Radius(physical float32) float32 {
+	scale := d.Scale
+	if scale == 0 {
+		scale = 1
+	}
+	return float32(max(float64(physical)*scale, d.Min))
+}
+
+// ParticleVertices appends one (x, y, z, radius) float32 vertex per particle to
+// dst, with the radius as drawn under display
+func ParticleVertices(dst []float32, particles []physics.Particle, display DisplayRadius) []float32 {
+	for _, p := range particles {
+		dst = append(dst, float32(p.Position.X), float32(p.Position.Y), float32(p.Position.Z), display.Radius(p.Radius))
+	}
+	return dst
+}
@@ -0,0 +1,81 @@
+package renderer
+
+import (
+	"relativity_simulation_2d/internal/physics"
+	"testing"
+)
+
+// TestGridLineVertices tests the vertex count, placement and displacement of the grid mesh
+func TestGridLineVertices(t *testing.T) {
+	grid := physics.NewGrid(4, 3)
+	grid[1][2] = -2
+
+	for _, stride := range []int{1, 2, 3} {
+		vertices := GridLineVertices(nil, grid, stride, 1, 0.5)
+		if want := 3 * GridLineVertexCount(4, 3, stride); len(vertices) != want {
+			t.Errorf("stride %d: got %d floats, want %d", stride, len(vertices), want)
+		}
+	}
+
+	vertices := GridLineVertices(nil, grid, 1, 1, 0.5)
+	// The first line runs along Z at i=0, starting at node (0, 0)
+	if vertices[0] != -2 || vertices[1] != 0 || vertices[2] != -1.5 {
+		t.Errorf("First vertex = %v, want (-2, 0, -1.5)", vertices[:3])
+	}
+	found := false
+	for k := 0; k < len(vertices); k += 3 {
+		if vertices[k] == -1 && vertices[k+2] == 0.5 {
+			found = true
+			if vertices[k+1] != -1 {
+				t.Errorf("Node (1, 2) height = %f, want -1", vertices[k+1])
+			}
+		}
+	}
+	if !found {
+		t.Error("Node (1, 2) missing from the mesh")
+	}
+
+	// A single column still has its line along Z
+	if got := len(GridLineVertices(nil, physics.NewGrid(1, 5), 1, 1, 1)); got != 3*GridLineVertexCount(1, 5, 1) || got != 3*8 {
+		t.Errorf("1x5 grid: got %d floats, want %d", got, 3*8)
+	}
+}
+
+// TestParticleVertices tests the per-particle vertex layout
+func TestParticleVertices(t *testing.T) {
+	particles := []physics.Particle{*physics.NewParticle(1, 1, 2, 3, 0, 0, 0), *physics.NewParticle(1, -1, 0, 4, 0, 0, 0)}
+	particles[1].Radius = 0.75
+
+	vertices := ParticleVertices(nil, particles, DisplayRadius{})
+	if len(vertices) != 8 {
+		t.Fatalf("Expected 8 floats, got %d", len(vertices))
+	}
+	if vertices[4] != -1 || vertices[6] != 4 || vertices[7] != 0.75 {
+		t.Errorf("Unexpected second vertex %v", vertices[4:])
+	}
+
+	vertices = ParticleVertices(vertices[:0], particles, DisplayRadius{Scale: 2})
+	if vertices[7] != 1.5 {
+		t.Errorf("Expected the radius scaled to 1.5, got %v", vertices[7])
+	}
+}
+
+// TestDisplayRadius tests scaling and the minimum drawn radius
+func TestDisplayRadius(t *testing.T) {
+	tests := []struct {
+		display  DisplayRadius
+		physical float32
+		want     float32
+	}{
+		{DisplayRadius{}, 0.5, 0.5},
+		{DisplayRadius{Scale: 3}, 0.5, 1.5},
+		{DisplayRadius{Scale: 1, Min: 0.2}, 0.1, 0.2},
+		{DisplayRadius{Min: 0.2}, 0, 0.2},
+		{DisplayRadius{Scale: 0.5, Min: 0.2}, 2, 1},
+	}
+	for _, tt := range tests {
+		if got := tt.display.Radius(tt.physical); got != tt.want {
+			t.Errorf("%+v.Radius(%v) = %v, want %v", tt.display, tt.physical, got, tt.want)
+		}
+	}
+}
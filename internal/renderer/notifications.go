@@ -0,0 +1,140 @@
+package renderer
+
+// NotificationLevel represents the severity of a notification
+type NotificationLevel int
+
+const (
+	// NotificationInfo represents an informational event
+	NotificationInfo NotificationLevel = iota
+	// NotificationWarning represents a recoverable problem (e.g. GPU fallback)
+	NotificationWarning
+	// NotificationError represents a failed operation
+	NotificationError
+)
+
+const (
+	// DefaultNotificationDuration is how long a notification stays on screen in seconds
+	DefaultNotificationDuration = 3.0
+	// DefaultMaxNotifications is the maximum number of notifications shown at once
+	DefaultMaxNotifications = 5
+
+	// notificationFadeTime is the time in seconds over which a notification fades out
+	notificationFadeTime = 0.5
+)
+
+// String returns string representation of NotificationLevel
+func (l NotificationLevel) String() string {
+	switch l {
+	case NotificationInfo:
+		return "Info"
+	case NotificationWarning:
+		return "Warning"
+	case NotificationError:
+		return "Error"
+	default:
+		return "Unknown"
+	}
+}
+
+// Notification represents a transient on-screen message
+type Notification struct {
+	Message   string
+	Level     NotificationLevel
+	Duration  float64 // Total display time in seconds
+	Remaining float64 // Seconds left before the notification expires
+}
+
+// Alpha returns the opacity of the notification, fading out near expiry
+func (n Notification) Alpha() uint8 {
+	if n.Remaining >= notificationFadeTime {
+		return 255
+	}
+	if n.Remaining <= 0 {
+		return 0
+	}
+	return uint8(255 * n.Remaining / notificationFadeTime)
+}
+
+// Notify queues a notification with the default duration
+func (ui *UIRenderer) Notify(level NotificationLevel, message string) {
+	ui.NotifyWithDuration(level, message, ui.notificationDuration)
+}
+
+// NotifyWithDuration queues a notification that stays visible for duration seconds
+func (ui *UIRenderer) NotifyWithDuration(level NotificationLevel, message string, duration float64) {
+	if duration <= 0 {
+		duration = DefaultNotificationDuration
+	}
+
+	ui.notifications = append(ui.notifications, Notification{
+		Message:   message,
+		Level:     level,
+		Duration:  duration,
+		Remaining: duration,
+	})
+
+	// Drop the oldest notifications when the queue is full
+	if len(ui.notifications) > ui.maxNotifications {
+		ui.notifications = ui.notifications[len(ui.notifications)-ui.maxNotifications:]
+	}
+}
+
+// UpdateNotifications advances notification timers and removes expired ones
+func (ui *UIRenderer) UpdateNotifications(dt float64) {
+	active := ui.notifications[:0]
+	for _, n := range ui.notifications {
+		n.Remaining -= dt
+		if n.Remaining > 0 {
+			active = append(active, n)
+		}
+	}
+	ui.notifications = active
+}
+
+// GetNotifications returns the currently visible notifications, oldest first
+func (ui *UIRenderer) GetNotifications() []Notification {
+	result := make([]Notification, len(ui.notifications))
+	copy(result, ui.notifications)
+	return result
+}
+
+// ClearNotifications removes all queued notifications
+func (ui *UIRenderer) ClearNotifications() {
+	ui.notifications = ui.notifications[:0]
+}
+
+// SetNotificationDuration sets the default notification duration in seconds
+func (ui *UIRenderer) SetNotificationDuration(duration float64) {
+	if duration > 0 {
+		ui.notificationDuration = duration
+	}
+}
+
+// SetMaxNotifications sets the maximum number of notifications shown at once
+func (ui *UIRenderer) SetMaxNotifications(max int) {
+	if max <= 0 {
+		return
+	}
+	ui.maxNotifications = max
+	if len(ui.notifications) > max {
+		ui.notifications = ui.notifications[len(ui.notifications)-max:]
+	}
+}
+
+// GetNotificationPosition returns the position for the notification at given index
+func (ui *UIRenderer) GetNotificationPosition(index int) (int, int) {
+	// Notifications stack upwards from the bottom-left corner with 30 pixel spacing
+	return ui.px(10), ui.screenHeight - ui.px(40+index*30)
+}
+
+// GetNotificationColor returns the color for a notification level
+func (ui *UIRenderer) GetNotificationColor(level NotificationLevel) UIColor {
+	switch level {
+	case NotificationWarning:
+		return ui.scheme.Warning
+	case NotificationError:
+		return ui.scheme.Error
+	default:
+		return ui.GetDefaultTextColor()
+	}
+}
@@ -0,0 +1,110 @@
+package renderer
+
+import (
+	"testing"
+)
+
+// TestNotificationQueue tests queuing and expiring notifications
+func TestNotificationQueue(t *testing.T) {
+	ui := NewUIRenderer(800, 600)
+
+	if len(ui.GetNotifications()) != 0 {
+		t.Fatal("Notification queue should start empty")
+	}
+
+	ui.Notify(NotificationWarning, "GPU error, falling back to CPU")
+	ui.NotifyWithDuration(NotificationInfo, "Checkpoint saved", 10.0)
+
+	notifications := ui.GetNotifications()
+	if len(notifications) != 2 {
+		t.Fatalf("Expected 2 notifications, got %d", len(notifications))
+	}
+	if notifications[0].Level != NotificationWarning {
+		t.Errorf("Expected first notification to be a warning, got %v", notifications[0].Level)
+	}
+	if notifications[0].Duration != DefaultNotificationDuration {
+		t.Errorf("Expected default duration %v, got %v", DefaultNotificationDuration, notifications[0].Duration)
+	}
+
+	// Advance past the default duration
+	ui.UpdateNotifications(DefaultNotificationDuration + 0.1)
+
+	notifications = ui.GetNotifications()
+	if len(notifications) != 1 {
+		t.Fatalf("Expected 1 notification after expiry, got %d", len(notifications))
+	}
+	if notifications[0].Message != "Checkpoint saved" {
+		t.Errorf("Wrong notification survived: %s", notifications[0].Message)
+	}
+
+	ui.ClearNotifications()
+	if len(ui.GetNotifications()) != 0 {
+		t.Error("ClearNotifications should empty the queue")
+	}
+}
+
+// TestNotificationQueueLimit tests that the oldest notifications are dropped
+func TestNotificationQueueLimit(t *testing.T) {
+	ui := NewUIRenderer(800, 600)
+	ui.SetMaxNotifications(3)
+
+	messages := []string{"one", "two", "three", "four", "five"}
+	for _, msg := range messages {
+		ui.Notify(NotificationInfo, msg)
+	}
+
+	notifications := ui.GetNotifications()
+	if len(notifications) != 3 {
+		t.Fatalf("Expected 3 notifications, got %d", len(notifications))
+	}
+	if notifications[0].Message != "three" || notifications[2].Message != "five" {
+		t.Errorf("Expected newest notifications to be kept, got %v", notifications)
+	}
+}
+
+// TestNotificationFade tests notification alpha near expiry
+func TestNotificationFade(t *testing.T) {
+	ui := NewUIRenderer(800, 600)
+	ui.NotifyWithDuration(NotificationError, "Error occurred", 1.0)
+
+	if alpha := ui.GetNotifications()[0].Alpha(); alpha != 255 {
+		t.Errorf("Fresh notification should be opaque, got alpha %d", alpha)
+	}
+
+	ui.UpdateNotifications(0.75)
+	alpha := ui.GetNotifications()[0].Alpha()
+	if alpha == 0 || alpha == 255 {
+		t.Errorf("Notification should be fading, got alpha %d", alpha)
+	}
+}
+
+// TestNotificationAppearance tests notification positions and colors
+func TestNotificationAppearance(t *testing.T) {
+	ui := NewUIRenderer(800, 600)
+
+	x, y := ui.GetNotificationPosition(0)
+	if x != 10 || y != 560 {
+		t.Errorf("Notification position incorrect: expected (10,560), got (%d,%d)", x, y)
+	}
+	_, y1 := ui.GetNotificationPosition(1)
+	if y1 >= y {
+		t.Error("Subsequent notifications should stack upwards")
+	}
+
+	color := ui.GetNotificationColor(NotificationError)
+	if color.R != 255 || color.G != 0 || color.B != 0 {
+		t.Error("Error notification color should be red")
+	}
+	color = ui.GetNotificationColor(NotificationWarning)
+	if color.R < 200 || color.G < 200 || color.B != 0 {
+		t.Error("Warning notification color should be yellow")
+	}
+	color = ui.GetNotificationColor(NotificationInfo)
+	if color != ui.GetDefaultTextColor() {
+		t.Error("Info notification color should be the default text color")
+	}
+
+	if NotificationWarning.String() != "Warning" {
+		t.Errorf("Unexpected level string: %s", NotificationWarning.String())
+	}
+}
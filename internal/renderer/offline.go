@@ -0,0 +1,65 @@
+package renderer
+
+import (
+	"image"
+	"image/color"
+)
+
+// Downsample averages each factor×factor block of img into one pixel, the
+// box filter that turns a supersampled render into an antialiased image.
+// Pixels past the last whole block are dropped
+func Downsample(img *image.RGBA, factor int) *image.RGBA {
+	if factor <= 1 {
+		return img
+	}
+	bounds := img.Bounds()
+	out := image.NewRGBA(image.Rect(0, 0, bounds.Dx()/factor, bounds.Dy()/factor))
+	samples := uint32(factor * factor)
+	for y := 0; y < out.Rect.Dy(); y++ {
+		for x := 0; x < out.Rect.Dx(); x++ {
+			var r, g, b, a uint32
+			for dy := 0; dy < factor; dy++ {
+				for dx := 0; dx < factor; dx++ {
+					c := img.RGBAAt(bounds.Min.X+x*factor+dx, bounds.Min.Y+y*factor+dy)
+					r, g, b, a = r+uint32(c.R), g+uint32(c.G), b+uint32(c.B), a+uint32(c.A)
+				}
+			}
+			half := samples / 2 // Rounds to the nearest value
+			out.SetRGBA(x, y, color.RGBA{uint8((r + half) / samples), uint8((g + half) / samples), uint8((b + half) / samples), uint8((a + half) / samples)})
+		}
+	}
+	return out
+}
+
+// Anaglyph combines a stereo pair of the same size into a half-color
+// red-cyan anaglyph: the red channel carries the brightness of the left eye,
+// and green and blue the colors of the right. Taking the brightness rather
+// than the red of the left eye keeps red and blue objects visible to both
+// eyes through the glasses
+func Anaglyph(left, right *image.RGBA) *image.RGBA {
+	bounds := left.Bounds()
+	out := image.NewRGBA(image.Rect(0, 0, bounds.Dx(), bounds.Dy()))
+	for y := 0; y < bounds.Dy(); y++ {
+		for x := 0; x < bounds.Dx(); x++ {
+			l := left.RGBAAt(bounds.Min.X+x, bounds.Min.Y+y)
+			r := right.RGBAAt(right.Rect.Min.X+x, right.Rect.Min.Y+y)
+			luma := (299*uint32(l.R) + 587*uint32(l.G) + 114*uint32(l.B) + 500) / 1000
+			out.SetRGBA(x, y, color.RGBA{uint8(luma), r.G, r.B, max(l.A, r.A)})
+		}
+	}
+	return out
+}
+
+// SideBySide places a stereo pair of the same size next to each other, the
+// left eye on the left, for parallel viewing and 3D displays
+func SideBySide(left, right *image.RGBA) *image.RGBA {
+	width, height := left.Rect.Dx(), left.Rect.Dy()
+	out := image.NewRGBA(image.Rect(0, 0, 2*width, height))
+	for i, eye := range [2]*image.RGBA{left, right} {
+		for y := 0; y < height; y++ {
+			row := eye.Pix[eye.PixOffset(eye.Rect.Min.X, eye.Rect.Min.Y+y):][:4*width]
+			copy(out.Pix[out.PixOffset(i*width, y):], row)
+		}
+	}
+	return out
+}
@@ -0,0 +1,57 @@
+package renderer
+
+import (
+	"image"
+	"image/color"
+	"testing"
+)
+
+// filled returns a width×height image of one color
+func filled(width, height int, c color.RGBA) *image.RGBA {
+	img := image.NewRGBA(image.Rect(0, 0, width, height))
+	for y := 0; y < height; y++ {
+		for x := 0; x < width; x++ {
+			img.SetRGBA(x, y, c)
+		}
+	}
+	return img
+}
+
+// TestDownsample tests averaging each block of a supersampled image
+func TestDownsample(t *testing.T) {
+	img := filled(5, 4, color.RGBA{0, 0, 0, 255})
+	img.SetRGBA(0, 0, color.RGBA{255, 255, 255, 255})
+	img.SetRGBA(1, 1, color.RGBA{255, 0, 0, 255})
+
+	out := Downsample(img, 2)
+	if out.Rect.Dx() != 2 || out.Rect.Dy() != 2 {
+		t.Fatalf("Expected a 2x2 image, got %v", out.Rect)
+	}
+	if c := out.RGBAAt(0, 0); c != (color.RGBA{128, 64, 64, 255}) {
+		t.Errorf("Expected the first block to average to (128, 64, 64), got %v", c)
+	}
+	if c := out.RGBAAt(1, 1); c != (color.RGBA{0, 0, 0, 255}) {
+		t.Errorf("Expected a black block, got %v", c)
+	}
+	if Downsample(img, 1) != img {
+		t.Error("Expected a factor of 1 to return the image itself")
+	}
+}
+
+// TestStereoComposition tests the anaglyph channels and the side-by-side layout
+func TestStereoComposition(t *testing.T) {
+	left := filled(3, 2, color.RGBA{255, 0, 0, 255})
+	right := filled(3, 2, color.RGBA{10, 20, 30, 255})
+
+	if c := Anaglyph(left, right).RGBAAt(2, 1); c != (color.RGBA{76, 20, 30, 255}) {
+		t.Errorf("Expected the left eye's brightness in red and the right eye's green and blue, got %v", c)
+	}
+
+	pair := SideBySide(left, right)
+	if pair.Rect.Dx() != 6 || pair.Rect.Dy() != 2 {
+		t.Fatalf("Expected a 6x2 image, got %v", pair.Rect)
+	}
+	if pair.RGBAAt(2, 1) != left.RGBAAt(0, 0) || pair.RGBAAt(3, 0) != right.RGBAAt(0, 0) {
+		t.Errorf("Expected the left eye on the left and the right eye on the right")
+	}
+}
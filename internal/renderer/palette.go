@@ -0,0 +1,252 @@
+package renderer
+
+import (
+	"fmt"
+	"math"
+)
+
+// Palette selects the colors of the HUD, grid, particles and plots
+type Palette int
+
+const (
+	// PaletteDefault is the original color scheme
+	PaletteDefault Palette = iota
+	// PaletteDeuteranopia replaces red/green pairs with the Okabe-Ito
+	// colors, which stay distinct with red-green color blindness
+	PaletteDeuteranopia
+	// PaletteHighContrast draws bright, saturated colors with a dark
+	// backdrop behind the HUD text
+	PaletteHighContrast
+)
+
+// paletteNames are the names accepted by ParsePalette, indexed by Palette
+var paletteNames = [...]string{
+	PaletteDefault:      "default",
+	PaletteDeuteranopia: "deuteranopia",
+	PaletteHighContrast: "high-contrast",
+}
+
+// ParsePalette returns the palette with the given name ("" = default)
+func ParsePalette(name string) (Palette, error) {
+	if name == "" {
+		return PaletteDefault, nil
+	}
+	for p, n := range paletteNames {
+		if n == name {
+			return Palette(p), nil
+		}
+	}
+	return PaletteDefault, fmt.Errorf("unknown palette: %q (want default, deuteranopia or high-contrast)", name)
+}
+
+// String returns the palette name
+func (p Palette) String() string {
+	if p < 0 || int(p) >= len(paletteNames) {
+		return fmt.Sprintf("Palette(%d)", int(p))
+	}
+	return paletteNames[p]
+}
+
+// ColorScheme is the set of colors a palette assigns
+type ColorScheme struct {
+	Title        UIColor
+	Text         UIColor
+	ModeGPU      UIColor
+	ModeFallback UIColor
+	ModeCPU      UIColor
+	Solver       UIColor
+	Warning      UIColor
+	Error        UIColor
+	Pause        UIColor
+	HUDBackdrop  UIColor // Drawn behind HUD text (A = 0 = none)
+
+	Grid     UIColor // Grid lines on a flat potential
+	GridHot  UIColor // Grid lines at the bottom of the deepest wells
+	Particle UIColor // Particles drawn in a single color
+	// Particles colored by binding: negative or non-negative total energy
+	ParticleBound   UIColor
+	ParticleUnbound UIColor
+	AxisX           UIColor
+	AxisY           UIColor
+	AxisZ           UIColor
+	Marker          UIColor // Scenario masses and markers such as Lagrange points
+
+	// Ends of the particle mass color ramp
+	ParticleLight UIColor
+	ParticleHeavy UIColor
+
+	// Diagnostics plot series: kinetic, potential and total energy, and the virial ratio
+	PlotKinetic   UIColor
+	PlotPotential UIColor
+	PlotTotal     UIColor
+	PlotVirial    UIColor
+
+	// Particle groups, in turn; ungrouped particles keep Particle
+	Groups [6]UIColor
+}
+
+// Okabe-Ito colors, chosen to be distinguishable with the common forms of
+// color blindness
+var (
+	okabeOrange        = UIColor{R: 230, G: 159, B: 0, A: 255}
+	okabeSkyBlue       = UIColor{R: 86, G: 180, B: 233, A: 255}
+	okabeBluishGreen   = UIColor{R: 0, G: 158, B: 115, A: 255}
+	okabeYellow        = UIColor{R: 240, G: 228, B: 66, A: 255}
+	okabeBlue          = UIColor{R: 0, G: 114, B: 178, A: 255}
+	okabeVermillion    = UIColor{R: 213, G: 94, B: 0, A: 255}
+	okabeReddishPurple = UIColor{R: 204, G: 121, B: 167, A: 255}
+)
+
+var (
+	white  = UIColor{R: 255, G: 255, B: 255, A: 255}
+	yellow = UIColor{R: 255, G: 255, B: 0, A: 255}
+)
+
+// Scheme returns the colors of the palette
+func (p Palette) Scheme() ColorScheme {
+	switch p {
+	case PaletteDeuteranopia:
+		return ColorScheme{
+			Title:           okabeSkyBlue,
+			Text:            white,
+			ModeGPU:         okabeSkyBlue,
+			ModeFallback:    okabeYellow,
+			ModeCPU:         okabeVermillion,
+			Solver:          okabeReddishPurple,
+			Warning:         okabeYellow,
+			Error:           okabeVermillion,
+			Pause:           okabeYellow,
+			Grid:            UIColor{R: 40, G: 60, B: 120, A: 255},
+			GridHot:         okabeOrange,
+			Particle:        okabeYellow,
+			ParticleBound:   okabeSkyBlue,
+			ParticleUnbound: okabeVermillion,
+			AxisX:           okabeVermillion,
+			AxisY:           okabeYellow,
+			AxisZ:           okabeBlue,
+			Marker:          okabeBluishGreen,
+			ParticleLight:   okabeBlue,
+			ParticleHeavy:   okabeOrange,
+			PlotKinetic:     okabeOrange,
+			PlotPotential:   okabeSkyBlue,
+			PlotTotal:       white,
+			PlotVirial:      okabeBluishGreen,
+			Groups:          [6]UIColor{okabeSkyBlue, okabeVermillion, okabeBluishGreen, okabeReddishPurple, okabeOrange, okabeBlue},
+		}
+	case PaletteHighContrast:
+		return ColorScheme{
+			Title:           yellow,
+			Text:            white,
+			ModeGPU:         UIColor{R: 0, G: 255, B: 255, A: 255},
+			ModeFallback:    yellow,
+			ModeCPU:         UIColor{R: 255, G: 128, B: 255, A: 255},
+			Solver:          UIColor{R: 0, G: 255, B: 255, A: 255},
+			Warning:         yellow,
+			Error:           UIColor{R: 255, G: 96, B: 96, A: 255},
+			Pause:           yellow,
+			HUDBackdrop:     UIColor{R: 0, G: 0, B: 0, A: 200},
+			Grid:            UIColor{R: 120, G: 120, B: 255, A: 255},
+			GridHot:         yellow,
+			Particle:        white,
+			ParticleBound:   UIColor{R: 0, G: 255, B: 255, A: 255},
+			ParticleUnbound: UIColor{R: 255, G: 128, B: 255, A: 255},
+			AxisX:           UIColor{R: 255, G: 96, B: 96, A: 255},
+			AxisY:           yellow,
+			AxisZ:           UIColor{R: 0, G: 255, B: 255, A: 255},
+			Marker:          UIColor{R: 0, G: 255, B: 0, A: 255},
+			ParticleLight:   UIColor{R: 0, G: 255, B: 255, A: 255},
+			ParticleHeavy:   yellow,
+			PlotKinetic:     yellow,
+			PlotPotential:   UIColor{R: 0, G: 255, B: 255, A: 255},
+			PlotTotal:       white,
+			PlotVirial:      UIColor{R: 255, G: 128, B: 255, A: 255},
+			Groups: [6]UIColor{
+				{R: 0, G: 255, B: 255, A: 255}, {R: 255, G: 128, B: 255, A: 255}, yellow,
+				{R: 0, G: 255, B: 0, A: 255}, {R: 255, G: 96, B: 96, A: 255}, {R: 255, G: 165, B: 0, A: 255},
+			},
+		}
+	default:
+		return ColorScheme{
+			Title:           UIColor{R: 0, G: 255, B: 0, A: 255},
+			Text:            white,
+			ModeGPU:         UIColor{R: 0, G: 255, B: 0, A: 255},
+			ModeFallback:    yellow,
+			ModeCPU:         UIColor{R: 255, G: 165, B: 0, A: 255},
+			Solver:          UIColor{R: 102, G: 191, B: 255, A: 255},
+			Warning:         yellow,
+			Error:           UIColor{R: 255, G: 0, B: 0, A: 255},
+			Pause:           yellow,
+			Grid:            UIColor{R: 50, G: 50, B: 100, A: 255},
+			GridHot:         UIColor{R: 255, G: 140, B: 25, A: 255},
+			Particle:        UIColor{R: 255, G: 203, B: 0, A: 255},
+			ParticleBound:   UIColor{R: 0, G: 121, B: 241, A: 255},
+			ParticleUnbound: UIColor{R: 230, G: 41, B: 55, A: 255},
+			AxisX:           UIColor{R: 230, G: 41, B: 55, A: 255},
+			AxisY:           UIColor{R: 0, G: 228, B: 48, A: 255},
+			AxisZ:           UIColor{R: 0, G: 121, B: 241, A: 255},
+			Marker:          UIColor{R: 200, G: 122, B: 255, A: 255},
+			ParticleLight:   UIColor{R: 0, G: 128, B: 255, A: 255},
+			ParticleHeavy:   UIColor{R: 255, G: 128, B: 0, A: 255},
+			PlotKinetic:     UIColor{R: 255, G: 161, B: 0, A: 255},
+			PlotPotential:   UIColor{R: 102, G: 191, B: 255, A: 255},
+			PlotTotal:       white,
+			PlotVirial:      UIColor{R: 0, G: 158, B: 47, A: 255},
+			Groups: [6]UIColor{
+				{R: 0, G: 121, B: 241, A: 255}, {R: 230, G: 41, B: 55, A: 255}, {R: 0, G: 228, B: 48, A: 255},
+				{R: 200, G: 122, B: 255, A: 255}, {R: 255, G: 109, B: 194, A: 255}, {R: 102, G: 191, B: 255, A: 255},
+			},
+		}
+	}
+}
+
+// Group returns the color of group i, reusing the colors after the last
+func (s ColorScheme) Group(i int) UIColor {
+	return s.Groups[i%len(s.Groups)]
+}
+
+// Ramp returns the color at t along the particle ramp, from ParticleLight at
+// 0 to ParticleHeavy at 1 (t is clamped to [0, 1])
+func (s ColorScheme) Ramp(t float64) UIColor {
+	return LerpColor(s.ParticleLight, s.ParticleHeavy, t)
+}
+
+// LerpColor returns the color at t between a at 0 and b at 1 (t is clamped to [0, 1])
+func LerpColor(a, b UIColor, t float64) UIColor {
+	t = math.Max(0, math.Min(1, t))
+	lerp := func(x, y uint8) uint8 {
+		return uint8(math.Round(float64(x) + (float64(y)-float64(x))*t))
+	}
+	return UIColor{R: lerp(a.R, b.R), G: lerp(a.G, b.G), B: lerp(a.B, b.B), A: lerp(a.A, b.A)}
+}
+
+// LogRamp maps positive values onto [0, 1] by their logarithm between the
+// smallest and largest of a set, so values spanning decades stay distinct
+type LogRamp struct {
+	logMin, logMax float64
+}
+
+// NewLogRamp creates a ramp spanning the positive values (non-positive ones are ignored)
+func NewLogRamp(values []float64) LogRamp {
+	r := LogRamp{logMin: math.Inf(1), logMax: math.Inf(-1)}
+	for _, v := range values {
+		if v > 0 {
+			r.logMin = math.Min(r.logMin, math.Log(v))
+			r.logMax = math.Max(r.logMax, math.Log(v))
+		}
+	}
+	return r
+}
+
+// At returns the position of v along the ramp: 0 for the smallest value and
+// non-positive values, 1 for the largest, and 0 if all values are equal
+func (r LogRamp) At(v float64) float64 {
+	if v <= 0 || !(r.logMax > r.logMin) {
+		return 0
+	}
+	return math.Max(0, math.Min(1, (math.Log(v)-r.logMin)/(r.logMax-r.logMin)))
+}
+
+// RGB returns the color as normalized red, green and blue, as used by shaders
+func (c UIColor) RGB() [3]float32 {
+	return [3]float32{float32(c.R) / 255, float32(c.G) / 255, float32(c.B) / 255}
+}
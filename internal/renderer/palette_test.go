@@ -0,0 +1,87 @@
+package renderer
+
+import (
+	"math"
+	"testing"
+)
+
+// TestParsePalette tests palette names round-trip
+func TestParsePalette(t *testing.T) {
+	for _, p := range []Palette{PaletteDefault, PaletteDeuteranopia, PaletteHighContrast} {
+		got, err := ParsePalette(p.String())
+		if err != nil || got != p {
+			t.Errorf("ParsePalette(%q) = %v, %v; want %v", p.String(), got, err, p)
+		}
+	}
+
+	if got, err := ParsePalette(""); err != nil || got != PaletteDefault {
+		t.Errorf("Expected the empty name to select the default palette, got %v, %v", got, err)
+	}
+	if _, err := ParsePalette("sepia"); err == nil {
+		t.Error("Expected an error for an unknown palette")
+	}
+}
+
+// TestDeuteranopiaModeColors tests that the mode colors avoid a red-green pair
+func TestDeuteranopiaModeColors(t *testing.T) {
+	scheme := PaletteDeuteranopia.Scheme()
+	colors := []UIColor{scheme.ModeGPU, scheme.ModeFallback, scheme.ModeCPU}
+	for i := range colors {
+		for j := i + 1; j < len(colors); j++ {
+			if colors[i] == colors[j] {
+				t.Errorf("Mode colors %d and %d are both %+v", i, j, colors[i])
+			}
+		}
+	}
+
+	// The default palette tells GPU and CPU apart by green and orange alone
+	if scheme.ModeGPU == PaletteDefault.Scheme().ModeGPU {
+		t.Error("Expected the deuteranopia palette to replace the green GPU color")
+	}
+}
+
+// TestHighContrastBackdrop tests that only the high-contrast palette draws a HUD backdrop
+func TestHighContrastBackdrop(t *testing.T) {
+	if PaletteHighContrast.Scheme().HUDBackdrop.A == 0 {
+		t.Error("Expected the high-contrast palette to draw a backdrop behind the HUD")
+	}
+	if PaletteDefault.Scheme().HUDBackdrop.A != 0 {
+		t.Error("Expected no backdrop with the default palette")
+	}
+}
+
+// TestUIColorRGB tests conversion to normalized shader colors
+func TestUIColorRGB(t *testing.T) {
+	got := UIColor{R: 255, G: 0, B: 51, A: 255}.RGB()
+	if got != [3]float32{1, 0, 0.2} {
+		t.Errorf("Expected (1, 0, 0.2), got %v", got)
+	}
+}
+
+// TestColorSchemeRamp tests interpolation along the particle ramp
+func TestColorSchemeRamp(t *testing.T) {
+	scheme := PaletteDefault.Scheme()
+	if got := scheme.Ramp(0); got != scheme.ParticleLight {
+		t.Errorf("Expected the light end at 0, got %+v", got)
+	}
+	if got := scheme.Ramp(2); got != scheme.ParticleHeavy {
+		t.Errorf("Expected values past 1 to clamp to the heavy end, got %+v", got)
+	}
+	if got := (ColorScheme{ParticleHeavy: UIColor{R: 200, A: 255}}).Ramp(0.5); got != (UIColor{R: 100, A: 128}) {
+		t.Errorf("Expected the midpoint, got %+v", got)
+	}
+}
+
+// TestLogRamp tests logarithmic placement of values
+func TestLogRamp(t *testing.T) {
+	ramp := NewLogRamp([]float64{1, 10, 100, 0, -5})
+	tests := []struct{ v, want float64 }{{1, 0}, {10, 0.5}, {100, 1}, {1000, 1}, {0, 0}}
+	for _, tt := range tests {
+		if got := ramp.At(tt.v); math.Abs(got-tt.want) > 1e-12 {
+			t.Errorf("At(%g) = %g, want %g", tt.v, got, tt.want)
+		}
+	}
+	if got := NewLogRamp([]float64{3, 3}).At(3); got != 0 {
+		t.Errorf("Expected 0 for equal values, got %g", got)
+	}
+}
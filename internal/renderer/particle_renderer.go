@@ -36,9 +36,17 @@ type ParticleRenderer struct {
 	particleSize   float32
 	renderMode     RenderMode
 	cullingEnabled bool
+	scheme         ColorScheme
+
+	// Culling settings
+	display    DisplayRadius // Drawn radius of the particles, tested against the frustum
+	padding    float64       // Added to the radius, e.g. half the eye separation in stereo
+	minPixels  float64       // Particles drawn smaller are culled (0 = no size culling)
+	viewHeight int           // Viewport height in pixels for the size culling
 
 	// Render state
-	visibleCount int
+	visible      []int // Indices of the visible particles
+	visibleDirty bool  // visible needs recomputing
 	maxBatchSize int
 }
 
@@ -49,9 +57,15 @@ func NewParticleRenderer() *ParticleRenderer {
 		particleSize: 1.0,
 		renderMode:   RenderModePoints,
 		maxBatchSize: 1000,
+		scheme:       PaletteDefault.Scheme(),
 	}
 }
 
+// SetPalette sets the palette of the particle mass color ramp
+func (r *ParticleRenderer) SetPalette(palette Palette) {
+	r.scheme = palette.Scheme()
+}
+
 // Setup initializes the renderer
 func (r *ParticleRenderer) Setup() error {
 	// In a real implementation, this would initialize shaders
@@ -62,7 +76,21 @@ func (r *ParticleRenderer) Setup() error {
 // SetParticles sets the particles to render
 func (r *ParticleRenderer) SetParticles(particles []*physics.Particle) {
 	r.particles = particles
-	r.updateVisibleCount()
+	r.markVisibleDirty()
+}
+
+// SetDisplayRadius sets the drawn radius of the particles that culling
+// tests against the frustum, plus padding
+func (r *ParticleRenderer) SetDisplayRadius(display DisplayRadius, padding float64) {
+	r.display, r.padding = display, padding
+	r.markVisibleDirty()
+}
+
+// SetSizeCulling culls particles whose drawn radius is below minPixels on a
+// viewport height pixels high, as seen from the camera (0 = no size culling)
+func (r *ParticleRenderer) SetSizeCulling(minPixels float64, height int) {
+	r.minPixels, r.viewHeight = minPixels, height
+	r.markVisibleDirty()
 }
 
 // GetParticleCount returns the number of particles
@@ -95,13 +123,14 @@ func (r *ParticleRenderer) GetBatchInfo() BatchInfo {
 
 // GetParticleColor returns the color for a particle based on its properties
 func (r *ParticleRenderer) GetParticleColor(particle *physics.Particle) Color {
-	// Map mass to color - lighter particles are bluish, heavier are reddish
-	massNorm := math.Min(float64(particle.Mass)/100.0, 1.0)
+	// Map mass along the palette's ramp - bluish to reddish by default
+	massNorm := float32(math.Min(float64(particle.Mass)/100.0, 1.0))
+	light, heavy := r.scheme.ParticleLight.RGB(), r.scheme.ParticleHeavy.RGB()
 
 	return Color{
-		R: float32(massNorm),
-		G: float32(0.5),
-		B: float32(1.0 - massNorm),
+		R: light[0] + (heavy[0]-light[0])*massNorm,
+		G: light[1] + (heavy[1]-light[1])*massNorm,
+		B: light[2] + (heavy[2]-light[2])*massNorm,
 		A: 1.0,
 	}
 }
@@ -116,34 +145,53 @@ func (r *ParticleRenderer) GetScaledParticleSize(particle *physics.Particle) flo
 // SetCamera sets the camera for culling
 func (r *ParticleRenderer) SetCamera(camera *Camera) {
 	r.camera = camera
-	r.updateVisibleCount()
+	r.markVisibleDirty()
 }
 
 // EnableCulling enables or disables frustum culling
 func (r *ParticleRenderer) EnableCulling(enable bool) {
 	r.cullingEnabled = enable
-	r.updateVisibleCount()
+	r.markVisibleDirty()
 }
 
 // GetVisibleParticleCount returns the number of visible particles
 func (r *ParticleRenderer) GetVisibleParticleCount() int {
-	return r.visibleCount
+	return len(r.GetVisibleIndices())
 }
 
-// updateVisibleCount updates the count of visible particles
-func (r *ParticleRenderer) updateVisibleCount() {
-	if !r.cullingEnabled || r.camera == nil {
-		r.visibleCount = len(r.particles)
-		return
+// GetVisibleIndices returns the indices of the visible particles in order.
+// The slice is reused until the particles, camera or settings change
+func (r *ParticleRenderer) GetVisibleIndices() []int {
+	if !r.visibleDirty {
+		return r.visible
 	}
-
-	count := 0
-	for _, particle := range r.particles {
-		if r.camera.IsPointInFrustum(particle.Position) {
-			count++
+	r.visible = r.visible[:0]
+	for i, particle := range r.particles {
+		if r.isVisible(particle) {
+			r.visible = append(r.visible, i)
 		}
 	}
-	r.visibleCount = count
+	r.visibleDirty = false
+	return r.visible
+}
+
+// markVisibleDirty marks the visible particles for recomputing on their
+// next use, so that setting the camera and the particles culls once
+func (r *ParticleRenderer) markVisibleDirty() {
+	r.visibleDirty = true
+}
+
+// isVisible reports whether a particle survives culling: its drawn sphere
+// reaches into the frustum and, with size culling, covers enough pixels
+func (r *ParticleRenderer) isVisible(particle *physics.Particle) bool {
+	if !r.cullingEnabled || r.camera == nil {
+		return true
+	}
+	radius := float64(r.display.Radius(particle.Radius))
+	if !r.camera.IsSphereInFrustum(particle.Position, radius+r.padding) {
+		return false
+	}
+	return r.minPixels <= 0 || r.camera.ProjectedRadius(particle.Position, radius, r.viewHeight) >= r.minPixels
 }
 
 // SetRenderMode sets the rendering mode
@@ -194,7 +242,8 @@ func (r *ParticleRenderer) RenderBatch(batchIndex int) error {
 func (r *ParticleRenderer) Cleanup() error {
 	// Clear particles
 	r.particles = r.particles[:0]
-	r.visibleCount = 0
+	r.visible = r.visible[:0]
+	r.visibleDirty = false
 
 	// In a real implementation, this would release GPU resources
 	return nil
@@ -206,16 +255,35 @@ func (r *ParticleRenderer) GetVisibleParticles() []*physics.Particle {
 		return r.particles
 	}
 
-	visible := make([]*physics.Particle, 0, r.visibleCount)
-	for _, particle := range r.particles {
-		if r.camera.IsPointInFrustum(particle.Position) {
-			visible = append(visible, particle)
-		}
+	indices := r.GetVisibleIndices()
+	visible := make([]*physics.Particle, len(indices))
+	for k, i := range indices {
+		visible[k] = r.particles[i]
 	}
 
 	return visible
 }
 
+// GetStats returns the draw statistics of the particles: one call per batch
+// of visible particles, with the vertices of the render mode, and the
+// particles culled
+func (r *ParticleRenderer) GetStats() LayerStats {
+	vertices := 1
+	switch r.renderMode {
+	case RenderModeSpheres:
+		vertices = SphereVertices
+	case RenderModeBillboards:
+		vertices = RectangleVertices
+	}
+	visible := r.GetVisibleParticleCount()
+	return LayerStats{
+		Name:      "particles",
+		DrawCalls: (visible + r.maxBatchSize - 1) / r.maxBatchSize,
+		Vertices:  visible * vertices,
+		Culled:    len(r.particles) - visible,
+	}
+}
+
 // SetMaxBatchSize sets the maximum batch size
 func (r *ParticleRenderer) SetMaxBatchSize(size int) {
 	if size > 0 {
@@ -107,6 +107,18 @@ func TestColorMapping(t *testing.T) {
 	}
 }
 
+// TestColorMappingPalette tests that the mass ramp follows the palette
+func TestColorMappingPalette(t *testing.T) {
+	renderer := NewParticleRenderer()
+	renderer.SetPalette(PaletteDeuteranopia)
+
+	heavy := renderer.GetParticleColor(physics.NewParticle(100.0, 0, 0, 0, 0, 0, 0))
+	want := PaletteDeuteranopia.Scheme().ParticleHeavy.RGB()
+	if heavy.R != want[0] || heavy.G != want[1] || heavy.B != want[2] {
+		t.Errorf("Expected the heavy end of the ramp %v, got %+v", want, heavy)
+	}
+}
+
 // TestParticleSize tests particle size calculation
 func TestParticleSize(t *testing.T) {
 	renderer := NewParticleRenderer()
@@ -162,6 +174,54 @@ func TestCulling(t *testing.T) {
 	if visibleCount != 1 {
 		t.Errorf("Expected 1 visible particle, got %d", visibleCount)
 	}
+
+	// The statistics count the visible particle and the culled ones
+	renderer.SetRenderMode(RenderModeSpheres)
+	if stats := renderer.GetStats(); stats.DrawCalls != 1 || stats.Vertices != SphereVertices || stats.Culled != 3 {
+		t.Errorf("Expected 1 call of %d vertices and 3 culled, got %+v", SphereVertices, stats)
+	}
+}
+
+// TestSizeCulling tests that culling uses the drawn radius and culls
+// particles drawn smaller than the minimum size
+func TestSizeCulling(t *testing.T) {
+	renderer := NewParticleRenderer()
+	camera := NewCamera(
+		physics.NewVec3(0, 0, 0),
+		physics.NewVec3(0, 0, -1),
+		physics.NewVec3(0, 1, 0),
+	)
+	camera.SetPerspective(60.0, 1.0, 1.0, 100.0)
+
+	particles := []*physics.Particle{
+		physics.NewParticle(1.0, 0, 0, -10, 0, 0, 0), // Near
+		physics.NewParticle(1.0, 0, 0, -90, 0, 0, 0), // Far
+		physics.NewParticle(1.0, 7, 0, -10, 0, 0, 0), // Center past the side plane
+	}
+	for _, p := range particles {
+		p.Radius = 1
+	}
+	renderer.SetParticles(particles)
+	renderer.SetCamera(camera)
+	renderer.EnableCulling(true)
+	if visible := renderer.GetVisibleIndices(); len(visible) != 2 || visible[0] != 0 || visible[1] != 1 {
+		t.Errorf("Expected the near and far particles visible, got %v", visible)
+	}
+
+	// Drawn larger, the third particle reaches into the view
+	renderer.SetDisplayRadius(DisplayRadius{Scale: 1.2}, 0)
+	if count := renderer.GetVisibleParticleCount(); count != 3 {
+		t.Errorf("Expected 3 particles visible at 1.2 times the radius, got %d", count)
+	}
+
+	// On a 600 pixel viewport the far one has a radius of about 7 pixels, the near one 62
+	renderer.SetSizeCulling(10, 600)
+	if visible := renderer.GetVisibleIndices(); len(visible) != 2 || visible[0] != 0 || visible[1] != 2 {
+		t.Errorf("Expected the far particle culled by size, got %v", visible)
+	}
+	if stats := renderer.GetStats(); stats.Culled != 1 {
+		t.Errorf("Expected 1 culled particle, got %+v", stats)
+	}
 }
 
 // TestRenderMode tests different rendering modes
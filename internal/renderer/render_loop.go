@@ -1,6 +1,7 @@
 package renderer
 
 import (
+	"math"
 	"time"
 )
 
@@ -30,6 +31,9 @@ type RenderLoop struct {
 
 	// Timing
 	frameStartTime time.Time
+	deltaFilter    *DeltaFilter // Filters the update's time step (nil = raw frame times)
+
+	renderStats *RenderStats // Scene statistics added to GetStatistics (nil = none)
 }
 
 // NewRenderLoop creates a new render loop
@@ -48,13 +52,21 @@ func (r *RenderLoop) GetTargetFPS() int {
 	return r.targetFPS
 }
 
-// SetTargetFPS sets the target FPS
+// SetTargetFPS sets the target FPS (0 = uncapped)
 func (r *RenderLoop) SetTargetFPS(fps int) {
 	r.targetFPS = fps
-	r.targetFrameTime = 1.0 / float64(fps)
+	r.targetFrameTime = 0
+	if fps > 0 {
+		r.targetFrameTime = 1.0 / float64(fps)
+	}
 }
 
-// GetTargetFrameTime returns the target frame time in seconds
+// IsUncapped returns whether the loop runs frames as fast as it can
+func (r *RenderLoop) IsUncapped() bool {
+	return r.targetFPS <= 0 && !r.vsyncEnabled
+}
+
+// GetTargetFrameTime returns the target frame time in seconds (0 = uncapped)
 func (r *RenderLoop) GetTargetFrameTime() float64 {
 	return r.targetFrameTime
 }
@@ -69,9 +81,10 @@ func (r *RenderLoop) RecordFrameTime(frameTime float64) {
 		r.frameTimes = r.frameTimes[1:]
 	}
 
-	// Calculate actual FPS
-	if frameTime > 0 {
-		r.actualFPS = int(1.0 / frameTime)
+	// Calculate actual FPS over the recorded samples, so it does not jitter
+	// from frame to frame
+	if average := r.GetAverageFrameTime(); average > 0 {
+		r.actualFPS = int(math.Round(1.0 / average))
 	}
 }
 
@@ -115,10 +128,14 @@ func (r *RenderLoop) EndFrame() {
 		r.endCallback()
 	}
 
-	// Calculate elapsed time
-	elapsed := time.Since(r.frameStartTime)
-
 	// Wait to maintain target FPS
+	r.limit(r.frameStartTime)
+}
+
+// limit sleeps out the rest of the target frame time of a frame started at
+// frameStart
+func (r *RenderLoop) limit(frameStart time.Time) {
+	elapsed := time.Since(frameStart)
 	targetDuration := time.Duration(r.targetFrameTime * float64(time.Second))
 	if elapsed < targetDuration {
 		time.Sleep(targetDuration - elapsed)
@@ -143,9 +160,13 @@ func (r *RenderLoop) ExecuteFrame() {
 		r.beginCallback()
 	}
 
-	// Update phase
+	// Update phase, with the filtered time step
 	if r.updateCallback != nil {
-		r.updateCallback(dt)
+		step := dt
+		if r.deltaFilter != nil {
+			step = r.deltaFilter.Filter(dt)
+		}
+		r.updateCallback(step)
 	}
 
 	// Render phase
@@ -166,6 +187,18 @@ func (r *RenderLoop) SetUpdateCallback(callback func(dt float64)) {
 	r.updateCallback = callback
 }
 
+// SetDeltaFilter sets the filter applied to the update's time step; the
+// render callback still receives the raw frame time (nil = no filtering)
+func (r *RenderLoop) SetDeltaFilter(filter *DeltaFilter) {
+	r.deltaFilter = filter
+}
+
+// SetRenderStats sets the scene statistics that GetStatistics reports
+// along with the frame rate (nil = none)
+func (r *RenderLoop) SetRenderStats(stats *RenderStats) {
+	r.renderStats = stats
+}
+
 // GetAverageFrameTime returns the average frame time
 func (r *RenderLoop) GetAverageFrameTime() float64 {
 	if len(r.frameTimes) == 0 {
@@ -219,7 +252,10 @@ func (r *RenderLoop) ResetCloseRequest() {
 	r.shouldClose = false
 }
 
-// Run runs the main render loop
+// Run runs the main render loop until Stop or RequestClose. Each frame's
+// update receives the wall time of the previous frame, including the wait
+// for the frame rate limit. With vsync the display paces the frames and the
+// target FPS is not enforced
 func (r *RenderLoop) Run() {
 	r.Start()
 
@@ -229,18 +265,13 @@ func (r *RenderLoop) Run() {
 		// Execute frame
 		r.ExecuteFrame()
 
-		// Record frame time
-		frameTime := time.Since(frameStart).Seconds()
-		r.RecordFrameTime(frameTime)
-
 		// Frame rate limiting
 		if !r.vsyncEnabled {
-			elapsed := time.Since(frameStart)
-			targetDuration := time.Duration(r.targetFrameTime * float64(time.Second))
-			if elapsed < targetDuration {
-				time.Sleep(targetDuration - elapsed)
-			}
+			r.limit(frameStart)
 		}
+
+		// Record frame time
+		r.RecordFrameTime(time.Since(frameStart).Seconds())
 	}
 
 	r.Stop()
@@ -248,7 +279,7 @@ func (r *RenderLoop) Run() {
 
 // GetStatistics returns render statistics
 func (r *RenderLoop) GetStatistics() map[string]interface{} {
-	return map[string]interface{}{
+	stats := map[string]interface{}{
 		"targetFPS":        r.targetFPS,
 		"actualFPS":        r.actualFPS,
 		"frameCount":       r.frameCount,
@@ -256,4 +287,10 @@ func (r *RenderLoop) GetStatistics() map[string]interface{} {
 		"lastFrameTime":    r.lastFrameTime,
 		"vsyncEnabled":     r.vsyncEnabled,
 	}
+	if r.renderStats != nil {
+		for key, value := range r.renderStats.Statistics() {
+			stats[key] = value
+		}
+	}
+	return stats
 }
@@ -96,6 +96,27 @@ func TestFrameRateLimit(t *testing.T) {
 	}
 }
 
+// TestUncappedFrameRate tests that a zero target runs frames without waiting
+func TestUncappedFrameRate(t *testing.T) {
+	loop := NewRenderLoop()
+	loop.SetTargetFPS(0)
+	if loop.GetTargetFrameTime() != 0 || !loop.IsUncapped() {
+		t.Fatalf("Expected an uncapped loop, got frame time %g", loop.GetTargetFrameTime())
+	}
+
+	startTime := time.Now()
+	loop.BeginFrame()
+	loop.EndFrame()
+	if elapsed := time.Since(startTime); elapsed > 10*time.Millisecond {
+		t.Errorf("Expected no frame rate wait, took %v", elapsed)
+	}
+
+	loop.EnableVSync(true)
+	if loop.IsUncapped() {
+		t.Error("Expected vsync to cap the loop")
+	}
+}
+
 // TestRenderCallback tests render callback functionality
 func TestRenderCallback(t *testing.T) {
 	loop := NewRenderLoop()
@@ -147,6 +168,25 @@ func TestUpdateCallback(t *testing.T) {
 	}
 }
 
+// TestUpdateDeltaFilter tests that spikes are filtered from the update's
+// time step but not from the render's
+func TestUpdateDeltaFilter(t *testing.T) {
+	loop := NewRenderLoop()
+	loop.SetDeltaFilter(NewDeltaFilter(0, 3))
+
+	var updateDt, renderDt float64
+	loop.SetUpdateCallback(func(dt float64) { updateDt = dt })
+	loop.SetRenderCallback(func(dt float64) { renderDt = dt })
+
+	loop.RecordFrameTime(0.016)
+	loop.ExecuteFrame()
+	loop.RecordFrameTime(0.25)
+	loop.ExecuteFrame()
+	if updateDt != 0.016 || renderDt != 0.25 {
+		t.Errorf("Expected update dt 0.016 and render dt 0.25, got %g and %g", updateDt, renderDt)
+	}
+}
+
 // TestFrameStatistics tests frame statistics tracking
 func TestFrameStatistics(t *testing.T) {
 	loop := NewRenderLoop()
@@ -235,6 +275,34 @@ func TestRenderPhases(t *testing.T) {
 	}
 }
 
+// TestRunFrameTime tests that Run paces frames at the target FPS, passes the
+// previous frame's wall time to the update and stops when a callback
+// requests it
+func TestRunFrameTime(t *testing.T) {
+	loop := NewRenderLoop()
+	loop.SetTargetFPS(50)
+
+	var deltas []float64
+	loop.SetUpdateCallback(func(dt float64) {
+		deltas = append(deltas, dt)
+	})
+	loop.SetEndCallback(func() {
+		if len(deltas) == 3 {
+			loop.RequestClose()
+		}
+	})
+	loop.Run()
+
+	if len(deltas) != 3 || loop.IsRunning() {
+		t.Fatalf("Expected 3 frames and a stopped loop, got %d frames", len(deltas))
+	}
+	for _, dt := range deltas[1:] {
+		if dt < 0.019 {
+			t.Errorf("Expected frame times of at least the 20 ms target, got %v", dt)
+		}
+	}
+}
+
 // TestShouldClose tests the should close functionality
 func TestShouldClose(t *testing.T) {
 	loop := NewRenderLoop()
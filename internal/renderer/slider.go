@@ -0,0 +1,37 @@
+package renderer
+
+import "math"
+
+// Slider is a HUD slider over a range of counts on a logarithmic scale, so
+// each step scales the value by the same factor and the knob moves evenly
+type Slider struct {
+	Min, Max int     // Range of the values
+	Factor   float64 // Scale of one step, above 1
+}
+
+// Step returns value moved by steps, up when positive, within the range.
+// Every step changes the value by at least one
+func (s Slider) Step(value, steps int) int {
+	next := int(math.Round(float64(value) * math.Pow(s.Factor, float64(steps))))
+	switch {
+	case steps > 0 && next <= value:
+		next = value + 1
+	case steps < 0 && next >= value:
+		next = value - 1
+	}
+	return s.Clamp(next)
+}
+
+// Clamp returns value within the range
+func (s Slider) Clamp(value int) int {
+	return min(max(value, s.Min), max(s.Max, s.Min))
+}
+
+// Fraction returns the position of the knob for value, from 0 at Min to 1
+// at Max
+func (s Slider) Fraction(value int) float64 {
+	if s.Max <= s.Min || s.Min <= 0 {
+		return 1
+	}
+	return math.Log(float64(s.Clamp(value))/float64(s.Min)) / math.Log(float64(s.Max)/float64(s.Min))
+}
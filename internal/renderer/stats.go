@@ -0,0 +1,201 @@
+package renderer
+
+import (
+	"fmt"
+	"math"
+)
+
+// Vertices raylib emits for each immediate-mode primitive
+const (
+	LineVertices      = 2
+	RectangleVertices = 4
+	CircleVertices    = 108  // rl.DrawCircle: 36 triangles
+	Circle3DVertices  = 72   // rl.DrawCircle3D: 36 lines
+	SphereVertices    = 1728 // rl.DrawSphere: 18 rings of 16 quads split into triangles
+)
+
+// Frame limits
+const (
+	LimitPhysics   = "physics"
+	LimitRendering = "rendering"
+	LimitFrameRate = "frame rate cap"
+)
+
+// statsSmoothing is the weight of each new frame in the smoothed times
+const statsSmoothing = 0.05
+
+// capSlack is how far over the target frame time a frame still counts as
+// paced by the cap or vsync
+const capSlack = 1.1
+
+// LayerStats counts the work of drawing one scene layer. Draw calls are
+// immediate-mode primitives, which raylib merges into fewer GPU batches
+type LayerStats struct {
+	Name      string
+	DrawCalls int
+	Vertices  int
+	Culled    int     // Items skipped as out of view
+	Thinned   int     // Items skipped by sub-sampling to the render budget
+	Time      float64 // Seconds spent issuing the draws
+}
+
+// Add counts calls primitives of vertices each
+func (l *LayerStats) Add(calls, vertices int) {
+	l.DrawCalls += calls
+	l.Vertices += calls * vertices
+}
+
+// RenderStats collects per-layer statistics frame by frame. Counts are the
+// last frame's; times are smoothed over recent frames so the HUD is readable
+type RenderStats struct {
+	frame   []LayerStats // Current frame
+	layers  []LayerStats // Last frame, with smoothed times
+	physics float64      // Smoothed seconds per frame stepping the simulation
+	render  float64      // Smoothed seconds per frame drawing and presenting
+	total   float64      // Smoothed wall seconds per frame
+	frames  int
+}
+
+// NewRenderStats creates empty render statistics
+func NewRenderStats() *RenderStats {
+	return &RenderStats{}
+}
+
+// Layer returns the counters of the named layer in the current frame,
+// created on its first draw; stereo frames add both eyes to it. The pointer
+// is valid until the next call
+func (s *RenderStats) Layer(name string) *LayerStats {
+	for i := range s.frame {
+		if s.frame[i].Name == name {
+			return &s.frame[i]
+		}
+	}
+	s.frame = append(s.frame, LayerStats{Name: name})
+	return &s.frame[len(s.frame)-1]
+}
+
+// EndFrame folds the current frame into the statistics and starts the next.
+// frameTime is the wall time of the frame, physicsTime the time stepping the
+// simulation and renderTime the time drawing and presenting it, in seconds
+func (s *RenderStats) EndFrame(frameTime, physicsTime, renderTime float64) {
+	smooth := func(average, value float64) float64 {
+		if s.frames == 0 {
+			return value
+		}
+		return average + statsSmoothing*(value-average)
+	}
+	layers := make([]LayerStats, len(s.frame))
+	for i, l := range s.frame {
+		if j := s.index(l.Name); j >= 0 {
+			l.Time = smooth(s.layers[j].Time, l.Time)
+		}
+		layers[i] = l
+	}
+	s.layers = layers
+	s.physics = smooth(s.physics, physicsTime)
+	s.render = smooth(s.render, renderTime)
+	s.total = smooth(s.total, frameTime)
+	s.frames++
+	s.frame = s.frame[:0]
+}
+
+// index returns the position of the named layer in the last frame, or -1
+func (s *RenderStats) index(name string) int {
+	for i, l := range s.layers {
+		if l.Name == name {
+			return i
+		}
+	}
+	return -1
+}
+
+// Layers returns the statistics of the layers drawn in the last frame, in
+// drawing order
+func (s *RenderStats) Layers() []LayerStats {
+	return s.layers
+}
+
+// Totals returns the sums over the layers of the last frame
+func (s *RenderStats) Totals() LayerStats {
+	total := LayerStats{Name: "total"}
+	for _, l := range s.layers {
+		total.DrawCalls += l.DrawCalls
+		total.Vertices += l.Vertices
+		total.Culled += l.Culled
+		total.Thinned += l.Thinned
+		total.Time += l.Time
+	}
+	return total
+}
+
+// Times returns the smoothed seconds per frame spent on physics, on
+// rendering, and in total
+func (s *RenderStats) Times() (physics, render, frame float64) {
+	return s.physics, s.render, s.total
+}
+
+// Limit names what holds the frame rate down: the cap or vsync while frames
+// take no longer than target seconds (0 = uncapped), otherwise whichever of
+// physics and rendering takes longer
+func (s *RenderStats) Limit(target float64) string {
+	switch {
+	case target > 0 && s.total <= capSlack*target:
+		return LimitFrameRate
+	case s.physics > s.render:
+		return LimitPhysics
+	default:
+		return LimitRendering
+	}
+}
+
+// Lines returns the HUD text of the statistics: the frame split into
+// physics and rendering with its limit, then one line per layer
+func (s *RenderStats) Lines(target float64) []string {
+	if s.frames == 0 {
+		return nil
+	}
+	ms := func(seconds float64) float64 { return 1000 * seconds }
+	total := s.Totals()
+	lines := []string{
+		fmt.Sprintf("Frame %.1f ms: physics %.1f, render %.1f, limited by %s", ms(s.total), ms(s.physics), ms(s.render), s.Limit(target)),
+		fmt.Sprintf("Layers %.1f ms: %d calls, %s vertices, %s", ms(total.Time), total.DrawCalls, count(total.Vertices), skipped(total)),
+	}
+	for _, l := range s.layers {
+		lines = append(lines, fmt.Sprintf("  %s %.2f ms: %d calls, %s vertices, %s", l.Name, ms(l.Time), l.DrawCalls, count(l.Vertices), skipped(l)))
+	}
+	return lines
+}
+
+// skipped formats the items a layer culled, and thinned if any
+func skipped(l LayerStats) string {
+	if l.Thinned > 0 {
+		return fmt.Sprintf("%d culled, %d thinned", l.Culled, l.Thinned)
+	}
+	return fmt.Sprintf("%d culled", l.Culled)
+}
+
+// count formats a count with a k or M suffix above a thousand
+func count(n int) string {
+	switch v := float64(n); {
+	case math.Abs(v) >= 1e6:
+		return fmt.Sprintf("%.1fM", v/1e6)
+	case math.Abs(v) >= 1e3:
+		return fmt.Sprintf("%.1fk", v/1e3)
+	}
+	return fmt.Sprint(n)
+}
+
+// Statistics returns the last frame's totals and the smoothed times, in the
+// form of RenderLoop.GetStatistics
+func (s *RenderStats) Statistics() map[string]interface{} {
+	total := s.Totals()
+	return map[string]interface{}{
+		"drawCalls":   total.DrawCalls,
+		"vertices":    total.Vertices,
+		"culled":      total.Culled,
+		"thinned":     total.Thinned,
+		"layerTime":   total.Time,
+		"physicsTime": s.physics,
+		"renderTime":  s.render,
+	}
+}
@@ -0,0 +1,90 @@
+package renderer
+
+import (
+	"math"
+	"strings"
+	"testing"
+)
+
+// TestRenderStatsLayers tests that layers keep the last frame's counts in
+// drawing order, adding up repeated draws, with smoothed times
+func TestRenderStatsLayers(t *testing.T) {
+	stats := NewRenderStats()
+	if lines := stats.Lines(0); lines != nil {
+		t.Errorf("Expected no lines before the first frame, got %v", lines)
+	}
+
+	grid := stats.Layer("grid")
+	grid.Add(10, LineVertices)
+	grid.Time = 0.002
+	particles := stats.Layer("particles")
+	particles.Add(3, SphereVertices)
+	particles.Thinned = 7
+	stats.Layer("grid").Add(10, LineVertices) // The second eye
+	stats.EndFrame(0.020, 0.010, 0.005)
+	if lines := stats.Lines(0); len(lines) != 4 || strings.Contains(lines[2], "thinned") || !strings.Contains(lines[3], "7 thinned") {
+		t.Errorf("Expected thinned particles reported on their layer only, got %q", lines)
+	}
+
+	layers := stats.Layers()
+	if len(layers) != 2 || layers[0].Name != "grid" || layers[1].Name != "particles" {
+		t.Fatalf("Expected grid and particles, got %+v", layers)
+	}
+	if l := layers[0]; l.DrawCalls != 20 || l.Vertices != 40 || l.Time != 0.002 {
+		t.Errorf("Expected 20 calls of 40 vertices in 2 ms, got %+v", l)
+	}
+	if total := stats.Totals(); total.DrawCalls != 23 || total.Vertices != 40+3*SphereVertices || total.Thinned != 7 {
+		t.Errorf("Expected 23 calls in total, got %+v", total)
+	}
+
+	// Counts follow the last frame and times move towards it
+	stats.Layer("grid").Time = 0.004
+	stats.EndFrame(0.040, 0.030, 0.005)
+	layers = stats.Layers()
+	if len(layers) != 1 || layers[0].DrawCalls != 0 {
+		t.Fatalf("Expected only an empty grid layer, got %+v", layers)
+	}
+	if want := 0.002 + statsSmoothing*0.002; math.Abs(layers[0].Time-want) > 1e-12 {
+		t.Errorf("Expected the grid time smoothed to %g, got %g", want, layers[0].Time)
+	}
+	physics, render, frame := stats.Times()
+	if want := 0.010 + statsSmoothing*0.020; math.Abs(physics-want) > 1e-12 || render != 0.005 || frame <= 0.020 {
+		t.Errorf("Expected physics %g, render 0.005 and a longer frame, got %g, %g and %g", want, physics, render, frame)
+	}
+}
+
+// TestRenderStatsLimit tests telling the cap, physics and rendering apart
+func TestRenderStatsLimit(t *testing.T) {
+	stats := NewRenderStats()
+	stats.EndFrame(1.0/60, 0.004, 0.003)
+	if limit := stats.Limit(1.0 / 60); limit != LimitFrameRate {
+		t.Errorf("Expected frames at the cap to be limited by it, got %s", limit)
+	}
+	if limit := stats.Limit(0); limit != LimitPhysics {
+		t.Errorf("Expected uncapped frames limited by the slower physics, got %s", limit)
+	}
+
+	stats = NewRenderStats()
+	stats.EndFrame(0.050, 0.010, 0.040)
+	if limit := stats.Limit(1.0 / 60); limit != LimitRendering {
+		t.Errorf("Expected slow frames limited by rendering, got %s", limit)
+	}
+	if lines := stats.Lines(1.0 / 60); len(lines) != 2 || !strings.Contains(lines[0], "limited by rendering") {
+		t.Errorf("Expected a frame and a layer total line, got %q", lines)
+	}
+}
+
+// TestRenderLoopRenderStats tests that the render loop reports the scene
+// statistics with its own
+func TestRenderLoopRenderStats(t *testing.T) {
+	loop := NewRenderLoop()
+	stats := NewRenderStats()
+	stats.Layer("grid").Add(5, LineVertices)
+	stats.EndFrame(0.016, 0.001, 0.002)
+	loop.SetRenderStats(stats)
+
+	statistics := loop.GetStatistics()
+	if statistics["drawCalls"] != 5 || statistics["vertices"] != 10 || statistics["targetFPS"] != 60 {
+		t.Errorf("Expected 5 calls, 10 vertices and the target FPS, got %v", statistics)
+	}
+}
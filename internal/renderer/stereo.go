@@ -0,0 +1,24 @@
+package renderer
+
+import "relativity_simulation_2d/internal/physics"
+
+// DefaultEyeSeparation is the distance between the stereo eyes in simulation
+// units, about 1/40 of the distance of the starting camera from the origin
+const DefaultEyeSeparation = 2.0
+
+// StereoPair returns the left and right eye cameras of a parallel stereo rig:
+// copies of the camera moved half the eye separation to either side, with
+// their targets moved along so both eyes look in the same direction
+func (c *Camera) StereoPair(eyeSeparation float64) (left, right *Camera) {
+	offset := c.GetRight().Scale(eyeSeparation / 2)
+	return c.offsetCopy(offset.Scale(-1)), c.offsetCopy(offset)
+}
+
+// offsetCopy returns a copy of the camera translated by offset
+func (c *Camera) offsetCopy(offset physics.Vec3) *Camera {
+	eye := *c
+	eye.Position = c.Position.Add(offset)
+	eye.Target = c.Target.Add(offset)
+	eye.viewDirty = true
+	return &eye
+}
@@ -0,0 +1,31 @@
+package renderer
+
+import (
+	"math"
+	"relativity_simulation_2d/internal/physics"
+	"testing"
+)
+
+// TestStereoPair tests the eye offsets of the parallel stereo rig
+func TestStereoPair(t *testing.T) {
+	camera := NewCamera(physics.NewVec3(0, 0, 10), physics.NewVec3(0, 0, 0), physics.NewVec3(0, 1, 0))
+	left, right := camera.StereoPair(2)
+
+	// Looking down -Z with +Y up, right is +X
+	if left.Position != physics.NewVec3(-1, 0, 10) || right.Position != physics.NewVec3(1, 0, 10) {
+		t.Errorf("Expected eyes at x = -1 and 1, got %+v and %+v", left.Position, right.Position)
+	}
+	if left.GetForward() != camera.GetForward() || right.GetForward() != camera.GetForward() {
+		t.Error("Expected both eyes to look in the camera's direction")
+	}
+	if camera.Position != physics.NewVec3(0, 0, 10) {
+		t.Errorf("Expected the camera to be unchanged, got %+v", camera.Position)
+	}
+
+	// The eyes see the origin on opposite sides of the view axis
+	l := left.GetViewMatrix().TransformPoint(physics.Vec3{})
+	r := right.GetViewMatrix().TransformPoint(physics.Vec3{})
+	if math.Abs(l.X-1) > 1e-9 || math.Abs(r.X+1) > 1e-9 {
+		t.Errorf("Expected the origin at x = 1 and -1 in eye space, got %g and %g", l.X, r.X)
+	}
+}
@@ -0,0 +1,123 @@
+package renderer
+
+import (
+	"math"
+	"relativity_simulation_2d/internal/physics"
+	"slices"
+)
+
+// DefaultSampleCell is the side in pixels of the screen cells that
+// sub-sampling balances the particles over
+const DefaultSampleCell = 16
+
+// ScreenPoint is a particle's position on screen
+type ScreenPoint struct {
+	Index int // Particle index
+	X, Y  float64
+}
+
+// DensitySampler thins particles on screen to a budget, stratified by screen
+// density: sparse cells keep all their particles and the dense ones share
+// the rest of the budget evenly, so clusters stay visible without hiding
+// the outskirts. Each particle has a fixed priority and a cell keeps those
+// below its share, so the subsample is deterministic and changes little as
+// particles move
+type DensitySampler struct {
+	Cell   float64       // Side of the screen cells in pixels (0 = DefaultSampleCell)
+	counts []int         // Particles per cell, reused between frames
+	cells  []int         // Cell of each point, reused between frames
+	sorted []int         // Cell counts in increasing order, reused between frames
+	points []ScreenPoint // Projected particles, reused between frames
+}
+
+// Sample appends to dst the indices of at most about budget of the points,
+// in their order, on a width × height pixel screen. Points off the screen
+// are clamped into its edge cells. A budget of 0, or at least the number
+// of points, keeps them all
+func (s *DensitySampler) Sample(dst []int, points []ScreenPoint, width, height, budget int) []int {
+	if budget <= 0 || budget >= len(points) {
+		for _, p := range points {
+			dst = append(dst, p.Index)
+		}
+		return dst
+	}
+
+	// Count the points in each cell
+	cell := s.Cell
+	if cell <= 0 {
+		cell = DefaultSampleCell
+	}
+	columns, rows := max(int(math.Ceil(float64(width)/cell)), 1), max(int(math.Ceil(float64(height)/cell)), 1)
+	s.counts = slices.Grow(s.counts[:0], columns*rows)[:columns*rows]
+	clear(s.counts)
+	s.cells = slices.Grow(s.cells[:0], len(points))[:len(points)]
+	for k, p := range points {
+		i := min(max(int(p.X/cell), 0), columns-1)
+		j := min(max(int(p.Y/cell), 0), rows-1)
+		s.cells[k] = j*columns + i
+		s.counts[s.cells[k]]++
+	}
+
+	// Keep each particle whose priority is under its cell's share
+	quota := s.quota(budget)
+	for k, p := range points {
+		if n := s.counts[s.cells[k]]; float64(n) <= quota || samplePriority(p.Index) < quota/float64(n) {
+			dst = append(dst, p.Index)
+		}
+	}
+	return dst
+}
+
+// SampleParticles appends to dst about budget of the visible particles,
+// sampled by where camera draws them on a width × height pixel viewport.
+// Particles around or behind the camera, whose place on screen is unknown,
+// are all kept
+func (s *DensitySampler) SampleParticles(dst, visible []int, particles []*physics.Particle, camera *Camera, width, height, budget int) []int {
+	if budget <= 0 || budget >= len(visible) {
+		return append(dst, visible...)
+	}
+	s.points = s.points[:0]
+	for _, i := range visible {
+		if x, y, ok := camera.ScreenPosition(particles[i].Position, width, height); ok {
+			s.points = append(s.points, ScreenPoint{Index: i, X: x, Y: y})
+		} else {
+			dst = append(dst, i)
+		}
+	}
+	return s.Sample(dst, s.points, width, height, max(budget-(len(visible)-len(s.points)), 1))
+}
+
+// quota returns the particles each cell may keep so that the cells keep
+// budget in total, the sparse ones all of theirs: the q with Σ min(nᵢ, q) =
+// budget, for a budget under the number of points
+func (s *DensitySampler) quota(budget int) float64 {
+	s.sorted = s.sorted[:0]
+	for _, n := range s.counts {
+		if n > 0 {
+			s.sorted = append(s.sorted, n)
+		}
+	}
+	slices.Sort(s.sorted)
+
+	// Fill from the sparsest cell: if every cell from k on gets q, the
+	// first k keep all theirs
+	remaining := float64(budget)
+	for k, n := range s.sorted {
+		q := remaining / float64(len(s.sorted)-k)
+		if float64(n) >= q {
+			return q
+		}
+		remaining -= float64(n)
+	}
+	return math.Inf(1)
+}
+
+// samplePriority returns the fixed priority in [0, 1) of particle i, from
+// the SplitMix64 mix of its index
+func samplePriority(i int) float64 {
+	z := uint64(i) + 0x9e3779b97f4a7c15
+	z = (z ^ (z >> 30)) * 0xbf58476d1ce4e5b9
+	z = (z ^ (z >> 27)) * 0x94d049bb133111eb
+	z ^= z >> 31
+	return float64(z>>11) / (1 << 53)
+}
@@ -0,0 +1,152 @@
+package renderer
+
+import (
+	"math"
+	"math/rand"
+	"relativity_simulation_2d/internal/physics"
+	"slices"
+	"testing"
+)
+
+// TestDensitySamplerBudget tests that points within the budget are all kept
+// in order, and that the subsample of uniform points is about the budget
+func TestDensitySamplerBudget(t *testing.T) {
+	rng := rand.New(rand.NewSource(1))
+	points := make([]ScreenPoint, 20000)
+	for i := range points {
+		points[i] = ScreenPoint{Index: i, X: rng.Float64() * 800, Y: rng.Float64() * 600}
+	}
+	var s DensitySampler
+	for _, budget := range []int{0, len(points), 2 * len(points)} {
+		if kept := s.Sample(nil, points, 800, 600, budget); len(kept) != len(points) || kept[0] != 0 || kept[len(kept)-1] != len(points)-1 {
+			t.Errorf("Expected all %d points kept at a budget of %d, got %d", len(points), budget, len(kept))
+		}
+	}
+
+	kept := s.Sample(nil, points, 800, 600, 5000)
+	if math.Abs(float64(len(kept))-5000) > 200 {
+		t.Errorf("Expected about 5000 points kept, got %d", len(kept))
+	}
+	if !slices.IsSorted(kept) {
+		t.Error("Expected the kept points in their order")
+	}
+}
+
+// TestDensitySamplerStratified tests that a dense cluster is thinned while
+// sparse points all stay, and that the subsample is deterministic and
+// stable as points move within their cells
+func TestDensitySamplerStratified(t *testing.T) {
+	// 10000 points in one cell and one point in each of 100 others
+	var points []ScreenPoint
+	for i := 0; i < 10000; i++ {
+		points = append(points, ScreenPoint{Index: i, X: 400 + float64(i%10), Y: 300 + float64(i/10%10)})
+	}
+	for i := 0; i < 100; i++ {
+		points = append(points, ScreenPoint{Index: 10000 + i, X: 8 + float64(i%50)*16, Y: 8 + float64(i/50)*16})
+	}
+
+	var s DensitySampler
+	kept := s.Sample(nil, points, 800, 600, 1100)
+	sparse, cluster := 0, 0
+	for _, i := range kept {
+		if i >= 10000 {
+			sparse++
+		} else {
+			cluster++
+		}
+	}
+	if sparse != 100 {
+		t.Errorf("Expected all 100 sparse points kept, got %d", sparse)
+	}
+	if cluster < 900 || cluster > 1100 {
+		t.Errorf("Expected about 1000 of the cluster kept, got %d", cluster)
+	}
+
+	if again := s.Sample(nil, points, 800, 600, 1100); !slices.Equal(again, kept) {
+		t.Error("Expected the same subsample for the same points")
+	}
+	for i := range points[:10000] {
+		points[i].X += 0.5
+	}
+	if moved := s.Sample(nil, points, 800, 600, 1100); !slices.Equal(moved, kept) {
+		t.Error("Expected the same subsample after moving within the cells")
+	}
+}
+
+// TestSampleParticles tests that particles the camera cannot place on
+// screen are kept and the rest sampled to the budget
+func TestSampleParticles(t *testing.T) {
+	cam := NewCamera(physics.NewVec3(0, 0, 0), physics.NewVec3(0, 0, -1), physics.NewVec3(0, 1, 0))
+	cam.SetPerspective(60.0, 1.0, 0.1, 100.0)
+
+	rng := rand.New(rand.NewSource(2))
+	var particles []*physics.Particle
+	var visible []int
+	for i := 0; i < 4000; i++ {
+		z := -1 - rng.Float64()*10
+		if i < 10 {
+			z = 0.5 // Behind the camera, yet reaching into the view
+		}
+		particles = append(particles, &physics.Particle{Position: physics.NewVec3(rng.Float64()-0.5, rng.Float64()-0.5, z)})
+		visible = append(visible, i)
+	}
+
+	var s DensitySampler
+	kept := s.SampleParticles(nil, visible, particles, cam, 400, 400, 1000)
+	for i := 0; i < 10; i++ {
+		if !slices.Contains(kept, i) {
+			t.Errorf("Expected particle %d behind the camera kept", i)
+		}
+	}
+	if math.Abs(float64(len(kept))-1000) > 100 {
+		t.Errorf("Expected about 1000 particles kept, got %d", len(kept))
+	}
+	if all := s.SampleParticles(nil, visible, particles, cam, 400, 400, 0); len(all) != len(visible) {
+		t.Errorf("Expected all particles without a budget, got %d", len(all))
+	}
+}
+
+// TestSlider tests stepping and placing a logarithmic slider
+func TestSlider(t *testing.T) {
+	s := Slider{Min: 1000, Max: 1000000, Factor: 2}
+	tests := []struct {
+		value, steps, want int
+	}{
+		{100000, 1, 200000},
+		{100000, -2, 25000},
+		{800000, 1, 1000000}, // Clamped to the range
+		{1500, -1, 1000},
+		{1000, 0, 1000},
+	}
+	for _, tt := range tests {
+		if got := s.Step(tt.value, tt.steps); got != tt.want {
+			t.Errorf("Step(%d, %d) = %d, want %d", tt.value, tt.steps, got, tt.want)
+		}
+	}
+	if got := (Slider{Min: 1, Max: 10, Factor: 1.1}).Step(2, 1); got != 3 {
+		t.Errorf("Expected a step of at least one, got %d", got)
+	}
+
+	for value, want := range map[int]float64{1000: 0, 31623: 0.5, 1000000: 1, 5000000: 1} {
+		if got := s.Fraction(value); math.Abs(got-want) > 1e-3 {
+			t.Errorf("Fraction(%d) = %g, want %g", value, got, want)
+		}
+	}
+}
+
+// BenchmarkDensitySampler measures thinning a million points on a 1080p
+// screen to the default budget
+func BenchmarkDensitySampler(b *testing.B) {
+	rng := rand.New(rand.NewSource(1))
+	points := make([]ScreenPoint, 1000000)
+	for i := range points {
+		// Clustered towards the center, as in an evolved system
+		points[i] = ScreenPoint{Index: i, X: 960 + 300*rng.NormFloat64(), Y: 540 + 200*rng.NormFloat64()}
+	}
+	var s DensitySampler
+	var kept []int
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		kept = s.Sample(kept[:0], points, 1920, 1080, 100000)
+	}
+}
@@ -3,6 +3,7 @@ package renderer
 import (
 	"errors"
 	"fmt"
+	"math"
 )
 
 // ComputeMode represents the compute mode for UI display
@@ -31,11 +32,17 @@ type UIState struct {
 	Paused        bool
 }
 
+// DefaultFontSize is the HUD font size at a UI scale of 1
+const DefaultFontSize = 20
+
 // UIRenderer handles UI rendering
 type UIRenderer struct {
 	screenWidth  int
 	screenHeight int
 	fontSize     int
+	scale        float64 // Multiplies font sizes and layout spacing
+	palette      Palette
+	scheme       ColorScheme
 
 	// UI state
 	title         string
@@ -46,6 +53,11 @@ type UIRenderer struct {
 	actualFPS     int
 	frameTime     float64
 	paused        bool
+
+	// Notification queue
+	notifications        []Notification
+	notificationDuration float64
+	maxNotifications     int
 }
 
 // NewUIRenderer creates a new UI renderer
@@ -53,11 +65,55 @@ func NewUIRenderer(screenWidth, screenHeight int) *UIRenderer {
 	return &UIRenderer{
 		screenWidth:  screenWidth,
 		screenHeight: screenHeight,
-		fontSize:     20, // Default font size from main.go
+		fontSize:     DefaultFontSize,
+		scale:        1,
+		scheme:       PaletteDefault.Scheme(),
 		title:        "GR (Weak-Field) N-Body Simulation",
+
+		notificationDuration: DefaultNotificationDuration,
+		maxNotifications:     DefaultMaxNotifications,
 	}
 }
 
+// SetPalette sets the color palette of the UI and the scene
+func (ui *UIRenderer) SetPalette(palette Palette) {
+	ui.palette = palette
+	ui.scheme = palette.Scheme()
+}
+
+// GetPalette returns the color palette
+func (ui *UIRenderer) GetPalette() Palette {
+	return ui.palette
+}
+
+// GetColorScheme returns the colors of the current palette
+func (ui *UIRenderer) GetColorScheme() ColorScheme {
+	return ui.scheme
+}
+
+// SetUIScale scales the font size and layout; 1 is the default size
+func (ui *UIRenderer) SetUIScale(scale float64) {
+	ui.scale = scale
+	ui.fontSize = ui.px(DefaultFontSize)
+}
+
+// GetUIScale returns the font and layout scale
+func (ui *UIRenderer) GetUIScale() float64 {
+	return ui.scale
+}
+
+// px scales a layout length in pixels at UI scale 1
+func (ui *UIRenderer) px(length int) int {
+	return int(math.Round(float64(length) * ui.scale))
+}
+
+// GetLinePosition returns the position of the given line of the HUD's left
+// column: line 0 is the title, followed by the particle count, the mode and
+// the solver, then the control instructions
+func (ui *UIRenderer) GetLinePosition(line int) (int, int) {
+	return ui.px(10), ui.px(10 + line*30)
+}
+
 // GetScreenDimensions returns the screen dimensions
 func (ui *UIRenderer) GetScreenDimensions() (int, int) {
 	return ui.screenWidth, ui.screenHeight
@@ -109,7 +165,11 @@ func (ui *UIRenderer) GetControlInstructions() []string {
 	return []string{
 		"Right-click + Mouse to look",
 		"W,A,S,D,Q,E to move",
-		"P to pause, G to toggle GPU",
+		"P to pause, G compute mode",
+		"Toggle: F1 explain, F2 plots, F3 stereo, M sound, B/V colors",
+		"F4 uncapped FPS, F5 vsync, F10 render stats, F11 GPU state",
+		"Shift+1-9, 0 scene layers, [ ] render budget",
+		"1-9 camera bookmarks, Ctrl+1-9 save view",
 	}
 }
 
@@ -160,37 +220,37 @@ func (ui *UIRenderer) GetPauseText() string {
 
 // GetTitlePosition returns the title position
 func (ui *UIRenderer) GetTitlePosition() (int, int) {
-	return 10, 10
+	return ui.GetLinePosition(0)
 }
 
 // GetParticleCountPosition returns the particle count position
 func (ui *UIRenderer) GetParticleCountPosition() (int, int) {
-	return 10, 40
+	return ui.GetLinePosition(1)
 }
 
 // GetModePosition returns the mode display position
 func (ui *UIRenderer) GetModePosition() (int, int) {
-	return 10, 70
+	return ui.GetLinePosition(2)
 }
 
 // GetFPSPosition returns the FPS display position
 func (ui *UIRenderer) GetFPSPosition() (int, int) {
-	return ui.screenWidth - 200, 10
+	return ui.screenWidth - ui.px(200), ui.px(10)
 }
 
 // GetPausePosition returns the pause indicator position
 func (ui *UIRenderer) GetPausePosition() (int, int) {
-	return ui.screenWidth/2 - 150, ui.screenHeight/2 - 10
+	return ui.screenWidth/2 - ui.px(150), ui.screenHeight/2 - ui.px(10)
 }
 
-// GetTitleColor returns the title color (lime/green)
+// GetTitleColor returns the title color (lime/green by default)
 func (ui *UIRenderer) GetTitleColor() UIColor {
-	return UIColor{R: 0, G: 255, B: 0, A: 255}
+	return ui.scheme.Title
 }
 
 // GetDefaultTextColor returns the default text color (white)
 func (ui *UIRenderer) GetDefaultTextColor() UIColor {
-	return UIColor{R: 255, G: 255, B: 255, A: 255}
+	return ui.scheme.Text
 }
 
 // GetModeColor returns the color for mode display
@@ -198,22 +258,19 @@ func (ui *UIRenderer) GetModeColor(mode ComputeMode, fallback bool) UIColor {
 	switch mode {
 	case ModeGPU:
 		if fallback {
-			// Yellow for fallback
-			return UIColor{R: 255, G: 255, B: 0, A: 255}
+			return ui.scheme.ModeFallback
 		}
-		// Green for GPU
-		return UIColor{R: 0, G: 255, B: 0, A: 255}
+		return ui.scheme.ModeGPU
 	case ModeCPU:
-		// Orange for CPU
-		return UIColor{R: 255, G: 165, B: 0, A: 255}
+		return ui.scheme.ModeCPU
 	default:
 		return ui.GetDefaultTextColor()
 	}
 }
 
-// GetPauseColor returns the pause indicator color (yellow)
+// GetPauseColor returns the pause indicator color (yellow by default)
 func (ui *UIRenderer) GetPauseColor() UIColor {
-	return UIColor{R: 255, G: 255, B: 0, A: 255}
+	return ui.scheme.Pause
 }
 
 // GetFontSize returns the font size
@@ -251,6 +308,9 @@ func (ui *UIRenderer) GetParticleCountText() string {
 
 // GetTargetFPSText returns formatted target FPS text
 func (ui *UIRenderer) GetTargetFPSText() string {
+	if ui.targetFPS <= 0 {
+		return "Target FPS: Uncapped"
+	}
 	return fmt.Sprintf("Target FPS: %d", ui.targetFPS)
 }
 
@@ -266,16 +326,65 @@ func (ui *UIRenderer) GetFrameTimeText() string {
 
 // GetControlPosition returns the position for control instruction at given index
 func (ui *UIRenderer) GetControlPosition(index int) (int, int) {
-	// Control instructions start at y=130 with 30 pixel spacing
-	return 10, 130 + index*30
+	// Control instructions follow the title, particle count, mode and solver lines
+	return ui.GetLinePosition(4 + index)
 }
 
 // GetActualFPSPosition returns the actual FPS display position
 func (ui *UIRenderer) GetActualFPSPosition() (int, int) {
-	return ui.screenWidth - 200, 35
+	return ui.screenWidth - ui.px(200), ui.px(35)
 }
 
 // GetFrameTimePosition returns the frame time display position
 func (ui *UIRenderer) GetFrameTimePosition() (int, int) {
-	return ui.screenWidth - 200, 60
+	return ui.screenWidth - ui.px(200), ui.px(60)
+}
+
+// GetMinimapPosition returns the top-left corner and side of a minimap of
+// size pixels at scale 1, in the top-right corner below the frame time
+func (ui *UIRenderer) GetMinimapPosition(size int) (int, int, int) {
+	side := ui.px(size)
+	return ui.screenWidth - side - ui.px(10), ui.px(95), side
+}
+
+// GetRenderStatsPosition returns the position of a line of the render
+// statistics, on the right below the frame time and below a minimap of
+// minimapSize pixels at scale 1 (0 = no minimap)
+func (ui *UIRenderer) GetRenderStatsPosition(line, minimapSize int) (int, int) {
+	top := 95
+	if minimapSize > 0 {
+		top += minimapSize + 10
+	}
+	return ui.screenWidth - ui.px(440), ui.px(top + line*25)
+}
+
+// GetGPUStatePosition returns the position of a line of the GPU state
+// overlay, on the left below the control instructions
+func (ui *UIRenderer) GetGPUStatePosition(line int) (int, int) {
+	_, top := ui.GetControlPosition(len(ui.GetControlInstructions()))
+	return ui.px(10), top + ui.px(10+line*25)
+}
+
+// GetExplainPanelPosition returns the top-left corner and side of the
+// explain mode's field panel of size pixels at scale 1, in the bottom-right
+// corner above its progress bar
+func (ui *UIRenderer) GetExplainPanelPosition(size int) (int, int, int) {
+	side := ui.px(size)
+	return ui.screenWidth - side - ui.px(10), ui.screenHeight - side - ui.px(20), side
+}
+
+// GetExplainTextPosition returns the position of a line of the explain
+// mode's text, lines lines stacked above its field panel of size pixels at
+// scale 1
+func (ui *UIRenderer) GetExplainTextPosition(line, lines, size int) (int, int) {
+	_, top, _ := ui.GetExplainPanelPosition(size)
+	return ui.screenWidth - ui.px(680), top - ui.px(10+(lines-line)*25)
+}
+
+// GetSliderPosition returns the top-left corner and the width of a slider
+// track width pixels wide at scale 1, centered at the top of the screen
+// below a line of its label
+func (ui *UIRenderer) GetSliderPosition(width int) (int, int, int) {
+	w := ui.px(width)
+	return (ui.screenWidth - w) / 2, ui.px(40), w
 }
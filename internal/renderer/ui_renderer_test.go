@@ -73,7 +73,7 @@ func TestUIControls(t *testing.T) {
 	expectedInstructions := []string{
 		"Right-click + Mouse to look",
 		"W,A,S,D,Q,E to move",
-		"P to pause, G to toggle GPU",
+		"P to pause, G compute mode",
 	}
 
 	for i, expected := range expectedInstructions {
@@ -107,6 +107,12 @@ func TestUIFPSDisplay(t *testing.T) {
 	if ui.GetFrameTime() != 0.017 {
 		t.Error("Failed to set frame time")
 	}
+
+	// A zero target is the uncapped benchmark mode
+	ui.SetTargetFPS(0)
+	if text := ui.GetTargetFPSText(); text != "Target FPS: Uncapped" {
+		t.Errorf("Expected the uncapped target text, got %q", text)
+	}
 }
 
 // TestUIPauseIndicator tests pause indicator
@@ -169,6 +175,59 @@ func TestUITextPositions(t *testing.T) {
 	}
 }
 
+// TestUIScale tests that the UI scale multiplies the font size and layout
+func TestUIScale(t *testing.T) {
+	ui := NewUIRenderer(800, 600)
+	ui.SetUIScale(2)
+
+	if ui.GetFontSize() != 2*DefaultFontSize {
+		t.Errorf("Expected font size %d, got %d", 2*DefaultFontSize, ui.GetFontSize())
+	}
+	if x, y := ui.GetParticleCountPosition(); x != 20 || y != 80 {
+		t.Errorf("Particle count position incorrect: expected (20,80), got (%d,%d)", x, y)
+	}
+	if x, y := ui.GetFPSPosition(); x != 400 || y != 20 { // 800 - 2*200 = 400
+		t.Errorf("FPS position incorrect: expected (400,20), got (%d,%d)", x, y)
+	}
+	if x, y, side := ui.GetMinimapPosition(100); x != 580 || y != 190 || side != 200 { // 800 - 200 - 20
+		t.Errorf("Minimap position incorrect: expected (580,190) side 200, got (%d,%d) side %d", x, y, side)
+	}
+
+	wide := NewUIRenderer(1920, 1080)
+	wide.SetUIScale(2)
+	if x, y := wide.GetRenderStatsPosition(2, 0); x != 1040 || y != 290 { // 1920 - 2*440, 2*(95 + 2*25)
+		t.Errorf("Render stats position incorrect: expected (1040,290), got (%d,%d)", x, y)
+	}
+	if _, y := wide.GetRenderStatsPosition(0, 100); y != 410 { // Below the minimap, 2*(95 + 100 + 10)
+		t.Errorf("Render stats position below the minimap incorrect: expected y 410, got %d", y)
+	}
+	if x, y := wide.GetGPUStatePosition(1); x != 20 || y != 750 { // Below 7 control lines, 2*(10 + 11*30) + 2*(10 + 25)
+		t.Errorf("GPU state position incorrect: expected (20,750), got (%d,%d)", x, y)
+	}
+	if x, y, side := wide.GetExplainPanelPosition(256); x != 1388 || y != 528 || side != 512 { // 1920 - 512 - 20, 1080 - 512 - 40
+		t.Errorf("Explain panel position incorrect: expected (1388,528) side 512, got (%d,%d) side %d", x, y, side)
+	}
+	if x, y := wide.GetExplainTextPosition(0, 5, 256); x != 560 || y != 258 { // 1920 - 2*680, 528 - 2*(10 + 5*25)
+		t.Errorf("Explain text position incorrect: expected (560,258), got (%d,%d)", x, y)
+	}
+	if x, y, w := wide.GetSliderPosition(300); x != 660 || y != 80 || w != 600 { // (1920 - 600) / 2
+		t.Errorf("Slider position incorrect: expected (660,80) width 600, got (%d,%d) width %d", x, y, w)
+	}
+}
+
+// TestUIPalette tests that the palette selects the UI colors
+func TestUIPalette(t *testing.T) {
+	ui := NewUIRenderer(800, 600)
+	ui.SetPalette(PaletteDeuteranopia)
+
+	if ui.GetPalette() != PaletteDeuteranopia {
+		t.Errorf("Expected the deuteranopia palette, got %v", ui.GetPalette())
+	}
+	if got, want := ui.GetModeColor(ModeGPU, false), PaletteDeuteranopia.Scheme().ModeGPU; got != want {
+		t.Errorf("Expected GPU mode color %+v, got %+v", want, got)
+	}
+}
+
 // TestUIColors tests UI color settings
 func TestUIColors(t *testing.T) {
 	ui := NewUIRenderer(800, 600)
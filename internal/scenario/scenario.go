@@ -0,0 +1,22 @@
+// Package scenario builds the showcase setups shipped with the simulation:
+// initial particles together with the extra forces and markers they need
+package scenario
+
+import "relativity_simulation_2d/internal/physics"
+
+// Marker is a labelled point of interest drawn over a scenario, such as a
+// Lagrange point
+type Marker struct {
+	Label    string
+	Position physics.Vec3
+}
+
+// Scenario is a ready-to-run setup. Masses are the analytic masses behind
+// its external potential, which are drawn but not simulated as particles
+type Scenario struct {
+	Particles []*physics.Particle
+	Forces    []physics.Force
+	Masses    []physics.PointMass
+	Markers   []Marker
+	Groups    []physics.Group // Named parts of the particles, such as a cluster, tracked separately
+}
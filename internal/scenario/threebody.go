@@ -0,0 +1,185 @@
+package scenario
+
+import (
+	"fmt"
+	"math"
+	"math/rand"
+	"relativity_simulation_2d/internal/physics"
+)
+
+// DefaultThreeBodyPeriod is the orbital period of the default binary in
+// simulation time, about 20 s at the interactive frame rate
+const DefaultThreeBodyPeriod = 20.0
+
+// ThreeBody is the circular restricted three-body problem: two masses on a
+// circular orbit about their center of mass at the origin, and massless
+// tracers moving in their field. It is integrated in the frame co-rotating
+// with the binary, where the primary stays fixed at negative x and the
+// secondary at positive x
+type ThreeBody struct {
+	PrimaryMass   float64
+	SecondaryMass float64
+	Separation    float64
+	G             float64
+	Softening     float64 // Softening length of the two masses
+
+	// Tracers are spread uniformly over an annulus about the center of mass,
+	// on circular orbits about the total mass
+	Tracers     int
+	InnerRadius float64
+	OuterRadius float64
+}
+
+// DefaultThreeBody returns a 20:1 binary a quarter of the box across, with an
+// orbital period of DefaultThreeBodyPeriod, and tracers from half to one and a
+// half times its separation, in a box of width×depth cells of size dx
+func DefaultThreeBody(width, depth int, dx, gravitationalConstant float64, tracers int) ThreeBody {
+	separation := float64(min(width, depth)) * dx / 4
+	omega := 2 * math.Pi / DefaultThreeBodyPeriod
+	total := (omega * separation) * (omega * separation) / (2 * gravitationalConstant)
+	return ThreeBody{
+		PrimaryMass:   total * 20 / 21,
+		SecondaryMass: total / 21,
+		Separation:    separation,
+		G:             gravitationalConstant,
+		Softening:     dx,
+		Tracers:       tracers,
+		InnerRadius:   0.5 * separation,
+		OuterRadius:   1.5 * separation,
+	}
+}
+
+// Validate checks that the masses, separation and tracer annulus are usable
+func (t ThreeBody) Validate() error {
+	if !(t.PrimaryMass > 0) || !(t.SecondaryMass > 0) {
+		return fmt.Errorf("invalid masses: %g and %g", t.PrimaryMass, t.SecondaryMass)
+	}
+	if t.SecondaryMass > t.PrimaryMass {
+		return fmt.Errorf("secondary mass %g exceeds primary mass %g", t.SecondaryMass, t.PrimaryMass)
+	}
+	if !(t.Separation > 0) || math.IsInf(t.Separation, 0) {
+		return fmt.Errorf("invalid separation: %g", t.Separation)
+	}
+	if !(t.G > 0) {
+		return fmt.Errorf("invalid gravitational constant: %g", t.G)
+	}
+	if t.Softening < 0 {
+		return fmt.Errorf("invalid softening: %g", t.Softening)
+	}
+	if t.Tracers < 0 {
+		return fmt.Errorf("invalid tracer count: %d", t.Tracers)
+	}
+	if t.InnerRadius < 0 || !(t.OuterRadius >= t.InnerRadius) {
+		return fmt.Errorf("invalid tracer annulus: %g to %g", t.InnerRadius, t.OuterRadius)
+	}
+	return nil
+}
+
+// Omega returns the orbital angular velocity of the binary. Under the 2D force
+// law the relative acceleration is 2G(m1+m2)/d, so ω = √(2G(m1+m2)) / d
+func (t ThreeBody) Omega() float64 {
+	return math.Sqrt(2*t.G*(t.PrimaryMass+t.SecondaryMass)) / t.Separation
+}
+
+// Masses returns the primary and the secondary at their fixed positions in the
+// co-rotating frame
+func (t ThreeBody) Masses() []physics.PointMass {
+	mu := t.SecondaryMass / (t.PrimaryMass + t.SecondaryMass)
+	return []physics.PointMass{
+		{X: -mu * t.Separation, Mass: t.PrimaryMass, Softening: t.Softening},
+		{X: (1 - mu) * t.Separation, Mass: t.SecondaryMass, Softening: t.Softening},
+	}
+}
+
+// LagrangePoints returns L1 to L5 of the unsoftened masses in the co-rotating
+// frame: L1 between the masses, L2 beyond the secondary, L3 beyond the
+// primary, and L4 and L5 at the apexes of the equilateral triangles on the
+// masses, L4 on the +Z side. The equilateral points hold for any central force
+// law; the collinear points are roots of the axial effective force
+func (t ThreeBody) LagrangePoints() [5]physics.Vec3 {
+	masses := t.Masses()
+	x1, x2 := masses[0].X, masses[1].X
+	omega2 := t.Omega() * t.Omega()
+	force := func(x float64) float64 {
+		f := omega2 * x
+		for _, m := range masses {
+			f -= 2 * t.G * m.Mass / (x - m.X)
+		}
+		return f
+	}
+
+	// The effective force rises monotonically between the poles at the masses
+	eps := 1e-9 * t.Separation
+	l1 := bisect(force, x1+eps, x2-eps)
+	l2 := bisect(force, x2+eps, x2+t.Separation)
+	l3 := bisect(force, x1-2*t.Separation, x1-eps)
+
+	apexX := (x1 + x2) / 2
+	apexZ := t.Separation * math.Sqrt(3) / 2
+	return [5]physics.Vec3{
+		physics.NewVec3(l1, 0, 0),
+		physics.NewVec3(l2, 0, 0),
+		physics.NewVec3(l3, 0, 0),
+		physics.NewVec3(apexX, 0, apexZ),
+		physics.NewVec3(apexX, 0, -apexZ),
+	}
+}
+
+// bisect returns the root of f between lo and hi, where f changes sign
+func bisect(f func(float64) float64, lo, hi float64) float64 {
+	negativeLo := f(lo) < 0
+	for i := 0; i < 200 && hi-lo > 1e-12*math.Max(1, math.Abs(lo)); i++ {
+		mid := (lo + hi) / 2
+		if (f(mid) < 0) == negativeLo {
+			lo = mid
+		} else {
+			hi = mid
+		}
+	}
+	return (lo + hi) / 2
+}
+
+// Build creates the scenario: the tracers with their velocities in the
+// co-rotating frame, the external potential of the binary and the frame's
+// pseudo-forces, and markers at the Lagrange points
+func (t ThreeBody) Build(rng *rand.Rand) (*Scenario, error) {
+	if err := t.Validate(); err != nil {
+		return nil, err
+	}
+	omega := t.Omega()
+	masses := t.Masses()
+
+	// Tracers start on circular orbits about the total mass, whose speed is
+	// the same at every radius under the 2D force law, co-rotating with the
+	// binary. In the rotating frame the velocity is (v/r - ω)(z, -x)
+	speed := math.Sqrt(2 * t.G * (t.PrimaryMass + t.SecondaryMass))
+	inner2, outer2 := t.InnerRadius*t.InnerRadius, t.OuterRadius*t.OuterRadius
+	particles := make([]*physics.Particle, t.Tracers)
+	for i := range particles {
+		r := math.Sqrt(inner2 + rng.Float64()*(outer2-inner2))
+		if r == 0 {
+			particles[i] = physics.NewTracer(0, 0, 0, 0)
+			continue
+		}
+		theta := 2 * math.Pi * rng.Float64()
+		x, z := r*math.Cos(theta), r*math.Sin(theta)
+		k := speed/r - omega
+		particles[i] = physics.NewTracer(x, z, k*z, -k*x)
+	}
+
+	frame := physics.RotatingFrame{Omega: omega}
+	forces := append([]physics.Force{physics.ExternalForce{Potential: physics.PointMasses{G: t.G, Masses: masses}}}, frame.Forces()...)
+
+	points := t.LagrangePoints()
+	markers := make([]Marker, len(points))
+	for i, p := range points {
+		markers[i] = Marker{Label: fmt.Sprintf("L%d", i+1), Position: p}
+	}
+
+	return &Scenario{
+		Particles: particles,
+		Forces:    forces,
+		Masses:    masses,
+		Markers:   markers,
+	}, nil
+}
@@ -0,0 +1,161 @@
+package scenario
+
+import (
+	"math"
+	"math/rand"
+	"relativity_simulation_2d/internal/physics"
+	"testing"
+)
+
+// TestLagrangePointsBalance tests that the gravity of the binary balances the
+// centrifugal force at each Lagrange point
+func TestLagrangePointsBalance(t *testing.T) {
+	for _, secondary := range []float64{1, 10, 100} {
+		tb := ThreeBody{PrimaryMass: 100, SecondaryMass: secondary, Separation: 40, G: 1}
+		potential := physics.PointMasses{G: tb.G, Masses: tb.Masses()}
+		omega2 := tb.Omega() * tb.Omega()
+		for i, p := range tb.LagrangePoints() {
+			ax, az := potential.Acceleration(p.X, p.Z)
+			ax += omega2 * p.X
+			az += omega2 * p.Z
+			if math.Hypot(ax, az) > 1e-9*omega2*tb.Separation {
+				t.Errorf("Secondary %g: L%d at %+v has residual acceleration (%g, %g)", secondary, i+1, p, ax, az)
+			}
+		}
+	}
+}
+
+// TestLagrangePointsOrder tests where the collinear points lie relative to
+// the masses
+func TestLagrangePointsOrder(t *testing.T) {
+	tb := ThreeBody{PrimaryMass: 100, SecondaryMass: 5, Separation: 40, G: 1}
+	masses := tb.Masses()
+	l := tb.LagrangePoints()
+	if !(masses[0].X < l[0].X && l[0].X < masses[1].X) {
+		t.Errorf("Expected L1 between the masses, got %g", l[0].X)
+	}
+	if !(l[1].X > masses[1].X) {
+		t.Errorf("Expected L2 beyond the secondary, got %g", l[1].X)
+	}
+	if !(l[2].X < masses[0].X) {
+		t.Errorf("Expected L3 beyond the primary, got %g", l[2].X)
+	}
+	if l[3].Z <= 0 || l[4].Z != -l[3].Z {
+		t.Errorf("Expected L4 and L5 mirrored across the axis, got %+v and %+v", l[3], l[4])
+	}
+	for i := 3; i < 5; i++ {
+		for _, m := range masses {
+			if d := math.Hypot(l[i].X-m.X, l[i].Z-m.Z); math.Abs(d-tb.Separation) > 1e-9 {
+				t.Errorf("Expected L%d one separation from each mass, got %g", i+1, d)
+			}
+		}
+	}
+}
+
+// TestThreeBodyCenterOfMass tests that the masses orbit about the origin
+func TestThreeBodyCenterOfMass(t *testing.T) {
+	tb := DefaultThreeBody(256, 128, 1, 2, 0)
+	masses := tb.Masses()
+	if com := masses[0].Mass*masses[0].X + masses[1].Mass*masses[1].X; math.Abs(com) > 1e-9 {
+		t.Errorf("Expected the center of mass at the origin, got mass moment %g", com)
+	}
+	if d := masses[1].X - masses[0].X; math.Abs(d-32) > 1e-12 {
+		t.Errorf("Expected a separation of a quarter of the box, got %g", d)
+	}
+	if period := 2 * math.Pi / tb.Omega(); math.Abs(period-DefaultThreeBodyPeriod) > 1e-9 {
+		t.Errorf("Expected period %g, got %g", DefaultThreeBodyPeriod, period)
+	}
+}
+
+// TestThreeBodyBuild tests the tracers, forces and markers of the scenario
+func TestThreeBodyBuild(t *testing.T) {
+	tb := DefaultThreeBody(256, 256, 1, 1, 500)
+	s, err := tb.Build(rand.New(rand.NewSource(1)))
+	if err != nil {
+		t.Fatalf("Build failed: %v", err)
+	}
+	if len(s.Particles) != 500 || len(s.Masses) != 2 || len(s.Markers) != 5 || len(s.Forces) != 3 {
+		t.Fatalf("Expected 500 tracers, 2 masses, 5 markers and 3 forces, got %d, %d, %d and %d",
+			len(s.Particles), len(s.Masses), len(s.Markers), len(s.Forces))
+	}
+	if s.Markers[0].Label != "L1" || s.Markers[4].Label != "L5" {
+		t.Errorf("Expected markers L1 to L5, got %q to %q", s.Markers[0].Label, s.Markers[4].Label)
+	}
+
+	// Seen from the inertial frame the tracers circle at the circular speed
+	speed := math.Sqrt(2 * tb.G * (tb.PrimaryMass + tb.SecondaryMass))
+	physics.RotatingFrame{Omega: tb.Omega()}.ToInertial(s.Particles)
+	for i, p := range s.Particles {
+		if !p.IsTracer() {
+			t.Fatalf("Particle %d is not a tracer", i)
+		}
+		r := math.Hypot(p.Position.X, p.Position.Z)
+		if r < tb.InnerRadius || r > tb.OuterRadius {
+			t.Fatalf("Tracer %d at radius %g outside [%g, %g]", i, r, tb.InnerRadius, tb.OuterRadius)
+		}
+		if math.Abs(p.Velocity.Length()-speed) > 1e-9 || math.Abs(p.Velocity.X*p.Position.X+p.Velocity.Z*p.Position.Z) > 1e-9*r*speed {
+			t.Fatalf("Tracer %d velocity %+v is not circular at speed %g", i, p.Velocity, speed)
+		}
+	}
+}
+
+// TestThreeBodyValidate tests rejection of unusable parameters
+func TestThreeBodyValidate(t *testing.T) {
+	valid := DefaultThreeBody(128, 128, 1, 1, 10)
+	if err := valid.Validate(); err != nil {
+		t.Fatalf("Expected the default to be valid, got %v", err)
+	}
+	tests := []struct {
+		name   string
+		modify func(*ThreeBody)
+	}{
+		{"zero secondary", func(tb *ThreeBody) { tb.SecondaryMass = 0 }},
+		{"secondary heavier", func(tb *ThreeBody) { tb.SecondaryMass = 2 * tb.PrimaryMass }},
+		{"zero separation", func(tb *ThreeBody) { tb.Separation = 0 }},
+		{"zero G", func(tb *ThreeBody) { tb.G = 0 }},
+		{"negative softening", func(tb *ThreeBody) { tb.Softening = -1 }},
+		{"negative tracers", func(tb *ThreeBody) { tb.Tracers = -1 }},
+		{"inverted annulus", func(tb *ThreeBody) { tb.InnerRadius = tb.OuterRadius + 1 }},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tb := valid
+			tt.modify(&tb)
+			if err := tb.Validate(); err == nil {
+				t.Error("Expected an error")
+			}
+			if _, err := tb.Build(rand.New(rand.NewSource(1))); err == nil {
+				t.Error("Expected Build to fail")
+			}
+		})
+	}
+}
+
+// TestThreeBodyForcesHoldLagrangePoints tests that the scenario's forces keep
+// tracers parked at the Lagrange points at rest
+func TestThreeBodyForcesHoldLagrangePoints(t *testing.T) {
+	tb := DefaultThreeBody(256, 256, 1, 1, 0)
+	tb.Softening = 0
+	s, err := tb.Build(rand.New(rand.NewSource(1)))
+	if err != nil {
+		t.Fatalf("Build failed: %v", err)
+	}
+	var tracers []*physics.Particle
+	for _, m := range s.Markers {
+		tracers = append(tracers, physics.NewTracer(m.Position.X, m.Position.Z, 0, 0))
+	}
+
+	const dt = 0.01
+	for step := 0; step < 100; step++ {
+		physics.KickForces(tracers, s.Forces, dt/2)
+		for _, p := range tracers {
+			p.Position = p.Position.Add(p.Velocity.Scale(dt))
+		}
+		physics.KickForces(tracers, s.Forces, dt/2)
+	}
+	for i, p := range tracers {
+		if d := p.Position.Sub(s.Markers[i].Position).Length(); d > 1e-6 {
+			t.Errorf("%s drifted by %g", s.Markers[i].Label, d)
+		}
+	}
+}
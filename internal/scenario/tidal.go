@@ -0,0 +1,254 @@
+package scenario
+
+import (
+	"fmt"
+	"math"
+	"math/rand"
+	"relativity_simulation_2d/internal/physics"
+	"relativity_simulation_2d/internal/verification"
+	"sort"
+)
+
+// DefaultTidalPeriod is the period of a circular orbit at the default
+// apocenter, in simulation time
+const DefaultTidalPeriod = 30.0
+
+// TidalDisruption is a self-gravitating Plummer cluster on a plunging orbit
+// about a fixed analytic host mass at the origin. The cluster starts at
+// apocenter on the +X axis, moving towards -Z, and is torn apart into tidal
+// tails as it passes pericenter
+type TidalDisruption struct {
+	HostMass      float64
+	HostSoftening float64
+	ClusterMass   float64
+	ClusterRadius float64 // Plummer scale radius of the cluster
+	Particles     int
+	Apocenter     float64 // Initial distance of the cluster from the host
+	Pericenter    float64 // Closest approach of the cluster's orbit about the host alone
+	G             float64
+}
+
+// DefaultTidalDisruption returns a cluster of a twentieth of the host's mass,
+// starting at 0.35 of the box size and plunging to 0.05 of it. Its scale
+// radius of 1/80 of the box lies well within its tidal radius at apocenter and
+// outside it at pericenter. The box is width×depth cells of size dx
+func DefaultTidalDisruption(width, depth int, dx, gravitationalConstant float64, particles int) TidalDisruption {
+	size := float64(min(width, depth)) * dx
+	apocenter := 0.35 * size
+	circular := 2 * math.Pi * apocenter / DefaultTidalPeriod
+	host := circular * circular / (2 * gravitationalConstant)
+	return TidalDisruption{
+		HostMass:      host,
+		HostSoftening: dx,
+		ClusterMass:   host / 20,
+		ClusterRadius: size / 80,
+		Particles:     particles,
+		Apocenter:     apocenter,
+		Pericenter:    0.05 * size,
+		G:             gravitationalConstant,
+	}
+}
+
+// Validate checks that the masses, sizes and orbit are usable
+func (t TidalDisruption) Validate() error {
+	if !(t.HostMass > 0) || !(t.ClusterMass > 0) {
+		return fmt.Errorf("invalid masses: host %g, cluster %g", t.HostMass, t.ClusterMass)
+	}
+	if t.HostSoftening < 0 {
+		return fmt.Errorf("invalid host softening: %g", t.HostSoftening)
+	}
+	if !(t.ClusterRadius > 0) {
+		return fmt.Errorf("invalid cluster radius: %g", t.ClusterRadius)
+	}
+	if t.Particles < 1 {
+		return fmt.Errorf("invalid particle count: %d", t.Particles)
+	}
+	if !(t.Pericenter > 0) || !(t.Apocenter > t.Pericenter) || math.IsInf(t.Apocenter, 0) {
+		return fmt.Errorf("invalid orbit: pericenter %g, apocenter %g", t.Pericenter, t.Apocenter)
+	}
+	if !(t.G > 0) {
+		return fmt.Errorf("invalid gravitational constant: %g", t.G)
+	}
+	return nil
+}
+
+// ApocenterSpeed returns the tangential speed at apocenter of the orbit that
+// reaches Pericenter in the host's potential 2GM ln r, from conservation of
+// energy and angular momentum:
+//
+//	v² = 4GM ln(ra/rp) / ((ra/rp)² - 1)
+func (t TidalDisruption) ApocenterSpeed() float64 {
+	ratio := t.Apocenter / t.Pericenter
+	return math.Sqrt(4 * t.G * t.HostMass * math.Log(ratio) / (ratio*ratio - 1))
+}
+
+// TidalRadius returns the Jacobi radius of a cluster of mass m at distance d
+// from the host. The host's tidal acceleration (Ω² - d²Φ/dr²)r = 4GMr/d² balances
+// the cluster's attraction 2Gm/r at r = d √(m / 2M)
+func (t TidalDisruption) TidalRadius(distance, mass float64) float64 {
+	return distance * math.Sqrt(mass/(2*t.HostMass))
+}
+
+// Build creates the scenario: the cluster particles and the host's external
+// potential. The cluster is sampled from the Plummer surface density with
+// isotropic Gaussian velocities of dispersion GM/2 per component, which is the
+// exact equilibrium of a Plummer disk under the 2D force law
+func (t TidalDisruption) Build(rng *rand.Rand) (*Scenario, error) {
+	if err := t.Validate(); err != nil {
+		return nil, err
+	}
+
+	// Flattening the 3D sample keeps the projected Plummer profile
+	particles := verification.SamplePlummer(t.Particles, t.ClusterRadius, t.ClusterMass, rng)
+	sigma := math.Sqrt(t.G * t.ClusterMass / 2)
+	var mean physics.Vec3
+	for _, p := range particles {
+		p.Position.Y = 0
+		p.Velocity = physics.NewVec3(sigma*rng.NormFloat64(), 0, sigma*rng.NormFloat64())
+		p.Radius = physics.TracerRadius
+		mean = mean.Add(p.Velocity)
+	}
+
+	// Remove the sampled bulk motion and put the cluster on its orbit
+	orbit := physics.NewVec3(0, 0, -t.ApocenterSpeed())
+	drift := orbit.Sub(mean.Scale(1 / float64(len(particles))))
+	for _, p := range particles {
+		p.Position.X += t.Apocenter
+		p.Velocity = p.Velocity.Add(drift)
+	}
+
+	masses := []physics.PointMass{{Mass: t.HostMass, Softening: t.HostSoftening}}
+	cluster := physics.Group{Name: "cluster", Members: make([]int, len(particles))}
+	for i := range cluster.Members {
+		cluster.Members[i] = i
+	}
+	return &Scenario{
+		Particles: particles,
+		Forces:    []physics.Force{physics.ExternalForce{Potential: physics.PointMasses{G: t.G, Masses: masses}}},
+		Masses:    masses,
+		Groups:    []physics.Group{cluster},
+	}, nil
+}
+
+// TidalStats measures the disruption of the cluster
+type TidalStats struct {
+	Center      physics.Vec3 // Density center of the cluster
+	Distance    float64      // Distance of the center from the host
+	TidalRadius float64      // Jacobi radius of the bound mass at Distance
+	BoundMass   float64      // Mass within the tidal radius
+	MassLoss    float64      // Fraction of the cluster mass beyond the tidal radius
+	TailLength  float64      // 95th percentile distance of the stripped particles from the center (0 = none stripped)
+}
+
+// Measure locates the cluster and splits it into the bound core and the
+// stripped tails at this instant. Near pericenter the tidal radius can shrink
+// to zero, reporting the whole cluster as stripped, and part of it may be
+// recaptured as it recedes. Distances use the nearest periodic image in a
+// width×depth box (0 = not periodic along that axis)
+func (t TidalDisruption) Measure(particles []*physics.Particle, width, depth float64) TidalStats {
+	var stats TidalStats
+	var total float64
+	for _, p := range particles {
+		total += float64(p.Mass)
+	}
+	if total <= 0 {
+		return stats
+	}
+	stats.Center = densityCenter(particles, width, depth)
+	stats.Distance = math.Hypot(stats.Center.X, stats.Center.Z)
+
+	distances := make([]float64, len(particles))
+	for i, p := range particles {
+		dx := periodicDelta(p.Position.X-stats.Center.X, width)
+		dz := periodicDelta(p.Position.Z-stats.Center.Z, depth)
+		distances[i] = math.Hypot(dx, dz)
+	}
+
+	// The tidal radius depends on the bound mass, which depends on the
+	// radius; a few fixed-point iterations from the total mass converge
+	bound := total
+	for iter := 0; iter < 20; iter++ {
+		stats.TidalRadius = t.TidalRadius(stats.Distance, bound)
+		next := 0.0
+		for i, p := range particles {
+			if distances[i] <= stats.TidalRadius {
+				next += float64(p.Mass)
+			}
+		}
+		if next == bound {
+			break
+		}
+		bound = next
+	}
+	stats.BoundMass = bound
+	stats.MassLoss = 1 - bound/total
+
+	var stripped []float64
+	for _, d := range distances {
+		if d > stats.TidalRadius {
+			stripped = append(stripped, d)
+		}
+	}
+	if len(stripped) > 0 {
+		sort.Float64s(stripped)
+		stats.TailLength = stripped[int(0.95*float64(len(stripped)-1))]
+	}
+	return stats
+}
+
+// densityCenter finds the center of the densest clump with shrinking spheres:
+// starting from the center of mass, it repeatedly recenters on the particles
+// within a sphere 25% smaller, until fewer than a tenth of them (at least
+// ten) would remain
+func densityCenter(particles []*physics.Particle, width, depth float64) physics.Vec3 {
+	center, _ := shrinkStep(particles, particles[0].Position, math.Inf(1), width, depth)
+	var radius float64
+	for _, p := range particles {
+		dx := periodicDelta(p.Position.X-center.X, width)
+		dz := periodicDelta(p.Position.Z-center.Z, depth)
+		radius = math.Max(radius, math.Hypot(dx, dz))
+	}
+
+	minCount := max(10, len(particles)/10)
+	for radius > 0 {
+		radius *= 0.75
+		next, count := shrinkStep(particles, center, radius, width, depth)
+		if count < minCount {
+			break
+		}
+		center = next
+	}
+	return center
+}
+
+// shrinkStep returns the center of mass of the particles within radius of
+// center and how many there are. With none inside it returns center
+func shrinkStep(particles []*physics.Particle, center physics.Vec3, radius, width, depth float64) (physics.Vec3, int) {
+	var sumX, sumZ, mass float64
+	var count int
+	for _, p := range particles {
+		dx := periodicDelta(p.Position.X-center.X, width)
+		dz := periodicDelta(p.Position.Z-center.Z, depth)
+		if dx*dx+dz*dz > radius*radius {
+			continue
+		}
+		m := float64(p.Mass)
+		sumX += m * dx
+		sumZ += m * dz
+		mass += m
+		count++
+	}
+	if mass == 0 {
+		return center, count
+	}
+	return physics.NewVec3(center.X+sumX/mass, 0, center.Z+sumZ/mass), count
+}
+
+// periodicDelta maps a separation onto its nearest periodic image
+// (period <= 0 = not periodic)
+func periodicDelta(d, period float64) float64 {
+	if period <= 0 {
+		return d
+	}
+	return d - period*math.Round(d/period)
+}
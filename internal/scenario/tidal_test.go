@@ -0,0 +1,151 @@
+package scenario
+
+import (
+	"math"
+	"math/rand"
+	"relativity_simulation_2d/internal/physics"
+	"testing"
+)
+
+// TestTidalApocenterSpeed tests that a test particle launched at the
+// apocenter speed reaches the requested pericenter
+func TestTidalApocenterSpeed(t *testing.T) {
+	td := DefaultTidalDisruption(256, 256, 1, 1, 1)
+	td.HostSoftening = 0
+	host := physics.ExternalForce{Potential: physics.PointMasses{G: td.G, Masses: []physics.PointMass{{Mass: td.HostMass}}}}
+	particles := []*physics.Particle{physics.NewTracer(td.Apocenter, 0, 0, -td.ApocenterSpeed())}
+
+	const dt = 0.0005
+	closest := math.Inf(1)
+	for step := 0; step < int(DefaultTidalPeriod/dt); step++ {
+		host.Kick(particles, dt/2)
+		p := particles[0]
+		p.Position = p.Position.Add(p.Velocity.Scale(dt))
+		host.Kick(particles, dt/2)
+		closest = math.Min(closest, math.Hypot(p.Position.X, p.Position.Z))
+	}
+	if math.Abs(closest-td.Pericenter) > 0.01*td.Pericenter {
+		t.Errorf("Expected pericenter %g, got %g", td.Pericenter, closest)
+	}
+}
+
+// TestTidalRadiusBalance tests that the tidal acceleration balances the
+// cluster's attraction at the tidal radius
+func TestTidalRadiusBalance(t *testing.T) {
+	td := TidalDisruption{HostMass: 50, G: 2}
+	const distance, mass = 30.0, 4.0
+	r := td.TidalRadius(distance, mass)
+	tidal := 4 * td.G * td.HostMass * r / (distance * distance)
+	self := 2 * td.G * mass / r
+	if math.Abs(tidal-self) > 1e-12*self {
+		t.Errorf("Tidal acceleration %g does not balance self-gravity %g at r = %g", tidal, self, r)
+	}
+}
+
+// TestTidalBuild tests the cluster's mass, orbit and flatness
+func TestTidalBuild(t *testing.T) {
+	td := DefaultTidalDisruption(256, 256, 1, 1, 2000)
+	s, err := td.Build(rand.New(rand.NewSource(3)))
+	if err != nil {
+		t.Fatalf("Build failed: %v", err)
+	}
+	if len(s.Particles) != 2000 || len(s.Masses) != 1 || len(s.Forces) != 1 {
+		t.Fatalf("Expected 2000 particles, the host and its force, got %d, %d and %d", len(s.Particles), len(s.Masses), len(s.Forces))
+	}
+	if len(s.Groups) != 1 || len(s.Groups[0].Members) != len(s.Particles) {
+		t.Errorf("Expected the whole cluster in one group, got %+v", s.Groups)
+	}
+
+	d := physics.ComputeDiagnostics(s.Particles)
+	if math.Abs(d.TotalMass-td.ClusterMass) > 1e-3*td.ClusterMass {
+		t.Errorf("Expected cluster mass %g, got %g", td.ClusterMass, d.TotalMass)
+	}
+	if vx, vz := d.MomentumX/d.TotalMass, d.MomentumZ/d.TotalMass; math.Abs(vx) > 1e-3 || math.Abs(vz+td.ApocenterSpeed()) > 1e-3 {
+		t.Errorf("Expected bulk velocity (0, %g), got (%g, %g)", -td.ApocenterSpeed(), vx, vz)
+	}
+	for i, p := range s.Particles {
+		if p.Position.Y != 0 || p.Velocity.Y != 0 {
+			t.Fatalf("Particle %d is out of the x-z plane: %+v", i, p)
+		}
+	}
+
+	// At apocenter the cluster sits well within its tidal radius
+	stats := td.Measure(s.Particles, 256, 256)
+	if math.Abs(stats.Distance-td.Apocenter) > 2 {
+		t.Errorf("Expected the cluster at distance %g, got %g", td.Apocenter, stats.Distance)
+	}
+	if stats.MassLoss > 0.1 {
+		t.Errorf("Expected under 10%% of the mass beyond the tidal radius at apocenter, got %g", stats.MassLoss)
+	}
+}
+
+// TestTidalMeasure tests mass loss and tail length on a core with a tail
+func TestTidalMeasure(t *testing.T) {
+	td := TidalDisruption{HostMass: 1e5, G: 1}
+	var particles []*physics.Particle
+
+	// A tight core of 80 particles at (40, 60), and 20 stripped particles
+	// strung out to 30 cells ahead of it, across the periodic boundary
+	for i := 0; i < 80; i++ {
+		angle := 2 * math.Pi * float64(i) / 80
+		particles = append(particles, physics.NewParticle(1, 40+0.5*math.Cos(angle), 0, 60+0.5*math.Sin(angle), 0, 0, 0))
+	}
+	for i := 1; i <= 20; i++ {
+		particles = append(particles, physics.NewParticle(1, 40, 0, wrap(60+1.5*float64(i), 128), 0, 0, 0))
+	}
+
+	stats := td.Measure(particles, 128, 128)
+	if stats.Center.Sub(physics.NewVec3(40, 0, 60)).Length() > 1e-9 {
+		t.Errorf("Expected the center at the core, got %+v", stats.Center)
+	}
+	if math.Abs(stats.MassLoss-0.2) > 1e-12 || stats.BoundMass != 80 {
+		t.Errorf("Expected 20%% mass loss and bound mass 80, got %g and %g", stats.MassLoss, stats.BoundMass)
+	}
+	if want := td.TidalRadius(math.Hypot(40, 60), 80); math.Abs(stats.TidalRadius-want) > 1e-12 {
+		t.Errorf("Expected tidal radius %g, got %g", want, stats.TidalRadius)
+	}
+
+	// The stripped particles lie 1.5 to 30 cells along the tail; the 95th
+	// percentile of 20 is the 19th, at 28.5
+	if math.Abs(stats.TailLength-28.5) > 1e-9 {
+		t.Errorf("Expected tail length 28.5, got %g", stats.TailLength)
+	}
+
+	if got := td.Measure(nil, 128, 128); got != (TidalStats{}) {
+		t.Errorf("Expected empty stats without particles, got %+v", got)
+	}
+}
+
+// wrap maps z into the periodic box [-period/2, period/2)
+func wrap(z, period float64) float64 {
+	return z - period*math.Floor(z/period+0.5)
+}
+
+// TestTidalValidate tests rejection of unusable parameters
+func TestTidalValidate(t *testing.T) {
+	valid := DefaultTidalDisruption(128, 128, 1, 1, 10)
+	if err := valid.Validate(); err != nil {
+		t.Fatalf("Expected the default to be valid, got %v", err)
+	}
+	tests := []struct {
+		name   string
+		modify func(*TidalDisruption)
+	}{
+		{"zero host", func(td *TidalDisruption) { td.HostMass = 0 }},
+		{"zero cluster", func(td *TidalDisruption) { td.ClusterMass = 0 }},
+		{"negative softening", func(td *TidalDisruption) { td.HostSoftening = -1 }},
+		{"zero radius", func(td *TidalDisruption) { td.ClusterRadius = 0 }},
+		{"no particles", func(td *TidalDisruption) { td.Particles = 0 }},
+		{"pericenter beyond apocenter", func(td *TidalDisruption) { td.Pericenter = 2 * td.Apocenter }},
+		{"zero G", func(td *TidalDisruption) { td.G = 0 }},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			td := valid
+			tt.modify(&td)
+			if err := td.Validate(); err == nil {
+				t.Error("Expected an error")
+			}
+		})
+	}
+}
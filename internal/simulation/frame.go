@@ -0,0 +1,108 @@
+package simulation
+
+import (
+	"relativity_simulation_2d/internal/physics"
+	"sync"
+)
+
+// Frame is a published copy of the simulation state after a completed step:
+// everything the renderer draws. A frame is never modified while it is
+// visible to readers
+type Frame struct {
+	Step            int64
+	SimTime         float64
+	Particles       []physics.Particle
+	Positions       []float32 // Particle positions as x, y, z float32 triples, for drawing without converting each one
+	PotentialGrid   physics.Grid
+	MassDensityGrid physics.Grid
+	AccelFieldX     physics.Grid
+	AccelFieldZ     physics.Grid
+}
+
+// newFrame allocates a frame sized for the simulation
+func newFrame(numParticles, width, height int) *Frame {
+	return &Frame{
+		Particles:       make([]physics.Particle, numParticles),
+		PotentialGrid:   physics.NewGrid(width, height),
+		MassDensityGrid: physics.NewGrid(width, height),
+		AccelFieldX:     physics.NewGrid(width, height),
+		AccelFieldZ:     physics.NewGrid(width, height),
+	}
+}
+
+// ParticlePointers returns pointers into the frame's particles, for the
+// physics functions that take []*physics.Particle. The particles must not be
+// modified
+func (f *Frame) ParticlePointers() []*physics.Particle {
+	pointers := make([]*physics.Particle, len(f.Particles))
+	for i := range f.Particles {
+		pointers[i] = &f.Particles[i]
+	}
+	return pointers
+}
+
+// FrameBuffer double-buffers the published state so that rendering can read
+// the last completed step while the next one overwrites the live fields.
+// Publish must be called from one goroutine at a time; Read from any
+type FrameBuffer struct {
+	mu     sync.RWMutex // Guards front against readers
+	frames [2]*Frame
+	front  int // Index of the frame visible to readers
+}
+
+// NewFrameBuffer allocates a buffer for a width×height grid. Particle storage
+// grows as needed
+func NewFrameBuffer(numParticles, width, height int) *FrameBuffer {
+	b := &FrameBuffer{}
+	for i := range b.frames {
+		b.frames[i] = newFrame(numParticles, width, height)
+	}
+	return b
+}
+
+// Publish copies the state into the back buffer and makes it the front
+// buffer. Readers hold the buffer for reading while they use the front frame,
+// so the swap waits for them and the back frame is never read while it is
+// being written
+func (b *FrameBuffer) Publish(step int64, simTime float64, particles []*physics.Particle, potential, massDensity, accelX, accelZ physics.Grid) {
+	back := b.frames[1-b.front]
+
+	back.Step = step
+	back.SimTime = simTime
+	if cap(back.Particles) < len(particles) {
+		back.Particles = make([]physics.Particle, len(particles))
+	}
+	back.Particles = back.Particles[:len(particles)]
+	for i, p := range particles {
+		back.Particles[i] = *p
+	}
+	back.Positions = physics.PackParticlePositions(back.Positions, particles)
+	physics.CopyGrid(back.PotentialGrid, potential)
+	physics.CopyGrid(back.MassDensityGrid, massDensity)
+	physics.CopyGrid(back.AccelFieldX, accelX)
+	physics.CopyGrid(back.AccelFieldZ, accelZ)
+
+	b.mu.Lock()
+	b.front = 1 - b.front
+	b.mu.Unlock()
+}
+
+// Read calls fn with the most recently published frame. The frame must not
+// be modified or retained after fn returns; publication (not computation)
+// waits while fn runs, so fn should be short
+func (b *FrameBuffer) Read(fn func(frame *Frame)) {
+	b.mu.RLock()
+	defer b.mu.RUnlock()
+	fn(b.frames[b.front])
+}
+
+// publish publishes the current state to readers
+func (s *Simulation) publish() {
+	s.frames.Publish(s.stepCount, s.simTime, s.Particles, s.PotentialGrid, s.MassDensityGrid, s.AccelFieldX, s.AccelFieldZ)
+}
+
+// ReadFrame calls fn with the most recently published frame, as
+// FrameBuffer.Read. Safe to call from any goroutine
+func (s *Simulation) ReadFrame(fn func(frame *Frame)) {
+	s.frames.Read(fn)
+}
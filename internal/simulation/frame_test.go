@@ -0,0 +1,55 @@
+package simulation
+
+import (
+	"relativity_simulation_2d/internal/physics"
+	"testing"
+)
+
+// TestFrameBufferPublish tests that a published frame holds a copy of every
+// visualization field, unaffected by later writes to the live state
+func TestFrameBufferPublish(t *testing.T) {
+	const width, height = 8, 6
+	particles := []*physics.Particle{physics.NewParticle(2, 1, 0, 1, 0, 0, 0)}
+	grids := make([]physics.Grid, 4)
+	for k := range grids {
+		grids[k] = physics.NewGrid(width, height)
+		grids[k][1][2] = float64(k + 1)
+	}
+
+	buffer := NewFrameBuffer(0, width, height)
+	buffer.Publish(7, 0.5, particles, grids[0], grids[1], grids[2], grids[3])
+
+	// The next step overwrites the live state
+	particles[0].Position.X = 9
+	for k := range grids {
+		grids[k][1][2] = -1
+	}
+
+	buffer.Read(func(f *Frame) {
+		if f.Step != 7 || f.SimTime != 0.5 {
+			t.Errorf("Expected step 7 at t=0.5, got %d at t=%g", f.Step, f.SimTime)
+		}
+		if len(f.Particles) != 1 || f.Particles[0].Position.X != 1 {
+			t.Errorf("Expected the particle as published, got %+v", f.Particles)
+		}
+		for k, g := range []physics.Grid{f.PotentialGrid, f.MassDensityGrid, f.AccelFieldX, f.AccelFieldZ} {
+			if g[1][2] != float64(k+1) {
+				t.Errorf("Field %d: expected %d, got %g", k, k+1, g[1][2])
+			}
+		}
+		if len(f.Positions) != 3 || f.Positions[0] != 1 {
+			t.Errorf("Expected the packed position as published, got %v", f.Positions)
+		}
+		if pointers := f.ParticlePointers(); len(pointers) != 1 || pointers[0] != &f.Particles[0] {
+			t.Error("Expected pointers into the frame's particles")
+		}
+	})
+
+	// A second publication swaps in the other frame
+	buffer.Publish(8, 0.6, particles, grids[0], grids[1], grids[2], grids[3])
+	buffer.Read(func(f *Frame) {
+		if f.Step != 8 || f.Particles[0].Position.X != 9 || f.PotentialGrid[1][2] != -1 {
+			t.Errorf("Expected the second publication, got step %d", f.Step)
+		}
+	})
+}
@@ -0,0 +1,40 @@
+package simulation
+
+// observer is a registered step callback
+type observer struct {
+	fn     func(step int64)
+	stride int64
+}
+
+// Observers calls registered functions after steps, each every stride steps.
+// The zero value has no observers
+type Observers struct {
+	list []observer
+}
+
+// Register calls fn after every step whose number is a multiple of stride
+// (stride < 1 = every step), after the observers registered before it
+func (o *Observers) Register(stride int64, fn func(step int64)) {
+	o.list = append(o.list, observer{fn: fn, stride: max(stride, 1)})
+}
+
+// Notify calls the observers due after the given completed step
+func (o *Observers) Notify(step int64) {
+	for _, obs := range o.list {
+		if step%obs.stride == 0 {
+			obs.fn(step)
+		}
+	}
+}
+
+// Observer is called after a step of a Simulation with the number of
+// completed steps
+type Observer func(step int64, sim *Simulation)
+
+// RegisterObserver calls fn after every step whose number is a multiple of
+// stride (stride < 1 = every step), e.g. to record a measurement. Observers
+// run on the stepping goroutine once the step is published, so they may read
+// the exported fields, but must not step the simulation themselves
+func (s *Simulation) RegisterObserver(stride int64, fn Observer) {
+	s.observers.Register(stride, func(step int64) { fn(step, s) })
+}
@@ -1,57 +1,170 @@
 package simulation
 
 import (
+	"math/rand"
 	"relativity_simulation_2d/internal/config"
 	"relativity_simulation_2d/internal/gpu"
 	"relativity_simulation_2d/internal/physics"
+	"relativity_simulation_2d/internal/scenario"
+	"sync"
+	"time"
 )
 
-// Simulation holds the entire state of the GR simulation
+// Simulation holds the entire state of the GR simulation.
+//
+// Concurrency contract: Update/Step (and SetGPU/CleanupGPU) advance the
+// simulation and are serialized internally; the exported fields belong to the
+// stepping goroutine and must not be touched elsewhere. The Get* methods and
+// ReadFrame may be called from any goroutine (e.g. a render loop) while
+// another goroutine steps; they observe the state published at the end of the
+// last completed step via a double-buffered Frame
 type Simulation struct {
 	Config           *config.Config
 	Particles        []*physics.Particle
-	PotentialGrid    [][]float64 // Stores the scalar potential Φ (proportional to h_00)
-	MassDensityGrid  [][]float64 // Stores the mass density ρ
-	AccelFieldX      [][]float64 // Stores the X component of the acceleration field
-	AccelFieldZ      [][]float64 // Stores the Z component of the acceleration field
-	gpu              *gpu.GPU    // Optional GPU context for acceleration (nil = CPU-only)
-	gpuErrorOccurred bool        // Tracks if GPU error occurred
+	PotentialGrid    physics.Grid      // Stores the scalar potential Φ (proportional to h_00)
+	MassDensityGrid  physics.Grid      // Stores the mass density ρ
+	AccelFieldX      physics.Grid      // Stores the X component of the acceleration field
+	AccelFieldZ      physics.Grid      // Stores the Z component of the acceleration field
+	gpu              *gpu.GPU          // Optional GPU context for acceleration (nil = CPU-only)
+	gpuErrorOccurred bool              // Tracks if GPU error occurred
+	stepCount        int64             // Number of completed simulation steps
+	simTime          float64           // Elapsed simulation time
+	precision        physics.Precision // CPU grid/FFT precision
+	mesh             *physics.Mesh     // Cell size and deposition of this simulation's grids
+
+	// Settings of the configuration, kept on the simulation so that
+	// simulations with different configurations step side by side
+	forces []physics.Force       // Kicked for half a step on either side of gravity: scenario, rotating frame, image correction, sponge
+	pm     *gpu.FallbackManager  // Runs PM steps on the CPU backend with incremental deposits (nil = full deposits)
+	sparse *physics.SparseSolver // Steps PM gravity on the tiles holding mass (nil = dense grids)
+
+	stepMu    sync.Mutex   // Serializes stepping
+	frames    *FrameBuffer // Double-buffered published state
+	observers Observers    // Called after each published step
 }
 
 // NewSimulation creates and initializes a new simulation instance
 func NewSimulation(cfg *config.Config) *Simulation {
+	return NewSimulationWithParticles(cfg, nil)
+}
+
+// NewSimulationWithParticles creates a simulation starting from the given
+// particles (e.g. imported initial conditions) instead of random ones. The
+// simulation takes ownership of the slice; nil falls back to the configured
+// scenario, or random initialization. Every physics setting of cfg is
+// applied to this simulation only
+func NewSimulationWithParticles(cfg *config.Config, particles []*physics.Particle) *Simulation {
 	sim := &Simulation{
 		Config:          cfg,
-		Particles:       make([]*physics.Particle, cfg.NumParticles),
-		PotentialGrid:   make([][]float64, cfg.SimulationWidth),
-		MassDensityGrid: make([][]float64, cfg.SimulationWidth),
-		AccelFieldX:     make([][]float64, cfg.SimulationWidth),
-		AccelFieldZ:     make([][]float64, cfg.SimulationWidth),
+		PotentialGrid:   physics.NewGrid(cfg.SimulationWidth, cfg.SimulationDepth),
+		MassDensityGrid: physics.NewGrid(cfg.SimulationWidth, cfg.SimulationDepth),
+		AccelFieldX:     physics.NewGrid(cfg.SimulationWidth, cfg.SimulationDepth),
+		AccelFieldZ:     physics.NewGrid(cfg.SimulationWidth, cfg.SimulationDepth),
 	}
-
-	for i := range sim.PotentialGrid {
-		sim.PotentialGrid[i] = make([]float64, cfg.SimulationDepth)
-		sim.MassDensityGrid[i] = make([]float64, cfg.SimulationDepth)
-		sim.AccelFieldX[i] = make([]float64, cfg.SimulationDepth)
-		sim.AccelFieldZ[i] = make([]float64, cfg.SimulationDepth)
+	sim.precision, _ = physics.ParsePrecision(cfg.Precision) // Unknown values fall back to float64
+	sim.mesh = &physics.Mesh{CellSize: cfg.CellSize, Deterministic: cfg.Deterministic}
+	if cfg.IncrementalDeposit {
+		sim.pm = gpu.NewFallbackManager()
+		sim.pm.RegisterBackend(gpu.ProcessorTypeCPU, gpu.CPUBackend{
+			Precision:   sim.precision,
+			Incremental: physics.NewIncrementalDeposit(sim.mesh),
+			Mesh:        sim.mesh,
+		})
+		sim.pm.SetMode(gpu.ModeCPU)
+	}
+	if cfg.SparseGrid {
+		sim.sparse = physics.NewSparseSolver()
+		sim.sparse.Crop = cfg.CropSolve
+		sim.sparse.Padding = cfg.CropPadding
+		sim.sparse.CellSize = cfg.CellSize
 	}
 
 	// Initialize particles using extracted function
-	sim.Particles = physics.InitializeParticles(cfg.NumParticles, float64(cfg.SimulationWidth), float64(cfg.SimulationDepth))
+	width, depth := cfg.DomainSize()
+	switch {
+	case particles != nil:
+		sim.Particles = particles
+	case sim.loadScenario():
+	case cfg.Seed != 0:
+		sim.Particles = physics.InitializeParticlesWithSeed(cfg.NumParticles, width, depth, cfg.Seed)
+	default:
+		sim.Particles = physics.InitializeParticles(cfg.NumParticles, width, depth)
+	}
 
 	// Optionally add a large central mass (uncomment to enable)
 	// sim.Particles = physics.InitializeParticlesWithCentralMass(cfg.NumParticles, float64(cfg.SimulationWidth), float64(cfg.SimulationDepth), 1000)
 
+	if model, err := physics.ParseRadiusModel(cfg.RadiusModel, cfg.ParticleDensity, cfg.ParticleRadius); err == nil {
+		physics.ApplyRadius(sim.Particles, model)
+	}
+	if n := cfg.TracerSheet; n > 0 {
+		_, tracers := physics.NewTracerSheet(len(sim.Particles), n, n, cfg.SimulationWidth, cfg.SimulationDepth, sim.mesh.Dx())
+		sim.Particles = append(sim.Particles, tracers...)
+	}
+
+	if cfg.FrameOmega != 0 {
+		frame := physics.RotatingFrame{Omega: cfg.FrameOmega}
+		frame.FromInertial(sim.Particles)
+		sim.forces = append(sim.forces, frame.Forces()...)
+	}
+	if cfg.ImageCorrection {
+		sim.forces = append(sim.forces, physics.NewImageCorrection(cfg.SimulationWidth, cfg.SimulationDepth, sim.mesh.Dx(), cfg.GravitationalConstant))
+	}
+	if cfg.SpongeWidth > 0 {
+		sim.forces = append(sim.forces, physics.Sponge{
+			Width:     cfg.SimulationWidth,
+			Height:    cfg.SimulationDepth,
+			Thickness: cfg.SpongeWidth,
+			Strength:  cfg.SpongeStrength,
+			CellSize:  cfg.CellSize,
+		})
+	}
+
+	sim.frames = NewFrameBuffer(len(sim.Particles), cfg.SimulationWidth, cfg.SimulationDepth)
+	sim.publish()
+
 	return sim
 }
 
+// loadScenario sets up the configured built-in scenario with one tracer or
+// cluster particle per configured particle. It returns false, leaving the
+// simulation unchanged, for random particles or if the scenario cannot be built
+func (s *Simulation) loadScenario() bool {
+	cfg := s.Config
+	var build func(*rand.Rand) (*scenario.Scenario, error)
+	switch cfg.Scenario {
+	case config.ScenarioThreeBody:
+		build = scenario.DefaultThreeBody(cfg.SimulationWidth, cfg.SimulationDepth, cfg.Spacing(), cfg.GravitationalConstant, cfg.NumParticles).Build
+	case config.ScenarioTidalDisruption:
+		build = scenario.DefaultTidalDisruption(cfg.SimulationWidth, cfg.SimulationDepth, cfg.Spacing(), cfg.GravitationalConstant, cfg.NumParticles).Build
+	default:
+		return false
+	}
+
+	seed := cfg.Seed
+	if seed == 0 {
+		seed = time.Now().UnixNano()
+	}
+	built, err := build(rand.New(rand.NewSource(seed)))
+	if err != nil {
+		return false
+	}
+	s.Particles = built.Particles
+	s.forces = append(s.forces, built.Forces...)
+	return true
+}
+
 // SetGPU sets the GPU context for acceleration
 func (s *Simulation) SetGPU(gpuCtx *gpu.GPU) {
+	s.stepMu.Lock()
+	defer s.stepMu.Unlock()
 	s.gpu = gpuCtx
 }
 
 // CleanupGPU releases GPU resources if allocated
 func (s *Simulation) CleanupGPU() {
+	s.stepMu.Lock()
+	defer s.stepMu.Unlock()
 	if s.gpu != nil {
 		// GPU cleanup would be handled by the GPU package
 		s.gpu = nil
@@ -63,33 +176,133 @@ func (s *Simulation) HasGPUErrorOccurred() bool {
 	return s.gpuErrorOccurred
 }
 
-// Update runs one full step of the simulation with frame-rate independent timing
+// Update runs one full step of the simulation with frame-rate independent
+// timing, then calls the observers due
 func (s *Simulation) Update(deltaTime float32) {
-	// Use the extracted physics engine for time evolution
-	forceField := physics.RunTimeEvolution(s.Particles, deltaTime, s.Config.SimulationWidth, s.Config.SimulationDepth, s.Config.GravitationalConstant)
+	s.stepMu.Lock()
+	s.update(deltaTime)
+	step := s.stepCount
+	s.stepMu.Unlock()
+	s.observers.Notify(step)
+}
+
+// update runs one full step; the caller holds stepMu. Extra forces are
+// kicked for half a step on either side of the gravity step
+func (s *Simulation) update(deltaTime float32) {
+	width, depth := s.Config.SimulationWidth, s.Config.SimulationDepth
+	physics.KickForces(s.Particles, s.forces, float64(deltaTime)/2)
+	direct := s.Config.DirectSolver() // No GL context here, so direct-gpu sums on the CPU
+	switch {
+	case direct && s.Config.BlockLevels > 0:
+		physics.RunBlockTimeEvolution(s.Particles, deltaTime, width, depth, physics.BlockOptions{
+			GravitationalConstant: s.Config.GravitationalConstant,
+			Softening:             s.Config.Softening,
+			MaxLevel:              s.Config.BlockLevels,
+			CellSize:              s.mesh.CellSize,
+		})
+	case direct:
+		physics.RunDirectTimeEvolution(s.Particles, deltaTime, width, depth, physics.DirectOptions{
+			GravitationalConstant: s.Config.GravitationalConstant,
+			Softening:             s.Config.Softening,
+			EncounterRadius:       s.Config.EncounterRadius,
+			CellSize:              s.mesh.CellSize,
+		})
+	case s.sparse != nil:
+		s.sparse.Step(s.Particles, deltaTime, width, depth, s.Config.GravitationalConstant)
+	case s.pm != nil:
+		// The incremental deposit keeps the density between steps, so the
+		// step fills the drawn grids itself
+		s.pm.StepPM(s.Particles, s.MassDensityGrid, s.PotentialGrid, s.forceField(), deltaTime, s.Config.GravitationalConstant)
+	default:
+		// Use the extracted physics engine for time evolution
+		forceField := physics.RunTimeEvolutionWithPrecision(s.precision, s.Particles, deltaTime, width, depth, s.mesh, s.Config.GravitationalConstant)
+
+		// Update our internal acceleration fields for visualization
+		physics.CopyGrid(s.AccelFieldX, forceField.AccelFieldX)
+		physics.CopyGrid(s.AccelFieldZ, forceField.AccelFieldZ)
+		forceField.Release()
+	}
+	physics.KickForces(s.Particles, s.forces, float64(deltaTime)/2)
 
-	// Update our internal acceleration fields for visualization
-	s.AccelFieldX = forceField.AccelFieldX
-	s.AccelFieldZ = forceField.AccelFieldZ
+	if direct || s.pm == nil {
+		// Update mass density grid for visualization
+		physics.DepositMassToGridInto(s.MassDensityGrid, s.Particles, s.mesh)
 
-	// Update mass density grid for visualization
-	s.MassDensityGrid = physics.DepositMassToGrid(s.Particles, s.Config.SimulationWidth, s.Config.SimulationDepth)
+		// Update potential grid for visualization
+		physics.SolvePoissonWithPrecision(s.precision, s.PotentialGrid, s.MassDensityGrid, s.mesh.Dx(), s.Config.GravitationalConstant)
+	}
+
+	// The direct and sparse solvers have no dense force field; derive one
+	// for visualization
+	if direct || s.sparse != nil {
+		physics.CalculateGradientInto(s.forceField(), s.PotentialGrid)
+	}
 
-	// Update potential grid for visualization
-	s.PotentialGrid = physics.SolvePoissonFFT(s.MassDensityGrid, s.Config.SimulationWidth, s.Config.SimulationDepth, s.Config.GravitationalConstant)
+	s.stepCount++
+	s.simTime += float64(deltaTime)
+	s.publish()
 }
 
-// GetParticles returns the current particles
+// forceField returns the acceleration grids as a force field on the mesh
+func (s *Simulation) forceField() *physics.ForceField {
+	return &physics.ForceField{
+		AccelFieldX: s.AccelFieldX,
+		AccelFieldZ: s.AccelFieldZ,
+		Width:       s.Config.SimulationWidth,
+		Height:      s.Config.SimulationDepth,
+		CellSize:    s.mesh.CellSize,
+	}
+}
+
+// Step advances the simulation by one fixed time step
+func (s *Simulation) Step(dt float32) {
+	s.Update(dt)
+}
+
+// GetStepCount returns the number of completed simulation steps
+func (s *Simulation) GetStepCount() int64 {
+	var step int64
+	s.ReadFrame(func(f *Frame) { step = f.Step })
+	return step
+}
+
+// GetSimTime returns the elapsed simulation time
+func (s *Simulation) GetSimTime() float64 {
+	var simTime float64
+	s.ReadFrame(func(f *Frame) { simTime = f.SimTime })
+	return simTime
+}
+
+// GetParticles returns a copy of the particles as of the last completed step
 func (s *Simulation) GetParticles() []*physics.Particle {
-	return s.Particles
+	var particles []*physics.Particle
+	s.ReadFrame(func(f *Frame) {
+		values := make([]physics.Particle, len(f.Particles))
+		copy(values, f.Particles)
+		particles = make([]*physics.Particle, len(values))
+		for i := range values {
+			particles[i] = &values[i]
+		}
+	})
+	return particles
 }
 
-// GetPotentialGrid returns the potential grid for visualization
-func (s *Simulation) GetPotentialGrid() [][]float64 {
-	return s.PotentialGrid
+// GetPotentialGrid returns a copy of the potential grid as of the last completed step
+func (s *Simulation) GetPotentialGrid() physics.Grid {
+	var grid physics.Grid
+	s.ReadFrame(func(f *Frame) {
+		grid = physics.NewGrid(f.PotentialGrid.Width(), f.PotentialGrid.Height())
+		physics.CopyGrid(grid, f.PotentialGrid)
+	})
+	return grid
 }
 
 // GetConfig returns the simulation configuration
 func (s *Simulation) GetConfig() *config.Config {
 	return s.Config
 }
+
+// Mesh returns the mesh the simulation deposits its particles onto
+func (s *Simulation) Mesh() *physics.Mesh {
+	return s.mesh
+}
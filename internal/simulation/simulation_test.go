@@ -0,0 +1,277 @@
+package simulation
+
+import (
+	"math"
+	"relativity_simulation_2d/internal/config"
+	"relativity_simulation_2d/internal/physics"
+	"sync"
+	"testing"
+)
+
+// testConfig returns a small deterministic configuration
+func testConfig() *config.Config {
+	cfg := config.DefaultConfig()
+	cfg.SimulationWidth = 32
+	cfg.SimulationDepth = 32
+	cfg.NumParticles = 50
+	cfg.Seed = 1
+	return cfg
+}
+
+// TestPublishedState tests that getters reflect the last completed step
+func TestPublishedState(t *testing.T) {
+	sim := NewSimulation(testConfig())
+
+	if sim.GetStepCount() != 0 || len(sim.GetParticles()) != 50 {
+		t.Fatalf("Initial frame not published: step %d, %d particles", sim.GetStepCount(), len(sim.GetParticles()))
+	}
+
+	sim.Step(0.1)
+	sim.Step(0.1)
+
+	if sim.GetStepCount() != 2 || math.Abs(sim.GetSimTime()-0.2) > 1e-6 {
+		t.Errorf("Expected step 2 at t=0.2, got %d at t=%f", sim.GetStepCount(), sim.GetSimTime())
+	}
+	particles := sim.GetParticles()
+	for i, p := range particles {
+		if p.Position != sim.Particles[i].Position {
+			t.Fatalf("Published particle %d does not match live state", i)
+		}
+	}
+
+	// Returned values are copies
+	particles[0].Mass = -1
+	sim.GetPotentialGrid()[0][0] = math.NaN()
+	if sim.GetParticles()[0].Mass == -1 || math.IsNaN(sim.GetPotentialGrid()[0][0]) {
+		t.Error("Getter results must not alias published state")
+	}
+}
+
+// TestNewSimulationWithParticles tests starting from provided initial conditions
+func TestNewSimulationWithParticles(t *testing.T) {
+	particles := []*physics.Particle{
+		physics.NewParticle(5, 1, 0, 2, 0, 0, 0),
+		physics.NewParticle(3, -4, 0, -1, 0.5, 0, 0),
+	}
+	sim := NewSimulationWithParticles(testConfig(), particles)
+
+	got := sim.GetParticles()
+	if len(got) != 2 {
+		t.Fatalf("Expected 2 particles, got %d", len(got))
+	}
+	if got[1].Position != particles[1].Position || got[1].Mass != 3 {
+		t.Errorf("Initial conditions not used: %+v", got[1])
+	}
+
+	sim.Step(0.1)
+	if len(sim.GetParticles()) != 2 {
+		t.Error("Particle count changed after a step")
+	}
+}
+
+// TestDirectSolver tests stepping with the direct N-body solver
+func TestDirectSolver(t *testing.T) {
+	cfg := testConfig()
+	cfg.Solver = config.SolverDirect
+	particles := []*physics.Particle{
+		physics.NewParticle(5, -4, 0, 0, 0, 0, 0),
+		physics.NewParticle(5, 4, 0, 0, 0, 0, 0),
+	}
+	sim := NewSimulationWithParticles(cfg, particles)
+
+	for i := 0; i < 10; i++ {
+		sim.Step(0.05)
+	}
+
+	got := sim.GetParticles()
+	if sep := got[1].Position.X - got[0].Position.X; sep >= 8 {
+		t.Errorf("Separation %f, expected the pair to attract", sep)
+	}
+	if sim.GetStepCount() != 10 {
+		t.Errorf("Expected 10 steps, got %d", sim.GetStepCount())
+	}
+	if sim.GetPotentialGrid()[16][16] == 0 {
+		t.Error("Potential grid should still be computed for visualization")
+	}
+}
+
+// TestConfiguredSolvers tests that the sparse grid steps the simulation
+// close to the dense grid, that incremental deposits keep the mass, and that
+// the tracer sheet and sponge settings are applied
+func TestConfiguredSolvers(t *testing.T) {
+	run := func(configure func(cfg *config.Config)) []*physics.Particle {
+		cfg := testConfig()
+		configure(cfg)
+		sim := NewSimulation(cfg)
+		for i := 0; i < 5; i++ {
+			sim.Step(0.05)
+		}
+		if mass := physics.ComputeDiagnostics(sim.Particles).TotalMass; math.Abs(sim.MassDensityGrid.Sum()-mass) > 1e-6*mass {
+			t.Errorf("Expected the density to hold the total mass %g, got %g", mass, sim.MassDensityGrid.Sum())
+		}
+		return sim.Particles
+	}
+	pm := run(func(*config.Config) {})
+	for i, p := range run(func(cfg *config.Config) { cfg.SparseGrid = true }) {
+		if d := math.Hypot(p.Position.X-pm[i].Position.X, p.Position.Z-pm[i].Position.Z); d > 1e-3 {
+			t.Errorf("Sparse grid: particle %d is %g from the dense step", i, d)
+			break
+		}
+	}
+	run(func(cfg *config.Config) { cfg.IncrementalDeposit = true })
+
+	if n := len(run(func(cfg *config.Config) { cfg.TracerSheet = 4 })); n != 50+16 {
+		t.Errorf("Expected 50 particles and 16 tracers, got %d", n)
+	}
+	sponged := NewSimulation(func() *config.Config { cfg := testConfig(); cfg.SpongeWidth = 4; return cfg }())
+	if len(sponged.forces) != 1 {
+		t.Errorf("Expected the sponge among the forces, got %d forces", len(sponged.forces))
+	}
+}
+
+// TestBlockLevels tests stepping the direct solver with block time steps
+func TestBlockLevels(t *testing.T) {
+	cfg := testConfig()
+	cfg.Solver = config.SolverDirect
+	cfg.BlockLevels = 2
+	particles := []*physics.Particle{
+		physics.NewParticle(5, -4, 0, 0, 0, 0, 0),
+		physics.NewParticle(5, 4, 0, 0, 0, 0, 0),
+	}
+	sim := NewSimulationWithParticles(cfg, particles)
+	for i := 0; i < 10; i++ {
+		sim.Step(0.05)
+	}
+	got := sim.GetParticles()
+	if sep := got[1].Position.X - got[0].Position.X; sep >= 8 {
+		t.Errorf("Separation %f, expected the pair to attract", sep)
+	}
+}
+
+// TestConcurrentReaders steps in one goroutine while others read; run with -race
+func TestConcurrentReaders(t *testing.T) {
+	const steps = 30
+	const dt = 0.05
+	sim := NewSimulation(testConfig())
+
+	done := make(chan struct{})
+	var wg sync.WaitGroup
+	for r := 0; r < 4; r++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			lastStep := int64(-1)
+			for {
+				select {
+				case <-done:
+					return
+				default:
+				}
+
+				sim.ReadFrame(func(f *Frame) {
+					// A torn frame would mix step counters and clocks from different steps
+					if math.Abs(f.SimTime-float64(f.Step)*dt) > 1e-4 {
+						t.Errorf("Inconsistent frame: step %d at t=%f", f.Step, f.SimTime)
+					}
+					if f.Step < lastStep {
+						t.Errorf("Step went backwards: %d after %d", f.Step, lastStep)
+					}
+					lastStep = f.Step
+				})
+				if n := len(sim.GetParticles()); n != 50 {
+					t.Errorf("Expected 50 particles, got %d", n)
+				}
+				_ = sim.GetPotentialGrid()
+			}
+		}()
+	}
+
+	for i := 0; i < steps; i++ {
+		sim.Step(dt)
+	}
+	close(done)
+	wg.Wait()
+
+	if sim.GetStepCount() != steps {
+		t.Errorf("Expected %d steps, got %d", steps, sim.GetStepCount())
+	}
+}
+
+// BenchmarkStep measures one step including state publication
+func BenchmarkStep(b *testing.B) {
+	cfg := testConfig()
+	cfg.SimulationWidth, cfg.SimulationDepth, cfg.NumParticles = 256, 256, 1000
+	sim := NewSimulation(cfg)
+
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		sim.Step(0.01)
+	}
+}
+
+// BenchmarkStepWithReader measures stepping while a render goroutine reads every frame
+func BenchmarkStepWithReader(b *testing.B) {
+	cfg := testConfig()
+	cfg.SimulationWidth, cfg.SimulationDepth, cfg.NumParticles = 256, 256, 1000
+	sim := NewSimulation(cfg)
+
+	done := make(chan struct{})
+	go func() {
+		var sum float64
+		for {
+			select {
+			case <-done:
+				return
+			default:
+			}
+			sim.ReadFrame(func(f *Frame) {
+				for i := range f.Particles {
+					sum += f.Particles[i].Position.X
+				}
+			})
+		}
+	}()
+
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		sim.Step(0.01)
+	}
+	b.StopTimer()
+	close(done)
+}
+
+// BenchmarkReadFrame measures the cost of a reader accessing the published frame
+func BenchmarkReadFrame(b *testing.B) {
+	sim := NewSimulation(testConfig())
+
+	b.ReportAllocs()
+	for i := 0; i < b.N; i++ {
+		sim.ReadFrame(func(f *Frame) {})
+	}
+}
+
+// TestObservers tests that observers run after the steps of their stride,
+// once the step is published
+func TestObservers(t *testing.T) {
+	sim := NewSimulation(testConfig())
+	var every, third []int64
+	sim.RegisterObserver(0, func(step int64, sim *Simulation) {
+		if sim.GetStepCount() != step {
+			t.Errorf("Expected step %d published before the observer, got %d", step, sim.GetStepCount())
+		}
+		every = append(every, step)
+	})
+	sim.RegisterObserver(3, func(step int64, sim *Simulation) { third = append(third, step) })
+
+	for i := 0; i < 7; i++ {
+		sim.Step(0.01)
+	}
+	if len(every) != 7 || every[6] != 7 {
+		t.Errorf("Expected an observer call per step, got %v", every)
+	}
+	if len(third) != 2 || third[0] != 3 || third[1] != 6 {
+		t.Errorf("Expected calls after steps 3 and 6, got %v", third)
+	}
+}
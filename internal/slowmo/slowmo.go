@@ -0,0 +1,236 @@
+// Package slowmo slows the interactive simulation down around events of
+// interest. A watch marks a particle or a region; when a trigger fires there,
+// such as a close approach, the time scale drops to slow motion for a few
+// seconds of wall-clock time and then ramps back to real time
+package slowmo
+
+import (
+	"math"
+	"relativity_simulation_2d/internal/physics"
+	"sort"
+)
+
+// Slow motion defaults
+const (
+	DefaultScale        = 0.1 // Time scale during slow motion
+	DefaultDuration     = 3.0 // Wall-clock seconds of slow motion per trigger
+	DefaultDistance     = 1.0 // Separation in cells that counts as a close approach
+	DefaultRegionRadius = 10.0
+	maxRamp             = 0.5 // Seconds over which the time scale returns to 1
+)
+
+// Trigger selects the event that starts slow motion
+type Trigger int
+
+const (
+	// TriggerApproach fires when two particles come closer than the distance
+	TriggerApproach Trigger = iota
+	// TriggerContact fires when the physical radii of two particles touch,
+	// where colliding bodies would merge
+	TriggerContact
+)
+
+// String returns "approach" or "contact"
+func (t Trigger) String() string {
+	if t == TriggerContact {
+		return "contact"
+	}
+	return "approach"
+}
+
+// Watch is what a Controller watches: a particle, or else a circular region
+// of the x-z plane
+type Watch struct {
+	Particle int     // Watched particle index (< 0 = none)
+	X, Z     float64 // Center of the watched region
+	Radius   float64 // Radius of the watched region (0 = none)
+}
+
+// ParticleWatch returns a watch on particle i
+func ParticleWatch(i int) Watch {
+	return Watch{Particle: i}
+}
+
+// RegionWatch returns a watch on the circle of radius r around (x, z)
+func RegionWatch(x, z, r float64) Watch {
+	return Watch{Particle: -1, X: x, Z: z, Radius: r}
+}
+
+// Watching reports whether the watch marks a particle or a region
+func (w Watch) Watching() bool {
+	return w.Particle >= 0 || w.Radius > 0
+}
+
+// Event is a trigger firing between particles I < J at Separation
+type Event struct {
+	I, J       int
+	Separation float64
+}
+
+// Controller watches for events and holds the time scale. Check it after
+// each step and Advance it by the wall-clock frame time
+type Controller struct {
+	Trigger       Trigger
+	Distance      float64 // Close approach separation (0 = DefaultDistance)
+	Scale         float64 // Time scale during slow motion (0 = DefaultScale)
+	Duration      float64 // Wall-clock seconds of slow motion (0 = DefaultDuration)
+	Width, Height int     // Periodic domain, so separations take the nearest image
+
+	watch     Watch
+	remaining float64 // Wall-clock seconds of slow motion left
+	engaged   bool    // The trigger condition held at the last check
+}
+
+// NewController creates a controller watching w for trigger, in a
+// width×height periodic domain
+func NewController(trigger Trigger, w Watch, width, height int) *Controller {
+	return &Controller{Trigger: trigger, Width: width, Height: height, watch: w}
+}
+
+// Watch returns the current watch
+func (c *Controller) Watch() Watch {
+	return c.watch
+}
+
+// SetWatch replaces the watch. Slow motion under way continues
+func (c *Controller) SetWatch(w Watch) {
+	c.watch = w
+	c.engaged = false
+}
+
+// Check looks for the trigger at the watch. When it starts to hold, it
+// starts slow motion and returns the event; while it keeps holding, it does
+// not fire again
+func (c *Controller) Check(particles []*physics.Particle) (Event, bool) {
+	event, ok := c.find(particles)
+	fired := ok && !c.engaged
+	c.engaged = ok
+	if fired {
+		c.remaining = c.duration()
+	}
+	return event, fired
+}
+
+// Advance counts down dt seconds of wall-clock time
+func (c *Controller) Advance(dt float64) {
+	c.remaining = math.Max(0, c.remaining-dt)
+}
+
+// Active reports whether slow motion is under way
+func (c *Controller) Active() bool {
+	return c.remaining > 0
+}
+
+// TimeScale returns the factor to apply to the physics time step: the slow
+// motion scale, rising linearly back to 1 over the last half second (or half
+// the duration, if shorter)
+func (c *Controller) TimeScale() float64 {
+	if c.remaining <= 0 {
+		return 1
+	}
+	scale := c.Scale
+	if scale <= 0 {
+		scale = DefaultScale
+	}
+	ramp := math.Min(maxRamp, c.duration()/2)
+	if c.remaining >= ramp {
+		return scale
+	}
+	return 1 - (1-scale)*c.remaining/ramp
+}
+
+// duration returns the configured duration or the default
+func (c *Controller) duration() float64 {
+	if c.Duration > 0 {
+		return c.Duration
+	}
+	return DefaultDuration
+}
+
+// find returns the closest pair meeting the trigger: the watched particle
+// and any other, or two particles both inside the watched region
+func (c *Controller) find(particles []*physics.Particle) (Event, bool) {
+	w := c.watch
+	switch {
+	case w.Particle >= 0:
+		if w.Particle >= len(particles) {
+			return Event{}, false
+		}
+		p := particles[w.Particle]
+		best, found := Event{}, false
+		for j, q := range particles {
+			if j == w.Particle {
+				continue
+			}
+			d := math.Hypot(c.delta(q.Position.X-p.Position.X, c.Width), c.delta(q.Position.Z-p.Position.Z, c.Height))
+			if d < c.threshold(p, q) && (!found || d < best.Separation) {
+				best, found = Event{I: min(w.Particle, j), J: max(w.Particle, j), Separation: d}, true
+			}
+		}
+		return best, found
+	case w.Radius > 0:
+		return c.findInRegion(particles)
+	}
+	return Event{}, false
+}
+
+// findInRegion sweeps the particles inside the region in order of x, so only
+// pairs within the largest threshold along x are compared
+func (c *Controller) findInRegion(particles []*physics.Particle) (Event, bool) {
+	w := c.watch
+	type local struct {
+		i    int
+		x, z float64
+	}
+	var inside []local
+	window := c.Distance
+	if window <= 0 {
+		window = DefaultDistance
+	}
+	maxRadius := 0.0
+	for i, p := range particles {
+		dx, dz := c.delta(p.Position.X-w.X, c.Width), c.delta(p.Position.Z-w.Z, c.Height)
+		if dx*dx+dz*dz <= w.Radius*w.Radius {
+			inside = append(inside, local{i, dx, dz})
+			maxRadius = math.Max(maxRadius, float64(p.Radius))
+		}
+	}
+	if c.Trigger == TriggerContact {
+		window = 2 * maxRadius
+	}
+	sort.Slice(inside, func(a, b int) bool { return inside[a].x < inside[b].x })
+
+	best, found := Event{}, false
+	for a := range inside {
+		for b := a + 1; b < len(inside) && inside[b].x-inside[a].x < window; b++ {
+			p, q := particles[inside[a].i], particles[inside[b].i]
+			d := math.Hypot(inside[b].x-inside[a].x, inside[b].z-inside[a].z)
+			if d < c.threshold(p, q) && (!found || d < best.Separation) {
+				i, j := inside[a].i, inside[b].i
+				best, found = Event{I: min(i, j), J: max(i, j), Separation: d}, true
+			}
+		}
+	}
+	return best, found
+}
+
+// threshold returns the separation below which p and q trigger
+func (c *Controller) threshold(p, q *physics.Particle) float64 {
+	if c.Trigger == TriggerContact {
+		return float64(p.Radius + q.Radius)
+	}
+	if c.Distance > 0 {
+		return c.Distance
+	}
+	return DefaultDistance
+}
+
+// delta returns the nearest-image offset d along an axis of the given period
+// (≤ 0 = not periodic)
+func (c *Controller) delta(d float64, period int) float64 {
+	if period <= 0 {
+		return d
+	}
+	p := float64(period)
+	return d - p*math.Round(d/p)
+}
@@ -0,0 +1,79 @@
+package slowmo
+
+import (
+	"math"
+	"relativity_simulation_2d/internal/physics"
+	"testing"
+)
+
+// particlesAt returns unit-mass particles of radius 0.5 at the given x positions on z = 0
+func particlesAt(xs ...float64) []*physics.Particle {
+	particles := make([]*physics.Particle, len(xs))
+	for i, x := range xs {
+		particles[i] = physics.NewParticle(1, x, 0, 0, 0, 0, 0)
+		particles[i].Radius = 0.5
+	}
+	return particles
+}
+
+// TestParticleApproach tests firing once when the watched particle approaches another
+func TestParticleApproach(t *testing.T) {
+	particles := particlesAt(0, 3, 10)
+	c := NewController(TriggerApproach, ParticleWatch(1), 64, 64)
+	c.Distance = 2
+
+	if _, fired := c.Check(particles); fired || c.Active() {
+		t.Fatal("Expected no trigger with the particles 3 apart")
+	}
+	particles[0].Position.X = 1.5
+	event, fired := c.Check(particles)
+	if !fired || event.I != 0 || event.J != 1 || event.Separation != 1.5 {
+		t.Fatalf("Expected particles 0 and 1 at 1.5, got %+v (fired %v)", event, fired)
+	}
+	if _, fired := c.Check(particles); fired {
+		t.Error("Expected no second trigger while the pair stays close")
+	}
+	if c.TimeScale() != DefaultScale {
+		t.Errorf("Expected time scale %g, got %g", DefaultScale, c.TimeScale())
+	}
+}
+
+// TestRegionContact tests contact between two particles inside a region,
+// across the periodic edge, ignoring a pair outside it
+func TestRegionContact(t *testing.T) {
+	particles := particlesAt(31.6, -31.8, 0, 0.9)
+	c := NewController(TriggerContact, RegionWatch(32, 0, 3), 64, 64)
+	event, fired := c.Check(particles)
+	if !fired || event.I != 0 || event.J != 1 || math.Abs(event.Separation-0.6) > 1e-9 {
+		t.Fatalf("Expected contact of particles 0 and 1 across the edge, got %+v (fired %v)", event, fired)
+	}
+
+	c.SetWatch(RegionWatch(-20, 0, 3))
+	if _, fired := c.Check(particles); fired {
+		t.Error("Expected no trigger in an empty region")
+	}
+	if (Watch{Particle: -1}).Watching() {
+		t.Error("Expected an empty watch to watch nothing")
+	}
+}
+
+// TestTimeScale tests the slow motion duration and the ramp back to real time
+func TestTimeScale(t *testing.T) {
+	c := NewController(TriggerApproach, ParticleWatch(0), 0, 0)
+	c.Scale, c.Duration = 0.2, 2
+	if _, fired := c.Check(particlesAt(0, 0.5)); !fired {
+		t.Fatal("Expected a trigger")
+	}
+	c.Advance(1)
+	if c.TimeScale() != 0.2 {
+		t.Errorf("Expected slow motion after 1s, got %g", c.TimeScale())
+	}
+	c.Advance(0.75) // 0.25s left, halfway through the ramp
+	if got := c.TimeScale(); math.Abs(got-0.6) > 1e-9 {
+		t.Errorf("Expected time scale 0.6 halfway through the ramp, got %g", got)
+	}
+	c.Advance(1)
+	if c.Active() || c.TimeScale() != 1 {
+		t.Errorf("Expected real time after the duration, got %g", c.TimeScale())
+	}
+}
@@ -0,0 +1,393 @@
+package snapshot
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/binary"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"hash/crc32"
+	"io"
+	"math"
+	"relativity_simulation_2d/internal/config"
+	"relativity_simulation_2d/internal/provenance"
+	"time"
+)
+
+// Binary state container (little-endian), shared by snapshots, checkpoints
+// and crash dumps:
+//
+//	magic    [8]byte  "RSIMSTAT"
+//	version  uint16   container version (Version); readers reject newer versions
+//	flags    uint16   reserved, written as zero and ignored on read
+//	blocks   ...      tag [4]byte, block version uint16, reserved uint16,
+//	                  length uint64, payload [length]byte, crc32(payload) uint32
+//
+// Blocks: HEAD (step, time), CONF (JSON config), META (JSON provenance
+// metadata), PART (particles), GRID (one per named grid) and END, which must come last so truncated files are
+// detected. Forward-compatibility rules:
+//
+//   - Readers skip blocks whose tag they do not know
+//   - New fields are only appended to a block, bumping its block version;
+//     readers decode the fields they know and ignore trailing bytes, and
+//     fields missing from older files take their zero value
+//   - PART stores its record size, so particle fields can be appended the same way
+//   - The container version changes only for incompatible layout changes
+//   - Version 1 JSON snapshots remain loadable through Load
+const magic = "RSIMSTAT"
+
+// Block tags
+var (
+	tagHeader    = [4]byte{'H', 'E', 'A', 'D'}
+	tagConfig    = [4]byte{'C', 'O', 'N', 'F'}
+	tagMetadata  = [4]byte{'M', 'E', 'T', 'A'}
+	tagParticles = [4]byte{'P', 'A', 'R', 'T'}
+	tagGrid      = [4]byte{'G', 'R', 'I', 'D'}
+	tagEnd       = [4]byte{'E', 'N', 'D', 0}
+)
+
+const (
+	blockVersion       = 1
+	particleRecordSize = 6*8 + 2*4 // Position, velocity (float64) and mass, radius (float32)
+	maxBlockLength     = 1 << 34   // Sanity bound on corrupt length fields (16 GiB)
+)
+
+// IsBinary reports whether data starts with the binary container magic
+func IsBinary(data []byte) bool {
+	return bytes.HasPrefix(data, []byte(magic))
+}
+
+// Encode writes s to w in the binary container format
+func Encode(w io.Writer, s *Snapshot) error {
+	bw := bufio.NewWriter(w)
+
+	var header [12]byte
+	copy(header[:8], magic)
+	binary.LittleEndian.PutUint16(header[8:], Version)
+	if _, err := bw.Write(header[:]); err != nil {
+		return fmt.Errorf("failed to write header: %v", err)
+	}
+
+	var head blockWriter
+	head.putInt64(s.Step)
+	head.putFloat64(s.SimTime)
+	var created int64 // Zero time is stored as 0
+	if !s.CreatedAt.IsZero() {
+		created = s.CreatedAt.UnixNano()
+	}
+	head.putInt64(created)
+	if err := writeBlock(bw, tagHeader, head.Bytes()); err != nil {
+		return err
+	}
+
+	if s.Config != nil {
+		data, err := json.Marshal(s.Config)
+		if err != nil {
+			return fmt.Errorf("failed to encode config: %v", err)
+		}
+		if err := writeBlock(bw, tagConfig, data); err != nil {
+			return err
+		}
+	}
+
+	if s.Metadata != nil {
+		data, err := json.Marshal(s.Metadata)
+		if err != nil {
+			return fmt.Errorf("failed to encode metadata: %v", err)
+		}
+		if err := writeBlock(bw, tagMetadata, data); err != nil {
+			return err
+		}
+	}
+
+	var part blockWriter
+	part.putUint32(uint32(len(s.Particles)))
+	part.putUint32(particleRecordSize)
+	for _, p := range s.Particles {
+		for _, v := range p.Position {
+			part.putFloat64(v)
+		}
+		for _, v := range p.Velocity {
+			part.putFloat64(v)
+		}
+		part.putFloat32(p.Mass)
+		part.putFloat32(p.Radius)
+	}
+	if err := writeBlock(bw, tagParticles, part.Bytes()); err != nil {
+		return err
+	}
+
+	for _, g := range s.Grids {
+		if len(g.Data) != g.Width*g.Height {
+			return fmt.Errorf("grid %q has %d values, expected %dx%d", g.Name, len(g.Data), g.Width, g.Height)
+		}
+		var grid blockWriter
+		grid.putUint16(uint16(len(g.Name)))
+		grid.WriteString(g.Name)
+		grid.putUint32(uint32(g.Width))
+		grid.putUint32(uint32(g.Height))
+		for _, v := range g.Data {
+			grid.putFloat64(v)
+		}
+		if err := writeBlock(bw, tagGrid, grid.Bytes()); err != nil {
+			return err
+		}
+	}
+
+	if err := writeBlock(bw, tagEnd, nil); err != nil {
+		return err
+	}
+	return bw.Flush()
+}
+
+// Decode reads a snapshot in the binary container format from r
+func Decode(r io.Reader) (*Snapshot, error) {
+	br := bufio.NewReader(r)
+
+	var header [12]byte
+	if _, err := io.ReadFull(br, header[:]); err != nil {
+		return nil, fmt.Errorf("failed to read header: %v", err)
+	}
+	if string(header[:8]) != magic {
+		return nil, errors.New("not a binary state file")
+	}
+	version := int(binary.LittleEndian.Uint16(header[8:]))
+	if version > Version {
+		return nil, fmt.Errorf("unsupported snapshot version: %d (max %d)", version, Version)
+	}
+
+	s := &Snapshot{Version: version}
+	var haveHeader, haveParticles bool
+	for {
+		tag, payload, err := readBlock(br)
+		if err != nil {
+			return nil, err
+		}
+
+		switch tag {
+		case tagEnd:
+			if !haveHeader || !haveParticles {
+				return nil, errors.New("state file is missing required blocks")
+			}
+			return s, nil
+		case tagHeader:
+			b := blockReader{data: payload}
+			s.Step = b.int64()
+			s.SimTime = b.float64()
+			if created := b.int64(); created != 0 {
+				s.CreatedAt = time.Unix(0, created).UTC()
+			}
+			if b.err != nil {
+				return nil, fmt.Errorf("invalid header block: %v", b.err)
+			}
+			haveHeader = true
+		case tagConfig:
+			cfg := &config.Config{}
+			if err := json.Unmarshal(payload, cfg); err != nil {
+				return nil, fmt.Errorf("invalid config block: %v", err)
+			}
+			s.Config = cfg
+		case tagMetadata:
+			meta := &provenance.Metadata{}
+			if err := json.Unmarshal(payload, meta); err != nil {
+				return nil, fmt.Errorf("invalid metadata block: %v", err)
+			}
+			s.Metadata = meta
+		case tagParticles:
+			particles, err := decodeParticles(payload)
+			if err != nil {
+				return nil, err
+			}
+			s.Particles = particles
+			haveParticles = true
+		case tagGrid:
+			grid, err := decodeGrid(payload)
+			if err != nil {
+				return nil, err
+			}
+			s.Grids = append(s.Grids, grid)
+		default:
+			// Unknown block from a newer writer; skipped per the compatibility rules
+		}
+	}
+}
+
+// decodeParticles decodes a PART block, ignoring record fields appended by newer writers
+func decodeParticles(payload []byte) ([]ParticleState, error) {
+	b := blockReader{data: payload}
+	count := int(b.uint32())
+	recordSize := int(b.uint32())
+	if b.err != nil {
+		return nil, fmt.Errorf("invalid particle block: %v", b.err)
+	}
+	if recordSize < particleRecordSize {
+		return nil, fmt.Errorf("invalid particle block: record size %d < %d", recordSize, particleRecordSize)
+	}
+	if uint64(len(b.data))/uint64(recordSize) < uint64(count) { // Division avoids overflow of count*recordSize
+		return nil, fmt.Errorf("invalid particle block: %d records do not fit in %d bytes", count, len(b.data))
+	}
+
+	particles := make([]ParticleState, count)
+	for i := range particles {
+		rec := blockReader{data: b.data[i*recordSize : (i+1)*recordSize]}
+		p := &particles[i]
+		for k := range p.Position {
+			p.Position[k] = rec.float64()
+		}
+		for k := range p.Velocity {
+			p.Velocity[k] = rec.float64()
+		}
+		p.Mass = rec.float32()
+		p.Radius = rec.float32()
+	}
+	return particles, nil
+}
+
+// decodeGrid decodes a GRID block
+func decodeGrid(payload []byte) (GridState, error) {
+	b := blockReader{data: payload}
+	name := b.string(int(b.uint16()))
+	width := int(b.uint32())
+	height := int(b.uint32())
+	if b.err != nil {
+		return GridState{}, fmt.Errorf("invalid grid block: %v", b.err)
+	}
+	if width == 0 || height == 0 {
+		return GridState{}, fmt.Errorf("invalid grid block %q: empty %dx%d grid", name, width, height)
+	}
+	if uint64(len(b.data))/8 < uint64(width)*uint64(height) {
+		return GridState{}, fmt.Errorf("invalid grid block %q: %dx%d values do not fit in %d bytes", name, width, height, len(b.data))
+	}
+
+	data := make([]float64, width*height)
+	for i := range data {
+		data[i] = b.float64()
+	}
+	return GridState{Name: name, Width: width, Height: height, Data: data}, nil
+}
+
+// writeBlock writes one framed block with its CRC
+func writeBlock(w io.Writer, tag [4]byte, payload []byte) error {
+	var frame [16]byte
+	copy(frame[:4], tag[:])
+	binary.LittleEndian.PutUint16(frame[4:], blockVersion)
+	binary.LittleEndian.PutUint64(frame[8:], uint64(len(payload)))
+	var crc [4]byte
+	binary.LittleEndian.PutUint32(crc[:], crc32.ChecksumIEEE(payload))
+
+	for _, part := range [][]byte{frame[:], payload, crc[:]} {
+		if _, err := w.Write(part); err != nil {
+			return fmt.Errorf("failed to write %s block: %v", tag[:], err)
+		}
+	}
+	return nil
+}
+
+// readBlock reads one framed block and verifies its CRC
+func readBlock(r io.Reader) (tag [4]byte, payload []byte, err error) {
+	var frame [16]byte
+	if _, err := io.ReadFull(r, frame[:]); err != nil {
+		return tag, nil, fmt.Errorf("truncated state file: %v", err)
+	}
+	copy(tag[:], frame[:4])
+	length := binary.LittleEndian.Uint64(frame[8:])
+	if length > maxBlockLength {
+		return tag, nil, fmt.Errorf("invalid %q block length %d", tag[:], length)
+	}
+
+	// Read incrementally rather than allocating length up front, so a corrupt
+	// length field cannot allocate far more memory than the file holds
+	var buf bytes.Buffer
+	if n, err := io.CopyN(&buf, r, int64(length)); err != nil {
+		return tag, nil, fmt.Errorf("truncated %q block: %d of %d bytes: %v", tag[:], n, length, err)
+	}
+	payload = buf.Bytes()
+	var crc [4]byte
+	if _, err := io.ReadFull(r, crc[:]); err != nil {
+		return tag, nil, fmt.Errorf("truncated %q block: %v", tag[:], err)
+	}
+	if binary.LittleEndian.Uint32(crc[:]) != crc32.ChecksumIEEE(payload) {
+		return tag, nil, fmt.Errorf("checksum mismatch in %q block", tag[:])
+	}
+	return tag, payload, nil
+}
+
+// blockWriter appends little-endian values to a block payload
+type blockWriter struct {
+	bytes.Buffer
+}
+
+func (w *blockWriter) putUint16(v uint16) {
+	w.Write(binary.LittleEndian.AppendUint16(nil, v))
+}
+
+func (w *blockWriter) putUint32(v uint32) {
+	w.Write(binary.LittleEndian.AppendUint32(nil, v))
+}
+
+func (w *blockWriter) putInt64(v int64) {
+	w.Write(binary.LittleEndian.AppendUint64(nil, uint64(v)))
+}
+
+func (w *blockWriter) putFloat32(v float32) {
+	w.putUint32(math.Float32bits(v))
+}
+
+func (w *blockWriter) putFloat64(v float64) {
+	w.putInt64(int64(math.Float64bits(v)))
+}
+
+// blockReader consumes little-endian values from a block payload. Reads past
+// the end yield zero and set err, so callers check once after a group of reads
+type blockReader struct {
+	data []byte
+	err  error
+}
+
+// next returns the next n bytes, or nil once the payload is exhausted
+func (r *blockReader) next(n int) []byte {
+	if r.err != nil {
+		return nil
+	}
+	if len(r.data) < n {
+		r.err = io.ErrUnexpectedEOF
+		return nil
+	}
+	b := r.data[:n]
+	r.data = r.data[n:]
+	return b
+}
+
+func (r *blockReader) uint16() uint16 {
+	if b := r.next(2); b != nil {
+		return binary.LittleEndian.Uint16(b)
+	}
+	return 0
+}
+
+func (r *blockReader) uint32() uint32 {
+	if b := r.next(4); b != nil {
+		return binary.LittleEndian.Uint32(b)
+	}
+	return 0
+}
+
+func (r *blockReader) int64() int64 {
+	if b := r.next(8); b != nil {
+		return int64(binary.LittleEndian.Uint64(b))
+	}
+	return 0
+}
+
+func (r *blockReader) float32() float32 {
+	return math.Float32frombits(r.uint32())
+}
+
+func (r *blockReader) float64() float64 {
+	return math.Float64frombits(uint64(r.int64()))
+}
+
+func (r *blockReader) string(n int) string {
+	return string(r.next(n))
+}
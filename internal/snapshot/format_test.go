@@ -0,0 +1,179 @@
+package snapshot
+
+import (
+	"bytes"
+	"encoding/binary"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"relativity_simulation_2d/internal/config"
+	"relativity_simulation_2d/internal/physics"
+	"relativity_simulation_2d/internal/provenance"
+	"testing"
+)
+
+// testSnapshot builds a snapshot with config, metadata, particles and a grid
+func testSnapshot() *Snapshot {
+	particles := []*physics.Particle{
+		physics.NewParticle(10.0, 1.0, 0.5, -2.0, 0.5, 0, 0.25),
+		physics.NewParticle(20.0, -3.0, 0, 4.0, 0, -1.5, -1.0),
+	}
+	snap := New(config.DefaultConfig(), particles, 42, 1.5)
+	meta := provenance.New(snap.Config, provenance.BackendCPU)
+	snap.Metadata = &meta
+	grid := physics.NewGrid(3, 2)
+	grid[2][1] = -7.25
+	grid[0][1] = 3
+	snap.AddGrid(GridPotential, grid)
+	return snap
+}
+
+// TestBinaryRoundTrip tests that Encode/Decode preserve every field
+func TestBinaryRoundTrip(t *testing.T) {
+	snap := testSnapshot()
+
+	var buf bytes.Buffer
+	if err := Encode(&buf, snap); err != nil {
+		t.Fatalf("Encode failed: %v", err)
+	}
+	if !IsBinary(buf.Bytes()) {
+		t.Fatal("Encoded data should start with the container magic")
+	}
+
+	decoded, err := Decode(&buf)
+	if err != nil {
+		t.Fatalf("Decode failed: %v", err)
+	}
+
+	if decoded.Version != Version || decoded.Step != 42 || decoded.SimTime != 1.5 {
+		t.Errorf("Header mismatch: version %d step %d time %f", decoded.Version, decoded.Step, decoded.SimTime)
+	}
+	if !decoded.CreatedAt.Equal(snap.CreatedAt) {
+		t.Errorf("CreatedAt mismatch: got %v, want %v", decoded.CreatedAt, snap.CreatedAt)
+	}
+	if decoded.Config == nil || *decoded.Config != *snap.Config {
+		t.Errorf("Config mismatch: got %+v", decoded.Config)
+	}
+	if decoded.Metadata == nil || *decoded.Metadata != *snap.Metadata {
+		t.Errorf("Metadata mismatch: got %+v", decoded.Metadata)
+	}
+	if len(decoded.Particles) != len(snap.Particles) {
+		t.Fatalf("Expected %d particles, got %d", len(snap.Particles), len(decoded.Particles))
+	}
+	for i := range snap.Particles {
+		if decoded.Particles[i] != snap.Particles[i] {
+			t.Errorf("Particle %d mismatch: got %+v, want %+v", i, decoded.Particles[i], snap.Particles[i])
+		}
+	}
+
+	grid, ok := decoded.Grid(GridPotential)
+	if !ok {
+		t.Fatal("Potential grid missing")
+	}
+	if grid.Width() != 3 || grid.Height() != 2 || grid[2][1] != -7.25 || grid[0][1] != 3 {
+		t.Errorf("Grid mismatch: %v", grid)
+	}
+}
+
+// TestBinaryRejectsCorruption tests CRC, truncation and version checks
+func TestBinaryRejectsCorruption(t *testing.T) {
+	var buf bytes.Buffer
+	if err := Encode(&buf, testSnapshot()); err != nil {
+		t.Fatal(err)
+	}
+	data := buf.Bytes()
+
+	flipped := bytes.Clone(data)
+	flipped[len(flipped)/2] ^= 0xFF
+	if _, err := Decode(bytes.NewReader(flipped)); err == nil {
+		t.Error("Expected checksum error for corrupted payload")
+	}
+
+	// Dropping the END block must be detected even though every other block is intact
+	if _, err := Decode(bytes.NewReader(data[:len(data)-20])); err == nil {
+		t.Error("Expected error for truncated file")
+	}
+
+	future := bytes.Clone(data)
+	binary.LittleEndian.PutUint16(future[8:], Version+1)
+	if _, err := Decode(bytes.NewReader(future)); err == nil {
+		t.Error("Expected error for newer container version")
+	}
+
+	if _, err := Decode(bytes.NewReader([]byte("{\"version\": 1}"))); err == nil {
+		t.Error("Expected error for non-binary data")
+	}
+}
+
+// TestBinaryForwardCompatibility tests that unknown blocks and appended
+// particle fields from a newer writer are ignored
+func TestBinaryForwardCompatibility(t *testing.T) {
+	var buf bytes.Buffer
+	buf.WriteString(magic)
+	buf.Write(binary.LittleEndian.AppendUint16(nil, Version))
+	buf.Write([]byte{0, 0})
+
+	var head blockWriter
+	head.putInt64(7)
+	head.putFloat64(0.5)
+	head.putInt64(0)
+	head.putInt64(12345) // Field appended by a newer HEAD version
+	mustWriteBlock(t, &buf, tagHeader, head.Bytes())
+
+	mustWriteBlock(t, &buf, [4]byte{'X', 'T', 'R', 'A'}, []byte("future data"))
+
+	var part blockWriter
+	part.putUint32(1)
+	part.putUint32(particleRecordSize + 8) // Each record carries one extra float64
+	for _, v := range []float64{1, 2, 3, 4, 5, 6} {
+		part.putFloat64(v)
+	}
+	part.putFloat32(9)
+	part.putFloat32(0.5)
+	part.putFloat64(99) // Unknown appended field
+	mustWriteBlock(t, &buf, tagParticles, part.Bytes())
+	mustWriteBlock(t, &buf, tagEnd, nil)
+
+	s, err := Decode(&buf)
+	if err != nil {
+		t.Fatalf("Decode failed: %v", err)
+	}
+	if s.Step != 7 || s.SimTime != 0.5 || !s.CreatedAt.IsZero() || s.Config != nil {
+		t.Errorf("Header mismatch: %+v", s)
+	}
+	want := ParticleState{Position: [3]float64{1, 2, 3}, Velocity: [3]float64{4, 5, 6}, Mass: 9, Radius: 0.5}
+	if len(s.Particles) != 1 || s.Particles[0] != want {
+		t.Errorf("Particles mismatch: %+v", s.Particles)
+	}
+}
+
+// TestLoadLegacyJSON tests that version 1 JSON snapshots remain loadable
+func TestLoadLegacyJSON(t *testing.T) {
+	legacy := testSnapshot()
+	legacy.Version = 1
+	legacy.Grids = nil
+	data, err := json.MarshalIndent(legacy, "", "  ")
+	if err != nil {
+		t.Fatal(err)
+	}
+	path := filepath.Join(t.TempDir(), "legacy.json")
+	if err := os.WriteFile(path, data, 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	loaded, err := Load(path)
+	if err != nil {
+		t.Fatalf("Load failed: %v", err)
+	}
+	if loaded.Version != 1 || loaded.Step != 42 || len(loaded.Particles) != 2 {
+		t.Errorf("Legacy snapshot mismatch: %+v", loaded)
+	}
+}
+
+// mustWriteBlock writes a framed block or fails the test
+func mustWriteBlock(t *testing.T, buf *bytes.Buffer, tag [4]byte, payload []byte) {
+	t.Helper()
+	if err := writeBlock(buf, tag, payload); err != nil {
+		t.Fatal(err)
+	}
+}
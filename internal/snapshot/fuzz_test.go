@@ -0,0 +1,48 @@
+package snapshot
+
+import (
+	"bytes"
+	"encoding/json"
+	"testing"
+)
+
+// FuzzParse tests that malformed snapshot files are rejected with an error
+// rather than a panic or a runaway allocation, and that whatever parses can
+// be restored and re-encoded. Run with: go test -fuzz FuzzParse ./internal/snapshot
+func FuzzParse(f *testing.F) {
+	var binary bytes.Buffer
+	if err := Encode(&binary, testSnapshot()); err != nil {
+		f.Fatal(err)
+	}
+	legacy := testSnapshot()
+	legacy.Version = 1
+	legacyJSON, err := json.Marshal(legacy)
+	if err != nil {
+		f.Fatal(err)
+	}
+
+	f.Add(binary.Bytes())
+	f.Add(binary.Bytes()[:binary.Len()/2]) // Truncated
+	f.Add(legacyJSON)
+	f.Add([]byte(magic))
+	f.Add([]byte(`{"version":1,"grids":[{"name":"potential","width":2,"height":2,"data":[1]}]}`))
+	f.Add([]byte{})
+
+	f.Fuzz(func(t *testing.T, data []byte) {
+		s, err := Parse(data)
+		if err != nil {
+			return
+		}
+		s.Restore()
+		for _, g := range s.Grids {
+			s.Grid(g.Name)
+		}
+		var buf bytes.Buffer
+		if err := Encode(&buf, s); err != nil {
+			t.Fatalf("Parsed snapshot failed to encode: %v", err)
+		}
+		if _, err := Decode(&buf); err != nil {
+			t.Fatalf("Re-encoded snapshot failed to decode: %v", err)
+		}
+	})
+}
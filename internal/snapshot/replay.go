@@ -0,0 +1,45 @@
+package snapshot
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+)
+
+// Replay frame files are named by step so that name order is step order
+const (
+	framePrefix = "frame_"
+	frameSuffix = ".rsim"
+)
+
+// FrameFileName returns the name of the replay frame file for a step
+func FrameFileName(step int64) string {
+	return fmt.Sprintf("%s%08d%s", framePrefix, step, frameSuffix)
+}
+
+// SaveFrame writes a snapshot as the replay frame FrameFileName(s.Step) in
+// dir, creating dir if needed
+func SaveFrame(dir string, s *Snapshot) error {
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return fmt.Errorf("failed to create replay directory: %v", err)
+	}
+	return Save(filepath.Join(dir, FrameFileName(s.Step)), s)
+}
+
+// ListFrames returns the paths of the replay frames in dir in step order
+func ListFrames(dir string) ([]string, error) {
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read replay directory: %v", err)
+	}
+	var paths []string
+	for _, e := range entries {
+		if !e.IsDir() && strings.HasPrefix(e.Name(), framePrefix) && strings.HasSuffix(e.Name(), frameSuffix) {
+			paths = append(paths, filepath.Join(dir, e.Name()))
+		}
+	}
+	sort.Strings(paths)
+	return paths, nil
+}
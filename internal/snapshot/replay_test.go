@@ -0,0 +1,44 @@
+package snapshot
+
+import (
+	"os"
+	"path/filepath"
+	"relativity_simulation_2d/internal/config"
+	"relativity_simulation_2d/internal/physics"
+	"testing"
+)
+
+// TestReplayFrames tests saving frames and listing them in step order
+func TestReplayFrames(t *testing.T) {
+	dir := filepath.Join(t.TempDir(), "replay")
+	particles := []*physics.Particle{physics.NewParticle(1, 0, 0, 0, 0, 0, 0)}
+	for _, step := range []int64{100, 5, 20} {
+		if err := SaveFrame(dir, New(config.DefaultConfig(), particles, step, 0)); err != nil {
+			t.Fatalf("SaveFrame failed: %v", err)
+		}
+	}
+	if err := os.WriteFile(filepath.Join(dir, "notes.txt"), nil, 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	paths, err := ListFrames(dir)
+	if err != nil {
+		t.Fatalf("ListFrames failed: %v", err)
+	}
+	want := []string{FrameFileName(5), FrameFileName(20), FrameFileName(100)}
+	if len(paths) != len(want) {
+		t.Fatalf("Expected %d frames, got %v", len(want), paths)
+	}
+	for i, path := range paths {
+		if filepath.Base(path) != want[i] {
+			t.Errorf("Expected frame %d to be %s, got %s", i, want[i], path)
+		}
+	}
+	if s, err := Load(paths[2]); err != nil || s.Step != 100 {
+		t.Errorf("Expected the last frame at step 100, got %v", err)
+	}
+
+	if _, err := ListFrames(filepath.Join(dir, "missing")); err == nil {
+		t.Error("Expected an error for a missing directory")
+	}
+}
@@ -0,0 +1,181 @@
+package snapshot
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"os"
+	"relativity_simulation_2d/internal/config"
+	"relativity_simulation_2d/internal/physics"
+	"relativity_simulation_2d/internal/provenance"
+	"time"
+)
+
+// Version is the current snapshot format version. Version 1 snapshots are
+// JSON; version 2 introduced the binary container described in format.go
+const Version = 2
+
+// ParticleState is the serializable state of a single particle
+type ParticleState struct {
+	Position [3]float64 `json:"position"`
+	Velocity [3]float64 `json:"velocity"`
+	Mass     float32    `json:"mass"`
+	Radius   float32    `json:"radius"`
+}
+
+// GridPotential is the conventional name of the potential grid
+const GridPotential = "potential"
+
+// GridState is a named scalar grid stored row-major (index i*Height+j)
+type GridState struct {
+	Name   string    `json:"name"`
+	Width  int       `json:"width"`
+	Height int       `json:"height"`
+	Data   []float64 `json:"data"`
+}
+
+// validate checks that the dimensions are positive and match the data
+func (g GridState) validate() error {
+	if g.Width <= 0 || g.Height <= 0 || len(g.Data)%g.Width != 0 || len(g.Data)/g.Width != g.Height {
+		return fmt.Errorf("invalid grid %q: %dx%d with %d values", g.Name, g.Width, g.Height, len(g.Data))
+	}
+	return nil
+}
+
+// Snapshot captures the full simulation state at a point in time
+type Snapshot struct {
+	Version   int                  `json:"version"`
+	CreatedAt time.Time            `json:"created_at"`
+	Step      int64                `json:"step"`
+	SimTime   float64              `json:"sim_time"`
+	Config    *config.Config       `json:"config"`
+	Particles []ParticleState      `json:"particles"`
+	Grids     []GridState          `json:"grids,omitempty"`
+	Metadata  *provenance.Metadata `json:"metadata,omitempty"` // Code and parameters that produced the state (nil = unknown)
+}
+
+// New creates a snapshot of the given particles and configuration
+func New(cfg *config.Config, particles []*physics.Particle, step int64, simTime float64) *Snapshot {
+	snap := &Snapshot{
+		Version:   Version,
+		CreatedAt: time.Now().UTC(),
+		Step:      step,
+		SimTime:   simTime,
+		Particles: make([]ParticleState, len(particles)),
+	}
+	if cfg != nil {
+		snap.Config = cfg.Clone()
+	}
+
+	for i, p := range particles {
+		snap.Particles[i] = ParticleState{
+			Position: [3]float64{p.Position.X, p.Position.Y, p.Position.Z},
+			Velocity: [3]float64{p.Velocity.X, p.Velocity.Y, p.Velocity.Z},
+			Mass:     p.Mass,
+			Radius:   p.Radius,
+		}
+	}
+
+	return snap
+}
+
+// Restore recreates the particles stored in the snapshot
+func (s *Snapshot) Restore() []*physics.Particle {
+	particles := make([]*physics.Particle, len(s.Particles))
+	for i, ps := range s.Particles {
+		particles[i] = &physics.Particle{
+			Position: physics.NewVec3(ps.Position[0], ps.Position[1], ps.Position[2]),
+			Velocity: physics.NewVec3(ps.Velocity[0], ps.Velocity[1], ps.Velocity[2]),
+			Mass:     ps.Mass,
+			Radius:   ps.Radius,
+		}
+	}
+	return particles
+}
+
+// AddGrid stores a copy of grid under name, replacing any grid with the same name
+func (s *Snapshot) AddGrid(name string, grid physics.Grid) {
+	state := GridState{Name: name, Width: grid.Width(), Height: grid.Height()}
+	state.Data = grid.Flatten(nil)
+	for i := range s.Grids {
+		if s.Grids[i].Name == name {
+			s.Grids[i] = state
+			return
+		}
+	}
+	s.Grids = append(s.Grids, state)
+}
+
+// Grid returns a copy of the grid stored under name
+func (s *Snapshot) Grid(name string) (physics.Grid, bool) {
+	for _, g := range s.Grids {
+		if g.Name == name {
+			if g.validate() != nil {
+				return nil, false
+			}
+			grid := physics.NewGrid(g.Width, g.Height)
+			for i := 0; i < g.Width; i++ {
+				copy(grid[i], g.Data[i*g.Height:(i+1)*g.Height])
+			}
+			return grid, true
+		}
+	}
+	return nil, false
+}
+
+// Save writes the snapshot to path in the binary container format, replacing
+// any existing file atomically
+func Save(path string, s *Snapshot) error {
+	var buf bytes.Buffer
+	if err := Encode(&buf, s); err != nil {
+		return fmt.Errorf("failed to encode snapshot: %v", err)
+	}
+
+	tmpPath := path + ".tmp"
+	if err := os.WriteFile(tmpPath, buf.Bytes(), 0o644); err != nil {
+		return fmt.Errorf("failed to write snapshot: %v", err)
+	}
+	if err := os.Rename(tmpPath, path); err != nil {
+		_ = os.Remove(tmpPath)
+		return fmt.Errorf("failed to finalize snapshot: %v", err)
+	}
+
+	return nil
+}
+
+// Load reads a snapshot from path, accepting both the binary container and
+// version 1 JSON snapshots
+func Load(path string) (*Snapshot, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read snapshot: %v", err)
+	}
+	return Parse(data)
+}
+
+// Parse decodes a snapshot held in memory, accepting both the binary
+// container and version 1 JSON snapshots
+func Parse(data []byte) (*Snapshot, error) {
+	if IsBinary(data) {
+		s, err := Decode(bytes.NewReader(data))
+		if err != nil {
+			return nil, fmt.Errorf("failed to decode snapshot: %v", err)
+		}
+		return s, nil
+	}
+
+	var s Snapshot
+	if err := json.Unmarshal(data, &s); err != nil {
+		return nil, fmt.Errorf("failed to decode snapshot: %v", err)
+	}
+	if s.Version > Version {
+		return nil, fmt.Errorf("unsupported snapshot version: %d (max %d)", s.Version, Version)
+	}
+	for _, g := range s.Grids {
+		if err := g.validate(); err != nil {
+			return nil, fmt.Errorf("failed to decode snapshot: %v", err)
+		}
+	}
+
+	return &s, nil
+}
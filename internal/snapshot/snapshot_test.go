@@ -0,0 +1,89 @@
+package snapshot
+
+import (
+	"os"
+	"path/filepath"
+	"relativity_simulation_2d/internal/config"
+	"relativity_simulation_2d/internal/physics"
+	"testing"
+)
+
+// TestSnapshotRoundTrip tests saving and loading a snapshot
+func TestSnapshotRoundTrip(t *testing.T) {
+	cfg := config.DefaultConfig()
+	particles := []*physics.Particle{
+		physics.NewParticle(10.0, 1.0, 0, -2.0, 0.5, 0, 0.25),
+		physics.NewParticle(20.0, -3.0, 0, 4.0, 0, 0, -1.0),
+	}
+
+	snap := New(cfg, particles, 42, 1.5)
+	path := filepath.Join(t.TempDir(), "state.json")
+
+	if err := Save(path, snap); err != nil {
+		t.Fatalf("Save failed: %v", err)
+	}
+
+	loaded, err := Load(path)
+	if err != nil {
+		t.Fatalf("Load failed: %v", err)
+	}
+
+	if loaded.Version != Version {
+		t.Errorf("Expected version %d, got %d", Version, loaded.Version)
+	}
+	if loaded.Step != 42 || loaded.SimTime != 1.5 {
+		t.Errorf("Expected step 42 at t=1.5, got step %d at t=%f", loaded.Step, loaded.SimTime)
+	}
+	if loaded.Config == nil || loaded.Config.NumParticles != cfg.NumParticles {
+		t.Error("Config not preserved")
+	}
+
+	restored := loaded.Restore()
+	if len(restored) != len(particles) {
+		t.Fatalf("Expected %d particles, got %d", len(particles), len(restored))
+	}
+	for i := range particles {
+		if restored[i].Position != particles[i].Position || restored[i].Velocity != particles[i].Velocity {
+			t.Errorf("Particle %d state mismatch: got %+v, want %+v", i, restored[i], particles[i])
+		}
+		if restored[i].Mass != particles[i].Mass {
+			t.Errorf("Particle %d mass mismatch: got %f, want %f", i, restored[i].Mass, particles[i].Mass)
+		}
+	}
+}
+
+// TestSnapshotIsIndependentCopy tests that snapshots do not alias live particles
+func TestSnapshotIsIndependentCopy(t *testing.T) {
+	particles := []*physics.Particle{physics.NewParticle(1.0, 0, 0, 0, 0, 0, 0)}
+	snap := New(nil, particles, 0, 0)
+
+	particles[0].Position.X = 100.0
+	if snap.Particles[0].Position[0] != 0 {
+		t.Error("Snapshot should not change when live particles move")
+	}
+}
+
+// TestLoadErrors tests loading missing and corrupt snapshots
+func TestLoadErrors(t *testing.T) {
+	dir := t.TempDir()
+
+	if _, err := Load(filepath.Join(dir, "missing.json")); err == nil {
+		t.Error("Expected error for missing file")
+	}
+
+	corrupt := filepath.Join(dir, "corrupt.json")
+	if err := os.WriteFile(corrupt, []byte("{not json"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := Load(corrupt); err == nil {
+		t.Error("Expected error for corrupt file")
+	}
+
+	future := filepath.Join(dir, "future.json")
+	if err := os.WriteFile(future, []byte(`{"version": 999}`), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := Load(future); err == nil {
+		t.Error("Expected error for unsupported version")
+	}
+}
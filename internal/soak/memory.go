@@ -0,0 +1,39 @@
+package soak
+
+import (
+	"runtime"
+	"runtime/metrics"
+)
+
+// Runtime metrics read for each sample
+const (
+	metricAllocs   = "/gc/heap/allocs:bytes"
+	metricHeap     = "/memory/classes/heap/objects:bytes"
+	metricTotal    = "/memory/classes/total:bytes"
+	metricReleased = "/memory/classes/heap/released:bytes"
+)
+
+// ReadMemory returns the memory use of this process. Resident memory comes
+// from the operating system where it is known, and otherwise is estimated
+// as the memory the Go runtime has mapped and not returned
+func ReadMemory() Memory {
+	samples := []metrics.Sample{{Name: metricAllocs}, {Name: metricHeap}, {Name: metricTotal}, {Name: metricReleased}}
+	metrics.Read(samples)
+	value := func(i int) uint64 {
+		if samples[i].Value.Kind() != metrics.KindUint64 {
+			return 0
+		}
+		return samples[i].Value.Uint64()
+	}
+	mem := Memory{
+		Allocs:     value(0),
+		Heap:       value(1),
+		Goroutines: runtime.NumGoroutine(),
+	}
+	if rss, ok := residentMemory(); ok {
+		mem.RSS = rss
+	} else if total, released := value(2), value(3); total > released {
+		mem.RSS = total - released
+	}
+	return mem
+}
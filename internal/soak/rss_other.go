@@ -0,0 +1,9 @@
+//go:build !linux
+
+package soak
+
+// residentMemory is unknown outside Linux, where ReadMemory estimates it
+// from the Go runtime
+func residentMemory() (uint64, bool) {
+	return 0, false
+}
@@ -0,0 +1,311 @@
+package soak
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+	"math"
+	"os"
+	"path/filepath"
+	"relativity_simulation_2d/internal/provenance"
+	"strconv"
+	"time"
+)
+
+// DefaultInterval is the number of seconds between samples
+const DefaultInterval = 60
+
+// Warmup is the number of samples left out of the trends while caches,
+// pools and GPU plans fill up at the start
+const Warmup = 3
+
+// MinSamples is the number of samples after the warm-up a trend needs
+// before it can stop the run, so a single spike cannot
+const MinSamples = 5
+
+// Window is the number of latest samples a trend is fitted to, so growth
+// that starts late in a long run is not averaged away
+const Window = 60
+
+// Metric names
+const (
+	MetricRSS         = "rss"
+	MetricGPUMemory   = "gpu memory"
+	MetricAllocRate   = "allocation rate"
+	MetricEnergyDrift = "energy drift"
+)
+
+// Thresholds are the growth rates per hour above which a metric's trend
+// stops the run (0 = unwatched)
+type Thresholds struct {
+	RSS         float64 `json:"rss_mb_per_hour"`
+	GPUMemory   float64 `json:"gpu_memory_mb_per_hour"`
+	AllocRate   float64 `json:"alloc_rate_mb_per_s_per_hour"`
+	EnergyDrift float64 `json:"energy_drift_per_hour"`
+}
+
+// Sample is one measurement of a soak run
+type Sample struct {
+	Elapsed     float64 `json:"elapsed_s"` // Wall-clock seconds since the soak started
+	Step        int64   `json:"step"`
+	SimTime     float64 `json:"sim_time"`
+	RSS         uint64  `json:"rss_bytes"`         // Resident memory of the process
+	Heap        uint64  `json:"heap_bytes"`        // Live Go heap objects
+	AllocRate   float64 `json:"alloc_bytes_per_s"` // Go heap allocations per second since the previous sample
+	GPUMemory   uint64  `json:"gpu_memory_bytes"`  // GPU memory in use (0 = unknown)
+	Goroutines  int     `json:"goroutines"`
+	EnergyDrift float64 `json:"energy_drift"` // |E − E₀| / (K₀ + |W₀|), 0 without a potential
+}
+
+// Trend is the growth of a metric fitted to the latest samples
+type Trend struct {
+	Metric    string  `json:"metric"`
+	Unit      string  `json:"unit"`
+	Slope     float64 `json:"slope_per_hour"` // Least-squares growth per hour
+	Threshold float64 `json:"threshold_per_hour"`
+	Samples   int     `json:"samples"` // Samples fitted
+	Start     float64 `json:"start"`   // Value at the first sample fitted
+	Latest    float64 `json:"latest"`
+}
+
+// Exceeded reports whether the trend is watched, has enough samples and
+// grows faster than its threshold
+func (t Trend) Exceeded() bool {
+	return t.Threshold > 0 && t.Samples >= MinSamples && t.Slope > t.Threshold
+}
+
+// String describes the trend in one line
+func (t Trend) String() string {
+	unit := ""
+	if t.Unit != "" {
+		unit = " " + t.Unit
+	}
+	return fmt.Sprintf("%s grows %.3g%s per hour over %d samples (limit %.3g%s)", t.Metric, t.Slope, unit, t.Samples, t.Threshold, unit)
+}
+
+// Violation is a metric that trended upward beyond its threshold, with
+// everything measured so far
+type Violation struct {
+	Time       time.Time            `json:"time"`
+	Trend      Trend                `json:"trend"`  // The metric that stopped the run
+	Trends     []Trend              `json:"trends"` // Every metric's trend at that sample
+	Thresholds Thresholds           `json:"thresholds"`
+	Samples    []Sample             `json:"samples"`
+	Metadata   *provenance.Metadata `json:"metadata,omitempty"`
+}
+
+// Error describes the violation in one line
+func (v *Violation) Error() string {
+	return fmt.Sprintf("%v at step %d", v.Trend, v.Samples[len(v.Samples)-1].Step)
+}
+
+// Memory is the memory use of the process
+type Memory struct {
+	RSS        uint64 // Resident memory
+	Heap       uint64 // Live Go heap objects
+	Allocs     uint64 // Go heap bytes allocated since the process started
+	Goroutines int
+}
+
+// Options configures a Monitor
+type Options struct {
+	Interval   time.Duration         // Time between samples (0 = DefaultInterval seconds)
+	Thresholds Thresholds            // Growth that stops the run
+	GPUMemory  func() (uint64, bool) // GPU memory in use, if known (nil = unwatched)
+	ReadMemory func() Memory         // Memory of the process (nil = ReadMemory)
+	Log        io.Writer             // Receives the samples as CSV (nil = none)
+}
+
+// Monitor samples a long run at a fixed wall-clock interval and watches
+// the trends of its memory use and energy drift
+type Monitor struct {
+	opts       Options
+	start      time.Time
+	next       time.Time
+	last       time.Time
+	lastAllocs uint64
+	energy     float64 // Total energy of the first sample with a potential
+	scale      float64 // Its K + |W| (0 = no reference yet)
+	samples    []Sample
+	log        *csv.Writer
+}
+
+// NewMonitor creates a monitor whose soak starts at start
+func NewMonitor(opts Options, start time.Time) *Monitor {
+	if opts.Interval <= 0 {
+		opts.Interval = DefaultInterval * time.Second
+	}
+	if opts.ReadMemory == nil {
+		opts.ReadMemory = ReadMemory
+	}
+	m := &Monitor{opts: opts, start: start, next: start.Add(opts.Interval), last: start}
+	m.lastAllocs = opts.ReadMemory().Allocs
+	if opts.Log != nil {
+		m.log = csv.NewWriter(opts.Log)
+	}
+	return m
+}
+
+// Due reports whether a sample is due at now
+func (m *Monitor) Due(now time.Time) bool {
+	return !now.Before(m.next)
+}
+
+// Samples returns the samples taken so far
+func (m *Monitor) Samples() []Sample {
+	return m.samples
+}
+
+// Measure takes a sample at now of the run at step, with the kinetic and
+// potential energy of its particles (hasPotential false = no potential)
+func (m *Monitor) Measure(now time.Time, step int64, simTime, kinetic, potential float64, hasPotential bool) Sample {
+	mem := m.opts.ReadMemory()
+	s := Sample{
+		Elapsed:    now.Sub(m.start).Seconds(),
+		Step:       step,
+		SimTime:    simTime,
+		RSS:        mem.RSS,
+		Heap:       mem.Heap,
+		Goroutines: mem.Goroutines,
+	}
+	if seconds := now.Sub(m.last).Seconds(); seconds > 0 && mem.Allocs >= m.lastAllocs {
+		s.AllocRate = float64(mem.Allocs-m.lastAllocs) / seconds
+	}
+	m.last, m.lastAllocs = now, mem.Allocs
+	if late := now.Sub(m.next); late >= 0 {
+		m.next = m.next.Add((late/m.opts.Interval + 1) * m.opts.Interval)
+	}
+	if m.opts.GPUMemory != nil {
+		if used, ok := m.opts.GPUMemory(); ok {
+			s.GPUMemory = used
+		}
+	}
+	if hasPotential {
+		if m.scale == 0 {
+			m.energy, m.scale = kinetic+potential, kinetic+math.Abs(potential)
+		}
+		if m.scale > 0 {
+			s.EnergyDrift = math.Abs(kinetic+potential-m.energy) / m.scale
+		}
+	}
+	return s
+}
+
+// Record adds a sample and writes it to the log
+func (m *Monitor) Record(s Sample) error {
+	if m.log != nil {
+		if len(m.samples) == 0 {
+			if err := m.log.Write(CSVHeader); err != nil {
+				return err
+			}
+		}
+		if err := m.log.Write(s.csvRow()); err != nil {
+			return err
+		}
+		m.log.Flush()
+		if err := m.log.Error(); err != nil {
+			return err
+		}
+	}
+	m.samples = append(m.samples, s)
+	return nil
+}
+
+// Trends fits the growth of every metric to the latest samples after the
+// warm-up. GPU memory is left out while it is unknown
+func (m *Monitor) Trends() []Trend {
+	samples := m.samples[min(Warmup, len(m.samples)):]
+	if len(samples) > Window {
+		samples = samples[len(samples)-Window:]
+	}
+	const mb = 1 << 20
+	th := m.opts.Thresholds
+	trends := []Trend{
+		trend(MetricRSS, "MB", th.RSS, samples, func(s Sample) float64 { return float64(s.RSS) / mb }),
+		trend(MetricAllocRate, "MB/s", th.AllocRate, samples, func(s Sample) float64 { return s.AllocRate / mb }),
+		trend(MetricEnergyDrift, "", th.EnergyDrift, samples, func(s Sample) float64 { return s.EnergyDrift }),
+	}
+	if len(samples) > 0 && samples[0].GPUMemory > 0 {
+		trends = append(trends, trend(MetricGPUMemory, "MB", th.GPUMemory, samples, func(s Sample) float64 { return float64(s.GPUMemory) / mb }))
+	}
+	return trends
+}
+
+// Check returns the first metric trending upward beyond its threshold, or
+// nil
+func (m *Monitor) Check(now time.Time) *Violation {
+	trends := m.Trends()
+	for _, t := range trends {
+		if t.Exceeded() {
+			return &Violation{
+				Time:       now,
+				Trend:      t,
+				Trends:     trends,
+				Thresholds: m.opts.Thresholds,
+				Samples:    append([]Sample(nil), m.samples...),
+			}
+		}
+	}
+	return nil
+}
+
+// trend fits the least-squares growth per hour of value over the samples
+func trend(metric, unit string, threshold float64, samples []Sample, value func(Sample) float64) Trend {
+	t := Trend{Metric: metric, Unit: unit, Threshold: threshold, Samples: len(samples)}
+	if len(samples) == 0 {
+		return t
+	}
+	t.Start, t.Latest = value(samples[0]), value(samples[len(samples)-1])
+	var meanX, meanY float64
+	for _, s := range samples {
+		meanX += s.Elapsed / 3600
+		meanY += value(s)
+	}
+	meanX /= float64(len(samples))
+	meanY /= float64(len(samples))
+	var cov, vari float64
+	for _, s := range samples {
+		dx := s.Elapsed/3600 - meanX
+		cov += dx * (value(s) - meanY)
+		vari += dx * dx
+	}
+	if vari > 0 {
+		t.Slope = cov / vari
+	}
+	return t
+}
+
+// CSVHeader is the header row of the soak log
+var CSVHeader = []string{"elapsed_s", "step", "sim_time", "rss_bytes", "heap_bytes", "alloc_bytes_per_s", "gpu_memory_bytes", "goroutines", "energy_drift"}
+
+// csvRow formats the sample as a row of the soak log
+func (s Sample) csvRow() []string {
+	return []string{
+		strconv.FormatFloat(s.Elapsed, 'f', 1, 64),
+		strconv.FormatInt(s.Step, 10),
+		strconv.FormatFloat(s.SimTime, 'g', -1, 64),
+		strconv.FormatUint(s.RSS, 10),
+		strconv.FormatUint(s.Heap, 10),
+		strconv.FormatFloat(s.AllocRate, 'f', 0, 64),
+		strconv.FormatUint(s.GPUMemory, 10),
+		strconv.Itoa(s.Goroutines),
+		strconv.FormatFloat(s.EnergyDrift, 'g', 6, 64),
+	}
+}
+
+// WriteReport writes the violation as JSON into dir and returns its path
+func WriteReport(dir string, v *Violation) (string, error) {
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return "", fmt.Errorf("failed to create report directory: %v", err)
+	}
+	data, err := json.MarshalIndent(v, "", "  ")
+	if err != nil {
+		return "", fmt.Errorf("failed to encode soak report: %v", err)
+	}
+	path := filepath.Join(dir, fmt.Sprintf("soak_%s_step%d.json", v.Time.Format("20060102_150405"), v.Samples[len(v.Samples)-1].Step))
+	if err := os.WriteFile(path, append(data, '\n'), 0o644); err != nil {
+		return "", fmt.Errorf("failed to write soak report: %v", err)
+	}
+	return path, nil
+}
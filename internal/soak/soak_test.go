@@ -0,0 +1,203 @@
+package soak
+
+import (
+	"encoding/json"
+	"math"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+// fakeMemory returns a ReadMemory whose resident memory grows by growth
+// bytes on each read, allocating alloc bytes per read
+func fakeMemory(growth, alloc uint64) func() Memory {
+	var mem Memory
+	return func() Memory {
+		mem.RSS += growth
+		mem.Allocs += alloc
+		return mem
+	}
+}
+
+// record takes n samples a minute apart with the given energies
+func record(t *testing.T, m *Monitor, start time.Time, n int, kinetic, potential float64) {
+	t.Helper()
+	for i := 1; i <= n; i++ {
+		s := m.Measure(start.Add(time.Duration(i)*time.Minute), int64(i*100), float64(i), kinetic, potential, true)
+		if err := m.Record(s); err != nil {
+			t.Fatal(err)
+		}
+	}
+}
+
+// TestMonitorDue tests that samples fall due at each interval
+func TestMonitorDue(t *testing.T) {
+	start := time.Unix(0, 0)
+	m := NewMonitor(Options{Interval: time.Minute, ReadMemory: fakeMemory(0, 0)}, start)
+	if m.Due(start.Add(59 * time.Second)) {
+		t.Error("Expected no sample due before the first interval")
+	}
+	if !m.Due(start.Add(time.Minute)) {
+		t.Error("Expected a sample due after the first interval")
+	}
+	m.Measure(start.Add(150*time.Second), 1, 0, 0, 0, false)
+	if m.Due(start.Add(170*time.Second)) || !m.Due(start.Add(180*time.Second)) {
+		t.Error("Expected the next sample due at the next whole interval")
+	}
+}
+
+// TestMonitorMeasure tests the allocation rate and the energy drift of samples
+func TestMonitorMeasure(t *testing.T) {
+	start := time.Unix(0, 0)
+	m := NewMonitor(Options{ReadMemory: fakeMemory(1, 6000)}, start)
+
+	first := m.Measure(start.Add(time.Minute), 1, 0, 2, -1, true)
+	if first.AllocRate != 100 {
+		t.Errorf("Expected 100 bytes/s allocated, got %g", first.AllocRate)
+	}
+	if first.EnergyDrift != 0 {
+		t.Errorf("Expected no drift at the first sample, got %g", first.EnergyDrift)
+	}
+	second := m.Measure(start.Add(2*time.Minute), 2, 0, 2.3, -1, true)
+	if math.Abs(second.EnergyDrift-0.1) > 1e-12 {
+		t.Errorf("Expected a drift of 0.3/3, got %g", second.EnergyDrift)
+	}
+	if second.GPUMemory != 0 {
+		t.Errorf("Expected unknown GPU memory, got %d", second.GPUMemory)
+	}
+}
+
+// TestMonitorStableRun tests that flat metrics pass every check
+func TestMonitorStableRun(t *testing.T) {
+	start := time.Unix(0, 0)
+	m := NewMonitor(Options{
+		Thresholds: Thresholds{RSS: 64, AllocRate: 1, EnergyDrift: 0.01},
+		ReadMemory: fakeMemory(0, 1<<20),
+	}, start)
+	record(t, m, start, 30, 1, -1)
+	if v := m.Check(start.Add(30 * time.Minute)); v != nil {
+		t.Errorf("Expected a stable run to pass, got %v", v)
+	}
+}
+
+// TestMonitorLeak tests that growing resident memory is reported only once
+// the trend has enough samples after the warm-up
+func TestMonitorLeak(t *testing.T) {
+	start := time.Unix(0, 0)
+	// 4 MB per minute is 240 MB per hour
+	m := NewMonitor(Options{
+		Thresholds: Thresholds{RSS: 64},
+		ReadMemory: fakeMemory(4<<20, 0),
+	}, start)
+	record(t, m, start, Warmup+MinSamples-1, 1, -1)
+	if v := m.Check(start); v != nil {
+		t.Fatalf("Expected no violation before %d samples, got %v", MinSamples, v)
+	}
+	n := Warmup + MinSamples
+	if err := m.Record(m.Measure(start.Add(time.Duration(n)*time.Minute), int64(n*100), float64(n), 1, -1, true)); err != nil {
+		t.Fatal(err)
+	}
+	v := m.Check(start)
+	if v == nil {
+		t.Fatal("Expected growing memory to be reported")
+	}
+	if v.Trend.Metric != MetricRSS || len(v.Samples) != Warmup+MinSamples || len(v.Trends) != 3 {
+		t.Errorf("Unexpected violation: %+v", v)
+	}
+	if !strings.HasPrefix(v.Error(), "rss grows") {
+		t.Errorf("Expected the error to name the metric, got %q", v.Error())
+	}
+}
+
+// TestMonitorGPUMemory tests that GPU memory is watched once it is known
+func TestMonitorGPUMemory(t *testing.T) {
+	start := time.Unix(0, 0)
+	var used uint64
+	m := NewMonitor(Options{
+		Thresholds: Thresholds{RSS: 64, GPUMemory: 64},
+		ReadMemory: fakeMemory(0, 0),
+		GPUMemory: func() (uint64, bool) {
+			used += 8 << 20
+			return used, true
+		},
+	}, start)
+	record(t, m, start, 10, 1, -1)
+	v := m.Check(start)
+	if v == nil || v.Trend.Metric != MetricGPUMemory {
+		t.Fatalf("Expected growing GPU memory to be reported, got %v", v)
+	}
+	if math.Abs(v.Trend.Slope-480) > 1e-6 {
+		t.Errorf("Expected 480 MB per hour, got %g", v.Trend.Slope)
+	}
+}
+
+// TestMonitorEnergyDrift tests that a run whose energy walks away is reported
+func TestMonitorEnergyDrift(t *testing.T) {
+	start := time.Unix(0, 0)
+	m := NewMonitor(Options{Thresholds: Thresholds{EnergyDrift: 0.01}, ReadMemory: fakeMemory(0, 0)}, start)
+	for i := 1; i <= 10; i++ {
+		s := m.Measure(start.Add(time.Duration(i)*time.Minute), int64(i), 0, 1+0.01*float64(i), -1, true)
+		if err := m.Record(s); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if v := m.Check(start); v == nil || v.Trend.Metric != MetricEnergyDrift {
+		t.Errorf("Expected the energy drift to be reported, got %v", v)
+	}
+}
+
+// TestMonitorLog tests that samples are written as CSV with a header
+func TestMonitorLog(t *testing.T) {
+	start := time.Unix(0, 0)
+	var log strings.Builder
+	m := NewMonitor(Options{ReadMemory: fakeMemory(1024, 0), Log: &log}, start)
+	record(t, m, start, 2, 1, -1)
+	lines := strings.Split(strings.TrimSpace(log.String()), "\n")
+	if len(lines) != 3 || lines[0] != strings.Join(CSVHeader, ",") {
+		t.Fatalf("Expected a header and two rows, got %q", log.String())
+	}
+	if !strings.HasPrefix(lines[2], "120.0,200,2,3072,") {
+		t.Errorf("Unexpected second row: %q", lines[2])
+	}
+}
+
+// TestWriteReport tests that a violation is written as JSON
+func TestWriteReport(t *testing.T) {
+	dir := filepath.Join(t.TempDir(), "reports")
+	start := time.Unix(0, 0)
+	m := NewMonitor(Options{Thresholds: Thresholds{RSS: 1}, ReadMemory: fakeMemory(1<<20, 0)}, start)
+	record(t, m, start, 10, 1, -1)
+	v := m.Check(start)
+	if v == nil {
+		t.Fatal("Expected a violation")
+	}
+
+	path, err := WriteReport(dir, v)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.HasPrefix(filepath.Base(path), "soak_") || !strings.HasSuffix(path, "_step1000.json") {
+		t.Errorf("Unexpected report name: %s", path)
+	}
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var loaded Violation
+	if err := json.Unmarshal(data, &loaded); err != nil {
+		t.Fatal(err)
+	}
+	if loaded.Trend.Metric != MetricRSS || len(loaded.Samples) != 10 || loaded.Thresholds.RSS != 1 {
+		t.Errorf("Unexpected report: %+v", loaded)
+	}
+}
+
+// TestReadMemory tests that the process memory is read
+func TestReadMemory(t *testing.T) {
+	mem := ReadMemory()
+	if mem.RSS == 0 || mem.Heap == 0 || mem.Allocs == 0 || mem.Goroutines == 0 {
+		t.Errorf("Expected the memory of this process, got %+v", mem)
+	}
+}
@@ -0,0 +1,187 @@
+package sweep
+
+import (
+	"encoding/csv"
+	"io"
+	"math"
+	"strconv"
+)
+
+// Stats summarizes one quantity across the runs of an ensemble
+type Stats struct {
+	N      int
+	Mean   float64
+	StdDev float64 // Sample standard deviation (0 for fewer than two values)
+	Min    float64
+	Max    float64
+}
+
+// Ensemble aggregates the successful runs of one configuration across seeds
+type Ensemble struct {
+	G         float64
+	Particles int
+	Runs      int
+	Failed    int
+
+	KineticDrift   Stats // Relative kinetic energy change over the run
+	FinalKinetic   Stats
+	Momentum       Stats // Final total momentum magnitude
+	FinalGyration  Stats // Final radius of gyration
+	GyrationChange Stats // Final / initial radius of gyration (< 1 means the system contracted)
+}
+
+// ComputeStats computes mean, sample standard deviation and range of values
+func ComputeStats(values []float64) Stats {
+	s := Stats{N: len(values)}
+	if s.N == 0 {
+		return s
+	}
+
+	s.Min, s.Max = values[0], values[0]
+	for _, v := range values {
+		s.Mean += v
+		s.Min = math.Min(s.Min, v)
+		s.Max = math.Max(s.Max, v)
+	}
+	s.Mean /= float64(s.N)
+
+	if s.N > 1 {
+		var sumSq float64
+		for _, v := range values {
+			d := v - s.Mean
+			sumSq += d * d
+		}
+		s.StdDev = math.Sqrt(sumSq / float64(s.N-1))
+	}
+
+	return s
+}
+
+// StdErr returns the standard error of the mean
+func (s Stats) StdErr() float64 {
+	if s.N < 2 {
+		return 0
+	}
+	return s.StdDev / math.Sqrt(float64(s.N))
+}
+
+// Significance returns |mean| in units of the standard error, or 0 when the
+// spread is unknown. Values above ~2 indicate a systematic effect rather than
+// seed-to-seed noise
+func (s Stats) Significance() float64 {
+	stdErr := s.StdErr()
+	if stdErr == 0 {
+		return 0
+	}
+	return math.Abs(s.Mean) / stdErr
+}
+
+// Aggregate groups results by configuration (G, particle count) and computes
+// ensemble statistics over seeds. Groups are returned in first-seen order
+func Aggregate(results []Result) []Ensemble {
+	type key struct {
+		g         float64
+		particles int
+	}
+
+	var order []key
+	groups := make(map[key][]Result)
+	for _, r := range results {
+		k := key{r.Job.G, r.Job.Particles}
+		if _, ok := groups[k]; !ok {
+			order = append(order, k)
+		}
+		groups[k] = append(groups[k], r)
+	}
+
+	ensembles := make([]Ensemble, 0, len(order))
+	for _, k := range order {
+		e := Ensemble{G: k.g, Particles: k.particles}
+
+		var drift, kinetic, momentum, gyration, gyrationChange []float64
+		for _, r := range groups[k] {
+			e.Runs++
+			if r.Err != nil {
+				e.Failed++
+				continue
+			}
+			s := r.Summary
+			drift = append(drift, s.RelativeKineticDelta)
+			kinetic = append(kinetic, s.FinalKinetic)
+			momentum = append(momentum, math.Hypot(s.FinalMomentumX, s.FinalMomentumZ))
+			gyration = append(gyration, s.FinalGyration)
+			if s.InitialGyration > 0 {
+				gyrationChange = append(gyrationChange, s.FinalGyration/s.InitialGyration)
+			}
+		}
+
+		e.KineticDrift = ComputeStats(drift)
+		e.FinalKinetic = ComputeStats(kinetic)
+		e.Momentum = ComputeStats(momentum)
+		e.FinalGyration = ComputeStats(gyration)
+		e.GyrationChange = ComputeStats(gyrationChange)
+		ensembles = append(ensembles, e)
+	}
+
+	return ensembles
+}
+
+// EnsembleHeader is the column header written by WriteEnsembles
+var EnsembleHeader = []string{
+	"G", "particles", "runs", "failed",
+	"drift_mean", "drift_std", "drift_stderr", "drift_significance", "drift_min", "drift_max",
+	"final_kinetic_mean", "final_kinetic_std",
+	"momentum_mean", "momentum_std",
+	"gyration_mean", "gyration_std",
+	"gyration_change_mean", "gyration_change_std", "gyration_change_significance",
+}
+
+// WriteEnsembles writes one CSV row per ensemble
+func WriteEnsembles(w io.Writer, ensembles []Ensemble) error {
+	writer := csv.NewWriter(w)
+	if err := writer.Write(EnsembleHeader); err != nil {
+		return err
+	}
+
+	for _, e := range ensembles {
+		// Contraction is measured relative to no change (ratio 1)
+		change := e.GyrationChange
+		change.Mean -= 1
+		row := []string{
+			formatFloat(e.G),
+			strconv.Itoa(e.Particles),
+			strconv.Itoa(e.Runs),
+			strconv.Itoa(e.Failed),
+			formatFloat(e.KineticDrift.Mean),
+			formatFloat(e.KineticDrift.StdDev),
+			formatFloat(e.KineticDrift.StdErr()),
+			formatFloat(e.KineticDrift.Significance()),
+			formatFloat(e.KineticDrift.Min),
+			formatFloat(e.KineticDrift.Max),
+			formatFloat(e.FinalKinetic.Mean),
+			formatFloat(e.FinalKinetic.StdDev),
+			formatFloat(e.Momentum.Mean),
+			formatFloat(e.Momentum.StdDev),
+			formatFloat(e.FinalGyration.Mean),
+			formatFloat(e.FinalGyration.StdDev),
+			formatFloat(e.GyrationChange.Mean),
+			formatFloat(e.GyrationChange.StdDev),
+			formatFloat(change.Significance()),
+		}
+		if err := writer.Write(row); err != nil {
+			return err
+		}
+	}
+
+	writer.Flush()
+	return writer.Error()
+}
+
+// SeedRange returns k consecutive seeds starting at base
+func SeedRange(base int64, k int) []int64 {
+	seeds := make([]int64, 0, k)
+	for i := 0; i < k; i++ {
+		seeds = append(seeds, base+int64(i))
+	}
+	return seeds
+}
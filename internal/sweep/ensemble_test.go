@@ -0,0 +1,116 @@
+package sweep
+
+import (
+	"bytes"
+	"encoding/csv"
+	"fmt"
+	"math"
+	"testing"
+)
+
+// TestComputeStats tests mean, sample standard deviation and range
+func TestComputeStats(t *testing.T) {
+	s := ComputeStats([]float64{2, 4, 4, 4, 5, 5, 7, 9})
+
+	if s.N != 8 || s.Mean != 5 || s.Min != 2 || s.Max != 9 {
+		t.Errorf("Unexpected stats: %+v", s)
+	}
+	if math.Abs(s.StdDev-math.Sqrt(32.0/7.0)) > 1e-12 {
+		t.Errorf("Expected sample stddev %f, got %f", math.Sqrt(32.0/7.0), s.StdDev)
+	}
+	if math.Abs(s.StdErr()-s.StdDev/math.Sqrt(8)) > 1e-12 {
+		t.Errorf("Unexpected standard error %f", s.StdErr())
+	}
+
+	single := ComputeStats([]float64{3})
+	if single.StdDev != 0 || single.StdErr() != 0 || single.Significance() != 0 {
+		t.Errorf("Single value should have no spread: %+v", single)
+	}
+	if empty := ComputeStats(nil); empty.N != 0 || empty.Mean != 0 {
+		t.Errorf("Unexpected empty stats: %+v", empty)
+	}
+}
+
+// TestSignificance tests separating systematic drift from noise
+func TestSignificance(t *testing.T) {
+	systematic := ComputeStats([]float64{0.10, 0.11, 0.09, 0.10})
+	noise := ComputeStats([]float64{0.10, -0.12, 0.05, -0.03})
+
+	if systematic.Significance() < 2 {
+		t.Errorf("Consistent drift should be significant, got %f", systematic.Significance())
+	}
+	if noise.Significance() > 2 {
+		t.Errorf("Zero-mean scatter should not be significant, got %f", noise.Significance())
+	}
+}
+
+// TestAggregate tests grouping results by configuration
+func TestAggregate(t *testing.T) {
+	jobs := Matrix{GValues: []float64{1, 2}, ParticleCounts: []int{100}, Seeds: SeedRange(10, 3)}.Jobs()
+
+	var results []Result
+	for _, job := range jobs {
+		r := Result{Job: job, Summary: Summary{
+			RelativeKineticDelta: job.G * 0.01 * float64(job.Seed-10),
+			FinalKinetic:         job.G,
+			FinalMomentumX:       3,
+			FinalMomentumZ:       4,
+			InitialGyration:      10,
+			FinalGyration:        5,
+		}}
+		if job.G == 2 && job.Seed == 12 {
+			r.Err = fmt.Errorf("crashed")
+		}
+		results = append(results, r)
+	}
+
+	ensembles := Aggregate(results)
+	if len(ensembles) != 2 {
+		t.Fatalf("Expected 2 ensembles, got %d", len(ensembles))
+	}
+
+	first := ensembles[0]
+	if first.G != 1 || first.Particles != 100 || first.Runs != 3 || first.Failed != 0 {
+		t.Errorf("Unexpected first ensemble: %+v", first)
+	}
+	if math.Abs(first.KineticDrift.Mean-0.01) > 1e-12 {
+		t.Errorf("Expected mean drift 0.01, got %f", first.KineticDrift.Mean)
+	}
+	if first.Momentum.Mean != 5 || first.GyrationChange.Mean != 0.5 {
+		t.Errorf("Unexpected momentum/gyration stats: %+v", first)
+	}
+
+	second := ensembles[1]
+	if second.Runs != 3 || second.Failed != 1 || second.KineticDrift.N != 2 {
+		t.Errorf("Failed runs should be excluded from statistics: %+v", second)
+	}
+}
+
+// TestWriteEnsembles tests the aggregated CSV output
+func TestWriteEnsembles(t *testing.T) {
+	ensembles := []Ensemble{{G: 1, Particles: 50, Runs: 4, KineticDrift: ComputeStats([]float64{1, 2, 3})}}
+
+	var buf bytes.Buffer
+	if err := WriteEnsembles(&buf, ensembles); err != nil {
+		t.Fatalf("WriteEnsembles failed: %v", err)
+	}
+
+	rows, err := csv.NewReader(&buf).ReadAll()
+	if err != nil {
+		t.Fatalf("Output is not valid CSV: %v", err)
+	}
+	if len(rows) != 2 || len(rows[1]) != len(EnsembleHeader) {
+		t.Fatalf("Unexpected output shape: %v", rows)
+	}
+	if rows[1][1] != "50" || rows[1][4] != "2" || rows[1][5] != "1" {
+		t.Errorf("Unexpected ensemble row: %v", rows[1])
+	}
+}
+
+// TestSeedRange tests consecutive seed generation
+func TestSeedRange(t *testing.T) {
+	seeds := SeedRange(5, 3)
+	if len(seeds) != 3 || seeds[0] != 5 || seeds[2] != 7 {
+		t.Errorf("Unexpected seeds: %v", seeds)
+	}
+}
@@ -0,0 +1,282 @@
+package sweep
+
+import (
+	"context"
+	"encoding/csv"
+	"fmt"
+	"io"
+	"os"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Matrix is the set of parameter values to combine in a sweep
+type Matrix struct {
+	GValues        []float64
+	ParticleCounts []int
+	Seeds          []int64
+}
+
+// Job is a single point in the parameter matrix
+type Job struct {
+	ID        int
+	G         float64
+	Particles int
+	Seed      int64
+}
+
+// Summary condenses the diagnostics of one run
+type Summary struct {
+	Steps                int64
+	SimTime              float64
+	InitialKinetic       float64
+	FinalKinetic         float64
+	TotalMass            float64
+	FinalMomentumX       float64
+	FinalMomentumZ       float64
+	MaxSpeed             float64
+	RelativeKineticDelta float64 // (final - initial) / initial kinetic energy (0 if initial is 0)
+	InitialGyration      float64
+	FinalGyration        float64
+}
+
+// Result is the outcome of running one job
+type Result struct {
+	Job      Job
+	Summary  Summary
+	Duration time.Duration
+	Err      error
+}
+
+// Executor runs a single job and returns its result. It should stop the job
+// early when ctx is cancelled
+type Executor func(ctx context.Context, job Job) Result
+
+// Jobs expands the matrix into one job per parameter combination
+func (m Matrix) Jobs() []Job {
+	jobs := make([]Job, 0, len(m.GValues)*len(m.ParticleCounts)*len(m.Seeds))
+	for _, g := range m.GValues {
+		for _, n := range m.ParticleCounts {
+			for _, seed := range m.Seeds {
+				jobs = append(jobs, Job{ID: len(jobs), G: g, Particles: n, Seed: seed})
+			}
+		}
+	}
+	return jobs
+}
+
+// Args returns the simulation command-line arguments for a headless run of the job
+func (j Job) Args(steps int, diagnosticsPath string) []string {
+	return []string{
+		"--headless",
+		"--steps", strconv.Itoa(steps),
+		"--G", strconv.FormatFloat(j.G, 'g', -1, 64),
+		"--particles", strconv.Itoa(j.Particles),
+		"--seed", strconv.FormatInt(j.Seed, 10),
+		"--diagnostics", diagnosticsPath,
+		"--progress-interval", "0",
+	}
+}
+
+// Run executes jobs on the given number of parallel workers, returning results
+// in job order. Once ctx is cancelled no further jobs start; their results
+// carry the context error
+func Run(ctx context.Context, jobs []Job, workers int, execute Executor) []Result {
+	if workers < 1 {
+		workers = 1
+	}
+
+	results := make([]Result, len(jobs))
+	queue := make(chan int)
+
+	var wg sync.WaitGroup
+	for w := 0; w < workers; w++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := range queue {
+				if err := ctx.Err(); err != nil {
+					results[i] = Result{Job: jobs[i], Err: err}
+					continue
+				}
+				results[i] = execute(ctx, jobs[i])
+			}
+		}()
+	}
+
+	for i := range jobs {
+		queue <- i
+	}
+	close(queue)
+	wg.Wait()
+
+	return results
+}
+
+// SummarizeDiagnostics reads a diagnostics CSV written by the headless runner
+func SummarizeDiagnostics(path string) (Summary, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return Summary{}, fmt.Errorf("failed to open diagnostics: %v", err)
+	}
+	defer file.Close()
+
+	rows, err := csv.NewReader(file).ReadAll()
+	if err != nil {
+		return Summary{}, fmt.Errorf("failed to read diagnostics: %v", err)
+	}
+	if len(rows) < 2 {
+		return Summary{}, fmt.Errorf("diagnostics file has no records")
+	}
+
+	columns := make(map[string]int, len(rows[0]))
+	for i, name := range rows[0] {
+		columns[name] = i
+	}
+	first, last := rows[1], rows[len(rows)-1]
+
+	var parseErr error
+	value := func(row []string, name string) float64 {
+		idx, ok := columns[name]
+		if !ok || idx >= len(row) {
+			if parseErr == nil {
+				parseErr = fmt.Errorf("diagnostics missing column %q", name)
+			}
+			return 0
+		}
+		v, err := strconv.ParseFloat(row[idx], 64)
+		if err != nil && parseErr == nil {
+			parseErr = fmt.Errorf("invalid %s value %q", name, row[idx])
+		}
+		return v
+	}
+
+	summary := Summary{
+		Steps:          int64(value(last, "step")),
+		SimTime:        value(last, "sim_time"),
+		InitialKinetic: value(first, "kinetic_energy"),
+		FinalKinetic:   value(last, "kinetic_energy"),
+		TotalMass:      value(last, "total_mass"),
+		FinalMomentumX: value(last, "momentum_x"),
+		FinalMomentumZ: value(last, "momentum_z"),
+		MaxSpeed:       value(last, "max_speed"),
+	}
+	if _, ok := columns["gyration_radius"]; ok {
+		summary.InitialGyration = value(first, "gyration_radius")
+		summary.FinalGyration = value(last, "gyration_radius")
+	}
+	if parseErr != nil {
+		return Summary{}, parseErr
+	}
+	if summary.InitialKinetic != 0 {
+		summary.RelativeKineticDelta = (summary.FinalKinetic - summary.InitialKinetic) / summary.InitialKinetic
+	}
+
+	return summary, nil
+}
+
+// ResultHeader is the column header written by WriteResults
+var ResultHeader = []string{
+	"job", "G", "particles", "seed", "status", "wall_sec", "steps", "sim_time",
+	"initial_kinetic", "final_kinetic", "kinetic_delta", "total_mass",
+	"momentum_x", "momentum_z", "max_speed", "initial_gyration", "final_gyration", "error",
+}
+
+// WriteResults writes one CSV row per result
+func WriteResults(w io.Writer, results []Result) error {
+	writer := csv.NewWriter(w)
+	if err := writer.Write(ResultHeader); err != nil {
+		return err
+	}
+
+	for _, r := range results {
+		status, errText := "ok", ""
+		if r.Err != nil {
+			status, errText = "failed", strings.ReplaceAll(r.Err.Error(), "\n", " ")
+		}
+		s := r.Summary
+		row := []string{
+			strconv.Itoa(r.Job.ID),
+			formatFloat(r.Job.G),
+			strconv.Itoa(r.Job.Particles),
+			strconv.FormatInt(r.Job.Seed, 10),
+			status,
+			formatFloat(r.Duration.Seconds()),
+			strconv.FormatInt(s.Steps, 10),
+			formatFloat(s.SimTime),
+			formatFloat(s.InitialKinetic),
+			formatFloat(s.FinalKinetic),
+			formatFloat(s.RelativeKineticDelta),
+			formatFloat(s.TotalMass),
+			formatFloat(s.FinalMomentumX),
+			formatFloat(s.FinalMomentumZ),
+			formatFloat(s.MaxSpeed),
+			formatFloat(s.InitialGyration),
+			formatFloat(s.FinalGyration),
+			errText,
+		}
+		if err := writer.Write(row); err != nil {
+			return err
+		}
+	}
+
+	writer.Flush()
+	return writer.Error()
+}
+
+// ParseFloatList parses a comma-separated list of floats
+func ParseFloatList(s string) ([]float64, error) {
+	var values []float64
+	for _, field := range splitList(s) {
+		v, err := strconv.ParseFloat(field, 64)
+		if err != nil {
+			return nil, fmt.Errorf("invalid number %q", field)
+		}
+		values = append(values, v)
+	}
+	return values, nil
+}
+
+// ParseIntList parses a comma-separated list of integers
+func ParseIntList(s string) ([]int, error) {
+	var values []int
+	for _, field := range splitList(s) {
+		v, err := strconv.Atoi(field)
+		if err != nil {
+			return nil, fmt.Errorf("invalid integer %q", field)
+		}
+		values = append(values, v)
+	}
+	return values, nil
+}
+
+// ParseInt64List parses a comma-separated list of 64-bit integers
+func ParseInt64List(s string) ([]int64, error) {
+	var values []int64
+	for _, field := range splitList(s) {
+		v, err := strconv.ParseInt(field, 10, 64)
+		if err != nil {
+			return nil, fmt.Errorf("invalid integer %q", field)
+		}
+		values = append(values, v)
+	}
+	return values, nil
+}
+
+// splitList splits a comma-separated list, dropping empty entries
+func splitList(s string) []string {
+	var fields []string
+	for _, field := range strings.Split(s, ",") {
+		if field = strings.TrimSpace(field); field != "" {
+			fields = append(fields, field)
+		}
+	}
+	return fields
+}
+
+// formatFloat formats a float with the shortest exact representation
+func formatFloat(f float64) string {
+	return strconv.FormatFloat(f, 'g', -1, 64)
+}
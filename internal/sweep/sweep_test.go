@@ -0,0 +1,199 @@
+package sweep
+
+import (
+	"bytes"
+	"context"
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync/atomic"
+	"testing"
+)
+
+// TestMatrixJobs tests expansion of the parameter matrix
+func TestMatrixJobs(t *testing.T) {
+	m := Matrix{
+		GValues:        []float64{1, 2},
+		ParticleCounts: []int{10, 20, 30},
+		Seeds:          []int64{7, 8},
+	}
+
+	jobs := m.Jobs()
+	if len(jobs) != 12 {
+		t.Fatalf("Expected 12 jobs, got %d", len(jobs))
+	}
+	for i, job := range jobs {
+		if job.ID != i {
+			t.Errorf("Job %d has ID %d", i, job.ID)
+		}
+	}
+	if jobs[0] != (Job{ID: 0, G: 1, Particles: 10, Seed: 7}) {
+		t.Errorf("Unexpected first job: %+v", jobs[0])
+	}
+	if jobs[11] != (Job{ID: 11, G: 2, Particles: 30, Seed: 8}) {
+		t.Errorf("Unexpected last job: %+v", jobs[11])
+	}
+
+	if len((Matrix{GValues: []float64{1}}).Jobs()) != 0 {
+		t.Error("Matrix with an empty axis should produce no jobs")
+	}
+}
+
+// TestJobArgs tests the headless command line built for a job
+func TestJobArgs(t *testing.T) {
+	job := Job{ID: 3, G: 0.5, Particles: 200, Seed: 42}
+	args := strings.Join(job.Args(1000, "out/run_3.csv"), " ")
+
+	for _, want := range []string{"--headless", "--steps 1000", "--G 0.5", "--particles 200", "--seed 42", "--diagnostics out/run_3.csv"} {
+		if !strings.Contains(args, want) {
+			t.Errorf("Args %q missing %q", args, want)
+		}
+	}
+}
+
+// TestRunParallel tests that all jobs run once and results stay in job order
+func TestRunParallel(t *testing.T) {
+	jobs := Matrix{GValues: []float64{1, 2, 3}, ParticleCounts: []int{10, 20}, Seeds: []int64{1, 2}}.Jobs()
+
+	var calls int32
+	results := Run(context.Background(), jobs, 4, func(_ context.Context, job Job) Result {
+		atomic.AddInt32(&calls, 1)
+		return Result{Job: job, Summary: Summary{Steps: int64(job.ID)}}
+	})
+
+	if int(calls) != len(jobs) {
+		t.Errorf("Expected %d executions, got %d", len(jobs), calls)
+	}
+	for i, r := range results {
+		if r.Job.ID != i || r.Summary.Steps != int64(i) {
+			t.Errorf("Result %d out of order: %+v", i, r)
+		}
+	}
+
+	// Zero workers still runs everything
+	if got := Run(context.Background(), jobs[:2], 0, func(_ context.Context, job Job) Result { return Result{Job: job} }); len(got) != 2 {
+		t.Errorf("Expected 2 results, got %d", len(got))
+	}
+}
+
+// TestRunCancelled tests that jobs queued after cancellation are not started
+func TestRunCancelled(t *testing.T) {
+	jobs := Matrix{GValues: []float64{1}, ParticleCounts: []int{10}, Seeds: []int64{1, 2, 3, 4}}.Jobs()
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	var calls int32
+	results := Run(ctx, jobs, 1, func(ctx context.Context, job Job) Result {
+		atomic.AddInt32(&calls, 1)
+		cancel()
+		return Result{Job: job}
+	})
+
+	if calls != 1 {
+		t.Errorf("Expected 1 execution before cancellation, got %d", calls)
+	}
+	if results[0].Err != nil {
+		t.Errorf("First job should have run: %v", results[0].Err)
+	}
+	for _, r := range results[1:] {
+		if !errors.Is(r.Err, context.Canceled) || r.Job.ID == 0 {
+			t.Errorf("Expected skipped job with context.Canceled, got %+v", r)
+		}
+	}
+}
+
+// TestSummarizeDiagnostics tests reading first/last records from a diagnostics CSV
+func TestSummarizeDiagnostics(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "diag.csv")
+	content := "step,sim_time,particles,total_mass,kinetic_energy,momentum_x,momentum_z,com_x,com_z,max_speed,non_finite,gyration_radius\n" +
+		"0,0,10,5,2,0,0,0,0,1,0,8\n" +
+		"100,1.5,10,5,3,0.1,-0.2,0,0,4,0,6\n"
+	if err := os.WriteFile(path, []byte(content), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	s, err := SummarizeDiagnostics(path)
+	if err != nil {
+		t.Fatalf("SummarizeDiagnostics failed: %v", err)
+	}
+	if s.Steps != 100 || s.SimTime != 1.5 {
+		t.Errorf("Expected step 100 at t=1.5, got %d at t=%f", s.Steps, s.SimTime)
+	}
+	if s.InitialKinetic != 2 || s.FinalKinetic != 3 || s.RelativeKineticDelta != 0.5 {
+		t.Errorf("Unexpected kinetic summary: %+v", s)
+	}
+	if s.TotalMass != 5 || s.FinalMomentumX != 0.1 || s.FinalMomentumZ != -0.2 || s.MaxSpeed != 4 {
+		t.Errorf("Unexpected final values: %+v", s)
+	}
+	if s.InitialGyration != 8 || s.FinalGyration != 6 {
+		t.Errorf("Unexpected gyration summary: %+v", s)
+	}
+
+	// Header-only file has no records
+	if err := os.WriteFile(path, []byte("step,sim_time\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := SummarizeDiagnostics(path); err == nil {
+		t.Error("Expected error for diagnostics without records")
+	}
+
+	if _, err := SummarizeDiagnostics(filepath.Join(t.TempDir(), "missing.csv")); err == nil {
+		t.Error("Expected error for missing file")
+	}
+}
+
+// TestWriteResults tests the combined sweep CSV
+func TestWriteResults(t *testing.T) {
+	results := []Result{
+		{Job: Job{ID: 0, G: 1, Particles: 10, Seed: 1}, Summary: Summary{Steps: 50, FinalKinetic: 2.5}},
+		{Job: Job{ID: 1, G: 2, Particles: 10, Seed: 1}, Err: fmt.Errorf("exit status 1")},
+	}
+
+	var buf bytes.Buffer
+	if err := WriteResults(&buf, results); err != nil {
+		t.Fatalf("WriteResults failed: %v", err)
+	}
+
+	rows, err := csv.NewReader(&buf).ReadAll()
+	if err != nil {
+		t.Fatalf("Output is not valid CSV: %v", err)
+	}
+	if len(rows) != 3 {
+		t.Fatalf("Expected header + 2 rows, got %d", len(rows))
+	}
+	if len(rows[0]) != len(ResultHeader) {
+		t.Errorf("Header has %d columns, expected %d", len(rows[0]), len(ResultHeader))
+	}
+	if rows[1][4] != "ok" || rows[1][6] != "50" {
+		t.Errorf("Unexpected success row: %v", rows[1])
+	}
+	if rows[2][4] != "failed" || rows[2][len(rows[2])-1] != "exit status 1" {
+		t.Errorf("Unexpected failure row: %v", rows[2])
+	}
+}
+
+// TestParseLists tests comma-separated list parsing
+func TestParseLists(t *testing.T) {
+	floats, err := ParseFloatList("1, 2.5,,3e-1")
+	if err != nil || len(floats) != 3 || floats[1] != 2.5 || floats[2] != 0.3 {
+		t.Errorf("Unexpected float list %v (err %v)", floats, err)
+	}
+	ints, err := ParseIntList("100,200")
+	if err != nil || len(ints) != 2 || ints[1] != 200 {
+		t.Errorf("Unexpected int list %v (err %v)", ints, err)
+	}
+	seeds, err := ParseInt64List("9000000000")
+	if err != nil || len(seeds) != 1 || seeds[0] != 9000000000 {
+		t.Errorf("Unexpected int64 list %v (err %v)", seeds, err)
+	}
+
+	if _, err := ParseFloatList("1,x"); err == nil {
+		t.Error("Expected error for invalid float")
+	}
+	if _, err := ParseIntList("1.5"); err == nil {
+		t.Error("Expected error for invalid integer")
+	}
+}
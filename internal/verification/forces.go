@@ -0,0 +1,209 @@
+package verification
+
+import (
+	"encoding/csv"
+	"fmt"
+	"io"
+	"math"
+	"math/rand"
+	"relativity_simulation_2d/internal/physics"
+	"strconv"
+)
+
+// ForceConfig describes a benchmark of particle-mesh forces against direct
+// summation on random configurations. Lengths are in cells
+type ForceConfig struct {
+	GridSizes       []int     // Square grid sizes to test
+	Softenings      []float64 // Direct-summation softening lengths
+	Particles       int       // Particles per configuration
+	Configurations  int       // Random configurations per grid size
+	ClusterFraction float64   // Particles are uniform in a disk of this radius relative to the grid size
+	G               float64
+	Seed            int64
+	Workers         int // Goroutines for direct summation (< 1 = one per CPU)
+}
+
+// DefaultForceConfig returns a benchmark over three grid sizes and softenings
+// around the one-cell PM resolution
+func DefaultForceConfig() ForceConfig {
+	return ForceConfig{
+		GridSizes:       []int{64, 128, 256},
+		Softenings:      []float64{0, 0.5, 1, 2},
+		Particles:       1000,
+		Configurations:  3,
+		ClusterFraction: 0.125,
+		G:               1,
+		Seed:            1,
+		Workers:         0,
+	}
+}
+
+// ForceResult is the PM force error for one grid size and softening. Errors
+// are |a_PM - a_direct| relative to the RMS direct acceleration of each
+// configuration
+type ForceResult struct {
+	GridSize  int
+	Softening float64
+	Samples   int // Particles compared over all configurations
+	RMSError  float64
+	MaxError  float64
+}
+
+// CompareForces runs the benchmark, returning one result per grid size and
+// softening in configuration order. The same configurations, scaled to each
+// grid, are used for every grid size
+func CompareForces(cfg ForceConfig) ([]ForceResult, error) {
+	if cfg.Particles < 2 || cfg.Configurations < 1 {
+		return nil, fmt.Errorf("need at least 2 particles and 1 configuration, got %d and %d", cfg.Particles, cfg.Configurations)
+	}
+	if !(cfg.ClusterFraction > 0) || cfg.ClusterFraction > 0.5 {
+		return nil, fmt.Errorf("invalid cluster fraction %v (want 0 < f <= 0.5)", cfg.ClusterFraction)
+	}
+	if !(cfg.G > 0) {
+		return nil, fmt.Errorf("G must be positive")
+	}
+	if len(cfg.GridSizes) == 0 || len(cfg.Softenings) == 0 {
+		return nil, fmt.Errorf("need at least one grid size and softening")
+	}
+	for _, n := range cfg.GridSizes {
+		if n < 4 {
+			return nil, fmt.Errorf("invalid grid size %d", n)
+		}
+	}
+	for _, eps := range cfg.Softenings {
+		if !(eps >= 0) {
+			return nil, fmt.Errorf("invalid softening %v", eps)
+		}
+	}
+
+	// Configurations on the unit disk, scaled to each grid below
+	rng := rand.New(rand.NewSource(cfg.Seed))
+	configs := make([][][2]float64, cfg.Configurations)
+	for c := range configs {
+		configs[c] = make([][2]float64, cfg.Particles)
+		for i := range configs[c] {
+			r := math.Sqrt(rng.Float64())
+			theta := 2 * math.Pi * rng.Float64()
+			configs[c][i] = [2]float64{r * math.Cos(theta), r * math.Sin(theta)}
+		}
+	}
+
+	var results []ForceResult
+	for _, n := range cfg.GridSizes {
+		grid := make([]ForceResult, len(cfg.Softenings))
+		for s, eps := range cfg.Softenings {
+			grid[s] = ForceResult{GridSize: n, Softening: eps}
+		}
+		sumSq := make([]float64, len(cfg.Softenings))
+
+		radius := cfg.ClusterFraction * float64(n)
+		for _, unit := range configs {
+			particles := make([]*physics.Particle, len(unit))
+			for i, p := range unit {
+				particles[i] = physics.NewParticle(1/float64(len(unit)), p[0]*radius, 0, p[1]*radius, 0, 0, 0)
+			}
+			pmX, pmZ := PMAccelerations(particles, n, cfg.G)
+
+			for s, eps := range cfg.Softenings {
+				refX, refZ := DirectAccelerationsInBox(particles, cfg.G, eps, float64(n*n), cfg.Workers)
+
+				var refSq float64
+				for i := range refX {
+					refSq += refX[i]*refX[i] + refZ[i]*refZ[i]
+				}
+				scale := math.Sqrt(refSq / float64(len(refX)))
+				if scale == 0 {
+					continue
+				}
+
+				r := &grid[s]
+				for i := range refX {
+					err := math.Hypot(pmX[i]-refX[i], pmZ[i]-refZ[i]) / scale
+					sumSq[s] += err * err
+					r.MaxError = math.Max(r.MaxError, err)
+					r.Samples++
+				}
+			}
+		}
+
+		for s := range grid {
+			if grid[s].Samples > 0 {
+				grid[s].RMSError = math.Sqrt(sumSq[s] / float64(grid[s].Samples))
+			}
+		}
+		results = append(results, grid...)
+	}
+
+	return results, nil
+}
+
+// PMAccelerations computes the particle-mesh acceleration of every particle on
+// an n×n grid of unit cells: CIC deposit, FFT Poisson solve,
+// central-difference gradient and CIC interpolation
+func PMAccelerations(particles []*physics.Particle, n int, gravitationalConstant float64) (ax, az []float64) {
+	mass := physics.DepositMassToGrid(particles, n, n, nil)
+	potential := physics.SolvePoissonFFT(mass, n, n, 1, gravitationalConstant)
+	field := physics.CalculateGradient(potential, n, n, 1)
+
+	ax = make([]float64, len(particles))
+	az = make([]float64, len(particles))
+	for i, p := range particles {
+		ax[i], az[i] = physics.InterpolateAcceleration(p.Position, field)
+	}
+	return ax, az
+}
+
+// DirectAccelerationsInBox returns direct-summation accelerations corrected for
+// the uniform negative background a periodic solver implies (it drops the mean
+// density), which pushes particles away from the center of mass with
+// a = 2πGM (x - x_cm) / boxArea. Periodic images are not included, so the
+// result matches a periodic solver only for systems well inside the box
+func DirectAccelerationsInBox(particles []*physics.Particle, gravitationalConstant, softening, boxArea float64, workers int) (ax, az []float64) {
+	ax, az = physics.DirectAccelerations(particles, gravitationalConstant, softening, workers)
+
+	var total, cmX, cmZ float64
+	for _, p := range particles {
+		m := float64(p.Mass)
+		total += m
+		cmX += m * p.Position.X
+		cmZ += m * p.Position.Z
+	}
+	if total == 0 || boxArea <= 0 {
+		return ax, az
+	}
+	cmX, cmZ = cmX/total, cmZ/total
+
+	k := 2 * math.Pi * gravitationalConstant * total / boxArea
+	for i, p := range particles {
+		ax[i] += k * (p.Position.X - cmX)
+		az[i] += k * (p.Position.Z - cmZ)
+	}
+	return ax, az
+}
+
+// ForceHeader is the header row written by WriteForceResults
+var ForceHeader = []string{"grid_size", "softening", "samples", "rms_error", "max_error"}
+
+// WriteForceResults writes one CSV row per grid size and softening
+func WriteForceResults(w io.Writer, results []ForceResult) error {
+	writer := csv.NewWriter(w)
+	if err := writer.Write(ForceHeader); err != nil {
+		return err
+	}
+
+	for _, r := range results {
+		row := []string{
+			strconv.Itoa(r.GridSize),
+			formatFloat(r.Softening),
+			strconv.Itoa(r.Samples),
+			formatFloat(r.RMSError),
+			formatFloat(r.MaxError),
+		}
+		if err := writer.Write(row); err != nil {
+			return err
+		}
+	}
+
+	writer.Flush()
+	return writer.Error()
+}
@@ -0,0 +1,95 @@
+package verification
+
+import (
+	"bytes"
+	"encoding/csv"
+	"math"
+	"relativity_simulation_2d/internal/physics"
+	"testing"
+)
+
+// TestCompareForces tests that PM forces match direct summation at the PM
+// resolution and improve as the grid resolves the configuration better
+func TestCompareForces(t *testing.T) {
+	cfg := DefaultForceConfig()
+	cfg.Softenings = []float64{1}
+	cfg.Particles = 500
+	results, err := CompareForces(cfg)
+	if err != nil {
+		t.Fatalf("CompareForces failed: %v", err)
+	}
+	if len(results) != len(cfg.GridSizes) {
+		t.Fatalf("Expected %d results, got %d", len(cfg.GridSizes), len(results))
+	}
+
+	for i, r := range results {
+		t.Logf("grid %d: rms error %.3g, max error %.3g", r.GridSize, r.RMSError, r.MaxError)
+		if r.Samples != cfg.Particles*cfg.Configurations {
+			t.Errorf("grid %d: %d samples, want %d", r.GridSize, r.Samples, cfg.Particles*cfg.Configurations)
+		}
+		if i > 0 && r.RMSError >= results[i-1].RMSError {
+			t.Errorf("RMS error should fall with grid size: %d -> %.3g, %d -> %.3g",
+				results[i-1].GridSize, results[i-1].RMSError, r.GridSize, r.RMSError)
+		}
+	}
+	if last := results[len(results)-1]; last.RMSError > 0.03 || last.MaxError > 0.1 {
+		t.Errorf("grid %d: rms %.3g, max %.3g exceed 0.03/0.1", last.GridSize, last.RMSError, last.MaxError)
+	}
+}
+
+// TestDirectAccelerationsInBox tests the background correction for a single
+// mass against the PM field
+func TestDirectAccelerationsInBox(t *testing.T) {
+	const n = 128
+	particles := []*physics.Particle{
+		physics.NewParticle(1, 0, 0, 0, 0, 0, 0),
+		physics.NewParticle(1e-6, 20, 0, 0, 0, 0, 0), // Test particle
+	}
+	refX, _ := DirectAccelerationsInBox(particles, 1, 0, n*n, 1)
+	isoX, _ := physics.DirectAccelerations(particles, 1, 0, 1)
+	pmX, _ := PMAccelerations(particles, n, 1)
+
+	if want := isoX[1] + 2*math.Pi*20/(n*n); math.Abs(refX[1]-want) > 1e-6 {
+		t.Errorf("Corrected acceleration = %g, want %g", refX[1], want)
+	}
+	if math.Abs(pmX[1]-refX[1]) > 0.01*math.Abs(refX[1]) {
+		t.Errorf("PM acceleration %g differs from corrected direct %g by more than 1%%", pmX[1], refX[1])
+	}
+}
+
+// TestWriteForceResults tests the CSV layout
+func TestWriteForceResults(t *testing.T) {
+	results := []ForceResult{{GridSize: 64, Softening: 0.5, Samples: 10, RMSError: 0.01, MaxError: 0.1}}
+	var buf bytes.Buffer
+	if err := WriteForceResults(&buf, results); err != nil {
+		t.Fatalf("WriteForceResults failed: %v", err)
+	}
+	rows, err := csv.NewReader(&buf).ReadAll()
+	if err != nil {
+		t.Fatalf("Invalid CSV: %v", err)
+	}
+	if len(rows) != 2 || rows[1][0] != "64" || rows[1][1] != "0.5" || rows[1][4] != "0.1" {
+		t.Errorf("Unexpected rows %v", rows)
+	}
+}
+
+// TestCompareForcesInvalidConfig tests configuration validation
+func TestCompareForcesInvalidConfig(t *testing.T) {
+	mutations := map[string]func(*ForceConfig){
+		"one particle":      func(c *ForceConfig) { c.Particles = 1 },
+		"no configurations": func(c *ForceConfig) { c.Configurations = 0 },
+		"cluster too big":   func(c *ForceConfig) { c.ClusterFraction = 0.8 },
+		"no grids":          func(c *ForceConfig) { c.GridSizes = nil },
+		"tiny grid":         func(c *ForceConfig) { c.GridSizes = []int{2} },
+		"negative softening": func(c *ForceConfig) {
+			c.Softenings = []float64{-1}
+		},
+	}
+	for name, mutate := range mutations {
+		cfg := DefaultForceConfig()
+		mutate(&cfg)
+		if _, err := CompareForces(cfg); err == nil {
+			t.Errorf("%s: expected error", name)
+		}
+	}
+}
@@ -0,0 +1,135 @@
+package verification
+
+import (
+	"bytes"
+	"encoding/csv"
+	"math"
+	"math/rand"
+	"relativity_simulation_2d/internal/physics"
+	"testing"
+)
+
+// TestComparePlummer tests that the CPU deposition and solvers pass the gate
+func TestComparePlummer(t *testing.T) {
+	solvers := map[string]func(potentialGrid, massGrid physics.Grid, gravitationalConstant float64){
+		"float64": nil,
+		"float32": func(potentialGrid, massGrid physics.Grid, gravitationalConstant float64) {
+			physics.SolvePoissonWithPrecision(physics.PrecisionFloat32, potentialGrid, massGrid, 1, gravitationalConstant)
+		},
+	}
+	for name, solve := range solvers {
+		cfg := DefaultPlummerConfig()
+		cfg.Solve = solve
+		report, err := ComparePlummer(cfg)
+		if err != nil {
+			t.Fatalf("%s: ComparePlummer failed: %v", name, err)
+		}
+		if err := report.Check(PlummerTolerance); err != nil {
+			t.Errorf("%s: %v", name, err)
+		}
+		t.Logf("%s: rms error %.2e, max error %.2e", name, report.RMSError, report.MaxError)
+	}
+}
+
+// TestComparePlummerDetectsErrors tests that broken kernels fail the gate
+func TestComparePlummerDetectsErrors(t *testing.T) {
+	scaled := DefaultPlummerConfig()
+	scaled.Solve = func(potentialGrid, massGrid physics.Grid, gravitationalConstant float64) {
+		physics.SolvePoissonFFTInto(potentialGrid, massGrid, 1, gravitationalConstant*1.05)
+	}
+
+	shifted := DefaultPlummerConfig()
+	shifted.Deposit = func(grid physics.Grid, particles []*physics.Particle) {
+		for _, p := range particles {
+			p.Position.X++
+		}
+		physics.DepositMassToGridInto(grid, particles, nil)
+	}
+
+	for name, cfg := range map[string]PlummerConfig{"scaled G": scaled, "shifted deposit": shifted} {
+		report, err := ComparePlummer(cfg)
+		if err != nil {
+			t.Fatalf("%s: ComparePlummer failed: %v", name, err)
+		}
+		if report.Check(PlummerTolerance) == nil {
+			t.Errorf("%s: expected gate failure, max error %.2e", name, report.MaxError)
+		}
+	}
+}
+
+// TestSamplePlummer tests the mass and projected half-mass radius of the sample
+func TestSamplePlummer(t *testing.T) {
+	const n, a = 100000, 3.0
+	particles := SamplePlummer(n, a, 2, rand.New(rand.NewSource(7)))
+
+	var total float64
+	inside := 0
+	for _, p := range particles {
+		total += float64(p.Mass)
+		if math.Hypot(p.Position.X, p.Position.Z) < a {
+			inside++
+		}
+	}
+	if math.Abs(total-2) > 1e-3 {
+		t.Errorf("Total mass = %f, want 2", total)
+	}
+	// Projected enclosed fraction R²/(R² + a²) is one half at R = a
+	if frac := float64(inside) / n; math.Abs(frac-0.5) > 0.01 {
+		t.Errorf("Fraction inside R = a is %f, want 0.5", frac)
+	}
+}
+
+// TestPlummerPotential tests the isolated and periodic analytic potentials
+func TestPlummerPotential(t *testing.T) {
+	if got, want := PlummerPotential(0, 2, 3, 1.5, 0), 1.5*3*math.Log(4); math.Abs(got-want) > 1e-12 {
+		t.Errorf("Central potential = %f, want %f", got, want)
+	}
+	isolated := PlummerPotential(10, 2, 3, 1.5, 0)
+	periodic := PlummerPotential(10, 2, 3, 1.5, 400)
+	if want := math.Pi * 1.5 * 3 * 100 / 400; math.Abs(isolated-periodic-want) > 1e-12 {
+		t.Errorf("Background term = %f, want %f", isolated-periodic, want)
+	}
+}
+
+// TestWriteProfile tests the CSV profile layout
+func TestWriteProfile(t *testing.T) {
+	cfg := DefaultPlummerConfig()
+	cfg.GridSize, cfg.Particles, cfg.Bins = 64, 5000, 4
+	report, err := ComparePlummer(cfg)
+	if err != nil {
+		t.Fatalf("ComparePlummer failed: %v", err)
+	}
+
+	var buf bytes.Buffer
+	if err := report.WriteProfile(&buf); err != nil {
+		t.Fatalf("WriteProfile failed: %v", err)
+	}
+	rows, err := csv.NewReader(&buf).ReadAll()
+	if err != nil {
+		t.Fatalf("Invalid CSV: %v", err)
+	}
+	if len(rows) != 5 || len(rows[0]) != len(ProfileHeader) {
+		t.Errorf("Expected header and 4 bins, got %d rows", len(rows))
+	}
+	if rows[4][1] != "16" {
+		t.Errorf("Last bin should end at GridSize/4, got %s", rows[4][1])
+	}
+}
+
+// TestComparePlummerInvalidConfig tests configuration validation
+func TestComparePlummerInvalidConfig(t *testing.T) {
+	mutations := map[string]func(*PlummerConfig){
+		"tiny grid":      func(c *PlummerConfig) { c.GridSize = 2 },
+		"no particles":   func(c *PlummerConfig) { c.Particles = 0 },
+		"no bins":        func(c *PlummerConfig) { c.Bins = 0 },
+		"zero radius":    func(c *PlummerConfig) { c.ScaleRadius = 0 },
+		"radius too big": func(c *PlummerConfig) { c.MaxRadius = 200 },
+	}
+	for name, mutate := range mutations {
+		cfg := DefaultPlummerConfig()
+		mutate(&cfg)
+		if _, err := ComparePlummer(cfg); err == nil {
+			t.Errorf("%s: expected error", name)
+		}
+	}
+}
@@ -0,0 +1,146 @@
+package verification
+
+import (
+	"fmt"
+	"math"
+	"relativity_simulation_2d/internal/physics"
+)
+
+// DefaultReversalSteps is the number of steps run each way by default
+const DefaultReversalSteps = 100
+
+// Stepper is a simulation that advances its particles in steps
+type Stepper interface {
+	Step(dt float32)
+	GetParticles() []*physics.Particle
+}
+
+// ReversalConfig describes a time-reversal test. Lengths are in cells
+type ReversalConfig struct {
+	Steps    int     // Steps run forward, then again after negating the velocities
+	TimeStep float32 // Time step of every step
+	Width    int     // Periodic domain, so positions are compared across the wrap (0 = not periodic)
+	Height   int
+}
+
+// ReversalResult is how far the particles ended from where they started after
+// running forward and back. A time-symmetric integrator in exact arithmetic
+// returns every particle to its start; the error measures round-off, its
+// growth through chaotic encounters, and any dissipative or non-reversible
+// part of the step (block time steps, damping, Coriolis forces)
+type ReversalResult struct {
+	Steps            int
+	TimeStep         float32
+	Particles        int
+	RMSError         float64 // RMS distance from the starting positions
+	MaxError         float64 // Largest distance from a starting position
+	MaxParticle      int     // Index of the particle with the largest distance
+	RMSDisplacement  float64 // RMS distance covered in the forward run, to put the errors in scale
+	RMSVelocityError float64 // RMS |v_end - v_start| relative to the RMS starting speed
+}
+
+// String summarizes the result on one line
+func (r ReversalResult) String() string {
+	return fmt.Sprintf("time reversal over %d steps of %g: RMS error %.3g cells, max %.3g (particle %d), RMS displacement %.3g, velocity error %.3g",
+		r.Steps, r.TimeStep, r.RMSError, r.MaxError, r.MaxParticle, r.RMSDisplacement, r.RMSVelocityError)
+}
+
+// TimeReversal runs sim cfg.Steps steps forward, negates every velocity, runs
+// the same number of steps back and negates the velocities again, then
+// compares the particles with their starting state. Afterwards the starting
+// positions and velocities are restored, so the test leaves the particles as
+// it found them. Anything else the stepper keeps, such as step counts, has
+// advanced by twice cfg.Steps
+func TimeReversal(sim Stepper, cfg ReversalConfig) (ReversalResult, error) {
+	if cfg.Steps < 1 {
+		return ReversalResult{}, fmt.Errorf("invalid number of steps: %d", cfg.Steps)
+	}
+	if !(cfg.TimeStep > 0) {
+		return ReversalResult{}, fmt.Errorf("invalid time step: %v", cfg.TimeStep)
+	}
+	if cfg.Width < 0 || cfg.Height < 0 {
+		return ReversalResult{}, fmt.Errorf("invalid domain %dx%d", cfg.Width, cfg.Height)
+	}
+
+	particles := sim.GetParticles()
+	start := make([]physics.Particle, len(particles))
+	for i, p := range particles {
+		start[i] = *p
+	}
+	restore := func() {
+		for i, p := range sim.GetParticles() {
+			if i < len(start) {
+				p.Position, p.Velocity = start[i].Position, start[i].Velocity
+			}
+		}
+	}
+	reverse := func() {
+		for _, p := range sim.GetParticles() {
+			p.Velocity = p.Velocity.Scale(-1)
+		}
+	}
+	run := func() error {
+		for step := 0; step < cfg.Steps; step++ {
+			sim.Step(cfg.TimeStep)
+			if len(sim.GetParticles()) != len(start) {
+				return fmt.Errorf("particle count changed from %d to %d during the test", len(start), len(sim.GetParticles()))
+			}
+		}
+		return nil
+	}
+
+	result := ReversalResult{Steps: cfg.Steps, TimeStep: cfg.TimeStep, Particles: len(start)}
+	if err := run(); err != nil {
+		restore()
+		return result, err
+	}
+	var sumDisplacement float64
+	for i, p := range sim.GetParticles() {
+		d := distance(p.Position, start[i].Position, cfg.Width, cfg.Height)
+		sumDisplacement += d * d
+	}
+	reverse()
+	if err := run(); err != nil {
+		restore()
+		return result, err
+	}
+	reverse()
+
+	var sumError, sumVelocityError, sumSpeed float64
+	for i, p := range sim.GetParticles() {
+		d := distance(p.Position, start[i].Position, cfg.Width, cfg.Height)
+		sumError += d * d
+		if d > result.MaxError {
+			result.MaxError, result.MaxParticle = d, i
+		}
+		dv := p.Velocity.Sub(start[i].Velocity).Length()
+		sumVelocityError += dv * dv
+		speed := start[i].Velocity.Length()
+		sumSpeed += speed * speed
+	}
+	restore()
+
+	if n := float64(len(start)); n > 0 {
+		result.RMSError = math.Sqrt(sumError / n)
+		result.RMSDisplacement = math.Sqrt(sumDisplacement / n)
+		if sumSpeed > 0 {
+			result.RMSVelocityError = math.Sqrt(sumVelocityError / sumSpeed)
+		}
+	}
+	return result, nil
+}
+
+// distance returns the in-plane distance between a and b, taking the
+// shorter way across the wrap of a periodic width×height domain
+func distance(a, b physics.Vec3, width, height int) float64 {
+	return math.Hypot(periodicDelta(a.X-b.X, width), periodicDelta(a.Z-b.Z, height))
+}
+
+// periodicDelta maps a coordinate difference onto [-extent/2, extent/2]
+func periodicDelta(d float64, extent int) float64 {
+	if extent <= 0 {
+		return d
+	}
+	e := float64(extent)
+	return d - e*math.Round(d/e)
+}
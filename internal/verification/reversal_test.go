@@ -0,0 +1,105 @@
+package verification
+
+import (
+	"math"
+	"math/rand"
+	"relativity_simulation_2d/internal/physics"
+	"testing"
+)
+
+// pmStepper steps particles with the CPU particle-mesh integrator
+type pmStepper struct {
+	particles     []*physics.Particle
+	width, height int
+	damping       float64 // Velocity lost per step, to break time symmetry
+	steps         int
+}
+
+func (s *pmStepper) Step(dt float32) {
+	physics.RunTimeEvolution(s.particles, dt, s.width, s.height, nil, 1)
+	for _, p := range s.particles {
+		p.Velocity = p.Velocity.Scale(1 - s.damping)
+	}
+	s.steps++
+}
+
+func (s *pmStepper) GetParticles() []*physics.Particle {
+	return s.particles
+}
+
+// newPMStepper returns a stepper with a rotating disk of particles in the middle of a 64×64 domain
+func newPMStepper() *pmStepper {
+	rng := rand.New(rand.NewSource(3))
+	particles := make([]*physics.Particle, 200)
+	for i := range particles {
+		r := 8 * math.Sqrt(rng.Float64())
+		theta := 2 * math.Pi * rng.Float64()
+		x, z := r*math.Cos(theta), r*math.Sin(theta)
+		particles[i] = physics.NewParticle(1, x, 0, z, -0.3*z, 0, 0.3*x)
+	}
+	return &pmStepper{particles: particles, width: 64, height: 64}
+}
+
+// TestTimeReversalPM tests that the kick-drift-kick PM step retraces its path
+func TestTimeReversalPM(t *testing.T) {
+	sim := newPMStepper()
+	result, err := TimeReversal(sim, ReversalConfig{Steps: 50, TimeStep: 0.05, Width: 64, Height: 64})
+	if err != nil {
+		t.Fatalf("TimeReversal failed: %v", err)
+	}
+	if result.RMSDisplacement < 0.5 {
+		t.Fatalf("particles barely moved: RMS displacement %g", result.RMSDisplacement)
+	}
+	if result.MaxError > 1e-6 || result.RMSVelocityError > 1e-6 {
+		t.Errorf("reversal error too large: %v", result)
+	}
+	if sim.steps != 100 {
+		t.Errorf("expected 100 steps, got %d", sim.steps)
+	}
+	t.Log(result)
+}
+
+// TestTimeReversalDetectsDissipation tests that a damped step fails to retrace its path
+func TestTimeReversalDetectsDissipation(t *testing.T) {
+	sim := newPMStepper()
+	sim.damping = 0.01
+	result, err := TimeReversal(sim, ReversalConfig{Steps: 50, TimeStep: 0.05, Width: 64, Height: 64})
+	if err != nil {
+		t.Fatalf("TimeReversal failed: %v", err)
+	}
+	if result.RMSError < 0.01*result.RMSDisplacement {
+		t.Errorf("expected a visible error from damping: %v", result)
+	}
+}
+
+// TestTimeReversalRestoresState tests that the particles are left as they started
+func TestTimeReversalRestoresState(t *testing.T) {
+	sim := newPMStepper()
+	sim.damping = 0.01
+	before := make([]physics.Particle, len(sim.particles))
+	for i, p := range sim.particles {
+		before[i] = *p
+	}
+	if _, err := TimeReversal(sim, ReversalConfig{Steps: 10, TimeStep: 0.05}); err != nil {
+		t.Fatalf("TimeReversal failed: %v", err)
+	}
+	for i, p := range sim.particles {
+		if p.Position != before[i].Position || p.Velocity != before[i].Velocity {
+			t.Fatalf("particle %d not restored: %+v, want %+v", i, *p, before[i])
+		}
+	}
+
+	if _, err := TimeReversal(sim, ReversalConfig{Steps: 0, TimeStep: 0.05}); err == nil {
+		t.Error("expected an error for zero steps")
+	}
+}
+
+// TestPeriodicDelta tests that differences take the short way across the wrap
+func TestPeriodicDelta(t *testing.T) {
+	if d := periodicDelta(60, 64); d != -4 {
+		t.Errorf("expected -4, got %g", d)
+	}
+	if d := periodicDelta(60, 0); d != 60 {
+		t.Errorf("expected 60 without a period, got %g", d)
+	}
+}
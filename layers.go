@@ -0,0 +1,563 @@
+//go:build !js
+
+package main
+
+import (
+	"fmt"
+	rl "github.com/gen2brain/raylib-go/raylib"
+	"math"
+	"relativity_simulation_2d/internal/config"
+	"relativity_simulation_2d/internal/physics"
+	"relativity_simulation_2d/internal/renderer"
+	"relativity_simulation_2d/internal/simulation"
+	"slices"
+	"time"
+)
+
+// sceneFrame is what the layers draw from in one frame
+type sceneFrame struct {
+	sim        *Simulation
+	frame      *simulation.Frame
+	camera     rl.Camera
+	width      int // Viewport size in pixels, of each eye's half in stereo
+	height     int
+	padding    float64 // Distance of each stereo eye from camera, added to the culling radius (0 = mono)
+	budget     int     // Particles drawn at most, sub-sampled by screen density (0 = all)
+	drawn      int     // Particles the particle layer drew, in each eye
+	scheme     renderer.ColorScheme
+	colors     []rl.Color   // Particle colors
+	gridColors [][]rl.Color // Grid node colors (nil = colored by the potential)
+
+	stats *renderer.LayerStats // Counters of the layer drawing (nil = not counted)
+}
+
+// count counts calls draws of vertices each for the layer drawing
+func (f *sceneFrame) count(calls, vertices int) {
+	if f.stats != nil {
+		f.stats.Add(calls, vertices)
+	}
+}
+
+// cull counts items the layer drawing skipped as out of view
+func (f *sceneFrame) cull(items int) {
+	if f.stats != nil {
+		f.stats.Culled += items
+	}
+}
+
+// thin counts items the layer drawing skipped to stay within its budget
+func (f *sceneFrame) thin(items int) {
+	if f.stats != nil {
+		f.stats.Thinned += items
+	}
+}
+
+// Layer is one part of the scene. World layers draw inside rl.BeginMode3D,
+// once per eye in stereo; overlay layers draw in screen space over the view
+type Layer interface {
+	Name() string
+	Overlay() bool
+	Draw(f *sceneFrame)
+}
+
+// SceneRenderer draws its layers in order, skipping the hidden ones, and
+// counts the draws and time of each
+type SceneRenderer struct {
+	layers []Layer
+	shown  []bool
+	stats  *renderer.RenderStats
+}
+
+// NewSceneRenderer creates a scene of the layers in drawing order, all shown
+func NewSceneRenderer(layers ...Layer) *SceneRenderer {
+	shown := make([]bool, len(layers))
+	for i := range shown {
+		shown[i] = true
+	}
+	return &SceneRenderer{layers: layers, shown: shown, stats: renderer.NewRenderStats()}
+}
+
+// Stats returns the draw statistics of the layers
+func (s *SceneRenderer) Stats() *renderer.RenderStats {
+	return s.stats
+}
+
+// index returns the position of the named layer, or -1
+func (s *SceneRenderer) index(name string) int {
+	return slices.IndexFunc(s.layers, func(l Layer) bool { return l.Name() == name })
+}
+
+// Configure shows the named layers, moved to the front in the given order,
+// and hides the others, which keep their order after them
+func (s *SceneRenderer) Configure(names []string) error {
+	layers := make([]Layer, 0, len(s.layers))
+	shown := make([]bool, 0, len(s.layers))
+	for _, name := range names {
+		i := s.index(name)
+		if i < 0 {
+			return fmt.Errorf("unknown layer: %q", name)
+		}
+		if !slices.ContainsFunc(layers, func(l Layer) bool { return l.Name() == name }) {
+			layers, shown = append(layers, s.layers[i]), append(shown, true)
+		}
+	}
+	for _, l := range s.layers {
+		if !slices.Contains(names, l.Name()) {
+			layers, shown = append(layers, l), append(shown, false)
+		}
+	}
+	s.layers, s.shown = layers, shown
+	return nil
+}
+
+// Names returns the layer names in drawing order
+func (s *SceneRenderer) Names() []string {
+	names := make([]string, len(s.layers))
+	for i, l := range s.layers {
+		names[i] = l.Name()
+	}
+	return names
+}
+
+// Shown reports whether the named layer is drawn
+func (s *SceneRenderer) Shown(name string) bool {
+	i := s.index(name)
+	return i >= 0 && s.shown[i]
+}
+
+// SetShown shows or hides the named layer
+func (s *SceneRenderer) SetShown(name string, shown bool) error {
+	i := s.index(name)
+	if i < 0 {
+		return fmt.Errorf("unknown layer: %q", name)
+	}
+	s.shown[i] = shown
+	return nil
+}
+
+// Toggle shows or hides the layer at position i of the drawing order and
+// returns its name and new state (ok = false if there is no such layer)
+func (s *SceneRenderer) Toggle(i int) (name string, shown, ok bool) {
+	if i < 0 || i >= len(s.layers) {
+		return "", false, false
+	}
+	s.shown[i] = !s.shown[i]
+	return s.layers[i].Name(), s.shown[i], true
+}
+
+// Move moves the named layer by places in the drawing order, later for
+// positive places, stopping at either end
+func (s *SceneRenderer) Move(name string, places int) error {
+	i := s.index(name)
+	if i < 0 {
+		return fmt.Errorf("unknown layer: %q", name)
+	}
+	j := min(max(i+places, 0), len(s.layers)-1)
+	layer, shown := s.layers[i], s.shown[i]
+	s.layers, s.shown = slices.Delete(s.layers, i, i+1), slices.Delete(s.shown, i, i+1)
+	s.layers, s.shown = slices.Insert(s.layers, j, layer), slices.Insert(s.shown, j, shown)
+	return nil
+}
+
+// DrawWorld draws the shown world layers; call it inside rl.BeginMode3D
+func (s *SceneRenderer) DrawWorld(f *sceneFrame) {
+	s.draw(f, false)
+}
+
+// DrawOverlay draws the shown overlay layers after the 3D view
+func (s *SceneRenderer) DrawOverlay(f *sceneFrame) {
+	s.draw(f, true)
+}
+
+// draw draws the shown layers of one pass in order, timing each
+func (s *SceneRenderer) draw(f *sceneFrame, overlay bool) {
+	for i, l := range s.layers {
+		if !s.shown[i] || l.Overlay() != overlay {
+			continue
+		}
+		stats := s.stats.Layer(l.Name())
+		if f != nil {
+			f.stats = stats
+		}
+		start := time.Now()
+		l.Draw(f)
+		stats.Time += time.Since(start).Seconds()
+	}
+	if f != nil {
+		f.stats = nil
+	}
+}
+
+// newScene creates the scene layers with the settings and order configured
+// by cfg
+func newScene() *SceneRenderer {
+	s := NewSceneRenderer(
+		gridLayer{},
+		&contourLayer{levels: orDefault(cfg.ContourLevels, config.DefaultContourLevels)},
+		&particleLayer{},
+		haloLayer{threshold: cfg.HaloThreshold},
+		&trailLayer{
+			particles: orDefault(cfg.TrailParticles, config.DefaultTrailParticles),
+			length:    orDefault(cfg.TrailLength, config.DefaultTrailLength),
+		},
+		vectorLayer{stride: orDefault(cfg.VectorStride, config.DefaultVectorStride)},
+		sheetLayer{},
+		markerLayer{},
+		axesLayer{},
+		minimapLayer{size: orDefault(cfg.MinimapSize, config.DefaultMinimapSize)},
+	)
+	spec := cfg.Layers
+	if spec == "" {
+		spec = config.DefaultLayers
+	}
+	names, _ := config.ParseLayers(spec) // Validated at startup
+	_ = s.Configure(names)
+	return s
+}
+
+// drawRenderStats draws the scene's draw statistics and what limits the
+// frame rate, for frames paced to budget seconds (0 = uncapped)
+func drawRenderStats(s *SceneRenderer, budget float64) {
+	minimap := 0
+	if s.Shown(config.LayerMinimap) {
+		minimap = orDefault(cfg.MinimapSize, config.DefaultMinimapSize)
+	}
+	for i, line := range s.Stats().Lines(budget) {
+		x, y := ui.GetRenderStatsPosition(i, minimap)
+		drawHUDText(line, x, y, ui.GetDefaultTextColor())
+	}
+}
+
+// orDefault returns value, or fallback if value is 0
+func orDefault(value, fallback int) int {
+	if value == 0 {
+		return fallback
+	}
+	return value
+}
+
+// toggleLayers toggles the layer at the position of a number key pressed
+// with Shift, 1 to 9 and then 0 for the tenth, and notifies its new state.
+// The number keys alone jump to camera bookmarks
+func toggleLayers(s *SceneRenderer) {
+	if !rl.IsKeyDown(rl.KeyLeftShift) && !rl.IsKeyDown(rl.KeyRightShift) {
+		return
+	}
+	keys := []int32{rl.KeyOne, rl.KeyTwo, rl.KeyThree, rl.KeyFour, rl.KeyFive, rl.KeySix, rl.KeySeven, rl.KeyEight, rl.KeyNine, rl.KeyZero}
+	for i, key := range keys {
+		if !rl.IsKeyPressed(key) {
+			continue
+		}
+		if name, shown, ok := s.Toggle(i); ok {
+			state := "hidden"
+			if shown {
+				state = "shown"
+			}
+			ui.Notify(renderer.NotificationInfo, fmt.Sprintf("Layer %s: %s", name, state))
+		}
+	}
+}
+
+// cullingCamera returns the frame's camera for frustum tests and screen
+// positions
+func (f *sceneFrame) cullingCamera() *renderer.Camera {
+	c := renderer.NewCamera(fromRaylib(f.camera.Position), fromRaylib(f.camera.Target), fromRaylib(f.camera.Up))
+	c.SetPerspective(float64(f.camera.Fovy), float64(f.width)/float64(max(f.height, 1)), cullNear, cullFar)
+	return c
+}
+
+// Clipping planes of the interactive session, set with rl.SetClipPlanes
+const (
+	cullNear = 0.1
+	cullFar  = 10000.0
+)
+
+// gridPoint returns the world position of grid node (i, j), fractional
+// between nodes, lifted to height on the deformed grid of cells of size dx
+func gridPoint(i, j float64, width, height int, dx, y float64) rl.Vector3 {
+	return rl.NewVector3(float32((i-float64(width)/2)*dx), float32(y), float32((j-float64(height)/2)*dx))
+}
+
+// flatCircle rotates circles from the x-y plane into the x-z plane
+var flatCircle = rl.NewVector3(1, 0, 0)
+
+// gridLayer draws the deformed potential grid, straight from the GPU
+// potential when possible
+type gridLayer struct{}
+
+func (gridLayer) Name() string  { return config.LayerGrid }
+func (gridLayer) Overlay() bool { return false }
+
+func (gridLayer) Draw(f *sceneFrame) {
+	stride := quality.level.GridStride()
+	grid := f.frame.PotentialGrid
+	lines := gridLines(grid.Width(), grid.Height(), stride)
+	if f.gridColors == nil && f.sim.drawPotentialGPU(stride) {
+		f.count(1, lines*renderer.LineVertices) // One draw of the GPU vertex buffer
+		return
+	}
+	drawDeformedGrid(grid, stride, f.sim.mesh.Dx(), f.gridColors)
+	f.count(lines, renderer.LineVertices)
+}
+
+// gridLines returns the number of segments in every stride-th line of a
+// width × height grid
+func gridLines(width, height, stride int) int {
+	across := (width + stride - 1) / stride
+	along := (height + stride - 1) / stride
+	return across*max(height-1, 0) + along*max(width-1, 0)
+}
+
+// contourLayer draws contour lines of the potential on the deformed grid
+type contourLayer struct {
+	levels   int
+	segments []renderer.ContourSegment // Reused between frames
+}
+
+func (*contourLayer) Name() string  { return config.LayerContours }
+func (*contourLayer) Overlay() bool { return false }
+
+func (l *contourLayer) Draw(f *sceneFrame) {
+	grid := f.frame.PotentialGrid
+	width, height := grid.Width(), grid.Height()
+	l.segments = renderer.ContourSegments(l.segments[:0], grid, renderer.ContourLevels(grid, l.levels))
+	dx := f.sim.mesh.Dx()
+	color := raylibColor(f.scheme.GridHot)
+	for _, s := range l.segments {
+		y := s.Level * cfg.GridVisScale
+		rl.DrawLine3D(gridPoint(s.I1, s.J1, width, height, dx, y), gridPoint(s.I2, s.J2, width, height, dx, y), color)
+	}
+	f.count(len(l.segments), renderer.LineVertices)
+}
+
+// particleLayer draws the particles as spheres in their colors, except the
+// tracers of the sheet, culling those out of view or too small to see and
+// sub-sampling the rest to the frame's budget
+type particleLayer struct {
+	culler   *renderer.ParticleRenderer
+	sampler  renderer.DensitySampler
+	pointers []*physics.Particle // Reused between frames
+	sampled  []int               // Reused between frames
+}
+
+func (*particleLayer) Name() string  { return config.LayerParticles }
+func (*particleLayer) Overlay() bool { return false }
+
+func (l *particleLayer) Draw(f *sceneFrame) {
+	display := renderer.DisplayRadius{Scale: cfg.DisplayScale, Min: cfg.MinDisplayRadius}
+	drawn := l.visible(f, display)
+	f.drawn = len(drawn)
+	positions := raylibVectors(f.frame.Positions) // Packed on publish, so nothing is converted here
+	for _, i := range drawn {
+		if f.sim.sheet != nil && f.sim.sheet.Contains(i) {
+			continue // Drawn as the mesh
+		}
+		rl.DrawSphere(positions[i], display.Radius(f.frame.Particles[i].Radius), f.colors[i])
+		f.count(1, renderer.SphereVertices)
+	}
+}
+
+// visible returns the indices of the particles that survive culling and
+// sub-sampling
+func (l *particleLayer) visible(f *sceneFrame, display renderer.DisplayRadius) []int {
+	if l.culler == nil {
+		l.culler = renderer.NewParticleRenderer()
+	}
+	l.pointers = l.pointers[:0]
+	for i := range f.frame.Particles {
+		l.pointers = append(l.pointers, &f.frame.Particles[i])
+	}
+	l.culler.EnableCulling(cfg.Culling)
+	l.culler.SetDisplayRadius(display, f.padding)
+	l.culler.SetSizeCulling(cfg.CullPixels, f.height)
+	camera := f.cullingCamera()
+	l.culler.SetCamera(camera)
+	l.culler.SetParticles(l.pointers)
+	visible := l.culler.GetVisibleIndices()
+	f.cull(len(f.frame.Particles) - len(visible))
+	if f.budget <= 0 || len(visible) <= f.budget {
+		return visible
+	}
+	l.sampled = l.sampler.SampleParticles(l.sampled[:0], visible, l.pointers, camera, f.width, f.height, f.budget)
+	f.thin(len(visible) - len(l.sampled))
+	return l.sampled
+}
+
+// haloLayer rings the density peaks of the mass grid
+type haloLayer struct {
+	threshold float64 // Cell mass over the mean (0 = config.DefaultHaloThreshold)
+}
+
+func (haloLayer) Name() string  { return config.LayerHalos }
+func (haloLayer) Overlay() bool { return false }
+
+func (l haloLayer) Draw(f *sceneFrame) {
+	threshold := l.threshold
+	if threshold == 0 {
+		threshold = config.DefaultHaloThreshold
+	}
+	grid := f.frame.MassDensityGrid
+	color := raylibColor(f.scheme.ParticleHeavy)
+	dx := f.sim.mesh.Dx()
+	halos := renderer.FindHalos(grid, threshold)
+	for _, h := range halos {
+		center := gridPoint(float64(h.I)+0.5, float64(h.J)+0.5, grid.Width(), grid.Height(), dx, 0)
+		rl.DrawCircle3D(center, float32(h.Radius*dx), flatCircle, 90, color)
+	}
+	f.count(len(halos), renderer.Circle3DVertices)
+}
+
+// trailLayer draws the recent paths of the first particles, fading with age
+type trailLayer struct {
+	particles, length int
+	trails            *renderer.Trails // Created on the first draw
+	points            []physics.Vec3   // Reused between trails
+	vectors           []rl.Vector3     // Reused between trails
+}
+
+func (*trailLayer) Name() string  { return config.LayerTrails }
+func (*trailLayer) Overlay() bool { return false }
+
+func (l *trailLayer) Draw(f *sceneFrame) {
+	if l.trails == nil {
+		l.trails = renderer.NewTrails(l.particles, l.length,
+			f.sim.mesh.Extent(cfg.SimulationWidth), f.sim.mesh.Extent(cfg.SimulationDepth))
+	}
+	l.trails.Record(f.frame.Step, f.frame.Particles)
+	for i := 0; i < l.trails.Len() && i < len(f.colors); i++ {
+		l.points = l.trails.Trail(l.points[:0], i)
+		l.vectors = appendRaylib(l.vectors[:0], l.points)
+		for k := 1; k < len(l.vectors); k++ {
+			color := f.colors[i]
+			color.A = uint8(255 * k / len(l.vectors))
+			rl.DrawLine3D(l.vectors[k-1], l.vectors[k], color)
+		}
+		f.count(max(len(l.points)-1, 0), renderer.LineVertices)
+	}
+}
+
+// vectorLayer draws arrows of the acceleration field on the deformed grid
+type vectorLayer struct {
+	stride int
+}
+
+func (vectorLayer) Name() string  { return config.LayerVectors }
+func (vectorLayer) Overlay() bool { return false }
+
+func (l vectorLayer) Draw(f *sceneFrame) {
+	potential := f.frame.PotentialGrid
+	width, height := potential.Width(), potential.Height()
+	dx := f.sim.mesh.Dx()
+	color := raylibColor(f.scheme.Solver)
+	arrows := renderer.FieldArrows(f.frame.AccelFieldX, f.frame.AccelFieldZ, l.stride)
+	for _, a := range arrows {
+		y := potential[a.I][a.J] * cfg.GridVisScale
+		i, j := float64(a.I), float64(a.J)
+		tip := gridPoint(i+a.DI, j+a.DJ, width, height, dx, y)
+		rl.DrawLine3D(gridPoint(i, j, width, height, dx, y), tip, color)
+
+		// A head of two barbs a quarter of the arrow long
+		for _, side := range []float64{-1, 1} {
+			bi := i + 0.75*a.DI - 0.25*side*a.DJ
+			bj := j + 0.75*a.DJ + 0.25*side*a.DI
+			rl.DrawLine3D(tip, gridPoint(bi, bj, width, height, dx, y), color)
+		}
+	}
+	f.count(3*len(arrows), renderer.LineVertices)
+}
+
+// sheetLayer draws the tracer sheet as a mesh
+type sheetLayer struct{}
+
+func (sheetLayer) Name() string  { return config.LayerSheet }
+func (sheetLayer) Overlay() bool { return false }
+
+func (sheetLayer) Draw(f *sceneFrame) {
+	f.count(drawTracerSheet(f.sim.sheet, f.frame, f.scheme), renderer.LineVertices)
+}
+
+// markerLayer draws the scenario's analytic masses and markers and the slow
+// motion watch
+type markerLayer struct{}
+
+func (markerLayer) Name() string  { return config.LayerMarkers }
+func (markerLayer) Overlay() bool { return false }
+
+func (markerLayer) Draw(f *sceneFrame) {
+	display := renderer.DisplayRadius{Scale: cfg.DisplayScale, Min: cfg.MinDisplayRadius}
+	marker := raylibColor(f.scheme.Marker)
+	for _, m := range f.sim.Masses {
+		rl.DrawSphere(rl.NewVector3(float32(m.X), 0, float32(m.Z)), display.Radius(float32(radiusModel().Radius(m.Mass))), marker)
+	}
+	for _, m := range f.sim.Markers {
+		p := toRaylib(m.Position)
+		rl.DrawLine3D(rl.NewVector3(p.X-1, p.Y, p.Z-1), rl.NewVector3(p.X+1, p.Y, p.Z+1), marker)
+		rl.DrawLine3D(rl.NewVector3(p.X-1, p.Y, p.Z+1), rl.NewVector3(p.X+1, p.Y, p.Z-1), marker)
+		rl.DrawLine3D(p, rl.NewVector3(p.X, p.Y+2, p.Z), marker)
+	}
+	f.count(len(f.sim.Masses), renderer.SphereVertices)
+	f.count(3*len(f.sim.Markers), renderer.LineVertices)
+	if drawSlowMotionWatch(slowMotion, f.frame, marker) {
+		f.count(1, renderer.Circle3DVertices)
+	}
+}
+
+// axesLayer draws the coordinate axes
+type axesLayer struct{}
+
+func (axesLayer) Name() string  { return config.LayerAxes }
+func (axesLayer) Overlay() bool { return false }
+
+func (axesLayer) Draw(f *sceneFrame) {
+	rl.DrawLine3D(rl.NewVector3(0, 0, 0), rl.NewVector3(5, 0, 0), raylibColor(f.scheme.AxisX))
+	rl.DrawLine3D(rl.NewVector3(0, 0, 0), rl.NewVector3(0, 5, 0), raylibColor(f.scheme.AxisY))
+	rl.DrawLine3D(rl.NewVector3(0, 0, 0), rl.NewVector3(0, 0, 5), raylibColor(f.scheme.AxisZ))
+	f.count(3, renderer.LineVertices)
+}
+
+// minimapLayer draws a top-down map of the domain in the top-right corner,
+// with the particles in their colors and the camera looking along its arrow
+type minimapLayer struct {
+	size int // Side in pixels at UI scale 1
+}
+
+func (minimapLayer) Name() string  { return config.LayerMinimap }
+func (minimapLayer) Overlay() bool { return true }
+
+func (l minimapLayer) Draw(f *sceneFrame) {
+	x, y, side := ui.GetMinimapPosition(l.size)
+	m := renderer.Minimap{
+		Width:  f.sim.mesh.Extent(cfg.SimulationWidth),
+		Height: f.sim.mesh.Extent(cfg.SimulationDepth),
+		Size:   float64(side),
+	}
+	w, h := m.Bounds()
+	rl.DrawRectangle(int32(x), int32(y), int32(w), int32(h), rl.NewColor(0, 0, 0, 160))
+	rl.DrawRectangleLines(int32(x), int32(y), int32(w), int32(h), raylibColor(f.scheme.Text))
+	f.count(1, renderer.RectangleVertices)
+	f.count(4, renderer.LineVertices)
+
+	for i, p := range f.frame.Particles {
+		if px, py, ok := m.Point(p.Position.X, p.Position.Z); ok {
+			rl.DrawRectangle(int32(x)+int32(px)-1, int32(y)+int32(py)-1, 2, 2, f.colors[i])
+			f.count(1, renderer.RectangleVertices)
+		} else {
+			f.cull(1)
+		}
+	}
+
+	camera := raylibColor(f.scheme.Warning)
+	px, py, ok := m.Point(float64(f.camera.Position.X), float64(f.camera.Position.Z))
+	if !ok {
+		return
+	}
+	cx, cy := float32(x)+float32(px), float32(y)+float32(py)
+	rl.DrawCircle(int32(cx), int32(cy), 3, camera)
+	f.count(1, renderer.CircleVertices)
+	dirX, dirZ := f.camera.Target.X-f.camera.Position.X, f.camera.Target.Z-f.camera.Position.Z
+	if length := float32(math.Hypot(float64(dirX), float64(dirZ))); length > 0 {
+		arrow := float32(side) / 10
+		rl.DrawLine(int32(cx), int32(cy), int32(cx+dirX/length*arrow), int32(cy+dirZ/length*arrow), camera)
+		f.count(1, renderer.LineVertices)
+	}
+}
@@ -0,0 +1,168 @@
+//go:build !js && !android
+
+package main
+
+import (
+	"relativity_simulation_2d/internal/config"
+	"relativity_simulation_2d/internal/renderer"
+	"slices"
+	"testing"
+)
+
+// recordingLayer is a layer that records its draws
+type recordingLayer struct {
+	name    string
+	overlay bool
+	drawn   *[]string
+}
+
+func (l recordingLayer) Name() string     { return l.name }
+func (l recordingLayer) Overlay() bool    { return l.overlay }
+func (l recordingLayer) Draw(*sceneFrame) { *l.drawn = append(*l.drawn, l.name) }
+
+// newRecordingScene returns a scene of layers a, b, c and the overlay d,
+// and the record of their draws
+func newRecordingScene() (*SceneRenderer, *[]string) {
+	drawn := &[]string{}
+	return NewSceneRenderer(
+		recordingLayer{name: "a", drawn: drawn},
+		recordingLayer{name: "b", drawn: drawn},
+		recordingLayer{name: "c", drawn: drawn},
+		recordingLayer{name: "d", overlay: true, drawn: drawn},
+	), drawn
+}
+
+// TestSceneRendererPasses tests that world and overlay layers are drawn in
+// their own passes, in order
+func TestSceneRendererPasses(t *testing.T) {
+	s, drawn := newRecordingScene()
+	s.DrawWorld(nil)
+	if !slices.Equal(*drawn, []string{"a", "b", "c"}) {
+		t.Errorf("Expected the world layers a, b, c, got %v", *drawn)
+	}
+	*drawn = nil
+	s.DrawOverlay(nil)
+	if !slices.Equal(*drawn, []string{"d"}) {
+		t.Errorf("Expected the overlay layer d, got %v", *drawn)
+	}
+}
+
+// TestSceneRendererConfigure tests that configured layers come first in the
+// given order and the others follow hidden
+func TestSceneRendererConfigure(t *testing.T) {
+	s, drawn := newRecordingScene()
+	if err := s.Configure([]string{"c", "a", "c"}); err != nil {
+		t.Fatalf("Configure failed: %v", err)
+	}
+	if names := s.Names(); !slices.Equal(names, []string{"c", "a", "b", "d"}) {
+		t.Errorf("Expected the order c, a, b, d, got %v", names)
+	}
+	s.DrawWorld(nil)
+	s.DrawOverlay(nil)
+	if !slices.Equal(*drawn, []string{"c", "a"}) {
+		t.Errorf("Expected only c and a drawn, got %v", *drawn)
+	}
+	if err := s.Configure([]string{"z"}); err == nil {
+		t.Error("Expected an error for an unknown layer")
+	}
+}
+
+// TestSceneRendererToggle tests toggling layers by position and by name
+func TestSceneRendererToggle(t *testing.T) {
+	s, drawn := newRecordingScene()
+	if name, shown, ok := s.Toggle(1); name != "b" || shown || !ok {
+		t.Errorf("Expected b hidden, got %q shown=%v ok=%v", name, shown, ok)
+	}
+	if _, _, ok := s.Toggle(4); ok {
+		t.Error("Expected no layer at position 4")
+	}
+	if err := s.SetShown("a", false); err != nil || s.Shown("a") {
+		t.Errorf("Expected a hidden, got shown=%v (%v)", s.Shown("a"), err)
+	}
+	s.DrawWorld(nil)
+	if !slices.Equal(*drawn, []string{"c"}) {
+		t.Errorf("Expected only c drawn, got %v", *drawn)
+	}
+}
+
+// TestSceneRendererMove tests moving layers in the drawing order, stopping
+// at the ends
+func TestSceneRendererMove(t *testing.T) {
+	s, _ := newRecordingScene()
+	_ = s.SetShown("a", false)
+	if err := s.Move("a", 2); err != nil {
+		t.Fatalf("Move failed: %v", err)
+	}
+	if names := s.Names(); !slices.Equal(names, []string{"b", "c", "a", "d"}) {
+		t.Errorf("Expected b, c, a, d, got %v", names)
+	}
+	if s.Shown("a") {
+		t.Error("Expected a to stay hidden after moving")
+	}
+	_ = s.Move("d", -10)
+	if names := s.Names(); !slices.Equal(names, []string{"d", "b", "c", "a"}) {
+		t.Errorf("Expected d moved to the front, got %v", names)
+	}
+}
+
+// countingLayer is a layer that counts one line per draw
+type countingLayer struct{}
+
+func (countingLayer) Name() string       { return "lines" }
+func (countingLayer) Overlay() bool      { return false }
+func (countingLayer) Draw(f *sceneFrame) { f.count(1, renderer.LineVertices) }
+
+// TestSceneRendererStats tests that the statistics count the draws of each
+// shown layer, per eye, and skip the hidden ones
+func TestSceneRendererStats(t *testing.T) {
+	drawn := &[]string{}
+	s := NewSceneRenderer(countingLayer{}, recordingLayer{name: "hidden", drawn: drawn})
+	_ = s.SetShown("hidden", false)
+	f := &sceneFrame{}
+	s.DrawWorld(f)
+	s.DrawWorld(f) // The second eye
+	s.Stats().EndFrame(0.016, 0, 0)
+
+	layers := s.Stats().Layers()
+	if len(layers) != 1 || layers[0].Name != "lines" || layers[0].DrawCalls != 2 || layers[0].Vertices != 4 {
+		t.Errorf("Expected 2 lines counted, got %+v", layers)
+	}
+	if f.stats != nil {
+		t.Error("Expected the frame's counters cleared after the pass")
+	}
+}
+
+// TestGridLines tests counting the segments of a strided grid
+func TestGridLines(t *testing.T) {
+	if n := gridLines(4, 3, 1); n != 4*2+3*3 {
+		t.Errorf("Expected 17 segments, got %d", n)
+	}
+	if n := gridLines(5, 5, 2); n != 3*4+3*4 {
+		t.Errorf("Expected 24 segments every second line, got %d", n)
+	}
+}
+
+// TestNewScene tests that the scene has every configurable layer, with the
+// configured ones shown first
+func TestNewScene(t *testing.T) {
+	saved := cfg
+	defer func() { cfg = saved }()
+	cfg = config.DefaultConfig()
+
+	s := newScene()
+	names := s.Names()
+	if !slices.Equal(slices.Sorted(slices.Values(names)), slices.Sorted(slices.Values(config.SceneLayers))) {
+		t.Fatalf("Expected the layers %v, got %v", config.SceneLayers, names)
+	}
+	shown, _ := config.ParseLayers(config.DefaultLayers)
+	for i, name := range names {
+		if want := i < len(shown); s.Shown(name) != want || (want && name != shown[i]) {
+			t.Errorf("Layer %d is %s (shown %v), want the default layers %v first", i, name, s.Shown(name), shown)
+		}
+	}
+
+	cfg.Layers = "minimap,trails"
+	if names := newScene().Names(); names[0] != config.LayerMinimap || names[1] != config.LayerTrails {
+		t.Errorf("Expected minimap and trails first, got %v", names)
+	}
+}
@@ -1,14 +1,30 @@
+//go:build !js
+
 package main
 
 import (
+	"errors"
+	"flag"
 	"fmt"
 	rl "github.com/gen2brain/raylib-go/raylib"
-	"github.com/go-gl/gl/v4.3-core/gl"
 	"math"
+	"math/rand"
+	"os"
 	"relativity_simulation_2d/internal/config"
+	"relativity_simulation_2d/internal/crash"
+	"relativity_simulation_2d/internal/cuda"
+	"relativity_simulation_2d/internal/export"
 	"relativity_simulation_2d/internal/gpu"
+	"relativity_simulation_2d/internal/guard"
+	"relativity_simulation_2d/internal/health"
 	"relativity_simulation_2d/internal/input"
 	"relativity_simulation_2d/internal/physics"
+	"relativity_simulation_2d/internal/renderer"
+	"relativity_simulation_2d/internal/scenario"
+	"relativity_simulation_2d/internal/simulation"
+	"relativity_simulation_2d/internal/slowmo"
+	"relativity_simulation_2d/internal/verification"
+	"strings"
 	"time"
 )
 
@@ -16,59 +32,221 @@ var (
 	cfg              *config.Config
 	pause            bool
 	useGPU           bool
+	computeMode      string // config.ComputeAuto, ComputeCPU or ComputeGPU; G cycles it
 	mouseSensitivity float32
 	yaw              float32
 	pitch            float32
+	ui               *renderer.UIRenderer
+	initialParticles []*physics.Particle    // Imported initial conditions (nil = random)
+	controls         *input.InputController // Keeps touch gestures across frames
+	quality          *qualityState          // Adaptive quality of the interactive session
+	slowMotion       *slowmo.Controller     // Event-triggered slow motion of the interactive session (nil = off)
+	scene            *SceneRenderer         // Layers of the drawn scene; the number keys toggle them
 )
 
 // Simulation holds the entire state of the GR simulation
 type Simulation struct {
 	Particles       []*physics.Particle
-	PotentialGrid   [][]float64 // Stores the scalar potential Φ (proportional to h_00)
-	MassDensityGrid [][]float64 // Stores the mass density ρ
-	AccelFieldX     [][]float64 // Stores the X component of the acceleration field
-	AccelFieldZ     [][]float64 // Stores the Z component of the acceleration field
-	gpu             *gpu.GPU    // Optional GPU context for acceleration (nil = CPU-only)
+	PotentialGrid   physics.Grid      // Stores the scalar potential Φ (proportional to h_00)
+	MassDensityGrid physics.Grid      // Stores the mass density ρ
+	AccelFieldX     physics.Grid      // Stores the X component of the acceleration field
+	AccelFieldZ     physics.Grid      // Stores the Z component of the acceleration field
+	gpu             *gpu.GPU          // Optional GPU context for acceleration (nil = CPU-only)
+	cuda            *cuda.Solver      // CUDA backend, created on first use (nil = not in use)
+	StepCount       int64             // Number of completed simulation steps
+	SimTime         float64           // Elapsed simulation time
+	precision       physics.Precision // CPU grid/FFT precision
+	mesh            *physics.Mesh     // Cell size and deposition of the grids
+	Forces          []physics.Force   // Extra forces kicked around each gravity step (nil = gravity only)
+
+	// Selects the processor of each particle-mesh phase in GPU mode, moving
+	// to the CPU after a GPU error
+	compute *gpu.FallbackManager
+	deposit *physics.IncrementalDeposit // Keeps MassDensityGrid between PM steps (nil = full deposits)
+	sparse  *physics.SparseSolver       // PM steps on the tiles holding mass (nil = dense grids)
+	chaos   *physics.LyapunovTracker    // Shadows of the first particles for Lyapunov exponents (nil = off)
+
+	// Bound pairs, detected every cfg.BinaryInterval steps
+	binaries  *physics.BinaryTracker // nil = detection off
+	binaryLog *export.BinaryLog      // Formation and disruption events (nil = not logged)
+
+	health *health.Monitor // Scores the run every cfg.HealthInterval steps (nil = off)
+
+	// Named subsets of the particles, from the scenario, cfg.GroupRegions and T
+	groups []physics.Group
+
+	sheet *physics.TracerSheet // Tracers after the particles, drawn as a mesh (nil = none)
 
 	// Error handling state for testing
-	forceGPUInitFailure bool // For testing GPU initialization failures
-	forceGPUCompFailure bool // For testing GPU computation failures
-	gpuErrorOccurred    bool // Tracks if GPU error occurred
-	fallbackToCPU       bool // Tracks if fallback to CPU was triggered
+	forceGPUInitFailure bool  // For testing GPU initialization failures
+	forceGPUCompFailure bool  // For testing GPU computation failures
+	gpuErrorOccurred    bool  // Tracks if GPU error occurred
+	fallbackToCPU       bool  // Tracks if fallback to CPU was triggered
+	lastGPUError        error // Most recent GPU error (nil if none)
+	cpuDiagnostics      bool  // GPU reductions failed; compute diagnostics on the CPU
+	cpuGridView         bool  // GPU potential rendering failed; draw the grid from the CPU copy
+
+	// Scenario masses and markers, drawn over the particles
+	Masses  []physics.PointMass       // Analytic masses, not simulated as particles
+	Markers []scenario.Marker         // Labelled points such as Lagrange points
+	tidal   *scenario.TidalDisruption // Tidal disruption parameters for TidalStats (nil = other scenario)
+
+	// Velocity flow maps for the grid coloring, recomputed every cfg.FlowInterval steps
+	flow      physics.FlowField
+	flowStep  int64
+	flowReady bool
+
+	// State as of the last completed step, read by the renderer so that
+	// drawing never sees fields that a step is halfway through overwriting
+	frames *simulation.FrameBuffer
+
+	observers simulation.Observers // Called after each step, e.g. binary detection
 }
 
 // NewSimulation creates and initializes a new simulation instance
 func NewSimulation() *Simulation {
 	sim := &Simulation{
-		Particles:       make([]*physics.Particle, cfg.NumParticles),
-		PotentialGrid:   make([][]float64, cfg.SimulationWidth),
-		MassDensityGrid: make([][]float64, cfg.SimulationWidth),
-		AccelFieldX:     make([][]float64, cfg.SimulationWidth),
-		AccelFieldZ:     make([][]float64, cfg.SimulationWidth),
+		PotentialGrid:   physics.NewGrid(cfg.SimulationWidth, cfg.SimulationDepth),
+		MassDensityGrid: physics.NewGrid(cfg.SimulationWidth, cfg.SimulationDepth),
+		AccelFieldX:     physics.NewGrid(cfg.SimulationWidth, cfg.SimulationDepth),
+		AccelFieldZ:     physics.NewGrid(cfg.SimulationWidth, cfg.SimulationDepth),
 	}
-
-	for i := range sim.PotentialGrid {
-		sim.PotentialGrid[i] = make([]float64, cfg.SimulationDepth)
-		sim.MassDensityGrid[i] = make([]float64, cfg.SimulationDepth)
-		sim.AccelFieldX[i] = make([]float64, cfg.SimulationDepth)
-		sim.AccelFieldZ[i] = make([]float64, cfg.SimulationDepth)
+	sim.precision, _ = physics.ParsePrecision(cfg.Precision) // Validated at startup
+	sim.mesh = &physics.Mesh{CellSize: cfg.CellSize, Deterministic: cfg.Deterministic}
+	sim.mesh.SetDepositStride(physicsLevel().DepositStride()) // Keep the session's physics level across presets
+	if cfg.IncrementalDeposit {
+		sim.deposit = physics.NewIncrementalDeposit(sim.mesh)
 	}
+	if cfg.SparseGrid {
+		sim.sparse = physics.NewSparseSolver()
+		sim.sparse.Crop = cfg.CropSolve
+		sim.sparse.Padding = cfg.CropPadding
+		sim.sparse.CellSize = cfg.CellSize
+	}
+	sim.compute = newComputeManager(sim)
 
 	// Initialize particles using extracted function
-	sim.Particles = physics.InitializeParticles(cfg.NumParticles, float64(cfg.SimulationWidth), float64(cfg.SimulationDepth))
+	switch {
+	case initialParticles != nil:
+		sim.Particles = make([]*physics.Particle, len(initialParticles))
+		for i, p := range initialParticles {
+			particle := *p
+			sim.Particles[i] = &particle
+		}
+	case sim.loadScenario():
+	case cfg.Seed != 0:
+		width, depth := cfg.DomainSize()
+		sim.Particles = physics.InitializeParticlesWithSeed(cfg.NumParticles, width, depth, cfg.Seed)
+	default:
+		width, depth := cfg.DomainSize()
+		sim.Particles = physics.InitializeParticles(cfg.NumParticles, width, depth)
+	}
 
 	// Optionally add a large central mass (uncomment to enable)
 	// sim.Particles = physics.InitializeParticlesWithCentralMass(cfg.NumParticles, float64(cfg.SimulationWidth), float64(cfg.SimulationDepth), 1000)
 
+	physics.ApplyRadius(sim.Particles, radiusModel())
+	sim.tagGroupRegions()
+	sim.addTracerSheet()
+
+	if cfg.FrameOmega != 0 {
+		frame := physics.RotatingFrame{Omega: cfg.FrameOmega}
+		frame.FromInertial(sim.Particles)
+		sim.Forces = append(sim.Forces, frame.Forces()...)
+	}
+	if cfg.ImageCorrection {
+		sim.Forces = append(sim.Forces, physics.NewImageCorrection(cfg.SimulationWidth, cfg.SimulationDepth, sim.mesh.Dx(), cfg.GravitationalConstant))
+	}
+	if cfg.SpongeWidth > 0 {
+		sim.Forces = append(sim.Forces, physics.Sponge{
+			Width:     cfg.SimulationWidth,
+			Height:    cfg.SimulationDepth,
+			Thickness: cfg.SpongeWidth,
+			Strength:  cfg.SpongeStrength,
+			CellSize:  cfg.CellSize,
+		})
+	}
+	if cfg.LyapunovParticles > 0 {
+		sim.chaos = physics.NewLyapunovTracker(sim.Particles, cfg.LyapunovParticles, cfg.SimulationWidth, cfg.SimulationDepth, cfg.CellSize, cfg.Seed)
+	}
+	if cfg.HealthInterval > 0 {
+		sim.health = newHealthMonitor()
+	}
+	if cfg.BinarySeparation > 0 {
+		sim.binaries = physics.NewBinaryTracker(binaryOptions())
+		sim.RegisterObserver(binaryInterval(), func(step int64, s *Simulation) { s.detectBinaries(step) })
+	}
+
+	sim.frames = simulation.NewFrameBuffer(len(sim.Particles), cfg.SimulationWidth, cfg.SimulationDepth)
+	sim.publish()
 	return sim
 }
 
+// loadScenario sets up the configured built-in scenario with one tracer or
+// cluster particle per configured particle. It returns false, leaving the
+// simulation unchanged, for random particles or if the scenario cannot be built
+func (s *Simulation) loadScenario() bool {
+	var build func(*rand.Rand) (*scenario.Scenario, error)
+	switch cfg.Scenario {
+	case config.ScenarioThreeBody:
+		build = scenario.DefaultThreeBody(cfg.SimulationWidth, cfg.SimulationDepth, cfg.Spacing(), cfg.GravitationalConstant, cfg.NumParticles).Build
+	case config.ScenarioTidalDisruption:
+		tidal := scenario.DefaultTidalDisruption(cfg.SimulationWidth, cfg.SimulationDepth, cfg.Spacing(), cfg.GravitationalConstant, cfg.NumParticles)
+		s.tidal = &tidal
+		build = tidal.Build
+	default:
+		return false
+	}
+
+	seed := cfg.Seed
+	if seed == 0 {
+		seed = time.Now().UnixNano()
+	}
+	built, err := build(rand.New(rand.NewSource(seed)))
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Warning: %v; using random particles\n", err)
+		s.tidal = nil
+		return false
+	}
+	s.Particles, s.Forces, s.Masses, s.Markers, s.groups = built.Particles, built.Forces, built.Masses, built.Markers, built.Groups
+	return true
+}
+
+// TidalStats measures the cluster of the tidal disruption scenario. It
+// returns false in other scenarios
+func (s *Simulation) TidalStats() (scenario.TidalStats, bool) {
+	return s.tidalStats(s.Particles)
+}
+
+// tidalStats measures the cluster among the given particles
+func (s *Simulation) tidalStats(particles []*physics.Particle) (scenario.TidalStats, bool) {
+	if s.tidal == nil {
+		return scenario.TidalStats{}, false
+	}
+	width, depth := cfg.DomainSize()
+	return s.tidal.Measure(particles, width, depth), true
+}
+
+// radiusModel returns the configured particle radius model, or the default
+// one if the configuration is invalid
+func radiusModel() physics.RadiusModel {
+	model, err := physics.ParseRadiusModel(cfg.RadiusModel, cfg.ParticleDensity, cfg.ParticleRadius)
+	if err != nil {
+		return physics.DefaultRadius()
+	}
+	return model
+}
+
 // CleanupGPU releases GPU resources if allocated
 func (s *Simulation) CleanupGPU() {
 	if s.gpu != nil {
 		_ = CleanupGPU(s.gpu) // Ignore cleanup errors
 		s.gpu = nil
 	}
+	if s.cuda != nil {
+		_ = s.cuda.Close()
+		s.cuda = nil
+	}
 }
 
 // HasGPUErrorOccurred returns true if a GPU error was encountered
@@ -78,1222 +256,1271 @@ func (s *Simulation) HasGPUErrorOccurred() bool {
 
 // Update runs one full step of the simulation with frame-rate independent timing
 func (s *Simulation) Update(deltaTime float32) {
+	if cfg.DirectSolver() {
+		s.updateDirect(deltaTime)
+		return
+	}
+
 	// Use the extracted physics engine for time evolution
-	forceField := physics.RunTimeEvolution(s.Particles, deltaTime, cfg.SimulationWidth, cfg.SimulationDepth, cfg.GravitationalConstant)
+	forceField := physics.RunTimeEvolutionWithPrecision(s.precision, s.Particles, deltaTime, cfg.SimulationWidth, cfg.SimulationDepth, s.mesh, cfg.GravitationalConstant)
 
 	// Update our internal acceleration fields for visualization
-	s.AccelFieldX = forceField.AccelFieldX
-	s.AccelFieldZ = forceField.AccelFieldZ
-
-	// Update mass density grid for visualization
-	s.MassDensityGrid = physics.DepositMassToGrid(s.Particles, cfg.SimulationWidth, cfg.SimulationDepth)
+	physics.CopyGrid(s.AccelFieldX, forceField.AccelFieldX)
+	physics.CopyGrid(s.AccelFieldZ, forceField.AccelFieldZ)
+	forceField.Release()
 
-	// Update potential grid for visualization
-	s.PotentialGrid = physics.SolvePoissonFFT(s.MassDensityGrid, cfg.SimulationWidth, cfg.SimulationDepth, cfg.GravitationalConstant)
-}
+	// Update mass density and potential grids for visualization
+	s.solveView()
 
-// solvePotential solves ∇²Φ = 4πGρ using FFT (kept for GPU fallback)
-func (s *Simulation) solvePotential() {
-	s.PotentialGrid = physics.SolvePoissonFFT(s.MassDensityGrid, cfg.SimulationWidth, cfg.SimulationDepth, cfg.GravitationalConstant)
+	s.advanceClock(deltaTime)
 }
 
-// Real GPU Types and Functions for OpenGL 4.3+ Compute Shaders
-// These replace fake CPU implementations with actual GPU acceleration
-// Uses raylib's OpenGL context instead of separate GLFW window
+// updateSparse runs one PM step on the sparse density grid. The drawn grids
+// still cover the whole domain, so they are computed from the particles
+func (s *Simulation) updateSparse(deltaTime float32) {
+	s.sparse.Step(s.Particles, deltaTime, cfg.SimulationWidth, cfg.SimulationDepth, cfg.GravitationalConstant)
 
-// isHeadlessEnvironment detects if we're running in a headless environment (tests)
-func isHeadlessEnvironment() bool {
-	// Check if raylib window is initialized
-	return !rl.IsWindowReady()
-}
+	s.solveView()
+	physics.CalculateGradientInto(&physics.ForceField{
+		AccelFieldX: s.AccelFieldX,
+		AccelFieldZ: s.AccelFieldZ,
+		Width:       cfg.SimulationWidth,
+		Height:      cfg.SimulationDepth,
+		CellSize:    s.mesh.CellSize,
+	}, s.PotentialGrid)
 
-// InitializeGPU initializes GPU with proper context handling
-func InitializeGPU() (*gpu.GPU, error) {
-	return InitializeGPUWithMode(false)
+	s.advanceClock(deltaTime)
 }
 
-// InitializeGPUWithMode initializes GPU with optional headless mode
-func InitializeGPUWithMode(forceHeadless bool) (*gpu.GPU, error) {
-	// Check if we need to create a headless context
-	headless := forceHeadless || isHeadlessEnvironment()
-
-	var needsCleanup bool
-	if headless {
-		// Initialize minimal raylib context for OpenGL
-		rl.SetConfigFlags(rl.FlagWindowHidden)
-		rl.InitWindow(1, 1, "GPU Test Context")
-		needsCleanup = true
-	}
-
-	// Initialize OpenGL function pointers
-	if err := gl.Init(); err != nil {
-		if needsCleanup {
-			rl.CloseWindow()
-		}
-		return nil, fmt.Errorf("failed to initialize OpenGL: %v", err)
+// updateDirect runs one step with the direct N-body solver. The grids are still
+// computed from the particles so the spacetime grid can be drawn
+func (s *Simulation) updateDirect(deltaTime float32) {
+	opts := physics.DirectOptions{
+		GravitationalConstant: cfg.GravitationalConstant,
+		Softening:             cfg.Softening,
+		EncounterRadius:       cfg.EncounterRadius,
+		CellSize:              s.mesh.CellSize,
+	}
+	if cfg.Solver == config.SolverDirectGPU {
+		opts.Accelerations = s.directAccelerationsGPU
+	}
+	if cfg.BlockLevels > 0 {
+		physics.RunBlockTimeEvolution(s.Particles, deltaTime, cfg.SimulationWidth, cfg.SimulationDepth, physics.BlockOptions{
+			GravitationalConstant: cfg.GravitationalConstant,
+			Softening:             cfg.Softening,
+			MaxLevel:              cfg.BlockLevels,
+			CellSize:              s.mesh.CellSize,
+		})
+	} else {
+		physics.RunDirectTimeEvolution(s.Particles, deltaTime, cfg.SimulationWidth, cfg.SimulationDepth, opts)
 	}
 
-	// Test if OpenGL context is working
-	var testBuffer uint32
-	gl.GenBuffers(1, &testBuffer)
-	if testBuffer == 0 {
-		glError := gl.GetError()
-		if needsCleanup {
-			rl.CloseWindow()
-		}
-		return nil, fmt.Errorf("OpenGL context not available: GenBuffers failed (GL error: %d)", glError)
-	}
-	gl.DeleteBuffers(1, &testBuffer)
+	s.solveView()
+	physics.CalculateGradientInto(&physics.ForceField{
+		AccelFieldX: s.AccelFieldX,
+		AccelFieldZ: s.AccelFieldZ,
+		Width:       cfg.SimulationWidth,
+		Height:      cfg.SimulationDepth,
+		CellSize:    s.mesh.CellSize,
+	}, s.PotentialGrid)
 
-	return &gpu.GPU{
-		Initialized:  true,
-		Headless:     headless,
-		NeedsCleanup: needsCleanup,
-		FftPlanCache: make(map[string]*gpu.GPUFFTPlan),
-		ShaderCache:  make(map[string]*gpu.ComputeShader),
-	}, nil
+	s.advanceClock(deltaTime)
 }
 
-func AllocateGPUMemory(g *gpu.GPU, sizeBytes int) (*gpu.GPUMemoryBuffer, error) {
-	if !g.Initialized {
-		return nil, fmt.Errorf("GPU context not initialized")
-	}
-
-	var bufferID uint32
-	gl.GenBuffers(1, &bufferID)
-
-	// Debug: Check if GenBuffers is working
-	if bufferID == 0 {
-		// Try to get more info about OpenGL state
-		glError := gl.GetError()
-		return nil, fmt.Errorf("gl.GenBuffers returned 0, GL error: %d", glError)
+// solveView fills MassDensityGrid and PotentialGrid, which only the view
+// reads, from the particles; coarsely while the physics budget asks for it
+func (s *Simulation) solveView() {
+	if factor := physicsLevel().ViewFactor(); factor > 1 {
+		physics.SolveCoarseInto(s.PotentialGrid, s.MassDensityGrid, s.Particles, factor, s.mesh, cfg.GravitationalConstant)
+		return
 	}
+	physics.DepositMassToGridInto(s.MassDensityGrid, s.Particles, s.mesh)
+	physics.SolvePoissonWithPrecision(s.precision, s.PotentialGrid, s.MassDensityGrid, s.mesh.Dx(), cfg.GravitationalConstant)
+}
 
-	gl.BindBuffer(gl.SHADER_STORAGE_BUFFER, bufferID)
-	gl.BufferData(gl.SHADER_STORAGE_BUFFER, sizeBytes, gl.Ptr(nil), gl.DYNAMIC_DRAW)
+// Step advances the simulation by one step using the currently selected compute mode.
+// Extra forces are kicked for half a step on either side of the gravity step
+func (s *Simulation) Step(deltaTime float32) {
+	physics.KickForces(s.Particles, s.Forces, float64(deltaTime)/2)
+	switch {
+	case useGPU && !cfg.DirectSolver():
+		s.UpdateGPU(deltaTime) // Use GPU acceleration
+	case previewFactor() > 1:
+		s.updatePreview(deltaTime) // Coarse forces while the user interacts
+	case s.sparse != nil && !cfg.DirectSolver():
+		s.updateSparse(deltaTime)
+	case s.deposit != nil && !cfg.DirectSolver():
+		s.updatePMOnCPU(deltaTime) // Only the backend step keeps the density between steps
+	default:
+		s.Update(deltaTime)
+	}
+	physics.KickForces(s.Particles, s.Forces, float64(deltaTime)/2)
+	if s.chaos != nil {
+		s.chaos.Step(s.Particles, deltaTime, &physics.ForceField{
+			AccelFieldX: s.AccelFieldX,
+			AccelFieldZ: s.AccelFieldZ,
+			Width:       cfg.SimulationWidth,
+			Height:      cfg.SimulationDepth,
+			CellSize:    s.mesh.CellSize,
+		})
+	}
+	s.publish()
+	s.observers.Notify(s.StepCount)
+}
 
-	if glError := gl.GetError(); glError != gl.NO_ERROR {
-		return nil, fmt.Errorf("OpenGL error during buffer allocation: %d", glError)
-	}
+// Observer is called after a step with the number of completed steps
+type Observer func(step int64, s *Simulation)
 
-	return &gpu.GPUMemoryBuffer{BufferID: bufferID, Size: sizeBytes}, nil
+// RegisterObserver calls fn after every step whose number is a multiple of
+// stride (stride < 1 = every step), once the step is published. Observers run
+// in registration order and must not step the simulation themselves
+func (s *Simulation) RegisterObserver(stride int64, fn Observer) {
+	s.observers.Register(stride, func(step int64) { fn(step, s) })
 }
 
-func CompileComputeShader(g *gpu.GPU, source string) (*gpu.ComputeShader, error) {
-	if !g.Initialized {
-		return nil, fmt.Errorf("GPU context not initialized")
-	}
+// publish makes the current state the frame that ReadFrame returns
+func (s *Simulation) publish() {
+	s.frames.Publish(s.StepCount, s.SimTime, s.Particles, s.PotentialGrid, s.MassDensityGrid, s.AccelFieldX, s.AccelFieldZ)
+}
 
-	// Create and compile compute shader
-	shaderID := gl.CreateShader(gl.COMPUTE_SHADER)
-	cSources, free := gl.Strs(source + "\x00")
-	gl.ShaderSource(shaderID, 1, cSources, nil)
-	free()
-	gl.CompileShader(shaderID)
+// ReadFrame calls fn with the state as of the last completed step (or spawn).
+// The frame is not modified while fn runs and must not be retained after it
+func (s *Simulation) ReadFrame(fn func(frame *simulation.Frame)) {
+	s.frames.Read(fn)
+}
 
-	// Check compilation status
-	var status int32
-	gl.GetShaderiv(shaderID, gl.COMPILE_STATUS, &status)
-	if status == gl.FALSE {
-		var logLength int32
-		gl.GetShaderiv(shaderID, gl.INFO_LOG_LENGTH, &logLength)
+// GetParticles returns the current particles
+func (s *Simulation) GetParticles() []*physics.Particle {
+	return s.Particles
+}
 
-		log := make([]byte, logLength+1)
-		gl.GetShaderInfoLog(shaderID, logLength, nil, &log[0])
+// GetStepCount returns the number of completed simulation steps
+func (s *Simulation) GetStepCount() int64 {
+	return s.StepCount
+}
 
-		gl.DeleteShader(shaderID)
-		return nil, fmt.Errorf("compute shader compilation failed: %s", string(log))
-	}
+// GetSimTime returns the elapsed simulation time
+func (s *Simulation) GetSimTime() float64 {
+	return s.SimTime
+}
 
-	// Create program and link
-	programID := gl.CreateProgram()
-	gl.AttachShader(programID, shaderID)
-	gl.LinkProgram(programID)
+// CurrentPotential returns the potential grid of the last step, shared, not copied
+func (s *Simulation) CurrentPotential() physics.Grid {
+	return s.PotentialGrid
+}
 
-	// Check linking status
-	gl.GetProgramiv(programID, gl.LINK_STATUS, &status)
-	if status == gl.FALSE {
-		var logLength int32
-		gl.GetProgramiv(programID, gl.INFO_LOG_LENGTH, &logLength)
+// ParticleEnergy returns the kinetic and potential energy of particle i in the
+// simulation frame, with the potential interpolated from the current grid
+func (s *Simulation) ParticleEnergy(i int) physics.ParticleEnergy {
+	return physics.ComputeParticleEnergy(s.Particles[i], s.PotentialGrid, s.mesh.Dx(), physics.Vec3{})
+}
 
-		log := make([]byte, logLength+1)
-		gl.GetProgramInfoLog(programID, logLength, nil, &log[0])
+// LocalDensities returns the surface density around each particle. Up to
+// physics.MaxKernelDensityParticles it uses the adaptive-kernel estimate,
+// which resolves sparse systems smoothly; beyond that it interpolates the
+// CIC mass grid
+func (s *Simulation) LocalDensities() []float64 {
+	return localDensities(s.Particles, s.MassDensityGrid, s.mesh.Dx())
+}
 
-		gl.DeleteProgram(programID)
-		gl.DeleteShader(shaderID)
-		return nil, fmt.Errorf("compute shader linking failed: %s", string(log))
+// localDensities estimates the surface density around each particle as
+// LocalDensities, from the given particles and mass grid of cells of size dx
+func localDensities(particles []*physics.Particle, massDensity physics.Grid, dx float64) []float64 {
+	if len(particles) <= physics.MaxKernelDensityParticles {
+		return physics.EstimateKernelDensity(particles, cfg.SimulationWidth, cfg.SimulationDepth, dx, physics.DefaultKernelNeighbours).Density
 	}
+	densities := make([]float64, len(particles))
+	for i, p := range particles {
+		densities[i] = physics.InterpolateGrid(massDensity, p.Position.X, p.Position.Z, dx)
+	}
+	return densities
+}
 
-	// Clean up shader (program retains copy)
-	gl.DeleteShader(shaderID)
+// FlowField returns the particle velocity field and its divergence, vorticity
+// and shear, recomputing them if they are cfg.FlowInterval or more steps old
+func (s *Simulation) FlowField() physics.FlowField {
+	return s.flowFieldAt(s.StepCount, s.Particles)
+}
 
-	return &gpu.ComputeShader{ProgramID: programID}, nil
+// flowFieldAt returns the flow maps of the given particles at the given step,
+// reusing the cached maps if they are less than cfg.FlowInterval steps old
+// (longer while the physics budget pauses diagnostics)
+func (s *Simulation) flowFieldAt(step int64, particles []*physics.Particle) physics.FlowField {
+	interval := int64(cfg.FlowInterval * physicsLevel().FlowIntervalScale())
+	if !s.flowReady || step-s.flowStep >= interval {
+		s.flow = physics.ComputeFlowField(particles, cfg.SimulationWidth, cfg.SimulationDepth, s.mesh.Dx())
+		s.flowStep = step
+		s.flowReady = true
+	}
+	return s.flow
 }
 
-func DeleteComputeShader(shader *gpu.ComputeShader) error {
-	if shader.ProgramID != 0 {
-		gl.DeleteProgram(shader.ProgramID)
-		shader.ProgramID = 0
-	}
-	return nil
+// SpawnParticle adds a particle at rest at (x, 0, z) with the mean mass of the
+// random initial conditions. It returns false if the point is outside the
+// simulation box or the direct solver is at its particle limit
+func (s *Simulation) SpawnParticle(x, z float64) bool {
+	width, depth := cfg.DomainSize()
+	halfWidth, halfDepth := width/2, depth/2
+	if x < -halfWidth || x >= halfWidth || z < -halfDepth || z >= halfDepth {
+		return false
+	}
+	limit := config.MaxDirectParticles
+	if cfg.Solver == config.SolverDirectGPU {
+		limit = config.MaxDirectGPUParticles
+	}
+	if cfg.DirectSolver() && len(s.Particles) >= limit {
+		return false
+	}
+
+	const mass = 35.0
+	s.Particles = append(s.Particles, &physics.Particle{
+		Position: physics.NewVec3(x, 0, z),
+		Mass:     mass,
+		Radius:   float32(radiusModel().Radius(mass)),
+	})
+	s.publish()
+	return true
 }
 
-func CreateGPUFFTPlan2D(g *gpu.GPU, width, height int, isForward bool) (*gpu.GPUFFTPlan, error) {
-	if !g.Initialized {
-		return nil, fmt.Errorf("GPU context not initialized")
+// advanceClock records the completion of a step of length deltaTime
+func (s *Simulation) advanceClock(deltaTime float32) {
+	s.StepCount++
+	s.SimTime += float64(deltaTime)
+}
+
+// sampleDiagnostics returns the kinetic energy and potential range. In GPU
+// mode they are reduced on the GPU so only two vec4s are downloaded; on any
+// GPU error it switches to the CPU for the rest of the run
+func (s *Simulation) sampleDiagnostics() (kinetic float64, potential gpu.PotentialRange) {
+	if useGPU && s.gpu != nil && !s.fallbackToCPU && !s.cpuDiagnostics {
+		moments, err := ParticleMomentsGPU(s.gpu, s.Particles)
+		if err == nil {
+			potential, err = PotentialRangeGPU(s.gpu)
+		}
+		if err == nil {
+			return moments.KineticEnergy, potential
+		}
+		s.lastGPUError = err
+		s.cpuDiagnostics = true
 	}
 
-	// For now, implement as a Cooley-Tukey FFT using compute shaders
-	// This is a placeholder - real implementation would use optimized GPU FFT library
-	plan := &gpu.GPUFFTPlan{
-		Gpu:       g,
-		Width:     width,
-		Height:    height,
-		IsForward: isForward,
+	kinetic = physics.ComputeDiagnostics(s.Particles).KineticEnergy
+	potential = gpu.PotentialRange{Min: math.Inf(1), Max: math.Inf(-1)}
+	for i := range s.PotentialGrid {
+		for _, v := range s.PotentialGrid[i] {
+			potential.Min = math.Min(potential.Min, v)
+			potential.Max = math.Max(potential.Max, v)
+		}
 	}
+	return kinetic, potential
+}
 
-	// Create compute shaders for FFT operations (simplified for TDD)
-	// Real implementation would have bit-reversal, butterfly operations, and transpose shaders
-	return plan, nil
+// crashState captures the simulation state for a crash report
+func (s *Simulation) crashState() crash.State {
+	diagnostics := physics.ComputeDiagnostics(s.Particles)
+	state := crash.State{
+		Config:      cfg,
+		Diagnostics: &diagnostics,
+	}
+	if cfg.CrashDumpState {
+		state.Snapshot = s.snapshot()
+	}
+	return state
 }
 
-func CreateComplexGPUBuffer(g *gpu.GPU, elementCount int) (*gpu.ComplexGPUBuffer, error) {
-	if !g.Initialized {
-		return nil, fmt.Errorf("GPU context not initialized")
+// notifyConfigWarnings shows the configuration warnings printed at startup in the UI
+func notifyConfigWarnings() {
+	for _, warning := range cfg.Warnings() {
+		ui.Notify(renderer.NotificationWarning, "Config: "+warning)
 	}
+}
 
-	// Complex data requires 2x space (real + imaginary as float32 pairs)
-	sizeBytes := elementCount * 8 // 2 * sizeof(float32)
+// newGuard creates the sanity checker configured by cfg (nil = disabled)
+func newGuard() *guard.Checker {
+	if cfg.GuardInterval <= 0 {
+		return nil
+	}
+	return guard.NewChecker(guard.Options{
+		Interval:     int64(cfg.GuardInterval),
+		EnergyGrowth: cfg.GuardEnergyGrowth,
+	})
+}
 
-	// Use the same method that works for regular buffers
-	memBuffer, err := AllocateGPUMemory(g, sizeBytes)
+// reportViolation writes a guard report with a state snapshot and returns the message to show
+func (s *Simulation) reportViolation(v *guard.Violation) string {
+	v.Snapshot = s.snapshot()
+	path, err := guard.WriteReport(cfg.CrashReportDir, v)
 	if err != nil {
-		return nil, fmt.Errorf("failed to allocate GPU memory for complex buffer: %v", err)
+		fmt.Fprintf(os.Stderr, "Sanity check failed: %v (report not written: %v)\n", v, err)
+		return "Paused: " + v.Error()
 	}
+	fmt.Fprintf(os.Stderr, "Sanity check failed: %v (report: %s)\n", v, path)
+	return fmt.Sprintf("Paused: %v (report: %s)", v, path)
+}
 
-	// Wrap the GPUMemoryBuffer in a ComplexGPUBuffer
-	return &gpu.ComplexGPUBuffer{BufferID: memBuffer.BufferID, Size: elementCount}, nil
+// timeReversal runs the time-reversal test on the simulation with the
+// headless time step. The particles, step count, time, Lyapunov shadows and
+// observers are restored afterwards, so the run continues as if the test had
+// not happened
+func (s *Simulation) timeReversal() (verification.ReversalResult, error) {
+	steps := cfg.ReversalSteps
+	if steps == 0 {
+		steps = verification.DefaultReversalSteps
+	}
+	stepCount, simTime, chaos, observers := s.StepCount, s.SimTime, s.chaos, s.observers
+	s.chaos = nil                        // The shadows would not be reversed with the particles
+	s.observers = simulation.Observers{} // Nor would binaries or measurements on the way be real
+	defer func() {
+		s.StepCount, s.SimTime, s.chaos, s.observers = stepCount, simTime, chaos, observers
+		if s.deposit != nil {
+			s.deposit.Reset() // The particles moved back without it
+		}
+		s.publish()
+	}()
+	return verification.TimeReversal(s, verification.ReversalConfig{
+		Steps:    steps,
+		TimeStep: cfg.FixedTimeStep,
+		Width:    cfg.SimulationWidth,
+		Height:   cfg.SimulationDepth,
+	})
 }
 
-func UploadComplexData(buffer *gpu.ComplexGPUBuffer, data []complex128) error {
-	if buffer.BufferID == 0 {
-		return fmt.Errorf("invalid complex GPU buffer")
-	}
+// solvePotential solves ∇²Φ = 4πGρ using FFT (kept for GPU fallback)
+func (s *Simulation) solvePotential() {
+	physics.SolvePoissonWithPrecision(s.precision, s.PotentialGrid, s.MassDensityGrid, s.mesh.Dx(), cfg.GravitationalConstant)
+}
 
-	if len(data) > buffer.Size {
-		return fmt.Errorf("data too large for buffer: %d > %d", len(data), buffer.Size)
-	}
+// raylibPlatform drives raylib's single window for the GL context manager
+type raylibPlatform struct{}
+
+func (raylibPlatform) WindowReady() bool { return rl.IsWindowReady() }
 
-	// Convert complex128 to interleaved float32 (real, imag, real, imag, ...)
-	float32Data := make([]float32, len(data)*2)
-	for i, c := range data {
-		float32Data[i*2] = float32(real(c))
-		float32Data[i*2+1] = float32(imag(c))
+func (raylibPlatform) OpenWindow(width, height int, title string, hidden bool) {
+	if hidden {
+		rl.SetConfigFlags(rl.FlagWindowHidden)
 	}
+	rl.InitWindow(int32(width), int32(height), title)
+}
 
-	expectedSize := len(float32Data) * 4 // float32 = 4 bytes
-	gl.BindBuffer(gl.SHADER_STORAGE_BUFFER, buffer.BufferID)
-	gl.BufferSubData(gl.SHADER_STORAGE_BUFFER, 0, expectedSize, gl.Ptr(float32Data))
-	gl.MemoryBarrier(gl.SHADER_STORAGE_BARRIER_BIT)
+func (raylibPlatform) CloseWindow() { rl.CloseWindow() }
 
-	if glError := gl.GetError(); glError != gl.NO_ERROR {
-		return fmt.Errorf("OpenGL error during complex data upload: %d", glError)
+// glContext owns the process's OpenGL context, shared by the window and all GPU users
+var glContext = gpu.NewGLContext(raylibPlatform{})
+
+// UpdateGPU performs a simulation timestep using GPU acceleration for Poisson solver
+func (s *Simulation) UpdateGPU(deltaTime float32) {
+	if cfg.GPUBackend == config.GPUBackendCUDA {
+		if s.deposit != nil {
+			s.deposit.Reset() // CUDA overwrites the density grid
+		}
+		s.updateCUDA(deltaTime)
+		return
 	}
 
-	return nil
+	s.updatePM(deltaTime)
 }
 
-func DownloadComplexData(buffer *gpu.ComplexGPUBuffer, elementCount int) ([]complex128, error) {
-	if buffer.BufferID == 0 {
-		return nil, fmt.Errorf("invalid complex GPU buffer")
+// updateCUDA runs the whole step on the CUDA backend and downloads the grids
+// for visualization. Any CUDA error, including a binary built without the
+// cuda tag, switches to the CPU for the rest of the run
+func (s *Simulation) updateCUDA(deltaTime float32) {
+	if s.fallbackToCPU || s.forceGPUInitFailure || s.forceGPUCompFailure {
+		s.gpuErrorOccurred = true
+		s.fallbackToCPU = true
+		s.Update(deltaTime)
+		return
 	}
 
-	if elementCount > buffer.Size {
-		return nil, fmt.Errorf("requested size too large: %d > %d", elementCount, buffer.Size)
+	if s.cuda == nil {
+		solver, err := cuda.NewSolver(cfg.SimulationWidth, cfg.SimulationDepth, s.mesh.Dx())
+		if err != nil {
+			s.lastGPUError = err
+			s.gpuErrorOccurred = true
+			s.fallbackToCPU = true
+			s.Update(deltaTime)
+			return
+		}
+		s.cuda = solver
 	}
 
-	// Download as interleaved float32, then convert to complex128
-	float32Data := make([]float32, elementCount*2)
-	expectedSize := len(float32Data) * 4 // float32 = 4 bytes
-
-	gl.BindBuffer(gl.SHADER_STORAGE_BUFFER, buffer.BufferID)
-	gl.GetBufferSubData(gl.SHADER_STORAGE_BUFFER, 0, expectedSize, gl.Ptr(float32Data))
-
-	if glError := gl.GetError(); glError != gl.NO_ERROR {
-		return nil, fmt.Errorf("OpenGL error during complex data download: %d", glError)
+	if err := s.cuda.Step(s.Particles, deltaTime, cfg.GravitationalConstant); err != nil {
+		s.lastGPUError = err
+		s.gpuErrorOccurred = true
+		s.fallbackToCPU = true
+		s.Update(deltaTime)
+		return
 	}
 
-	// Convert float32 pairs back to complex128
-	data := make([]complex128, elementCount)
-	for i := 0; i < elementCount; i++ {
-		realData := float64(float32Data[i*2])
-		imagData := float64(float32Data[i*2+1])
-		data[i] = complex(realData, imagData)
+	forceField := &physics.ForceField{
+		AccelFieldX: s.AccelFieldX,
+		AccelFieldZ: s.AccelFieldZ,
+		Width:       cfg.SimulationWidth,
+		Height:      cfg.SimulationDepth,
+		CellSize:    s.mesh.CellSize,
+	}
+	if err := s.cuda.Fields(s.MassDensityGrid, s.PotentialGrid, forceField); err != nil {
+		// The step itself completed, so only recompute the grids on the CPU
+		s.lastGPUError = err
+		s.gpuErrorOccurred = true
+		s.fallbackToCPU = true
+		physics.DepositMassToGridInto(s.MassDensityGrid, s.Particles, s.mesh)
+		s.solvePotential()
+		physics.CalculateGradientInto(forceField, s.PotentialGrid)
 	}
 
-	return data, nil
+	s.advanceClock(deltaTime)
 }
 
-func FreeComplexGPUBuffer(buffer *gpu.ComplexGPUBuffer) error {
-	if buffer.BufferID != 0 {
-		gl.DeleteBuffers(1, &buffer.BufferID)
-		buffer.BufferID = 0
+// directAccelerationsGPU is the physics.AccelerationFunc of the direct-gpu
+// solver. It falls back to CPU summation for the rest of the run on any GPU error
+func (s *Simulation) directAccelerationsGPU(particles []*physics.Particle, gravitationalConstant, softening, periodX, periodZ float64) (ax, az []float64) {
+	cpu := func() ([]float64, []float64) {
+		return physics.DirectAccelerationsPeriodic(particles, gravitationalConstant, softening, 0, periodX, periodZ)
 	}
-	return nil
-}
-
-func ExecuteFFT(plan *gpu.GPUFFTPlan, inputBuffer, outputBuffer *gpu.ComplexGPUBuffer) error {
-	if inputBuffer == nil || outputBuffer == nil {
-		return fmt.Errorf("input and output buffers must not be nil")
+	if s.fallbackToCPU {
+		return cpu()
 	}
 
-	totalSize := plan.Width * plan.Height
-
-	// Create FFT shader for this execution
-	fftShader, err := compileFFTComputeShader(plan.Width, plan.Height, plan.IsForward)
-	if err != nil {
-		return fmt.Errorf("failed to compile FFT shader: %v", err)
+	// Check for forced failures (testing)
+	if s.forceGPUInitFailure || s.forceGPUCompFailure {
+		s.gpuErrorOccurred = true
+		s.fallbackToCPU = true
+		return cpu()
 	}
-	defer func() {
-		_ = DeleteComputeShader(fftShader) // Ignore error during cleanup
-	}()
 
-	// Check if we're using Cooley-Tukey (power of 2) or fallback naive DFT
-	if !isPowerOfTwo(plan.Width) || !isPowerOfTwo(plan.Height) {
-		// Naive DFT - single pass
-		return executeNaiveFFT(plan, fftShader, inputBuffer, outputBuffer, totalSize)
+	if s.gpu == nil {
+		GPU, err := InitializeGPU()
+		if err != nil {
+			s.lastGPUError = err
+			s.gpuErrorOccurred = true
+			s.fallbackToCPU = true
+			return cpu()
+		}
+		s.gpu = GPU
 	}
 
-	// Try Cooley-Tukey FFT - multi-stage execution
-	err = executeCooleyTukeyFFT(plan, fftShader, inputBuffer, outputBuffer)
+	ax, az, err := DirectAccelerationsGPU(s.gpu, particles, gravitationalConstant, softening, periodX, periodZ)
 	if err != nil {
-		// Fallback to naive DFT if Cooley-Tukey implementation is incomplete
-		// This allows progressive implementation while maintaining functionality
-		_ = DeleteComputeShader(fftShader) // Clean up the Cooley-Tukey shader
+		s.lastGPUError = err
+		s.gpuErrorOccurred = true
+		s.fallbackToCPU = true
+		return cpu()
+	}
+	return ax, az
+}
 
-		// Create naive DFT shader for fallback
-		naiveFftShader, naiveErr := compileNaiveDFTShader(plan.Width, plan.Height, plan.IsForward)
-		if naiveErr != nil {
-			return fmt.Errorf("Cooley-Tukey failed (%v) and naive fallback failed (%v)", err, naiveErr)
+func processInput(camera *rl.Camera3D, sim *Simulation) {
+	// Process all input through the controller
+	controls.UpdateFromRaylib()
+	state := &input.SimulationState{Pause: pause, UseGPU: useGPU, Yaw: yaw, Pitch: pitch}
+	controls.ProcessInput(camera, state, &input.InputConfig{
+		MoveSpeed:        cfg.MoveSpeed,
+		MouseSensitivity: mouseSensitivity,
+		ScreenWidth:      int(cfg.ScreenWidth),
+		ScreenHeight:     int(cfg.ScreenHeight),
+		TouchEnabled:     cfg.TouchControls,
+		FrameTime:        rl.GetFrameTime(),
+	})
+	pause, yaw, pitch = state.Pause, state.Yaw, state.Pitch
+	if state.Cycle {
+		setComputeMode(sim, nextComputeMode(computeMode))
+		ui.Notify(renderer.NotificationInfo, fmt.Sprintf("Compute mode: %s", sim.compute.GetMode()))
+		if warning := gpuFFTWarning(); warning != "" {
+			ui.Notify(renderer.NotificationWarning, warning)
 		}
-		defer func() {
-			_ = DeleteComputeShader(naiveFftShader)
-		}()
-
-		return executeNaiveFFT(plan, naiveFftShader, inputBuffer, outputBuffer, totalSize)
 	}
 
-	return nil
+	// Spawn a particle where the tap's view ray meets the simulation plane
+	if state.Spawn {
+		ray := rl.GetScreenToWorldRay(rl.NewVector2(state.SpawnX, state.SpawnY), *camera)
+		if x, z, ok := groundPoint(ray); ok {
+			sim.SpawnParticle(x, z)
+		}
+	}
 }
 
-func executeNaiveFFT(plan *gpu.GPUFFTPlan, shader *gpu.ComputeShader, inputBuffer, outputBuffer *gpu.ComplexGPUBuffer, totalSize int) error {
-	// Single-pass naive DFT
-	gl.BindBufferBase(gl.SHADER_STORAGE_BUFFER, 0, inputBuffer.BufferID)
-	gl.BindBufferBase(gl.SHADER_STORAGE_BUFFER, 1, outputBuffer.BufferID)
-
-	gl.UseProgram(shader.ProgramID)
-
-	workGroupsX := uint32((totalSize + 63) / 64)
-	gl.DispatchCompute(workGroupsX, 1, 1)
-	gl.MemoryBarrier(gl.SHADER_STORAGE_BARRIER_BIT)
-
-	if glError := gl.GetError(); glError != gl.NO_ERROR {
-		return fmt.Errorf("OpenGL error during naive FFT execution: %d", glError)
+// groundPoint returns where ray crosses the y = 0 plane, or false if it
+// points away from the plane
+func groundPoint(ray rl.Ray) (x, z float64, ok bool) {
+	if ray.Direction.Y == 0 || (ray.Direction.Y > 0) == (ray.Position.Y > 0) {
+		return 0, 0, false
 	}
-
-	return nil
+	t := -ray.Position.Y / ray.Direction.Y
+	return float64(ray.Position.X + t*ray.Direction.X), float64(ray.Position.Z + t*ray.Direction.Z), true
 }
 
-func executeCooleyTukeyFFT(plan *gpu.GPUFFTPlan, shader *gpu.ComputeShader, inputBuffer, outputBuffer *gpu.ComplexGPUBuffer) error {
-	gl.UseProgram(shader.ProgramID)
-
-	// Get uniform locations
-	stageLocation := gl.GetUniformLocation(shader.ProgramID, gl.Str("stage\x00"))
-	directionLocation := gl.GetUniformLocation(shader.ProgramID, gl.Str("direction_flag\x00"))
-	columnPassLocation := gl.GetUniformLocation(shader.ProgramID, gl.Str("is_column_pass\x00"))
-
-	direction := int32(1)
-	if !plan.IsForward {
-		direction = -1
+// initialCamera returns the camera a session starts with
+func initialCamera() rl.Camera3D {
+	return rl.Camera3D{
+		Position:   rl.NewVector3(50.0, 50.0, 50.0),
+		Target:     rl.NewVector3(0.0, 0.0, 0.0),
+		Up:         rl.NewVector3(0.0, 1.0, 0.0),
+		Fovy:       65.0,
+		Projection: rl.CameraPerspective,
 	}
-	gl.Uniform1i(directionLocation, direction)
+}
 
-	// Create temporary buffer for ping-pong operations
-	tempBuffer, err := createTempComplexBuffer(plan, plan.Width*plan.Height)
+func main() {
+	// Initialize configuration
+	loaded, err := loadConfig(os.Args[1:], "")
 	if err != nil {
-		return fmt.Errorf("failed to create temp buffer: %v", err)
+		if errors.Is(err, flag.ErrHelp) {
+			return
+		}
+		if errors.Is(err, errConfigFile) {
+			fmt.Fprintf(os.Stderr, "Invalid configuration: %v\n", err)
+		}
+		os.Exit(2)
 	}
-	defer func() {
-		_ = FreeComplexGPUBuffer(tempBuffer)
-	}()
-
-	currentInput := inputBuffer
-	currentOutput := tempBuffer
-
-	// Phase 1: Row-wise FFT
-	gl.Uniform1i(columnPassLocation, 0) // Row pass
-	totalSize := uint32(plan.Width * plan.Height)
-	workGroups := (totalSize + 31) / 32
-
-	// Row bit-reversal pass
-	gl.Uniform1i(stageLocation, -1) // Special stage for a bit of reversal
-	gl.BindBufferBase(gl.SHADER_STORAGE_BUFFER, 0, currentInput.BufferID)
-	gl.BindBufferBase(gl.SHADER_STORAGE_BUFFER, 1, currentOutput.BufferID)
-	gl.DispatchCompute(workGroups, 1, 1)
-	gl.MemoryBarrier(gl.SHADER_STORAGE_BARRIER_BIT)
-
-	// Swap buffers
-	currentInput, currentOutput = currentOutput, currentInput
-
-	// Row butterfly stages
-	numStages := int(math.Log2(float64(plan.Width)))
-	for stage := 0; stage < numStages; stage++ {
-		gl.Uniform1i(stageLocation, int32(stage))
-		gl.BindBufferBase(gl.SHADER_STORAGE_BUFFER, 0, currentInput.BufferID)
-		gl.BindBufferBase(gl.SHADER_STORAGE_BUFFER, 1, currentOutput.BufferID)
-		gl.DispatchCompute(workGroups, 1, 1)
-		gl.MemoryBarrier(gl.SHADER_STORAGE_BARRIER_BIT)
-
-		// Swap buffers
-		currentInput, currentOutput = currentOutput, currentInput
-	}
-
-	// Phase 2: Column-wise FFT
-	gl.Uniform1i(columnPassLocation, 1) // Column pass
-
-	// Column bit-reversal pass
-	gl.Uniform1i(stageLocation, -1)
-	gl.BindBufferBase(gl.SHADER_STORAGE_BUFFER, 0, currentInput.BufferID)
-	gl.BindBufferBase(gl.SHADER_STORAGE_BUFFER, 1, currentOutput.BufferID)
-	gl.DispatchCompute(workGroups, 1, 1)
-	gl.MemoryBarrier(gl.SHADER_STORAGE_BARRIER_BIT)
-
-	// Swap buffers
-	currentInput, currentOutput = currentOutput, currentInput
-
-	// Column butterfly stages
-	numStages = int(math.Log2(float64(plan.Height)))
-	for stage := 0; stage < numStages; stage++ {
-		gl.Uniform1i(stageLocation, int32(stage))
-		gl.BindBufferBase(gl.SHADER_STORAGE_BUFFER, 0, currentInput.BufferID)
-		gl.BindBufferBase(gl.SHADER_STORAGE_BUFFER, 1, currentOutput.BufferID)
-		gl.DispatchCompute(workGroups, 1, 1)
-		gl.MemoryBarrier(gl.SHADER_STORAGE_BARRIER_BIT)
-
-		// Swap buffers
-		currentInput, currentOutput = currentOutput, currentInput
-	}
-
-	// Copy final result to output buffer (if needed)
-	if currentInput != outputBuffer {
-		err = copyComplexBuffer(plan, currentInput, outputBuffer)
+	cfg = loaded
+	if cfg.ListPresets {
+		listPresets(os.Stdout)
+		return
+	}
+	if err := cfg.Validate(); err != nil {
+		fmt.Fprintf(os.Stderr, "Invalid configuration: %v\n", err)
+		os.Exit(2)
+	}
+	if cfg.ListGPUs || cfg.GPUDevice != 0 {
+		devices, err := gpu.EnumerateDevices()
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Warning: %v\n", err)
+		}
+		if cfg.ListGPUs {
+			listGPUs(os.Stdout, devices)
+			return
+		}
+		if err := selectGPUDevice(devices, cfg.GPUDevice); err != nil {
+			fmt.Fprintf(os.Stderr, "Warning: %v; using the driver default\n", err)
+		}
+	}
+	if result, ok := loadCalibration(os.Stderr); ok {
+		// Layer the configuration over the calibrated defaults from now on,
+		// including config reloads and presets
+		defaults = func() *config.Config {
+			c := config.DefaultConfig()
+			result.Apply(c)
+			return c
+		}
+		if cfg, err = loadConfig(os.Args[1:], ""); err == nil {
+			err = cfg.Validate()
+		}
 		if err != nil {
-			return fmt.Errorf("failed to copy final result: %v", err)
+			fmt.Fprintf(os.Stderr, "Invalid configuration: %v\n", err)
+			os.Exit(2)
 		}
 	}
-
-	if glError := gl.GetError(); glError != gl.NO_ERROR {
-		return fmt.Errorf("OpenGL error during Cooley-Tukey FFT: %d", glError)
+	if cfg.ImportPath != "" {
+		particles, err := loadInitialConditions(cfg)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Failed to load initial conditions: %v\n", err)
+			os.Exit(2)
+		}
+		initialParticles = particles
+		cfg.NumParticles = len(particles)
+		if err := cfg.Validate(); err != nil { // The imported count may exceed solver limits
+			fmt.Fprintf(os.Stderr, "Invalid configuration: %v\n", err)
+			os.Exit(2)
+		}
 	}
-
-	return nil
-}
-
-func createTempComplexBuffer(plan *gpu.GPUFFTPlan, elementCount int) (*gpu.ComplexGPUBuffer, error) {
-	// Create a temporary complex buffer for intermediate FFT operations
-	tempBuffer, err := CreateComplexGPUBuffer(plan.Gpu, elementCount)
-	if err != nil {
-		return nil, fmt.Errorf("failed to create temporary complex buffer: %v", err)
+	for _, warning := range cfg.Warnings() {
+		fmt.Fprintf(os.Stderr, "Warning: %s\n", warning)
 	}
-	return tempBuffer, nil
-}
-
-func copyComplexBuffer(plan *gpu.GPUFFTPlan, src, dst *gpu.ComplexGPUBuffer) error {
-	// Copy data between complex GPU buffers using OpenGL buffer copy
-	if src.BufferID == 0 || dst.BufferID == 0 {
-		return fmt.Errorf("invalid buffer IDs for copy operation")
+	if err := startRun(time.Now()); err != nil {
+		fmt.Fprintf(os.Stderr, "Failed to create the run directory: %v\n", err)
+		os.Exit(1)
 	}
+	applyScheduling(cfg, os.Stderr)
+	pause = cfg.StartPaused
+	setComputeMode(nil, cfg.Compute())
+	mouseSensitivity = cfg.MouseSensitivity
+	yaw = cfg.InitialYaw
+	pitch = cfg.InitialPitch
+	controls = input.NewInputController()
+	ui = newUIRenderer(cfg)
 
-	// Calculate byte size (complex elements * 8 bytes per element)
-	byteSize := src.Size * 8
-	if dst.Size < src.Size {
-		return fmt.Errorf("destination buffer too small: %d < %d", dst.Size, src.Size)
+	if cfg.RenderDir != "" {
+		if err := runOfflineRender(); err != nil {
+			fmt.Fprintf(os.Stderr, "Offline render failed: %v\n", err)
+			os.Exit(1)
+		}
+		return
 	}
 
-	// Use OpenGL's efficient buffer-to-buffer copy
-	gl.BindBuffer(gl.COPY_READ_BUFFER, src.BufferID)
-	gl.BindBuffer(gl.COPY_WRITE_BUFFER, dst.BufferID)
-	gl.CopyBufferSubData(gl.COPY_READ_BUFFER, gl.COPY_WRITE_BUFFER, 0, 0, byteSize)
-
-	// Unbind buffers
-	gl.BindBuffer(gl.COPY_READ_BUFFER, 0)
-	gl.BindBuffer(gl.COPY_WRITE_BUFFER, 0)
-
-	if glError := gl.GetError(); glError != gl.NO_ERROR {
-		return fmt.Errorf("OpenGL error during buffer copy: %d", glError)
+	if cfg.Headless {
+		if err := runHeadless(); err != nil {
+			fmt.Fprintf(os.Stderr, "Headless run failed: %v\n", err)
+			os.Exit(1)
+		}
+		return
 	}
 
-	return nil
-}
-
-func DestroyFFTPlan(plan *gpu.GPUFFTPlan) error {
-	// Clean up any allocated resources
-	return nil
-}
+	// Initialize window
+	rl.SetConfigFlags(windowFlags())
+	if err := glContext.OpenWindow(cfg.ScreenWidth, cfg.ScreenHeight, "Golang GR Simulation - (2+1)D Spacetime"); err != nil {
+		fmt.Fprintf(os.Stderr, "Failed to open window: %v\n", err)
+		os.Exit(1)
+	}
+	defer glContext.CloseWindow()
+	platformWindowOpened(cfg)
 
-func isPowerOfTwo(n int) bool {
-	return n > 0 && (n&(n-1)) == 0
-}
+	// Set up camera
+	camera := initialCamera()
 
-func compileNaiveDFTShader(width, height int, isForward bool) (*gpu.ComputeShader, error) {
-	// Fallback to O(N²) DFT implementation for non-power-of-2 sizes
-	direction := "1.0"
-	if !isForward {
-		direction = "-1.0"
+	// Create the simulation
+	simulation := NewSimulation()
+	defer func() { simulation.CleanupGPU() }() // Clean up GPU resources on exit; F8 replaces the simulation
+	if err := simulation.openBinaryLog(); err != nil {
+		fmt.Fprintf(os.Stderr, "Warning: %v\n", err)
 	}
+	defer func() { _ = simulation.closeBinaryLog() }()
 
-	shaderSource := fmt.Sprintf(`
-		#version 430
-		layout(local_size_x = 64) in;
-
-		layout(std430, binding = 0) buffer InputBuffer {
-			vec2 inputData[];
-		};
-		layout(std430, binding = 1) buffer OutputBuffer {
-			vec2 outputData[];
-		};
+	// Write a crash report before exiting if the main loop panics
+	defer crash.Recover(cfg.CrashReportDir, func() crash.State { return simulation.crashState() })
 
-		const float PI = 3.14159265359;
-		const float direction = %s;
-		const int WIDTH = %d;
-		const int HEIGHT = %d;
-		const int TOTAL_SIZE = WIDTH * HEIGHT;
+	rl.HideCursor()
+	rl.SetClipPlanes(cullNear, cullFar)
+	notifyConfigWarnings()
+	watcher := newConfigWatcher(os.Args[1:])
+	quality = newQualityState()
+	slowMotion = newSlowMotion()
+	scene = newScene()
+	explain = newExplainState()
+	if cfg.Explain {
+		explain.restart(simulation)
+	}
+	loop := renderer.NewRenderLoop()
+	loop.SetRenderStats(scene.Stats())
+	frameRate := newFrameRateState(loop)
+	frameRate.apply(quality.level)
+	sanity := newGuard()
+	gpuFallbackNotified := false
+	gpuFeaturesNotified := false
+	plots := newDiagnosticsPlots()
+	sound := newSoundState()
+	defer sound.close()
+	stereo := &stereoState{}
+	defer stereo.close()
+	if cfg.Sonify {
+		sound.setEnabled(true)
+	}
+
+	// Main game loop, paced by the render loop
+	var frameStart time.Time
+	var physicsTime, renderTime float64 // Seconds stepping and drawing this frame, for the render statistics
+	screenshot := false                 // F12 was pressed; taken once the frame is drawn
+	loop.SetBeginCallback(func() {
+		frameStart = time.Now()
 
-		vec2 complexMul(vec2 a, vec2 b) {
-			return vec2(a.x * b.x - a.y * b.y, a.x * b.y + a.y * b.x);
+		// Handle input
+		previousCamera := camera
+		processInput(&camera, simulation)
+		handleBookmarks(&camera, watcher)
+		interacting := camera != previousCamera
+		if rl.IsKeyPressed(rl.KeyF1) {
+			toggleExplain(simulation)
 		}
-
-		void main() {
-			uint index = gl_GlobalInvocationID.x;
-			if (index >= TOTAL_SIZE) return;
-
-			uint outputX = index %% WIDTH;
-			uint outputY = index / WIDTH;
-
-			vec2 sum = vec2(0.0, 0.0);
-
-			for (uint inputY = 0; inputY < HEIGHT; inputY++) {
-				for (uint inputX = 0; inputX < WIDTH; inputX++) {
-					float angle = direction * 2.0 * PI * (
-						float(outputX * inputX) / float(WIDTH) +
-						float(outputY * inputY) / float(HEIGHT)
-					);
-					vec2 twiddle = vec2(cos(angle), sin(angle));
-					vec2 inputSample = inputData[inputY * WIDTH + inputX];
-					sum += complexMul(inputSample, twiddle);
+		if rl.IsKeyPressed(rl.KeyN) && cfg.Explain {
+			explain.skip = true
+		}
+		if rl.IsKeyPressed(rl.KeyF2) {
+			cfg.ShowPlots = !cfg.ShowPlots
+		}
+		toggleLayers(scene)
+		if rl.IsKeyPressed(rl.KeyF6) {
+			cfg.ShowPhaseSpace = !cfg.ShowPhaseSpace
+		}
+		if rl.IsKeyPressed(rl.KeyF7) {
+			cfg.ShowProfiles = !cfg.ShowProfiles
+		}
+		if rl.IsKeyPressed(rl.KeyF10) {
+			cfg.ShowRenderStats = !cfg.ShowRenderStats
+		}
+		if rl.IsKeyPressed(rl.KeyF11) {
+			toggleGPUState(simulation)
+		}
+		if rl.IsKeyPressed(rl.KeyF12) {
+			screenshot = true
+		}
+		if rl.IsKeyPressed(rl.KeyF3) {
+			cfg.Stereo = !cfg.Stereo
+		}
+		if rl.IsKeyPressed(rl.KeyV) {
+			cfg.GridColoring = nextMode(config.GridColorings, cfg.GridColoring)
+			ui.Notify(renderer.NotificationInfo, "Grid colors: "+cfg.GridColoring)
+		}
+		if rl.IsKeyPressed(rl.KeyB) {
+			cfg.ParticleColoring = nextMode(config.ParticleColorings, cfg.ParticleColoring)
+			ui.Notify(renderer.NotificationInfo, "Particle colors: "+cfg.ParticleColoring)
+		}
+		if rl.IsKeyPressed(rl.KeyM) {
+			sound.setEnabled(!sound.enabled)
+		}
+		if rl.IsKeyPressed(rl.KeyR) {
+			if result, err := simulation.timeReversal(); err != nil {
+				ui.Notify(renderer.NotificationError, "Time reversal test failed: "+err.Error())
+			} else {
+				ui.Notify(renderer.NotificationInfo, fmt.Sprintf("Time reversal over %d steps: RMS error %.3g cells, max %.3g", result.Steps, result.RMSError, result.MaxError))
+			}
+		}
+		if rl.IsKeyPressed(rl.KeyF8) {
+			next, err := restartWithPreset(simulation)
+			if next == nil {
+				ui.Notify(renderer.NotificationError, "Preset failed: "+err.Error())
+			} else {
+				simulation = next
+				quality = newQualityState()
+				frameRate.apply(quality.level)
+				slowMotion = newSlowMotion()
+				sanity = newGuard()
+				plots = newDiagnosticsPlots()
+				explain.restart(simulation)
+				gpuFallbackNotified, gpuFeaturesNotified = false, false
+				if err != nil {
+					ui.Notify(renderer.NotificationWarning, "Binary log: "+err.Error())
 				}
+				p, _ := config.LookupPreset(cfg.Preset)
+				ui.Notify(renderer.NotificationInfo, "Preset: "+p.Name+" - "+p.Description)
+				notifyConfigWarnings()
+				watcher.rebase()
 			}
-
-			if (direction < 0.0) {
-				float normFactor = 1.0 / float(TOTAL_SIZE);
-				sum *= normFactor;
+		}
+		if rl.IsKeyPressed(rl.KeyF9) {
+			path := numberedMeshPath(simulation.StepCount)
+			if err := simulation.exportMesh(path); err != nil {
+				ui.Notify(renderer.NotificationError, "Mesh export failed: "+err.Error())
+			} else {
+				ui.Notify(renderer.NotificationInfo, "Mesh written to "+path)
 			}
-
-			outputData[index] = sum;
 		}
-	`, direction, width, height)
-
-	// Compile the compute shader
-	shaderID := gl.CreateShader(gl.COMPUTE_SHADER)
-	csources, free := gl.Strs(shaderSource + "\x00")
-	gl.ShaderSource(shaderID, 1, csources, nil)
-	free()
-	gl.CompileShader(shaderID)
-
-	// Check compilation status
-	var status int32
-	gl.GetShaderiv(shaderID, gl.COMPILE_STATUS, &status)
-	if status == gl.FALSE {
-		var logLength int32
-		gl.GetShaderiv(shaderID, gl.INFO_LOG_LENGTH, &logLength)
-
-		log := make([]byte, logLength+1)
-		gl.GetShaderInfoLog(shaderID, logLength, nil, &log[0])
-
-		gl.DeleteShader(shaderID)
-		return nil, fmt.Errorf("naive DFT compute shader compilation failed: %s", string(log))
-	}
+		if watcher.poll(frameStart) {
+			if r, err := watcher.reload(); err != nil {
+				ui.Notify(renderer.NotificationError, "Config not reloaded: "+err.Error())
+			} else {
+				applied, restart := reloadMessages(r)
+				if applied != "" {
+					frameRate.apply(quality.level)
+					ui.Notify(renderer.NotificationInfo, applied)
+					interacting = true
+				}
+				if restart != "" {
+					ui.Notify(renderer.NotificationWarning, restart)
+				}
+			}
+		}
+		if rl.IsKeyPressed(rl.KeyT) {
+			tagGroup(&camera, simulation, rl.IsKeyDown(rl.KeyLeftShift) || rl.IsKeyDown(rl.KeyRightShift))
+		}
+		if rl.IsKeyPressed(rl.KeyK) {
+			markSlowMotion(slowMotion, &camera, simulation, rl.IsKeyDown(rl.KeyLeftShift) || rl.IsKeyDown(rl.KeyRightShift))
+		}
+		frameRate.handleKeys()
+		handleBudgetKeys(len(simulation.Particles))
+		quality.recordInteraction(float64(rl.GetFrameTime()), interacting)
+	})
+	loop.SetUpdateCallback(func(dt float64) {
+		// Update simulation state if not paused or idling in the background.
+		// Explain mode takes one step per pass through its stages instead
+		stepTime := 0.0
+		stepDue := frameRate.stepping()
+		if cfg.Explain {
+			stepDue = explain.advance(dt, simulation, stepDue)
+		}
+		if stepDue {
+			// Use actual frame time for frame-rate independent simulation
+			deltaTime := float32(dt)
+			// Cap delta time to prevent simulation instability during lag spikes
+			if deltaTime > 0.05 {
+				deltaTime = 0.05 // Max 20 FPS equivalent
+			}
+			deltaTime *= float32(slowMotion.TimeScale())
+			slowMotion.Advance(dt)
+
+			stepStart := time.Now()
+			simulation.Step(deltaTime)
+			stepTime = time.Since(stepStart).Seconds()
+			checkSlowMotion(slowMotion, simulation)
+
+			// Stop at the first NaN/Inf or energy explosion so the state can be inspected
+			if sanity != nil {
+				sanity.Record(deltaTime)
+				if v := sanity.Check(simulation.StepCount, simulation.SimTime, simulation.Particles); v != nil {
+					pause = true
+					ui.Notify(renderer.NotificationError, simulation.reportViolation(v))
+				}
+			}
+			simulation.checkHealth(deltaTime)
+		}
 
-	// Create program and link
-	programID := gl.CreateProgram()
-	gl.AttachShader(programID, shaderID)
-	gl.LinkProgram(programID)
+		// Surface the first GPU fallback to the user
+		if useGPU && simulation.HasGPUErrorOccurred() && !gpuFallbackNotified {
+			ui.Notify(renderer.NotificationWarning, gpuFallbackMessage(simulation.lastGPUError))
+			gpuFallbackNotified = true
+		}
+		if simulation.gpu != nil && !gpuFeaturesNotified {
+			if message := gpuDegradedMessage(simulation.gpu.Caps); message != "" {
+				ui.Notify(renderer.NotificationInfo, message)
+			}
+			gpuFeaturesNotified = true
+		}
+		ui.UpdateNotifications(dt)
+		sound.update(simulation)
 
-	// Check linking status
-	gl.GetProgramiv(programID, gl.LINK_STATUS, &status)
-	if status == gl.FALSE {
-		var logLength int32
-		gl.GetProgramiv(programID, gl.INFO_LOG_LENGTH, &logLength)
+		quality.updatePhysics(simulation, dt, stepTime)
+		physicsTime = stepTime
 
-		log := make([]byte, logLength+1)
-		gl.GetProgramInfoLog(programID, logLength, nil, &log[0])
+		// Sample diagnostics even while hidden so the history is there when
+		// shown, unless the physics budget pauses them
+		if !physicsLevel().SkipDiagnostics() {
+			plots.Sample(rl.GetTime(), simulation)
+		}
+	})
+	loop.SetRenderCallback(func(dt float64) {
+		renderStart := time.Now()
+		ui.SetActualFPS(loop.GetActualFPS())
+		ui.SetFrameTime(dt)
+		draw(&camera, simulation, plots, stereo)
+		if cfg.ShowRenderStats {
+			drawRenderStats(scene, frameRate.budget())
+		}
+		if showGPUState {
+			drawGPUState(simulation)
+		}
+		if screenshot {
+			screenshot = false
+			path := screenshotPath(simulation.StepCount)
+			if err := takeScreenshot(path, simulation.metadata()); err != nil {
+				ui.Notify(renderer.NotificationError, "Screenshot failed: "+err.Error())
+			} else {
+				ui.Notify(renderer.NotificationInfo, "Screenshot saved to "+path)
+			}
+		}
+		renderTime = time.Since(renderStart).Seconds()
+	})
+	loop.SetEndCallback(func() {
+		// Time the work before EndDrawing waits for vsync
+		workTime := time.Since(frameStart).Seconds()
+		presentStart := time.Now()
+		rl.EndDrawing()
+		scene.Stats().EndFrame(loop.GetLastFrameTime(), physicsTime, renderTime+time.Since(presentStart).Seconds())
+
+		quality.update(loop.GetLastFrameTime(), workTime)
+		frameRate.apply(quality.level)
+		if rl.WindowShouldClose() {
+			loop.RequestClose()
+		}
+	})
+	loop.Run()
+}
 
-		gl.DeleteProgram(programID)
-		gl.DeleteShader(shaderID)
-		return nil, fmt.Errorf("naive DFT compute shader linking failed: %s", string(log))
+// nextMode returns the mode after mode in modes, which start with the
+// default that "" stands for
+func nextMode(modes []string, mode string) string {
+	for i, m := range modes {
+		if m == mode {
+			return modes[(i+1)%len(modes)]
+		}
 	}
-
-	// Clean up shader object (no longer needed after linking)
-	gl.DeleteShader(shaderID)
-
-	return &gpu.ComputeShader{ProgramID: programID}, nil
+	return modes[1%len(modes)]
 }
 
-func compileFFTComputeShader(width, height int, isForward bool) (*gpu.ComputeShader, error) {
-	// O(N log N) Cooley-Tukey FFT implementation for GPU
-	// Uses separable 2D FFT: row FFTs then column FFTs
-
-	// Check if dimensions are power of 2 (required for Cooley-Tukey)
-	if !isPowerOfTwo(width) || !isPowerOfTwo(height) {
-		return compileNaiveDFTShader(width, height, isForward)
+// flowColors returns the color of each grid node for a flow grid coloring
+// mode, or nil for the potential coloring. Signed maps run from the low end
+// of the particle ramp through the grid color to the hot grid color; shear
+// runs from the grid color to the hot color. Both are scaled to the largest
+// magnitude on the grid
+func flowColors(sim *Simulation, frame *simulation.Frame, scheme renderer.ColorScheme) [][]rl.Color {
+	field := func() physics.FlowField { return sim.flowFieldAt(frame.Step, frame.ParticlePointers()) }
+	var values physics.Grid
+	signed := true
+	switch cfg.GridColoring {
+	case config.GridColoringDivergence:
+		values = field().Divergence
+	case config.GridColoringVorticity:
+		values = field().Vorticity
+	case config.GridColoringShear:
+		values, signed = field().Shear, false
+	default:
+		return nil
+	}
+
+	maxAbs := 0.0
+	for i := range values {
+		for _, v := range values[i] {
+			maxAbs = math.Max(maxAbs, math.Abs(v))
+		}
 	}
-
-	shaderSource := fmt.Sprintf(`
-		#version 430
-		layout(local_size_x = 32, local_size_y = 1) in;
-
-		layout(std430, binding = 0) buffer InputBuffer {
-			vec2 inputData[];
-		};
-		layout(std430, binding = 1) buffer OutputBuffer {
-			vec2 outputData[];
-		};
-
-		uniform int stage;      // Current FFT stage (0 to log2(size)-1)
-		uniform int direction_flag;  // 1 for forward, -1 for inverse
-		uniform int is_column_pass;  // 0 for row pass, 1 for column pass
-
-		const float PI = 3.14159265359;
-		const int WIDTH = %d;
-		const int HEIGHT = %d;
-		const int TOTAL_SIZE = WIDTH * HEIGHT;
-
-		vec2 complexMul(vec2 a, vec2 b) {
-			return vec2(a.x * b.x - a.y * b.y, a.x * b.y + a.y * b.x);
+	colors := make([][]rl.Color, len(values))
+	for i := range values {
+		colors[i] = make([]rl.Color, len(values[i]))
+		for j, v := range values[i] {
+			t := 0.0
+			if maxAbs > 0 {
+				t = v / maxAbs
+			}
+			color := renderer.LerpColor(scheme.Grid, scheme.GridHot, t)
+			if signed && t < 0 {
+				color = renderer.LerpColor(scheme.Grid, scheme.ParticleLight, -t)
+			}
+			colors[i][j] = raylibColor(color)
 		}
+	}
+	return colors
+}
 
-		// Bit reversal for FFT
-		uint bitReverse(uint x, uint bits) {
-			uint result = 0;
-			for (uint i = 0; i < bits; i++) {
-				if ((x & (1u << i)) != 0) {
-					result |= 1u << (bits - 1 - i);
-				}
+// particleColors returns the color of each particle for the configured coloring mode
+func particleColors(sim *Simulation, frame *simulation.Frame, scheme renderer.ColorScheme) []rl.Color {
+	colors := make([]rl.Color, len(frame.Particles))
+	switch cfg.ParticleColoring {
+	case config.ParticleColoringBinding:
+		for i := range frame.Particles {
+			color := scheme.ParticleUnbound
+			if physics.ComputeParticleEnergy(&frame.Particles[i], frame.PotentialGrid, sim.mesh.Dx(), physics.Vec3{}).Bound() {
+				color = scheme.ParticleBound
 			}
-			return result;
-		}
-
-		void main() {
-			uint index = gl_GlobalInvocationID.x;
-
-			if (is_column_pass == 0) {
-				// Row pass: process each row independently
-				uint row = index / WIDTH;
-				uint col = index %% WIDTH;
-
-				if (row >= HEIGHT || col >= WIDTH) return;
-
-				if (stage == -1) {
-					// Bit reversal stage for rows
-					uint bits = uint(log2(float(WIDTH)));
-					uint reversedCol = bitReverse(col, bits);
-					uint srcIndex = row * WIDTH + col;
-					uint dstIndex = row * WIDTH + reversedCol;
-					outputData[dstIndex] = inputData[srcIndex];
-				} else {
-					// Butterfly operations for current stage
-					uint stepSize = 1u << (stage + 1);
-					uint halfStep = stepSize >> 1;
-					uint group = col / stepSize;
-					uint pos = col %% stepSize;
-
-					if (pos < halfStep) {
-						uint partner = index + halfStep;
-						if (partner < TOTAL_SIZE) {
-							float angle = float(direction_flag) * (-2.0 * PI * float(pos)) / float(stepSize);
-							vec2 twiddle = vec2(cos(angle), sin(angle));
-
-							vec2 a = inputData[index];
-							vec2 b = complexMul(inputData[partner], twiddle);
-
-							outputData[index] = a + b;
-							outputData[partner] = a - b;
-						}
-					}
-				}
+			colors[i] = raylibColor(color)
+		}
+	case config.ParticleColoringDensity:
+		densities := localDensities(frame.ParticlePointers(), frame.MassDensityGrid, sim.mesh.Dx())
+		ramp := renderer.NewLogRamp(densities)
+		for i, density := range densities {
+			colors[i] = raylibColor(scheme.Ramp(ramp.At(density)))
+		}
+	case config.ParticleColoringLyapunov:
+		// Tracked particles from the light end of the ramp at zero to the
+		// heavy end at the largest exponent; the rest keep the particle color
+		var exponents []float64
+		if sim.chaos != nil {
+			exponents = sim.chaos.Exponents()
+		}
+		_, largest := physics.LyapunovSummary(exponents)
+		for i := range colors {
+			if i >= len(exponents) {
+				colors[i] = raylibColor(scheme.Particle)
+				continue
+			}
+			t := 0.0
+			if largest > 0 {
+				t = exponents[i] / largest
+			}
+			colors[i] = raylibColor(scheme.Ramp(t))
+		}
+	case config.ParticleColoringGroup:
+		// Ungrouped particles keep the particle color
+		for i, g := range physics.GroupIndex(sim.groups, len(colors)) {
+			if g < 0 {
+				colors[i] = raylibColor(scheme.Particle)
 			} else {
-				// Column pass: process each column independently
-				uint col = index / HEIGHT;
-				uint row = index %% HEIGHT;
-
-				if (col >= WIDTH || row >= HEIGHT) return;
-
-				if (stage == -1) {
-					// Bit reversal stage for columns
-					uint bits = uint(log2(float(HEIGHT)));
-					uint reversedRow = bitReverse(row, bits);
-					uint srcIndex = row * WIDTH + col;
-					uint dstIndex = reversedRow * WIDTH + col;
-					outputData[dstIndex] = inputData[srcIndex];
-				} else {
-					// Butterfly operations for current stage
-					uint stepSize = 1u << (stage + 1);
-					uint halfStep = stepSize >> 1;
-					uint group = row / stepSize;
-					uint pos = row %% stepSize;
-
-					if (pos < halfStep) {
-						uint partnerRow = row + halfStep;
-						if (partnerRow < HEIGHT) {
-							uint currentIndex = row * WIDTH + col;
-							uint partnerIndex = partnerRow * WIDTH + col;
-
-							float angle = float(direction_flag) * (-2.0 * PI * float(pos)) / float(stepSize);
-							vec2 twiddle = vec2(cos(angle), sin(angle));
-
-							vec2 a = inputData[currentIndex];
-							vec2 b = complexMul(inputData[partnerIndex], twiddle);
-
-							outputData[currentIndex] = a + b;
-							outputData[partnerIndex] = a - b;
-						}
-					}
-				}
+				colors[i] = raylibColor(scheme.Group(g))
 			}
 		}
-	`, width, height)
-
-	// Compile the compute shader
-	shaderID := gl.CreateShader(gl.COMPUTE_SHADER)
-	csources, free := gl.Strs(shaderSource + "\x00")
-	gl.ShaderSource(shaderID, 1, csources, nil)
-	free()
-	gl.CompileShader(shaderID)
-
-	// Check compilation status
-	var status int32
-	gl.GetShaderiv(shaderID, gl.COMPILE_STATUS, &status)
-	if status == gl.FALSE {
-		var logLength int32
-		gl.GetShaderiv(shaderID, gl.INFO_LOG_LENGTH, &logLength)
-
-		log := make([]byte, logLength+1)
-		gl.GetShaderInfoLog(shaderID, logLength, nil, &log[0])
-
-		gl.DeleteShader(shaderID)
-		return nil, fmt.Errorf("FFT compute shader compilation failed: %s", string(log))
-	}
-
-	// Create program and link
-	programID := gl.CreateProgram()
-	gl.AttachShader(programID, shaderID)
-	gl.LinkProgram(programID)
-
-	// Check linking status
-	gl.GetProgramiv(programID, gl.LINK_STATUS, &status)
-	if status == gl.FALSE {
-		var logLength int32
-		gl.GetProgramiv(programID, gl.INFO_LOG_LENGTH, &logLength)
-
-		log := make([]byte, logLength+1)
-		gl.GetProgramInfoLog(programID, logLength, nil, &log[0])
-
-		gl.DeleteProgram(programID)
-		gl.DeleteShader(shaderID)
-		return nil, fmt.Errorf("FFT compute shader linking failed: %s", string(log))
+	default:
+		for i := range colors {
+			colors[i] = raylibColor(scheme.Particle)
+		}
 	}
+	return colors
+}
 
-	// Clean up shader (program retains copy)
-	gl.DeleteShader(shaderID)
-
-	return &gpu.ComputeShader{ProgramID: programID}, nil
+// draw renders one frame, side by side per eye in stereo mode; the caller
+// ends it with rl.EndDrawing
+func draw(camera *rl.Camera, sim *Simulation, plots *diagnosticsPlots, stereo *stereoState) {
+	sim.ReadFrame(func(frame *simulation.Frame) { drawFrame(camera, sim, frame, plots, stereo) })
 }
 
-func SolvePoissonGPU(g *gpu.GPU, densityGrid [][]float64, gravitationalConstant float64) ([][]float64, error) {
-	if !g.Initialized {
-		return nil, fmt.Errorf("GPU context not initialized")
+// drawFrame renders the published frame
+func drawFrame(camera *rl.Camera, sim *Simulation, frame *simulation.Frame, plots *diagnosticsPlots, stereo *stereoState) {
+	scheme := ui.GetColorScheme()
+	layers := &sceneFrame{
+		sim:        sim,
+		frame:      frame,
+		camera:     *camera,
+		scheme:     scheme,
+		width:      rl.GetScreenWidth(),
+		height:     rl.GetScreenHeight(),
+		budget:     cfg.RenderBudget,
+		colors:     particleColors(sim, frame, scheme),
+		gridColors: flowColors(sim, frame, scheme),
+	}
+	if cfg.Stereo {
+		layers.width /= 2
+		layers.padding = eyeOffset(cfg.EyeSeparation)
+	}
+	drawWorld := func() { scene.DrawWorld(layers) }
+	if cfg.Stereo {
+		stereo.render(*camera, cfg.EyeSeparation, drawWorld)
 	}
 
-	// Match CPU coordinate system: densityGrid[i][j] where i=cfg.SimulationWidth, j=cfg.SimulationDepth
-	width := len(densityGrid)     // cfg.SimulationWidth (first dimension)
-	height := len(densityGrid[0]) // cfg.SimulationDepth (second dimension)
-	totalSize := width * height
-
-	// Step 1: Upload density grid to GPU as complex data (real part = density, imag = 0)
-	inputBuffer, err := CreateComplexGPUBuffer(g, totalSize)
-	if err != nil {
-		return nil, fmt.Errorf("failed to create input buffer: %v", err)
-	}
+	rl.BeginDrawing()
+	rl.ClearBackground(rl.Black)
 
-	// Convert density to complex128 and upload (match CPU coordinate system)
-	complexData := make([]complex128, totalSize)
-	idx := 0
-	for i := 0; i < width; i++ {
-		for j := 0; j < height; j++ {
-			complexData[idx] = complex(densityGrid[i][j], 0) // Use i,j to match CPU reference
-			idx++
+	if cfg.Stereo {
+		stereo.draw()
+	} else {
+		rl.BeginMode3D(*camera)
+		drawWorld()
+		rl.EndMode3D()
+		if scene.Shown(config.LayerMarkers) {
+			drawMarkerLabels(camera, sim.Markers, scheme.Marker)
 		}
 	}
+	scene.DrawOverlay(layers)
 
-	err = UploadComplexData(inputBuffer, complexData)
-	if err != nil {
-		return nil, fmt.Errorf("failed to upload density data: %v", err)
+	// Draw UI
+	x, y := ui.GetTitlePosition()
+	drawHUDText(ui.GetTitle(), x, y, ui.GetTitleColor())
+	x, y = ui.GetParticleCountPosition()
+	count := fmt.Sprintf("Particles: %d", len(frame.Particles))
+	if stats, ok := sim.tidalStats(frame.ParticlePointers()); ok {
+		count += fmt.Sprintf(" (mass lost %.0f%%, tail %.1f)", 100*stats.MassLoss, stats.TailLength)
 	}
-
-	// Step 2: Forward FFT
-	fftOutputBuffer, err := CreateComplexGPUBuffer(g, totalSize)
-	if err != nil {
-		return nil, fmt.Errorf("failed to create FFT output buffer: %v", err)
+	if sim.chaos != nil {
+		mean, largest := physics.LyapunovSummary(sim.chaos.Exponents())
+		count += fmt.Sprintf(" (Lyapunov mean %.3g, max %.3g)", mean, largest)
 	}
-
-	// Step 2: Forward FFT (use cached plan if available)
-	fftKey := fmt.Sprintf("%dx%d_fwd", width, height)
-	fftPlan, exists := g.FftPlanCache[fftKey]
-	if !exists {
-		var err error
-		fftPlan, err = CreateGPUFFTPlan2D(g, width, height, true) // forward FFT
-		if err != nil {
-			return nil, fmt.Errorf("failed to create FFT plan: %v", err)
-		}
-		g.FftPlanCache[fftKey] = fftPlan
+	if sim.binaries != nil {
+		count += fmt.Sprintf(" (binaries %d)", len(sim.binaries.Active()))
 	}
-
-	err = ExecuteFFT(fftPlan, inputBuffer, fftOutputBuffer)
-	if err != nil {
-		return nil, fmt.Errorf("failed to execute forward FFT: %v", err)
+	if sim.sheet != nil {
+		count += fmt.Sprintf(" (sheet folded %.0f%%)", 100*physics.FoldedFraction(sheetStretch(sim.sheet, frame.Particles)))
 	}
-
-	// Step 3: Apply Green's function in Fourier space
-	err = applyGreensFunction(g, fftOutputBuffer, width, height, gravitationalConstant)
-	if err != nil {
-		return nil, fmt.Errorf("failed to apply Green's function: %v", err)
+	if slowMotion != nil && slowMotion.Active() {
+		count += fmt.Sprintf(" (slow motion x%.2f)", slowMotion.TimeScale())
 	}
+	drawHUDText(count, x, y, ui.GetDefaultTextColor())
 
-	// Step 4: Inverse FFT (use cached plan if available)
-	ifftKey := fmt.Sprintf("%dx%d_inv", width, height)
-	ifftPlan, exists := g.FftPlanCache[ifftKey]
-	if !exists {
-		var err error
-		ifftPlan, err = CreateGPUFFTPlan2D(g, width, height, false) // inverse FFT
-		if err != nil {
-			return nil, fmt.Errorf("failed to create IFFT plan: %v", err)
+	// GPU/CPU status indicator with GPU error status
+	x, y = ui.GetModePosition()
+	if useGPU && computeMode == config.ComputeAuto && !cfg.DirectSolver() {
+		mode := renderer.ModeCPU
+		if sim.compute.GetProcessor().GetType() == gpu.ProcessorTypeGPU {
+			mode = renderer.ModeGPU
 		}
-		g.FftPlanCache[ifftKey] = ifftPlan
+		drawHUDText("Mode: Auto, "+sim.compute.AutoReason(), x, y, ui.GetModeColor(mode, sim.HasGPUErrorOccurred()))
+	} else if useGPU {
+		if sim.HasGPUErrorOccurred() {
+			drawHUDText("Mode: GPU (Fallback to CPU)", x, y, ui.GetModeColor(renderer.ModeGPU, true))
+		} else {
+			drawHUDText(gpuModeLabel(sim), x, y, ui.GetModeColor(renderer.ModeGPU, false))
+		}
+	} else {
+		drawHUDText("Mode: CPU Only", x, y, ui.GetModeColor(renderer.ModeCPU, false))
 	}
 
-	finalBuffer, err := CreateComplexGPUBuffer(g, totalSize)
-	if err != nil {
-		return nil, fmt.Errorf("failed to create final buffer: %v", err)
+	x, y = ui.GetLinePosition(3)
+	switch {
+	case cfg.Solver == config.SolverDirectGPU && sim.fallbackToCPU:
+		drawHUDText(fmt.Sprintf("Solver: Direct N-body, GPU fell back to CPU (softening %.2g)", cfg.Softening), x, y, scheme.Warning)
+	case cfg.Solver == config.SolverDirectGPU:
+		drawHUDText(fmt.Sprintf("Solver: Direct N-body on GPU (softening %.2g)", cfg.Softening), x, y, scheme.Solver)
+	case cfg.Solver == config.SolverDirect:
+		drawHUDText(fmt.Sprintf("Solver: Direct N-body (softening %.2g)", cfg.Softening), x, y, scheme.Solver)
 	}
 
-	err = ExecuteFFT(ifftPlan, fftOutputBuffer, finalBuffer)
-	if err != nil {
-		return nil, fmt.Errorf("failed to execute inverse FFT: %v", err)
+	controls := ui.GetControlInstructions()
+	for i, line := range controls {
+		x, y = ui.GetControlPosition(i)
+		drawHUDText(line, x, y, ui.GetDefaultTextColor())
 	}
-
-	// Step 5: Download result and extract real part
-	resultData, err := DownloadComplexData(finalBuffer, totalSize)
-	if err != nil {
-		return nil, fmt.Errorf("failed to download result: %v", err)
+	if label := quality.label(); label != "" {
+		x, y = ui.GetControlPosition(len(controls))
+		drawHUDText(label, x, y, scheme.Warning)
 	}
-
-	// Apply inverse FFT normalization to match CPU go-dsp library behavior
-	// The CPU library auto-normalizes, but GPU IFFT may not
-	normalizationFactor := 1.0 / float64(totalSize)
-	for i := range resultData {
-		resultData[i] *= complex(normalizationFactor, 0)
+	if report := sim.healthReport(); report != nil {
+		x, y = ui.GetControlPosition(len(controls) + 1)
+		drawHUDText(healthLabel(report), x, y, healthColor(report, scheme))
 	}
-
-	// Convert back to 2D real grid (match CPU coordinate system)
-	potentialGrid := make([][]float64, width) // width rows (cfg.SimulationWidth)
-	for i := 0; i < width; i++ {
-		potentialGrid[i] = make([]float64, height) // height columns (cfg.SimulationDepth)
+	if scene.Shown(config.LayerParticles) {
+		drawBudgetSlider(layers.drawn, len(frame.Particles), scheme)
 	}
-
-	idx = 0
-	for i := 0; i < width; i++ {
-		for j := 0; j < height; j++ {
-			potentialGrid[i][j] = real(resultData[idx]) // Use i,j to match CPU coordinate system
-			idx++
-		}
+	if len(sim.groups) > 0 {
+		plots.groups.Draw(4+len(controls)+2, scheme) // Below the controls, the quality label and the health line
 	}
 
-	return potentialGrid, nil
-}
-
-// applyGreensFunction applies Green's function kernel in Fourier space
-func applyGreensFunction(g *gpu.GPU, buffer *gpu.ComplexGPUBuffer, width, height int, gravitationalConstant float64) error {
-	// Create compute shader for Green's function
-	shaderSource := fmt.Sprintf(`
-		#version 430
-		layout(local_size_x = 64) in;
-
-		layout(std430, binding = 0) buffer FourierBuffer {
-			vec2 fourierData[];
-		};
-
-		uniform int uWidth;
-		uniform int uHeight;
-		uniform float uGConstant;
-		uniform float uKxFactor;
-		uniform float uKzFactor;
-
-		void main() {
-			uint index = gl_GlobalInvocationID.x;
-			uint totalSize = uint(uWidth * uHeight);
-
-			if (index >= totalSize) return;
-
-			// Convert 1D index to 2D coordinates
-			// Data is uploaded as densityGrid[i][j] with j inner loop
-			// So u (width/i) changes slower, v (height/j) changes faster
-			uint u = index / uint(uHeight);
-			uint v = index %% uint(uHeight);
-
-			// Calculate wave vector k
-			float kx = float(u);
-			if (u > uint(uWidth)/2u) {
-				kx = float(int(u) - uWidth);
-			}
-
-			float kz = float(v);
-			if (v > uint(uHeight)/2u) {
-				kz = float(int(v) - uHeight);
-			}
-
-			float kSquared = (kx * uKxFactor) * (kx * uKxFactor) +
-							 (kz * uKzFactor) * (kz * uKzFactor);
-
-			if (kSquared == 0.0) {
-				// Ignore DC component
-				fourierData[index] = vec2(0.0, 0.0);
-			} else {
-				// Apply Green's function: G(k) = -4πG / |k|²
-				float scalingFactor = -4.0 * 3.14159265359 * uGConstant / kSquared;
-				fourierData[index] *= scalingFactor;
-			}
-		}
-	`)
+	// Display both target and actual FPS
+	x, y = ui.GetFPSPosition()
+	drawHUDText(ui.GetTargetFPSText(), x, y, ui.GetDefaultTextColor())
+	x, y = ui.GetActualFPSPosition()
+	drawHUDText(ui.GetActualFPSText(), x, y, ui.GetDefaultTextColor())
+	x, y = ui.GetFrameTimePosition()
+	drawHUDText(ui.GetFrameTimeText(), x, y, ui.GetDefaultTextColor())
 
-	// Use cached shader if available
-	shaderKey := "greens_function_shader"
-	shader, exists := g.ShaderCache[shaderKey]
-	if !exists {
-		var err error
-		shader, err = CompileComputeShader(g, shaderSource)
-		if err != nil {
-			return fmt.Errorf("failed to compile Green's function shader: %v", err)
-		}
-		g.ShaderCache[shaderKey] = shader
+	if pause {
+		x, y = ui.GetPausePosition()
+		drawHUDText(ui.GetPauseText(), x, y, ui.GetPauseColor())
 	}
 
-	// Bind buffer and set uniforms
-	gl.UseProgram(shader.ProgramID)
-
-	// Bind buffer to shader storage buffer object
-	gl.BindBufferBase(gl.SHADER_STORAGE_BUFFER, 0, buffer.BufferID)
-
-	// Set uniforms
-	kxFactor := 2.0 * math.Pi / float64(width)
-	kzFactor := 2.0 * math.Pi / float64(height)
-
-	widthLoc := gl.GetUniformLocation(shader.ProgramID, gl.Str("uWidth\x00"))
-	heightLoc := gl.GetUniformLocation(shader.ProgramID, gl.Str("uHeight\x00"))
-	gravitationalConstantLoc := gl.GetUniformLocation(shader.ProgramID, gl.Str("uGConstant\x00"))
-	kxFactorLoc := gl.GetUniformLocation(shader.ProgramID, gl.Str("uKxFactor\x00"))
-	kzFactorLoc := gl.GetUniformLocation(shader.ProgramID, gl.Str("uKzFactor\x00"))
-
-	gl.Uniform1i(widthLoc, int32(width))
-	gl.Uniform1i(heightLoc, int32(height))
-	gl.Uniform1f(gravitationalConstantLoc, float32(gravitationalConstant))
-	gl.Uniform1f(kxFactorLoc, float32(kxFactor))
-	gl.Uniform1f(kzFactorLoc, float32(kzFactor))
-
-	// Dispatch compute shader
-	totalSize := width * height
-	workGroups := (totalSize + 63) / 64 // Round up to handle all elements
-	gl.DispatchCompute(uint32(workGroups), 1, 1)
-
-	// Memory barrier to ensure shader writes are visible
-	gl.MemoryBarrier(gl.SHADER_STORAGE_BARRIER_BIT)
-
-	// Check for OpenGL errors
-	if glError := gl.GetError(); glError != gl.NO_ERROR {
-		return fmt.Errorf("OpenGL error in Green's function shader: %d", glError)
+	if cfg.ShowPlots {
+		plots.Draw()
 	}
-
-	return nil
-}
-
-func CleanupGPU(g *gpu.GPU) error {
-	if g.Initialized {
-		// Clean up cached FFT plans
-		for _, plan := range g.FftPlanCache {
-			_ = DestroyFFTPlan(plan)
-		}
-		g.FftPlanCache = nil
-
-		// Clean up cached shaders
-		for _, shader := range g.ShaderCache {
-			_ = DeleteComputeShader(shader)
-		}
-		g.ShaderCache = nil
-
-		// Clean up headless raylib context if we created one
-		if g.NeedsCleanup {
-			rl.CloseWindow()
-		}
-
-		g.Initialized = false
+	if cfg.ShowProfiles {
+		plots.profiles.Draw()
 	}
-	return nil
-}
-
-// UpdateGPU performs a simulation timestep using GPU acceleration for Poisson solver
-func (s *Simulation) UpdateGPU(deltaTime float32) {
-	// Use a hybrid approach: physics engine for particle updates, GPU for Poisson solver
-
-	// 1. Kick (half step velocity update)
-	forceField := &physics.ForceField{
-		AccelFieldX: s.AccelFieldX,
-		AccelFieldZ: s.AccelFieldZ,
-		Width:       cfg.SimulationWidth,
-		Height:      cfg.SimulationDepth,
+	if cfg.ShowPhaseSpace {
+		drawPhaseSpace(frame, layers.colors)
 	}
-	forceCorrectionFactor := float32(0.5)
-	physics.UpdateVelocities(s.Particles, forceField, deltaTime*0.5, forceCorrectionFactor)
-
-	// 2. Drift (full step position update)
-	physics.UpdatePositions(s.Particles, deltaTime, cfg.SimulationWidth, cfg.SimulationDepth)
-
-	// 3. Calculate new accelerations using GPU-accelerated Poisson solver
-	s.calculateAccelerationFieldGPU()
-
-	// 4. Kick (half step velocity update)
-	forceField.AccelFieldX = s.AccelFieldX
-	forceField.AccelFieldZ = s.AccelFieldZ
-	physics.UpdateVelocities(s.Particles, forceField, deltaTime*0.5, forceCorrectionFactor)
-}
-
-// calculateAccelerationFieldGPU performs PM method steps with GPU-accelerated potential calculation
-func (s *Simulation) calculateAccelerationFieldGPU() {
-	// Step 1: Deposit mass onto the grid (Cloud-in-Cell) - same as CPU
-	s.MassDensityGrid = physics.DepositMassToGrid(s.Particles, cfg.SimulationWidth, cfg.SimulationDepth)
-
-	// Step 2: Solve for potential Φ using GPU
-	s.solvePotentialGPU()
+	if cfg.Explain {
+		explain.draw(frame, sim.mesh.Dx(), scheme)
+	}
+	drawOverlay(overlayLines(frame.Step, frame.SimTime, cfg.Summary()), rl.GetScreenWidth(), rl.GetScreenHeight(), ui.GetFontSize(), ui.GetDefaultTextColor())
 
-	// Step 3: Calculate acceleration (a = -∇Φ) from the potential field
-	forceField := physics.CalculateGradient(s.PotentialGrid, cfg.SimulationWidth, cfg.SimulationDepth)
-	s.AccelFieldX = forceField.AccelFieldX
-	s.AccelFieldZ = forceField.AccelFieldZ
+	drawNotifications()
 }
 
-// solvePotentialGPU solves ∇²Φ = 4πGρ using GPU-accelerated FFT
-func (s *Simulation) solvePotentialGPU() {
-	// Check for forced initialization failure (testing)
-	if s.forceGPUInitFailure {
-		s.gpuErrorOccurred = true
-		s.fallbackToCPU = true
-		s.solvePotential()
-		return
-	}
-
-	// Initialize GPU context if needed
-	if s.gpu == nil {
-		GPU, err := InitializeGPU()
-		if err != nil {
-			// Fallback to CPU if GPU unavailable
-			s.gpuErrorOccurred = true
-			s.fallbackToCPU = true
-			s.solvePotential()
-			return
+// drawMarkerLabels labels the scenario markers in screen space, just above
+// their posts, skipping markers behind the camera
+func drawMarkerLabels(camera *rl.Camera, markers []scenario.Marker, color renderer.UIColor) {
+	forward := rl.Vector3Subtract(camera.Target, camera.Position)
+	for _, m := range markers {
+		p := toRaylib(m.Position)
+		p.Y += 2
+		if rl.Vector3DotProduct(rl.Vector3Subtract(p, camera.Position), forward) <= 0 {
+			continue
 		}
-		s.gpu = GPU
+		screen := rl.GetWorldToScreen(p, *camera)
+		drawHUDText(m.Label, int(screen.X), int(screen.Y)-ui.GetFontSize(), color)
 	}
+}
 
-	// Check for forced computation failure (testing)
-	if s.forceGPUCompFailure {
-		s.gpuErrorOccurred = true
-		s.fallbackToCPU = true
-		s.solvePotential()
-		return
+// drawHUDText draws a line of HUD text in the UI font size, over the
+// palette's backdrop if it has one
+func drawHUDText(text string, x, y int, color renderer.UIColor) {
+	size := int32(ui.GetFontSize())
+	if backdrop := ui.GetColorScheme().HUDBackdrop; backdrop.A != 0 {
+		pad := size / 5
+		rl.DrawRectangle(int32(x)-pad, int32(y)-pad, rl.MeasureText(text, size)+2*pad, size+2*pad, raylibColor(backdrop))
 	}
+	rl.DrawText(text, int32(x), int32(y), size, raylibColor(color))
+}
 
-	// Use GPU Poisson solver
-	result, err := SolvePoissonGPU(s.gpu, s.MassDensityGrid, cfg.GravitationalConstant)
+// newUIRenderer creates the HUD renderer with the configured palette and
+// scale; an unknown palette falls back to the default
+func newUIRenderer(cfg *config.Config) *renderer.UIRenderer {
+	r := renderer.NewUIRenderer(cfg.ScreenWidth, cfg.ScreenHeight)
+	palette, err := renderer.ParsePalette(cfg.Palette)
 	if err != nil {
-		// Fallback to CPU if GPU computation fails
-		s.gpuErrorOccurred = true
-		s.fallbackToCPU = true
-		s.solvePotential()
-		return
+		fmt.Fprintf(os.Stderr, "Warning: %v; using the default palette\n", err)
 	}
-
-	// Copy result back to PotentialGrid
-	for i := range s.PotentialGrid {
-		for j := range s.PotentialGrid[i] {
-			s.PotentialGrid[i][j] = result[i][j]
-		}
+	r.SetPalette(palette)
+	if cfg.UIScale > 0 {
+		r.SetUIScale(cfg.UIScale)
 	}
+	return r
 }
 
-func processInput(camera *rl.Camera3D) {
-	// Process all input through the controller
-	input.ProcessAllInput(camera, &pause, &useGPU, &yaw, &pitch, cfg.MoveSpeed, mouseSensitivity, int(cfg.ScreenWidth), int(cfg.ScreenHeight))
+// raylibColor converts a UI color to a raylib color
+func raylibColor(c renderer.UIColor) rl.Color {
+	return rl.NewColor(c.R, c.G, c.B, c.A)
 }
 
-func main() {
-	// Initialize configuration
-	cfg = config.DefaultConfig()
-	pause = cfg.StartPaused
-	useGPU = cfg.UseGPU
-	mouseSensitivity = cfg.MouseSensitivity
-	yaw = cfg.InitialYaw
-	pitch = cfg.InitialPitch
-
-	// Initialize window
-	rl.InitWindow(int32(cfg.ScreenWidth), int32(cfg.ScreenHeight), "Golang GR Simulation - (2+1)D Spacetime")
-	defer rl.CloseWindow()
-
-	// Set up camera
-	camera := rl.Camera3D{
-		Position:   rl.NewVector3(50.0, 50.0, 50.0),
-		Target:     rl.NewVector3(0.0, 0.0, 0.0),
-		Up:         rl.NewVector3(0.0, 1.0, 0.0),
-		Fovy:       65.0,
-		Projection: rl.CameraPerspective,
+// drawNotifications draws the transient notification toasts, newest at the bottom
+func drawNotifications() {
+	notifications := ui.GetNotifications()
+	for i := range notifications {
+		n := notifications[len(notifications)-1-i]
+		x, y := ui.GetNotificationPosition(i)
+		c := ui.GetNotificationColor(n.Level)
+		c.A = n.Alpha()
+		drawHUDText(n.Message, x, y, c)
 	}
+}
 
-	// Create the simulation
-	simulation := NewSimulation()
-	defer simulation.CleanupGPU() // Clean up GPU resources on exit
-
-	rl.HideCursor()
-	rl.SetClipPlanes(0.1, 10000.0)
-	rl.SetTargetFPS(60)
-	// Main game loop
-	for !rl.WindowShouldClose() {
-		// Handle input
-		processInput(&camera)
-
-		// Update simulation state if not paused
-		if !pause {
-			// Use actual frame time for frame-rate independent simulation
-			deltaTime := rl.GetFrameTime()
-			// Cap delta time to prevent simulation instability during lag spikes
-			if deltaTime > 0.05 {
-				deltaTime = 0.05 // Max 20 FPS equivalent
-			}
-
-			start := time.Now()
-			if useGPU {
-				simulation.UpdateGPU(deltaTime) // Use GPU acceleration
-			} else {
-				simulation.Update(deltaTime)
-			}
-			_ = time.Since(start) // Measure simulation time (for future performance monitoring)
-		}
-		// Draw the scene
-		draw(&camera, simulation)
+// gpuModeLabel names the active GPU backend for the status line
+func gpuModeLabel(sim *Simulation) string {
+	if sim.cuda != nil {
+		return fmt.Sprintf("Mode: GPU Accelerated (CUDA, %s)", sim.cuda.Name())
 	}
+	return "Mode: GPU Accelerated"
 }
 
-func draw(camera *rl.Camera, sim *Simulation) {
-	rl.BeginDrawing()
-	rl.ClearBackground(rl.Black)
-
-	rl.BeginMode3D(*camera)
-
-	// Draw the deformed spacetime grid
-	drawDeformedGrid(sim)
+// gpuFallbackMessage formats the notification text shown when the GPU path
+// fails, naming the kind of failure so the user knows whether to check the
+// driver, the shaders or the grid size
+func gpuFallbackMessage(err error) string {
+	switch {
+	case err == nil:
+		return "GPU unavailable, falling back to CPU"
+	case errors.Is(err, gpu.ErrNoGLContext):
+		return "No OpenGL 4.3 context, running on CPU"
+	case errors.Is(err, gpu.ErrUnsupportedGL):
+		return fmt.Sprintf("Driver too old for GPU compute, running on CPU: %v", err)
+	case errors.Is(err, gpu.ErrShaderCompile):
+		return fmt.Sprintf("GPU shader failed to build, falling back to CPU: %v", err)
+	case errors.Is(err, gpu.ErrBufferTooSmall), errors.Is(err, physics.ErrInvalidGrid):
+		return fmt.Sprintf("Grid does not fit the GPU buffers, falling back to CPU: %v", err)
+	}
+	return fmt.Sprintf("GPU error, falling back to CPU: %v", err)
+}
 
-	// Draw the particles
-	for _, p := range sim.Particles {
-		rl.DrawSphere(p.Position.ToRaylib(), p.Radius, rl.Gold)
+// gpuDegradedMessage names the optional GPU features the driver lacks, or
+// returns "" if it has them all
+func gpuDegradedMessage(caps gpu.Capabilities) string {
+	missing := caps.Degraded()
+	if len(missing) == 0 {
+		return ""
 	}
+	return fmt.Sprintf("GPU without %s, using slower paths", strings.Join(missing, " and "))
+}
 
-	// Draw coordinate axes
-	rl.DrawLine3D(rl.NewVector3(0, 0, 0), rl.NewVector3(5, 0, 0), rl.Red)   // X axis
-	rl.DrawLine3D(rl.NewVector3(0, 0, 0), rl.NewVector3(0, 5, 0), rl.Green) // Y axis
-	rl.DrawLine3D(rl.NewVector3(0, 0, 0), rl.NewVector3(0, 0, 5), rl.Blue)  // Z axis
-
-	rl.EndMode3D()
-
-	// Draw UI
-	rl.DrawText("GR (Weak-Field) N-Body Simulation", 10, 10, 20, rl.Lime)
-	rl.DrawText(fmt.Sprintf("Particles: %d", cfg.NumParticles), 10, 40, 20, rl.White)
+// drawPotentialGPU draws every stride-th grid line from the GPU potential
+// texture and reports whether it did; the CPU grid is drawn instead if the GPU potential is not
+// current or drawing fails
+func (s *Simulation) drawPotentialGPU(stride int) bool {
+	if !useGPU || s.gpu == nil || !s.gpu.Initialized || s.fallbackToCPU || s.cpuGridView || s.cuda != nil || cfg.DirectSolver() || s.gpu.PotentialBuffer == nil {
+		return false
+	}
+	scheme := ui.GetColorScheme()
+	colors := gpu.PotentialColors{Base: scheme.Grid.RGB(), Hot: scheme.GridHot.RGB()}
+	if err := DrawPotentialGPU(s.gpu, s.PotentialGrid.Width(), s.PotentialGrid.Height(), stride, s.mesh.Dx(), cfg.GridVisScale, colors); err != nil {
+		// Rendering does not affect the physics, so only the view falls back
+		s.lastGPUError = err
+		s.cpuGridView = true
+		return false
+	}
+	return true
+}
 
-	// GPU/CPU status indicator with GPU error status
-	if useGPU {
-		if sim.HasGPUErrorOccurred() {
-			rl.DrawText("Mode: GPU (Fallback to CPU)", 10, 70, 20, rl.Yellow)
-		} else {
-			rl.DrawText("Mode: GPU Accelerated", 10, 70, 20, rl.Green)
+// drawDeformedGrid draws every stride-th line of a CPU potential grid of
+// cells of size cellSize, each segment in the color of its first node (nil =
+// the palette's grid color)
+func drawDeformedGrid(grid physics.Grid, stride int, cellSize float64, nodeColors [][]rl.Color) {
+	gridColor := raylibColor(ui.GetColorScheme().Grid)
+	colorAt := func(i, j int) rl.Color {
+		if nodeColors == nil {
+			return gridColor
 		}
-	} else {
-		rl.DrawText("Mode: CPU Only", 10, 70, 20, rl.Orange)
-	}
-
-	rl.DrawText("Right-click + Mouse to look", 10, 130, 20, rl.White)
-	rl.DrawText("W,A,S,D,Q,E to move", 10, 160, 20, rl.White)
-	rl.DrawText("P to pause, G to toggle GPU", 10, 190, 20, rl.White)
-
-	// Display both target and actual FPS
-	targetFPS := 60
-	actualFPS := rl.GetFPS()
-	frameTime := rl.GetFrameTime()
-	rl.DrawText(fmt.Sprintf("Target FPS: %d", targetFPS), int32(cfg.ScreenWidth)-200, 10, 20, rl.White)
-	rl.DrawText(fmt.Sprintf("Actual FPS: %d", actualFPS), int32(cfg.ScreenWidth)-200, 35, 20, rl.White)
-	rl.DrawText(fmt.Sprintf("Frame Time: %.3fs", frameTime), int32(cfg.ScreenWidth)-200, 60, 20, rl.White)
-
-	if pause {
-		rl.DrawText("PAUSED (Press P to unpause)", int32(cfg.ScreenWidth)/2-150, int32(cfg.ScreenHeight)/2-10, 20, rl.Yellow)
+		return nodeColors[i][j]
 	}
-
-	rl.EndDrawing()
-}
-
-func drawDeformedGrid(sim *Simulation) {
-	gridColor := rl.NewColor(50, 50, 100, 255)
+	width, height := grid.Width(), grid.Height()
+	dx := float32(cellSize)
 
 	// Draw lines parallel to Z axis
-	for i := 0; i < cfg.SimulationWidth; i++ {
-		for j := 0; j < cfg.SimulationDepth-1; j++ {
-			p1X := float32(i) - float32(cfg.SimulationWidth)/2.0
-			p1Z := float32(j) - float32(cfg.SimulationDepth)/2.0
-			p1Y := float32(sim.PotentialGrid[i][j] * cfg.GridVisScale)
+	for i := 0; i < width; i += stride {
+		for j := 0; j < height-1; j++ {
+			p1X := (float32(i) - float32(width)/2.0) * dx
+			p1Z := (float32(j) - float32(height)/2.0) * dx
+			p1Y := float32(grid.AtUnchecked(i, j) * cfg.GridVisScale)
 
-			p2X := float32(i) - float32(cfg.SimulationWidth)/2.0
-			p2Z := float32(j+1) - float32(cfg.SimulationDepth)/2.0
-			p2Y := float32(sim.PotentialGrid[i][j+1] * cfg.GridVisScale)
+			p2X := (float32(i) - float32(width)/2.0) * dx
+			p2Z := (float32(j+1) - float32(height)/2.0) * dx
+			p2Y := float32(grid.AtUnchecked(i, j+1) * cfg.GridVisScale)
 
-			rl.DrawLine3D(rl.NewVector3(p1X, p1Y, p1Z), rl.NewVector3(p2X, p2Y, p2Z), gridColor)
+			rl.DrawLine3D(rl.NewVector3(p1X, p1Y, p1Z), rl.NewVector3(p2X, p2Y, p2Z), colorAt(i, j))
 		}
 	}
 
 	// Draw lines parallel to X axis
-	for j := 0; j < cfg.SimulationDepth; j++ {
-		for i := 0; i < cfg.SimulationWidth-1; i++ {
-			p1X := float32(i) - float32(cfg.SimulationWidth)/2.0
-			p1Z := float32(j) - float32(cfg.SimulationDepth)/2.0
-			p1Y := float32(sim.PotentialGrid[i][j] * cfg.GridVisScale)
+	for j := 0; j < height; j += stride {
+		for i := 0; i < width-1; i++ {
+			p1X := (float32(i) - float32(width)/2.0) * dx
+			p1Z := (float32(j) - float32(height)/2.0) * dx
+			p1Y := float32(grid.AtUnchecked(i, j) * cfg.GridVisScale)
 
-			p2X := float32(i+1) - float32(cfg.SimulationWidth)/2.0
-			p2Z := float32(j) - float32(cfg.SimulationDepth)/2.0
-			p2Y := float32(sim.PotentialGrid[i+1][j] * cfg.GridVisScale)
+			p2X := (float32(i+1) - float32(width)/2.0) * dx
+			p2Z := (float32(j) - float32(height)/2.0) * dx
+			p2Y := float32(grid.AtUnchecked(i+1, j) * cfg.GridVisScale)
 
-			rl.DrawLine3D(rl.NewVector3(p1X, p1Y, p1Z), rl.NewVector3(p2X, p2Y, p2Z), gridColor)
+			rl.DrawLine3D(rl.NewVector3(p1X, p1Y, p1Z), rl.NewVector3(p2X, p2Y, p2Z), colorAt(i, j))
 		}
 	}
 }
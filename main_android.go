@@ -0,0 +1,31 @@
+//go:build android
+
+package main
+
+import (
+	rl "github.com/gen2brain/raylib-go/raylib"
+	"path/filepath"
+	"relativity_simulation_2d/internal/config"
+)
+
+// The app is loaded as a shared library by raylib's native activity, which
+// calls main through this callback instead of running it at startup
+func init() {
+	rl.SetCallbackFunc(main)
+}
+
+// applyPlatformDefaults turns on touch controls and keeps compute on the CPU,
+// since OpenGL ES has no compute shaders
+func applyPlatformDefaults(cfg *config.Config) {
+	cfg.TouchControls = true
+	cfg.UseGPU = false
+}
+
+// platformWindowOpened adopts the display size, which raylib uses instead of
+// the requested one, and moves crash reports into the app's internal storage
+func platformWindowOpened(cfg *config.Config) {
+	cfg.ScreenWidth = rl.GetScreenWidth()
+	cfg.ScreenHeight = rl.GetScreenHeight()
+	cfg.CrashReportDir = filepath.Join(rl.HomeDir(), config.DefaultConfig().CrashReportDir)
+	ui = newUIRenderer(cfg)
+}
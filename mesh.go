@@ -0,0 +1,69 @@
+//go:build !js
+
+package main
+
+import (
+	"fmt"
+	"path/filepath"
+	"relativity_simulation_2d/internal/export"
+	"relativity_simulation_2d/internal/output"
+	"relativity_simulation_2d/internal/physics"
+	"relativity_simulation_2d/internal/provenance"
+	"relativity_simulation_2d/internal/renderer"
+	"relativity_simulation_2d/internal/simulation"
+	"strings"
+)
+
+// Resolution of the particle spheres in exported meshes
+const (
+	meshSphereSlices = 16
+	meshSphereStacks = 8
+)
+
+// defaultMeshPath is the file F9 numbers by step without -mesh-out
+const defaultMeshPath = "spacetime.gltf"
+
+// sceneMeshes returns the frame's deformed grid as a surface and, with
+// -mesh-particles, the particles as spheres of their drawn radii. Tracer
+// sheet members are left out, as in the view
+func sceneMeshes(sim *Simulation, frame *simulation.Frame) []export.Mesh {
+	meshes := []export.Mesh{export.SurfaceMesh(frame.PotentialGrid, cfg.GridVisScale)}
+	if !cfg.MeshParticles {
+		return meshes
+	}
+	display := renderer.DisplayRadius{Scale: cfg.DisplayScale, Min: cfg.MinDisplayRadius}
+	centers := make([]physics.Vec3, 0, len(frame.Particles))
+	radii := make([]float32, 0, len(frame.Particles))
+	for i, p := range frame.Particles {
+		if sim.sheet != nil && sim.sheet.Contains(i) {
+			continue
+		}
+		centers = append(centers, p.Position)
+		radii = append(radii, display.Radius(p.Radius))
+	}
+	return append(meshes, export.SphereMesh("particles", centers, radii, meshSphereSlices, meshSphereStacks))
+}
+
+// exportMesh writes the meshes of the published frame to path, with the
+// run's metadata beside them
+func (s *Simulation) exportMesh(path string) error {
+	var err error
+	s.ReadFrame(func(frame *simulation.Frame) {
+		err = export.SaveMesh(path, sceneMeshes(s, frame))
+	})
+	if err != nil {
+		return err
+	}
+	return provenance.WriteSidecar(path, s.metadata())
+}
+
+// numberedMeshPath returns the file F9 writes at step: -mesh-out, or
+// defaultMeshPath in the run's exports, with the step before the extension
+func numberedMeshPath(step int64) string {
+	path := cfg.MeshPath
+	if path == "" {
+		path = outputs.Path(output.ExportsDir, defaultMeshPath)
+	}
+	ext := filepath.Ext(path)
+	return fmt.Sprintf("%s-%06d%s", strings.TrimSuffix(path, ext), step, ext)
+}
@@ -0,0 +1,54 @@
+//go:build !js
+
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"relativity_simulation_2d/internal/config"
+	"relativity_simulation_2d/internal/simulation"
+	"testing"
+)
+
+// TestMeshExport tests writing the published grid and particles as a mesh,
+// numbered by step on F9
+func TestMeshExport(t *testing.T) {
+	saved, savedGPU := cfg, useGPU
+	defer func() { cfg, useGPU = saved, savedGPU }()
+	cfg = config.DefaultConfig()
+	cfg.SimulationWidth, cfg.SimulationDepth = 64, 64
+	cfg.NumParticles = 10
+	cfg.Seed = 7
+	cfg.TracerSheet = 4
+	cfg.MeshParticles = true
+	cfg.ComputeMode = config.ComputeCPU
+	useGPU = false
+
+	sim := NewSimulation()
+	sim.Step(0.05)
+	sim.ReadFrame(func(frame *simulation.Frame) {
+		meshes := sceneMeshes(sim, frame)
+		if len(meshes) != 2 || meshes[0].VertexCount() != 64*64 {
+			t.Fatalf("Expected the grid surface and the particles, got %d meshes", len(meshes))
+		}
+		if spheres := meshes[1].VertexCount() / ((meshSphereSlices + 1) * (meshSphereStacks + 1)); spheres != 10 {
+			t.Errorf("Expected spheres for the 10 particles but not the sheet, got %d", spheres)
+		}
+	})
+
+	path := filepath.Join(t.TempDir(), "spacetime.obj")
+	if err := sim.exportMesh(path); err != nil {
+		t.Fatalf("exportMesh failed: %v", err)
+	}
+	if info, err := os.Stat(path); err != nil || info.Size() == 0 {
+		t.Errorf("Expected a mesh file, got %v", err)
+	}
+
+	if got := numberedMeshPath(42); got != "spacetime-000042.gltf" {
+		t.Errorf("Expected spacetime-000042.gltf, got %s", got)
+	}
+	cfg.MeshPath = filepath.Join("out", "still.obj")
+	if got := numberedMeshPath(7); got != filepath.Join("out", "still-000007.obj") {
+		t.Errorf("Expected out/still-000007.obj, got %s", got)
+	}
+}
@@ -0,0 +1,231 @@
+//go:build !js
+
+package main
+
+import (
+	"fmt"
+	rl "github.com/gen2brain/raylib-go/raylib"
+	"image"
+	"os"
+	"path/filepath"
+	"relativity_simulation_2d/internal/config"
+	"relativity_simulation_2d/internal/physics"
+	"relativity_simulation_2d/internal/provenance"
+	"relativity_simulation_2d/internal/renderer"
+	"relativity_simulation_2d/internal/simulation"
+	"relativity_simulation_2d/internal/snapshot"
+)
+
+// offlineOverlayLines is the number of overlay lines that would fill the
+// height of a rendered image, setting their font size
+const offlineOverlayLines = 45
+
+// runOfflineRender renders each frame of the replay in cfg.RenderDir to a PNG
+// image. Nothing runs in real time, so the images can be any size up to the
+// GPU's texture limit and are supersampled for antialiasing
+func runOfflineRender() error {
+	paths, err := snapshot.ListFrames(cfg.RenderDir)
+	if err != nil {
+		return err
+	}
+	if len(paths) == 0 {
+		return fmt.Errorf("no replay frames in %s (record them with -headless -record)", cfg.RenderDir)
+	}
+	out := renderOutDir()
+	if err := os.MkdirAll(out, 0755); err != nil {
+		return fmt.Errorf("failed to create render directory: %v", err)
+	}
+
+	if _, err := glContext.Acquire(); err != nil {
+		return fmt.Errorf("failed to create GL context: %v", err)
+	}
+	defer glContext.Release()
+	quality = &qualityState{} // Every grid line, whatever the cost
+	scene = newScene()        // Overlay layers, such as the minimap, are not rendered
+	target := &offlineTarget{}
+	defer target.close()
+
+	camera := initialCamera()
+	for i, path := range paths {
+		snap, err := snapshot.Load(path)
+		if err != nil {
+			return err
+		}
+		applyReplayConfig(snap.Config)
+		params := cfg.Summary()
+		if snap.Config != nil {
+			params = snap.Config.Summary()
+		}
+		sim := newReplaySimulation(snap)
+		var img *image.RGBA
+		sim.ReadFrame(func(frame *simulation.Frame) {
+			img = renderOffline(target, camera, sim, frame, overlayLines(frame.Step, frame.SimTime, params))
+		})
+		name := fmt.Sprintf("frame_%08d.png", snap.Step)
+		if err := savePNG(filepath.Join(out, name), img, frameMetadata(snap)); err != nil {
+			return err
+		}
+		fmt.Printf("Rendered %s (%d/%d)\n", name, i+1, len(paths))
+	}
+	return nil
+}
+
+// frameMetadata returns the metadata of the run that recorded a replay
+// frame, which its rendered image carries. Frames recorded before metadata
+// was kept get this build's, with the recorded configuration and an unknown
+// back end
+func frameMetadata(snap *snapshot.Snapshot) provenance.Metadata {
+	if snap.Metadata != nil {
+		return *snap.Metadata
+	}
+	recorded := snap.Config
+	if recorded == nil {
+		recorded = cfg
+	}
+	return provenance.New(recorded, "")
+}
+
+// renderOutDir returns the directory for rendered images
+func renderOutDir() string {
+	if cfg.RenderOut != "" {
+		return cfg.RenderOut
+	}
+	return filepath.Join(cfg.RenderDir, "render")
+}
+
+// applyReplayConfig takes over the settings of the recorded run that shape
+// its frames: the grid and its cell size, the gravity the potential is solved with and the
+// tracer sheet. How they are drawn stays as configured for the render
+func applyReplayConfig(recorded *config.Config) {
+	if recorded == nil {
+		return
+	}
+	cfg.SimulationWidth, cfg.SimulationDepth = recorded.SimulationWidth, recorded.SimulationDepth
+	cfg.CellSize = recorded.CellSize
+	cfg.GravitationalConstant = recorded.GravitationalConstant
+	cfg.TracerSheet = recorded.TracerSheet
+}
+
+// newReplaySimulation returns a simulation holding a recorded frame for
+// drawing, with the grids solved again from its particles
+func newReplaySimulation(snap *snapshot.Snapshot) *Simulation {
+	width, height := cfg.SimulationWidth, cfg.SimulationDepth
+	sim := &Simulation{
+		Particles:       snap.Restore(),
+		PotentialGrid:   physics.NewGrid(width, height),
+		MassDensityGrid: physics.NewGrid(width, height),
+		AccelFieldX:     physics.NewGrid(width, height),
+		AccelFieldZ:     physics.NewGrid(width, height),
+		StepCount:       snap.Step,
+		SimTime:         snap.SimTime,
+	}
+	sim.precision, _ = physics.ParsePrecision(cfg.Precision) // Validated at startup
+	sim.mesh = &physics.Mesh{CellSize: cfg.CellSize}
+	if n := cfg.TracerSheet; n > 0 && len(sim.Particles) >= n*n {
+		sheet, _ := physics.NewTracerSheet(len(sim.Particles)-n*n, n, n, width, height, sim.mesh.Dx()) // The sheet comes last
+		sim.sheet = &sheet
+	}
+	sim.solveView()
+	sim.frames = simulation.NewFrameBuffer(len(sim.Particles), width, height)
+	sim.publish()
+	return sim
+}
+
+// renderOffline draws the frame at cfg.RenderWidth×cfg.RenderHeight, in
+// stereo if configured, stamps the overlay on it and returns the image
+func renderOffline(target *offlineTarget, camera rl.Camera, sim *Simulation, frame *simulation.Frame, overlay []string) *image.RGBA {
+	scheme := ui.GetColorScheme()
+	layers := &sceneFrame{
+		sim:        sim,
+		frame:      frame,
+		camera:     camera,
+		scheme:     scheme,
+		colors:     particleColors(sim, frame, scheme),
+		gridColors: flowColors(sim, frame, scheme),
+	}
+	drawWorld := func() { scene.DrawWorld(layers) }
+
+	width, height := cfg.RenderWidth, cfg.RenderHeight
+	layers.width, layers.height = width, height
+	if cfg.RenderStereo != "" {
+		layers.padding = eyeOffset(cfg.EyeSeparation)
+	}
+	left, right := stereoEyes(camera, cfg.EyeSeparation)
+	switch cfg.RenderStereo {
+	case config.RenderStereoSideBySide:
+		layers.width = width / 2
+		return renderer.SideBySide(target.render(left, width/2, height, drawWorld, overlay), target.render(right, width/2, height, drawWorld, overlay))
+	case config.RenderStereoAnaglyph:
+		return renderer.Anaglyph(target.render(left, width, height, drawWorld, overlay), target.render(right, width, height, drawWorld, overlay))
+	default:
+		return target.render(camera, width, height, drawWorld, overlay)
+	}
+}
+
+// offlineTarget is the supersampled render texture of the offline renderer
+type offlineTarget struct {
+	texture       rl.RenderTexture2D
+	width, height int32 // Size of the texture (0 = not loaded)
+}
+
+// render draws the scene from camera and the overlay into the texture at
+// cfg.Supersample times width×height, reads it back and averages it down to
+// width×height. Both eyes of a stereo pair carry the overlay at the same
+// place, so it is seen at the depth of the screen
+func (t *offlineTarget) render(camera rl.Camera, width, height int, drawScene func(), overlay []string) *image.RGBA {
+	w, h := int32(width*cfg.Supersample), int32(height*cfg.Supersample)
+	if w != t.width || h != t.height {
+		t.close()
+		t.texture = rl.LoadRenderTexture(w, h)
+		t.width, t.height = w, h
+	}
+
+	rl.BeginTextureMode(t.texture)
+	rl.ClearBackground(rl.Black)
+	rl.SetLineWidth(float32(cfg.Supersample)) // Lines keep their width once averaged down
+	rl.BeginMode3D(camera)
+	drawScene()
+	rl.EndMode3D()
+	rl.SetLineWidth(1)
+	drawOverlay(overlay, int(w), int(h), max(10, int(h)/offlineOverlayLines), ui.GetDefaultTextColor())
+	rl.EndTextureMode()
+
+	// Render textures are stored bottom-up
+	readback := rl.LoadImageFromTexture(t.texture.Texture)
+	defer rl.UnloadImage(readback)
+	rl.ImageFlipVertical(readback)
+	return renderer.Downsample(opaqueImage(readback), cfg.Supersample)
+}
+
+// opaqueImage copies a raylib image into an opaque RGBA image
+func opaqueImage(src *rl.Image) *image.RGBA {
+	pixels := rl.LoadImageColors(src)
+	defer rl.UnloadImageColors(pixels)
+	img := image.NewRGBA(image.Rect(0, 0, int(src.Width), int(src.Height)))
+	for i, c := range pixels {
+		img.Pix[4*i], img.Pix[4*i+1], img.Pix[4*i+2], img.Pix[4*i+3] = c.R, c.G, c.B, 255
+	}
+	return img
+}
+
+// close releases the texture
+func (t *offlineTarget) close() {
+	if t.width == 0 {
+		return
+	}
+	rl.UnloadRenderTexture(t.texture)
+	t.width, t.height = 0, 0
+}
+
+// savePNG writes img to path as PNG with meta embedded
+func savePNG(path string, img image.Image, meta provenance.Metadata) error {
+	file, err := os.Create(path)
+	if err != nil {
+		return fmt.Errorf("failed to create image: %v", err)
+	}
+	if err := provenance.EncodePNG(file, img, meta); err != nil {
+		_ = file.Close()
+		return fmt.Errorf("failed to write image: %v", err)
+	}
+	return file.Close()
+}
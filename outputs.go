@@ -0,0 +1,53 @@
+//go:build !js
+
+package main
+
+import (
+	"fmt"
+	rl "github.com/gen2brain/raylib-go/raylib"
+	"os"
+	"relativity_simulation_2d/internal/output"
+	"relativity_simulation_2d/internal/provenance"
+	"time"
+)
+
+// outputs keeps the outputs of this run in its own directory with -run-dir
+// (nil = outputs where configured)
+var outputs *output.Manager
+
+// startRun creates the run directory under -run-dir for a run started at
+// start, copies the configuration into it, links it as the latest run and
+// moves the configured outputs inside
+func startRun(start time.Time) error {
+	if cfg.RunDir == "" {
+		return nil
+	}
+	m, err := output.NewRun(cfg.RunDir, start)
+	if err != nil {
+		return err
+	}
+	if err := m.WriteConfig(cfg); err != nil {
+		return err
+	}
+	if err := m.LinkLatest(); err != nil {
+		fmt.Fprintf(os.Stderr, "Warning: %v\n", err)
+	}
+	m.Redirect(cfg)
+	outputs = m
+	fmt.Printf("Run directory: %s\n", m.Dir)
+	return nil
+}
+
+// screenshotPath returns a free path for a screenshot at step, in the run's
+// screenshots directory or else the working directory
+func screenshotPath(step int64) string {
+	return output.Unique(outputs.Path(output.ScreenshotsDir, fmt.Sprintf("screenshot-%06d.png", step)))
+}
+
+// takeScreenshot writes the frame drawn so far to path as PNG with meta
+// embedded; call it before rl.EndDrawing
+func takeScreenshot(path string, meta provenance.Metadata) error {
+	shot := rl.LoadImageFromScreen()
+	defer rl.UnloadImage(shot)
+	return savePNG(path, opaqueImage(shot), meta)
+}
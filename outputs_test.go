@@ -0,0 +1,43 @@
+//go:build !js && !android
+
+package main
+
+import (
+	"path/filepath"
+	"relativity_simulation_2d/internal/config"
+	"relativity_simulation_2d/internal/output"
+	"testing"
+	"time"
+)
+
+// TestStartRun tests that a run directory takes the relative outputs, the
+// default mesh and screenshots, and holds the configuration as given
+func TestStartRun(t *testing.T) {
+	saved := cfg
+	defer func() { cfg, outputs = saved, nil }()
+	cfg = config.DefaultConfig()
+	cfg.RunDir = t.TempDir()
+	cfg.DiagnosticsPath = "diagnostics.csv"
+	if err := startRun(time.Date(2026, 10, 18, 9, 0, 0, 0, time.Local)); err != nil {
+		t.Fatalf("startRun failed: %v", err)
+	}
+
+	dir := filepath.Join(cfg.RunDir, "20261018-090000")
+	if cfg.DiagnosticsPath != filepath.Join(dir, output.LogsDir, "diagnostics.csv") {
+		t.Errorf("Expected the diagnostics in the run's logs, got %s", cfg.DiagnosticsPath)
+	}
+	if got := numberedMeshPath(42); got != filepath.Join(dir, output.ExportsDir, "spacetime-000042.gltf") {
+		t.Errorf("Expected the F9 mesh in the run's exports, got %s", got)
+	}
+	if got := screenshotPath(7); got != filepath.Join(dir, output.ScreenshotsDir, "screenshot-000007.png") {
+		t.Errorf("Expected the screenshot in the run's screenshots, got %s", got)
+	}
+
+	copied := config.DefaultConfig()
+	if err := copied.LoadFile(filepath.Join(dir, output.ConfigFile)); err != nil {
+		t.Fatalf("Expected a loadable config copy: %v", err)
+	}
+	if copied.DiagnosticsPath != "diagnostics.csv" {
+		t.Errorf("Expected the copy to keep the paths as given, got %s", copied.DiagnosticsPath)
+	}
+}
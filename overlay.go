@@ -0,0 +1,56 @@
+//go:build !js
+
+package main
+
+import (
+	"fmt"
+	rl "github.com/gen2brain/raylib-go/raylib"
+	"relativity_simulation_2d/internal/config"
+	"relativity_simulation_2d/internal/renderer"
+	"slices"
+	"strings"
+)
+
+// overlayBackdrop is the opacity of the band behind the overlay text
+const overlayBackdrop = 0.6
+
+// overlayLines returns the configured overlay text for the frame at step and
+// simTime of a run summarized by params: the time and step on one line, then
+// the parameters and the label
+func overlayLines(step int64, simTime float64, params string) []string {
+	items, _ := config.ParseOverlay(cfg.Overlay) // Validated at startup
+	var clock, lines []string
+	for _, item := range items {
+		switch item {
+		case config.OverlayTime:
+			clock = append(clock, fmt.Sprintf("t = %.3f", simTime))
+		case config.OverlayStep:
+			clock = append(clock, fmt.Sprintf("step %d", step))
+		}
+	}
+	if len(clock) > 0 {
+		lines = append(lines, strings.Join(clock, "   "))
+	}
+	if slices.Contains(items, config.OverlayParams) {
+		lines = append(lines, params)
+	}
+	if cfg.OverlayLabel != "" {
+		lines = append(lines, cfg.OverlayLabel)
+	}
+	return lines
+}
+
+// drawOverlay draws the lines centered at the bottom of a width×height
+// target, in the given font size over a dark band
+func drawOverlay(lines []string, width, height, size int, color renderer.UIColor) {
+	if len(lines) == 0 {
+		return
+	}
+	pad := size / 3
+	top := height - len(lines)*(size+pad) - pad
+	rl.DrawRectangle(0, int32(top-pad), int32(width), int32(height-top+pad), rl.Fade(rl.Black, overlayBackdrop))
+	for i, line := range lines {
+		x := (width - int(rl.MeasureText(line, int32(size)))) / 2
+		rl.DrawText(line, int32(x), int32(top+i*(size+pad)), int32(size), raylibColor(color))
+	}
+}
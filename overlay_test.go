@@ -0,0 +1,31 @@
+//go:build !js
+
+package main
+
+import (
+	"relativity_simulation_2d/internal/config"
+	"testing"
+)
+
+// TestOverlayLines tests composing the frame overlay from the configured items
+func TestOverlayLines(t *testing.T) {
+	saved := cfg
+	defer func() { cfg = saved }()
+	cfg = config.DefaultConfig()
+
+	if lines := overlayLines(10, 1.5, "params"); len(lines) != 0 {
+		t.Errorf("Expected no overlay by default, got %q", lines)
+	}
+	cfg.Overlay = "params,step,time"
+	cfg.OverlayLabel = "(c) lab"
+	lines := overlayLines(740, 12.3456, "random, 10 particles")
+	want := []string{"step 740   t = 12.346", "random, 10 particles", "(c) lab"}
+	if len(lines) != len(want) {
+		t.Fatalf("Expected %q, got %q", want, lines)
+	}
+	for i := range want {
+		if lines[i] != want[i] {
+			t.Errorf("Expected line %d to be %q, got %q", i, want[i], lines[i])
+		}
+	}
+}
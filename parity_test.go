@@ -0,0 +1,313 @@
+//go:build !js && !android
+
+package main
+
+import (
+	"fmt"
+	"math"
+	"math/rand"
+	"os"
+	"relativity_simulation_2d/internal/gpu"
+	"relativity_simulation_2d/internal/physics"
+	fftpkg "relativity_simulation_2d/pkg/fft"
+	"testing"
+)
+
+// parityKernel is a GPU kernel checked against the CPU code it stands in
+// for. run computes both on the same problem and returns them flattened in
+// the same order
+type parityKernel struct {
+	name      string
+	tolerance float64 // Largest difference allowed, relative to the largest CPU value
+	run       func(g *gpu.GPU, width, height int) (gpuValues, cpuValues []float64, err error)
+}
+
+// paritySizes are the grids every kernel runs on: powers of two for the
+// radix-2 FFT, square and not, sizes that take the direct DFT, and a long
+// rectangular domain
+var paritySizes = [][2]int{{8, 8}, {16, 8}, {8, 16}, {12, 10}, {128, 512}}
+
+// parityKernels lists the OpenGL kernels with a CPU fallback. Deposition and
+// integration have no OpenGL kernels and run on the CPU in that backend; the
+// CUDA backend checks its own in internal/cuda
+var parityKernels = []parityKernel{
+	{name: "fft forward", tolerance: 1e-5, run: parityFFT(true)},
+	{name: "fft inverse", tolerance: 1e-5, run: parityFFT(false)},
+	{name: "greens", tolerance: 1e-4, run: parityGreens},
+	{name: "gradient", tolerance: 1e-5, run: parityGradient},
+	{name: "poisson", tolerance: 1e-4, run: parityPoisson},
+	{name: "potential range", tolerance: 1e-4, run: parityPotentialRange},
+	{name: "direct", tolerance: 1e-4, run: parityDirect},
+	{name: "moments", tolerance: 1e-5, run: parityMoments},
+}
+
+// parityEnv opts in to the GPU parity test. Opening the window for the GL
+// context aborts the test binary on a machine without a display, which no
+// recover can catch
+const parityEnv = "RELATIVITY_GPU_TESTS"
+
+// TestGPUParity runs every GPU kernel and its CPU fallback on small problems
+// and checks that they agree to float32 rounding. It opens a hidden window
+// for the GL context, so it runs only with RELATIVITY_GPU_TESTS=1, and is
+// skipped without GL 4.3 or with -short
+func TestGPUParity(t *testing.T) {
+	if os.Getenv(parityEnv) != "1" {
+		t.Skip("Opens a window; set " + parityEnv + "=1 to run")
+	}
+	if testing.Short() {
+		t.Skip("Needs an OpenGL context")
+	}
+	g, err := initializeParityGPU()
+	if err != nil {
+		t.Skipf("No OpenGL 4.3 context: %v", err)
+	}
+	defer CleanupGPU(g)
+
+	for _, kernel := range parityKernels {
+		for _, size := range paritySizes {
+			width, height := size[0], size[1]
+			t.Run(fmt.Sprintf("%s/%dx%d", kernel.name, width, height), func(t *testing.T) {
+				gpuValues, cpuValues, err := kernel.run(g, width, height)
+				if err != nil {
+					t.Fatalf("GPU kernel failed: %v", err)
+				}
+				if len(gpuValues) != len(cpuValues) {
+					t.Fatalf("Expected %d values, got %d", len(cpuValues), len(gpuValues))
+				}
+				if diff, at := parityError(gpuValues, cpuValues); diff > kernel.tolerance {
+					t.Errorf("Relative difference %.3g at value %d exceeds %.3g (GPU %g, CPU %g)",
+						diff, at, kernel.tolerance, gpuValues[at], cpuValues[at])
+				}
+			})
+		}
+	}
+}
+
+// initializeParityGPU initializes the GPU, turning a panic from a raylib
+// that cannot open windows here into an error
+func initializeParityGPU() (g *gpu.GPU, err error) {
+	defer func() {
+		if r := recover(); r != nil {
+			g, err = nil, fmt.Errorf("%w: %v", gpu.ErrNoGLContext, r)
+		}
+	}()
+	return InitializeGPU()
+}
+
+// parityError returns the largest difference between got and want relative
+// to the largest magnitude in want, and where it occurs
+func parityError(got, want []float64) (float64, int) {
+	scale := 0.0
+	for _, v := range want {
+		scale = math.Max(scale, math.Abs(v))
+	}
+	if scale == 0 {
+		scale = 1
+	}
+	worst, at := 0.0, 0
+	for i := range want {
+		diff := math.Abs(got[i]-want[i]) / scale
+		if math.IsNaN(diff) {
+			return math.Inf(1), i
+		}
+		if diff > worst {
+			worst, at = diff, i
+		}
+	}
+	return worst, at
+}
+
+// parityDensity returns the deposited density of a small particle cloud
+func parityDensity(width, height int) physics.Grid {
+	particles := physics.InitializeParticlesWithSeed(1000, float64(width), float64(height), 7)
+	return physics.DepositMassToGrid(particles, width, height, nil)
+}
+
+// parityParticles returns a small particle cloud with random velocities
+func parityParticles(width, height int) []*physics.Particle {
+	particles := physics.InitializeParticlesWithSeed(64, float64(width), float64(height), 7)
+	rng := rand.New(rand.NewSource(7))
+	for _, p := range particles {
+		p.Velocity = physics.NewVec3(rng.Float64()-0.5, 0, rng.Float64()-0.5)
+	}
+	return particles
+}
+
+// complexGrid returns data, indexed i*height+j, as a [width][height] grid
+func complexGrid(data []complex128, width, height int) [][]complex128 {
+	grid := make([][]complex128, width)
+	for i := range grid {
+		grid[i] = data[i*height : (i+1)*height : (i+1)*height]
+	}
+	return grid
+}
+
+// interleave returns the real and imaginary parts of data in turn
+func interleave(data []complex128) []float64 {
+	values := make([]float64, 0, 2*len(data))
+	for _, c := range data {
+		values = append(values, real(c), imag(c))
+	}
+	return values
+}
+
+// runGPUComplex uploads data, runs kernel on its buffer and downloads the
+// buffer kernel returns
+func runGPUComplex(g *gpu.GPU, data []complex128, kernel func(input *gpu.ComplexGPUBuffer) (*gpu.ComplexGPUBuffer, error)) ([]complex128, error) {
+	input, err := CreateComplexGPUBuffer(g, len(data))
+	if err != nil {
+		return nil, err
+	}
+	defer FreeComplexGPUBuffer(input)
+	if err := UploadComplexData(input, data); err != nil {
+		return nil, err
+	}
+	output, err := kernel(input)
+	if err != nil {
+		return nil, err
+	}
+	return DownloadComplexData(output, len(data))
+}
+
+// parityFFT checks a forward or inverse GPU transform of random data against
+// fftpkg.Transform2DInPlace. The GPU inverse is not normalized
+func parityFFT(forward bool) func(g *gpu.GPU, width, height int) ([]float64, []float64, error) {
+	return func(g *gpu.GPU, width, height int) ([]float64, []float64, error) {
+		rng := rand.New(rand.NewSource(int64(width*height) + 1))
+		data := make([]complex128, width*height)
+		for i := range data {
+			data[i] = complex(rng.Float64()-0.5, rng.Float64()-0.5)
+		}
+
+		plan, err := cachedFFTPlan(g, width, height, forward)
+		if err != nil {
+			return nil, nil, err
+		}
+		output, err := CreateComplexGPUBuffer(g, len(data))
+		if err != nil {
+			return nil, nil, err
+		}
+		defer FreeComplexGPUBuffer(output)
+		result, err := runGPUComplex(g, data, func(input *gpu.ComplexGPUBuffer) (*gpu.ComplexGPUBuffer, error) {
+			return output, ExecuteFFT(plan, input, output)
+		})
+		if err != nil {
+			return nil, nil, err
+		}
+		if !forward {
+			for i := range result {
+				result[i] /= complex(float64(len(result)), 0)
+			}
+		}
+
+		want := append([]complex128(nil), data...)
+		fftpkg.Transform2DInPlace(complexGrid(want, width, height), !forward)
+		return interleave(result), interleave(want), nil
+	}
+}
+
+// parityGreens applies the GPU Green's function to the CPU transform of a
+// density and checks the inverse-transformed result against the CPU
+// potential
+func parityGreens(g *gpu.GPU, width, height int) ([]float64, []float64, error) {
+	density := parityDensity(width, height)
+	spectrum := make([]complex128, width*height)
+	for idx, v := range density.Flatten(nil) {
+		spectrum[idx] = complex(v, 0)
+	}
+	fftpkg.Transform2DInPlace(complexGrid(spectrum, width, height), false)
+
+	result, err := runGPUComplex(g, spectrum, func(input *gpu.ComplexGPUBuffer) (*gpu.ComplexGPUBuffer, error) {
+		return input, applyGreensFunction(g, input, width, height, 1, 1)
+	})
+	if err != nil {
+		return nil, nil, err
+	}
+	fftpkg.Transform2DInPlace(complexGrid(result, width, height), true)
+	potential := make([]float64, len(result))
+	for i, c := range result {
+		potential[i] = real(c)
+	}
+	return potential, physics.SolvePoissonFFT(density, width, height, 1, 1).Flatten(nil), nil
+}
+
+// parityGradient checks the GPU gradient of the CPU potential against
+// physics.CalculateGradient
+func parityGradient(g *gpu.GPU, width, height int) ([]float64, []float64, error) {
+	potential := physics.SolvePoissonFFT(parityDensity(width, height), width, height, 1, 1)
+	data := make([]complex128, width*height)
+	for idx, v := range potential.Flatten(nil) {
+		data[idx] = complex(v, 0)
+	}
+
+	buffer, err := CreateComplexGPUBuffer(g, len(data))
+	if err != nil {
+		return nil, nil, err
+	}
+	defer FreeComplexGPUBuffer(buffer)
+	if err := UploadComplexData(buffer, data); err != nil {
+		return nil, nil, err
+	}
+	got, err := GradientGPU(g, buffer, width, height, 1, 1)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	want := physics.CalculateGradient(potential, width, height, 1)
+	return append(got.AccelFieldX.Flatten(nil), got.AccelFieldZ.Flatten(nil)...),
+		append(want.AccelFieldX.Flatten(nil), want.AccelFieldZ.Flatten(nil)...), nil
+}
+
+// parityPoisson checks the whole GPU Poisson solve against
+// physics.SolvePoissonFFT
+func parityPoisson(g *gpu.GPU, width, height int) ([]float64, []float64, error) {
+	density := parityDensity(width, height)
+	got, err := SolvePoissonGPU(g, density, 1, 1)
+	if err != nil {
+		return nil, nil, err
+	}
+	return got.Flatten(nil), physics.SolvePoissonFFT(density, width, height, 1, 1).Flatten(nil), nil
+}
+
+// parityPotentialRange checks the range reduction over the potential left
+// on the GPU by a Poisson solve against the CPU potential's range
+func parityPotentialRange(g *gpu.GPU, width, height int) ([]float64, []float64, error) {
+	density := parityDensity(width, height)
+	if _, err := SolvePoissonGPU(g, density, 1, 1); err != nil {
+		return nil, nil, err
+	}
+	got, err := PotentialRangeGPU(g)
+	if err != nil {
+		return nil, nil, err
+	}
+	lo, hi := math.Inf(1), math.Inf(-1)
+	for _, v := range physics.SolvePoissonFFT(density, width, height, 1, 1).Flatten(nil) {
+		lo, hi = math.Min(lo, v), math.Max(hi, v)
+	}
+	return []float64{got.Min, got.Max}, []float64{lo, hi}, nil
+}
+
+// parityDirect checks the tiled direct-summation kernel against
+// physics.DirectAccelerationsPeriodic
+func parityDirect(g *gpu.GPU, width, height int) ([]float64, []float64, error) {
+	particles := parityParticles(width, height)
+	ax, az, err := DirectAccelerationsGPU(g, particles, 1, 0.5, float64(width), float64(height))
+	if err != nil {
+		return nil, nil, err
+	}
+	wantX, wantZ := physics.DirectAccelerationsPeriodic(particles, 1, 0.5, 1, float64(width), float64(height))
+	return append(ax, az...), append(wantX, wantZ...), nil
+}
+
+// parityMoments checks the particle moments reduction against
+// physics.ComputeDiagnostics
+func parityMoments(g *gpu.GPU, width, height int) ([]float64, []float64, error) {
+	particles := parityParticles(width, height)
+	got, err := ParticleMomentsGPU(g, particles)
+	if err != nil {
+		return nil, nil, err
+	}
+	want := physics.ComputeDiagnostics(particles)
+	return []float64{got.KineticEnergy, got.MomentumX, got.MomentumZ, got.TotalMass},
+		[]float64{want.KineticEnergy, want.MomentumX, want.MomentumZ, want.TotalMass}, nil
+}
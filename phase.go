@@ -0,0 +1,68 @@
+//go:build !js
+
+package main
+
+import (
+	rl "github.com/gen2brain/raylib-go/raylib"
+	"relativity_simulation_2d/internal/plot"
+	"relativity_simulation_2d/internal/simulation"
+)
+
+// Phase-space panel settings
+const (
+	phaseMaxPoints = 4096 // Particles plotted at most; larger systems are evenly sub-sampled
+	phasePlotSize  = 260  // Width and height of each scatter plot
+	phasePointSize = 2
+)
+
+// phaseScatters returns the x–vx and z–vz scatter plots of the frame's
+// particles, at most phaseMaxPoints of them taken at an even stride, with
+// the particle index of each point
+func phaseScatters(frame *simulation.Frame) (xs, zs *plot.Scatter, index []int) {
+	stride := (len(frame.Particles) + phaseMaxPoints - 1) / phaseMaxPoints
+	if stride < 1 {
+		stride = 1
+	}
+	n := (len(frame.Particles) + stride - 1) / stride
+	xs = &plot.Scatter{Title: "Phase space x-vx", XLabel: "x", YLabel: "vx", X: make([]float64, 0, n), Y: make([]float64, 0, n)}
+	zs = &plot.Scatter{Title: "Phase space z-vz", XLabel: "z", YLabel: "vz", X: make([]float64, 0, n), Y: make([]float64, 0, n)}
+	index = make([]int, 0, n)
+	for i := 0; i < len(frame.Particles); i += stride {
+		p := &frame.Particles[i]
+		xs.X, xs.Y = append(xs.X, p.Position.X), append(xs.Y, p.Velocity.X)
+		zs.X, zs.Y = append(zs.X, p.Position.Z), append(zs.Y, p.Velocity.Z)
+		index = append(index, i)
+	}
+	return xs, zs, index
+}
+
+// drawPhaseSpace draws the x–vx and z–vz scatter plots side by side in the
+// bottom-left corner, each particle in its color
+func drawPhaseSpace(frame *simulation.Frame, colors []rl.Color) {
+	xs, zs, index := phaseScatters(frame)
+	x := float64(10 + plotMargin/2)
+	y := float64(cfg.ScreenHeight - phasePlotSize - 10)
+	for _, scatter := range []*plot.Scatter{xs, zs} {
+		drawScatter(scatter, plot.Rect{X: x, Y: y, W: phasePlotSize, H: phasePlotSize}, func(i int) rl.Color {
+			if particle := index[i]; particle < len(colors) {
+				return colors[particle]
+			}
+			return rl.RayWhite
+		})
+		x += phasePlotSize + plotMargin/2 + 10
+	}
+}
+
+// drawScatter draws a scatter plot with its title, axes and axis labels
+// into area, point i in color(i)
+func drawScatter(scatter *plot.Scatter, area plot.Rect, color func(i int) rl.Color) {
+	layout := scatter.Layout(area, plotMargin, plotTicks)
+	p := layout.Plot
+	drawPlotFrame(scatter.Title, area, p, layout.XTicks, layout.YTicks)
+	rl.DrawText(scatter.XLabel, int32(p.X+p.W)-6, int32(p.Y+p.H+18), plotFontSize, rl.LightGray)
+	rl.DrawText(scatter.YLabel, int32(p.X)-plotMargin+4, int32(p.Y), plotFontSize, rl.LightGray)
+
+	for k, point := range layout.Points {
+		rl.DrawRectangle(int32(point.X)-phasePointSize/2, int32(point.Y)-phasePointSize/2, phasePointSize, phasePointSize, color(layout.Index[k]))
+	}
+}
@@ -0,0 +1,27 @@
+//go:build !js
+
+package main
+
+import (
+	"relativity_simulation_2d/internal/physics"
+	"relativity_simulation_2d/internal/simulation"
+	"testing"
+)
+
+// TestPhaseScatters tests the phase-space points and their sub-sampling
+func TestPhaseScatters(t *testing.T) {
+	frame := &simulation.Frame{Particles: []physics.Particle{
+		*physics.NewParticle(1, 1, 0, 2, 3, 0, 4),
+		*physics.NewParticle(1, -1, 0, -2, -3, 0, -4),
+	}}
+	xs, zs, index := phaseScatters(frame)
+	if xs.Len() != 2 || xs.X[0] != 1 || xs.Y[0] != 3 || zs.X[1] != -2 || zs.Y[1] != -4 || index[1] != 1 {
+		t.Errorf("Unexpected points x %v/%v z %v/%v", xs.X, xs.Y, zs.X, zs.Y)
+	}
+
+	frame.Particles = make([]physics.Particle, 3*phaseMaxPoints+1)
+	xs, _, index = phaseScatters(frame)
+	if xs.Len() > phaseMaxPoints || index[1] != 4 {
+		t.Errorf("Expected at most %d points at stride 4, got %d from %v", phaseMaxPoints, xs.Len(), index[:2])
+	}
+}
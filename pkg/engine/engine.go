@@ -0,0 +1,166 @@
+// Package engine is the simulation without a window: a Go program, such as a
+// notebook or a parameter study, creates an Engine, steps it and reads the
+// particles and fields back. It builds without raylib or OpenGL and always
+// runs on the CPU
+package engine
+
+import (
+	"fmt"
+	"relativity_simulation_2d/internal/config"
+	"relativity_simulation_2d/internal/importer"
+	"relativity_simulation_2d/internal/physics"
+	"relativity_simulation_2d/internal/simulation"
+)
+
+// Types shared with the simulation packages, so that callers outside this
+// module can name them
+type (
+	Config      = config.Config
+	Particle    = physics.Particle
+	Vec3        = physics.Vec3
+	Grid        = physics.Grid
+	Force       = physics.Force
+	Diagnostics = physics.Diagnostics
+)
+
+// DefaultConfig returns the configuration the application starts with
+func DefaultConfig() *Config {
+	return config.DefaultConfig()
+}
+
+// Fields are the grids of the last step, indexed [x][z]
+type Fields struct {
+	Potential   Grid // Gravitational potential Φ
+	MassDensity Grid // Mass density ρ
+	AccelX      Grid // X component of the acceleration -∇Φ
+	AccelZ      Grid // Z component of the acceleration -∇Φ
+}
+
+// Observer is called after a step with the number of completed steps
+type Observer func(step int64, e *Engine)
+
+// Engine steps a simulation on the CPU. It is not safe for concurrent use
+type Engine struct {
+	sim       *simulation.Simulation
+	forces    []Force
+	observers simulation.Observers
+}
+
+// New creates an engine with the particles cfg asks for: imported from
+// cfg.ImportPath, the configured scenario, or random ones placed by cfg.Seed
+func New(cfg *Config) (*Engine, error) {
+	return NewWithParticles(cfg, nil)
+}
+
+// NewWithParticles creates an engine starting from the given particles. The
+// engine copies them; nil falls back to the particles New creates. The
+// engine also copies cfg and applies all of it to its own simulation, so
+// engines with different configurations run side by side and later changes
+// to cfg do not reach a running engine
+func NewWithParticles(cfg *Config, particles []Particle) (*Engine, error) {
+	if cfg == nil {
+		cfg = DefaultConfig()
+	}
+	cfg = cfg.Clone()
+	if err := cfg.Validate(); err != nil {
+		return nil, err
+	}
+	var initial []*physics.Particle
+	switch {
+	case particles != nil:
+		values := make([]Particle, len(particles))
+		copy(values, particles)
+		initial = make([]*physics.Particle, len(values))
+		for i := range values {
+			initial[i] = &values[i]
+		}
+	case cfg.ImportPath != "":
+		format, err := importer.ParseFormat(cfg.ImportFormat)
+		if err != nil {
+			return nil, err
+		}
+		if initial, err = importer.Load(cfg.ImportPath, format, importer.Options{PlaneXY: cfg.ImportPlaneXY}); err != nil {
+			return nil, fmt.Errorf("failed to load initial conditions: %v", err)
+		}
+		cfg.NumParticles = len(initial)
+		if err := cfg.Validate(); err != nil { // The imported count may exceed solver limits
+			return nil, err
+		}
+	}
+	return &Engine{sim: simulation.NewSimulationWithParticles(cfg, initial)}, nil
+}
+
+// RegisterForce adds a force on top of gravity. Forces are kicked for half
+// a step before and after each gravity step, in registration order
+func (e *Engine) RegisterForce(f Force) {
+	e.forces = append(e.forces, f)
+}
+
+// RegisterObserver calls fn after every step whose number is a multiple of
+// stride (stride < 1 = every step), e.g. to record a measurement. Observers
+// run in registration order and see the state after the forces' final kick
+func (e *Engine) RegisterObserver(stride int64, fn Observer) {
+	e.observers.Register(stride, func(step int64) { fn(step, e) })
+}
+
+// Step advances the simulation by dt
+func (e *Engine) Step(dt float32) {
+	physics.KickForces(e.sim.Particles, e.forces, float64(dt)/2)
+	e.sim.Step(dt)
+	physics.KickForces(e.sim.Particles, e.forces, float64(dt)/2)
+	e.observers.Notify(e.sim.GetStepCount())
+}
+
+// Run advances the simulation by steps steps of cfg.FixedTimeStep
+func (e *Engine) Run(steps int) {
+	for i := 0; i < steps; i++ {
+		e.Step(e.sim.Config.FixedTimeStep)
+	}
+}
+
+// Particles returns a copy of the particles
+func (e *Engine) Particles() []Particle {
+	particles := make([]Particle, len(e.sim.Particles))
+	for i, p := range e.sim.Particles {
+		particles[i] = *p
+	}
+	return particles
+}
+
+// Fields returns a copy of the grids of the last step. They are zero before
+// the first step
+func (e *Engine) Fields() Fields {
+	return Fields{
+		Potential:   copyGrid(e.sim.PotentialGrid),
+		MassDensity: copyGrid(e.sim.MassDensityGrid),
+		AccelX:      copyGrid(e.sim.AccelFieldX),
+		AccelZ:      copyGrid(e.sim.AccelFieldZ),
+	}
+}
+
+// Diagnostics returns the conserved quantities and shape of the particles
+func (e *Engine) Diagnostics() Diagnostics {
+	return physics.ComputeDiagnostics(e.sim.Particles)
+}
+
+// StepCount returns the number of completed steps
+func (e *Engine) StepCount() int64 {
+	return e.sim.GetStepCount()
+}
+
+// Time returns the elapsed simulation time
+func (e *Engine) Time() float64 {
+	return e.sim.GetSimTime()
+}
+
+// Config returns the engine's copy of its configuration
+func (e *Engine) Config() *Config {
+	return e.sim.Config
+}
+
+// copyGrid returns a copy of g
+func copyGrid(g Grid) Grid {
+	c := physics.NewGrid(g.Width(), g.Height())
+	physics.CopyGrid(c, g)
+	return c
+}
@@ -0,0 +1,175 @@
+package engine
+
+import (
+	"math"
+	"relativity_simulation_2d/internal/physics"
+	"testing"
+)
+
+// push is a uniform acceleration along X
+type push struct {
+	ax float64
+}
+
+// Kick adds ax·dt to each X velocity
+func (p push) Kick(particles []*Particle, dt float64) {
+	for _, particle := range particles {
+		particle.Velocity.X += p.ax * dt
+	}
+}
+
+// testConfig returns a small deterministic configuration
+func testConfig() *Config {
+	cfg := DefaultConfig()
+	cfg.SimulationWidth = 32
+	cfg.SimulationDepth = 32
+	cfg.NumParticles = 50
+	cfg.Seed = 1
+	return cfg
+}
+
+// TestEngine tests stepping, observers and reading the state back
+func TestEngine(t *testing.T) {
+	e, err := New(testConfig())
+	if err != nil {
+		t.Fatalf("New failed: %v", err)
+	}
+	var steps, strided []int64
+	e.RegisterObserver(0, func(step int64, e *Engine) { steps = append(steps, step) })
+	e.RegisterObserver(2, func(step int64, e *Engine) { strided = append(strided, step) })
+
+	e.Run(3)
+	if len(steps) != 3 || steps[2] != 3 || e.StepCount() != 3 {
+		t.Fatalf("Expected observers after each of 3 steps, got %v", steps)
+	}
+	if len(strided) != 1 || strided[0] != 2 {
+		t.Errorf("Expected the strided observer after step 2 only, got %v", strided)
+	}
+	if want := 3 * float64(e.Config().FixedTimeStep); math.Abs(e.Time()-want) > 1e-6 {
+		t.Errorf("Expected time %g, got %g", want, e.Time())
+	}
+
+	fields := e.Fields()
+	if fields.Potential.Width() != 32 || fields.MassDensity.Height() != 32 {
+		t.Fatalf("Expected 32x32 fields, got %dx%d", fields.Potential.Width(), fields.Potential.Height())
+	}
+	if mass := e.Diagnostics().TotalMass; math.Abs(fields.MassDensity.Sum()-mass) > 1e-6*mass {
+		t.Errorf("Expected the density to hold the total mass %g, got %g", mass, fields.MassDensity.Sum())
+	}
+
+	particles := e.Particles()
+	particles[0].Position.X += 100
+	if e.Particles()[0].Position == particles[0].Position {
+		t.Error("Expected Particles to return a copy")
+	}
+
+	cfg := testConfig()
+	cfg.SimulationWidth = -1
+	if _, err := New(cfg); err == nil {
+		t.Error("Expected an error for an invalid config")
+	}
+}
+
+// TestRegisterForce tests that a registered force kicks every particle by a
+// full step each step
+func TestRegisterForce(t *testing.T) {
+	cfg := testConfig()
+	cfg.GravitationalConstant = 0
+	start := []Particle{{Mass: 1}, {Position: Vec3{X: 5, Z: -3}, Velocity: Vec3{X: 0.5}, Mass: 2}}
+	e, err := NewWithParticles(cfg, start)
+	if err != nil {
+		t.Fatalf("NewWithParticles failed: %v", err)
+	}
+	e.RegisterForce(push{ax: 2})
+
+	const dt = 0.01
+	for i := 0; i < 10; i++ {
+		e.Step(dt)
+	}
+	for i, p := range e.Particles() {
+		if want := start[i].Velocity.X + 2*10*dt; math.Abs(p.Velocity.X-want) > 1e-6 {
+			t.Errorf("Particle %d: expected vx %g, got %g", i, want, p.Velocity.X)
+		}
+	}
+	if start[1].Velocity.X != 0.5 {
+		t.Error("Expected the engine to copy the initial particles")
+	}
+}
+
+// run steps a new engine for cfg steps times and returns its particles
+func run(t *testing.T, cfg *Config, steps int) []Particle {
+	t.Helper()
+	e, err := New(cfg)
+	if err != nil {
+		t.Fatalf("New failed: %v", err)
+	}
+	e.Run(steps)
+	return e.Particles()
+}
+
+// TestEnginesSideBySide tests that two engines with different settings,
+// stepped in turn, each follow the run they make alone
+func TestEnginesSideBySide(t *testing.T) {
+	plain := testConfig()
+	tuned := testConfig()
+	tuned.CellSize = 0.5
+	tuned.Deterministic = true
+	tuned.SpongeWidth = 4
+	tuned.FrameOmega = 0.05
+
+	wantPlain, wantTuned := run(t, plain, 5), run(t, tuned, 5)
+	a, err := New(plain)
+	if err != nil {
+		t.Fatalf("New failed: %v", err)
+	}
+	b, err := New(tuned)
+	if err != nil {
+		t.Fatalf("New failed: %v", err)
+	}
+	plain.CellSize = 4 // Changing the caller's config must not reach the engines
+	for i := 0; i < 5; i++ {
+		a.Step(a.Config().FixedTimeStep)
+		b.Step(b.Config().FixedTimeStep)
+	}
+
+	for i, p := range a.Particles() {
+		if p != wantPlain[i] {
+			t.Fatalf("Particle %d of the plain engine differs beside the tuned one", i)
+		}
+	}
+	for i, p := range b.Particles() {
+		if p != wantTuned[i] {
+			t.Fatalf("Particle %d of the tuned engine differs beside the plain one", i)
+		}
+	}
+	if wantPlain[0].Position == wantTuned[0].Position {
+		t.Error("Expected the tuned settings to change the run")
+	}
+}
+
+// TestEngineDeterministic tests that cfg.Deterministic switches the engine's
+// own deposits to fixed point
+func TestEngineDeterministic(t *testing.T) {
+	cfg := testConfig()
+	cfg.Deterministic = true
+	e, err := New(cfg)
+	if err != nil {
+		t.Fatalf("New failed: %v", err)
+	}
+	e.Run(2)
+
+	values := e.Particles()
+	particles := make([]*physics.Particle, len(values))
+	for i := range values {
+		particles[i] = &values[i]
+	}
+	want := physics.DepositMassToGrid(particles, 32, 32, &physics.Mesh{Deterministic: true})
+	got := e.Fields().MassDensity
+	for i := range want {
+		for j := range want[i] {
+			if math.Float64bits(got[i][j]) != math.Float64bits(want[i][j]) {
+				t.Fatalf("Cell (%d, %d) is %g, fixed-point deposit %g", i, j, got[i][j], want[i][j])
+			}
+		}
+	}
+}
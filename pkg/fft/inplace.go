@@ -0,0 +1,170 @@
+package fft
+
+import "math"
+
+// radix2Plan holds precomputed bit-reversal indices and twiddle factors for one length
+type radix2Plan struct {
+	n         int
+	rev       []int
+	twiddle   []complex128 // exp(-2πik/n) for k < n/2
+	twiddle32 []complex64  // twiddle rounded to single precision
+}
+
+// columnScratch is reusable storage for gathering one grid column
+type columnScratch struct {
+	data []complex128
+}
+
+// columnScratch32 is reusable single-precision storage for gathering one grid column
+type columnScratch32 struct {
+	data []complex64
+}
+
+// IsPowerOfTwo reports whether n is a positive power of two
+func IsPowerOfTwo(n int) bool {
+	return n > 0 && n&(n-1) == 0
+}
+
+// newRadix2Plan creates the plan for length n (a power of two). Plans are
+// only kept by the 2D plans of a PlanCache, so they go with them on eviction
+func newRadix2Plan(n int) *radix2Plan {
+	p := &radix2Plan{n: n, rev: make([]int, n), twiddle: make([]complex128, n/2), twiddle32: make([]complex64, n/2)}
+	bits := 0
+	for 1<<bits < n {
+		bits++
+	}
+	for i := range p.rev {
+		r := 0
+		for b := 0; b < bits; b++ {
+			if i&(1<<b) != 0 {
+				r |= 1 << (bits - 1 - b)
+			}
+		}
+		p.rev[i] = r
+	}
+	for k := range p.twiddle {
+		angle := -2 * math.Pi * float64(k) / float64(n)
+		p.twiddle[k] = complex(math.Cos(angle), math.Sin(angle))
+		p.twiddle32[k] = complex64(p.twiddle[k])
+	}
+
+	return p
+}
+
+// transform runs an unnormalized iterative Cooley-Tukey FFT on x in place
+func (p *radix2Plan) transform(x []complex128, inverse bool) {
+	for i, r := range p.rev {
+		if i < r {
+			x[i], x[r] = x[r], x[i]
+		}
+	}
+
+	for size := 2; size <= p.n; size <<= 1 {
+		half := size / 2
+		step := p.n / size
+		for start := 0; start < p.n; start += size {
+			for k := 0; k < half; k++ {
+				w := p.twiddle[k*step]
+				if inverse {
+					w = complex(real(w), -imag(w))
+				}
+				// Multiply with each product rounded, so that no
+				// architecture fuses it into the butterfly's add
+				a, b := x[start+k], x[start+k+half]
+				tr := float64(real(b)*real(w)) - float64(imag(b)*imag(w))
+				ti := float64(real(b)*imag(w)) + float64(imag(b)*real(w))
+				x[start+k] = complex(real(a)+tr, imag(a)+ti)
+				x[start+k+half] = complex(real(a)-tr, imag(a)-ti)
+			}
+		}
+	}
+}
+
+// Transform2DInPlace performs a 2D FFT (or inverse FFT, normalized by 1/N) on
+// grid in place. Power-of-two grids use the plan for their size from Plans,
+// so repeated calls do not allocate; other sizes fall back to the CPU
+// processor and copy the result back
+func Transform2DInPlace(grid [][]complex128, inverse bool) {
+	width := len(grid)
+	if width == 0 {
+		return
+	}
+	height := len(grid[0])
+
+	if !IsPowerOfTwo(width) || !IsPowerOfTwo(height) {
+		processor := NewFFTProcessor()
+		var result [][]complex128
+		if inverse {
+			result = processor.IFFT2D(grid)
+		} else {
+			result = processor.FFT2D(grid)
+		}
+		for i := range grid {
+			copy(grid[i], result[i])
+		}
+		return
+	}
+
+	Plans.Get(width, height, Double).Transform(grid, inverse)
+}
+
+// transform32 is the single-precision variant of transform
+func (p *radix2Plan) transform32(x []complex64, inverse bool) {
+	for i, r := range p.rev {
+		if i < r {
+			x[i], x[r] = x[r], x[i]
+		}
+	}
+
+	for size := 2; size <= p.n; size <<= 1 {
+		half := size / 2
+		step := p.n / size
+		for start := 0; start < p.n; start += size {
+			for k := 0; k < half; k++ {
+				// Multiply in explicit float32 arithmetic; complex64 products
+				// are otherwise computed via float64
+				w := p.twiddle32[k*step]
+				wr, wi := real(w), imag(w)
+				if inverse {
+					wi = -wi
+				}
+				a := x[start+k]
+				b := x[start+k+half]
+				br, bi := real(b), imag(b)
+				tr := float32(br*wr) - float32(bi*wi)
+				ti := float32(br*wi) + float32(bi*wr)
+				x[start+k] = complex(real(a)+tr, imag(a)+ti)
+				x[start+k+half] = complex(real(a)-tr, imag(a)-ti)
+			}
+		}
+	}
+}
+
+// Transform2DInPlace32 is the single-precision variant of Transform2DInPlace.
+// Non-power-of-two grids are transformed in double precision and rounded back
+func Transform2DInPlace32(grid [][]complex64, inverse bool) {
+	width := len(grid)
+	if width == 0 {
+		return
+	}
+	height := len(grid[0])
+
+	if !IsPowerOfTwo(width) || !IsPowerOfTwo(height) {
+		wide := make([][]complex128, width)
+		for i := range grid {
+			wide[i] = make([]complex128, height)
+			for j, v := range grid[i] {
+				wide[i][j] = complex128(v)
+			}
+		}
+		Transform2DInPlace(wide, inverse)
+		for i := range grid {
+			for j, v := range wide[i] {
+				grid[i][j] = complex64(v)
+			}
+		}
+		return
+	}
+
+	Plans.Get(width, height, Single).Transform32(grid, inverse)
+}
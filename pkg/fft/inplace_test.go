@@ -0,0 +1,123 @@
+package fft
+
+import (
+	"math"
+	"math/cmplx"
+	"math/rand"
+	"testing"
+)
+
+// randomGrid returns a deterministic pseudo-random complex grid
+func randomGrid(width, height int, seed int64) [][]complex128 {
+	r := rand.New(rand.NewSource(seed))
+	grid := make([][]complex128, width)
+	for i := range grid {
+		grid[i] = make([]complex128, height)
+		for j := range grid[i] {
+			grid[i][j] = complex(r.Float64()-0.5, r.Float64()-0.5)
+		}
+	}
+	return grid
+}
+
+// cloneGrid returns a deep copy of grid
+func cloneGrid(grid [][]complex128) [][]complex128 {
+	out := make([][]complex128, len(grid))
+	for i := range grid {
+		out[i] = append([]complex128(nil), grid[i]...)
+	}
+	return out
+}
+
+// TestIsPowerOfTwo tests power-of-two detection
+func TestIsPowerOfTwo(t *testing.T) {
+	for n, want := range map[int]bool{0: false, 1: true, 2: true, 3: false, 64: true, 96: false, -4: false} {
+		if got := IsPowerOfTwo(n); got != want {
+			t.Errorf("IsPowerOfTwo(%d) = %v, want %v", n, got, want)
+		}
+	}
+}
+
+// TestTransform2DInPlaceMatchesProcessor tests agreement with the CPU processor
+func TestTransform2DInPlaceMatchesProcessor(t *testing.T) {
+	processor := NewFFTProcessor()
+
+	for _, size := range [][2]int{{8, 8}, {16, 4}, {32, 64}, {6, 10}} {
+		input := randomGrid(size[0], size[1], 1)
+
+		forward := cloneGrid(input)
+		Transform2DInPlace(forward, false)
+		expected := processor.FFT2D(input)
+		for i := range forward {
+			for j := range forward[i] {
+				if !complexApproxEqual(forward[i][j], expected[i][j], 1e-9) {
+					t.Fatalf("%dx%d forward mismatch at (%d,%d): %v vs %v", size[0], size[1], i, j, forward[i][j], expected[i][j])
+				}
+			}
+		}
+
+		Transform2DInPlace(forward, true)
+		for i := range forward {
+			for j := range forward[i] {
+				if !complexApproxEqual(forward[i][j], input[i][j], 1e-9) {
+					t.Fatalf("%dx%d round trip mismatch at (%d,%d)", size[0], size[1], i, j)
+				}
+			}
+		}
+	}
+}
+
+// TestTransform2DInPlaceNoAllocs tests that power-of-two transforms reuse plans and scratch
+func TestTransform2DInPlaceNoAllocs(t *testing.T) {
+	grid := randomGrid(64, 64, 2)
+	Transform2DInPlace(grid, false) // Warm the plan cache
+
+	allocs := testing.AllocsPerRun(10, func() {
+		Transform2DInPlace(grid, false)
+		Transform2DInPlace(grid, true)
+	})
+	if allocs > 1 {
+		t.Errorf("Expected no steady-state allocations, got %.1f per run", allocs)
+	}
+}
+
+// TestTransform2DInPlace32Accuracy tests single-precision error against double precision
+func TestTransform2DInPlace32Accuracy(t *testing.T) {
+	for _, size := range [][2]int{{64, 64}, {12, 8}} {
+		input := randomGrid(size[0], size[1], 3)
+
+		expected := cloneGrid(input)
+		Transform2DInPlace(expected, false)
+
+		narrow := make([][]complex64, len(input))
+		for i := range input {
+			narrow[i] = make([]complex64, len(input[i]))
+			for j, v := range input[i] {
+				narrow[i][j] = complex64(v)
+			}
+		}
+		Transform2DInPlace32(narrow, false)
+
+		var maxErr, maxMag float64
+		for i := range expected {
+			for j := range expected[i] {
+				maxErr = math.Max(maxErr, cmplx.Abs(complex128(narrow[i][j])-expected[i][j]))
+				maxMag = math.Max(maxMag, cmplx.Abs(expected[i][j]))
+			}
+		}
+		relErr := maxErr / maxMag
+		t.Logf("%dx%d float32 FFT max relative error: %.2e", size[0], size[1], relErr)
+		if relErr > 1e-5 {
+			t.Errorf("float32 FFT error too large: %.2e", relErr)
+		}
+
+		Transform2DInPlace32(narrow, true)
+		for i := range input {
+			for j := range input[i] {
+				if cmplx.Abs(complex128(narrow[i][j])-input[i][j]) > 1e-5 {
+					t.Fatalf("%dx%d float32 round trip mismatch at (%d,%d)", size[0], size[1], i, j)
+				}
+			}
+		}
+	}
+}
@@ -0,0 +1,184 @@
+package fft
+
+import "sync"
+
+// DefaultPlanCapacity is the number of 2D plans a PlanCache keeps: enough for
+// a few grid sizes in use at once, such as the full grid, a coarse preview
+// grid and a region of interest, at both precisions
+const DefaultPlanCapacity = 8
+
+// Precision is the floating-point precision a 2D plan transforms in
+type Precision int
+
+const (
+	Double Precision = iota // complex128 grids
+	Single                  // complex64 grids
+)
+
+// PlanKey identifies a 2D plan by grid size and precision
+type PlanKey struct {
+	Width, Height int
+	Precision     Precision
+}
+
+// Plan2D transforms power-of-two grids of one size and precision: it holds
+// the row and column plans, with their bit-reversal tables and twiddle
+// factors, and pools the scratch the columns are gathered into
+type Plan2D struct {
+	Key     PlanKey
+	rows    *radix2Plan
+	columns *radix2Plan
+	scratch sync.Pool // *columnScratch or *columnScratch32 of Width elements
+}
+
+// newPlan2D creates the plan for key; both dimensions must be powers of two.
+// Square grids share one plan between rows and columns
+func newPlan2D(key PlanKey) *Plan2D {
+	p := &Plan2D{Key: key, rows: newRadix2Plan(key.Height)}
+	p.columns = p.rows
+	if key.Width != key.Height {
+		p.columns = newRadix2Plan(key.Width)
+	}
+	if key.Precision == Single {
+		p.scratch.New = func() any { return &columnScratch32{data: make([]complex64, key.Width)} }
+	} else {
+		p.scratch.New = func() any { return &columnScratch{data: make([]complex128, key.Width)} }
+	}
+	return p
+}
+
+// Transform runs the 2D FFT (or inverse FFT, normalized by 1/N) on grid in
+// place. The plan must be of Double precision and grid of its size
+func (p *Plan2D) Transform(grid [][]complex128, inverse bool) {
+	// Rows are contiguous and transform directly
+	for i := range grid {
+		p.rows.transform(grid[i], inverse)
+	}
+
+	// Columns are gathered into scratch, transformed and scattered back
+	scratch := p.scratch.Get().(*columnScratch)
+	column := scratch.data
+	for j := 0; j < p.Key.Height; j++ {
+		for i := range column {
+			column[i] = grid[i][j]
+		}
+		p.columns.transform(column, inverse)
+		for i := range column {
+			grid[i][j] = column[i]
+		}
+	}
+	p.scratch.Put(scratch)
+
+	if inverse {
+		scale := complex(1/float64(p.Key.Width*p.Key.Height), 0)
+		for i := range grid {
+			row := grid[i]
+			for j := range row {
+				row[j] *= scale
+			}
+		}
+	}
+}
+
+// Transform32 is the single-precision variant of Transform, for plans of
+// Single precision
+func (p *Plan2D) Transform32(grid [][]complex64, inverse bool) {
+	for i := range grid {
+		p.rows.transform32(grid[i], inverse)
+	}
+
+	scratch := p.scratch.Get().(*columnScratch32)
+	column := scratch.data
+	for j := 0; j < p.Key.Height; j++ {
+		for i := range column {
+			column[i] = grid[i][j]
+		}
+		p.columns.transform32(column, inverse)
+		for i := range column {
+			grid[i][j] = column[i]
+		}
+	}
+	p.scratch.Put(scratch)
+
+	if inverse {
+		scale := float32(1 / float64(p.Key.Width*p.Key.Height))
+		for i := range grid {
+			row := grid[i]
+			for j, v := range row {
+				row[j] = complex(real(v)*scale, imag(v)*scale)
+			}
+		}
+	}
+}
+
+// PlanCacheStats counts the lookups of a plan cache
+type PlanCacheStats struct {
+	Plans  int // Plans held
+	Hits   int // Lookups that found their plan
+	Misses int // Lookups that created one
+}
+
+// PlanCache keeps the 2D plans of recently used grid sizes, so switching
+// between sizes at runtime reuses their setup. Beyond its capacity the least
+// recently used plan is dropped. It is safe for concurrent use
+type PlanCache struct {
+	mu       sync.Mutex
+	capacity int
+	plans    map[PlanKey]*Plan2D
+	order    []PlanKey // Least recently used first
+	stats    PlanCacheStats
+}
+
+// Plans is the cache Transform2DInPlace and Transform2DInPlace32 take their plans from
+var Plans = NewPlanCache(DefaultPlanCapacity)
+
+// NewPlanCache creates a cache of up to capacity plans (DefaultPlanCapacity if not positive)
+func NewPlanCache(capacity int) *PlanCache {
+	if capacity <= 0 {
+		capacity = DefaultPlanCapacity
+	}
+	return &PlanCache{capacity: capacity, plans: make(map[PlanKey]*Plan2D)}
+}
+
+// Get returns the plan for width×height grids at precision, creating it on
+// first use. Both dimensions must be powers of two
+func (c *PlanCache) Get(width, height int, precision Precision) *Plan2D {
+	key := PlanKey{Width: width, Height: height, Precision: precision}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if p, ok := c.plans[key]; ok {
+		c.stats.Hits++
+		c.touch(key)
+		return p
+	}
+
+	c.stats.Misses++
+	if len(c.order) >= c.capacity {
+		delete(c.plans, c.order[0])
+		c.order = c.order[1:]
+	}
+	p := newPlan2D(key)
+	c.plans[key] = p
+	c.order = append(c.order, key)
+	return p
+}
+
+// touch moves key to the most recently used end of the order
+func (c *PlanCache) touch(key PlanKey) {
+	for i, k := range c.order {
+		if k == key {
+			copy(c.order[i:], c.order[i+1:])
+			c.order[len(c.order)-1] = key
+			return
+		}
+	}
+}
+
+// Stats returns the number of plans held and the lookups so far
+func (c *PlanCache) Stats() PlanCacheStats {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	stats := c.stats
+	stats.Plans = len(c.plans)
+	return stats
+}
@@ -0,0 +1,87 @@
+package fft
+
+import (
+	"math/cmplx"
+	"runtime"
+	"testing"
+	"weak"
+)
+
+// TestPlanCacheEviction tests reusing plans by size and precision and
+// dropping the least recently used one beyond the capacity
+func TestPlanCacheEviction(t *testing.T) {
+	c := NewPlanCache(2)
+	full := c.Get(64, 64, Double)
+	if c.Get(64, 64, Double) != full {
+		t.Fatal("Expected the same plan for the same key")
+	}
+	single := c.Get(64, 64, Single)
+	if single == full {
+		t.Fatal("Expected a separate plan per precision")
+	}
+
+	c.Get(64, 64, Double) // The single-precision plan is now the least recently used
+	c.Get(32, 32, Double)
+	if stats := c.Stats(); stats.Plans != 2 || stats.Hits != 2 || stats.Misses != 3 {
+		t.Fatalf("Unexpected stats %+v", stats)
+	}
+	if c.Get(64, 64, Double) != full {
+		t.Error("Expected the recently used plan to be kept")
+	}
+	if c.Get(64, 64, Single) == single {
+		t.Error("Expected the least recently used plan to be dropped")
+	}
+}
+
+// TestPlanCacheEvictionReleases tests that an evicted plan's row and column
+// plans are not kept anywhere else, so the garbage collector frees them
+func TestPlanCacheEvictionReleases(t *testing.T) {
+	c := NewPlanCache(1)
+	plan := c.Get(128, 64, Double)
+	rows, columns := weak.Make(plan.rows), weak.Make(plan.columns)
+
+	runtime.GC()
+	if rows.Value() == nil || columns.Value() == nil {
+		t.Fatal("Expected the cached plan to keep its row and column plans")
+	}
+	c.Get(32, 32, Double)
+	runtime.GC()
+	if rows.Value() != nil || columns.Value() != nil {
+		t.Error("Expected the evicted plan's row and column plans to be freed")
+	}
+}
+
+// TestTransform2DInPlaceSwitchingSizes tests that alternating grid sizes
+// keeps results exact and reuses the scratch of each size
+func TestTransform2DInPlaceSwitchingSizes(t *testing.T) {
+	full, coarse := randomGrid(64, 32, 3), randomGrid(32, 16, 4)
+	wantFull, wantCoarse := cloneGrid(full), cloneGrid(coarse)
+	for i := 0; i < 3; i++ {
+		Transform2DInPlace(full, false)
+		Transform2DInPlace(full, true)
+		Transform2DInPlace(coarse, false)
+		Transform2DInPlace(coarse, true)
+	}
+	for i := range full {
+		for j := range full[i] {
+			if cmplx.Abs(full[i][j]-wantFull[i][j]) > 1e-9 {
+				t.Fatalf("Round trip of the full grid differs at (%d, %d)", i, j)
+			}
+		}
+	}
+	for i := range coarse {
+		for j := range coarse[i] {
+			if cmplx.Abs(coarse[i][j]-wantCoarse[i][j]) > 1e-9 {
+				t.Fatalf("Round trip of the coarse grid differs at (%d, %d)", i, j)
+			}
+		}
+	}
+
+	allocs := testing.AllocsPerRun(10, func() {
+		Transform2DInPlace(full, false)
+		Transform2DInPlace(coarse, false)
+	})
+	if allocs > 1 {
+		t.Errorf("Expected no steady-state allocations when switching sizes, got %.1f per run", allocs)
+	}
+}
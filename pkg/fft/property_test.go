@@ -0,0 +1,78 @@
+//go:build property
+
+package fft
+
+import (
+	"math"
+	"math/cmplx"
+	"testing"
+
+	"pgregory.net/rapid"
+)
+
+// Property tests are opt-in: go test -tags property ./pkg/fft
+
+// complexGridGen draws a grid of complex values with parts in [-1, 1]; sizes
+// cover both the radix-2 path and the fallback for other sizes
+func complexGridGen() *rapid.Generator[[][]complex128] {
+	return rapid.Custom(func(t *rapid.T) [][]complex128 {
+		width := rapid.SampledFrom([]int{1, 2, 3, 4, 6, 8, 16}).Draw(t, "width")
+		height := rapid.SampledFrom([]int{1, 2, 3, 4, 6, 8, 16}).Draw(t, "height")
+		part := rapid.Float64Range(-1, 1)
+		grid := make([][]complex128, width)
+		for i := range grid {
+			grid[i] = make([]complex128, height)
+			for j := range grid[i] {
+				grid[i][j] = complex(part.Draw(t, "re"), part.Draw(t, "im"))
+			}
+		}
+		return grid
+	})
+}
+
+// copyGrid returns a deep copy of grid
+func copyGrid(grid [][]complex128) [][]complex128 {
+	clone := make([][]complex128, len(grid))
+	for i := range grid {
+		clone[i] = append([]complex128(nil), grid[i]...)
+	}
+	return clone
+}
+
+// TestPropertyRoundTrip tests that the inverse transform undoes the forward one
+func TestPropertyRoundTrip(t *testing.T) {
+	rapid.Check(t, func(t *rapid.T) {
+		input := complexGridGen().Draw(t, "input")
+		grid := copyGrid(input)
+		Transform2DInPlace(grid, false)
+		Transform2DInPlace(grid, true)
+		for i := range input {
+			for j := range input[i] {
+				if d := cmplx.Abs(grid[i][j] - input[i][j]); d > 1e-12 {
+					t.Fatalf("Round trip differs at [%d][%d] by %g", i, j, d)
+				}
+			}
+		}
+	})
+}
+
+// TestPropertyParseval tests that the forward transform scales energy by N
+func TestPropertyParseval(t *testing.T) {
+	rapid.Check(t, func(t *rapid.T) {
+		input := complexGridGen().Draw(t, "input")
+		grid := copyGrid(input)
+		Transform2DInPlace(grid, false)
+
+		var spatial, spectral float64
+		for i := range input {
+			for j := range input[i] {
+				spatial += math.Pow(cmplx.Abs(input[i][j]), 2)
+				spectral += math.Pow(cmplx.Abs(grid[i][j]), 2)
+			}
+		}
+		n := float64(len(input) * len(input[0]))
+		if math.Abs(spectral-n*spatial) > 1e-9*(1+n*spatial) {
+			t.Fatalf("Σ|X|² = %g, want N·Σ|x|² = %g", spectral, n*spatial)
+		}
+	})
+}
@@ -0,0 +1,133 @@
+// Package testkit provides the measurements, scenario builders and
+// assertions used to verify the physics engine, so code embedding the engine
+// can run the same checks against its own integration
+package testkit
+
+import (
+	"math"
+	"math/rand"
+	"relativity_simulation_2d/internal/physics"
+)
+
+// TB is the part of testing.TB the assertions use. Both *testing.T and
+// property-test runners such as *rapid.T satisfy it
+type TB interface {
+	Helper()
+	Errorf(format string, args ...any)
+}
+
+// KineticEnergy returns the total kinetic energy ½ m v² in the x-z plane
+func KineticEnergy(particles []*physics.Particle) float64 {
+	totalKE := 0.0
+	for _, p := range particles {
+		v2 := p.Velocity.X*p.Velocity.X + p.Velocity.Z*p.Velocity.Z
+		totalKE += 0.5 * float64(p.Mass) * v2
+	}
+	return totalKE
+}
+
+// TotalMomentum returns the total momentum in the x-z plane
+func TotalMomentum(particles []*physics.Particle) physics.Vec3 {
+	totalP := physics.NewVec3(0, 0, 0)
+	for _, p := range particles {
+		totalP.X += float64(p.Mass) * p.Velocity.X
+		totalP.Z += float64(p.Mass) * p.Velocity.Z
+	}
+	return totalP
+}
+
+// CenterOfMass returns the mass-weighted mean position (the origin if there
+// is no mass)
+func CenterOfMass(particles []*physics.Particle) physics.Vec3 {
+	totalMass := 0.0
+	com := physics.Vec3{}
+	for _, p := range particles {
+		mass := float64(p.Mass)
+		totalMass += mass
+		com.X += p.Position.X * mass
+		com.Y += p.Position.Y * mass
+		com.Z += p.Position.Z * mass
+	}
+	if totalMass > 0 {
+		com.X /= totalMass
+		com.Y /= totalMass
+		com.Z /= totalMass
+	}
+	return com
+}
+
+// Clone returns a deep copy of particles, so one scenario can be run through
+// several integrators
+func Clone(particles []*physics.Particle) []*physics.Particle {
+	clone := make([]*physics.Particle, len(particles))
+	for i, p := range particles {
+		copied := *p
+		clone[i] = &copied
+	}
+	return clone
+}
+
+// Cluster returns n particles at rest with masses in [0.5, 1.5) and positions
+// normally distributed around the origin with the given spread. The same seed
+// always gives the same particles
+func Cluster(n int, spread float64, seed int64) []*physics.Particle {
+	rng := rand.New(rand.NewSource(seed))
+	particles := make([]*physics.Particle, n)
+	for i := range particles {
+		particles[i] = physics.NewParticle(rng.Float64()+0.5, rng.NormFloat64()*spread, 0, rng.NormFloat64()*spread, 0, 0, 0)
+	}
+	return particles
+}
+
+// Pair returns two particles of equal mass separated along x and moving in
+// opposite z directions at speed, so the total momentum is zero
+func Pair(mass, separation, speed float64) []*physics.Particle {
+	return []*physics.Particle{
+		physics.NewParticle(mass, -separation/2, 0, 0, 0, 0, speed),
+		physics.NewParticle(mass, separation/2, 0, 0, 0, 0, -speed),
+	}
+}
+
+// Uniform returns n particles spread uniformly over a width x depth domain,
+// as the simulation initializes them
+func Uniform(n int, width, depth float64, seed int64) []*physics.Particle {
+	return physics.InitializeParticlesWithSeed(n, width, depth, seed)
+}
+
+// AssertMomentumConserved fails tb if either momentum component changed by
+// more than tolerance
+func AssertMomentumConserved(tb TB, initial, final physics.Vec3, tolerance float64) {
+	tb.Helper()
+	if math.Abs(final.X-initial.X) > tolerance || math.Abs(final.Z-initial.Z) > tolerance {
+		tb.Errorf("Momentum not conserved: initial=%v, final=%v, tolerance=%v", initial, final, tolerance)
+	}
+}
+
+// AssertCenterOfMassFixed fails tb if the center of mass moved by more than tolerance
+func AssertCenterOfMassFixed(tb TB, initial, final physics.Vec3, tolerance float64) {
+	tb.Helper()
+	if drift := final.Sub(initial).Length(); drift > tolerance {
+		tb.Errorf("Center of mass drifted: initial=%v, final=%v, drift=%v", initial, final, drift)
+	}
+}
+
+// AssertEnergyBounded fails tb if the kinetic energy grew beyond limit
+func AssertEnergyBounded(tb TB, kinetic, limit float64) {
+	tb.Helper()
+	if kinetic > limit {
+		tb.Errorf("Energy exploded: KE=%v, limit=%v", kinetic, limit)
+	}
+}
+
+// AssertFinite fails tb for every particle with a NaN or infinite position or velocity
+func AssertFinite(tb TB, particles []*physics.Particle) {
+	tb.Helper()
+	for i, p := range particles {
+		for _, v := range []float64{p.Position.X, p.Position.Y, p.Position.Z, p.Velocity.X, p.Velocity.Y, p.Velocity.Z} {
+			if math.IsNaN(v) || math.IsInf(v, 0) {
+				tb.Errorf("Particle %d is not finite: position=%v, velocity=%v", i, p.Position, p.Velocity)
+				break
+			}
+		}
+	}
+}
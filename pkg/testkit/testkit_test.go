@@ -0,0 +1,95 @@
+package testkit
+
+import (
+	"math"
+	"relativity_simulation_2d/internal/physics"
+	"testing"
+)
+
+// recorder captures assertion failures instead of failing the test
+type recorder struct {
+	failures int
+}
+
+func (r *recorder) Helper() {}
+
+func (r *recorder) Errorf(format string, args ...any) { r.failures++ }
+
+// TestMeasurements tests the conserved quantities of a known pair
+func TestMeasurements(t *testing.T) {
+	particles := Pair(2, 10, 3)
+
+	if ke := KineticEnergy(particles); math.Abs(ke-18) > 1e-12 {
+		t.Errorf("KineticEnergy = %v, want 18", ke)
+	}
+	if p := TotalMomentum(particles); p.X != 0 || p.Z != 0 {
+		t.Errorf("TotalMomentum = %v, want zero", p)
+	}
+	if com := CenterOfMass(particles); com.Length() > 1e-12 {
+		t.Errorf("CenterOfMass = %v, want the origin", com)
+	}
+	if com := CenterOfMass(nil); com.Length() != 0 {
+		t.Errorf("CenterOfMass(nil) = %v, want the origin", com)
+	}
+}
+
+// TestScenarios tests that seeded scenarios are reproducible
+func TestScenarios(t *testing.T) {
+	a, b := Cluster(20, 5, 42), Cluster(20, 5, 42)
+	for i := range a {
+		if *a[i] != *b[i] {
+			t.Fatalf("Cluster particle %d differs between runs with the same seed", i)
+		}
+	}
+	if c := Cluster(20, 5, 43); *c[0] == *a[0] {
+		t.Error("Expected a different seed to give different particles")
+	}
+
+	u := Uniform(10, 100, 100, 7)
+	if len(u) != 10 {
+		t.Errorf("Expected 10 particles, got %d", len(u))
+	}
+
+	clone := Clone(a)
+	clone[0].Position.X += 1
+	if a[0].Position.X == clone[0].Position.X {
+		t.Error("Clone shares particles with the original")
+	}
+}
+
+// TestAssertions tests that the assertions pass and fail at their tolerances
+func TestAssertions(t *testing.T) {
+	tests := []struct {
+		name string
+		fn   func(tb TB)
+		want int
+	}{
+		{"momentum within tolerance", func(tb TB) {
+			AssertMomentumConserved(tb, physics.NewVec3(1, 0, 1), physics.NewVec3(1.05, 0, 0.95), 0.1)
+		}, 0},
+		{"momentum drift", func(tb TB) {
+			AssertMomentumConserved(tb, physics.NewVec3(1, 0, 1), physics.NewVec3(1, 0, 2), 0.1)
+		}, 1},
+		{"center of mass drift", func(tb TB) {
+			AssertCenterOfMassFixed(tb, physics.NewVec3(0, 0, 0), physics.NewVec3(0.3, 0, 0.4), 0.1)
+		}, 1},
+		{"bounded energy", func(tb TB) { AssertEnergyBounded(tb, 5, 10) }, 0},
+		{"exploded energy", func(tb TB) { AssertEnergyBounded(tb, 50, 10) }, 1},
+		{"non-finite particles", func(tb TB) {
+			AssertFinite(tb, []*physics.Particle{
+				physics.NewParticle(1, math.NaN(), 0, 0, 0, 0, 0),
+				physics.NewParticle(1, 0, 0, 0, 0, 0, 0),
+				physics.NewParticle(1, 0, 0, 0, math.Inf(1), math.NaN(), 0),
+			})
+		}, 2},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r := &recorder{}
+			tt.fn(r)
+			if r.failures != tt.want {
+				t.Errorf("Expected %d failures, got %d", tt.want, r.failures)
+			}
+		})
+	}
+}
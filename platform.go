@@ -0,0 +1,11 @@
+//go:build !js && !android
+
+package main
+
+import "relativity_simulation_2d/internal/config"
+
+// applyPlatformDefaults adjusts the defaults for the platform; desktop uses them as they are
+func applyPlatformDefaults(cfg *config.Config) {}
+
+// platformWindowOpened adjusts the configuration once the window is open; desktop needs nothing
+func platformWindowOpened(cfg *config.Config) {}
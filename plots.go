@@ -0,0 +1,124 @@
+//go:build !js
+
+package main
+
+import (
+	"fmt"
+	rl "github.com/gen2brain/raylib-go/raylib"
+	"relativity_simulation_2d/internal/gpu"
+	"relativity_simulation_2d/internal/physics"
+	"relativity_simulation_2d/internal/plot"
+)
+
+// Diagnostics plot panel settings
+const (
+	plotInterval = 1.0 // Wall-clock seconds between samples
+	plotSamples  = 120 // Samples kept per series
+	plotWidth    = 420
+	plotHeight   = 180
+	plotMargin   = 50 // Space for tick labels left of and below each chart
+	plotTicks    = 5
+	plotFontSize = 10
+)
+
+// diagnosticsPlots holds the time-series charts shown in the plot panel
+type diagnosticsPlots struct {
+	energy    *plot.Chart
+	virial    *plot.Chart
+	potential gpu.PotentialRange // Extremes of Φ at the last sample
+	profiles  profilePanel       // Radial profiles, sampled only while shown
+	groups    groupPanel         // Group diagnostics, sampled while there are groups
+}
+
+// newDiagnosticsPlots creates the energy and virial ratio charts in the UI palette
+func newDiagnosticsPlots() *diagnosticsPlots {
+	scheme := ui.GetColorScheme()
+	return &diagnosticsPlots{
+		energy: plot.NewChart("Energy", plotInterval,
+			plot.NewSeries("KE", raylibColor(scheme.PlotKinetic), plotSamples),
+			plot.NewSeries("PE", raylibColor(scheme.PlotPotential), plotSamples),
+			plot.NewSeries("Total", raylibColor(scheme.PlotTotal), plotSamples),
+		),
+		virial: plot.NewChart("Virial ratio 2K/|W|", plotInterval,
+			plot.NewSeries("2K/|W|", raylibColor(scheme.PlotVirial), plotSamples),
+		),
+	}
+}
+
+// Sample records KE, PE and the virial ratio against simulation time if a
+// sample is due at wall-clock time now, the radial profiles if shown and the
+// group diagnostics
+func (d *diagnosticsPlots) Sample(now float64, sim *Simulation) {
+	if cfg.ShowProfiles {
+		d.profiles.Sample(now, sim)
+	}
+	if len(sim.groups) > 0 {
+		d.groups.Sample(now, sim)
+	}
+	if !d.energy.Due(now) {
+		return
+	}
+	kinetic, potentialRange := sim.sampleDiagnostics()
+	d.potential = potentialRange
+	potential := physics.PotentialEnergy(sim.Particles, sim.PotentialGrid, sim.mesh.Dx())
+	d.energy.Sample(now, sim.SimTime, kinetic, potential, kinetic+potential)
+	d.virial.Sample(now, sim.SimTime, physics.VirialRatio(kinetic, potential))
+}
+
+// Draw renders both charts stacked in the bottom-right corner of the screen
+func (d *diagnosticsPlots) Draw() {
+	x := float64(cfg.ScreenWidth - plotWidth - 10)
+	y := float64(cfg.ScreenHeight - 2*(plotHeight+30) - 10)
+	label := fmt.Sprintf("Potential range [%.4g, %.4g]", d.potential.Min, d.potential.Max)
+	rl.DrawText(label, int32(x+plotMargin), int32(y-40), plotFontSize+2, rl.RayWhite)
+	for _, chart := range []*plot.Chart{d.energy, d.virial} {
+		drawChart(chart, plot.Rect{X: x, Y: y, W: plotWidth, H: plotHeight})
+		y += plotHeight + 30
+	}
+}
+
+// drawChart draws a chart with its title, axes, tick labels and legend into area
+func drawChart(chart *plot.Chart, area plot.Rect) {
+	layout := chart.Layout(area, plotMargin, plotTicks)
+	p := layout.Plot
+	drawPlotFrame(chart.Title, area, p, layout.XTicks, layout.YTicks)
+	xLabel := chart.XLabel
+	if xLabel == "" {
+		xLabel = "t"
+	}
+	rl.DrawText(xLabel, int32(p.X+p.W)-6, int32(p.Y+p.H+18), plotFontSize, rl.LightGray)
+
+	legendX := int32(p.X + p.W)
+	for i := len(layout.Lines) - 1; i >= 0; i-- {
+		s := layout.Lines[i].Series
+		label := fmt.Sprintf("%s %.4g", s.Name, s.Last())
+		legendX -= rl.MeasureText(label, plotFontSize) + 12
+		rl.DrawText(label, legendX, int32(area.Y-14), plotFontSize, s.Color)
+	}
+
+	for _, line := range layout.Lines {
+		for i := 1; i < len(line.Points); i++ {
+			a, b := line.Points[i-1], line.Points[i]
+			rl.DrawLineV(rl.NewVector2(float32(a.X), float32(a.Y)), rl.NewVector2(float32(b.X), float32(b.Y)), line.Series.Color)
+		}
+	}
+}
+
+// drawPlotFrame draws the background and title of a plot in area, and the
+// axes of its plot area p with their tick marks and labels
+func drawPlotFrame(title string, area, p plot.Rect, xTicks, yTicks []plot.Tick) {
+	rl.DrawRectangle(int32(area.X-plotMargin/2), int32(area.Y-20), int32(area.W+plotMargin/2), int32(area.H+25), rl.Fade(rl.Black, 0.7))
+	rl.DrawText(title, int32(area.X+plotMargin), int32(area.Y-16), plotFontSize+2, rl.RayWhite)
+	rl.DrawRectangleLines(int32(p.X), int32(p.Y), int32(p.W), int32(p.H), rl.Gray)
+
+	for _, tick := range xTicks {
+		rl.DrawLine(int32(tick.Pos), int32(p.Y+p.H), int32(tick.Pos), int32(p.Y+p.H+4), rl.Gray)
+		width := rl.MeasureText(tick.Label, plotFontSize)
+		rl.DrawText(tick.Label, int32(tick.Pos)-width/2, int32(p.Y+p.H+6), plotFontSize, rl.LightGray)
+	}
+	for _, tick := range yTicks {
+		rl.DrawLine(int32(p.X-4), int32(tick.Pos), int32(p.X), int32(tick.Pos), rl.Gray)
+		width := rl.MeasureText(tick.Label, plotFontSize)
+		rl.DrawText(tick.Label, int32(p.X)-width-6, int32(tick.Pos)-plotFontSize/2, plotFontSize, rl.LightGray)
+	}
+}
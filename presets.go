@@ -0,0 +1,31 @@
+//go:build !js
+
+package main
+
+import (
+	"os"
+	"relativity_simulation_2d/internal/config"
+)
+
+// restartWithPreset replaces old with a new simulation under the preset after
+// the current one, layered as at launch so command-line flags still win. The
+// window keeps its size; old is left untouched if the preset does not validate
+func restartWithPreset(old *Simulation) (*Simulation, error) {
+	next, err := loadConfig(os.Args[1:], config.NextPreset(cfg.Preset))
+	if err != nil {
+		return nil, err
+	}
+	outputs.Redirect(next) // Into the same run directory
+	next.ScreenWidth, next.ScreenHeight = cfg.ScreenWidth, cfg.ScreenHeight
+	if err := next.Validate(); err != nil {
+		return nil, err
+	}
+
+	old.CleanupGPU()
+	_ = old.closeBinaryLog()
+	cfg = next
+	setComputeMode(nil, cfg.Compute())
+
+	sim := NewSimulation()
+	return sim, sim.openBinaryLog()
+}
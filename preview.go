@@ -0,0 +1,64 @@
+//go:build !js
+
+package main
+
+import (
+	"relativity_simulation_2d/internal/gpu"
+	"relativity_simulation_2d/internal/physics"
+)
+
+// previewGridFactor is the coarsening of each grid dimension in preview physics
+const previewGridFactor = 2
+
+// previewIdleDelay is the number of seconds without interaction before
+// preview physics returns to full resolution
+const previewIdleDelay = 0.5
+
+// recordInteraction records a frame of frameTime seconds, in which the user
+// moved the camera or changed settings if interacting, and starts or ends
+// preview physics
+func (q *qualityState) recordInteraction(frameTime float64, interacting bool) {
+	if interacting {
+		q.previewIdle = 0
+	} else {
+		q.previewIdle += frameTime
+	}
+	q.previewing = q.previewIdle < previewIdleDelay
+}
+
+// previewFactor returns the grid coarsening of the next physics step:
+// previewGridFactor while the user interacts with -preview-physics, else 1.
+// Only CPU particle-mesh steps on grids the factor divides are previewed
+func previewFactor() int {
+	switch {
+	case quality == nil || !quality.previewing || !cfg.PreviewPhysics:
+		return 1
+	case useGPU || cfg.DirectSolver():
+		return 1
+	case cfg.SimulationWidth%previewGridFactor != 0 || cfg.SimulationDepth%previewGridFactor != 0:
+		return 1
+	}
+	return previewGridFactor
+}
+
+// updatePreview runs one kick-drift-kick particle-mesh step with the forces
+// solved on a grid coarsened by previewFactor, which also fills the drawn
+// grids. The first kick reuses the forces of the previous step
+func (s *Simulation) updatePreview(deltaTime float32) {
+	field := &physics.ForceField{
+		AccelFieldX: s.AccelFieldX,
+		AccelFieldZ: s.AccelFieldZ,
+		Width:       cfg.SimulationWidth,
+		Height:      cfg.SimulationDepth,
+		CellSize:    s.mesh.CellSize,
+	}
+	cpu := gpu.CPUBackend{Mesh: s.mesh}
+	_ = cpu.IntegrateParticles(s.Particles, field, deltaTime*0.5, deltaTime)
+	physics.SolveCoarseInto(s.PotentialGrid, s.MassDensityGrid, s.Particles, previewFactor(), s.mesh, cfg.GravitationalConstant)
+	physics.CalculateGradientInto(field, s.PotentialGrid)
+	_ = cpu.IntegrateParticles(s.Particles, field, deltaTime*0.5, 0)
+	if s.deposit != nil {
+		s.deposit.Reset() // The density grid was written without it
+	}
+	s.advanceClock(deltaTime)
+}
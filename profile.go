@@ -0,0 +1,78 @@
+//go:build !js
+
+package main
+
+import (
+	"relativity_simulation_2d/internal/config"
+	"relativity_simulation_2d/internal/physics"
+	"relativity_simulation_2d/internal/plot"
+	"relativity_simulation_2d/internal/renderer"
+)
+
+// profileOptions returns the radial profile options configured by cfg
+func profileOptions() physics.ProfileOptions {
+	return physics.ProfileOptions{
+		Bins:                  cfg.ProfileBins,
+		Densest:               cfg.ProfileCenter != config.ProfileCenterMass,
+		Width:                 cfg.SimulationWidth,
+		Height:                cfg.SimulationDepth,
+		CellSize:              cfg.CellSize,
+		GravitationalConstant: cfg.GravitationalConstant,
+	}
+}
+
+// profilePanel holds the radial profile charts, recomputed from the
+// particles once per plotInterval while shown
+type profilePanel struct {
+	density    *plot.Chart
+	velocity   *plot.Chart
+	lastSample float64
+	sampled    bool
+}
+
+// Sample recomputes the profile charts if a sample is due at wall-clock time now
+func (p *profilePanel) Sample(now float64, sim *Simulation) {
+	if p.sampled && now-p.lastSample < plotInterval {
+		return
+	}
+	p.lastSample, p.sampled = now, true
+	p.density, p.velocity = profileCharts(physics.ComputeRadialProfile(sim.Particles, profileOptions()), ui.GetColorScheme())
+}
+
+// profileCharts returns charts of surface density, and of velocity
+// dispersion, rotation and circular velocity, against radius
+func profileCharts(profile physics.RadialProfile, scheme renderer.ColorScheme) (density, velocity *plot.Chart) {
+	n := len(profile.Bins)
+	sigma := plot.NewSeries("Sigma", raylibColor(scheme.PlotTotal), n)
+	dispersion := plot.NewSeries("sigma", raylibColor(scheme.PlotKinetic), n)
+	rotation := plot.NewSeries("Vrot", raylibColor(scheme.PlotVirial), n)
+	circular := plot.NewSeries("Vc", raylibColor(scheme.PlotPotential), n)
+	for _, b := range profile.Bins {
+		r := b.Radius()
+		sigma.Add(r, b.Density)
+		dispersion.Add(r, b.Dispersion)
+		rotation.Add(r, b.Rotation)
+		circular.Add(r, b.CircularVelocity)
+	}
+	density = plot.NewChart("Surface density", plotInterval, sigma)
+	velocity = plot.NewChart("Velocities", plotInterval, dispersion, rotation, circular)
+	density.XLabel, velocity.XLabel = "r", "r"
+	return density, velocity
+}
+
+// Draw renders both charts stacked on the right of the screen, above the
+// diagnostics plots when those are shown
+func (p *profilePanel) Draw() {
+	if !p.sampled {
+		return
+	}
+	x := float64(cfg.ScreenWidth - plotWidth - 10)
+	y := float64(cfg.ScreenHeight - 2*(plotHeight+30) - 10)
+	if cfg.ShowPlots {
+		y -= 2*(plotHeight+30) + 40
+	}
+	for _, chart := range []*plot.Chart{p.density, p.velocity} {
+		drawChart(chart, plot.Rect{X: x, Y: y, W: plotWidth, H: plotHeight})
+		y += plotHeight + 30
+	}
+}
@@ -0,0 +1,37 @@
+//go:build !js
+
+package main
+
+import (
+	"relativity_simulation_2d/internal/config"
+	"relativity_simulation_2d/internal/physics"
+	"relativity_simulation_2d/internal/renderer"
+	"testing"
+)
+
+// TestProfileCharts tests the configured profile center and the charts of a profile
+func TestProfileCharts(t *testing.T) {
+	saved := cfg
+	defer func() { cfg = saved }()
+	cfg = config.DefaultConfig()
+	cfg.ProfileBins = 8
+	if opts := profileOptions(); !opts.Densest || opts.Bins != 8 {
+		t.Errorf("Expected 8 bins around the densest point, got %+v", opts)
+	}
+	cfg.ProfileCenter = config.ProfileCenterMass
+	if profileOptions().Densest {
+		t.Error("Expected the center of mass")
+	}
+
+	particles := []*physics.Particle{
+		physics.NewParticle(1, -2, 0, 0, 0, 0, -1),
+		physics.NewParticle(1, 2, 0, 0, 0, 0, 1),
+	}
+	density, velocity := profileCharts(physics.ComputeRadialProfile(particles, profileOptions()), renderer.PaletteDefault.Scheme())
+	if density.Series[0].Len() != 8 || len(velocity.Series) != 3 || velocity.XLabel != "r" {
+		t.Fatalf("Expected 8 bins of density and 3 velocity series against r, got %d and %d", density.Series[0].Len(), len(velocity.Series))
+	}
+	if _, rotation := velocity.Series[1].At(7); rotation != 1 {
+		t.Errorf("Expected a rotation of 1 in the outer bin, got %g", rotation)
+	}
+}
@@ -0,0 +1,41 @@
+//go:build !js
+
+package main
+
+import (
+	"relativity_simulation_2d/internal/config"
+	"relativity_simulation_2d/internal/gpu"
+	"relativity_simulation_2d/internal/provenance"
+	"relativity_simulation_2d/internal/snapshot"
+)
+
+// backend names the back end computing the simulation: the GPU API while the
+// GPU runs the steps, else the CPU
+func (s *Simulation) backend() string {
+	if !useGPU || s.fallbackToCPU {
+		return provenance.BackendCPU
+	}
+	if cfg.GPUBackend == config.GPUBackendCUDA {
+		return provenance.BackendCUDA
+	}
+	if !cfg.DirectSolver() && !gpuFFTGrid(cfg.SimulationWidth, cfg.SimulationDepth) {
+		return provenance.BackendCPU
+	}
+	if s.compute != nil && s.compute.GetProcessor().GetType() == gpu.ProcessorTypeCPU {
+		return provenance.BackendCPU
+	}
+	return provenance.BackendOpenGL
+}
+
+// metadata returns the metadata stamped on the outputs of the simulation
+func (s *Simulation) metadata() provenance.Metadata {
+	return provenance.New(cfg, s.backend())
+}
+
+// snapshot returns a snapshot of the current state with its metadata
+func (s *Simulation) snapshot() *snapshot.Snapshot {
+	snap := snapshot.New(cfg, s.Particles, s.StepCount, s.SimTime)
+	meta := s.metadata()
+	snap.Metadata = &meta
+	return snap
+}
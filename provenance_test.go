@@ -0,0 +1,53 @@
+//go:build !js && !android
+
+package main
+
+import (
+	"relativity_simulation_2d/internal/config"
+	"relativity_simulation_2d/internal/provenance"
+	"relativity_simulation_2d/internal/snapshot"
+	"testing"
+)
+
+// TestSimulationBackend tests that the back end follows the compute mode
+// and any fallback to the CPU
+func TestSimulationBackend(t *testing.T) {
+	saved, savedGPU := cfg, useGPU
+	defer func() { cfg, useGPU = saved, savedGPU }()
+	cfg = config.DefaultConfig()
+	sim := &Simulation{}
+
+	useGPU = false
+	if got := sim.backend(); got != provenance.BackendCPU {
+		t.Errorf("Expected %s in CPU mode, got %s", provenance.BackendCPU, got)
+	}
+	useGPU = true
+	if got := sim.backend(); got != provenance.BackendOpenGL {
+		t.Errorf("Expected %s in GPU mode, got %s", provenance.BackendOpenGL, got)
+	}
+	cfg.GPUBackend = config.GPUBackendCUDA
+	if got := sim.backend(); got != provenance.BackendCUDA {
+		t.Errorf("Expected %s with the CUDA back end, got %s", provenance.BackendCUDA, got)
+	}
+	sim.fallbackToCPU = true
+	if got := sim.backend(); got != provenance.BackendCPU {
+		t.Errorf("Expected %s after a fallback, got %s", provenance.BackendCPU, got)
+	}
+}
+
+// TestFrameMetadata tests that rendered frames carry the metadata of the
+// recording run, or the recorded configuration's without it
+func TestFrameMetadata(t *testing.T) {
+	recorded := config.DefaultConfig()
+	recorded.Seed = 3
+	snap := snapshot.New(recorded, nil, 10, 1)
+	if got := frameMetadata(snap); got.ConfigHash != recorded.Hash() || got.Seed != 3 || got.Backend != "" {
+		t.Errorf("Expected the recorded configuration with an unknown back end, got %+v", got)
+	}
+
+	meta := provenance.New(recorded, provenance.BackendCUDA)
+	snap.Metadata = &meta
+	if got := frameMetadata(snap); got != meta {
+		t.Errorf("Expected the recording run's metadata %+v, got %+v", meta, got)
+	}
+}
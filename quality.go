@@ -0,0 +1,146 @@
+//go:build !js
+
+package main
+
+import (
+	"fmt"
+	"relativity_simulation_2d/internal/governor"
+	"relativity_simulation_2d/internal/renderer"
+	"strings"
+)
+
+// batteryPollInterval is the number of seconds between battery state reads
+const batteryPollInterval = 5.0
+
+// qualityState applies the adaptive quality level of the interactive session
+type qualityState struct {
+	governor     *governor.Governor // nil = fixed level
+	level        governor.Level
+	onBattery    bool
+	batteryCheck float64 // Seconds until the battery state is read again
+	restoreGPU   bool    // GPU mode was on when LevelCPU switched it off
+
+	// Physics reductions while steps run over cfg.PhysicsBudget
+	physics      *governor.PhysicsGovernor // nil = no budget
+	physicsLevel governor.PhysicsLevel
+
+	// Coarse physics steps while the user interacts, with cfg.PreviewPhysics
+	previewIdle float64 // Seconds since the last interaction
+	previewing  bool
+}
+
+// newQualityState creates the quality state for cfg. Without adaptive
+// quality the level is fixed: full, or reduced FPS in power-saver mode
+func newQualityState() *qualityState {
+	q := &qualityState{previewIdle: previewIdleDelay}
+	if cfg.AdaptiveQuality {
+		q.governor = governor.NewGovernor(governor.DefaultOptions())
+	} else if cfg.PowerSaver {
+		q.level = governor.LevelReducedFPS
+	}
+	if cfg.PhysicsBudget > 0 {
+		q.physics = governor.NewPhysicsGovernor(governor.DefaultPhysicsOptions(cfg.PhysicsBudget / 1000))
+	}
+	return q
+}
+
+// updatePhysics records the time spent stepping sim in one frame and applies
+// any physics level change to it
+func (q *qualityState) updatePhysics(sim *Simulation, frameTime, stepTime float64) {
+	if q.physics == nil {
+		return
+	}
+	level, changed := q.physics.Update(frameTime, stepTime)
+	if !changed {
+		return
+	}
+	previous := q.physicsLevel
+	q.physicsLevel = level
+	sim.mesh.SetDepositStride(level.DepositStride())
+	if ui != nil {
+		if level > previous {
+			ui.Notify(renderer.NotificationWarning, fmt.Sprintf("Physics over budget: %s", level))
+		} else {
+			ui.Notify(renderer.NotificationInfo, fmt.Sprintf("Physics restored: %s", level))
+		}
+	}
+}
+
+// physicsLevel returns the physics level of the interactive session
+// (governor.PhysicsFull without one, e.g. in headless runs)
+func physicsLevel() governor.PhysicsLevel {
+	if quality == nil {
+		return governor.PhysicsFull
+	}
+	return quality.physicsLevel
+}
+
+// update records one frame and applies any level change. frameTime is the
+// wall time of the frame and workTime the time spent simulating and drawing
+func (q *qualityState) update(frameTime, workTime float64) {
+	if q.governor == nil {
+		return
+	}
+	q.batteryCheck -= frameTime
+	if q.batteryCheck <= 0 {
+		q.onBattery = governor.OnBattery()
+		q.batteryCheck = batteryPollInterval
+	}
+
+	level, changed := q.governor.Update(frameTime, workTime, cfg.PowerSaver || q.onBattery)
+	if !changed {
+		return
+	}
+	previous := q.level
+	q.level = level
+	q.apply()
+	if ui != nil {
+		if level > previous {
+			ui.Notify(renderer.NotificationWarning, fmt.Sprintf("Quality lowered: %s", level))
+		} else {
+			ui.Notify(renderer.NotificationInfo, fmt.Sprintf("Quality raised: %s", level))
+		}
+	}
+}
+
+// apply switches the simulation in and out of CPU mode for the current
+// level. The frame rate and grid stride are read from the level when used
+func (q *qualityState) apply() {
+	switch {
+	case q.level.ForceCPU() && useGPU:
+		useGPU = false
+		q.restoreGPU = true
+	case !q.level.ForceCPU() && q.restoreGPU:
+		useGPU = true
+		q.restoreGPU = false
+	}
+}
+
+// label returns the status line text, or "" at full quality
+func (q *qualityState) label() string {
+	var parts []string
+	if text := q.renderLabel(); text != "" {
+		parts = append(parts, text)
+	}
+	if q.physicsLevel != governor.PhysicsFull {
+		parts = append(parts, fmt.Sprintf("Physics: %s (over %g ms budget)", q.physicsLevel, cfg.PhysicsBudget))
+	}
+	if factor := previewFactor(); factor > 1 {
+		parts = append(parts, fmt.Sprintf("Physics: preview at 1/%d resolution (interacting)", factor))
+	}
+	return strings.Join(parts, ", ")
+}
+
+// renderLabel returns the status line text of the render quality level, or "" at full quality
+func (q *qualityState) renderLabel() string {
+	switch {
+	case q.level == governor.LevelFull:
+		return ""
+	case q.governor == nil:
+		return fmt.Sprintf("Quality: %s (power saver)", q.level)
+	case cfg.PowerSaver || q.onBattery:
+		return fmt.Sprintf("Quality: %s (adaptive, saving power)", q.level)
+	default:
+		return fmt.Sprintf("Quality: %s (adaptive)", q.level)
+	}
+}
@@ -0,0 +1,36 @@
+//go:build !js
+
+package main
+
+import (
+	"relativity_simulation_2d/internal/config"
+	"testing"
+)
+
+// TestRadiusModel tests that the configured radius model sets the physical
+// radius of initial and spawned particles
+func TestRadiusModel(t *testing.T) {
+	saved, savedGPU := cfg, useGPU
+	defer func() { cfg, useGPU = saved, savedGPU }()
+	cfg = config.DefaultConfig()
+	cfg.SimulationWidth, cfg.SimulationDepth = 32, 32
+	cfg.NumParticles = 10
+	cfg.Seed = 2
+	useGPU = false
+
+	cfg.RadiusModel, cfg.ParticleRadius = config.RadiusModelFixed, 0.3
+	sim := NewSimulation()
+	sim.SpawnParticle(1, 1)
+	for i, p := range sim.Particles {
+		if p.Radius != 0.3 {
+			t.Fatalf("Particle %d has radius %v, want 0.3", i, p.Radius)
+		}
+	}
+
+	cfg.RadiusModel = config.RadiusModelOff
+	for i, p := range NewSimulation().Particles {
+		if p.Radius != 0 {
+			t.Fatalf("Particle %d has radius %v, want a point", i, p.Radius)
+		}
+	}
+}
@@ -0,0 +1,36 @@
+//go:build !js
+
+package main
+
+import (
+	"relativity_simulation_2d/internal/config"
+	"testing"
+)
+
+// TestTimeReversal tests that the time-reversal test retraces a PM run and
+// leaves the simulation as it was
+func TestTimeReversal(t *testing.T) {
+	saved, savedGPU := cfg, useGPU
+	defer func() { cfg, useGPU = saved, savedGPU }()
+	cfg = config.DefaultConfig()
+	cfg.SimulationWidth, cfg.SimulationDepth = 64, 64
+	cfg.NumParticles = 10
+	cfg.Seed = 7
+	cfg.ReversalSteps = 20
+	useGPU = false
+
+	sim := NewSimulation()
+	sim.Step(0.01)
+	before := *sim.Particles[0]
+
+	result, err := sim.timeReversal()
+	if err != nil {
+		t.Fatalf("timeReversal failed: %v", err)
+	}
+	if result.Steps != 20 || result.MaxError > 1e-6 {
+		t.Errorf("Expected 20 steps retraced to round-off, got %v", result)
+	}
+	if sim.StepCount != 1 || *sim.Particles[0] != before {
+		t.Errorf("Expected the simulation restored at step 1, got step %d and %+v", sim.StepCount, *sim.Particles[0])
+	}
+}
@@ -0,0 +1,70 @@
+//go:build !js
+
+package main
+
+import (
+	"math"
+	"relativity_simulation_2d/internal/config"
+	"testing"
+)
+
+// TestThreeBodySimulation tests that the three-body scenario replaces the
+// random particles with tracers and keeps them in its field
+func TestThreeBodySimulation(t *testing.T) {
+	saved, savedGPU := cfg, useGPU
+	defer func() { cfg, useGPU = saved, savedGPU }()
+	cfg = config.DefaultConfig()
+	cfg.SimulationWidth, cfg.SimulationDepth = 64, 64
+	cfg.NumParticles = 30
+	cfg.Seed = 9
+	cfg.Scenario = config.ScenarioThreeBody
+	useGPU = false
+
+	sim := NewSimulation()
+	if len(sim.Particles) != 30 || len(sim.Masses) != 2 || len(sim.Markers) != 5 || len(sim.Forces) == 0 {
+		t.Fatalf("Expected 30 tracers, 2 masses, 5 markers and the scenario forces, got %d, %d, %d and %d",
+			len(sim.Particles), len(sim.Masses), len(sim.Markers), len(sim.Forces))
+	}
+	for i, p := range sim.Particles {
+		if !p.IsTracer() {
+			t.Fatalf("Particle %d is not a tracer", i)
+		}
+	}
+
+	before := sim.Particles[0].Velocity
+	sim.Step(0.05)
+	if sim.Particles[0].Velocity == before {
+		t.Error("Expected the binary and frame forces to change the tracer velocity")
+	}
+}
+
+// TestTidalDisruptionSimulation tests that the tidal disruption scenario
+// places the cluster about the host and measures it
+func TestTidalDisruptionSimulation(t *testing.T) {
+	saved, savedGPU := cfg, useGPU
+	defer func() { cfg, useGPU = saved, savedGPU }()
+	cfg = config.DefaultConfig()
+	cfg.SimulationWidth, cfg.SimulationDepth = 64, 64
+	cfg.NumParticles = 200
+	cfg.Seed = 4
+	cfg.Scenario = config.ScenarioTidalDisruption
+	useGPU = false
+
+	sim := NewSimulation()
+	if len(sim.Particles) != 200 || len(sim.Masses) != 1 || len(sim.Forces) != 1 {
+		t.Fatalf("Expected 200 particles, the host and its force, got %d, %d and %d", len(sim.Particles), len(sim.Masses), len(sim.Forces))
+	}
+	stats, ok := sim.TidalStats()
+	if !ok {
+		t.Fatal("Expected tidal stats in the tidal disruption scenario")
+	}
+	if want := 0.35 * 64; math.Abs(stats.Distance-want) > 1 {
+		t.Errorf("Expected the cluster %g from the host, got %g", want, stats.Distance)
+	}
+	sim.Step(0.05)
+
+	cfg.Scenario = config.ScenarioRandom
+	if _, ok := NewSimulation().TidalStats(); ok {
+		t.Error("Expected no tidal stats for random particles")
+	}
+}
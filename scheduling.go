@@ -0,0 +1,34 @@
+//go:build !js
+
+package main
+
+import (
+	"fmt"
+	"io"
+	"relativity_simulation_2d/internal/affinity"
+	"relativity_simulation_2d/internal/config"
+	"relativity_simulation_2d/internal/physics"
+	"runtime"
+)
+
+// applyScheduling sets GOMAXPROCS, the CPU pinning, huge pages and, for
+// headless runs, the priority hints of the configuration. The hints are best
+// effort: a platform without them gets a warning and runs unpinned
+func applyScheduling(cfg *config.Config, warnings io.Writer) {
+	if cfg.MaxProcs > 0 {
+		runtime.GOMAXPROCS(cfg.MaxProcs)
+	}
+	physics.SetHugePages(cfg.HugePages)
+	if cfg.PinCores {
+		if cpus := affinity.PerformanceCores(); cpus == nil {
+			fmt.Fprintln(warnings, "Warning: no performance cores found; running on all CPUs")
+		} else if err := affinity.Pin(cpus); err != nil {
+			fmt.Fprintf(warnings, "Warning: cannot pin to the performance cores: %v\n", err)
+		}
+	}
+	if cfg.Headless && cfg.HeadlessNice > 0 {
+		if err := affinity.LowerPriority(cfg.HeadlessNice); err != nil {
+			fmt.Fprintf(warnings, "Warning: cannot lower the priority: %v\n", err)
+		}
+	}
+}
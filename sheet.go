@@ -0,0 +1,60 @@
+//go:build !js
+
+package main
+
+import (
+	rl "github.com/gen2brain/raylib-go/raylib"
+	"relativity_simulation_2d/internal/physics"
+	"relativity_simulation_2d/internal/renderer"
+	"relativity_simulation_2d/internal/simulation"
+)
+
+// sheetMaxCompression is the compression drawn in the hottest grid color
+const sheetMaxCompression = 100.0
+
+// addTracerSheet appends the configured tracer sheet to the particles
+func (s *Simulation) addTracerSheet() {
+	if cfg.TracerSheet <= 0 {
+		return
+	}
+	sheet, tracers := physics.NewTracerSheet(len(s.Particles), cfg.TracerSheet, cfg.TracerSheet, cfg.SimulationWidth, cfg.SimulationDepth, s.mesh.Dx())
+	s.Particles = append(s.Particles, tracers...)
+	s.sheet = &sheet
+}
+
+// sheetStretch returns the flow map's Jacobian at each tracer of the sheet
+// in the frame
+func sheetStretch(sheet *physics.TracerSheet, particles []physics.Particle) []float64 {
+	return sheet.Stretch(func(k int) physics.Vec3 { return particles[k].Position })
+}
+
+// drawTracerSheet draws the sheet as a mesh joining each tracer to its
+// neighbors along the sheet, in the grid colors from flat to compressed and
+// in the warning color where it has folded, and returns the number of lines
+// drawn; call it inside rl.BeginMode3D
+func drawTracerSheet(sheet *physics.TracerSheet, frame *simulation.Frame, scheme renderer.ColorScheme) int {
+	if sheet == nil || sheet.First+sheet.Len() > len(frame.Particles) {
+		return 0
+	}
+	stretch := sheetStretch(sheet, frame.Particles)
+	color := func(a, b int) rl.Color {
+		s := min(stretch[a], stretch[b])
+		if s < 0 {
+			return raylibColor(scheme.Warning)
+		}
+		return raylibColor(renderer.LerpColor(scheme.Grid, scheme.GridHot, physics.Compression(s, sheetMaxCompression)))
+	}
+	for j := 0; j < sheet.Rows; j++ {
+		for i := 0; i < sheet.Columns; i++ {
+			k := j*sheet.Columns + i
+			p := frame.Particles[sheet.Index(i, j)].Position
+			for _, n := range [2][2]int{{i + 1, j}, {i, j + 1}} {
+				q := frame.Particles[sheet.Index(n[0], n[1])].Position
+				dx, dz := sheet.Offset(p, q) // Joins neighbors across the periodic edges the short way
+				end := physics.NewVec3(p.X+dx, q.Y, p.Z+dz)
+				rl.DrawLine3D(toRaylib(p), toRaylib(end), color(k, sheet.Index(n[0], n[1])-sheet.First))
+			}
+		}
+	}
+	return 2 * sheet.Rows * sheet.Columns
+}
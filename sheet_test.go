@@ -0,0 +1,47 @@
+//go:build !js
+
+package main
+
+import (
+	"math"
+	"relativity_simulation_2d/internal/config"
+	"relativity_simulation_2d/internal/physics"
+	"testing"
+)
+
+// TestTracerSheet tests laying the sheet after the particles and following
+// it as the particles pull it in
+func TestTracerSheet(t *testing.T) {
+	saved, savedGPU := cfg, useGPU
+	defer func() { cfg, useGPU = saved, savedGPU }()
+	cfg = config.DefaultConfig()
+	cfg.SimulationWidth, cfg.SimulationDepth = 64, 64
+	cfg.NumParticles = 50
+	cfg.Seed = 7
+	cfg.TracerSheet = 16
+	cfg.ComputeMode = config.ComputeCPU
+	useGPU = false
+
+	sim := NewSimulation()
+	if sim.sheet == nil || sim.sheet.First != 50 || len(sim.Particles) != 50+16*16 {
+		t.Fatalf("Expected 256 tracers after 50 particles, got %d particles and sheet %+v", len(sim.Particles), sim.sheet)
+	}
+	position := func(k int) physics.Vec3 { return sim.Particles[k].Position }
+	for _, s := range sim.sheet.Stretch(position) {
+		if math.Abs(s-1) > 1e-9 {
+			t.Fatalf("Expected an undisturbed sheet, got stretch %g", s)
+		}
+	}
+	for i := 0; i < 20; i++ {
+		sim.Step(0.05)
+	}
+	moved := false
+	for _, s := range sim.sheet.Stretch(position) {
+		if math.Abs(s-1) > 1e-6 {
+			moved = true
+		}
+	}
+	if !moved {
+		t.Error("Expected the sheet to deform under the particles' pull")
+	}
+}
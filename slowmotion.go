@@ -0,0 +1,106 @@
+//go:build !js
+
+package main
+
+import (
+	"fmt"
+	rl "github.com/gen2brain/raylib-go/raylib"
+	"math"
+	"relativity_simulation_2d/internal/config"
+	"relativity_simulation_2d/internal/physics"
+	"relativity_simulation_2d/internal/renderer"
+	"relativity_simulation_2d/internal/simulation"
+	"relativity_simulation_2d/internal/slowmo"
+)
+
+// newSlowMotion creates the slow motion controller configured by cfg. It
+// only checks for triggers while cfg.SlowMotionTrigger is set
+func newSlowMotion() *slowmo.Controller {
+	c := slowmo.NewController(slowMotionTrigger(), slowmo.Watch{
+		Particle: cfg.SlowMotionParticle,
+		X:        cfg.SlowMotionRegionX,
+		Z:        cfg.SlowMotionRegionZ,
+		Radius:   cfg.SlowMotionRadius,
+	}, cfg.SimulationWidth, cfg.SimulationDepth)
+	c.Distance, c.Scale, c.Duration = cfg.SlowMotionDistance, cfg.SlowMotionScale, cfg.SlowMotionDuration
+	return c
+}
+
+// slowMotionTrigger returns the configured trigger
+func slowMotionTrigger() slowmo.Trigger {
+	if cfg.SlowMotionTrigger == config.SlowMotionContact {
+		return slowmo.TriggerContact
+	}
+	return slowmo.TriggerApproach
+}
+
+// checkSlowMotion looks for a trigger after a step and announces the slow motion it starts
+func checkSlowMotion(c *slowmo.Controller, sim *Simulation) {
+	if c == nil || cfg.SlowMotionTrigger == "" {
+		return
+	}
+	if event, fired := c.Check(sim.Particles); fired {
+		ui.Notify(renderer.NotificationInfo, fmt.Sprintf("Slow motion: %s of particles %d and %d at %.2f cells", c.Trigger, event.I, event.J, event.Separation))
+	}
+}
+
+// markSlowMotion watches the particle nearest the point at the center of the
+// view, or with region the circle around that point, turning slow motion on
+// for close approaches if it was off
+func markSlowMotion(c *slowmo.Controller, camera *rl.Camera, sim *Simulation, region bool) {
+	center := rl.NewVector2(float32(rl.GetScreenWidth())/2, float32(rl.GetScreenHeight())/2)
+	x, z, ok := groundPoint(rl.GetScreenToWorldRay(center, *camera))
+	if !ok {
+		ui.Notify(renderer.NotificationWarning, "Slow motion: aim at the simulation plane to mark")
+		return
+	}
+	if cfg.SlowMotionTrigger == "" {
+		cfg.SlowMotionTrigger = config.SlowMotionApproach
+		c.Trigger = slowmo.TriggerApproach
+	}
+
+	if region {
+		radius := cfg.SlowMotionRadius
+		if radius == 0 {
+			radius = math.Min(slowmo.DefaultRegionRadius, float64(min(cfg.SimulationWidth, cfg.SimulationDepth))/2)
+		}
+		c.SetWatch(slowmo.RegionWatch(x, z, radius))
+		ui.Notify(renderer.NotificationInfo, fmt.Sprintf("Slow motion: watching %.0f cells around (%.1f, %.1f) for %s", radius, x, z, c.Trigger))
+		return
+	}
+	nearest, best := -1, math.Inf(1)
+	for i, p := range sim.Particles {
+		if d := math.Hypot(p.Position.X-x, p.Position.Z-z); d < best {
+			nearest, best = i, d
+		}
+	}
+	if nearest < 0 {
+		ui.Notify(renderer.NotificationWarning, "Slow motion: no particle to mark")
+		return
+	}
+	c.SetWatch(slowmo.ParticleWatch(nearest))
+	ui.Notify(renderer.NotificationInfo, fmt.Sprintf("Slow motion: watching particle %d for %s", nearest, c.Trigger))
+}
+
+// drawSlowMotionWatch circles the watched particle or region on the
+// simulation plane and reports whether there is one; call it inside
+// rl.BeginMode3D
+func drawSlowMotionWatch(c *slowmo.Controller, frame *simulation.Frame, color rl.Color) bool {
+	if c == nil || cfg.SlowMotionTrigger == "" {
+		return false
+	}
+	flat := rl.NewVector3(1, 0, 0) // Rotates the circle from the x-y plane into the x-z plane
+	w := c.Watch()
+	switch {
+	case w.Particle >= 0 && w.Particle < len(frame.Particles):
+		p := frame.Particles[w.Particle]
+		display := renderer.DisplayRadius{Scale: cfg.DisplayScale, Min: cfg.MinDisplayRadius}
+		radius := max(2*display.Radius(p.Radius), 1)
+		rl.DrawCircle3D(toRaylib(p.Position), radius, flat, 90, color)
+	case w.Radius > 0:
+		rl.DrawCircle3D(toRaylib(physics.NewVec3(w.X, 0, w.Z)), float32(w.Radius), flat, 90, color)
+	default:
+		return false
+	}
+	return true
+}
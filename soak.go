@@ -0,0 +1,54 @@
+//go:build !js
+
+package main
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"relativity_simulation_2d/internal/provenance"
+	"relativity_simulation_2d/internal/soak"
+	"time"
+)
+
+// openSoak creates the soak monitor of a headless run, with its CSV log if
+// one is configured. close closes the log
+func (s *Simulation) openSoak() (monitor *soak.Monitor, close func() error, err error) {
+	opts := soak.Options{
+		Interval: time.Duration(cfg.SoakInterval * float64(time.Second)),
+		Thresholds: soak.Thresholds{
+			RSS:         cfg.SoakRSSGrowth,
+			GPUMemory:   cfg.SoakGPUGrowth,
+			AllocRate:   cfg.SoakAllocGrowth,
+			EnergyDrift: cfg.SoakDriftGrowth,
+		},
+		GPUMemory: func() (uint64, bool) { return GPUMemoryUsed(s.gpu) },
+	}
+	close = func() error { return nil }
+	if cfg.SoakPath != "" {
+		file, err := os.Create(cfg.SoakPath)
+		if err != nil {
+			return nil, nil, fmt.Errorf("failed to create soak log: %v", err)
+		}
+		if err := provenance.WriteSidecar(cfg.SoakPath, s.metadata()); err != nil {
+			_ = file.Close()
+			return nil, nil, err
+		}
+		opts.Log, close = file, file.Close
+	}
+	return soak.NewMonitor(opts, time.Now()), close, nil
+}
+
+// printSoak prints the trend of each metric watched through the soak run
+func printSoak(w io.Writer, m *soak.Monitor) {
+	samples := m.Samples()
+	if len(samples) == 0 {
+		fmt.Fprintln(w, "Soak: no samples (the run was shorter than -soak-interval)")
+		return
+	}
+	last := samples[len(samples)-1]
+	fmt.Fprintf(w, "Soak: %d samples over %s, RSS %.1f MB\n", len(samples), time.Duration(last.Elapsed*float64(time.Second)).Round(time.Second), float64(last.RSS)/(1<<20))
+	for _, t := range m.Trends() {
+		fmt.Fprintf(w, "  %v\n", t)
+	}
+}
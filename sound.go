@@ -0,0 +1,63 @@
+//go:build !js
+
+package main
+
+import (
+	"relativity_simulation_2d/internal/audio"
+	"relativity_simulation_2d/internal/renderer"
+)
+
+// soundState plays the curvature of the interactive session: a drone that
+// deepens with the potential well and pings for particles falling into it
+type soundState struct {
+	synth    *audio.Synth
+	sonifier *audio.Sonifier
+	player   *audio.Player // Opened on first use (nil = not opened)
+	enabled  bool
+}
+
+// newSoundState creates a silent sound state; the audio device is opened the
+// first time sound is enabled
+func newSoundState() *soundState {
+	synth := audio.NewSynth(audio.SampleRate)
+	return &soundState{synth: synth, sonifier: audio.NewSonifier(synth)}
+}
+
+// setEnabled turns the sound on or off and notifies the user. It reports a
+// warning and stays off if the audio device cannot be opened
+func (s *soundState) setEnabled(enabled bool) {
+	if enabled && s.player == nil {
+		player, err := audio.NewPlayer(s.synth)
+		if err != nil {
+			ui.Notify(renderer.NotificationWarning, "Sound unavailable: "+err.Error())
+			return
+		}
+		s.player = player
+	}
+	if s.player != nil {
+		s.player.SetPlaying(enabled)
+	}
+	s.enabled = enabled
+	if enabled {
+		ui.Notify(renderer.NotificationInfo, "Sound on")
+	} else {
+		ui.Notify(renderer.NotificationInfo, "Sound off")
+	}
+}
+
+// update follows the simulation and refills the audio stream; call it once per frame
+func (s *soundState) update(sim *Simulation) {
+	if !s.enabled {
+		return
+	}
+	s.sonifier.Update(sim.PotentialGrid, sim.Particles, sim.mesh.Dx())
+	s.player.Update()
+}
+
+// close releases the audio device
+func (s *soundState) close() {
+	if s.player != nil {
+		s.player.Close()
+		s.player = nil
+	}
+}
@@ -0,0 +1,82 @@
+//go:build !js
+
+package main
+
+import (
+	rl "github.com/gen2brain/raylib-go/raylib"
+	"relativity_simulation_2d/internal/renderer"
+)
+
+// stereoState renders side-by-side stereo: each eye draws the scene into its
+// own half-width render texture, and the two are shown next to each other
+// with the left eye on the left, for 3D TVs, phone viewers and parallel viewing
+type stereoState struct {
+	eyes          [2]rl.RenderTexture2D
+	width, height int32 // Size of each eye's texture (0 = not loaded)
+}
+
+// stereoEyes returns the left and right eye cameras, the camera moved half
+// the eye separation to either side (0 = renderer.DefaultEyeSeparation)
+func stereoEyes(camera rl.Camera, separation float64) (left, right rl.Camera) {
+	if separation == 0 {
+		separation = renderer.DefaultEyeSeparation
+	}
+	rig := renderer.NewCamera(fromRaylib(camera.Position), fromRaylib(camera.Target), fromRaylib(camera.Up))
+	l, r := rig.StereoPair(separation)
+	left, right = camera, camera
+	left.Position, left.Target = toRaylib(l.Position), toRaylib(l.Target)
+	right.Position, right.Target = toRaylib(r.Position), toRaylib(r.Target)
+	return left, right
+}
+
+// eyeOffset returns the distance of each stereo eye from the camera
+func eyeOffset(separation float64) float64 {
+	if separation == 0 {
+		separation = renderer.DefaultEyeSeparation
+	}
+	return separation / 2
+}
+
+// render draws the scene once per eye into the eye textures, resizing them to
+// half the window first if needed. Call it before rl.BeginDrawing
+func (s *stereoState) render(camera rl.Camera, separation float64, drawScene func()) {
+	width, height := int32(rl.GetScreenWidth()/2), int32(rl.GetScreenHeight())
+	if width != s.width || height != s.height {
+		s.close()
+		for i := range s.eyes {
+			s.eyes[i] = rl.LoadRenderTexture(width, height)
+		}
+		s.width, s.height = width, height
+	}
+
+	left, right := stereoEyes(camera, separation)
+	for i, eye := range [2]rl.Camera{left, right} {
+		rl.BeginTextureMode(s.eyes[i])
+		rl.ClearBackground(rl.Black)
+		rl.BeginMode3D(eye)
+		drawScene()
+		rl.EndMode3D()
+		rl.EndTextureMode()
+	}
+}
+
+// draw shows the eye textures side by side; call it between rl.BeginDrawing
+// and the HUD
+func (s *stereoState) draw() {
+	// Render textures are stored bottom-up, so flip them with a negative source height
+	source := rl.NewRectangle(0, 0, float32(s.width), -float32(s.height))
+	for i := range s.eyes {
+		rl.DrawTextureRec(s.eyes[i].Texture, source, rl.NewVector2(float32(int32(i)*s.width), 0), rl.White)
+	}
+}
+
+// close releases the eye textures
+func (s *stereoState) close() {
+	if s.width == 0 {
+		return
+	}
+	for i := range s.eyes {
+		rl.UnloadRenderTexture(s.eyes[i])
+	}
+	s.width, s.height = 0, 0
+}
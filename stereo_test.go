@@ -0,0 +1,32 @@
+//go:build !js
+
+package main
+
+import (
+	rl "github.com/gen2brain/raylib-go/raylib"
+	"relativity_simulation_2d/internal/renderer"
+	"testing"
+)
+
+// TestStereoEyes tests that the eye cameras straddle the camera and keep its projection
+func TestStereoEyes(t *testing.T) {
+	camera := rl.Camera{
+		Position:   rl.NewVector3(0, 0, 10),
+		Target:     rl.NewVector3(0, 0, 0),
+		Up:         rl.NewVector3(0, 1, 0),
+		Fovy:       65,
+		Projection: rl.CameraPerspective,
+	}
+	left, right := stereoEyes(camera, 4)
+	if left.Position != rl.NewVector3(-2, 0, 10) || right.Position != rl.NewVector3(2, 0, 10) {
+		t.Errorf("Expected eyes at x = -2 and 2, got %+v and %+v", left.Position, right.Position)
+	}
+	if left.Target != rl.NewVector3(-2, 0, 0) || right.Fovy != camera.Fovy {
+		t.Errorf("Expected parallel eyes with the camera's projection, got %+v and %+v", left, right)
+	}
+
+	left, _ = stereoEyes(camera, 0)
+	if want := float32(-renderer.DefaultEyeSeparation / 2); left.Position.X != want {
+		t.Errorf("Expected the default separation to put the left eye at x = %g, got %g", want, left.Position.X)
+	}
+}
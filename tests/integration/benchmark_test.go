@@ -1,8 +1,12 @@
 package integration_test
 
 import (
+	"fmt"
 	"relativity_simulation_2d/internal/config"
+	"relativity_simulation_2d/internal/cuda"
+	"relativity_simulation_2d/internal/gpu"
 	"relativity_simulation_2d/internal/physics"
+	"runtime"
 	"testing"
 	"time"
 )
@@ -19,7 +23,7 @@ func BenchmarkSimulation(b *testing.B) {
 	b.ResetTimer()
 	for i := 0; i < b.N; i++ {
 		// Run one complete simulation step
-		physics.RunTimeEvolution(particles, deltaTime, cfg.SimulationWidth, cfg.SimulationDepth, cfg.GravitationalConstant)
+		physics.RunTimeEvolution(particles, deltaTime, cfg.SimulationWidth, cfg.SimulationDepth, nil, cfg.GravitationalConstant)
 	}
 }
 
@@ -37,7 +41,7 @@ func BenchmarkSimulationVaryingParticles(b *testing.B) {
 
 			b.ResetTimer()
 			for i := 0; i < b.N; i++ {
-				physics.RunTimeEvolution(particles, deltaTime, cfg.SimulationWidth, cfg.SimulationDepth, cfg.GravitationalConstant)
+				physics.RunTimeEvolution(particles, deltaTime, cfg.SimulationWidth, cfg.SimulationDepth, nil, cfg.GravitationalConstant)
 			}
 		})
 	}
@@ -50,19 +54,139 @@ func BenchmarkMassDensityDeposition(b *testing.B) {
 
 	b.ResetTimer()
 	for i := 0; i < b.N; i++ {
-		_ = physics.DepositMassToGrid(particles, cfg.SimulationWidth, cfg.SimulationDepth)
+		_ = physics.DepositMassToGrid(particles, cfg.SimulationWidth, cfg.SimulationDepth, nil)
 	}
 }
 
-// BenchmarkPoissonSolver benchmarks the FFT-based Poisson solver
+// BenchmarkPoissonSolver benchmarks the FFT-based Poisson solver on the
+// default grid and on a large grid, with and without huge pages
 func BenchmarkPoissonSolver(b *testing.B) {
 	cfg := config.DefaultConfig()
-	particles := physics.InitializeParticles(cfg.NumParticles, float64(cfg.SimulationWidth), float64(cfg.SimulationDepth))
-	massDensityGrid := physics.DepositMassToGrid(particles, cfg.SimulationWidth, cfg.SimulationDepth)
+	for _, size := range []int{cfg.SimulationWidth, 1024} {
+		for _, huge := range []bool{false, true} {
+			if huge && size*size < physics.LargeGridCells {
+				continue // Small grids are not aligned, so the advice changes nothing
+			}
+			name := fmt.Sprintf("%dx%d", size, size)
+			if huge {
+				name += "/huge-pages"
+			}
+			b.Run(name, func(b *testing.B) {
+				physics.SetHugePages(huge)
+				defer physics.SetHugePages(false)
+				// Empty the scratch pools, so the solver allocates its FFT grid with this setting
+				runtime.GC()
+				runtime.GC()
+
+				particles := physics.InitializeParticles(cfg.NumParticles, float64(size), float64(size))
+				massDensityGrid := physics.DepositMassToGrid(particles, size, size, nil)
+				potentialGrid := physics.NewGrid(size, size)
+
+				b.ResetTimer()
+				for i := 0; i < b.N; i++ {
+					physics.SolvePoissonFFTInto(potentialGrid, massDensityGrid, 1, cfg.GravitationalConstant)
+				}
+			})
+		}
+	}
+}
 
-	b.ResetTimer()
-	for i := 0; i < b.N; i++ {
-		_ = physics.SolvePoissonFFT(massDensityGrid, cfg.SimulationWidth, cfg.SimulationDepth, cfg.GravitationalConstant)
+// pipelineParticles is the number of particles the GPU pipeline benchmarks step
+const pipelineParticles = 2000
+
+// cudaSolveBackend solves the Poisson equation on the CUDA device and runs
+// the other phases on the CPU, as the OpenGL backend does
+type cudaSolveBackend struct {
+	gpu.CPUBackend
+	solver *cuda.Solver
+}
+
+// SolvePoisson solves ∇²Φ = 4πGρ on the device
+func (b cudaSolveBackend) SolvePoisson(potential, density physics.Grid, gravitationalConstant float64) error {
+	return b.solver.SolvePoisson(potential, density, gravitationalConstant)
+}
+
+// BenchmarkGPUPipeline measures whole particle-mesh steps per second across
+// grid sizes, as the simulation's GPU step runs them:
+//   - cpu: every phase through the compute manager on the CPU, the baseline
+//   - phased: the same step with the Poisson solve on the device, copying the
+//     grids to and from it every step
+//   - fused: the whole step on the device with the grids kept there, and the
+//     grids downloaded for drawing afterwards
+//
+// The device variants are skipped without a CUDA device (build with -tags cuda)
+func BenchmarkGPUPipeline(b *testing.B) {
+	cfg := config.DefaultConfig()
+	for _, size := range []int{128, 256, 512, 1024} {
+		grids := func() (density, potential physics.Grid, field *physics.ForceField) {
+			field = &physics.ForceField{AccelFieldX: physics.NewGrid(size, size), AccelFieldZ: physics.NewGrid(size, size), Width: size, Height: size}
+			return physics.NewGrid(size, size), physics.NewGrid(size, size), field
+		}
+		stepManager := func(b *testing.B, manager *gpu.FallbackManager) {
+			particles := physics.InitializeParticles(pipelineParticles, float64(size), float64(size))
+			density, potential, field := grids()
+			manager.StepPM(particles, density, potential, field, 0.01, cfg.GravitationalConstant) // Warm the plans and buffers
+
+			b.ResetTimer()
+			for i := 0; i < b.N; i++ {
+				manager.StepPM(particles, density, potential, field, 0.01, cfg.GravitationalConstant)
+			}
+			b.StopTimer()
+			if manager.HasError() {
+				b.Fatalf("Step fell back to the CPU: %v", manager.GetLastError())
+			}
+			b.ReportMetric(float64(b.N)/b.Elapsed().Seconds(), "steps/s")
+		}
+		name := fmt.Sprintf("%dx%d", size, size)
+
+		b.Run(name+"/cpu", func(b *testing.B) {
+			manager := gpu.NewFallbackManager()
+			manager.RegisterBackend(gpu.ProcessorTypeCPU, gpu.CPUBackend{})
+			manager.SetMode(gpu.ModeCPU)
+			stepManager(b, manager)
+		})
+
+		b.Run(name+"/phased", func(b *testing.B) {
+			solver, err := cuda.NewSolver(size, size, 1)
+			if err != nil {
+				b.Skipf("No CUDA device: %v", err)
+			}
+			defer solver.Close()
+			manager := gpu.NewFallbackManager()
+			manager.RegisterBackend(gpu.ProcessorTypeCPU, gpu.CPUBackend{})
+			manager.RegisterBackend(gpu.ProcessorTypeGPU, cudaSolveBackend{solver: solver})
+			manager.SetGPUAvailable(true)
+			manager.SetMode(gpu.ModeGPU)
+			stepManager(b, manager)
+		})
+
+		b.Run(name+"/fused", func(b *testing.B) {
+			solver, err := cuda.NewSolver(size, size, 1)
+			if err != nil {
+				b.Skipf("No CUDA device: %v", err)
+			}
+			defer solver.Close()
+			particles := physics.InitializeParticles(pipelineParticles, float64(size), float64(size))
+			density, potential, field := grids()
+			step := func() error {
+				if err := solver.Step(particles, 0.01, cfg.GravitationalConstant); err != nil {
+					return err
+				}
+				return solver.Fields(density, potential, field)
+			}
+			if err := step(); err != nil {
+				b.Fatalf("Step failed: %v", err)
+			}
+
+			b.ResetTimer()
+			for i := 0; i < b.N; i++ {
+				if err := step(); err != nil {
+					b.Fatalf("Step failed: %v", err)
+				}
+			}
+			b.StopTimer()
+			b.ReportMetric(float64(b.N)/b.Elapsed().Seconds(), "steps/s")
+		})
 	}
 }
 
@@ -86,14 +210,14 @@ func TestPerformanceRegression(t *testing.T) {
 
 	// Warm up
 	for i := 0; i < 10; i++ {
-		physics.RunTimeEvolution(particles, deltaTime, cfg.SimulationWidth, cfg.SimulationDepth, cfg.GravitationalConstant)
+		physics.RunTimeEvolution(particles, deltaTime, cfg.SimulationWidth, cfg.SimulationDepth, nil, cfg.GravitationalConstant)
 	}
 
 	// Measure 100 iterations
 	start := time.Now()
 	iterations := 100
 	for i := 0; i < iterations; i++ {
-		physics.RunTimeEvolution(particles, deltaTime, cfg.SimulationWidth, cfg.SimulationDepth, cfg.GravitationalConstant)
+		physics.RunTimeEvolution(particles, deltaTime, cfg.SimulationWidth, cfg.SimulationDepth, nil, cfg.GravitationalConstant)
 	}
 	elapsed := time.Since(start)
 
@@ -128,9 +252,9 @@ func TestMemoryUsage(t *testing.T) {
 
 	// Run for 1000 iterations to check for memory leaks
 	for i := 0; i < 1000; i++ {
-		massDensityGrid := physics.DepositMassToGrid(particles, cfg.SimulationWidth, cfg.SimulationDepth)
-		_ = physics.SolvePoissonFFT(massDensityGrid, cfg.SimulationWidth, cfg.SimulationDepth, cfg.GravitationalConstant)
-		_ = physics.RunTimeEvolution(particles, deltaTime, cfg.SimulationWidth, cfg.SimulationDepth, cfg.GravitationalConstant)
+		massDensityGrid := physics.DepositMassToGrid(particles, cfg.SimulationWidth, cfg.SimulationDepth, nil)
+		_ = physics.SolvePoissonFFT(massDensityGrid, cfg.SimulationWidth, cfg.SimulationDepth, 1, cfg.GravitationalConstant)
+		_ = physics.RunTimeEvolution(particles, deltaTime, cfg.SimulationWidth, cfg.SimulationDepth, nil, cfg.GravitationalConstant)
 
 		// Periodically check that we haven't created too many particles
 		if len(particles) != cfg.NumParticles {
@@ -156,13 +280,13 @@ func TestScalability(t *testing.T) {
 
 		// Warm up
 		for j := 0; j < 5; j++ {
-			physics.RunTimeEvolution(particles, deltaTime, cfg.SimulationWidth, cfg.SimulationDepth, cfg.GravitationalConstant)
+			physics.RunTimeEvolution(particles, deltaTime, cfg.SimulationWidth, cfg.SimulationDepth, nil, cfg.GravitationalConstant)
 		}
 
 		// Measure
 		start := time.Now()
 		for j := 0; j < iterations; j++ {
-			physics.RunTimeEvolution(particles, deltaTime, cfg.SimulationWidth, cfg.SimulationDepth, cfg.GravitationalConstant)
+			physics.RunTimeEvolution(particles, deltaTime, cfg.SimulationWidth, cfg.SimulationDepth, nil, cfg.GravitationalConstant)
 		}
 		elapsed := time.Since(start)
 
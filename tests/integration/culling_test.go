@@ -0,0 +1,81 @@
+package integration_test
+
+import (
+	"math"
+	"relativity_simulation_2d/internal/config"
+	"relativity_simulation_2d/internal/physics"
+	"relativity_simulation_2d/internal/renderer"
+	"testing"
+)
+
+// TestParticleCulling verifies culling an evolved particle system against a
+// camera inside the domain: no particle on screen is culled, the culled ones
+// are off screen, and size culling only removes distant particles
+func TestParticleCulling(t *testing.T) {
+	cfg := config.DefaultConfig()
+	particles := physics.InitializeParticlesWithSeed(2000, float64(cfg.SimulationWidth), float64(cfg.SimulationDepth), 1)
+	for step := 0; step < 5; step++ {
+		physics.RunTimeEvolution(particles, 0.01, cfg.SimulationWidth, cfg.SimulationDepth, nil, cfg.GravitationalConstant)
+	}
+
+	// The session camera's lens, placed low over the domain
+	const fovY, aspect, height = 65.0, 16.0 / 9.0, 720
+	camera := renderer.NewCamera(physics.NewVec3(20, 15, 20), physics.NewVec3(0, 0, 0), physics.NewVec3(0, 1, 0))
+	camera.SetPerspective(fovY, aspect, 0.01, 1000)
+	display := renderer.DisplayRadius{Scale: cfg.DisplayScale, Min: cfg.MinDisplayRadius}
+
+	culler := renderer.NewParticleRenderer()
+	culler.SetDisplayRadius(display, 0)
+	culler.SetParticles(particles)
+	culler.SetCamera(camera)
+	culler.EnableCulling(true)
+
+	visible := map[int]bool{}
+	for _, i := range culler.GetVisibleIndices() {
+		visible[i] = true
+	}
+	if len(visible) == 0 || len(visible) == len(particles) {
+		t.Fatalf("Expected some of the %d particles culled, %d visible", len(particles), len(visible))
+	}
+
+	// Reference: the particle's center in normalized device coordinates
+	tanY := math.Tan(fovY * math.Pi / 360)
+	view := camera.GetViewMatrix()
+	onScreen := func(p *physics.Particle) bool {
+		c := view.TransformPoint(p.Position)
+		z := -c.Z
+		return z > 0.01 && z < 1000 && math.Abs(c.X/(z*tanY*aspect)) <= 1 && math.Abs(c.Y/(z*tanY)) <= 1
+	}
+	for i, p := range particles {
+		switch {
+		case onScreen(p) && !visible[i]:
+			t.Fatalf("Particle %d at %v is on screen but culled", i, p.Position)
+		case !onScreen(p) && visible[i]:
+			// Kept for the part of its sphere reaching into the view
+			if c := view.TransformPoint(p.Position); -c.Z < -float64(display.Radius(p.Radius)) {
+				t.Fatalf("Particle %d at %v is behind the camera but visible", i, p.Position)
+			}
+		}
+	}
+	if stats := culler.GetStats(); stats.Culled != len(particles)-len(visible) {
+		t.Errorf("Expected %d culled in the statistics, got %d", len(particles)-len(visible), stats.Culled)
+	}
+
+	// Particles drawn under 4 pixels in radius at the back of the view are
+	// culled by size, and every one left is drawn at least that large
+	const minPixels = 4.0
+	culler.SetSizeCulling(minPixels, height)
+	sized := culler.GetVisibleIndices()
+	t.Logf("%d of %d particles visible, %d above %g pixels", len(visible), len(particles), len(sized), minPixels)
+	if len(sized) == 0 || len(sized) >= len(visible) {
+		t.Fatalf("Expected size culling to remove some of the %d visible particles, %d left", len(visible), len(sized))
+	}
+	for _, i := range sized {
+		if !visible[i] {
+			t.Fatalf("Particle %d is visible only with size culling", i)
+		}
+		if r := camera.ProjectedRadius(particles[i].Position, float64(display.Radius(particles[i].Radius)), height); r < minPixels {
+			t.Fatalf("Particle %d is drawn with a radius of %g pixels, under %g", i, r, minPixels)
+		}
+	}
+}
@@ -293,7 +293,7 @@ func TestGPUPerformanceWithSimulation(t *testing.T) {
 				physics.RunTimeEvolution(
 					particles, deltaTime,
 					cfg.SimulationWidth, cfg.SimulationDepth,
-					cfg.GravitationalConstant,
+					nil, cfg.GravitationalConstant,
 				)
 			}
 
@@ -303,7 +303,7 @@ func TestGPUPerformanceWithSimulation(t *testing.T) {
 				physics.RunTimeEvolution(
 					particles, deltaTime,
 					cfg.SimulationWidth, cfg.SimulationDepth,
-					cfg.GravitationalConstant,
+					nil, cfg.GravitationalConstant,
 				)
 			}
 			elapsed := time.Since(start)
@@ -47,7 +47,7 @@ func TestFullSimulation(t *testing.T) {
 	}
 
 	// Test 2: Mass density grid deposition
-	massDensityGrid := physics.DepositMassToGrid(particles, cfg.SimulationWidth, cfg.SimulationDepth)
+	massDensityGrid := physics.DepositMassToGrid(particles, cfg.SimulationWidth, cfg.SimulationDepth, nil)
 	if len(massDensityGrid) != cfg.SimulationWidth {
 		t.Errorf("Mass density grid width mismatch: expected %d, got %d", cfg.SimulationWidth, len(massDensityGrid))
 	}
@@ -72,7 +72,7 @@ func TestFullSimulation(t *testing.T) {
 	}
 
 	// Test 3: Poisson solver (field calculation)
-	potentialGrid := physics.SolvePoissonFFT(massDensityGrid, cfg.SimulationWidth, cfg.SimulationDepth, cfg.GravitationalConstant)
+	potentialGrid := physics.SolvePoissonFFT(massDensityGrid, cfg.SimulationWidth, cfg.SimulationDepth, 1, cfg.GravitationalConstant)
 	if len(potentialGrid) != cfg.SimulationWidth {
 		t.Errorf("Potential grid width mismatch: expected %d, got %d", cfg.SimulationWidth, len(potentialGrid))
 	}
@@ -81,7 +81,7 @@ func TestFullSimulation(t *testing.T) {
 	}
 
 	// Test 4: Force calculation (via RunTimeEvolution which returns force field)
-	forceField := physics.RunTimeEvolution(particles, float32(0.001), cfg.SimulationWidth, cfg.SimulationDepth, cfg.GravitationalConstant)
+	forceField := physics.RunTimeEvolution(particles, float32(0.001), cfg.SimulationWidth, cfg.SimulationDepth, nil, cfg.GravitationalConstant)
 	if forceField == nil {
 		t.Fatal("Force field calculation returned nil")
 	}
@@ -102,7 +102,7 @@ func TestFullSimulation(t *testing.T) {
 
 	// Run multiple time steps
 	for step := 0; step < 10; step++ {
-		forceField = physics.RunTimeEvolution(particles, deltaTime, cfg.SimulationWidth, cfg.SimulationDepth, cfg.GravitationalConstant)
+		forceField = physics.RunTimeEvolution(particles, deltaTime, cfg.SimulationWidth, cfg.SimulationDepth, nil, cfg.GravitationalConstant)
 		if forceField == nil {
 			t.Fatalf("Time evolution failed at step %d", step)
 		}
@@ -123,7 +123,7 @@ func TestFullSimulation(t *testing.T) {
 	// Test 6: Performance benchmark
 	start := time.Now()
 	for i := 0; i < 100; i++ {
-		physics.RunTimeEvolution(particles, deltaTime, cfg.SimulationWidth, cfg.SimulationDepth, cfg.GravitationalConstant)
+		physics.RunTimeEvolution(particles, deltaTime, cfg.SimulationWidth, cfg.SimulationDepth, nil, cfg.GravitationalConstant)
 	}
 	elapsed := time.Since(start)
 	t.Logf("100 simulation steps completed in %v", elapsed)
@@ -143,7 +143,7 @@ func TestFullSimulation(t *testing.T) {
 
 	// Run simulation for a longer period
 	for i := 0; i < 100; i++ {
-		physics.RunTimeEvolution(particles, deltaTime, cfg.SimulationWidth, cfg.SimulationDepth, cfg.GravitationalConstant)
+		physics.RunTimeEvolution(particles, deltaTime, cfg.SimulationWidth, cfg.SimulationDepth, nil, cfg.GravitationalConstant)
 	}
 
 	// Calculate kinetic energy after evolution
@@ -184,13 +184,13 @@ func TestFullSimulation(t *testing.T) {
 	// Run a complete simulation cycle
 	for i := 0; i < 10; i++ {
 		// Deposit mass
-		massDensityGrid = physics.DepositMassToGrid(particles, cfg.SimulationWidth, cfg.SimulationDepth)
+		massDensityGrid = physics.DepositMassToGrid(particles, cfg.SimulationWidth, cfg.SimulationDepth, nil)
 
 		// Solve potential
-		_ = physics.SolvePoissonFFT(massDensityGrid, cfg.SimulationWidth, cfg.SimulationDepth, cfg.GravitationalConstant)
+		_ = physics.SolvePoissonFFT(massDensityGrid, cfg.SimulationWidth, cfg.SimulationDepth, 1, cfg.GravitationalConstant)
 
 		// Evolve in time (RunTimeEvolution handles force calculation internally)
-		_ = physics.RunTimeEvolution(particles, deltaTime, cfg.SimulationWidth, cfg.SimulationDepth, cfg.GravitationalConstant)
+		_ = physics.RunTimeEvolution(particles, deltaTime, cfg.SimulationWidth, cfg.SimulationDepth, nil, cfg.GravitationalConstant)
 	}
 
 	t.Log("Full simulation integration test completed successfully")
@@ -221,7 +221,7 @@ func TestSimulationWithCentralMass(t *testing.T) {
 	// Run simulation and verify orbits form
 	deltaTime := float32(0.01)
 	for i := 0; i < 50; i++ {
-		physics.RunTimeEvolution(particles, deltaTime, cfg.SimulationWidth, cfg.SimulationDepth, cfg.GravitationalConstant)
+		physics.RunTimeEvolution(particles, deltaTime, cfg.SimulationWidth, cfg.SimulationDepth, nil, cfg.GravitationalConstant)
 	}
 
 	// Verify central mass hasn't moved much from origin
@@ -254,7 +254,7 @@ func TestParallelSimulation(t *testing.T) {
 			deltaTime := float32(0.01)
 
 			for step := 0; step < 10; step++ {
-				physics.RunTimeEvolution(particles, deltaTime, cfg.SimulationWidth, cfg.SimulationDepth, cfg.GravitationalConstant)
+				physics.RunTimeEvolution(particles, deltaTime, cfg.SimulationWidth, cfg.SimulationDepth, nil, cfg.GravitationalConstant)
 			}
 		}(i)
 	}
@@ -0,0 +1,51 @@
+//go:build !js
+
+package main
+
+import (
+	rl "github.com/gen2brain/raylib-go/raylib"
+	"relativity_simulation_2d/internal/physics"
+	"unsafe"
+)
+
+// rl.Vector3 must be exactly three packed float32s for raylibVectors to
+// reinterpret float32 triples; this fails to compile if raylib changes it
+var _ = [1]struct{}{}[unsafe.Sizeof(rl.Vector3{})-3*unsafe.Sizeof(float32(0))]
+
+// toRaylib converts a physics vector to raylib's Vector3. The conversions
+// live here rather than in the physics package so that the engine builds
+// without raylib
+func toRaylib(v physics.Vec3) rl.Vector3 {
+	return rl.Vector3{
+		X: float32(v.X),
+		Y: float32(v.Y),
+		Z: float32(v.Z),
+	}
+}
+
+// fromRaylib converts raylib's Vector3 to a physics vector
+func fromRaylib(v rl.Vector3) physics.Vec3 {
+	return physics.Vec3{
+		X: float64(v.X),
+		Y: float64(v.Y),
+		Z: float64(v.Z),
+	}
+}
+
+// raylibVectors returns x, y, z float32 triples, such as a frame's packed
+// positions, as raylib vectors sharing their memory, without copying
+func raylibVectors(xyz []float32) []rl.Vector3 {
+	if len(xyz) < 3 {
+		return nil
+	}
+	return unsafe.Slice((*rl.Vector3)(unsafe.Pointer(&xyz[0])), len(xyz)/3)
+}
+
+// appendRaylib appends the raylib conversions of vectors to dst, for
+// batches that are not packed as float32 already
+func appendRaylib(dst []rl.Vector3, vectors []physics.Vec3) []rl.Vector3 {
+	for _, v := range vectors {
+		dst = append(dst, toRaylib(v))
+	}
+	return dst
+}